@@ -6,3 +6,35 @@ CONTROLLER_GEN ?= $(shell which controller-gen 2>/dev/null)
 generate: ## Generate deepcopy methods and CRD manifests.
 	@echo "====> $@"
 	$(CONTROLLER_GEN) object paths="./api/..."
+
+##@ Testing
+
+ENVTEST_K8S_VERSION ?= 1.36.0
+SETUP_ENVTEST ?= go run sigs.k8s.io/controller-runtime/tools/setup-envtest@release-0.24
+KIND ?= kind
+KIND_CLUSTER ?= klaus-e2e
+E2E_REGISTRY ?= klaus-operator.local
+E2E_IMAGE := $(E2E_REGISTRY)/klaus-operator:e2e
+E2E_NAMESPACE ?= klaus-system
+
+.PHONY: test-integration
+test-integration: ## Runs the envtest integration tests against a local kube-apiserver and etcd.
+	@echo "====> $@"
+	KUBEBUILDER_ASSETS="$$($(SETUP_ENVTEST) use $(ENVTEST_K8S_VERSION) -p path)" \
+		go test -tags integration -count=1 ./tests/integration/...
+
+.PHONY: test-e2e
+test-e2e: ## Installs the chart on a kind cluster and runs the e2e tests.
+	@echo "====> $@"
+	$(KIND) get clusters | grep -qx $(KIND_CLUSTER) || $(KIND) create cluster --name $(KIND_CLUSTER)
+	CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -o klaus-operator-linux-amd64 .
+	docker buildx build --platform linux/amd64 --load -t $(E2E_IMAGE) .
+	$(KIND) load docker-image $(E2E_IMAGE) --name $(KIND_CLUSTER)
+	helm upgrade --install klaus-operator helm/klaus-operator --namespace $(E2E_NAMESPACE) --create-namespace \
+		--values tests/test-values.yaml \
+		--set image.registry=$(E2E_REGISTRY) \
+		--set image.name=klaus-operator \
+		--set image.tag=e2e \
+		--set image.pullPolicy=Never \
+		--wait --timeout 5m
+	E2E_OPERATOR_NAMESPACE=$(E2E_NAMESPACE) go test -tags e2e -count=1 -v ./tests/e2e/...
@@ -26,6 +26,26 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&KlausInstanceList{},
 		&KlausMCPServer{},
 		&KlausMCPServerList{},
+		&KlausJob{},
+		&KlausJobList{},
+		&KlausCronJob{},
+		&KlausCronJobList{},
+		&KlausFleetStatus{},
+		&KlausFleetStatusList{},
+		&KlausQuota{},
+		&KlausQuotaList{},
+		&KlausTenant{},
+		&KlausTenantList{},
+		&KlausUsageReport{},
+		&KlausUsageReportList{},
+		&KlausTrigger{},
+		&KlausTriggerList{},
+		&KlausToolchain{},
+		&KlausToolchainList{},
+		&KlausSkillPack{},
+		&KlausSkillPackList{},
+		&KlausFleetOperation{},
+		&KlausFleetOperationList{},
 	)
 	metav1.AddToGroupVersion(scheme, GroupVersion)
 	return nil
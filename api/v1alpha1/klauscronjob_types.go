@@ -0,0 +1,141 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConcurrencyPolicy describes how overlapping KlausCronJob runs are handled.
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyAllow lets runs overlap.
+	ConcurrencyAllow ConcurrencyPolicy = "Allow"
+
+	// ConcurrencyForbid skips a run while the previous one is still active.
+	ConcurrencyForbid ConcurrencyPolicy = "Forbid"
+
+	// ConcurrencyReplace deletes the active run and starts a new one.
+	ConcurrencyReplace ConcurrencyPolicy = "Replace"
+)
+
+// KlausCronJobSpec defines the desired state of a KlausCronJob.
+type KlausCronJobSpec struct {
+	// Schedule is a five-field cron expression or macro (e.g. "0 2 * * *",
+	// "@weekly"), evaluated in timeZone.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the IANA time zone the schedule is evaluated in.
+	// Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// ConcurrencyPolicy controls overlapping runs.
+	// +kubebuilder:default=Forbid
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Suspend stops scheduling new runs. Active runs are not affected.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// StartingDeadlineSeconds skips a run that could not be started within
+	// this many seconds of its scheduled time (e.g. after operator downtime).
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// SuccessfulJobsHistoryLimit is the number of succeeded KlausJobs to keep.
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed KlausJobs to keep.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// JobTemplate is the KlausJob spec stamped out for each run.
+	JobTemplate KlausJobSpec `json:"jobTemplate"`
+}
+
+// KlausCronJobStatus defines the observed state of a KlausCronJob.
+type KlausCronJobStatus struct {
+	// Active lists the names of KlausJobs that are currently running.
+	// +optional
+	Active []string `json:"active,omitempty"`
+
+	// LastScheduleTime is the last time a run was scheduled.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the completion time of the last successful run.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// LastRun summarises the most recently finished run.
+	// +optional
+	LastRun *CronJobRunSummary `json:"lastRun,omitempty"`
+
+	// Conditions represent the latest available observations.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// CronJobRunSummary describes a finished KlausJob run.
+type CronJobRunSummary struct {
+	// JobName is the name of the KlausJob.
+	JobName string `json:"jobName"`
+
+	// State is the final state of the run.
+	State JobState `json:"state"`
+
+	// CompletionTime is when the run finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Result is the final output of the run.
+	// +optional
+	Result string `json:"result,omitempty"`
+
+	// Summary is the exit summary of the run, if the agent wrote one.
+	// +optional
+	Summary *JobExitSummary `json:"summary,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Active",type=string,JSONPath=`.status.active`
+// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Last Success",type=date,JSONPath=`.status.lastSuccessfulTime`,priority=1
+// +kubebuilder:printcolumn:name="Scheduled",type=string,JSONPath=`.status.conditions[?(@.type=="Scheduled")].status`,priority=1
+// +kubebuilder:resource:shortName=kcron,categories=klaus
+
+// KlausCronJob is the Schema for the klauscronjobs API.
+// It creates KlausJob runs on a recurring schedule.
+type KlausCronJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausCronJobSpec   `json:"spec,omitempty"`
+	Status KlausCronJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausCronJobList contains a list of KlausCronJob.
+type KlausCronJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausCronJob `json:"items"`
+}
@@ -0,0 +1,173 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FleetOperationType is the bulk action of a KlausFleetOperation.
+// +kubebuilder:validation:Enum=Restart;ReloadAPIKeys;DrainToolchain
+type FleetOperationType string
+
+const (
+	// FleetOperationRestart restarts the pods of the selected instances.
+	FleetOperationRestart FleetOperationType = "Restart"
+
+	// FleetOperationReloadAPIKeys copies the Anthropic API key Secret to
+	// the namespaces of the selected instances again and restarts their
+	// pods, so they use a key rotated in the operator's Secret. It does not
+	// issue a new key itself.
+	FleetOperationReloadAPIKeys FleetOperationType = "ReloadAPIKeys"
+
+	// FleetOperationDrainToolchain moves the selected instances from the
+	// KlausToolchain in spec.toolchain to spec.replacementToolchain.
+	FleetOperationDrainToolchain FleetOperationType = "DrainToolchain"
+)
+
+// KlausFleetOperationSpec defines a bulk action on the KlausInstances in the
+// namespace of the operation. The spec is immutable; create another
+// operation to repeat or change it.
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+// +kubebuilder:validation:XValidation:rule="self.type != 'DrainToolchain' || (has(self.toolchain) && has(self.replacementToolchain))",message="DrainToolchain requires toolchain and replacementToolchain"
+// +kubebuilder:validation:XValidation:rule="!has(self.toolchain) || !has(self.replacementToolchain) || self.toolchain != self.replacementToolchain",message="replacementToolchain must differ from toolchain"
+type KlausFleetOperationSpec struct {
+	// Type is the action applied to every selected instance.
+	Type FleetOperationType `json:"type"`
+
+	// Personality selects the instances whose spec.personality is this OCI
+	// reference. A reference without a tag or digest selects every version
+	// of the repository.
+	// +optional
+	Personality string `json:"personality,omitempty"`
+
+	// Owner selects the instances of this owner.
+	// +optional
+	// +kubebuilder:validation:MaxLength=254
+	Owner string `json:"owner,omitempty"`
+
+	// Toolchain selects the instances whose spec.toolchainRef names this
+	// KlausToolchain. DrainToolchain requires it.
+	// +optional
+	Toolchain string `json:"toolchain,omitempty"`
+
+	// ReplacementToolchain is the KlausToolchain DrainToolchain sets as
+	// spec.toolchainRef of the selected instances.
+	// +optional
+	ReplacementToolchain string `json:"replacementToolchain,omitempty"`
+
+	// BatchSize is the number of instances changed at a time. The next
+	// instances are changed once the previous ones are rolled out or failed.
+	// +optional
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	BatchSize int32 `json:"batchSize,omitempty"`
+
+	// MaxFailures is the number of instances the operation may fail on.
+	// Once more failed, it changes no further instance and fails. Defaults
+	// to 0: the first failure stops the operation.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxFailures int32 `json:"maxFailures,omitempty"`
+}
+
+// FleetOperationState represents the lifecycle state of a
+// KlausFleetOperation.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type FleetOperationState string
+
+const (
+	FleetOperationStatePending   FleetOperationState = "Pending"
+	FleetOperationStateRunning   FleetOperationState = "Running"
+	FleetOperationStateSucceeded FleetOperationState = "Succeeded"
+	FleetOperationStateFailed    FleetOperationState = "Failed"
+)
+
+// FleetOperationFailure is an instance the operation failed on.
+type FleetOperationFailure struct {
+	// Name is the instance name.
+	Name string `json:"name"`
+
+	// Message says why the operation failed on the instance.
+	Message string `json:"message"`
+}
+
+// KlausFleetOperationStatus defines the observed state of a
+// KlausFleetOperation.
+type KlausFleetOperationStatus struct {
+	// State is the current lifecycle state. The operation succeeds once
+	// every selected instance is rolled out, and fails when it failed on
+	// more than spec.maxFailures of them or could not start.
+	// +optional
+	State FleetOperationState `json:"state,omitempty"`
+
+	// Message describes the progress or why the operation failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Total is the number of selected instances.
+	// +optional
+	Total int32 `json:"total,omitempty"`
+
+	// Completed is the number of instances that were changed and rolled
+	// out.
+	// +optional
+	Completed int32 `json:"completed,omitempty"`
+
+	// Failed is the number of instances the operation failed on.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// InProgress lists the instances that were changed and are rolling out.
+	// +optional
+	InProgress []string `json:"inProgress,omitempty"`
+
+	// Changed lists every instance the operation changed. Instances are
+	// recorded before they are changed, so one missing from a lagging cache
+	// is not changed, and restarted, twice.
+	// +optional
+	Changed []string `json:"changed,omitempty"`
+
+	// Failures lists the instances the operation failed on, truncated to
+	// the first 50 in name order.
+	// +optional
+	Failures []FleetOperationFailure `json:"failures,omitempty"`
+
+	// StartTime is when the operation changed its first instance.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the operation succeeded or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.total`
+// +kubebuilder:printcolumn:name="Completed",type=integer,JSONPath=`.status.completed`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failed`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:shortName=kfop,categories=klaus
+
+// KlausFleetOperation is a maintenance action on many KlausInstances at
+// once, such as restarting every instance of a personality, reloading the
+// copied API key Secrets or draining instances off a deprecated toolchain.
+// The operator applies it in batches and tracks its progress in the status.
+type KlausFleetOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausFleetOperationSpec   `json:"spec,omitempty"`
+	Status KlausFleetOperationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausFleetOperationList contains a list of KlausFleetOperation.
+type KlausFleetOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausFleetOperation `json:"items"`
+}
@@ -0,0 +1,207 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FleetStatusName is the name of the KlausFleetStatus singleton maintained in
+// the operator namespace.
+const FleetStatusName = "fleet"
+
+// KlausFleetStatusSpec is intentionally empty: the object is created and
+// maintained by the operator and only its status carries information.
+type KlausFleetStatusSpec struct{}
+
+// StateCount is the number of objects in a lifecycle state.
+type StateCount struct {
+	// State is the lifecycle state. Objects the controller has not yet
+	// reconciled are counted under "Unknown".
+	State string `json:"state"`
+
+	// Count is the number of objects in the state.
+	Count int32 `json:"count"`
+}
+
+// ReasonCount is the number of instances reporting an error reason.
+type ReasonCount struct {
+	// Reason is the reason of the instance Ready condition.
+	Reason string `json:"reason"`
+
+	// Count is the number of instances reporting the reason.
+	Count int32 `json:"count"`
+}
+
+// InstanceSummary identifies an instance in the fleet status.
+type InstanceSummary struct {
+	// Name is the instance name.
+	Name string `json:"name"`
+
+	// Namespace is the instance namespace.
+	Namespace string `json:"namespace"`
+
+	// Owner is the instance owner.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// State is the current lifecycle state of the instance.
+	// +optional
+	State InstanceState `json:"state,omitempty"`
+
+	// Reason is the reason of the instance Ready condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Since is when the instance stopped being ready, or its creation time
+	// if it never was.
+	// +optional
+	Since *metav1.Time `json:"since,omitempty"`
+}
+
+// OCICacheStats describes the on-disk OCI registry cache of the operator.
+type OCICacheStats struct {
+	// Enabled reports whether the operator runs with an OCI cache directory.
+	Enabled bool `json:"enabled"`
+
+	// References is the number of cached tag-to-digest resolutions.
+	// +optional
+	References int32 `json:"references,omitempty"`
+
+	// TagLists is the number of cached repository tag lists.
+	// +optional
+	TagLists int32 `json:"tagLists,omitempty"`
+
+	// Blobs is the number of cached manifests and blobs.
+	// +optional
+	Blobs int32 `json:"blobs,omitempty"`
+
+	// SizeBytes is the total size of the cached manifests and blobs.
+	// +optional
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// Error is set when the cache directory could not be read.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PersonalityRollout is the progress of a staged rollout of a new
+// personality revision across the instances sharing a personality
+// reference.
+type PersonalityRollout struct {
+	// Personality is the personality reference of the instances.
+	Personality string `json:"personality"`
+
+	// Revision is the revision being rolled out.
+	Revision string `json:"revision"`
+
+	// Updated is the number of instances on the new revision.
+	Updated int32 `json:"updated"`
+
+	// Total is the number of instances with the personality reference.
+	Total int32 `json:"total"`
+}
+
+// FleetEvent is a recent Kubernetes Event recorded for a Klaus object.
+type FleetEvent struct {
+	// Time is when the event was last observed.
+	Time metav1.Time `json:"time"`
+
+	// Kind is the kind of the object the event is about.
+	Kind string `json:"kind"`
+
+	// Name is the name of the object the event is about.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the object the event is about.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Type is the event type (Normal or Warning).
+	Type string `json:"type"`
+
+	// Reason is the event reason.
+	Reason string `json:"reason"`
+
+	// Message is the event message.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Count is how often the event occurred.
+	// +optional
+	Count int32 `json:"count,omitempty"`
+}
+
+// KlausFleetStatusStatus is the aggregated state of all Klaus objects in the
+// cluster.
+type KlausFleetStatusStatus struct {
+	// ObservedAt is when the operator last refreshed the status.
+	// +optional
+	ObservedAt *metav1.Time `json:"observedAt,omitempty"`
+
+	// Instances is the total number of KlausInstances.
+	// +optional
+	Instances int32 `json:"instances,omitempty"`
+
+	// ReadyInstances is the number of KlausInstances whose Ready condition
+	// is True.
+	// +optional
+	ReadyInstances int32 `json:"readyInstances,omitempty"`
+
+	// InstanceStates counts KlausInstances by lifecycle state.
+	// +optional
+	InstanceStates []StateCount `json:"instanceStates,omitempty"`
+
+	// JobStates counts KlausJobs by lifecycle state.
+	// +optional
+	JobStates []StateCount `json:"jobStates,omitempty"`
+
+	// ErrorReasons ranks the reasons of not-ready instances, most frequent
+	// first.
+	// +optional
+	ErrorReasons []ReasonCount `json:"errorReasons,omitempty"`
+
+	// OldestNotReady is the instance that has been not ready the longest.
+	// Stopped instances are not considered.
+	// +optional
+	OldestNotReady *InstanceSummary `json:"oldestNotReady,omitempty"`
+
+	// OCICache describes the operator's OCI registry cache.
+	// +optional
+	OCICache *OCICacheStats `json:"ociCache,omitempty"`
+
+	// PersonalityRollouts lists the staged personality rollouts in progress.
+	// +optional
+	PersonalityRollouts []PersonalityRollout `json:"personalityRollouts,omitempty"`
+
+	// RecentEvents lists the most recent Events recorded for Klaus objects,
+	// newest first.
+	// +optional
+	RecentEvents []FleetEvent `json:"recentEvents,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Instances",type=integer,JSONPath=`.status.instances`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyInstances`
+// +kubebuilder:printcolumn:name="Observed",type=date,JSONPath=`.status.observedAt`
+// +kubebuilder:resource:shortName=kfleet,categories=klaus
+
+// KlausFleetStatus is the Schema for the klausfleetstatuses API.
+// The operator maintains a single KlausFleetStatus named "fleet" in its own
+// namespace that aggregates the state of all Klaus objects for operators of
+// the platform.
+type KlausFleetStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausFleetStatusSpec   `json:"spec,omitempty"`
+	Status KlausFleetStatusStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausFleetStatusList contains a list of KlausFleetStatus.
+type KlausFleetStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausFleetStatus `json:"items"`
+}
@@ -0,0 +1,5 @@
+package v1alpha1
+
+// Hub marks v1alpha1 as the version other KlausInstance versions convert
+// through; it is also the storage version.
+func (*KlausInstance) Hub() {}
@@ -5,14 +5,38 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // KlausInstanceSpec defines the desired state of a KlausInstance.
+// +kubebuilder:validation:XValidation:rule="!(has(self.personality) && has(self.inlinePersonality))",message="personality and inlinePersonality are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.image) && size(self.image) > 0 && has(self.toolchainRef) && size(self.toolchainRef) > 0)",message="image and toolchainRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.cloneFrom) || !has(self.cloneFrom.workspace) || !self.cloneFrom.workspace || has(self.workspace)",message="cloneFrom.workspace requires workspace to be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.claude) || !has(self.claude.settingsFile) || size(self.claude.settingsFile) == 0 || ((!has(self.hooks) || size(self.hooks) == 0) && (!has(self.claude.outputStyle) || size(self.claude.outputStyle) == 0) && !has(self.claude.statusLine))",message="hooks, claude.outputStyle and claude.statusLine are rendered to settings.json and mutually exclusive with claude.settingsFile"
+// +kubebuilder:validation:XValidation:rule="!(has(self.desiredState) && self.desiredState == 'Running' && has(self.stopped) && self.stopped)",message="stopped contradicts desiredState Running; set desiredState only"
 type KlausInstanceSpec struct {
 	// Owner is the user identity (email) that owns this instance.
 	// Used for access control and namespace isolation.
+	// +kubebuilder:validation:MaxLength=254
+	// +kubebuilder:validation:XValidation:rule="self.matches('^[^@ ]+@[^@ ]+$')",message="owner must be an email address"
 	Owner string `json:"owner"`
 
+	// Owners lists additional user identities (emails) sharing the
+	// instance through the MCP server. The namespace is still derived from
+	// Owner, which remains the primary owner.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:MaxLength=254
+	// +kubebuilder:validation:XValidation:rule="self.all(o, o.matches('^[^@ ]+@[^@ ]+$'))",message="owners must be email addresses"
+	Owners []string `json:"owners,omitempty"`
+
+	// OwnerGroups lists groups, as found in the groups claim of the caller's
+	// token, whose members share the instance through the MCP server.
+	// +optional
+	// +listType=set
+	OwnerGroups []string `json:"ownerGroups,omitempty"`
+
 	// Personality is an OCI reference to a personality artifact that provides
 	// default configuration for this instance. The artifact must contain a
 	// personality.yaml file describing plugins, image overrides, and system prompts.
@@ -20,11 +44,42 @@ type KlausInstanceSpec struct {
 	// +optional
 	Personality string `json:"personality,omitempty"`
 
+	// InlinePersonality defines a personality directly on the instance, for
+	// one-off experiments that do not warrant publishing an artifact. It is
+	// merged like a referenced personality and is mutually exclusive with
+	// Personality.
+	// +optional
+	InlinePersonality *InlinePersonality `json:"inlinePersonality,omitempty"`
+
+	// PersonalityUpdatePolicy controls when the instance picks up new
+	// revisions of its personality. Auto (default) resolves the reference on
+	// every reconcile; Manual keeps the revision recorded in
+	// status.personalityRevision until spec.personality or the
+	// klaus.giantswarm.io/personality-rollout annotation changes.
+	// +optional
+	PersonalityUpdatePolicy PersonalityUpdatePolicy `json:"personalityUpdatePolicy,omitempty"`
+
+	// PersonalityRefreshPolicy controls when the tag the personality
+	// reference resolves to is resolved to a new digest. Pods always mount
+	// the digest recorded in status.resolvedPersonality. OnSpecChange
+	// (default) re-resolves it whenever the spec changes; Periodic also
+	// re-resolves the reference at the operator's personality resync
+	// interval, picking up new versions and re-pushed tags; Never keeps the
+	// recorded digest until the resolved reference changes.
+	// +optional
+	PersonalityRefreshPolicy RefreshPolicy `json:"personalityRefreshPolicy,omitempty"`
+
 	// Image overrides the container image for this instance.
 	// Takes precedence over personality image and the operator default.
 	// +optional
 	Image string `json:"image,omitempty"`
 
+	// ToolchainRef names a KlausToolchain in the operator namespace whose
+	// image the instance uses, like spec.image. Mutually exclusive with
+	// Image.
+	// +optional
+	ToolchainRef string `json:"toolchainRef,omitempty"`
+
 	// Claude contains all Claude Code agent configuration.
 	// +optional
 	Claude ClaudeConfig `json:"claude,omitempty"`
@@ -33,6 +88,15 @@ type KlausInstanceSpec struct {
 	// +optional
 	Plugins []PluginReference `json:"plugins,omitempty"`
 
+	// PluginRefreshPolicy controls when tag-based plugin references are
+	// resolved to a new digest. Pods always mount the digest recorded in
+	// status.resolvedPlugins. OnSpecChange (default) re-resolves the tags
+	// whenever the spec changes; Periodic also re-resolves them at the
+	// operator's plugin refresh interval; Never keeps the recorded digest
+	// until the plugin reference itself changes.
+	// +optional
+	PluginRefreshPolicy RefreshPolicy `json:"pluginRefreshPolicy,omitempty"`
+
 	// PluginDirs specifies additional user-provided plugin directory paths.
 	// These are merged with the OCI plugin mount paths into CLAUDE_PLUGIN_DIRS.
 	// +optional
@@ -55,6 +119,29 @@ type KlausInstanceSpec struct {
 	// +optional
 	AgentFiles map[string]AgentFileConfig `json:"agentFiles,omitempty"`
 
+	// Commands defines inline slash commands rendered as command files.
+	// +optional
+	Commands map[string]CommandConfig `json:"commands,omitempty"`
+
+	// SkillPacks references KlausSkillPacks in the namespace of the instance
+	// whose skills, agent files and commands are added to the instance. An
+	// entry of spec.skills, spec.agentFiles or spec.commands with the same
+	// name takes precedence, as do earlier packs over later ones.
+	// +optional
+	SkillPacks []SkillPackReference `json:"skillPacks,omitempty"`
+
+	// Memory defines CLAUDE.md memory files with standing instructions for
+	// the agent.
+	// +optional
+	Memory *MemoryConfig `json:"memory,omitempty"`
+
+	// TemplateValues are substituted for ${name} placeholders in the system
+	// prompts, skills, commands, memory and soul. The built-in
+	// ${klaus.owner}, ${klaus.instance}, ${klaus.namespace} and
+	// ${klaus.repo} are always available.
+	// +optional
+	TemplateValues map[string]string `json:"templateValues,omitempty"`
+
 	// Hooks defines lifecycle hooks rendered to settings.json.
 	// Mutually exclusive with Claude.SettingsFile.
 	// +optional
@@ -76,10 +163,38 @@ type KlausInstanceSpec struct {
 	// +optional
 	Workspace *WorkspaceConfig `json:"workspace,omitempty"`
 
+	// CloneFrom records the instance this one was cloned from, e.g. by the
+	// clone_instance MCP tool, and optionally copies its workspace.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="cloneFrom is immutable"
+	CloneFrom *CloneSource `json:"cloneFrom,omitempty"`
+
 	// Resources specifies compute resource requirements for the instance pod.
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// ExtraEnv are additional environment variables of the Klaus container,
+	// e.g. PIP_INDEX_URL. Names starting with KLAUS_ or CLAUDE_, and the
+	// variables the operator sets, are rejected. Referenced Secrets and
+	// ConfigMaps must exist in the namespace the pod runs in; in
+	// namespace-scoped mode Secrets must be labeled
+	// klaus.giantswarm.io/extra-env=true.
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// ExtraEnvFrom populates environment variables of the Klaus container
+	// from Secrets or ConfigMaps in the namespace the pod runs in. Every
+	// source needs a prefix, and prefixes that could produce a name starting
+	// with KLAUS_ or CLAUDE_ are rejected.
+	// +optional
+	ExtraEnvFrom []corev1.EnvFromSource `json:"extraEnvFrom,omitempty"`
+
+	// Network routes the egress of the instance through an HTTP proxy and
+	// trusts a custom CA bundle, in the Klaus container and the workspace
+	// init containers.
+	// +optional
+	Network *NetworkConfig `json:"network,omitempty"`
+
 	// Telemetry configures OpenTelemetry and Prometheus metrics.
 	// +optional
 	Telemetry *TelemetryConfig `json:"telemetry,omitempty"`
@@ -88,14 +203,133 @@ type KlausInstanceSpec struct {
 	// +optional
 	Muster *MusterConfig `json:"muster,omitempty"`
 
-	// Stopped indicates that the instance should be scaled to zero replicas.
-	// When true, the controller sets the Deployment replicas to 0 and the
-	// instance status transitions to Stopped. Setting this back to false
-	// causes the controller to scale the Deployment back to 1 replica.
+	// Gateway selects how the instance is registered with an MCP gateway.
+	// +optional
+	Gateway *GatewayConfig `json:"gateway,omitempty"`
+
+	// Expose makes the instance reachable from outside the cluster through
+	// an Ingress or a Gateway API HTTPRoute in the user namespace.
+	// +optional
+	Expose *ExposeConfig `json:"expose,omitempty"`
+
+	// TLS serves the instance Service over mutual TLS. A per-instance
+	// serving certificate is mounted into the pod and muster is registered
+	// with a client certificate from the same issuer.
+	// +optional
+	TLS *InstanceTLSConfig `json:"tls,omitempty"`
+
+	// Scheduling controls where the instance pod runs and protects it from
+	// voluntary disruptions such as node drains.
+	// +optional
+	Scheduling *SchedulingConfig `json:"scheduling,omitempty"`
+
+	// Probes tunes the liveness, readiness and startup probes of the Klaus
+	// container, e.g. a longer startup for toolchain images loading many
+	// plugins.
+	// +optional
+	Probes *ProbesConfig `json:"probes,omitempty"`
+
+	// UpdateStrategy replaces the instance pod by stopping the old pod
+	// first (Recreate) or starting the new pod first (RollingUpdate).
+	// Defaults to Recreate when the workspace PVC is ReadWriteOnce or
+	// ReadWriteOncePod, whose volume the new pod could not mount while the
+	// old pod holds it, and to RollingUpdate otherwise.
+	// +optional
+	UpdateStrategy UpdateStrategyType `json:"updateStrategy,omitempty"`
+
+	// Shutdown configures how the instance pod stops on rollouts and
+	// deletion, so persistent agents can finish their current turn.
+	// +optional
+	Shutdown *ShutdownConfig `json:"shutdown,omitempty"`
+
+	// Sandbox selects a hardening preset for the instance pod. Each preset
+	// bundles a RuntimeClass, seccomp profile, root filesystem and network
+	// restrictions as configured on the operator. Defaults to none.
+	// +optional
+	Sandbox SandboxProfile `json:"sandbox,omitempty"`
+
+	// KubernetesAccess gives the agent access to the Kubernetes API as the
+	// instance ServiceAccount. Without it no ServiceAccount token is mounted
+	// into the pod.
+	// +optional
+	KubernetesAccess *KubernetesAccess `json:"kubernetesAccess,omitempty"`
+
+	// MockMode runs the instance against a mock agent instead of Claude, for
+	// developing personalities without spending Anthropic credits. No API key
+	// or provider credentials are required, the operator's mock agent image
+	// replaces the instance, personality and toolchain images, and the
+	// instance is marked as non-production in its status and muster
+	// registration.
+	// +optional
+	MockMode bool `json:"mockMode,omitempty"`
+
+	// DependsOn lists KlausInstances of the same owner this instance works
+	// with, e.g. a researcher feeding an implementer. The instance does not
+	// start until every dependency is Running. Each dependency's endpoint is
+	// injected as a KLAUS_DEPENDENCY_<NAME>_URL environment variable and as an
+	// MCP server named after the dependency.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	DependsOn []InstanceDependency `json:"dependsOn,omitempty"`
+
+	// ReadinessGates are checks on external dependencies, such as a
+	// database an MCP server fronts, the instance is not Ready without.
+	// Once its pod is available the controller evaluates every gate on each
+	// reconcile; the instance stays Pending with Ready False until all
+	// pass, and the result of each gate is reported in status.readinessGates.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=16
+	ReadinessGates []ReadinessGate `json:"readinessGates,omitempty"`
+
+	// DesiredState is the lifecycle state the instance should be in. With
+	// Stopped the controller scales the Deployment to zero replicas and the
+	// instance status transitions to Stopped, keeping its PVCs and
+	// configuration; with Running, the default, it scales the Deployment
+	// back to 1 replica.
+	// +optional
+	DesiredState DesiredState `json:"desiredState,omitempty"`
+
+	// Stopped stops the instance like desiredState Stopped.
+	//
+	// Deprecated: set desiredState instead. The instance is stopped when
+	// either field stops it.
 	// +optional
 	Stopped bool `json:"stopped"`
 }
 
+// PersonalityUpdatePolicy controls when an instance rolls forward to a new
+// personality revision.
+// +kubebuilder:validation:Enum=Auto;Manual
+type PersonalityUpdatePolicy string
+
+const (
+	// PersonalityUpdatePolicyAuto follows the personality reference.
+	PersonalityUpdatePolicyAuto PersonalityUpdatePolicy = "Auto"
+
+	// PersonalityUpdatePolicyManual pins the resolved personality revision.
+	PersonalityUpdatePolicyManual PersonalityUpdatePolicy = "Manual"
+)
+
+// RefreshPolicy controls when the digests of tag-based personality and
+// plugin references are re-resolved.
+// +kubebuilder:validation:Enum=Never;Periodic;OnSpecChange
+type RefreshPolicy string
+
+const (
+	// RefreshPolicyNever keeps the recorded digest of a tag.
+	RefreshPolicyNever RefreshPolicy = "Never"
+
+	// RefreshPolicyPeriodic re-resolves tags periodically and on spec
+	// changes.
+	RefreshPolicyPeriodic RefreshPolicy = "Periodic"
+
+	// RefreshPolicyOnSpecChange re-resolves tags on spec changes.
+	RefreshPolicyOnSpecChange RefreshPolicy = "OnSpecChange"
+)
+
 // PermissionMode controls how tool permissions are handled.
 // +kubebuilder:validation:Enum=bypassPermissions;default
 type PermissionMode string
@@ -129,11 +363,19 @@ type ClaudeConfig struct {
 	// +optional
 	MaxTurns *int `json:"maxTurns,omitempty"`
 
-	// PermissionMode controls tool permission handling.
-	// +kubebuilder:default=bypassPermissions
+	// PermissionMode controls tool permission handling. When unset, the
+	// operator's permission policy picks the default for the creator's
+	// groups; objects created before the policy existed keep the
+	// bypassPermissions value the CRD used to default to.
 	// +optional
 	PermissionMode PermissionMode `json:"permissionMode,omitempty"`
 
+	// PermissionModeOverride lets PermissionMode exceed the most permissive
+	// mode the permission policy allows the creator's groups. Only members
+	// of the policy's admin groups may set it.
+	// +optional
+	PermissionModeOverride bool `json:"permissionModeOverride,omitempty"`
+
 	// SystemPrompt overrides the default system prompt.
 	// +optional
 	SystemPrompt string `json:"systemPrompt,omitempty"`
@@ -163,7 +405,10 @@ type ClaudeConfig struct {
 	// +optional
 	StrictMCPConfig *bool `json:"strictMcpConfig,omitempty"`
 
-	// MaxBudgetUSD sets the maximum spend per session in USD.
+	// MaxBudgetUSD sets the maximum spend per session in USD. The operator
+	// also suspends the instance once status.tokenUsage.costUSD, its spend
+	// since creation, reaches it.
+	// +kubebuilder:validation:Minimum=0
 	// +optional
 	MaxBudgetUSD *float64 `json:"maxBudgetUSD,omitempty"`
 
@@ -179,10 +424,22 @@ type ClaudeConfig struct {
 	// +optional
 	JSONSchema string `json:"jsonSchema,omitempty"`
 
-	// SettingsFile path to a custom settings file. Mutually exclusive with Hooks.
+	// SettingsFile path to a custom settings file. Mutually exclusive with
+	// Hooks, OutputStyle and StatusLine.
 	// +optional
 	SettingsFile string `json:"settingsFile,omitempty"`
 
+	// OutputStyle selects the output style of the agent, a built-in style
+	// like Explanatory or the name of a custom one. Rendered to
+	// settings.json.
+	// +optional
+	OutputStyle string `json:"outputStyle,omitempty"`
+
+	// StatusLine configures the status line of the agent. Rendered to
+	// settings.json.
+	// +optional
+	StatusLine *StatusLineConfig `json:"statusLine,omitempty"`
+
 	// SettingSources controls which settings sources are loaded.
 	// +optional
 	SettingSources string `json:"settingSources,omitempty"`
@@ -217,6 +474,98 @@ type ClaudeConfig struct {
 	// IncludePartialMessages enables streaming partial messages.
 	// +optional
 	IncludePartialMessages *bool `json:"includePartialMessages,omitempty"`
+
+	// Provider selects the model provider. Defaults to the Anthropic API.
+	// +optional
+	Provider *ProviderConfig `json:"provider,omitempty"`
+}
+
+// ProviderType identifies the model provider backing the agent.
+// +kubebuilder:validation:Enum=anthropic;bedrock;vertex
+type ProviderType string
+
+const (
+	// ProviderAnthropic sends traffic directly to the Anthropic API.
+	ProviderAnthropic ProviderType = "anthropic"
+
+	// ProviderBedrock sends traffic to Amazon Bedrock.
+	ProviderBedrock ProviderType = "bedrock"
+
+	// ProviderVertex sends traffic to Google Vertex AI.
+	ProviderVertex ProviderType = "vertex"
+)
+
+// ProviderConfig configures the model provider for the instance.
+// +kubebuilder:validation:XValidation:rule="!has(self.bedrock) || (has(self.type) && self.type == 'bedrock')",message="bedrock requires provider type bedrock"
+// +kubebuilder:validation:XValidation:rule="!has(self.vertex) || (has(self.type) && self.type == 'vertex')",message="vertex requires provider type vertex"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'bedrock' || has(self.bedrock)",message="bedrock is required when provider type is bedrock"
+// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type != 'vertex' || has(self.vertex)",message="vertex is required when provider type is vertex"
+type ProviderConfig struct {
+	// Type is the provider type.
+	// +kubebuilder:default=anthropic
+	// +optional
+	Type ProviderType `json:"type,omitempty"`
+
+	// Bedrock configures Amazon Bedrock. Required when type is "bedrock".
+	// +optional
+	Bedrock *BedrockConfig `json:"bedrock,omitempty"`
+
+	// Vertex configures Google Vertex AI. Required when type is "vertex".
+	// +optional
+	Vertex *VertexConfig `json:"vertex,omitempty"`
+}
+
+// BedrockConfig configures Amazon Bedrock access.
+type BedrockConfig struct {
+	// Region is the AWS region hosting the Bedrock models (e.g. "eu-central-1").
+	Region string `json:"region"`
+
+	// RoleARN is an IAM role assumed via IRSA. When set, the instance
+	// ServiceAccount is annotated with eks.amazonaws.com/role-arn.
+	// +optional
+	RoleARN string `json:"roleARN,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the operator namespace with
+	// static AWS credentials (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+	// optionally AWS_SESSION_TOKEN). The Secret is copied to the user namespace
+	// and exposed to the agent as environment variables.
+	// +optional
+	CredentialsSecretRef *ProviderSecretReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// VertexConfig configures Google Vertex AI access.
+type VertexConfig struct {
+	// ProjectID is the GCP project hosting the Vertex AI models.
+	ProjectID string `json:"projectID"`
+
+	// Region is the Vertex AI region (e.g. "us-east5").
+	Region string `json:"region"`
+
+	// ServiceAccount is a GCP service account used via GKE Workload Identity.
+	// When set, the instance ServiceAccount is annotated with
+	// iam.gke.io/gcp-service-account.
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
+	// CredentialsSecretRef references a Secret in the operator namespace
+	// containing a service account key file. The Secret is copied to the user
+	// namespace, mounted into the pod and referenced via
+	// GOOGLE_APPLICATION_CREDENTIALS.
+	// +optional
+	CredentialsSecretRef *ProviderSecretReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// ProviderSecretReference references a Kubernetes Secret holding model
+// provider credentials.
+type ProviderSecretReference struct {
+	// Name is the name of the Kubernetes Secret in the operator namespace.
+	Name string `json:"name"`
+
+	// Key is the key in the Secret data holding the credentials file. Only
+	// used for Vertex AI. Defaults to "credentials.json".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // MCPServerSecret defines a Kubernetes Secret reference for MCP server credential injection.
@@ -228,6 +577,75 @@ type MCPServerSecret struct {
 	Env map[string]string `json:"env"`
 }
 
+// InlinePersonality is a personality defined on the instance itself. It
+// mirrors the contents of a personality artifact. Instance-level settings
+// take precedence over the personality ones.
+type InlinePersonality struct {
+	// Description is a human-readable description of the personality.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Soul is the content of the SOUL.md file describing the agent's
+	// identity and behaviour.
+	// +optional
+	Soul string `json:"soul,omitempty"`
+
+	// Image is the toolchain container image, used when spec.image is not set.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Plugins are added to spec.plugins. A plugin in spec.plugins with the
+	// same repository takes precedence.
+	// +optional
+	Plugins []PluginReference `json:"plugins,omitempty"`
+
+	// Skills are added to spec.skills. A skill in spec.skills with the same
+	// name takes precedence.
+	// +optional
+	Skills map[string]SkillConfig `json:"skills,omitempty"`
+
+	// Commands are added to spec.commands. A command in spec.commands with
+	// the same name takes precedence.
+	// +optional
+	Commands map[string]CommandConfig `json:"commands,omitempty"`
+
+	// SkillPacks are added to spec.skillPacks after the packs referenced by
+	// the instance.
+	// +optional
+	SkillPacks []SkillPackReference `json:"skillPacks,omitempty"`
+
+	// Memory is merged into spec.memory: its content is placed before that
+	// of spec.memory, and a file in spec.memory.files with the same path
+	// takes precedence.
+	// +optional
+	Memory *MemoryConfig `json:"memory,omitempty"`
+
+	// TemplateValues are the defaults of spec.templateValues. A value in
+	// spec.templateValues with the same name takes precedence.
+	// +optional
+	TemplateValues map[string]string `json:"templateValues,omitempty"`
+
+	// Probes apply to the probes not tuned by spec.probes.
+	// +optional
+	Probes *ProbesConfig `json:"probes,omitempty"`
+
+	// ExtraEnv is added to spec.extraEnv. A variable in spec.extraEnv with
+	// the same name takes precedence.
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// ExtraEnvFrom is added before spec.extraEnvFrom, whose sources take
+	// precedence for keys in both.
+	// +optional
+	ExtraEnvFrom []corev1.EnvFromSource `json:"extraEnvFrom,omitempty"`
+
+	// Scheduling is merged into spec.scheduling: node selector labels and
+	// tolerations are added, and the other fields apply when
+	// spec.scheduling does not set them.
+	// +optional
+	Scheduling *SchedulingConfig `json:"scheduling,omitempty"`
+}
+
 // PluginReference defines an OCI image reference for a Klaus plugin.
 // +kubebuilder:validation:XValidation:rule="!(has(self.tag) && has(self.digest))",message="tag and digest are mutually exclusive"
 // +kubebuilder:validation:XValidation:rule="has(self.tag) || has(self.digest)",message="must specify either tag or digest"
@@ -244,13 +662,162 @@ type PluginReference struct {
 	Digest string `json:"digest,omitempty"`
 }
 
+// InstanceDependency references another KlausInstance in the same namespace.
+type InstanceDependency struct {
+	// Name is the name of the KlausInstance.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ReadinessGate is a check the instance is not Ready without. Exactly one of
+// http, object and exec is set.
+// +kubebuilder:validation:XValidation:rule="[has(self.http), has(self.object), has(self.exec)].filter(x, x).size() == 1",message="exactly one of http, object and exec must be set"
+type ReadinessGate struct {
+	// Name identifies the gate in status.readinessGates.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// HTTP passes when a GET request from the klaus container returns a
+	// 2xx status.
+	// +optional
+	HTTP *HTTPReadinessCheck `json:"http,omitempty"`
+
+	// Object passes when a Kubernetes object in the namespace of the
+	// instance's pod reports a condition.
+	// +optional
+	Object *ObjectReadinessCheck `json:"object,omitempty"`
+
+	// Exec passes when a command run in the klaus container exits with
+	// status zero.
+	// +optional
+	Exec *ExecReadinessCheck `json:"exec,omitempty"`
+}
+
+// HTTPReadinessCheck is an HTTP readiness gate.
+type HTTPReadinessCheck struct {
+	// URL is the http or https URL requested.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+}
+
+// ObjectReadinessCheck is a Kubernetes object condition readiness gate.
+// The operator must be allowed to get the object.
+type ObjectReadinessCheck struct {
+	// APIVersion is the API version of the object, e.g.
+	// "postgresql.cnpg.io/v1".
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind is the kind of the object.
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Name is the name of the object.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of a namespaced object. Objects can only be
+	// checked in the namespace of the instance's pod, the default.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Condition is the condition type that must be True.
+	// +kubebuilder:default=Ready
+	// +optional
+	Condition string `json:"condition,omitempty"`
+}
+
+// ExecReadinessCheck is a command readiness gate.
+type ExecReadinessCheck struct {
+	// Command is run in the klaus container of the instance pod, without a
+	// shell.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+}
+
 // MCPServerReference references a KlausMCPServer CRD by name.
 // Merge semantics: if a referenced KlausMCPServer has the same name as an
 // inline entry in claude.mcpServers, the resolved KlausMCPServer config takes
 // precedence. An informational event is emitted when this override occurs.
+// Headers, Env and TimeoutMs override the KlausMCPServer config for this
+// instance only, e.g. to scope a shared server to a different organization.
 type MCPServerReference struct {
 	// Name is the name of the KlausMCPServer resource.
 	Name string `json:"name"`
+
+	// Namespace is the namespace of the KlausMCPServer, e.g. a central
+	// catalog of shared servers. Defaults to the namespace of the instance;
+	// other namespaces must be allowed by the operator's
+	// --mcp-server-namespaces.
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Headers are merged over the headers of the KlausMCPServer; an entry
+	// with the same name replaces the shared value. Values support ${VAR}
+	// expansion from the secretRefs of the KlausMCPServer.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Env is merged over the environment of the KlausMCPServer like
+	// Headers.
+	// +optional
+	Env map[string]string `json:"env,omitempty"`
+
+	// TimeoutMs is the timeout of the server's requests in milliseconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutMs *int64 `json:"timeoutMs,omitempty"`
+}
+
+// SkillPackReference references a KlausSkillPack by name.
+type SkillPackReference struct {
+	// Name is the name of the KlausSkillPack resource.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// MemoryConfig defines the CLAUDE.md memory files of an instance.
+type MemoryConfig struct {
+	// Content is rendered after the soul of the personality as the CLAUDE.md
+	// of the extensions directory, loaded as memory by every session.
+	// +optional
+	Content string `json:"content,omitempty"`
+
+	// Files maps paths of CLAUDE.md files relative to the workspace root,
+	// e.g. CLAUDE.md or services/api/CLAUDE.md, to their content. Requires
+	// spec.workspace.
+	// +optional
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// StatusLineType is the kind of a status line.
+// +kubebuilder:validation:Enum=command
+type StatusLineType string
+
+const (
+	// StatusLineTypeCommand renders the output of a command as the status
+	// line.
+	StatusLineTypeCommand StatusLineType = "command"
+)
+
+// StatusLineConfig mirrors the statusLine key of the Claude Code
+// settings.json.
+type StatusLineConfig struct {
+	// Type is the kind of status line.
+	// +kubebuilder:default=command
+	// +optional
+	Type StatusLineType `json:"type,omitempty"`
+
+	// Command is run to render the status line, e.g. a hook script.
+	// +kubebuilder:validation:MinLength=1
+	Command string `json:"command"`
+
+	// Padding is the horizontal padding of the status line in characters.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Padding *int `json:"padding,omitempty"`
 }
 
 // SkillConfig defines an inline skill rendered as SKILL.md with YAML frontmatter.
@@ -297,7 +864,54 @@ type AgentFileConfig struct {
 	Content string `json:"content"`
 }
 
+// CommandConfig defines an inline slash command rendered as a command file
+// with YAML frontmatter, invoked as /<name>.
+type CommandConfig struct {
+	// Description is the command description shown in the command list.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Content is the prompt of the command. $ARGUMENTS is replaced with the
+	// arguments of the invocation.
+	Content string `json:"content"`
+
+	// AllowedTools restricts which tools the command can use.
+	// +optional
+	AllowedTools []string `json:"allowedTools,omitempty"`
+
+	// ArgumentHint describes the arguments the command expects.
+	// +optional
+	ArgumentHint string `json:"argumentHint,omitempty"`
+
+	// Model overrides the model for this command.
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// DisableModelInvocation prevents the model from invoking this command.
+	// +optional
+	DisableModelInvocation *bool `json:"disableModelInvocation,omitempty"`
+}
+
+// CloneSource identifies the instance an instance was cloned from.
+type CloneSource struct {
+	// Name of the source KlausInstance in the namespace of the clone.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Workspace provisions the workspace PVC of the clone as a copy of the
+	// workspace PVC of the source, using CSI volume cloning. The source must
+	// have the same owner, and must exist when the PVC is created; an
+	// existing PVC is never replaced.
+	// +optional
+	Workspace bool `json:"workspace,omitempty"`
+}
+
 // WorkspaceConfig configures persistent storage for the instance.
+// +kubebuilder:validation:XValidation:rule="!(has(self.gitSecretRef) && has(self.gitHubApp))",message="gitSecretRef and gitHubApp are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.gitRepo) && has(self.repos))",message="gitRepo and repos are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.gitSecretRef) || has(self.gitRepo)",message="gitSecretRef requires gitRepo to be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.gitHubApp) || (has(self.gitRepo) && self.gitRepo.startsWith('https://'))",message="gitHubApp requires gitRepo to be set to an https URL"
+// +kubebuilder:validation:XValidation:rule="has(self.accessModes) == has(oldSelf.accessModes) && (!has(self.accessModes) || self.accessModes == oldSelf.accessModes)",message="accessModes is immutable"
 type WorkspaceConfig struct {
 	// StorageClass is the storage class for the PVC.
 	// +optional
@@ -308,8 +922,19 @@ type WorkspaceConfig struct {
 	// +optional
 	Size *resource.Quantity `json:"size,omitempty"`
 
+	// AccessModes are the access modes of the PVC. Defaults to
+	// ReadWriteOnce. A ReadWriteMany volume lets the new pod of a rolling
+	// update mount the workspace while the old pod still runs. They cannot
+	// be set, changed or unset once the workspace exists, as the PVC keeps
+	// its access modes.
+	// +kubebuilder:validation:items:Enum=ReadWriteOnce;ReadOnlyMany;ReadWriteMany;ReadWriteOncePod
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
 	// GitRepo is a git repository URL to clone into the workspace.
 	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9@._:/%+~-]+$`
+	// +kubebuilder:validation:MaxLength=2048
+	// +kubebuilder:validation:XValidation:rule="self.matches('^(https?|ssh|git|file)://') || self.matches('^[a-zA-Z0-9._-]+@[a-zA-Z0-9.-]+:')",message="must be an http(s), ssh, git or file URL, or an scp-like address such as git@github.com:org/repo.git"
 	// +optional
 	GitRepo string `json:"gitRepo,omitempty"`
 
@@ -318,63 +943,258 @@ type WorkspaceConfig struct {
 	// +optional
 	GitRef string `json:"gitRef,omitempty"`
 
+	// GitCloneOptions tune the clone of gitRepo.
+	GitCloneOptions `json:",inline"`
+
 	// GitSecretRef references a Secret containing an HTTPS access token for cloning
 	// private repositories. The operator copies the Secret to the user namespace and
 	// configures the git-clone init container to authenticate using the token.
 	// The repository URL must use HTTPS (e.g., https://github.com/org/repo.git).
 	// +optional
 	GitSecretRef *GitSecretReference `json:"gitSecretRef,omitempty"`
-}
-
-// GitSecretReference references a Kubernetes Secret containing a git access
-// token (PAT or fine-grained token) for cloning private repositories over HTTPS.
-type GitSecretReference struct {
-	// Name is the name of the Kubernetes Secret in the operator namespace.
-	Name string `json:"name"`
 
-	// Key is the key in the Secret data containing the access token. Defaults to "token".
-	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// GitHubApp authenticates with short-lived installation tokens of the
+	// GitHub App configured on the operator instead of a static token. The
+	// operator mints a token scoped to the workspace repository, writes it to
+	// the git credential Secret in the user namespace and refreshes it before
+	// it expires. Mutually exclusive with gitSecretRef.
 	// +optional
-	Key string `json:"key,omitempty"`
-}
+	GitHubApp *GitHubAppCredentials `json:"gitHubApp,omitempty"`
 
-// TelemetryConfig configures OpenTelemetry and metrics for the instance.
-type TelemetryConfig struct {
-	// Enabled enables telemetry collection.
+	// Repos are git repositories cloned side by side into the workspace, for
+	// instances working across several repositories. Each checkout is added
+	// to the agent's additional directories. Mutually exclusive with gitRepo.
+	// +kubebuilder:validation:MaxItems=20
 	// +optional
-	Enabled *bool `json:"enabled,omitempty"`
+	Repos []WorkspaceRepository `json:"repos,omitempty"`
 
-	// MetricsExporter specifies the metrics exporter (e.g. "otlp").
+	// SetupCommands are shell commands preparing the workspace before the
+	// agent starts, e.g. go mod download or npm ci. They run in order in
+	// /workspace, after the clone, in an init container using the instance
+	// image; the pod does not start when one fails.
+	// +kubebuilder:validation:MaxItems=20
+	// +kubebuilder:validation:items:MinLength=1
 	// +optional
-	MetricsExporter string `json:"metricsExporter,omitempty"`
+	SetupCommands []string `json:"setupCommands,omitempty"`
 
-	// LogsExporter specifies the logs exporter (e.g. "otlp").
+	// SetupTimeout bounds the time all setupCommands may take together.
+	// Defaults to 10m; at most 1h.
 	// +optional
-	LogsExporter string `json:"logsExporter,omitempty"`
+	SetupTimeout *metav1.Duration `json:"setupTimeout,omitempty"`
 
-	// OTLP contains OTLP exporter configuration.
+	// Output commits the changes the agent made to the gitRepo checkout and
+	// pushes them to a branch when a KlausJob run succeeds, optionally
+	// opening a GitHub pull request. Requires gitSecretRef or gitHubApp
+	// credentials allowed to push. Only supported by KlausJobs.
 	// +optional
-	OTLP *OTLPConfig `json:"otlp,omitempty"`
+	Output *WorkspaceOutput `json:"output,omitempty"`
+}
 
-	// MetricExportIntervalMs sets the metric export interval in milliseconds.
+// WorkspaceOutput configures pushing the workspace changes of a finished
+// KlausJob run. PushBranch and PRTitleTemplate are Go templates with the
+// fields .Name, .Namespace and .Owner of the KlausJob; the title template
+// also gets the rendered .Branch.
+type WorkspaceOutput struct {
+	// PushBranch is the branch the changes are committed and pushed to,
+	// e.g. klaus/{{.Name}}. An existing branch is only fast-forwarded.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=255
+	PushBranch string `json:"pushBranch"`
+
+	// CreatePR opens a pull request from pushBranch into the branch the
+	// workspace was cloned from. Requires a GitHub repository.
 	// +optional
-	MetricExportIntervalMs *int `json:"metricExportIntervalMs,omitempty"`
+	CreatePR bool `json:"createPR,omitempty"`
 
-	// LogsExportIntervalMs sets the logs export interval in milliseconds.
+	// PRTitleTemplate is the pull request title, also used as the commit
+	// message. Defaults to "klaus: {{.Name}}".
+	// +kubebuilder:validation:MaxLength=1024
 	// +optional
-	LogsExportIntervalMs *int `json:"logsExportIntervalMs,omitempty"`
+	PRTitleTemplate string `json:"prTitleTemplate,omitempty"`
+}
 
-	// LogUserPrompts enables logging of user prompts.
+// WorkspaceRepository is a git repository cloned into a directory of the
+// workspace.
+type WorkspaceRepository struct {
+	// Repo is the git repository URL.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9@._:/%+~-]+$`
+	// +kubebuilder:validation:MaxLength=2048
+	// +kubebuilder:validation:XValidation:rule="self.matches('^(https?|ssh|git|file)://') || self.matches('^[a-zA-Z0-9._-]+@[a-zA-Z0-9.-]+:')",message="must be an http(s), ssh, git or file URL, or an scp-like address such as git@github.com:org/repo.git"
+	Repo string `json:"repo"`
+
+	// Ref is the git ref to checkout.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._/^~-]+$`
 	// +optional
-	LogUserPrompts *bool `json:"logUserPrompts,omitempty"`
+	Ref string `json:"ref,omitempty"`
 
-	// LogToolDetails enables logging of tool details.
+	// Path is the checkout directory relative to the workspace root.
+	// Defaults to the repository name.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-][a-zA-Z0-9._-]*(/[a-zA-Z0-9_-][a-zA-Z0-9._-]*)*$`
 	// +optional
-	LogToolDetails *bool `json:"logToolDetails,omitempty"`
+	Path string `json:"path,omitempty"`
 
-	// IncludeSessionID includes session ID in telemetry.
+	// SecretRef references a Secret containing an HTTPS access token for
+	// cloning the repository, like gitSecretRef.
 	// +optional
-	IncludeSessionID *bool `json:"includeSessionId,omitempty"`
+	SecretRef *GitSecretReference `json:"secretRef,omitempty"`
+
+	// GitCloneOptions tune the clone of the repository.
+	GitCloneOptions `json:",inline"`
+}
+
+// GitCloneOptions tune how the git-clone init container clones a workspace
+// repository, e.g. to keep large monorepos from filling the workspace.
+type GitCloneOptions struct {
+	// Depth creates a shallow clone with history truncated to the given
+	// number of commits. Updates of an existing checkout fetch only the new
+	// commits.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Depth *int32 `json:"depth,omitempty"`
+
+	// SparseCheckoutPaths checks out only the given directories, relative to
+	// the repository root, using a blobless partial clone. Top-level files
+	// are always checked out.
+	// +kubebuilder:validation:MaxItems=100
+	// +kubebuilder:validation:items:Pattern=`^[a-zA-Z0-9_-][a-zA-Z0-9._-]*(/[a-zA-Z0-9_-][a-zA-Z0-9._-]*)*$`
+	// +optional
+	SparseCheckoutPaths []string `json:"sparseCheckoutPaths,omitempty"`
+
+	// LFS downloads Git LFS objects after the checkout. The git clone image
+	// must contain git-lfs; LFS pointer files are checked out otherwise.
+	// +optional
+	LFS bool `json:"lfs,omitempty"`
+
+	// Submodules initializes and updates submodules recursively, shallow
+	// when depth is set.
+	// +optional
+	Submodules bool `json:"submodules,omitempty"`
+}
+
+// GitHubAppCredentials configures workspace git authentication with
+// installation tokens of the operator's GitHub App.
+type GitHubAppCredentials struct {
+	// InstallationID is the app installation to mint tokens from. Defaults
+	// to the installation on the account owning the workspace repository.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	InstallationID int64 `json:"installationID,omitempty"`
+}
+
+// NetworkConfig configures the egress proxy and trusted certificate
+// authorities of an instance.
+type NetworkConfig struct {
+	// HTTPProxy is the proxy URL for HTTP requests, set as HTTP_PROXY and
+	// http_proxy. It must not embed credentials: set
+	// proxyCredentialsSecretRef instead.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the proxy URL for HTTPS requests, set as HTTPS_PROXY
+	// and https_proxy.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// ProxyCredentialsSecretRef references a Secret in the namespace of the
+	// instance holding the proxy username and password. The operator
+	// copies it to the user namespace and adds the credentials to both
+	// proxy URLs from the pod environment, so they never appear in the
+	// spec. The values must be URL-encoded.
+	// +optional
+	ProxyCredentialsSecretRef *ProxyCredentialsReference `json:"proxyCredentialsSecretRef,omitempty"`
+
+	// NoProxy is a comma-separated list of hosts, domains and CIDRs reached
+	// without the proxy, set as NO_PROXY and no_proxy. Loopback and
+	// in-cluster Service addresses are always added.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+
+	// CABundleConfigMapRef references a ConfigMap in the operator namespace
+	// holding a PEM CA bundle. The operator copies it to the user namespace
+	// and mounts it into the Klaus and workspace init containers.
+	// +optional
+	CABundleConfigMapRef *CABundleReference `json:"caBundleConfigMapRef,omitempty"`
+}
+
+// ProxyCredentialsReference references the proxy credentials in a Secret.
+type ProxyCredentialsReference struct {
+	// Name is the name of the Secret.
+	Name string `json:"name"`
+
+	// UsernameKey is the key in the Secret data holding the username.
+	// Defaults to "username".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// +optional
+	UsernameKey string `json:"usernameKey,omitempty"`
+
+	// PasswordKey is the key in the Secret data holding the password.
+	// Defaults to "password".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// +optional
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+// CABundleReference references a CA bundle in a ConfigMap.
+type CABundleReference struct {
+	// Name is the name of the ConfigMap in the operator namespace.
+	Name string `json:"name"`
+
+	// Key is the key in the ConfigMap data holding the PEM bundle. Defaults
+	// to "ca.crt".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// GitSecretReference references a Kubernetes Secret containing a git access
+// token (PAT or fine-grained token) for cloning private repositories over HTTPS.
+type GitSecretReference struct {
+	// Name is the name of the Kubernetes Secret in the operator namespace.
+	Name string `json:"name"`
+
+	// Key is the key in the Secret data containing the access token. Defaults to "token".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TelemetryConfig configures OpenTelemetry and metrics for the instance.
+type TelemetryConfig struct {
+	// Enabled enables telemetry collection.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MetricsExporter specifies the metrics exporter (e.g. "otlp").
+	// +optional
+	MetricsExporter string `json:"metricsExporter,omitempty"`
+
+	// LogsExporter specifies the logs exporter (e.g. "otlp").
+	// +optional
+	LogsExporter string `json:"logsExporter,omitempty"`
+
+	// OTLP contains OTLP exporter configuration.
+	// +optional
+	OTLP *OTLPConfig `json:"otlp,omitempty"`
+
+	// MetricExportIntervalMs sets the metric export interval in milliseconds.
+	// +optional
+	MetricExportIntervalMs *int `json:"metricExportIntervalMs,omitempty"`
+
+	// LogsExportIntervalMs sets the logs export interval in milliseconds.
+	// +optional
+	LogsExportIntervalMs *int `json:"logsExportIntervalMs,omitempty"`
+
+	// LogUserPrompts enables logging of user prompts.
+	// +optional
+	LogUserPrompts *bool `json:"logUserPrompts,omitempty"`
+
+	// LogToolDetails enables logging of tool details.
+	// +optional
+	LogToolDetails *bool `json:"logToolDetails,omitempty"`
+
+	// IncludeSessionID includes session ID in telemetry.
+	// +optional
+	IncludeSessionID *bool `json:"includeSessionId,omitempty"`
 
 	// IncludeVersion includes version in telemetry.
 	// +optional
@@ -416,8 +1236,323 @@ type MusterConfig struct {
 	ToolPrefix string `json:"toolPrefix,omitempty"`
 }
 
+// GatewayType selects the backend registering an instance with an MCP
+// gateway.
+// +kubebuilder:validation:Enum=muster;service;none
+type GatewayType string
+
+const (
+	// GatewayTypeMuster registers the instance with a muster MCPServer.
+	GatewayTypeMuster GatewayType = "muster"
+
+	// GatewayTypeService annotates the instance Service with its MCP
+	// endpoint for gateways discovering servers by annotation.
+	GatewayTypeService GatewayType = "service"
+
+	// GatewayTypeNone does not register the instance.
+	GatewayTypeNone GatewayType = "none"
+)
+
+// GatewayConfig configures the registration of an instance with an MCP
+// gateway.
+type GatewayConfig struct {
+	// Type is the registration backend. Defaults to the operator's
+	// --gateway flag.
+	// +optional
+	Type GatewayType `json:"type,omitempty"`
+}
+
+// ExposeType selects the resource used to expose an instance.
+// +kubebuilder:validation:Enum=Ingress;HTTPRoute
+type ExposeType string
+
+const (
+	// ExposeTypeIngress exposes the instance with a networking.k8s.io Ingress.
+	ExposeTypeIngress ExposeType = "Ingress"
+
+	// ExposeTypeHTTPRoute exposes the instance with a Gateway API HTTPRoute.
+	ExposeTypeHTTPRoute ExposeType = "HTTPRoute"
+)
+
+// ExposeConfig configures external access to an instance.
+// +kubebuilder:validation:XValidation:rule="has(self.type) && self.type == 'HTTPRoute' ? has(self.gateway) : !has(self.gateway)",message="gateway is required for, and only allowed with, expose type HTTPRoute"
+// +kubebuilder:validation:XValidation:rule="!(has(self.type) && self.type == 'HTTPRoute' && has(self.ingressClassName))",message="ingressClassName must not be set when expose type is HTTPRoute"
+type ExposeConfig struct {
+	// Type selects an Ingress or a Gateway API HTTPRoute.
+	// +kubebuilder:default=Ingress
+	// +optional
+	Type ExposeType `json:"type,omitempty"`
+
+	// Hostname is the external host name the instance is served on.
+	// +kubebuilder:validation:MinLength=1
+	Hostname string `json:"hostname"`
+
+	// Path is the URL path prefix routed to the instance.
+	// +kubebuilder:default="/"
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// TLSSecretName is the name of a TLS Secret in the user namespace used
+	// by the Ingress. For HTTPRoute, TLS is terminated by the Gateway
+	// listener and setting this only switches the reported endpoint to https.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// IngressClassName is the Ingress class. Defaults to the cluster default.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// Gateway is the Gateway the HTTPRoute attaches to. Required for
+	// type HTTPRoute.
+	// +optional
+	Gateway *GatewayReference `json:"gateway,omitempty"`
+
+	// Annotations are added to the generated Ingress or HTTPRoute, e.g. for
+	// cert-manager or external-dns.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GatewayReference identifies a Gateway API Gateway listener.
+type GatewayReference struct {
+	// Name of the Gateway.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the Gateway. Defaults to the user namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the listener name on the Gateway.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// TLSIssuer selects who issues the certificates of an instance.
+// +kubebuilder:validation:Enum=Operator;CertManager
+type TLSIssuer string
+
+const (
+	// TLSIssuerOperator issues certificates from the operator-managed CA.
+	TLSIssuerOperator TLSIssuer = "Operator"
+
+	// TLSIssuerCertManager requests certificates from a cert-manager
+	// ClusterIssuer.
+	TLSIssuerCertManager TLSIssuer = "CertManager"
+)
+
+// InstanceTLSConfig configures mTLS on the instance Service.
+// +kubebuilder:validation:XValidation:rule="(has(self.issuer) && self.issuer == 'CertManager') == (has(self.clusterIssuer) && size(self.clusterIssuer) > 0)",message="clusterIssuer is required for, and only allowed with, issuer CertManager"
+type InstanceTLSConfig struct {
+	// Issuer selects the operator-managed CA or cert-manager.
+	// +kubebuilder:default=Operator
+	// +optional
+	Issuer TLSIssuer `json:"issuer,omitempty"`
+
+	// ClusterIssuer is the cert-manager ClusterIssuer used when issuer is
+	// CertManager. A ClusterIssuer is required because the serving and
+	// client certificates live in different namespaces.
+	// +optional
+	ClusterIssuer string `json:"clusterIssuer,omitempty"`
+}
+
+// KubernetesAccess configures the Kubernetes API access of an instance.
+type KubernetesAccess struct {
+	// Enabled mounts a projected ServiceAccount token at the standard
+	// in-cluster location, so Kubernetes clients in the pod authenticate as
+	// the instance ServiceAccount.
+	Enabled bool `json:"enabled"`
+
+	// ClusterRole is granted to the instance ServiceAccount in the instance
+	// namespace by a RoleBinding. It must be one of the ClusterRoles platform
+	// admins allowed the operator to grant. Without it the token grants no
+	// access beyond what every authenticated ServiceAccount has.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	ClusterRole string `json:"clusterRole,omitempty"`
+
+	// Audience is the intended audience of the token. Defaults to the API
+	// server.
+	// +optional
+	Audience string `json:"audience,omitempty"`
+
+	// ExpirationSeconds is the requested lifetime of the token, which the
+	// kubelet rotates before it expires. Defaults to 3600.
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// SandboxProfile names a sandbox preset.
+// +kubebuilder:validation:Enum=none;gvisor;kata;strict
+type SandboxProfile string
+
+const (
+	// SandboxNone runs the pod with the default container runtime and the
+	// standard security context.
+	SandboxNone SandboxProfile = "none"
+
+	// SandboxGVisor runs the pod under the gVisor RuntimeClass.
+	SandboxGVisor SandboxProfile = "gvisor"
+
+	// SandboxKata runs the pod under the Kata Containers RuntimeClass.
+	SandboxKata SandboxProfile = "kata"
+
+	// SandboxStrict combines a sandboxing RuntimeClass with a read-only root
+	// filesystem and a restrictive NetworkPolicy.
+	SandboxStrict SandboxProfile = "strict"
+)
+
+// UpdateStrategyType selects how the instance Deployment replaces its pod.
+// +kubebuilder:validation:Enum=Recreate;RollingUpdate
+type UpdateStrategyType string
+
+const (
+	// UpdateStrategyRecreate stops the old pod before starting the new one.
+	UpdateStrategyRecreate UpdateStrategyType = "Recreate"
+
+	// UpdateStrategyRollingUpdate starts the new pod before stopping the old
+	// one.
+	UpdateStrategyRollingUpdate UpdateStrategyType = "RollingUpdate"
+)
+
+// ShutdownConfig configures the graceful shutdown of the instance pod.
+type ShutdownConfig struct {
+	// TerminationGracePeriodSeconds is how long the agent may take to stop
+	// after it was asked to before it is killed. Defaults to 300 for chat
+	// mode instances and to the Kubernetes default of 30 otherwise.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// Drain runs a preStop hook asking the agent to finish or checkpoint
+	// its current turn before the container receives SIGTERM. Defaults to
+	// true for chat mode instances whose agent reports the
+	// graceful-shutdown capability.
+	// +optional
+	Drain *bool `json:"drain,omitempty"`
+}
+
+// ProbesConfig tunes the probes of the Klaus container. Unset fields keep
+// the operator defaults.
+// +kubebuilder:validation:XValidation:rule="!has(self.liveness) || !has(self.liveness.successThreshold) || self.liveness.successThreshold == 1",message="liveness.successThreshold must be 1"
+// +kubebuilder:validation:XValidation:rule="!has(self.startup) || !has(self.startup.successThreshold) || self.startup.successThreshold == 1",message="startup.successThreshold must be 1"
+type ProbesConfig struct {
+	// Liveness restarts the container when it fails. Defaults to /healthz
+	// every 30 seconds.
+	// +optional
+	Liveness *ProbeConfig `json:"liveness,omitempty"`
+
+	// Readiness removes the pod from the Service while it fails. Defaults to
+	// /readyz every 10 seconds.
+	// +optional
+	Readiness *ProbeConfig `json:"readiness,omitempty"`
+
+	// Startup holds off the liveness and readiness probes until it
+	// succeeds. Defaults to /healthz every 5 seconds for up to 5 minutes.
+	// +optional
+	Startup *ProbeConfig `json:"startup,omitempty"`
+}
+
+// ProbeConfig tunes an HTTP probe of the Klaus container.
+type ProbeConfig struct {
+	// Path is the HTTP path probed on the Klaus port.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// InitialDelaySeconds is the delay after the container started before
+	// the first probe.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds is how often the probe runs.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds is how long a probe may take.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// SuccessThreshold is the number of consecutive successes after a
+	// failure for the probe to pass. Must be 1 for liveness and startup.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures for the probe
+	// to fail.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// SchedulingConfig configures pod placement and disruption protection.
+type SchedulingConfig struct {
+	// NodeSelector restricts the pod to nodes with these labels, e.g. a
+	// dedicated or GPU-adjacent agent node pool.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Affinity is the pod affinity. Node affinity terms are combined with
+	// the architecture requirement derived from the toolchain image.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Tolerations allow the pod to run on tainted nodes, e.g. a dedicated
+	// agent node pool.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// TopologySpreadConstraints spread agent pods across topology domains.
+	// Constraints without a labelSelector select all Klaus instance pods.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the PriorityClass of the instance pod.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// RuntimeClassName is the RuntimeClass the instance pod runs with, e.g.
+	// a gVisor or Kata Containers class that sandboxes the agent more
+	// strongly than the default container runtime. It is deprecated in
+	// favour of spec.sandbox and removed in v1alpha2.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// DisruptionBudget creates a PodDisruptionBudget for the instance pod
+	// while the instance is running.
+	// +optional
+	DisruptionBudget *DisruptionBudgetConfig `json:"disruptionBudget,omitempty"`
+}
+
+// DisruptionBudgetConfig configures the PodDisruptionBudget of an instance.
+// At most one of minAvailable and maxUnavailable may be set; with neither,
+// minAvailable defaults to 1, which blocks evictions of the single instance
+// pod until the instance is stopped.
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="minAvailable and maxUnavailable are mutually exclusive"
+type DisruptionBudgetConfig struct {
+	// MinAvailable is the number or percentage of pods that must stay
+	// available.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that may be
+	// unavailable.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // InstanceState represents the lifecycle state of a KlausInstance.
-// +kubebuilder:validation:Enum=Pending;Running;Error;Stopped
+// +kubebuilder:validation:Enum=Pending;Running;Error;Stopped;DryRun
 type InstanceState string
 
 const (
@@ -425,6 +1560,19 @@ const (
 	InstanceStateRunning InstanceState = "Running"
 	InstanceStateError   InstanceState = "Error"
 	InstanceStateStopped InstanceState = "Stopped"
+	// InstanceStateDryRun is the state of instances annotated with
+	// klaus.giantswarm.io/dry-run whose child resources were rendered
+	// without creating them.
+	InstanceStateDryRun InstanceState = "DryRun"
+)
+
+// DesiredState is the lifecycle state a KlausInstance should be in.
+// +kubebuilder:validation:Enum=Running;Stopped
+type DesiredState string
+
+const (
+	DesiredStateRunning DesiredState = "Running"
+	DesiredStateStopped DesiredState = "Stopped"
 )
 
 // InstanceMode represents the process mode of a KlausInstance.
@@ -447,30 +1595,83 @@ type KlausInstanceStatus struct {
 	// +optional
 	State InstanceState `json:"state,omitempty"`
 
-	// Endpoint is the internal service URL for the instance.
+	// Endpoint is the URL the instance is reached on: the external URL when
+	// spec.expose is set, otherwise the internal service URL.
 	// +optional
 	Endpoint string `json:"endpoint,omitempty"`
 
+	// ServiceEndpoint is the internal service URL for the instance.
+	// +optional
+	ServiceEndpoint string `json:"serviceEndpoint,omitempty"`
+
 	// Mode indicates the process mode (agent or chat).
 	// +optional
 	Mode InstanceMode `json:"mode,omitempty"`
 
+	// Mock is true when the instance runs the mock agent (spec.mockMode)
+	// and must not be used for production work.
+	// +optional
+	Mock bool `json:"mock,omitempty"`
+
 	// LastActivity is the timestamp of the last activity.
 	// +optional
 	LastActivity *metav1.Time `json:"lastActivity,omitempty"`
 
-	// Personality is the OCI reference of the resolved personality artifact.
+	// Personality is the OCI reference of the resolved personality artifact,
+	// or "inline" for an inline personality.
 	// +optional
 	Personality string `json:"personality,omitempty"`
 
+	// PersonalityRevision is the resolved personality reference the
+	// instance runs, e.g. the concrete version a latest tag resolved to.
+	// +optional
+	PersonalityRevision string `json:"personalityRevision,omitempty"`
+
+	// PersonalityRollout is the value of the
+	// klaus.giantswarm.io/personality-rollout annotation when
+	// PersonalityRevision was resolved.
+	// +optional
+	PersonalityRollout string `json:"personalityRollout,omitempty"`
+
+	// PersonalityPendingRevision is a newer personality revision the
+	// instance waits to roll forward to during a staged rollout.
+	// +optional
+	PersonalityPendingRevision string `json:"personalityPendingRevision,omitempty"`
+
+	// ResolvedPersonality is the personality the pods mount, with the
+	// digest its tag resolved to.
+	// +optional
+	ResolvedPersonality *ResolvedPersonality `json:"resolvedPersonality,omitempty"`
+
 	// PluginCount is the number of plugins loaded.
 	// +optional
 	PluginCount int `json:"pluginCount,omitempty"`
 
+	// ResolvedPlugins are the plugins the pods mount, including those of
+	// the personality, with the digest each tag resolved to.
+	// +optional
+	ResolvedPlugins []ResolvedPlugin `json:"resolvedPlugins,omitempty"`
+
 	// MCPServerCount is the number of MCP servers configured.
 	// +optional
 	MCPServerCount int `json:"mcpServerCount,omitempty"`
 
+	// Gateway is the backend the instance is registered with, so the
+	// registration is removed when spec.gateway.type changes.
+	// +optional
+	Gateway GatewayType `json:"gateway,omitempty"`
+
+	// FailureReason is a CamelCase reason why the instance pods fail, e.g.
+	// ImagePullFailed, CrashLoopBackOff or GitCloneFailed. Unset while the
+	// pods are healthy.
+	// +optional
+	FailureReason string `json:"failureReason,omitempty"`
+
+	// FailureMessage explains FailureReason with the container states and
+	// last termination messages of the failing pod.
+	// +optional
+	FailureMessage string `json:"failureMessage,omitempty"`
+
 	// Conditions represent the latest available observations of the instance's state.
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -479,17 +1680,294 @@ type KlausInstanceStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// EffectiveConfigHash is the checksum of the effective spec the instance
+	// runs: its spec merged with the personality, skill packs and
+	// KlausMCPServers, with its OCI references resolved. The spec, with
+	// secrets redacted, is published in the <instance>-effective-config
+	// ConfigMap next to the instance pods.
+	// +optional
+	EffectiveConfigHash string `json:"effectiveConfigHash,omitempty"`
+
 	// Toolchain is the resolved container image name when different from the default.
 	// +optional
 	Toolchain string `json:"toolchain,omitempty"`
+
+	// AgentCapabilities records the features reported by the agent's
+	// /capabilities endpoint. Populated on first readiness and refreshed
+	// whenever the resolved container image changes.
+	// +optional
+	AgentCapabilities *AgentCapabilities `json:"agentCapabilities,omitempty"`
+
+	// ImagePlatforms records the platforms supported by the resolved
+	// container image, read from its image index (or image config for
+	// single-platform images). Refreshed whenever the resolved image changes.
+	// +optional
+	ImagePlatforms *ImagePlatforms `json:"imagePlatforms,omitempty"`
+
+	// ResourceUsage is the latest observed resource consumption of the
+	// instance pod and workspace. Refreshed periodically while the instance
+	// runs; unset when no usage is available.
+	// +optional
+	ResourceUsage *ResourceUsage `json:"resourceUsage,omitempty"`
+
+	// Workspace reports the state of the workspace git checkout. Only set
+	// for instances with spec.workspace.gitRepo.
+	// +optional
+	Workspace *WorkspaceStatus `json:"workspace,omitempty"`
+
+	// TokenUsage is the Claude token consumption and cost of the instance
+	// since it was created, read from its telemetry metrics. The operator
+	// suspends the instance once the cost reaches
+	// spec.claude.maxBudgetUSD.
+	// +optional
+	TokenUsage *TokenUsage `json:"tokenUsage,omitempty"`
+
+	// ReadinessGates reports the last result of each of
+	// spec.readinessGates.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReadinessGates []ReadinessGateStatus `json:"readinessGates,omitempty"`
+
+	// Result is the final output of the last completed prompt of an agent
+	// mode instance. It is kept while the instance is stopped.
+	// +optional
+	Result *InstanceResult `json:"result,omitempty"`
+}
+
+// InstanceResult is the final output of an agent run.
+type InstanceResult struct {
+	// Text is the output of the agent. Structured output, requested with
+	// spec.claude.jsonSchema, is stored as compact JSON.
+	// +optional
+	Text string `json:"text,omitempty"`
+
+	// Structured is true when Text is the JSON output requested by
+	// spec.claude.jsonSchema and matches the schema.
+	// +optional
+	Structured bool `json:"structured,omitempty"`
+
+	// Truncated is true when Text was cut to fit into the status. The full
+	// output is available with the get_result MCP tool while the instance
+	// runs.
+	// +optional
+	Truncated bool `json:"truncated,omitempty"`
+
+	// Error describes why the output does not match
+	// spec.claude.jsonSchema, e.g. because it is not JSON or misses a
+	// required property. The ResultValid condition reports it too.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// MessageCount is the number of messages of the agent session when the
+	// result was recorded.
+	// +optional
+	MessageCount int64 `json:"messageCount,omitempty"`
+
+	// CompletedAt is when the operator observed the result.
+	CompletedAt metav1.Time `json:"completedAt"`
+}
+
+// ResolvedPersonality is a personality reference pinned to a digest.
+type ResolvedPersonality struct {
+	// Reference is the resolved personality reference without digest,
+	// e.g. the concrete version a latest tag resolved to.
+	Reference string `json:"reference"`
+
+	// Digest is the manifest digest the pods mount.
+	Digest string `json:"digest"`
+
+	// ResolvedAt is when Reference was last resolved.
+	// +optional
+	ResolvedAt *metav1.Time `json:"resolvedAt,omitempty"`
+
+	// ResolvedGeneration is the instance generation Reference was last
+	// resolved at.
+	// +optional
+	ResolvedGeneration int64 `json:"resolvedGeneration,omitempty"`
+}
+
+// ResolvedPlugin is a plugin reference pinned to a digest.
+type ResolvedPlugin struct {
+	// Repository is the OCI repository of the plugin.
+	Repository string `json:"repository"`
+
+	// Tag is the tag that resolved to Digest; empty for plugins referenced
+	// by digest.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest is the manifest digest the pods mount.
+	Digest string `json:"digest"`
+
+	// ResolvedAt is when Tag was last resolved.
+	// +optional
+	ResolvedAt *metav1.Time `json:"resolvedAt,omitempty"`
+
+	// ResolvedGeneration is the instance generation Tag was last resolved
+	// at.
+	// +optional
+	ResolvedGeneration int64 `json:"resolvedGeneration,omitempty"`
+}
+
+// ReadinessGateStatus is the result of a readiness gate.
+type ReadinessGateStatus struct {
+	// Name is the name of the gate.
+	Name string `json:"name"`
+
+	// Ready is true when the gate passed.
+	Ready bool `json:"ready"`
+
+	// Message describes why the gate did not pass.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is when ready last changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// TokenUsage describes the Claude token consumption and cost of an
+// instance.
+type TokenUsage struct {
+	// InputTokens is the number of input tokens.
+	// +optional
+	InputTokens int64 `json:"inputTokens,omitempty"`
+
+	// OutputTokens is the number of output tokens.
+	// +optional
+	OutputTokens int64 `json:"outputTokens,omitempty"`
+
+	// CacheReadTokens is the number of input tokens read from the prompt
+	// cache.
+	// +optional
+	CacheReadTokens int64 `json:"cacheReadTokens,omitempty"`
+
+	// CacheCreationTokens is the number of input tokens written to the
+	// prompt cache.
+	// +optional
+	CacheCreationTokens int64 `json:"cacheCreationTokens,omitempty"`
+
+	// CostUSD is the estimated cost in US dollars, e.g. "1.2345".
+	// +optional
+	CostUSD string `json:"costUSD,omitempty"`
+
+	// ObservedAt is when the usage was collected.
+	ObservedAt metav1.Time `json:"observedAt"`
+}
+
+// WorkspaceStatus describes the git checkout on the workspace volume.
+type WorkspaceStatus struct {
+	// ResolvedSHA is the commit the workspace was last cloned or synced
+	// to, by the git-clone init container or the workspace_pull and
+	// workspace_reset MCP tools.
+	// +optional
+	ResolvedSHA string `json:"resolvedSHA,omitempty"`
+
+	// HeadSHA is the commit currently checked out. It differs from
+	// resolvedSHA when the agent committed or switched branches.
+	// +optional
+	HeadSHA string `json:"headSHA,omitempty"`
+
+	// LastSyncTime is when the workspace was last cloned or synced.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Dirty is true when the checkout has uncommitted changes or untracked
+	// files. Unset until the workspace has been checked.
+	// +optional
+	Dirty *bool `json:"dirty,omitempty"`
+
+	// ChangedFiles is the number of modified, added, deleted and untracked
+	// paths in the checkout.
+	// +optional
+	ChangedFiles int32 `json:"changedFiles,omitempty"`
+
+	// LastCheckTime is when headSHA and dirty were last observed.
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// ResourceUsage describes the resource consumption of an instance.
+type ResourceUsage struct {
+	// CPU is the CPU usage of the instance pod, summed over its containers,
+	// as reported by metrics-server.
+	// +optional
+	CPU *resource.Quantity `json:"cpu,omitempty"`
+
+	// Memory is the working set memory of the instance pod, summed over its
+	// containers, as reported by metrics-server.
+	// +optional
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// WorkspaceUsed is the used space of the workspace volume, read from
+	// the kubelet volume statistics in Prometheus.
+	// +optional
+	WorkspaceUsed *resource.Quantity `json:"workspaceUsed,omitempty"`
+
+	// WorkspaceCapacity is the capacity of the workspace volume, read from
+	// the kubelet volume statistics in Prometheus.
+	// +optional
+	WorkspaceCapacity *resource.Quantity `json:"workspaceCapacity,omitempty"`
+
+	// ObservedAt is when the usage was collected.
+	ObservedAt metav1.Time `json:"observedAt"`
+}
+
+// ImagePlatforms describes the platforms a container image is published for.
+type ImagePlatforms struct {
+	// Image is the container image the platforms were read from.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Platforms lists the supported platforms in os/architecture[/variant]
+	// form (e.g. "linux/amd64", "linux/arm64/v8").
+	// +optional
+	Platforms []string `json:"platforms,omitempty"`
+
+	// ObservedAt is the time the image was last inspected.
+	// +optional
+	ObservedAt *metav1.Time `json:"observedAt,omitempty"`
+}
+
+// AgentCapabilities describes the features supported by the klaus binary
+// running in an instance, as reported by its /capabilities endpoint.
+type AgentCapabilities struct {
+	// Image is the container image the capabilities were observed on.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Version is the klaus binary version reported by the agent.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Features lists the optional features the agent supports
+	// (e.g. "persistent-mode", "partial-messages", "structured-output").
+	// +optional
+	Features []string `json:"features,omitempty"`
+
+	// ObservedAt is the time the capabilities were last queried.
+	// +optional
+	ObservedAt *metav1.Time `json:"observedAt,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.status.mode`
 // +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
 // +kubebuilder:printcolumn:name="Personality",type=string,JSONPath=`.status.personality`
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.status.endpoint`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Toolchain",type=string,JSONPath=`.status.toolchain`,priority=1
+// +kubebuilder:printcolumn:name="Cost USD",type=string,JSONPath=`.status.tokenUsage.costUSD`,priority=1
+// +kubebuilder:printcolumn:name="Budget USD",type=number,JSONPath=`.spec.claude.maxBudgetUSD`,priority=1
+// +kubebuilder:printcolumn:name="MCP Servers",type=string,JSONPath=`.status.conditions[?(@.type=="MCPServerReady")].status`,priority=1
+// +kubebuilder:printcolumn:name="Plugins",type=integer,JSONPath=`.status.pluginCount`,priority=1
+// +kubebuilder:printcolumn:name="Repo",type=string,JSONPath=`.spec.workspace.gitRepo`,priority=1
+// +kubebuilder:printcolumn:name="Mock",type=boolean,JSONPath=`.status.mock`,priority=1
+// +kubebuilder:resource:shortName=ki,categories=klaus
 
 // KlausInstance is the Schema for the klausinstances API.
 // It represents a running Klaus agent instance with its configuration.
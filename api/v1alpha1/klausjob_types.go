@@ -0,0 +1,284 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KlausJobSpec defines the desired state of a KlausJob.
+type KlausJobSpec struct {
+	// Owner is the user identity (email) that owns this job.
+	// Used for access control and namespace isolation.
+	// +kubebuilder:validation:MaxLength=254
+	// +kubebuilder:validation:XValidation:rule="self.matches('^[^@ ]+@[^@ ]+$')",message="owner must be an email address"
+	Owner string `json:"owner"`
+
+	// Prompt is the prompt sent to the agent. Mutually exclusive with
+	// promptTemplate.
+	// +optional
+	Prompt string `json:"prompt,omitempty"`
+
+	// PromptTemplate is a Go text/template rendered with its variables to
+	// produce the prompt. Mutually exclusive with prompt.
+	// +optional
+	PromptTemplate *PromptTemplate `json:"promptTemplate,omitempty"`
+
+	// Personality is an OCI reference to a personality artifact that provides
+	// default configuration for this job.
+	// +optional
+	Personality string `json:"personality,omitempty"`
+
+	// Image overrides the container image for this job.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ToolchainRef names a KlausToolchain in the operator namespace whose
+	// image the job uses, like spec.image. Mutually exclusive with Image.
+	// +optional
+	ToolchainRef string `json:"toolchainRef,omitempty"`
+
+	// Claude contains Claude Code agent configuration.
+	// +optional
+	Claude ClaudeConfig `json:"claude,omitempty"`
+
+	// Plugins lists OCI plugin references to mount into the job pod.
+	// +optional
+	Plugins []PluginReference `json:"plugins,omitempty"`
+
+	// ImagePullSecrets specifies pull secrets for private registries.
+	// +optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
+	// Workspace configures persistent storage for the job, typically with a
+	// git repository cloned before the agent starts.
+	// +optional
+	Workspace *WorkspaceConfig `json:"workspace,omitempty"`
+
+	// Resources specifies compute resource requirements for the job pod.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Telemetry configures OpenTelemetry for the job, like
+	// spec.telemetry of a KlausInstance.
+	// +optional
+	Telemetry *TelemetryConfig `json:"telemetry,omitempty"`
+
+	// Timeout bounds the total run time of the job, across all attempts.
+	// Maps to the Job's activeDeadlineSeconds.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Retry configures how failed runs are retried.
+	// +optional
+	Retry *JobRetryPolicy `json:"retry,omitempty"`
+
+	// TerminationMessage configures where the klaus container writes its
+	// termination message and whether it is a JSON exit summary.
+	// +optional
+	TerminationMessage *TerminationMessageConfig `json:"terminationMessage,omitempty"`
+}
+
+// TerminationMessageConfig configures the termination message of the klaus
+// container in a KlausJob pod.
+type TerminationMessageConfig struct {
+	// Path is the file the klaus container writes its termination message
+	// to. Defaults to /dev/termination-log.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Policy selects how the termination message is populated. With
+	// FallbackToLogsOnError (the default) the tail of the container log is
+	// used when the container fails without writing a message.
+	// +kubebuilder:validation:Enum=File;FallbackToLogsOnError
+	// +optional
+	Policy corev1.TerminationMessagePolicy `json:"policy,omitempty"`
+
+	// ExitSummary makes the agent write a JSON exit summary (turns, cost,
+	// result) instead of the bare result. Defaults to true.
+	// +optional
+	ExitSummary *bool `json:"exitSummary,omitempty"`
+}
+
+// PromptTemplate is a Go text/template prompt with named variables.
+type PromptTemplate struct {
+	// Template is the template text, e.g. "Review {{ .repo }} for security issues".
+	Template string `json:"template"`
+
+	// Variables are the values available to the template.
+	// +optional
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// JobRetryPolicy configures retries for a KlausJob.
+type JobRetryPolicy struct {
+	// BackoffLimit is the number of retries before the job is marked failed.
+	// Defaults to 0 (no retries).
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// JobState represents the lifecycle state of a KlausJob.
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type JobState string
+
+const (
+	JobStatePending   JobState = "Pending"
+	JobStateRunning   JobState = "Running"
+	JobStateSucceeded JobState = "Succeeded"
+	JobStateFailed    JobState = "Failed"
+)
+
+// KlausJobStatus defines the observed state of a KlausJob.
+type KlausJobStatus struct {
+	// State is the current lifecycle state.
+	// +optional
+	State JobState `json:"state,omitempty"`
+
+	// JobName is the name of the batch Job in the user namespace.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// Attempts is the number of pods started for this job.
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// StartTime is when the batch Job started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the job finished, successfully or not.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// ExitCode is the exit code of the klaus container in the last attempt.
+	// +optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Result is the final output of the agent, read from the container
+	// termination message. Structured output is stored verbatim as JSON.
+	// +optional
+	Result string `json:"result,omitempty"`
+
+	// Summary is the exit summary the agent wrote to its termination
+	// message, when spec.terminationMessage.exitSummary is enabled.
+	// +optional
+	Summary *JobExitSummary `json:"summary,omitempty"`
+
+	// Output records pushing the workspace changes for
+	// spec.workspace.output after the run succeeded.
+	// +optional
+	Output *JobOutputStatus `json:"output,omitempty"`
+
+	// Conditions represent the latest available observations.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// JobOutputState is the state of pushing the workspace changes of a
+// KlausJob.
+// +kubebuilder:validation:Enum=Pending;Pushed;NoChanges;Failed
+type JobOutputState string
+
+const (
+	// JobOutputPending means the changes are being pushed.
+	JobOutputPending JobOutputState = "Pending"
+
+	// JobOutputPushed means the changes were pushed, and the pull request
+	// opened when requested.
+	JobOutputPushed JobOutputState = "Pushed"
+
+	// JobOutputNoChanges means the agent left the checkout unchanged, so
+	// nothing was pushed.
+	JobOutputNoChanges JobOutputState = "NoChanges"
+
+	// JobOutputFailed means pushing the changes or opening the pull request
+	// failed.
+	JobOutputFailed JobOutputState = "Failed"
+)
+
+// JobOutputStatus is the outcome of spec.workspace.output.
+type JobOutputStatus struct {
+	// State is the push state.
+	State JobOutputState `json:"state"`
+
+	// Branch is the rendered branch the changes are pushed to.
+	// +optional
+	Branch string `json:"branch,omitempty"`
+
+	// Commit is the pushed commit.
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// PullRequestURL is the web URL of the opened pull request.
+	// +optional
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+
+	// Message explains a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// JobExitSummary is the exit summary of a finished KlausJob run.
+type JobExitSummary struct {
+	// Turns is the number of agent turns taken.
+	// +optional
+	Turns *int32 `json:"turns,omitempty"`
+
+	// CostUSD is the total API cost of the run in US dollars, as a decimal
+	// string.
+	// +optional
+	CostUSD string `json:"costUSD,omitempty"`
+
+	// DurationMilliseconds is the wall-clock duration of the run.
+	// +optional
+	DurationMilliseconds *int64 `json:"durationMilliseconds,omitempty"`
+
+	// IsError reports whether the agent ended the run with an error.
+	// +optional
+	IsError bool `json:"isError,omitempty"`
+
+	// ResultPath points to the full result on the workspace volume when it
+	// does not fit into the termination message.
+	// +optional
+	ResultPath string `json:"resultPath,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
+// +kubebuilder:printcolumn:name="Attempts",type=integer,JSONPath=`.status.attempts`
+// +kubebuilder:printcolumn:name="Completed",type=date,JSONPath=`.status.completionTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Personality",type=string,JSONPath=`.spec.personality`,priority=1
+// +kubebuilder:printcolumn:name="Job",type=string,JSONPath=`.status.jobName`,priority=1
+// +kubebuilder:printcolumn:name="Exit Code",type=integer,JSONPath=`.status.exitCode`,priority=1
+// +kubebuilder:printcolumn:name="Pull Request",type=string,JSONPath=`.status.output.pullRequestURL`,priority=1
+// +kubebuilder:resource:shortName=kjob,categories=klaus
+
+// KlausJob is the Schema for the klausjobs API.
+// It runs a prompt to completion as a Kubernetes Job instead of a long-lived
+// Deployment, for CI-style one-shot agent runs.
+type KlausJob struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausJobSpec   `json:"spec,omitempty"`
+	Status KlausJobStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausJobList contains a list of KlausJob.
+type KlausJobList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausJob `json:"items"`
+}
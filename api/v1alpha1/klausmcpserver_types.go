@@ -19,6 +19,15 @@ type KlausMCPServerSpec struct {
 	// +optional
 	Command string `json:"command,omitempty"`
 
+	// OCIRef is an OCI artifact holding the executable or package of a
+	// stdio server, e.g. gsoci.azurecr.io/giantswarm/mcp-servers/github:v1.0.0.
+	// It is mounted read-only into instance pods like plugins, so the server
+	// does not have to exist in the instance image; a relative command is
+	// resolved against the mount.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9.-]+(:[0-9]+)?/[a-zA-Z0-9._/-]+(:[a-zA-Z0-9._-]+)?(@sha256:[a-f0-9]{64})?$`
+	// +optional
+	OCIRef string `json:"ociRef,omitempty"`
+
 	// Args for stdio-based MCP servers.
 	// +optional
 	Args []string `json:"args,omitempty"`
@@ -58,14 +67,28 @@ type KlausMCPServerStatus struct {
 	// ObservedGeneration is the most recent generation observed by the controller.
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastProbeTime is when the URL of an HTTP-based server was last probed.
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+
+	// LastProbeLatency is how long the server took to respond to the last
+	// successful probe.
+	// +optional
+	LastProbeLatency *metav1.Duration `json:"lastProbeLatency,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
 // +kubebuilder:printcolumn:name="Instances",type=integer,JSONPath=`.status.instanceCount`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reachable",type=string,JSONPath=`.status.conditions[?(@.type=="Reachable")].status`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
-// +kubebuilder:resource:shortName=kmcp
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`,priority=1
+// +kubebuilder:printcolumn:name="Latency",type=string,JSONPath=`.status.lastProbeLatency`,priority=1
+// +kubebuilder:printcolumn:name="Last Probe",type=date,JSONPath=`.status.lastProbeTime`,priority=1
+// +kubebuilder:resource:shortName=kmcp,categories=klaus
 
 // KlausMCPServer defines a shared MCP server configuration that can be
 // referenced by KlausInstance resources. The operator resolves these
@@ -0,0 +1,63 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KlausQuotaSpec defines the limits applied to the instances of an owner.
+type KlausQuotaSpec struct {
+	// Owner is the owner identity, matching spec.owner of instances, the
+	// quota applies to. A quota without an owner is the default for owners
+	// that have no quota of their own.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// AnthropicAPI limits the owner's Anthropic API usage across all of
+	// their instances. Each running instance calling the Anthropic API gets
+	// an equal share, passed to its agent in environment variables.
+	// +optional
+	AnthropicAPI *APIRateLimit `json:"anthropicAPI,omitempty"`
+}
+
+// APIRateLimit is a token bucket limit on API requests.
+type APIRateLimit struct {
+	// MaxConcurrentRequests caps the requests in flight at any time.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentRequests int32 `json:"maxConcurrentRequests,omitempty"`
+
+	// RequestsPerMinute is the rate the token bucket refills at.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RequestsPerMinute int32 `json:"requestsPerMinute,omitempty"`
+
+	// Burst is the token bucket size. Defaults to RequestsPerMinute.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
+// +kubebuilder:printcolumn:name="Concurrency",type=integer,JSONPath=`.spec.anthropicAPI.maxConcurrentRequests`
+// +kubebuilder:printcolumn:name="RPM",type=integer,JSONPath=`.spec.anthropicAPI.requestsPerMinute`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:shortName=kquota,categories=klaus
+
+// KlausQuota sets limits for the instances of an owner. Quotas are read from
+// the operator namespace.
+type KlausQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KlausQuotaSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausQuotaList contains a list of KlausQuota.
+type KlausQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausQuota `json:"items"`
+}
@@ -0,0 +1,78 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KlausSkillPackSpec defines a shared bundle of skills, agent files and
+// commands. They are rendered like spec.skills, spec.agentFiles and
+// spec.commands of the instances referencing the pack.
+type KlausSkillPackSpec struct {
+	// Description tells users and agents what the skill pack is for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Skills defines skills rendered as SKILL.md files.
+	// +optional
+	Skills map[string]SkillConfig `json:"skills,omitempty"`
+
+	// AgentFiles defines markdown-format subagent definitions.
+	// +optional
+	AgentFiles map[string]AgentFileConfig `json:"agentFiles,omitempty"`
+
+	// Commands defines slash commands rendered as command files.
+	// +optional
+	Commands map[string]CommandConfig `json:"commands,omitempty"`
+}
+
+// KlausSkillPackStatus defines the observed state of a KlausSkillPack.
+type KlausSkillPackStatus struct {
+	// Conditions represent the latest available observations of the pack's
+	// state.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// InstanceCount is the number of KlausInstance resources referencing
+	// this pack.
+	// +optional
+	InstanceCount int `json:"instanceCount,omitempty"`
+
+	// Instances lists the names of the KlausInstances referencing this
+	// pack, truncated to the first 50 in name order.
+	// +optional
+	Instances []string `json:"instances,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed by the
+	// controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Instances",type=integer,JSONPath=`.status.instanceCount`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=`.spec.description`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:shortName=ksp,categories=klaus
+
+// KlausSkillPack defines a bundle of skills, agent files and commands that
+// KlausInstances and their inline personalities reference by name in
+// spec.skillPacks, instead of copying the markdown into each of them.
+// Instances reference packs in their own namespace.
+type KlausSkillPack struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausSkillPackSpec   `json:"spec,omitempty"`
+	Status KlausSkillPackStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausSkillPackList contains a list of KlausSkillPack.
+type KlausSkillPackList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausSkillPack `json:"items"`
+}
@@ -0,0 +1,64 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KlausTenantSpec defines the limits applied to an owner as an MCP caller.
+type KlausTenantSpec struct {
+	// Owner is the owner identity, matching spec.owner of instances and the
+	// identity of MCP callers, the tenant applies to. A tenant without an
+	// owner is the default for owners that have no tenant of their own.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// MCP limits the owner's MCP tool calls, overriding the operator's
+	// --mcp-rate-limit and --mcp-rate-burst for them.
+	// +optional
+	MCP *MCPRateLimit `json:"mcp,omitempty"`
+
+	// MaxInstances caps the owner's instances that are not stopped,
+	// overriding the operator's --max-instances-per-owner. Creating or
+	// starting an instance beyond it is rejected at admission and through
+	// MCP.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxInstances int32 `json:"maxInstances,omitempty"`
+}
+
+// MCPRateLimit is a token bucket limit on the MCP tool calls of a user.
+type MCPRateLimit struct {
+	// RequestsPerMinute is the rate the token bucket refills at.
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerMinute int32 `json:"requestsPerMinute"`
+
+	// Burst is the token bucket size. Defaults to RequestsPerMinute.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
+// +kubebuilder:printcolumn:name="RPM",type=integer,JSONPath=`.spec.mcp.requestsPerMinute`
+// +kubebuilder:printcolumn:name="Max Instances",type=integer,JSONPath=`.spec.maxInstances`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:shortName=ktenant,categories=klaus
+
+// KlausTenant sets the MCP rate limit and the instance limit of an owner.
+// Tenants are read from the operator namespace.
+type KlausTenant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KlausTenantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausTenantList contains a list of KlausTenant.
+type KlausTenantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausTenant `json:"items"`
+}
@@ -0,0 +1,58 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KlausToolchainSpec describes an approved toolchain image instances may
+// reference by name.
+type KlausToolchainSpec struct {
+	// Image is the toolchain container image. It is resolved like
+	// spec.image of an instance: short names are looked up in the Klaus
+	// toolchain registry and untagged or latest references resolve to the
+	// newest version.
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// Description tells users and agents what the toolchain is for.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Language is the main programming language of the toolchain, e.g. go
+	// or python.
+	// +optional
+	Language string `json:"language,omitempty"`
+
+	// Personalities restricts the toolchain to instances and jobs using one
+	// of these personalities, given by short name such as go-dev or as a
+	// reference. Empty allows any personality. Listed by the
+	// list_toolchains MCP tool.
+	// +optional
+	Personalities []string `json:"personalities,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.image`
+// +kubebuilder:printcolumn:name="Language",type=string,JSONPath=`.spec.language`
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=`.spec.description`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:resource:shortName=ktc,categories=klaus
+
+// KlausToolchain catalogues a toolchain image approved for instances, which
+// reference it by name in spec.toolchainRef. Toolchains are read from the
+// operator namespace.
+type KlausToolchain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec KlausToolchainSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausToolchainList contains a list of KlausToolchain.
+type KlausToolchainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausToolchain `json:"items"`
+}
@@ -0,0 +1,169 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TriggerSourceType selects the kind of events a KlausTrigger accepts.
+// +kubebuilder:validation:Enum=Webhook;GitHub;Alertmanager
+type TriggerSourceType string
+
+const (
+	// TriggerSourceWebhook accepts any JSON payload authenticated with a
+	// bearer token. The event name is read from the X-Klaus-Event header.
+	TriggerSourceWebhook TriggerSourceType = "Webhook"
+
+	// TriggerSourceGitHub accepts GitHub webhook deliveries signed with the
+	// webhook secret. The event name is the X-GitHub-Event header.
+	TriggerSourceGitHub TriggerSourceType = "GitHub"
+
+	// TriggerSourceAlertmanager accepts Alertmanager webhook notifications
+	// authenticated with a bearer token. The event name is the status of
+	// the notification, firing or resolved.
+	TriggerSourceAlertmanager TriggerSourceType = "Alertmanager"
+)
+
+// KlausTriggerSpec defines the desired state of a KlausTrigger.
+// +kubebuilder:validation:XValidation:rule="has(self.job) != has(self.instance)",message="exactly one of job and instance must be set"
+type KlausTriggerSpec struct {
+	// Owner is the user identity (email) the trigger acts for. Prompted
+	// instances must be owned by it.
+	// +kubebuilder:validation:MaxLength=254
+	// +kubebuilder:validation:XValidation:rule="self.matches('^[^@ ]+@[^@ ]+$')",message="owner must be an email address"
+	Owner string `json:"owner"`
+
+	// Source configures the events that fire the trigger.
+	Source TriggerSource `json:"source"`
+
+	// PromptTemplate is a Go text/template rendered with the event to
+	// produce the prompt. The template sees .event (the event name),
+	// .payload (the decoded JSON body) and .trigger (the trigger name).
+	// Required with instance; for job it replaces the prompt of
+	// jobTemplate.
+	// +optional
+	PromptTemplate string `json:"promptTemplate,omitempty"`
+
+	// Job creates a KlausJob in the trigger's namespace for every event.
+	// Mutually exclusive with instance.
+	// +optional
+	Job *TriggerJobAction `json:"job,omitempty"`
+
+	// Instance sends the prompt to a persistent KlausInstance in the
+	// operator namespace for every event. Mutually exclusive with job.
+	// +optional
+	Instance *TriggerInstanceAction `json:"instance,omitempty"`
+
+	// Suspend rejects events without acting on them.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// TriggerSource configures the events of a KlausTrigger.
+type TriggerSource struct {
+	// Type is the kind of events accepted.
+	Type TriggerSourceType `json:"type"`
+
+	// SecretRef references a Secret in the trigger's namespace holding the
+	// bearer token, or the webhook secret for GitHub.
+	SecretRef TriggerSecretReference `json:"secretRef"`
+
+	// Events limits the trigger to these event names. GitHub events also
+	// match as <event>.<action>, e.g. "pull_request.opened". All events
+	// fire the trigger when empty.
+	// +optional
+	Events []string `json:"events,omitempty"`
+}
+
+// TriggerSecretReference references a key of a Secret in the trigger's
+// namespace.
+type TriggerSecretReference struct {
+	// Name is the name of the Secret.
+	Name string `json:"name"`
+
+	// Key is the key in the Secret data. Defaults to "token".
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9._-]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TriggerJobAction creates a KlausJob for every event.
+type TriggerJobAction struct {
+	// JobTemplate is the KlausJob spec stamped out for each event.
+	JobTemplate KlausJobSpec `json:"jobTemplate"`
+
+	// SuccessfulJobsHistoryLimit is the number of succeeded KlausJobs to keep.
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed KlausJobs to keep.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// TriggerInstanceAction prompts a persistent instance for every event.
+type TriggerInstanceAction struct {
+	// Name is the name of the KlausInstance in the operator namespace.
+	Name string `json:"name"`
+}
+
+// KlausTriggerStatus defines the observed state of a KlausTrigger.
+type KlausTriggerStatus struct {
+	// Path is the path of the trigger on the operator's trigger listener.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// LastTriggerTime is when an event last fired the trigger.
+	// +optional
+	LastTriggerTime *metav1.Time `json:"lastTriggerTime,omitempty"`
+
+	// LastEvent is the name of the event that last fired the trigger.
+	// +optional
+	LastEvent string `json:"lastEvent,omitempty"`
+
+	// LastJobName is the KlausJob created for the last event.
+	// +optional
+	LastJobName string `json:"lastJobName,omitempty"`
+
+	// Conditions represent the latest available observations.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.spec.source.type`
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Last Trigger",type=date,JSONPath=`.status.lastTriggerTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Last Event",type=string,JSONPath=`.status.lastEvent`,priority=1
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.status.path`,priority=1
+// +kubebuilder:resource:shortName=ktrigger,categories=klaus
+
+// KlausTrigger is the Schema for the klaustriggers API.
+// It creates KlausJobs or prompts an instance when external events arrive.
+type KlausTrigger struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausTriggerSpec   `json:"spec,omitempty"`
+	Status KlausTriggerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausTriggerList contains a list of KlausTrigger.
+type KlausTriggerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausTrigger `json:"items"`
+}
@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KlausUsageReportSpec is intentionally empty: the object is created and
+// maintained by the operator and only its status carries information.
+type KlausUsageReportSpec struct{}
+
+// InstanceTokenUsage is the token usage of one instance in a usage report.
+type InstanceTokenUsage struct {
+	// Name is the instance name.
+	Name string `json:"name"`
+
+	// BudgetUSD is spec.claude.maxBudgetUSD of the instance, if set.
+	// +optional
+	BudgetUSD string `json:"budgetUSD,omitempty"`
+
+	// Suspended is true when the instance is suspended because its cost
+	// reached its budget.
+	// +optional
+	Suspended bool `json:"suspended,omitempty"`
+
+	TokenUsage `json:",inline"`
+}
+
+// KlausUsageReportStatus is the aggregated token usage of an owner.
+type KlausUsageReportStatus struct {
+	// Owner is the user identity the report is for.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// Total is the sum of the usage of the owner's instances.
+	// +optional
+	Total *TokenUsage `json:"total,omitempty"`
+
+	// Instances lists the usage per instance, sorted by name.
+	// +optional
+	Instances []InstanceTokenUsage `json:"instances,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.status.owner`
+// +kubebuilder:printcolumn:name="Cost USD",type=string,JSONPath=`.status.total.costUSD`
+// +kubebuilder:printcolumn:name="Observed",type=date,JSONPath=`.status.total.observedAt`
+// +kubebuilder:resource:shortName=kusage,categories=klaus
+
+// KlausUsageReport is the Schema for the klaususagereports API.
+// The operator maintains one KlausUsageReport per instance owner in its own
+// namespace, named after the owner's namespace, that aggregates the token
+// usage and cost of the owner's instances.
+type KlausUsageReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausUsageReportSpec   `json:"spec,omitempty"`
+	Status KlausUsageReportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausUsageReportList contains a list of KlausUsageReport.
+type KlausUsageReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausUsageReport `json:"items"`
+}
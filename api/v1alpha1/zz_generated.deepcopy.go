@@ -5,11 +5,51 @@
 package v1alpha1
 
 import (
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIRateLimit) DeepCopyInto(out *APIRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimit.
+func (in *APIRateLimit) DeepCopy() *APIRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(APIRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentCapabilities) DeepCopyInto(out *AgentCapabilities) {
+	*out = *in
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ObservedAt != nil {
+		in, out := &in.ObservedAt, &out.ObservedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentCapabilities.
+func (in *AgentCapabilities) DeepCopy() *AgentCapabilities {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentCapabilities)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AgentFileConfig) DeepCopyInto(out *AgentFileConfig) {
 	*out = *in
@@ -25,6 +65,41 @@ func (in *AgentFileConfig) DeepCopy() *AgentFileConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BedrockConfig) DeepCopyInto(out *BedrockConfig) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(ProviderSecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BedrockConfig.
+func (in *BedrockConfig) DeepCopy() *BedrockConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BedrockConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleReference) DeepCopyInto(out *CABundleReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleReference.
+func (in *CABundleReference) DeepCopy() *CABundleReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClaudeConfig) DeepCopyInto(out *ClaudeConfig) {
 	*out = *in
@@ -67,6 +142,11 @@ func (in *ClaudeConfig) DeepCopyInto(out *ClaudeConfig) {
 		*out = new(float64)
 		**out = **in
 	}
+	if in.StatusLine != nil {
+		in, out := &in.StatusLine, &out.StatusLine
+		*out = new(StatusLineConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tools != nil {
 		in, out := &in.Tools, &out.Tools
 		*out = make([]string, len(*in))
@@ -99,6 +179,11 @@ func (in *ClaudeConfig) DeepCopyInto(out *ClaudeConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(ProviderConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaudeConfig.
@@ -112,399 +197,2675 @@ func (in *ClaudeConfig) DeepCopy() *ClaudeConfig {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitSecretReference) DeepCopyInto(out *GitSecretReference) {
+func (in *CloneSource) DeepCopyInto(out *CloneSource) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSecretReference.
-func (in *GitSecretReference) DeepCopy() *GitSecretReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSource.
+func (in *CloneSource) DeepCopy() *CloneSource {
 	if in == nil {
 		return nil
 	}
-	out := new(GitSecretReference)
+	out := new(CloneSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausInstance) DeepCopyInto(out *KlausInstance) {
+func (in *CommandConfig) DeepCopyInto(out *CommandConfig) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.AllowedTools != nil {
+		in, out := &in.AllowedTools, &out.AllowedTools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableModelInvocation != nil {
+		in, out := &in.DisableModelInvocation, &out.DisableModelInvocation
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstance.
-func (in *KlausInstance) DeepCopy() *KlausInstance {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandConfig.
+func (in *CommandConfig) DeepCopy() *CommandConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausInstance)
+	out := new(CommandConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KlausInstance) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausInstanceList) DeepCopyInto(out *KlausInstanceList) {
+func (in *CronJobRunSummary) DeepCopyInto(out *CronJobRunSummary) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KlausInstance, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(JobExitSummary)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceList.
-func (in *KlausInstanceList) DeepCopy() *KlausInstanceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronJobRunSummary.
+func (in *CronJobRunSummary) DeepCopy() *CronJobRunSummary {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausInstanceList)
+	out := new(CronJobRunSummary)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KlausInstanceList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudgetConfig) DeepCopyInto(out *DisruptionBudgetConfig) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
 	}
-	return nil
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudgetConfig.
+func (in *DisruptionBudgetConfig) DeepCopy() *DisruptionBudgetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudgetConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausInstanceSpec) DeepCopyInto(out *KlausInstanceSpec) {
+func (in *ExecReadinessCheck) DeepCopyInto(out *ExecReadinessCheck) {
 	*out = *in
-	in.Claude.DeepCopyInto(&out.Claude)
-	if in.Plugins != nil {
-		in, out := &in.Plugins, &out.Plugins
-		*out = make([]PluginReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.PluginDirs != nil {
-		in, out := &in.PluginDirs, &out.PluginDirs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.MCPServers != nil {
-		in, out := &in.MCPServers, &out.MCPServers
-		*out = make([]MCPServerReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.Skills != nil {
-		in, out := &in.Skills, &out.Skills
-		*out = make(map[string]SkillConfig, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecReadinessCheck.
+func (in *ExecReadinessCheck) DeepCopy() *ExecReadinessCheck {
+	if in == nil {
+		return nil
 	}
-	if in.AgentFiles != nil {
-		in, out := &in.AgentFiles, &out.AgentFiles
-		*out = make(map[string]AgentFileConfig, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out := new(ExecReadinessCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExposeConfig) DeepCopyInto(out *ExposeConfig) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
 	}
-	if in.Hooks != nil {
-		in, out := &in.Hooks, &out.Hooks
-		*out = make(map[string]runtime.RawExtension, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayReference)
+		**out = **in
 	}
-	if in.HookScripts != nil {
-		in, out := &in.HookScripts, &out.HookScripts
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.AddDirs != nil {
-		in, out := &in.AddDirs, &out.AddDirs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.LoadAdditionalDirsMemory != nil {
-		in, out := &in.LoadAdditionalDirsMemory, &out.LoadAdditionalDirsMemory
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Workspace != nil {
-		in, out := &in.Workspace, &out.Workspace
-		*out = new(WorkspaceConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Telemetry != nil {
-		in, out := &in.Telemetry, &out.Telemetry
-		*out = new(TelemetryConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Muster != nil {
-		in, out := &in.Muster, &out.Muster
-		*out = new(MusterConfig)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceSpec.
-func (in *KlausInstanceSpec) DeepCopy() *KlausInstanceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExposeConfig.
+func (in *ExposeConfig) DeepCopy() *ExposeConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausInstanceSpec)
+	out := new(ExposeConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausInstanceStatus) DeepCopyInto(out *KlausInstanceStatus) {
+func (in *FleetEvent) DeepCopyInto(out *FleetEvent) {
 	*out = *in
-	if in.LastActivity != nil {
-		in, out := &in.LastActivity, &out.LastActivity
-		*out = (*in).DeepCopy()
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.Time.DeepCopyInto(&out.Time)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceStatus.
-func (in *KlausInstanceStatus) DeepCopy() *KlausInstanceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetEvent.
+func (in *FleetEvent) DeepCopy() *FleetEvent {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausInstanceStatus)
+	out := new(FleetEvent)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausMCPServer) DeepCopyInto(out *KlausMCPServer) {
+func (in *FleetOperationFailure) DeepCopyInto(out *FleetOperationFailure) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServer.
-func (in *KlausMCPServer) DeepCopy() *KlausMCPServer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetOperationFailure.
+func (in *FleetOperationFailure) DeepCopy() *FleetOperationFailure {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausMCPServer)
+	out := new(FleetOperationFailure)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KlausMCPServer) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayConfig) DeepCopyInto(out *GatewayConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayConfig.
+func (in *GatewayConfig) DeepCopy() *GatewayConfig {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(GatewayConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausMCPServerList) DeepCopyInto(out *KlausMCPServerList) {
+func (in *GatewayReference) DeepCopyInto(out *GatewayReference) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]KlausMCPServer, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServerList.
-func (in *KlausMCPServerList) DeepCopy() *KlausMCPServerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayReference.
+func (in *GatewayReference) DeepCopy() *GatewayReference {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausMCPServerList)
+	out := new(GatewayReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *KlausMCPServerList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitCloneOptions) DeepCopyInto(out *GitCloneOptions) {
+	*out = *in
+	if in.Depth != nil {
+		in, out := &in.Depth, &out.Depth
+		*out = new(int32)
+		**out = **in
 	}
-	return nil
+	if in.SparseCheckoutPaths != nil {
+		in, out := &in.SparseCheckoutPaths, &out.SparseCheckoutPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitCloneOptions.
+func (in *GitCloneOptions) DeepCopy() *GitCloneOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(GitCloneOptions)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausMCPServerSpec) DeepCopyInto(out *KlausMCPServerSpec) {
+func (in *GitHubAppCredentials) DeepCopyInto(out *GitHubAppCredentials) {
 	*out = *in
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubAppCredentials.
+func (in *GitHubAppCredentials) DeepCopy() *GitHubAppCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubAppCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSecretReference) DeepCopyInto(out *GitSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSecretReference.
+func (in *GitSecretReference) DeepCopy() *GitSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPReadinessCheck) DeepCopyInto(out *HTTPReadinessCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPReadinessCheck.
+func (in *HTTPReadinessCheck) DeepCopy() *HTTPReadinessCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPReadinessCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePlatforms) DeepCopyInto(out *ImagePlatforms) {
+	*out = *in
+	if in.Platforms != nil {
+		in, out := &in.Platforms, &out.Platforms
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Env != nil {
-		in, out := &in.Env, &out.Env
-		*out = make(map[string]string, len(*in))
+	if in.ObservedAt != nil {
+		in, out := &in.ObservedAt, &out.ObservedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePlatforms.
+func (in *ImagePlatforms) DeepCopy() *ImagePlatforms {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePlatforms)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InlinePersonality) DeepCopyInto(out *InlinePersonality) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Skills != nil {
+		in, out := &in.Skills, &out.Skills
+		*out = make(map[string]SkillConfig, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
-	if in.Headers != nil {
-		in, out := &in.Headers, &out.Headers
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make(map[string]CommandConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.SkillPacks != nil {
+		in, out := &in.SkillPacks, &out.SkillPacks
+		*out = make([]SkillPackReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(MemoryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateValues != nil {
+		in, out := &in.TemplateValues, &out.TemplateValues
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
-	if in.SecretRefs != nil {
-		in, out := &in.SecretRefs, &out.SecretRefs
-		*out = make([]MCPServerSecret, len(*in))
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnvFrom != nil {
+		in, out := &in.ExtraEnvFrom, &out.ExtraEnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(SchedulingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServerSpec.
-func (in *KlausMCPServerSpec) DeepCopy() *KlausMCPServerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InlinePersonality.
+func (in *InlinePersonality) DeepCopy() *InlinePersonality {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausMCPServerSpec)
+	out := new(InlinePersonality)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KlausMCPServerStatus) DeepCopyInto(out *KlausMCPServerStatus) {
+func (in *InstanceDependency) DeepCopyInto(out *InstanceDependency) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceDependency.
+func (in *InstanceDependency) DeepCopy() *InstanceDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceResult) DeepCopyInto(out *InstanceResult) {
+	*out = *in
+	in.CompletedAt.DeepCopyInto(&out.CompletedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceResult.
+func (in *InstanceResult) DeepCopy() *InstanceResult {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceSummary) DeepCopyInto(out *InstanceSummary) {
+	*out = *in
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSummary.
+func (in *InstanceSummary) DeepCopy() *InstanceSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceTLSConfig) DeepCopyInto(out *InstanceTLSConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceTLSConfig.
+func (in *InstanceTLSConfig) DeepCopy() *InstanceTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceTokenUsage) DeepCopyInto(out *InstanceTokenUsage) {
+	*out = *in
+	in.TokenUsage.DeepCopyInto(&out.TokenUsage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceTokenUsage.
+func (in *InstanceTokenUsage) DeepCopy() *InstanceTokenUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceTokenUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobExitSummary) DeepCopyInto(out *JobExitSummary) {
+	*out = *in
+	if in.Turns != nil {
+		in, out := &in.Turns, &out.Turns
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DurationMilliseconds != nil {
+		in, out := &in.DurationMilliseconds, &out.DurationMilliseconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobExitSummary.
+func (in *JobExitSummary) DeepCopy() *JobExitSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(JobExitSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobOutputStatus) DeepCopyInto(out *JobOutputStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobOutputStatus.
+func (in *JobOutputStatus) DeepCopy() *JobOutputStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JobOutputStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobRetryPolicy) DeepCopyInto(out *JobRetryPolicy) {
+	*out = *in
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobRetryPolicy.
+func (in *JobRetryPolicy) DeepCopy() *JobRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(JobRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausCronJob) DeepCopyInto(out *KlausCronJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausCronJob.
+func (in *KlausCronJob) DeepCopy() *KlausCronJob {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausCronJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausCronJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausCronJobList) DeepCopyInto(out *KlausCronJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausCronJob, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServerStatus.
-func (in *KlausMCPServerStatus) DeepCopy() *KlausMCPServerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausCronJobList.
+func (in *KlausCronJobList) DeepCopy() *KlausCronJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausCronJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausCronJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausCronJobSpec) DeepCopyInto(out *KlausCronJobSpec) {
+	*out = *in
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausCronJobSpec.
+func (in *KlausCronJobSpec) DeepCopy() *KlausCronJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausCronJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausCronJobStatus) DeepCopyInto(out *KlausCronJobStatus) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRun != nil {
+		in, out := &in.LastRun, &out.LastRun
+		*out = new(CronJobRunSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausCronJobStatus.
+func (in *KlausCronJobStatus) DeepCopy() *KlausCronJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausCronJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetOperation) DeepCopyInto(out *KlausFleetOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetOperation.
+func (in *KlausFleetOperation) DeepCopy() *KlausFleetOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausFleetOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetOperationList) DeepCopyInto(out *KlausFleetOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausFleetOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetOperationList.
+func (in *KlausFleetOperationList) DeepCopy() *KlausFleetOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausFleetOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetOperationSpec) DeepCopyInto(out *KlausFleetOperationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetOperationSpec.
+func (in *KlausFleetOperationSpec) DeepCopy() *KlausFleetOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetOperationStatus) DeepCopyInto(out *KlausFleetOperationStatus) {
+	*out = *in
+	if in.InProgress != nil {
+		in, out := &in.InProgress, &out.InProgress
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Changed != nil {
+		in, out := &in.Changed, &out.Changed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]FleetOperationFailure, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetOperationStatus.
+func (in *KlausFleetOperationStatus) DeepCopy() *KlausFleetOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetStatus) DeepCopyInto(out *KlausFleetStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetStatus.
+func (in *KlausFleetStatus) DeepCopy() *KlausFleetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausFleetStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetStatusList) DeepCopyInto(out *KlausFleetStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausFleetStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetStatusList.
+func (in *KlausFleetStatusList) DeepCopy() *KlausFleetStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausFleetStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetStatusSpec) DeepCopyInto(out *KlausFleetStatusSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetStatusSpec.
+func (in *KlausFleetStatusSpec) DeepCopy() *KlausFleetStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausFleetStatusStatus) DeepCopyInto(out *KlausFleetStatusStatus) {
+	*out = *in
+	if in.ObservedAt != nil {
+		in, out := &in.ObservedAt, &out.ObservedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.InstanceStates != nil {
+		in, out := &in.InstanceStates, &out.InstanceStates
+		*out = make([]StateCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.JobStates != nil {
+		in, out := &in.JobStates, &out.JobStates
+		*out = make([]StateCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.ErrorReasons != nil {
+		in, out := &in.ErrorReasons, &out.ErrorReasons
+		*out = make([]ReasonCount, len(*in))
+		copy(*out, *in)
+	}
+	if in.OldestNotReady != nil {
+		in, out := &in.OldestNotReady, &out.OldestNotReady
+		*out = new(InstanceSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OCICache != nil {
+		in, out := &in.OCICache, &out.OCICache
+		*out = new(OCICacheStats)
+		**out = **in
+	}
+	if in.PersonalityRollouts != nil {
+		in, out := &in.PersonalityRollouts, &out.PersonalityRollouts
+		*out = make([]PersonalityRollout, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecentEvents != nil {
+		in, out := &in.RecentEvents, &out.RecentEvents
+		*out = make([]FleetEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausFleetStatusStatus.
+func (in *KlausFleetStatusStatus) DeepCopy() *KlausFleetStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausFleetStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausInstance) DeepCopyInto(out *KlausInstance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstance.
+func (in *KlausInstance) DeepCopy() *KlausInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausInstance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausInstanceList) DeepCopyInto(out *KlausInstanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceList.
+func (in *KlausInstanceList) DeepCopy() *KlausInstanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausInstanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausInstanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausInstanceSpec) DeepCopyInto(out *KlausInstanceSpec) {
+	*out = *in
+	if in.Owners != nil {
+		in, out := &in.Owners, &out.Owners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OwnerGroups != nil {
+		in, out := &in.OwnerGroups, &out.OwnerGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InlinePersonality != nil {
+		in, out := &in.InlinePersonality, &out.InlinePersonality
+		*out = new(InlinePersonality)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Claude.DeepCopyInto(&out.Claude)
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginDirs != nil {
+		in, out := &in.PluginDirs, &out.PluginDirs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MCPServers != nil {
+		in, out := &in.MCPServers, &out.MCPServers
+		*out = make([]MCPServerReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Skills != nil {
+		in, out := &in.Skills, &out.Skills
+		*out = make(map[string]SkillConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.AgentFiles != nil {
+		in, out := &in.AgentFiles, &out.AgentFiles
+		*out = make(map[string]AgentFileConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make(map[string]CommandConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.SkillPacks != nil {
+		in, out := &in.SkillPacks, &out.SkillPacks
+		*out = make([]SkillPackReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(MemoryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateValues != nil {
+		in, out := &in.TemplateValues, &out.TemplateValues
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.HookScripts != nil {
+		in, out := &in.HookScripts, &out.HookScripts
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AddDirs != nil {
+		in, out := &in.AddDirs, &out.AddDirs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadAdditionalDirsMemory != nil {
+		in, out := &in.LoadAdditionalDirsMemory, &out.LoadAdditionalDirsMemory
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Workspace != nil {
+		in, out := &in.Workspace, &out.Workspace
+		*out = new(WorkspaceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloneFrom != nil {
+		in, out := &in.CloneFrom, &out.CloneFrom
+		*out = new(CloneSource)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnvFrom != nil {
+		in, out := &in.ExtraEnvFrom, &out.ExtraEnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(NetworkConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Telemetry != nil {
+		in, out := &in.Telemetry, &out.Telemetry
+		*out = new(TelemetryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Muster != nil {
+		in, out := &in.Muster, &out.Muster
+		*out = new(MusterConfig)
+		**out = **in
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(GatewayConfig)
+		**out = **in
+	}
+	if in.Expose != nil {
+		in, out := &in.Expose, &out.Expose
+		*out = new(ExposeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(InstanceTLSConfig)
+		**out = **in
+	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(SchedulingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(ShutdownConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KubernetesAccess != nil {
+		in, out := &in.KubernetesAccess, &out.KubernetesAccess
+		*out = new(KubernetesAccess)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]InstanceDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]ReadinessGate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceSpec.
+func (in *KlausInstanceSpec) DeepCopy() *KlausInstanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausInstanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausInstanceStatus) DeepCopyInto(out *KlausInstanceStatus) {
+	*out = *in
+	if in.LastActivity != nil {
+		in, out := &in.LastActivity, &out.LastActivity
+		*out = (*in).DeepCopy()
+	}
+	if in.ResolvedPersonality != nil {
+		in, out := &in.ResolvedPersonality, &out.ResolvedPersonality
+		*out = new(ResolvedPersonality)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResolvedPlugins != nil {
+		in, out := &in.ResolvedPlugins, &out.ResolvedPlugins
+		*out = make([]ResolvedPlugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AgentCapabilities != nil {
+		in, out := &in.AgentCapabilities, &out.AgentCapabilities
+		*out = new(AgentCapabilities)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePlatforms != nil {
+		in, out := &in.ImagePlatforms, &out.ImagePlatforms
+		*out = new(ImagePlatforms)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceUsage != nil {
+		in, out := &in.ResourceUsage, &out.ResourceUsage
+		*out = new(ResourceUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Workspace != nil {
+		in, out := &in.Workspace, &out.Workspace
+		*out = new(WorkspaceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TokenUsage != nil {
+		in, out := &in.TokenUsage, &out.TokenUsage
+		*out = new(TokenUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]ReadinessGateStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = new(InstanceResult)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceStatus.
+func (in *KlausInstanceStatus) DeepCopy() *KlausInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausJob) DeepCopyInto(out *KlausJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausJob.
+func (in *KlausJob) DeepCopy() *KlausJob {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausJobList) DeepCopyInto(out *KlausJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausJobList.
+func (in *KlausJobList) DeepCopy() *KlausJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausJobSpec) DeepCopyInto(out *KlausJobSpec) {
+	*out = *in
+	if in.PromptTemplate != nil {
+		in, out := &in.PromptTemplate, &out.PromptTemplate
+		*out = new(PromptTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Claude.DeepCopyInto(&out.Claude)
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Workspace != nil {
+		in, out := &in.Workspace, &out.Workspace
+		*out = new(WorkspaceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Telemetry != nil {
+		in, out := &in.Telemetry, &out.Telemetry
+		*out = new(TelemetryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(JobRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationMessage != nil {
+		in, out := &in.TerminationMessage, &out.TerminationMessage
+		*out = new(TerminationMessageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausJobSpec.
+func (in *KlausJobSpec) DeepCopy() *KlausJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausJobStatus) DeepCopyInto(out *KlausJobStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(JobExitSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = new(JobOutputStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausJobStatus.
+func (in *KlausJobStatus) DeepCopy() *KlausJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausMCPServer) DeepCopyInto(out *KlausMCPServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServer.
+func (in *KlausMCPServer) DeepCopy() *KlausMCPServer {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausMCPServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausMCPServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausMCPServerList) DeepCopyInto(out *KlausMCPServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausMCPServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServerList.
+func (in *KlausMCPServerList) DeepCopy() *KlausMCPServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausMCPServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausMCPServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausMCPServerSpec) DeepCopyInto(out *KlausMCPServerSpec) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretRefs != nil {
+		in, out := &in.SecretRefs, &out.SecretRefs
+		*out = make([]MCPServerSecret, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServerSpec.
+func (in *KlausMCPServerSpec) DeepCopy() *KlausMCPServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausMCPServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausMCPServerStatus) DeepCopyInto(out *KlausMCPServerStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastProbeLatency != nil {
+		in, out := &in.LastProbeLatency, &out.LastProbeLatency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausMCPServerStatus.
+func (in *KlausMCPServerStatus) DeepCopy() *KlausMCPServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausMCPServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausQuota) DeepCopyInto(out *KlausQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausQuota.
+func (in *KlausQuota) DeepCopy() *KlausQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausQuotaList) DeepCopyInto(out *KlausQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausQuotaList.
+func (in *KlausQuotaList) DeepCopy() *KlausQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausQuotaSpec) DeepCopyInto(out *KlausQuotaSpec) {
+	*out = *in
+	if in.AnthropicAPI != nil {
+		in, out := &in.AnthropicAPI, &out.AnthropicAPI
+		*out = new(APIRateLimit)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausQuotaSpec.
+func (in *KlausQuotaSpec) DeepCopy() *KlausQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausSkillPack) DeepCopyInto(out *KlausSkillPack) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausSkillPack.
+func (in *KlausSkillPack) DeepCopy() *KlausSkillPack {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausSkillPack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausSkillPack) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausSkillPackList) DeepCopyInto(out *KlausSkillPackList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausSkillPack, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausSkillPackList.
+func (in *KlausSkillPackList) DeepCopy() *KlausSkillPackList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausSkillPackList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausSkillPackList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausSkillPackSpec) DeepCopyInto(out *KlausSkillPackSpec) {
+	*out = *in
+	if in.Skills != nil {
+		in, out := &in.Skills, &out.Skills
+		*out = make(map[string]SkillConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.AgentFiles != nil {
+		in, out := &in.AgentFiles, &out.AgentFiles
+		*out = make(map[string]AgentFileConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make(map[string]CommandConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausSkillPackSpec.
+func (in *KlausSkillPackSpec) DeepCopy() *KlausSkillPackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausSkillPackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausSkillPackStatus) DeepCopyInto(out *KlausSkillPackStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausSkillPackStatus.
+func (in *KlausSkillPackStatus) DeepCopy() *KlausSkillPackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausSkillPackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausTenant) DeepCopyInto(out *KlausTenant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausTenant.
+func (in *KlausTenant) DeepCopy() *KlausTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausTenant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausTenantList) DeepCopyInto(out *KlausTenantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausTenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausTenantList.
+func (in *KlausTenantList) DeepCopy() *KlausTenantList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausTenantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausTenantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausTenantSpec) DeepCopyInto(out *KlausTenantSpec) {
+	*out = *in
+	if in.MCP != nil {
+		in, out := &in.MCP, &out.MCP
+		*out = new(MCPRateLimit)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausTenantSpec.
+func (in *KlausTenantSpec) DeepCopy() *KlausTenantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausTenantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausToolchain) DeepCopyInto(out *KlausToolchain) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausToolchain.
+func (in *KlausToolchain) DeepCopy() *KlausToolchain {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausToolchain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausToolchain) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausToolchainList) DeepCopyInto(out *KlausToolchainList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausToolchain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausToolchainList.
+func (in *KlausToolchainList) DeepCopy() *KlausToolchainList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausToolchainList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausToolchainList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausToolchainSpec) DeepCopyInto(out *KlausToolchainSpec) {
+	*out = *in
+	if in.Personalities != nil {
+		in, out := &in.Personalities, &out.Personalities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausToolchainSpec.
+func (in *KlausToolchainSpec) DeepCopy() *KlausToolchainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausToolchainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausTrigger) DeepCopyInto(out *KlausTrigger) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausTrigger.
+func (in *KlausTrigger) DeepCopy() *KlausTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausTrigger) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausTriggerList) DeepCopyInto(out *KlausTriggerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausTrigger, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausTriggerList.
+func (in *KlausTriggerList) DeepCopy() *KlausTriggerList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausTriggerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausTriggerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausTriggerSpec) DeepCopyInto(out *KlausTriggerSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(TriggerJobAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Instance != nil {
+		in, out := &in.Instance, &out.Instance
+		*out = new(TriggerInstanceAction)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausTriggerSpec.
+func (in *KlausTriggerSpec) DeepCopy() *KlausTriggerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausTriggerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausTriggerStatus) DeepCopyInto(out *KlausTriggerStatus) {
+	*out = *in
+	if in.LastTriggerTime != nil {
+		in, out := &in.LastTriggerTime, &out.LastTriggerTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausTriggerStatus.
+func (in *KlausTriggerStatus) DeepCopy() *KlausTriggerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausTriggerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausUsageReport) DeepCopyInto(out *KlausUsageReport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausUsageReport.
+func (in *KlausUsageReport) DeepCopy() *KlausUsageReport {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausUsageReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausUsageReport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausUsageReportList) DeepCopyInto(out *KlausUsageReportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausUsageReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausUsageReportList.
+func (in *KlausUsageReportList) DeepCopy() *KlausUsageReportList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausUsageReportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausUsageReportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausUsageReportSpec) DeepCopyInto(out *KlausUsageReportSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausUsageReportSpec.
+func (in *KlausUsageReportSpec) DeepCopy() *KlausUsageReportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausUsageReportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausUsageReportStatus) DeepCopyInto(out *KlausUsageReportStatus) {
+	*out = *in
+	if in.Total != nil {
+		in, out := &in.Total, &out.Total
+		*out = new(TokenUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]InstanceTokenUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausUsageReportStatus.
+func (in *KlausUsageReportStatus) DeepCopy() *KlausUsageReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausUsageReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesAccess) DeepCopyInto(out *KubernetesAccess) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesAccess.
+func (in *KubernetesAccess) DeepCopy() *KubernetesAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPRateLimit) DeepCopyInto(out *MCPRateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPRateLimit.
+func (in *MCPRateLimit) DeepCopy() *MCPRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPServerReference) DeepCopyInto(out *MCPServerReference) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TimeoutMs != nil {
+		in, out := &in.TimeoutMs, &out.TimeoutMs
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPServerReference.
+func (in *MCPServerReference) DeepCopy() *MCPServerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPServerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MCPServerSecret) DeepCopyInto(out *MCPServerSecret) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPServerSecret.
+func (in *MCPServerSecret) DeepCopy() *MCPServerSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(MCPServerSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryConfig) DeepCopyInto(out *MemoryConfig) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryConfig.
+func (in *MemoryConfig) DeepCopy() *MemoryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MusterConfig) DeepCopyInto(out *MusterConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MusterConfig.
+func (in *MusterConfig) DeepCopy() *MusterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MusterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkConfig) DeepCopyInto(out *NetworkConfig) {
+	*out = *in
+	if in.ProxyCredentialsSecretRef != nil {
+		in, out := &in.ProxyCredentialsSecretRef, &out.ProxyCredentialsSecretRef
+		*out = new(ProxyCredentialsReference)
+		**out = **in
+	}
+	if in.CABundleConfigMapRef != nil {
+		in, out := &in.CABundleConfigMapRef, &out.CABundleConfigMapRef
+		*out = new(CABundleReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkConfig.
+func (in *NetworkConfig) DeepCopy() *NetworkConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCICacheStats) DeepCopyInto(out *OCICacheStats) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCICacheStats.
+func (in *OCICacheStats) DeepCopy() *OCICacheStats {
+	if in == nil {
+		return nil
+	}
+	out := new(OCICacheStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OTLPConfig) DeepCopyInto(out *OTLPConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OTLPConfig.
+func (in *OTLPConfig) DeepCopy() *OTLPConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OTLPConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectReadinessCheck) DeepCopyInto(out *ObjectReadinessCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectReadinessCheck.
+func (in *ObjectReadinessCheck) DeepCopy() *ObjectReadinessCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectReadinessCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersonalityRollout) DeepCopyInto(out *PersonalityRollout) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersonalityRollout.
+func (in *PersonalityRollout) DeepCopy() *PersonalityRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(PersonalityRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginReference) DeepCopyInto(out *PluginReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginReference.
+func (in *PluginReference) DeepCopy() *PluginReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeConfig) DeepCopyInto(out *ProbeConfig) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeConfig.
+func (in *ProbeConfig) DeepCopy() *ProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbesConfig) DeepCopyInto(out *ProbesConfig) {
+	*out = *in
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(ProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(ProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(ProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbesConfig.
+func (in *ProbesConfig) DeepCopy() *ProbesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PromptTemplate) DeepCopyInto(out *PromptTemplate) {
+	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PromptTemplate.
+func (in *PromptTemplate) DeepCopy() *PromptTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PromptTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderConfig) DeepCopyInto(out *ProviderConfig) {
+	*out = *in
+	if in.Bedrock != nil {
+		in, out := &in.Bedrock, &out.Bedrock
+		*out = new(BedrockConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Vertex != nil {
+		in, out := &in.Vertex, &out.Vertex
+		*out = new(VertexConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderConfig.
+func (in *ProviderConfig) DeepCopy() *ProviderConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderSecretReference) DeepCopyInto(out *ProviderSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderSecretReference.
+func (in *ProviderSecretReference) DeepCopy() *ProviderSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyCredentialsReference) DeepCopyInto(out *ProxyCredentialsReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyCredentialsReference.
+func (in *ProxyCredentialsReference) DeepCopy() *ProxyCredentialsReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyCredentialsReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessGate) DeepCopyInto(out *ReadinessGate) {
+	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPReadinessCheck)
+		**out = **in
+	}
+	if in.Object != nil {
+		in, out := &in.Object, &out.Object
+		*out = new(ObjectReadinessCheck)
+		**out = **in
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(ExecReadinessCheck)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessGate.
+func (in *ReadinessGate) DeepCopy() *ReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessGateStatus) DeepCopyInto(out *ReadinessGateStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessGateStatus.
+func (in *ReadinessGateStatus) DeepCopy() *ReadinessGateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessGateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReasonCount) DeepCopyInto(out *ReasonCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReasonCount.
+func (in *ReasonCount) DeepCopy() *ReasonCount {
 	if in == nil {
 		return nil
 	}
-	out := new(KlausMCPServerStatus)
+	out := new(ReasonCount)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MCPServerReference) DeepCopyInto(out *MCPServerReference) {
+func (in *ResolvedPersonality) DeepCopyInto(out *ResolvedPersonality) {
 	*out = *in
+	if in.ResolvedAt != nil {
+		in, out := &in.ResolvedAt, &out.ResolvedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPServerReference.
-func (in *MCPServerReference) DeepCopy() *MCPServerReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedPersonality.
+func (in *ResolvedPersonality) DeepCopy() *ResolvedPersonality {
 	if in == nil {
 		return nil
 	}
-	out := new(MCPServerReference)
+	out := new(ResolvedPersonality)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MCPServerSecret) DeepCopyInto(out *MCPServerSecret) {
+func (in *ResolvedPlugin) DeepCopyInto(out *ResolvedPlugin) {
 	*out = *in
-	if in.Env != nil {
-		in, out := &in.Env, &out.Env
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.ResolvedAt != nil {
+		in, out := &in.ResolvedAt, &out.ResolvedAt
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MCPServerSecret.
-func (in *MCPServerSecret) DeepCopy() *MCPServerSecret {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedPlugin.
+func (in *ResolvedPlugin) DeepCopy() *ResolvedPlugin {
 	if in == nil {
 		return nil
 	}
-	out := new(MCPServerSecret)
+	out := new(ResolvedPlugin)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MusterConfig) DeepCopyInto(out *MusterConfig) {
+func (in *ResourceUsage) DeepCopyInto(out *ResourceUsage) {
 	*out = *in
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.WorkspaceUsed != nil {
+		in, out := &in.WorkspaceUsed, &out.WorkspaceUsed
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.WorkspaceCapacity != nil {
+		in, out := &in.WorkspaceCapacity, &out.WorkspaceCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	in.ObservedAt.DeepCopyInto(&out.ObservedAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MusterConfig.
-func (in *MusterConfig) DeepCopy() *MusterConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceUsage.
+func (in *ResourceUsage) DeepCopy() *ResourceUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(MusterConfig)
+	out := new(ResourceUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OTLPConfig) DeepCopyInto(out *OTLPConfig) {
+func (in *SchedulingConfig) DeepCopyInto(out *SchedulingConfig) {
 	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DisruptionBudgetConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OTLPConfig.
-func (in *OTLPConfig) DeepCopy() *OTLPConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingConfig.
+func (in *SchedulingConfig) DeepCopy() *SchedulingConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(OTLPConfig)
+	out := new(SchedulingConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PluginReference) DeepCopyInto(out *PluginReference) {
+func (in *ShutdownConfig) DeepCopyInto(out *ShutdownConfig) {
 	*out = *in
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginReference.
-func (in *PluginReference) DeepCopy() *PluginReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShutdownConfig.
+func (in *ShutdownConfig) DeepCopy() *ShutdownConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(PluginReference)
+	out := new(ShutdownConfig)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -544,6 +2905,56 @@ func (in *SkillConfig) DeepCopy() *SkillConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkillPackReference) DeepCopyInto(out *SkillPackReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkillPackReference.
+func (in *SkillPackReference) DeepCopy() *SkillPackReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SkillPackReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StateCount) DeepCopyInto(out *StateCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StateCount.
+func (in *StateCount) DeepCopy() *StateCount {
+	if in == nil {
+		return nil
+	}
+	out := new(StateCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusLineConfig) DeepCopyInto(out *StatusLineConfig) {
+	*out = *in
+	if in.Padding != nil {
+		in, out := &in.Padding, &out.Padding
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusLineConfig.
+func (in *StatusLineConfig) DeepCopy() *StatusLineConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusLineConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TelemetryConfig) DeepCopyInto(out *TelemetryConfig) {
 	*out = *in
@@ -604,6 +3015,139 @@ func (in *TelemetryConfig) DeepCopy() *TelemetryConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TerminationMessageConfig) DeepCopyInto(out *TerminationMessageConfig) {
+	*out = *in
+	if in.ExitSummary != nil {
+		in, out := &in.ExitSummary, &out.ExitSummary
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TerminationMessageConfig.
+func (in *TerminationMessageConfig) DeepCopy() *TerminationMessageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TerminationMessageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TokenUsage) DeepCopyInto(out *TokenUsage) {
+	*out = *in
+	in.ObservedAt.DeepCopyInto(&out.ObservedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TokenUsage.
+func (in *TokenUsage) DeepCopy() *TokenUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(TokenUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerInstanceAction) DeepCopyInto(out *TriggerInstanceAction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerInstanceAction.
+func (in *TriggerInstanceAction) DeepCopy() *TriggerInstanceAction {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerInstanceAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerJobAction) DeepCopyInto(out *TriggerJobAction) {
+	*out = *in
+	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerJobAction.
+func (in *TriggerJobAction) DeepCopy() *TriggerJobAction {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerJobAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerSecretReference) DeepCopyInto(out *TriggerSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerSecretReference.
+func (in *TriggerSecretReference) DeepCopy() *TriggerSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerSource) DeepCopyInto(out *TriggerSource) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerSource.
+func (in *TriggerSource) DeepCopy() *TriggerSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VertexConfig) DeepCopyInto(out *VertexConfig) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(ProviderSecretReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VertexConfig.
+func (in *VertexConfig) DeepCopy() *VertexConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VertexConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceConfig) DeepCopyInto(out *WorkspaceConfig) {
 	*out = *in
@@ -612,11 +3156,44 @@ func (in *WorkspaceConfig) DeepCopyInto(out *WorkspaceConfig) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	in.GitCloneOptions.DeepCopyInto(&out.GitCloneOptions)
 	if in.GitSecretRef != nil {
 		in, out := &in.GitSecretRef, &out.GitSecretRef
 		*out = new(GitSecretReference)
 		**out = **in
 	}
+	if in.GitHubApp != nil {
+		in, out := &in.GitHubApp, &out.GitHubApp
+		*out = new(GitHubAppCredentials)
+		**out = **in
+	}
+	if in.Repos != nil {
+		in, out := &in.Repos, &out.Repos
+		*out = make([]WorkspaceRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SetupCommands != nil {
+		in, out := &in.SetupCommands, &out.SetupCommands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SetupTimeout != nil {
+		in, out := &in.SetupTimeout, &out.SetupTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Output != nil {
+		in, out := &in.Output, &out.Output
+		*out = new(WorkspaceOutput)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceConfig.
@@ -628,3 +3205,67 @@ func (in *WorkspaceConfig) DeepCopy() *WorkspaceConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceOutput) DeepCopyInto(out *WorkspaceOutput) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceOutput.
+func (in *WorkspaceOutput) DeepCopy() *WorkspaceOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceRepository) DeepCopyInto(out *WorkspaceRepository) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(GitSecretReference)
+		**out = **in
+	}
+	in.GitCloneOptions.DeepCopyInto(&out.GitCloneOptions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceRepository.
+func (in *WorkspaceRepository) DeepCopy() *WorkspaceRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceStatus) DeepCopyInto(out *WorkspaceStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Dirty != nil {
+		in, out := &in.Dirty, &out.Dirty
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceStatus.
+func (in *WorkspaceStatus) DeepCopy() *WorkspaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
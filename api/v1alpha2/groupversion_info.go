@@ -0,0 +1,32 @@
+// Package v1alpha2 contains API Schema definitions for the klaus v1alpha2 API group.
+// v1alpha2 tightens the loosely-typed fields of KlausInstance; objects are
+// stored as v1alpha1, the hub the conversion webhook converts through.
+// +kubebuilder:object:generate=true
+// +groupName=klaus.giantswarm.io
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupVersion is group version used to register these objects.
+	GroupVersion = schema.GroupVersion{Group: "klaus.giantswarm.io", Version: "v1alpha2"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionResource scheme.
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(GroupVersion,
+		&KlausInstance{},
+		&KlausInstanceList{},
+	)
+	metav1.AddToGroupVersion(scheme, GroupVersion)
+	return nil
+}
@@ -0,0 +1,68 @@
+package v1alpha2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// ConvertTo converts this KlausInstance to the v1alpha1 hub version.
+func (src *KlausInstance) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*klausv1alpha1.KlausInstance)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", dstRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+
+	// Fields whose JSON representation is the same in both versions are
+	// converted by decoding, the others are converted explicitly.
+	spec := src.Spec
+	spec.Claude.SettingSources = nil
+	dst.Spec = klausv1alpha1.KlausInstanceSpec{}
+	if err := decodeInto(&spec, &dst.Spec); err != nil {
+		return fmt.Errorf("converting spec of KlausInstance %s/%s: %w", src.Namespace, src.Name, err)
+	}
+	sources := make([]string, 0, len(src.Spec.Claude.SettingSources))
+	for _, source := range src.Spec.Claude.SettingSources {
+		sources = append(sources, string(source))
+	}
+	dst.Spec.Claude.SettingSources = strings.Join(sources, ",")
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 hub version to this KlausInstance.
+func (dst *KlausInstance) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*klausv1alpha1.KlausInstance)
+	if !ok {
+		return fmt.Errorf("unexpected hub type %T", srcRaw)
+	}
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Status = *src.Status.DeepCopy()
+
+	spec := src.Spec
+	spec.Claude.SettingSources = ""
+	dst.Spec = KlausInstanceSpec{}
+	if err := decodeInto(&spec, &dst.Spec); err != nil {
+		return fmt.Errorf("converting spec of KlausInstance %s/%s: %w", src.Namespace, src.Name, err)
+	}
+	for _, source := range strings.Split(src.Spec.Claude.SettingSources, ",") {
+		if source = strings.TrimSpace(source); source != "" {
+			dst.Spec.Claude.SettingSources = append(dst.Spec.Claude.SettingSources, SettingSource(source))
+		}
+	}
+	return nil
+}
+
+// decodeInto copies in to out through their JSON representation.
+func decodeInto(in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,113 @@
+package v1alpha2
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func hubInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system", Labels: map[string]string{"team": "platform"}},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "user@example.com",
+			Claude: klausv1alpha1.ClaudeConfig{
+				Model:          "claude-sonnet-4-5",
+				PermissionMode: klausv1alpha1.PermissionModeDefault,
+				Effort:         klausv1alpha1.EffortHigh,
+				SettingSources: "user,project",
+				Mode:           ptr.To("chat"),
+				MCPServers:     map[string]runtime.RawExtension{"github": {Raw: []byte(`{"url":"https://example.com/mcp"}`)}},
+			},
+			Plugins: []klausv1alpha1.PluginReference{{Repository: "registry.example.com/plugins/gs-base", Tag: "v1.2.0"}},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				Soul:    "You review code.",
+				Plugins: []klausv1alpha1.PluginReference{{Repository: "registry.example.com/plugins/review", Tag: "v1"}},
+			},
+			Workspace: &klausv1alpha1.WorkspaceConfig{GitRepo: "https://github.com/giantswarm/klaus-operator"},
+		},
+		Status: klausv1alpha1.KlausInstanceStatus{State: klausv1alpha1.InstanceStateRunning, Personality: "inline"},
+	}
+}
+
+func TestKlausInstanceConversion_RoundTrip(t *testing.T) {
+	hub := hubInstance()
+
+	var spoke KlausInstance
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if !slices.Equal(spoke.Spec.Claude.SettingSources, []SettingSource{SettingSourceUser, SettingSourceProject}) {
+		t.Errorf("settingSources = %v, want user and project", spoke.Spec.Claude.SettingSources)
+	}
+	if spoke.Spec.Claude.Mode != klausv1alpha1.InstanceModeChat {
+		t.Errorf("mode = %q, want chat", spoke.Spec.Claude.Mode)
+	}
+	if len(spoke.Spec.InlinePersonality.Plugins) != 1 || spoke.Spec.Workspace.GitRepo != hub.Spec.Workspace.GitRepo {
+		t.Errorf("spec = %+v, want the plugins and workspace of the hub", spoke.Spec)
+	}
+
+	var got klausv1alpha1.KlausInstance
+	if err := spoke.ConvertTo(&got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if !equality.Semantic.DeepEqual(&got, hub) {
+		t.Errorf("round trip = %+v, want %+v", got, hub)
+	}
+}
+
+func TestKlausInstanceConversion_SettingSources(t *testing.T) {
+	hub := hubInstance()
+	hub.Spec.Claude.SettingSources = " project , local,"
+
+	var spoke KlausInstance
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if !slices.Equal(spoke.Spec.Claude.SettingSources, []SettingSource{SettingSourceProject, SettingSourceLocal}) {
+		t.Errorf("settingSources = %v, want project and local", spoke.Spec.Claude.SettingSources)
+	}
+
+	spoke.Spec.Claude.SettingSources = nil
+	var got klausv1alpha1.KlausInstance
+	if err := spoke.ConvertTo(&got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if got.Spec.Claude.SettingSources != "" {
+		t.Errorf("settingSources = %q, want none", got.Spec.Claude.SettingSources)
+	}
+}
+
+// TestKlausInstanceConversion_Fields guards against fields added to one
+// version only, which the conversion would drop.
+func TestKlausInstanceConversion_Fields(t *testing.T) {
+	pairs := []struct{ hub, spoke any }{
+		{klausv1alpha1.KlausInstanceSpec{}, KlausInstanceSpec{}},
+		{klausv1alpha1.ClaudeConfig{}, ClaudeConfig{}},
+		{klausv1alpha1.InlinePersonality{}, InlinePersonality{}},
+		{klausv1alpha1.PluginReference{}, PluginReference{}},
+	}
+	for _, pair := range pairs {
+		hub, spoke := jsonFields(reflect.TypeOf(pair.hub)), jsonFields(reflect.TypeOf(pair.spoke))
+		if !slices.Equal(hub, spoke) {
+			t.Errorf("%T fields = %v, v1alpha1 fields = %v", pair.spoke, spoke, hub)
+		}
+	}
+}
+
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for field := range t.Fields() {
+		names = append(names, strings.Split(field.Tag.Get("json"), ",")[0])
+	}
+	slices.Sort(names)
+	return names
+}
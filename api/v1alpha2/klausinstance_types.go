@@ -0,0 +1,570 @@
+package v1alpha2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// KlausInstanceSpec defines the desired state of a KlausInstance.
+// +kubebuilder:validation:XValidation:rule="!(has(self.personality) && has(self.inlinePersonality))",message="personality and inlinePersonality are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.image) && size(self.image) > 0 && has(self.toolchainRef) && size(self.toolchainRef) > 0)",message="image and toolchainRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.cloneFrom) || !has(self.cloneFrom.workspace) || !self.cloneFrom.workspace || has(self.workspace)",message="cloneFrom.workspace requires workspace to be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.claude) || !has(self.claude.settingsFile) || size(self.claude.settingsFile) == 0 || ((!has(self.hooks) || size(self.hooks) == 0) && (!has(self.claude.outputStyle) || size(self.claude.outputStyle) == 0) && !has(self.claude.statusLine))",message="hooks, claude.outputStyle and claude.statusLine are rendered to settings.json and mutually exclusive with claude.settingsFile"
+// +kubebuilder:validation:XValidation:rule="!(has(self.desiredState) && self.desiredState == 'Running' && has(self.stopped) && self.stopped)",message="stopped contradicts desiredState Running; set desiredState only"
+type KlausInstanceSpec struct {
+	// Owner is the user identity (email) that owns this instance.
+	// Used for access control and namespace isolation.
+	// +kubebuilder:validation:MaxLength=254
+	// +kubebuilder:validation:XValidation:rule="self.matches('^[^@ ]+@[^@ ]+$')",message="owner must be an email address"
+	Owner string `json:"owner"`
+
+	// Owners lists additional user identities (emails) sharing the
+	// instance through the MCP server. The namespace is still derived from
+	// Owner, which remains the primary owner.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=64
+	// +kubebuilder:validation:items:MaxLength=254
+	// +kubebuilder:validation:XValidation:rule="self.all(o, o.matches('^[^@ ]+@[^@ ]+$'))",message="owners must be email addresses"
+	Owners []string `json:"owners,omitempty"`
+
+	// OwnerGroups lists groups, as found in the groups claim of the caller's
+	// token, whose members share the instance through the MCP server.
+	// +optional
+	// +listType=set
+	OwnerGroups []string `json:"ownerGroups,omitempty"`
+
+	// Personality is an OCI reference to a personality artifact that provides
+	// default configuration for this instance. The artifact must contain a
+	// personality.yaml file describing plugins, image overrides, and system prompts.
+	// Example: "gsoci.azurecr.io/giantswarm/personalities/go-dev:latest"
+	// +optional
+	Personality string `json:"personality,omitempty"`
+
+	// InlinePersonality defines a personality directly on the instance, for
+	// one-off experiments that do not warrant publishing an artifact. It is
+	// merged like a referenced personality and is mutually exclusive with
+	// Personality.
+	// +optional
+	InlinePersonality *InlinePersonality `json:"inlinePersonality,omitempty"`
+
+	// PersonalityUpdatePolicy controls when the instance picks up new
+	// revisions of its personality. Auto (default) resolves the reference on
+	// every reconcile; Manual keeps the revision recorded in
+	// status.personalityRevision until spec.personality or the
+	// klaus.giantswarm.io/personality-rollout annotation changes.
+	// +optional
+	PersonalityUpdatePolicy klausv1alpha1.PersonalityUpdatePolicy `json:"personalityUpdatePolicy,omitempty"`
+
+	// PersonalityRefreshPolicy controls when the tag the personality
+	// reference resolves to is resolved to a new digest. Pods always mount
+	// the digest recorded in status.resolvedPersonality. OnSpecChange
+	// (default) re-resolves it whenever the spec changes; Periodic also
+	// re-resolves the reference at the operator's personality resync
+	// interval, picking up new versions and re-pushed tags; Never keeps the
+	// recorded digest until the resolved reference changes.
+	// +optional
+	PersonalityRefreshPolicy klausv1alpha1.RefreshPolicy `json:"personalityRefreshPolicy,omitempty"`
+
+	// Image overrides the container image for this instance.
+	// Takes precedence over personality image and the operator default.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// ToolchainRef names a KlausToolchain in the operator namespace whose
+	// image the instance uses, like spec.image. Mutually exclusive with
+	// Image.
+	// +optional
+	ToolchainRef string `json:"toolchainRef,omitempty"`
+
+	// Claude contains all Claude Code agent configuration.
+	// +optional
+	Claude ClaudeConfig `json:"claude,omitempty"`
+
+	// Plugins defines OCI image references rendered as Kubernetes image volumes.
+	// +optional
+	Plugins []PluginReference `json:"plugins,omitempty"`
+
+	// PluginRefreshPolicy controls when tag-based plugin references are
+	// resolved to a new digest. Pods always mount the digest recorded in
+	// status.resolvedPlugins. OnSpecChange (default) re-resolves the tags
+	// whenever the spec changes; Periodic also re-resolves them at the
+	// operator's plugin refresh interval; Never keeps the recorded digest
+	// until the plugin reference itself changes.
+	// +optional
+	PluginRefreshPolicy klausv1alpha1.RefreshPolicy `json:"pluginRefreshPolicy,omitempty"`
+
+	// PluginDirs specifies additional user-provided plugin directory paths.
+	// These are merged with the OCI plugin mount paths into CLAUDE_PLUGIN_DIRS.
+	// +optional
+	PluginDirs []string `json:"pluginDirs,omitempty"`
+
+	// ImagePullSecrets specifies pull secrets for private registries used by plugins.
+	// These are set on the instance pod spec.
+	// +optional
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
+	// MCPServers references shared KlausMCPServer CRDs by name.
+	// +optional
+	MCPServers []klausv1alpha1.MCPServerReference `json:"mcpServers,omitempty"`
+
+	// Skills defines inline skill configurations rendered as SKILL.md files.
+	// +optional
+	Skills map[string]klausv1alpha1.SkillConfig `json:"skills,omitempty"`
+
+	// AgentFiles defines inline markdown-format subagent definitions.
+	// +optional
+	AgentFiles map[string]klausv1alpha1.AgentFileConfig `json:"agentFiles,omitempty"`
+
+	// Commands defines inline slash commands rendered as command files.
+	// +optional
+	Commands map[string]klausv1alpha1.CommandConfig `json:"commands,omitempty"`
+
+	// SkillPacks references KlausSkillPacks in the namespace of the instance
+	// whose skills, agent files and commands are added to the instance. An
+	// entry of spec.skills, spec.agentFiles or spec.commands with the same
+	// name takes precedence, as do earlier packs over later ones.
+	// +optional
+	SkillPacks []klausv1alpha1.SkillPackReference `json:"skillPacks,omitempty"`
+
+	// Memory defines CLAUDE.md memory files with standing instructions for
+	// the agent.
+	// +optional
+	Memory *klausv1alpha1.MemoryConfig `json:"memory,omitempty"`
+
+	// TemplateValues are substituted for ${name} placeholders in the system
+	// prompts, skills, commands, memory and soul. The built-in
+	// ${klaus.owner}, ${klaus.instance}, ${klaus.namespace} and
+	// ${klaus.repo} are always available.
+	// +optional
+	TemplateValues map[string]string `json:"templateValues,omitempty"`
+
+	// Hooks defines lifecycle hooks rendered to settings.json.
+	// Mutually exclusive with Claude.SettingsFile.
+	// +optional
+	Hooks map[string]runtime.RawExtension `json:"hooks,omitempty"`
+
+	// HookScripts defines executable scripts mounted at /etc/klaus/hooks/.
+	// +optional
+	HookScripts map[string]string `json:"hookScripts,omitempty"`
+
+	// AddDirs specifies additional directories to load.
+	// +optional
+	AddDirs []string `json:"addDirs,omitempty"`
+
+	// LoadAdditionalDirsMemory enables CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD.
+	// +optional
+	LoadAdditionalDirsMemory *bool `json:"loadAdditionalDirsMemory,omitempty"`
+
+	// Workspace configures persistent storage for the instance.
+	// +optional
+	Workspace *klausv1alpha1.WorkspaceConfig `json:"workspace,omitempty"`
+
+	// CloneFrom records the instance this one was cloned from, e.g. by the
+	// clone_instance MCP tool, and optionally copies its workspace.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="cloneFrom is immutable"
+	CloneFrom *klausv1alpha1.CloneSource `json:"cloneFrom,omitempty"`
+
+	// Resources specifies compute resource requirements for the instance pod.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// ExtraEnv are additional environment variables of the Klaus container,
+	// e.g. proxy settings or PIP_INDEX_URL. Names starting with KLAUS_ or
+	// CLAUDE_ are reserved; variables the operator sets take precedence.
+	// Referenced Secrets and ConfigMaps must exist in the namespace the pod
+	// runs in.
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// ExtraEnvFrom populates environment variables of the Klaus container
+	// from Secrets or ConfigMaps in the namespace the pod runs in. Prefixes
+	// starting with KLAUS_ or CLAUDE_ are reserved.
+	// +optional
+	ExtraEnvFrom []corev1.EnvFromSource `json:"extraEnvFrom,omitempty"`
+
+	// Network routes the egress of the instance through an HTTP proxy and
+	// trusts a custom CA bundle, in the Klaus container and the workspace
+	// init containers.
+	// +optional
+	Network *klausv1alpha1.NetworkConfig `json:"network,omitempty"`
+
+	// Telemetry configures OpenTelemetry and Prometheus metrics.
+	// +optional
+	Telemetry *klausv1alpha1.TelemetryConfig `json:"telemetry,omitempty"`
+
+	// Muster configures MCPServer CRD registration in the muster namespace.
+	// +optional
+	Muster *klausv1alpha1.MusterConfig `json:"muster,omitempty"`
+
+	// Gateway selects how the instance is registered with an MCP gateway.
+	// +optional
+	Gateway *klausv1alpha1.GatewayConfig `json:"gateway,omitempty"`
+
+	// Expose makes the instance reachable from outside the cluster through
+	// an Ingress or a Gateway API HTTPRoute in the user namespace.
+	// +optional
+	Expose *klausv1alpha1.ExposeConfig `json:"expose,omitempty"`
+
+	// TLS serves the instance Service over mutual TLS. A per-instance
+	// serving certificate is mounted into the pod and muster is registered
+	// with a client certificate from the same issuer.
+	// +optional
+	TLS *klausv1alpha1.InstanceTLSConfig `json:"tls,omitempty"`
+
+	// Scheduling controls where the instance pod runs and protects it from
+	// voluntary disruptions such as node drains.
+	// +optional
+	Scheduling *klausv1alpha1.SchedulingConfig `json:"scheduling,omitempty"`
+
+	// Probes tunes the liveness, readiness and startup probes of the Klaus
+	// container, e.g. a longer startup for toolchain images loading many
+	// plugins.
+	// +optional
+	Probes *klausv1alpha1.ProbesConfig `json:"probes,omitempty"`
+
+	// UpdateStrategy replaces the instance pod by stopping the old pod
+	// first (Recreate) or starting the new pod first (RollingUpdate).
+	// Defaults to Recreate when the workspace PVC is ReadWriteOnce or
+	// ReadWriteOncePod, whose volume the new pod could not mount while the
+	// old pod holds it, and to RollingUpdate otherwise.
+	// +optional
+	UpdateStrategy klausv1alpha1.UpdateStrategyType `json:"updateStrategy,omitempty"`
+
+	// Shutdown configures how the instance pod stops on rollouts and
+	// deletion, so persistent agents can finish their current turn.
+	// +optional
+	Shutdown *klausv1alpha1.ShutdownConfig `json:"shutdown,omitempty"`
+
+	// Sandbox selects a hardening preset for the instance pod. Each preset
+	// bundles a RuntimeClass, seccomp profile, root filesystem and network
+	// restrictions as configured on the operator. Defaults to none.
+	// +optional
+	Sandbox klausv1alpha1.SandboxProfile `json:"sandbox,omitempty"`
+
+	// KubernetesAccess gives the agent access to the Kubernetes API as the
+	// instance ServiceAccount. Without it no ServiceAccount token is mounted
+	// into the pod.
+	// +optional
+	KubernetesAccess *klausv1alpha1.KubernetesAccess `json:"kubernetesAccess,omitempty"`
+
+	// MockMode runs the instance against a mock agent instead of Claude, for
+	// developing personalities without spending Anthropic credits. No API key
+	// or provider credentials are required, the operator's mock agent image
+	// replaces the instance, personality and toolchain images, and the
+	// instance is marked as non-production in its status and muster
+	// registration.
+	// +optional
+	MockMode bool `json:"mockMode,omitempty"`
+
+	// DependsOn lists KlausInstances of the same owner this instance works
+	// with, e.g. a researcher feeding an implementer. The instance does not
+	// start until every dependency is Running. Each dependency's endpoint is
+	// injected as a KLAUS_DEPENDENCY_<NAME>_URL environment variable and as an
+	// MCP server named after the dependency.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	DependsOn []klausv1alpha1.InstanceDependency `json:"dependsOn,omitempty"`
+
+	// ReadinessGates are checks on external dependencies, such as a
+	// database an MCP server fronts, the instance is not Ready without.
+	// Once its pod is available the controller evaluates every gate on each
+	// reconcile; the instance stays Pending with Ready False until all
+	// pass, and the result of each gate is reported in status.readinessGates.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=16
+	ReadinessGates []klausv1alpha1.ReadinessGate `json:"readinessGates,omitempty"`
+
+	// DesiredState is the lifecycle state the instance should be in. With
+	// Stopped the controller scales the Deployment to zero replicas and the
+	// instance status transitions to Stopped, keeping its PVCs and
+	// configuration; with Running, the default, it scales the Deployment
+	// back to 1 replica.
+	// +optional
+	DesiredState klausv1alpha1.DesiredState `json:"desiredState,omitempty"`
+
+	// Stopped stops the instance like desiredState Stopped.
+	//
+	// Deprecated: set desiredState instead. The instance is stopped when
+	// either field stops it.
+	// +optional
+	Stopped bool `json:"stopped"`
+}
+
+// SettingSource is a settings source Claude Code loads settings from.
+// +kubebuilder:validation:Enum=user;project;local
+type SettingSource string
+
+const (
+	// SettingSourceUser loads the user settings.
+	SettingSourceUser SettingSource = "user"
+
+	// SettingSourceProject loads the settings checked into the workspace.
+	SettingSourceProject SettingSource = "project"
+
+	// SettingSourceLocal loads the local, untracked settings of the
+	// workspace.
+	SettingSourceLocal SettingSource = "local"
+)
+
+// ClaudeConfig contains all Claude Code agent configuration options.
+// This mirrors the Helm chart's claude.* values.
+type ClaudeConfig struct {
+	// Model specifies the Claude model to use.
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// MaxTurns limits the number of agentic turns. 0 means unlimited.
+	// +optional
+	MaxTurns *int `json:"maxTurns,omitempty"`
+
+	// PermissionMode controls tool permission handling. When unset, the
+	// operator's permission policy picks the default for the creator's
+	// groups; objects created before the policy existed keep the
+	// bypassPermissions value the CRD used to default to.
+	// +optional
+	PermissionMode klausv1alpha1.PermissionMode `json:"permissionMode,omitempty"`
+
+	// PermissionModeOverride lets PermissionMode exceed the most permissive
+	// mode the permission policy allows the creator's groups. Only members
+	// of the policy's admin groups may set it.
+	// +optional
+	PermissionModeOverride bool `json:"permissionModeOverride,omitempty"`
+
+	// SystemPrompt overrides the default system prompt.
+	// +optional
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+
+	// AppendSystemPrompt appends text to the default system prompt.
+	// +optional
+	AppendSystemPrompt string `json:"appendSystemPrompt,omitempty"`
+
+	// MCPServers defines inline MCP server configuration (free-form map rendered to .mcp.json).
+	// +optional
+	MCPServers map[string]runtime.RawExtension `json:"mcpServers,omitempty"`
+
+	// MCPServerSecrets defines secret references for ${VAR} expansion in MCP config.
+	// +optional
+	MCPServerSecrets []klausv1alpha1.MCPServerSecret `json:"mcpServerSecrets,omitempty"`
+
+	// MCPTimeout sets the MCP_TIMEOUT env var (milliseconds).
+	// +optional
+	MCPTimeout *int `json:"mcpTimeout,omitempty"`
+
+	// MaxMCPOutputTokens sets MAX_MCP_OUTPUT_TOKENS.
+	// +optional
+	MaxMCPOutputTokens *int `json:"maxMcpOutputTokens,omitempty"`
+
+	// StrictMCPConfig prevents loading MCP configs from user/project/local sources.
+	// +kubebuilder:default=true
+	// +optional
+	StrictMCPConfig *bool `json:"strictMcpConfig,omitempty"`
+
+	// MaxBudgetUSD sets the maximum spend per session in USD. The operator
+	// also suspends the instance once status.tokenUsage.costUSD, its spend
+	// since creation, reaches it.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxBudgetUSD *float64 `json:"maxBudgetUSD,omitempty"`
+
+	// Effort controls thinking effort level (low, medium, high).
+	// +optional
+	Effort klausv1alpha1.EffortLevel `json:"effort,omitempty"`
+
+	// FallbackModel specifies a fallback model if the primary is unavailable.
+	// +optional
+	FallbackModel string `json:"fallbackModel,omitempty"`
+
+	// JSONSchema defines structured output schema.
+	// +optional
+	JSONSchema string `json:"jsonSchema,omitempty"`
+
+	// SettingsFile path to a custom settings file. Mutually exclusive with
+	// Hooks, OutputStyle and StatusLine.
+	// +optional
+	SettingsFile string `json:"settingsFile,omitempty"`
+
+	// OutputStyle selects the output style of the agent, a built-in style
+	// like Explanatory or the name of a custom one. Rendered to
+	// settings.json.
+	// +optional
+	OutputStyle string `json:"outputStyle,omitempty"`
+
+	// StatusLine configures the status line of the agent. Rendered to
+	// settings.json.
+	// +optional
+	StatusLine *klausv1alpha1.StatusLineConfig `json:"statusLine,omitempty"`
+
+	// SettingSources lists the settings sources that are loaded. v1alpha1
+	// takes them as a comma-separated string.
+	// +optional
+	// +listType=set
+	SettingSources []SettingSource `json:"settingSources,omitempty"`
+
+	// Tools specifies tools to enable.
+	// +optional
+	Tools []string `json:"tools,omitempty"`
+
+	// AllowedTools restricts which tools can be used.
+	// +optional
+	AllowedTools []string `json:"allowedTools,omitempty"`
+
+	// DisallowedTools prevents specific tools from being used.
+	// +optional
+	DisallowedTools []string `json:"disallowedTools,omitempty"`
+
+	// Agents defines JSON-format subagent configurations.
+	// +optional
+	Agents map[string]runtime.RawExtension `json:"agents,omitempty"`
+
+	// ActiveAgent selects the top-level agent.
+	// +optional
+	ActiveAgent string `json:"activeAgent,omitempty"`
+
+	// Mode selects the instance process mode.
+	// "agent" (default): autonomous coding, new process per prompt, no session persistence.
+	// "chat": interactive conversation, persistent process, sessions saved.
+	// +optional
+	Mode klausv1alpha1.InstanceMode `json:"mode,omitempty"`
+
+	// IncludePartialMessages enables streaming partial messages.
+	// +optional
+	IncludePartialMessages *bool `json:"includePartialMessages,omitempty"`
+
+	// Provider selects the model provider. Defaults to the Anthropic API.
+	// +optional
+	Provider *klausv1alpha1.ProviderConfig `json:"provider,omitempty"`
+}
+
+// InlinePersonality is a personality defined on the instance itself. It
+// mirrors the contents of a personality artifact. Instance-level settings
+// take precedence over the personality ones.
+type InlinePersonality struct {
+	// Description is a human-readable description of the personality.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Soul is the content of the SOUL.md file describing the agent's
+	// identity and behaviour.
+	// +optional
+	Soul string `json:"soul,omitempty"`
+
+	// Image is the toolchain container image, used when spec.image is not set.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Plugins are added to spec.plugins. A plugin in spec.plugins with the
+	// same repository takes precedence.
+	// +optional
+	Plugins []PluginReference `json:"plugins,omitempty"`
+
+	// Skills are added to spec.skills. A skill in spec.skills with the same
+	// name takes precedence.
+	// +optional
+	Skills map[string]klausv1alpha1.SkillConfig `json:"skills,omitempty"`
+
+	// Commands are added to spec.commands. A command in spec.commands with
+	// the same name takes precedence.
+	// +optional
+	Commands map[string]klausv1alpha1.CommandConfig `json:"commands,omitempty"`
+
+	// SkillPacks are added to spec.skillPacks after the packs referenced by
+	// the instance.
+	// +optional
+	SkillPacks []klausv1alpha1.SkillPackReference `json:"skillPacks,omitempty"`
+
+	// Memory is merged into spec.memory: its content is placed before that
+	// of spec.memory, and a file in spec.memory.files with the same path
+	// takes precedence.
+	// +optional
+	Memory *klausv1alpha1.MemoryConfig `json:"memory,omitempty"`
+
+	// TemplateValues are the defaults of spec.templateValues. A value in
+	// spec.templateValues with the same name takes precedence.
+	// +optional
+	TemplateValues map[string]string `json:"templateValues,omitempty"`
+
+	// Probes apply to the probes not tuned by spec.probes.
+	// +optional
+	Probes *klausv1alpha1.ProbesConfig `json:"probes,omitempty"`
+
+	// ExtraEnv is added to spec.extraEnv. A variable in spec.extraEnv with
+	// the same name takes precedence.
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// ExtraEnvFrom is added before spec.extraEnvFrom, whose sources take
+	// precedence for keys in both.
+	// +optional
+	ExtraEnvFrom []corev1.EnvFromSource `json:"extraEnvFrom,omitempty"`
+
+	// Scheduling is merged into spec.scheduling: node selector labels and
+	// tolerations are added, and the other fields apply when
+	// spec.scheduling does not set them.
+	// +optional
+	Scheduling *klausv1alpha1.SchedulingConfig `json:"scheduling,omitempty"`
+}
+
+// PluginReference defines an OCI image reference for a Klaus plugin.
+// +kubebuilder:validation:XValidation:rule="!(has(self.tag) && has(self.digest))",message="tag and digest are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="has(self.tag) || has(self.digest)",message="must specify either tag or digest"
+type PluginReference struct {
+	// Repository is the OCI image repository.
+	// +kubebuilder:validation:MinLength=1
+	Repository string `json:"repository"`
+
+	// Tag is the image tag. Mutually exclusive with Digest.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest is the image digest (sha256:...). Mutually exclusive with Tag.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.status.mode`
+// +kubebuilder:printcolumn:name="Owner",type=string,JSONPath=`.spec.owner`
+// +kubebuilder:printcolumn:name="Personality",type=string,JSONPath=`.status.personality`
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.status.endpoint`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Toolchain",type=string,JSONPath=`.status.toolchain`,priority=1
+// +kubebuilder:printcolumn:name="Cost USD",type=string,JSONPath=`.status.tokenUsage.costUSD`,priority=1
+// +kubebuilder:printcolumn:name="Budget USD",type=number,JSONPath=`.spec.claude.maxBudgetUSD`,priority=1
+// +kubebuilder:printcolumn:name="MCP Servers",type=string,JSONPath=`.status.conditions[?(@.type=="MCPServerReady")].status`,priority=1
+// +kubebuilder:printcolumn:name="Plugins",type=integer,JSONPath=`.status.pluginCount`,priority=1
+// +kubebuilder:printcolumn:name="Repo",type=string,JSONPath=`.spec.workspace.gitRepo`,priority=1
+// +kubebuilder:printcolumn:name="Mock",type=boolean,JSONPath=`.status.mock`,priority=1
+// +kubebuilder:resource:shortName=ki,categories=klaus
+// +kubebuilder:unservedversion
+
+// KlausInstance is the Schema for the klausinstances API.
+// It represents a running Klaus agent instance with its configuration.
+type KlausInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KlausInstanceSpec                 `json:"spec,omitempty"`
+	Status klausv1alpha1.KlausInstanceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KlausInstanceList contains a list of KlausInstance.
+type KlausInstanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KlausInstance `json:"items"`
+}
@@ -0,0 +1,462 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"github.com/giantswarm/klaus-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClaudeConfig) DeepCopyInto(out *ClaudeConfig) {
+	*out = *in
+	if in.MaxTurns != nil {
+		in, out := &in.MaxTurns, &out.MaxTurns
+		*out = new(int)
+		**out = **in
+	}
+	if in.MCPServers != nil {
+		in, out := &in.MCPServers, &out.MCPServers
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.MCPServerSecrets != nil {
+		in, out := &in.MCPServerSecrets, &out.MCPServerSecrets
+		*out = make([]v1alpha1.MCPServerSecret, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MCPTimeout != nil {
+		in, out := &in.MCPTimeout, &out.MCPTimeout
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxMCPOutputTokens != nil {
+		in, out := &in.MaxMCPOutputTokens, &out.MaxMCPOutputTokens
+		*out = new(int)
+		**out = **in
+	}
+	if in.StrictMCPConfig != nil {
+		in, out := &in.StrictMCPConfig, &out.StrictMCPConfig
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxBudgetUSD != nil {
+		in, out := &in.MaxBudgetUSD, &out.MaxBudgetUSD
+		*out = new(float64)
+		**out = **in
+	}
+	if in.StatusLine != nil {
+		in, out := &in.StatusLine, &out.StatusLine
+		*out = new(v1alpha1.StatusLineConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SettingSources != nil {
+		in, out := &in.SettingSources, &out.SettingSources
+		*out = make([]SettingSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedTools != nil {
+		in, out := &in.AllowedTools, &out.AllowedTools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisallowedTools != nil {
+		in, out := &in.DisallowedTools, &out.DisallowedTools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Agents != nil {
+		in, out := &in.Agents, &out.Agents
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.IncludePartialMessages != nil {
+		in, out := &in.IncludePartialMessages, &out.IncludePartialMessages
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(v1alpha1.ProviderConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaudeConfig.
+func (in *ClaudeConfig) DeepCopy() *ClaudeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClaudeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InlinePersonality) DeepCopyInto(out *InlinePersonality) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Skills != nil {
+		in, out := &in.Skills, &out.Skills
+		*out = make(map[string]v1alpha1.SkillConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make(map[string]v1alpha1.CommandConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.SkillPacks != nil {
+		in, out := &in.SkillPacks, &out.SkillPacks
+		*out = make([]v1alpha1.SkillPackReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(v1alpha1.MemoryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateValues != nil {
+		in, out := &in.TemplateValues, &out.TemplateValues
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(v1alpha1.ProbesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnvFrom != nil {
+		in, out := &in.ExtraEnvFrom, &out.ExtraEnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(v1alpha1.SchedulingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InlinePersonality.
+func (in *InlinePersonality) DeepCopy() *InlinePersonality {
+	if in == nil {
+		return nil
+	}
+	out := new(InlinePersonality)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausInstance) DeepCopyInto(out *KlausInstance) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstance.
+func (in *KlausInstance) DeepCopy() *KlausInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausInstance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausInstanceList) DeepCopyInto(out *KlausInstanceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]KlausInstance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceList.
+func (in *KlausInstanceList) DeepCopy() *KlausInstanceList {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausInstanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *KlausInstanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlausInstanceSpec) DeepCopyInto(out *KlausInstanceSpec) {
+	*out = *in
+	if in.Owners != nil {
+		in, out := &in.Owners, &out.Owners
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.OwnerGroups != nil {
+		in, out := &in.OwnerGroups, &out.OwnerGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InlinePersonality != nil {
+		in, out := &in.InlinePersonality, &out.InlinePersonality
+		*out = new(InlinePersonality)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Claude.DeepCopyInto(&out.Claude)
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]PluginReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginDirs != nil {
+		in, out := &in.PluginDirs, &out.PluginDirs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MCPServers != nil {
+		in, out := &in.MCPServers, &out.MCPServers
+		*out = make([]v1alpha1.MCPServerReference, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Skills != nil {
+		in, out := &in.Skills, &out.Skills
+		*out = make(map[string]v1alpha1.SkillConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.AgentFiles != nil {
+		in, out := &in.AgentFiles, &out.AgentFiles
+		*out = make(map[string]v1alpha1.AgentFileConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make(map[string]v1alpha1.CommandConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.SkillPacks != nil {
+		in, out := &in.SkillPacks, &out.SkillPacks
+		*out = make([]v1alpha1.SkillPackReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(v1alpha1.MemoryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateValues != nil {
+		in, out := &in.TemplateValues, &out.TemplateValues
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.HookScripts != nil {
+		in, out := &in.HookScripts, &out.HookScripts
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AddDirs != nil {
+		in, out := &in.AddDirs, &out.AddDirs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LoadAdditionalDirsMemory != nil {
+		in, out := &in.LoadAdditionalDirsMemory, &out.LoadAdditionalDirsMemory
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Workspace != nil {
+		in, out := &in.Workspace, &out.Workspace
+		*out = new(v1alpha1.WorkspaceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloneFrom != nil {
+		in, out := &in.CloneFrom, &out.CloneFrom
+		*out = new(v1alpha1.CloneSource)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnvFrom != nil {
+		in, out := &in.ExtraEnvFrom, &out.ExtraEnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(v1alpha1.NetworkConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Telemetry != nil {
+		in, out := &in.Telemetry, &out.Telemetry
+		*out = new(v1alpha1.TelemetryConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Muster != nil {
+		in, out := &in.Muster, &out.Muster
+		*out = new(v1alpha1.MusterConfig)
+		**out = **in
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(v1alpha1.GatewayConfig)
+		**out = **in
+	}
+	if in.Expose != nil {
+		in, out := &in.Expose, &out.Expose
+		*out = new(v1alpha1.ExposeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(v1alpha1.InstanceTLSConfig)
+		**out = **in
+	}
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(v1alpha1.SchedulingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(v1alpha1.ProbesConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(v1alpha1.ShutdownConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KubernetesAccess != nil {
+		in, out := &in.KubernetesAccess, &out.KubernetesAccess
+		*out = new(v1alpha1.KubernetesAccess)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]v1alpha1.InstanceDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadinessGates != nil {
+		in, out := &in.ReadinessGates, &out.ReadinessGates
+		*out = make([]v1alpha1.ReadinessGate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlausInstanceSpec.
+func (in *KlausInstanceSpec) DeepCopy() *KlausInstanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KlausInstanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginReference) DeepCopyInto(out *PluginReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginReference.
+func (in *PluginReference) DeepCopy() *PluginReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginReference)
+	in.DeepCopyInto(out)
+	return out
+}
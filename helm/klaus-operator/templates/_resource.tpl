@@ -14,3 +14,52 @@ room for such suffix.
 {{- define "resource.default.namespace" -}}
 {{ .Release.Namespace }}
 {{- end -}}
+
+{{/*
+ClusterRole bound to instance owners in their namespace: the configured
+ownerAccess.clusterRole, or the chart-managed read-only role.
+*/}}
+{{- define "resource.owner.clusterRole" -}}
+{{- .Values.ownerAccess.clusterRole | default (printf "%s-owner" (include "resource.default.name" .)) -}}
+{{- end -}}
+
+{{/*
+Whether instance owners are bound to a ClusterRole in their namespace.
+Namespace-scoped mode has no per-owner namespaces to bind them in.
+*/}}
+{{- define "resource.owner.enabled" -}}
+{{- if and .Values.ownerAccess.enabled (not .Values.namespaceScoped) }}true{{ end -}}
+{{- end -}}
+
+{{/*
+ClusterRole bound to the tenant ServiceAccount of each user namespace in
+impersonation mode: the configured impersonation.clusterRole, or the
+chart-managed role covering the child resources.
+*/}}
+{{- define "resource.tenant.clusterRole" -}}
+{{- .Values.impersonation.clusterRole | default (printf "%s-tenant" (include "resource.default.name" .)) -}}
+{{- end -}}
+
+{{/*
+Name of the admission webhook configurations, certificate and issuer.
+*/}}
+{{- define "resource.webhook.name" -}}
+{{- printf "%s-webhook" (include "resource.default.name" .) -}}
+{{- end -}}
+
+{{/*
+Secret holding the MCP serving certificate: the configured
+mcp.tls.secretName, or the Secret of the chart-managed cert-manager
+Certificate.
+*/}}
+{{- define "resource.mcp.tlsSecret" -}}
+{{- .Values.mcp.tls.secretName | default (printf "%s-mcp-tls" (include "resource.default.name" .)) -}}
+{{- end -}}
+
+{{/*
+Whether any volume is mounted into the operator container.
+*/}}
+{{- define "resource.operator.volumes" -}}
+{{- if or .Values.ociCache.enabled .Values.permissionPolicy .Values.webhook.enabled .Values.mcp.tls.enabled .Values.githubApp.appID }}true{{ end -}}
+{{- end -}}
+
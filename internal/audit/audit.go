@@ -0,0 +1,191 @@
+// Package audit implements the append-only audit stream of MCP tool
+// invocations and controller actions. Records are buffered by a Logger and
+// written in batches to its sinks, such as a JSON lines file or an OTLP
+// logs endpoint, so compliance teams can reconstruct who created or deleted
+// which agent and when.
+package audit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+)
+
+// Sources of audit records.
+const (
+	SourceMCP        = "mcp"
+	SourceController = "controller"
+)
+
+// Results of audited actions.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+const (
+	// bufferSize is the number of records buffered before Record blocks.
+	bufferSize = 1024
+
+	// enqueueTimeout is how long Record waits for room in a full buffer
+	// before it drops the record.
+	enqueueTimeout = 100 * time.Millisecond
+
+	// maxBatch is the largest number of records written to the sinks at
+	// once.
+	maxBatch = 100
+
+	// writeTimeout bounds a batch write to a sink.
+	writeTimeout = 10 * time.Second
+)
+
+// Record is an audited action.
+type Record struct {
+	// Time is when the action happened.
+	Time time.Time `json:"time"`
+
+	// Source is SourceMCP or SourceController.
+	Source string `json:"source"`
+
+	// Actor is the identity that performed the action: the MCP caller, or
+	// the controller for controller actions.
+	Actor string `json:"actor"`
+
+	// Action is the MCP tool or the event reason of a controller action.
+	Action string `json:"action"`
+
+	// Resource is the object acted on, if any.
+	Resource *Resource `json:"resource,omitempty"`
+
+	// Result is ResultSuccess or ResultFailure.
+	Result string `json:"result"`
+
+	// Message describes the action or its failure.
+	Message string `json:"message,omitempty"`
+
+	// Session is the MCP client session of the request, if any.
+	Session string `json:"session,omitempty"`
+}
+
+// Resource identifies an audited object.
+type Resource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// Sink stores audit records.
+type Sink interface {
+	Write(ctx context.Context, records []Record) error
+}
+
+// Logger buffers audit records and writes them to its sinks in the
+// background. It implements manager.Runnable; records are only written
+// while it runs. A nil *Logger discards records.
+type Logger struct {
+	sinks   []Sink
+	records chan Record
+	dropped atomic.Int64
+
+	// reported is the number of dropped records logged by Start.
+	reported int64
+}
+
+// NewLogger returns a logger writing to sinks.
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks, records: make(chan Record, bufferSize)}
+}
+
+// Record queues a record. While the buffer is full it waits up to
+// enqueueTimeout for room, then drops the record and counts it in the
+// dropped records metric, so a sink that is down delays tool calls and
+// reconciles by at most that long. Start logs the drops.
+func (l *Logger) Record(record Record) {
+	if l == nil {
+		return
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now().UTC()
+	}
+	select {
+	case l.records <- record:
+		return
+	default:
+	}
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+	select {
+	case l.records <- record:
+	case <-timer.C:
+		l.dropped.Add(1)
+		metrics.AuditRecordsDropped.WithLabelValues(record.Source).Inc()
+	}
+}
+
+// Dropped returns the number of records dropped because the buffer was
+// full.
+func (l *Logger) Dropped() int64 {
+	return l.dropped.Load()
+}
+
+// Start writes queued records until ctx is cancelled, then flushes the
+// records still buffered.
+func (l *Logger) Start(ctx context.Context) error {
+	for {
+		select {
+		case record := <-l.records:
+			l.write(context.WithoutCancel(ctx), l.batch(record))
+		case <-ctx.Done():
+			for {
+				select {
+				case record := <-l.records:
+					l.write(context.WithoutCancel(ctx), l.batch(record))
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false: every replica audits the MCP calls it
+// serves.
+func (l *Logger) NeedLeaderElection() bool {
+	return false
+}
+
+// batch returns first and the records queued behind it, up to maxBatch.
+func (l *Logger) batch(first Record) []Record {
+	batch := []Record{first}
+	for len(batch) < maxBatch {
+		select {
+		case record := <-l.records:
+			batch = append(batch, record)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// write writes a batch to every sink. Failures are logged; the records are
+// not retried, so a sink that is down does not grow the buffer. Records
+// dropped since the previous batch are logged first.
+func (l *Logger) write(ctx context.Context, batch []Record) {
+	if dropped := l.dropped.Load(); dropped > l.reported {
+		log.FromContext(ctx).Error(nil, "dropped audit records, the audit buffer was full",
+			"dropped", dropped-l.reported, "total", dropped)
+		l.reported = dropped
+	}
+	for _, sink := range l.sinks {
+		writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+		if err := sink.Write(writeCtx, batch); err != nil {
+			log.FromContext(ctx).Error(err, "failed to write audit records", "records", len(batch))
+		}
+		cancel()
+	}
+}
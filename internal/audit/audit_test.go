@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+)
+
+// memorySink collects written batches.
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Record
+	err     error
+}
+
+func (s *memorySink) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, records)
+	return s.err
+}
+
+func (s *memorySink) records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []Record
+	for _, batch := range s.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+// runLogger starts l and returns a function stopping it and waiting for the
+// final flush.
+func runLogger(t *testing.T, l *Logger) func() {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- l.Start(ctx) }()
+	return func() {
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Start() = %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Start() did not return after cancellation")
+		}
+	}
+}
+
+func TestLoggerWritesToAllSinks(t *testing.T) {
+	first, second := &memorySink{}, &memorySink{err: errors.New("collector down")}
+	l := NewLogger(first, second)
+	stop := runLogger(t, l)
+
+	l.Record(Record{Source: SourceMCP, Actor: "alice@example.com", Action: "create_instance", Result: ResultSuccess})
+	l.Record(Record{Source: SourceController, Actor: "klausinstance-controller", Action: "Deleted", Result: ResultSuccess})
+	stop()
+
+	for _, sink := range []*memorySink{first, second} {
+		records := sink.records()
+		if len(records) != 2 {
+			t.Fatalf("sink got %d records, want 2 even when another sink fails", len(records))
+		}
+		if records[0].Action != "create_instance" || records[1].Action != "Deleted" {
+			t.Errorf("records = %+v, want them in order", records)
+		}
+		if records[0].Time.IsZero() {
+			t.Error("record time was not set")
+		}
+	}
+}
+
+func TestLoggerFlushesOnShutdown(t *testing.T) {
+	sink := &memorySink{}
+	l := NewLogger(sink)
+	for range 3 * maxBatch {
+		l.Record(Record{Action: "get_instance"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Start(ctx); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if got := len(sink.records()); got != 3*maxBatch {
+		t.Fatalf("flushed %d records, want %d", got, 3*maxBatch)
+	}
+	for _, batch := range sink.batches {
+		if len(batch) > maxBatch {
+			t.Errorf("batch of %d records, want at most %d", len(batch), maxBatch)
+		}
+	}
+}
+
+func TestLoggerDropsWhenFull(t *testing.T) {
+	dropped := metrics.AuditRecordsDropped.WithLabelValues(SourceMCP)
+	before := testutil.ToFloat64(dropped)
+	l := NewLogger(&memorySink{})
+	for range bufferSize + 5 {
+		l.Record(Record{Source: SourceMCP, Action: "list_instances"})
+	}
+	if got := l.Dropped(); got != 5 {
+		t.Errorf("Dropped() = %d, want 5", got)
+	}
+	if got := testutil.ToFloat64(dropped) - before; got != 5 {
+		t.Errorf("dropped records metric grew by %v, want 5", got)
+	}
+}
+
+func TestLoggerWaitsForRoom(t *testing.T) {
+	l := NewLogger(&memorySink{})
+	for range bufferSize {
+		l.Record(Record{Action: "list_instances"})
+	}
+	go func() {
+		time.Sleep(enqueueTimeout / 10)
+		<-l.records
+	}()
+	l.Record(Record{Action: "create_instance"})
+	if got := l.Dropped(); got != 0 {
+		t.Errorf("Dropped() = %d, want the record queued once there is room", got)
+	}
+}
+
+func TestNilLoggerDiscards(t *testing.T) {
+	var l *Logger
+	l.Record(Record{Action: "create_instance"})
+}
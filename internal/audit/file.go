@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterSink writes records as JSON lines to a writer.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a sink writing JSON lines to w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewFileSink returns a sink appending JSON lines to the file at path,
+// creating it if needed. A path of "-" writes to standard output.
+func NewFileSink(path string) (*WriterSink, error) {
+	if path == "-" {
+		return NewWriterSink(os.Stdout), nil
+	}
+	// #nosec G304 -- path is an operator flag
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return NewWriterSink(f), nil
+}
+
+// Write implements Sink.
+func (s *WriterSink) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(s.w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
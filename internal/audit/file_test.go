@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+	records := []Record{
+		{
+			Time:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			Source:   SourceMCP,
+			Actor:    "alice@example.com",
+			Action:   "delete_instance",
+			Resource: &Resource{Kind: "KlausInstance", Namespace: "klaus-system", Name: "agent"},
+			Result:   ResultSuccess,
+			Session:  "session-1",
+		},
+		{Source: SourceMCP, Actor: "bob@example.com", Action: "get_instance", Result: ResultFailure, Message: "not found"},
+	}
+	if err := sink.Write(context.Background(), records); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("wrote %d lines, want one per record", len(lines))
+	}
+	var first map[string]any
+	if err := json.Unmarshal(lines[0], &first); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if first["time"] != "2026-01-02T03:04:05Z" || first["actor"] != "alice@example.com" || first["session"] != "session-1" {
+		t.Errorf("first record = %v", first)
+	}
+	if resource, _ := first["resource"].(map[string]any); resource["name"] != "agent" {
+		t.Errorf("resource = %v, want agent", first["resource"])
+	}
+	var second map[string]any
+	if err := json.Unmarshal(lines[1], &second); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if _, ok := second["resource"]; ok {
+		t.Errorf("second record = %v, want no resource", second)
+	}
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for _, action := range []string{"create_instance", "delete_instance"} {
+		sink, err := NewFileSink(path)
+		if err != nil {
+			t.Fatalf("NewFileSink() = %v", err)
+		}
+		if err := sink.Write(context.Background(), []Record{{Action: action}}); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var actions []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line is not JSON: %v", err)
+		}
+		actions = append(actions, record.Action)
+	}
+	if len(actions) != 2 || actions[0] != "create_instance" || actions[1] != "delete_instance" {
+		t.Errorf("actions = %v, want both writes appended", actions)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("mode = %v, want 0600", perm)
+	}
+}
+
+func TestNewFileSinkError(t *testing.T) {
+	if _, err := NewFileSink(filepath.Join(t.TempDir(), "missing", "audit.log")); err == nil {
+		t.Error("NewFileSink() succeeded for a missing directory")
+	}
+}
@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// otlpScope is the instrumentation scope of exported audit records.
+const otlpScope = "github.com/giantswarm/klaus-operator/audit"
+
+// OTLP severity numbers of audit records.
+const (
+	severityInfo = 9
+	severityWarn = 13
+)
+
+// defaultOTLPTimeout is the timeout of the client NewOTLPSink creates.
+const defaultOTLPTimeout = 10 * time.Second
+
+// OTLPSink exports records as OTLP log records over HTTP, using the JSON
+// encoding of the OTLP protocol.
+type OTLPSink struct {
+	// Endpoint is the logs URL, typically http://<collector>:4318/v1/logs.
+	Endpoint string
+
+	// Headers are added to every export request, for example to
+	// authenticate to the collector.
+	Headers map[string]string
+
+	// ServiceName is the service.name resource attribute.
+	ServiceName string
+
+	// Client sends the export requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// NewOTLPSink returns a sink exporting to endpoint as serviceName.
+func NewOTLPSink(endpoint, serviceName string, headers map[string]string) *OTLPSink {
+	return &OTLPSink{
+		Endpoint:    endpoint,
+		Headers:     headers,
+		ServiceName: serviceName,
+		Client:      &http.Client{Timeout: defaultOTLPTimeout},
+	}
+}
+
+// The OTLP/JSON structures of a logs export request.
+type (
+	otlpRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScopeName   `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScopeName struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano   string          `json:"timeUnixNano"`
+		SeverityNumber int             `json:"severityNumber"`
+		SeverityText   string          `json:"severityText"`
+		Body           otlpValue       `json:"body"`
+		Attributes     []otlpAttribute `json:"attributes"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// Write implements Sink.
+func (s *OTLPSink) Write(ctx context.Context, records []Record) error {
+	logs := make([]otlpLogRecord, 0, len(records))
+	for _, record := range records {
+		logs = append(logs, otlpRecord(record))
+	}
+	body, err := json.Marshal(otlpRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource:  otlpResource{Attributes: []otlpAttribute{attribute("service.name", s.ServiceName)}},
+		ScopeLogs: []otlpScopeLogs{{Scope: otlpScopeName{Name: otlpScope}, LogRecords: logs}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("encoding OTLP logs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("exporting audit records: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("exporting audit records: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// otlpRecord maps a record to an OTLP log record. The message is the body
+// and the other fields are attributes prefixed with audit.
+func otlpRecord(record Record) otlpLogRecord {
+	severity, text := severityInfo, "INFO"
+	if record.Result == ResultFailure {
+		severity, text = severityWarn, "WARN"
+	}
+	attrs := []otlpAttribute{
+		attribute("audit.source", record.Source),
+		attribute("audit.actor", record.Actor),
+		attribute("audit.action", record.Action),
+		attribute("audit.result", record.Result),
+	}
+	if r := record.Resource; r != nil {
+		attrs = append(attrs, attribute("audit.resource.kind", r.Kind), attribute("audit.resource.name", r.Name))
+		if r.Namespace != "" {
+			attrs = append(attrs, attribute("audit.resource.namespace", r.Namespace))
+		}
+	}
+	if record.Session != "" {
+		attrs = append(attrs, attribute("audit.session", record.Session))
+	}
+	return otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(record.Time.UnixNano(), 10),
+		SeverityNumber: severity,
+		SeverityText:   text,
+		Body:           otlpValue{StringValue: record.Message},
+		Attributes:     attrs,
+	}
+}
+
+func attribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
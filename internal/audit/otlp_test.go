@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOTLPSink(t *testing.T) {
+	var (
+		got    otlpRequest
+		header http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("request is not OTLP/JSON: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	sink := NewOTLPSink(srv.URL, "klaus-operator", map[string]string{"Authorization": "Bearer secret"})
+	err := sink.Write(context.Background(), []Record{
+		{
+			Time:     time.Unix(1700000000, 5),
+			Source:   SourceMCP,
+			Actor:    "alice@example.com",
+			Action:   "create_instance",
+			Resource: &Resource{Kind: "KlausInstance", Namespace: "klaus-system", Name: "agent"},
+			Result:   ResultSuccess,
+			Session:  "session-1",
+		},
+		{Source: SourceController, Actor: "klausinstance-controller", Action: "ReconcileFailed", Result: ResultFailure, Message: "boom"},
+	})
+	if err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	if header.Get("Authorization") != "Bearer secret" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v", header)
+	}
+	if len(got.ResourceLogs) != 1 || len(got.ResourceLogs[0].ScopeLogs) != 1 {
+		t.Fatalf("request = %+v, want one resource and scope", got)
+	}
+	if attrs := got.ResourceLogs[0].Resource.Attributes; len(attrs) != 1 || attrs[0].Value.StringValue != "klaus-operator" {
+		t.Errorf("resource attributes = %+v, want service.name", attrs)
+	}
+	logs := got.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(logs) != 2 {
+		t.Fatalf("exported %d log records, want 2", len(logs))
+	}
+
+	if logs[0].TimeUnixNano != "1700000000000000005" || logs[0].SeverityNumber != severityInfo {
+		t.Errorf("first log record = %+v", logs[0])
+	}
+	attrs := map[string]string{}
+	for _, a := range logs[0].Attributes {
+		attrs[a.Key] = a.Value.StringValue
+	}
+	want := map[string]string{
+		"audit.source":             SourceMCP,
+		"audit.actor":              "alice@example.com",
+		"audit.action":             "create_instance",
+		"audit.result":             ResultSuccess,
+		"audit.resource.kind":      "KlausInstance",
+		"audit.resource.namespace": "klaus-system",
+		"audit.resource.name":      "agent",
+		"audit.session":            "session-1",
+	}
+	for k, v := range want {
+		if attrs[k] != v {
+			t.Errorf("attribute %s = %q, want %q", k, attrs[k], v)
+		}
+	}
+
+	if logs[1].SeverityNumber != severityWarn || logs[1].SeverityText != "WARN" || logs[1].Body.StringValue != "boom" {
+		t.Errorf("failure log record = %+v, want WARN with the message as body", logs[1])
+	}
+}
+
+func TestOTLPSinkErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if err := NewOTLPSink(srv.URL, "klaus-operator", nil).Write(context.Background(), []Record{{Action: "get_instance"}}); err == nil {
+		t.Error("Write() succeeded for a 503 response")
+	}
+}
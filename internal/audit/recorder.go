@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// eventRecorder records the events of a controller both as Kubernetes
+// Events and in the audit stream. The events a controller emits are its
+// significant actions, such as creating a namespace or deleting an instance.
+type eventRecorder struct {
+	record.EventRecorder
+	logger *Logger
+	scheme *runtime.Scheme
+	actor  string
+}
+
+// NewEventRecorder returns a recorder forwarding events to recorder and
+// recording them as controller actions of actor. Without a logger recorder
+// is returned as is.
+func NewEventRecorder(recorder record.EventRecorder, logger *Logger, scheme *runtime.Scheme, actor string) record.EventRecorder {
+	if logger == nil {
+		return recorder
+	}
+	return &eventRecorder{EventRecorder: recorder, logger: logger, scheme: scheme, actor: actor}
+}
+
+func (r *eventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, message)
+	r.audit(object, eventtype, reason, message)
+}
+
+func (r *eventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...any) {
+	r.EventRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	r.audit(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *eventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...any) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	r.audit(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// audit records an event. Warning events are failures.
+func (r *eventRecorder) audit(object runtime.Object, eventtype, reason, message string) {
+	result := ResultSuccess
+	if eventtype == corev1.EventTypeWarning {
+		result = ResultFailure
+	}
+	r.logger.Record(Record{
+		Source:   SourceController,
+		Actor:    r.actor,
+		Action:   reason,
+		Resource: ResourceOf(object, r.scheme),
+		Result:   result,
+		Message:  message,
+	})
+}
+
+// ResourceOf identifies object. The kind is looked up in scheme when the
+// object does not carry it.
+func ResourceOf(object runtime.Object, scheme *runtime.Scheme) *Resource {
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return nil
+	}
+	kind := object.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" && scheme != nil {
+		if gvk, err := apiutil.GVKForObject(object, scheme); err == nil {
+			kind = gvk.Kind
+		}
+	}
+	return &Resource{Kind: kind, Namespace: accessor.GetNamespace(), Name: accessor.GetName()}
+}
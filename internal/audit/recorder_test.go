@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestEventRecorder(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	sink := &memorySink{}
+	logger := NewLogger(sink)
+	fake := record.NewFakeRecorder(10)
+	recorder := NewEventRecorder(fake, logger, scheme, "klausinstance-controller")
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "klaus-user-alice"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "agent-0", Namespace: "klaus-user-alice"}}
+	recorder.Event(ns, corev1.EventTypeNormal, "NamespaceCreated", "created namespace")
+	recorder.Eventf(pod, corev1.EventTypeWarning, "PodFailed", "pod %s failed", pod.Name)
+	recorder.AnnotatedEventf(pod, map[string]string{"a": "b"}, corev1.EventTypeNormal, "PodReady", "pod %s ready", pod.Name)
+
+	if got := len(fake.Events); got != 3 {
+		t.Fatalf("forwarded %d events, want 3", got)
+	}
+	if event := <-fake.Events; !strings.Contains(event, "NamespaceCreated") {
+		t.Errorf("event = %q", event)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = logger.Start(ctx)
+	records := sink.records()
+	if len(records) != 3 {
+		t.Fatalf("recorded %d audit records, want 3", len(records))
+	}
+	first := records[0]
+	if first.Source != SourceController || first.Actor != "klausinstance-controller" || first.Action != "NamespaceCreated" || first.Result != ResultSuccess {
+		t.Errorf("first record = %+v", first)
+	}
+	if r := first.Resource; r == nil || r.Kind != "Namespace" || r.Name != "klaus-user-alice" || r.Namespace != "" {
+		t.Errorf("first resource = %+v, want the namespace", r)
+	}
+	second := records[1]
+	if second.Result != ResultFailure || second.Message != "pod agent-0 failed" {
+		t.Errorf("warning record = %+v, want a failure with the formatted message", second)
+	}
+	if r := second.Resource; r == nil || r.Kind != "Pod" || r.Namespace != "klaus-user-alice" {
+		t.Errorf("second resource = %+v, want the pod", r)
+	}
+}
+
+func TestNewEventRecorderWithoutLogger(t *testing.T) {
+	fake := record.NewFakeRecorder(1)
+	if got := NewEventRecorder(fake, nil, nil, "klausinstance-controller"); got != fake {
+		t.Errorf("NewEventRecorder() = %T, want the recorder unwrapped", got)
+	}
+}
@@ -0,0 +1,241 @@
+// Package certs implements the operator-managed certificate authority used to
+// issue per-instance serving certificates and client certificates for mTLS
+// between muster, the operator and Klaus instances.
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// CAValidity is the lifetime of the operator CA.
+	CAValidity = 10 * 365 * 24 * time.Hour
+
+	// LeafValidity is the lifetime of issued serving and client certificates.
+	LeafValidity = 90 * 24 * time.Hour
+)
+
+// Keys of kubernetes.io/tls Secrets holding issued certificates. ca.crt
+// follows the cert-manager convention so Secrets issued by either the
+// operator or cert-manager have the same layout.
+const (
+	CertKey = "tls.crt"
+	KeyKey  = "tls.key"
+	CAKey   = "ca.crt"
+)
+
+// CA is a certificate authority able to sign leaf certificates.
+type CA struct {
+	Cert *x509.Certificate
+	Key  *ecdsa.PrivateKey
+
+	// CertPEM is the PEM encoding of Cert.
+	CertPEM []byte
+}
+
+// KeyPair is a PEM-encoded certificate and private key.
+type KeyPair struct {
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// NewCA generates a self-signed CA valid from now for CAValidity.
+func NewCA(commonName string, now time.Time) (*CA, *KeyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating CA key: %w", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(CAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating CA certificate: %w", err)
+	}
+	pair, err := encode(der, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	ca, err := LoadCA(pair.CertPEM, pair.KeyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ca, pair, nil
+}
+
+// LoadCA parses a PEM-encoded CA certificate and private key.
+func LoadCA(certPEM, keyPEM []byte) (*CA, error) {
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, errors.New("certificate is not a CA")
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("decoding CA key: no PEM block")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing CA key: %w", err)
+	}
+	return &CA{Cert: cert, Key: key, CertPEM: certPEM}, nil
+}
+
+// IssueServing issues a server certificate for dnsNames, valid from now for
+// LeafValidity. The first DNS name is used as the common name.
+func (ca *CA) IssueServing(dnsNames []string, now time.Time) (*KeyPair, error) {
+	if len(dnsNames) == 0 {
+		return nil, errors.New("serving certificate needs at least one DNS name")
+	}
+	return ca.issue(pkix.Name{CommonName: dnsNames[0]}, dnsNames, x509.ExtKeyUsageServerAuth, now)
+}
+
+// IssueClient issues a client certificate for commonName, valid from now for
+// LeafValidity.
+func (ca *CA) IssueClient(commonName string, now time.Time) (*KeyPair, error) {
+	return ca.issue(pkix.Name{CommonName: commonName}, nil, x509.ExtKeyUsageClientAuth, now)
+}
+
+func (ca *CA) issue(subject pkix.Name, dnsNames []string, usage x509.ExtKeyUsage, now time.Time) (*KeyPair, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(LeafValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, &key.PublicKey, ca.Key)
+	if err != nil {
+		return nil, fmt.Errorf("signing certificate: %w", err)
+	}
+	return encode(der, key)
+}
+
+// ParseCertificate parses the first certificate of a PEM bundle.
+func ParseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("decoding certificate: no CERTIFICATE PEM block")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// RenewAt returns the time at which a certificate should be renewed: when a
+// third of its lifetime remains.
+func RenewAt(cert *x509.Certificate) time.Time {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotAfter.Add(-lifetime / 3)
+}
+
+// NeedsRenewal reports whether certPEM is missing, unparsable, not signed by
+// ca, or due for renewal at now.
+func NeedsRenewal(certPEM []byte, ca *CA, now time.Time) bool {
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return true
+	}
+	if err := cert.CheckSignatureFrom(ca.Cert); err != nil {
+		return true
+	}
+	return !now.Before(RenewAt(cert))
+}
+
+// VerifyChain checks that the certificate bundle certPEM chains to one of
+// the CAs in caPEM at now. Certificates after the first in certPEM are used
+// as intermediates.
+func VerifyChain(certPEM, caPEM []byte, now time.Time) error {
+	leaf, err := ParseCertificate(certPEM)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return errors.New("loading CA bundle: no certificates found")
+	}
+	intermediates := x509.NewCertPool()
+	_, rest := pem.Decode(certPEM)
+	intermediates.AppendCertsFromPEM(rest)
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("verifying certificate: %w", err)
+	}
+	return nil
+}
+
+// ClientTLSConfig builds a TLS client configuration presenting the client
+// certificate in certPEM/keyPEM and trusting the CAs in caPEM.
+func ClientTLSConfig(certPEM, keyPEM, caPEM []byte) (*tls.Config, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("loading client certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("loading CA bundle: no certificates found")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func encode(der []byte, key *ecdsa.PrivateKey) (*KeyPair, error) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling key: %w", err)
+	}
+	return &KeyPair{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generating serial number: %w", err)
+	}
+	return serial, nil
+}
@@ -0,0 +1,191 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+)
+
+func testCA(t *testing.T, now time.Time) (*CA, *KeyPair) {
+	t.Helper()
+	ca, pair, err := NewCA("klaus-operator-ca", now)
+	if err != nil {
+		t.Fatalf("NewCA() error = %v", err)
+	}
+	return ca, pair
+}
+
+func TestNewCA_RoundTrip(t *testing.T) {
+	now := time.Now()
+	ca, pair := testCA(t, now)
+
+	loaded, err := LoadCA(pair.CertPEM, pair.KeyPEM)
+	if err != nil {
+		t.Fatalf("LoadCA() error = %v", err)
+	}
+	if !loaded.Cert.Equal(ca.Cert) {
+		t.Error("loaded CA certificate differs from generated one")
+	}
+	if !loaded.Cert.IsCA || loaded.Cert.Subject.CommonName != "klaus-operator-ca" {
+		t.Errorf("unexpected CA certificate: CA=%v CN=%q", loaded.Cert.IsCA, loaded.Cert.Subject.CommonName)
+	}
+}
+
+func TestLoadCA_RejectsLeaf(t *testing.T) {
+	ca, _ := testCA(t, time.Now())
+	leaf, err := ca.IssueClient("muster", time.Now())
+	if err != nil {
+		t.Fatalf("IssueClient() error = %v", err)
+	}
+	if _, err := LoadCA(leaf.CertPEM, leaf.KeyPEM); err == nil {
+		t.Error("expected error loading a leaf certificate as CA")
+	}
+}
+
+func TestIssueServing(t *testing.T) {
+	now := time.Now()
+	ca, _ := testCA(t, now)
+	names := []string{"agent.klaus-user-x.svc", "agent.klaus-user-x.svc.cluster.local"}
+
+	pair, err := ca.IssueServing(names, now)
+	if err != nil {
+		t.Fatalf("IssueServing() error = %v", err)
+	}
+	cert, err := ParseCertificate(pair.CertPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	for _, name := range names {
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: name, Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}); err != nil {
+			t.Errorf("verifying %s: %v", name, err)
+		}
+	}
+	if _, err := tls.X509KeyPair(pair.CertPEM, pair.KeyPEM); err != nil {
+		t.Errorf("key pair does not match: %v", err)
+	}
+}
+
+func TestIssueServing_NoNames(t *testing.T) {
+	ca, _ := testCA(t, time.Now())
+	if _, err := ca.IssueServing(nil, time.Now()); err == nil {
+		t.Error("expected error without DNS names")
+	}
+}
+
+func TestNeedsRenewal(t *testing.T) {
+	now := time.Now()
+	ca, _ := testCA(t, now)
+	other, _ := testCA(t, now)
+	pair, err := ca.IssueClient("muster", now)
+	if err != nil {
+		t.Fatalf("IssueClient() error = %v", err)
+	}
+
+	if NeedsRenewal(pair.CertPEM, ca, now) {
+		t.Error("fresh certificate should not need renewal")
+	}
+	if !NeedsRenewal(pair.CertPEM, ca, now.Add(LeafValidity*2/3+time.Hour)) {
+		t.Error("certificate with less than a third of its lifetime left should need renewal")
+	}
+	if !NeedsRenewal(pair.CertPEM, other, now) {
+		t.Error("certificate signed by another CA should need renewal")
+	}
+	if !NeedsRenewal(nil, ca, now) {
+		t.Error("missing certificate should need renewal")
+	}
+}
+
+func TestClientTLSConfig_Handshake(t *testing.T) {
+	now := time.Now()
+	ca, _ := testCA(t, now)
+	serving, err := ca.IssueServing([]string{"localhost"}, now)
+	if err != nil {
+		t.Fatalf("IssueServing() error = %v", err)
+	}
+	client, err := ca.IssueClient("muster", now)
+	if err != nil {
+		t.Fatalf("IssueClient() error = %v", err)
+	}
+
+	serverCert, err := tls.X509KeyPair(serving.CertPEM, serving.KeyPEM)
+	if err != nil {
+		t.Fatalf("loading serving key pair: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.Cert)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	defer func() { _ = listener.Close() }()
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		done <- conn.(*tls.Conn).Handshake()
+	}()
+
+	cfg, err := ClientTLSConfig(client.CertPEM, client.KeyPEM, ca.CertPEM)
+	if err != nil {
+		t.Fatalf("ClientTLSConfig() error = %v", err)
+	}
+	cfg.ServerName = "localhost"
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	conn, err := tls.Dial("tcp", "127.0.0.1:"+port, cfg)
+	if err != nil {
+		t.Fatalf("client handshake: %v", err)
+	}
+	_ = conn.Close()
+	if err := <-done; err != nil {
+		t.Errorf("server handshake: %v", err)
+	}
+}
+
+func TestClientTLSConfig_InvalidCA(t *testing.T) {
+	ca, _ := testCA(t, time.Now())
+	client, err := ca.IssueClient("muster", time.Now())
+	if err != nil {
+		t.Fatalf("IssueClient() error = %v", err)
+	}
+	if _, err := ClientTLSConfig(client.CertPEM, client.KeyPEM, []byte("not a cert")); err == nil {
+		t.Error("expected error for invalid CA bundle")
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	now := time.Now()
+	ca, caPair := testCA(t, now)
+	serving, err := ca.IssueServing([]string{"agent.klaus-system.svc"}, now)
+	if err != nil {
+		t.Fatalf("IssueServing() error = %v", err)
+	}
+	_, otherPair := testCA(t, now)
+
+	if err := VerifyChain(serving.CertPEM, caPair.CertPEM, now); err != nil {
+		t.Errorf("VerifyChain() error = %v", err)
+	}
+	if err := VerifyChain(serving.CertPEM, otherPair.CertPEM, now); err == nil {
+		t.Error("expected error for a certificate of another CA")
+	}
+	if err := VerifyChain(serving.CertPEM, []byte("not a cert"), now); err == nil {
+		t.Error("expected error for an invalid CA bundle")
+	}
+	if err := VerifyChain([]byte("not a cert"), caPair.CertPEM, now); err == nil {
+		t.Error("expected error for an invalid certificate")
+	}
+}
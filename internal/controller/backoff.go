@@ -0,0 +1,17 @@
+package controller
+
+import "time"
+
+// retryBackoff returns the delay before retrying an operation that has been
+// failing since since. The delay starts at minDelay and doubles for every
+// delay already elapsed, up to maxDelay, so retries back off without the
+// controller keeping a failure count: the start of the failures is read
+// from a condition or timestamp of the object.
+func retryBackoff(since, now time.Time, minDelay, maxDelay time.Duration) time.Duration {
+	elapsed := now.Sub(since)
+	backoff := minDelay
+	for backoff < maxDelay && backoff <= elapsed {
+		backoff *= 2
+	}
+	return min(backoff, maxDelay)
+}
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		elapsed time.Duration
+		want    time.Duration
+	}{
+		{elapsed: 0, want: time.Second},
+		{elapsed: -time.Minute, want: time.Second},
+		{elapsed: time.Second, want: 2 * time.Second},
+		{elapsed: 5 * time.Second, want: 8 * time.Second},
+		{elapsed: time.Hour, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(now.Add(-tt.elapsed), now, time.Second, 10*time.Second); got != tt.want {
+			t.Errorf("retryBackoff after %s = %s, want %s", tt.elapsed, got, tt.want)
+		}
+	}
+}
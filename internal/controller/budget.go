@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+// reconcileBudget suspends an instance whose recorded cost reached its
+// spec.claude.maxBudgetUSD by setting the desiredState of the merged spec to
+// Stopped. Setting the deprecated spec.stopped instead would contradict a
+// desiredState of Running and fail validation. The instance's own
+// spec.desiredState and spec.stopped are left alone, so raising the budget
+// resumes the instance.
+func (r *KlausInstanceReconciler) reconcileBudget(instance, merged *klausv1alpha1.KlausInstance) {
+	budget := instance.Spec.Claude.MaxBudgetUSD
+	if budget == nil || *budget <= 0 {
+		apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionBudgetExceeded)
+		return
+	}
+	cost := "0"
+	if instance.Status.TokenUsage != nil && instance.Status.TokenUsage.CostUSD != "" {
+		cost = instance.Status.TokenUsage.CostUSD
+	}
+	if !usage.BudgetExceeded(instance) {
+		setCondition(instance, ConditionBudgetExceeded, metav1.ConditionFalse, "WithinBudget",
+			fmt.Sprintf("Cost of %s USD is within the budget of %g USD", cost, *budget))
+		return
+	}
+
+	msg := fmt.Sprintf("Cost of %s USD reached the budget of %g USD; raise spec.claude.maxBudgetUSD to resume", cost, *budget)
+	if !apimeta.IsStatusConditionTrue(instance.Status.Conditions, ConditionBudgetExceeded) {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "BudgetExceeded", "Suspending instance: "+msg)
+	}
+	setCondition(instance, ConditionBudgetExceeded, metav1.ConditionTrue, "BudgetExceeded", msg)
+	merged.Spec.DesiredState = klausv1alpha1.DesiredStateStopped
+}
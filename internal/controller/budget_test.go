@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func budgetInstance(budget *float64, cost string) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("dev", "dev@example.com")
+	instance.Spec.Claude.MaxBudgetUSD = budget
+	if cost != "" {
+		instance.Status.TokenUsage = &klausv1alpha1.TokenUsage{CostUSD: cost}
+	}
+	return instance
+}
+
+func TestReconcileBudget(t *testing.T) {
+	tests := []struct {
+		name        string
+		budget      *float64
+		cost        string
+		wantStopped bool
+		wantStatus  metav1.ConditionStatus
+	}{
+		{name: "no budget", cost: "12"},
+		{name: "no usage yet", budget: ptr.To(5.0), wantStatus: metav1.ConditionFalse},
+		{name: "within budget", budget: ptr.To(5.0), cost: "4.5", wantStatus: metav1.ConditionFalse},
+		{name: "budget reached", budget: ptr.To(5.0), cost: "5.01", wantStopped: true, wantStatus: metav1.ConditionTrue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			r := &KlausInstanceReconciler{Recorder: recorder}
+			instance := budgetInstance(tt.budget, tt.cost)
+			merged := instance.DeepCopy()
+
+			r.reconcileBudget(instance, merged)
+
+			if resources.IsStopped(merged) != tt.wantStopped {
+				t.Errorf("merged stopped = %v, want %v", resources.IsStopped(merged), tt.wantStopped)
+			}
+			if resources.IsStopped(instance) {
+				t.Error("the desired state of the instance must not change")
+			}
+			cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionBudgetExceeded)
+			switch {
+			case tt.wantStatus == "" && cond != nil:
+				t.Errorf("unexpected condition %+v", cond)
+			case tt.wantStatus != "" && (cond == nil || cond.Status != tt.wantStatus):
+				t.Errorf("condition = %+v, want status %s", cond, tt.wantStatus)
+			}
+			if tt.wantStopped && len(recorder.Events) != 1 {
+				t.Errorf("expected a BudgetExceeded event, got %d", len(recorder.Events))
+			}
+		})
+	}
+}
+
+func TestReconcileBudget_EventOnce(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &KlausInstanceReconciler{Recorder: recorder}
+	instance := budgetInstance(ptr.To(1.0), "2")
+
+	r.reconcileBudget(instance, instance.DeepCopy())
+	r.reconcileBudget(instance, instance.DeepCopy())
+
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected one event for a suspended instance, got %d", len(recorder.Events))
+	}
+}
+
+func TestReconcileBudget_RaisedBudgetResumes(t *testing.T) {
+	r := &KlausInstanceReconciler{Recorder: record.NewFakeRecorder(10)}
+	instance := budgetInstance(ptr.To(1.0), "2")
+	r.reconcileBudget(instance, instance.DeepCopy())
+
+	instance.Spec.Claude.MaxBudgetUSD = ptr.To(10.0)
+	merged := instance.DeepCopy()
+	r.reconcileBudget(instance, merged)
+
+	if resources.IsStopped(merged) {
+		t.Error("expected the instance to resume after raising the budget")
+	}
+	if apimeta.IsStatusConditionTrue(instance.Status.Conditions, ConditionBudgetExceeded) {
+		t.Error("expected BudgetExceeded to be False")
+	}
+}
+
+func TestResolveInstance_BudgetExceededDesiredRunning(t *testing.T) {
+	instance := budgetInstance(ptr.To(1.0), "2")
+	instance.Spec.DesiredState = klausv1alpha1.DesiredStateRunning
+	r := &KlausInstanceReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance).Build(),
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	resolved, err := r.resolveInstance(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("resolveInstance() error = %v, want the suspended spec to validate", err)
+	}
+	if !resources.IsStopped(resolved.merged) {
+		t.Error("expected the instance over its budget to be stopped despite desiredState Running")
+	}
+	if instance.Spec.DesiredState != klausv1alpha1.DesiredStateRunning {
+		t.Errorf("spec.desiredState = %s, want it left alone", instance.Spec.DesiredState)
+	}
+}
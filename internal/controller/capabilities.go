@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// capabilitiesTimeout bounds the capability handshake so an unresponsive
+// agent does not stall reconciliation.
+const capabilitiesTimeout = 5 * time.Second
+
+// maxCapabilitiesBytes caps the size of the /capabilities response body.
+const maxCapabilitiesBytes = 64 << 10
+
+// unknownCapabilitiesTTL is how long an image without a capabilities
+// endpoint is not probed again.
+const unknownCapabilitiesTTL = 30 * time.Minute
+
+// unknownCapabilityImages remembers the images whose agents answered the
+// handshake with 404, so instances of old images are not probed on every
+// reconcile. The entries expire after unknownCapabilitiesTTL, picking up a
+// tag moved to an image that supports the handshake. A nil cache records
+// nothing.
+type unknownCapabilityImages struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// has reports whether image was recorded within the TTL.
+func (u *unknownCapabilityImages) has(image string, now time.Time) bool {
+	if u == nil {
+		return false
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	until, ok := u.until[image]
+	if ok && !now.Before(until) {
+		delete(u.until, image)
+		return false
+	}
+	return ok
+}
+
+// add records image for unknownCapabilitiesTTL.
+func (u *unknownCapabilityImages) add(image string, now time.Time) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.until == nil {
+		u.until = map[string]time.Time{}
+	}
+	for image, until := range u.until {
+		if !now.Before(until) {
+			delete(u.until, image)
+		}
+	}
+	u.until[image] = now.Add(unknownCapabilitiesTTL)
+}
+
+// CapabilityProber queries a running agent for the features it supports.
+type CapabilityProber interface {
+	// Capabilities returns the agent's reported capabilities. It returns
+	// (nil, nil) when the agent does not expose a capabilities endpoint.
+	// tlsConfig is set for instances served over mTLS and nil otherwise.
+	Capabilities(ctx context.Context, endpoint string, tlsConfig *tls.Config) (*klausv1alpha1.AgentCapabilities, error)
+}
+
+// httpCapabilityProber implements CapabilityProber against the agent's
+// /capabilities HTTP endpoint.
+type httpCapabilityProber struct {
+	client *http.Client
+}
+
+// NewHTTPCapabilityProber creates a CapabilityProber that issues HTTP
+// requests to the instance Service endpoint, presenting the operator client
+// certificate to instances served over mTLS.
+func NewHTTPCapabilityProber() CapabilityProber {
+	return &httpCapabilityProber{client: &http.Client{Timeout: capabilitiesTimeout}}
+}
+
+// capabilitiesResponse is the JSON payload returned by the agent's
+// /capabilities endpoint.
+type capabilitiesResponse struct {
+	Version  string   `json:"version"`
+	Features []string `json:"features"`
+}
+
+func (p *httpCapabilityProber) Capabilities(ctx context.Context, endpoint string, tlsConfig *tls.Config) (*klausv1alpha1.AgentCapabilities, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/capabilities", nil)
+	if err != nil {
+		return nil, err
+	}
+	client := p.client
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		defer transport.CloseIdleConnections()
+		client = &http.Client{Timeout: p.client.Timeout, Transport: transport}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Agents built before the handshake existed return 404; treat that as
+	// "unknown" rather than an error.
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from capabilities endpoint", resp.StatusCode)
+	}
+
+	var parsed capabilitiesResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxCapabilitiesBytes)).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("decoding capabilities response: %w", err)
+	}
+	return &klausv1alpha1.AgentCapabilities{
+		Version:  parsed.Version,
+		Features: parsed.Features,
+	}, nil
+}
+
+// reconcileCapabilities performs the capability handshake once the instance
+// is available, recording the result in status and warning when the spec
+// requests features the agent image does not support. The handshake is
+// repeated only when the resolved image changes; an image without a
+// capabilities endpoint is probed again after unknownCapabilitiesTTL.
+// Failures are logged and do not affect the reconcile result.
+func (r *KlausInstanceReconciler) reconcileCapabilities(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace, resolvedImage string) {
+	if r.CapabilityProber == nil {
+		return
+	}
+
+	caps := instance.Status.AgentCapabilities
+	if caps == nil || caps.Image != resolvedImage {
+		if r.unknownCapabilities.has(resolvedImage, time.Now()) {
+			instance.Status.AgentCapabilities = nil
+			apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionCapabilitiesSupported)
+			return
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, capabilitiesTimeout)
+		defer cancel()
+
+		tlsConfig, err := r.instanceClientTLSConfig(ctx, merged, namespace)
+		if err != nil {
+			log.FromContext(ctx).Info("agent capability handshake skipped", "instance", instance.Name, "error", err.Error())
+			return
+		}
+		observed, err := r.CapabilityProber.Capabilities(probeCtx, resources.ServiceEndpoint(merged, namespace), tlsConfig)
+		if err != nil {
+			log.FromContext(ctx).Info("agent capability handshake failed", "instance", instance.Name, "error", err.Error())
+			return
+		}
+		if observed == nil {
+			// Capabilities are unknown for this image; drop any stale
+			// record from a previous image and skip the feature check.
+			r.unknownCapabilities.add(resolvedImage, time.Now())
+			instance.Status.AgentCapabilities = nil
+			apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionCapabilitiesSupported)
+			return
+		}
+		now := metav1.Now()
+		observed.Image = resolvedImage
+		observed.ObservedAt = &now
+		instance.Status.AgentCapabilities = observed
+		caps = observed
+	}
+
+	if missing := resources.UnsupportedFeatures(merged, caps); len(missing) > 0 {
+		msg := fmt.Sprintf("agent image %s does not support requested features: %s", resolvedImage, strings.Join(missing, ", "))
+		// Warn only when the condition changes, not on every reconcile.
+		if setCondition(instance, ConditionCapabilitiesSupported, metav1.ConditionFalse, "UnsupportedFeature", msg) {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "UnsupportedFeature", msg)
+		}
+		return
+	}
+	setCondition(instance, ConditionCapabilitiesSupported, metav1.ConditionTrue, "Supported", "Agent supports all requested features")
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// mockCapabilityProber is a test double for CapabilityProber.
+type mockCapabilityProber struct {
+	caps  *klausv1alpha1.AgentCapabilities
+	err   error
+	calls int
+}
+
+func (m *mockCapabilityProber) Capabilities(_ context.Context, _ string, _ *tls.Config) (*klausv1alpha1.AgentCapabilities, error) {
+	m.calls++
+	return m.caps, m.err
+}
+
+func capabilitiesInstance() *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("test", testOwner)
+	instance.Spec.Claude.Mode = ptr.To(klausv1alpha1.ModeChat)
+	instance.Spec.Claude.IncludePartialMessages = ptr.To(true)
+	return instance
+}
+
+func TestReconcileCapabilities_RecordsAndWarns(t *testing.T) {
+	prober := &mockCapabilityProber{caps: &klausv1alpha1.AgentCapabilities{
+		Version:  "v0.3.0",
+		Features: []string{"persistent-mode"},
+	}}
+	recorder := record.NewFakeRecorder(10)
+	r := &KlausInstanceReconciler{Recorder: recorder, CapabilityProber: prober}
+	instance := capabilitiesInstance()
+
+	r.reconcileCapabilities(context.Background(), instance, instance, "klaus-user-test", "klaus:v1")
+
+	caps := instance.Status.AgentCapabilities
+	if caps == nil {
+		t.Fatal("expected agentCapabilities to be recorded")
+	}
+	if caps.Image != "klaus:v1" || caps.Version != "v0.3.0" || caps.ObservedAt == nil {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionCapabilitiesSupported)
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Fatalf("expected CapabilitiesSupported=False, got %+v", cond)
+	}
+	select {
+	case ev := <-recorder.Events:
+		if ev != "Warning UnsupportedFeature agent image klaus:v1 does not support requested features: partial-messages" {
+			t.Errorf("unexpected event: %q", ev)
+		}
+	default:
+		t.Error("expected a warning event")
+	}
+
+	// Reconciles of the unchanged instance do not repeat the warning.
+	r.reconcileCapabilities(context.Background(), instance, instance, "klaus-user-test", "klaus:v1")
+	select {
+	case ev := <-recorder.Events:
+		t.Errorf("unexpected repeated event: %q", ev)
+	default:
+	}
+}
+
+func TestReconcileCapabilities_SkipsWhenImageUnchanged(t *testing.T) {
+	prober := &mockCapabilityProber{}
+	r := &KlausInstanceReconciler{Recorder: record.NewFakeRecorder(10), CapabilityProber: prober}
+	instance := capabilitiesInstance()
+	instance.Status.AgentCapabilities = &klausv1alpha1.AgentCapabilities{
+		Image:    "klaus:v1",
+		Features: []string{"persistent-mode", "partial-messages"},
+	}
+
+	r.reconcileCapabilities(context.Background(), instance, instance, "klaus-user-test", "klaus:v1")
+
+	if prober.calls != 0 {
+		t.Errorf("prober called %d times, want 0", prober.calls)
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionCapabilitiesSupported)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected CapabilitiesSupported=True, got %+v", cond)
+	}
+}
+
+func TestReconcileCapabilities_ReprobesOnImageChange(t *testing.T) {
+	prober := &mockCapabilityProber{}
+	r := &KlausInstanceReconciler{Recorder: record.NewFakeRecorder(10), CapabilityProber: prober}
+	instance := capabilitiesInstance()
+	instance.Status.AgentCapabilities = &klausv1alpha1.AgentCapabilities{Image: "klaus:v1"}
+
+	r.reconcileCapabilities(context.Background(), instance, instance, "klaus-user-test", "klaus:v2")
+
+	if prober.calls != 1 {
+		t.Errorf("prober called %d times, want 1", prober.calls)
+	}
+	if instance.Status.AgentCapabilities != nil {
+		t.Error("expected stale capabilities to be cleared when the new image reports none")
+	}
+}
+
+func TestReconcileCapabilities_CachesUnknownImages(t *testing.T) {
+	prober := &mockCapabilityProber{}
+	r := &KlausInstanceReconciler{
+		Recorder:            record.NewFakeRecorder(10),
+		CapabilityProber:    prober,
+		unknownCapabilities: &unknownCapabilityImages{},
+	}
+
+	for range 3 {
+		r.reconcileCapabilities(context.Background(), capabilitiesInstance(), capabilitiesInstance(), "klaus-user-test", "klaus:v1")
+	}
+	if prober.calls != 1 {
+		t.Errorf("prober called %d times, want the image without capabilities probed once", prober.calls)
+	}
+	r.reconcileCapabilities(context.Background(), capabilitiesInstance(), capabilitiesInstance(), "klaus-user-test", "klaus:v2")
+	if prober.calls != 2 {
+		t.Errorf("prober called %d times, want another image probed", prober.calls)
+	}
+
+	now := time.Now()
+	if !r.unknownCapabilities.has("klaus:v1", now) || r.unknownCapabilities.has("klaus:v1", now.Add(unknownCapabilitiesTTL)) {
+		t.Error("want the image without capabilities cached for the TTL")
+	}
+}
+
+func TestReconcileCapabilities_ProbeErrorIsNonFatal(t *testing.T) {
+	prober := &mockCapabilityProber{err: errors.New("connection refused")}
+	r := &KlausInstanceReconciler{Recorder: record.NewFakeRecorder(10), CapabilityProber: prober}
+	instance := capabilitiesInstance()
+
+	r.reconcileCapabilities(context.Background(), instance, instance, "klaus-user-test", "klaus:v1")
+
+	if instance.Status.AgentCapabilities != nil {
+		t.Error("expected no capabilities after probe error")
+	}
+	if len(instance.Status.Conditions) != 0 {
+		t.Errorf("expected no conditions, got %v", instance.Status.Conditions)
+	}
+}
+
+func TestHTTPCapabilityProber(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/capabilities" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":"v0.3.0","features":["persistent-mode","structured-output"]}`))
+	}))
+	defer srv.Close()
+
+	caps, err := NewHTTPCapabilityProber().Capabilities(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps == nil || caps.Version != "v0.3.0" || len(caps.Features) != 2 {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+}
+
+func TestHTTPCapabilityProber_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	caps, err := NewHTTPCapabilityProber().Capabilities(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps != nil {
+		t.Errorf("expected nil capabilities for 404, got %+v", caps)
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// ReasonCloneSourceNotFound is the reason of the Ready condition of clones
+// whose spec.cloneFrom instance or its workspace PVC does not exist while the
+// workspace PVC of the clone is created.
+const ReasonCloneSourceNotFound = "CloneSourceNotFound"
+
+// errCloneSource marks errors resolving the spec.cloneFrom instance or its
+// workspace PVC.
+var errCloneSource = errors.New("clone source")
+
+// cloneWorkspace points the new workspace PVC of a clone at the workspace PVC
+// of its spec.cloneFrom instance. PVC data sources cannot cross namespaces,
+// and the workspace of another owner must not leak, so the source must have
+// the same owner and keep its workspace in the namespace of the clone.
+func (r *KlausInstanceReconciler) cloneWorkspace(ctx context.Context, instance *klausv1alpha1.KlausInstance, pvc *corev1.PersistentVolumeClaim) error {
+	name := instance.Spec.CloneFrom.Name
+	var source klausv1alpha1.KlausInstance
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: instance.Namespace}, &source); err != nil {
+		return fmt.Errorf("resolving %w %q: %w", errCloneSource, name, err)
+	}
+	if source.Spec.Owner != instance.Spec.Owner {
+		return fmt.Errorf("clone source %q has another owner; only workspaces of the same owner can be cloned", name)
+	}
+	if source.Spec.Workspace == nil {
+		return fmt.Errorf("clone source %q has no workspace", name)
+	}
+	if namespace := r.childNamespace(&source); namespace != pvc.Namespace {
+		return fmt.Errorf("clone source %q keeps its workspace in namespace %s, not %s", name, namespace, pvc.Namespace)
+	}
+
+	var sourcePVC corev1.PersistentVolumeClaim
+	if err := r.Get(ctx, types.NamespacedName{Name: resources.PVCName(&source), Namespace: pvc.Namespace}, &sourcePVC); err != nil {
+		return fmt.Errorf("resolving workspace of %w %q: %w", errCloneSource, name, err)
+	}
+	return resources.SetWorkspaceDataSource(pvc, &sourcePVC)
+}
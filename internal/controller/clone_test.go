@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// cloneInstances returns a source instance with a workspace and a clone of
+// it that copies the workspace.
+func cloneInstances() (*klausv1alpha1.KlausInstance, *klausv1alpha1.KlausInstance) {
+	source := resizeInstance("csi-fast", "10Gi")
+	clone := resizeInstance("", "5Gi")
+	clone.Name = "agent-copy"
+	clone.Spec.CloneFrom = &klausv1alpha1.CloneSource{Name: source.Name, Workspace: true}
+	return source, clone
+}
+
+func TestReconcilePVC_CloneWorkspace(t *testing.T) {
+	source, clone := cloneInstances()
+	namespace := resources.InstanceNamespace(clone, false)
+	r := resizeReconciler(t, source, boundPVC(source, namespace, "10Gi"))
+
+	if err := r.reconcilePVC(context.Background(), clone, clone, namespace); err != nil {
+		t.Fatalf("reconcilePVC: %v", err)
+	}
+
+	var pvc corev1.PersistentVolumeClaim
+	if err := r.Get(context.Background(), types.NamespacedName{Name: resources.PVCName(clone), Namespace: namespace}, &pvc); err != nil {
+		t.Fatalf("getting clone PVC: %v", err)
+	}
+	if ds := pvc.Spec.DataSource; ds == nil || ds.Kind != "PersistentVolumeClaim" || ds.Name != resources.PVCName(source) {
+		t.Errorf("dataSource = %+v, want the workspace PVC of the source", ds)
+	}
+	if sc := pvc.Spec.StorageClassName; sc == nil || *sc != "csi-fast" {
+		t.Errorf("storageClassName = %v, want csi-fast", sc)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.Cmp(resource.MustParse("10Gi")) != 0 {
+		t.Errorf("storage request = %s, want 10Gi", size.String())
+	}
+}
+
+func TestReconcilePVC_CloneOnlyOnCreate(t *testing.T) {
+	_, clone := cloneInstances()
+	namespace := resources.InstanceNamespace(clone, false)
+	// The source is gone, but the clone PVC already exists.
+	r := resizeReconciler(t, boundPVC(clone, namespace, "5Gi"))
+
+	if err := r.reconcilePVC(context.Background(), clone, clone, namespace); err != nil {
+		t.Fatalf("reconcilePVC with an existing PVC: %v", err)
+	}
+}
+
+func TestReconcilePVC_CloneSourceErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		mutate       func(source *klausv1alpha1.KlausInstance)
+		withSource   bool
+		withPVC      bool
+		wantNotFound bool
+		wantErr      string
+	}{
+		{name: "missing source", wantNotFound: true, wantErr: `resolving clone source "agent"`},
+		{name: "missing source PVC", withSource: true, wantNotFound: true, wantErr: `resolving workspace of clone source "agent"`},
+		{
+			name:       "other owner",
+			mutate:     func(source *klausv1alpha1.KlausInstance) { source.Spec.Owner = "other@example.com" },
+			withSource: true, withPVC: true, wantErr: "has another owner",
+		},
+		{
+			name:       "no workspace",
+			mutate:     func(source *klausv1alpha1.KlausInstance) { source.Spec.Workspace = nil },
+			withSource: true, wantErr: "has no workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, clone := cloneInstances()
+			namespace := resources.InstanceNamespace(clone, false)
+			pvc := boundPVC(source, namespace, "10Gi")
+			if tt.mutate != nil {
+				tt.mutate(source)
+			}
+			var r *KlausInstanceReconciler
+			switch {
+			case tt.withSource && tt.withPVC:
+				r = resizeReconciler(t, source, pvc)
+			case tt.withSource:
+				r = resizeReconciler(t, source)
+			default:
+				r = resizeReconciler(t)
+			}
+
+			err := r.reconcilePVC(context.Background(), clone, clone, namespace)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("reconcilePVC error = %v, want %q", err, tt.wantErr)
+			}
+			if notFound := errors.Is(err, errCloneSource) && apierrors.IsNotFound(err); notFound != tt.wantNotFound {
+				t.Errorf("clone source not found = %v, want %v", notFound, tt.wantNotFound)
+			}
+
+			var created corev1.PersistentVolumeClaim
+			if err := r.Get(context.Background(), types.NamespacedName{Name: resources.PVCName(clone), Namespace: namespace}, &created); !apierrors.IsNotFound(err) {
+				t.Errorf("clone PVC get error = %v, want it not to be created", err)
+			}
+		})
+	}
+}
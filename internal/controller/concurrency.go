@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Controller names as passed to Named, used to key the per-controller
+// settings of ControllerOptions.
+const (
+	ControllerNameKlausInstance  = "klausinstance"
+	ControllerNameKlausJob       = "klausjob"
+	ControllerNameKlausMCPServer = "klausmcpserver"
+	ControllerNameKlausCronJob   = "klauscronjob"
+	ControllerNameKlausTrigger   = "klaustrigger"
+	ControllerNameKlausSkillPack = "klausskillpack"
+
+	ControllerNameKlausFleetOperation = "klausfleetoperation"
+)
+
+// Work queue rate limiter defaults, matching the controller-runtime ones.
+const (
+	DefaultRateLimiterBaseDelay = 5 * time.Millisecond
+	DefaultRateLimiterMaxDelay  = 1000 * time.Second
+	DefaultRateLimiterQPS       = 10
+	DefaultRateLimiterBurst     = 100
+)
+
+// ControllerOptions tunes the work queues of the controllers. The zero value
+// keeps the controller-runtime defaults: one worker per controller and the
+// default rate limiter.
+type ControllerOptions struct {
+	// MaxConcurrentReconciles is the number of workers by controller name.
+	MaxConcurrentReconciles map[string]int
+
+	// DefaultMaxConcurrentReconciles is the number of workers of the
+	// controllers not in MaxConcurrentReconciles; 0 means one.
+	DefaultMaxConcurrentReconciles int
+
+	// BaseDelay and MaxDelay bound the exponential backoff of a single
+	// failing object, so one broken instance does not delay the others.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// QPS and Burst limit the rate at which the queue hands out objects
+	// overall, e.g. when all instances are enqueued after a restart.
+	QPS   float64
+	Burst int
+}
+
+// For returns the controller-runtime options of the named controller.
+func (o ControllerOptions) For(name string) controller.Options {
+	opts := controller.Options{MaxConcurrentReconciles: o.DefaultMaxConcurrentReconciles}
+	if n, ok := o.MaxConcurrentReconciles[name]; ok {
+		opts.MaxConcurrentReconciles = n
+	}
+	if o.BaseDelay > 0 || o.MaxDelay > 0 || o.QPS > 0 || o.Burst > 0 {
+		opts.RateLimiter = o.rateLimiter()
+	}
+	return opts
+}
+
+// rateLimiter combines the per-object backoff with the overall bucket like
+// workqueue.DefaultTypedControllerRateLimiter, with unset values defaulted.
+func (o ControllerOptions) rateLimiter() workqueue.TypedRateLimiter[reconcile.Request] {
+	base, maxDelay := o.BaseDelay, o.MaxDelay
+	if base <= 0 {
+		base = DefaultRateLimiterBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultRateLimiterMaxDelay
+	}
+	qps, burst := o.QPS, o.Burst
+	if qps <= 0 {
+		qps = DefaultRateLimiterQPS
+	}
+	if burst <= 0 {
+		burst = DefaultRateLimiterBurst
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](base, maxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
+
+// ParseMaxConcurrentReconciles parses a comma-separated list of worker
+// counts into o. Entries of the form name=N set the count of one
+// controller, a bare N sets the default of the others, e.g.
+// "2,klausinstance=8".
+func (o *ControllerOptions) ParseMaxConcurrentReconciles(s string) error {
+	known := []string{ControllerNameKlausInstance, ControllerNameKlausJob, ControllerNameKlausMCPServer,
+		ControllerNameKlausCronJob, ControllerNameKlausTrigger, ControllerNameKlausSkillPack, ControllerNameKlausFleetOperation}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, named := strings.Cut(entry, "=")
+		if !named {
+			value = name
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid worker count %q: must be a positive integer", entry)
+		}
+		if !named {
+			o.DefaultMaxConcurrentReconciles = n
+			continue
+		}
+		name = strings.TrimSpace(name)
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("unknown controller %q, must be one of %s", name, strings.Join(known, ", "))
+		}
+		if o.MaxConcurrentReconciles == nil {
+			o.MaxConcurrentReconciles = map[string]int{}
+		}
+		o.MaxConcurrentReconciles[name] = n
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestParseMaxConcurrentReconciles(t *testing.T) {
+	var o ControllerOptions
+	if err := o.ParseMaxConcurrentReconciles(" 2, klausinstance=8,klausjob=4 "); err != nil {
+		t.Fatalf("ParseMaxConcurrentReconciles() error = %v", err)
+	}
+	for name, want := range map[string]int{
+		ControllerNameKlausInstance:  8,
+		ControllerNameKlausJob:       4,
+		ControllerNameKlausMCPServer: 2,
+	} {
+		if got := o.For(name).MaxConcurrentReconciles; got != want {
+			t.Errorf("%s: MaxConcurrentReconciles = %d, want %d", name, got, want)
+		}
+	}
+
+	for _, s := range []string{"klausinstance=0", "klausinstance=many", "klausfoo=2", "-1"} {
+		if err := (&ControllerOptions{}).ParseMaxConcurrentReconciles(s); err == nil {
+			t.Errorf("ParseMaxConcurrentReconciles(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestControllerOptionsFor_RateLimiter(t *testing.T) {
+	opts := ControllerOptions{}.For(ControllerNameKlausInstance)
+	if opts.MaxConcurrentReconciles != 0 || opts.RateLimiter != nil {
+		t.Errorf("options = %+v, want the controller-runtime defaults", opts)
+	}
+
+	limiter := ControllerOptions{BaseDelay: time.Second, MaxDelay: 4 * time.Second}.For(ControllerNameKlausInstance).RateLimiter
+	if limiter == nil {
+		t.Fatal("no rate limiter configured")
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "klaus-system", Name: "my-agent"}}
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if got := limiter.When(req); got != want {
+			t.Errorf("When() = %s, want %s", got, want)
+		}
+	}
+	limiter.Forget(req)
+	if got := limiter.When(req); got != time.Second {
+		t.Errorf("When() after Forget = %s, want the base delay", got)
+	}
+}
@@ -18,13 +18,80 @@ const (
 	// ConditionDeploymentReady indicates the Deployment has been created/updated.
 	ConditionDeploymentReady = "DeploymentReady"
 
-	// ConditionMCPServerReady indicates the MCPServer CRD has been created in muster.
+	// ConditionDeploymentProgressing mirrors the Deployment's Progressing condition.
+	ConditionDeploymentProgressing = "DeploymentProgressing"
+
+	// ConditionDeploymentReplicaFailure mirrors the Deployment's ReplicaFailure condition.
+	ConditionDeploymentReplicaFailure = "DeploymentReplicaFailure"
+
+	// ConditionPodHealthy indicates no instance pod fails to be scheduled,
+	// pull its images or start its containers. The reason and message of a
+	// failure are also reported in status.failureReason and failureMessage.
+	ConditionPodHealthy = "PodHealthy"
+
+	// ConditionMCPServerReady indicates the instance is registered with its
+	// MCP gateway, e.g. the MCPServer CRD has been created in muster.
 	ConditionMCPServerReady = "MCPServerReady"
+
+	// ConditionCapabilitiesSupported indicates whether the agent image
+	// supports every feature requested by the instance spec.
+	ConditionCapabilitiesSupported = "CapabilitiesSupported"
+
+	// ConditionExposed indicates the Ingress or HTTPRoute requested by
+	// spec.expose has been reconciled.
+	ConditionExposed = "Exposed"
+
+	// ConditionArchitectureSupported indicates whether the container image
+	// is published for an architecture the cluster nodes run.
+	ConditionArchitectureSupported = "ArchitectureSupported"
+
+	// ConditionTLSReady indicates the serving and client certificates for
+	// spec.tls have been issued.
+	ConditionTLSReady = "TLSReady"
+
+	// ConditionDependenciesReady indicates every instance in spec.dependsOn
+	// is Running.
+	ConditionDependenciesReady = "DependenciesReady"
+
+	// ConditionPluginsSynced indicates every plugin has been synced into the
+	// shared plugins PVC when plugins are not served as image volumes.
+	ConditionPluginsSynced = "PluginsSynced"
+
+	// ConditionBudgetExceeded indicates the recorded cost of the instance
+	// reached spec.claude.maxBudgetUSD and the instance is suspended.
+	ConditionBudgetExceeded = "BudgetExceeded"
+
+	// ConditionWorkspaceResized indicates the workspace PVC has the size
+	// requested by spec.workspace.size. It is only set once the size was
+	// changed.
+	ConditionWorkspaceResized = "WorkspaceResized"
+
+	// ConditionArtifactVerificationFailed indicates the signature of a
+	// personality, toolchain or plugin artifact could not be verified
+	// against the artifact verification policy. False once every artifact
+	// the policy covers was verified.
+	ConditionArtifactVerificationFailed = "ArtifactVerificationFailed"
+
+	// ConditionAPIKeyReady indicates the Anthropic API key Secret was
+	// copied to the instance namespace. The reason says whether the
+	// per-owner Secret or the shared one is used.
+	ConditionAPIKeyReady = "APIKeyReady"
+
+	// ConditionResultValid indicates whether status.result of an instance
+	// with spec.claude.jsonSchema matches the schema. It is not set without
+	// a schema or a result.
+	ConditionResultValid = "ResultValid"
+
+	// ConditionDegraded indicates the instance runs with less than its
+	// spec asks for, e.g. without the soul of its personality when the
+	// operator runs offline with --offline-fail-open.
+	ConditionDegraded = "Degraded"
 )
 
-// setCondition updates or appends a condition on the instance status.
-func setCondition(instance *klausv1alpha1.KlausInstance, condType string, status metav1.ConditionStatus, reason, message string) {
-	apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+// setCondition updates or appends a condition on the instance status and
+// reports whether it changed.
+func setCondition(instance *klausv1alpha1.KlausInstance, condType string, status metav1.ConditionStatus, reason, message string) bool {
+	return apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
 		Type:               condType,
 		Status:             status,
 		ObservedGeneration: instance.Generation,
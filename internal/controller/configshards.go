@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// deleteConfigShards deletes the configuration ConfigMaps of an instance
+// from the given shard index on, left behind when its configuration shrank
+// or when it is deleted. Shards are numbered without gaps, so it stops at
+// the first one that does not exist.
+func deleteConfigShards(ctx context.Context, c client.Client, instance *klausv1alpha1.KlausInstance, namespace string, from int) error {
+	for i := max(from, 1); ; i++ {
+		cm := &corev1.ConfigMap{}
+		err := c.Get(ctx, types.NamespacedName{Name: resources.ConfigMapShardName(instance, i), Namespace: namespace}, cm)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("getting ConfigMap %s: %w", resources.ConfigMapShardName(instance, i), err)
+		}
+		if err := c.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting ConfigMap %s: %w", cm.Name, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestDeleteConfigShards(t *testing.T) {
+	instance := testInstance()
+	namespace := resources.UserNamespace(instance.Spec.Owner)
+	builder := fake.NewClientBuilder().WithScheme(testScheme(t))
+	for i := range 3 {
+		builder = builder.WithObjects(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: resources.ConfigMapShardName(instance, i), Namespace: namespace,
+		}})
+	}
+	c := builder.Build()
+	ctx := context.Background()
+
+	if err := deleteConfigShards(ctx, c, instance, namespace, 2); err != nil {
+		t.Fatalf("deleteConfigShards() error = %v", err)
+	}
+	for i, wantExists := range []bool{true, true, false} {
+		err := c.Get(ctx, types.NamespacedName{Name: resources.ConfigMapShardName(instance, i), Namespace: namespace}, &corev1.ConfigMap{})
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("ConfigMap %d exists = %t, want %t (%v)", i, exists, wantExists, err)
+		}
+	}
+
+	// The first ConfigMap is never deleted as a shard.
+	if err := deleteConfigShards(ctx, c, instance, namespace, 0); err != nil {
+		t.Fatalf("deleteConfigShards() error = %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: resources.ConfigMapName(instance), Namespace: namespace}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("first ConfigMap deleted: %v", err)
+	}
+	err := c.Get(ctx, types.NamespacedName{Name: resources.ConfigMapShardName(instance, 1), Namespace: namespace}, &corev1.ConfigMap{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("shard 1 not deleted: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// DependsOnIndexField is the field path used by the field indexer to look up
+// KlausInstances by the instances in their spec.dependsOn.
+const DependsOnIndexField = "spec.dependsOn.name"
+
+// dependencyRequeueInterval is how often an instance waiting for its
+// dependencies is re-checked, in case a dependency state change is missed.
+const dependencyRequeueInterval = 30 * time.Second
+
+// IndexDependsOn extracts the dependency names from a KlausInstance for the
+// DependsOnIndexField field indexer.
+func IndexDependsOn(obj client.Object) []string {
+	instance, ok := obj.(*klausv1alpha1.KlausInstance)
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(instance.Spec.DependsOn))
+	for _, dep := range instance.Spec.DependsOn {
+		names = append(names, dep.Name)
+	}
+	return names
+}
+
+// EnqueueDependentInstances returns a map function that enqueues the
+// KlausInstances depending on the given KlausInstance, so they start once it
+// is Running. It uses the DependsOnIndexField field indexer.
+func EnqueueDependentInstances(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var instanceList klausv1alpha1.KlausInstanceList
+		if err := c.List(ctx, &instanceList,
+			client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{DependsOnIndexField: obj.GetName()},
+		); err != nil {
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(instanceList.Items))
+		for _, inst := range instanceList.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: inst.Name, Namespace: inst.Namespace},
+			})
+		}
+		return requests
+	}
+}
+
+// reconcileDependencies checks the instances in spec.dependsOn and sets the
+// DependenciesReady condition. It returns the dependencies whose endpoints
+// are injected and whether the instance must wait: an instance whose
+// Deployment does not exist yet waits until every dependency is Running,
+// while a started instance keeps running and only reports the condition.
+func (r *KlausInstanceReconciler) reconcileDependencies(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) ([]resources.Dependency, bool, error) {
+	if len(merged.Spec.DependsOn) == 0 {
+		apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionDependenciesReady)
+		return nil, false, nil
+	}
+
+	if err := r.checkDependencyCycle(ctx, merged); err != nil {
+		setCondition(instance, ConditionDependenciesReady, metav1.ConditionFalse, "InvalidDependency", err.Error())
+		return nil, false, err
+	}
+
+	deps := make([]resources.Dependency, 0, len(merged.Spec.DependsOn))
+	var notReady []string
+	for _, ref := range merged.Spec.DependsOn {
+		var dependency klausv1alpha1.KlausInstance
+		err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: merged.Namespace}, &dependency)
+		if apierrors.IsNotFound(err) {
+			notReady = append(notReady, ref.Name+" (not found)")
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("getting dependency %q: %w", ref.Name, err)
+		}
+		if dependency.Spec.Owner != merged.Spec.Owner {
+			err := fmt.Errorf("dependency %q is owned by %q, not %q", ref.Name, dependency.Spec.Owner, merged.Spec.Owner)
+			setCondition(instance, ConditionDependenciesReady, metav1.ConditionFalse, "InvalidDependency", err.Error())
+			return nil, false, err
+		}
+		deps = append(deps, resources.NewDependency(&dependency, namespace))
+		if dependency.Status.State != klausv1alpha1.InstanceStateRunning {
+			state := string(dependency.Status.State)
+			if state == "" {
+				state = string(klausv1alpha1.InstanceStatePending)
+			}
+			notReady = append(notReady, ref.Name+" ("+state+")")
+		}
+	}
+
+	if len(notReady) == 0 {
+		setCondition(instance, ConditionDependenciesReady, metav1.ConditionTrue, "Running", "All dependencies are Running")
+		return deps, false, nil
+	}
+
+	message := "Waiting for dependencies: " + strings.Join(notReady, ", ")
+	previous := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDependenciesReady)
+	changed := previous == nil || previous.Status != metav1.ConditionFalse ||
+		previous.Reason != "DependenciesNotReady" || previous.Message != message
+	setCondition(instance, ConditionDependenciesReady, metav1.ConditionFalse, "DependenciesNotReady", message)
+
+	// Stopped instances have nothing to start.
+	if resources.IsStopped(merged) {
+		return deps, false, nil
+	}
+	var dep appsv1.Deployment
+	err := r.Get(ctx, types.NamespacedName{Name: resources.DeploymentName(merged), Namespace: namespace}, &dep)
+	if apierrors.IsNotFound(err) {
+		return deps, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("getting deployment: %w", err)
+	}
+	// Warn only when the condition changes, not on every resync.
+	if changed {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "DependenciesNotReady", message)
+	}
+	return deps, false, nil
+}
+
+// checkDependencyCycle walks spec.dependsOn transitively and returns an
+// error if the instance depends on itself. Missing instances end the walk;
+// they are reported as not ready instead.
+func (r *KlausInstanceReconciler) checkDependencyCycle(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	visited := map[string]bool{}
+	var walk func(path []string, deps []klausv1alpha1.InstanceDependency) error
+	walk = func(path []string, deps []klausv1alpha1.InstanceDependency) error {
+		for _, ref := range deps {
+			if ref.Name == instance.Name {
+				return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, ref.Name), " -> "))
+			}
+			if visited[ref.Name] {
+				continue
+			}
+			visited[ref.Name] = true
+
+			var dependency klausv1alpha1.KlausInstance
+			if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: instance.Namespace}, &dependency); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return fmt.Errorf("getting dependency %q: %w", ref.Name, err)
+			}
+			if err := walk(append(path, ref.Name), dependency.Spec.DependsOn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk([]string{instance.Name}, instance.Spec.DependsOn)
+}
+
+// updateStatusWaitingForDependencies marks the instance Pending until its
+// dependencies are Running. The instance is requeued when a dependency's
+// state changes and, as a fallback, after dependencyRequeueInterval.
+func (r *KlausInstanceReconciler) updateStatusWaitingForDependencies(ctx context.Context, instance *klausv1alpha1.KlausInstance) (ctrl.Result, error) {
+	instance.Status.State = klausv1alpha1.InstanceStatePending
+	instance.Status.ObservedGeneration = instance.Generation
+	message := "Waiting for dependencies"
+	if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDependenciesReady); cond != nil {
+		message = cond.Message
+	}
+	setCondition(instance, ConditionReady, metav1.ConditionFalse, "WaitingForDependencies", message)
+
+	if err := r.patchStatus(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: dependencyRequeueInterval}, nil
+}
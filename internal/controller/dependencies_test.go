@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const dependencyTestNamespace = "klaus-user-user-example-com"
+
+func dependencyInstance(name, owner string, state klausv1alpha1.InstanceState, dependsOn ...string) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance(name, owner)
+	instance.Status.State = state
+	for _, dep := range dependsOn {
+		instance.Spec.DependsOn = append(instance.Spec.DependsOn, klausv1alpha1.InstanceDependency{Name: dep})
+	}
+	return instance
+}
+
+func dependencyTestReconciler(t *testing.T, objs ...client.Object) *KlausInstanceReconciler {
+	t.Helper()
+	return testReconciler(t, testScheme(t, appsv1.AddToScheme), objs...)
+}
+
+func dependenciesCondition(instance *klausv1alpha1.KlausInstance) *metav1.Condition {
+	return apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDependenciesReady)
+}
+
+func TestReconcileDependencies_None(t *testing.T) {
+	r := dependencyTestReconciler(t)
+	instance := dependencyInstance("implementer", "user@example.com", "")
+	setCondition(instance, ConditionDependenciesReady, metav1.ConditionFalse, "DependenciesNotReady", "stale")
+
+	deps, waiting, err := r.reconcileDependencies(context.Background(), instance, instance.DeepCopy(), dependencyTestNamespace)
+	if err != nil || waiting || deps != nil {
+		t.Fatalf("reconcileDependencies() = %v, %v, %v; want nothing to do", deps, waiting, err)
+	}
+	if dependenciesCondition(instance) != nil {
+		t.Error("expected the DependenciesReady condition to be removed")
+	}
+}
+
+func TestReconcileDependencies_Running(t *testing.T) {
+	researcher := dependencyInstance("researcher", "user@example.com", klausv1alpha1.InstanceStateRunning)
+	r := dependencyTestReconciler(t, researcher)
+	instance := dependencyInstance("implementer", "user@example.com", "", "researcher")
+
+	deps, waiting, err := r.reconcileDependencies(context.Background(), instance, instance.DeepCopy(), dependencyTestNamespace)
+	if err != nil || waiting {
+		t.Fatalf("waiting = %v, err = %v; want ready", waiting, err)
+	}
+	want := resources.NewDependency(researcher, dependencyTestNamespace)
+	if len(deps) != 1 || deps[0] != want {
+		t.Errorf("deps = %+v, want [%+v]", deps, want)
+	}
+	if cond := dependenciesCondition(instance); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("condition = %+v, want True", cond)
+	}
+}
+
+func TestReconcileDependencies_Waiting(t *testing.T) {
+	r := dependencyTestReconciler(t, dependencyInstance("researcher", "user@example.com", klausv1alpha1.InstanceStatePending))
+	instance := dependencyInstance("implementer", "user@example.com", "", "researcher", "reviewer")
+
+	_, waiting, err := r.reconcileDependencies(context.Background(), instance, instance.DeepCopy(), dependencyTestNamespace)
+	if err != nil || !waiting {
+		t.Fatalf("waiting = %v, err = %v; want waiting", waiting, err)
+	}
+	cond := dependenciesCondition(instance)
+	if cond == nil || cond.Status != metav1.ConditionFalse ||
+		!strings.Contains(cond.Message, "researcher (Pending)") || !strings.Contains(cond.Message, "reviewer (not found)") {
+		t.Errorf("condition = %+v", cond)
+	}
+}
+
+func TestReconcileDependencies_StartedInstanceKeepsRunning(t *testing.T) {
+	instance := dependencyInstance("implementer", "user@example.com", klausv1alpha1.InstanceStateRunning, "researcher")
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: resources.DeploymentName(instance), Namespace: dependencyTestNamespace}}
+	r := dependencyTestReconciler(t, dep, dependencyInstance("researcher", "user@example.com", klausv1alpha1.InstanceStateError))
+	recorder := testRecorder(r)
+
+	deps, waiting, err := r.reconcileDependencies(context.Background(), instance, instance.DeepCopy(), dependencyTestNamespace)
+	if err != nil || waiting {
+		t.Fatalf("waiting = %v, err = %v; want the started instance to continue", waiting, err)
+	}
+	if len(deps) != 1 {
+		t.Errorf("deps = %+v, want the researcher endpoint kept", deps)
+	}
+	if cond := dependenciesCondition(instance); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("condition = %+v, want False", cond)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "DependenciesNotReady") {
+			t.Errorf("event = %q", event)
+		}
+	default:
+		t.Error("expected a DependenciesNotReady event")
+	}
+
+	// An unchanged condition is not reported again.
+	if _, _, err := r.reconcileDependencies(context.Background(), instance, instance.DeepCopy(), dependencyTestNamespace); err != nil {
+		t.Fatalf("reconcileDependencies() error = %v", err)
+	}
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("unexpected event %q for an unchanged condition", event)
+	default:
+	}
+}
+
+func TestReconcileDependencies_StoppedDoesNotWait(t *testing.T) {
+	r := dependencyTestReconciler(t)
+	instance := dependencyInstance("implementer", "user@example.com", "", "researcher")
+	instance.Spec.Stopped = true
+
+	if _, waiting, err := r.reconcileDependencies(context.Background(), instance, instance.DeepCopy(), dependencyTestNamespace); err != nil || waiting {
+		t.Errorf("waiting = %v, err = %v; want a stopped instance not to wait", waiting, err)
+	}
+}
+
+func TestReconcileDependencies_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		objs     []client.Object
+		instance *klausv1alpha1.KlausInstance
+		wantErr  string
+	}{
+		{
+			name:     "other owner",
+			objs:     []client.Object{dependencyInstance("researcher", "other@example.com", klausv1alpha1.InstanceStateRunning)},
+			instance: dependencyInstance("implementer", "user@example.com", "", "researcher"),
+			wantErr:  `owned by "other@example.com"`,
+		},
+		{
+			name:     "self",
+			instance: dependencyInstance("implementer", "user@example.com", "", "implementer"),
+			wantErr:  "dependency cycle: implementer -> implementer",
+		},
+		{
+			name: "transitive cycle",
+			objs: []client.Object{
+				dependencyInstance("researcher", "user@example.com", "", "reviewer"),
+				dependencyInstance("reviewer", "user@example.com", "", "implementer"),
+			},
+			instance: dependencyInstance("implementer", "user@example.com", "", "researcher"),
+			wantErr:  "dependency cycle: implementer -> researcher -> reviewer -> implementer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := dependencyTestReconciler(t, tt.objs...)
+			_, _, err := r.reconcileDependencies(context.Background(), tt.instance, tt.instance.DeepCopy(), dependencyTestNamespace)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if cond := dependenciesCondition(tt.instance); cond == nil || cond.Reason != "InvalidDependency" {
+				t.Errorf("condition = %+v, want reason InvalidDependency", cond)
+			}
+		})
+	}
+}
+
+func TestUpdateStatusWaitingForDependencies(t *testing.T) {
+	instance := dependencyInstance("implementer", "user@example.com", "", "researcher")
+	r := dependencyTestReconciler(t, instance)
+	setCondition(instance, ConditionDependenciesReady, metav1.ConditionFalse, "DependenciesNotReady", "Waiting for dependencies: researcher (Pending)")
+
+	result, err := r.updateStatusWaitingForDependencies(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != dependencyRequeueInterval {
+		t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, dependencyRequeueInterval)
+	}
+	ready := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionReady)
+	if instance.Status.State != klausv1alpha1.InstanceStatePending || ready == nil ||
+		ready.Reason != "WaitingForDependencies" || !strings.Contains(ready.Message, "researcher (Pending)") {
+		t.Errorf("state = %q, ready = %+v", instance.Status.State, ready)
+	}
+}
+
+func TestEnqueueDependentInstances(t *testing.T) {
+	researcher := dependencyInstance("researcher", "user@example.com", klausv1alpha1.InstanceStateRunning)
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(
+			researcher,
+			dependencyInstance("implementer", "user@example.com", "", "researcher"),
+			dependencyInstance("unrelated", "user@example.com", ""),
+		).
+		WithIndex(&klausv1alpha1.KlausInstance{}, DependsOnIndexField, IndexDependsOn).
+		Build()
+
+	requests := EnqueueDependentInstances(c)(context.Background(), researcher)
+	if len(requests) != 1 || requests[0].Name != "implementer" || requests[0].Namespace != "klaus-system" {
+		t.Errorf("requests = %v, want only implementer", requests)
+	}
+}
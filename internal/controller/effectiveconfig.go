@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// reconcileEffectiveConfig publishes the effective spec of an instance, the
+// merged spec with its references resolved, in the effective configuration
+// ConfigMap and records its checksum in status.effectiveConfigHash, so
+// users can see which configuration the instance runs.
+func (r *KlausInstanceReconciler) reconcileEffectiveConfig(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) error {
+	desired, err := resources.BuildEffectiveConfigMap(merged, namespace, r.instanceImage(merged))
+	if err != nil {
+		return err
+	}
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
+		existing.Data = desired.Data
+		existing.Labels = desired.Labels
+		return r.setInstanceOwner(merged, existing)
+	}); err != nil {
+		return err
+	}
+	instance.Status.EffectiveConfigHash = resources.EffectiveConfigHash(desired)
+	return nil
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestReconcileEffectiveConfig(t *testing.T) {
+	instance := testInstance()
+	instance.Spec.ExtraEnv = []corev1.EnvVar{{Name: "API_TOKEN", Value: "s3cret"}}
+	namespace := resources.UserNamespace(instance.Spec.Owner)
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+	r := &KlausInstanceReconciler{Client: c, KlausImage: "registry.example.com/klaus:v1"}
+	ctx := context.Background()
+
+	if err := r.reconcileEffectiveConfig(ctx, instance, instance, namespace); err != nil {
+		t.Fatalf("reconcileEffectiveConfig: %v", err)
+	}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Name: resources.EffectiveConfigName(instance), Namespace: namespace}, &cm); err != nil {
+		t.Fatalf("getting effective config: %v", err)
+	}
+	spec := cm.Data[resources.EffectiveConfigKey]
+	if !strings.Contains(spec, "image: registry.example.com/klaus:v1") {
+		t.Errorf("effective spec = %s, want the image the pods run", spec)
+	}
+	if strings.Contains(spec, "s3cret") {
+		t.Errorf("effective spec = %s, want the extraEnv value redacted", spec)
+	}
+	if hash := instance.Status.EffectiveConfigHash; hash == "" || hash != resources.EffectiveConfigHash(&cm) {
+		t.Errorf("status.effectiveConfigHash = %q, want the hash of the ConfigMap", hash)
+	}
+
+	// The ConfigMap follows spec changes.
+	previous := instance.Status.EffectiveConfigHash
+	instance.Spec.Claude.Model = "claude-opus-4-20250514"
+	if err := r.reconcileEffectiveConfig(ctx, instance, instance, namespace); err != nil {
+		t.Fatalf("reconcileEffectiveConfig: %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: resources.EffectiveConfigName(instance), Namespace: namespace}, &cm); err != nil {
+		t.Fatalf("getting effective config: %v", err)
+	}
+	if !strings.Contains(cm.Data[resources.EffectiveConfigKey], "model: claude-opus-4-20250514") || instance.Status.EffectiveConfigHash == previous {
+		t.Errorf("effective config not updated: hash %q, spec %s", instance.Status.EffectiveConfigHash, cm.Data[resources.EffectiveConfigKey])
+	}
+}
+
+func TestReconcileEffectiveConfig_KeepsHashOnError(t *testing.T) {
+	instance := testInstance()
+	instance.Status.EffectiveConfigHash = "previous"
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(context.Context, client.WithWatch, client.Object, ...client.CreateOption) error {
+			return errors.New("create failed")
+		},
+	}).Build()
+	r := &KlausInstanceReconciler{Client: c}
+
+	if err := r.reconcileEffectiveConfig(context.Background(), instance, instance, resources.UserNamespace(instance.Spec.Owner)); err == nil {
+		t.Fatal("reconcileEffectiveConfig succeeded, want the create error")
+	}
+	if instance.Status.EffectiveConfigHash != "previous" {
+		t.Errorf("status.effectiveConfigHash = %q, want it unchanged", instance.Status.EffectiveConfigHash)
+	}
+}
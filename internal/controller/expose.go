@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// Reasons of the Exposed condition. The success reasons record which kind of
+// resource exists so that a later reconcile only cleans up what may be there.
+const (
+	reasonIngressReconciled   = "IngressReconciled"
+	reasonHTTPRouteReconciled = "HTTPRouteReconciled"
+	reasonExposeError         = "ReconcileError"
+)
+
+// reconcileExpose creates the Ingress or HTTPRoute requested by spec.expose
+// and removes the one that is no longer requested. Failures are reported on
+// the Exposed condition but do not fail the reconcile: the instance remains
+// reachable inside the cluster.
+func (r *KlausInstanceReconciler) reconcileExpose(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) {
+	previous := ""
+	if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionExposed); cond != nil {
+		previous = cond.Reason
+	}
+	mayHaveIngress := previous == reasonIngressReconciled || previous == reasonExposeError
+	mayHaveHTTPRoute := previous == reasonHTTPRouteReconciled || previous == reasonExposeError
+
+	var err error
+	reason := ""
+	switch resources.ExposeType(merged) {
+	case klausv1alpha1.ExposeTypeIngress:
+		reason = reasonIngressReconciled
+		if mayHaveHTTPRoute {
+			err = r.deleteHTTPRoute(ctx, merged, namespace)
+		}
+		if err == nil {
+			err = r.reconcileIngress(ctx, instance, resources.BuildIngress(merged, namespace))
+		}
+	case klausv1alpha1.ExposeTypeHTTPRoute:
+		reason = reasonHTTPRouteReconciled
+		if mayHaveIngress {
+			err = r.deleteIngress(ctx, merged, namespace)
+		}
+		if err == nil {
+			err = r.reconcileHTTPRoute(ctx, instance, resources.BuildHTTPRoute(merged, namespace))
+		}
+	default:
+		if mayHaveIngress {
+			err = r.deleteIngress(ctx, merged, namespace)
+		}
+		if err == nil && mayHaveHTTPRoute {
+			err = r.deleteHTTPRoute(ctx, merged, namespace)
+		}
+		if err == nil {
+			apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionExposed)
+			return
+		}
+	}
+
+	if err != nil {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "ExposeError", err.Error())
+		setCondition(instance, ConditionExposed, metav1.ConditionFalse, reasonExposeError, err.Error())
+		return
+	}
+	setCondition(instance, ConditionExposed, metav1.ConditionTrue, reason,
+		"Exposed at "+resources.ExternalEndpoint(merged))
+}
+
+func (r *KlausInstanceReconciler) reconcileIngress(ctx context.Context, instance *klausv1alpha1.KlausInstance, desired *networkingv1.Ingress) error {
+	existing := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
+		existing.Spec = desired.Spec
+		existing.Labels = desired.Labels
+		existing.Annotations = desired.Annotations
+		return r.setInstanceOwner(instance, existing)
+	})
+	if err != nil {
+		return fmt.Errorf("reconciling Ingress %s: %w", desired.Name, err)
+	}
+	if op == controllerutil.OperationResultCreated {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingIngress", "Created Ingress "+desired.Name)
+	}
+	return nil
+}
+
+func (r *KlausInstanceReconciler) reconcileHTTPRoute(ctx context.Context, instance *klausv1alpha1.KlausInstance, desired *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(resources.HTTPRouteGVK)
+	err := r.Get(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, existing)
+	if apierrors.IsNotFound(err) {
+		if err := r.setInstanceOwner(instance, desired); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			return fmt.Errorf("creating HTTPRoute %s: %w", desired.GetName(), err)
+		}
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingHTTPRoute", "Created HTTPRoute "+desired.GetName())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching HTTPRoute %s: %w", desired.GetName(), err)
+	}
+
+	existing.Object["spec"] = desired.Object["spec"]
+	existing.SetLabels(desired.GetLabels())
+	existing.SetAnnotations(desired.GetAnnotations())
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("updating HTTPRoute %s: %w", desired.GetName(), err)
+	}
+	return nil
+}
+
+func (r *KlausInstanceReconciler) deleteIngress(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: resources.ExposeName(instance), Namespace: namespace}}
+	if err := r.Delete(ctx, ingress); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting Ingress %s: %w", ingress.Name, err)
+	}
+	return nil
+}
+
+// deleteHTTPRoute removes the instance HTTPRoute. A cluster without the
+// Gateway API CRDs cannot have one, so a missing kind is not an error.
+func (r *KlausInstanceReconciler) deleteHTTPRoute(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(resources.HTTPRouteGVK)
+	route.SetName(resources.ExposeName(instance))
+	route.SetNamespace(namespace)
+	if err := r.Delete(ctx, route); err != nil && !apierrors.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+		return fmt.Errorf("deleting HTTPRoute %s: %w", route.GetName(), err)
+	}
+	return nil
+}
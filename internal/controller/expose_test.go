@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const exposeTestNamespace = "klaus-user-user-example-com"
+
+func exposeTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := testScheme(t)
+	if err := networkingv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding networkingv1 to scheme: %v", err)
+	}
+	scheme.AddKnownTypeWithName(resources.HTTPRouteGVK, &unstructured.Unstructured{})
+	listGVK := resources.HTTPRouteGVK
+	listGVK.Kind += "List"
+	scheme.AddKnownTypeWithName(listGVK, &unstructured.UnstructuredList{})
+	return scheme
+}
+
+func exposeTestInstance(expose *klausv1alpha1.ExposeConfig) *klausv1alpha1.KlausInstance {
+	instance := testInstance()
+	instance.Spec.Expose = expose
+	return instance
+}
+
+func getHTTPRoute(t *testing.T, r *KlausInstanceReconciler) error {
+	t.Helper()
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(resources.HTTPRouteGVK)
+	return r.Get(context.Background(), types.NamespacedName{Name: "my-agent", Namespace: exposeTestNamespace}, route)
+}
+
+func TestReconcileExpose_CreatesIngress(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(exposeTestScheme(t)).Build()
+	r := &KlausInstanceReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+	instance := exposeTestInstance(&klausv1alpha1.ExposeConfig{Hostname: "klaus.example.com", TLSSecretName: "klaus-tls"})
+
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+
+	ingress := &networkingv1.Ingress{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "my-agent", Namespace: exposeTestNamespace}, ingress); err != nil {
+		t.Fatalf("expected Ingress to be created: %v", err)
+	}
+	if ingress.Spec.Rules[0].Host != "klaus.example.com" {
+		t.Errorf("host = %q", ingress.Spec.Rules[0].Host)
+	}
+
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionExposed)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != reasonIngressReconciled {
+		t.Fatalf("unexpected Exposed condition: %+v", cond)
+	}
+	if cond.Message != "Exposed at https://klaus.example.com" {
+		t.Errorf("message = %q", cond.Message)
+	}
+}
+
+func TestReconcileExpose_UpdatesIngress(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(exposeTestScheme(t)).Build()
+	r := &KlausInstanceReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+	instance := exposeTestInstance(&klausv1alpha1.ExposeConfig{Hostname: "old.example.com"})
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+
+	instance.Spec.Expose.Hostname = "new.example.com"
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+
+	ingress := &networkingv1.Ingress{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "my-agent", Namespace: exposeTestNamespace}, ingress); err != nil {
+		t.Fatalf("getting Ingress: %v", err)
+	}
+	if ingress.Spec.Rules[0].Host != "new.example.com" {
+		t.Errorf("host = %q, want new.example.com", ingress.Spec.Rules[0].Host)
+	}
+}
+
+func TestReconcileExpose_SwitchToHTTPRouteDeletesIngress(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(exposeTestScheme(t)).Build()
+	r := &KlausInstanceReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+	instance := exposeTestInstance(&klausv1alpha1.ExposeConfig{Hostname: "klaus.example.com"})
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+
+	instance.Spec.Expose.Type = klausv1alpha1.ExposeTypeHTTPRoute
+	instance.Spec.Expose.Gateway = &klausv1alpha1.GatewayReference{Name: "public", Namespace: "gateways"}
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+
+	err := c.Get(context.Background(), types.NamespacedName{Name: "my-agent", Namespace: exposeTestNamespace}, &networkingv1.Ingress{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected Ingress to be deleted, got %v", err)
+	}
+	if err := getHTTPRoute(t, r); err != nil {
+		t.Errorf("expected HTTPRoute to be created: %v", err)
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionExposed)
+	if cond == nil || cond.Reason != reasonHTTPRouteReconciled {
+		t.Errorf("unexpected Exposed condition: %+v", cond)
+	}
+}
+
+func TestReconcileExpose_RemovedCleansUp(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(exposeTestScheme(t)).Build()
+	r := &KlausInstanceReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+	instance := exposeTestInstance(&klausv1alpha1.ExposeConfig{
+		Type:     klausv1alpha1.ExposeTypeHTTPRoute,
+		Hostname: "klaus.example.com",
+		Gateway:  &klausv1alpha1.GatewayReference{Name: "public"},
+	})
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+	if err := getHTTPRoute(t, r); err != nil {
+		t.Fatalf("expected HTTPRoute to be created: %v", err)
+	}
+
+	instance.Spec.Expose = nil
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+
+	if err := getHTTPRoute(t, r); !apierrors.IsNotFound(err) {
+		t.Errorf("expected HTTPRoute to be deleted, got %v", err)
+	}
+	if apimeta.FindStatusCondition(instance.Status.Conditions, ConditionExposed) != nil {
+		t.Error("expected Exposed condition to be removed")
+	}
+}
+
+func TestReconcileExpose_NotExposedIsNoop(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(exposeTestScheme(t)).Build()
+	r := &KlausInstanceReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+	instance := exposeTestInstance(nil)
+
+	r.reconcileExpose(context.Background(), instance, instance, exposeTestNamespace)
+
+	if len(instance.Status.Conditions) != 0 {
+		t.Errorf("expected no conditions, got %+v", instance.Status.Conditions)
+	}
+}
+
+func TestPopulateCommonStatus_Endpoints(t *testing.T) {
+	r := &KlausInstanceReconciler{}
+	instance := exposeTestInstance(nil)
+
+	r.populateCommonStatus(instance, exposeTestNamespace, "")
+	internal := resources.ServiceEndpoint(instance, exposeTestNamespace)
+	if instance.Status.Endpoint != internal || instance.Status.ServiceEndpoint != internal {
+		t.Errorf("unexposed: endpoint = %q, serviceEndpoint = %q, want %q", instance.Status.Endpoint, instance.Status.ServiceEndpoint, internal)
+	}
+
+	instance.Spec.Expose = &klausv1alpha1.ExposeConfig{Hostname: "klaus.example.com", TLSSecretName: "klaus-tls"}
+	r.populateCommonStatus(instance, exposeTestNamespace, "")
+	if instance.Status.Endpoint != "https://klaus.example.com" {
+		t.Errorf("endpoint = %q, want external URL", instance.Status.Endpoint)
+	}
+	if instance.Status.ServiceEndpoint != internal {
+		t.Errorf("serviceEndpoint = %q, want %q", instance.Status.ServiceEndpoint, internal)
+	}
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// checkExtraEnvSecrets checks that the Secrets spec.extraEnv and
+// spec.extraEnvFrom of an instance read in namespace are labeled
+// resources.LabelExtraEnvSecret=true. In namespace-scoped mode the instance
+// pods share their namespace with the operator's Secret copies and other
+// owners' Secrets, which instance creators must not be able to mount.
+// Missing optional Secrets are left to the kubelet.
+func checkExtraEnvSecrets(ctx context.Context, c client.Reader, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	check := func(field, name string, optional *bool) error {
+		var secret corev1.Secret
+		err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &secret)
+		if apierrors.IsNotFound(err) && optional != nil && *optional {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("getting Secret %s/%s of %s: %w", namespace, name, field, err)
+		}
+		if secret.Labels[resources.LabelExtraEnvSecret] != "true" {
+			return fmt.Errorf("%s: Secret %s/%s is not labeled %s=true", field, namespace, name, resources.LabelExtraEnvSecret)
+		}
+		return nil
+	}
+
+	for i, e := range instance.Spec.ExtraEnv {
+		if e.ValueFrom == nil || e.ValueFrom.SecretKeyRef == nil {
+			continue
+		}
+		ref := e.ValueFrom.SecretKeyRef
+		if err := check(fmt.Sprintf("spec.extraEnv[%d]", i), ref.Name, ref.Optional); err != nil {
+			return err
+		}
+	}
+	for i, source := range instance.Spec.ExtraEnvFrom {
+		if source.SecretRef == nil {
+			continue
+		}
+		if err := check(fmt.Sprintf("spec.extraEnvFrom[%d]", i), source.SecretRef.Name, source.SecretRef.Optional); err != nil {
+			return err
+		}
+	}
+	return nil
+}
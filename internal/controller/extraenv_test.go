@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestCheckExtraEnvSecrets(t *testing.T) {
+	labeled := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name: "team-env", Namespace: "klaus-system",
+		Labels: map[string]string{resources.LabelExtraEnvSecret: "true"},
+	}}
+	operator := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "anthropic-api-key", Namespace: "klaus-system"}}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(labeled, operator).Build()
+
+	secretEnv := func(name string, optional *bool) corev1.EnvVar {
+		return corev1.EnvVar{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: "token", Optional: optional,
+		}}}
+	}
+	secretEnvFrom := func(name string) corev1.EnvFromSource {
+		return corev1.EnvFromSource{Prefix: "TEAM_", SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		}}
+	}
+	tests := []struct {
+		name    string
+		spec    klausv1alpha1.KlausInstanceSpec
+		wantErr string
+	}{
+		{
+			name: "labeled Secrets",
+			spec: klausv1alpha1.KlausInstanceSpec{
+				ExtraEnv:     []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}, secretEnv("team-env", nil)},
+				ExtraEnvFrom: []corev1.EnvFromSource{secretEnvFrom("team-env")},
+			},
+		},
+		{
+			name:    "unlabeled Secret key",
+			spec:    klausv1alpha1.KlausInstanceSpec{ExtraEnv: []corev1.EnvVar{secretEnv("anthropic-api-key", nil)}},
+			wantErr: "spec.extraEnv[0]: Secret klaus-system/anthropic-api-key is not labeled",
+		},
+		{
+			name:    "unlabeled envFrom Secret",
+			spec:    klausv1alpha1.KlausInstanceSpec{ExtraEnvFrom: []corev1.EnvFromSource{secretEnvFrom("anthropic-api-key")}},
+			wantErr: "spec.extraEnvFrom[0]: Secret klaus-system/anthropic-api-key is not labeled",
+		},
+		{
+			name: "missing optional Secret",
+			spec: klausv1alpha1.KlausInstanceSpec{ExtraEnv: []corev1.EnvVar{secretEnv("missing", ptr.To(true))}},
+		},
+		{
+			name:    "missing Secret",
+			spec:    klausv1alpha1.KlausInstanceSpec{ExtraEnv: []corev1.EnvVar{secretEnv("missing", nil)}},
+			wantErr: "getting Secret klaus-system/missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &klausv1alpha1.KlausInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+				Spec:       tt.spec,
+			}
+			err := checkExtraEnvSecrets(context.Background(), c, instance, "klaus-system")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
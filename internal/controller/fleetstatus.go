@@ -0,0 +1,355 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// DefaultFleetStatusInterval is how often the KlausFleetStatus singleton is
+// refreshed unless configured otherwise.
+const DefaultFleetStatusInterval = time.Minute
+
+const (
+	// maxFleetErrorReasons bounds the error reason leaderboard.
+	maxFleetErrorReasons = 10
+
+	// maxFleetEvents bounds the recent events list.
+	maxFleetEvents = 20
+
+	// fleetStateUnknown counts objects without a reported state.
+	fleetStateUnknown = "Unknown"
+)
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausfleetstatuses,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausfleetstatuses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list
+
+// FleetStatusReporter is a leader-elected manager runnable that periodically
+// aggregates the state of all KlausInstances and KlausJobs into the
+// KlausFleetStatus singleton, so the health of the fleet can be read with
+// kubectl alone.
+type FleetStatusReporter struct {
+	Client client.Client
+
+	// APIReader lists Events without starting an informer for them.
+	APIReader client.Reader
+
+	// Namespace is the namespace the singleton is maintained in.
+	Namespace string
+
+	// Interval is the refresh interval.
+	Interval time.Duration
+
+	// OCICacheDir is the on-disk OCI cache directory of the operator, empty
+	// when the cache is disabled.
+	OCICacheDir string
+}
+
+// NeedLeaderElection ensures only the leader writes the singleton.
+func (f *FleetStatusReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs Refresh every Interval; see periodic.
+func (f *FleetStatusReporter) Start(ctx context.Context) error {
+	return periodic{
+		name:            "fleet-status",
+		interval:        f.Interval,
+		defaultInterval: DefaultFleetStatusInterval,
+		action:          "refreshing fleet status",
+		fn:              f.Refresh,
+	}.Start(ctx)
+}
+
+// Refresh recomputes the fleet status and writes it to the singleton,
+// creating the singleton if it does not exist.
+func (f *FleetStatusReporter) Refresh(ctx context.Context) error {
+	var instances klausv1alpha1.KlausInstanceList
+	if err := f.Client.List(ctx, &instances); err != nil {
+		return fmt.Errorf("listing KlausInstances: %w", err)
+	}
+	var jobs klausv1alpha1.KlausJobList
+	if err := f.Client.List(ctx, &jobs); err != nil {
+		return fmt.Errorf("listing KlausJobs: %w", err)
+	}
+	events, err := f.listKlausEvents(ctx, instances.Items, jobs.Items)
+	if err != nil {
+		return err
+	}
+
+	status := summarizeFleet(instances.Items, jobs.Items, events)
+	status.OCICache = ociCacheStats(f.OCICacheDir)
+	status.ObservedAt = &metav1.Time{Time: time.Now()}
+
+	fleet, err := f.ensureSingleton(ctx)
+	if err != nil {
+		return err
+	}
+	fleet.Status = status
+	if err := patchObjectStatus(ctx, f.Client, f.APIReader, fleet); err != nil {
+		return fmt.Errorf("updating KlausFleetStatus status: %w", err)
+	}
+	return nil
+}
+
+func (f *FleetStatusReporter) ensureSingleton(ctx context.Context) (*klausv1alpha1.KlausFleetStatus, error) {
+	fleet := &klausv1alpha1.KlausFleetStatus{}
+	key := types.NamespacedName{Name: klausv1alpha1.FleetStatusName, Namespace: f.Namespace}
+	err := f.Client.Get(ctx, key, fleet)
+	if err == nil {
+		return fleet, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("fetching KlausFleetStatus: %w", err)
+	}
+
+	fleet = &klausv1alpha1.KlausFleetStatus{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+	}
+	if err := f.Client.Create(ctx, fleet); err != nil {
+		return nil, fmt.Errorf("creating KlausFleetStatus: %w", err)
+	}
+	return fleet, nil
+}
+
+// listKlausEvents lists the Events about Klaus objects in every namespace
+// that holds instances or jobs.
+func (f *FleetStatusReporter) listKlausEvents(ctx context.Context, instances []klausv1alpha1.KlausInstance, jobs []klausv1alpha1.KlausJob) ([]corev1.Event, error) {
+	namespaces := map[string]bool{}
+	for i := range instances {
+		namespaces[instances[i].Namespace] = true
+	}
+	for i := range jobs {
+		namespaces[jobs[i].Namespace] = true
+	}
+
+	var events []corev1.Event
+	for ns := range namespaces {
+		var list corev1.EventList
+		if err := f.APIReader.List(ctx, &list, client.InNamespace(ns)); err != nil {
+			return nil, fmt.Errorf("listing events in namespace %s: %w", ns, err)
+		}
+		for _, e := range list.Items {
+			gv, err := schema.ParseGroupVersion(e.InvolvedObject.APIVersion)
+			if err == nil && gv.Group == klausv1alpha1.GroupVersion.Group {
+				events = append(events, e)
+			}
+		}
+	}
+	return events, nil
+}
+
+// summarizeFleet aggregates instance and job state, the error reason
+// leaderboard, the oldest not-ready instance and the most recent events.
+func summarizeFleet(instances []klausv1alpha1.KlausInstance, jobs []klausv1alpha1.KlausJob, events []corev1.Event) klausv1alpha1.KlausFleetStatusStatus {
+	status := klausv1alpha1.KlausFleetStatusStatus{Instances: int32(len(instances))}
+
+	instanceStates := map[string]int32{}
+	reasons := map[string]int32{}
+	for i := range instances {
+		instance := &instances[i]
+		instanceStates[stateOrUnknown(string(instance.Status.State))]++
+
+		ready := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionReady)
+		if ready != nil && ready.Status == metav1.ConditionTrue {
+			status.ReadyInstances++
+			continue
+		}
+		if resources.IsStopped(instance) || instance.Status.State == klausv1alpha1.InstanceStateStopped ||
+			instance.Status.State == klausv1alpha1.InstanceStateDryRun {
+			continue
+		}
+		if ready != nil && ready.Reason != "" {
+			reasons[ready.Reason]++
+		}
+
+		since := instance.CreationTimestamp
+		if ready != nil {
+			since = ready.LastTransitionTime
+		}
+		if status.OldestNotReady == nil || since.Before(status.OldestNotReady.Since) {
+			summary := &klausv1alpha1.InstanceSummary{
+				Name:      instance.Name,
+				Namespace: instance.Namespace,
+				Owner:     instance.Spec.Owner,
+				State:     instance.Status.State,
+				Since:     since.DeepCopy(),
+			}
+			if ready != nil {
+				summary.Reason = ready.Reason
+			}
+			status.OldestNotReady = summary
+		}
+	}
+	status.InstanceStates = sortedStateCounts(instanceStates)
+
+	jobStates := map[string]int32{}
+	for i := range jobs {
+		jobStates[stateOrUnknown(string(jobs[i].Status.State))]++
+	}
+	status.JobStates = sortedStateCounts(jobStates)
+
+	for reason, count := range reasons {
+		status.ErrorReasons = append(status.ErrorReasons, klausv1alpha1.ReasonCount{Reason: reason, Count: count})
+	}
+	sort.Slice(status.ErrorReasons, func(i, j int) bool {
+		a, b := status.ErrorReasons[i], status.ErrorReasons[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Reason < b.Reason
+	})
+	if len(status.ErrorReasons) > maxFleetErrorReasons {
+		status.ErrorReasons = status.ErrorReasons[:maxFleetErrorReasons]
+	}
+
+	status.PersonalityRollouts = personalityRollouts(instances)
+	status.RecentEvents = recentFleetEvents(events)
+	return status
+}
+
+// personalityRollouts reports the staged personality rollouts in progress,
+// i.e. the personality references with instances waiting for a new
+// revision.
+func personalityRollouts(instances []klausv1alpha1.KlausInstance) []klausv1alpha1.PersonalityRollout {
+	byPersonality := map[string][]*klausv1alpha1.KlausInstance{}
+	targets := map[string]string{}
+	for i := range instances {
+		instance := &instances[i]
+		if instance.Spec.Personality == "" {
+			continue
+		}
+		byPersonality[instance.Spec.Personality] = append(byPersonality[instance.Spec.Personality], instance)
+		if pending := instance.Status.PersonalityPendingRevision; pending != "" {
+			targets[instance.Spec.Personality] = pending
+		}
+	}
+
+	var rollouts []klausv1alpha1.PersonalityRollout
+	for personality, revision := range targets {
+		rollout := klausv1alpha1.PersonalityRollout{
+			Personality: personality,
+			Revision:    revision,
+			Total:       int32(len(byPersonality[personality])),
+		}
+		for _, instance := range byPersonality[personality] {
+			if instance.Status.PersonalityRevision == revision {
+				rollout.Updated++
+			}
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	sort.Slice(rollouts, func(i, j int) bool { return rollouts[i].Personality < rollouts[j].Personality })
+	return rollouts
+}
+
+func stateOrUnknown(state string) string {
+	if state == "" {
+		return fleetStateUnknown
+	}
+	return state
+}
+
+func sortedStateCounts(counts map[string]int32) []klausv1alpha1.StateCount {
+	var states []klausv1alpha1.StateCount
+	for state, count := range counts {
+		states = append(states, klausv1alpha1.StateCount{State: state, Count: count})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].State < states[j].State })
+	return states
+}
+
+// recentFleetEvents returns the newest maxFleetEvents events, newest first.
+func recentFleetEvents(events []corev1.Event) []klausv1alpha1.FleetEvent {
+	var recent []klausv1alpha1.FleetEvent
+	for _, e := range events {
+		recent = append(recent, klausv1alpha1.FleetEvent{
+			Time:      eventTime(e),
+			Kind:      e.InvolvedObject.Kind,
+			Name:      e.InvolvedObject.Name,
+			Namespace: e.InvolvedObject.Namespace,
+			Type:      e.Type,
+			Reason:    e.Reason,
+			Message:   e.Message,
+			Count:     e.Count,
+		})
+	}
+	sort.SliceStable(recent, func(i, j int) bool { return recent[j].Time.Before(&recent[i].Time) })
+	if len(recent) > maxFleetEvents {
+		recent = recent[:maxFleetEvents]
+	}
+	return recent
+}
+
+// eventTime returns when an event was last observed, falling back through
+// the timestamps set by the different event recorders.
+func eventTime(e corev1.Event) metav1.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp
+	case !e.EventTime.IsZero():
+		return metav1.Time{Time: e.EventTime.Time}
+	case !e.FirstTimestamp.IsZero():
+		return e.FirstTimestamp
+	default:
+		return e.CreationTimestamp
+	}
+}
+
+// ociCacheStats reads the size of the on-disk OCI cache. The cache keeps
+// tag resolutions under refs/, tag lists under tags/ and content under
+// blobs/. A cache directory that has not been created yet reports zeroes.
+func ociCacheStats(dir string) *klausv1alpha1.OCICacheStats {
+	stats := &klausv1alpha1.OCICacheStats{Enabled: dir != ""}
+	if dir == "" {
+		return stats
+	}
+
+	var errs []error
+	count := func(sub string, size *int64) int32 {
+		var n int32
+		err := filepath.WalkDir(filepath.Join(dir, sub), func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			n++
+			if size != nil {
+				if info, err := d.Info(); err == nil {
+					*size += info.Size()
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		return n
+	}
+	stats.References = count("refs", nil)
+	stats.TagLists = count("tags", nil)
+	stats.Blobs = count(filepath.Join("blobs", "sha256"), &stats.SizeBytes)
+	if err := errors.Join(errs...); err != nil {
+		stats.Error = err.Error()
+	}
+	return stats
+}
@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func fleetInstance(name string, state klausv1alpha1.InstanceState, ready metav1.ConditionStatus, reason string, since time.Time) klausv1alpha1.KlausInstance {
+	instance := klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system", CreationTimestamp: metav1.NewTime(since.Add(-time.Hour))},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: name + "@example.com"},
+		Status:     klausv1alpha1.KlausInstanceStatus{State: state},
+	}
+	if ready != "" {
+		instance.Status.Conditions = []metav1.Condition{{
+			Type:               ConditionReady,
+			Status:             ready,
+			Reason:             reason,
+			LastTransitionTime: metav1.NewTime(since),
+		}}
+	}
+	return instance
+}
+
+func fleetEvent(name, reason string, at time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "." + reason, Namespace: "klaus-system"},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: klausv1alpha1.GroupVersion.String(),
+			Kind:       "KlausInstance",
+			Name:       name,
+			Namespace:  "klaus-system",
+		},
+		Type:          corev1.EventTypeNormal,
+		Reason:        reason,
+		LastTimestamp: metav1.NewTime(at),
+	}
+}
+
+func TestSummarizeFleet(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	instances := []klausv1alpha1.KlausInstance{
+		fleetInstance("ready", klausv1alpha1.InstanceStateRunning, metav1.ConditionTrue, "Running", now.Add(-5*time.Hour)),
+		fleetInstance("broken-a", klausv1alpha1.InstanceStateError, metav1.ConditionFalse, "DeploymentError", now.Add(-2*time.Hour)),
+		fleetInstance("broken-b", klausv1alpha1.InstanceStateError, metav1.ConditionFalse, "DeploymentError", now.Add(-3*time.Hour)),
+		fleetInstance("broken-c", klausv1alpha1.InstanceStateError, metav1.ConditionFalse, "OCIResolutionError", now.Add(-time.Hour)),
+		fleetInstance("stopped", klausv1alpha1.InstanceStateStopped, metav1.ConditionFalse, "Stopped", now.Add(-24*time.Hour)),
+		fleetInstance("preview", klausv1alpha1.InstanceStateDryRun, metav1.ConditionFalse, ReasonDryRun, now.Add(-48*time.Hour)),
+		fleetInstance("new", "", "", "", now),
+	}
+	jobs := []klausv1alpha1.KlausJob{
+		{Status: klausv1alpha1.KlausJobStatus{State: klausv1alpha1.JobStateSucceeded}},
+		{Status: klausv1alpha1.KlausJobStatus{State: klausv1alpha1.JobStateSucceeded}},
+		{Status: klausv1alpha1.KlausJobStatus{State: klausv1alpha1.JobStateFailed}},
+	}
+	events := []corev1.Event{
+		fleetEvent("ready", "Old", now.Add(-time.Hour)),
+		fleetEvent("broken-a", "Newest", now),
+	}
+
+	status := summarizeFleet(instances, jobs, events)
+
+	if status.Instances != 7 || status.ReadyInstances != 1 {
+		t.Errorf("instances = %d, ready = %d, want 7 and 1", status.Instances, status.ReadyInstances)
+	}
+	wantStates := []klausv1alpha1.StateCount{
+		{State: "DryRun", Count: 1}, {State: "Error", Count: 3}, {State: "Running", Count: 1},
+		{State: "Stopped", Count: 1}, {State: "Unknown", Count: 1},
+	}
+	if fmt.Sprint(status.InstanceStates) != fmt.Sprint(wantStates) {
+		t.Errorf("InstanceStates = %v, want %v", status.InstanceStates, wantStates)
+	}
+	wantJobs := []klausv1alpha1.StateCount{{State: "Failed", Count: 1}, {State: "Succeeded", Count: 2}}
+	if fmt.Sprint(status.JobStates) != fmt.Sprint(wantJobs) {
+		t.Errorf("JobStates = %v, want %v", status.JobStates, wantJobs)
+	}
+	wantReasons := []klausv1alpha1.ReasonCount{{Reason: "DeploymentError", Count: 2}, {Reason: "OCIResolutionError", Count: 1}}
+	if fmt.Sprint(status.ErrorReasons) != fmt.Sprint(wantReasons) {
+		t.Errorf("ErrorReasons = %v, want %v", status.ErrorReasons, wantReasons)
+	}
+
+	// The never-reconciled instance was created an hour ago, which is more
+	// recent than broken-b's transition three hours ago; stopped and dry-run
+	// instances are ignored.
+	oldest := status.OldestNotReady
+	if oldest == nil || oldest.Name != "broken-b" || oldest.Reason != "DeploymentError" || oldest.Owner != "broken-b@example.com" {
+		t.Fatalf("OldestNotReady = %+v, want broken-b", oldest)
+	}
+	if !oldest.Since.Time.Equal(now.Add(-3 * time.Hour)) {
+		t.Errorf("OldestNotReady.Since = %v, want %v", oldest.Since, now.Add(-3*time.Hour))
+	}
+
+	if len(status.RecentEvents) != 2 || status.RecentEvents[0].Reason != "Newest" {
+		t.Errorf("RecentEvents = %+v, want newest first", status.RecentEvents)
+	}
+}
+
+func TestSummarizeFleet_Limits(t *testing.T) {
+	now := time.Now()
+	var instances []klausv1alpha1.KlausInstance
+	var events []corev1.Event
+	for i := range maxFleetErrorReasons + 5 {
+		name := fmt.Sprintf("instance-%d", i)
+		instances = append(instances, fleetInstance(name, klausv1alpha1.InstanceStateError, metav1.ConditionFalse, fmt.Sprintf("Reason%02d", i), now))
+	}
+	for i := range maxFleetEvents + 5 {
+		events = append(events, fleetEvent("instance-0", fmt.Sprintf("Event%d", i), now.Add(time.Duration(i)*time.Second)))
+	}
+
+	status := summarizeFleet(instances, nil, events)
+	if len(status.ErrorReasons) != maxFleetErrorReasons {
+		t.Errorf("len(ErrorReasons) = %d, want %d", len(status.ErrorReasons), maxFleetErrorReasons)
+	}
+	if len(status.RecentEvents) != maxFleetEvents {
+		t.Errorf("len(RecentEvents) = %d, want %d", len(status.RecentEvents), maxFleetEvents)
+	}
+	if got, want := status.RecentEvents[0].Reason, fmt.Sprintf("Event%d", maxFleetEvents+4); got != want {
+		t.Errorf("newest event = %q, want %q", got, want)
+	}
+}
+
+func TestPersonalityRollouts(t *testing.T) {
+	instance := func(name, personality, revision, pending string) klausv1alpha1.KlausInstance {
+		return klausv1alpha1.KlausInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       klausv1alpha1.KlausInstanceSpec{Personality: personality},
+			Status: klausv1alpha1.KlausInstanceStatus{
+				PersonalityRevision:        revision,
+				PersonalityPendingRevision: pending,
+			},
+		}
+	}
+	instances := []klausv1alpha1.KlausInstance{
+		instance("a", "go-dev", "go-dev:v2", ""),
+		instance("b", "go-dev", "go-dev:v1", "go-dev:v2"),
+		instance("c", "go-dev", "go-dev:v1", "go-dev:v2"),
+		instance("d", "sre", "sre:v1", ""),
+		instance("e", "", "", ""),
+	}
+
+	rollouts := personalityRollouts(instances)
+
+	if len(rollouts) != 1 {
+		t.Fatalf("expected only the go-dev rollout, got %+v", rollouts)
+	}
+	want := klausv1alpha1.PersonalityRollout{Personality: "go-dev", Revision: "go-dev:v2", Updated: 1, Total: 3}
+	if rollouts[0] != want {
+		t.Errorf("rollout = %+v, want %+v", rollouts[0], want)
+	}
+}
+
+func TestOCICacheStats(t *testing.T) {
+	if stats := ociCacheStats(""); stats.Enabled {
+		t.Errorf("stats = %+v, want disabled", stats)
+	}
+
+	dir := t.TempDir()
+	if stats := ociCacheStats(dir); !stats.Enabled || stats.Error != "" || stats.Blobs != 0 {
+		t.Errorf("stats = %+v, want an enabled empty cache", stats)
+	}
+
+	write := func(path string, size int) {
+		t.Helper()
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("refs/a.json", 10)
+	write("refs/b.json", 10)
+	write("tags/c.json", 10)
+	write("blobs/sha256/aaaa", 100)
+	write("blobs/sha256/bbbb", 50)
+
+	stats := ociCacheStats(dir)
+	if stats.References != 2 || stats.TagLists != 1 || stats.Blobs != 2 || stats.SizeBytes != 150 {
+		t.Errorf("stats = %+v, want 2 refs, 1 tag list, 2 blobs of 150 bytes", stats)
+	}
+}
+
+func TestFleetStatusReporter_Refresh(t *testing.T) {
+	now := time.Now()
+	instance := fleetInstance("broken", klausv1alpha1.InstanceStateError, metav1.ConditionFalse, "DeploymentError", now)
+	klausEvent := fleetEvent("broken", "DeploymentError", now)
+	podEvent := corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "pod.event", Namespace: "klaus-system"},
+		InvolvedObject: corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Name: "some-pod"},
+		Reason:         "Pulled",
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme(t)).
+		WithObjects(&instance, &klausEvent, &podEvent).
+		WithStatusSubresource(&klausv1alpha1.KlausFleetStatus{}).
+		Build()
+	f := &FleetStatusReporter{Client: c, APIReader: c, Namespace: "klaus-system"}
+
+	// The first refresh creates the singleton, the second updates it.
+	for range 2 {
+		if err := f.Refresh(context.Background()); err != nil {
+			t.Fatalf("Refresh() error = %v", err)
+		}
+	}
+
+	fleet := &klausv1alpha1.KlausFleetStatus{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: klausv1alpha1.FleetStatusName, Namespace: "klaus-system"}, fleet); err != nil {
+		t.Fatalf("expected KlausFleetStatus singleton: %v", err)
+	}
+	if fleet.Status.ObservedAt == nil || fleet.Status.Instances != 1 {
+		t.Errorf("status = %+v, want one observed instance", fleet.Status)
+	}
+	if fleet.Status.OldestNotReady == nil || fleet.Status.OldestNotReady.Name != "broken" {
+		t.Errorf("OldestNotReady = %+v, want broken", fleet.Status.OldestNotReady)
+	}
+	if len(fleet.Status.RecentEvents) != 1 || fleet.Status.RecentEvents[0].Kind != "KlausInstance" {
+		t.Errorf("RecentEvents = %+v, want only the KlausInstance event", fleet.Status.RecentEvents)
+	}
+	if fleet.Status.OCICache == nil || fleet.Status.OCICache.Enabled {
+		t.Errorf("OCICache = %+v, want disabled", fleet.Status.OCICache)
+	}
+}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const (
+	// gatewayRecheckInterval is how often instances check whether an
+	// unavailable gateway, like muster without its MCPServer CRD, has been
+	// installed.
+	gatewayRecheckInterval = 5 * time.Minute
+
+	// gatewayMinBackoff and gatewayMaxBackoff bound the retries of a failed
+	// gateway registration or cleanup.
+	gatewayMinBackoff = 5 * time.Second
+	gatewayMaxBackoff = 5 * time.Minute
+)
+
+// GatewayRegistrar registers instances with an MCP gateway. The instance
+// Service lives in namespace.
+type GatewayRegistrar interface {
+	// Register creates or updates the registration of the instance.
+	Register(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error
+	// Deregister removes the registration of the instance, succeeding when
+	// there is none.
+	Deregister(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error
+}
+
+// gatewayUnavailableError is returned by a GatewayRegistrar whose gateway is
+// not installed in the cluster. Registration is re-checked periodically
+// instead of retried with a backoff.
+type gatewayUnavailableError struct {
+	reason  string
+	message string
+}
+
+func (e *gatewayUnavailableError) Error() string {
+	return e.message
+}
+
+// ParseGatewayType validates the value of the --gateway flag.
+func ParseGatewayType(s string) (klausv1alpha1.GatewayType, error) {
+	switch gateway := klausv1alpha1.GatewayType(s); gateway {
+	case klausv1alpha1.GatewayTypeMuster, klausv1alpha1.GatewayTypeService, klausv1alpha1.GatewayTypeNone:
+		return gateway, nil
+	}
+	return "", fmt.Errorf("unknown gateway %q, want muster, service or none", s)
+}
+
+// gatewayType returns the gateway backend of the instance:
+// spec.gateway.type, else the operator default, else muster.
+func (r *KlausInstanceReconciler) gatewayType(instance *klausv1alpha1.KlausInstance) klausv1alpha1.GatewayType {
+	if instance.Spec.Gateway != nil && instance.Spec.Gateway.Type != "" {
+		return instance.Spec.Gateway.Type
+	}
+	if r.DefaultGateway != "" {
+		return r.DefaultGateway
+	}
+	return klausv1alpha1.GatewayTypeMuster
+}
+
+func (r *KlausInstanceReconciler) gatewayRegistrar(gateway klausv1alpha1.GatewayType) GatewayRegistrar {
+	switch gateway {
+	case klausv1alpha1.GatewayTypeMuster:
+		return &musterRegistrar{client: r.Client}
+	case klausv1alpha1.GatewayTypeService:
+		return &serviceRegistrar{client: r.Client}
+	}
+	return noopRegistrar{}
+}
+
+// registeredGateway returns the gateway backend the instance was last
+// registered with. Instances reconciled before status.gateway was recorded
+// were registered with muster.
+func registeredGateway(instance *klausv1alpha1.KlausInstance) klausv1alpha1.GatewayType {
+	if instance.Status.Gateway != "" {
+		return instance.Status.Gateway
+	}
+	if apimeta.FindStatusCondition(instance.Status.Conditions, ConditionMCPServerReady) != nil {
+		return klausv1alpha1.GatewayTypeMuster
+	}
+	return klausv1alpha1.GatewayTypeNone
+}
+
+// registerGateway registers the instance with its gateway backend and
+// records the outcome in status.gateway and the MCPServerReady condition,
+// first removing the registration with a previous backend. Failures do not
+// fail the reconcile, the agent works without a gateway. It returns when to
+// check again: gatewayRecheckInterval while the gateway is not installed, a
+// backoff after a failure, and 0 once registered.
+func (r *KlausInstanceReconciler) registerGateway(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) time.Duration {
+	gateway := r.gatewayType(merged)
+	if previous := registeredGateway(instance); previous != gateway {
+		if err := r.gatewayRegistrar(previous).Deregister(ctx, merged, namespace); err != nil {
+			return r.gatewayFailed(ctx, instance, fmt.Errorf("deregistering from the %s gateway: %w", previous, err))
+		}
+	}
+	instance.Status.Gateway = gateway
+
+	if gateway == klausv1alpha1.GatewayTypeNone {
+		apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionMCPServerReady)
+		return 0
+	}
+
+	err := r.gatewayRegistrar(gateway).Register(ctx, merged, namespace)
+	if unavailable := (*gatewayUnavailableError)(nil); errors.As(err, &unavailable) {
+		if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionMCPServerReady); cond == nil || cond.Reason != unavailable.reason {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, unavailable.reason,
+				fmt.Sprintf("%s, the instance is not registered with the %s gateway", unavailable.message, gateway))
+		}
+		setCondition(instance, ConditionMCPServerReady, metav1.ConditionFalse, unavailable.reason, unavailable.message)
+		return gatewayRecheckInterval
+	}
+	if err != nil {
+		return r.gatewayFailed(ctx, instance, err)
+	}
+	setCondition(instance, ConditionMCPServerReady, metav1.ConditionTrue, "Reconciled",
+		fmt.Sprintf("Registered with the %s gateway", gateway))
+	return 0
+}
+
+// gatewayFailed records a failed gateway registration and returns the
+// backoff before retrying it.
+func (r *KlausInstanceReconciler) gatewayFailed(ctx context.Context, instance *klausv1alpha1.KlausInstance, err error) time.Duration {
+	log.FromContext(ctx).Error(err, "failed to register with the MCP gateway")
+	r.Recorder.Event(instance, corev1.EventTypeWarning, "MCPServerError", err.Error())
+	backoff := gatewayBackoff(instance, time.Now())
+	setCondition(instance, ConditionMCPServerReady, metav1.ConditionFalse, "ReconcileError", err.Error())
+	return backoff
+}
+
+// deregisterGateways removes the registrations of a deleted instance with
+// the gateway backend it was registered with and the one it selects.
+func (r *KlausInstanceReconciler) deregisterGateways(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	registered, selected := registeredGateway(instance), r.gatewayType(instance)
+	err := r.gatewayRegistrar(registered).Deregister(ctx, instance, namespace)
+	if selected != registered {
+		err = errors.Join(err, r.gatewayRegistrar(selected).Deregister(ctx, instance, namespace))
+	}
+	return err
+}
+
+// gatewayBackoff returns the delay before retrying a failed gateway request
+// for instance. It doubles from gatewayMinBackoff up to gatewayMaxBackoff
+// for as long as the MCPServerReady condition has been failing, or the
+// instance has been deleting.
+func gatewayBackoff(instance *klausv1alpha1.KlausInstance, now time.Time) time.Duration {
+	since := now
+	if !instance.DeletionTimestamp.IsZero() {
+		since = instance.DeletionTimestamp.Time
+	} else if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionMCPServerReady); cond != nil &&
+		cond.Status == metav1.ConditionFalse && cond.Reason == "ReconcileError" {
+		since = cond.LastTransitionTime.Time
+	}
+	return retryBackoff(since, now, gatewayMinBackoff, gatewayMaxBackoff)
+}
+
+// serviceRegistrar implements GatewayRegistrar with discovery annotations on
+// the instance Service, for gateways listing Services by annotation.
+type serviceRegistrar struct {
+	client client.Client
+}
+
+func (s *serviceRegistrar) Register(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	return s.annotate(ctx, instance, namespace, func(annotations map[string]string) {
+		maps.Copy(annotations, resources.GatewayServiceAnnotations(instance, namespace))
+	})
+}
+
+func (s *serviceRegistrar) Deregister(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	err := s.annotate(ctx, instance, namespace, func(annotations map[string]string) {
+		delete(annotations, resources.AnnotationMCPURL)
+		delete(annotations, resources.AnnotationMCPTransport)
+		delete(annotations, resources.AnnotationMCPToolPrefix)
+	})
+	return client.IgnoreNotFound(err)
+}
+
+// annotate updates the annotations of the instance Service with update,
+// writing only when they changed.
+func (s *serviceRegistrar) annotate(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string, update func(map[string]string)) error {
+	svc := &corev1.Service{}
+	if err := s.client.Get(ctx, types.NamespacedName{Name: resources.ServiceName(instance), Namespace: namespace}, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return err
+		}
+		return fmt.Errorf("getting Service %s/%s: %w", namespace, resources.ServiceName(instance), err)
+	}
+	annotations := maps.Clone(svc.Annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	update(annotations)
+	if maps.Equal(annotations, svc.Annotations) {
+		return nil
+	}
+	svc.Annotations = annotations
+	return s.client.Update(ctx, svc)
+}
+
+// noopRegistrar implements GatewayRegistrar for instances not registered
+// with any gateway.
+type noopRegistrar struct{}
+
+func (noopRegistrar) Register(context.Context, *klausv1alpha1.KlausInstance, string) error {
+	return nil
+}
+
+func (noopRegistrar) Deregister(context.Context, *klausv1alpha1.KlausInstance, string) error {
+	return nil
+}
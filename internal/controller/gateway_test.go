@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestParseGatewayType(t *testing.T) {
+	for _, s := range []string{"muster", "service", "none"} {
+		if got, err := ParseGatewayType(s); err != nil || string(got) != s {
+			t.Errorf("ParseGatewayType(%q) = %q, %v", s, got, err)
+		}
+	}
+	if _, err := ParseGatewayType("Muster"); err == nil {
+		t.Error("ParseGatewayType(Muster) succeeded, want an error")
+	}
+}
+
+func TestGatewayType(t *testing.T) {
+	r := &KlausInstanceReconciler{}
+	instance := testInstance()
+	if got := r.gatewayType(instance); got != klausv1alpha1.GatewayTypeMuster {
+		t.Errorf("gatewayType() = %q, want muster by default", got)
+	}
+	r.DefaultGateway = klausv1alpha1.GatewayTypeService
+	if got := r.gatewayType(instance); got != klausv1alpha1.GatewayTypeService {
+		t.Errorf("gatewayType() = %q, want the operator default", got)
+	}
+	instance.Spec.Gateway = &klausv1alpha1.GatewayConfig{Type: klausv1alpha1.GatewayTypeNone}
+	if got := r.gatewayType(instance); got != klausv1alpha1.GatewayTypeNone {
+		t.Errorf("gatewayType() = %q, want spec.gateway.type", got)
+	}
+}
+
+func TestRegisterGateway_Service(t *testing.T) {
+	r, _ := musterTestReconciler(t, true)
+	ctx := context.Background()
+	instance := testInstance()
+	instance.Spec.Gateway = &klausv1alpha1.GatewayConfig{Type: klausv1alpha1.GatewayTypeService}
+	namespace := resources.UserNamespace(instance.Spec.Owner)
+	svc := resources.BuildService(instance, namespace)
+	svc.Annotations = map[string]string{"example.com/keep": "yes"}
+	if err := r.Create(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+
+	if next := r.registerGateway(ctx, instance, instance, namespace); next != 0 {
+		t.Errorf("next check in %s, want none once registered", next)
+	}
+	if !apimeta.IsStatusConditionTrue(instance.Status.Conditions, ConditionMCPServerReady) ||
+		instance.Status.Gateway != klausv1alpha1.GatewayTypeService {
+		t.Errorf("status = %+v, want registered with the service gateway", instance.Status)
+	}
+	got := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: namespace}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Annotations[resources.AnnotationMCPURL] != resources.ServiceEndpoint(instance, namespace)+"/mcp" ||
+		got.Annotations["example.com/keep"] != "yes" {
+		t.Errorf("annotations = %v, want the MCP URL added", got.Annotations)
+	}
+
+	// Switching to none removes the annotations.
+	instance.Spec.Gateway.Type = klausv1alpha1.GatewayTypeNone
+	r.registerGateway(ctx, instance, instance, namespace)
+	if err := r.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: namespace}, got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.Annotations[resources.AnnotationMCPURL]; ok || got.Annotations["example.com/keep"] != "yes" {
+		t.Errorf("annotations = %v, want the MCP annotations removed", got.Annotations)
+	}
+	if apimeta.FindStatusCondition(instance.Status.Conditions, ConditionMCPServerReady) != nil ||
+		instance.Status.Gateway != klausv1alpha1.GatewayTypeNone {
+		t.Errorf("status = %+v, want no gateway condition", instance.Status)
+	}
+}
+
+func TestRegisterGateway_SwitchFromMuster(t *testing.T) {
+	r, _ := musterTestReconciler(t, true)
+	ctx := context.Background()
+	instance := testInstance()
+	namespace := resources.UserNamespace(instance.Spec.Owner)
+	if err := r.Create(ctx, resources.BuildService(instance, namespace)); err != nil {
+		t.Fatal(err)
+	}
+	r.registerGateway(ctx, instance, instance, namespace)
+
+	// Instances registered before status.gateway existed only carry the
+	// condition.
+	instance.Status.Gateway = ""
+	instance.Spec.Gateway = &klausv1alpha1.GatewayConfig{Type: klausv1alpha1.GatewayTypeService}
+	if next := r.registerGateway(ctx, instance, instance, namespace); next != 0 {
+		t.Errorf("next check in %s, want none once registered", next)
+	}
+	mcpServer := &unstructured.Unstructured{}
+	mcpServer.SetGroupVersionKind(mcpServerGVK)
+	key := types.NamespacedName{Name: "klaus-" + instance.Name, Namespace: resources.MusterNamespace(instance)}
+	if err := r.Get(ctx, key, mcpServer); err == nil {
+		t.Error("muster MCPServer kept after switching to the service gateway")
+	}
+}
+
+func TestGatewayBackoff(t *testing.T) {
+	now := time.Now()
+	instance := testInstance()
+	if got := gatewayBackoff(instance, now); got != gatewayMinBackoff {
+		t.Errorf("first backoff = %s, want %s", got, gatewayMinBackoff)
+	}
+
+	instance.Status.Conditions = []metav1.Condition{{
+		Type: ConditionMCPServerReady, Status: metav1.ConditionFalse, Reason: "ReconcileError",
+		LastTransitionTime: metav1.NewTime(now.Add(-30 * time.Second)),
+	}}
+	if got := gatewayBackoff(instance, now); got != 40*time.Second {
+		t.Errorf("backoff after 30s of failures = %s, want 40s", got)
+	}
+
+	deleting := testInstance()
+	deleting.DeletionTimestamp = &metav1.Time{Time: now.Add(-time.Hour)}
+	if got := gatewayBackoff(deleting, now); got != gatewayMaxBackoff {
+		t.Errorf("backoff after an hour = %s, want the maximum", got)
+	}
+}
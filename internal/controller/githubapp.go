@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/githubapp"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const (
+	// DefaultGitHubAppTokenInterval is how often the GitHub App installation
+	// tokens of the instances are checked for expiry unless configured
+	// otherwise.
+	DefaultGitHubAppTokenInterval = 5 * time.Minute
+
+	// gitHubAppTokenMinValidity is the remaining validity below which an
+	// installation token is replaced. Installation tokens are valid for an
+	// hour; the margin keeps tokens read by a starting clone or workspace
+	// Job valid for the whole operation.
+	gitHubAppTokenMinValidity = 30 * time.Minute
+)
+
+// GitHubTokenMinter mints GitHub App installation tokens restricted to a
+// repository and permissions.
+type GitHubTokenMinter interface {
+	InstallationToken(ctx context.Context, req githubapp.TokenRequest) (string, time.Time, error)
+}
+
+// refreshGitHubAppToken mints a new installation token into the git
+// credential Secret of an instance unless it holds a sufficiently valid one
+// for the current workspace repository. It reports whether the Secret was
+// changed.
+func refreshGitHubAppToken(ctx context.Context, minter GitHubTokenMinter, instance *klausv1alpha1.KlausInstance, secret *corev1.Secret, now time.Time) (bool, error) {
+	if !resources.GitHubAppTokenNeedsRefresh(secret, instance, now, gitHubAppTokenMinValidity) {
+		return false, nil
+	}
+	if minter == nil {
+		return false, errors.New("spec.workspace.gitHubApp requires the operator to be configured with a GitHub App")
+	}
+	repo, err := githubapp.ParseRepository(instance.Spec.Workspace.GitRepo)
+	if err != nil {
+		return false, err
+	}
+	token, expiresAt, err := minter.InstallationToken(ctx, githubapp.TokenRequest{
+		InstanceOwner:  instance.Spec.Owner,
+		Repository:     repo,
+		InstallationID: instance.Spec.Workspace.GitHubApp.InstallationID,
+		Permissions:    resources.GitHubAppPermissions(instance),
+	})
+	if err != nil {
+		return false, err
+	}
+	resources.SetGitHubAppToken(secret, instance, token, expiresAt)
+	return true, nil
+}
+
+// GitHubAppTokenRefresher is a leader-elected manager runnable that replaces
+// the GitHub App installation tokens in the git credential Secrets of
+// instances before they expire. Running pods see the new token through the
+// Secret volume, so workspace pulls and restarts of long-running instances
+// keep authenticating. Secrets are created by the instance reconciler; the
+// refresher only updates existing ones.
+type GitHubAppTokenRefresher struct {
+	Client client.Client
+
+	// Minter mints the installation tokens.
+	Minter GitHubTokenMinter
+
+	// Interval is the check interval.
+	Interval time.Duration
+
+	// NamespaceScoped looks up the Secrets in the namespace of the instance
+	// instead of the owner's user namespace.
+	NamespaceScoped bool
+
+	// now returns the current time; time.Now when nil.
+	now func() time.Time
+}
+
+// NeedLeaderElection ensures only the leader mints tokens.
+func (r *GitHubAppTokenRefresher) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs Refresh every Interval; see periodic.
+func (r *GitHubAppTokenRefresher) Start(ctx context.Context) error {
+	return periodic{
+		name:            "github-app-tokens",
+		interval:        r.Interval,
+		defaultInterval: DefaultGitHubAppTokenInterval,
+		action:          "refreshing GitHub App installation tokens",
+		fn:              r.Refresh,
+	}.Start(ctx)
+}
+
+// Refresh replaces the expiring tokens of all instances using GitHub App
+// workspace credentials.
+func (r *GitHubAppTokenRefresher) Refresh(ctx context.Context) error {
+	var instances klausv1alpha1.KlausInstanceList
+	if err := r.Client.List(ctx, &instances); err != nil {
+		return fmt.Errorf("listing KlausInstances: %w", err)
+	}
+
+	now := time.Now
+	if r.now != nil {
+		now = r.now
+	}
+
+	var errs []error
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if !instance.DeletionTimestamp.IsZero() || !resources.UsesGitHubApp(instance) {
+			continue
+		}
+
+		var secret corev1.Secret
+		key := types.NamespacedName{
+			Name:      resources.GitSecretName(instance),
+			Namespace: resources.InstanceNamespace(instance, r.NamespaceScoped),
+		}
+		if err := r.Client.Get(ctx, key, &secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("instance %s: %w", instance.Name, err))
+			}
+			continue
+		}
+		changed, err := refreshGitHubAppToken(ctx, r.Minter, instance, &secret, now())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("instance %s: %w", instance.Name, err))
+			continue
+		}
+		if changed {
+			if err := r.Client.Update(ctx, &secret); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("instance %s: updating git secret: %w", instance.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/githubapp"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+type fakeTokenMinter struct {
+	calls     []string
+	requests  []githubapp.TokenRequest
+	expiresAt time.Time
+	err       error
+}
+
+func (m *fakeTokenMinter) InstallationToken(_ context.Context, req githubapp.TokenRequest) (string, time.Time, error) {
+	m.calls = append(m.calls, req.Repository.String())
+	m.requests = append(m.requests, req)
+	if m.err != nil {
+		return "", time.Time{}, m.err
+	}
+	return "ghs_" + req.Repository.Name, m.expiresAt, nil
+}
+
+func gitHubAppInstance(name string) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance(name, testOwner)
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{
+		GitRepo:   "https://github.com/org/" + name + ".git",
+		GitHubApp: &klausv1alpha1.GitHubAppCredentials{},
+	}
+	return instance
+}
+
+func getGitSecret(t *testing.T, c client.Client, instance *klausv1alpha1.KlausInstance) *corev1.Secret {
+	t.Helper()
+	var secret corev1.Secret
+	key := types.NamespacedName{Name: resources.GitSecretName(instance), Namespace: resources.InstanceNamespace(instance, false)}
+	if err := c.Get(context.Background(), key, &secret); err != nil {
+		t.Fatalf("failed to get git secret: %v", err)
+	}
+	return &secret
+}
+
+func TestCopyGitSecret_GitHubApp(t *testing.T) {
+	instance := gitHubAppInstance("repo")
+	namespace := resources.InstanceNamespace(instance, false)
+	minter := &fakeTokenMinter{expiresAt: time.Now().Add(time.Hour)}
+	c := workspaceStatusClient(t, instance)
+	r := &KlausInstanceReconciler{Client: c, Scheme: c.Scheme(), GitHubApp: minter}
+
+	op, err := r.copyGitSecret(context.Background(), instance, namespace)
+	if err != nil {
+		t.Fatalf("copyGitSecret: %v", err)
+	}
+	if op != "created" {
+		t.Errorf("operation = %q, want created", op)
+	}
+	secret := getGitSecret(t, c, instance)
+	if string(secret.Data[resources.DefaultGitSecretKey]) != "ghs_repo" {
+		t.Errorf("token = %q", secret.Data[resources.DefaultGitSecretKey])
+	}
+
+	// A valid token is kept.
+	if _, err := r.copyGitSecret(context.Background(), instance, namespace); err != nil {
+		t.Fatalf("copyGitSecret: %v", err)
+	}
+	if len(minter.calls) != 1 || minter.calls[0] != "org/repo" {
+		t.Errorf("minted tokens = %v, want one for org/repo", minter.calls)
+	}
+	if req := minter.requests[0]; req.InstanceOwner != "user@example.com" || req.Repository.Host != "github.com" ||
+		req.Permissions["contents"] != "read" || len(req.Permissions) != 1 {
+		t.Errorf("token request = %+v, want read access for the instance owner", req)
+	}
+
+	r.GitHubApp = nil
+	other := gitHubAppInstance("other")
+	if _, err := r.copyGitSecret(context.Background(), other, namespace); err == nil || !strings.Contains(err.Error(), "configured with a GitHub App") {
+		t.Errorf("error = %v, want a missing GitHub App error", err)
+	}
+}
+
+func TestGitHubAppTokenRefresher(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	expiring, fresh, missing := gitHubAppInstance("expiring"), gitHubAppInstance("fresh"), gitHubAppInstance("missing")
+	secrets := map[*klausv1alpha1.KlausInstance]time.Time{
+		expiring: now.Add(10 * time.Minute),
+		fresh:    now.Add(50 * time.Minute),
+	}
+	objs := []client.Object{expiring, fresh, missing}
+	for instance, expiresAt := range secrets {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.GitSecretName(instance),
+			Namespace: resources.InstanceNamespace(instance, false),
+		}}
+		resources.SetGitHubAppToken(secret, instance, "old", expiresAt)
+		objs = append(objs, secret)
+	}
+	c := workspaceStatusClient(t, objs...)
+	minter := &fakeTokenMinter{expiresAt: now.Add(time.Hour)}
+	r := &GitHubAppTokenRefresher{Client: c, Minter: minter, now: func() time.Time { return now }}
+
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if len(minter.calls) != 1 || minter.calls[0] != "org/expiring" {
+		t.Errorf("minted tokens = %v, want one for the expiring token only", minter.calls)
+	}
+	if got := string(getGitSecret(t, c, expiring).Data["token"]); got != "ghs_expiring" {
+		t.Errorf("expiring token = %q, want it replaced", got)
+	}
+	if got := string(getGitSecret(t, c, fresh).Data["token"]); got != "old" {
+		t.Errorf("fresh token = %q, want it kept", got)
+	}
+
+	minter.err = errors.New("rate limited")
+	r.now = func() time.Time { return now.Add(45 * time.Minute) }
+	if err := r.Refresh(context.Background()); err == nil || !strings.Contains(err.Error(), "instance fresh") {
+		t.Errorf("error = %v, want the failed instance", err)
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// The defaults of the instances and reconcilers of the controller tests.
+const (
+	testInstanceName  = "my-agent"
+	testNamespace     = "klaus-system"
+	testOwner         = "user@example.com"
+	testRecorderDepth = 10
+)
+
+// testScheme returns a scheme with the core and klaus types and the types
+// added by addToScheme, e.g. appsv1.AddToScheme.
+func testScheme(t *testing.T, addToScheme ...func(*runtime.Scheme) error) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range append([]func(*runtime.Scheme) error{corev1.AddToScheme, klausv1alpha1.AddToScheme}, addToScheme...) {
+		if err := add(scheme); err != nil {
+			t.Fatalf("adding types to scheme: %v", err)
+		}
+	}
+	return scheme
+}
+
+// testInstance returns an instance named testInstanceName in testNamespace
+// owned by testOwner.
+func testInstance() *klausv1alpha1.KlausInstance {
+	return namedTestInstance(testInstanceName, testOwner)
+}
+
+// namedTestInstance returns an instance named name in testNamespace owned
+// by owner.
+func namedTestInstance(name, owner string) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: owner},
+	}
+}
+
+// testReconciler returns an instance reconciler backed by a fake client of
+// scheme holding objs, with the KlausInstance status subresource.
+func testReconciler(t *testing.T, scheme *runtime.Scheme, objs ...client.Object) *KlausInstanceReconciler {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).Build()
+	return testReconcilerFor(c)
+}
+
+// testReconcilerFor returns an instance reconciler backed by c, running in
+// testNamespace and recording events with a fake recorder; see
+// testRecorder.
+func testReconcilerFor(c client.Client) *KlausInstanceReconciler {
+	return &KlausInstanceReconciler{
+		Client:            c,
+		Scheme:            c.Scheme(),
+		Recorder:          record.NewFakeRecorder(testRecorderDepth),
+		OperatorNamespace: testNamespace,
+	}
+}
+
+// testRecorder returns the fake recorder of a reconciler created by
+// testReconciler.
+func testRecorder(r *KlausInstanceReconciler) *record.FakeRecorder {
+	return r.Recorder.(*record.FakeRecorder)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// imageVolumeProbeName is the name of the pod ProbeImageVolumes creates
+// with a server-side dry run.
+const imageVolumeProbeName = "klaus-image-volume-probe"
+
+// ProbeImageVolumes reports whether the API server accepts pods with OCI
+// image volumes, by creating one in namespace with a server-side dry run.
+// API servers without the ImageVolume feature gate drop the volume source
+// and reject the pod as invalid. Nodes are not probed: their kubelet and
+// container runtime must support image volumes as well. Dry runs need the
+// create verb on pods in namespace.
+func ProbeImageVolumes(ctx context.Context, c client.Client, namespace, image string) (bool, error) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: imageVolumeProbeName, Namespace: namespace},
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   ptr.To(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []corev1.Container{{
+				Name:  "probe",
+				Image: image,
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "probe", MountPath: "/probe", ReadOnly: true},
+				},
+				SecurityContext: &corev1.SecurityContext{
+					AllowPrivilegeEscalation: ptr.To(false),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "probe",
+				VolumeSource: corev1.VolumeSource{
+					Image: &corev1.ImageVolumeSource{Reference: image, PullPolicy: corev1.PullIfNotPresent},
+				},
+			}},
+		},
+	}
+	err := c.Create(ctx, pod, client.DryRunAll)
+	switch {
+	case apierrors.IsInvalid(err):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("creating image volume probe pod: %w", err)
+	}
+	return len(pod.Spec.Volumes) == 1 && pod.Spec.Volumes[0].Image != nil, nil
+}
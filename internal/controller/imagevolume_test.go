@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// imageVolumeGate mimics an API server with the ImageVolume feature gate
+// disabled, which drops image volume sources before validation.
+func imageVolumeGate(t *testing.T, enabled bool, createErr error) client.Client {
+	t.Helper()
+	return fake.NewClientBuilder().WithScheme(testScheme(t)).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if createErr != nil {
+				return createErr
+			}
+			createOpts := (&client.CreateOptions{}).ApplyOptions(opts)
+			if len(createOpts.DryRun) == 0 {
+				t.Errorf("probe pod created without a dry run")
+			}
+			pod := obj.(*corev1.Pod)
+			if !enabled {
+				pod.Spec.Volumes[0].Image = nil
+				return apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, pod.Name, field.ErrorList{
+					field.Required(field.NewPath("spec", "volumes").Index(0), "must specify a volume type"),
+				})
+			}
+			return nil
+		},
+	}).Build()
+}
+
+func TestProbeImageVolumes(t *testing.T) {
+	ctx := context.Background()
+	if ok, err := ProbeImageVolumes(ctx, imageVolumeGate(t, true, nil), "klaus-system", "klaus-operator:latest"); err != nil || !ok {
+		t.Errorf("ProbeImageVolumes() = %v, %v; want supported", ok, err)
+	}
+	if ok, err := ProbeImageVolumes(ctx, imageVolumeGate(t, false, nil), "klaus-system", "klaus-operator:latest"); err != nil || ok {
+		t.Errorf("ProbeImageVolumes() = %v, %v; want unsupported", ok, err)
+	}
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, imageVolumeProbeName, errors.New("denied"))
+	if _, err := ProbeImageVolumes(ctx, imageVolumeGate(t, true, forbidden), "klaus-system", "klaus-operator:latest"); !apierrors.IsForbidden(err) {
+		t.Errorf("error = %v, want the Forbidden error", err)
+	}
+}
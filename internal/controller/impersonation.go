@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=impersonate,resourceNames=klaus-operator-tenant
+
+// tenantClientCacheSize bounds the tenant clients an ImpersonatingClient
+// keeps; the least recently used are recreated on demand.
+const tenantClientCacheSize = 256
+
+// ImpersonatingClient is a client.Client that performs writes in user
+// namespaces as the tenant ServiceAccount of the namespace, so the API
+// server audit log attributes them to the owner and RBAC fences the
+// operator per tenant. Reads are served by the operator's cache, and writes
+// outside user namespaces use the operator's own identity. So do writes on a
+// context returned by withOperatorIdentity, which set up the tenant's
+// access in the first place.
+type ImpersonatingClient struct {
+	client.Client
+
+	newTenantClient func(namespace string) (client.Client, error)
+
+	// tenants caches the tenant clients by namespace. Entries of deleted
+	// namespaces are dropped.
+	tenants *lru.Cache
+}
+
+// NewImpersonatingClient returns an ImpersonatingClient reading through
+// operator and impersonating the tenant ServiceAccounts with config.
+func NewImpersonatingClient(operator client.Client, config *rest.Config) *ImpersonatingClient {
+	return &ImpersonatingClient{
+		Client: operator,
+		newTenantClient: func(namespace string) (client.Client, error) {
+			cfg := rest.CopyConfig(config)
+			cfg.Impersonate = rest.ImpersonationConfig{UserName: resources.TenantUsername(namespace)}
+			return client.New(cfg, client.Options{Scheme: operator.Scheme(), Mapper: operator.RESTMapper()})
+		},
+		tenants: lru.New(tenantClientCacheSize),
+	}
+}
+
+type operatorIdentityKey struct{}
+
+// withOperatorIdentity marks ctx so the ImpersonatingClient writes with the
+// operator's own identity.
+func withOperatorIdentity(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operatorIdentityKey{}, true)
+}
+
+func isOperatorIdentity(ctx context.Context) bool {
+	v, _ := ctx.Value(operatorIdentityKey{}).(bool)
+	return v
+}
+
+// Create creates obj, impersonating the tenant in user namespaces.
+func (c *ImpersonatingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	w, err := c.writer(ctx, obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+	return w.Create(ctx, obj, opts...)
+}
+
+// Update updates obj, impersonating the tenant in user namespaces.
+func (c *ImpersonatingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w, err := c.writer(ctx, obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+	return w.Update(ctx, obj, opts...)
+}
+
+// Patch patches obj, impersonating the tenant in user namespaces.
+func (c *ImpersonatingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w, err := c.writer(ctx, obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+	return w.Patch(ctx, obj, patch, opts...)
+}
+
+// Delete deletes obj, impersonating the tenant in user namespaces. The
+// tenant's RoleBinding goes with its namespace, so deletes in a namespace
+// that no longer exists report obj as not found instead of being denied.
+// Deleting a user namespace drops the client of its tenant.
+func (c *ImpersonatingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if ns, ok := obj.(*corev1.Namespace); ok {
+		c.tenants.Remove(ns.Name)
+	}
+	namespace := obj.GetNamespace()
+	w, err := c.writer(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	if w != c.Client && c.namespaceGone(ctx, namespace) {
+		return c.notFound(obj)
+	}
+	return w.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf deletes the matching objects, impersonating the tenant in
+// user namespaces.
+func (c *ImpersonatingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	options := &client.DeleteAllOfOptions{}
+	options.ApplyOptions(opts)
+	w, err := c.writer(ctx, options.Namespace)
+	if err != nil {
+		return err
+	}
+	if w != c.Client && c.namespaceGone(ctx, options.Namespace) {
+		return nil
+	}
+	return w.DeleteAllOf(ctx, obj, opts...)
+}
+
+// writer returns the client writing in namespace.
+func (c *ImpersonatingClient) writer(ctx context.Context, namespace string) (client.Writer, error) {
+	if !resources.IsUserNamespace(namespace) || isOperatorIdentity(ctx) {
+		return c.Client, nil
+	}
+
+	if tenant, ok := c.tenants.Get(namespace); ok {
+		return tenant.(client.Client), nil
+	}
+	tenant, err := c.newTenantClient(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("creating client impersonating %s: %w", resources.TenantUsername(namespace), err)
+	}
+	c.tenants.Add(namespace, tenant)
+	return tenant, nil
+}
+
+// namespaceGone reports whether namespace was deleted, dropping the client
+// of its tenant then.
+func (c *ImpersonatingClient) namespaceGone(ctx context.Context, namespace string) bool {
+	err := c.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{})
+	if !apierrors.IsNotFound(err) {
+		return false
+	}
+	c.tenants.Remove(namespace)
+	return true
+}
+
+func (c *ImpersonatingClient) notFound(obj client.Object) error {
+	var resource schema.GroupResource
+	if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+		if mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+			resource = mapping.Resource.GroupResource()
+		}
+	}
+	return apierrors.NewNotFound(resource, obj.GetName())
+}
+
+// reconcileTenantAccess creates the tenant ServiceAccount of the owner's
+// namespace and binds it to TenantClusterRole, with the operator's own
+// identity. Nothing is created when TenantClusterRole is unset. The roleRef
+// of a RoleBinding is immutable, so a binding to a different ClusterRole is
+// recreated.
+func (r *KlausInstanceReconciler) reconcileTenantAccess(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	if r.TenantClusterRole == "" {
+		return nil
+	}
+	ctx = withOperatorIdentity(ctx)
+	owner := instance.Spec.Owner
+
+	desiredSA := resources.BuildTenantServiceAccount(owner, namespace)
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: desiredSA.Name, Namespace: namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
+		sa.Labels = desiredSA.Labels
+		sa.AutomountServiceAccountToken = desiredSA.AutomountServiceAccountToken
+		return nil
+	}); err != nil {
+		return fmt.Errorf("reconciling tenant ServiceAccount: %w", err)
+	}
+
+	desired := resources.BuildTenantRoleBinding(owner, namespace, r.TenantClusterRole)
+	existing := &rbacv1.RoleBinding{}
+	err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("fetching tenant RoleBinding: %w", err)
+	}
+	if err == nil && existing.RoleRef != desired.RoleRef {
+		if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("deleting tenant RoleBinding: %w", err)
+		}
+		err = apierrors.NewNotFound(rbacv1.Resource("rolebindings"), desired.Name)
+	}
+	if apierrors.IsNotFound(err) {
+		if err := r.Create(ctx, desired); err != nil {
+			return fmt.Errorf("creating tenant RoleBinding: %w", err)
+		}
+		return nil
+	}
+
+	existing.Labels = desired.Labels
+	existing.Subjects = desired.Subjects
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("updating tenant RoleBinding: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/lru"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// impersonationTestClient returns an ImpersonatingClient over a fake
+// operator client whose tenant clients write to a separate fake client, so
+// tests can tell which identity performed a write.
+func impersonationTestClient(t *testing.T, objs ...client.Object) (c *ImpersonatingClient, operator, tenant client.Client, impersonated *[]string) {
+	t.Helper()
+	scheme := testScheme(t)
+	if err := rbacv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding rbacv1 to scheme: %v", err)
+	}
+	operator = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	tenant = fake.NewClientBuilder().WithScheme(scheme).Build()
+	impersonated = &[]string{}
+	c = &ImpersonatingClient{
+		Client: operator,
+		newTenantClient: func(namespace string) (client.Client, error) {
+			*impersonated = append(*impersonated, namespace)
+			return tenant, nil
+		},
+		tenants: lru.New(tenantClientCacheSize),
+	}
+	return c, operator, tenant, impersonated
+}
+
+func configMap(name, namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+}
+
+func TestImpersonatingClient_Writes(t *testing.T) {
+	c, operator, tenant, impersonated := impersonationTestClient(t)
+	ctx := context.Background()
+
+	for _, cm := range []*corev1.ConfigMap{configMap("a", ownerTestNamespace), configMap("b", ownerTestNamespace)} {
+		if err := c.Create(ctx, cm); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if err := c.Create(ctx, configMap("c", "klaus-system")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := c.Create(withOperatorIdentity(ctx), configMap("d", ownerTestNamespace)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for name, want := range map[string]client.Client{"a": tenant, "b": tenant, "d": operator} {
+		if err := want.Get(ctx, types.NamespacedName{Name: name, Namespace: ownerTestNamespace}, &corev1.ConfigMap{}); err != nil {
+			t.Errorf("ConfigMap %s not written with the expected identity: %v", name, err)
+		}
+	}
+	if err := operator.Get(ctx, types.NamespacedName{Name: "c", Namespace: "klaus-system"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("writes outside user namespaces must use the operator identity: %v", err)
+	}
+	if len(*impersonated) != 1 || (*impersonated)[0] != ownerTestNamespace {
+		t.Errorf("impersonated = %v, want one client for %s", *impersonated, ownerTestNamespace)
+	}
+}
+
+func TestImpersonatingClient_DeleteInMissingNamespace(t *testing.T) {
+	c, _, _, _ := impersonationTestClient(t)
+
+	err := c.Delete(context.Background(), configMap("a", ownerTestNamespace))
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Delete() error = %v, want not found", err)
+	}
+	if c.tenants.Len() != 0 {
+		t.Errorf("%d tenant clients cached, want the client of the missing namespace dropped", c.tenants.Len())
+	}
+}
+
+func TestImpersonatingClient_Delete(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ownerTestNamespace}}
+	c, _, tenant, _ := impersonationTestClient(t, ns)
+	ctx := context.Background()
+	if err := tenant.Create(ctx, configMap("a", ownerTestNamespace)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Delete(ctx, configMap("a", ownerTestNamespace)); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := tenant.Get(ctx, types.NamespacedName{Name: "a", Namespace: ownerTestNamespace}, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the tenant client to delete the ConfigMap, got %v", err)
+	}
+}
+
+func TestImpersonatingClient_ForgetsDeletedNamespaces(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ownerTestNamespace}}
+	c, _, _, impersonated := impersonationTestClient(t, ns)
+	ctx := context.Background()
+
+	if err := c.Create(ctx, configMap("a", ownerTestNamespace)); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := c.Delete(ctx, ns); err != nil {
+		t.Fatalf("deleting the namespace: %v", err)
+	}
+	if c.tenants.Len() != 0 {
+		t.Errorf("%d tenant clients cached, want the client of the deleted namespace dropped", c.tenants.Len())
+	}
+	if len(*impersonated) != 1 {
+		t.Errorf("impersonated = %v, want one tenant client", *impersonated)
+	}
+}
+
+func TestReconcileTenantAccess(t *testing.T) {
+	c, operator, tenant, _ := impersonationTestClient(t)
+	r := &KlausInstanceReconciler{Client: c, TenantClusterRole: "klaus-operator-tenant"}
+	instance := testInstance()
+	ctx := context.Background()
+
+	if err := r.reconcileTenantAccess(ctx, instance, ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileTenantAccess() error = %v", err)
+	}
+	key := types.NamespacedName{Name: resources.TenantServiceAccountName, Namespace: ownerTestNamespace}
+	if err := operator.Get(ctx, key, &corev1.ServiceAccount{}); err != nil {
+		t.Errorf("expected the operator to create the tenant ServiceAccount: %v", err)
+	}
+	rb := &rbacv1.RoleBinding{}
+	if err := operator.Get(ctx, key, rb); err != nil {
+		t.Fatalf("expected the operator to create the tenant RoleBinding: %v", err)
+	}
+	if rb.RoleRef.Name != "klaus-operator-tenant" {
+		t.Errorf("RoleRef = %+v", rb.RoleRef)
+	}
+	if err := tenant.Get(ctx, key, &rbacv1.RoleBinding{}); !apierrors.IsNotFound(err) {
+		t.Error("the tenant must not grant itself access")
+	}
+
+	// A different ClusterRole recreates the binding since roleRef is immutable.
+	r.TenantClusterRole = "custom-tenant"
+	if err := r.reconcileTenantAccess(ctx, instance, ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileTenantAccess() error = %v", err)
+	}
+	if err := operator.Get(ctx, key, rb); err != nil || rb.RoleRef.Name != "custom-tenant" {
+		t.Errorf("RoleBinding = %+v, %v; want custom-tenant", rb.RoleRef, err)
+	}
+}
+
+func TestReconcileTenantAccess_Disabled(t *testing.T) {
+	c, operator, _, _ := impersonationTestClient(t)
+	r := &KlausInstanceReconciler{Client: c}
+
+	if err := r.reconcileTenantAccess(context.Background(), testInstance(), ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileTenantAccess() error = %v", err)
+	}
+	var sas corev1.ServiceAccountList
+	if err := operator.List(context.Background(), &sas); err != nil || len(sas.Items) != 0 {
+		t.Errorf("expected no tenant ServiceAccount, got %v, %v", sas.Items, err)
+	}
+}
@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+	"github.com/giantswarm/klaus-operator/internal/schedule"
+)
+
+const (
+	// CronJobConditionScheduled indicates whether the KlausCronJob is
+	// scheduling runs.
+	CronJobConditionScheduled = "Scheduled"
+
+	defaultSuccessfulJobsHistoryLimit = 3
+	defaultFailedJobsHistoryLimit     = 1
+)
+
+// KlausCronJobReconciler reconciles a KlausCronJob object by creating
+// KlausJob runs on its schedule and pruning finished runs.
+type KlausCronJobReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// APIReader is an uncached reader the status is re-read from on a
+	// conflict. The cached client is used when nil.
+	APIReader client.Reader
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+
+	// ControllerOptions tunes the work queue of the controller.
+	ControllerOptions ControllerOptions
+}
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klauscronjobs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klauscronjobs/status,verbs=get;update;patch
+
+// Reconcile handles KlausCronJob create/update events and scheduled wake-ups.
+func (r *KlausCronJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var cron klausv1alpha1.KlausCronJob
+	if err := r.Get(ctx, req.NamespacedName, &cron); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	ctx = withStatusBase(ctx, &cron)
+	if !cron.DeletionTimestamp.IsZero() {
+		// Runs are owned by the cron job and garbage collected with it.
+		return ctrl.Result{}, nil
+	}
+
+	var jobs klausv1alpha1.KlausJobList
+	if err := r.List(ctx, &jobs,
+		client.InNamespace(cron.Namespace),
+		client.MatchingLabels{resources.LabelCronJob: cron.Name},
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+	active, succeeded, failed := classifyRuns(jobs.Items)
+	recordLastRun(&cron, succeeded, failed)
+
+	if err := pruneHistory(ctx, r.Client, succeeded, historyLimit(cron.Spec.SuccessfulJobsHistoryLimit, defaultSuccessfulJobsHistoryLimit)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := pruneHistory(ctx, r.Client, failed, historyLimit(cron.Spec.FailedJobsHistoryLimit, defaultFailedJobsHistoryLimit)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	sched, err := schedule.Parse(cron.Spec.Schedule)
+	if err != nil {
+		return r.updateStatus(ctx, &cron, active, metav1.ConditionFalse, "InvalidSchedule", err.Error())
+	}
+	loc := time.UTC
+	if cron.Spec.TimeZone != "" {
+		if loc, err = time.LoadLocation(cron.Spec.TimeZone); err != nil {
+			return r.updateStatus(ctx, &cron, active, metav1.ConditionFalse, "InvalidTimeZone", err.Error())
+		}
+	}
+	if cron.Spec.Suspend {
+		return r.updateStatus(ctx, &cron, active, metav1.ConditionFalse, "Suspended", "Scheduling is suspended")
+	}
+
+	now := r.now().In(loc)
+	scheduled, next := mostRecentActivation(&cron, sched, now)
+	if next.IsZero() {
+		return r.updateStatus(ctx, &cron, active, metav1.ConditionFalse, "NeverScheduled", "Schedule has no upcoming activation")
+	}
+	requeue := ctrl.Result{RequeueAfter: next.Sub(now)}
+	nextMessage := "Next run at " + next.UTC().Format(time.RFC3339)
+
+	if scheduled.IsZero() {
+		_, err := r.updateStatus(ctx, &cron, active, metav1.ConditionTrue, "Scheduled", nextMessage)
+		return requeue, err
+	}
+
+	policy := cron.Spec.ConcurrencyPolicy
+	if policy == "" {
+		policy = klausv1alpha1.ConcurrencyForbid
+	}
+	if len(active) > 0 {
+		switch policy {
+		case klausv1alpha1.ConcurrencyForbid:
+			// Keep the missed activation pending; the run starts once the
+			// active one finishes, unless the starting deadline passes first.
+			_, err := r.updateStatus(ctx, &cron, active, metav1.ConditionTrue, "Waiting",
+				"Run skipped while a previous run is still active")
+			return requeue, err
+		case klausv1alpha1.ConcurrencyReplace:
+			for i := range active {
+				if err := r.Delete(ctx, &active[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+					return ctrl.Result{}, err
+				}
+				r.Recorder.Event(&cron, corev1.EventTypeNormal, "ReplacedJob", "Deleted active run "+active[i].Name)
+			}
+			active = nil
+		}
+	}
+
+	run := resources.BuildCronJobRun(&cron, scheduled)
+	if err := controllerutil.SetControllerReference(&cron, run, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Create(ctx, run); err != nil && !apierrors.IsAlreadyExists(err) {
+		r.Recorder.Event(&cron, corev1.EventTypeWarning, "FailedCreate", err.Error())
+		_, _ = r.updateStatus(ctx, &cron, active, metav1.ConditionFalse, "JobError", err.Error())
+		return ctrl.Result{}, err
+	}
+	logger.Info("created scheduled KlausJob", "cronjob", cron.Name, "job", run.Name, "scheduled", scheduled)
+	r.Recorder.Event(&cron, corev1.EventTypeNormal, "SuccessfulCreate", "Created run "+run.Name)
+
+	cron.Status.LastScheduleTime = &metav1.Time{Time: scheduled}
+	active = append(active, *run)
+	_, err = r.updateStatus(ctx, &cron, active, metav1.ConditionTrue, "Scheduled", nextMessage)
+	return requeue, err
+}
+
+func (r *KlausCronJobReconciler) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// mostRecentActivation returns the latest activation that is due but has not
+// been scheduled yet (zero if none), and the next activation after now.
+func mostRecentActivation(cron *klausv1alpha1.KlausCronJob, sched *schedule.Schedule, now time.Time) (time.Time, time.Time) {
+	earliest := cron.CreationTimestamp.Time
+	if cron.Status.LastScheduleTime != nil {
+		earliest = cron.Status.LastScheduleTime.Time
+	}
+	// Activations older than the starting deadline can never run, so there
+	// is no need to walk through them after a long outage.
+	if deadline := cron.Spec.StartingDeadlineSeconds; deadline != nil {
+		if cutoff := now.Add(-time.Duration(*deadline) * time.Second); cutoff.After(earliest) {
+			earliest = cutoff
+		}
+	}
+
+	var scheduled time.Time
+	t := sched.Next(earliest.In(now.Location()))
+	for !t.IsZero() && !t.After(now) {
+		scheduled = t
+		t = sched.Next(t)
+	}
+	return scheduled, t
+}
+
+// classifyRuns splits the runs of a cron job into active, succeeded and
+// failed, with finished runs ordered oldest first.
+func classifyRuns(jobs []klausv1alpha1.KlausJob) (active, succeeded, failed []klausv1alpha1.KlausJob) {
+	for _, job := range jobs {
+		if !job.DeletionTimestamp.IsZero() {
+			continue
+		}
+		switch job.Status.State {
+		case klausv1alpha1.JobStateSucceeded:
+			succeeded = append(succeeded, job)
+		case klausv1alpha1.JobStateFailed:
+			failed = append(failed, job)
+		default:
+			active = append(active, job)
+		}
+	}
+	byCompletion := func(a, b klausv1alpha1.KlausJob) int {
+		return completionTime(a).Compare(completionTime(b))
+	}
+	slices.SortFunc(succeeded, byCompletion)
+	slices.SortFunc(failed, byCompletion)
+	return active, succeeded, failed
+}
+
+func completionTime(job klausv1alpha1.KlausJob) time.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time
+	}
+	return job.CreationTimestamp.Time
+}
+
+// recordLastRun stores the most recently finished run and the last success
+// in the cron job status.
+func recordLastRun(cron *klausv1alpha1.KlausCronJob, succeeded, failed []klausv1alpha1.KlausJob) {
+	var last *klausv1alpha1.KlausJob
+	if len(succeeded) > 0 {
+		last = &succeeded[len(succeeded)-1]
+		completion := metav1.NewTime(completionTime(*last))
+		cron.Status.LastSuccessfulTime = &completion
+	}
+	if len(failed) > 0 {
+		f := &failed[len(failed)-1]
+		if last == nil || completionTime(*f).After(completionTime(*last)) {
+			last = f
+		}
+	}
+	if last == nil {
+		return
+	}
+	completion := metav1.NewTime(completionTime(*last))
+	cron.Status.LastRun = &klausv1alpha1.CronJobRunSummary{
+		JobName:        last.Name,
+		State:          last.Status.State,
+		CompletionTime: &completion,
+		Result:         last.Status.Result,
+		Summary:        last.Status.Summary,
+	}
+}
+
+// pruneHistory deletes the oldest finished runs beyond limit.
+func pruneHistory(ctx context.Context, c client.Client, finished []klausv1alpha1.KlausJob, limit int) error {
+	for i := 0; i < len(finished)-limit; i++ {
+		if err := c.Delete(ctx, &finished[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("pruning KlausJob %s: %w", finished[i].Name, err)
+		}
+	}
+	return nil
+}
+
+func historyLimit(limit *int32, def int) int {
+	if limit == nil {
+		return def
+	}
+	return int(*limit)
+}
+
+func (r *KlausCronJobReconciler) updateStatus(ctx context.Context, cron *klausv1alpha1.KlausCronJob, active []klausv1alpha1.KlausJob, status metav1.ConditionStatus, reason, message string) (ctrl.Result, error) {
+	cron.Status.Active = nil
+	for _, job := range active {
+		cron.Status.Active = append(cron.Status.Active, job.Name)
+	}
+	cron.Status.ObservedGeneration = cron.Generation
+	apimeta.SetStatusCondition(&cron.Status.Conditions, metav1.Condition{
+		Type:               CronJobConditionScheduled,
+		Status:             status,
+		ObservedGeneration: cron.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, cron)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KlausCronJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&klausv1alpha1.KlausCronJob{},
+			builder.WithPredicates(primaryPredicate())).
+		Owns(&klausv1alpha1.KlausJob{},
+			builder.WithPredicates(childChangedPredicate())).
+		Named(ControllerNameKlausCronJob).
+		WithOptions(r.ControllerOptions.For(ControllerNameKlausCronJob)).
+		Complete(r)
+}
@@ -0,0 +1,292 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+var cronTestCreated = time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+
+func newCronJob(name, expr string) *klausv1alpha1.KlausCronJob {
+	return &klausv1alpha1.KlausCronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "klaus-system",
+			UID:               "cron-uid",
+			CreationTimestamp: metav1.NewTime(cronTestCreated),
+		},
+		Spec: klausv1alpha1.KlausCronJobSpec{
+			Schedule: expr,
+			JobTemplate: klausv1alpha1.KlausJobSpec{
+				Owner:  "user@example.com",
+				Prompt: "Triage new issues",
+			},
+		},
+	}
+}
+
+func cronRun(cron *klausv1alpha1.KlausCronJob, name string, state klausv1alpha1.JobState, completed time.Time) *klausv1alpha1.KlausJob {
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cron.Namespace,
+			Labels:    map[string]string{resources.LabelCronJob: cron.Name},
+		},
+		Spec:   cron.Spec.JobTemplate,
+		Status: klausv1alpha1.KlausJobStatus{State: state},
+	}
+	if !completed.IsZero() {
+		job.Status.CompletionTime = &metav1.Time{Time: completed}
+		job.Status.Result = "result of " + name
+	}
+	return job
+}
+
+func newCronReconciler(c client.Client, now time.Time) *KlausCronJobReconciler {
+	return &KlausCronJobReconciler{
+		Client:   c,
+		Scheme:   c.Scheme(),
+		Recorder: record.NewFakeRecorder(20),
+		Now:      func() time.Time { return now },
+	}
+}
+
+func reconcileCron(t *testing.T, r *KlausCronJobReconciler, name string) ctrl.Result {
+	t.Helper()
+	res, err := r.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: name, Namespace: "klaus-system"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return res
+}
+
+func listCronRuns(t *testing.T, c client.Client) []klausv1alpha1.KlausJob {
+	t.Helper()
+	var jobs klausv1alpha1.KlausJobList
+	if err := c.List(context.Background(), &jobs, client.InNamespace("klaus-system")); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	return jobs.Items
+}
+
+func TestKlausCronJobReconcile_CreatesRunWhenDue(t *testing.T) {
+	cron := newCronJob("nightly", "0 2 * * *")
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron).WithStatusSubresource(cron).Build()
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 2, 0, 30, 0, time.UTC))
+
+	res := reconcileCron(t, r, "nightly")
+
+	jobs := listCronRuns(t, c)
+	if len(jobs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(jobs))
+	}
+	run := jobs[0]
+	if run.Name != "nightly-29557560" {
+		t.Errorf("run name = %q", run.Name)
+	}
+	if ref := metav1.GetControllerOf(&run); ref == nil || ref.Name != "nightly" {
+		t.Errorf("expected controller reference to the cron job, got %v", ref)
+	}
+	if want := 24*time.Hour - 30*time.Second; res.RequeueAfter != want {
+		t.Errorf("RequeueAfter = %v, want %v", res.RequeueAfter, want)
+	}
+
+	var updated klausv1alpha1.KlausCronJob
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "nightly", Namespace: "klaus-system"}, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.LastScheduleTime == nil || !updated.Status.LastScheduleTime.Equal(&metav1.Time{Time: time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)}) {
+		t.Errorf("LastScheduleTime = %v", updated.Status.LastScheduleTime)
+	}
+	if len(updated.Status.Active) != 1 || updated.Status.Active[0] != run.Name {
+		t.Errorf("Active = %v", updated.Status.Active)
+	}
+
+	// A second reconcile in the same minute must not create another run.
+	reconcileCron(t, r, "nightly")
+	if n := len(listCronRuns(t, c)); n != 1 {
+		t.Errorf("expected 1 run after second reconcile, got %d", n)
+	}
+}
+
+func TestKlausCronJobReconcile_NotDueYet(t *testing.T) {
+	cron := newCronJob("nightly", "0 2 * * *")
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron).WithStatusSubresource(cron).Build()
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 1, 0, 0, 0, time.UTC))
+
+	res := reconcileCron(t, r, "nightly")
+
+	if n := len(listCronRuns(t, c)); n != 0 {
+		t.Errorf("expected no runs, got %d", n)
+	}
+	if res.RequeueAfter != time.Hour {
+		t.Errorf("RequeueAfter = %v, want 1h", res.RequeueAfter)
+	}
+}
+
+func TestKlausCronJobReconcile_ForbidSkipsWhileActive(t *testing.T) {
+	cron := newCronJob("nightly", "0 2 * * *")
+	cron.Spec.ConcurrencyPolicy = klausv1alpha1.ConcurrencyForbid
+	active := cronRun(cron, "nightly-old", klausv1alpha1.JobStateRunning, time.Time{})
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron, active).WithStatusSubresource(cron).Build()
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 2, 0, 30, 0, time.UTC))
+
+	reconcileCron(t, r, "nightly")
+
+	jobs := listCronRuns(t, c)
+	if len(jobs) != 1 || jobs[0].Name != "nightly-old" {
+		t.Errorf("expected only the active run, got %v", jobs)
+	}
+	var updated klausv1alpha1.KlausCronJob
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "nightly", Namespace: "klaus-system"}, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status.LastScheduleTime != nil {
+		t.Errorf("expected LastScheduleTime unset, got %v", updated.Status.LastScheduleTime)
+	}
+}
+
+func TestKlausCronJobReconcile_ReplaceDeletesActive(t *testing.T) {
+	cron := newCronJob("nightly", "0 2 * * *")
+	cron.Spec.ConcurrencyPolicy = klausv1alpha1.ConcurrencyReplace
+	active := cronRun(cron, "nightly-old", klausv1alpha1.JobStateRunning, time.Time{})
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron, active).WithStatusSubresource(cron).Build()
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 2, 0, 30, 0, time.UTC))
+
+	reconcileCron(t, r, "nightly")
+
+	jobs := listCronRuns(t, c)
+	if len(jobs) != 1 || jobs[0].Name != "nightly-29557560" {
+		t.Errorf("expected only the new run, got %v", jobs)
+	}
+}
+
+func TestKlausCronJobReconcile_StartingDeadlineSkipsOldActivations(t *testing.T) {
+	cron := newCronJob("nightly", "0 2 * * *")
+	deadline := int64(60)
+	cron.Spec.StartingDeadlineSeconds = &deadline
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron).WithStatusSubresource(cron).Build()
+	// The operator comes back an hour after the activation.
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 3, 0, 0, 0, time.UTC))
+
+	reconcileCron(t, r, "nightly")
+
+	if n := len(listCronRuns(t, c)); n != 0 {
+		t.Errorf("expected the missed run to be skipped, got %d runs", n)
+	}
+}
+
+func TestKlausCronJobReconcile_Suspended(t *testing.T) {
+	cron := newCronJob("nightly", "* * * * *")
+	cron.Spec.Suspend = true
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron).WithStatusSubresource(cron).Build()
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC))
+
+	reconcileCron(t, r, "nightly")
+
+	if n := len(listCronRuns(t, c)); n != 0 {
+		t.Errorf("expected no runs while suspended, got %d", n)
+	}
+	var updated klausv1alpha1.KlausCronJob
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "nightly", Namespace: "klaus-system"}, &updated); err != nil {
+		t.Fatal(err)
+	}
+	cond := apimeta.FindStatusCondition(updated.Status.Conditions, CronJobConditionScheduled)
+	if cond == nil || cond.Reason != "Suspended" {
+		t.Errorf("expected Suspended condition, got %v", cond)
+	}
+}
+
+func TestKlausCronJobReconcile_InvalidSchedule(t *testing.T) {
+	cron := newCronJob("nightly", "not a schedule")
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron).WithStatusSubresource(cron).Build()
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC))
+
+	reconcileCron(t, r, "nightly")
+
+	var updated klausv1alpha1.KlausCronJob
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "nightly", Namespace: "klaus-system"}, &updated); err != nil {
+		t.Fatal(err)
+	}
+	cond := apimeta.FindStatusCondition(updated.Status.Conditions, CronJobConditionScheduled)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "InvalidSchedule" {
+		t.Errorf("expected InvalidSchedule condition, got %v", cond)
+	}
+}
+
+func TestKlausCronJobReconcile_PrunesHistoryAndRecordsLastRun(t *testing.T) {
+	cron := newCronJob("nightly", "0 2 * * *")
+	one := int32(1)
+	cron.Spec.SuccessfulJobsHistoryLimit = &one
+	cron.Spec.FailedJobsHistoryLimit = &one
+	cron.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)}
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 2, 30, 0, 0, time.UTC) }
+	objs := []client.Object{
+		cron,
+		cronRun(cron, "ok-1", klausv1alpha1.JobStateSucceeded, day(10)),
+		cronRun(cron, "ok-2", klausv1alpha1.JobStateSucceeded, day(12)),
+		cronRun(cron, "fail-1", klausv1alpha1.JobStateFailed, day(11)),
+		cronRun(cron, "fail-2", klausv1alpha1.JobStateFailed, day(13)),
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(objs...).WithStatusSubresource(cron).Build()
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC))
+
+	reconcileCron(t, r, "nightly")
+
+	names := map[string]bool{}
+	for _, job := range listCronRuns(t, c) {
+		names[job.Name] = true
+	}
+	if len(names) != 2 || !names["ok-2"] || !names["fail-2"] {
+		t.Errorf("expected ok-2 and fail-2 to remain, got %v", names)
+	}
+
+	var updated klausv1alpha1.KlausCronJob
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "nightly", Namespace: "klaus-system"}, &updated); err != nil {
+		t.Fatal(err)
+	}
+	last := updated.Status.LastRun
+	if last == nil || last.JobName != "fail-2" || last.State != klausv1alpha1.JobStateFailed || last.Result != "result of fail-2" {
+		t.Errorf("LastRun = %+v", last)
+	}
+	if updated.Status.LastSuccessfulTime == nil || !updated.Status.LastSuccessfulTime.Time.Equal(day(12)) {
+		t.Errorf("LastSuccessfulTime = %v", updated.Status.LastSuccessfulTime)
+	}
+}
+
+func TestKlausCronJobReconcile_TimeZone(t *testing.T) {
+	cron := newCronJob("nightly", "0 2 * * *")
+	cron.Spec.TimeZone = "Europe/Berlin"
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(cron).WithStatusSubresource(cron).Build()
+	// 02:00 in Berlin on 14 March is 01:00 UTC.
+	r := newCronReconciler(c, time.Date(2026, 3, 14, 1, 0, 30, 0, time.UTC))
+
+	reconcileCron(t, r, "nightly")
+
+	if n := len(listCronRuns(t, c)); n != 1 {
+		t.Errorf("expected 1 run at 02:00 Berlin time, got %d", n)
+	}
+}
@@ -0,0 +1,384 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// AnnotationFleetOperation on a KlausInstance records the UID of the last
+// KlausFleetOperation that changed it, so every operation changes an
+// instance once.
+const AnnotationFleetOperation = "klaus.giantswarm.io/fleet-operation"
+
+const (
+	// fleetOperationPollInterval is how often a running operation checks
+	// the rollout of the instances it changed.
+	fleetOperationPollInterval = 15 * time.Second
+
+	// fleetOperationRolloutDeadline is how long an instance may take to
+	// roll out after it was changed before the operation fails on it.
+	fleetOperationRolloutDeadline = 15 * time.Minute
+
+	// maxFleetOperationFailures bounds status.failures of a
+	// KlausFleetOperation.
+	maxFleetOperationFailures = 50
+
+	defaultFleetOperationBatchSize = 5
+)
+
+// KlausFleetOperationReconciler reconciles a KlausFleetOperation object by
+// changing the selected KlausInstances in batches and tracking their
+// rollout.
+type KlausFleetOperationReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// APIReader is an uncached reader the status is re-read from on a
+	// conflict. The cached client is used when nil.
+	APIReader client.Reader
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+
+	// OperatorNamespace is the namespace KlausToolchains are read from.
+	OperatorNamespace string
+
+	// NamespaceScoped is set when the child resources of instances live in
+	// the namespace of the instance rather than in the owner's namespace.
+	NamespaceScoped bool
+
+	// ControllerOptions tunes the work queue of the controller.
+	ControllerOptions ControllerOptions
+}
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausfleetoperations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausfleetoperations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausinstances,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustoolchains,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+
+// Reconcile advances a KlausFleetOperation: it checks the rollout of the
+// instances it changed and changes the next selected instances while fewer
+// than spec.batchSize are rolling out.
+func (r *KlausFleetOperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var op klausv1alpha1.KlausFleetOperation
+	if err := r.Get(ctx, req.NamespacedName, &op); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	ctx = withStatusBase(ctx, &op)
+	if op.Status.State == klausv1alpha1.FleetOperationStateSucceeded || op.Status.State == klausv1alpha1.FleetOperationStateFailed {
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("reconciling KlausFleetOperation", "name", op.Name, "type", op.Spec.Type)
+
+	// A missing replacement toolchain fails the operation before it changes
+	// any instance.
+	if op.Status.StartTime == nil {
+		if err := r.validateFleetOperation(ctx, &op); err != nil {
+			return ctrl.Result{}, r.finishFleetOperation(ctx, &op, err.Error())
+		}
+	}
+
+	var instanceList klausv1alpha1.KlausInstanceList
+	if err := r.List(ctx, &instanceList, client.InNamespace(op.Namespace)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing KlausInstances: %w", err)
+	}
+	instances := instanceList.Items
+	slices.SortFunc(instances, func(a, b klausv1alpha1.KlausInstance) int { return cmp.Compare(a.Name, b.Name) })
+
+	now := r.now()
+	var changed, pending []*klausv1alpha1.KlausInstance
+	for i := range instances {
+		instance := &instances[i]
+		switch {
+		case instance.Annotations[AnnotationFleetOperation] == string(op.UID) || slices.Contains(op.Status.Changed, instance.Name):
+			changed = append(changed, instance)
+		case instance.DeletionTimestamp.IsZero() && fleetOperationSelects(&op, instance):
+			pending = append(pending, instance)
+		}
+	}
+
+	op.Status.Total = int32(len(changed) + len(pending))
+	op.Status.Completed = 0
+	op.Status.InProgress = nil
+	var failures []klausv1alpha1.FleetOperationFailure
+	for _, instance := range changed {
+		if instance.Annotations[AnnotationFleetOperation] != string(op.UID) {
+			// Recorded as changed, but the change is not cached yet or
+			// its patch failed.
+			if err := r.reapplyFleetOperation(ctx, &op, instance, now); err != nil {
+				return ctrl.Result{}, fmt.Errorf("changing KlausInstance %s: %w", instance.Name, err)
+			}
+			op.Status.InProgress = append(op.Status.InProgress, instance.Name)
+			continue
+		}
+		done, failure, err := r.instanceRolledOut(ctx, instance, now)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		switch {
+		case failure != "":
+			failures = append(failures, klausv1alpha1.FleetOperationFailure{Name: instance.Name, Message: failure})
+		case done:
+			op.Status.Completed++
+		default:
+			op.Status.InProgress = append(op.Status.InProgress, instance.Name)
+		}
+	}
+
+	op.Status.Failed = int32(len(failures))
+	op.Status.Failures = failures[:min(len(failures), maxFleetOperationFailures)]
+	if len(failures) > int(op.Spec.MaxFailures) {
+		message := fmt.Sprintf("%s failed on %d of %d instances, more than the %d allowed; %d instances were not changed",
+			op.Spec.Type, len(failures), op.Status.Total, op.Spec.MaxFailures, len(pending))
+		return ctrl.Result{}, r.finishFleetOperation(ctx, &op, message)
+	}
+
+	// Change the next instances while fewer than a batch roll out. They
+	// are recorded in the status before they are changed.
+	batchSize := int(cmp.Or(op.Spec.BatchSize, defaultFleetOperationBatchSize))
+	batch := pending[:min(len(pending), max(batchSize-len(op.Status.InProgress), 0))]
+	pending = pending[len(batch):]
+	if len(batch) > 0 {
+		for _, instance := range batch {
+			op.Status.Changed = append(op.Status.Changed, instance.Name)
+			op.Status.InProgress = append(op.Status.InProgress, instance.Name)
+		}
+		if op.Status.StartTime == nil {
+			op.Status.StartTime = &metav1.Time{Time: now}
+		}
+		op.Status.State = klausv1alpha1.FleetOperationStateRunning
+		if err := patchObjectStatus(ctx, r.Client, r.APIReader, &op); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	for _, instance := range batch {
+		if err := r.applyFleetOperation(ctx, &op, instance, now); err != nil {
+			return ctrl.Result{}, fmt.Errorf("changing KlausInstance %s: %w", instance.Name, err)
+		}
+	}
+
+	if len(op.Status.InProgress) == 0 && len(pending) == 0 {
+		return ctrl.Result{}, r.finishFleetOperation(ctx, &op, "")
+	}
+
+	op.Status.State = klausv1alpha1.FleetOperationStateRunning
+	op.Status.Message = fmt.Sprintf("%d of %d instances completed, %d rolling out", op.Status.Completed, op.Status.Total, len(op.Status.InProgress))
+	return ctrl.Result{RequeueAfter: fleetOperationPollInterval}, patchObjectStatus(ctx, r.Client, r.APIReader, &op)
+}
+
+// validateFleetOperation checks that the replacement toolchain of a
+// DrainToolchain operation exists.
+func (r *KlausFleetOperationReconciler) validateFleetOperation(ctx context.Context, op *klausv1alpha1.KlausFleetOperation) error {
+	if op.Spec.Type != klausv1alpha1.FleetOperationDrainToolchain {
+		return nil
+	}
+	if op.Spec.Toolchain == "" || op.Spec.ReplacementToolchain == "" {
+		return fmt.Errorf("DrainToolchain requires spec.toolchain and spec.replacementToolchain")
+	}
+	var toolchain klausv1alpha1.KlausToolchain
+	err := r.Get(ctx, types.NamespacedName{Name: op.Spec.ReplacementToolchain, Namespace: r.OperatorNamespace}, &toolchain)
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("replacement toolchain %q not found in namespace %s", op.Spec.ReplacementToolchain, r.OperatorNamespace)
+	}
+	return err
+}
+
+// fleetOperationSelects reports whether the operation applies to an instance
+// it has not changed yet. Restarts and API key reloads skip stopped
+// instances, which pick up the change when they start, and reloads skip
+// instances not using the Anthropic API key.
+func fleetOperationSelects(op *klausv1alpha1.KlausFleetOperation, instance *klausv1alpha1.KlausInstance) bool {
+	spec := op.Spec
+	if spec.Owner != "" && instance.Spec.Owner != spec.Owner {
+		return false
+	}
+	if spec.Personality != "" && !personalityMatches(spec.Personality, instance.Spec.Personality) {
+		return false
+	}
+	if spec.Toolchain != "" && instance.Spec.ToolchainRef != spec.Toolchain {
+		return false
+	}
+	switch spec.Type {
+	case klausv1alpha1.FleetOperationRestart:
+		return !resources.IsStopped(instance)
+	case klausv1alpha1.FleetOperationReloadAPIKeys:
+		return !resources.IsStopped(instance) && resources.UsesAnthropicAPIKey(instance)
+	case klausv1alpha1.FleetOperationDrainToolchain:
+		return spec.Toolchain != ""
+	}
+	return false
+}
+
+// personalityMatches reports whether the personality reference ref is
+// selected by selector: the same reference, or any version of the
+// repository of a selector without a tag or digest.
+func personalityMatches(selector, ref string) bool {
+	if ref == "" {
+		return false
+	}
+	if selector == ref {
+		return true
+	}
+	return ociRepository(selector) == selector && ociRepository(ref) == selector
+}
+
+// ociRepository returns an OCI reference without its tag and digest.
+func ociRepository(ref string) string {
+	ref, _, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// applyFleetOperation changes an instance for the operation. Every type
+// restarts the pods through resources.AnnotationRestartedAt, whose reconcile
+// also copies the API key Secret again; DrainToolchain also replaces
+// spec.toolchainRef.
+func (r *KlausFleetOperationReconciler) applyFleetOperation(ctx context.Context, op *klausv1alpha1.KlausFleetOperation, instance *klausv1alpha1.KlausInstance, now time.Time) error {
+	base := instance.DeepCopy()
+	if instance.Annotations == nil {
+		instance.Annotations = map[string]string{}
+	}
+	instance.Annotations[AnnotationFleetOperation] = string(op.UID)
+	instance.Annotations[resources.AnnotationRestartedAt] = now.UTC().Format(time.RFC3339)
+
+	message := fmt.Sprintf("Restarted by KlausFleetOperation %s", op.Name)
+	switch op.Spec.Type {
+	case klausv1alpha1.FleetOperationReloadAPIKeys:
+		message = fmt.Sprintf("Reloading the API key for KlausFleetOperation %s", op.Name)
+	case klausv1alpha1.FleetOperationDrainToolchain:
+		instance.Spec.ToolchainRef = op.Spec.ReplacementToolchain
+		message = fmt.Sprintf("Moved from toolchain %s to %s by KlausFleetOperation %s", op.Spec.Toolchain, op.Spec.ReplacementToolchain, op.Name)
+	}
+	if err := r.Patch(ctx, instance, client.MergeFrom(base)); err != nil {
+		return err
+	}
+	r.Recorder.Event(instance, corev1.EventTypeNormal, "FleetOperation", message)
+	return nil
+}
+
+// reapplyFleetOperation changes an instance recorded as changed by the
+// operation whose cached copy does not show the change. The instance is read
+// from the API server first: when the change is there, the cache lags and
+// the instance is not changed, and restarted, again.
+func (r *KlausFleetOperationReconciler) reapplyFleetOperation(ctx context.Context, op *klausv1alpha1.KlausFleetOperation, instance *klausv1alpha1.KlausInstance, now time.Time) error {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	var live klausv1alpha1.KlausInstance
+	if err := reader.Get(ctx, client.ObjectKeyFromObject(instance), &live); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if live.Annotations[AnnotationFleetOperation] == string(op.UID) {
+		return nil
+	}
+	return r.applyFleetOperation(ctx, op, &live, now)
+}
+
+// instanceRolledOut reports whether an instance changed by the operation at
+// its resources.AnnotationRestartedAt time has rolled out, or why the
+// operation failed on it.
+func (r *KlausFleetOperationReconciler) instanceRolledOut(ctx context.Context, instance *klausv1alpha1.KlausInstance, now time.Time) (bool, string, error) {
+	restartedAt := instance.Annotations[resources.AnnotationRestartedAt]
+	if instance.Status.ObservedGeneration == instance.Generation && instance.Status.State == klausv1alpha1.InstanceStateError {
+		message := "instance reports an error"
+		if ready := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionReady); ready != nil {
+			message = fmt.Sprintf("instance reports %s: %s", ready.Reason, ready.Message)
+		}
+		return false, message, nil
+	}
+
+	var dep appsv1.Deployment
+	key := types.NamespacedName{Name: resources.DeploymentName(instance), Namespace: resources.InstanceNamespace(instance, r.NamespaceScoped)}
+	err := r.Get(ctx, key, &dep)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, "", fmt.Errorf("getting Deployment of KlausInstance %s: %w", instance.Name, err)
+	}
+	if err == nil && instance.Status.ObservedGeneration == instance.Generation &&
+		dep.Spec.Template.Annotations[resources.AnnotationRestartedAt] == restartedAt && deploymentRolledOut(&dep) {
+		return true, "", nil
+	}
+
+	if changedAt, err := time.Parse(time.RFC3339, restartedAt); err == nil && now.Sub(changedAt) > fleetOperationRolloutDeadline {
+		return false, fmt.Sprintf("not rolled out within %s", fleetOperationRolloutDeadline), nil
+	}
+	return false, "", nil
+}
+
+// deploymentRolledOut reports whether every replica of a Deployment runs its
+// current pod template and is available.
+func deploymentRolledOut(dep *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if dep.Spec.Replicas != nil {
+		replicas = *dep.Spec.Replicas
+	}
+	return dep.Status.ObservedGeneration >= dep.Generation &&
+		dep.Status.UpdatedReplicas == replicas &&
+		dep.Status.Replicas == replicas &&
+		dep.Status.AvailableReplicas == replicas
+}
+
+// finishFleetOperation records the end of an operation: it succeeded,
+// possibly with as many failures as spec.maxFailures allows, without a
+// failure message and failed with one.
+func (r *KlausFleetOperationReconciler) finishFleetOperation(ctx context.Context, op *klausv1alpha1.KlausFleetOperation, failure string) error {
+	op.Status.InProgress = nil
+	op.Status.CompletionTime = &metav1.Time{Time: r.now()}
+	if failure != "" {
+		op.Status.State = klausv1alpha1.FleetOperationStateFailed
+		op.Status.Message = failure
+		r.Recorder.Event(op, corev1.EventTypeWarning, "Failed", failure)
+	} else {
+		op.Status.State = klausv1alpha1.FleetOperationStateSucceeded
+		op.Status.Message = fmt.Sprintf("%s completed on %d instances", op.Spec.Type, op.Status.Completed)
+		if op.Status.Failed > 0 {
+			op.Status.Message += fmt.Sprintf(", failed on %d", op.Status.Failed)
+		}
+		r.Recorder.Event(op, corev1.EventTypeNormal, "Succeeded", op.Status.Message)
+	}
+	return patchObjectStatus(ctx, r.Client, r.APIReader, op)
+}
+
+func (r *KlausFleetOperationReconciler) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// SetupWithManager sets up the controller with the Manager. Running
+// operations poll the rollout of the instances they changed.
+func (r *KlausFleetOperationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&klausv1alpha1.KlausFleetOperation{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named(ControllerNameKlausFleetOperation).
+		WithOptions(r.ControllerOptions.For(ControllerNameKlausFleetOperation)).
+		Complete(r)
+}
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func fleetOperationTestInstance(name, personality string) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance(name, testOwner)
+	instance.Spec.Personality = personality
+	instance.Spec.ToolchainRef = "go-1-22"
+	return instance
+}
+
+func fleetOperationReconciler(t *testing.T, now *time.Time, objs ...client.Object) *KlausFleetOperationReconciler {
+	t.Helper()
+	scheme := testScheme(t)
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding appsv1 to scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&klausv1alpha1.KlausFleetOperation{}, &klausv1alpha1.KlausInstance{}).Build()
+	return &KlausFleetOperationReconciler{
+		Client:            c,
+		Scheme:            scheme,
+		Recorder:          record.NewFakeRecorder(100),
+		Now:               func() time.Time { return *now },
+		OperatorNamespace: "klaus-system",
+	}
+}
+
+func reconcileFleetOperation(t *testing.T, r *KlausFleetOperationReconciler, name string) (ctrl.Result, *klausv1alpha1.KlausFleetOperation) {
+	t.Helper()
+	key := types.NamespacedName{Name: name, Namespace: "klaus-system"}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	var op klausv1alpha1.KlausFleetOperation
+	if err := r.Get(context.Background(), key, &op); err != nil {
+		t.Fatalf("getting KlausFleetOperation: %v", err)
+	}
+	return result, &op
+}
+
+// changedInstances returns the names of the instances changed by op.
+func changedInstances(t *testing.T, r *KlausFleetOperationReconciler, op *klausv1alpha1.KlausFleetOperation) []string {
+	t.Helper()
+	var list klausv1alpha1.KlausInstanceList
+	if err := r.List(context.Background(), &list); err != nil {
+		t.Fatalf("listing KlausInstances: %v", err)
+	}
+	var names []string
+	for _, instance := range list.Items {
+		if instance.Annotations[AnnotationFleetOperation] == string(op.UID) {
+			names = append(names, instance.Name)
+		}
+	}
+	return names
+}
+
+// rollOut creates the rolled out Deployment of an instance, as the instance
+// controller and the Deployment controller would.
+func rollOut(t *testing.T, r *KlausFleetOperationReconciler, name string) {
+	t.Helper()
+	ctx := context.Background()
+	var instance klausv1alpha1.KlausInstance
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("getting KlausInstance: %v", err)
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: resources.DeploymentName(&instance), Namespace: resources.InstanceNamespace(&instance, false)},
+	}
+	dep.Spec.Template.Annotations = map[string]string{resources.AnnotationRestartedAt: instance.Annotations[resources.AnnotationRestartedAt]}
+	if err := r.Create(ctx, dep); err != nil {
+		t.Fatalf("creating Deployment: %v", err)
+	}
+	dep.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if err := r.Status().Update(ctx, dep); err != nil {
+		t.Fatalf("updating Deployment status: %v", err)
+	}
+}
+
+func TestKlausFleetOperationReconcile_RestartInBatches(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	op := &klausv1alpha1.KlausFleetOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "restart", Namespace: "klaus-system", UID: "op-uid"},
+		Spec: klausv1alpha1.KlausFleetOperationSpec{
+			Type:        klausv1alpha1.FleetOperationRestart,
+			Personality: "registry.example.com/personalities/reviewer",
+			BatchSize:   2,
+		},
+	}
+	stopped := fleetOperationTestInstance("d-stopped", "registry.example.com/personalities/reviewer:v1")
+	stopped.Spec.DesiredState = klausv1alpha1.DesiredStateStopped
+	r := fleetOperationReconciler(t, &now, op,
+		fleetOperationTestInstance("a", "registry.example.com/personalities/reviewer:v1"),
+		fleetOperationTestInstance("b", "registry.example.com/personalities/reviewer:v2"),
+		fleetOperationTestInstance("c", "registry.example.com/personalities/reviewer@sha256:abc"),
+		stopped,
+		fleetOperationTestInstance("e", "registry.example.com/personalities/coder:v1"),
+	)
+
+	result, got := reconcileFleetOperation(t, r, "restart")
+	if got.Status.State != klausv1alpha1.FleetOperationStateRunning || got.Status.Total != 3 || result.RequeueAfter != fleetOperationPollInterval {
+		t.Fatalf("status = %+v, result = %+v, want a running operation on three instances", got.Status, result)
+	}
+	if names := strings.Join(changedInstances(t, r, got), ","); names != "a,b" {
+		t.Errorf("changed %s, want the first batch a,b", names)
+	}
+	if got.Status.StartTime == nil || strings.Join(got.Status.InProgress, ",") != "a,b" || strings.Join(got.Status.Changed, ",") != "a,b" {
+		t.Errorf("status = %+v, want a and b in progress", got.Status)
+	}
+
+	rollOut(t, r, "a")
+	_, got = reconcileFleetOperation(t, r, "restart")
+	if names := strings.Join(changedInstances(t, r, got), ","); names != "a,b,c" {
+		t.Errorf("changed %s, want c once a rolled out", names)
+	}
+	if got.Status.Completed != 1 || strings.Join(got.Status.InProgress, ",") != "b,c" {
+		t.Errorf("status = %+v, want a completed and b,c in progress", got.Status)
+	}
+
+	rollOut(t, r, "b")
+	rollOut(t, r, "c")
+	result, got = reconcileFleetOperation(t, r, "restart")
+	if got.Status.State != klausv1alpha1.FleetOperationStateSucceeded || got.Status.Completed != 3 ||
+		got.Status.CompletionTime == nil || result.RequeueAfter != 0 {
+		t.Errorf("status = %+v, want a succeeded operation", got.Status)
+	}
+
+	var instance klausv1alpha1.KlausInstance
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "a", Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("getting KlausInstance: %v", err)
+	}
+	if instance.Annotations[resources.AnnotationRestartedAt] != "2026-10-01T12:00:00Z" {
+		t.Errorf("annotations = %v, want the restart time", instance.Annotations)
+	}
+}
+
+func TestKlausFleetOperationReconcile_DrainToolchain(t *testing.T) {
+	now := time.Now()
+	op := &klausv1alpha1.KlausFleetOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain", Namespace: "klaus-system", UID: "op-uid"},
+		Spec: klausv1alpha1.KlausFleetOperationSpec{
+			Type:                 klausv1alpha1.FleetOperationDrainToolchain,
+			Toolchain:            "go-1-22",
+			ReplacementToolchain: "go-1-24",
+			BatchSize:            5,
+		},
+	}
+	other := fleetOperationTestInstance("b", "")
+	other.Spec.ToolchainRef = "python-3"
+	replacement := &klausv1alpha1.KlausToolchain{ObjectMeta: metav1.ObjectMeta{Name: "go-1-24", Namespace: "klaus-system"}}
+	r := fleetOperationReconciler(t, &now, op, replacement, fleetOperationTestInstance("a", ""), other)
+
+	_, got := reconcileFleetOperation(t, r, "drain")
+	if got.Status.Total != 1 || strings.Join(changedInstances(t, r, got), ",") != "a" {
+		t.Fatalf("status = %+v, want only the instance on the drained toolchain", got.Status)
+	}
+	var instance klausv1alpha1.KlausInstance
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "a", Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("getting KlausInstance: %v", err)
+	}
+	if instance.Spec.ToolchainRef != "go-1-24" {
+		t.Errorf("toolchainRef = %q, want the replacement", instance.Spec.ToolchainRef)
+	}
+}
+
+func TestKlausFleetOperationReconcile_MissingReplacement(t *testing.T) {
+	now := time.Now()
+	op := &klausv1alpha1.KlausFleetOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "drain", Namespace: "klaus-system", UID: "op-uid"},
+		Spec: klausv1alpha1.KlausFleetOperationSpec{
+			Type:                 klausv1alpha1.FleetOperationDrainToolchain,
+			Toolchain:            "go-1-22",
+			ReplacementToolchain: "go-1-24",
+		},
+	}
+	r := fleetOperationReconciler(t, &now, op, fleetOperationTestInstance("a", ""))
+
+	_, got := reconcileFleetOperation(t, r, "drain")
+	if got.Status.State != klausv1alpha1.FleetOperationStateFailed || !strings.Contains(got.Status.Message, `"go-1-24" not found`) {
+		t.Errorf("status = %+v, want a failure naming the replacement", got.Status)
+	}
+	if names := changedInstances(t, r, got); len(names) > 0 {
+		t.Errorf("changed %v, want no instance changed", names)
+	}
+}
+
+func TestKlausFleetOperationReconcile_RolloutDeadline(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	op := &klausv1alpha1.KlausFleetOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "rotate", Namespace: "klaus-system", UID: "op-uid"},
+		Spec:       klausv1alpha1.KlausFleetOperationSpec{Type: klausv1alpha1.FleetOperationReloadAPIKeys, BatchSize: 5},
+	}
+	mock := fleetOperationTestInstance("b", "")
+	mock.Spec.MockMode = true
+	r := fleetOperationReconciler(t, &now, op, fleetOperationTestInstance("a", ""), mock)
+
+	_, got := reconcileFleetOperation(t, r, "rotate")
+	if got.Status.Total != 1 || got.Status.State != klausv1alpha1.FleetOperationStateRunning {
+		t.Fatalf("status = %+v, want the instance using the API key rotating", got.Status)
+	}
+
+	now = now.Add(fleetOperationRolloutDeadline + time.Minute)
+	_, got = reconcileFleetOperation(t, r, "rotate")
+	if got.Status.State != klausv1alpha1.FleetOperationStateFailed || got.Status.Failed != 1 ||
+		len(got.Status.Failures) != 1 || got.Status.Failures[0].Name != "a" {
+		t.Errorf("status = %+v, want a failed on the rollout deadline", got.Status)
+	}
+}
+
+func TestKlausFleetOperationReconcile_InstanceError(t *testing.T) {
+	now := time.Now()
+	op := &klausv1alpha1.KlausFleetOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "restart", Namespace: "klaus-system", UID: "op-uid"},
+		Spec:       klausv1alpha1.KlausFleetOperationSpec{Type: klausv1alpha1.FleetOperationRestart, BatchSize: 5},
+	}
+	instance := fleetOperationTestInstance("a", "")
+	r := fleetOperationReconciler(t, &now, op, instance)
+	reconcileFleetOperation(t, r, "restart")
+
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(instance), instance); err != nil {
+		t.Fatalf("getting KlausInstance: %v", err)
+	}
+	instance.Status.State = klausv1alpha1.InstanceStateError
+	if err := r.Status().Update(context.Background(), instance); err != nil {
+		t.Fatalf("updating KlausInstance status: %v", err)
+	}
+	_, got := reconcileFleetOperation(t, r, "restart")
+	if got.Status.State != klausv1alpha1.FleetOperationStateFailed || got.Status.Failed != 1 {
+		t.Errorf("status = %+v, want the operation failed on the instance", got.Status)
+	}
+}
+
+func TestKlausFleetOperationReconcile_MaxFailures(t *testing.T) {
+	for _, maxFailures := range []int32{0, 1} {
+		now := time.Now()
+		op := &klausv1alpha1.KlausFleetOperation{
+			ObjectMeta: metav1.ObjectMeta{Name: "restart", Namespace: "klaus-system", UID: "op-uid"},
+			Spec:       klausv1alpha1.KlausFleetOperationSpec{Type: klausv1alpha1.FleetOperationRestart, BatchSize: 1, MaxFailures: maxFailures},
+		}
+		failing := fleetOperationTestInstance("a", "")
+		r := fleetOperationReconciler(t, &now, op, failing, fleetOperationTestInstance("b", ""))
+		reconcileFleetOperation(t, r, "restart")
+
+		if err := r.Get(context.Background(), client.ObjectKeyFromObject(failing), failing); err != nil {
+			t.Fatalf("getting KlausInstance: %v", err)
+		}
+		failing.Status.State = klausv1alpha1.InstanceStateError
+		if err := r.Status().Update(context.Background(), failing); err != nil {
+			t.Fatalf("updating KlausInstance status: %v", err)
+		}
+		_, got := reconcileFleetOperation(t, r, "restart")
+		names := strings.Join(changedInstances(t, r, got), ",")
+		switch {
+		case maxFailures == 0 && (got.Status.State != klausv1alpha1.FleetOperationStateFailed || names != "a"):
+			t.Errorf("maxFailures 0: status = %+v, changed %s, want the operation stopped before b", got.Status, names)
+		case maxFailures == 1 && (got.Status.State != klausv1alpha1.FleetOperationStateRunning || names != "a,b"):
+			t.Errorf("maxFailures 1: status = %+v, changed %s, want b changed despite the failure", got.Status, names)
+		}
+	}
+}
+
+func TestKlausFleetOperationReconcile_CacheLag(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	op := &klausv1alpha1.KlausFleetOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "restart", Namespace: "klaus-system", UID: "op-uid"},
+		Spec:       klausv1alpha1.KlausFleetOperationSpec{Type: klausv1alpha1.FleetOperationRestart, BatchSize: 5},
+		Status:     klausv1alpha1.KlausFleetOperationStatus{Changed: []string{"a", "b"}},
+	}
+	// The cache has not seen the change of a yet; b was never changed.
+	cached := fleetOperationReconciler(t, &now, op, fleetOperationTestInstance("a", ""), fleetOperationTestInstance("b", ""))
+	live := fleetOperationTestInstance("a", "")
+	live.Annotations = map[string]string{
+		AnnotationFleetOperation:        "op-uid",
+		resources.AnnotationRestartedAt: "2026-10-01T11:59:00Z",
+	}
+	cached.APIReader = fleetOperationReconciler(t, &now, live, fleetOperationTestInstance("b", "")).Client
+
+	_, got := reconcileFleetOperation(t, cached, "restart")
+	if names := strings.Join(changedInstances(t, cached, got), ","); names != "b" {
+		t.Errorf("changed %s, want only b changed again", names)
+	}
+	if strings.Join(got.Status.InProgress, ",") != "a,b" {
+		t.Errorf("status = %+v, want a and b in progress", got.Status)
+	}
+}
+
+func TestKlausFleetOperationReconcile_NoInstances(t *testing.T) {
+	now := time.Now()
+	op := &klausv1alpha1.KlausFleetOperation{
+		ObjectMeta: metav1.ObjectMeta{Name: "restart", Namespace: "klaus-system", UID: "op-uid"},
+		Spec:       klausv1alpha1.KlausFleetOperationSpec{Type: klausv1alpha1.FleetOperationRestart, Owner: "nobody@example.com"},
+	}
+	r := fleetOperationReconciler(t, &now, op, fleetOperationTestInstance("a", ""))
+
+	_, got := reconcileFleetOperation(t, r, "restart")
+	if got.Status.State != klausv1alpha1.FleetOperationStateSucceeded || got.Status.Total != 0 {
+		t.Errorf("status = %+v, want an operation succeeded without instances", got.Status)
+	}
+}
+
+func TestPersonalityMatches(t *testing.T) {
+	tests := []struct {
+		selector, ref string
+		want          bool
+	}{
+		{"registry.example.com/p/reviewer:v1", "registry.example.com/p/reviewer:v1", true},
+		{"registry.example.com/p/reviewer", "registry.example.com/p/reviewer:v2", true},
+		{"registry.example.com/p/reviewer", "registry.example.com/p/reviewer@sha256:abc", true},
+		{"localhost:5000/p/reviewer", "localhost:5000/p/reviewer:v1", true},
+		{"registry.example.com/p/reviewer:v1", "registry.example.com/p/reviewer:v2", false},
+		{"registry.example.com/p/reviewer", "registry.example.com/p/reviewer-next:v1", false},
+		{"registry.example.com/p/reviewer", "", false},
+	}
+	for _, tt := range tests {
+		if got := personalityMatches(tt.selector, tt.ref); got != tt.want {
+			t.Errorf("personalityMatches(%q, %q) = %v, want %v", tt.selector, tt.ref, got, tt.want)
+		}
+	}
+}
@@ -4,19 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,23 +34,29 @@ import (
 	klausoci "github.com/giantswarm/klaus-oci"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+	"github.com/giantswarm/klaus-operator/internal/oci"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
 	"github.com/giantswarm/klaus-operator/internal/resources"
 )
 
-const finalizerName = "klaus.giantswarm.io/finalizer"
+// FinalizerName is the finalizer the operator adds to every KlausInstance to
+// clean up cross-namespace child resources on deletion.
+const FinalizerName = "klaus.giantswarm.io/finalizer"
 
-// mcpServerGVK is the GroupVersionKind for the MCPServer CRD managed by muster.
-var mcpServerGVK = schema.GroupVersionKind{
-	Group:   "muster.giantswarm.io",
-	Version: "v1alpha1",
-	Kind:    "MCPServer",
-}
+// ReasonImageVolumesUnsupported is the reason of the Ready condition of
+// instances mounting MCP server artifacts in a cluster without image volume
+// support.
+const ReasonImageVolumesUnsupported = "ImageVolumesUnsupported"
 
 // OCIResolver resolves short names and :latest tags to concrete OCI references.
 type OCIResolver interface {
 	ResolvePersonalityRef(ctx context.Context, ref string) (string, error)
 	ResolveToolchainRef(ctx context.Context, ref string) (string, error)
 	ResolvePluginRef(ctx context.Context, ref string) (string, error)
+	// Resolve returns the manifest digest a tag or digest reference points
+	// to, "" when it is not known and the reference is to be left unpinned.
+	Resolve(ctx context.Context, ref string) (string, error)
 }
 
 // KlausInstanceReconciler reconciles a KlausInstance object.
@@ -59,25 +70,142 @@ type KlausInstanceReconciler struct {
 	AnthropicKeyNs     string
 	OperatorNamespace  string
 	OCIClient          OCIResolver
+	CapabilityProber   CapabilityProber
+	// unknownCapabilities caches the images without a capabilities
+	// endpoint. SetupWithManager creates it.
+	unknownCapabilities *unknownCapabilityImages
+	// ReadinessChecker evaluates spec.readinessGates; gates fail while it
+	// is nil.
+	ReadinessChecker ReadinessChecker
+	// MockImage is the mock agent image run by instances with
+	// spec.mockMode set.
+	MockImage         string
+	PlatformInspector PlatformInspector
+	// SandboxPresets maps spec.sandbox profiles to pod hardening; the
+	// default presets are used when nil.
+	SandboxPresets resources.SandboxPresets
+	// OwnerClusterRole is bound to each owner in their namespace; no
+	// RoleBinding is created when empty.
+	OwnerClusterRole string
+	// OwnerSubjectPrefix is prepended to the owner in the RoleBinding
+	// subject to match the API server's OIDC username prefix.
+	OwnerSubjectPrefix string
+	// DefaultPermission is the permission mode of instances whose
+	// spec.claude.permissionMode was not set by the admission webhook,
+	// typically because the webhook is disabled.
+	DefaultPermission klausv1alpha1.PermissionMode
+	// APIReader is an uncached reader used for pod lookups, avoiding a
+	// cluster-wide pod informer.
+	APIReader client.Reader
+	// PluginPVC, when set, serves plugins from a shared PVC per user
+	// namespace instead of OCI image volumes.
+	PluginPVC *resources.PluginPVCOptions
+	// PluginPullImage, when set, pulls plugins into an emptyDir with an init
+	// container running this image, normally the operator image, instead of
+	// mounting OCI image volumes.
+	PluginPullImage string
+	// ImageVolumesUnsupported is set when the API server rejects OCI image
+	// volumes. Instances referencing KlausMCPServers with an ociRef then
+	// fail with ReasonImageVolumesUnsupported instead of rolling out pods
+	// that never start: MCP server artifacts have no fallback like the
+	// plugin sources.
+	ImageVolumesUnsupported bool
+	// PluginRefreshInterval is how often the tags of plugins with the
+	// Periodic refresh policy are re-resolved; DefaultPluginRefreshInterval
+	// when zero.
+	PluginRefreshInterval time.Duration
+	// PersonalityResyncInterval is how often the personalities of instances
+	// with the Periodic refresh policy are re-resolved;
+	// DefaultPersonalityResyncInterval when zero.
+	PersonalityResyncInterval time.Duration
+	// PersonalityRolloutBatch limits how many instances sharing a
+	// personality reference roll forward to a new revision at once, as a
+	// count or a percentage; all roll forward at once when nil.
+	PersonalityRolloutBatch *intstr.IntOrString
+	// DefaultGateway is the MCP gateway backend of instances without
+	// spec.gateway.type; muster when empty.
+	DefaultGateway klausv1alpha1.GatewayType
+	// TelemetryCollector, when set, runs an OpenTelemetry Collector sidecar
+	// for instances with telemetry enabled but no OTLP endpoint.
+	TelemetryCollector *resources.TelemetryCollector
+	// TenantClusterRole, when set, is bound to the tenant ServiceAccount of
+	// every user namespace, which an ImpersonatingClient acts as.
+	TenantClusterRole string
+	// KubernetesAccessClusterRoles are the ClusterRoles
+	// spec.kubernetesAccess.clusterRole may name; instances naming another
+	// one fail to reconcile.
+	KubernetesAccessClusterRoles []string
+	// NamespaceScoped creates the child resources in the namespace of the
+	// instance, owned by it, instead of the owner's user namespace, which
+	// is then neither created nor managed.
+	NamespaceScoped bool
+	// GitHubApp mints the installation tokens of workspaces using
+	// spec.workspace.gitHubApp; such instances fail to reconcile when nil.
+	GitHubApp GitHubTokenMinter
+	// ArtifactVerifier, when set, verifies the signatures of the resolved
+	// personality, toolchain and plugin artifacts before they are mounted.
+	ArtifactVerifier ArtifactVerifier
+	// SoulFetcher, when set, reads the SOUL.md of the resolved personality
+	// artifact into the instance memory.
+	SoulFetcher SoulFetcher
+	// RegistryPolicy restricts the registries the resolved image,
+	// personality and plugin references may point to; every registry is
+	// allowed when nil.
+	RegistryPolicy *registrypolicy.Policy
+	// MCPServerNamespaces are the namespaces whose KlausMCPServers instances
+	// in other namespaces may reference through spec.mcpServers[].namespace.
+	MCPServerNamespaces []string
+
+	// Shards, when set, restricts the controller to the instances of the
+	// shard this replica holds and runs it on every replica rather than on
+	// the leader only.
+	Shards *ShardClaimer
+
+	// ControllerOptions tunes the work queue of the controller.
+	ControllerOptions ControllerOptions
 }
 
 // +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausinstances,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausinstances/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausinstances/finalizers,verbs=update
-// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausquotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustoolchains,verbs=get;list;watch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausskillpacks,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=muster.giantswarm.io,resources=mcpservers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=update;patch
 
 // Reconcile handles a KlausInstance event.
 func (r *KlausInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
+	// Another replica reconciles the instances of other shards. The
+	// context is cancelled once the shard is lost.
+	ctx, cancel, owned := r.Shards.ReconcileContext(ctx, req.NamespacedName)
+	defer cancel()
+	if !owned {
+		return ctrl.Result{}, nil
+	}
+
 	// Fetch the KlausInstance.
 	var instance klausv1alpha1.KlausInstance
 	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
@@ -87,6 +215,9 @@ func (r *KlausInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	// Status writes only send what this reconcile changed.
+	ctx = withStatusBase(ctx, &instance)
+
 	// Handle deletion.
 	if !instance.DeletionTimestamp.IsZero() {
 		return r.reconcileDelete(ctx, &instance)
@@ -94,51 +225,47 @@ func (r *KlausInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// Ensure finalizer. Return early so the next reconcile starts with a
 	// consistent object that includes the finalizer.
-	if !controllerutil.ContainsFinalizer(&instance, finalizerName) {
-		controllerutil.AddFinalizer(&instance, finalizerName)
+	if !controllerutil.ContainsFinalizer(&instance, FinalizerName) {
+		controllerutil.AddFinalizer(&instance, FinalizerName)
 		return ctrl.Result{}, r.Update(ctx, &instance)
 	}
 
+	// Render the child resources of dry-run instances without creating them.
+	if isDryRun(&instance) {
+		return r.reconcileDryRun(ctx, &instance)
+	}
+
 	// Update status to Pending.
 	if instance.Status.State == "" {
 		instance.Status.State = klausv1alpha1.InstanceStatePending
-		if err := r.Status().Update(ctx, &instance); err != nil {
+		if err := r.patchStatus(ctx, &instance); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	// Deep copy the instance so the informer cache is not mutated.
-	merged := instance.DeepCopy()
-
-	// Resolve OCI references (personality, plugins, toolchain image) to
-	// concrete versions so the pod spec uses pinned digests/tags.
-	if err := r.resolveOCIReferences(ctx, merged); err != nil {
-		return r.updateStatusError(ctx, &instance, "OCIResolutionError", err)
+	// Merge the spec and resolve its references.
+	resolved, err := r.resolveInstance(ctx, &instance)
+	if err != nil {
+		return r.updateStatusReasonError(ctx, &instance, err)
 	}
+	merged := resolved.merged
 
-	// Detect inline MCP server configs that will be overridden by resolved
-	// KlausMCPServer references and emit informational events.
-	for _, ref := range merged.Spec.MCPServers {
-		if _, exists := merged.Spec.Claude.MCPServers[ref.Name]; exists {
-			r.Recorder.Event(&instance, corev1.EventTypeNormal, "MCPServerOverride",
-				fmt.Sprintf("KlausMCPServer %q overrides inline MCP server config with the same name", ref.Name))
-		}
-	}
+	// Determine the target namespace.
+	namespace := r.childNamespace(merged)
 
-	// Resolve KlausMCPServer references and merge their configs and secrets
-	// into the merged spec. This must happen after personality merge so that
-	// personality-level MCP server refs are included.
-	if err := r.resolveMCPServers(ctx, merged); err != nil {
-		return r.updateStatusError(ctx, &instance, "MCPServerRefError", err)
+	// Wait for spec.dependsOn instances before starting, and expose their
+	// endpoints to the agent as MCP servers.
+	deps, waiting, err := r.reconcileDependencies(ctx, &instance, merged, namespace)
+	if err != nil {
+		return r.updateStatusError(ctx, &instance, "DependencyError", err)
 	}
-
-	// Validate the merged spec.
-	if err := resources.ValidateSpec(merged); err != nil {
-		return r.updateStatusError(ctx, &instance, "ValidationError", err)
+	if waiting {
+		logger.Info("waiting for dependencies", "instance", merged.Name)
+		return r.updateStatusWaitingForDependencies(ctx, &instance)
+	}
+	if err := resources.MergeDependencyMCPServers(merged, deps); err != nil {
+		return r.updateStatusError(ctx, &instance, "DependencyError", err)
 	}
-
-	// Determine the target namespace.
-	namespace := resources.UserNamespace(merged.Spec.Owner)
 
 	logger.Info("reconciling KlausInstance",
 		"instance", merged.Name,
@@ -146,24 +273,43 @@ func (r *KlausInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		"namespace", namespace,
 	)
 
-	// 1. Ensure namespace exists.
-	if err := r.ensureNamespace(ctx, merged, namespace); err != nil {
-		return r.updateStatusError(ctx, &instance, "NamespaceError", err)
+	// 1. Ensure namespace exists. In namespace-scoped mode the namespace of
+	// the instance is used as is.
+	if !r.NamespaceScoped {
+		if err := r.ensureNamespace(ctx, merged, namespace); err != nil {
+			return r.updateStatusError(ctx, &instance, "NamespaceError", err)
+		}
 	}
 
-	// 2. Copy the Anthropic API key Secret.
-	found, err := r.copyAPIKeySecret(ctx, merged, namespace)
-	if err != nil {
-		return r.updateStatusError(ctx, &instance, "SecretError", err)
+	// 1a. Grant the owner access to their namespace.
+	if err := r.reconcileOwnerRoleBinding(ctx, merged, namespace); err != nil {
+		return r.updateStatusError(ctx, &instance, "OwnerRoleBindingError", err)
 	}
-	if !found {
-		logger.Info("Anthropic API key secret not found, requeuing")
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+
+	// 2. Copy the Anthropic API key Secret.
+	// Bedrock and Vertex AI instances use provider credentials instead.
+	if resources.UsesAnthropicAPIKey(merged) {
+		source, err := r.copyAPIKeySecret(ctx, merged, namespace)
+		if err != nil {
+			metrics.SecretCopyFailures.WithLabelValues(metrics.SecretAnthropicAPIKey).Inc()
+			setCondition(&instance, ConditionAPIKeyReady, metav1.ConditionFalse, "SecretError", err.Error())
+			return r.updateStatusError(ctx, &instance, "SecretError", err)
+		}
+		if source == "" {
+			logger.Info("Anthropic API key secret not found, requeuing")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		r.setAPIKeyCondition(&instance, source)
+	} else if err := r.copyProviderSecret(ctx, merged, namespace); err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretProvider).Inc()
+		return r.updateStatusError(ctx, &instance, "ProviderSecretError", err)
 	}
 
-	// 3. Copy git credential Secret (if workspace.gitSecretRef configured).
+	// 3. Copy git credential Secret (if workspace.gitSecretRef configured),
+	// or mint a GitHub App installation token into it (workspace.gitHubApp).
 	gitSecretOp, err := r.copyGitSecret(ctx, merged, namespace)
 	if err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretGit).Inc()
 		return r.updateStatusError(ctx, &instance, "GitSecretError", err)
 	}
 	if gitSecretOp == controllerutil.OperationResultCreated || gitSecretOp == controllerutil.OperationResultUpdated {
@@ -171,43 +317,141 @@ func (r *KlausInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			"Git credential secret copied to user namespace")
 	}
 
-	// 4. Create/update ConfigMap.
+	// 3a. Copy the telemetry collector configuration (if the operator runs
+	// a collector sidecar for the instance).
+	collectorChecksum, err := r.copyTelemetryCollector(ctx, merged, namespace)
+	if err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretOTelCollector).Inc()
+		return r.updateStatusError(ctx, &instance, "TelemetryCollectorError", err)
+	}
+
+	// 3b. Copy the custom CA bundle and proxy credentials (if spec.network
+	// references them).
+	caBundleChecksum, err := r.copyCABundle(ctx, merged, namespace)
+	if err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretCABundle).Inc()
+		return r.updateStatusError(ctx, &instance, "CABundleError", err)
+	}
+	proxyChecksum, err := r.copyProxyCredentials(ctx, merged, namespace)
+	if err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretProxyCredentials).Inc()
+		return r.updateStatusError(ctx, &instance, "ProxyCredentialsError", err)
+	}
+
+	// 3c. Check that the Secrets of spec.extraEnv may be read by the
+	// instance pods sharing the operator's namespace.
+	if r.NamespaceScoped {
+		if err := checkExtraEnvSecrets(ctx, r.Client, merged, namespace); err != nil {
+			return r.updateStatusError(ctx, &instance, "ExtraEnvSecretError", err)
+		}
+	}
+
+	// 4. Create/update ConfigMap, including the soul of the personality
+	// artifact.
+	soul, err := r.fetchPersonalitySoul(ctx, merged)
+	if err != nil && !errors.Is(err, oci.ErrSoulUnavailable) {
+		return r.updateStatusError(ctx, &instance, "PersonalitySoulError", err)
+	}
+	setSoulDegraded(r.Recorder, &instance, err)
 	cm, err := resources.BuildConfigMap(merged, namespace)
+	if err == nil {
+		err = resources.SetPersonalitySoul(merged, cm, soul)
+	}
 	if err != nil {
 		setCondition(&instance, ConditionConfigReady, metav1.ConditionFalse, "BuildError", err.Error())
 		return r.updateStatusError(ctx, &instance, "ConfigMapError", err)
 	}
-	if err := r.reconcileConfigMap(ctx, &instance, cm); err != nil {
+	shards := resources.SplitConfigMap(merged, cm)
+	for _, shard := range shards {
+		if err := r.reconcileConfigMap(ctx, &instance, shard); err != nil {
+			setCondition(&instance, ConditionConfigReady, metav1.ConditionFalse, "ReconcileError", err.Error())
+			return r.updateStatusError(ctx, &instance, "ConfigMapError", err)
+		}
+	}
+	if err := deleteConfigShards(ctx, r.Client, merged, namespace, len(shards)); err != nil {
 		setCondition(&instance, ConditionConfigReady, metav1.ConditionFalse, "ReconcileError", err.Error())
 		return r.updateStatusError(ctx, &instance, "ConfigMapError", err)
 	}
 	setCondition(&instance, ConditionConfigReady, metav1.ConditionTrue, "Reconciled", "ConfigMap reconciled")
 
-	// 5. Create/update PVC (if workspace configured).
-	if err := r.reconcilePVC(ctx, merged, namespace); err != nil {
+	// 4a. Publish the effective spec, with secrets redacted.
+	if err := r.reconcileEffectiveConfig(ctx, &instance, merged, namespace); err != nil {
+		return r.updateStatusError(ctx, &instance, "EffectiveConfigError", err)
+	}
+
+	// 5. Create/update PVC (if workspace configured), cloning the workspace
+	// of spec.cloneFrom.
+	if err := r.reconcilePVC(ctx, &instance, merged, namespace); err != nil {
+		if errors.Is(err, errCloneSource) && apierrors.IsNotFound(err) {
+			return r.updateStatusError(ctx, &instance, ReasonCloneSourceNotFound, err)
+		}
 		return r.updateStatusError(ctx, &instance, "PVCError", err)
 	}
 
+	// 5a. Sync plugins into the shared plugins PVC (if plugins are served
+	// from it).
+	waitingForPlugins, err := r.reconcilePlugins(ctx, &instance, merged, namespace)
+	if err != nil {
+		return r.updateStatusError(ctx, &instance, "PluginSyncError", err)
+	}
+	if waitingForPlugins {
+		logger.Info("waiting for plugin sync", "instance", merged.Name)
+		return r.updateStatusWaitingForPlugins(ctx, &instance)
+	}
+
 	// 6. Ensure ServiceAccount.
 	if err := r.ensureServiceAccount(ctx, merged, namespace); err != nil {
 		return r.updateStatusError(ctx, &instance, "ServiceAccountError", err)
 	}
+	if err := r.reconcileKubernetesAccess(ctx, &instance, merged, namespace); err != nil {
+		return r.updateStatusError(ctx, &instance, "KubernetesAccessError", err)
+	}
 
-	// 7. Create/update Deployment.
-	// Resolve the container image: instance > personality > operator default.
-	resolvedImage := r.KlausImage
-	if merged.Spec.Image != "" {
-		resolvedImage = merged.Spec.Image
+	// 6a. Issue the mTLS serving and client certificates (if spec.tls set).
+	tlsRenewIn, err := r.reconcileTLS(ctx, &instance, merged, namespace)
+	if err != nil {
+		return r.updateStatusError(ctx, &instance, "TLSError", err)
 	}
-	dep := resources.BuildDeployment(merged, namespace, resolvedImage, r.GitCloneImage, cm.Data)
+
+	// 6b. Resolve the owner's API limits from their KlausQuota.
+	apiLimit, err := r.reconcileAPILimits(ctx, merged, namespace)
+	if err != nil {
+		return r.updateStatusError(ctx, &instance, "APILimitsError", err)
+	}
+
+	// 7. Create/update Deployment.
+	resolvedImage := r.instanceImage(merged)
+	// Restrict scheduling to the architectures the image is published for.
+	archs, err := r.reconcileImagePlatforms(ctx, &instance, resolvedImage)
+	if err != nil {
+		return r.updateStatusError(ctx, &instance, "UnsupportedArchitecture", err)
+	}
+	dep := r.buildDeployment(merged, namespace, deploymentInputs{
+		image:             resolvedImage,
+		configData:        cm.Data,
+		archs:             archs,
+		deps:              deps,
+		mcpArtifacts:      resolved.mcpArtifacts,
+		apiLimit:          apiLimit,
+		collectorChecksum: collectorChecksum,
+		caBundleChecksum:  caBundleChecksum,
+		proxyChecksum:     proxyChecksum,
+	})
 	depOp, err := r.reconcileDeployment(ctx, &instance, dep)
 	if err != nil {
 		setCondition(&instance, ConditionDeploymentReady, metav1.ConditionFalse, "ReconcileError", err.Error())
 		return r.updateStatusError(ctx, &instance, "DeploymentError", err)
 	}
-	if resources.NeedsGitClone(merged) && (depOp == controllerutil.OperationResultCreated || depOp == controllerutil.OperationResultUpdated) {
+	// The collector copies stay until no workload of the namespace mounts
+	// them any more. This is best-effort and does not fail the reconcile.
+	if !resources.UsesTelemetryCollector(merged, r.TelemetryCollector) {
+		if err := collectTelemetryCollector(ctx, r.Client, namespace, dep.Name); err != nil {
+			logger.Error(err, "failed to garbage-collect the telemetry collector copies", "namespace", namespace)
+		}
+	}
+	if resources.NeedsWorkspaceClone(merged) && (depOp == controllerutil.OperationResultCreated || depOp == controllerutil.OperationResultUpdated) {
 		r.Recorder.Event(&instance, corev1.EventTypeNormal, "WorkspaceClone",
-			fmt.Sprintf("Workspace git clone configured for %s", merged.Spec.Workspace.GitRepo))
+			fmt.Sprintf("Workspace git clone configured for %s", workspaceRepoList(merged)))
 	}
 
 	// Check Deployment readiness before declaring Running.
@@ -218,15 +462,21 @@ func (r *KlausInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return r.updateStatusError(ctx, &instance, "DeploymentError", err)
 	}
 
-	if merged.Spec.Stopped {
+	if resources.IsStopped(merged) {
 		setCondition(&instance, ConditionDeploymentReady, metav1.ConditionTrue, "Stopped", "Deployment scaled to zero")
+		clearPodHealth(&instance)
 	} else {
-		deploymentReady := currentDep.Status.AvailableReplicas > 0
-		if deploymentReady {
-			setCondition(&instance, ConditionDeploymentReady, metav1.ConditionTrue, "Available", "Deployment has available replicas")
-		} else {
-			setCondition(&instance, ConditionDeploymentReady, metav1.ConditionFalse, "Progressing", "Deployment is rolling out")
-		}
+		r.reconcileRolloutConditions(ctx, &instance, merged, &currentDep)
+	}
+
+	// 7a. Create/update or remove the PodDisruptionBudget.
+	if err := r.reconcilePodDisruptionBudget(ctx, &instance, merged, namespace); err != nil {
+		return r.updateStatusError(ctx, &instance, "PodDisruptionBudgetError", err)
+	}
+
+	// 7b. Create/update or remove the sandbox NetworkPolicy.
+	if err := r.reconcileNetworkPolicy(ctx, &instance, merged, namespace); err != nil {
+		return r.updateStatusError(ctx, &instance, "NetworkPolicyError", err)
 	}
 
 	// 8. Create/update Service.
@@ -235,26 +485,243 @@ func (r *KlausInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return r.updateStatusError(ctx, &instance, "ServiceError", err)
 	}
 
-	// 9. Create/update MCPServer CRD in muster namespace.
-	if err := r.reconcileMCPServer(ctx, merged, namespace); err != nil {
-		// MCPServer creation failure is not fatal -- log and continue.
-		logger.Error(err, "failed to reconcile MCPServer CRD")
-		r.Recorder.Event(&instance, corev1.EventTypeWarning, "MCPServerError", err.Error())
-		setCondition(&instance, ConditionMCPServerReady, metav1.ConditionFalse, "ReconcileError", err.Error())
-	} else {
-		setCondition(&instance, ConditionMCPServerReady, metav1.ConditionTrue, "Reconciled", "MCPServer CRD reconciled")
-	}
+	// 8a. Create/update or remove the Ingress/HTTPRoute for spec.expose.
+	r.reconcileExpose(ctx, &instance, merged, namespace)
+
+	// 9. Register the instance with its MCP gateway: a MCPServer CRD in
+	// the muster namespace when muster is installed, or annotations on the
+	// Service.
+	gatewayIn := r.registerGateway(ctx, &instance, merged, namespace)
 
 	// 10. Update status. Use the merged spec for status computation (plugin
 	// counts, mode) so the status reflects the effective configuration.
 	// When stopped, set the Stopped state and do not requeue for readiness.
-	if merged.Spec.Stopped {
-		return r.updateStatusStopped(ctx, &instance, namespace, resolvedImage)
+	// Requeue in time to renew certificates before they expire and to
+	// re-check a held personality rollout, and when the plugin or
+	// personality digests are due for their periodic refresh.
+	requeueIn := tlsRenewIn
+	if resolved.rolloutIn > 0 && (requeueIn == 0 || resolved.rolloutIn < requeueIn) {
+		requeueIn = resolved.rolloutIn
+	}
+	for _, due := range []time.Duration{resolved.refreshIn, resolved.resyncIn, gatewayIn} {
+		if due > 0 && (requeueIn == 0 || due < requeueIn) {
+			requeueIn = due
+		}
+	}
+	if resources.IsStopped(merged) {
+		return requeueBefore(requeueIn)(r.updateStatusStopped(ctx, &instance, namespace, resolvedImage))
 	}
 	if currentDep.Status.AvailableReplicas > 0 {
-		return r.updateStatusRunning(ctx, &instance, namespace, resolvedImage)
+		// Hold Ready until every readiness gate passes, and keep
+		// re-checking them once Running.
+		if !r.reconcileReadinessGates(ctx, &instance, merged, namespace) {
+			return requeueBefore(requeueIn)(r.updateStatusWaitingForReadinessGates(ctx, &instance, namespace, resolvedImage))
+		}
+		if len(merged.Spec.ReadinessGates) > 0 && (requeueIn == 0 || readinessGateRecheckInterval < requeueIn) {
+			requeueIn = readinessGateRecheckInterval
+		}
+		r.reconcileCapabilities(ctx, &instance, merged, namespace, resolvedImage)
+		return requeueBefore(requeueIn)(r.updateStatusRunning(ctx, &instance, namespace, resolvedImage))
 	}
-	return r.updateStatusPending(ctx, &instance, namespace, resolvedImage)
+	return requeueBefore(requeueIn)(r.updateStatusPending(ctx, &instance, namespace, resolvedImage))
+}
+
+// resolvedInstance is the spec of an instance merged with its personality,
+// skill packs and KlausMCPServers, with its references resolved.
+type resolvedInstance struct {
+	merged *klausv1alpha1.KlausInstance
+	// mcpArtifacts are the OCI artifacts of the referenced stdio MCP
+	// servers, for ApplyMCPServerArtifacts.
+	mcpArtifacts map[string]string
+	// refreshIn, resyncIn and rolloutIn are when the plugin digests and the
+	// personality are due to be re-resolved, and a held personality rollout
+	// re-checked; zero when not.
+	refreshIn, resyncIn, rolloutIn time.Duration
+}
+
+// resolveInstance merges the spec of instance with its personality, skill
+// packs and KlausMCPServers, resolves and verifies its OCI references and
+// validates the result. Conditions and the pinned digests are recorded in
+// the status of instance. Errors are *reasonError.
+func (r *KlausInstanceReconciler) resolveInstance(ctx context.Context, instance *klausv1alpha1.KlausInstance) (*resolvedInstance, error) {
+	// Deep copy the instance so the informer cache is not mutated.
+	merged := instance.DeepCopy()
+	applyDefaultPermission(merged, r.DefaultPermission)
+
+	// Suspend the instance once its recorded cost reached its budget.
+	r.reconcileBudget(instance, merged)
+
+	// Use the image of the referenced KlausToolchain like spec.image.
+	if err := r.resolveToolchainRef(ctx, merged); err != nil {
+		return nil, err
+	}
+
+	// Merge the inline personality before resolution so its image and
+	// plugins are pinned like the instance's own.
+	resources.MergeInlinePersonality(merged)
+
+	// Add the skills, agent files and commands of the referenced
+	// KlausSkillPacks, including those of the inline personality.
+	if err := r.resolveSkillPacks(ctx, merged); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, &reasonError{reason: ReasonSkillPackNotFound, err: err}
+		}
+		return nil, &reasonError{reason: "SkillPackError", err: err}
+	}
+
+	// Keep the pinned personality revision of instances rolled forward
+	// manually.
+	pinPersonalityRevision(instance, merged)
+
+	// Resolve OCI references (personality, plugins, toolchain image) to
+	// concrete versions so the pod spec uses pinned digests/tags.
+	if err := r.resolveOCIReferences(ctx, merged); err != nil {
+		return nil, &reasonError{reason: "OCIResolutionError", err: err}
+	}
+
+	// Pin plugin tags to the digests recorded in status.resolvedPlugins,
+	// re-resolving them per spec.pluginRefreshPolicy.
+	refreshIn, err := r.pinPluginDigests(ctx, instance, merged)
+	if err != nil {
+		return nil, &reasonError{reason: "OCIResolutionError", err: err}
+	}
+
+	// Pin the personality to the digest recorded in
+	// status.resolvedPersonality, re-resolving it per
+	// spec.personalityRefreshPolicy.
+	resyncIn, err := r.pinPersonalityDigest(ctx, instance, merged)
+	if err != nil {
+		return nil, &reasonError{reason: "OCIResolutionError", err: err}
+	}
+
+	// Check the resolved references against the registry policy: the
+	// admission webhook only sees the unresolved ones, and instances
+	// created before the policy are not re-admitted.
+	if err := r.checkRegistryPolicy(merged); err != nil {
+		return nil, &reasonError{reason: ReasonRegistryPolicyViolation, err: err}
+	}
+
+	// Refuse to mount artifacts whose signature cannot be verified, and pin
+	// the verified ones to their digests.
+	verified, err := r.verifyOCIReferences(ctx, merged)
+	setArtifactVerificationCondition(instance, verified, err)
+	if err != nil {
+		return nil, &reasonError{reason: ReasonArtifactVerificationFailed, err: err}
+	}
+
+	// Stage the rollout of new personality revisions across the instances
+	// sharing the personality reference.
+	rolloutIn, err := r.stagePersonalityRollout(ctx, instance, merged)
+	if err != nil {
+		return nil, &reasonError{reason: "PersonalityRolloutError", err: err}
+	}
+	recordPersonalityRevision(instance, merged)
+
+	// Detect inline MCP server configs that will be overridden by resolved
+	// KlausMCPServer references and emit informational events.
+	for _, ref := range merged.Spec.MCPServers {
+		if _, exists := merged.Spec.Claude.MCPServers[ref.Name]; exists {
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "MCPServerOverride",
+				fmt.Sprintf("KlausMCPServer %q overrides inline MCP server config with the same name", ref.Name))
+		}
+	}
+
+	// Resolve KlausMCPServer references and merge their configs and secrets
+	// into the merged spec. This must happen after personality merge so that
+	// personality-level MCP server refs are included.
+	mcpArtifacts, err := r.resolveMCPServers(ctx, merged)
+	if err != nil {
+		return nil, &reasonError{reason: "MCPServerRefError", err: err}
+	}
+	if len(mcpArtifacts) > 0 && r.ImageVolumesUnsupported {
+		return nil, &reasonError{reason: ReasonImageVolumesUnsupported, err: fmt.Errorf(
+			"MCP servers %s mount an OCI artifact, which needs image volume support in the cluster",
+			strings.Join(slices.Sorted(maps.Keys(mcpArtifacts)), ", "))}
+	}
+
+	// Pin the MCP server artifacts to digests and verify their signatures
+	// like plugins.
+	if err := r.pinMCPServerDigests(ctx, mcpArtifacts); err != nil {
+		return nil, &reasonError{reason: "OCIResolutionError", err: err}
+	}
+	mcpVerified, err := r.verifyMCPServerArtifacts(ctx, mcpArtifacts)
+	if mcpVerified > 0 || err != nil {
+		setArtifactVerificationCondition(instance, verified+mcpVerified, err)
+	}
+	if err != nil {
+		return nil, &reasonError{reason: ReasonArtifactVerificationFailed, err: err}
+	}
+
+	// Validate the merged spec.
+	if err := resources.ValidateInstanceSpec(merged); err != nil {
+		return nil, &reasonError{reason: "ValidationError", err: err}
+	}
+
+	return &resolvedInstance{
+		merged:       merged,
+		mcpArtifacts: mcpArtifacts,
+		refreshIn:    refreshIn,
+		resyncIn:     resyncIn,
+		rolloutIn:    rolloutIn,
+	}, nil
+
+}
+
+// requeueBefore returns a function capping the RequeueAfter of a reconcile
+// result at d. A zero d leaves the result unchanged.
+func requeueBefore(d time.Duration) func(ctrl.Result, error) (ctrl.Result, error) {
+	return func(result ctrl.Result, err error) (ctrl.Result, error) {
+		if d > 0 && (result.RequeueAfter == 0 || d < result.RequeueAfter) {
+			result.RequeueAfter = d
+		}
+		return result, err
+	}
+}
+
+// deploymentInputs are the results of the reconcile steps the Deployment of
+// an instance is built from.
+type deploymentInputs struct {
+	image             string
+	configData        map[string]string
+	archs             []string
+	deps              []resources.Dependency
+	mcpArtifacts      map[string]string
+	apiLimit          *klausv1alpha1.APIRateLimit
+	collectorChecksum string
+	caBundleChecksum  string
+	proxyChecksum     string
+}
+
+// buildDeployment builds the Deployment of an instance from its merged spec.
+func (r *KlausInstanceReconciler) buildDeployment(merged *klausv1alpha1.KlausInstance, namespace string, in deploymentInputs) *appsv1.Deployment {
+	dep := resources.BuildDeployment(merged, namespace, in.image, r.GitCloneImage, in.configData)
+	resources.ApplyArchitectureAffinity(&dep.Spec.Template.Spec, in.archs)
+	resources.ApplySandbox(&dep.Spec.Template.Spec, r.sandboxPreset(merged))
+	resources.ApplyDependencyEnv(&dep.Spec.Template.Spec, in.deps)
+	resources.ApplyMCPServerArtifacts(&dep.Spec.Template.Spec, in.mcpArtifacts)
+	resources.ApplyAPIRateLimit(&dep.Spec.Template.Spec, merged, in.apiLimit)
+	resources.ApplyTelemetryCollector(&dep.Spec.Template, merged, r.TelemetryCollector, in.collectorChecksum)
+	resources.ApplyCABundleChecksum(&dep.Spec.Template, in.caBundleChecksum)
+	resources.ApplyProxyCredentialsChecksum(&dep.Spec.Template, in.proxyChecksum)
+	switch {
+	case r.PluginPVC != nil:
+		resources.ApplyPluginPVC(&dep.Spec.Template.Spec, merged)
+	case r.PluginPullImage != "":
+		resources.ApplyPluginInitContainer(&dep.Spec.Template.Spec, merged, r.PluginPullImage)
+	}
+	return dep
+}
+
+// instanceImage resolves the container image of the instance: the mock agent
+// image in mock mode, otherwise instance > personality > operator default.
+func (r *KlausInstanceReconciler) instanceImage(instance *klausv1alpha1.KlausInstance) string {
+	if instance.Spec.MockMode {
+		return r.MockImage
+	}
+	if instance.Spec.Image != "" {
+		return instance.Spec.Image
+	}
+	return r.KlausImage
 }
 
 func (r *KlausInstanceReconciler) ensureNamespace(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
@@ -269,29 +736,33 @@ func (r *KlausInstanceReconciler) ensureNamespace(ctx context.Context, instance
 		}
 		return nil
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	return r.reconcileTenantAccess(ctx, instance, namespace)
 }
 
-// copyAPIKeySecret copies the shared Anthropic API key Secret into the
-// instance namespace. Returns (true, nil) on success, (false, nil) if the
-// source secret does not exist yet, or (false, err) on failure.
-func (r *KlausInstanceReconciler) copyAPIKeySecret(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (bool, error) {
-	// Read the shared org secret.
-	srcSecret := &corev1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      r.AnthropicKeySecret,
-		Namespace: r.AnthropicKeyNs,
-	}, srcSecret)
+// copyAPIKeySecret copies the Anthropic API key Secret into the instance
+// namespace. A per-owner Secret (see resources.OwnerAPIKeySecretName) takes
+// precedence over the shared org Secret so teams can meter spend and revoke
+// individual users. A per-owner Secret without an api-key is an error rather
+// than a reason to fall back to the shared key. Returns the name of the
+// copied Secret, "" if no source secret exists yet, or an error.
+func (r *KlausInstanceReconciler) copyAPIKeySecret(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (string, error) {
+	srcSecret, err := r.getAPIKeySourceSecret(ctx, instance.Spec.Owner)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return false, nil
-		}
-		return false, fmt.Errorf("fetching Anthropic API key secret: %w", err)
+		return "", err
+	}
+	if srcSecret == nil {
+		return "", nil
 	}
 
-	apiKey, ok := srcSecret.Data["api-key"]
-	if !ok {
-		return false, fmt.Errorf("anthropic API key secret missing 'api-key' field")
+	apiKey := srcSecret.Data["api-key"]
+	if len(apiKey) == 0 {
+		if srcSecret.Name != r.AnthropicKeySecret {
+			return "", fmt.Errorf("per-owner Anthropic API key secret %s has no 'api-key'; the shared key is not used while it exists", srcSecret.Name)
+		}
+		return "", fmt.Errorf("anthropic API key secret %s missing 'api-key' field", srcSecret.Name)
 	}
 
 	// Create or update the secret in the instance namespace.
@@ -300,12 +771,51 @@ func (r *KlausInstanceReconciler) copyAPIKeySecret(ctx context.Context, instance
 	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
 		existing.Data = desired.Data
 		existing.Labels = desired.Labels
-		return nil
+		return r.setInstanceOwner(instance, existing)
 	})
 	if err != nil {
-		return false, err
+		return "", err
+	}
+	return srcSecret.Name, nil
+}
+
+// getAPIKeySourceSecret returns the per-owner API key Secret when it exists,
+// falling back to the shared org Secret. Returns (nil, nil) when neither
+// exists.
+func (r *KlausInstanceReconciler) getAPIKeySourceSecret(ctx context.Context, owner string) (*corev1.Secret, error) {
+	for _, name := range []string{
+		resources.OwnerAPIKeySecretName(r.AnthropicKeySecret, owner),
+		r.AnthropicKeySecret,
+	} {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: r.AnthropicKeyNs}, secret)
+		if err == nil {
+			return secret, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("fetching Anthropic API key secret %s: %w", name, err)
+		}
 	}
-	return true, nil
+	return nil, nil
+}
+
+// setAPIKeyCondition records in the APIKeyReady condition whether the
+// per-owner or the shared API key Secret was copied, naming the per-owner
+// Secret the operator looks for. Falling back to the shared key after the
+// per-owner Secret was used emits a warning event.
+func (r *KlausInstanceReconciler) setAPIKeyCondition(instance *klausv1alpha1.KlausInstance, source string) {
+	ownerSecret := resources.OwnerAPIKeySecretName(r.AnthropicKeySecret, instance.Spec.Owner)
+	if source == ownerSecret {
+		setCondition(instance, ConditionAPIKeyReady, metav1.ConditionTrue, "OwnerKey",
+			fmt.Sprintf("Using the per-owner API key Secret %s/%s", r.AnthropicKeyNs, source))
+		return
+	}
+	message := fmt.Sprintf("Using the shared API key Secret %s/%s: there is no per-owner Secret %s/%s",
+		r.AnthropicKeyNs, source, r.AnthropicKeyNs, ownerSecret)
+	if prev := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionAPIKeyReady); prev != nil && prev.Reason == "OwnerKey" {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "SharedAPIKey", message)
+	}
+	setCondition(instance, ConditionAPIKeyReady, metav1.ConditionTrue, "SharedKey", message)
 }
 
 func (r *KlausInstanceReconciler) reconcileConfigMap(ctx context.Context, instance *klausv1alpha1.KlausInstance, desired *corev1.ConfigMap) error {
@@ -313,7 +823,7 @@ func (r *KlausInstanceReconciler) reconcileConfigMap(ctx context.Context, instan
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
 		existing.Data = desired.Data
 		existing.Labels = desired.Labels
-		return nil
+		return r.setInstanceOwner(instance, existing)
 	})
 	if op == controllerutil.OperationResultCreated {
 		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingConfigMap", "Created ConfigMap "+desired.Name)
@@ -321,8 +831,11 @@ func (r *KlausInstanceReconciler) reconcileConfigMap(ctx context.Context, instan
 	return err
 }
 
-func (r *KlausInstanceReconciler) reconcilePVC(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
-	pvc := resources.BuildPVC(instance, namespace)
+// reconcilePVC creates the workspace PVC, as a clone of the workspace of
+// spec.cloneFrom if requested, and expands it when spec.workspace.size
+// grows. Conditions are set on instance, the PVC is built from merged.
+func (r *KlausInstanceReconciler) reconcilePVC(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) error {
+	pvc := resources.BuildPVC(merged, namespace)
 	if pvc == nil {
 		return nil
 	}
@@ -330,18 +843,51 @@ func (r *KlausInstanceReconciler) reconcilePVC(ctx context.Context, instance *kl
 	existing := &corev1.PersistentVolumeClaim{}
 	err := r.Get(ctx, types.NamespacedName{Name: pvc.Name, Namespace: namespace}, existing)
 	if apierrors.IsNotFound(err) {
+		if err := r.setInstanceOwner(merged, pvc); err != nil {
+			return err
+		}
+		if resources.ClonesWorkspace(merged) {
+			if err := r.cloneWorkspace(ctx, merged, pvc); err != nil {
+				return err
+			}
+			r.Recorder.Event(instance, corev1.EventTypeNormal, "CloningPVC",
+				fmt.Sprintf("Creating PVC %s as a clone of %s", pvc.Name, pvc.Spec.DataSource.Name))
+			return r.Create(ctx, pvc)
+		}
 		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingPVC", "Creating PVC "+pvc.Name)
 		return r.Create(ctx, pvc)
 	}
-	// PVCs cannot be updated (immutable spec), so we just return.
-	return err
+	if err != nil {
+		return err
+	}
+	// The PVC spec is immutable apart from the requested storage, so the
+	// update strategy follows the access modes of the existing PVC, e.g.
+	// when a personality changed them.
+	if !slices.Equal(existing.Spec.AccessModes, pvc.Spec.AccessModes) {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "AccessModesImmutable",
+			fmt.Sprintf("PVC %s keeps its access modes %v", existing.Name, existing.Spec.AccessModes))
+		workspace := merged.Spec.Workspace.DeepCopy()
+		workspace.AccessModes = existing.Spec.AccessModes
+		merged.Spec.Workspace = workspace
+	}
+	return r.resizePVC(ctx, instance, existing, pvc)
 }
 
 func (r *KlausInstanceReconciler) ensureServiceAccount(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
 	existing := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: namespace}}
 	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
 		existing.Labels = resources.InstanceLabels(instance)
-		return nil
+		// Workload identity annotations are managed by the operator; drop
+		// them when the provider no longer needs them.
+		desired := resources.ProviderServiceAccountAnnotations(instance)
+		for _, key := range []string{resources.AnnotationIRSARoleARN, resources.AnnotationGKEServiceAccount} {
+			if value, ok := desired[key]; ok {
+				metav1.SetMetaDataAnnotation(&existing.ObjectMeta, key, value)
+			} else {
+				delete(existing.Annotations, key)
+			}
+		}
+		return r.setInstanceOwner(instance, existing)
 	})
 	return err
 }
@@ -351,7 +897,7 @@ func (r *KlausInstanceReconciler) reconcileDeployment(ctx context.Context, insta
 	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
 		existing.Spec = desired.Spec
 		existing.Labels = desired.Labels
-		return nil
+		return r.setInstanceOwner(instance, existing)
 	})
 	if op == controllerutil.OperationResultCreated {
 		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingDeployment", "Created Deployment "+desired.Name)
@@ -367,7 +913,7 @@ func (r *KlausInstanceReconciler) reconcileService(ctx context.Context, instance
 		existing.Spec = desired.Spec
 		existing.Spec.ClusterIP = clusterIP
 		existing.Labels = desired.Labels
-		return nil
+		return r.setInstanceOwner(instance, existing)
 	})
 	if op == controllerutil.OperationResultCreated {
 		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingService", "Created Service "+desired.Name)
@@ -375,41 +921,17 @@ func (r *KlausInstanceReconciler) reconcileService(ctx context.Context, instance
 	return err
 }
 
-func (r *KlausInstanceReconciler) reconcileMCPServer(ctx context.Context, instance *klausv1alpha1.KlausInstance, instanceNamespace string) error {
-	desired := resources.BuildMCPServerCRD(instance, instanceNamespace)
-
-	musterNamespace := resources.MusterNamespace(instance)
-	existing := &unstructured.Unstructured{}
-	existing.SetGroupVersionKind(mcpServerGVK)
-	existing.SetName("klaus-" + instance.Name)
-	existing.SetNamespace(musterNamespace)
-
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      "klaus-" + instance.Name,
-		Namespace: musterNamespace,
-	}, existing)
-	if apierrors.IsNotFound(err) {
-		return r.Create(ctx, desired)
-	}
-	if err != nil {
-		return err
-	}
-
-	// Update the spec and labels using typed accessors to avoid panics.
-	existing.Object["spec"] = desired.Object["spec"]
-	existing.SetLabels(desired.GetLabels())
-	return r.Update(ctx, existing)
-}
-
 func (r *KlausInstanceReconciler) reconcileDelete(ctx context.Context, instance *klausv1alpha1.KlausInstance) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("reconciling deletion", "instance", instance.Name)
 
-	namespace := resources.UserNamespace(instance.Spec.Owner)
+	namespace := r.childNamespace(instance)
 
-	// Clean up in-namespace resources. These are not garbage-collected via
-	// owner references because they live in a different namespace than the
-	// KlausInstance.
+	// Clean up in-namespace resources. In user namespaces these are not
+	// garbage-collected via owner references because they live in a
+	// different namespace than the KlausInstance. In namespace-scoped mode
+	// they are, but are still deleted here so the finalizer is only removed
+	// once they are gone.
 	inNamespaceResources := []client.Object{
 		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
 			Name: instance.Name, Namespace: namespace,
@@ -420,6 +942,9 @@ func (r *KlausInstanceReconciler) reconcileDelete(ctx context.Context, instance
 		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
 			Name: resources.ConfigMapName(instance), Namespace: namespace,
 		}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: resources.EffectiveConfigName(instance), Namespace: namespace,
+		}},
 		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
 			Name: resources.SecretName(instance), Namespace: namespace,
 		}},
@@ -428,6 +953,16 @@ func (r *KlausInstanceReconciler) reconcileDelete(ctx context.Context, instance
 		}},
 	}
 
+	// The RoleBinding only exists if a Kubernetes access ClusterRole was
+	// configured.
+	if resources.BuildKubernetesAccessRoleBinding(instance, namespace) != nil {
+		inNamespaceResources = append(inNamespaceResources,
+			&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{
+				Name: resources.KubernetesAccessBindingName(instance), Namespace: namespace,
+			}},
+		)
+	}
+
 	// PVC only exists if workspace was configured.
 	if instance.Spec.Workspace != nil {
 		inNamespaceResources = append(inNamespaceResources, &corev1.PersistentVolumeClaim{
@@ -437,6 +972,24 @@ func (r *KlausInstanceReconciler) reconcileDelete(ctx context.Context, instance
 		})
 	}
 
+	// PodDisruptionBudget only exists if a disruption budget was configured.
+	if instance.Spec.Scheduling != nil && instance.Spec.Scheduling.DisruptionBudget != nil {
+		inNamespaceResources = append(inNamespaceResources, &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resources.PodDisruptionBudgetName(instance), Namespace: namespace,
+			},
+		})
+	}
+
+	// NetworkPolicy only exists if a sandbox profile was configured.
+	if instance.Spec.Sandbox != "" && instance.Spec.Sandbox != klausv1alpha1.SandboxNone {
+		inNamespaceResources = append(inNamespaceResources, &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resources.NetworkPolicyName(instance), Namespace: namespace,
+			},
+		})
+	}
+
 	// Git credential secret only exists if gitSecretRef was configured.
 	if resources.NeedsGitSecret(instance) {
 		inNamespaceResources = append(inNamespaceResources, &corev1.Secret{
@@ -446,8 +999,52 @@ func (r *KlausInstanceReconciler) reconcileDelete(ctx context.Context, instance
 		})
 	}
 
+	// CA bundle copy only exists if spec.network references a bundle.
+	if resources.HasCABundle(instance) {
+		inNamespaceResources = append(inNamespaceResources, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resources.CABundleConfigMapName(instance), Namespace: namespace,
+			},
+		})
+	}
+
+	if resources.HasProxyCredentials(instance) {
+		inNamespaceResources = append(inNamespaceResources, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resources.ProxyCredentialsSecretName(instance), Namespace: namespace,
+			},
+		})
+	}
+
+	// Provider credentials secret only exists if a credentialsSecretRef was configured.
+	if resources.ProviderCredentialsRef(instance) != nil {
+		inNamespaceResources = append(inNamespaceResources, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: resources.ProviderSecretName(instance), Namespace: namespace,
+			},
+		})
+	}
+
 	var errs []error
 
+	// Clean up the Ingress/HTTPRoute for spec.expose, including one left
+	// behind by an earlier spec.
+	if instance.Spec.Expose != nil || apimeta.FindStatusCondition(instance.Status.Conditions, ConditionExposed) != nil {
+		if err := r.deleteIngress(ctx, instance, namespace); err != nil {
+			errs = append(errs, err)
+		}
+		if err := r.deleteHTTPRoute(ctx, instance, namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Clean up the mTLS certificates and their Secrets.
+	if resources.TLSEnabled(instance) || apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady) != nil {
+		if err := r.deleteTLSResources(ctx, instance, namespace); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	// Clean up stale MCP secrets, respecting multi-instance ownership. This
 	// only removes secrets no longer referenced by any non-deleting instance
 	// for the same owner.
@@ -465,27 +1062,48 @@ func (r *KlausInstanceReconciler) reconcileDelete(ctx context.Context, instance
 			errs = append(errs, err)
 		}
 	}
+	if err := deleteConfigShards(ctx, r.Client, instance, namespace, 1); err != nil {
+		logger.Error(err, "failed to delete configuration ConfigMaps")
+		errs = append(errs, err)
+	}
 
-	// Clean up cross-namespace MCPServer CRD.
-	musterNamespace := resources.MusterNamespace(instance)
-
-	mcpServer := &unstructured.Unstructured{}
-	mcpServer.SetGroupVersionKind(mcpServerGVK)
-	mcpServer.SetName("klaus-" + instance.Name)
-	mcpServer.SetNamespace(musterNamespace)
-
-	if err := r.Delete(ctx, mcpServer); err != nil && !apierrors.IsNotFound(err) {
-		logger.Error(err, "failed to delete MCPServer CRD")
+	if err := collectTelemetryCollector(ctx, r.Client, namespace, instance.Name); err != nil {
+		logger.Error(err, "failed to garbage-collect the telemetry collector copies")
 		errs = append(errs, err)
 	}
 
+	// Clean up the gateway registration, like the cross-namespace muster
+	// MCPServer. Failures are retried with a backoff instead of failing the
+	// deletion.
+	gatewayErr := r.deregisterGateways(ctx, instance, namespace)
+	if gatewayErr != nil {
+		logger.Error(gatewayErr, "failed to deregister from the MCP gateway")
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "MCPServerError", gatewayErr.Error())
+		setCondition(instance, ConditionMCPServerReady, metav1.ConditionFalse, "CleanupError", gatewayErr.Error())
+	}
+
 	// Only remove the finalizer once all child resources are confirmed deleted.
 	if len(errs) > 0 {
 		return ctrl.Result{}, fmt.Errorf("cleaning up child resources: %w", errors.Join(errs...))
 	}
+	if gatewayErr != nil {
+		if err := r.patchStatus(ctx, instance); err != nil {
+			logger.Error(err, "failed to update status")
+		}
+		return ctrl.Result{RequeueAfter: gatewayBackoff(instance, time.Now())}, nil
+	}
+
+	// Remove the user namespace with the last instance of the owner. This is
+	// best-effort and does not block the deletion.
+	if deleted, err := r.collectUserNamespace(ctx, instance, namespace); err != nil {
+		logger.Error(err, "failed to garbage-collect the user namespace", "namespace", namespace)
+	} else if deleted {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "NamespaceDeleted",
+			fmt.Sprintf("Deleted user namespace %s, no longer used by any instance or job", namespace))
+	}
 
 	// Remove finalizer.
-	controllerutil.RemoveFinalizer(instance, finalizerName)
+	controllerutil.RemoveFinalizer(instance, FinalizerName)
 	if err := r.Update(ctx, instance); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -494,16 +1112,52 @@ func (r *KlausInstanceReconciler) reconcileDelete(ctx context.Context, instance
 }
 
 func (r *KlausInstanceReconciler) updateStatusError(ctx context.Context, instance *klausv1alpha1.KlausInstance, reason string, err error) (ctrl.Result, error) {
+	reason = offlineReason(reason, err)
+	metrics.ReconcileErrors.WithLabelValues(metrics.ControllerKlausInstance, reason).Inc()
 	instance.Status.State = klausv1alpha1.InstanceStateError
 	instance.Status.ObservedGeneration = instance.Generation
 	setCondition(instance, ConditionReady, metav1.ConditionFalse, reason, err.Error())
-	_ = r.Status().Update(ctx, instance)
+	_ = r.patchStatus(ctx, instance)
 	r.Recorder.Event(instance, corev1.EventTypeWarning, reason, err.Error())
 	return ctrl.Result{}, err
 }
 
+// reasonError is an error of a reconcile step with the reason of the Ready
+// condition it sets.
+type reasonError struct {
+	reason string
+	err    error
+}
+
+func (e *reasonError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reasonError) Unwrap() error {
+	return e.err
+}
+
+// Reason returns the reason of the Ready condition set for the error.
+func (e *reasonError) Reason() string {
+	return e.reason
+}
+
+// updateStatusReasonError is updateStatusError for a *reasonError, using
+// ReconcileError as the reason of other errors.
+func (r *KlausInstanceReconciler) updateStatusReasonError(ctx context.Context, instance *klausv1alpha1.KlausInstance, err error) (ctrl.Result, error) {
+	var rerr *reasonError
+	if errors.As(err, &rerr) {
+		return r.updateStatusError(ctx, instance, rerr.reason, rerr.err)
+	}
+	return r.updateStatusError(ctx, instance, "ReconcileError", err)
+}
+
 func (r *KlausInstanceReconciler) populateCommonStatus(instance *klausv1alpha1.KlausInstance, namespace, resolvedImage string) {
-	instance.Status.Endpoint = resources.ServiceEndpoint(instance, namespace)
+	instance.Status.ServiceEndpoint = resources.ServiceEndpoint(instance, namespace)
+	instance.Status.Endpoint = instance.Status.ServiceEndpoint
+	if external := resources.ExternalEndpoint(instance); external != "" {
+		instance.Status.Endpoint = external
+	}
 	instance.Status.PluginCount = len(instance.Spec.Plugins)
 	instance.Status.MCPServerCount = len(instance.Spec.MCPServers) + len(instance.Spec.Claude.MCPServers)
 	instance.Status.ObservedGeneration = instance.Generation
@@ -514,8 +1168,13 @@ func (r *KlausInstanceReconciler) populateCommonStatus(instance *klausv1alpha1.K
 		instance.Status.Mode = klausv1alpha1.InstanceModeAgent
 	}
 
+	instance.Status.Mock = instance.Spec.MockMode
+
 	// Record the OCI personality reference in status.
 	instance.Status.Personality = instance.Spec.Personality
+	if instance.Spec.InlinePersonality != nil {
+		instance.Status.Personality = resources.InlinePersonalityStatus
+	}
 
 	// Report the resolved image when it differs from the operator default.
 	if resolvedImage != r.KlausImage {
@@ -530,7 +1189,7 @@ func (r *KlausInstanceReconciler) updateStatusRunning(ctx context.Context, insta
 	r.populateCommonStatus(instance, namespace, resolvedImage)
 	setCondition(instance, ConditionReady, metav1.ConditionTrue, "Reconciled", "All resources reconciled successfully")
 
-	if err := r.Status().Update(ctx, instance); err != nil {
+	if err := r.patchStatus(ctx, instance); err != nil {
 		return ctrl.Result{}, err
 	}
 	return ctrl.Result{}, nil
@@ -539,9 +1198,13 @@ func (r *KlausInstanceReconciler) updateStatusRunning(ctx context.Context, insta
 func (r *KlausInstanceReconciler) updateStatusStopped(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace, resolvedImage string) (ctrl.Result, error) {
 	instance.Status.State = klausv1alpha1.InstanceStateStopped
 	r.populateCommonStatus(instance, namespace, resolvedImage)
-	setCondition(instance, ConditionReady, metav1.ConditionTrue, "Stopped", "Instance is stopped")
+	if budget := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionBudgetExceeded); budget != nil && budget.Status == metav1.ConditionTrue {
+		setCondition(instance, ConditionReady, metav1.ConditionFalse, "BudgetExceeded", budget.Message)
+	} else {
+		setCondition(instance, ConditionReady, metav1.ConditionTrue, "Stopped", "Instance is stopped")
+	}
 
-	if err := r.Status().Update(ctx, instance); err != nil {
+	if err := r.patchStatus(ctx, instance); err != nil {
 		return ctrl.Result{}, err
 	}
 	// No requeue -- the instance is intentionally stopped.
@@ -551,9 +1214,13 @@ func (r *KlausInstanceReconciler) updateStatusStopped(ctx context.Context, insta
 func (r *KlausInstanceReconciler) updateStatusPending(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace, resolvedImage string) (ctrl.Result, error) {
 	instance.Status.State = klausv1alpha1.InstanceStatePending
 	r.populateCommonStatus(instance, namespace, resolvedImage)
-	setCondition(instance, ConditionReady, metav1.ConditionFalse, "Progressing", "Waiting for Deployment to become available")
+	if instance.Status.FailureReason != "" {
+		setCondition(instance, ConditionReady, metav1.ConditionFalse, instance.Status.FailureReason, instance.Status.FailureMessage)
+	} else {
+		setCondition(instance, ConditionReady, metav1.ConditionFalse, "Progressing", "Waiting for Deployment to become available")
+	}
 
-	if err := r.Status().Update(ctx, instance); err != nil {
+	if err := r.patchStatus(ctx, instance); err != nil {
 		return ctrl.Result{}, err
 	}
 	// Requeue to check deployment readiness again.
@@ -571,27 +1238,30 @@ func (r *KlausInstanceReconciler) updateStatusPending(ctx context.Context, insta
 //   - Detects secret name collisions across MCP servers that would cause
 //     conflicts in the user namespace.
 //   - Cleans up stale MCP secrets no longer referenced by any instance.
-func (r *KlausInstanceReconciler) resolveMCPServers(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+//   - Points the commands of servers with an OCI artifact into its mount,
+//     returning the artifacts for ApplyMCPServerArtifacts.
+func (r *KlausInstanceReconciler) resolveMCPServers(ctx context.Context, instance *klausv1alpha1.KlausInstance) (map[string]string, error) {
 	if len(instance.Spec.MCPServers) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	resolved := &resources.ResolvedMCPConfig{
 		Servers: make(map[string]runtime.RawExtension, len(instance.Spec.MCPServers)),
 	}
 
-	namespace := resources.UserNamespace(instance.Spec.Owner)
+	namespace := r.childNamespace(instance)
 
 	// Track which MCP servers own each secret name to detect collisions.
 	secretOwners := make(map[string]string)
 
 	for _, ref := range instance.Spec.MCPServers {
+		key := mcpServerRefKey(instance.Namespace, ref)
+		if err := checkMCPServerNamespace(r.MCPServerNamespaces, instance.Namespace, key); err != nil {
+			return nil, err
+		}
 		var server klausv1alpha1.KlausMCPServer
-		if err := r.Get(ctx, types.NamespacedName{
-			Name:      ref.Name,
-			Namespace: instance.Namespace,
-		}, &server); err != nil {
-			return fmt.Errorf("resolving MCP server %q: %w", ref.Name, err)
+		if err := r.Get(ctx, key, &server); err != nil {
+			return nil, fmt.Errorf("resolving MCP server %q: %w", ref.Name, err)
 		}
 
 		// Check if the MCP server is ready. If the controller has explicitly
@@ -601,13 +1271,27 @@ func (r *KlausInstanceReconciler) resolveMCPServers(ctx context.Context, instanc
 		// conditions) are allowed through.
 		readyCond := apimeta.FindStatusCondition(server.Status.Conditions, MCPServerConditionReady)
 		if readyCond != nil && readyCond.Status == metav1.ConditionFalse {
-			return fmt.Errorf("MCP server %q is not ready: %s", ref.Name, readyCond.Message)
+			return nil, fmt.Errorf("MCP server %q is not ready: %s", ref.Name, readyCond.Message)
+		}
+
+		// Serve stdio servers with an OCI artifact from its mount, subject to
+		// the registry policy like plugins.
+		spec := server.Spec
+		if spec.OCIRef != "" {
+			if err := r.RegistryPolicy.Check(spec.OCIRef, klausoci.DefaultPluginRegistry); err != nil {
+				return nil, fmt.Errorf("MCP server %q: %w", ref.Name, err)
+			}
+			if resolved.Artifacts == nil {
+				resolved.Artifacts = map[string]string{}
+			}
+			resolved.Artifacts[ref.Name] = spec.OCIRef
+			spec.Command = resources.MCPServerArtifactCommand(ref.Name, spec.Command)
 		}
 
 		// Convert the server spec to a RawExtension for .mcp.json assembly.
-		rawConfig, err := resources.ServerConfigToRawExtension(&server.Spec)
+		rawConfig, err := resources.ReferencedServerConfigToRawExtension(&spec, ref)
 		if err != nil {
-			return fmt.Errorf("marshaling MCP server %q config: %w", ref.Name, err)
+			return nil, fmt.Errorf("marshaling MCP server %q config: %w", ref.Name, err)
 		}
 		resolved.Servers[ref.Name] = rawConfig
 
@@ -617,7 +1301,7 @@ func (r *KlausInstanceReconciler) resolveMCPServers(ctx context.Context, instanc
 		resolved.Secrets = append(resolved.Secrets, server.Spec.SecretRefs...)
 		for _, secretRef := range server.Spec.SecretRefs {
 			if prevOwner, exists := secretOwners[secretRef.SecretName]; exists && prevOwner != ref.Name {
-				return fmt.Errorf(
+				return nil, fmt.Errorf(
 					"secret name collision: secret %q is referenced by both MCP servers %q and %q; "+
 						"use uniquely-named secrets to avoid conflicts in the user namespace",
 					secretRef.SecretName, prevOwner, ref.Name,
@@ -628,8 +1312,8 @@ func (r *KlausInstanceReconciler) resolveMCPServers(ctx context.Context, instanc
 
 		// Copy referenced Secrets from operator namespace to user namespace.
 		for _, secretRef := range server.Spec.SecretRefs {
-			if err := r.copyMCPSecret(ctx, instance, secretRef.SecretName, namespace); err != nil {
-				return fmt.Errorf("copying MCP secret %q for server %q: %w",
+			if err := r.copyMCPSecret(ctx, instance, secretRef.SecretName, server.Namespace, namespace); err != nil {
+				return nil, fmt.Errorf("copying MCP secret %q for server %q: %w",
 					secretRef.SecretName, ref.Name, err)
 			}
 		}
@@ -638,20 +1322,44 @@ func (r *KlausInstanceReconciler) resolveMCPServers(ctx context.Context, instanc
 	// Clean up stale MCP secrets that are no longer referenced by any
 	// non-deleting instance for the same owner.
 	if err := r.cleanupStaleMCPSecrets(ctx, instance.Spec.Owner, namespace); err != nil {
-		return fmt.Errorf("cleaning up stale MCP secrets: %w", err)
+		return nil, fmt.Errorf("cleaning up stale MCP secrets: %w", err)
 	}
 
 	resources.MergeResolvedMCPIntoInstance(resolved, &instance.Spec)
-	return nil
+	return resolved.Artifacts, nil
 }
 
 // copyGitSecret copies the workspace git credential Secret from the operator
 // namespace to the user namespace so the git-clone init container can access it.
-// Returns OperationResultNone when gitSecretRef is not configured.
+// With workspace.gitHubApp, the Secret instead holds an installation token
+// minted by the operator, replaced when it is about to expire. With
+// workspace.repos, it holds the token of every repository with a secretRef
+// under its own key.
+// Returns OperationResultNone when no credentials are configured.
 func (r *KlausInstanceReconciler) copyGitSecret(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (controllerutil.OperationResult, error) {
 	if !resources.NeedsGitSecret(instance) {
 		return controllerutil.OperationResultNone, nil
 	}
+	if resources.UsesGitHubApp(instance) {
+		desired := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.GitSecretName(instance),
+			Namespace: namespace,
+		}}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, desired, func() error {
+			if _, err := refreshGitHubAppToken(ctx, r.GitHubApp, instance, desired, time.Now()); err != nil {
+				return fmt.Errorf("minting GitHub App installation token: %w", err)
+			}
+			desired.Labels = resources.InstanceLabels(instance)
+			return r.setInstanceOwner(instance, desired)
+		})
+		if err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("reconciling git secret: %w", err)
+		}
+		return op, nil
+	}
+	if resources.HasWorkspaceRepos(instance) {
+		return r.copyWorkspaceRepoSecrets(ctx, instance, namespace)
+	}
 
 	srcName := instance.Spec.Workspace.GitSecretRef.Name
 	srcSecret := &corev1.Secret{}
@@ -670,7 +1378,7 @@ func (r *KlausInstanceReconciler) copyGitSecret(ctx context.Context, instance *k
 		desired.Type = srcSecret.Type
 		desired.Data = srcSecret.Data
 		desired.Labels = resources.InstanceLabels(instance)
-		return nil
+		return r.setInstanceOwner(instance, desired)
 	})
 	if err != nil {
 		return controllerutil.OperationResultNone, fmt.Errorf("reconciling git secret copy: %w", err)
@@ -678,15 +1386,109 @@ func (r *KlausInstanceReconciler) copyGitSecret(ctx context.Context, instance *k
 	return op, nil
 }
 
+// copyWorkspaceRepoSecrets collects the tokens of the workspace.repos
+// entries with a secretRef into the git credential Secret in the user
+// namespace.
+func (r *KlausInstanceReconciler) copyWorkspaceRepoSecrets(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (controllerutil.OperationResult, error) {
+	data := map[string][]byte{}
+	for i, repo := range instance.Spec.Workspace.Repos {
+		if repo.SecretRef == nil {
+			continue
+		}
+		srcSecret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{
+			Name:      repo.SecretRef.Name,
+			Namespace: instance.Namespace,
+		}, srcSecret); err != nil {
+			return controllerutil.OperationResultNone, fmt.Errorf("fetching git secret %q: %w", repo.SecretRef.Name, err)
+		}
+		key := repo.SecretRef.Key
+		if key == "" {
+			key = resources.DefaultGitSecretKey
+		}
+		token, ok := srcSecret.Data[key]
+		if !ok {
+			return controllerutil.OperationResultNone, fmt.Errorf("git secret %q has no key %q", repo.SecretRef.Name, key)
+		}
+		data[resources.WorkspaceRepoSecretKey(i)] = token
+	}
+
+	desired := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      resources.GitSecretName(instance),
+		Namespace: namespace,
+	}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, desired, func() error {
+		desired.Type = corev1.SecretTypeOpaque
+		desired.Data = data
+		desired.Labels = resources.InstanceLabels(instance)
+		return r.setInstanceOwner(instance, desired)
+	})
+	if err != nil {
+		return controllerutil.OperationResultNone, fmt.Errorf("reconciling git secret copy: %w", err)
+	}
+	return op, nil
+}
+
+// workspaceRepoList returns the repositories cloned into the workspace of
+// an instance, for events.
+func workspaceRepoList(instance *klausv1alpha1.KlausInstance) string {
+	if !resources.HasWorkspaceRepos(instance) {
+		return instance.Spec.Workspace.GitRepo
+	}
+	repos := make([]string, 0, len(instance.Spec.Workspace.Repos))
+	for _, repo := range instance.Spec.Workspace.Repos {
+		repos = append(repos, repo.Repo)
+	}
+	return strings.Join(repos, ", ")
+}
+
+// copyProviderSecret copies the model provider credentials Secret from the
+// operator namespace into the user namespace when the provider references one.
+func (r *KlausInstanceReconciler) copyProviderSecret(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	ref := resources.ProviderCredentialsRef(instance)
+	if ref == nil {
+		return nil
+	}
+
+	srcSecret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      ref.Name,
+		Namespace: instance.Namespace,
+	}, srcSecret); err != nil {
+		return fmt.Errorf("fetching provider secret %q: %w", ref.Name, err)
+	}
+
+	desired := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      resources.ProviderSecretName(instance),
+		Namespace: namespace,
+	}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, desired, func() error {
+		desired.Type = srcSecret.Type
+		desired.Data = srcSecret.Data
+		desired.Labels = resources.InstanceLabels(instance)
+		return r.setInstanceOwner(instance, desired)
+	})
+	if err != nil {
+		return fmt.Errorf("reconciling provider secret copy: %w", err)
+	}
+	return nil
+}
+
 // copyMCPSecret copies a Secret from the operator namespace to the target user
 // namespace, ensuring that secretKeyRef env vars on the instance pod can resolve.
 // Labels are owner-scoped (not instance-specific) because multiple instances
 // for the same owner may share the same MCP secret.
-func (r *KlausInstanceReconciler) copyMCPSecret(ctx context.Context, instance *klausv1alpha1.KlausInstance, secretName, targetNamespace string) error {
+func (r *KlausInstanceReconciler) copyMCPSecret(ctx context.Context, instance *klausv1alpha1.KlausInstance, secretName, sourceNamespace, targetNamespace string) error {
+	if targetNamespace == sourceNamespace {
+		// In namespace-scoped mode the Secret of a server in the instance's
+		// namespace is used directly.
+		return nil
+	}
+
 	srcSecret := &corev1.Secret{}
 	err := r.Get(ctx, types.NamespacedName{
 		Name:      secretName,
-		Namespace: instance.Namespace,
+		Namespace: sourceNamespace,
 	}, srcSecret)
 	if err != nil {
 		return fmt.Errorf("fetching source secret: %w", err)
@@ -711,15 +1513,17 @@ func (r *KlausInstanceReconciler) copyMCPSecret(ctx context.Context, instance *k
 func (r *KlausInstanceReconciler) cleanupStaleMCPSecrets(ctx context.Context, owner, namespace string) error {
 	logger := log.FromContext(ctx)
 
-	// Build a lookup of MCP server name -> secret names.
+	// Build a lookup of MCP server -> secret names, across namespaces since
+	// references may cross them.
 	var serverList klausv1alpha1.KlausMCPServerList
-	if err := r.List(ctx, &serverList, client.InNamespace(r.OperatorNamespace)); err != nil {
+	if err := r.List(ctx, &serverList); err != nil {
 		return fmt.Errorf("listing MCP servers: %w", err)
 	}
-	serverSecrets := make(map[string][]string, len(serverList.Items))
+	serverSecrets := make(map[types.NamespacedName][]string, len(serverList.Items))
 	for _, server := range serverList.Items {
+		key := client.ObjectKeyFromObject(&server)
 		for _, ref := range server.Spec.SecretRefs {
-			serverSecrets[server.Name] = append(serverSecrets[server.Name], ref.SecretName)
+			serverSecrets[key] = append(serverSecrets[key], ref.SecretName)
 		}
 	}
 
@@ -731,11 +1535,11 @@ func (r *KlausInstanceReconciler) cleanupStaleMCPSecrets(ctx context.Context, ow
 		return fmt.Errorf("listing instances: %w", err)
 	}
 	for _, inst := range instanceList.Items {
-		if resources.UserNamespace(inst.Spec.Owner) != namespace || !inst.DeletionTimestamp.IsZero() {
+		if r.childNamespace(&inst) != namespace || !inst.DeletionTimestamp.IsZero() {
 			continue
 		}
 		for _, ref := range inst.Spec.MCPServers {
-			for _, secretName := range serverSecrets[ref.Name] {
+			for _, secretName := range serverSecrets[mcpServerRefKey(inst.Namespace, ref)] {
 				desiredSecrets[secretName] = true
 			}
 		}
@@ -835,6 +1639,8 @@ func (r *KlausInstanceReconciler) resolveOCIReferences(ctx context.Context, inst
 // KlausInstance (operator namespace). Owns() relies on owner references which
 // cannot cross namespace boundaries.
 func (r *KlausInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.unknownCapabilities = &unknownCapabilityImages{}
+
 	managedByPredicate, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			"app.kubernetes.io/managed-by": "klaus-operator",
@@ -850,26 +1656,74 @@ func (r *KlausInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			if instanceName == "" {
 				return nil
 			}
+			// In namespace-scoped mode the children share the namespace of
+			// their instance.
+			namespace := r.OperatorNamespace
+			if r.NamespaceScoped {
+				namespace = obj.GetNamespace()
+			}
 			return []reconcile.Request{{
 				NamespacedName: types.NamespacedName{
 					Name:      instanceName,
-					Namespace: r.OperatorNamespace,
+					Namespace: namespace,
 				},
 			}}
 		},
 	)
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&klausv1alpha1.KlausInstance{}).
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&klausv1alpha1.KlausInstance{},
+			builder.WithPredicates(primaryPredicate())).
 		Watches(&appsv1.Deployment{}, mapToInstance,
-			builder.WithPredicates(managedByPredicate)).
+			builder.WithPredicates(managedByPredicate, childChangedPredicate())).
 		Watches(&corev1.Service{}, mapToInstance,
-			builder.WithPredicates(managedByPredicate)).
+			builder.WithPredicates(managedByPredicate, childChangedPredicate())).
 		Watches(&corev1.ConfigMap{}, mapToInstance,
-			builder.WithPredicates(managedByPredicate)).
+			builder.WithPredicates(managedByPredicate, childChangedPredicate())).
+		Watches(&corev1.PersistentVolumeClaim{}, mapToInstance,
+			builder.WithPredicates(managedByPredicate, childChangedPredicate())).
+		Watches(&networkingv1.Ingress{}, mapToInstance,
+			builder.WithPredicates(managedByPredicate, childChangedPredicate())).
+		Watches(&policyv1.PodDisruptionBudget{}, mapToInstance,
+			builder.WithPredicates(managedByPredicate, childChangedPredicate())).
+		Watches(&networkingv1.NetworkPolicy{}, mapToInstance,
+			builder.WithPredicates(managedByPredicate, childChangedPredicate())).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(EnqueueNetworkRefInstances(r.Client))).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(EnqueueNetworkRefInstances(r.Client))).
+		Watches(&klausv1alpha1.KlausInstance{},
+			handler.EnqueueRequestsFromMapFunc(EnqueueDependentInstances(r.Client)),
+			builder.WithPredicates(instanceStateChangedPredicate()),
+		).
 		Watches(&klausv1alpha1.KlausMCPServer{},
-			handler.EnqueueRequestsFromMapFunc(EnqueueReferencingMCPServerInstances(r.Client, r.OperatorNamespace)),
+			handler.EnqueueRequestsFromMapFunc(EnqueueReferencingMCPServerInstances(r.Client)),
+			builder.WithPredicates(mcpServerReadinessPredicate()),
+		).
+		Watches(&klausv1alpha1.KlausSkillPack{},
+			handler.EnqueueRequestsFromMapFunc(EnqueueReferencingSkillPackInstances(r.Client)),
+			builder.WithPredicates(specChangedPredicate()),
+		).
+		Watches(&klausv1alpha1.KlausToolchain{},
+			handler.EnqueueRequestsFromMapFunc(EnqueueToolchainInstances(r.Client, r.OperatorNamespace)),
+			builder.WithPredicates(specChangedPredicate()),
+		).
+		Watches(&klausv1alpha1.KlausInstance{},
+			handler.EnqueueRequestsFromMapFunc(EnqueueAPIBudgetSharers(r.Client, r.OperatorNamespace)),
+			builder.WithPredicates(apiBudgetSharePredicate()),
 		).
-		Named("klausinstance").
+		Watches(&klausv1alpha1.KlausQuota{},
+			handler.EnqueueRequestsFromMapFunc(EnqueueQuotaInstances(r.Client, r.OperatorNamespace)),
+			builder.WithPredicates(specChangedPredicate()),
+		)
+
+	opts := r.ControllerOptions.For(ControllerNameKlausInstance)
+	if r.Shards != nil {
+		// The shard Lease keeps every instance on one replica.
+		opts.NeedLeaderElection = ptr.To(false)
+		b = b.WatchesRawSource(claimedInstancesSource(r.Shards, r.Client))
+	}
+	return b.Named(ControllerNameKlausInstance).
+		WithOptions(opts).
 		Complete(r)
 }
@@ -2,16 +2,28 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+	"github.com/giantswarm/klaus-operator/internal/resources"
 )
 
 // mockOCIResolver is a test double for OCIResolver.
@@ -19,6 +31,7 @@ type mockOCIResolver struct {
 	personalityFn func(ctx context.Context, ref string) (string, error)
 	toolchainFn   func(ctx context.Context, ref string) (string, error)
 	pluginFn      func(ctx context.Context, ref string) (string, error)
+	digestFn      func(ctx context.Context, ref string) (string, error)
 }
 
 func (m *mockOCIResolver) ResolvePersonalityRef(ctx context.Context, ref string) (string, error) {
@@ -42,6 +55,15 @@ func (m *mockOCIResolver) ResolvePluginRef(ctx context.Context, ref string) (str
 	return ref, nil
 }
 
+// Resolve returns a digest derived from ref by default, so every tag
+// resolves to a distinct digest.
+func (m *mockOCIResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	if m.digestFn != nil {
+		return m.digestFn(ctx, ref)
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(ref))), nil
+}
+
 func TestPopulateCommonStatus_Toolchain(t *testing.T) {
 	const defaultImage = "gsoci.azurecr.io/giantswarm/klaus:latest"
 
@@ -158,6 +180,22 @@ func TestPopulateCommonStatus_BasicFields(t *testing.T) {
 	}
 }
 
+func TestPopulateCommonStatus_InlinePersonality(t *testing.T) {
+	r := &KlausInstanceReconciler{KlausImage: "default:latest"}
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:             "test@example.com",
+			InlinePersonality: &klausv1alpha1.InlinePersonality{Soul: "You are helpful."},
+		},
+	}
+
+	r.populateCommonStatus(instance, "klaus-user-test", "default:latest")
+
+	if instance.Status.Personality != "inline" {
+		t.Errorf("Personality = %q, want inline", instance.Status.Personality)
+	}
+}
+
 func TestResolveOCIReferences_NilClient(t *testing.T) {
 	r := &KlausInstanceReconciler{}
 	instance := &klausv1alpha1.KlausInstance{
@@ -307,3 +345,291 @@ func TestResolveOCIReferences_NoopWhenEmpty(t *testing.T) {
 		t.Error("expected no OCI calls when personality/image/plugins are empty")
 	}
 }
+
+func apiKeySecret(name, key string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system"},
+		Data:       map[string][]byte{"api-key": []byte(key)},
+	}
+}
+
+func copiedAPIKey(t *testing.T, shared *corev1.Secret, extra ...*corev1.Secret) (bool, string) {
+	t.Helper()
+	builder := fake.NewClientBuilder().WithScheme(testScheme(t))
+	if shared != nil {
+		builder = builder.WithObjects(shared)
+	}
+	for _, s := range extra {
+		builder = builder.WithObjects(s)
+	}
+	c := builder.Build()
+	r := &KlausInstanceReconciler{
+		Client:             c,
+		AnthropicKeySecret: "anthropic-api-key",
+		AnthropicKeyNs:     "klaus-system",
+	}
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+
+	source, err := r.copyAPIKeySecret(context.Background(), instance, "klaus-user-user-example-com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source == "" {
+		return false, ""
+	}
+	var copied corev1.Secret
+	if err := c.Get(context.Background(), types.NamespacedName{
+		Name: resources.SecretName(instance), Namespace: "klaus-user-user-example-com",
+	}, &copied); err != nil {
+		t.Fatalf("failed to get copied secret: %v", err)
+	}
+	return true, string(copied.Data["api-key"])
+}
+
+func TestCopyAPIKeySecret_SharedFallback(t *testing.T) {
+	found, key := copiedAPIKey(t, apiKeySecret("anthropic-api-key", "shared-key"))
+	if !found {
+		t.Fatal("expected shared secret to be found")
+	}
+	if key != "shared-key" {
+		t.Errorf("api-key = %q, want %q", key, "shared-key")
+	}
+}
+
+func TestCopyAPIKeySecret_PerOwnerOverride(t *testing.T) {
+	owner := apiKeySecret(resources.OwnerAPIKeySecretName("anthropic-api-key", "user@example.com"), "owner-key")
+	found, key := copiedAPIKey(t, apiKeySecret("anthropic-api-key", "shared-key"), owner)
+	if !found {
+		t.Fatal("expected per-owner secret to be found")
+	}
+	if key != "owner-key" {
+		t.Errorf("api-key = %q, want %q", key, "owner-key")
+	}
+}
+
+func TestCopyAPIKeySecret_InvalidPerOwnerSecret(t *testing.T) {
+	owner := apiKeySecret(resources.OwnerAPIKeySecretName("anthropic-api-key", "user@example.com"), "")
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(apiKeySecret("anthropic-api-key", "shared-key"), owner).Build()
+	r := &KlausInstanceReconciler{Client: c, AnthropicKeySecret: "anthropic-api-key", AnthropicKeyNs: "klaus-system"}
+
+	_, err := r.copyAPIKeySecret(context.Background(), testInstance(), "klaus-user-user-example-com")
+	if err == nil || !strings.Contains(err.Error(), owner.Name) {
+		t.Errorf("copyAPIKeySecret() error = %v, want the empty per-owner Secret reported instead of the shared key", err)
+	}
+}
+
+func TestSetAPIKeyCondition(t *testing.T) {
+	r := &KlausInstanceReconciler{AnthropicKeySecret: "anthropic-api-key", AnthropicKeyNs: "klaus-system"}
+	recorder := record.NewFakeRecorder(testRecorderDepth)
+	r.Recorder = recorder
+	instance := testInstance()
+	ownerSecret := resources.OwnerAPIKeySecretName("anthropic-api-key", instance.Spec.Owner)
+
+	r.setAPIKeyCondition(instance, ownerSecret)
+	if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionAPIKeyReady); cond == nil || cond.Reason != "OwnerKey" {
+		t.Errorf("condition = %+v, want OwnerKey", cond)
+	}
+
+	r.setAPIKeyCondition(instance, "anthropic-api-key")
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionAPIKeyReady)
+	if cond == nil || cond.Reason != "SharedKey" || !strings.Contains(cond.Message, ownerSecret) {
+		t.Errorf("condition = %+v, want SharedKey naming the per-owner Secret", cond)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("got %d events, want a warning for the fallback to the shared key", len(recorder.Events))
+	}
+	r.setAPIKeyCondition(instance, "anthropic-api-key")
+	if len(recorder.Events) != 1 {
+		t.Errorf("got %d events, want no repeated warning", len(recorder.Events))
+	}
+}
+
+func TestCopyAPIKeySecret_NotFound(t *testing.T) {
+	if found, _ := copiedAPIKey(t, nil); found {
+		t.Error("expected found=false when no secret exists")
+	}
+}
+
+func TestInstanceImage(t *testing.T) {
+	r := &KlausInstanceReconciler{KlausImage: "default:latest", MockImage: "mock:latest"}
+
+	tests := []struct {
+		name string
+		spec klausv1alpha1.KlausInstanceSpec
+		want string
+	}{
+		{name: "operator default", want: "default:latest"},
+		{name: "instance image", spec: klausv1alpha1.KlausInstanceSpec{Image: "custom:1"}, want: "custom:1"},
+		{name: "mock mode replaces the instance image", spec: klausv1alpha1.KlausInstanceSpec{Image: "custom:1", MockMode: true}, want: "mock:latest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.instanceImage(&klausv1alpha1.KlausInstance{Spec: tt.spec}); got != tt.want {
+				t.Errorf("instanceImage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPopulateCommonStatus_Mock(t *testing.T) {
+	r := &KlausInstanceReconciler{KlausImage: "default:latest", MockImage: "mock:latest"}
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-instance"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com", MockMode: true},
+	}
+
+	r.populateCommonStatus(instance, "klaus-user-test", "mock:latest")
+	if !instance.Status.Mock {
+		t.Error("expected status.mock to mark the instance as non-production")
+	}
+
+	instance.Spec.MockMode = false
+	r.populateCommonStatus(instance, "klaus-user-test", "default:latest")
+	if instance.Status.Mock {
+		t.Error("expected status.mock to be cleared when mock mode is disabled")
+	}
+}
+
+func TestCopyGitSecret_WorkspaceRepos(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "multi", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "user@example.com",
+			Workspace: &klausv1alpha1.WorkspaceConfig{Repos: []klausv1alpha1.WorkspaceRepository{
+				{Repo: "https://github.com/org/api.git", SecretRef: &klausv1alpha1.GitSecretReference{Name: "api-token"}},
+				{Repo: "https://github.com/org/docs.git"},
+				{Repo: "https://gitlab.com/org/infra.git", SecretRef: &klausv1alpha1.GitSecretReference{Name: "gitlab", Key: "pat"}},
+			}},
+		},
+	}
+	apiToken := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-token", Namespace: "klaus-system"},
+		Data:       map[string][]byte{"token": []byte("ghp_api")},
+	}
+	gitlab := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "gitlab", Namespace: "klaus-system"},
+		Data:       map[string][]byte{"pat": []byte("glpat")},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance, apiToken, gitlab).Build()
+	r := &KlausInstanceReconciler{Client: c, Scheme: c.Scheme()}
+
+	if _, err := r.copyGitSecret(context.Background(), instance, "klaus-user-test"); err != nil {
+		t.Fatalf("copyGitSecret: %v", err)
+	}
+	var secret corev1.Secret
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "multi-git-creds", Namespace: "klaus-user-test"}, &secret); err != nil {
+		t.Fatalf("failed to get git secret: %v", err)
+	}
+	if len(secret.Data) != 2 || string(secret.Data["repo-0"]) != "ghp_api" || string(secret.Data["repo-2"]) != "glpat" {
+		t.Errorf("secret data = %v, want the tokens keyed by repository index", secret.Data)
+	}
+
+	gitlab.Data = map[string][]byte{"token": []byte("glpat")}
+	if err := c.Update(context.Background(), gitlab); err != nil {
+		t.Fatalf("updating secret: %v", err)
+	}
+	if _, err := r.copyGitSecret(context.Background(), instance, "klaus-user-test"); err == nil || !strings.Contains(err.Error(), `no key "pat"`) {
+		t.Errorf("error = %v, want a missing key error", err)
+	}
+}
+
+func TestResolveMCPServers_OCIArtifact(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:      "user@example.com",
+			MCPServers: []klausv1alpha1.MCPServerReference{{Name: "github"}},
+		},
+	}
+	server := &klausv1alpha1.KlausMCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausMCPServerSpec{
+			Type:    "stdio",
+			Command: "bin/github-mcp-server",
+			Args:    []string{"stdio"},
+			OCIRef:  "gsoci.azurecr.io/giantswarm/mcp-servers/github:v1.0.0",
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance, server).Build()
+	r := &KlausInstanceReconciler{Client: c, Scheme: c.Scheme(), OperatorNamespace: "klaus-system"}
+
+	artifacts, err := r.resolveMCPServers(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("resolveMCPServers: %v", err)
+	}
+	if artifacts["github"] != server.Spec.OCIRef || len(artifacts) != 1 {
+		t.Errorf("artifacts = %v, want the github artifact", artifacts)
+	}
+	var config map[string]any
+	if err := json.Unmarshal(instance.Spec.Claude.MCPServers["github"].Raw, &config); err != nil {
+		t.Fatalf("decoding config: %v", err)
+	}
+	if config["command"] != "/var/lib/klaus/mcp-servers/github/bin/github-mcp-server" {
+		t.Errorf("command = %v, want it pointing into the artifact mount", config["command"])
+	}
+
+	r.RegistryPolicy = &registrypolicy.Policy{Allow: []string{"ghcr.io"}}
+	if _, err := r.resolveMCPServers(context.Background(), instance); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("error = %v, want the artifact rejected by the registry policy", err)
+	}
+}
+
+func TestResolveInstance_MCPServerArtifact(t *testing.T) {
+	const ociRef = "gsoci.azurecr.io/giantswarm/mcp-servers/github:v1.0.0"
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:      "user@example.com",
+			MCPServers: []klausv1alpha1.MCPServerReference{{Name: "github"}},
+		},
+	}
+	server := &klausv1alpha1.KlausMCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausMCPServerSpec{Type: "stdio", Command: "bin/github-mcp-server", OCIRef: ociRef},
+	}
+	resolver := &mockOCIResolver{}
+	digest, _ := resolver.Resolve(context.Background(), ociRef)
+	pinned := ociRef + "@" + digest
+	verifier := &fakeArtifactVerifier{digests: map[string]string{pinned: digest}}
+	newReconciler := func() *KlausInstanceReconciler {
+		return &KlausInstanceReconciler{
+			Client:            fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance, server).Build(),
+			Recorder:          record.NewFakeRecorder(10),
+			OperatorNamespace: "klaus-system",
+			OCIClient:         resolver,
+			ArtifactVerifier:  verifier,
+		}
+	}
+
+	resolved, err := newReconciler().resolveInstance(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("resolveInstance() error = %v", err)
+	}
+	if resolved.mcpArtifacts["github"] != pinned {
+		t.Errorf("artifact = %q, want it pinned to %s", resolved.mcpArtifacts["github"], digest)
+	}
+	if !slices.Equal(verifier.refs, []string{pinned}) {
+		t.Errorf("verified %v, want the pinned artifact", verifier.refs)
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionArtifactVerificationFailed)
+	if cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("ArtifactVerificationFailed = %+v, want False", cond)
+	}
+
+	verifier.failures = map[string]error{pinned: errors.New("no matching signatures")}
+	_, err = newReconciler().resolveInstance(context.Background(), instance)
+	var re *reasonError
+	if !errors.As(err, &re) || re.reason != ReasonArtifactVerificationFailed {
+		t.Errorf("error = %v, want %s", err, ReasonArtifactVerificationFailed)
+	}
+
+	r := newReconciler()
+	r.ImageVolumesUnsupported = true
+	_, err = r.resolveInstance(context.Background(), instance)
+	if !errors.As(err, &re) || re.reason != ReasonImageVolumesUnsupported || !strings.Contains(err.Error(), "github") {
+		t.Errorf("error = %v, want %s naming the server", err, ReasonImageVolumesUnsupported)
+	}
+}
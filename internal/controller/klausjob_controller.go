@@ -0,0 +1,530 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+	"github.com/giantswarm/klaus-operator/internal/oci"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// JobConditionComplete indicates the KlausJob has finished, successfully or not.
+const JobConditionComplete = "Complete"
+
+// KlausJobReconciler reconciles a KlausJob object into a batch Job in the
+// owner's namespace.
+type KlausJobReconciler struct {
+	client.Client
+	Scheme             *runtime.Scheme
+	Recorder           record.EventRecorder
+	KlausImage         string
+	GitCloneImage      string
+	AnthropicKeySecret string
+	AnthropicKeyNs     string
+	OperatorNamespace  string
+	OCIClient          OCIResolver
+	// DefaultPermission is the permission mode of jobs that do not set
+	// spec.claude.permissionMode.
+	DefaultPermission klausv1alpha1.PermissionMode
+	// APIReader is an uncached reader used for pod lookups and to re-read
+	// the status on a conflict.
+	APIReader client.Reader
+	// PluginPVC, when set, serves plugins from a shared PVC per user
+	// namespace instead of OCI image volumes.
+	PluginPVC *resources.PluginPVCOptions
+	// PluginPullImage, when set, pulls plugins into an emptyDir with an init
+	// container running this image, normally the operator image, instead of
+	// mounting OCI image volumes.
+	PluginPullImage string
+	// TenantClusterRole, when set, is bound to the tenant ServiceAccount of
+	// every user namespace, which an ImpersonatingClient acts as.
+	TenantClusterRole string
+	// NamespaceScoped creates the batch Job and its children in the
+	// namespace of the KlausJob instead of the owner's user namespace.
+	NamespaceScoped bool
+	// GitHubApp mints the installation tokens of workspaces using
+	// spec.workspace.gitHubApp.
+	GitHubApp GitHubTokenMinter
+	// PullRequests opens the pull requests of spec.workspace.output.
+	PullRequests PullRequestOpener
+	// ArtifactVerifier, when set, verifies the signatures of the resolved
+	// personality, toolchain and plugin artifacts before the Job is created.
+	ArtifactVerifier ArtifactVerifier
+	// SoulFetcher, when set, reads the SOUL.md of the resolved personality
+	// artifact into the agent memory.
+	SoulFetcher SoulFetcher
+	// RegistryPolicy restricts the registries the resolved references may
+	// point to; every registry is allowed when nil.
+	RegistryPolicy *registrypolicy.Policy
+	// TelemetryCollector, when set, runs the collector sidecar in the pods
+	// of jobs with telemetry enabled but no OTLP endpoint.
+	TelemetryCollector *resources.TelemetryCollector
+
+	// ControllerOptions tunes the work queue of the controller.
+	ControllerOptions ControllerOptions
+}
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausjobs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausjobs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+
+// Reconcile handles KlausJob create/update/delete events.
+func (r *KlausJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	var job klausv1alpha1.KlausJob
+	if err := r.Get(ctx, req.NamespacedName, &job); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	ctx = withStatusBase(ctx, &job)
+
+	if !job.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, &job)
+	}
+
+	if !controllerutil.ContainsFinalizer(&job, FinalizerName) {
+		controllerutil.AddFinalizer(&job, FinalizerName)
+		return ctrl.Result{}, r.Update(ctx, &job)
+	}
+
+	// Finished jobs are immutable; keep their recorded result. Only the
+	// push of the workspace changes of a succeeded run continues.
+	if job.Status.State == klausv1alpha1.JobStateSucceeded || job.Status.State == klausv1alpha1.JobStateFailed {
+		if outputPending(&job) {
+			return r.reconcileOutput(ctx, &job)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if job.Status.State == "" {
+		job.Status.State = klausv1alpha1.JobStatePending
+		if err := patchObjectStatus(ctx, r.Client, r.APIReader, &job); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := resources.ValidateJobSpec(&job); err != nil {
+		return r.updateStatusFailed(ctx, &job, "ValidationError", err)
+	}
+	prompt, err := resources.RenderJobPrompt(&job)
+	if err != nil {
+		return r.updateStatusFailed(ctx, &job, "PromptTemplateError", err)
+	}
+
+	instance := resources.JobInstance(&job)
+	namespace := resources.InstanceNamespace(instance, r.NamespaceScoped)
+	applyDefaultPermission(instance, r.DefaultPermission)
+	shared := r.instanceHelpers()
+
+	if err := shared.resolveToolchainRef(ctx, instance); err != nil {
+		reason := "ToolchainError"
+		var rerr *reasonError
+		if errors.As(err, &rerr) {
+			reason = rerr.reason
+		}
+		if reason == ReasonToolchainPersonalityMismatch {
+			return r.updateStatusFailed(ctx, &job, reason, err)
+		}
+		return r.updateStatusError(ctx, &job, reason, err)
+	}
+	if err := shared.resolveOCIReferences(ctx, instance); err != nil {
+		return r.updateStatusError(ctx, &job, "OCIResolutionError", err)
+	}
+	if err := shared.checkRegistryPolicy(instance); err != nil {
+		return r.updateStatusFailed(ctx, &job, ReasonRegistryPolicyViolation, err)
+	}
+	if _, err := shared.verifyOCIReferences(ctx, instance); err != nil {
+		return r.updateStatusError(ctx, &job, ReasonArtifactVerificationFailed, err)
+	}
+
+	logger.Info("reconciling KlausJob", "job", job.Name, "owner", job.Spec.Owner, "namespace", namespace)
+
+	if !r.NamespaceScoped {
+		if err := shared.ensureNamespace(ctx, instance, namespace); err != nil {
+			return r.updateStatusError(ctx, &job, "NamespaceError", err)
+		}
+	}
+	if resources.UsesAnthropicAPIKey(instance) {
+		source, err := shared.copyAPIKeySecret(ctx, instance, namespace)
+		if err != nil {
+			metrics.SecretCopyFailures.WithLabelValues(metrics.SecretAnthropicAPIKey).Inc()
+			return r.updateStatusError(ctx, &job, "SecretError", err)
+		}
+		if source == "" {
+			logger.Info("Anthropic API key secret not found, requeuing")
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		if ownerSecret := resources.OwnerAPIKeySecretName(r.AnthropicKeySecret, job.Spec.Owner); source != ownerSecret {
+			r.Recorder.Event(&job, corev1.EventTypeNormal, "SharedAPIKey", fmt.Sprintf(
+				"Using the shared API key Secret %s: there is no per-owner Secret %s", source, ownerSecret))
+		}
+	} else if err := shared.copyProviderSecret(ctx, instance, namespace); err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretProvider).Inc()
+		return r.updateStatusError(ctx, &job, "ProviderSecretError", err)
+	}
+	if _, err := shared.copyGitSecret(ctx, instance, namespace); err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretGit).Inc()
+		return r.updateStatusError(ctx, &job, "GitSecretError", err)
+	}
+	collectorChecksum, err := shared.copyTelemetryCollector(ctx, instance, namespace)
+	if err != nil {
+		metrics.SecretCopyFailures.WithLabelValues(metrics.SecretOTelCollector).Inc()
+		return r.updateStatusError(ctx, &job, "TelemetryCollectorError", err)
+	}
+	if r.NamespaceScoped {
+		if err := checkExtraEnvSecrets(ctx, r.Client, instance, namespace); err != nil {
+			return r.updateStatusError(ctx, &job, "ExtraEnvSecretError", err)
+		}
+	}
+
+	soul, err := shared.fetchPersonalitySoul(ctx, instance)
+	if errors.Is(err, oci.ErrSoulUnavailable) {
+		r.Recorder.Event(&job, corev1.EventTypeWarning, ReasonPersonalitySoulUnavailable,
+			"Running without the soul of personality "+instance.Spec.Personality+": "+err.Error())
+	} else if err != nil {
+		return r.updateStatusError(ctx, &job, "PersonalitySoulError", err)
+	}
+	cm, err := resources.BuildJobConfigMap(&job, namespace, prompt)
+	if err == nil {
+		err = resources.SetPersonalitySoul(instance, cm, soul)
+	}
+	if err != nil {
+		return r.updateStatusError(ctx, &job, "ConfigMapError", err)
+	}
+	for _, shard := range resources.SplitConfigMap(instance, cm) {
+		existingCM := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: shard.Name, Namespace: namespace}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, existingCM, func() error {
+			existingCM.Data = shard.Data
+			existingCM.Labels = shard.Labels
+			return r.setJobOwner(&job, existingCM)
+		}); err != nil {
+			return r.updateStatusError(ctx, &job, "ConfigMapError", err)
+		}
+	}
+
+	if pvc := resources.BuildPVC(instance, namespace); pvc != nil {
+		pvc.Labels = resources.JobLabels(&job)
+		if err := r.setJobOwner(&job, pvc); err != nil {
+			return r.updateStatusError(ctx, &job, "PVCError", err)
+		}
+		if err := r.Create(ctx, pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+			return r.updateStatusError(ctx, &job, "PVCError", err)
+		}
+	}
+	if err := shared.ensureServiceAccount(ctx, instance, namespace); err != nil {
+		return r.updateStatusError(ctx, &job, "ServiceAccountError", err)
+	}
+
+	// Plugins served from the shared plugins PVC are synced before the Job
+	// is created.
+	if r.PluginPVC != nil {
+		synced, err := reconcilePluginCatalog(ctx, r.Client, *r.PluginPVC, namespace, instance.Spec.Plugins)
+		if err != nil {
+			return r.updateStatusError(ctx, &job, "PluginSyncError", err)
+		}
+		if !synced {
+			logger.Info("waiting for plugin sync", "job", job.Name)
+			return ctrl.Result{RequeueAfter: pluginSyncRequeueInterval}, nil
+		}
+	}
+
+	// The Job pod template is immutable, so the Job is only ever created.
+	resolvedImage := r.KlausImage
+	if instance.Spec.Image != "" {
+		resolvedImage = instance.Spec.Image
+	}
+	desired := resources.BuildJob(&job, namespace, resolvedImage, r.GitCloneImage, cm.Data)
+	switch {
+	case r.PluginPVC != nil:
+		resources.ApplyPluginPVC(&desired.Spec.Template.Spec, instance)
+	case r.PluginPullImage != "":
+		resources.ApplyPluginInitContainer(&desired.Spec.Template.Spec, instance, r.PluginPullImage)
+	}
+	resources.ApplyTelemetryCollector(&desired.Spec.Template, instance, r.TelemetryCollector, collectorChecksum)
+	var batchJob batchv1.Job
+	err = r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: namespace}, &batchJob)
+	if apierrors.IsNotFound(err) {
+		if err := r.setJobOwner(&job, desired); err != nil {
+			return r.updateStatusError(ctx, &job, "JobError", err)
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			return r.updateStatusError(ctx, &job, "JobError", err)
+		}
+		r.Recorder.Event(&job, corev1.EventTypeNormal, "CreatingJob", "Created Job "+desired.Name)
+		batchJob = *desired
+	} else if err != nil {
+		return r.updateStatusError(ctx, &job, "JobError", err)
+	}
+
+	return r.updateStatusFromJob(ctx, &job, &batchJob)
+}
+
+// instanceHelpers returns a KlausInstanceReconciler sharing this reconciler's
+// client and credential settings, so the event-free child resource helpers
+// (namespace, secrets, ServiceAccount, OCI resolution) can be reused.
+func (r *KlausJobReconciler) instanceHelpers() *KlausInstanceReconciler {
+	return &KlausInstanceReconciler{
+		Client:             r.Client,
+		AnthropicKeySecret: r.AnthropicKeySecret,
+		AnthropicKeyNs:     r.AnthropicKeyNs,
+		OperatorNamespace:  r.OperatorNamespace,
+		OCIClient:          r.OCIClient,
+		TenantClusterRole:  r.TenantClusterRole,
+		GitHubApp:          r.GitHubApp,
+		ArtifactVerifier:   r.ArtifactVerifier,
+		SoulFetcher:        r.SoulFetcher,
+		RegistryPolicy:     r.RegistryPolicy,
+		TelemetryCollector: r.TelemetryCollector,
+	}
+}
+
+// updateStatusFromJob maps the batch Job status onto the KlausJob and, once
+// the Job has finished, records the exit code and result of the last attempt.
+func (r *KlausJobReconciler) updateStatusFromJob(ctx context.Context, job *klausv1alpha1.KlausJob, batchJob *batchv1.Job) (ctrl.Result, error) {
+	job.Status.JobName = batchJob.Name
+	job.Status.ObservedGeneration = job.Generation
+	job.Status.Attempts = batchJob.Status.Active + batchJob.Status.Succeeded + batchJob.Status.Failed
+	job.Status.StartTime = batchJob.Status.StartTime
+
+	finished := finishedJobCondition(batchJob)
+	switch {
+	case finished != nil:
+		r.recordTermination(ctx, job, batchJob)
+		completion := finished.LastTransitionTime
+		job.Status.CompletionTime = &completion
+		if finished.Type == batchv1.JobComplete {
+			job.Status.State = klausv1alpha1.JobStateSucceeded
+			setJobCondition(job, metav1.ConditionTrue, "Succeeded", "Job completed successfully")
+			r.Recorder.Event(job, corev1.EventTypeNormal, "JobSucceeded", "Job completed successfully")
+			if resources.JobWorkspaceOutput(job) != nil && job.Status.Output == nil {
+				job.Status.Output = &klausv1alpha1.JobOutputStatus{State: klausv1alpha1.JobOutputPending}
+				return r.reconcileOutput(ctx, job)
+			}
+		} else {
+			job.Status.State = klausv1alpha1.JobStateFailed
+			setJobCondition(job, metav1.ConditionTrue, finished.Reason, finished.Message)
+			r.Recorder.Event(job, corev1.EventTypeWarning, "JobFailed", finished.Message)
+		}
+	case batchJob.Status.Active > 0:
+		job.Status.State = klausv1alpha1.JobStateRunning
+		setJobCondition(job, metav1.ConditionFalse, "Running", "Job is running")
+	default:
+		job.Status.State = klausv1alpha1.JobStatePending
+		setJobCondition(job, metav1.ConditionFalse, "Pending", "Waiting for the Job pod to start")
+	}
+
+	if err := patchObjectStatus(ctx, r.Client, r.APIReader, job); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// finishedJobCondition returns the Complete or Failed condition of a
+// finished batch Job, or nil while it runs.
+func finishedJobCondition(batchJob *batchv1.Job) *batchv1.JobCondition {
+	for i := range batchJob.Status.Conditions {
+		c := &batchJob.Status.Conditions[i]
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return c
+		}
+	}
+	return nil
+}
+
+// recordTermination reads the klaus container state of the most recent Job
+// pod and stores its exit code, and the agent result and exit summary from
+// its termination message.
+func (r *KlausJobReconciler) recordTermination(ctx context.Context, job *klausv1alpha1.KlausJob, batchJob *batchv1.Job) {
+	terminated := r.lastTermination(ctx, batchJob, resources.AppKlaus)
+	if terminated == nil {
+		return
+	}
+	exitCode := terminated.ExitCode
+	job.Status.ExitCode = &exitCode
+	job.Status.Result, job.Status.Summary = resources.ParseTerminationMessage(
+		terminated.Message, resources.JobExitSummaryEnabled(job))
+}
+
+// lastTermination returns the terminated state of a container in the most
+// recent pod of a batch Job, or nil when it is not known.
+func (r *KlausJobReconciler) lastTermination(ctx context.Context, batchJob *batchv1.Job, container string) *corev1.ContainerStateTerminated {
+	if r.APIReader == nil {
+		return nil
+	}
+	var pods corev1.PodList
+	if err := r.APIReader.List(ctx, &pods,
+		client.InNamespace(batchJob.Namespace),
+		client.MatchingLabels{batchv1.JobNameLabel: batchJob.Name},
+	); err != nil {
+		log.FromContext(ctx).Info("listing job pods failed", "job", batchJob.Name, "error", err.Error())
+		return nil
+	}
+	if len(pods.Items) == 0 {
+		return nil
+	}
+
+	latest := slices.MaxFunc(pods.Items, func(a, b corev1.Pod) int {
+		return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+	})
+	for _, cs := range latest.Status.ContainerStatuses {
+		if cs.Name == container && cs.State.Terminated != nil {
+			return cs.State.Terminated
+		}
+	}
+	return nil
+}
+
+func (r *KlausJobReconciler) reconcileDelete(ctx context.Context, job *klausv1alpha1.KlausJob) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	instance := resources.JobInstance(job)
+	namespace := resources.InstanceNamespace(instance, r.NamespaceScoped)
+	name := resources.JobResourceName(job)
+
+	children := []client.Object{
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: resources.JobOutputName(job), Namespace: namespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: resources.ConfigMapName(instance), Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.SecretName(instance), Namespace: namespace}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+	}
+	if job.Spec.Workspace != nil {
+		children = append(children, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: resources.PVCName(instance), Namespace: namespace},
+		})
+	}
+	if resources.NeedsGitSecret(instance) {
+		children = append(children, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: resources.GitSecretName(instance), Namespace: namespace},
+		})
+	}
+	if resources.ProviderCredentialsRef(instance) != nil {
+		children = append(children, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: resources.ProviderSecretName(instance), Namespace: namespace},
+		})
+	}
+
+	var errs []error
+	for _, obj := range children {
+		// Background propagation removes the Job's pods along with it.
+		if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			logger.Error(err, "failed to delete resource", "kind", fmt.Sprintf("%T", obj), "name", obj.GetName())
+			errs = append(errs, err)
+		}
+	}
+	if err := deleteConfigShards(ctx, r.Client, instance, namespace, 1); err != nil {
+		logger.Error(err, "failed to delete configuration ConfigMaps")
+		errs = append(errs, err)
+	}
+	if err := collectTelemetryCollector(ctx, r.Client, namespace, name); err != nil {
+		logger.Error(err, "failed to garbage-collect the telemetry collector copies")
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	controllerutil.RemoveFinalizer(job, FinalizerName)
+	return ctrl.Result{}, r.Update(ctx, job)
+}
+
+// updateStatusError records a transient reconcile error without finishing
+// the job; the error is returned so the request is retried.
+func (r *KlausJobReconciler) updateStatusError(ctx context.Context, job *klausv1alpha1.KlausJob, reason string, err error) (ctrl.Result, error) {
+	reason = offlineReason(reason, err)
+	metrics.ReconcileErrors.WithLabelValues(metrics.ControllerKlausJob, reason).Inc()
+	job.Status.ObservedGeneration = job.Generation
+	setJobCondition(job, metav1.ConditionFalse, reason, err.Error())
+	_ = patchObjectStatus(ctx, r.Client, r.APIReader, job)
+	r.Recorder.Event(job, corev1.EventTypeWarning, reason, err.Error())
+	return ctrl.Result{}, err
+}
+
+// updateStatusFailed marks the job as permanently failed, e.g. for an invalid
+// spec that no retry can fix.
+func (r *KlausJobReconciler) updateStatusFailed(ctx context.Context, job *klausv1alpha1.KlausJob, reason string, err error) (ctrl.Result, error) {
+	now := metav1.Now()
+	job.Status.State = klausv1alpha1.JobStateFailed
+	job.Status.CompletionTime = &now
+	job.Status.ObservedGeneration = job.Generation
+	setJobCondition(job, metav1.ConditionTrue, reason, err.Error())
+	r.Recorder.Event(job, corev1.EventTypeWarning, reason, err.Error())
+	return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, job)
+}
+
+func setJobCondition(job *klausv1alpha1.KlausJob, status metav1.ConditionStatus, reason, message string) {
+	apimeta.SetStatusCondition(&job.Status.Conditions, metav1.Condition{
+		Type:               JobConditionComplete,
+		Status:             status,
+		ObservedGeneration: job.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *KlausJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	jobPredicate, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			resources.LabelManagedBy: resources.AppKlausOperator,
+		},
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      resources.LabelJob,
+			Operator: metav1.LabelSelectorOpExists,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("creating label selector predicate: %w", err)
+	}
+
+	mapToJob := handler.EnqueueRequestsFromMapFunc(
+		func(_ context.Context, obj client.Object) []reconcile.Request {
+			name := obj.GetLabels()[resources.LabelJob]
+			if name == "" {
+				return nil
+			}
+			namespace := r.OperatorNamespace
+			if r.NamespaceScoped {
+				namespace = obj.GetNamespace()
+			}
+			return []reconcile.Request{{
+				NamespacedName: types.NamespacedName{Name: name, Namespace: namespace},
+			}}
+		},
+	)
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&klausv1alpha1.KlausJob{},
+			builder.WithPredicates(primaryPredicate())).
+		Watches(&batchv1.Job{}, mapToJob,
+			builder.WithPredicates(jobPredicate, childChangedPredicate())).
+		Named(ControllerNameKlausJob).
+		WithOptions(r.ControllerOptions.For(ControllerNameKlausJob)).
+		Complete(r)
+}
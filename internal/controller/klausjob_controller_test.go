@@ -0,0 +1,295 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func jobTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := testScheme(t)
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding batchv1 to scheme: %v", err)
+	}
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding appsv1 to scheme: %v", err)
+	}
+	return scheme
+}
+
+func newJobReconciler(c client.Client) *KlausJobReconciler {
+	return &KlausJobReconciler{
+		Client:             c,
+		Recorder:           record.NewFakeRecorder(20),
+		KlausImage:         "klaus:latest",
+		AnthropicKeySecret: "anthropic-api-key",
+		AnthropicKeyNs:     "klaus-system",
+		OperatorNamespace:  "klaus-system",
+		APIReader:          c,
+	}
+}
+
+func TestKlausJobReconcile_CreatesJobAndRecordsResult(t *testing.T) {
+	ctx := context.Background()
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:  "user@example.com",
+			Prompt: "Review the repository",
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).
+		WithObjects(job, apiKeySecret("anthropic-api-key", "shared-key")).
+		WithStatusSubresource(job).
+		Build()
+	r := newJobReconciler(c)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "review", Namespace: "klaus-system"}}
+
+	// First reconcile adds the finalizer, second creates the children.
+	for range 2 {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	namespace := "klaus-user-user-example-com"
+	var batchJob batchv1.Job
+	if err := c.Get(ctx, types.NamespacedName{Name: "review-job", Namespace: namespace}, &batchJob); err != nil {
+		t.Fatalf("expected batch Job to be created: %v", err)
+	}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Name: "review-job-config", Namespace: namespace}, &cm); err != nil {
+		t.Fatalf("expected ConfigMap to be created: %v", err)
+	}
+	if cm.Data["prompt"] != "Review the repository" {
+		t.Errorf("prompt = %q", cm.Data["prompt"])
+	}
+
+	// Simulate completion of the Job and its pod.
+	batchJob.Status.Succeeded = 1
+	batchJob.Status.Conditions = []batchv1.JobCondition{{
+		Type:   batchv1.JobComplete,
+		Status: corev1.ConditionTrue,
+	}}
+	if err := c.Status().Update(ctx, &batchJob); err != nil {
+		t.Fatalf("updating job: %v", err)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "review-job-abc",
+			Namespace: namespace,
+			Labels:    map[string]string{batchv1.JobNameLabel: "review-job"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "klaus",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 0,
+					Message:  `{"summary":"no issues"}`,
+				}},
+			}},
+		},
+	}
+	if err := c.Create(ctx, pod); err != nil {
+		t.Fatalf("creating pod: %v", err)
+	}
+
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updated klausv1alpha1.KlausJob
+	if err := c.Get(ctx, req.NamespacedName, &updated); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if updated.Status.State != klausv1alpha1.JobStateSucceeded {
+		t.Errorf("State = %q, want Succeeded", updated.Status.State)
+	}
+	if updated.Status.Result != `{"summary":"no issues"}` {
+		t.Errorf("Result = %q", updated.Status.Result)
+	}
+	if updated.Status.ExitCode == nil || *updated.Status.ExitCode != 0 {
+		t.Errorf("ExitCode = %v, want 0", updated.Status.ExitCode)
+	}
+}
+
+func TestKlausJobReconcile_TelemetryCollector(t *testing.T) {
+	ctx := context.Background()
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:     "user@example.com",
+			Prompt:    "Review the repository",
+			Telemetry: &klausv1alpha1.TelemetryConfig{Enabled: ptr.To(true)},
+		},
+	}
+	config := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "otel", Namespace: "klaus-system"},
+		Data:       map[string]string{resources.OTelCollectorConfigKey: "receivers: {}"},
+	}
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).
+		WithObjects(job, config, apiKeySecret("anthropic-api-key", "shared-key")).
+		WithStatusSubresource(job).
+		Build()
+	r := newJobReconciler(c)
+	r.TelemetryCollector = &resources.TelemetryCollector{Image: "otelcol:1", ConfigMapName: "otel"}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "review", Namespace: "klaus-system"}}
+	for range 2 {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	namespace := "klaus-user-user-example-com"
+	var batchJob batchv1.Job
+	if err := c.Get(ctx, types.NamespacedName{Name: "review-job", Namespace: namespace}, &batchJob); err != nil {
+		t.Fatalf("expected batch Job to be created: %v", err)
+	}
+	if !resources.MountsTelemetryCollector(&batchJob.Spec.Template.Spec) {
+		t.Errorf("volumes = %+v, want the collector config copy", batchJob.Spec.Template.Spec.Volumes)
+	}
+	key := types.NamespacedName{Name: resources.OTelCollectorName, Namespace: namespace}
+	if err := c.Get(ctx, key, &corev1.ConfigMap{}); err != nil {
+		t.Fatalf("expected the collector config to be copied: %v", err)
+	}
+
+	// Deleting the last job mounting the copy removes it.
+	if err := c.Delete(ctx, job); err != nil {
+		t.Fatalf("deleting job: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.Get(ctx, key, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("collector config copy = %v, want it deleted", err)
+	}
+}
+
+func TestKlausJobRecordTermination_ExitSummary(t *testing.T) {
+	ctx := context.Background()
+	namespace := "klaus-user-user-example-com"
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "review-job-abc",
+			Namespace: namespace,
+			Labels:    map[string]string{batchv1.JobNameLabel: "review-job"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "klaus",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Message:  `{"type":"exit_summary","num_turns":3,"total_cost_usd":0.5,"is_error":true,"result":"gave up"}`,
+				}},
+			}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).WithObjects(pod).Build()
+	r := newJobReconciler(c)
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausJobSpec{Owner: "user@example.com"},
+	}
+	batchJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "review-job", Namespace: namespace}}
+
+	r.recordTermination(ctx, job, batchJob)
+
+	if job.Status.Result != "gave up" {
+		t.Errorf("Result = %q, want %q", job.Status.Result, "gave up")
+	}
+	if job.Status.ExitCode == nil || *job.Status.ExitCode != 1 {
+		t.Errorf("ExitCode = %v, want 1", job.Status.ExitCode)
+	}
+	summary := job.Status.Summary
+	if summary == nil || summary.Turns == nil || *summary.Turns != 3 || summary.CostUSD != "0.5" || !summary.IsError {
+		t.Errorf("Summary = %+v, want 3 turns, cost 0.5, error", summary)
+	}
+}
+
+func TestKlausJobReconcile_Toolchain(t *testing.T) {
+	ctx := context.Background()
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "klaus-system", Finalizers: []string{FinalizerName}},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:        "user@example.com",
+			Prompt:       "Review the repository",
+			ToolchainRef: "go-1.25",
+		},
+	}
+	restricted := testToolchain("go-dev-only", "golang:1.25")
+	restricted.Spec.Personalities = []string{"go-dev"}
+	mismatched := job.DeepCopy()
+	mismatched.Name = "mismatched"
+	mismatched.Spec.ToolchainRef = restricted.Name
+	mismatched.Spec.Personality = "gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v1.0.0"
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).
+		WithObjects(job, mismatched, restricted, testToolchain("go-1.25", "golang:1.25"), apiKeySecret("anthropic-api-key", "shared-key")).
+		WithStatusSubresource(job, mismatched).
+		Build()
+	r := newJobReconciler(c)
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(job)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var batchJob batchv1.Job
+	if err := c.Get(ctx, types.NamespacedName{Name: "review-job", Namespace: "klaus-user-user-example-com"}, &batchJob); err != nil {
+		t.Fatalf("expected batch Job to be created: %v", err)
+	}
+	if image := batchJob.Spec.Template.Spec.Containers[0].Image; image != "golang:1.25" {
+		t.Errorf("image = %q, want the toolchain image", image)
+	}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(mismatched)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var updated klausv1alpha1.KlausJob
+	if err := c.Get(ctx, client.ObjectKeyFromObject(mismatched), &updated); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if updated.Status.State != klausv1alpha1.JobStateFailed || len(updated.Status.Conditions) == 0 ||
+		updated.Status.Conditions[0].Reason != ReasonToolchainPersonalityMismatch {
+		t.Errorf("state = %q, conditions = %v, want failed with %s", updated.Status.State, updated.Status.Conditions, ReasonToolchainPersonalityMismatch)
+	}
+}
+
+func TestKlausJobReconcile_InvalidSpecFails(t *testing.T) {
+	ctx := context.Background()
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "broken",
+			Namespace:  "klaus-system",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: klausv1alpha1.KlausJobSpec{Owner: "user@example.com"},
+	}
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).WithObjects(job).WithStatusSubresource(job).Build()
+	r := newJobReconciler(c)
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "broken", Namespace: "klaus-system"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updated klausv1alpha1.KlausJob
+	if err := c.Get(ctx, types.NamespacedName{Name: "broken", Namespace: "klaus-system"}, &updated); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if updated.Status.State != klausv1alpha1.JobStateFailed {
+		t.Errorf("State = %q, want Failed", updated.Status.State)
+	}
+}
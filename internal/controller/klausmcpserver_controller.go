@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
@@ -11,10 +12,12 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
@@ -29,22 +32,23 @@ const (
 )
 
 // MCPServerRefIndexField is the field path used by the field indexer to look up
-// KlausInstance resources by referenced KlausMCPServer name.
+// KlausInstance resources by referenced KlausMCPServer.
 const MCPServerRefIndexField = "spec.mcpServers.name"
 
-// IndexMCPServerRefs extracts KlausMCPServer names from a KlausInstance for
-// the field indexer. This enables efficient lookups of instances by MCP server
-// name without requiring full list scans.
+// IndexMCPServerRefs extracts the referenced KlausMCPServers from a
+// KlausInstance for the field indexer, as namespace/name since references
+// may cross namespaces. This enables efficient lookups of instances by MCP
+// server without requiring full list scans.
 func IndexMCPServerRefs(obj client.Object) []string {
 	instance, ok := obj.(*klausv1alpha1.KlausInstance)
 	if !ok {
 		return nil
 	}
-	names := make([]string, 0, len(instance.Spec.MCPServers))
+	keys := make([]string, 0, len(instance.Spec.MCPServers))
 	for _, ref := range instance.Spec.MCPServers {
-		names = append(names, ref.Name)
+		keys = append(keys, mcpServerIndexValue(mcpServerRefKey(instance.Namespace, ref)))
 	}
-	return names
+	return keys
 }
 
 // KlausMCPServerReconciler reconciles a KlausMCPServer object.
@@ -53,9 +57,27 @@ type KlausMCPServerReconciler struct {
 	Scheme            *runtime.Scheme
 	Recorder          record.EventRecorder
 	OperatorNamespace string
+
+	// APIReader is an uncached reader the status is re-read from on a
+	// conflict. The cached client is used when nil.
+	APIReader client.Reader
+
+	// Prober checks the URLs of HTTP-based servers; they are not probed
+	// when nil.
+	Prober MCPServerProber
+
+	// ProbeInterval is how often the URLs are probed. Zero disables
+	// probing.
+	ProbeInterval time.Duration
+
+	// probes caches the probe results per URL.
+	probes mcpServerProbes
+
+	// ControllerOptions tunes the work queue of the controller.
+	ControllerOptions ControllerOptions
 }
 
-// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausmcpservers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausmcpservers,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausmcpservers/status,verbs=get;update;patch
 
 // Reconcile handles a KlausMCPServer event.
@@ -69,6 +91,7 @@ func (r *KlausMCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 		return ctrl.Result{}, err
 	}
+	ctx = withStatusBase(ctx, &server)
 
 	logger.Info("reconciling KlausMCPServer", "name", server.Name)
 
@@ -86,7 +109,7 @@ func (r *KlausMCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		r.Recorder.Event(&server, corev1.EventTypeWarning, "ValidationError", err.Error())
 
 		server.Status.ObservedGeneration = server.Generation
-		if statusErr := r.Status().Update(ctx, &server); statusErr != nil {
+		if statusErr := patchObjectStatus(ctx, r.Client, r.APIReader, &server); statusErr != nil {
 			logger.Error(statusErr, "failed to update status after validation error")
 			return ctrl.Result{}, statusErr
 		}
@@ -117,7 +140,7 @@ func (r *KlausMCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Count referencing instances. A transient error here would reset the
 	// count to 0 in the status, so we return the error to requeue.
-	instanceCount, err := r.countReferencingInstances(ctx, server.Name)
+	instanceCount, err := r.countReferencingInstances(ctx, &server)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("counting referencing instances: %w", err)
 	}
@@ -142,11 +165,15 @@ func (r *KlausMCPServerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		Message:            readyMessage,
 	})
 
-	if err := r.Status().Update(ctx, &server); err != nil {
+	// The endpoint is probed in the background; the reconcile records the
+	// last result.
+	probeIn := r.probeMCPServer(ctx, &server)
+
+	if err := patchObjectStatus(ctx, r.Client, r.APIReader, &server); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: probeIn}, nil
 }
 
 // validateSpec performs basic validation on the KlausMCPServer spec.
@@ -167,6 +194,9 @@ func (r *KlausMCPServerReconciler) validateSpec(server *klausv1alpha1.KlausMCPSe
 	default:
 		return fmt.Errorf("unsupported server type %q (valid types: streamable-http, sse, http, stdio)", server.Spec.Type)
 	}
+	if server.Spec.OCIRef != "" && server.Spec.Type != "stdio" {
+		return fmt.Errorf("spec.ociRef is only supported for type %q", "stdio")
+	}
 
 	return nil
 }
@@ -185,13 +215,13 @@ func (r *KlausMCPServerReconciler) validateSecrets(ctx context.Context, server *
 	return nil
 }
 
-// countReferencingInstances counts KlausInstance resources that reference this
-// MCP server by name using the MCPServerRefIndexField field indexer.
-func (r *KlausMCPServerReconciler) countReferencingInstances(ctx context.Context, serverName string) (int, error) {
+// countReferencingInstances counts KlausInstance resources in any namespace
+// that reference this MCP server using the MCPServerRefIndexField field
+// indexer.
+func (r *KlausMCPServerReconciler) countReferencingInstances(ctx context.Context, server *klausv1alpha1.KlausMCPServer) (int, error) {
 	var instanceList klausv1alpha1.KlausInstanceList
 	if err := r.List(ctx, &instanceList,
-		client.InNamespace(r.OperatorNamespace),
-		client.MatchingFields{MCPServerRefIndexField: serverName},
+		client.MatchingFields{MCPServerRefIndexField: mcpServerIndexValue(client.ObjectKeyFromObject(server))},
 	); err != nil {
 		return 0, err
 	}
@@ -199,18 +229,25 @@ func (r *KlausMCPServerReconciler) countReferencingInstances(ctx context.Context
 }
 
 // SetupWithManager sets up the controller with the Manager.
-// Watches KlausInstance changes to update instance counts and Secret changes
-// to re-validate secret references (e.g., when a missing Secret is created).
+// Watches KlausInstance spec changes to update instance counts and Secret
+// changes to re-validate secret references (e.g., when a missing Secret is
+// created), and finished probes to record their result. Instance status
+// updates are ignored.
 func (r *KlausMCPServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&klausv1alpha1.KlausMCPServer{}).
+		For(&klausv1alpha1.KlausMCPServer{},
+			builder.WithPredicates(primaryPredicate())).
 		Watches(&klausv1alpha1.KlausInstance{},
 			handler.EnqueueRequestsFromMapFunc(r.mapInstanceToMCPServers),
+			builder.WithPredicates(specChangedPredicate()),
 		).
 		Watches(&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.mapSecretToMCPServers),
+			builder.WithPredicates(childChangedPredicate()),
 		).
-		Named("klausmcpserver").
+		WatchesRawSource(source.Channel(r.probes.events(), &handler.EnqueueRequestForObject{})).
+		Named(ControllerNameKlausMCPServer).
+		WithOptions(r.ControllerOptions.For(ControllerNameKlausMCPServer)).
 		Complete(r)
 }
 
@@ -224,12 +261,7 @@ func (r *KlausMCPServerReconciler) mapInstanceToMCPServers(_ context.Context, ob
 
 	var requests []reconcile.Request
 	for _, ref := range instance.Spec.MCPServers {
-		requests = append(requests, reconcile.Request{
-			NamespacedName: types.NamespacedName{
-				Name:      ref.Name,
-				Namespace: instance.Namespace,
-			},
-		})
+		requests = append(requests, reconcile.Request{NamespacedName: mcpServerRefKey(instance.Namespace, ref)})
 	}
 	return requests
 }
@@ -243,13 +275,9 @@ func (r *KlausMCPServerReconciler) mapSecretToMCPServers(ctx context.Context, ob
 		return nil
 	}
 
-	// Only consider Secrets in the operator namespace.
-	if secret.Namespace != r.OperatorNamespace {
-		return nil
-	}
-
+	// Servers reference Secrets in their own namespace.
 	var serverList klausv1alpha1.KlausMCPServerList
-	if err := r.List(ctx, &serverList, client.InNamespace(r.OperatorNamespace)); err != nil {
+	if err := r.List(ctx, &serverList, client.InNamespace(secret.Namespace)); err != nil {
 		return nil
 	}
 
@@ -271,9 +299,10 @@ func (r *KlausMCPServerReconciler) mapSecretToMCPServers(ctx context.Context, ob
 }
 
 // EnqueueReferencingMCPServerInstances returns reconcile requests for all
-// KlausInstance resources that reference the given MCP server. Uses the
-// MCPServerRefIndexField field indexer for efficient lookups.
-func EnqueueReferencingMCPServerInstances(c client.Client, operatorNamespace string) handler.MapFunc {
+// KlausInstance resources that reference the given MCP server, from any
+// namespace. Uses the MCPServerRefIndexField field indexer for efficient
+// lookups.
+func EnqueueReferencingMCPServerInstances(c client.Client) handler.MapFunc {
 	return func(ctx context.Context, obj client.Object) []reconcile.Request {
 		server, ok := obj.(*klausv1alpha1.KlausMCPServer)
 		if !ok {
@@ -282,8 +311,7 @@ func EnqueueReferencingMCPServerInstances(c client.Client, operatorNamespace str
 
 		var instanceList klausv1alpha1.KlausInstanceList
 		if err := c.List(ctx, &instanceList,
-			client.InNamespace(operatorNamespace),
-			client.MatchingFields{MCPServerRefIndexField: server.Name},
+			client.MatchingFields{MCPServerRefIndexField: mcpServerIndexValue(client.ObjectKeyFromObject(server))},
 		); err != nil {
 			return nil
 		}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// SkillPackConditionReady indicates the skill pack is valid and usable.
+const SkillPackConditionReady = "Ready"
+
+// maxSkillPackStatusInstances bounds status.instances of a KlausSkillPack.
+const maxSkillPackStatusInstances = 50
+
+// SkillPackRefIndexField is the field path used by the field indexer to look
+// up KlausInstance resources by referenced KlausSkillPack.
+const SkillPackRefIndexField = "spec.skillPacks.name"
+
+// IndexSkillPackRefs extracts the KlausSkillPacks referenced by a
+// KlausInstance and its inline personality for the field indexer, as
+// namespace/name.
+func IndexSkillPackRefs(obj client.Object) []string {
+	instance, ok := obj.(*klausv1alpha1.KlausInstance)
+	if !ok {
+		return nil
+	}
+	names := resources.SkillPackNames(instance)
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, types.NamespacedName{Namespace: instance.Namespace, Name: name}.String())
+	}
+	return keys
+}
+
+// KlausSkillPackReconciler reconciles a KlausSkillPack object.
+type KlausSkillPackReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// APIReader is an uncached reader the status is re-read from on a
+	// conflict. The cached client is used when nil.
+	APIReader client.Reader
+
+	// ControllerOptions tunes the work queue of the controller.
+	ControllerOptions ControllerOptions
+}
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausskillpacks,verbs=get;list;watch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausskillpacks/status,verbs=get;update;patch
+
+// Reconcile validates a KlausSkillPack and records the instances
+// referencing it.
+func (r *KlausSkillPackReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx)
+
+	var pack klausv1alpha1.KlausSkillPack
+	if err := r.Get(ctx, req.NamespacedName, &pack); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	ctx = withStatusBase(ctx, &pack)
+
+	logger.Info("reconciling KlausSkillPack", "name", pack.Name)
+
+	// Validation errors are permanent until the spec changes, so they are
+	// recorded without requeuing.
+	ready := metav1.Condition{
+		Type:               SkillPackConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: pack.Generation,
+		Reason:             "Reconciled",
+		Message:            "Skill pack is ready",
+	}
+	if err := resources.ValidateSkillPack(&pack.Spec); err != nil {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "ValidationError"
+		ready.Message = err.Error()
+		r.Recorder.Event(&pack, corev1.EventTypeWarning, "ValidationError", err.Error())
+	}
+	apimeta.SetStatusCondition(&pack.Status.Conditions, ready)
+
+	// A transient error here would reset the instances in the status, so
+	// the error is returned to requeue.
+	instances, err := r.referencingInstances(ctx, &pack)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing referencing instances: %w", err)
+	}
+	pack.Status.InstanceCount = len(instances)
+	pack.Status.Instances = instances[:min(len(instances), maxSkillPackStatusInstances)]
+	pack.Status.ObservedGeneration = pack.Generation
+
+	return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, &pack)
+}
+
+// referencingInstances returns the sorted names of the KlausInstances
+// referencing the pack, using the SkillPackRefIndexField field indexer.
+func (r *KlausSkillPackReconciler) referencingInstances(ctx context.Context, pack *klausv1alpha1.KlausSkillPack) ([]string, error) {
+	var instanceList klausv1alpha1.KlausInstanceList
+	if err := r.List(ctx, &instanceList,
+		client.MatchingFields{SkillPackRefIndexField: client.ObjectKeyFromObject(pack).String()},
+	); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(instanceList.Items))
+	for _, instance := range instanceList.Items {
+		names = append(names, instance.Name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. It watches
+// KlausInstance spec changes to update the referencing instances; their
+// status updates are ignored.
+func (r *KlausSkillPackReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&klausv1alpha1.KlausSkillPack{},
+			builder.WithPredicates(primaryPredicate())).
+		Watches(&klausv1alpha1.KlausInstance{},
+			handler.EnqueueRequestsFromMapFunc(mapInstanceToSkillPacks),
+			builder.WithPredicates(specChangedPredicate()),
+		).
+		Named(ControllerNameKlausSkillPack).
+		WithOptions(r.ControllerOptions.For(ControllerNameKlausSkillPack)).
+		Complete(r)
+}
+
+// mapInstanceToSkillPacks maps a KlausInstance to the KlausSkillPacks it
+// references.
+func mapInstanceToSkillPacks(_ context.Context, obj client.Object) []reconcile.Request {
+	instance, ok := obj.(*klausv1alpha1.KlausInstance)
+	if !ok {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, name := range resources.SkillPackNames(instance) {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: instance.Namespace},
+		})
+	}
+	return requests
+}
+
+// EnqueueReferencingSkillPackInstances returns a map function that enqueues
+// the KlausInstances referencing a KlausSkillPack, so they render its
+// changed content.
+func EnqueueReferencingSkillPackInstances(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var instanceList klausv1alpha1.KlausInstanceList
+		if err := c.List(ctx, &instanceList,
+			client.MatchingFields{SkillPackRefIndexField: client.ObjectKeyFromObject(obj).String()},
+		); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, inst := range instanceList.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: inst.Name, Namespace: inst.Namespace},
+			})
+		}
+		return requests
+	}
+}
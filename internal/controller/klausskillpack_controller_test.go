@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testSkillPack(name string) *klausv1alpha1.KlausSkillPack {
+	return &klausv1alpha1.KlausSkillPack{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system", Generation: 1},
+		Spec: klausv1alpha1.KlausSkillPackSpec{
+			Skills:   map[string]klausv1alpha1.SkillConfig{"review": {Content: "Review the change."}},
+			Commands: map[string]klausv1alpha1.CommandConfig{"triage": {Content: "Triage $ARGUMENTS."}},
+		},
+	}
+}
+
+func skillPackInstance(name string, packs ...string) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance(name, testOwner)
+	for _, pack := range packs {
+		instance.Spec.SkillPacks = append(instance.Spec.SkillPacks, klausv1alpha1.SkillPackReference{Name: pack})
+	}
+	return instance
+}
+
+func skillPackTestClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	return fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(objs...).
+		WithStatusSubresource(&klausv1alpha1.KlausSkillPack{}).
+		WithIndex(&klausv1alpha1.KlausInstance{}, SkillPackRefIndexField, IndexSkillPackRefs).
+		Build()
+}
+
+func TestKlausSkillPackReconcile(t *testing.T) {
+	pack := testSkillPack("review")
+	c := skillPackTestClient(t, pack,
+		skillPackInstance("writer", "review"),
+		skillPackInstance("agent", "go", "review"),
+		skillPackInstance("unrelated", "go"),
+	)
+	r := &KlausSkillPackReconciler{Client: c, Recorder: record.NewFakeRecorder(10)}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pack)}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	var got klausv1alpha1.KlausSkillPack
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(pack), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.InstanceCount != 2 || strings.Join(got.Status.Instances, ",") != "agent,writer" {
+		t.Errorf("instances = %d %v, want agent and writer", got.Status.InstanceCount, got.Status.Instances)
+	}
+	if !apimeta.IsStatusConditionTrue(got.Status.Conditions, SkillPackConditionReady) || got.Status.ObservedGeneration != 1 {
+		t.Errorf("status = %+v, want ready at generation 1", got.Status)
+	}
+}
+
+func TestKlausSkillPackReconcile_ValidationError(t *testing.T) {
+	pack := testSkillPack("review")
+	pack.Spec.Skills = map[string]klausv1alpha1.SkillConfig{"review": {}}
+	c := skillPackTestClient(t, pack)
+	recorder := record.NewFakeRecorder(10)
+	r := &KlausSkillPackReconciler{Client: c, Recorder: recorder}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pack)}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	var got klausv1alpha1.KlausSkillPack
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(pack), &got); err != nil {
+		t.Fatal(err)
+	}
+	ready := apimeta.FindStatusCondition(got.Status.Conditions, SkillPackConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != "ValidationError" ||
+		!strings.Contains(ready.Message, `skill "review" has no content`) {
+		t.Errorf("ready = %+v, want a validation error", ready)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("events = %d, want a warning", len(recorder.Events))
+	}
+}
+
+func TestResolveSkillPacks(t *testing.T) {
+	c := skillPackTestClient(t, testSkillPack("review"))
+	r := &KlausInstanceReconciler{Client: c}
+	instance := skillPackInstance("agent", "review")
+	instance.Spec.Skills = map[string]klausv1alpha1.SkillConfig{"review": {Content: "Own review."}}
+
+	if err := r.resolveSkillPacks(context.Background(), instance); err != nil {
+		t.Fatalf("resolveSkillPacks: %v", err)
+	}
+	if instance.Spec.Skills["review"].Content != "Own review." || instance.Spec.Commands["triage"].Content == "" {
+		t.Errorf("skills = %v, commands = %v, want the own skill and the pack command", instance.Spec.Skills, instance.Spec.Commands)
+	}
+
+	instance = skillPackInstance("agent", "missing")
+	if err := r.resolveSkillPacks(context.Background(), instance); !apierrors.IsNotFound(err) {
+		t.Errorf("error = %v, want not found", err)
+	}
+}
+
+func TestResolveSkillPacks_Invalid(t *testing.T) {
+	pack := testSkillPack("review")
+	pack.Spec.Commands = map[string]klausv1alpha1.CommandConfig{"bad name": {Content: "x"}}
+	r := &KlausInstanceReconciler{Client: skillPackTestClient(t, pack)}
+
+	err := r.resolveSkillPacks(context.Background(), skillPackInstance("agent", "review"))
+	if err == nil || !strings.Contains(err.Error(), `skill pack "review"`) {
+		t.Errorf("error = %v, want the invalid skill pack", err)
+	}
+}
+
+func TestEnqueueReferencingSkillPackInstances(t *testing.T) {
+	personality := skillPackInstance("personality")
+	personality.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{
+		SkillPacks: []klausv1alpha1.SkillPackReference{{Name: "review"}},
+	}
+	c := skillPackTestClient(t, skillPackInstance("agent", "review"), personality, skillPackInstance("other", "go"))
+
+	requests := EnqueueReferencingSkillPackInstances(c)(context.Background(), testSkillPack("review"))
+	var names []string
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"agent", "personality"}) {
+		t.Errorf("requests = %v, want agent and personality", requests)
+	}
+
+	if got := mapInstanceToSkillPacks(context.Background(), personality); len(got) != 1 ||
+		got[0].NamespacedName != (types.NamespacedName{Name: "review", Namespace: "klaus-system"}) {
+		t.Errorf("mapInstanceToSkillPacks() = %v, want the review pack", got)
+	}
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// TriggerConditionReady indicates whether a KlausTrigger accepts events.
+const TriggerConditionReady = "Ready"
+
+// KlausTriggerReconciler reconciles a KlausTrigger object by validating its
+// spec and references and pruning the KlausJobs it created. Events are
+// received by the trigger listener.
+type KlausTriggerReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// OperatorNamespace is the namespace of the instances triggers prompt.
+	OperatorNamespace string
+
+	// ControllerOptions tunes the work queue of the controller.
+	ControllerOptions ControllerOptions
+}
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustriggers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustriggers/status,verbs=get;update;patch
+
+// Reconcile handles KlausTrigger create/update events.
+func (r *KlausTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var trigger klausv1alpha1.KlausTrigger
+	if err := r.Get(ctx, req.NamespacedName, &trigger); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !trigger.DeletionTimestamp.IsZero() {
+		// Created jobs are owned by the trigger and garbage collected with it.
+		return ctrl.Result{}, nil
+	}
+
+	if job := trigger.Spec.Job; job != nil {
+		var jobs klausv1alpha1.KlausJobList
+		if err := r.List(ctx, &jobs,
+			client.InNamespace(trigger.Namespace),
+			client.MatchingLabels{resources.LabelTrigger: trigger.Name},
+		); err != nil {
+			return ctrl.Result{}, err
+		}
+		_, succeeded, failed := classifyRuns(jobs.Items)
+		if err := pruneHistory(ctx, r.Client, succeeded, historyLimit(job.SuccessfulJobsHistoryLimit, defaultSuccessfulJobsHistoryLimit)); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := pruneHistory(ctx, r.Client, failed, historyLimit(job.FailedJobsHistoryLimit, defaultFailedJobsHistoryLimit)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	status, reason, message := metav1.ConditionTrue, "Ready", "Accepting events on "+resources.TriggerPath(&trigger)
+	if err := r.validate(ctx, &trigger); err != nil {
+		status, reason, message = metav1.ConditionFalse, "Invalid", err.Error()
+	} else if trigger.Spec.Suspend {
+		status, reason, message = metav1.ConditionFalse, "Suspended", "Events are rejected while suspended"
+	}
+	return ctrl.Result{}, r.updateStatus(ctx, &trigger, status, reason, message)
+}
+
+// validate checks the spec, the Secret and, for instance actions, that the
+// instance exists and is owned by the trigger owner.
+func (r *KlausTriggerReconciler) validate(ctx context.Context, trigger *klausv1alpha1.KlausTrigger) error {
+	if err := resources.ValidateTriggerSpec(trigger); err != nil {
+		return err
+	}
+
+	var secret corev1.Secret
+	ref := trigger.Spec.Source.SecretRef
+	if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: trigger.Namespace}, &secret); err != nil {
+		return fmt.Errorf("secret %q: %w", ref.Name, err)
+	}
+	if len(secret.Data[resources.TriggerSecretKey(trigger)]) == 0 {
+		return fmt.Errorf("secret %q has no key %q", ref.Name, resources.TriggerSecretKey(trigger))
+	}
+
+	if action := trigger.Spec.Instance; action != nil {
+		var instance klausv1alpha1.KlausInstance
+		if err := r.Get(ctx, types.NamespacedName{Name: action.Name, Namespace: r.OperatorNamespace}, &instance); err != nil {
+			return fmt.Errorf("instance %q: %w", action.Name, err)
+		}
+		if !resources.IsOwnedBy(&instance, trigger.Spec.Owner, nil) {
+			return fmt.Errorf("instance %q is not owned by %s", action.Name, trigger.Spec.Owner)
+		}
+	}
+	return nil
+}
+
+func (r *KlausTriggerReconciler) updateStatus(ctx context.Context, trigger *klausv1alpha1.KlausTrigger, status metav1.ConditionStatus, reason, message string) error {
+	base := trigger.DeepCopy()
+	trigger.Status.Path = resources.TriggerPath(trigger)
+	trigger.Status.ObservedGeneration = trigger.Generation
+	apimeta.SetStatusCondition(&trigger.Status.Conditions, metav1.Condition{
+		Type:               TriggerConditionReady,
+		Status:             status,
+		ObservedGeneration: trigger.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	// Patch rather than update: the trigger listener records the last event
+	// in the same status concurrently.
+	return r.Status().Patch(ctx, trigger, client.MergeFrom(base))
+}
+
+// SetupWithManager sets up the controller with the Manager. Secret changes
+// re-validate the triggers referencing them, e.g. when a missing Secret is
+// created.
+func (r *KlausTriggerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&klausv1alpha1.KlausTrigger{},
+			builder.WithPredicates(primaryPredicate())).
+		Owns(&klausv1alpha1.KlausJob{},
+			builder.WithPredicates(childChangedPredicate())).
+		Watches(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretToTriggers),
+			builder.WithPredicates(childChangedPredicate()),
+		).
+		Named(ControllerNameKlausTrigger).
+		WithOptions(r.ControllerOptions.For(ControllerNameKlausTrigger)).
+		Complete(r)
+}
+
+// mapSecretToTriggers maps a Secret to the KlausTriggers in its namespace
+// referencing it.
+func (r *KlausTriggerReconciler) mapSecretToTriggers(ctx context.Context, obj client.Object) []reconcile.Request {
+	var triggers klausv1alpha1.KlausTriggerList
+	if err := r.List(ctx, &triggers, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, trigger := range triggers.Items {
+		if trigger.Spec.Source.SecretRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: trigger.Name, Namespace: trigger.Namespace},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func newTrigger(name string) *klausv1alpha1.KlausTrigger {
+	return &klausv1alpha1.KlausTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Generation: 1},
+		Spec: klausv1alpha1.KlausTriggerSpec{
+			Owner: "user@example.com",
+			Source: klausv1alpha1.TriggerSource{
+				Type:      klausv1alpha1.TriggerSourceWebhook,
+				SecretRef: klausv1alpha1.TriggerSecretReference{Name: "trigger-token"},
+			},
+			Job: &klausv1alpha1.TriggerJobAction{JobTemplate: klausv1alpha1.KlausJobSpec{
+				Owner:  "user@example.com",
+				Prompt: "Investigate",
+			}},
+		},
+	}
+}
+
+func triggerSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "trigger-token", Namespace: "team-a"},
+		Data:       map[string][]byte{"token": []byte("s3cret")},
+	}
+}
+
+func reconcileTrigger(t *testing.T, objs ...client.Object) (client.Client, *klausv1alpha1.KlausTrigger) {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(objs...).
+		WithStatusSubresource(&klausv1alpha1.KlausTrigger{}).
+		Build()
+	r := &KlausTriggerReconciler{Client: c, Scheme: c.Scheme(), OperatorNamespace: "klaus-system"}
+
+	key := types.NamespacedName{Name: "alerts", Namespace: "team-a"}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	var trigger klausv1alpha1.KlausTrigger
+	if err := c.Get(context.Background(), key, &trigger); err != nil {
+		t.Fatalf("failed to get trigger: %v", err)
+	}
+	return c, &trigger
+}
+
+func TestKlausTriggerReconcile_Ready(t *testing.T) {
+	_, trigger := reconcileTrigger(t, newTrigger("alerts"), triggerSecret())
+
+	cond := apimeta.FindStatusCondition(trigger.Status.Conditions, TriggerConditionReady)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("Ready condition = %+v, want true", cond)
+	}
+	if trigger.Status.Path != "/triggers/team-a/alerts" {
+		t.Errorf("path = %q", trigger.Status.Path)
+	}
+	if trigger.Status.ObservedGeneration != 1 {
+		t.Errorf("observedGeneration = %d, want 1", trigger.Status.ObservedGeneration)
+	}
+}
+
+func TestKlausTriggerReconcile_Invalid(t *testing.T) {
+	notOwned := newTrigger("alerts")
+	notOwned.Spec.Job = nil
+	notOwned.Spec.PromptTemplate = "Check {{ .payload.commonLabels.alertname }}"
+	notOwned.Spec.Instance = &klausv1alpha1.TriggerInstanceAction{Name: "on-call"}
+	otherOwner := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "on-call", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "other@example.com"},
+	}
+
+	tests := []struct {
+		name string
+		objs []client.Object
+		want string
+	}{
+		{name: "missing secret", objs: []client.Object{newTrigger("alerts")}, want: "trigger-token"},
+		{name: "instance of another owner", objs: []client.Object{notOwned, triggerSecret(), otherOwner}, want: "not owned"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, trigger := reconcileTrigger(t, tt.objs...)
+			cond := apimeta.FindStatusCondition(trigger.Status.Conditions, TriggerConditionReady)
+			if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "Invalid" {
+				t.Fatalf("Ready condition = %+v, want Invalid", cond)
+			}
+			if !strings.Contains(cond.Message, tt.want) {
+				t.Errorf("message = %q, want %q", cond.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestKlausTriggerReconcile_Suspended(t *testing.T) {
+	trigger := newTrigger("alerts")
+	trigger.Spec.Suspend = true
+	_, trigger = reconcileTrigger(t, trigger, triggerSecret())
+
+	cond := apimeta.FindStatusCondition(trigger.Status.Conditions, TriggerConditionReady)
+	if cond == nil || cond.Reason != "Suspended" {
+		t.Errorf("Ready condition = %+v, want Suspended", cond)
+	}
+}
+
+func TestKlausTriggerReconcile_PrunesHistory(t *testing.T) {
+	trigger := newTrigger("alerts")
+	trigger.Spec.Job.SuccessfulJobsHistoryLimit = ptr.To(int32(1))
+	base := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	var objs []client.Object
+	for i, name := range []string{"alerts-a", "alerts-b", "alerts-c"} {
+		objs = append(objs, &klausv1alpha1.KlausJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a", Labels: map[string]string{resources.LabelTrigger: "alerts"}},
+			Spec:       trigger.Spec.Job.JobTemplate,
+			Status: klausv1alpha1.KlausJobStatus{
+				State:          klausv1alpha1.JobStateSucceeded,
+				CompletionTime: &metav1.Time{Time: base.Add(time.Duration(i) * time.Hour)},
+			},
+		})
+	}
+	c, _ := reconcileTrigger(t, append(objs, trigger, triggerSecret())...)
+
+	var jobs klausv1alpha1.KlausJobList
+	if err := c.List(context.Background(), &jobs, client.InNamespace("team-a")); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 1 || jobs.Items[0].Name != "alerts-c" {
+		t.Errorf("remaining jobs = %v, want only the newest", jobs.Items)
+	}
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// reconcileKubernetesAccess maintains the RoleBinding granting the instance
+// ServiceAccount the ClusterRole of spec.kubernetesAccess, which is removed
+// when the instance no longer has Kubernetes API access or a ClusterRole.
+// Only the ClusterRoles in KubernetesAccessClusterRoles are granted: the
+// operator holds bind on them, and copying rules from the spec would let
+// any owner obtain whatever the operator may do.
+func (r *KlausInstanceReconciler) reconcileKubernetesAccess(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) error {
+	binding := resources.BuildKubernetesAccessRoleBinding(merged, namespace)
+	if binding == nil {
+		return r.deleteKubernetesAccess(ctx, resources.KubernetesAccessBindingName(merged), namespace)
+	}
+	if !slices.Contains(r.KubernetesAccessClusterRoles, binding.RoleRef.Name) {
+		allowed := "none"
+		if len(r.KubernetesAccessClusterRoles) > 0 {
+			allowed = strings.Join(r.KubernetesAccessClusterRoles, ", ")
+		}
+		return fmt.Errorf("spec.kubernetesAccess.clusterRole %q is not a ClusterRole the operator may grant (allowed: %s)", binding.RoleRef.Name, allowed)
+	}
+
+	// The role of a RoleBinding cannot change: replace bindings of another
+	// ClusterRole.
+	existing := &rbacv1.RoleBinding{}
+	err := r.Get(ctx, types.NamespacedName{Name: binding.Name, Namespace: namespace}, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("reading Kubernetes access RoleBinding: %w", err)
+	}
+	if err == nil && existing.RoleRef != binding.RoleRef {
+		if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("replacing Kubernetes access RoleBinding: %w", err)
+		}
+	}
+
+	desired := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: binding.Name, Namespace: namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, desired, func() error {
+		desired.RoleRef = binding.RoleRef
+		desired.Subjects = binding.Subjects
+		desired.Labels = binding.Labels
+		return r.setInstanceOwner(instance, desired)
+	})
+	if err != nil {
+		return fmt.Errorf("reconciling Kubernetes access RoleBinding: %w", err)
+	}
+	if op == controllerutil.OperationResultCreated {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingRoleBinding",
+			fmt.Sprintf("Granted ClusterRole %s to the instance ServiceAccount", binding.RoleRef.Name))
+	}
+	return nil
+}
+
+// deleteKubernetesAccess removes the RoleBinding of an instance that no
+// longer has Kubernetes API access.
+func (r *KlausInstanceReconciler) deleteKubernetesAccess(ctx context.Context, name, namespace string) error {
+	binding := &rbacv1.RoleBinding{}
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, binding)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := r.Delete(ctx, binding); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("deleting Kubernetes access RoleBinding: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestReconcileKubernetesAccess(t *testing.T) {
+	ctx := context.Background()
+	r := ownerRBACTestReconciler(t)
+	r.KubernetesAccessClusterRoles = []string{"klaus-pod-reader", "view"}
+	c := r.Client
+	instance := pdbTestInstance(nil)
+	instance.Spec.KubernetesAccess = &klausv1alpha1.KubernetesAccess{Enabled: true, ClusterRole: "klaus-pod-reader"}
+	key := types.NamespacedName{Name: "my-agent", Namespace: schedulingTestNamespace}
+
+	if err := r.reconcileKubernetesAccess(ctx, instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcileKubernetesAccess() error = %v", err)
+	}
+	var binding rbacv1.RoleBinding
+	if err := c.Get(ctx, key, &binding); err != nil || binding.Subjects[0].Name != "my-agent" || binding.RoleRef.Name != "klaus-pod-reader" {
+		t.Fatalf("binding = %+v, err = %v; want the ClusterRole bound to the ServiceAccount", binding, err)
+	}
+
+	// The role of a binding is immutable: a new ClusterRole replaces it.
+	instance.Spec.KubernetesAccess.ClusterRole = "view"
+	if err := r.reconcileKubernetesAccess(ctx, instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcileKubernetesAccess() error = %v", err)
+	}
+	if err := c.Get(ctx, key, &binding); err != nil || binding.RoleRef.Name != "view" {
+		t.Fatalf("roleRef = %+v, err = %v; want the new ClusterRole", binding.RoleRef, err)
+	}
+
+	instance.Spec.KubernetesAccess.Enabled = false
+	if err := r.reconcileKubernetesAccess(ctx, instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcileKubernetesAccess() error = %v", err)
+	}
+	if err := c.Get(ctx, key, &rbacv1.RoleBinding{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the RoleBinding to be deleted, got err = %v", err)
+	}
+}
+
+func TestReconcileKubernetesAccess_ClusterRoleNotAllowed(t *testing.T) {
+	ctx := context.Background()
+	r := ownerRBACTestReconciler(t)
+	r.KubernetesAccessClusterRoles = []string{"klaus-pod-reader"}
+	instance := pdbTestInstance(nil)
+	instance.Spec.KubernetesAccess = &klausv1alpha1.KubernetesAccess{Enabled: true, ClusterRole: "cluster-admin"}
+
+	err := r.reconcileKubernetesAccess(ctx, instance, instance, schedulingTestNamespace)
+	if err == nil || !strings.Contains(err.Error(), "klaus-pod-reader") {
+		t.Fatalf("reconcileKubernetesAccess() error = %v, want cluster-admin refused", err)
+	}
+	key := types.NamespacedName{Name: "my-agent", Namespace: schedulingTestNamespace}
+	if err := r.Client.Get(ctx, key, &rbacv1.RoleBinding{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no RoleBinding, got err = %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// ManagedCacheByObject returns cache options restricting the Secret and
+// ConfigMap informers to the objects labeled as managed by the operator.
+// Large clusters hold many unrelated Secrets and ConfigMaps, which would
+// otherwise all be cached in the operator's memory. Use the manager client
+// from NewManagedCacheClient so the others can still be read.
+func ManagedCacheByObject() map[client.Object]cache.ByObject {
+	selector := labels.SelectorFromSet(labels.Set{resources.LabelManagedBy: resources.AppKlausOperator})
+	return map[client.Object]cache.ByObject{
+		&corev1.Secret{}:    {Label: selector},
+		&corev1.ConfigMap{}: {Label: selector},
+	}
+}
+
+// NewManagedCacheClient is a client.NewClientFunc for a cache restricted by
+// ManagedCacheByObject. Secrets and ConfigMaps missing from the cache, like
+// the Anthropic API key or the Secrets referenced by KlausMCPServers, are
+// read from the API server, as are lists of them that do not select
+// managed objects.
+func NewManagedCacheClient(config *rest.Config, options client.Options) (client.Client, error) {
+	cached, err := client.New(config, options)
+	if err != nil {
+		return nil, err
+	}
+	live, err := client.New(config, client.Options{
+		HTTPClient: options.HTTPClient,
+		Scheme:     options.Scheme,
+		Mapper:     options.Mapper,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &managedCacheClient{Client: cached, live: live}, nil
+}
+
+// managedCacheClient falls back to an uncached reader for the Secrets and
+// ConfigMaps not held by a cache restricted to managed objects.
+type managedCacheClient struct {
+	client.Client
+	live client.Reader
+}
+
+func (c *managedCacheClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	err := c.Client.Get(ctx, key, obj, opts...)
+	if apierrors.IsNotFound(err) && isManagedCacheObject(obj) {
+		return c.live.Get(ctx, key, obj, opts...)
+	}
+	return err
+}
+
+func (c *managedCacheClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if isManagedCacheList(list) && !selectsManagedObjects(opts) {
+		return c.live.List(ctx, list, opts...)
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func isManagedCacheObject(obj client.Object) bool {
+	switch obj.(type) {
+	case *corev1.Secret, *corev1.ConfigMap:
+		return true
+	}
+	return false
+}
+
+func isManagedCacheList(list client.ObjectList) bool {
+	switch list.(type) {
+	case *corev1.SecretList, *corev1.ConfigMapList:
+		return true
+	}
+	return false
+}
+
+// selectsManagedObjects reports whether the list options only select
+// objects labeled as managed by the operator, which the cache holds.
+func selectsManagedObjects(opts []client.ListOption) bool {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	if listOpts.LabelSelector == nil {
+		return false
+	}
+	value, ok := listOpts.LabelSelector.RequiresExactMatch(resources.LabelManagedBy)
+	return ok && value == resources.AppKlausOperator
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestManagedCacheClient(t *testing.T) {
+	managed := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name: "my-agent-api-key", Namespace: "klaus-user-user",
+		Labels: map[string]string{resources.LabelManagedBy: resources.AppKlausOperator},
+	}}
+	userSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "anthropic-api-key", Namespace: "klaus-system"}}
+
+	// The cache only holds managed objects, the API server all of them.
+	scheme := testScheme(t)
+	cached := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managed.DeepCopy()).Build()
+	live := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managed.DeepCopy(), userSecret).Build()
+	c := &managedCacheClient{Client: cached, live: live}
+	ctx := context.Background()
+
+	var secret corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "klaus-system", Name: "anthropic-api-key"}, &secret); err != nil {
+		t.Errorf("Get() of an unmanaged Secret: %v, want it read from the API server", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "klaus-system", Name: "missing"}, &secret); err == nil {
+		t.Error("Get() of a missing Secret succeeded")
+	}
+
+	var all corev1.SecretList
+	if err := c.List(ctx, &all); err != nil || len(all.Items) != 2 {
+		t.Errorf("List() = %d Secrets, %v; want both from the API server", len(all.Items), err)
+	}
+	var owned corev1.SecretList
+	if err := c.List(ctx, &owned, client.MatchingLabels{resources.LabelManagedBy: resources.AppKlausOperator}); err != nil ||
+		len(owned.Items) != 1 {
+		t.Errorf("List(managed) = %d Secrets, %v; want the cached one", len(owned.Items), err)
+	}
+}
+
+func TestManagedCacheByObject(t *testing.T) {
+	byObject := ManagedCacheByObject()
+	if len(byObject) != 2 {
+		t.Fatalf("got %d restricted kinds, want Secrets and ConfigMaps", len(byObject))
+	}
+	for obj, opts := range byObject {
+		if opts.Label == nil || opts.Label.String() != resources.LabelManagedBy+"="+resources.AppKlausOperator {
+			t.Errorf("%T selector = %v, want managed-by", obj, opts.Label)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// mcpServerRefKey returns the KlausMCPServer referenced by ref from an
+// instance in namespace: ref.Namespace, or the instance's own namespace
+// when unset.
+func mcpServerRefKey(namespace string, ref klausv1alpha1.MCPServerReference) types.NamespacedName {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
+
+// mcpServerIndexValue is the MCPServerRefIndexField value of a reference to
+// the KlausMCPServer at key.
+func mcpServerIndexValue(key types.NamespacedName) string {
+	return key.String()
+}
+
+// checkMCPServerNamespace returns an error unless an instance in namespace
+// may reference the KlausMCPServer at key. Servers in the instance's own
+// namespace can always be referenced, others only from the namespaces in
+// allowed.
+func checkMCPServerNamespace(allowed []string, namespace string, key types.NamespacedName) error {
+	if key.Namespace == namespace || slices.Contains(allowed, key.Namespace) {
+		return nil
+	}
+	return fmt.Errorf("KlausMCPServer %s: namespace %q is not in the allowed MCP server namespaces", key, key.Namespace)
+}
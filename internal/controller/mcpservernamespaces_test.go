@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestIndexMCPServerRefs_Namespaces(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{MCPServers: []klausv1alpha1.MCPServerReference{
+			{Name: "local"},
+			{Name: "github", Namespace: "mcp-catalog"},
+		}},
+	}
+	got := strings.Join(IndexMCPServerRefs(instance), ",")
+	if got != "klaus-system/local,mcp-catalog/github" {
+		t.Errorf("index values = %s, want namespace/name of each server", got)
+	}
+}
+
+func TestCheckMCPServerNamespace(t *testing.T) {
+	allowed := []string{"mcp-catalog"}
+	for key, wantErr := range map[types.NamespacedName]bool{
+		{Namespace: "klaus-system", Name: "local"}:  false,
+		{Namespace: "mcp-catalog", Name: "github"}:  false,
+		{Namespace: "team-a", Name: "private"}:      true,
+		{Namespace: "kube-system", Name: "servers"}: true,
+	} {
+		if err := checkMCPServerNamespace(allowed, "klaus-system", key); (err != nil) != wantErr {
+			t.Errorf("checkMCPServerNamespace(%s) error = %v, want error %t", key, err, wantErr)
+		}
+	}
+}
+
+func TestResolveMCPServers_CrossNamespace(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:      "user@example.com",
+			MCPServers: []klausv1alpha1.MCPServerReference{{Name: "github", Namespace: "mcp-catalog"}},
+		},
+	}
+	server := &klausv1alpha1.KlausMCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "mcp-catalog"},
+		Spec: klausv1alpha1.KlausMCPServerSpec{
+			Type:       "streamable-http",
+			URL:        "https://github-mcp.example.com/mcp",
+			SecretRefs: []klausv1alpha1.MCPServerSecret{{SecretName: "github-token", Env: map[string]string{"GITHUB_TOKEN": "token"}}},
+		},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-token", Namespace: "mcp-catalog"},
+		Data:       map[string][]byte{"token": []byte("ghp_catalog")},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance, server, token).Build()
+	r := &KlausInstanceReconciler{Client: c, Scheme: c.Scheme(), OperatorNamespace: "klaus-system"}
+
+	if _, err := r.resolveMCPServers(context.Background(), instance.DeepCopy()); err == nil ||
+		!strings.Contains(err.Error(), "not in the allowed MCP server namespaces") {
+		t.Fatalf("error = %v, want the catalog namespace rejected", err)
+	}
+
+	r.MCPServerNamespaces = []string{"mcp-catalog"}
+	if _, err := r.resolveMCPServers(context.Background(), instance); err != nil {
+		t.Fatalf("resolveMCPServers: %v", err)
+	}
+	if _, ok := instance.Spec.Claude.MCPServers["github"]; !ok {
+		t.Errorf("mcp servers = %v, want the catalog server", instance.Spec.Claude.MCPServers)
+	}
+	var copied corev1.Secret
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "github-token", Namespace: r.childNamespace(instance)}, &copied); err != nil {
+		t.Fatalf("getting copied secret: %v", err)
+	}
+	if string(copied.Data["token"]) != "ghp_catalog" {
+		t.Errorf("copied secret = %v, want the catalog token", copied.Data)
+	}
+}
@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// MCPServerConditionReachable indicates whether the URL of an HTTP-based
+// KlausMCPServer answered the last probe. It does not affect Ready:
+// instances keep referencing servers that are temporarily unreachable.
+const MCPServerConditionReachable = "Reachable"
+
+// DefaultMCPServerProbeInterval is how often the URLs of HTTP-based
+// KlausMCPServers are probed.
+const DefaultMCPServerProbeInterval = time.Minute
+
+// mcpServerProbeTimeout bounds a single probe of an unresponsive endpoint.
+const mcpServerProbeTimeout = 10 * time.Second
+
+// MCPServerProber checks that the endpoint of an HTTP-based MCP server
+// answers.
+type MCPServerProber interface {
+	// Probe returns an error unless the server at url answers.
+	Probe(ctx context.Context, url string) error
+}
+
+// httpMCPServerProber implements MCPServerProber with an HTTP GET.
+type httpMCPServerProber struct {
+	client *http.Client
+}
+
+// NewHTTPMCPServerProber creates an MCPServerProber sending a GET request to
+// the server URL. Any response below 500 counts as reachable: MCP endpoints
+// commonly answer a plain GET with 401 or 405, which still proves that the
+// server is up. The body is not read, so SSE streams are not consumed.
+func NewHTTPMCPServerProber() MCPServerProber {
+	return &httpMCPServerProber{client: &http.Client{Timeout: mcpServerProbeTimeout}}
+}
+
+func (p *httpMCPServerProber) Probe(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("server answered %s", resp.Status)
+	}
+	return nil
+}
+
+// mcpServerProbeResult is the outcome of a probe of an MCP server URL.
+type mcpServerProbeResult struct {
+	at      time.Time
+	latency time.Duration
+	err     error
+}
+
+// mcpServerProbes caches the probe results per URL and runs the probes in
+// the background, so a reconcile never waits for a slow endpoint and
+// servers sharing a URL share its result. A finished probe enqueues the
+// server that started it through events.
+type mcpServerProbes struct {
+	mu       sync.Mutex
+	results  map[string]mcpServerProbeResult
+	inFlight map[string]bool
+	notify   chan event.GenericEvent
+	wg       sync.WaitGroup
+}
+
+// events returns the channel finished probes send the server to reconcile
+// on.
+func (p *mcpServerProbes) events() chan event.GenericEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.notify == nil {
+		p.notify = make(chan event.GenericEvent, 100)
+	}
+	return p.notify
+}
+
+// result returns the last probe result of the URL of server, if any, and
+// starts a probe in the background unless one is in flight or the result is
+// younger than interval. It reports whether a probe is in flight. Results
+// of other URLs not probed for several intervals are dropped.
+func (p *mcpServerProbes) result(ctx context.Context, prober MCPServerProber, server *klausv1alpha1.KlausMCPServer, interval time.Duration) (mcpServerProbeResult, bool, bool) {
+	notify := p.events()
+	url := server.Spec.URL
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.results == nil {
+		p.results = map[string]mcpServerProbeResult{}
+		p.inFlight = map[string]bool{}
+	}
+	for u, res := range p.results {
+		if u != url && now.Sub(res.at) > 3*interval && !p.inFlight[u] {
+			delete(p.results, u)
+		}
+	}
+	res, ok := p.results[url]
+	if p.inFlight[url] || (ok && now.Sub(res.at) < interval) {
+		return res, ok, p.inFlight[url]
+	}
+
+	p.inFlight[url] = true
+	p.wg.Add(1)
+	obj := server.DeepCopy()
+	go func() {
+		defer p.wg.Done()
+		// The probe outlives the reconcile that started it.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mcpServerProbeTimeout)
+		defer cancel()
+		start := time.Now()
+		err := prober.Probe(ctx, url)
+		probed := mcpServerProbeResult{at: start, latency: time.Since(start).Round(time.Millisecond), err: err}
+
+		p.mu.Lock()
+		p.results[url] = probed
+		delete(p.inFlight, url)
+		p.mu.Unlock()
+		select {
+		case notify <- event.GenericEvent{Object: obj}:
+		default:
+			// The reconcile requeued while the probe was in flight
+			// picks the result up.
+		}
+	}()
+	return res, ok, true
+}
+
+// probeMCPServer records the cached probe result of the URL of an
+// HTTP-based server in the Reachable condition and the probe status fields,
+// starting a new probe in the background when the result is older than
+// r.ProbeInterval. It returns when to look at the result again, or 0 when
+// the server is not probed: probing is disabled, the server is stdio, or
+// its URL contains ${VAR} references that are only expanded in the instance
+// pods.
+func (r *KlausMCPServerReconciler) probeMCPServer(ctx context.Context, server *klausv1alpha1.KlausMCPServer) time.Duration {
+	if r.Prober == nil || r.ProbeInterval <= 0 || server.Spec.Type == "stdio" {
+		apimeta.RemoveStatusCondition(&server.Status.Conditions, MCPServerConditionReachable)
+		server.Status.LastProbeTime = nil
+		server.Status.LastProbeLatency = nil
+		return 0
+	}
+	if strings.Contains(server.Spec.URL, "${") {
+		setMCPServerReachable(server, metav1.ConditionUnknown, "NotProbed",
+			"The URL references variables that are only expanded in instance pods")
+		server.Status.LastProbeTime = nil
+		server.Status.LastProbeLatency = nil
+		return 0
+	}
+
+	res, ok, probing := r.probes.result(ctx, r.Prober, server, r.ProbeInterval)
+	next := r.ProbeInterval - time.Since(res.at)
+	if probing {
+		// The probe enqueues the server when it finishes; the requeue
+		// covers a dropped notification.
+		next = mcpServerProbeTimeout
+	}
+	if !ok {
+		if apimeta.FindStatusCondition(server.Status.Conditions, MCPServerConditionReachable) == nil {
+			setMCPServerReachable(server, metav1.ConditionUnknown, "Probing", "The first probe is in flight")
+		}
+		return next
+	}
+
+	server.Status.LastProbeTime = ptr.To(metav1.NewTime(res.at))
+	if res.err != nil {
+		// Only the transition to unreachable is reported as an event.
+		wasUnreachable := apimeta.IsStatusConditionFalse(server.Status.Conditions, MCPServerConditionReachable)
+		setMCPServerReachable(server, metav1.ConditionFalse, "Unreachable", res.err.Error())
+		server.Status.LastProbeLatency = nil
+		if !wasUnreachable {
+			r.Recorder.Event(server, corev1.EventTypeWarning, "Unreachable",
+				fmt.Sprintf("MCP server %s is unreachable: %v", server.Spec.URL, res.err))
+		}
+		return max(next, time.Second)
+	}
+	server.Status.LastProbeLatency = &metav1.Duration{Duration: res.latency}
+	setMCPServerReachable(server, metav1.ConditionTrue, "Reachable", fmt.Sprintf("Answered in %s", res.latency))
+	return max(next, time.Second)
+}
+
+func setMCPServerReachable(server *klausv1alpha1.KlausMCPServer, status metav1.ConditionStatus, reason, message string) {
+	apimeta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
+		Type:               MCPServerConditionReachable,
+		Status:             status,
+		ObservedGeneration: server.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// fakeMCPServerProber fails probes with err and records the probed URLs.
+type fakeMCPServerProber struct {
+	err  error
+	urls []string
+}
+
+func (p *fakeMCPServerProber) Probe(_ context.Context, url string) error {
+	p.urls = append(p.urls, url)
+	return p.err
+}
+
+func probeTestServer(serverType, url string) *klausv1alpha1.KlausMCPServer {
+	return &klausv1alpha1.KlausMCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "klaus-system", Generation: 2},
+		Spec:       klausv1alpha1.KlausMCPServerSpec{Type: serverType, URL: url},
+	}
+}
+
+func TestHTTPMCPServerProber(t *testing.T) {
+	status := http.StatusMethodNotAllowed
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	prober := NewHTTPMCPServerProber()
+	if err := prober.Probe(context.Background(), srv.URL); err != nil {
+		t.Errorf("Probe() error = %v, want a 405 to count as reachable", err)
+	}
+	status = http.StatusBadGateway
+	if err := prober.Probe(context.Background(), srv.URL); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Probe() error = %v, want the 502 reported", err)
+	}
+	srv.Close()
+	if err := prober.Probe(context.Background(), srv.URL); err == nil {
+		t.Error("Probe() succeeded against a closed server")
+	}
+}
+
+// probeAndWait records the probe result of server once the background
+// probe it starts finished.
+func probeAndWait(r *KlausMCPServerReconciler, server *klausv1alpha1.KlausMCPServer) time.Duration {
+	r.probeMCPServer(context.Background(), server)
+	r.probes.wg.Wait()
+	return r.probeMCPServer(context.Background(), server)
+}
+
+func TestProbeMCPServer(t *testing.T) {
+	prober := &fakeMCPServerProber{}
+	recorder := record.NewFakeRecorder(10)
+	r := &KlausMCPServerReconciler{Recorder: recorder, Prober: prober, ProbeInterval: time.Minute}
+	server := probeTestServer("streamable-http", "https://mcp.example.com/mcp")
+
+	if next := r.probeMCPServer(context.Background(), server); next != mcpServerProbeTimeout {
+		t.Errorf("next look in %s, want the probe timeout while the first probe is in flight", next)
+	}
+	if cond := apimeta.FindStatusCondition(server.Status.Conditions, MCPServerConditionReachable); cond == nil || cond.Reason != "Probing" {
+		t.Errorf("condition = %+v, want Probing", cond)
+	}
+	r.probes.wg.Wait()
+	if next := r.probeMCPServer(context.Background(), server); next <= 0 || next > time.Minute {
+		t.Errorf("next probe in %s, want within the interval", next)
+	}
+	cond := apimeta.FindStatusCondition(server.Status.Conditions, MCPServerConditionReachable)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.ObservedGeneration != 2 {
+		t.Errorf("condition = %+v, want Reachable", cond)
+	}
+	if server.Status.LastProbeTime == nil || server.Status.LastProbeLatency == nil {
+		t.Errorf("status = %+v, want the probe time and latency", server.Status)
+	}
+
+	// Another server with the same URL shares the cached result.
+	other := probeTestServer("streamable-http", "https://mcp.example.com/mcp")
+	other.Name = "github-copy"
+	r.probeMCPServer(context.Background(), other)
+	if len(prober.urls) != 1 || !apimeta.IsStatusConditionTrue(other.Status.Conditions, MCPServerConditionReachable) {
+		t.Errorf("probed %v, conditions = %+v; want the cached result reused", prober.urls, other.Status.Conditions)
+	}
+
+	prober.err = errors.New("connection refused")
+	r.ProbeInterval = time.Nanosecond
+	probeAndWait(r, server)
+	probeAndWait(r, server)
+	cond = apimeta.FindStatusCondition(server.Status.Conditions, MCPServerConditionReachable)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Message != "connection refused" {
+		t.Errorf("condition = %+v, want Unreachable", cond)
+	}
+	if server.Status.LastProbeLatency != nil {
+		t.Errorf("latency = %s, want none for a failed probe", server.Status.LastProbeLatency.Duration)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("got %d events, want one for the transition to unreachable", len(recorder.Events))
+	}
+	if len(r.probes.events()) == 0 {
+		t.Error("finished probes did not enqueue the server")
+	}
+}
+
+func TestProbeMCPServer_Slow(t *testing.T) {
+	release := make(chan struct{})
+	r := &KlausMCPServerReconciler{
+		Recorder:      record.NewFakeRecorder(10),
+		Prober:        blockingMCPServerProber(release),
+		ProbeInterval: time.Minute,
+	}
+	server := probeTestServer("http", "https://slow.example.com")
+
+	done := make(chan struct{})
+	go func() {
+		r.probeMCPServer(context.Background(), server)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("probeMCPServer waited for the probe")
+	}
+	close(release)
+	r.probes.wg.Wait()
+}
+
+// blockingMCPServerProber answers probes once release is closed.
+type blockingMCPServerProber chan struct{}
+
+func (p blockingMCPServerProber) Probe(ctx context.Context, _ string) error {
+	select {
+	case <-p:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestProbeMCPServer_NotProbed(t *testing.T) {
+	prober := &fakeMCPServerProber{}
+	r := &KlausMCPServerReconciler{Recorder: record.NewFakeRecorder(10), Prober: prober, ProbeInterval: time.Minute}
+
+	stdio := probeTestServer("stdio", "")
+	setMCPServerReachable(stdio, metav1.ConditionTrue, "Reachable", "")
+	if next := r.probeMCPServer(context.Background(), stdio); next != 0 || len(stdio.Status.Conditions) != 0 {
+		t.Errorf("next = %s, conditions = %+v; want stdio servers not probed", next, stdio.Status.Conditions)
+	}
+
+	templated := probeTestServer("sse", "https://${MCP_HOST}/sse")
+	r.probeMCPServer(context.Background(), templated)
+	if cond := apimeta.FindStatusCondition(templated.Status.Conditions, MCPServerConditionReachable); cond == nil ||
+		cond.Status != metav1.ConditionUnknown {
+		t.Errorf("condition = %+v, want Unknown for a URL with variables", cond)
+	}
+
+	r.ProbeInterval = 0
+	r.probeMCPServer(context.Background(), probeTestServer("http", "https://mcp.example.com"))
+	if len(prober.urls) != 0 {
+		t.Errorf("probed %v, want nothing probed", prober.urls)
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// mcpServerGVK is the GroupVersionKind for the MCPServer CRD managed by muster.
+var mcpServerGVK = schema.GroupVersionKind{
+	Group:   "muster.giantswarm.io",
+	Version: "v1alpha1",
+	Kind:    "MCPServer",
+}
+
+// musterRegistrar implements GatewayRegistrar with a muster MCPServer in
+// the muster namespace of the instance.
+type musterRegistrar struct {
+	client client.Client
+}
+
+// installed reports whether the API server serves the muster MCPServer
+// kind. The manager's REST mapper rediscovers kinds it does not know yet, so
+// muster being installed later is picked up by the next check.
+func (m *musterRegistrar) installed() (bool, error) {
+	_, err := m.client.RESTMapper().RESTMapping(mcpServerGVK.GroupKind(), mcpServerGVK.Version)
+	if apimeta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (m *musterRegistrar) Register(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	installed, err := m.installed()
+	if err != nil {
+		return err
+	}
+	if !installed {
+		return &gatewayUnavailableError{reason: "MusterNotInstalled", message: "The muster.giantswarm.io MCPServer CRD is not installed"}
+	}
+
+	desired := resources.BuildMCPServerCRD(instance, namespace)
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(mcpServerGVK)
+	err = m.client.Get(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, existing)
+	if apierrors.IsNotFound(err) {
+		return m.client.Create(ctx, desired)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Update the spec and labels using typed accessors to avoid panics.
+	existing.Object["spec"] = desired.Object["spec"]
+	existing.SetLabels(desired.GetLabels())
+	return m.client.Update(ctx, existing)
+}
+
+// Deregister deletes the MCPServer. There is nothing to delete when muster
+// is not installed.
+func (m *musterRegistrar) Deregister(ctx context.Context, instance *klausv1alpha1.KlausInstance, _ string) error {
+	mcpServer := &unstructured.Unstructured{}
+	mcpServer.SetGroupVersionKind(mcpServerGVK)
+	mcpServer.SetName("klaus-" + instance.Name)
+	mcpServer.SetNamespace(resources.MusterNamespace(instance))
+
+	err := m.client.Delete(ctx, mcpServer)
+	if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("deleting muster MCPServer %s/%s: %w", mcpServer.GetNamespace(), mcpServer.GetName(), err)
+	}
+	return nil
+}
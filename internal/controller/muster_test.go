@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// musterTestReconciler returns a reconciler whose API server serves the
+// muster MCPServer kind when installed is set.
+func musterTestReconciler(t *testing.T, installed bool) (*KlausInstanceReconciler, *record.FakeRecorder) {
+	t.Helper()
+	scheme := testScheme(t)
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	for gvk := range scheme.AllKnownTypes() {
+		mapper.Add(gvk, apimeta.RESTScopeNamespace)
+	}
+	if installed {
+		mapper.Add(mcpServerGVK, apimeta.RESTScopeNamespace)
+	}
+	r := testReconcilerFor(fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).Build())
+	return r, testRecorder(r)
+}
+
+func TestRegisterGateway_MusterNotInstalled(t *testing.T) {
+	r, recorder := musterTestReconciler(t, false)
+	instance := testInstance()
+
+	for range 2 {
+		if next := r.registerGateway(context.Background(), instance, instance, resources.UserNamespace(instance.Spec.Owner)); next != gatewayRecheckInterval {
+			t.Errorf("next check in %s, want %s", next, gatewayRecheckInterval)
+		}
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionMCPServerReady)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "MusterNotInstalled" {
+		t.Errorf("condition = %+v, want MusterNotInstalled", cond)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("got %d events, want one for the first check", len(recorder.Events))
+	}
+
+	if instance.Status.Gateway != klausv1alpha1.GatewayTypeMuster {
+		t.Errorf("status.gateway = %q, want muster", instance.Status.Gateway)
+	}
+
+	if err := r.deregisterGateways(context.Background(), instance, resources.UserNamespace(instance.Spec.Owner)); err != nil {
+		t.Errorf("deregisterGateways() error = %v, want nothing to delete", err)
+	}
+}
+
+func TestRegisterGateway_MusterInstalled(t *testing.T) {
+	r, _ := musterTestReconciler(t, true)
+	instance := testInstance()
+
+	if next := r.registerGateway(context.Background(), instance, instance, resources.UserNamespace(instance.Spec.Owner)); next != 0 {
+		t.Errorf("next check in %s, want none once registered", next)
+	}
+	if !apimeta.IsStatusConditionTrue(instance.Status.Conditions, ConditionMCPServerReady) {
+		t.Errorf("conditions = %+v, want MCPServerReady", instance.Status.Conditions)
+	}
+
+	mcpServer := &unstructured.Unstructured{}
+	mcpServer.SetGroupVersionKind(mcpServerGVK)
+	key := types.NamespacedName{Name: "klaus-" + instance.Name, Namespace: resources.MusterNamespace(instance)}
+	if err := r.Get(context.Background(), key, mcpServer); err != nil {
+		t.Fatalf("MCPServer not created: %v", err)
+	}
+	if err := r.deregisterGateways(context.Background(), instance, resources.UserNamespace(instance.Spec.Owner)); err != nil {
+		t.Fatalf("deregisterGateways() error = %v", err)
+	}
+	if err := r.deregisterGateways(context.Background(), instance, resources.UserNamespace(instance.Spec.Owner)); err != nil {
+		t.Errorf("deregisterGateways() of a deleted MCPServer error = %v", err)
+	}
+}
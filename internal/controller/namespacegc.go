@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// LabelRetainNamespace opts a user namespace out of garbage collection when
+// set to "true", e.g. to keep resources added to it by hand.
+const LabelRetainNamespace = "klaus.giantswarm.io/retain-namespace"
+
+// collectUserNamespace deletes the klaus-user namespace of a deleted
+// instance once no other instance, KlausJob or KlausCronJob uses it, taking the copied
+// API key and MCP secrets, the owner and tenant RoleBindings and other
+// per-owner resources with it. Namespaces not created by the operator, those
+// labeled with LabelRetainNamespace and, in namespace-scoped mode, the
+// instance's own namespace are kept. It reports whether the namespace was
+// deleted.
+func (r *KlausInstanceReconciler) collectUserNamespace(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (bool, error) {
+	if r.NamespaceScoped {
+		return false, nil
+	}
+
+	var ns corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if ns.Labels[resources.LabelManagedBy] != resources.AppKlausOperator ||
+		ns.Labels[LabelRetainNamespace] == "true" || !ns.DeletionTimestamp.IsZero() {
+		return false, nil
+	}
+
+	inUse, err := r.userNamespaceInUse(ctx, r.Client, instance, namespace)
+	if err != nil || inUse {
+		return false, err
+	}
+	// The cache may not have seen an instance or job created just now in the
+	// namespace yet: confirm with the API server before deleting.
+	if r.APIReader != nil {
+		inUse, err := r.userNamespaceInUse(ctx, r.APIReader, instance, namespace)
+		if err != nil || inUse {
+			return false, err
+		}
+	}
+
+	log.FromContext(ctx).Info("deleting unused user namespace", "namespace", namespace)
+	if err := r.Delete(ctx, &ns); err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// userNamespaceInUse reports whether a non-deleting instance other than
+// instance, or any KlausJob, runs in the user namespace, or a KlausCronJob
+// starts jobs in it, as listed by reader.
+func (r *KlausInstanceReconciler) userNamespaceInUse(ctx context.Context, reader client.Reader, instance *klausv1alpha1.KlausInstance, namespace string) (bool, error) {
+	var instances klausv1alpha1.KlausInstanceList
+	if err := reader.List(ctx, &instances, client.InNamespace(r.OperatorNamespace)); err != nil {
+		return false, err
+	}
+	for i := range instances.Items {
+		inst := &instances.Items[i]
+		if inst.UID == instance.UID || !inst.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if r.childNamespace(inst) == namespace {
+			return true, nil
+		}
+	}
+
+	var jobs klausv1alpha1.KlausJobList
+	if err := reader.List(ctx, &jobs, client.InNamespace(r.OperatorNamespace)); err != nil {
+		return false, err
+	}
+	for i := range jobs.Items {
+		if resources.UserNamespace(jobs.Items[i].Spec.Owner) == namespace {
+			return true, nil
+		}
+	}
+
+	var cronJobs klausv1alpha1.KlausCronJobList
+	if err := reader.List(ctx, &cronJobs, client.InNamespace(r.OperatorNamespace)); err != nil {
+		return false, err
+	}
+	for i := range cronJobs.Items {
+		if resources.UserNamespace(cronJobs.Items[i].Spec.JobTemplate.Owner) == namespace {
+			return true, nil
+		}
+	}
+	return false, nil
+}
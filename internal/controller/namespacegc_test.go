@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func gcTestNamespace(labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ownerTestNamespace, Labels: labels}}
+}
+
+// collectTestNamespace collects the namespace of a deleted instance with objs
+// in the cache and, additionally, uncached only visible to the APIReader.
+func collectTestNamespace(t *testing.T, namespaceScoped bool, uncached []client.Object, objs ...client.Object) (bool, bool) {
+	t.Helper()
+	instance := testInstance()
+	instance.UID = "deleted"
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objs...).Build()
+	apiReader := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(append(uncached, objs...)...).Build()
+	r := &KlausInstanceReconciler{Client: c, APIReader: apiReader, OperatorNamespace: "klaus-system", NamespaceScoped: namespaceScoped}
+
+	deleted, err := r.collectUserNamespace(context.Background(), instance, ownerTestNamespace)
+	if err != nil {
+		t.Fatalf("collectUserNamespace: %v", err)
+	}
+	err = c.Get(context.Background(), types.NamespacedName{Name: ownerTestNamespace}, &corev1.Namespace{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		t.Fatalf("getting namespace: %v", err)
+	}
+	return deleted, err == nil
+}
+
+func TestCollectUserNamespace(t *testing.T) {
+	managed := map[string]string{resources.LabelManagedBy: resources.AppKlausOperator}
+	other := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-agent", Namespace: "klaus-system", UID: "other"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	otherOwner := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "their-agent", Namespace: "klaus-system", UID: "their"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "someone@example.com"},
+	}
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly-1", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausJobSpec{Owner: "user@example.com"},
+	}
+	cronJob := &klausv1alpha1.KlausCronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausCronJobSpec{JobTemplate: klausv1alpha1.KlausJobSpec{Owner: "user@example.com"}},
+	}
+
+	tests := []struct {
+		name        string
+		scoped      bool
+		objs        []client.Object
+		uncached    []client.Object
+		wantDeleted bool
+	}{
+		{name: "last instance", objs: []client.Object{gcTestNamespace(managed), otherOwner}, wantDeleted: true},
+		{name: "another instance of the owner", objs: []client.Object{gcTestNamespace(managed), other}},
+		{name: "a job of the owner", objs: []client.Object{gcTestNamespace(managed), job}},
+		{name: "a cron job of the owner", objs: []client.Object{gcTestNamespace(managed), cronJob}},
+		{name: "an instance not yet cached", objs: []client.Object{gcTestNamespace(managed)}, uncached: []client.Object{other}},
+		{name: "retained", objs: []client.Object{gcTestNamespace(map[string]string{
+			resources.LabelManagedBy: resources.AppKlausOperator, LabelRetainNamespace: "true",
+		})}},
+		{name: "not created by the operator", objs: []client.Object{gcTestNamespace(nil)}},
+		{name: "namespace-scoped", scoped: true, objs: []client.Object{gcTestNamespace(managed)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted, exists := collectTestNamespace(t, tt.scoped, tt.uncached, tt.objs...)
+			if deleted != tt.wantDeleted || exists == tt.wantDeleted {
+				t.Errorf("deleted = %t, exists = %t; want deleted %t", deleted, exists, tt.wantDeleted)
+			}
+		})
+	}
+
+	if deleted, _ := collectTestNamespace(t, false, nil); deleted {
+		t.Error("deleted a namespace that does not exist")
+	}
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// childNamespace returns the namespace the child resources of an instance
+// are created in.
+func (r *KlausInstanceReconciler) childNamespace(instance *klausv1alpha1.KlausInstance) string {
+	return resources.InstanceNamespace(instance, r.NamespaceScoped)
+}
+
+// setInstanceOwner makes the instance the controller owner of a child
+// object in namespace-scoped mode, so the children sharing its namespace are
+// garbage collected with it even when the finalizer is removed by hand.
+// Owner references cannot cross namespaces, so children in user namespaces
+// are only cleaned up by the finalizer.
+func (r *KlausInstanceReconciler) setInstanceOwner(instance *klausv1alpha1.KlausInstance, obj metav1.Object) error {
+	if !r.NamespaceScoped || obj.GetNamespace() != instance.Namespace {
+		return nil
+	}
+	return controllerutil.SetControllerReference(instance, obj, r.Client.Scheme())
+}
+
+// setJobOwner makes the KlausJob the controller owner of a child object in
+// namespace-scoped mode, like setInstanceOwner. The credential copies and
+// ServiceAccount shared with the instance helpers are removed by the
+// finalizer only.
+func (r *KlausJobReconciler) setJobOwner(job *klausv1alpha1.KlausJob, obj metav1.Object) error {
+	if !r.NamespaceScoped || obj.GetNamespace() != job.Namespace {
+		return nil
+	}
+	return controllerutil.SetControllerReference(job, obj, r.Client.Scheme())
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestSetInstanceOwner(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "klaus-system", UID: "uid-1"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+
+	r := &KlausInstanceReconciler{Client: c}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dev-config", Namespace: "klaus-system"}}
+	if err := r.setInstanceOwner(instance, cm); err != nil || len(cm.OwnerReferences) != 0 {
+		t.Errorf("owner references = %+v (%v), want none outside namespace-scoped mode", cm.OwnerReferences, err)
+	}
+
+	r.NamespaceScoped = true
+	if err := r.setInstanceOwner(instance, cm); err != nil {
+		t.Fatalf("setInstanceOwner() error = %v", err)
+	}
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].UID != "uid-1" ||
+		cm.OwnerReferences[0].Kind != "KlausInstance" || !*cm.OwnerReferences[0].Controller {
+		t.Errorf("owner references = %+v, want the instance as controller", cm.OwnerReferences)
+	}
+
+	elsewhere := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "dev-config", Namespace: "muster"}}
+	if err := r.setInstanceOwner(instance, elsewhere); err != nil || len(elsewhere.OwnerReferences) != 0 {
+		t.Errorf("owner references = %+v (%v), want none across namespaces", elsewhere.OwnerReferences, err)
+	}
+}
+
+func TestCopyMCPSecret_NamespaceScoped(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-token", Namespace: "klaus-system"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(source).Build()
+	r := &KlausInstanceReconciler{Client: c, OperatorNamespace: "klaus-system", NamespaceScoped: true}
+	instance := testInstance()
+
+	if err := r.copyMCPSecret(context.Background(), instance, "github-token", instance.Namespace, r.childNamespace(instance)); err != nil {
+		t.Fatalf("copyMCPSecret() error = %v", err)
+	}
+	var got corev1.Secret
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "github-token", Namespace: "klaus-system"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Labels) != 0 {
+		t.Errorf("labels = %v, want the source Secret left alone", got.Labels)
+	}
+}
+
+func TestKlausJobReconcile_NamespaceScoped(t *testing.T) {
+	ctx := context.Background()
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "klaus-system", UID: "job-uid"},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:  "user@example.com",
+			Prompt: "Review the repository",
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).
+		WithObjects(job, apiKeySecret("anthropic-api-key", "shared-key")).
+		WithStatusSubresource(job).
+		Build()
+	r := newJobReconciler(c)
+	r.NamespaceScoped = true
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "review", Namespace: "klaus-system"}}
+
+	for range 2 {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var batchJob batchv1.Job
+	if err := c.Get(ctx, types.NamespacedName{Name: "review-job", Namespace: "klaus-system"}, &batchJob); err != nil {
+		t.Fatalf("expected batch Job in the KlausJob namespace: %v", err)
+	}
+	if len(batchJob.OwnerReferences) != 1 || batchJob.OwnerReferences[0].UID != "job-uid" {
+		t.Errorf("owner references = %+v, want the KlausJob", batchJob.OwnerReferences)
+	}
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Name: "review-job-config", Namespace: "klaus-system"}, &cm); err != nil {
+		t.Fatalf("expected ConfigMap in the KlausJob namespace: %v", err)
+	}
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].UID != "job-uid" {
+		t.Errorf("owner references = %+v, want the KlausJob", cm.OwnerReferences)
+	}
+
+	var ns corev1.Namespace
+	if err := c.Get(ctx, types.NamespacedName{Name: resources.UserNamespace("user@example.com")}, &ns); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no user namespace, got %v", err)
+	}
+}
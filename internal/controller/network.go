@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// copyCABundle copies the CA bundle referenced by
+// spec.network.caBundleConfigMapRef from the operator namespace into the
+// user namespace and returns its checksum. The copy of an instance that no
+// longer references a bundle is deleted.
+func (r *KlausInstanceReconciler) copyCABundle(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (string, error) {
+	desired := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      resources.CABundleConfigMapName(instance),
+		Namespace: namespace,
+	}}
+	if !resources.HasCABundle(instance) {
+		if err := r.Delete(ctx, desired); client.IgnoreNotFound(err) != nil {
+			return "", fmt.Errorf("deleting CA bundle copy: %w", err)
+		}
+		return "", nil
+	}
+
+	srcName := instance.Spec.Network.CABundleConfigMapRef.Name
+	src := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      srcName,
+		Namespace: instance.Namespace,
+	}, src); err != nil {
+		return "", fmt.Errorf("fetching CA bundle %q: %w", srcName, err)
+	}
+	key := resources.CABundleKey(instance)
+	bundle, ok := src.Data[key]
+	if !ok {
+		return "", fmt.Errorf("CA bundle %q has no key %q", srcName, key)
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, desired, func() error {
+		desired.Data = map[string]string{key: bundle}
+		desired.Labels = resources.InstanceLabels(instance)
+		return r.setInstanceOwner(instance, desired)
+	}); err != nil {
+		return "", fmt.Errorf("reconciling CA bundle copy: %w", err)
+	}
+	return resources.CABundleChecksum(bundle), nil
+}
+
+// copyProxyCredentials copies the Secret referenced by
+// spec.network.proxyCredentialsSecretRef from the namespace of the instance
+// into the user namespace and returns a checksum of the credentials. The
+// copy of an instance that no longer references one is deleted.
+func (r *KlausInstanceReconciler) copyProxyCredentials(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (string, error) {
+	desired := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      resources.ProxyCredentialsSecretName(instance),
+		Namespace: namespace,
+	}}
+	if !resources.HasProxyCredentials(instance) {
+		if err := r.Delete(ctx, desired); client.IgnoreNotFound(err) != nil {
+			return "", fmt.Errorf("deleting proxy credentials copy: %w", err)
+		}
+		return "", nil
+	}
+
+	srcName := instance.Spec.Network.ProxyCredentialsSecretRef.Name
+	src := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      srcName,
+		Namespace: instance.Namespace,
+	}, src); err != nil {
+		return "", fmt.Errorf("fetching proxy credentials %q: %w", srcName, err)
+	}
+	usernameKey, passwordKey := resources.ProxyCredentialKeys(instance.Spec.Network.ProxyCredentialsSecretRef)
+	data := map[string][]byte{}
+	for _, key := range []string{usernameKey, passwordKey} {
+		value, ok := src.Data[key]
+		if !ok {
+			return "", fmt.Errorf("proxy credentials %q have no key %q", srcName, key)
+		}
+		data[key] = value
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, desired, func() error {
+		desired.Type = corev1.SecretTypeOpaque
+		desired.Data = data
+		desired.Labels = resources.InstanceLabels(instance)
+		return r.setInstanceOwner(instance, desired)
+	}); err != nil {
+		return "", fmt.Errorf("reconciling proxy credentials copy: %w", err)
+	}
+	return resources.ProxyCredentialsChecksum(data[usernameKey], data[passwordKey]), nil
+}
+
+// EnqueueNetworkRefInstances maps a ConfigMap or Secret to the instances of
+// its namespace referencing it as their CA bundle or proxy credentials, so
+// that rotations reach the copies and roll the pods.
+func EnqueueNetworkRefInstances(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var instanceList klausv1alpha1.KlausInstanceList
+		if err := c.List(ctx, &instanceList, client.InNamespace(obj.GetNamespace())); err != nil {
+			return nil
+		}
+		_, isSecret := obj.(*corev1.Secret)
+
+		var requests []reconcile.Request
+		for _, inst := range instanceList.Items {
+			network := inst.Spec.Network
+			if network == nil {
+				continue
+			}
+			var name string
+			switch {
+			case isSecret && network.ProxyCredentialsSecretRef != nil:
+				name = network.ProxyCredentialsSecretRef.Name
+			case !isSecret && network.CABundleConfigMapRef != nil:
+				name = network.CABundleConfigMapRef.Name
+			}
+			if name == obj.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: inst.Name, Namespace: inst.Namespace},
+				})
+			}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestCopyCABundle(t *testing.T) {
+	bundle := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "corporate-ca", Namespace: "klaus-system"},
+		Data:       map[string]string{"bundle.pem": "-----BEGIN CERTIFICATE-----", "other": "ignored"},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(bundle).Build()
+	r := &KlausInstanceReconciler{Client: c}
+	ctx := context.Background()
+	instance := testInstance()
+	instance.Spec.Network = &klausv1alpha1.NetworkConfig{
+		CABundleConfigMapRef: &klausv1alpha1.CABundleReference{Name: "corporate-ca", Key: "bundle.pem"},
+	}
+	namespace := resources.UserNamespace(instance.Spec.Owner)
+
+	checksum, err := r.copyCABundle(ctx, instance, namespace)
+	if err != nil {
+		t.Fatalf("copyCABundle() error = %v", err)
+	}
+	if want := resources.CABundleChecksum("-----BEGIN CERTIFICATE-----"); checksum != want {
+		t.Errorf("checksum = %q, want %q", checksum, want)
+	}
+	key := types.NamespacedName{Name: resources.CABundleConfigMapName(instance), Namespace: namespace}
+	var copied corev1.ConfigMap
+	if err := c.Get(ctx, key, &copied); err != nil {
+		t.Fatalf("CA bundle not copied: %v", err)
+	}
+	if len(copied.Data) != 1 || copied.Data["bundle.pem"] != "-----BEGIN CERTIFICATE-----" {
+		t.Errorf("copied data = %v, want only the bundle key", copied.Data)
+	}
+
+	instance.Spec.Network = nil
+	if checksum, err := r.copyCABundle(ctx, instance, namespace); err != nil || checksum != "" {
+		t.Fatalf("copyCABundle() without bundle = %q, %v", checksum, err)
+	}
+	if err := c.Get(ctx, key, &copied); !apierrors.IsNotFound(err) {
+		t.Errorf("copy of a removed CA bundle not deleted: %v", err)
+	}
+}
+
+func TestCopyCABundle_MissingKey(t *testing.T) {
+	bundle := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "corporate-ca", Namespace: "klaus-system"},
+		Data:       map[string]string{"bundle.pem": "-----BEGIN CERTIFICATE-----"},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(bundle).Build()
+	r := &KlausInstanceReconciler{Client: c}
+	instance := testInstance()
+	instance.Spec.Network = &klausv1alpha1.NetworkConfig{
+		CABundleConfigMapRef: &klausv1alpha1.CABundleReference{Name: "corporate-ca"},
+	}
+
+	if _, err := r.copyCABundle(context.Background(), instance, resources.UserNamespace(instance.Spec.Owner)); err == nil {
+		t.Error("expected an error for a bundle without the ca.crt key")
+	}
+}
+
+func TestCopyProxyCredentials(t *testing.T) {
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: "klaus-system"},
+		Data:       map[string][]byte{"username": []byte("agent"), "password": []byte("secret"), "other": []byte("ignored")},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(credentials).Build()
+	r := &KlausInstanceReconciler{Client: c}
+	ctx := context.Background()
+	instance := testInstance()
+	instance.Spec.Network = &klausv1alpha1.NetworkConfig{
+		HTTPSProxy:                "http://proxy.example.com:3128",
+		ProxyCredentialsSecretRef: &klausv1alpha1.ProxyCredentialsReference{Name: "proxy"},
+	}
+	namespace := resources.UserNamespace(instance.Spec.Owner)
+
+	checksum, err := r.copyProxyCredentials(ctx, instance, namespace)
+	if err != nil {
+		t.Fatalf("copyProxyCredentials() error = %v", err)
+	}
+	if want := resources.ProxyCredentialsChecksum([]byte("agent"), []byte("secret")); checksum != want {
+		t.Errorf("checksum = %q, want %q", checksum, want)
+	}
+	key := types.NamespacedName{Name: resources.ProxyCredentialsSecretName(instance), Namespace: namespace}
+	var copied corev1.Secret
+	if err := c.Get(ctx, key, &copied); err != nil {
+		t.Fatalf("proxy credentials not copied: %v", err)
+	}
+	if len(copied.Data) != 2 || string(copied.Data["password"]) != "secret" {
+		t.Errorf("copied data = %v, want only the username and password", copied.Data)
+	}
+
+	instance.Spec.Network.ProxyCredentialsSecretRef.PasswordKey = "token"
+	if _, err := r.copyProxyCredentials(ctx, instance, namespace); err == nil {
+		t.Error("expected an error for credentials without the token key")
+	}
+
+	instance.Spec.Network = nil
+	if checksum, err := r.copyProxyCredentials(ctx, instance, namespace); err != nil || checksum != "" {
+		t.Fatalf("copyProxyCredentials() without credentials = %q, %v", checksum, err)
+	}
+	if err := c.Get(ctx, key, &copied); !apierrors.IsNotFound(err) {
+		t.Errorf("copy of removed proxy credentials not deleted: %v", err)
+	}
+}
+
+func TestEnqueueNetworkRefInstances(t *testing.T) {
+	withCA := testInstance()
+	withCA.Name = "with-ca"
+	withCA.Spec.Network = &klausv1alpha1.NetworkConfig{
+		CABundleConfigMapRef:      &klausv1alpha1.CABundleReference{Name: "corporate-ca"},
+		ProxyCredentialsSecretRef: &klausv1alpha1.ProxyCredentialsReference{Name: "proxy"},
+	}
+	without := testInstance()
+	without.Name = "without"
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(withCA, without).Build()
+	enqueue := EnqueueNetworkRefInstances(c)
+	ctx := context.Background()
+
+	for _, obj := range []client.Object{
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "corporate-ca", Namespace: withCA.Namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: withCA.Namespace}},
+	} {
+		if got := enqueue(ctx, obj); len(got) != 1 || got[0].Name != "with-ca" {
+			t.Errorf("%T %s enqueued %v, want with-ca", obj, obj.GetName(), got)
+		}
+	}
+	for _, obj := range []client.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "corporate-ca", Namespace: withCA.Namespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "proxy", Namespace: withCA.Namespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "corporate-ca", Namespace: "other"}},
+	} {
+		if got := enqueue(ctx, obj); len(got) != 0 {
+			t.Errorf("%T %s/%s enqueued %v, want nothing", obj, obj.GetNamespace(), obj.GetName(), got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/oci"
+)
+
+// ReasonRegistryOffline is the reason of the Ready condition of instances
+// and jobs that need an OCI registry lookup while the operator runs offline
+// and fails closed.
+const ReasonRegistryOffline = "RegistryOffline"
+
+// ReasonPersonalitySoulUnavailable is the reason of the Degraded condition
+// of instances running without the soul of their personality because the
+// operator runs offline and fails open.
+const ReasonPersonalitySoulUnavailable = "PersonalitySoulUnavailable"
+
+// offlineReason returns ReasonRegistryOffline for errors of registry
+// lookups refused in offline mode, and reason for any other error.
+func offlineReason(reason string, err error) string {
+	if errors.Is(err, oci.ErrOffline) {
+		return ReasonRegistryOffline
+	}
+	return reason
+}
+
+// setSoulDegraded sets the Degraded condition of an instance whose soul
+// failed with oci.ErrSoulUnavailable, recording a warning event when the
+// condition is first set, and removes it once the soul was fetched. The
+// instance otherwise runs as a different agent without anyone knowing.
+func setSoulDegraded(recorder record.EventRecorder, instance *klausv1alpha1.KlausInstance, err error) {
+	current := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDegraded)
+	if !errors.Is(err, oci.ErrSoulUnavailable) {
+		if current != nil && current.Reason == ReasonPersonalitySoulUnavailable {
+			apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionDegraded)
+		}
+		return
+	}
+	message := "Running without the soul of personality " + instance.Spec.Personality + ": " + err.Error()
+	if current == nil || current.Status != metav1.ConditionTrue || current.Reason != ReasonPersonalitySoulUnavailable {
+		recorder.Event(instance, corev1.EventTypeWarning, ReasonPersonalitySoulUnavailable, message)
+	}
+	setCondition(instance, ConditionDegraded, metav1.ConditionTrue, ReasonPersonalitySoulUnavailable, message)
+}
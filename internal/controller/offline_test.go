@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/oci"
+	"github.com/giantswarm/klaus-operator/internal/testutil"
+	"github.com/giantswarm/klaus-operator/pkg/ocibuild"
+)
+
+// pinInstance runs the OCI reference steps of resolveInstance on a copy of
+// instance and returns the pinned copy.
+func pinInstance(ctx context.Context, r *KlausInstanceReconciler, instance *klausv1alpha1.KlausInstance) (*klausv1alpha1.KlausInstance, error) {
+	merged := instance.DeepCopy()
+	if err := r.resolveOCIReferences(ctx, merged); err != nil {
+		return nil, err
+	}
+	if _, err := r.pinPluginDigests(ctx, instance, merged); err != nil {
+		return nil, err
+	}
+	if _, err := r.pinPersonalityDigest(ctx, instance, merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// registryInstance pushes a personality and a plugin to registry and
+// returns an instance using the latest personality and the plugin tag.
+func registryInstance(t *testing.T, registry *testutil.Registry) (*klausv1alpha1.KlausInstance, *ocibuild.Artifact) {
+	t.Helper()
+	ctx := context.Background()
+	personality, err := ocibuild.BuildPersonality(klausoci.Personality{Name: "sre"}, fstest.MapFS{
+		"SOUL.md": {Data: []byte("You are an SRE.\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin, err := ocibuild.BuildPlugin(klausoci.Plugin{Name: "gs-base"}, fstest.MapFS{
+		".claude-plugin/plugin.json": {Data: []byte(`{"name":"gs-base"}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Push(ctx, "personalities/sre", "v1.0.0", personality); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Push(ctx, "plugins/gs-base", "v1.0.0", plugin); err != nil {
+		t.Fatal(err)
+	}
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system", Generation: 1},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:       "user@example.com",
+			Personality: registry.Ref("personalities/sre", ""),
+			Plugins: []klausv1alpha1.PluginReference{
+				{Repository: registry.Ref("plugins/gs-base", ""), Tag: "v1.0.0"},
+			},
+		},
+	}
+	return instance, personality
+}
+
+func TestPinInstance_Registry(t *testing.T) {
+	registry := testutil.NewRegistry()
+	defer registry.Close()
+	instance, personality := registryInstance(t, registry)
+	r := &KlausInstanceReconciler{
+		Recorder:  record.NewFakeRecorder(10),
+		OCIClient: klausoci.NewClient(klausoci.WithPlainHTTP(true)),
+	}
+
+	merged, err := pinInstance(context.Background(), r, instance)
+	if err != nil {
+		t.Fatalf("pinning the instance: %v", err)
+	}
+	if want := registry.Ref("personalities/sre", "v1.0.0") + "@" + personality.Digest(); merged.Spec.Personality != want {
+		t.Errorf("personality = %s, want %s", merged.Spec.Personality, want)
+	}
+	if p := merged.Spec.Plugins[0]; p.Tag != "" || p.Digest == "" {
+		t.Errorf("plugin = %+v, want the tag pinned to its digest", p)
+	}
+}
+
+func TestPinInstance_OfflineFailClosed(t *testing.T) {
+	registry := testutil.NewRegistry()
+	defer registry.Close()
+	instance, _ := registryInstance(t, registry)
+	pushed := registry.Requests()
+	r := &KlausInstanceReconciler{
+		Recorder:  record.NewFakeRecorder(10),
+		OCIClient: &oci.OfflineClient{},
+	}
+
+	// The untagged personality needs the tags of the registry.
+	if _, err := pinInstance(context.Background(), r, instance); !errors.Is(err, oci.ErrOffline) {
+		t.Errorf("error = %v, want ErrOffline", err)
+	}
+	// So does the digest of the plugin tag.
+	instance.Spec.Personality = ""
+	if _, err := pinInstance(context.Background(), r, instance); !errors.Is(err, oci.ErrOffline) {
+		t.Errorf("error = %v, want ErrOffline", err)
+	}
+	// References pinned to a digest need no lookup.
+	instance.Spec.Plugins[0].Tag = ""
+	instance.Spec.Plugins[0].Digest = digestPlugin
+	if _, err := pinInstance(context.Background(), r, instance); err != nil {
+		t.Errorf("error = %v, want the pinned plugin used", err)
+	}
+	if n := registry.Requests(); n != pushed {
+		t.Errorf("registry served %d requests offline, want none", n-pushed)
+	}
+}
+
+func TestPinInstance_OfflineFailOpen(t *testing.T) {
+	registry := testutil.NewRegistry()
+	defer registry.Close()
+	instance, _ := registryInstance(t, registry)
+	pushed := registry.Requests()
+	r := &KlausInstanceReconciler{
+		Recorder:  record.NewFakeRecorder(10),
+		OCIClient: &oci.OfflineClient{FailOpen: true},
+	}
+
+	merged, err := pinInstance(context.Background(), r, instance)
+	if err != nil {
+		t.Fatalf("pinning the instance: %v", err)
+	}
+	if merged.Spec.Personality != instance.Spec.Personality || instance.Status.ResolvedPersonality != nil {
+		t.Errorf("personality = %s, resolved %+v; want it used as written", merged.Spec.Personality, instance.Status.ResolvedPersonality)
+	}
+	if p := merged.Spec.Plugins[0]; p.Tag != "v1.0.0" || p.Digest != "" || len(instance.Status.ResolvedPlugins) != 0 {
+		t.Errorf("plugin = %+v, resolved %+v; want the tag left unpinned", p, instance.Status.ResolvedPlugins)
+	}
+	if n := registry.Requests(); n != pushed {
+		t.Errorf("registry served %d requests offline, want none", n-pushed)
+	}
+}
+
+func TestReconcile_RegistryOffline(t *testing.T) {
+	ctx := context.Background()
+	instance := verificationInstance()
+	instance.Finalizers = []string{FinalizerName}
+	instance.Spec.Personality = "sre"
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(instance).
+		WithStatusSubresource(instance).
+		Build()
+	r := &KlausInstanceReconciler{
+		Client:            c,
+		Recorder:          record.NewFakeRecorder(10),
+		OperatorNamespace: "klaus-system",
+		OCIClient:         &oci.OfflineClient{},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "agent", Namespace: "klaus-system"}}
+	if _, err := r.Reconcile(ctx, req); !errors.Is(err, oci.ErrOffline) {
+		t.Fatalf("Reconcile() error = %v, want ErrOffline", err)
+	}
+
+	var got klausv1alpha1.KlausInstance
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("getting instance: %v", err)
+	}
+	ready := apimeta.FindStatusCondition(got.Status.Conditions, ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != ReasonRegistryOffline ||
+		got.Status.State != klausv1alpha1.InstanceStateError {
+		t.Errorf("state = %s, ready = %+v; want a RegistryOffline error", got.Status.State, ready)
+	}
+}
+
+func TestSetSoulDegraded(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{Personality: "sre"}}
+	_, err := oci.NewSoulFetcher(&oci.OfflineClient{FailOpen: true}, oci.DefaultSoulCacheSize).FetchSoul(context.Background(), "sre:v1.0.0")
+
+	setSoulDegraded(recorder, instance, err)
+	setSoulDegraded(recorder, instance, err)
+	degraded := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue || degraded.Reason != ReasonPersonalitySoulUnavailable {
+		t.Fatalf("degraded = %+v, want PersonalitySoulUnavailable", degraded)
+	}
+	if n := len(recorder.Events); n != 1 {
+		t.Errorf("recorded %d events, want one warning", n)
+	}
+
+	setSoulDegraded(recorder, instance, nil)
+	if degraded := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDegraded); degraded != nil {
+		t.Errorf("degraded = %+v, want it removed once the soul is fetched", degraded)
+	}
+}
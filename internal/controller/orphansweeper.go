@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// DefaultOrphanSweepInterval is how often child resources are checked for
+// a missing KlausInstance or KlausJob unless configured otherwise.
+const DefaultOrphanSweepInterval = 30 * time.Minute
+
+// orphanGracePeriod spares resources created less than this long ago, so a
+// sweep racing with the creation of an instance and its children does not
+// delete them.
+const orphanGracePeriod = 10 * time.Minute
+
+// OrphanSweeper is a leader-elected manager runnable that periodically
+// deletes the Deployments, Services, ConfigMaps, Secrets and PVCs the
+// operator created for a KlausInstance or KlausJob that no longer exists,
+// e.g. because its finalizer was removed by hand or it was deleted while
+// the operator was down. Resources shared by the instances of an owner,
+// like the copied MCP secrets, carry no instance label and are never swept.
+type OrphanSweeper struct {
+	// Client lists and deletes the child resources.
+	Client client.Client
+
+	// APIReader confirms that an instance or job is gone, bypassing the
+	// cache so a lagging informer does not cause deletions.
+	APIReader client.Reader
+
+	// Namespace is the namespace of the KlausInstances and KlausJobs.
+	Namespace string
+
+	// Interval is the sweep interval.
+	Interval time.Duration
+
+	// NamespaceScoped looks up the instance of a resource in the
+	// resource's own namespace instead of Namespace, and sweeps all
+	// namespaces instead of only the user namespaces.
+	NamespaceScoped bool
+}
+
+// NeedLeaderElection ensures only the leader deletes resources.
+func (s *OrphanSweeper) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs Sweep every Interval; see periodic.
+func (s *OrphanSweeper) Start(ctx context.Context) error {
+	return periodic{
+		name:            "orphan-sweeper",
+		interval:        s.Interval,
+		defaultInterval: DefaultOrphanSweepInterval,
+		action:          "sweeping orphaned resources",
+		fn:              s.Sweep,
+	}.Start(ctx)
+}
+
+// orphanKinds are the kinds of child resources checked by a sweep, with the
+// metrics label value they are counted under.
+var orphanKinds = []struct {
+	kind    string
+	newList func() client.ObjectList
+}{
+	{"Deployment", func() client.ObjectList { return &appsv1.DeploymentList{} }},
+	{"Service", func() client.ObjectList { return &corev1.ServiceList{} }},
+	{"ConfigMap", func() client.ObjectList { return &corev1.ConfigMapList{} }},
+	{"Secret", func() client.ObjectList { return &corev1.SecretList{} }},
+	{"PersistentVolumeClaim", func() client.ObjectList { return &corev1.PersistentVolumeClaimList{} }},
+}
+
+// Sweep deletes the orphaned child resources of all kinds and counts them
+// in the OrphanedResources metrics.
+func (s *OrphanSweeper) Sweep(ctx context.Context) error {
+	selector, err := orphanSelector()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, k := range orphanKinds {
+		list := k.newList()
+		if err := s.Client.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			errs = append(errs, fmt.Errorf("listing %ss: %w", k.kind, err))
+			continue
+		}
+		items, err := apimeta.ExtractList(list)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			if err := s.sweepObject(ctx, k.kind, obj); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s/%s: %w", k.kind, obj.GetNamespace(), obj.GetName(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// orphanSelector selects the operator-managed resources that belong to a
+// single instance or job.
+func orphanSelector() (labels.Selector, error) {
+	managedBy, err := labels.NewRequirement(resources.LabelManagedBy, selection.Equals, []string{resources.AppKlausOperator})
+	if err != nil {
+		return nil, err
+	}
+	instance, err := labels.NewRequirement("app.kubernetes.io/instance", selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*managedBy, *instance), nil
+}
+
+// sweepObject deletes obj when its KlausInstance or KlausJob is gone.
+func (s *OrphanSweeper) sweepObject(ctx context.Context, kind string, obj client.Object) error {
+	if !s.NamespaceScoped && !resources.IsUserNamespace(obj.GetNamespace()) {
+		return nil
+	}
+	if !obj.GetDeletionTimestamp().IsZero() || time.Since(obj.GetCreationTimestamp().Time) < orphanGracePeriod {
+		return nil
+	}
+
+	exists, err := s.parentExists(ctx, obj)
+	if err != nil || exists {
+		return err
+	}
+
+	metrics.OrphanedResourcesFound.WithLabelValues(kind).Inc()
+	log.FromContext(ctx).Info("deleting orphaned resource", "kind", kind,
+		"namespace", obj.GetNamespace(), "name", obj.GetName())
+	if err := s.Client.Delete(ctx, obj, client.Preconditions{UID: ptr.To(obj.GetUID())}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	metrics.OrphanedResourcesDeleted.WithLabelValues(kind).Inc()
+	return nil
+}
+
+// parentExists reports whether the KlausJob named by the job label of obj,
+// or else the KlausInstance named by its instance label, exists.
+func (s *OrphanSweeper) parentExists(ctx context.Context, obj client.Object) (bool, error) {
+	namespace := s.Namespace
+	if s.NamespaceScoped {
+		namespace = obj.GetNamespace()
+	}
+
+	var parent client.Object = &klausv1alpha1.KlausInstance{}
+	name := obj.GetLabels()["app.kubernetes.io/instance"]
+	if job := obj.GetLabels()[resources.LabelJob]; job != "" {
+		parent, name = &klausv1alpha1.KlausJob{}, job
+	}
+
+	err := s.APIReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, parent)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// orphanTestObject returns metadata of a child resource created an hour
+// ago in namespace with the given labels.
+func orphanTestObject(namespace, name string, labels map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         namespace,
+		Labels:            labels,
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+	}
+}
+
+func orphanTestSweeper(t *testing.T, objs ...client.Object) (*OrphanSweeper, client.Client) {
+	t.Helper()
+	scheme := testScheme(t)
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding appsv1 to scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &OrphanSweeper{Client: c, APIReader: c, Namespace: "klaus-system"}, c
+}
+
+func TestOrphanSweeper_Sweep(t *testing.T) {
+	metrics.OrphanedResourcesFound.Reset()
+	metrics.OrphanedResourcesDeleted.Reset()
+
+	live := testInstance()
+	gone := testInstance()
+	gone.Name = "deleted-agent"
+	userNS := resources.UserNamespace(live.Spec.Owner)
+
+	recent := orphanTestObject(userNS, "recent-agent", resources.InstanceLabels(gone))
+	recent.Labels["app.kubernetes.io/instance"] = "recent-agent"
+	recent.CreationTimestamp = metav1.Now()
+
+	jobLabels := resources.InstanceLabels(gone)
+	jobLabels["app.kubernetes.io/instance"] = "nightly-job"
+	jobLabels[resources.LabelJob] = "nightly"
+	job := &klausv1alpha1.KlausJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "klaus-system"}}
+
+	s, c := orphanTestSweeper(t, live, job,
+		&appsv1.Deployment{ObjectMeta: orphanTestObject(userNS, live.Name, resources.InstanceLabels(live))},
+		&appsv1.Deployment{ObjectMeta: orphanTestObject(userNS, gone.Name, resources.InstanceLabels(gone))},
+		&corev1.PersistentVolumeClaim{ObjectMeta: orphanTestObject(userNS, gone.Name+"-workspace", resources.InstanceLabels(gone))},
+		&corev1.Secret{ObjectMeta: orphanTestObject(userNS, "mcp-github", resources.MCPSecretLabels(gone.Spec.Owner))},
+		&corev1.ConfigMap{ObjectMeta: orphanTestObject("default", gone.Name+"-config", resources.InstanceLabels(gone))},
+		&corev1.ConfigMap{ObjectMeta: recent},
+		&corev1.ConfigMap{ObjectMeta: orphanTestObject(userNS, "nightly-job-config", jobLabels)},
+	)
+
+	if err := s.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+
+	for _, tc := range []struct {
+		obj     client.Object
+		key     types.NamespacedName
+		deleted bool
+	}{
+		{&appsv1.Deployment{}, types.NamespacedName{Namespace: userNS, Name: live.Name}, false},
+		{&appsv1.Deployment{}, types.NamespacedName{Namespace: userNS, Name: gone.Name}, true},
+		{&corev1.PersistentVolumeClaim{}, types.NamespacedName{Namespace: userNS, Name: gone.Name + "-workspace"}, true},
+		{&corev1.Secret{}, types.NamespacedName{Namespace: userNS, Name: "mcp-github"}, false},
+		{&corev1.ConfigMap{}, types.NamespacedName{Namespace: "default", Name: gone.Name + "-config"}, false},
+		{&corev1.ConfigMap{}, types.NamespacedName{Namespace: userNS, Name: "recent-agent"}, false},
+		{&corev1.ConfigMap{}, types.NamespacedName{Namespace: userNS, Name: "nightly-job-config"}, false},
+	} {
+		err := c.Get(context.Background(), tc.key, tc.obj)
+		if deleted := apierrors.IsNotFound(err); deleted != tc.deleted {
+			t.Errorf("%T %s: deleted = %t (err %v), want %t", tc.obj, tc.key, deleted, err, tc.deleted)
+		}
+	}
+
+	if got := testutil.ToFloat64(metrics.OrphanedResourcesFound.WithLabelValues("Deployment")); got != 1 {
+		t.Errorf("found Deployments = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.OrphanedResourcesDeleted.WithLabelValues("PersistentVolumeClaim")); got != 1 {
+		t.Errorf("deleted PVCs = %v, want 1", got)
+	}
+}
+
+func TestOrphanSweeper_NamespaceScoped(t *testing.T) {
+	instance := testInstance()
+	instance.Namespace = "team-a"
+	s, c := orphanTestSweeper(t, instance,
+		&corev1.Service{ObjectMeta: orphanTestObject("team-a", instance.Name, resources.InstanceLabels(instance))},
+		&corev1.Service{ObjectMeta: orphanTestObject("team-b", instance.Name, resources.InstanceLabels(instance))},
+	)
+	s.NamespaceScoped = true
+
+	if err := s.Sweep(context.Background()); err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	var svc corev1.Service
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: instance.Name}, &svc); err != nil {
+		t.Errorf("Service next to its instance: %v, want it kept", err)
+	}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "team-b", Name: instance.Name}, &svc); !apierrors.IsNotFound(err) {
+		t.Errorf("Service without an instance in its namespace: %v, want it deleted", err)
+	}
+}
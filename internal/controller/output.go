@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/githubapp"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// PullRequestOpener opens GitHub pull requests authenticated with a
+// repository access token and returns their web URL.
+type PullRequestOpener interface {
+	OpenPullRequest(ctx context.Context, token string, repository githubapp.Repository, pr githubapp.PullRequest) (string, error)
+}
+
+// outputPending reports whether the workspace changes of a succeeded
+// KlausJob still have to be pushed.
+func outputPending(job *klausv1alpha1.KlausJob) bool {
+	return job.Status.State == klausv1alpha1.JobStateSucceeded &&
+		job.Status.Output != nil && job.Status.Output.State == klausv1alpha1.JobOutputPending
+}
+
+// reconcileOutput pushes the workspace changes of a succeeded KlausJob run
+// for spec.workspace.output. A batch Job with the git clone image commits and
+// pushes the checkout left on the workspace PVC; once it finished, the pull
+// request is opened. Failures are recorded in status.output and not retried,
+// as the run itself succeeded.
+func (r *KlausJobReconciler) reconcileOutput(ctx context.Context, job *klausv1alpha1.KlausJob) (ctrl.Result, error) {
+	instance := resources.JobInstance(job)
+	namespace := resources.InstanceNamespace(instance, r.NamespaceScoped)
+	status := job.Status.Output
+
+	branch, title, err := resources.RenderWorkspaceOutput(job)
+	if err != nil {
+		return r.updateOutputFailed(ctx, job, err)
+	}
+	status.Branch = branch
+
+	var pushJob batchv1.Job
+	err = r.Get(ctx, types.NamespacedName{Name: resources.JobOutputName(job), Namespace: namespace}, &pushJob)
+	if apierrors.IsNotFound(err) {
+		// Installation tokens may have expired during a long run.
+		if _, err := r.instanceHelpers().copyGitSecret(ctx, instance, namespace); err != nil {
+			return r.updateOutputFailed(ctx, job, fmt.Errorf("refreshing the git credentials: %w", err))
+		}
+		desired := resources.BuildJobOutputJob(job, namespace, r.GitCloneImage, branch, title)
+		if err := r.setJobOwner(job, desired); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.Create(ctx, desired); err != nil {
+			return ctrl.Result{}, fmt.Errorf("creating the output Job: %w", err)
+		}
+		r.Recorder.Event(job, corev1.EventTypeNormal, "PushingOutput", "Pushing the workspace changes to branch "+branch)
+		return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, job)
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The output Job is watched; wait for it to finish.
+	finished := finishedJobCondition(&pushJob)
+	if finished == nil {
+		return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, job)
+	}
+	var message string
+	if terminated := r.lastTermination(ctx, &pushJob, resources.WorkspaceGitContainerName); terminated != nil {
+		message = terminated.Message
+	}
+	if finished.Type == batchv1.JobFailed {
+		if message == "" {
+			message = finished.Message
+		}
+		return r.updateOutputFailed(ctx, job, fmt.Errorf("pushing the workspace changes failed: %s", message))
+	}
+	if message == "" {
+		return r.updateOutputFailed(ctx, job, errors.New("the result of the output Job is not available"))
+	}
+
+	commit, base, changed := resources.ParseOutputTerminationMessage(message)
+	if !changed {
+		status.State = klausv1alpha1.JobOutputNoChanges
+		r.Recorder.Event(job, corev1.EventTypeNormal, "OutputUnchanged", "The agent made no workspace changes to push")
+		return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, job)
+	}
+	status.Commit = commit
+	if resources.JobWorkspaceOutput(job).CreatePR && status.PullRequestURL == "" {
+		url, err := r.openPullRequest(ctx, job, instance, namespace, base, title)
+		if err != nil {
+			return r.updateOutputFailed(ctx, job, err)
+		}
+		status.PullRequestURL = url
+	}
+	status.State = klausv1alpha1.JobOutputPushed
+	message = fmt.Sprintf("Pushed commit %s to branch %s", commit, branch)
+	if status.PullRequestURL != "" {
+		message += ", pull request " + status.PullRequestURL
+	}
+	r.Recorder.Event(job, corev1.EventTypeNormal, "OutputPushed", message)
+	return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, job)
+}
+
+// openPullRequest opens the pull request from the pushed branch into base
+// with the token of the git credential Secret in the user namespace.
+func (r *KlausJobReconciler) openPullRequest(ctx context.Context, job *klausv1alpha1.KlausJob, instance *klausv1alpha1.KlausInstance, namespace, base, title string) (string, error) {
+	if r.PullRequests == nil {
+		return "", errors.New("the operator is not configured to open pull requests")
+	}
+	if base == "" {
+		return "", errors.New("cannot open a pull request: the workspace was not cloned from a branch")
+	}
+	repo, err := githubapp.ParseRepository(instance.Spec.Workspace.GitRepo)
+	if err != nil {
+		return "", err
+	}
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Name: resources.GitSecretName(instance), Namespace: namespace}, &secret); err != nil {
+		return "", fmt.Errorf("reading the git credential secret: %w", err)
+	}
+	return r.PullRequests.OpenPullRequest(ctx, string(secret.Data[resources.GitSecretKey(instance)]), repo, githubapp.PullRequest{
+		Title: title,
+		Head:  job.Status.Output.Branch,
+		Base:  base,
+		Body:  resources.OutputPullRequestBody(job),
+	})
+}
+
+// updateOutputFailed records a failed push of the workspace changes.
+func (r *KlausJobReconciler) updateOutputFailed(ctx context.Context, job *klausv1alpha1.KlausJob, err error) (ctrl.Result, error) {
+	job.Status.Output.State = klausv1alpha1.JobOutputFailed
+	job.Status.Output.Message = err.Error()
+	r.Recorder.Event(job, corev1.EventTypeWarning, "OutputFailed", err.Error())
+	return ctrl.Result{}, patchObjectStatus(ctx, r.Client, r.APIReader, job)
+}
@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/githubapp"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+type fakePullRequests struct {
+	tokens []string
+	opened []githubapp.PullRequest
+}
+
+func (p *fakePullRequests) OpenPullRequest(_ context.Context, token string, repo githubapp.Repository, pr githubapp.PullRequest) (string, error) {
+	p.tokens = append(p.tokens, token)
+	p.opened = append(p.opened, pr)
+	return "https://" + repo.Host + "/" + repo.String() + "/pull/1", nil
+}
+
+// finishBatchJob marks a batch Job as finished with the given condition and
+// creates its pod with the termination message of container.
+func finishBatchJob(t *testing.T, c client.Client, name, namespace string, condition batchv1.JobConditionType, container, message string) {
+	t.Helper()
+	ctx := context.Background()
+	var batchJob batchv1.Job
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &batchJob); err != nil {
+		t.Fatalf("getting Job %s: %v", name, err)
+	}
+	batchJob.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+	if err := c.Status().Update(ctx, &batchJob); err != nil {
+		t.Fatalf("updating Job %s: %v", name, err)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-pod",
+			Namespace: namespace,
+			Labels:    map[string]string{batchv1.JobNameLabel: name},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  container,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: message}},
+			}},
+		},
+	}
+	if err := c.Create(ctx, pod); err != nil {
+		t.Fatalf("creating pod: %v", err)
+	}
+}
+
+func outputJob(createPR bool) *klausv1alpha1.KlausJob {
+	return &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "fix", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:  "user@example.com",
+			Prompt: "Fix the flaky test",
+			Workspace: &klausv1alpha1.WorkspaceConfig{
+				GitRepo:      "https://github.com/org/repo.git",
+				GitSecretRef: &klausv1alpha1.GitSecretReference{Name: "github-token"},
+				Output: &klausv1alpha1.WorkspaceOutput{
+					PushBranch:      "klaus/{{.Name}}",
+					CreatePR:        createPR,
+					PRTitleTemplate: "Fix from {{.Branch}}",
+				},
+			},
+		},
+	}
+}
+
+func runOutputJob(t *testing.T, job *klausv1alpha1.KlausJob, prs *fakePullRequests) (client.Client, *KlausJobReconciler, ctrl.Request) {
+	t.Helper()
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-token", Namespace: "klaus-system"},
+		Data:       map[string][]byte{"token": []byte("ghp_abc")},
+	}
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).
+		WithObjects(job, token, apiKeySecret("anthropic-api-key", "shared-key")).
+		WithStatusSubresource(job).
+		Build()
+	r := newJobReconciler(c)
+	r.PullRequests = prs
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: job.Name, Namespace: job.Namespace}}
+	for range 2 {
+		if _, err := r.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	finishBatchJob(t, c, "fix-job", "klaus-user-user-example-com", batchv1.JobComplete, resources.AppKlaus, "Fixed the race")
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return c, r, req
+}
+
+func getOutputStatus(t *testing.T, c client.Client, req ctrl.Request) (*klausv1alpha1.KlausJob, *klausv1alpha1.JobOutputStatus) {
+	t.Helper()
+	var job klausv1alpha1.KlausJob
+	if err := c.Get(context.Background(), req.NamespacedName, &job); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if job.Status.Output == nil {
+		t.Fatal("status.output is not set")
+	}
+	return &job, job.Status.Output
+}
+
+func TestKlausJobReconcile_OutputPushAndPullRequest(t *testing.T) {
+	namespace := "klaus-user-user-example-com"
+	prs := &fakePullRequests{}
+	c, r, req := runOutputJob(t, outputJob(true), prs)
+
+	job, status := getOutputStatus(t, c, req)
+	if job.Status.State != klausv1alpha1.JobStateSucceeded || status.State != klausv1alpha1.JobOutputPending {
+		t.Fatalf("state = %s, output = %+v; want Succeeded with a pending output", job.Status.State, status)
+	}
+	if status.Branch != "klaus/fix" {
+		t.Errorf("branch = %q, want klaus/fix", status.Branch)
+	}
+	var push batchv1.Job
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "fix-job-output", Namespace: namespace}, &push); err != nil {
+		t.Fatalf("expected the output Job to be created: %v", err)
+	}
+	if push.Labels[resources.LabelJob] != "fix" {
+		t.Errorf("labels = %v, want the KlausJob label", push.Labels)
+	}
+	if script := push.Spec.Template.Spec.Containers[0].Args[0]; !strings.Contains(script, "BRANCH='klaus/fix'") || !strings.Contains(script, "'Fix from klaus/fix'") {
+		t.Errorf("script does not push klaus/fix with the rendered title:\n%s", script)
+	}
+
+	// Reconciles while the output Job runs change nothing.
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs.opened) != 0 {
+		t.Errorf("opened pull requests before the push finished: %v", prs.opened)
+	}
+
+	finishBatchJob(t, c, "fix-job-output", namespace, batchv1.JobComplete, resources.WorkspaceGitContainerName, "commit=abc123\nbase=main\n")
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, status = getOutputStatus(t, c, req)
+	if status.State != klausv1alpha1.JobOutputPushed || status.Commit != "abc123" || status.PullRequestURL != "https://github.com/org/repo/pull/1" {
+		t.Errorf("output = %+v, want pushed with a pull request", status)
+	}
+	if len(prs.opened) != 1 || prs.opened[0].Head != "klaus/fix" || prs.opened[0].Base != "main" || prs.opened[0].Title != "Fix from klaus/fix" {
+		t.Errorf("opened = %+v", prs.opened)
+	}
+	if prs.tokens[0] != "ghp_abc" || !strings.Contains(prs.opened[0].Body, "Fixed the race") {
+		t.Errorf("token = %q, body = %q", prs.tokens[0], prs.opened[0].Body)
+	}
+
+	// The finished output is kept.
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prs.opened) != 1 {
+		t.Errorf("opened %d pull requests, want 1", len(prs.opened))
+	}
+}
+
+func TestKlausJobReconcile_OutputResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		condition   batchv1.JobConditionType
+		message     string
+		wantState   klausv1alpha1.JobOutputState
+		wantMessage string
+	}{
+		{name: "no changes", condition: batchv1.JobComplete, message: "unchanged\n", wantState: klausv1alpha1.JobOutputNoChanges},
+		{name: "push rejected", condition: batchv1.JobFailed, message: "! [rejected] HEAD -> klaus/fix (non-fast-forward)",
+			wantState: klausv1alpha1.JobOutputFailed, wantMessage: "non-fast-forward"},
+		{name: "detached checkout", condition: batchv1.JobComplete, message: "commit=abc123\nbase=\n",
+			wantState: klausv1alpha1.JobOutputFailed, wantMessage: "not cloned from a branch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prs := &fakePullRequests{}
+			c, r, req := runOutputJob(t, outputJob(true), prs)
+			finishBatchJob(t, c, "fix-job-output", "klaus-user-user-example-com", tt.condition, resources.WorkspaceGitContainerName, tt.message)
+			if _, err := r.Reconcile(context.Background(), req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			job, status := getOutputStatus(t, c, req)
+			if status.State != tt.wantState || !strings.Contains(status.Message, tt.wantMessage) {
+				t.Errorf("output = %+v, want %s with %q", status, tt.wantState, tt.wantMessage)
+			}
+			if job.Status.State != klausv1alpha1.JobStateSucceeded {
+				t.Errorf("state = %s, want the run to stay Succeeded", job.Status.State)
+			}
+			if len(prs.opened) != 0 {
+				t.Errorf("opened = %v, want no pull request", prs.opened)
+			}
+		})
+	}
+}
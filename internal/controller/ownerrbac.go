@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// reconcileOwnerRoleBinding grants the instance owner OwnerClusterRole in
+// their namespace so they can debug their own instances. The RoleBinding is
+// shared by all instances of the owner. A binding left behind after the
+// ClusterRole was unset is removed. The roleRef of a RoleBinding is
+// immutable, so a binding to a different ClusterRole is recreated.
+func (r *KlausInstanceReconciler) reconcileOwnerRoleBinding(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	desired := resources.BuildOwnerRoleBinding(instance.Spec.Owner, namespace, r.OwnerClusterRole, r.OwnerSubjectPrefix)
+
+	existing := &rbacv1.RoleBinding{}
+	err := r.Get(ctx, types.NamespacedName{Name: resources.OwnerRoleBindingName, Namespace: namespace}, existing)
+	found := err == nil
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("fetching owner RoleBinding: %w", err)
+	}
+	if found && existing.Labels[resources.LabelManagedBy] != resources.AppKlausOperator {
+		// Leave bindings the operator did not create alone.
+		return nil
+	}
+
+	if desired == nil || (found && existing.RoleRef != desired.RoleRef) {
+		if found {
+			if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("deleting owner RoleBinding: %w", err)
+			}
+		}
+		if desired == nil {
+			return nil
+		}
+		found = false
+	}
+
+	if !found {
+		if err := r.Create(ctx, desired); err != nil {
+			return fmt.Errorf("creating owner RoleBinding: %w", err)
+		}
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingOwnerRoleBinding",
+			fmt.Sprintf("Granted %s ClusterRole %s in namespace %s", desired.Subjects[0].Name, desired.RoleRef.Name, namespace))
+		return nil
+	}
+
+	existing.Labels = desired.Labels
+	existing.Subjects = desired.Subjects
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("updating owner RoleBinding: %w", err)
+	}
+	return nil
+}
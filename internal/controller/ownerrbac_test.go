@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const ownerTestNamespace = "klaus-user-user-example-com"
+
+func ownerRBACTestReconciler(t *testing.T, objs ...client.Object) *KlausInstanceReconciler {
+	t.Helper()
+	r := testReconciler(t, testScheme(t, rbacv1.AddToScheme), objs...)
+	r.OwnerClusterRole = "klaus-operator-owner"
+	return r
+}
+
+func getOwnerRoleBinding(t *testing.T, r *KlausInstanceReconciler) (*rbacv1.RoleBinding, error) {
+	t.Helper()
+	rb := &rbacv1.RoleBinding{}
+	err := r.Get(context.Background(), types.NamespacedName{Name: resources.OwnerRoleBindingName, Namespace: ownerTestNamespace}, rb)
+	return rb, err
+}
+
+func TestReconcileOwnerRoleBinding_Lifecycle(t *testing.T) {
+	r := ownerRBACTestReconciler(t)
+	instance := testInstance()
+
+	if err := r.reconcileOwnerRoleBinding(context.Background(), instance, ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileOwnerRoleBinding() error = %v", err)
+	}
+	rb, err := getOwnerRoleBinding(t, r)
+	if err != nil {
+		t.Fatalf("expected owner RoleBinding to be created: %v", err)
+	}
+	if rb.RoleRef.Name != "klaus-operator-owner" || rb.Subjects[0].Name != "user@example.com" {
+		t.Errorf("RoleBinding = %+v", rb)
+	}
+
+	// A different ClusterRole recreates the binding since roleRef is immutable.
+	r.OwnerClusterRole = "edit"
+	r.OwnerSubjectPrefix = "oidc:"
+	if err := r.reconcileOwnerRoleBinding(context.Background(), instance, ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileOwnerRoleBinding() error = %v", err)
+	}
+	rb, err = getOwnerRoleBinding(t, r)
+	if err != nil {
+		t.Fatalf("fetching owner RoleBinding: %v", err)
+	}
+	if rb.RoleRef.Name != "edit" || rb.Subjects[0].Name != "oidc:user@example.com" {
+		t.Errorf("RoleBinding = %+v, want edit for the prefixed owner", rb)
+	}
+
+	// Disabling owner access removes the binding.
+	r.OwnerClusterRole = ""
+	if err := r.reconcileOwnerRoleBinding(context.Background(), instance, ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileOwnerRoleBinding() error = %v", err)
+	}
+	if _, err := getOwnerRoleBinding(t, r); !apierrors.IsNotFound(err) {
+		t.Errorf("expected owner RoleBinding to be deleted, got err = %v", err)
+	}
+}
+
+func TestReconcileOwnerRoleBinding_LeavesForeignBinding(t *testing.T) {
+	foreign := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: resources.OwnerRoleBindingName, Namespace: ownerTestNamespace},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"},
+	}
+	r := ownerRBACTestReconciler(t, foreign)
+
+	if err := r.reconcileOwnerRoleBinding(context.Background(), testInstance(), ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileOwnerRoleBinding() error = %v", err)
+	}
+	rb, err := getOwnerRoleBinding(t, r)
+	if err != nil {
+		t.Fatalf("fetching RoleBinding: %v", err)
+	}
+	if rb.RoleRef.Name != "admin" {
+		t.Errorf("RoleRef = %+v, want the unmanaged binding untouched", rb.RoleRef)
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// periodic is a manager.Runnable running fn immediately and then every
+// interval until the context is cancelled. A failed run is logged and
+// retried on the next tick. fn gets a logger named name in its context.
+type periodic struct {
+	// name names the logger of the runs.
+	name string
+
+	// interval is the run interval; defaultInterval when not positive.
+	interval        time.Duration
+	defaultInterval time.Duration
+
+	// action describes a run in the error log, e.g. "refreshing results".
+	action string
+
+	fn func(ctx context.Context) error
+}
+
+// Start implements manager.Runnable.
+func (p periodic) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName(p.name)
+	ctx = log.IntoContext(ctx, logger)
+
+	interval := p.interval
+	if interval <= 0 {
+		interval = p.defaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.fn(ctx); err != nil {
+			logger.Error(err, p.action+" failed")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPeriodic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runs := make(chan struct{}, 10)
+	p := periodic{
+		name:            "test",
+		defaultInterval: time.Millisecond,
+		action:          "testing",
+		fn: func(context.Context) error {
+			runs <- struct{}{}
+			return errors.New("transient")
+		},
+	}
+	done := make(chan error, 1)
+	go func() { done <- p.Start(ctx) }()
+
+	// A failed run is retried on the next tick.
+	for range 2 {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("expected periodic runs")
+		}
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start() = %v, want nil once cancelled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start() did not return after the context was cancelled")
+	}
+}
@@ -0,0 +1,15 @@
+package controller
+
+import (
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// applyDefaultPermission sets an unset permission mode to the operator's
+// policy default. The admission webhook normally sets the mode on create; this
+// covers clusters running without the webhook. It must only be applied to a
+// copy of the cached object.
+func applyDefaultPermission(instance *klausv1alpha1.KlausInstance, mode klausv1alpha1.PermissionMode) {
+	if instance.Spec.Claude.PermissionMode == "" {
+		instance.Spec.Claude.PermissionMode = mode
+	}
+}
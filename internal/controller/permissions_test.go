@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestApplyDefaultPermission(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  klausv1alpha1.PermissionMode
+		deflt klausv1alpha1.PermissionMode
+		want  klausv1alpha1.PermissionMode
+	}{
+		{name: "unset gets the default", deflt: klausv1alpha1.PermissionModeDefault, want: klausv1alpha1.PermissionModeDefault},
+		{name: "explicit mode is kept", mode: klausv1alpha1.PermissionModeBypass, deflt: klausv1alpha1.PermissionModeDefault, want: klausv1alpha1.PermissionModeBypass},
+		{name: "no operator default", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := &klausv1alpha1.KlausInstance{}
+			instance.Spec.Claude.PermissionMode = tt.mode
+			applyDefaultPermission(instance, tt.deflt)
+			if got := instance.Spec.Claude.PermissionMode; got != tt.want {
+				t.Errorf("PermissionMode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// AnnotationPersonalityRollout rolls an instance with the Manual personality
+// update policy forward to the current personality revision whenever its
+// value changes.
+const AnnotationPersonalityRollout = "klaus.giantswarm.io/personality-rollout"
+
+// personalityRolloutRecheck is how often an instance held back by a staged
+// personality rollout checks whether it may roll forward.
+const personalityRolloutRecheck = 30 * time.Second
+
+// DefaultPersonalityResyncInterval is how often the personalities of
+// instances with the Periodic refresh policy are re-resolved when no
+// interval is configured.
+const DefaultPersonalityResyncInterval = 15 * time.Minute
+
+// ReasonPersonalityUpdated is the reason of the event recorded when the
+// personality of an instance resolves to a new version or digest while
+// spec.personality is unchanged.
+const ReasonPersonalityUpdated = "PersonalityUpdated"
+
+// pinPersonalityRevision replaces the personality reference of the merged
+// spec with the revision recorded in status when the instance uses the
+// Manual update policy and neither spec.personality nor the rollout
+// annotation changed since that revision was resolved.
+func pinPersonalityRevision(instance, merged *klausv1alpha1.KlausInstance) {
+	if merged.Spec.PersonalityUpdatePolicy != klausv1alpha1.PersonalityUpdatePolicyManual || merged.Spec.Personality == "" {
+		return
+	}
+	status := instance.Status
+	if status.PersonalityRevision == "" ||
+		status.Personality != instance.Spec.Personality ||
+		status.PersonalityRollout != instance.Annotations[AnnotationPersonalityRollout] {
+		return
+	}
+	merged.Spec.Personality = status.PersonalityRevision
+}
+
+// recordPersonalityRevision records the resolved personality reference of
+// the merged spec in status.
+func recordPersonalityRevision(instance, merged *klausv1alpha1.KlausInstance) {
+	if merged.Spec.Personality == "" {
+		instance.Status.PersonalityRevision = ""
+		instance.Status.PersonalityRollout = ""
+		return
+	}
+	instance.Status.PersonalityRevision = merged.Spec.Personality
+	instance.Status.PersonalityRollout = instance.Annotations[AnnotationPersonalityRollout]
+}
+
+// pinPersonalityDigest pins the resolved personality reference of the
+// merged spec to a digest and records it in status.resolvedPersonality. A
+// reference keeps the digest recorded for it until
+// spec.personalityRefreshPolicy asks for it to be resolved again. It returns
+// how long until the next periodic resync is due, zero when none is.
+func (r *KlausInstanceReconciler) pinPersonalityDigest(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance) (time.Duration, error) {
+	ref := merged.Spec.Personality
+	if r.OCIClient == nil || ref == "" {
+		instance.Status.ResolvedPersonality = nil
+		return 0, nil
+	}
+	recorded := instance.Status.ResolvedPersonality
+
+	// References pinned to a digest, e.g. the revision kept by the Manual
+	// update policy, are mounted as they are.
+	if reference, digest, ok := strings.Cut(ref, "@"); ok {
+		if recorded == nil || recorded.Reference != reference || recorded.Digest != digest {
+			instance.Status.ResolvedPersonality = &klausv1alpha1.ResolvedPersonality{Reference: reference, Digest: digest}
+		}
+		return 0, nil
+	}
+
+	interval := r.PersonalityResyncInterval
+	if interval <= 0 {
+		interval = DefaultPersonalityResyncInterval
+	}
+	policy := merged.Spec.PersonalityRefreshPolicy
+	now := time.Now()
+	pinned := recorded
+	if recorded == nil || recorded.Reference != ref ||
+		refreshDue(policy, recorded.ResolvedAt, recorded.ResolvedGeneration, instance.Generation, now, interval) {
+		digest, err := r.OCIClient.Resolve(ctx, ref)
+		if err != nil {
+			return 0, fmt.Errorf("resolving the digest of personality %q: %w", ref, err)
+		}
+		if digest == "" {
+			// Without a known digest, e.g. offline failing open, the
+			// personality is mounted by tag and nothing is recorded.
+			instance.Status.ResolvedPersonality = nil
+			return 0, nil
+		}
+		pinned = &klausv1alpha1.ResolvedPersonality{
+			Reference:          ref,
+			Digest:             digest,
+			ResolvedAt:         &metav1.Time{Time: now},
+			ResolvedGeneration: instance.Generation,
+		}
+		if recorded != nil && (recorded.Reference != ref || recorded.Digest != digest) &&
+			instance.Status.Personality == instance.Spec.Personality {
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, ReasonPersonalityUpdated,
+				"Personality %s updated from %s@%s to %s@%s", instance.Spec.Personality,
+				recorded.Reference, recorded.Digest, ref, digest)
+		}
+	}
+	instance.Status.ResolvedPersonality = pinned
+	merged.Spec.Personality = pinDigest(ref, pinned.Digest)
+	return refreshDueIn(policy, pinned.ResolvedAt, now, interval), nil
+}
+
+// stagePersonalityRollout holds an instance on its current personality
+// revision while PersonalityRolloutBatch instances sharing its personality
+// reference are rolling forward to the newly resolved revision and their
+// Deployments have not completed the rollout yet. The instance state is no
+// gate: with the RollingUpdate strategy the old pod stays available and the
+// instance Running throughout. Changes of spec.personality itself and
+// stopped instances are never held. It returns how long to wait before
+// checking again, zero when the instance may roll forward.
+func (r *KlausInstanceReconciler) stagePersonalityRollout(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance) (time.Duration, error) {
+	current := instance.Status.PersonalityRevision
+	candidate := merged.Spec.Personality
+	if r.PersonalityRolloutBatch == nil || current == "" || candidate == current || resources.IsStopped(merged) ||
+		instance.Status.Personality != instance.Spec.Personality {
+		instance.Status.PersonalityPendingRevision = ""
+		return 0, nil
+	}
+
+	var instances klausv1alpha1.KlausInstanceList
+	if err := r.List(ctx, &instances); err != nil {
+		return 0, fmt.Errorf("listing KlausInstances: %w", err)
+	}
+	var total, rolling int
+	for i := range instances.Items {
+		other := &instances.Items[i]
+		if other.Spec.Personality != instance.Spec.Personality {
+			continue
+		}
+		total++
+		if other.Status.PersonalityRevision != candidate || resources.IsStopped(other) {
+			continue
+		}
+		done, err := r.deploymentRolledOut(ctx, other)
+		if err != nil {
+			return 0, err
+		}
+		if !done {
+			rolling++
+		}
+	}
+	batch, err := intstr.GetScaledValueFromIntOrPercent(r.PersonalityRolloutBatch, total, true)
+	if err != nil {
+		return 0, fmt.Errorf("invalid personality rollout batch: %w", err)
+	}
+	if rolling < max(batch, 1) {
+		instance.Status.PersonalityPendingRevision = ""
+		return 0, nil
+	}
+
+	merged.Spec.Personality = current
+	if instance.Status.PersonalityPendingRevision != candidate {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "PersonalityRolloutWaiting",
+			fmt.Sprintf("Waiting for %d instance(s) to complete their rollout to personality %s", rolling, candidate))
+	}
+	instance.Status.PersonalityPendingRevision = candidate
+	return personalityRolloutRecheck, nil
+}
+
+// deploymentRolledOut reports whether the Deployment of an instance
+// completed its latest rollout: the controller observed its generation and
+// every replica is updated and available. The Deployment is read with the
+// APIReader, as the cache may lag behind the rollout. A missing Deployment
+// has not rolled out yet.
+func (r *KlausInstanceReconciler) deploymentRolledOut(ctx context.Context, instance *klausv1alpha1.KlausInstance) (bool, error) {
+	reader := client.Reader(r.Client)
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	var dep appsv1.Deployment
+	key := client.ObjectKey{Namespace: r.childNamespace(instance), Name: resources.DeploymentName(instance)}
+	if err := reader.Get(ctx, key, &dep); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("getting Deployment %s: %w", key, err)
+	}
+	status := dep.Status
+	return status.ObservedGeneration >= dep.Generation &&
+		status.UpdatedReplicas == status.Replicas && status.Replicas == status.AvailableReplicas, nil
+}
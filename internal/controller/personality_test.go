@@ -0,0 +1,381 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const (
+	testPersonalityLatest = "gsoci.azurecr.io/giantswarm/personalities/go-dev:latest"
+	testPersonalityV1     = "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.0.0"
+)
+
+func pinnedInstance(policy klausv1alpha1.PersonalityUpdatePolicy) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("test", "test@example.com")
+	instance.Spec.Personality = testPersonalityLatest
+	instance.Spec.PersonalityUpdatePolicy = policy
+	instance.Status.Personality = testPersonalityLatest
+	instance.Status.PersonalityRevision = testPersonalityV1
+	return instance
+}
+
+func TestPinPersonalityRevision(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*klausv1alpha1.KlausInstance)
+		want   string
+	}{
+		{
+			name: "manual keeps the recorded revision",
+			want: testPersonalityV1,
+		},
+		{
+			name: "auto follows the reference",
+			mutate: func(i *klausv1alpha1.KlausInstance) {
+				i.Spec.PersonalityUpdatePolicy = klausv1alpha1.PersonalityUpdatePolicyAuto
+			},
+			want: testPersonalityLatest,
+		},
+		{
+			name: "changed reference rolls forward",
+			mutate: func(i *klausv1alpha1.KlausInstance) {
+				i.Spec.Personality = "gsoci.azurecr.io/giantswarm/personalities/go-dev:v2.0.0"
+			},
+			want: "gsoci.azurecr.io/giantswarm/personalities/go-dev:v2.0.0",
+		},
+		{
+			name: "changed rollout annotation rolls forward",
+			mutate: func(i *klausv1alpha1.KlausInstance) {
+				i.Annotations = map[string]string{AnnotationPersonalityRollout: "2026-10-14"}
+			},
+			want: testPersonalityLatest,
+		},
+		{
+			name: "nothing recorded yet",
+			mutate: func(i *klausv1alpha1.KlausInstance) {
+				i.Status.PersonalityRevision = ""
+			},
+			want: testPersonalityLatest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := pinnedInstance(klausv1alpha1.PersonalityUpdatePolicyManual)
+			if tt.mutate != nil {
+				tt.mutate(instance)
+			}
+			merged := instance.DeepCopy()
+
+			pinPersonalityRevision(instance, merged)
+
+			if merged.Spec.Personality != tt.want {
+				t.Errorf("personality = %q, want %q", merged.Spec.Personality, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordPersonalityRevision(t *testing.T) {
+	instance := pinnedInstance(klausv1alpha1.PersonalityUpdatePolicyManual)
+	instance.Annotations = map[string]string{AnnotationPersonalityRollout: "1"}
+	merged := instance.DeepCopy()
+	merged.Spec.Personality = "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.1.0"
+
+	recordPersonalityRevision(instance, merged)
+
+	if instance.Status.PersonalityRevision != "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.1.0" {
+		t.Errorf("PersonalityRevision = %q, want the resolved reference", instance.Status.PersonalityRevision)
+	}
+	if instance.Status.PersonalityRollout != "1" {
+		t.Errorf("PersonalityRollout = %q, want the annotation value", instance.Status.PersonalityRollout)
+	}
+
+	// The rollout annotation is now handled, so the revision is pinned again.
+	instance.Status.Personality = instance.Spec.Personality
+	next := instance.DeepCopy()
+	pinPersonalityRevision(instance, next)
+	if next.Spec.Personality != "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.1.0" {
+		t.Errorf("personality = %q, want the recorded revision", next.Spec.Personality)
+	}
+
+	merged.Spec.Personality = ""
+	recordPersonalityRevision(instance, merged)
+	if instance.Status.PersonalityRevision != "" || instance.Status.PersonalityRollout != "" {
+		t.Errorf("status = %+v, want no revision without personality", instance.Status)
+	}
+}
+
+// rolloutInstance returns an instance of the go-dev cohort on revision,
+// Running throughout the rollout as with the RollingUpdate strategy.
+func rolloutInstance(name, revision string) *klausv1alpha1.KlausInstance {
+	instance := pinnedInstance("")
+	instance.Name = name
+	instance.Status.PersonalityRevision = revision
+	instance.Status.State = klausv1alpha1.InstanceStateRunning
+	return instance
+}
+
+// rolloutDeployment returns the Deployment of instance, with the rollout of
+// its latest generation complete or, with the old pod still available, in
+// progress.
+func rolloutDeployment(instance *klausv1alpha1.KlausInstance, complete bool) *appsv1.Deployment {
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:       resources.DeploymentName(instance),
+		Namespace:  resources.InstanceNamespace(instance, false),
+		Generation: 2,
+	}}
+	dep.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if !complete {
+		dep.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1}
+	}
+	return dep
+}
+
+func TestStagePersonalityRollout(t *testing.T) {
+	const v2 = "gsoci.azurecr.io/giantswarm/personalities/go-dev:v2.0.0"
+	b, c, d := rolloutInstance("b", v2), rolloutInstance("c", testPersonalityV1), rolloutInstance("d", testPersonalityV1)
+	unobserved := rolloutDeployment(b, true)
+	unobserved.Generation = 3
+	tests := []struct {
+		name     string
+		batch    *intstr.IntOrString
+		others   []client.Object
+		wantHeld bool
+	}{
+		{
+			name:   "no rollout batch",
+			others: []client.Object{b, rolloutDeployment(b, false)},
+		},
+		{
+			name:   "first of the cohort proceeds",
+			batch:  ptr.To(intstr.FromInt32(1)),
+			others: []client.Object{c, rolloutDeployment(c, true)},
+		},
+		{
+			name:   "updated instances rolled out",
+			batch:  ptr.To(intstr.FromInt32(1)),
+			others: []client.Object{b, rolloutDeployment(b, true)},
+		},
+		{
+			name:     "batch is rolling",
+			batch:    ptr.To(intstr.FromInt32(1)),
+			others:   []client.Object{b, rolloutDeployment(b, false), c, rolloutDeployment(c, true)},
+			wantHeld: true,
+		},
+		{
+			name:     "rollout not observed yet",
+			batch:    ptr.To(intstr.FromInt32(1)),
+			others:   []client.Object{b, unobserved, c, rolloutDeployment(c, true)},
+			wantHeld: true,
+		},
+		{
+			name:     "Deployment not created yet",
+			batch:    ptr.To(intstr.FromInt32(1)),
+			others:   []client.Object{b, c, rolloutDeployment(c, true)},
+			wantHeld: true,
+		},
+		{
+			name:  "percentage leaves room",
+			batch: ptr.To(intstr.FromString("50%")),
+			others: []client.Object{
+				b, rolloutDeployment(b, false),
+				c, rolloutDeployment(c, true),
+				d, rolloutDeployment(d, true),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := rolloutInstance("a", testPersonalityV1)
+			objs := []client.Object{instance}
+			for _, obj := range tt.others {
+				objs = append(objs, obj.DeepCopyObject().(client.Object))
+			}
+			scheme := testScheme(t)
+			if err := appsv1.AddToScheme(scheme); err != nil {
+				t.Fatalf("adding appsv1 to scheme: %v", err)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			r := &KlausInstanceReconciler{Client: c, APIReader: c, Recorder: record.NewFakeRecorder(10), PersonalityRolloutBatch: tt.batch}
+			merged := instance.DeepCopy()
+			merged.Spec.Personality = v2
+
+			wait, err := r.stagePersonalityRollout(context.Background(), instance, merged)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantHeld {
+				if merged.Spec.Personality != testPersonalityV1 || wait == 0 {
+					t.Errorf("personality = %q, wait = %v; want the instance held on %s", merged.Spec.Personality, wait, testPersonalityV1)
+				}
+				if instance.Status.PersonalityPendingRevision != v2 {
+					t.Errorf("PersonalityPendingRevision = %q, want %q", instance.Status.PersonalityPendingRevision, v2)
+				}
+				return
+			}
+			if merged.Spec.Personality != v2 || wait != 0 {
+				t.Errorf("personality = %q, wait = %v; want the instance to roll forward", merged.Spec.Personality, wait)
+			}
+			if instance.Status.PersonalityPendingRevision != "" {
+				t.Errorf("PersonalityPendingRevision = %q, want empty", instance.Status.PersonalityPendingRevision)
+			}
+		})
+	}
+}
+
+func TestStagePersonalityRollout_ChangedReference(t *testing.T) {
+	instance := rolloutInstance("a", testPersonalityV1)
+	instance.Spec.Personality = "gsoci.azurecr.io/giantswarm/personalities/sre:v1.0.0"
+	r := &KlausInstanceReconciler{PersonalityRolloutBatch: ptr.To(intstr.FromInt32(1))}
+	merged := instance.DeepCopy()
+
+	wait, err := r.stagePersonalityRollout(context.Background(), instance, merged)
+	if err != nil || wait != 0 || merged.Spec.Personality != instance.Spec.Personality {
+		t.Errorf("stagePersonalityRollout() = %v, %v, personality %q; want a changed reference to apply immediately",
+			wait, err, merged.Spec.Personality)
+	}
+}
+
+// pinPersonalityOnce runs pinPersonalityDigest on a copy of instance with
+// the personality resolved to testPersonalityV1, as Reconcile does with the
+// merged spec, and returns the pinned copy.
+func pinPersonalityOnce(t *testing.T, r *KlausInstanceReconciler, instance *klausv1alpha1.KlausInstance) (*klausv1alpha1.KlausInstance, time.Duration) {
+	t.Helper()
+	merged := instance.DeepCopy()
+	merged.Spec.Personality = testPersonalityV1
+	resyncIn, err := r.pinPersonalityDigest(context.Background(), instance, merged)
+	if err != nil {
+		t.Fatalf("pinPersonalityDigest: %v", err)
+	}
+	return merged, resyncIn
+}
+
+func TestPinPersonalityDigest(t *testing.T) {
+	digest := digestV1
+	recorder := record.NewFakeRecorder(10)
+	r := &KlausInstanceReconciler{
+		Recorder: recorder,
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return digest, nil
+		}},
+	}
+	instance := pinnedInstance(klausv1alpha1.PersonalityUpdatePolicyAuto)
+	instance.Generation = 1
+
+	merged, resyncIn := pinPersonalityOnce(t, r, instance)
+	if want := testPersonalityV1 + "@" + digestV1; merged.Spec.Personality != want || resyncIn != 0 {
+		t.Errorf("personality = %q, resyncIn = %s; want %q without a resync", merged.Spec.Personality, resyncIn, want)
+	}
+	if got := instance.Status.ResolvedPersonality; got == nil || got.Reference != testPersonalityV1 ||
+		got.Digest != digestV1 || got.ResolvedGeneration != 1 || got.ResolvedAt == nil {
+		t.Errorf("resolvedPersonality = %+v", got)
+	}
+
+	// The tag moves: the recorded digest is kept until the spec changes.
+	digest = digestV2
+	if merged, _ = pinPersonalityOnce(t, r, instance); !strings.HasSuffix(merged.Spec.Personality, digestV1) {
+		t.Errorf("personality = %q, want the recorded digest without a spec change", merged.Spec.Personality)
+	}
+	instance.Generation = 2
+	if merged, _ = pinPersonalityOnce(t, r, instance); !strings.HasSuffix(merged.Spec.Personality, digestV2) {
+		t.Errorf("personality = %q, want the new digest after a spec change", merged.Spec.Personality)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, ReasonPersonalityUpdated) || !strings.Contains(event, digestV2) {
+			t.Errorf("event = %q, want the personality update", event)
+		}
+	default:
+		t.Error("expected a PersonalityUpdated event")
+	}
+
+	// A changed spec.personality is not reported as an update.
+	instance.Generation = 3
+	instance.Spec.Personality = "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1"
+	digest = digestV1
+	pinPersonalityOnce(t, r, instance)
+	select {
+	case event := <-recorder.Events:
+		t.Errorf("unexpected event %q for a changed spec.personality", event)
+	default:
+	}
+}
+
+func TestPinPersonalityDigest_DigestReference(t *testing.T) {
+	r := &KlausInstanceReconciler{
+		Recorder: record.NewFakeRecorder(10),
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return "", errors.New("unexpected resolution")
+		}},
+	}
+	instance := pinnedInstance(klausv1alpha1.PersonalityUpdatePolicyManual)
+	merged := instance.DeepCopy()
+	merged.Spec.Personality = testPersonalityV1 + "@" + digestV1
+	if _, err := r.pinPersonalityDigest(context.Background(), instance, merged); err != nil {
+		t.Fatalf("pinPersonalityDigest: %v", err)
+	}
+	if got := instance.Status.ResolvedPersonality; got == nil || got.Reference != testPersonalityV1 || got.Digest != digestV1 {
+		t.Errorf("resolvedPersonality = %+v, want the pinned digest recorded", got)
+	}
+}
+
+func TestPinPersonalityDigest_Never(t *testing.T) {
+	digest := digestV1
+	r := &KlausInstanceReconciler{
+		Recorder: record.NewFakeRecorder(10),
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return digest, nil
+		}},
+	}
+	instance := pinnedInstance(klausv1alpha1.PersonalityUpdatePolicyAuto)
+	instance.Spec.PersonalityRefreshPolicy = klausv1alpha1.RefreshPolicyNever
+	pinPersonalityOnce(t, r, instance)
+
+	digest = digestV2
+	instance.Generation = 2
+	if merged, _ := pinPersonalityOnce(t, r, instance); !strings.HasSuffix(merged.Spec.Personality, digestV1) {
+		t.Errorf("personality = %q, want the recorded digest", merged.Spec.Personality)
+	}
+}
+
+func TestPinPersonalityDigest_Periodic(t *testing.T) {
+	digest := digestV1
+	r := &KlausInstanceReconciler{
+		Recorder: record.NewFakeRecorder(10),
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return digest, nil
+		}},
+		PersonalityResyncInterval: 10 * time.Minute,
+	}
+	instance := pinnedInstance(klausv1alpha1.PersonalityUpdatePolicyAuto)
+	instance.Spec.PersonalityRefreshPolicy = klausv1alpha1.RefreshPolicyPeriodic
+	if _, resyncIn := pinPersonalityOnce(t, r, instance); resyncIn <= 9*time.Minute || resyncIn > 10*time.Minute {
+		t.Errorf("resyncIn = %s, want the resync interval", resyncIn)
+	}
+
+	digest = digestV2
+	if merged, _ := pinPersonalityOnce(t, r, instance); !strings.HasSuffix(merged.Spec.Personality, digestV1) {
+		t.Errorf("personality = %q, want the recorded digest before the interval elapsed", merged.Spec.Personality)
+	}
+	instance.Status.ResolvedPersonality.ResolvedAt = &metav1.Time{Time: time.Now().Add(-11 * time.Minute)}
+	if merged, _ := pinPersonalityOnce(t, r, instance); !strings.HasSuffix(merged.Spec.Personality, digestV2) {
+		t.Errorf("personality = %q, want the new digest once the interval elapsed", merged.Spec.Personality)
+	}
+}
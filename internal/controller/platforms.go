@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// platformInspectTimeout bounds the registry round trips of an image
+// inspection so a slow registry does not stall reconciliation.
+const platformInspectTimeout = 10 * time.Second
+
+// Docker distribution media types, which are still what most registries
+// serve for images built with docker buildx.
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// PlatformInspector reads the platforms a container image is published for.
+type PlatformInspector interface {
+	// ImagePlatforms returns the image platforms in os/architecture[/variant]
+	// form. It returns (nil, nil) when the platforms cannot be determined,
+	// e.g. for references without a registry host.
+	ImagePlatforms(ctx context.Context, image string) ([]string, error)
+}
+
+// registryPlatformInspector implements PlatformInspector against the image
+// registry, using the Docker credentials mounted into the operator.
+type registryPlatformInspector struct {
+	client remote.Client
+}
+
+// NewRegistryPlatformInspector creates a PlatformInspector that reads image
+// indexes and configs from the registry. Credentials are resolved from the
+// Docker config; without one, registries are accessed anonymously.
+func NewRegistryPlatformInspector() PlatformInspector {
+	client := &auth.Client{
+		Client: http.DefaultClient,
+		Cache:  auth.NewCache(),
+	}
+	if store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{}); err == nil {
+		client.Credential = credentials.Credential(store)
+	}
+	return &registryPlatformInspector{client: client}
+}
+
+func (p *registryPlatformInspector) ImagePlatforms(ctx context.Context, image string) ([]string, error) {
+	repo, err := remote.NewRepository(image)
+	if err != nil {
+		// Short names such as "busybox" rely on the container runtime's
+		// default registry and cannot be inspected.
+		return nil, nil
+	}
+	repo.Client = p.client
+	return imagePlatforms(ctx, repo, repo.Reference.Reference)
+}
+
+// imagePlatforms returns the platforms of the image at reference in target.
+// Image indexes list one platform per manifest; attestation manifests
+// (platform unknown/unknown) are skipped. A single-platform image reports the
+// platform of its image config. Non-image artifacts yield (nil, nil).
+func imagePlatforms(ctx context.Context, target oras.ReadOnlyTarget, reference string) ([]string, error) {
+	desc, manifestJSON, err := oras.FetchBytes(ctx, target, reference, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest: %w", err)
+	}
+
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageIndex, mediaTypeDockerManifestList:
+		var index ocispec.Index
+		if err := json.Unmarshal(manifestJSON, &index); err != nil {
+			return nil, fmt.Errorf("decoding image index: %w", err)
+		}
+		var platforms []string
+		for _, m := range index.Manifests {
+			if m.Platform == nil || m.Platform.OS == "" || m.Platform.OS == "unknown" {
+				continue
+			}
+			platforms = append(platforms, resources.FormatPlatform(m.Platform.OS, m.Platform.Architecture, m.Platform.Variant))
+		}
+		slices.Sort(platforms)
+		return slices.Compact(platforms), nil
+
+	case ocispec.MediaTypeImageManifest, mediaTypeDockerManifest:
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+			return nil, fmt.Errorf("decoding image manifest: %w", err)
+		}
+		configJSON, err := content.FetchAll(ctx, target, manifest.Config)
+		if err != nil {
+			return nil, fmt.Errorf("fetching image config: %w", err)
+		}
+		var config ocispec.Image
+		if err := json.Unmarshal(configJSON, &config); err != nil {
+			return nil, fmt.Errorf("decoding image config: %w", err)
+		}
+		if config.OS == "" || config.Architecture == "" {
+			return nil, nil
+		}
+		return []string{resources.FormatPlatform(config.OS, config.Architecture, config.Variant)}, nil
+	}
+	return nil, nil
+}
+
+// reconcileImagePlatforms inspects the resolved container image and returns
+// the linux architectures the pod must be restricted to. The inspected
+// platforms are recorded in status and refreshed only when the image
+// changes. Inspection failures are logged and leave the pod unrestricted.
+//
+// When the image is not published for linux, or for none of the
+// architectures the cluster nodes run, the ArchitectureSupported condition is
+// set to False and an error is returned so the reconcile fails clearly
+// instead of rendering an unschedulable Deployment.
+func (r *KlausInstanceReconciler) reconcileImagePlatforms(ctx context.Context, instance *klausv1alpha1.KlausInstance, image string) ([]string, error) {
+	if r.PlatformInspector == nil {
+		return nil, nil
+	}
+	logger := log.FromContext(ctx)
+
+	observed := instance.Status.ImagePlatforms
+	if observed == nil || observed.Image != image {
+		inspectCtx, cancel := context.WithTimeout(ctx, platformInspectTimeout)
+		defer cancel()
+
+		platforms, err := r.PlatformInspector.ImagePlatforms(inspectCtx, image)
+		if err != nil {
+			logger.Info("image platform inspection failed", "instance", instance.Name, "image", image, "error", err.Error())
+			platforms = nil
+		}
+		if len(platforms) == 0 {
+			instance.Status.ImagePlatforms = nil
+			apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionArchitectureSupported)
+			return nil, nil
+		}
+		now := metav1.Now()
+		observed = &klausv1alpha1.ImagePlatforms{Image: image, Platforms: platforms, ObservedAt: &now}
+		instance.Status.ImagePlatforms = observed
+	}
+
+	archs := resources.LinuxArchitectures(observed.Platforms)
+	if len(archs) == 0 {
+		return nil, r.unsupportedArchitecture(instance, fmt.Sprintf("image %s is not published for linux (platforms: %s)",
+			image, strings.Join(observed.Platforms, ", ")))
+	}
+
+	nodeArchs, err := r.nodeArchitectures(ctx)
+	if err != nil {
+		logger.Info("listing node architectures failed", "error", err.Error())
+	} else if len(nodeArchs) > 0 && !slices.ContainsFunc(archs, func(arch string) bool { return slices.Contains(nodeArchs, arch) }) {
+		return nil, r.unsupportedArchitecture(instance, fmt.Sprintf("image %s supports architectures %s but cluster nodes run %s",
+			image, strings.Join(archs, ", "), strings.Join(nodeArchs, ", ")))
+	}
+
+	setCondition(instance, ConditionArchitectureSupported, metav1.ConditionTrue, "Supported",
+		"Scheduling restricted to architectures: "+strings.Join(archs, ", "))
+	return archs, nil
+}
+
+func (r *KlausInstanceReconciler) unsupportedArchitecture(instance *klausv1alpha1.KlausInstance, msg string) error {
+	setCondition(instance, ConditionArchitectureSupported, metav1.ConditionFalse, "UnsupportedArchitecture", msg)
+	return errors.New(msg)
+}
+
+// nodeArchitectures returns the sorted architectures of the cluster nodes.
+// Only node metadata is listed, from the cache, so the operator keeps a
+// metadata informer rather than full Node objects and does not list all
+// nodes from the API server on every reconcile.
+func (r *KlausInstanceReconciler) nodeArchitectures(ctx context.Context) ([]string, error) {
+	nodes := &metav1.PartialObjectMetadataList{}
+	nodes.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
+	if err := r.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	var archs []string
+	for _, node := range nodes.Items {
+		if arch := node.Labels[resources.LabelArch]; arch != "" && !slices.Contains(archs, arch) {
+			archs = append(archs, arch)
+		}
+	}
+	slices.Sort(archs)
+	return archs, nil
+}
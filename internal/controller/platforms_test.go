@@ -0,0 +1,203 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// mockPlatformInspector is a test double for PlatformInspector.
+type mockPlatformInspector struct {
+	platforms []string
+	err       error
+	calls     int
+}
+
+func (m *mockPlatformInspector) ImagePlatforms(_ context.Context, _ string) ([]string, error) {
+	m.calls++
+	return m.platforms, m.err
+}
+
+func pushJSON(t *testing.T, store *memory.Store, mediaType string, v any) ocispec.Descriptor {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshaling %s: %v", mediaType, err)
+	}
+	desc, err := oras.PushBytes(context.Background(), store, mediaType, data)
+	if err != nil {
+		t.Fatalf("pushing %s: %v", mediaType, err)
+	}
+	return desc
+}
+
+func pushImage(t *testing.T, store *memory.Store, os, arch, variant string) ocispec.Descriptor {
+	t.Helper()
+	config := ocispec.Image{Platform: ocispec.Platform{OS: os, Architecture: arch, Variant: variant}}
+	configDesc := pushJSON(t, store, ocispec.MediaTypeImageConfig, config)
+	return pushJSON(t, store, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    []ocispec.Descriptor{},
+	})
+}
+
+func TestImagePlatforms_Index(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	amd64 := pushImage(t, store, "linux", "amd64", "")
+	amd64.Platform = &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := pushImage(t, store, "linux", "arm64", "v8")
+	arm64.Platform = &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	attestation := pushImage(t, store, "unknown", "unknown", "")
+	attestation.Platform = &ocispec.Platform{OS: "unknown", Architecture: "unknown"}
+
+	indexDesc := pushJSON(t, store, ocispec.MediaTypeImageIndex, ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{arm64, amd64, attestation},
+	})
+	if err := store.Tag(ctx, indexDesc, "v1"); err != nil {
+		t.Fatalf("tagging index: %v", err)
+	}
+
+	got, err := imagePlatforms(ctx, store, "v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"linux/amd64", "linux/arm64/v8"}
+	if !slices.Equal(got, want) {
+		t.Errorf("imagePlatforms() = %v, want %v", got, want)
+	}
+}
+
+func TestImagePlatforms_SinglePlatformImage(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	desc := pushImage(t, store, "linux", "amd64", "")
+	if err := store.Tag(ctx, desc, "v1"); err != nil {
+		t.Fatalf("tagging manifest: %v", err)
+	}
+
+	got, err := imagePlatforms(ctx, store, "v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(got, []string{"linux/amd64"}) {
+		t.Errorf("imagePlatforms() = %v, want [linux/amd64]", got)
+	}
+}
+
+func TestImagePlatforms_MissingReference(t *testing.T) {
+	if _, err := imagePlatforms(context.Background(), memory.New(), "missing"); err == nil {
+		t.Error("expected error for missing reference")
+	}
+}
+
+func node(name, arch string) *corev1.Node {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{"kubernetes.io/arch": arch},
+	}}
+}
+
+func platformReconciler(t *testing.T, inspector PlatformInspector, nodes ...client.Object) *KlausInstanceReconciler {
+	t.Helper()
+	r := testReconciler(t, testScheme(t), nodes...)
+	r.PlatformInspector = inspector
+	return r
+}
+
+func TestReconcileImagePlatforms_RestrictsToSupportedArchitectures(t *testing.T) {
+	inspector := &mockPlatformInspector{platforms: []string{"linux/amd64"}}
+	r := platformReconciler(t, inspector, node("a", "amd64"), node("b", "arm64"))
+	instance := &klausv1alpha1.KlausInstance{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	archs, err := r.reconcileImagePlatforms(context.Background(), instance, "example.com/klaus-go:1.25")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(archs, []string{"amd64"}) {
+		t.Errorf("archs = %v, want [amd64]", archs)
+	}
+	status := instance.Status.ImagePlatforms
+	if status == nil || status.Image != "example.com/klaus-go:1.25" || !slices.Equal(status.Platforms, []string{"linux/amd64"}) {
+		t.Errorf("unexpected image platforms status: %+v", status)
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionArchitectureSupported)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected ArchitectureSupported=True, got %+v", cond)
+	}
+
+	// The same image is not inspected again.
+	if _, err := r.reconcileImagePlatforms(context.Background(), instance, "example.com/klaus-go:1.25"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inspector.calls != 1 {
+		t.Errorf("inspector called %d times, want 1", inspector.calls)
+	}
+}
+
+func TestReconcileImagePlatforms_NoMatchingNodes(t *testing.T) {
+	inspector := &mockPlatformInspector{platforms: []string{"linux/arm64"}}
+	r := platformReconciler(t, inspector, node("a", "amd64"))
+	instance := &klausv1alpha1.KlausInstance{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	if _, err := r.reconcileImagePlatforms(context.Background(), instance, "example.com/klaus-go:1.25"); err == nil {
+		t.Fatal("expected error for unsupported architecture")
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionArchitectureSupported)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "UnsupportedArchitecture" {
+		t.Errorf("expected UnsupportedArchitecture condition, got %+v", cond)
+	}
+}
+
+func TestReconcileImagePlatforms_NotLinux(t *testing.T) {
+	r := platformReconciler(t, &mockPlatformInspector{platforms: []string{"windows/amd64"}})
+	instance := &klausv1alpha1.KlausInstance{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+
+	if _, err := r.reconcileImagePlatforms(context.Background(), instance, "example.com/klaus-win:1"); err == nil {
+		t.Fatal("expected error for non-linux image")
+	}
+}
+
+func TestReconcileImagePlatforms_InspectionFailureIsNotFatal(t *testing.T) {
+	r := platformReconciler(t, &mockPlatformInspector{err: errors.New("registry unavailable")})
+	instance := &klausv1alpha1.KlausInstance{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	instance.Status.ImagePlatforms = &klausv1alpha1.ImagePlatforms{Image: "old", Platforms: []string{"linux/amd64"}}
+
+	archs, err := r.reconcileImagePlatforms(context.Background(), instance, "example.com/klaus-go:1.25")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archs != nil {
+		t.Errorf("archs = %v, want none", archs)
+	}
+	if instance.Status.ImagePlatforms != nil {
+		t.Errorf("expected stale platforms to be cleared, got %+v", instance.Status.ImagePlatforms)
+	}
+}
+
+func TestReconcileImagePlatforms_NilInspector(t *testing.T) {
+	r := &KlausInstanceReconciler{}
+	instance := &klausv1alpha1.KlausInstance{}
+	archs, err := r.reconcileImagePlatforms(context.Background(), instance, "example.com/klaus:1")
+	if err != nil || archs != nil {
+		t.Errorf("expected no-op, got %v, %v", archs, err)
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// DefaultPluginRefreshInterval is how often the tags of plugins with the
+// Periodic refresh policy are re-resolved when no interval is configured.
+const DefaultPluginRefreshInterval = time.Hour
+
+// ReasonPluginDigestChanged is the reason of the event recorded when a
+// plugin tag resolves to a new digest.
+const ReasonPluginDigestChanged = "PluginDigestChanged"
+
+// pinPluginDigests pins the tag-based plugin references of the merged spec
+// to a digest and records them in status.resolvedPlugins. A tag keeps the
+// digest recorded for it until spec.pluginRefreshPolicy asks for it to be
+// resolved again; a tag resolving to a new digest is reported with an event.
+// It returns how long until the next periodic refresh is due, zero when
+// none is.
+func (r *KlausInstanceReconciler) pinPluginDigests(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance) (time.Duration, error) {
+	if r.OCIClient == nil {
+		return 0, nil
+	}
+	interval := r.PluginRefreshInterval
+	if interval <= 0 {
+		interval = DefaultPluginRefreshInterval
+	}
+
+	recorded := map[string]klausv1alpha1.ResolvedPlugin{}
+	for _, p := range instance.Status.ResolvedPlugins {
+		if p.Tag != "" {
+			recorded[p.Repository+":"+p.Tag] = p
+		}
+	}
+
+	now := time.Now()
+	var refreshIn time.Duration
+	resolved := make([]klausv1alpha1.ResolvedPlugin, 0, len(merged.Spec.Plugins))
+	for i, plugin := range merged.Spec.Plugins {
+		if plugin.Tag == "" {
+			resolved = append(resolved, klausv1alpha1.ResolvedPlugin{Repository: plugin.Repository, Digest: plugin.Digest})
+			continue
+		}
+		ref := resources.PluginImageReference(plugin)
+		pinned, ok := recorded[ref]
+		policy := merged.Spec.PluginRefreshPolicy
+		if !ok || refreshDue(policy, pinned.ResolvedAt, pinned.ResolvedGeneration, instance.Generation, now, interval) {
+			digest, err := r.OCIClient.Resolve(ctx, ref)
+			if err != nil {
+				return 0, fmt.Errorf("resolving the digest of plugin %q: %w", ref, err)
+			}
+			if digest == "" {
+				// No digest is known offline failing open: mount the tag.
+				continue
+			}
+			if ok && pinned.Digest != digest {
+				r.Recorder.Eventf(instance, corev1.EventTypeNormal, ReasonPluginDigestChanged,
+					"Plugin %s moved from %s to %s", ref, pinned.Digest, digest)
+			}
+			pinned = klausv1alpha1.ResolvedPlugin{
+				Repository:         plugin.Repository,
+				Tag:                plugin.Tag,
+				Digest:             digest,
+				ResolvedAt:         &metav1.Time{Time: now},
+				ResolvedGeneration: instance.Generation,
+			}
+		}
+		resolved = append(resolved, pinned)
+		merged.Spec.Plugins[i].Tag = ""
+		merged.Spec.Plugins[i].Digest = pinned.Digest
+
+		if due := refreshDueIn(policy, pinned.ResolvedAt, now, interval); due > 0 && (refreshIn == 0 || due < refreshIn) {
+			refreshIn = due
+		}
+	}
+	instance.Status.ResolvedPlugins = resolved
+	if len(resolved) == 0 {
+		instance.Status.ResolvedPlugins = nil
+	}
+	return refreshIn, nil
+}
+
+// refreshDue reports whether a digest recorded at resolvedAt and
+// resolvedGeneration is to be resolved again under policy.
+func refreshDue(policy klausv1alpha1.RefreshPolicy, resolvedAt *metav1.Time, resolvedGeneration, generation int64, now time.Time, interval time.Duration) bool {
+	switch policy {
+	case klausv1alpha1.RefreshPolicyNever:
+		return false
+	case klausv1alpha1.RefreshPolicyPeriodic:
+		if resolvedAt == nil || !now.Before(resolvedAt.Add(interval)) {
+			return true
+		}
+	}
+	return resolvedGeneration != generation
+}
+
+// refreshDueIn returns how long until a digest resolved at resolvedAt is to
+// be resolved again under the Periodic policy, zero for other policies.
+func refreshDueIn(policy klausv1alpha1.RefreshPolicy, resolvedAt *metav1.Time, now time.Time, interval time.Duration) time.Duration {
+	if policy != klausv1alpha1.RefreshPolicyPeriodic || resolvedAt == nil {
+		return 0
+	}
+	return max(resolvedAt.Add(interval).Sub(now), time.Second)
+}
+
+// pinMCPServerDigests pins the tag-based OCI artifacts of stdio MCP
+// servers, keyed by server name, to the digests their tags resolve to, so
+// every pod of an instance mounts the same artifact. Unlike plugins, the
+// digests are not recorded: a moved tag rolls the instance out once the
+// resolver cache expires.
+func (r *KlausInstanceReconciler) pinMCPServerDigests(ctx context.Context, artifacts map[string]string) error {
+	if r.OCIClient == nil {
+		return nil
+	}
+	for name, ref := range artifacts {
+		if strings.Contains(ref, "@") {
+			continue
+		}
+		digest, err := r.OCIClient.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("resolving the digest of MCP server %q artifact %q: %w", name, ref, err)
+		}
+		artifacts[name] = pinDigest(ref, digest)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	digestV1 = "sha256:aaaa"
+	digestV2 = "sha256:bbbb"
+)
+
+func pluginDigestInstance(policy klausv1alpha1.RefreshPolicy) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("agent", testOwner)
+	instance.Generation = 1
+	instance.Spec.PluginRefreshPolicy = policy
+	instance.Spec.Plugins = []klausv1alpha1.PluginReference{
+		{Repository: "example.com/plugins/gs-base", Tag: "v1"},
+		{Repository: "example.com/plugins/gs-sre", Digest: digestPlugin},
+	}
+	return instance
+}
+
+// pinOnce runs pinPluginDigests on a copy of instance, as Reconcile does
+// with the merged spec, and returns the pinned copy.
+func pinOnce(t *testing.T, r *KlausInstanceReconciler, instance *klausv1alpha1.KlausInstance) (*klausv1alpha1.KlausInstance, time.Duration) {
+	t.Helper()
+	merged := instance.DeepCopy()
+	refreshIn, err := r.pinPluginDigests(context.Background(), instance, merged)
+	if err != nil {
+		t.Fatalf("pinPluginDigests: %v", err)
+	}
+	return merged, refreshIn
+}
+
+func TestPinPluginDigests(t *testing.T) {
+	digest := digestV1
+	recorder := record.NewFakeRecorder(10)
+	r := &KlausInstanceReconciler{
+		Recorder: recorder,
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return digest, nil
+		}},
+	}
+	instance := pluginDigestInstance("")
+
+	merged, refreshIn := pinOnce(t, r, instance)
+	if p := merged.Spec.Plugins[0]; p.Tag != "" || p.Digest != digestV1 || refreshIn != 0 {
+		t.Errorf("plugin = %+v, refreshIn = %s; want the tag pinned without a refresh", p, refreshIn)
+	}
+	resolved := instance.Status.ResolvedPlugins
+	if len(resolved) != 2 || resolved[0].Tag != "v1" || resolved[0].Digest != digestV1 || resolved[0].ResolvedGeneration != 1 ||
+		resolved[1].Tag != "" || resolved[1].Digest != digestPlugin {
+		t.Errorf("resolvedPlugins = %+v", resolved)
+	}
+
+	// The tag moves: the recorded digest is kept until the spec changes.
+	digest = digestV2
+	if merged, _ = pinOnce(t, r, instance); merged.Spec.Plugins[0].Digest != digestV1 {
+		t.Errorf("digest = %s, want the recorded one without a spec change", merged.Spec.Plugins[0].Digest)
+	}
+	instance.Generation = 2
+	if merged, _ = pinOnce(t, r, instance); merged.Spec.Plugins[0].Digest != digestV2 {
+		t.Errorf("digest = %s, want the new one after a spec change", merged.Spec.Plugins[0].Digest)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, ReasonPluginDigestChanged) || !strings.Contains(event, digestV2) {
+			t.Errorf("event = %q, want the digest change", event)
+		}
+	default:
+		t.Error("expected a PluginDigestChanged event")
+	}
+
+	// A new tag is resolved whatever the policy.
+	instance.Spec.Plugins[0].Tag = "v2"
+	instance.Spec.PluginRefreshPolicy = klausv1alpha1.RefreshPolicyNever
+	pinOnce(t, r, instance)
+	if instance.Status.ResolvedPlugins[0].Tag != "v2" {
+		t.Errorf("resolvedPlugins = %+v, want the new tag recorded", instance.Status.ResolvedPlugins)
+	}
+}
+
+func TestPinPluginDigests_Never(t *testing.T) {
+	digest := digestV1
+	r := &KlausInstanceReconciler{
+		Recorder: record.NewFakeRecorder(10),
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return digest, nil
+		}},
+	}
+	instance := pluginDigestInstance(klausv1alpha1.RefreshPolicyNever)
+	pinOnce(t, r, instance)
+
+	digest = digestV2
+	instance.Generation = 2
+	if merged, _ := pinOnce(t, r, instance); merged.Spec.Plugins[0].Digest != digestV1 {
+		t.Errorf("digest = %s, want the recorded one", merged.Spec.Plugins[0].Digest)
+	}
+}
+
+func TestPinPluginDigests_Periodic(t *testing.T) {
+	digest := digestV1
+	r := &KlausInstanceReconciler{
+		Recorder: record.NewFakeRecorder(10),
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return digest, nil
+		}},
+		PluginRefreshInterval: 10 * time.Minute,
+	}
+	instance := pluginDigestInstance(klausv1alpha1.RefreshPolicyPeriodic)
+	if _, refreshIn := pinOnce(t, r, instance); refreshIn <= 9*time.Minute || refreshIn > 10*time.Minute {
+		t.Errorf("refreshIn = %s, want the refresh interval", refreshIn)
+	}
+
+	digest = digestV2
+	if merged, _ := pinOnce(t, r, instance); merged.Spec.Plugins[0].Digest != digestV1 {
+		t.Errorf("digest = %s, want the recorded one before the interval elapsed", merged.Spec.Plugins[0].Digest)
+	}
+	instance.Status.ResolvedPlugins[0].ResolvedAt = &metav1.Time{Time: time.Now().Add(-11 * time.Minute)}
+	if merged, _ := pinOnce(t, r, instance); merged.Spec.Plugins[0].Digest != digestV2 {
+		t.Errorf("digest = %s, want the new one once the interval elapsed", merged.Spec.Plugins[0].Digest)
+	}
+}
+
+func TestPinPluginDigests_ResolveError(t *testing.T) {
+	r := &KlausInstanceReconciler{
+		Recorder: record.NewFakeRecorder(10),
+		OCIClient: &mockOCIResolver{digestFn: func(context.Context, string) (string, error) {
+			return "", errors.New("manifest unknown")
+		}},
+	}
+	instance := pluginDigestInstance("")
+	_, err := r.pinPluginDigests(context.Background(), instance, instance.DeepCopy())
+	if err == nil || !strings.Contains(err.Error(), "example.com/plugins/gs-base:v1") {
+		t.Errorf("error = %v, want the plugin named", err)
+	}
+}
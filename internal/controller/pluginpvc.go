@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// pluginSyncRequeueInterval is how often an instance waiting for its
+// plugins to be synced into the plugins PVC is requeued.
+const pluginSyncRequeueInterval = 10 * time.Second
+
+// reconcilePluginCatalog ensures the plugins PVC and catalog of a user
+// namespace exist and that every plugin is synced into the PVC. Missing
+// plugins are pulled by a sync Job; once it succeeded they are added to the
+// catalog. It reports whether all plugins are synced.
+func reconcilePluginCatalog(ctx context.Context, c client.Client, opts resources.PluginPVCOptions, namespace string, plugins []klausv1alpha1.PluginReference) (bool, error) {
+	if len(plugins) == 0 {
+		return true, nil
+	}
+
+	// The PVC is created once and never updated, as only its size could be
+	// changed in place.
+	pvc := resources.BuildPluginsPVC(namespace, opts)
+	if err := c.Create(ctx, pvc); err != nil && !apierrors.IsAlreadyExists(err) {
+		return false, fmt.Errorf("creating plugins PVC: %w", err)
+	}
+
+	var catalog corev1.ConfigMap
+	err := c.Get(ctx, types.NamespacedName{Name: resources.PluginCatalogName, Namespace: namespace}, &catalog)
+	if apierrors.IsNotFound(err) {
+		catalog = *resources.BuildPluginCatalog(namespace)
+		err = c.Create(ctx, &catalog)
+	}
+	if err != nil {
+		return false, fmt.Errorf("reconciling plugin catalog: %w", err)
+	}
+
+	pending := resources.PendingPlugins(&catalog, plugins)
+	if len(pending) == 0 {
+		return true, nil
+	}
+
+	desired := resources.BuildPluginSyncJob(namespace, opts, pending)
+	var job batchv1.Job
+	err = c.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: namespace}, &job)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := c.Create(ctx, desired); err != nil && !apierrors.IsAlreadyExists(err) {
+			return false, fmt.Errorf("creating plugin sync job: %w", err)
+		}
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("fetching plugin sync job: %w", err)
+	case job.Status.Succeeded > 0:
+		if catalog.Data == nil {
+			catalog.Data = map[string]string{}
+		}
+		maps.Copy(catalog.Data, pending)
+		if err := c.Update(ctx, &catalog); err != nil {
+			return false, fmt.Errorf("updating plugin catalog: %w", err)
+		}
+		if err := c.Delete(ctx, &job, client.PropagationPolicy(metav1.DeletePropagationBackground)); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("deleting plugin sync job: %w", err)
+		}
+		return true, nil
+	case jobFailed(&job):
+		return false, fmt.Errorf("plugin sync job %s/%s failed; it is retried once the Job is deleted or expires", namespace, job.Name)
+	default:
+		return false, nil
+	}
+}
+
+// jobFailed reports whether a Job has the Failed condition.
+func jobFailed(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcilePlugins syncs the instance plugins into the shared plugins PVC
+// when the operator serves plugins from it. It reports whether the instance
+// has to wait for the sync.
+func (r *KlausInstanceReconciler) reconcilePlugins(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) (bool, error) {
+	if r.PluginPVC == nil || len(merged.Spec.Plugins) == 0 {
+		return false, nil
+	}
+	synced, err := reconcilePluginCatalog(ctx, r.Client, *r.PluginPVC, namespace, merged.Spec.Plugins)
+	if err != nil {
+		setCondition(instance, ConditionPluginsSynced, metav1.ConditionFalse, "SyncFailed", err.Error())
+		return false, err
+	}
+	if !synced {
+		setCondition(instance, ConditionPluginsSynced, metav1.ConditionFalse, "Syncing",
+			"Syncing plugins into the "+resources.PluginsPVCName+" PVC")
+		return true, nil
+	}
+	setCondition(instance, ConditionPluginsSynced, metav1.ConditionTrue, "Synced", "Plugins synced into the "+resources.PluginsPVCName+" PVC")
+	return false, nil
+}
+
+// updateStatusWaitingForPlugins marks the instance as pending until the
+// plugin sync Job finished.
+func (r *KlausInstanceReconciler) updateStatusWaitingForPlugins(ctx context.Context, instance *klausv1alpha1.KlausInstance) (ctrl.Result, error) {
+	instance.Status.State = klausv1alpha1.InstanceStatePending
+	instance.Status.ObservedGeneration = instance.Generation
+	setCondition(instance, ConditionReady, metav1.ConditionFalse, "WaitingForPlugins", "Waiting for the plugin sync Job")
+
+	if err := r.patchStatus(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: pluginSyncRequeueInterval}, nil
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const pluginTestNamespace = "klaus-user-user-example-com"
+
+var pluginTestPlugins = []klausv1alpha1.PluginReference{
+	{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", Tag: "v1.0.0"},
+}
+
+func pluginTestClient(t *testing.T) client.Client {
+	t.Helper()
+	scheme := testScheme(t)
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding batchv1 to scheme: %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&batchv1.Job{}).Build()
+}
+
+func pluginSyncJob(t *testing.T, c client.Client) *batchv1.Job {
+	t.Helper()
+	var jobs batchv1.JobList
+	if err := c.List(context.Background(), &jobs, client.InNamespace(pluginTestNamespace)); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 1 {
+		t.Fatalf("got %d plugin sync jobs, want 1", len(jobs.Items))
+	}
+	return &jobs.Items[0]
+}
+
+func TestReconcilePluginCatalog_SyncsMissingPlugins(t *testing.T) {
+	ctx := context.Background()
+	c := pluginTestClient(t)
+	opts := resources.PluginPVCOptions{SyncImage: "klaus-operator:1.0.0"}
+
+	synced, err := reconcilePluginCatalog(ctx, c, opts, pluginTestNamespace, pluginTestPlugins)
+	if err != nil || synced {
+		t.Fatalf("reconcilePluginCatalog() = %v, %v; want waiting for the sync Job", synced, err)
+	}
+	var pvc corev1.PersistentVolumeClaim
+	if err := c.Get(ctx, types.NamespacedName{Name: resources.PluginsPVCName, Namespace: pluginTestNamespace}, &pvc); err != nil {
+		t.Fatalf("expected the plugins PVC: %v", err)
+	}
+	job := pluginSyncJob(t, c)
+
+	// Reconciling again while the Job runs neither creates another Job nor
+	// reports the plugins as synced.
+	if synced, err := reconcilePluginCatalog(ctx, c, opts, pluginTestNamespace, pluginTestPlugins); err != nil || synced {
+		t.Fatalf("reconcilePluginCatalog() = %v, %v; want still waiting", synced, err)
+	}
+	pluginSyncJob(t, c)
+
+	job.Status.Succeeded = 1
+	if err := c.Status().Update(ctx, job); err != nil {
+		t.Fatalf("updating job status: %v", err)
+	}
+	if synced, err := reconcilePluginCatalog(ctx, c, opts, pluginTestNamespace, pluginTestPlugins); err != nil || !synced {
+		t.Fatalf("reconcilePluginCatalog() = %v, %v; want synced", synced, err)
+	}
+
+	var catalog corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Name: resources.PluginCatalogName, Namespace: pluginTestNamespace}, &catalog); err != nil {
+		t.Fatalf("expected the plugin catalog: %v", err)
+	}
+	if catalog.Data[resources.PluginSubPath(pluginTestPlugins[0])] != resources.PluginImageReference(pluginTestPlugins[0]) {
+		t.Errorf("catalog = %v, want the synced plugin", catalog.Data)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(job), &batchv1.Job{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the finished sync Job to be deleted, got %v", err)
+	}
+}
+
+func TestReconcilePluginCatalog_FailedJob(t *testing.T) {
+	ctx := context.Background()
+	c := pluginTestClient(t)
+	opts := resources.PluginPVCOptions{SyncImage: "klaus-operator:1.0.0"}
+
+	if _, err := reconcilePluginCatalog(ctx, c, opts, pluginTestNamespace, pluginTestPlugins); err != nil {
+		t.Fatalf("reconcilePluginCatalog() error = %v", err)
+	}
+	job := pluginSyncJob(t, c)
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	if err := c.Status().Update(ctx, job); err != nil {
+		t.Fatalf("updating job status: %v", err)
+	}
+
+	_, err := reconcilePluginCatalog(ctx, c, opts, pluginTestNamespace, pluginTestPlugins)
+	if err == nil || !strings.Contains(err.Error(), job.Name) {
+		t.Errorf("reconcilePluginCatalog() error = %v, want the failed Job named", err)
+	}
+}
+
+func TestReconcilePlugins_ImageVolumes(t *testing.T) {
+	r := &KlausInstanceReconciler{Client: pluginTestClient(t)}
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{Plugins: pluginTestPlugins}}
+
+	waiting, err := r.reconcilePlugins(context.Background(), instance, instance, pluginTestNamespace)
+	if err != nil || waiting {
+		t.Errorf("reconcilePlugins() = %v, %v; want no sync without PluginPVC", waiting, err)
+	}
+	if len(instance.Status.Conditions) != 0 {
+		t.Errorf("conditions = %v, want none", instance.Status.Conditions)
+	}
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// imagePullWaitingReasons are the container waiting reasons of an image that
+// cannot be pulled.
+var imagePullWaitingReasons = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// podDiagnosis explains why the pods of an instance fail.
+type podDiagnosis struct {
+	// Reason is a CamelCase reason suitable for a condition.
+	Reason string
+	// Message is a human-readable explanation, e.g. "container klaus keeps
+	// crashing after 4 restarts, last exit code 1 (Error): missing API key".
+	Message string
+}
+
+// reconcilePodHealth records the first failure of the given instance pods in
+// the PodHealthy condition and status.failureReason and failureMessage, or
+// clears them while the pods are healthy.
+func reconcilePodHealth(instance *klausv1alpha1.KlausInstance, pods []corev1.Pod) {
+	diagnosis, failed := diagnosePods(pods)
+	if !failed {
+		instance.Status.FailureReason = ""
+		instance.Status.FailureMessage = ""
+		setCondition(instance, ConditionPodHealthy, metav1.ConditionTrue, "Healthy", "No pod failures observed")
+		return
+	}
+	instance.Status.FailureReason = diagnosis.Reason
+	instance.Status.FailureMessage = truncateMessage(diagnosis.Message)
+	setCondition(instance, ConditionPodHealthy, metav1.ConditionFalse, diagnosis.Reason, instance.Status.FailureMessage)
+}
+
+// clearPodHealth removes the pod diagnostics of an instance without pods,
+// e.g. a stopped one.
+func clearPodHealth(instance *klausv1alpha1.KlausInstance) {
+	instance.Status.FailureReason = ""
+	instance.Status.FailureMessage = ""
+	apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionPodHealthy)
+}
+
+// diagnosePods returns the first scheduling, image pull or container failure
+// of the given pods, checking init containers before regular containers.
+// Pods being deleted, e.g. those of a replaced ReplicaSet, are skipped.
+func diagnosePods(pods []corev1.Pod) (podDiagnosis, bool) {
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason != "" {
+				return podDiagnosis{
+					Reason:  c.Reason,
+					Message: fmt.Sprintf("pod %s cannot be scheduled: %s", pod.Name, c.Message),
+				}, true
+			}
+		}
+		for _, cs := range pod.Status.InitContainerStatuses {
+			if diagnosis, failed := diagnoseContainer(cs); failed {
+				if cs.Name == resources.GitCloneContainerName {
+					diagnosis.Reason = "GitCloneFailed"
+					diagnosis.Message = "workspace git clone failed: " + diagnosis.Message
+				}
+				return diagnosis, true
+			}
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if diagnosis, failed := diagnoseContainer(cs); failed {
+				return diagnosis, true
+			}
+		}
+	}
+	return podDiagnosis{}, false
+}
+
+// diagnoseContainer explains why a container is not running, including its
+// last termination when it keeps restarting.
+func diagnoseContainer(cs corev1.ContainerStatus) (podDiagnosis, bool) {
+	if w := cs.State.Waiting; w != nil && w.Reason != "" && !benignWaitingReasons[w.Reason] {
+		switch {
+		case imagePullWaitingReasons[w.Reason]:
+			return podDiagnosis{
+				Reason:  "ImagePullFailed",
+				Message: messageWithDetail(fmt.Sprintf("container %s cannot pull image %s (%s)", cs.Name, cs.Image, w.Reason), w.Message),
+			}, true
+		case w.Reason == "CrashLoopBackOff":
+			return podDiagnosis{
+				Reason:  w.Reason,
+				Message: fmt.Sprintf("container %s keeps crashing after %d restarts%s", cs.Name, cs.RestartCount, lastTermination(cs)),
+			}, true
+		}
+		return podDiagnosis{
+			Reason:  w.Reason,
+			Message: messageWithDetail(fmt.Sprintf("container %s is waiting (%s)", cs.Name, w.Reason), w.Message),
+		}, true
+	}
+	if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+		reason := t.Reason
+		if reason == "" {
+			reason = "Error"
+		}
+		return podDiagnosis{
+			Reason:  reason,
+			Message: messageWithDetail(fmt.Sprintf("container %s exited with code %d (%s)", cs.Name, t.ExitCode, reason), t.Message),
+		}, true
+	}
+	return podDiagnosis{}, false
+}
+
+// lastTermination renders the last termination of a restarting container,
+// e.g. ", last exit code 1 (Error): missing API key".
+func lastTermination(cs corev1.ContainerStatus) string {
+	t := cs.LastTerminationState.Terminated
+	if t == nil {
+		return ""
+	}
+	reason := t.Reason
+	if reason == "" {
+		reason = "Error"
+	}
+	return messageWithDetail(fmt.Sprintf(", last exit code %d (%s)", t.ExitCode, reason), t.Message)
+}
+
+func messageWithDetail(message, detail string) string {
+	detail = strings.TrimSpace(detail)
+	if detail == "" {
+		return message
+	}
+	return message + ": " + detail
+}
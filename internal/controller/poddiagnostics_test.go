@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestDiagnosePods(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      corev1.PodStatus
+		wantReason  string
+		wantMessage string
+	}{
+		{
+			name: "image pull",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "klaus",
+				Image: "gsoci.azurecr.io/giantswarm/klaus:v9",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "not found"}},
+			}}},
+			wantReason:  "ImagePullFailed",
+			wantMessage: "container klaus cannot pull image gsoci.azurecr.io/giantswarm/klaus:v9 (ImagePullBackOff): not found",
+		},
+		{
+			name: "crash loop",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "klaus",
+				RestartCount: 4,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 1m20s"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1, Reason: "Error", Message: "missing API key\n",
+				}},
+			}}},
+			wantReason:  "CrashLoopBackOff",
+			wantMessage: "container klaus keeps crashing after 4 restarts, last exit code 1 (Error): missing API key",
+		},
+		{
+			name: "git clone",
+			status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{{
+				Name: resources.GitCloneContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 128, Message: "fatal: repository not found",
+				}},
+			}}},
+			wantReason:  "GitCloneFailed",
+			wantMessage: "workspace git clone failed: container git-clone exited with code 128 (Error): fatal: repository not found",
+		},
+		{
+			name: "unschedulable",
+			status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable", Message: "0/3 nodes are available",
+			}}},
+			wantReason:  "Unschedulable",
+			wantMessage: "pod agent-abc cannot be scheduled: 0/3 nodes are available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "agent-abc"}, Status: tt.status}}
+			got, failed := diagnosePods(pods)
+			if !failed || got.Reason != tt.wantReason || got.Message != tt.wantMessage {
+				t.Errorf("diagnosePods() = %+v, %v, want %s: %s", got, failed, tt.wantReason, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestDiagnosePods_Healthy(t *testing.T) {
+	pods := []corev1.Pod{
+		{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "klaus",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+		}}}},
+		// A pod of the previous ReplicaSet being replaced.
+		{
+			ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "klaus",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}}},
+		},
+	}
+	if got, failed := diagnosePods(pods); failed {
+		t.Errorf("diagnosePods() = %+v, want healthy", got)
+	}
+}
+
+func TestReconcileRolloutConditions_PodHealth(t *testing.T) {
+	instance := testInstance()
+	dep := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: resources.UserNamespace(instance.Spec.Owner)}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-abc", Namespace: dep.Namespace, Labels: resources.SelectorLabels(instance)},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "klaus",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerConfigError", Message: `secret "api-key" not found`}},
+		}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(pod).Build()
+	r := &KlausInstanceReconciler{APIReader: c}
+
+	r.reconcileRolloutConditions(context.Background(), instance, instance, dep)
+	if instance.Status.FailureReason != "CreateContainerConfigError" || !strings.Contains(instance.Status.FailureMessage, `secret "api-key" not found`) {
+		t.Errorf("failure = %s: %s", instance.Status.FailureReason, instance.Status.FailureMessage)
+	}
+	healthy := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionPodHealthy)
+	if healthy == nil || healthy.Status != metav1.ConditionFalse || healthy.Reason != "CreateContainerConfigError" {
+		t.Errorf("PodHealthy = %+v", healthy)
+	}
+
+	pod.Status.ContainerStatuses[0].State = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	if err := c.Status().Update(context.Background(), pod); err != nil {
+		t.Fatal(err)
+	}
+	dep.Status.AvailableReplicas = 1
+	r.reconcileRolloutConditions(context.Background(), instance, instance, dep)
+	if instance.Status.FailureReason != "" || instance.Status.FailureMessage != "" ||
+		!apimeta.IsStatusConditionTrue(instance.Status.Conditions, ConditionPodHealthy) {
+		t.Errorf("status = %+v, want the failure cleared", instance.Status)
+	}
+
+	clearPodHealth(instance)
+	if apimeta.FindStatusCondition(instance.Status.Conditions, ConditionPodHealthy) != nil {
+		t.Error("PodHealthy kept for an instance without pods")
+	}
+}
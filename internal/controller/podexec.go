@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// PodExecutor runs a command in a pod container and returns its output.
+// The production implementation uses the pods/exec subresource, while tests
+// can supply a mock.
+type PodExecutor interface {
+	Exec(ctx context.Context, namespace, pod, container string, command []string) (string, error)
+}
+
+// kubePodExecutor implements PodExecutor with the Kubernetes API.
+type kubePodExecutor struct {
+	config     *rest.Config
+	coreClient corev1client.CoreV1Interface
+}
+
+// NewPodExecutor creates a PodExecutor for the cluster of config.
+func NewPodExecutor(config *rest.Config, coreClient corev1client.CoreV1Interface) PodExecutor {
+	return &kubePodExecutor{config: config, coreClient: coreClient}
+}
+
+func (e *kubePodExecutor) Exec(ctx context.Context, namespace, pod, container string, command []string) (string, error) {
+	req := e.coreClient.RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(e.config, "POST", req.URL())
+	if err != nil {
+		return "", fmt.Errorf("creating executor: %w", err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
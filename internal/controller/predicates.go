@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"slices"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+// primaryPredicate filters update events of a reconciled object down to
+// changes the reconciler acts on: spec (generation), labels, annotations,
+// finalizers, deletion and instances reaching their budget. Other
+// status-only updates, including the ones the reconciler writes itself, are
+// dropped.
+func primaryPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.LabelChangedPredicate{},
+		predicate.AnnotationChangedPredicate{},
+		lifecycleChangedPredicate(),
+		budgetChangedPredicate(),
+	)
+}
+
+// budgetChangedPredicate passes updates that make the recorded cost of an
+// instance reach or drop below its budget. The token usage reporter only
+// updates the status, so the reconciler would not suspend the instance
+// otherwise.
+func budgetChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldInstance, okOld := e.ObjectOld.(*klausv1alpha1.KlausInstance)
+			newInstance, okNew := e.ObjectNew.(*klausv1alpha1.KlausInstance)
+			if !okOld || !okNew {
+				return false
+			}
+			return usage.BudgetExceeded(oldInstance) != usage.BudgetExceeded(newInstance)
+		},
+	}
+}
+
+// lifecycleChangedPredicate passes updates that add or remove finalizers
+// or set the deletion timestamp. Neither bumps the generation, but the
+// reconciler returns early after adding its finalizer and must run again.
+func lifecycleChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return false
+			}
+			if !slices.Equal(e.ObjectOld.GetFinalizers(), e.ObjectNew.GetFinalizers()) {
+				return true
+			}
+			return e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero()
+		},
+	}
+}
+
+// specChangedPredicate passes create and delete events but only those
+// updates that change the spec. It is used for watched objects whose status
+// is irrelevant to the watching reconciler, which breaks status fan-out
+// loops such as instance status -> KlausMCPServer instance count -> every
+// referencing instance.
+func specChangedPredicate() predicate.Predicate {
+	return predicate.GenerationChangedPredicate{}
+}
+
+// mcpServerReadinessPredicate passes KlausMCPServer spec changes and Ready
+// condition transitions, which instances act on (a server becoming ready
+// unblocks referencing instances). Other status updates, such as the
+// referencing instance count, are dropped.
+func mcpServerReadinessPredicate() predicate.Predicate {
+	return predicate.Or(
+		predicate.GenerationChangedPredicate{},
+		predicate.Funcs{
+			CreateFunc:  func(event.CreateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldServer, okOld := e.ObjectOld.(*klausv1alpha1.KlausMCPServer)
+				newServer, okNew := e.ObjectNew.(*klausv1alpha1.KlausMCPServer)
+				if !okOld || !okNew {
+					return false
+				}
+				return readyStatus(oldServer) != readyStatus(newServer)
+			},
+		},
+	)
+}
+
+// instanceStateChangedPredicate passes KlausInstance creations, deletions
+// and status.state transitions, which unblock or block the instances
+// depending on them. Other updates are dropped.
+func instanceStateChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldInstance, okOld := e.ObjectOld.(*klausv1alpha1.KlausInstance)
+			newInstance, okNew := e.ObjectNew.(*klausv1alpha1.KlausInstance)
+			if !okOld || !okNew {
+				return false
+			}
+			return oldInstance.Status.State != newInstance.Status.State
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+func readyStatus(server *klausv1alpha1.KlausMCPServer) string {
+	if cond := apimeta.FindStatusCondition(server.Status.Conditions, MCPServerConditionReady); cond != nil {
+		return string(cond.Status)
+	}
+	return ""
+}
+
+// childChangedPredicate passes every real change of a watched child
+// resource, including status changes the reconciler mirrors (e.g.
+// Deployment readiness), and drops periodic resync events where the
+// resource version is unchanged.
+func childChangedPredicate() predicate.Predicate {
+	return predicate.ResourceVersionChangedPredicate{}
+}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func predicateTestInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-instance",
+			Namespace:       "klaus-system",
+			Generation:      1,
+			ResourceVersion: "100",
+			Finalizers:      []string{FinalizerName},
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+}
+
+func TestPrimaryPredicate_StatusOnlyUpdateIgnored(t *testing.T) {
+	old := predicateTestInstance()
+	updated := old.DeepCopy()
+	updated.ResourceVersion = "101"
+	updated.Status.State = klausv1alpha1.InstanceStateRunning
+	updated.Status.Conditions = []metav1.Condition{{Type: ConditionReady, Status: metav1.ConditionTrue}}
+
+	if primaryPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}) {
+		t.Error("expected status-only update to be filtered out")
+	}
+}
+
+func TestPrimaryPredicate_RelevantChangesPass(t *testing.T) {
+	now := metav1.Now()
+	tests := []struct {
+		name   string
+		mutate func(*klausv1alpha1.KlausInstance)
+	}{
+		{"spec change", func(i *klausv1alpha1.KlausInstance) { i.Generation = 2 }},
+		{"label change", func(i *klausv1alpha1.KlausInstance) { i.Labels = map[string]string{"team": "sre"} }},
+		{"annotation change", func(i *klausv1alpha1.KlausInstance) { i.Annotations = map[string]string{"note": "x"} }},
+		{"finalizer removed", func(i *klausv1alpha1.KlausInstance) { i.Finalizers = nil }},
+		{"deletion", func(i *klausv1alpha1.KlausInstance) { i.DeletionTimestamp = &now }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := predicateTestInstance()
+			updated := old.DeepCopy()
+			updated.ResourceVersion = "101"
+			tt.mutate(updated)
+			if !primaryPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}) {
+				t.Errorf("expected %s to trigger a reconcile", tt.name)
+			}
+		})
+	}
+}
+
+func TestPrimaryPredicate_CreateAndDeletePass(t *testing.T) {
+	p := primaryPredicate()
+	instance := predicateTestInstance()
+	if !p.Create(event.CreateEvent{Object: instance}) {
+		t.Error("expected create to pass")
+	}
+	if !p.Delete(event.DeleteEvent{Object: instance}) {
+		t.Error("expected delete to pass")
+	}
+}
+
+func TestSpecChangedPredicate_InstanceStatusIgnored(t *testing.T) {
+	// The KlausMCPServer controller watches instances only to count
+	// references; instance status updates must not fan out to it.
+	old := predicateTestInstance()
+	updated := old.DeepCopy()
+	updated.ResourceVersion = "101"
+	updated.Status.State = klausv1alpha1.InstanceStateRunning
+
+	if specChangedPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}) {
+		t.Error("expected instance status update to be filtered out")
+	}
+
+	updated.Generation = 2
+	if !specChangedPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}) {
+		t.Error("expected instance spec update to pass")
+	}
+}
+
+func TestMCPServerReadinessPredicate(t *testing.T) {
+	server := func(ready metav1.ConditionStatus, count int) *klausv1alpha1.KlausMCPServer {
+		s := &klausv1alpha1.KlausMCPServer{
+			ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: "klaus-system", Generation: 1},
+		}
+		s.Status.InstanceCount = count
+		if ready != "" {
+			s.Status.Conditions = []metav1.Condition{{Type: MCPServerConditionReady, Status: ready}}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		old, new *klausv1alpha1.KlausMCPServer
+		want     bool
+	}{
+		{"instance count only", server(metav1.ConditionTrue, 1), server(metav1.ConditionTrue, 2), false},
+		{"becomes ready", server(metav1.ConditionFalse, 1), server(metav1.ConditionTrue, 1), true},
+		{"first reconcile", server("", 0), server(metav1.ConditionTrue, 0), true},
+		{"becomes not ready", server(metav1.ConditionTrue, 1), server(metav1.ConditionFalse, 1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mcpServerReadinessPredicate().Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new})
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	spec := server(metav1.ConditionTrue, 1)
+	specChanged := spec.DeepCopy()
+	specChanged.Generation = 2
+	if !mcpServerReadinessPredicate().Update(event.UpdateEvent{ObjectOld: spec, ObjectNew: specChanged}) {
+		t.Error("expected spec change to pass")
+	}
+}
+
+func TestChildChangedPredicate(t *testing.T) {
+	old := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "d", ResourceVersion: "5"}}
+
+	resync := old.DeepCopy()
+	if childChangedPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: resync}) {
+		t.Error("expected resync with unchanged resource version to be filtered out")
+	}
+
+	// Deployment status changes (e.g. readiness) must still reach the
+	// instance reconciler.
+	statusChange := old.DeepCopy()
+	statusChange.ResourceVersion = "6"
+	statusChange.Status.ReadyReplicas = 1
+	if !childChangedPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: statusChange}) {
+		t.Error("expected Deployment status change to pass")
+	}
+}
+
+func TestInstanceStateChangedPredicate(t *testing.T) {
+	old := predicateTestInstance()
+
+	specOnly := old.DeepCopy()
+	specOnly.Generation = 2
+	if instanceStateChangedPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: specOnly}) {
+		t.Error("expected an update without a state change to be filtered out")
+	}
+
+	running := old.DeepCopy()
+	running.Status.State = klausv1alpha1.InstanceStateRunning
+	if !instanceStateChangedPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: running}) {
+		t.Error("expected a state transition to pass")
+	}
+
+	if !instanceStateChangedPredicate().Create(event.CreateEvent{Object: old}) {
+		t.Error("expected creations to pass")
+	}
+	if !instanceStateChangedPredicate().Delete(event.DeleteEvent{Object: old}) {
+		t.Error("expected deletions to pass")
+	}
+}
+
+func TestPrimaryPredicate_BudgetReached(t *testing.T) {
+	old := predicateTestInstance()
+	old.Spec.Claude.MaxBudgetUSD = ptr.To(5.0)
+	old.Status.TokenUsage = &klausv1alpha1.TokenUsage{CostUSD: "4"}
+
+	more := old.DeepCopy()
+	more.ResourceVersion = "101"
+	more.Status.TokenUsage.CostUSD = "4.5"
+	if primaryPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: more}) {
+		t.Error("expected a usage update within the budget to be filtered out")
+	}
+
+	exceeded := old.DeepCopy()
+	exceeded.ResourceVersion = "101"
+	exceeded.Status.TokenUsage.CostUSD = "5.2"
+	if !primaryPredicate().Update(event.UpdateEvent{ObjectOld: old, ObjectNew: exceeded}) {
+		t.Error("expected reaching the budget to pass")
+	}
+}
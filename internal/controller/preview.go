@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/oci"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const (
+	// AnnotationDryRun set to "true" makes the controller render the child
+	// resources of a KlausInstance without creating them, recording the
+	// outcome in its Ready condition.
+	AnnotationDryRun = "klaus.giantswarm.io/dry-run"
+
+	// ReasonDryRun is the reason of the Ready condition of dry-run
+	// instances whose child resources were rendered.
+	ReasonDryRun = "DryRun"
+)
+
+// isDryRun reports whether the instance is annotated with AnnotationDryRun.
+func isDryRun(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Annotations[AnnotationDryRun] == "true"
+}
+
+// PreviewInstance renders the ConfigMaps, effective configuration and
+// Deployment the reconcile of instance creates, without creating or
+// changing anything; the instance need not exist. It runs the personality,
+// skill pack, OCI and KlausMCPServer resolution and the validation of the
+// reconcile, and returns its first error, which reports the reason of the
+// Ready condition the reconcile would set with a Reason() string method.
+// Credentials are not copied, so missing API key, provider, git and MCP
+// Secrets are not reported.
+func (r *KlausInstanceReconciler) PreviewInstance(ctx context.Context, instance *klausv1alpha1.KlausInstance) ([]client.Object, error) {
+	// Reuse the reconcile steps with their writes and events discarded.
+	p := *r
+	p.Client = &readOnlyClient{Client: r.Client}
+	p.Recorder = discardRecorder{}
+	instance = instance.DeepCopy()
+
+	resolved, err := p.resolveInstance(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
+	merged := resolved.merged
+	namespace := p.childNamespace(merged)
+
+	// Render dependencies that are not running yet with the endpoints of
+	// the others.
+	deps, _, err := p.reconcileDependencies(ctx, instance, merged, namespace)
+	if err == nil {
+		err = resources.MergeDependencyMCPServers(merged, deps)
+	}
+	if err != nil {
+		return nil, &reasonError{reason: "DependencyError", err: err}
+	}
+
+	collectorChecksum, err := p.copyTelemetryCollector(ctx, merged, namespace)
+	if err != nil {
+		return nil, &reasonError{reason: "TelemetryCollectorError", err: err}
+	}
+	caBundleChecksum, err := p.copyCABundle(ctx, merged, namespace)
+	if err != nil {
+		return nil, &reasonError{reason: "CABundleError", err: err}
+	}
+	proxyChecksum, err := p.copyProxyCredentials(ctx, merged, namespace)
+	if err != nil {
+		return nil, &reasonError{reason: "ProxyCredentialsError", err: err}
+	}
+
+	soul, err := p.fetchPersonalitySoul(ctx, merged)
+	if err != nil && !errors.Is(err, oci.ErrSoulUnavailable) {
+		return nil, &reasonError{reason: "PersonalitySoulError", err: err}
+	}
+	cm, err := resources.BuildConfigMap(merged, namespace)
+	if err == nil {
+		err = resources.SetPersonalitySoul(merged, cm, soul)
+	}
+	if err != nil {
+		return nil, &reasonError{reason: "ConfigMapError", err: err}
+	}
+	image := p.instanceImage(merged)
+	effective, err := resources.BuildEffectiveConfigMap(merged, namespace, image)
+	if err != nil {
+		return nil, &reasonError{reason: "EffectiveConfigError", err: err}
+	}
+
+	apiLimit, err := p.reconcileAPILimits(ctx, merged, namespace)
+	if err != nil {
+		return nil, &reasonError{reason: "APILimitsError", err: err}
+	}
+	archs, err := p.reconcileImagePlatforms(ctx, instance, image)
+	if err != nil {
+		return nil, &reasonError{reason: "UnsupportedArchitecture", err: err}
+	}
+	dep := p.buildDeployment(merged, namespace, deploymentInputs{
+		image:             image,
+		configData:        cm.Data,
+		archs:             archs,
+		deps:              deps,
+		mcpArtifacts:      resolved.mcpArtifacts,
+		apiLimit:          apiLimit,
+		collectorChecksum: collectorChecksum,
+		caBundleChecksum:  caBundleChecksum,
+		proxyChecksum:     proxyChecksum,
+	})
+
+	var objs []client.Object
+	for _, shard := range resources.SplitConfigMap(merged, cm) {
+		objs = append(objs, shard)
+	}
+	objs = append(objs, effective, dep)
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, r.Client.Scheme())
+		if err != nil {
+			return nil, err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+	return objs, nil
+}
+
+// reconcileDryRun records the outcome of PreviewInstance for an instance
+// annotated with AnnotationDryRun. Its child resources are neither created
+// nor changed, so the resources of an instance annotated after it started
+// are left as they are.
+func (r *KlausInstanceReconciler) reconcileDryRun(ctx context.Context, instance *klausv1alpha1.KlausInstance) (ctrl.Result, error) {
+	objs, err := r.PreviewInstance(ctx, instance)
+	if err != nil {
+		return r.updateStatusReasonError(ctx, instance, err)
+	}
+
+	rendered := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		rendered = append(rendered, obj.GetObjectKind().GroupVersionKind().Kind+" "+obj.GetName())
+	}
+	instance.Status.State = klausv1alpha1.InstanceStateDryRun
+	instance.Status.ObservedGeneration = instance.Generation
+	setCondition(instance, ConditionReady, metav1.ConditionFalse, ReasonDryRun,
+		fmt.Sprintf("Rendered %s without creating them; remove the %s annotation to create the instance", strings.Join(rendered, ", "), AnnotationDryRun))
+	return ctrl.Result{}, r.patchStatus(ctx, instance)
+}
+
+// readOnlyClient discards the writes of the reconcile steps run by
+// PreviewInstance, as if they succeeded.
+type readOnlyClient struct {
+	client.Client
+}
+
+func (c *readOnlyClient) Create(context.Context, client.Object, ...client.CreateOption) error {
+	return nil
+}
+
+func (c *readOnlyClient) Update(context.Context, client.Object, ...client.UpdateOption) error {
+	return nil
+}
+
+func (c *readOnlyClient) Patch(context.Context, client.Object, client.Patch, ...client.PatchOption) error {
+	return nil
+}
+
+func (c *readOnlyClient) Apply(context.Context, runtime.ApplyConfiguration, ...client.ApplyOption) error {
+	return nil
+}
+
+func (c *readOnlyClient) Delete(context.Context, client.Object, ...client.DeleteOption) error {
+	return nil
+}
+
+func (c *readOnlyClient) DeleteAllOf(context.Context, client.Object, ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+func (c *readOnlyClient) Status() client.SubResourceWriter {
+	return readOnlySubResourceClient{}
+}
+
+func (c *readOnlyClient) SubResource(subResource string) client.SubResourceClient {
+	return readOnlySubResourceClient{SubResourceClient: c.Client.SubResource(subResource)}
+}
+
+// readOnlySubResourceClient reads subresources and discards their writes.
+type readOnlySubResourceClient struct {
+	client.SubResourceClient
+}
+
+func (readOnlySubResourceClient) Create(context.Context, client.Object, client.Object, ...client.SubResourceCreateOption) error {
+	return nil
+}
+
+func (readOnlySubResourceClient) Update(context.Context, client.Object, ...client.SubResourceUpdateOption) error {
+	return nil
+}
+
+func (readOnlySubResourceClient) Patch(context.Context, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+	return nil
+}
+
+func (readOnlySubResourceClient) Apply(context.Context, runtime.ApplyConfiguration, ...client.SubResourceApplyOption) error {
+	return nil
+}
+
+// discardRecorder drops the events of the reconcile steps run by
+// PreviewInstance.
+type discardRecorder struct{}
+
+var _ record.EventRecorder = discardRecorder{}
+
+func (discardRecorder) Event(runtime.Object, string, string, string) {}
+
+func (discardRecorder) Eventf(runtime.Object, string, string, string, ...any) {}
+
+func (discardRecorder) AnnotatedEventf(runtime.Object, map[string]string, string, string, string, ...any) {
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// previewReconciler returns a reconciler whose client fails every write, so
+// tests notice PreviewInstance reaching the API server.
+func previewReconciler(t *testing.T) *KlausInstanceReconciler {
+	t.Helper()
+	scheme := testScheme(t, appsv1.AddToScheme)
+	written := errors.New("write reached the API server")
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(context.Context, client.WithWatch, client.Object, ...client.CreateOption) error {
+				return written
+			},
+			Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+				return written
+			},
+			Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+				return written
+			},
+			Delete: func(context.Context, client.WithWatch, client.Object, ...client.DeleteOption) error {
+				return written
+			},
+		}).Build()
+	r := testReconcilerFor(c)
+	r.OperatorNamespace = ""
+	r.KlausImage = "registry.example.com/klaus:v1"
+	return r
+}
+
+func TestPreviewInstance(t *testing.T) {
+	instance := testInstance()
+	instance.Spec.Claude.Model = "claude-opus-4-20250514"
+	r := previewReconciler(t)
+
+	objs, err := r.PreviewInstance(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("PreviewInstance: %v", err)
+	}
+	var kinds []string
+	var dep *appsv1.Deployment
+	for _, obj := range objs {
+		kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+		if d, ok := obj.(*appsv1.Deployment); ok {
+			dep = d
+		}
+	}
+	if got := strings.Join(kinds, ","); got != "ConfigMap,ConfigMap,Deployment" {
+		t.Errorf("kinds = %s, want the ConfigMap, the effective configuration and the Deployment", got)
+	}
+	if dep == nil || dep.Spec.Template.Spec.Containers[0].Image != "registry.example.com/klaus:v1" {
+		t.Errorf("Deployment = %+v, want the operator image", dep)
+	}
+	if instance.Status.State != "" || len(instance.Status.Conditions) > 0 {
+		t.Errorf("status = %+v, want the instance left unchanged", instance.Status)
+	}
+}
+
+func TestPreviewInstance_ValidationError(t *testing.T) {
+	instance := testInstance()
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{Output: &klausv1alpha1.WorkspaceOutput{}}
+	r := previewReconciler(t)
+
+	_, err := r.PreviewInstance(context.Background(), instance)
+	var reasoned interface{ Reason() string }
+	if !errors.As(err, &reasoned) || reasoned.Reason() != "ValidationError" {
+		t.Fatalf("PreviewInstance error = %v, want a ValidationError", err)
+	}
+	if !strings.Contains(err.Error(), "spec.workspace.output") {
+		t.Errorf("error = %v, want the rejected field", err)
+	}
+}
+
+func TestReconcileDryRun(t *testing.T) {
+	instance := testInstance()
+	instance.Generation = 2
+	instance.Annotations = map[string]string{AnnotationDryRun: "true"}
+	r := previewReconciler(t)
+	// The dry run patches the status of the instance.
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(instance).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).Build()
+
+	if !isDryRun(instance) {
+		t.Fatal("isDryRun = false, want true")
+	}
+	if _, err := r.reconcileDryRun(context.Background(), instance); err != nil {
+		t.Fatalf("reconcileDryRun: %v", err)
+	}
+	if instance.Status.State != klausv1alpha1.InstanceStateDryRun || instance.Status.ObservedGeneration != 2 {
+		t.Errorf("status = %+v, want the DryRun state", instance.Status)
+	}
+	ready := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != ReasonDryRun ||
+		!strings.Contains(ready.Message, "Deployment my-agent") {
+		t.Errorf("Ready = %+v, want the rendered resources", ready)
+	}
+	var cms corev1.ConfigMapList
+	var deps appsv1.DeploymentList
+	if err := r.List(context.Background(), &cms); err != nil {
+		t.Fatalf("listing ConfigMaps: %v", err)
+	}
+	if err := r.List(context.Background(), &deps); err != nil {
+		t.Fatalf("listing Deployments: %v", err)
+	}
+	if len(cms.Items)+len(deps.Items) > 0 {
+		t.Errorf("created %d ConfigMaps and %d Deployments, want none", len(cms.Items), len(deps.Items))
+	}
+}
+
+func TestReconcileDryRun_Error(t *testing.T) {
+	instance := testInstance()
+	instance.Annotations = map[string]string{AnnotationDryRun: "true"}
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{Output: &klausv1alpha1.WorkspaceOutput{}}
+	r := previewReconciler(t)
+	r.Client = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(instance).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).Build()
+
+	if _, err := r.reconcileDryRun(context.Background(), instance); err == nil {
+		t.Fatal("reconcileDryRun succeeded, want the validation error")
+	}
+	ready := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionReady)
+	if instance.Status.State != klausv1alpha1.InstanceStateError || ready == nil || ready.Reason != "ValidationError" {
+		t.Errorf("status = %+v, want the ValidationError", instance.Status)
+	}
+}
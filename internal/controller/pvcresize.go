@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// resizePVC expands the existing workspace PVC to the size of the desired
+// one. All other PVC fields are immutable and left alone. Shrinking is not
+// possible; it is rejected by the admission webhook and surfaced as a
+// condition when the webhook is disabled. Once a resize was requested, the
+// WorkspaceResized condition reports its progress until the volume has the
+// requested capacity.
+func (r *KlausInstanceReconciler) resizePVC(ctx context.Context, instance *klausv1alpha1.KlausInstance, existing, desired *corev1.PersistentVolumeClaim) error {
+	// Only bound claims can be resized; the PVC watch requeues the instance
+	// once it is bound.
+	if existing.Status.Phase != corev1.ClaimBound {
+		return nil
+	}
+
+	requested := existing.Spec.Resources.Requests[corev1.ResourceStorage]
+	size := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+	resizing := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionWorkspaceResized) != nil
+
+	switch size.Cmp(requested) {
+	case -1:
+		setCondition(instance, ConditionWorkspaceResized, metav1.ConditionFalse, "ShrinkNotSupported",
+			fmt.Sprintf("spec.workspace.size %s is smaller than the PVC request %s; workspace PVCs cannot shrink", size.String(), requested.String()))
+		return nil
+	case 1:
+		expandable, reason, err := r.storageClassAllowsExpansion(ctx, existing.Spec.StorageClassName)
+		if err != nil {
+			return err
+		}
+		if !expandable {
+			setCondition(instance, ConditionWorkspaceResized, metav1.ConditionFalse, "ExpansionNotSupported",
+				fmt.Sprintf("Cannot expand PVC %s to %s: %s", existing.Name, size.String(), reason))
+			return nil
+		}
+
+		patch := client.MergeFrom(existing.DeepCopy())
+		existing.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if err := r.Patch(ctx, existing, patch); err != nil {
+			return fmt.Errorf("expanding PVC %s: %w", existing.Name, err)
+		}
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "ResizingPVC",
+			fmt.Sprintf("Expanding PVC %s from %s to %s", existing.Name, requested.String(), size.String()))
+		requested = size
+		resizing = true
+	}
+
+	if !resizing {
+		return nil
+	}
+	capacity := existing.Status.Capacity[corev1.ResourceStorage]
+	if capacity.Cmp(requested) < 0 {
+		message := fmt.Sprintf("Expanding PVC %s from %s to %s", existing.Name, capacity.String(), requested.String())
+		for _, cond := range existing.Status.Conditions {
+			if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending && cond.Status == corev1.ConditionTrue {
+				message += "; waiting for the file system to be resized on the node"
+			}
+		}
+		setCondition(instance, ConditionWorkspaceResized, metav1.ConditionFalse, "Resizing", message)
+		return nil
+	}
+	setCondition(instance, ConditionWorkspaceResized, metav1.ConditionTrue, "Resized",
+		fmt.Sprintf("PVC %s has the requested size %s", existing.Name, requested.String()))
+	return nil
+}
+
+// storageClassAllowsExpansion reports whether volumes of the storage class
+// can be expanded, and why not otherwise. Claims without a storage class are
+// bound to pre-provisioned volumes, which cannot be expanded.
+func (r *KlausInstanceReconciler) storageClassAllowsExpansion(ctx context.Context, name *string) (bool, string, error) {
+	if name == nil || *name == "" {
+		return false, "the PVC has no storage class", nil
+	}
+	var sc storagev1.StorageClass
+	if err := r.Get(ctx, types.NamespacedName{Name: *name}, &sc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, fmt.Sprintf("storage class %s not found", *name), nil
+		}
+		return false, "", fmt.Errorf("getting storage class %s: %w", *name, err)
+	}
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		return false, fmt.Sprintf("storage class %s does not allow volume expansion", *name), nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func resizeInstance(storageClass, size string) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("agent", testOwner)
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{
+		StorageClass: storageClass,
+		Size:         ptr.To(resource.MustParse(size)),
+	}
+	return instance
+}
+
+// boundPVC returns the workspace PVC of the instance as bound with the given
+// capacity.
+func boundPVC(instance *klausv1alpha1.KlausInstance, namespace, capacity string) *corev1.PersistentVolumeClaim {
+	pvc := resources.BuildPVC(instance, namespace)
+	pvc.Status.Phase = corev1.ClaimBound
+	pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)}
+	return pvc
+}
+
+func resizeReconciler(t *testing.T, objs ...client.Object) *KlausInstanceReconciler {
+	t.Helper()
+	return testReconciler(t, testScheme(t, storagev1.AddToScheme), objs...)
+}
+
+func TestReconcilePVC_Resize(t *testing.T) {
+	namespace := "klaus-user-user"
+	expandable := &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: "expandable"},
+		Provisioner:          "ebs.csi.aws.com",
+		AllowVolumeExpansion: ptr.To(true),
+	}
+	fixed := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "fixed"},
+		Provisioner: "ebs.csi.aws.com",
+	}
+
+	instance := resizeInstance("expandable", "10Gi")
+	r := resizeReconciler(t, expandable, fixed, boundPVC(instance, namespace, "10Gi"))
+
+	// An unchanged size sets no condition.
+	if err := r.reconcilePVC(context.Background(), instance, instance, namespace); err != nil {
+		t.Fatalf("reconcilePVC: %v", err)
+	}
+	if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionWorkspaceResized); cond != nil {
+		t.Errorf("condition = %+v, want none without a resize", cond)
+	}
+
+	instance.Spec.Workspace.Size = ptr.To(resource.MustParse("20Gi"))
+	if err := r.reconcilePVC(context.Background(), instance, instance, namespace); err != nil {
+		t.Fatalf("reconcilePVC: %v", err)
+	}
+	var pvc corev1.PersistentVolumeClaim
+	key := types.NamespacedName{Name: resources.PVCName(instance), Namespace: namespace}
+	if err := r.Get(context.Background(), key, &pvc); err != nil {
+		t.Fatalf("getting PVC: %v", err)
+	}
+	if got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; got.String() != "20Gi" {
+		t.Errorf("requested storage = %s, want 20Gi", got.String())
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionWorkspaceResized)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "Resizing" {
+		t.Fatalf("condition = %+v, want Resizing", cond)
+	}
+
+	// The volume reached the requested capacity.
+	pvc.Status.Capacity[corev1.ResourceStorage] = resource.MustParse("20Gi")
+	if err := r.Status().Update(context.Background(), &pvc); err != nil {
+		t.Fatalf("updating PVC status: %v", err)
+	}
+	if err := r.reconcilePVC(context.Background(), instance, instance, namespace); err != nil {
+		t.Fatalf("reconcilePVC: %v", err)
+	}
+	cond = apimeta.FindStatusCondition(instance.Status.Conditions, ConditionWorkspaceResized)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "Resized" {
+		t.Errorf("condition = %+v, want Resized", cond)
+	}
+
+	// Shrinking is rejected and leaves the PVC alone.
+	instance.Spec.Workspace.Size = ptr.To(resource.MustParse("15Gi"))
+	if err := r.reconcilePVC(context.Background(), instance, instance, namespace); err != nil {
+		t.Fatalf("reconcilePVC: %v", err)
+	}
+	cond = apimeta.FindStatusCondition(instance.Status.Conditions, ConditionWorkspaceResized)
+	if cond == nil || cond.Reason != "ShrinkNotSupported" {
+		t.Errorf("condition = %+v, want ShrinkNotSupported", cond)
+	}
+	if err := r.Get(context.Background(), key, &pvc); err != nil {
+		t.Fatalf("getting PVC: %v", err)
+	}
+	if got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; got.String() != "20Gi" {
+		t.Errorf("requested storage = %s, want it kept at 20Gi", got.String())
+	}
+}
+
+func TestReconcilePVC_ExpansionNotSupported(t *testing.T) {
+	namespace := "klaus-user-user"
+	fixed := &storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "fixed"},
+		Provisioner: "ebs.csi.aws.com",
+	}
+
+	tests := []struct {
+		name         string
+		storageClass string
+		wantMessage  string
+	}{
+		{name: "expansion disabled", storageClass: "fixed", wantMessage: "does not allow volume expansion"},
+		{name: "missing storage class", storageClass: "gone", wantMessage: "storage class gone not found"},
+		{name: "no storage class", wantMessage: "no storage class"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := resizeInstance(tt.storageClass, "10Gi")
+			r := resizeReconciler(t, fixed, boundPVC(instance, namespace, "10Gi"))
+
+			instance.Spec.Workspace.Size = ptr.To(resource.MustParse("20Gi"))
+			if err := r.reconcilePVC(context.Background(), instance, instance, namespace); err != nil {
+				t.Fatalf("reconcilePVC: %v", err)
+			}
+			cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionWorkspaceResized)
+			if cond == nil || cond.Reason != "ExpansionNotSupported" || !strings.Contains(cond.Message, tt.wantMessage) {
+				t.Errorf("condition = %+v, want ExpansionNotSupported containing %q", cond, tt.wantMessage)
+			}
+
+			var pvc corev1.PersistentVolumeClaim
+			key := types.NamespacedName{Name: resources.PVCName(instance), Namespace: namespace}
+			if err := r.Get(context.Background(), key, &pvc); err != nil {
+				t.Fatalf("getting PVC: %v", err)
+			}
+			if got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; got.String() != "10Gi" {
+				t.Errorf("requested storage = %s, want it unchanged", got.String())
+			}
+		})
+	}
+}
+
+func TestReconcilePVC_AccessModesFollowPVC(t *testing.T) {
+	namespace := "klaus-user-user"
+	instance := resizeInstance("", "10Gi")
+	r := resizeReconciler(t, boundPVC(instance, namespace, "10Gi"))
+
+	// The existing ReadWriteOnce PVC cannot become ReadWriteMany, so a
+	// rolling update would leave the new pod waiting for the volume.
+	merged := instance.DeepCopy()
+	merged.Spec.Workspace.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	if err := r.reconcilePVC(context.Background(), instance, merged, namespace); err != nil {
+		t.Fatalf("reconcilePVC: %v", err)
+	}
+	if got := merged.Spec.Workspace.AccessModes; len(got) != 1 || got[0] != corev1.ReadWriteOnce {
+		t.Errorf("access modes = %v, want those of the PVC", got)
+	}
+	if got := resources.UpdateStrategy(merged); got != klausv1alpha1.UpdateStrategyRecreate {
+		t.Errorf("update strategy = %s, want Recreate", got)
+	}
+	if event := <-r.Recorder.(*record.FakeRecorder).Events; !strings.Contains(event, "AccessModesImmutable") {
+		t.Errorf("event = %q, want AccessModesImmutable", event)
+	}
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// ownerQuota returns the KlausQuota applying to an owner: the quota naming
+// the owner, or else the default quota without an owner. When several match,
+// the first by name wins. It returns nil when no quota applies.
+func ownerQuota(ctx context.Context, c client.Reader, namespace, owner string) (*klausv1alpha1.KlausQuota, error) {
+	var quotaList klausv1alpha1.KlausQuotaList
+	if err := c.List(ctx, &quotaList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("listing quotas: %w", err)
+	}
+	return resources.SelectQuota(quotaList.Items, owner), nil
+}
+
+// legacyAPILimitsConfigMapName is the per-owner ConfigMap of the removed
+// Sidecar API limiter. Leftovers are deleted.
+const legacyAPILimitsConfigMapName = "klaus-api-limits"
+
+// reconcileAPILimits resolves the owner's Anthropic API limit from their
+// KlausQuota and returns the instance's share of it: the budget split over
+// the owner's running Anthropic API instances. It returns nil when no limit
+// applies.
+func (r *KlausInstanceReconciler) reconcileAPILimits(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (*klausv1alpha1.APIRateLimit, error) {
+	if err := r.deleteLegacyAPILimits(ctx, namespace); err != nil {
+		return nil, err
+	}
+	quota, err := ownerQuota(ctx, r, r.OperatorNamespace, instance.Spec.Owner)
+	if err != nil || quota == nil || quota.Spec.AnthropicAPI == nil {
+		return nil, err
+	}
+	instances, err := r.countLimitedInstances(ctx, instance.Spec.Owner)
+	if err != nil {
+		return nil, err
+	}
+	return resources.APIRateLimitShare(quota.Spec.AnthropicAPI, instances), nil
+}
+
+// deleteLegacyAPILimits deletes the limits ConfigMap the operator created
+// for the removed Sidecar limiter in an owner namespace.
+func (r *KlausInstanceReconciler) deleteLegacyAPILimits(ctx context.Context, namespace string) error {
+	if r.NamespaceScoped {
+		// The Sidecar limiter was not available in namespace-scoped mode.
+		return nil
+	}
+	existing := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: legacyAPILimitsConfigMapName, Namespace: namespace}, existing)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching API limits ConfigMap: %w", err)
+	}
+	if existing.Labels[resources.LabelManagedBy] != resources.AppKlausOperator {
+		// Leave ConfigMaps the operator did not create alone.
+		return nil
+	}
+	if err := r.Delete(ctx, existing); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("deleting API limits ConfigMap: %w", err)
+	}
+	return nil
+}
+
+// countLimitedInstances counts the owner's running instances calling the
+// Anthropic API, which share the owner's API budget. Stopped and deleting
+// instances do not count.
+func (r *KlausInstanceReconciler) countLimitedInstances(ctx context.Context, owner string) (int, error) {
+	var instanceList klausv1alpha1.KlausInstanceList
+	if err := r.List(ctx, &instanceList, client.InNamespace(r.OperatorNamespace)); err != nil {
+		return 0, fmt.Errorf("listing instances: %w", err)
+	}
+	count := 0
+	for i := range instanceList.Items {
+		inst := &instanceList.Items[i]
+		if inst.Spec.Owner != owner || resources.IsStopped(inst) || !inst.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if resources.UsesAnthropicAPIKey(inst) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// EnqueueAPIBudgetSharers returns a map function that enqueues the owner's
+// other instances when one of them starts, stops, is created or is deleted,
+// so their share of the owner's Anthropic API budget follows the number of
+// running instances.
+func EnqueueAPIBudgetSharers(c client.Client, operatorNamespace string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		changed, ok := obj.(*klausv1alpha1.KlausInstance)
+		if !ok || !resources.UsesAnthropicAPIKey(changed) {
+			return nil
+		}
+
+		var instanceList klausv1alpha1.KlausInstanceList
+		if err := c.List(ctx, &instanceList, client.InNamespace(operatorNamespace)); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, inst := range instanceList.Items {
+			if inst.Spec.Owner != changed.Spec.Owner || inst.Name == changed.Name || !resources.UsesAnthropicAPIKey(&inst) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: inst.Name, Namespace: inst.Namespace},
+			})
+		}
+		return requests
+	}
+}
+
+// apiBudgetSharePredicate passes the instance events that change the number
+// of the owner's running instances: creations, deletions, stops, starts and
+// the start of a deletion.
+func apiBudgetSharePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldInstance, okOld := e.ObjectOld.(*klausv1alpha1.KlausInstance)
+			newInstance, okNew := e.ObjectNew.(*klausv1alpha1.KlausInstance)
+			if !okOld || !okNew {
+				return false
+			}
+			return resources.IsStopped(oldInstance) != resources.IsStopped(newInstance) ||
+				oldInstance.DeletionTimestamp.IsZero() != newInstance.DeletionTimestamp.IsZero()
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// EnqueueQuotaInstances returns a map function that enqueues the
+// KlausInstances a KlausQuota applies to: the instances of its owner, or all
+// instances for the default quota.
+func EnqueueQuotaInstances(c client.Client, operatorNamespace string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		quota, ok := obj.(*klausv1alpha1.KlausQuota)
+		if !ok {
+			return nil
+		}
+
+		var instanceList klausv1alpha1.KlausInstanceList
+		if err := c.List(ctx, &instanceList, client.InNamespace(operatorNamespace)); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, inst := range instanceList.Items {
+			if quota.Spec.Owner != "" && inst.Spec.Owner != quota.Spec.Owner {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: inst.Name, Namespace: inst.Namespace},
+			})
+		}
+		return requests
+	}
+}
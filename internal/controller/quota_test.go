@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func testQuota(name, owner string) *klausv1alpha1.KlausQuota {
+	return &klausv1alpha1.KlausQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausQuotaSpec{
+			Owner:        owner,
+			AnthropicAPI: &klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 4, RequestsPerMinute: 60},
+		},
+	}
+}
+
+func TestOwnerQuota(t *testing.T) {
+	r := testReconciler(t, testScheme(t),
+		testQuota("b-default", ""),
+		testQuota("a-default", ""),
+		testQuota("alice", "alice@example.com"),
+	)
+
+	tests := []struct {
+		owner string
+		want  string
+	}{
+		{owner: "alice@example.com", want: "alice"},
+		{owner: "bob@example.com", want: "a-default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			quota, err := ownerQuota(context.Background(), r, "klaus-system", tt.owner)
+			if err != nil {
+				t.Fatalf("ownerQuota() error = %v", err)
+			}
+			if quota == nil || quota.Name != tt.want {
+				t.Errorf("ownerQuota() = %v, want %s", quota, tt.want)
+			}
+		})
+	}
+
+	if quota, err := ownerQuota(context.Background(), testReconciler(t, testScheme(t)), "klaus-system", "bob@example.com"); err != nil || quota != nil {
+		t.Errorf("ownerQuota() without quotas = %v, %v, want nil", quota, err)
+	}
+}
+
+func TestReconcileAPILimits_Share(t *testing.T) {
+	instance := namedTestInstance("my-agent", "user@example.com")
+	stopped := namedTestInstance("stopped", "user@example.com")
+	stopped.Spec.Stopped = true
+	mock := namedTestInstance("mock", "user@example.com")
+	mock.Spec.MockMode = true
+	r := testReconciler(t, testScheme(t),
+		testQuota("default", ""),
+		instance, stopped, mock,
+		namedTestInstance("other", "user@example.com"),
+		namedTestInstance("someone-else", "other@example.com"),
+	)
+
+	limit, err := r.reconcileAPILimits(context.Background(), instance, ownerTestNamespace)
+	if err != nil {
+		t.Fatalf("reconcileAPILimits() error = %v", err)
+	}
+	want := klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 2, RequestsPerMinute: 30, Burst: 30}
+	if limit == nil || *limit != want {
+		t.Errorf("limit = %+v, want the budget split over the 2 running Anthropic instances", limit)
+	}
+
+	// Deleting an instance grows the share of the remaining one.
+	other := &klausv1alpha1.KlausInstance{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: "other", Namespace: "klaus-system"}, other); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+	limit, err = r.reconcileAPILimits(context.Background(), instance, ownerTestNamespace)
+	if err != nil || limit.MaxConcurrentRequests != 4 {
+		t.Errorf("limit = %+v (%v), want the whole budget after the deletion", limit, err)
+	}
+
+	if limit, err := testReconciler(t, testScheme(t), instance).reconcileAPILimits(context.Background(), instance, ownerTestNamespace); err != nil || limit != nil {
+		t.Errorf("reconcileAPILimits() without quota = %+v, %v, want nil", limit, err)
+	}
+}
+
+func TestReconcileAPILimits_DeletesLegacyConfigMap(t *testing.T) {
+	instance := namedTestInstance("my-agent", "user@example.com")
+	legacy := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: legacyAPILimitsConfigMapName, Namespace: ownerTestNamespace,
+		Labels: map[string]string{resources.LabelManagedBy: resources.AppKlausOperator},
+	}}
+	foreign := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: legacyAPILimitsConfigMapName, Namespace: "other-namespace"},
+		Data:       map[string]string{"custom": "true"},
+	}
+	r := testReconciler(t, testScheme(t), instance, legacy, foreign)
+
+	if _, err := r.reconcileAPILimits(context.Background(), instance, ownerTestNamespace); err != nil {
+		t.Fatalf("reconcileAPILimits() error = %v", err)
+	}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(legacy), &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the Sidecar limiter ConfigMap to be deleted, got %v", err)
+	}
+
+	if _, err := r.reconcileAPILimits(context.Background(), instance, "other-namespace"); err != nil {
+		t.Fatalf("reconcileAPILimits() error = %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(foreign), cm); err != nil || cm.Data["custom"] != "true" {
+		t.Errorf("expected the foreign ConfigMap to be left alone, got %v (%v)", cm.Data, err)
+	}
+}
+
+func TestEnqueueAPIBudgetSharers(t *testing.T) {
+	mock := namedTestInstance("alice-mock", "alice@example.com")
+	mock.Spec.MockMode = true
+	r := testReconciler(t, testScheme(t),
+		namedTestInstance("alice-1", "alice@example.com"),
+		namedTestInstance("alice-2", "alice@example.com"),
+		mock,
+		namedTestInstance("bob-1", "bob@example.com"),
+	)
+	enqueue := EnqueueAPIBudgetSharers(r.Client, "klaus-system")
+
+	reqs := enqueue(context.Background(), namedTestInstance("alice-1", "alice@example.com"))
+	if len(reqs) != 1 || reqs[0].Name != "alice-2" {
+		t.Errorf("requests = %v, want alice's other Anthropic instance", reqs)
+	}
+	if reqs := enqueue(context.Background(), mock); len(reqs) != 0 {
+		t.Errorf("requests = %v, want none for an instance outside the budget", reqs)
+	}
+
+	p := apiBudgetSharePredicate()
+	running := namedTestInstance("alice-1", "alice@example.com")
+	stopped := running.DeepCopy()
+	stopped.Spec.Stopped = true
+	relabeled := running.DeepCopy()
+	relabeled.Labels = map[string]string{"team": "a"}
+	if !p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: stopped}) {
+		t.Error("expected stopping an instance to pass")
+	}
+	if p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: relabeled}) {
+		t.Error("expected a label change to be filtered")
+	}
+	if !p.Create(event.CreateEvent{Object: running}) || !p.Delete(event.DeleteEvent{Object: running}) {
+		t.Error("expected creations and deletions to pass")
+	}
+}
+
+func TestEnqueueQuotaInstances(t *testing.T) {
+	r := testReconciler(t, testScheme(t),
+		namedTestInstance("alice-1", "alice@example.com"),
+		namedTestInstance("bob-1", "bob@example.com"),
+	)
+	enqueue := EnqueueQuotaInstances(r.Client, "klaus-system")
+
+	if reqs := enqueue(context.Background(), testQuota("alice", "alice@example.com")); len(reqs) != 1 || reqs[0].Name != "alice-1" {
+		t.Errorf("owner quota requests = %v, want alice-1", reqs)
+	}
+	if reqs := enqueue(context.Background(), testQuota("default", "")); len(reqs) != 2 {
+		t.Errorf("default quota requests = %v, want all instances", reqs)
+	}
+}
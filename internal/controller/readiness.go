@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// readinessGateTimeout bounds each readiness gate check so an unresponsive
+// dependency does not stall reconciliation.
+const readinessGateTimeout = 5 * time.Second
+
+// readinessGateRequeueInterval is how often the gates of an instance held
+// back by one are re-checked.
+const readinessGateRequeueInterval = 15 * time.Second
+
+// readinessGateRecheckInterval is how often the gates of a Running instance
+// are re-checked, so Ready reflects a dependency going down.
+const readinessGateRecheckInterval = time.Minute
+
+// ReadinessChecker evaluates the readiness gates of instances. The
+// production implementation checks them from the operator, while tests can
+// supply a mock.
+type ReadinessChecker interface {
+	// Check returns nil when the gate passes and the reason otherwise.
+	// namespace is the namespace of the instance's child resources.
+	Check(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string, gate klausv1alpha1.ReadinessGate) error
+}
+
+// httpReadinessScript requests the URL passed as its argument from the
+// klaus container with the fetch of Node.js, which the Claude Code CLI runs
+// on, and exits non-zero unless the response is 2xx.
+const httpReadinessScript = `const url = process.argv[1];
+fetch(url, {signal: AbortSignal.timeout(5000)}).then(
+  (r) => { if (!r.ok) { console.error("GET " + url + " returned " + r.status); process.exit(1); } },
+  (e) => { console.error(e.message); process.exit(1); });`
+
+// readinessChecker implements ReadinessChecker with uncached reads of the
+// gated objects and pods/exec into the instance pods.
+type readinessChecker struct {
+	reader   client.Reader
+	executor PodExecutor
+}
+
+// NewReadinessChecker creates a ReadinessChecker reading objects and pods
+// with reader, which should be uncached so arbitrary kinds do not start
+// informers, and running http and exec gates in the klaus container with
+// executor. HTTP requests are made from the instance pod rather than the
+// operator, so gates only reach what the agent itself can reach.
+func NewReadinessChecker(reader client.Reader, executor PodExecutor) ReadinessChecker {
+	return &readinessChecker{
+		reader:   reader,
+		executor: executor,
+	}
+}
+
+func (c *readinessChecker) Check(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string, gate klausv1alpha1.ReadinessGate) error {
+	switch {
+	case gate.HTTP != nil:
+		return c.checkHTTP(ctx, instance, namespace, gate.HTTP)
+	case gate.Object != nil:
+		return c.checkObject(ctx, namespace, gate.Object)
+	case gate.Exec != nil:
+		return c.checkExec(ctx, instance, namespace, gate.Exec)
+	}
+	return errors.New("no check configured")
+}
+
+func (c *readinessChecker) checkHTTP(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string, check *klausv1alpha1.HTTPReadinessCheck) error {
+	if c.executor == nil {
+		return errors.New("http gates are not supported")
+	}
+	pod, err := c.runningPod(ctx, instance, namespace)
+	if err != nil {
+		return err
+	}
+	if _, err := c.executor.Exec(ctx, pod.Namespace, pod.Name, resources.AppKlaus, []string{"node", "-e", httpReadinessScript, check.URL}); err != nil {
+		return fmt.Errorf("GET %s: %w", check.URL, err)
+	}
+	return nil
+}
+
+// checkObject checks an object in the namespace of the instance's child
+// resources. The operator reads it with its own rights, so objects in other
+// namespaces, which the instance creator might not be allowed to read, are
+// refused.
+func (c *readinessChecker) checkObject(ctx context.Context, namespace string, check *klausv1alpha1.ObjectReadinessCheck) error {
+	if check.Namespace != "" && check.Namespace != namespace {
+		return fmt.Errorf("%s %s: only objects in namespace %s can be checked", check.Kind, check.Name, namespace)
+	}
+	condition := check.Condition
+	if condition == "" {
+		condition = ConditionReady
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(check.APIVersion)
+	obj.SetKind(check.Kind)
+	if err := c.reader.Get(ctx, types.NamespacedName{Name: check.Name, Namespace: namespace}, obj); err != nil {
+		return fmt.Errorf("getting %s %s: %w", check.Kind, check.Name, err)
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conditions {
+		cond, ok := raw.(map[string]any)
+		if !ok || cond["type"] != condition {
+			continue
+		}
+		if cond["status"] == string(metav1.ConditionTrue) {
+			return nil
+		}
+		msg := fmt.Sprintf("%s %s condition %s is %v", check.Kind, check.Name, condition, cond["status"])
+		if detail, _ := cond["message"].(string); detail != "" {
+			msg += ": " + detail
+		}
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%s %s has no %s condition", check.Kind, check.Name, condition)
+}
+
+func (c *readinessChecker) checkExec(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string, check *klausv1alpha1.ExecReadinessCheck) error {
+	if c.executor == nil {
+		return errors.New("exec gates are not supported")
+	}
+	pod, err := c.runningPod(ctx, instance, namespace)
+	if err != nil {
+		return err
+	}
+	if _, err := c.executor.Exec(ctx, pod.Namespace, pod.Name, resources.AppKlaus, check.Command); err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(check.Command, " "), err)
+	}
+	return nil
+}
+
+// runningPod returns a running pod of the instance.
+func (c *readinessChecker) runningPod(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (*corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := c.reader.List(ctx, &pods,
+		client.InNamespace(namespace),
+		client.MatchingLabels(resources.SelectorLabels(instance))); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			return pod, nil
+		}
+	}
+	return nil, errors.New("no running pod")
+}
+
+// reconcileReadinessGates evaluates spec.readinessGates, records the result
+// of each in status.readinessGates and returns whether all passed. A
+// warning event is recorded when a gate stops passing.
+func (r *KlausInstanceReconciler) reconcileReadinessGates(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) bool {
+	gates := merged.Spec.ReadinessGates
+	if len(gates) == 0 {
+		instance.Status.ReadinessGates = nil
+		return true
+	}
+
+	now := metav1.Now()
+	statuses := make([]klausv1alpha1.ReadinessGateStatus, 0, len(gates))
+	ready := true
+	for _, gate := range gates {
+		err := errors.New("no readiness checker configured")
+		if r.ReadinessChecker != nil {
+			checkCtx, cancel := context.WithTimeout(ctx, readinessGateTimeout)
+			err = r.ReadinessChecker.Check(checkCtx, merged, namespace, gate)
+			cancel()
+		}
+
+		status := klausv1alpha1.ReadinessGateStatus{Name: gate.Name, Ready: err == nil, LastTransitionTime: now}
+		if err != nil {
+			status.Message = err.Error()
+			ready = false
+		}
+		previous := findReadinessGateStatus(instance.Status.ReadinessGates, gate.Name)
+		if previous != nil && previous.Ready == status.Ready {
+			status.LastTransitionTime = previous.LastTransitionTime
+		} else if err != nil && (previous == nil || previous.Ready) {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ReadinessGateFailed",
+				fmt.Sprintf("Readiness gate %s failed: %s", gate.Name, status.Message))
+		}
+		statuses = append(statuses, status)
+	}
+	instance.Status.ReadinessGates = statuses
+	return ready
+}
+
+func findReadinessGateStatus(statuses []klausv1alpha1.ReadinessGateStatus, name string) *klausv1alpha1.ReadinessGateStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	return nil
+}
+
+// updateStatusWaitingForReadinessGates keeps an instance whose pod is
+// available Pending while a readiness gate fails.
+func (r *KlausInstanceReconciler) updateStatusWaitingForReadinessGates(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace, resolvedImage string) (ctrl.Result, error) {
+	instance.Status.State = klausv1alpha1.InstanceStatePending
+	r.populateCommonStatus(instance, namespace, resolvedImage)
+	var failing []string
+	for _, gate := range instance.Status.ReadinessGates {
+		if !gate.Ready {
+			failing = append(failing, gate.Name)
+		}
+	}
+	setCondition(instance, ConditionReady, metav1.ConditionFalse, "ReadinessGatesNotReady",
+		"Waiting for readiness gates: "+strings.Join(failing, ", "))
+
+	if err := r.patchStatus(ctx, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: readinessGateRequeueInterval}, nil
+}
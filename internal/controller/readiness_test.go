@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// mockReadinessChecker fails the gates named in failing.
+type mockReadinessChecker struct {
+	failing map[string]error
+}
+
+func (m *mockReadinessChecker) Check(_ context.Context, _ *klausv1alpha1.KlausInstance, _ string, gate klausv1alpha1.ReadinessGate) error {
+	return m.failing[gate.Name]
+}
+
+func gatedInstance(gates ...klausv1alpha1.ReadinessGate) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("agent", testOwner)
+	instance.Spec.ReadinessGates = gates
+	return instance
+}
+
+func TestReadinessChecker_HTTP(t *testing.T) {
+	pod := workspaceStatusPod("agent", time.Now(), "")
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(pod).Build()
+	executor := &fakePodExecutor{}
+	checker := NewReadinessChecker(c, executor)
+	gate := klausv1alpha1.ReadinessGate{Name: "db", HTTP: &klausv1alpha1.HTTPReadinessCheck{URL: "http://db:8080/healthz"}}
+
+	// The request is made from the klaus container, not the operator.
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if executor.container != "klaus" || len(executor.command) != 4 || executor.command[0] != "node" || executor.command[3] != "http://db:8080/healthz" {
+		t.Errorf("ran %v in %q, want the URL fetched with node", executor.command, executor.container)
+	}
+
+	executor.err = errors.New("command terminated with exit code 1: GET http://db:8080/healthz returned 503")
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Check() error = %v, want the 503 status", err)
+	}
+
+	if err := NewReadinessChecker(c, nil).Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err == nil {
+		t.Error("expected http gates to fail without an executor")
+	}
+}
+
+func TestReadinessChecker_Object(t *testing.T) {
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "klaus-user-user-example-com"},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Message: "no replicas"},
+		}},
+	}
+	scheme := testScheme(t)
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding appsv1 to scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dep).Build()
+	checker := NewReadinessChecker(c, nil)
+	gate := klausv1alpha1.ReadinessGate{Name: "db", Object: &klausv1alpha1.ObjectReadinessCheck{
+		APIVersion: "apps/v1", Kind: "Deployment", Name: "postgres", Condition: "Available",
+	}}
+
+	err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate)
+	if err == nil || !strings.Contains(err.Error(), "no replicas") {
+		t.Errorf("Check() error = %v, want the condition message", err)
+	}
+
+	dep.Status.Conditions[0].Status = corev1.ConditionTrue
+	if err := c.Status().Update(context.Background(), dep); err != nil {
+		t.Fatal(err)
+	}
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	gate.Object.Condition = ""
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err == nil || !strings.Contains(err.Error(), "no Ready condition") {
+		t.Errorf("Check() error = %v, want the missing Ready condition", err)
+	}
+
+	// Objects outside the instance's namespace are read with the operator's
+	// rights and refused.
+	gate.Object.Namespace = "kube-system"
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err == nil || !strings.Contains(err.Error(), "only objects in namespace") {
+		t.Errorf("Check() error = %v, want objects in other namespaces refused", err)
+	}
+	gate.Object.Namespace = ownerTestNamespace
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err == nil || !strings.Contains(err.Error(), "no Ready condition") {
+		t.Errorf("Check() error = %v, want the instance's namespace allowed", err)
+	}
+}
+
+func TestReadinessChecker_Exec(t *testing.T) {
+	pod := workspaceStatusPod("agent", time.Now(), "")
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(pod).Build()
+	executor := &fakePodExecutor{}
+	checker := NewReadinessChecker(c, executor)
+	gate := klausv1alpha1.ReadinessGate{Name: "vpn", Exec: &klausv1alpha1.ExecReadinessCheck{Command: []string{"test", "-e", "/run/vpn/up"}}}
+
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if executor.container != "klaus" || strings.Join(executor.command, " ") != "test -e /run/vpn/up" {
+		t.Errorf("ran %v in %q", executor.command, executor.container)
+	}
+
+	executor.err = errors.New("command terminated with exit code 1")
+	if err := checker.Check(context.Background(), gatedInstance(), ownerTestNamespace, gate); err == nil {
+		t.Error("expected a failing command to fail the gate")
+	}
+}
+
+func TestReconcileReadinessGates(t *testing.T) {
+	gates := []klausv1alpha1.ReadinessGate{
+		{Name: "db", HTTP: &klausv1alpha1.HTTPReadinessCheck{URL: "http://db"}},
+		{Name: "vpn", Exec: &klausv1alpha1.ExecReadinessCheck{Command: []string{"true"}}},
+	}
+	checker := &mockReadinessChecker{failing: map[string]error{"vpn": errors.New("tunnel down")}}
+	recorder := record.NewFakeRecorder(10)
+	r := &KlausInstanceReconciler{Recorder: recorder, ReadinessChecker: checker}
+	instance := gatedInstance(gates...)
+
+	if r.reconcileReadinessGates(context.Background(), instance, instance, ownerTestNamespace) {
+		t.Fatal("expected the failing vpn gate to hold the instance")
+	}
+	statuses := instance.Status.ReadinessGates
+	if len(statuses) != 2 || !statuses[0].Ready || statuses[1].Ready || statuses[1].Message != "tunnel down" {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected one ReadinessGateFailed event, got %d", len(recorder.Events))
+	}
+
+	// An unchanged result keeps its transition time and records no event.
+	transition := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	instance.Status.ReadinessGates[1].LastTransitionTime = transition
+	r.reconcileReadinessGates(context.Background(), instance, instance, ownerTestNamespace)
+	if !instance.Status.ReadinessGates[1].LastTransitionTime.Equal(&transition) {
+		t.Error("expected the transition time to be kept")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected no further event, got %d", len(recorder.Events))
+	}
+
+	delete(checker.failing, "vpn")
+	if !r.reconcileReadinessGates(context.Background(), instance, instance, ownerTestNamespace) {
+		t.Error("expected all gates to pass")
+	}
+
+	instance.Spec.ReadinessGates = nil
+	if !r.reconcileReadinessGates(context.Background(), instance, instance, ownerTestNamespace) || instance.Status.ReadinessGates != nil {
+		t.Error("expected an instance without gates to clear status.readinessGates")
+	}
+}
+
+func TestReconcileReadinessGates_NoChecker(t *testing.T) {
+	r := &KlausInstanceReconciler{Recorder: record.NewFakeRecorder(10)}
+	instance := gatedInstance(klausv1alpha1.ReadinessGate{Name: "db", HTTP: &klausv1alpha1.HTTPReadinessCheck{URL: "http://db"}})
+
+	if r.reconcileReadinessGates(context.Background(), instance, instance, ownerTestNamespace) {
+		t.Error("expected gates to fail without a checker")
+	}
+}
+
+func TestUpdateStatusWaitingForReadinessGates(t *testing.T) {
+	instance := gatedInstance(klausv1alpha1.ReadinessGate{Name: "db", HTTP: &klausv1alpha1.HTTPReadinessCheck{URL: "http://db"}})
+	instance.Status.ReadinessGates = []klausv1alpha1.ReadinessGateStatus{{Name: "db", Message: "connection refused"}}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).Build()
+	r := &KlausInstanceReconciler{Client: c}
+
+	result, err := r.updateStatusWaitingForReadinessGates(context.Background(), instance, "klaus-user-user-example-com", "klaus:latest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter != readinessGateRequeueInterval {
+		t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, readinessGateRequeueInterval)
+	}
+	if instance.Status.State != klausv1alpha1.InstanceStatePending {
+		t.Errorf("state = %s, want Pending", instance.Status.State)
+	}
+	ready := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionReady)
+	if ready == nil || ready.Reason != "ReadinessGatesNotReady" || !strings.Contains(ready.Message, "db") {
+		t.Errorf("Ready = %+v", ready)
+	}
+}
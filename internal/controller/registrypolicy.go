@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// ReasonRegistryPolicyViolation is the reason of the Ready and Complete
+// conditions of instances and jobs referencing a registry the registry policy
+// does not allow.
+const ReasonRegistryPolicyViolation = "RegistryPolicyViolation"
+
+// checkRegistryPolicy checks the resolved personality, toolchain image and
+// plugins of instance against the registry policy. The personality's own
+// image and plugins are merged into the spec by then, so they are covered
+// too.
+func (r *KlausInstanceReconciler) checkRegistryPolicy(instance *klausv1alpha1.KlausInstance) error {
+	return r.RegistryPolicy.Validate(field.NewPath("spec"),
+		instance.Spec.Image, instance.Spec.Personality, instance.Spec.Plugins).ToAggregate()
+}
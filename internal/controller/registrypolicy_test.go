@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+)
+
+func TestCheckRegistryPolicy(t *testing.T) {
+	instance := verificationInstance()
+	if err := (&KlausInstanceReconciler{}).checkRegistryPolicy(instance); err != nil {
+		t.Errorf("unexpected error without a registry policy: %v", err)
+	}
+
+	r := &KlausInstanceReconciler{RegistryPolicy: &registrypolicy.Policy{Allow: []string{"gsoci.azurecr.io/giantswarm"}}}
+	err := r.checkRegistryPolicy(instance)
+	if err == nil || !strings.Contains(err.Error(), "spec.image") || strings.Contains(err.Error(), "spec.plugins") {
+		t.Errorf("error = %v, want only the Docker Hub image rejected", err)
+	}
+}
+
+func TestReconcile_RegistryPolicyViolation(t *testing.T) {
+	ctx := context.Background()
+	instance := verificationInstance()
+	instance.Finalizers = []string{FinalizerName}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(instance).
+		WithStatusSubresource(instance).
+		Build()
+	r := &KlausInstanceReconciler{
+		Client:            c,
+		Recorder:          record.NewFakeRecorder(10),
+		OperatorNamespace: "klaus-system",
+		RegistryPolicy:    &registrypolicy.Policy{Deny: []string{"docker.io"}},
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "agent", Namespace: "klaus-system"}}
+	if _, err := r.Reconcile(ctx, req); err == nil || !strings.Contains(err.Error(), "docker.io/library/golang is denied") {
+		t.Fatalf("Reconcile() error = %v, want the registry policy violation", err)
+	}
+
+	var got klausv1alpha1.KlausInstance
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("getting instance: %v", err)
+	}
+	ready := apimeta.FindStatusCondition(got.Status.Conditions, ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != ReasonRegistryPolicyViolation ||
+		got.Status.State != klausv1alpha1.InstanceStateError {
+		t.Errorf("state = %s, ready = %+v; want a RegistryPolicyViolation error", got.Status.State, ready)
+	}
+}
+
+func TestKlausJobReconcile_RegistryPolicyViolationFails(t *testing.T) {
+	ctx := context.Background()
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "review",
+			Namespace:  "klaus-system",
+			Finalizers: []string{FinalizerName},
+		},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:  "user@example.com",
+			Prompt: "Review the open pull requests",
+			Image:  "ghcr.io/someone/agent:v1",
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).WithObjects(job).WithStatusSubresource(job).Build()
+	r := newJobReconciler(c)
+	r.RegistryPolicy = &registrypolicy.Policy{Allow: []string{"gsoci.azurecr.io"}}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "review", Namespace: "klaus-system"}}
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updated klausv1alpha1.KlausJob
+	if err := c.Get(ctx, req.NamespacedName, &updated); err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	complete := apimeta.FindStatusCondition(updated.Status.Conditions, JobConditionComplete)
+	if updated.Status.State != klausv1alpha1.JobStateFailed || complete == nil || complete.Reason != ReasonRegistryPolicyViolation {
+		t.Errorf("state = %q, complete = %+v; want a permanent RegistryPolicyViolation failure", updated.Status.State, complete)
+	}
+}
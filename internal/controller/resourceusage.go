@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+// DefaultResourceUsageInterval is how often status.resourceUsage of the
+// instances is refreshed unless configured otherwise.
+const DefaultResourceUsageInterval = 5 * time.Minute
+
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get;list
+
+// ResourceUsageReporter is a leader-elected manager runnable that
+// periodically records the resource usage of every instance in its
+// status.resourceUsage, so owners can see whether their agent is starved
+// with kubectl alone.
+type ResourceUsageReporter struct {
+	Client client.Client
+
+	// Collector reads the usage of an instance.
+	Collector *usage.Collector
+
+	// Interval is the refresh interval.
+	Interval time.Duration
+}
+
+// NeedLeaderElection ensures only the leader writes instance status.
+func (r *ResourceUsageReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs Refresh every Interval; see periodic.
+func (r *ResourceUsageReporter) Start(ctx context.Context) error {
+	return periodic{
+		name:            "resource-usage",
+		interval:        r.Interval,
+		defaultInterval: DefaultResourceUsageInterval,
+		action:          "refreshing resource usage",
+		fn:              r.Refresh,
+	}.Start(ctx)
+}
+
+// Refresh collects the usage of all instances and patches it into their
+// status. Instances without usage, such as stopped ones, have it cleared.
+func (r *ResourceUsageReporter) Refresh(ctx context.Context) error {
+	var instances klausv1alpha1.KlausInstanceList
+	if err := r.Client.List(ctx, &instances); err != nil {
+		return fmt.Errorf("listing KlausInstances: %w", err)
+	}
+
+	var errs []error
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if !instance.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.refreshInstance(ctx, instance); err != nil {
+			errs = append(errs, fmt.Errorf("instance %s: %w", instance.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refreshInstance records the current usage of one instance.
+func (r *ResourceUsageReporter) refreshInstance(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	current, err := r.Collector.Collect(ctx, instance)
+	if err != nil {
+		return err
+	}
+	status := current.Status()
+	if status == nil && instance.Status.ResourceUsage == nil {
+		return nil
+	}
+
+	base := instance.DeepCopy()
+	instance.Status.ResourceUsage = status
+	if err := r.Client.Status().Patch(ctx, instance, client.MergeFrom(base)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("patching status: %w", err)
+	}
+	return nil
+}
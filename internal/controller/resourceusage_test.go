@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+func TestResourceUsageReporter_Refresh(t *testing.T) {
+	running := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	stale := resource.MustParse("1")
+	stopped := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "stopped", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com", Stopped: true},
+		Status: klausv1alpha1.KlausInstanceStatus{
+			ResourceUsage: &klausv1alpha1.ResourceUsage{CPU: &stale, ObservedAt: metav1.Now()},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "running-abc",
+			Namespace: "klaus-user-user-example-com",
+			Labels:    map[string]string{"app.kubernetes.io/name": "klaus", "app.kubernetes.io/instance": "running"},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "klaus"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	metrics := &unstructured.Unstructured{Object: map[string]any{
+		"metadata":   map[string]any{"name": "running-abc", "namespace": "klaus-user-user-example-com"},
+		"containers": []any{map[string]any{"name": "klaus", "usage": map[string]any{"cpu": "150m", "memory": "512Mi"}}},
+	}}
+	metrics.SetGroupVersionKind(usage.PodMetricsGVK)
+
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(running, stopped, pod, metrics).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).
+		Build()
+	reporter := &ResourceUsageReporter{Client: c, Collector: &usage.Collector{Client: c, MetricsReader: c}}
+
+	ctx := context.Background()
+	if err := reporter.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	var got klausv1alpha1.KlausInstance
+	if err := c.Get(ctx, types.NamespacedName{Name: "running", Namespace: "klaus-system"}, &got); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	ru := got.Status.ResourceUsage
+	if ru == nil || ru.CPU.String() != "150m" || ru.Memory.String() != "512Mi" || ru.ObservedAt.IsZero() {
+		t.Errorf("resourceUsage = %+v", ru)
+	}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: "stopped", Namespace: "klaus-system"}, &got); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	if got.Status.ResourceUsage != nil {
+		t.Errorf("resourceUsage = %+v, want it cleared for a stopped instance", got.Status.ResourceUsage)
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// DefaultResultInterval is how often status.result of the instances is
+// refreshed unless configured otherwise.
+const DefaultResultInterval = 30 * time.Second
+
+// ResultFetcher reads the result of the last completed prompt of a running
+// agent instance. It returns nil while the agent is still working.
+type ResultFetcher interface {
+	FetchResult(ctx context.Context, instance *klausv1alpha1.KlausInstance) (*klausv1alpha1.InstanceResult, error)
+}
+
+// ResultReporter is a leader-elected manager runnable that periodically
+// records the final output of agent mode instances in their status.result,
+// including results of prompts sent directly to the agent rather than
+// through the operator's MCP tools. The result is kept when the instance
+// stops.
+type ResultReporter struct {
+	Client client.Client
+
+	// Fetcher reads the result from the agent.
+	Fetcher ResultFetcher
+
+	// Interval is the refresh interval.
+	Interval time.Duration
+}
+
+// NeedLeaderElection ensures only the leader writes instance status.
+func (r *ResultReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs Refresh every Interval; see periodic.
+func (r *ResultReporter) Start(ctx context.Context) error {
+	return periodic{
+		name:            "result",
+		interval:        r.Interval,
+		defaultInterval: DefaultResultInterval,
+		action:          "refreshing instance results",
+		fn:              r.Refresh,
+	}.Start(ctx)
+}
+
+// Refresh records the result of all running agent mode instances whose
+// agent completed a prompt since the last refresh.
+func (r *ResultReporter) Refresh(ctx context.Context) error {
+	var instances klausv1alpha1.KlausInstanceList
+	if err := r.Client.List(ctx, &instances); err != nil {
+		return fmt.Errorf("listing KlausInstances: %w", err)
+	}
+
+	var errs []error
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if !instance.DeletionTimestamp.IsZero() ||
+			instance.Status.State != klausv1alpha1.InstanceStateRunning ||
+			instance.Status.Mode == klausv1alpha1.InstanceModeChat {
+			continue
+		}
+		if err := r.refreshInstance(ctx, instance); err != nil {
+			errs = append(errs, fmt.Errorf("instance %s: %w", instance.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refreshInstance records the result of one instance when it changed, and
+// whether it matches spec.claude.jsonSchema in ConditionResultValid.
+func (r *ResultReporter) refreshInstance(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	result, err := r.Fetcher.FetchResult(ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	ctx = withStatusBase(ctx, instance)
+	if result != nil && resources.ResultChanged(instance.Status.Result, result) {
+		instance.Status.Result = result
+	}
+	setResultCondition(instance)
+	if err := patchObjectStatus(ctx, r.Client, nil, instance); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("patching status: %w", err)
+	}
+	return nil
+}
+
+// setResultCondition sets ConditionResultValid from status.result, removing
+// it when there is nothing to report.
+func setResultCondition(instance *klausv1alpha1.KlausInstance) {
+	status, reason, message, ok := resources.ResultCondition(instance)
+	if !ok {
+		apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionResultValid)
+		return
+	}
+	setCondition(instance, ConditionResultValid, status, reason, message)
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// fakeResultFetcher returns a fixed result per instance and records which
+// instances were fetched.
+type fakeResultFetcher struct {
+	results map[string]*klausv1alpha1.InstanceResult
+	err     error
+	fetched []string
+}
+
+func (f *fakeResultFetcher) FetchResult(_ context.Context, instance *klausv1alpha1.KlausInstance) (*klausv1alpha1.InstanceResult, error) {
+	f.fetched = append(f.fetched, instance.Name)
+	return f.results[instance.Name].DeepCopy(), f.err
+}
+
+func resultInstance(name string, state klausv1alpha1.InstanceState, result *klausv1alpha1.InstanceResult) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance(name, testOwner)
+	instance.Status = klausv1alpha1.KlausInstanceStatus{
+		State:  state,
+		Mode:   klausv1alpha1.InstanceModeAgent,
+		Result: result,
+	}
+	return instance
+}
+
+func getResult(t *testing.T, c client.Client, name string) *klausv1alpha1.InstanceResult {
+	t.Helper()
+	var instance klausv1alpha1.KlausInstance
+	if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	return instance.Status.Result
+}
+
+func TestResultReporter_RecordsCompletedResult(t *testing.T) {
+	c := workspaceStatusClient(t, resultInstance("alpha", klausv1alpha1.InstanceStateRunning, nil))
+	fetcher := &fakeResultFetcher{results: map[string]*klausv1alpha1.InstanceResult{
+		"alpha": {Text: "All tests pass.", MessageCount: 2, CompletedAt: metav1.Now()},
+	}}
+
+	reporter := &ResultReporter{Client: c, Fetcher: fetcher}
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	result := getResult(t, c, "alpha")
+	if result == nil || result.Text != "All tests pass." || result.MessageCount != 2 {
+		t.Errorf("status.result = %+v, want the fetched result", result)
+	}
+}
+
+func TestResultReporter_SetsResultCondition(t *testing.T) {
+	instance := resultInstance("alpha", klausv1alpha1.InstanceStateRunning, nil)
+	instance.Spec.Claude.JSONSchema = `{"type":"object","required":["issues"]}`
+	c := workspaceStatusClient(t, instance)
+	fetcher := &fakeResultFetcher{results: map[string]*klausv1alpha1.InstanceResult{
+		"alpha": resources.BuildInstanceResult(instance, `{"summary":"ok"}`, 2, time.Now()),
+	}}
+
+	reporter := &ResultReporter{Client: c, Fetcher: fetcher}
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	var got klausv1alpha1.KlausInstance
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(instance), &got); err != nil {
+		t.Fatal(err)
+	}
+	cond := apimeta.FindStatusCondition(got.Status.Conditions, ConditionResultValid)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "SchemaMismatch" {
+		t.Fatalf("ResultValid condition = %+v, want False/SchemaMismatch", cond)
+	}
+
+	// A matching result sets the condition back to True.
+	fetcher.results["alpha"] = resources.BuildInstanceResult(instance, `{"issues":[]}`, 4, time.Now())
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(instance), &got); err != nil {
+		t.Fatal(err)
+	}
+	if cond := apimeta.FindStatusCondition(got.Status.Conditions, ConditionResultValid); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("ResultValid condition = %+v, want True", cond)
+	}
+}
+
+func TestResultReporter_KeepsUnchangedResult(t *testing.T) {
+	observed := metav1.NewTime(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC))
+	c := workspaceStatusClient(t, resultInstance("alpha", klausv1alpha1.InstanceStateRunning,
+		&klausv1alpha1.InstanceResult{Text: "done", MessageCount: 2, CompletedAt: observed}))
+	fetcher := &fakeResultFetcher{results: map[string]*klausv1alpha1.InstanceResult{
+		"alpha": {Text: "done", MessageCount: 2, CompletedAt: metav1.Now()},
+	}}
+
+	reporter := &ResultReporter{Client: c, Fetcher: fetcher}
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if result := getResult(t, c, "alpha"); !result.CompletedAt.Equal(&observed) {
+		t.Errorf("CompletedAt = %v, want the first observation %v", result.CompletedAt, observed)
+	}
+}
+
+func TestResultReporter_SkipsInstances(t *testing.T) {
+	previous := &klausv1alpha1.InstanceResult{Text: "earlier", CompletedAt: metav1.Now()}
+	chat := resultInstance("chat", klausv1alpha1.InstanceStateRunning, nil)
+	chat.Status.Mode = klausv1alpha1.InstanceModeChat
+	c := workspaceStatusClient(t,
+		resultInstance("stopped", klausv1alpha1.InstanceStateStopped, previous),
+		resultInstance("working", klausv1alpha1.InstanceStateRunning, previous),
+		chat,
+	)
+	fetcher := &fakeResultFetcher{}
+
+	reporter := &ResultReporter{Client: c, Fetcher: fetcher}
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if len(fetcher.fetched) != 1 || fetcher.fetched[0] != "working" {
+		t.Errorf("fetched = %v, want only the running agent instance", fetcher.fetched)
+	}
+	for _, name := range []string{"stopped", "working"} {
+		if result := getResult(t, c, name); result == nil || result.Text != "earlier" {
+			t.Errorf("%s: status.result = %+v, want the previous result kept", name, result)
+		}
+	}
+}
+
+func TestResultReporter_ReportsFetchErrors(t *testing.T) {
+	c := workspaceStatusClient(t, resultInstance("alpha", klausv1alpha1.InstanceStateRunning, nil))
+	reporter := &ResultReporter{Client: c, Fetcher: &fakeResultFetcher{err: errors.New("connection refused")}}
+
+	if err := reporter.Refresh(context.Background()); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// maxConditionMessageLength caps propagated messages so a verbose registry or
+// kubelet error does not bloat the KlausInstance status.
+const maxConditionMessageLength = 256
+
+// benignWaitingReasons are container waiting reasons that are part of a
+// normal rollout and not worth surfacing as a failure.
+var benignWaitingReasons = map[string]bool{
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+}
+
+// rolloutProgress describes the rollout state of an instance Deployment.
+type rolloutProgress struct {
+	// Reason is a CamelCase reason suitable for a condition.
+	Reason string
+	// Message is a concise human-readable summary, e.g.
+	// "0/1 replicas available: container klaus: ImagePullBackOff: unauthorized".
+	Message string
+}
+
+// reconcileRolloutConditions mirrors the Deployment's Progressing and
+// ReplicaFailure conditions onto the instance and sets DeploymentReady with a
+// rollout summary that includes pod-level failures such as image pull or
+// crash loop errors. The pods are also diagnosed for the PodHealthy
+// condition, including while the Deployment is available.
+func (r *KlausInstanceReconciler) reconcileRolloutConditions(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, dep *appsv1.Deployment) {
+	mirrorDeploymentCondition(instance, dep, appsv1.DeploymentProgressing, ConditionDeploymentProgressing)
+	mirrorDeploymentCondition(instance, dep, appsv1.DeploymentReplicaFailure, ConditionDeploymentReplicaFailure)
+
+	var pods []corev1.Pod
+	if r.APIReader != nil {
+		var podList corev1.PodList
+		if err := r.APIReader.List(ctx, &podList,
+			client.InNamespace(dep.Namespace),
+			client.MatchingLabels(resources.SelectorLabels(merged)),
+		); err == nil {
+			pods = podList.Items
+			reconcilePodHealth(instance, pods)
+		}
+	}
+
+	if dep.Status.AvailableReplicas > 0 {
+		setCondition(instance, ConditionDeploymentReady, metav1.ConditionTrue, "Available", replicaSummary(dep))
+		return
+	}
+
+	progress := summarizeRollout(dep, pods)
+	setCondition(instance, ConditionDeploymentReady, metav1.ConditionFalse, progress.Reason, progress.Message)
+}
+
+// mirrorDeploymentCondition copies a Deployment condition onto the instance
+// under the given type, or removes it when the Deployment does not report it.
+func mirrorDeploymentCondition(instance *klausv1alpha1.KlausInstance, dep *appsv1.Deployment, depType appsv1.DeploymentConditionType, condType string) {
+	for _, c := range dep.Status.Conditions {
+		if c.Type != depType {
+			continue
+		}
+		reason := c.Reason
+		if reason == "" {
+			reason = string(depType)
+		}
+		setCondition(instance, condType, metav1.ConditionStatus(c.Status), reason, truncateMessage(c.Message))
+		return
+	}
+	apimeta.RemoveStatusCondition(&instance.Status.Conditions, condType)
+}
+
+// summarizeRollout derives the most useful explanation for an unavailable
+// Deployment, preferring concrete pod failures over Deployment conditions.
+func summarizeRollout(dep *appsv1.Deployment, pods []corev1.Pod) rolloutProgress {
+	summary := replicaSummary(dep)
+
+	if reason, detail := podFailure(pods); reason != "" {
+		return rolloutProgress{Reason: reason, Message: truncateMessage(summary + ": " + detail)}
+	}
+
+	for _, c := range dep.Status.Conditions {
+		switch {
+		case c.Type == appsv1.DeploymentReplicaFailure && c.Status == corev1.ConditionTrue:
+			return rolloutProgress{Reason: "ReplicaFailure", Message: truncateMessage(summary + ": " + c.Message)}
+		case c.Type == appsv1.DeploymentProgressing && c.Status == corev1.ConditionFalse:
+			return rolloutProgress{Reason: c.Reason, Message: truncateMessage(summary + ": " + c.Message)}
+		}
+	}
+
+	return rolloutProgress{Reason: "Progressing", Message: summary + ": Deployment is rolling out"}
+}
+
+// podFailure returns the first scheduling or container failure found across
+// the given pods, checking init containers before regular containers.
+func podFailure(pods []corev1.Pod) (reason, detail string) {
+	for _, pod := range pods {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason != "" {
+				return c.Reason, c.Message
+			}
+		}
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if w := cs.State.Waiting; w != nil && w.Reason != "" && !benignWaitingReasons[w.Reason] {
+				return w.Reason, containerDetail(cs.Name, w.Reason, w.Message)
+			}
+			if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+				reason := t.Reason
+				if reason == "" {
+					reason = "Error"
+				}
+				return reason, containerDetail(cs.Name, reason, fmt.Sprintf("exit code %d %s", t.ExitCode, t.Message))
+			}
+		}
+	}
+	return "", ""
+}
+
+func containerDetail(name, reason, message string) string {
+	if message == "" {
+		return fmt.Sprintf("container %s: %s", name, reason)
+	}
+	return fmt.Sprintf("container %s: %s: %s", name, reason, message)
+}
+
+// replicaSummary renders "<available>/<desired> replicas available".
+func replicaSummary(dep *appsv1.Deployment) string {
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+	return fmt.Sprintf("%d/%d replicas available", dep.Status.AvailableReplicas, desired)
+}
+
+func truncateMessage(msg string) string {
+	if len(msg) <= maxConditionMessageLength {
+		return msg
+	}
+	return msg[:maxConditionMessageLength-3] + "..."
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func TestSummarizeRollout_ImagePullBackOff(t *testing.T) {
+	dep := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))}}
+	pods := []corev1.Pod{{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "klaus",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "ImagePullBackOff",
+					Message: "unauthorized",
+				}},
+			}},
+		},
+	}}
+
+	got := summarizeRollout(dep, pods)
+	if got.Reason != "ImagePullBackOff" {
+		t.Errorf("Reason = %q, want %q", got.Reason, "ImagePullBackOff")
+	}
+	if got.Message != "0/1 replicas available: container klaus: ImagePullBackOff: unauthorized" {
+		t.Errorf("Message = %q", got.Message)
+	}
+}
+
+func TestSummarizeRollout_InitContainerCrash(t *testing.T) {
+	dep := &appsv1.Deployment{}
+	pods := []corev1.Pod{{
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name: "git-clone",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 128,
+				}},
+			}},
+		},
+	}}
+
+	got := summarizeRollout(dep, pods)
+	if got.Reason != "Error" || !strings.Contains(got.Message, "container git-clone: Error: exit code 128") {
+		t.Errorf("unexpected progress: %+v", got)
+	}
+}
+
+func TestSummarizeRollout_ReplicaFailure(t *testing.T) {
+	dep := &appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentReplicaFailure,
+				Status:  corev1.ConditionTrue,
+				Reason:  "FailedCreate",
+				Message: "pods \"klaus\" is forbidden: exceeded quota",
+			}},
+		},
+	}
+
+	got := summarizeRollout(dep, nil)
+	if got.Reason != "ReplicaFailure" || !strings.Contains(got.Message, "exceeded quota") {
+		t.Errorf("unexpected progress: %+v", got)
+	}
+}
+
+func TestSummarizeRollout_BenignWaiting(t *testing.T) {
+	pods := []corev1.Pod{{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "klaus",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			}},
+		},
+	}}
+
+	got := summarizeRollout(&appsv1.Deployment{}, pods)
+	if got.Reason != "Progressing" {
+		t.Errorf("Reason = %q, want %q", got.Reason, "Progressing")
+	}
+}
+
+func TestReconcileRolloutConditions_MirrorsDeploymentConditions(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-user-user-example-com"},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: "ReplicaSet has timed out progressing.",
+			}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "agent-abc",
+			Namespace: dep.Namespace,
+			Labels:    resources.SelectorLabels(instance),
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "klaus",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(pod).Build()
+	r := &KlausInstanceReconciler{APIReader: c}
+
+	r.reconcileRolloutConditions(context.Background(), instance, instance, dep)
+
+	progressing := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDeploymentProgressing)
+	if progressing == nil || progressing.Reason != "ProgressDeadlineExceeded" {
+		t.Errorf("expected mirrored Progressing condition, got %+v", progressing)
+	}
+	if apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDeploymentReplicaFailure) != nil {
+		t.Error("expected no ReplicaFailure condition")
+	}
+	ready := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionDeploymentReady)
+	if ready == nil || ready.Reason != "CrashLoopBackOff" {
+		t.Errorf("expected DeploymentReady reason CrashLoopBackOff, got %+v", ready)
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// sandboxPreset returns the sandbox preset of the instance, falling back to
+// the default presets when the operator was not configured with any.
+func (r *KlausInstanceReconciler) sandboxPreset(instance *klausv1alpha1.KlausInstance) resources.SandboxPreset {
+	presets := r.SandboxPresets
+	if presets == nil {
+		presets = resources.DefaultSandboxPresets()
+	}
+	return presets.Preset(instance)
+}
+
+// reconcileNetworkPolicy keeps the NetworkPolicy in line with the sandbox
+// preset, removing it when the preset does not restrict the network.
+func (r *KlausInstanceReconciler) reconcileNetworkPolicy(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) error {
+	desired := resources.BuildNetworkPolicy(merged, namespace, r.OperatorNamespace, r.sandboxPreset(merged))
+	if desired == nil {
+		existing := &networkingv1.NetworkPolicy{}
+		err := r.Get(ctx, types.NamespacedName{Name: resources.NetworkPolicyName(merged), Namespace: namespace}, existing)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, existing))
+	}
+
+	existing := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
+		existing.Spec = desired.Spec
+		existing.Labels = desired.Labels
+		return r.setInstanceOwner(instance, existing)
+	})
+	if op == controllerutil.OperationResultCreated {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingNetworkPolicy", "Created NetworkPolicy "+desired.Name)
+	}
+	return err
+}
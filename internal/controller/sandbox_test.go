@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func networkPolicyTestReconciler(t *testing.T) *KlausInstanceReconciler {
+	t.Helper()
+	return testReconciler(t, testScheme(t, networkingv1.AddToScheme))
+}
+
+func getNetworkPolicy(t *testing.T, r *KlausInstanceReconciler) (*networkingv1.NetworkPolicy, error) {
+	t.Helper()
+	np := &networkingv1.NetworkPolicy{}
+	err := r.Get(context.Background(), types.NamespacedName{Name: "my-agent", Namespace: schedulingTestNamespace}, np)
+	return np, err
+}
+
+func TestReconcileNetworkPolicy(t *testing.T) {
+	r := networkPolicyTestReconciler(t)
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com", Sandbox: klausv1alpha1.SandboxStrict},
+	}
+
+	if err := r.reconcileNetworkPolicy(context.Background(), instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcileNetworkPolicy() error = %v", err)
+	}
+	if _, err := getNetworkPolicy(t, r); err != nil {
+		t.Fatalf("expected NetworkPolicy to be created: %v", err)
+	}
+
+	// Switching to a preset that does not restrict the network removes it.
+	instance.Spec.Sandbox = klausv1alpha1.SandboxGVisor
+	if err := r.reconcileNetworkPolicy(context.Background(), instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcileNetworkPolicy() error = %v", err)
+	}
+	if _, err := getNetworkPolicy(t, r); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NetworkPolicy to be deleted, got err = %v", err)
+	}
+}
+
+func TestReconcileNetworkPolicy_ConfiguredPresets(t *testing.T) {
+	r := networkPolicyTestReconciler(t)
+	r.SandboxPresets = resources.SandboxPresets{
+		klausv1alpha1.SandboxStrict: {RuntimeClassName: "gvisor"},
+	}
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com", Sandbox: klausv1alpha1.SandboxStrict},
+	}
+
+	if err := r.reconcileNetworkPolicy(context.Background(), instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcileNetworkPolicy() error = %v", err)
+	}
+	if _, err := getNetworkPolicy(t, r); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no NetworkPolicy when the configured strict preset does not restrict the network, got err = %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// reconcilePodDisruptionBudget keeps the PodDisruptionBudget in line with
+// spec.scheduling.disruptionBudget, removing it when unset or stopped.
+func (r *KlausInstanceReconciler) reconcilePodDisruptionBudget(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) error {
+	desired := resources.BuildPodDisruptionBudget(merged, namespace)
+	if desired == nil {
+		existing := &policyv1.PodDisruptionBudget{}
+		err := r.Get(ctx, types.NamespacedName{Name: resources.PodDisruptionBudgetName(merged), Namespace: namespace}, existing)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, existing))
+	}
+
+	existing := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: desired.Name, Namespace: desired.Namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
+		existing.Spec = desired.Spec
+		existing.Labels = desired.Labels
+		return r.setInstanceOwner(instance, existing)
+	})
+	if op == controllerutil.OperationResultCreated {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "CreatingPodDisruptionBudget", "Created PodDisruptionBudget "+desired.Name)
+	}
+	return err
+}
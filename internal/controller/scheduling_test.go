@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const schedulingTestNamespace = "klaus-user-user-example-com"
+
+func pdbTestReconciler(t *testing.T) *KlausInstanceReconciler {
+	t.Helper()
+	return testReconciler(t, testScheme(t, policyv1.AddToScheme))
+}
+
+func pdbTestInstance(budget *klausv1alpha1.DisruptionBudgetConfig) *klausv1alpha1.KlausInstance {
+	instance := testInstance()
+	if budget != nil {
+		instance.Spec.Scheduling = &klausv1alpha1.SchedulingConfig{DisruptionBudget: budget}
+	}
+	return instance
+}
+
+func getPDB(t *testing.T, r *KlausInstanceReconciler) (*policyv1.PodDisruptionBudget, error) {
+	t.Helper()
+	pdb := &policyv1.PodDisruptionBudget{}
+	err := r.Get(context.Background(), types.NamespacedName{Name: "my-agent", Namespace: schedulingTestNamespace}, pdb)
+	return pdb, err
+}
+
+func TestReconcilePodDisruptionBudget_CreatesAndUpdates(t *testing.T) {
+	r := pdbTestReconciler(t)
+	instance := pdbTestInstance(&klausv1alpha1.DisruptionBudgetConfig{})
+
+	if err := r.reconcilePodDisruptionBudget(context.Background(), instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcilePodDisruptionBudget() error = %v", err)
+	}
+	pdb, err := getPDB(t, r)
+	if err != nil {
+		t.Fatalf("expected PodDisruptionBudget to be created: %v", err)
+	}
+	if pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.IntValue() != 1 {
+		t.Errorf("MinAvailable = %v, want 1", pdb.Spec.MinAvailable)
+	}
+
+	maxUnavailable := intstr.FromString("100%")
+	instance.Spec.Scheduling.DisruptionBudget = &klausv1alpha1.DisruptionBudgetConfig{MaxUnavailable: &maxUnavailable}
+	if err := r.reconcilePodDisruptionBudget(context.Background(), instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcilePodDisruptionBudget() error = %v", err)
+	}
+	pdb, err = getPDB(t, r)
+	if err != nil {
+		t.Fatalf("fetching PodDisruptionBudget: %v", err)
+	}
+	if pdb.Spec.MinAvailable != nil || pdb.Spec.MaxUnavailable == nil || pdb.Spec.MaxUnavailable.String() != "100%" {
+		t.Errorf("spec = %+v, want maxUnavailable 100%% only", pdb.Spec)
+	}
+}
+
+func TestReconcilePodDisruptionBudget_RemovedWhenStoppedOrUnset(t *testing.T) {
+	r := pdbTestReconciler(t)
+	instance := pdbTestInstance(&klausv1alpha1.DisruptionBudgetConfig{})
+	if err := r.reconcilePodDisruptionBudget(context.Background(), instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcilePodDisruptionBudget() error = %v", err)
+	}
+
+	instance.Spec.Stopped = true
+	if err := r.reconcilePodDisruptionBudget(context.Background(), instance, instance, schedulingTestNamespace); err != nil {
+		t.Fatalf("reconcilePodDisruptionBudget() error = %v", err)
+	}
+	if _, err := getPDB(t, r); !apierrors.IsNotFound(err) {
+		t.Errorf("expected PodDisruptionBudget to be deleted while stopped, got err = %v", err)
+	}
+
+	// Without a budget there is nothing to do.
+	if err := r.reconcilePodDisruptionBudget(context.Background(), pdbTestInstance(nil), pdbTestInstance(nil), schedulingTestNamespace); err != nil {
+		t.Errorf("reconcilePodDisruptionBudget() error = %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// DefaultShardLeaseDuration is the shard Lease duration of the
+// controller-runtime leader election.
+const DefaultShardLeaseDuration = 15 * time.Second
+
+// ShardOf returns the shard of the KlausInstance with the given namespace
+// and name: the FNV-1a hash of "namespace/name" modulo shards.
+func ShardOf(key types.NamespacedName, shards int) int {
+	return int(fnvHash(key.String()) % uint32(shards))
+}
+
+// fnvHash returns the FNV-1a hash of s.
+func fnvHash(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// shardLeasePrefix starts the names of the shard Leases.
+const shardLeasePrefix = "klaus-operator-shard-"
+
+// ShardLeaseName returns the name of the Lease of a shard out of shards.
+// The shard count is part of the name, so replicas splitting the instances
+// differently never share a Lease; see ShardClaimer.
+func ShardLeaseName(shard, shards int) string {
+	return fmt.Sprintf("%s%d-of-%d", shardLeasePrefix, shard, shards)
+}
+
+// ShardClaimer splits the KlausInstances across operator replicas. Every
+// replica runs one and competes for the Leases of all Shards shards, holding
+// every one it acquires: shards are never left unreconciled, whatever the
+// replica count. To spread them, a replica competes for its home shard,
+// picked by the hash of its identity, right away and for the others after
+// one Lease duration more per shard it already holds. While a shard Lease of
+// another shard count is held, e.g. during a rolling upgrade changing
+// Shards, no shard is claimed: the replicas would own the same instances. A
+// nil ShardClaimer owns every instance.
+type ShardClaimer struct {
+	// Client reads and writes the shard Leases.
+	Client coordinationv1client.LeasesGetter
+
+	// Namespace is where the shard Leases live.
+	Namespace string
+
+	// Identity is the holder identity of this replica in the Leases.
+	Identity string
+
+	// Shards is the number of shards.
+	Shards int
+
+	// LeaseDuration is how long a replica that stops renewing keeps its
+	// shards. Defaults to DefaultShardLeaseDuration.
+	LeaseDuration time.Duration
+
+	mu      sync.Mutex
+	held    map[int]context.Context // cancelled once the shard is lost
+	claimed chan int
+	started bool
+}
+
+// Owns reports whether this replica reconciles the instance with the given
+// key.
+func (c *ShardClaimer) Owns(key types.NamespacedName) bool {
+	_, owned := c.shardContext(key)
+	return owned
+}
+
+// ReconcileContext returns a copy of ctx cancelled once this replica loses
+// the shard of the instance with the given key, so in-flight reconciles stop
+// writing when another replica may take the instance over, and whether the
+// replica holds the shard. The CancelFunc must be called.
+func (c *ShardClaimer) ReconcileContext(ctx context.Context, key types.NamespacedName) (context.Context, context.CancelFunc, bool) {
+	shardCtx, owned := c.shardContext(key)
+	if !owned || shardCtx == nil {
+		return ctx, func() {}, owned
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(shardCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}, true
+}
+
+// shardContext returns the context of the held shard of the instance with
+// the given key, nil for a nil ShardClaimer owning every instance.
+func (c *ShardClaimer) shardContext(key types.NamespacedName) (context.Context, bool) {
+	if c == nil {
+		return nil, true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	shardCtx, ok := c.held[ShardOf(key, c.Shards)]
+	return shardCtx, ok
+}
+
+// Held returns the shards this replica holds, in order.
+func (c *ShardClaimer) Held() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	shards := make([]int, 0, len(c.held))
+	for shard := range c.held {
+		shards = append(shards, shard)
+	}
+	slices.Sort(shards)
+	return shards
+}
+
+// claims returns the channel announcing the shards this replica claims.
+func (c *ShardClaimer) claims() chan int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.claimed == nil {
+		c.claimed = make(chan int, 1)
+	}
+	return c.claimed
+}
+
+// Start implements manager.Runnable. It competes for every shard until ctx
+// is cancelled.
+func (c *ShardClaimer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return fmt.Errorf("shard claimer already started")
+	}
+	c.started = true
+	c.mu.Unlock()
+
+	for i := range c.Shards {
+		if _, err := c.elector(i, new(atomic.Bool)); err != nil {
+			return fmt.Errorf("creating elector of shard %d: %w", i, err)
+		}
+	}
+	var wg sync.WaitGroup
+	for i := range c.Shards {
+		wg.Go(func() { c.compete(ctx, i) })
+	}
+	wg.Wait()
+	return nil
+}
+
+// elector returns the leader elector of a shard Lease. Leading, it sets led
+// and records the shard as held; compete removes it once Run returns.
+func (c *ShardClaimer) elector(shard int, led *atomic.Bool) (*leaderelection.LeaderElector, error) {
+	duration := cmp.Or(c.LeaseDuration, DefaultShardLeaseDuration)
+	name := ShardLeaseName(shard, c.Shards)
+	return leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Name: name, Namespace: c.Namespace},
+			Client:     c.Client,
+			LockConfig: resourcelock.ResourceLockConfig{Identity: c.Identity},
+		},
+		LeaseDuration:   duration,
+		RenewDeadline:   duration * 2 / 3,
+		RetryPeriod:     duration * 2 / 15,
+		ReleaseOnCancel: true,
+		Name:            name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			// The context is cancelled once the Lease is lost.
+			OnStartedLeading: func(leaseCtx context.Context) {
+				led.Store(true)
+				c.mu.Lock()
+				if leaseCtx.Err() != nil {
+					c.mu.Unlock()
+					return
+				}
+				if c.held == nil {
+					c.held = map[int]context.Context{}
+				}
+				c.held[shard] = leaseCtx
+				c.mu.Unlock()
+				ctrl.Log.WithName("shard-claimer").Info("claimed shard", "shard", shard, "shards", c.Shards)
+				select {
+				case c.claims() <- shard:
+				default:
+				}
+
+			},
+			OnStoppedLeading: func() {},
+		},
+	})
+}
+
+// compete runs the elector of a shard until ctx is cancelled. Each attempt
+// gives up after one Lease duration unless it acquired the Lease, so the
+// delay before competing follows the shards held meanwhile.
+func (c *ShardClaimer) compete(ctx context.Context, shard int) {
+	logger := ctrl.Log.WithName("shard-claimer")
+	duration := cmp.Or(c.LeaseDuration, DefaultShardLeaseDuration)
+	home := int(fnvHash(c.Identity) % uint32(c.Shards))
+	for ctx.Err() == nil {
+		delay := time.Duration(0)
+		if shard != home {
+			delay = duration * time.Duration(1+len(c.Held()))
+		}
+		if !sleepContext(ctx, delay) {
+			return
+		}
+		other, err := c.otherShardLease(ctx, duration)
+		if err != nil {
+			logger.Error(err, "listing the shard Leases", "shard", shard)
+		}
+		if other != "" || err != nil {
+			if other != "" {
+				logger.Info("waiting for the shard Lease of another shard count to be released", "lease", other, "shards", c.Shards)
+			}
+			if !sleepContext(ctx, duration) {
+				return
+			}
+			continue
+		}
+
+		var led atomic.Bool
+		le, err := c.elector(shard, &led)
+		if err != nil {
+			logger.Error(err, "creating the shard elector", "shard", shard)
+			return
+		}
+		attemptCtx, cancel := context.WithCancel(ctx)
+		timer := time.AfterFunc(duration, func() {
+			if !le.IsLeader() {
+				cancel()
+			}
+		})
+		// Run cancels the Lease context before returning.
+		le.Run(attemptCtx)
+		timer.Stop()
+		cancel()
+		c.mu.Lock()
+		delete(c.held, shard)
+		c.mu.Unlock()
+		if led.Load() && ctx.Err() == nil {
+			logger.Info("lost shard", "shard", shard)
+		}
+	}
+}
+
+// otherShardLease returns the name of a shard Lease of another shard count
+// held by a replica, "" when there is none.
+func (c *ShardClaimer) otherShardLease(ctx context.Context, duration time.Duration) (string, error) {
+	leases, err := c.Client.Leases(c.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, lease := range leases.Items {
+		if !strings.HasPrefix(lease.Name, shardLeasePrefix) ||
+			strings.HasSuffix(lease.Name, fmt.Sprintf("-of-%d", c.Shards)) {
+			continue
+		}
+		if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" || lease.Spec.RenewTime == nil {
+			continue
+		}
+		expiry := duration
+		if lease.Spec.LeaseDurationSeconds != nil {
+			expiry = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+		}
+		if time.Since(lease.Spec.RenewTime.Time) < expiry {
+			return lease.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// sleepContext waits for d and reports whether ctx is still active.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable: every
+// replica claims shards.
+func (c *ShardClaimer) NeedLeaderElection() bool {
+	return false
+}
+
+// claimedInstancesSource enqueues the KlausInstances of every shard the
+// claimer claims, so a replica taking over a shard reconciles its instances
+// without waiting for their next change.
+func claimedInstancesSource(c *ShardClaimer, reader client.Reader) source.Source {
+	return source.Func(func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+		go func() {
+			logger := ctrl.Log.WithName("shard-claimer")
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case shard := <-c.claims():
+					var list klausv1alpha1.KlausInstanceList
+					if err := reader.List(ctx, &list); err != nil {
+						logger.Error(err, "listing the instances of a claimed shard", "shard", shard)
+						continue
+					}
+					for _, instance := range list.Items {
+						key := client.ObjectKeyFromObject(&instance)
+						if ShardOf(key, c.Shards) == shard {
+							queue.Add(reconcile.Request{NamespacedName: key})
+						}
+					}
+				}
+			}
+		}()
+		return nil
+	})
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestShardOf(t *testing.T) {
+	counts := make([]int, 4)
+	for i := range 400 {
+		key := types.NamespacedName{Namespace: "klaus-system", Name: fmt.Sprintf("agent-%d", i)}
+		shard := ShardOf(key, 4)
+		if again := ShardOf(key, 4); again != shard {
+			t.Fatalf("ShardOf(%s) = %d then %d, want a stable shard", key, shard, again)
+		}
+		counts[shard]++
+	}
+	for shard, n := range counts {
+		if n < 50 {
+			t.Errorf("shard %d has %d of 400 instances, want them spread", shard, n)
+		}
+	}
+}
+
+func TestShardClaimer_NilOwnsEverything(t *testing.T) {
+	var c *ShardClaimer
+	if !c.Owns(types.NamespacedName{Namespace: "klaus-system", Name: "agent"}) {
+		t.Error("nil ShardClaimer does not own the instance, want every instance owned")
+	}
+}
+
+// startShardClaimer starts a claimer of shards shards on the shared fake
+// clientset and returns it with the function stopping it.
+func startShardClaimer(t *testing.T, clientset *kubefake.Clientset, identity string, shards int) (*ShardClaimer, context.CancelFunc) {
+	t.Helper()
+	c := &ShardClaimer{
+		Client:        clientset.CoordinationV1(),
+		Namespace:     "klaus-system",
+		Identity:      identity,
+		Shards:        shards,
+		LeaseDuration: time.Second,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := c.Start(ctx); err != nil {
+			t.Errorf("Start: %v", err)
+		}
+	}()
+	stop := func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return c, stop
+}
+
+// waitForShards waits until c holds n shards and returns them.
+func waitForShards(t *testing.T, c *ShardClaimer, n int) []int {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if shards := c.Held(); len(shards) >= n {
+			return shards
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("%s holds shards %v, want %d", c.Identity, c.Held(), n)
+	return nil
+}
+
+func TestShardClaimer_SeveralShardsPerReplica(t *testing.T) {
+	clientset := kubefake.NewClientset()
+	a, _ := startShardClaimer(t, clientset, "a", 3)
+	if shards := waitForShards(t, a, 3); !slices.Equal(shards, []int{0, 1, 2}) {
+		t.Fatalf("held %v, want every shard on the only replica", shards)
+	}
+
+	lease, err := clientset.CoordinationV1().Leases("klaus-system").Get(context.Background(), ShardLeaseName(0, 3), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting the shard Lease: %v", err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != "a" {
+		t.Errorf("holder = %v, want a", lease.Spec.HolderIdentity)
+	}
+	for i := range 20 {
+		if key := (types.NamespacedName{Namespace: "klaus-system", Name: fmt.Sprintf("agent-%d", i)}); !a.Owns(key) {
+			t.Errorf("Owns(%s) = false, want every instance owned", key)
+		}
+	}
+}
+
+func TestShardClaimer_EveryShardHeldOnce(t *testing.T) {
+	clientset := kubefake.NewClientset()
+	a, _ := startShardClaimer(t, clientset, "a", 2)
+	b, _ := startShardClaimer(t, clientset, "b", 2)
+	deadline := time.Now().Add(10 * time.Second)
+	for len(a.Held())+len(b.Held()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	held := append(a.Held(), b.Held()...)
+	slices.Sort(held)
+	if !slices.Equal(held, []int{0, 1}) {
+		t.Fatalf("a holds %v and b %v, want each shard held once", a.Held(), b.Held())
+	}
+
+	key := types.NamespacedName{Namespace: "klaus-system", Name: "agent"}
+	if a.Owns(key) == b.Owns(key) {
+		t.Errorf("Owns(%s) = %v on both replicas, want exactly one owner", key, a.Owns(key))
+	}
+}
+
+func TestShardClaimer_StandbyTakesOver(t *testing.T) {
+	clientset := kubefake.NewClientset()
+	a, stopA := startShardClaimer(t, clientset, "a", 1)
+	waitForShards(t, a, 1)
+	b, _ := startShardClaimer(t, clientset, "b", 1)
+	time.Sleep(300 * time.Millisecond)
+	if shards := b.Held(); len(shards) > 0 {
+		t.Fatalf("standby holds shards %v while a holds them, want none", shards)
+	}
+
+	// Stopping a releases its Lease.
+	stopA()
+	if shards := a.Held(); len(shards) > 0 {
+		t.Errorf("stopped replica holds shards %v, want none", shards)
+	}
+	if shards := waitForShards(t, b, 1); !slices.Equal(shards, []int{0}) {
+		t.Errorf("standby holds shards %v, want 0", shards)
+	}
+}
+
+func TestShardClaimer_OtherShardCount(t *testing.T) {
+	clientset := kubefake.NewClientset()
+	old, stopOld := startShardClaimer(t, clientset, "old", 1)
+	waitForShards(t, old, 1)
+
+	// The replica of the new shard count waits for the old Lease.
+	c, _ := startShardClaimer(t, clientset, "new", 2)
+	time.Sleep(500 * time.Millisecond)
+	if shards := c.Held(); len(shards) > 0 {
+		t.Fatalf("held %v while a Lease of another shard count is held, want none", shards)
+	}
+	stopOld()
+	waitForShards(t, c, 2)
+}
+
+func TestShardClaimer_ReconcileContext(t *testing.T) {
+	leaseCtx, lose := context.WithCancel(context.Background())
+	c := &ShardClaimer{Shards: 1, held: map[int]context.Context{0: leaseCtx}}
+	key := types.NamespacedName{Namespace: "klaus-system", Name: "agent"}
+
+	ctx, cancel, owned := c.ReconcileContext(context.Background(), key)
+	defer cancel()
+	if !owned || ctx.Err() != nil {
+		t.Fatalf("owned = %v, err = %v; want an active context of the held shard", owned, ctx.Err())
+	}
+	lose()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("reconcile context not cancelled once the shard was lost")
+	}
+
+	var none *ShardClaimer
+	if ctx, cancel, owned := none.ReconcileContext(context.Background(), key); !owned || ctx.Err() != nil {
+		t.Errorf("nil ShardClaimer: owned = %v, err = %v; want every instance owned", owned, ctx.Err())
+	} else {
+		cancel()
+	}
+}
+
+func TestClaimedInstancesSource(t *testing.T) {
+	scheme := testScheme(t)
+	var objs []klausv1alpha1.KlausInstance
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range 20 {
+		instance := klausv1alpha1.KlausInstance{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("agent-%d", i), Namespace: "klaus-system"}}
+		objs = append(objs, instance)
+		builder = builder.WithObjects(&instance)
+	}
+	c := &ShardClaimer{Shards: 3}
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := claimedInstancesSource(c, builder.Build()).Start(ctx, queue); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	c.claims() <- 1
+
+	want := 0
+	for _, instance := range objs {
+		if ShardOf(types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name}, 3) == 1 {
+			want++
+		}
+	}
+	for range want {
+		req, _ := queue.Get()
+		if ShardOf(req.NamespacedName, 3) != 1 {
+			t.Errorf("enqueued %s of shard %d, want shard 1 only", req.NamespacedName, ShardOf(req.NamespacedName, 3))
+		}
+		queue.Done(req)
+	}
+	if n := queue.Len(); n != 0 {
+		t.Errorf("%d more requests enqueued, want the %d instances of shard 1", n, want)
+	}
+}
+
+func TestKlausInstanceReconcile_OtherShard(t *testing.T) {
+	instance := testInstance()
+	scheme := testScheme(t)
+	r := &KlausInstanceReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build(),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+		// Holding no shard, the replica owns no instance.
+		Shards: &ShardClaimer{Shards: 2},
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	var got klausv1alpha1.KlausInstance
+	if err := r.Get(context.Background(), types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}, &got); err != nil {
+		t.Fatalf("getting KlausInstance: %v", err)
+	}
+	if len(got.Finalizers) > 0 {
+		t.Errorf("finalizers = %v, want the instance of another shard left alone", got.Finalizers)
+	}
+}
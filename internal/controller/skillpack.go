@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// ReasonSkillPackNotFound is the reason of the Ready condition of instances
+// referencing a KlausSkillPack that does not exist.
+const ReasonSkillPackNotFound = "SkillPackNotFound"
+
+// resolveSkillPacks adds the skills, agent files and commands of the
+// KlausSkillPacks referenced by the merged spec, which includes those of
+// the inline personality. Packs are validated here rather than trusting
+// their Ready condition, which may lag behind a spec change.
+func (r *KlausInstanceReconciler) resolveSkillPacks(ctx context.Context, merged *klausv1alpha1.KlausInstance) error {
+	for _, ref := range merged.Spec.SkillPacks {
+		var pack klausv1alpha1.KlausSkillPack
+		if err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: merged.Namespace}, &pack); err != nil {
+			return fmt.Errorf("resolving skill pack %q: %w", ref.Name, err)
+		}
+		if err := resources.ValidateSkillPack(&pack.Spec); err != nil {
+			return fmt.Errorf("skill pack %q: %w", ref.Name, err)
+		}
+		resources.MergeSkillPack(merged, &pack.Spec)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// SoulFetcher reads the SOUL.md of personality artifacts.
+type SoulFetcher interface {
+	// FetchSoul returns the soul of the personality artifact ref, empty when
+	// it has none.
+	FetchSoul(ctx context.Context, ref string) (string, error)
+}
+
+// fetchPersonalitySoul returns the soul of the resolved personality artifact
+// of the instance, rendered into its memory by resources.SetPersonalitySoul.
+// It returns nothing without a SoulFetcher, for instances without a
+// personality artifact, and when an inline personality sets the soul.
+func (r *KlausInstanceReconciler) fetchPersonalitySoul(ctx context.Context, instance *klausv1alpha1.KlausInstance) (string, error) {
+	if r.SoulFetcher == nil || instance.Spec.Personality == "" {
+		return "", nil
+	}
+	if p := instance.Spec.InlinePersonality; p != nil && p.Soul != "" {
+		return "", nil
+	}
+	soul, err := r.SoulFetcher.FetchSoul(ctx, instance.Spec.Personality)
+	if err != nil {
+		return "", fmt.Errorf("fetching the soul of personality %q: %w", instance.Spec.Personality, err)
+	}
+	return soul, nil
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+type fakeSoulFetcher struct {
+	souls   map[string]string
+	err     error
+	fetched []string
+}
+
+func (f *fakeSoulFetcher) FetchSoul(_ context.Context, ref string) (string, error) {
+	f.fetched = append(f.fetched, ref)
+	return f.souls[ref], f.err
+}
+
+func TestFetchPersonalitySoul(t *testing.T) {
+	const ref = "registry.example.com/personalities/reviewer@sha256:abc"
+	fetcher := &fakeSoulFetcher{souls: map[string]string{ref: "You review code."}}
+	r := &KlausInstanceReconciler{SoulFetcher: fetcher}
+
+	instance := testInstance()
+	instance.Spec.Personality = ref
+	soul, err := r.fetchPersonalitySoul(context.Background(), instance)
+	if err != nil || soul != "You review code." {
+		t.Fatalf("fetchPersonalitySoul() = %q, %v, want the soul of the artifact", soul, err)
+	}
+
+	instance.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{Soul: "You write docs."}
+	if soul, err := r.fetchPersonalitySoul(context.Background(), instance); err != nil || soul != "" {
+		t.Errorf("fetchPersonalitySoul() with an inline soul = %q, %v, want none", soul, err)
+	}
+	if len(fetcher.fetched) != 1 {
+		t.Errorf("fetched %v, want the artifact fetched once", fetcher.fetched)
+	}
+
+	if soul, err := r.fetchPersonalitySoul(context.Background(), testInstance()); err != nil || soul != "" {
+		t.Errorf("fetchPersonalitySoul() without a personality = %q, %v, want none", soul, err)
+	}
+	if soul, err := (&KlausInstanceReconciler{}).fetchPersonalitySoul(context.Background(), instance); err != nil || soul != "" {
+		t.Errorf("fetchPersonalitySoul() without a fetcher = %q, %v, want none", soul, err)
+	}
+}
+
+func TestFetchPersonalitySoul_Error(t *testing.T) {
+	pullErr := errors.New("unauthorized")
+	r := &KlausInstanceReconciler{SoulFetcher: &fakeSoulFetcher{err: pullErr}}
+	instance := testInstance()
+	instance.Spec.Personality = "registry.example.com/personalities/reviewer:v1"
+
+	if _, err := r.fetchPersonalitySoul(context.Background(), instance); !errors.Is(err, pullErr) {
+		t.Errorf("fetchPersonalitySoul() error = %v, want the pull error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+type statusBaseKey struct{}
+
+// statusBase is the object, with its status, last read from or written to
+// the API server during a reconcile.
+type statusBase struct {
+	obj client.Object
+}
+
+// withStatusBase records the status of obj as read at the start of a
+// reconcile, so patchObjectStatus only writes the fields the reconcile
+// changed.
+func withStatusBase(ctx context.Context, obj client.Object) context.Context {
+	return context.WithValue(ctx, statusBaseKey{}, &statusBase{obj: obj.DeepCopyObject().(client.Object)})
+}
+
+// statusBaseFor returns the status base of ctx when it was recorded for obj.
+func statusBaseFor(ctx context.Context, obj client.Object) *statusBase {
+	base, _ := ctx.Value(statusBaseKey{}).(*statusBase)
+	if base == nil || reflect.TypeOf(base.obj) != reflect.TypeOf(obj) ||
+		client.ObjectKeyFromObject(base.obj) != client.ObjectKeyFromObject(obj) {
+		return nil
+	}
+	return base
+}
+
+// patchStatus writes the status of instance with patchObjectStatus.
+func (r *KlausInstanceReconciler) patchStatus(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	return patchObjectStatus(ctx, r.Client, r.APIReader, instance)
+}
+
+// patchObjectStatus writes the status fields of obj changed since the
+// status base of ctx as a merge patch with an optimistic lock. Nothing is
+// written when no field changed, so an unchanged status neither bumps the
+// resource version nor wakes up watchers. On a conflict, e.g. with a
+// reporter recording status.tokenUsage, the changed fields are applied to
+// the latest object read through reader, bypassing a cache that may still
+// hold the conflicting version, and the patch is retried, keeping the other
+// writer's fields. Conditions are merged by type. Without a status base the
+// whole status is written. A nil reader falls back to c.
+func patchObjectStatus(ctx context.Context, c client.Client, reader client.Reader, obj client.Object) error {
+	if reader == nil {
+		reader = c
+	}
+	base := statusBaseFor(ctx, obj)
+	first := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := obj.DeepCopyObject().(client.Object)
+		if first && base != nil {
+			statusOf(latest).Set(statusOf(base.obj.DeepCopyObject()))
+		} else if err := reader.Get(ctx, client.ObjectKeyFromObject(obj), latest); err != nil {
+			return err
+		}
+		first = false
+
+		desired := latest.DeepCopyObject().(client.Object)
+		if base != nil {
+			applyStatusChanges(statusOf(desired), statusOf(base.obj), statusOf(obj.DeepCopyObject()))
+		} else {
+			statusOf(desired).Set(statusOf(obj.DeepCopyObject()))
+		}
+		if !equality.Semantic.DeepEqual(statusOf(latest).Interface(), statusOf(desired).Interface()) {
+			if err := c.Status().Patch(ctx, desired, client.MergeFromWithOptions(latest, client.MergeFromWithOptimisticLock{})); err != nil {
+				return err
+			}
+		}
+
+		obj.SetResourceVersion(desired.GetResourceVersion())
+		statusOf(obj).Set(statusOf(desired.DeepCopyObject()))
+		if base != nil {
+			base.obj = desired.DeepCopyObject().(client.Object)
+		}
+		return nil
+	})
+}
+
+// statusOf returns the Status field of a pointer to an API object struct.
+func statusOf(obj runtime.Object) reflect.Value {
+	return reflect.ValueOf(obj).Elem().FieldByName("Status")
+}
+
+// applyStatusChanges copies the fields of the desired status struct that
+// differ from base to dst, sharing their values. Conditions are compared
+// and copied by type.
+func applyStatusChanges(dst, base, desired reflect.Value) {
+	conditionsType := reflect.TypeFor[[]metav1.Condition]()
+	for i := range desired.NumField() {
+		if equality.Semantic.DeepEqual(base.Field(i).Interface(), desired.Field(i).Interface()) {
+			continue
+		}
+		if field := desired.Type().Field(i); field.Name == "Conditions" && field.Type == conditionsType {
+			merged := mergeConditionChanges(dst.Field(i).Interface().([]metav1.Condition),
+				base.Field(i).Interface().([]metav1.Condition), desired.Field(i).Interface().([]metav1.Condition))
+			dst.Field(i).Set(reflect.ValueOf(merged))
+			continue
+		}
+		dst.Field(i).Set(desired.Field(i))
+	}
+}
+
+// mergeConditionChanges applies the conditions added, changed or removed
+// between base and desired to dst.
+func mergeConditionChanges(dst, base, desired []metav1.Condition) []metav1.Condition {
+	merged := append([]metav1.Condition(nil), dst...)
+	for _, cond := range desired {
+		if old := apimeta.FindStatusCondition(base, cond.Type); old != nil && equality.Semantic.DeepEqual(*old, cond) {
+			continue
+		}
+		if existing := apimeta.FindStatusCondition(merged, cond.Type); existing != nil {
+			*existing = cond
+		} else {
+			merged = append(merged, cond)
+		}
+	}
+	for _, cond := range base {
+		if apimeta.FindStatusCondition(desired, cond.Type) == nil {
+			apimeta.RemoveStatusCondition(&merged, cond.Type)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func statusTestInstance() *klausv1alpha1.KlausInstance {
+	instance := testInstance()
+	instance.Status.State = klausv1alpha1.InstanceStatePending
+	instance.Status.Conditions = []metav1.Condition{
+		{Type: ConditionReady, Status: metav1.ConditionFalse, Reason: "Progressing"},
+		{Type: ConditionDependenciesReady, Status: metav1.ConditionTrue, Reason: "Ready"},
+	}
+	return instance
+}
+
+func TestPatchStatus_Unchanged(t *testing.T) {
+	r := testReconciler(t, testScheme(t), statusTestInstance())
+	ctx := context.Background()
+
+	var instance klausv1alpha1.KlausInstance
+	if err := r.Get(ctx, client.ObjectKeyFromObject(statusTestInstance()), &instance); err != nil {
+		t.Fatal(err)
+	}
+	resourceVersion := instance.ResourceVersion
+	if err := r.patchStatus(withStatusBase(ctx, &instance), &instance); err != nil {
+		t.Fatalf("patchStatus() error = %v", err)
+	}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(&instance), &instance); err != nil {
+		t.Fatal(err)
+	}
+	if instance.ResourceVersion != resourceVersion {
+		t.Errorf("resource version %s -> %s, want no write for an unchanged status", resourceVersion, instance.ResourceVersion)
+	}
+}
+
+func TestPatchStatus_KeepsConcurrentChanges(t *testing.T) {
+	r := testReconciler(t, testScheme(t), statusTestInstance())
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(statusTestInstance())
+
+	var instance klausv1alpha1.KlausInstance
+	if err := r.Get(ctx, key, &instance); err != nil {
+		t.Fatal(err)
+	}
+	ctx = withStatusBase(ctx, &instance)
+
+	// A reporter records token usage after the reconcile read the instance.
+	var reported klausv1alpha1.KlausInstance
+	if err := r.Get(ctx, key, &reported); err != nil {
+		t.Fatal(err)
+	}
+	base := reported.DeepCopy()
+	reported.Status.TokenUsage = &klausv1alpha1.TokenUsage{InputTokens: 42}
+	if err := r.Status().Patch(ctx, &reported, client.MergeFrom(base)); err != nil {
+		t.Fatal(err)
+	}
+
+	instance.Status.State = klausv1alpha1.InstanceStateRunning
+	setCondition(&instance, ConditionReady, metav1.ConditionTrue, "Reconciled", "")
+	apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionDependenciesReady)
+	if err := r.patchStatus(ctx, &instance); err != nil {
+		t.Fatalf("patchStatus() error = %v", err)
+	}
+
+	var got klausv1alpha1.KlausInstance
+	if err := r.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.State != klausv1alpha1.InstanceStateRunning {
+		t.Errorf("state = %s, want the reconciler's change", got.Status.State)
+	}
+	if got.Status.TokenUsage == nil || got.Status.TokenUsage.InputTokens != 42 {
+		t.Errorf("tokenUsage = %+v, want the reporter's change kept", got.Status.TokenUsage)
+	}
+	if !apimeta.IsStatusConditionTrue(got.Status.Conditions, ConditionReady) ||
+		apimeta.FindStatusCondition(got.Status.Conditions, ConditionDependenciesReady) != nil {
+		t.Errorf("conditions = %+v, want Ready true and DependenciesReady removed", got.Status.Conditions)
+	}
+	if instance.ResourceVersion != got.ResourceVersion || instance.Status.TokenUsage == nil {
+		t.Errorf("instance not refreshed from the written status: %+v", instance.Status)
+	}
+
+	// The next write of the same reconcile is relative to the written status.
+	instance.Status.PluginCount = 3
+	if err := r.patchStatus(ctx, &instance); err != nil {
+		t.Fatalf("second patchStatus() error = %v", err)
+	}
+	if err := r.Get(ctx, key, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.PluginCount != 3 || got.Status.TokenUsage == nil {
+		t.Errorf("status = %+v, want the plugin count added", got.Status)
+	}
+}
+
+func TestMergeConditionChanges(t *testing.T) {
+	base := []metav1.Condition{
+		{Type: "A", Status: metav1.ConditionTrue},
+		{Type: "B", Status: metav1.ConditionTrue},
+	}
+	desired := []metav1.Condition{
+		{Type: "A", Status: metav1.ConditionFalse},
+		{Type: "C", Status: metav1.ConditionTrue},
+	}
+	// Another writer changed B and added D meanwhile.
+	dst := []metav1.Condition{
+		{Type: "A", Status: metav1.ConditionTrue},
+		{Type: "B", Status: metav1.ConditionFalse},
+		{Type: "D", Status: metav1.ConditionTrue},
+	}
+
+	got := mergeConditionChanges(dst, base, desired)
+	want := map[string]metav1.ConditionStatus{"A": metav1.ConditionFalse, "C": metav1.ConditionTrue, "D": metav1.ConditionTrue}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %v", got, want)
+	}
+	for _, cond := range got {
+		if want[cond.Type] != cond.Status {
+			t.Errorf("condition %s = %s, want %s", cond.Type, cond.Status, want[cond.Type])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// copyTelemetryCollector copies the collector configuration and credentials
+// of the operator's telemetry profile into the user namespace and returns
+// their checksum. Nothing is copied for instances that do not use the
+// collector.
+func (r *KlausInstanceReconciler) copyTelemetryCollector(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (string, error) {
+	collector := r.TelemetryCollector
+	if !resources.UsesTelemetryCollector(instance, collector) {
+		return "", nil
+	}
+
+	srcConfig := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      collector.ConfigMapName,
+		Namespace: r.OperatorNamespace,
+	}, srcConfig); err != nil {
+		return "", fmt.Errorf("fetching collector config %q: %w", collector.ConfigMapName, err)
+	}
+	if _, ok := srcConfig.Data[resources.OTelCollectorConfigKey]; !ok {
+		return "", fmt.Errorf("collector config %q has no %s key", collector.ConfigMapName, resources.OTelCollectorConfigKey)
+	}
+
+	desiredConfig := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      resources.OTelCollectorName,
+		Namespace: namespace,
+	}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, desiredConfig, func() error {
+		desiredConfig.Data = srcConfig.Data
+		desiredConfig.Labels = resources.OTelCollectorLabels(instance.Spec.Owner)
+		return nil
+	}); err != nil {
+		return "", fmt.Errorf("reconciling collector config copy: %w", err)
+	}
+
+	var credentials map[string][]byte
+	if collector.SecretName != "" {
+		srcSecret := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{
+			Name:      collector.SecretName,
+			Namespace: r.OperatorNamespace,
+		}, srcSecret); err != nil {
+			return "", fmt.Errorf("fetching collector secret %q: %w", collector.SecretName, err)
+		}
+		credentials = srcSecret.Data
+
+		desiredSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      resources.OTelCollectorName,
+			Namespace: namespace,
+		}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, desiredSecret, func() error {
+			desiredSecret.Type = corev1.SecretTypeOpaque
+			desiredSecret.Data = srcSecret.Data
+			desiredSecret.Labels = resources.OTelCollectorLabels(instance.Spec.Owner)
+			return nil
+		}); err != nil {
+			return "", fmt.Errorf("reconciling collector secret copy: %w", err)
+		}
+	}
+
+	return resources.OTelCollectorChecksum(srcConfig.Data, credentials), nil
+}
+
+// collectTelemetryCollector deletes the collector configuration and
+// credentials copies of namespace once no Deployment or Job in it mounts
+// them, e.g. after the last instance using the collector disabled telemetry
+// or was deleted. The workload named except, which the caller is changing
+// or deleting, is not counted.
+func collectTelemetryCollector(ctx context.Context, c client.Client, namespace, except string) error {
+	selector := client.MatchingLabels{
+		resources.LabelManagedBy:      resources.AppKlausOperator,
+		"app.kubernetes.io/component": resources.OTelCollectorContainerName,
+	}
+	var configs corev1.ConfigMapList
+	if err := c.List(ctx, &configs, client.InNamespace(namespace), selector); err != nil {
+		return fmt.Errorf("listing collector config copies: %w", err)
+	}
+	var secrets corev1.SecretList
+	if err := c.List(ctx, &secrets, client.InNamespace(namespace), selector); err != nil {
+		return fmt.Errorf("listing collector secret copies: %w", err)
+	}
+	if len(configs.Items) == 0 && len(secrets.Items) == 0 {
+		return nil
+	}
+
+	managed := client.MatchingLabels{resources.LabelManagedBy: resources.AppKlausOperator}
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments, client.InNamespace(namespace), managed); err != nil {
+		return fmt.Errorf("listing deployments: %w", err)
+	}
+	for _, dep := range deployments.Items {
+		if dep.Name != except && dep.DeletionTimestamp.IsZero() && resources.MountsTelemetryCollector(&dep.Spec.Template.Spec) {
+			return nil
+		}
+	}
+	var jobs batchv1.JobList
+	if err := c.List(ctx, &jobs, client.InNamespace(namespace), managed); err != nil {
+		return fmt.Errorf("listing jobs: %w", err)
+	}
+	for _, job := range jobs.Items {
+		if job.Name != except && job.DeletionTimestamp.IsZero() && resources.MountsTelemetryCollector(&job.Spec.Template.Spec) {
+			return nil
+		}
+	}
+
+	objs := make([]client.Object, 0, len(configs.Items)+len(secrets.Items))
+	for i := range configs.Items {
+		objs = append(objs, &configs.Items[i])
+	}
+	for i := range secrets.Items {
+		objs = append(objs, &secrets.Items[i])
+	}
+	for _, obj := range objs {
+		log.FromContext(ctx).Info("deleting unused collector copy", "kind", fmt.Sprintf("%T", obj), "namespace", namespace)
+		if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting collector copy %s: %w", obj.GetName(), err)
+		}
+	}
+	return nil
+}
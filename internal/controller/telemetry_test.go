@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func telemetryInstance(endpoint string) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("test", testOwner)
+	instance.Spec.Telemetry = &klausv1alpha1.TelemetryConfig{
+		Enabled: ptr.To(true),
+		OTLP:    &klausv1alpha1.OTLPConfig{Endpoint: endpoint},
+	}
+	return instance
+}
+
+func TestCopyTelemetryCollector(t *testing.T) {
+	config := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "otel", Namespace: "klaus-system"},
+		Data:       map[string]string{resources.OTelCollectorConfigKey: "receivers: {}"},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "otel-credentials", Namespace: "klaus-system"},
+		Data:       map[string][]byte{"TOKEN": []byte("secret")},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(config, credentials).Build()
+	r := &KlausInstanceReconciler{
+		Client:             c,
+		OperatorNamespace:  "klaus-system",
+		TelemetryCollector: &resources.TelemetryCollector{Image: "otelcol:1", ConfigMapName: "otel", SecretName: "otel-credentials"},
+	}
+	ctx := context.Background()
+	namespace := "klaus-user-user-example-com"
+
+	checksum, err := r.copyTelemetryCollector(ctx, telemetryInstance(""), namespace)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := resources.OTelCollectorChecksum(config.Data, credentials.Data); checksum != want {
+		t.Errorf("checksum = %q, want %q", checksum, want)
+	}
+
+	key := types.NamespacedName{Name: resources.OTelCollectorName, Namespace: namespace}
+	var copiedConfig corev1.ConfigMap
+	if err := c.Get(ctx, key, &copiedConfig); err != nil {
+		t.Fatalf("failed to get copied config: %v", err)
+	}
+	if copiedConfig.Data[resources.OTelCollectorConfigKey] != "receivers: {}" {
+		t.Errorf("config = %v", copiedConfig.Data)
+	}
+	var copiedSecret corev1.Secret
+	if err := c.Get(ctx, key, &copiedSecret); err != nil {
+		t.Fatalf("failed to get copied secret: %v", err)
+	}
+	if string(copiedSecret.Data["TOKEN"]) != "secret" {
+		t.Errorf("secret = %v", copiedSecret.Data)
+	}
+	if copiedSecret.Labels[resources.LabelOwner] == "" {
+		t.Errorf("labels = %v, want owner-scoped labels", copiedSecret.Labels)
+	}
+}
+
+func TestCopyTelemetryCollector_OwnEndpoint(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+	r := &KlausInstanceReconciler{
+		Client:             c,
+		OperatorNamespace:  "klaus-system",
+		TelemetryCollector: &resources.TelemetryCollector{Image: "otelcol:1", ConfigMapName: "otel"},
+	}
+
+	// The collector config is not fetched for instances that export
+	// themselves, so its absence is not an error.
+	checksum, err := r.copyTelemetryCollector(context.Background(), telemetryInstance("http://otel:4318"), "klaus-user-user-example-com")
+	if err != nil || checksum != "" {
+		t.Errorf("copyTelemetryCollector() = %q, %v; want nothing copied", checksum, err)
+	}
+}
+
+func TestCopyTelemetryCollector_MissingConfigKey(t *testing.T) {
+	config := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "otel", Namespace: "klaus-system"},
+		Data:       map[string]string{"collector.yaml": "receivers: {}"},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(config).Build()
+	r := &KlausInstanceReconciler{
+		Client:             c,
+		OperatorNamespace:  "klaus-system",
+		TelemetryCollector: &resources.TelemetryCollector{Image: "otelcol:1", ConfigMapName: "otel"},
+	}
+
+	if _, err := r.copyTelemetryCollector(context.Background(), telemetryInstance(""), "klaus-user-user-example-com"); err == nil {
+		t.Error("expected an error for a config without config.yaml")
+	}
+}
+
+func TestCollectTelemetryCollector(t *testing.T) {
+	namespace := "klaus-user-user-example-com"
+	labels := resources.OTelCollectorLabels("user@example.com")
+	managed := map[string]string{resources.LabelManagedBy: resources.AppKlausOperator}
+	mounting := corev1.PodSpec{Volumes: []corev1.Volume{{
+		Name: "otel-collector-config",
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: resources.OTelCollectorName},
+		}},
+	}}}
+	objects := func() []client.Object {
+		return []client.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: resources.OTelCollectorName, Namespace: namespace, Labels: labels}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.OTelCollectorName, Namespace: namespace, Labels: labels}},
+		}
+	}
+	tests := []struct {
+		name        string
+		workloads   []client.Object
+		except      string
+		wantDeleted bool
+	}{
+		{name: "unused", wantDeleted: true},
+		{
+			name: "mounted by a Deployment",
+			workloads: []client.Object{&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace, Labels: managed},
+				Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: mounting}},
+			}},
+		},
+		{
+			name: "mounted by a Job",
+			workloads: []client.Object{&batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "review-job", Namespace: namespace, Labels: managed},
+				Spec:       batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: mounting}},
+			}},
+		},
+		{
+			name: "mounted by the changed Deployment only",
+			workloads: []client.Object{&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: namespace, Labels: managed},
+				Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: mounting}},
+			}},
+			except:      "my-agent",
+			wantDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(jobTestScheme(t)).
+				WithObjects(append(objects(), tt.workloads...)...).Build()
+			ctx := context.Background()
+			if err := collectTelemetryCollector(ctx, c, namespace, tt.except); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			key := types.NamespacedName{Name: resources.OTelCollectorName, Namespace: namespace}
+			configErr := c.Get(ctx, key, &corev1.ConfigMap{})
+			secretErr := c.Get(ctx, key, &corev1.Secret{})
+			if deleted := apierrors.IsNotFound(configErr) && apierrors.IsNotFound(secretErr); deleted != tt.wantDeleted {
+				t.Errorf("copies deleted = %v (%v, %v), want %v", deleted, configErr, secretErr, tt.wantDeleted)
+			}
+		})
+	}
+}
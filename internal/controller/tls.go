@@ -0,0 +1,306 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/certs"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// Reasons of the TLSReady condition. The success reasons record the issuer
+// so that switching issuers cleans up what the previous one created.
+const (
+	reasonTLSOperatorIssued     = "OperatorIssued"
+	reasonTLSCertManager        = "CertManagerIssued"
+	reasonTLSCertificatePending = "CertificatePending"
+	reasonTLSError              = "ReconcileError"
+)
+
+// certificatePendingRequeue is how often an instance waiting for
+// cert-manager to issue its certificates is checked again.
+const certificatePendingRequeue = 10 * time.Second
+
+// reconcileTLS provisions the serving and client certificate Secrets for an
+// instance served over mTLS, and removes them when TLS is turned off. It
+// returns how long until a certificate needs to be renewed (zero when the
+// operator does not track renewal) so the caller can requeue in time.
+func (r *KlausInstanceReconciler) reconcileTLS(ctx context.Context, instance, merged *klausv1alpha1.KlausInstance, namespace string) (time.Duration, error) {
+	previous := ""
+	if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady); cond != nil {
+		previous = cond.Reason
+	}
+
+	switch resources.TLSIssuer(merged) {
+	case klausv1alpha1.TLSIssuerOperator:
+		if previous == reasonTLSCertManager || previous == reasonTLSCertificatePending {
+			if err := r.deleteCertificates(ctx, merged, namespace); err != nil {
+				return 0, r.tlsError(instance, err)
+			}
+		}
+		renewIn, err := r.issueOperatorCertificates(ctx, merged, namespace)
+		if err != nil {
+			return 0, r.tlsError(instance, err)
+		}
+		setCondition(instance, ConditionTLSReady, metav1.ConditionTrue, reasonTLSOperatorIssued,
+			"Certificates issued by the operator CA")
+		return renewIn, nil
+
+	case klausv1alpha1.TLSIssuerCertManager:
+		for _, cert := range []*unstructured.Unstructured{
+			resources.BuildServingCertificate(merged, namespace),
+			resources.BuildClientCertificate(merged),
+		} {
+			if err := r.reconcileCertificate(ctx, cert); err != nil {
+				return 0, r.tlsError(instance, err)
+			}
+		}
+		ready, err := r.certificateSecretsIssued(ctx, merged, namespace)
+		if err != nil {
+			return 0, r.tlsError(instance, err)
+		}
+		if !ready {
+			setCondition(instance, ConditionTLSReady, metav1.ConditionFalse, reasonTLSCertificatePending,
+				"Waiting for cert-manager to issue the certificates")
+			return certificatePendingRequeue, nil
+		}
+		setCondition(instance, ConditionTLSReady, metav1.ConditionTrue, reasonTLSCertManager,
+			"Certificates issued by ClusterIssuer "+merged.Spec.TLS.ClusterIssuer)
+		return 0, nil
+	}
+
+	if previous == "" {
+		return 0, nil
+	}
+	if err := r.deleteTLSResources(ctx, merged, namespace); err != nil {
+		return 0, r.tlsError(instance, err)
+	}
+	apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionTLSReady)
+	return 0, nil
+}
+
+func (r *KlausInstanceReconciler) tlsError(instance *klausv1alpha1.KlausInstance, err error) error {
+	setCondition(instance, ConditionTLSReady, metav1.ConditionFalse, reasonTLSError, err.Error())
+	return err
+}
+
+// issueOperatorCertificates issues or renews the serving and client
+// certificates from the operator CA and returns the time until the earlier
+// renewal is due.
+func (r *KlausInstanceReconciler) issueOperatorCertificates(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (time.Duration, error) {
+	ca, err := r.ensureOperatorCA(ctx)
+	if err != nil {
+		return 0, err
+	}
+	labels := resources.InstanceLabels(instance)
+	dnsNames := resources.ServiceDNSNames(instance, namespace)
+
+	servingRenew, err := r.ensureIssuedSecret(ctx, ca, resources.ServingCertSecretName(instance), namespace, labels,
+		func(now time.Time) (*certs.KeyPair, error) { return ca.IssueServing(dnsNames, now) })
+	if err != nil {
+		return 0, fmt.Errorf("issuing serving certificate: %w", err)
+	}
+	clientRenew, err := r.ensureIssuedSecret(ctx, ca, resources.ClientCertSecretName(instance), resources.MusterNamespace(instance), labels,
+		func(now time.Time) (*certs.KeyPair, error) {
+			return ca.IssueClient(resources.MusterClientCommonName, now)
+		})
+	if err != nil {
+		return 0, fmt.Errorf("issuing client certificate: %w", err)
+	}
+	renewAt := servingRenew
+	if clientRenew.Before(renewAt) {
+		renewAt = clientRenew
+	}
+	return time.Until(renewAt), nil
+}
+
+// ensureOperatorCA loads the operator CA from its Secret in the operator
+// namespace, generating it on first use.
+func (r *KlausInstanceReconciler) ensureOperatorCA(ctx context.Context) (*certs.CA, error) {
+	key := types.NamespacedName{Name: resources.OperatorCASecretName, Namespace: r.OperatorNamespace}
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, key, secret)
+	if apierrors.IsNotFound(err) {
+		ca, pair, genErr := certs.NewCA(resources.OperatorCASecretName, time.Now())
+		if genErr != nil {
+			return nil, genErr
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{resources.LabelManagedBy: resources.AppKlausOperator},
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: map[string][]byte{certs.CertKey: pair.CertPEM, certs.KeyKey: pair.KeyPEM},
+		}
+		createErr := r.Create(ctx, secret)
+		if createErr == nil {
+			return ca, nil
+		}
+		if !apierrors.IsAlreadyExists(createErr) {
+			return nil, fmt.Errorf("creating operator CA secret: %w", createErr)
+		}
+		// Another reconcile created the CA first; use that one.
+		err = r.Get(ctx, key, secret)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching operator CA secret: %w", err)
+	}
+	ca, err := certs.LoadCA(secret.Data[certs.CertKey], secret.Data[certs.KeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("loading operator CA: %w", err)
+	}
+	return ca, nil
+}
+
+// ensureIssuedSecret keeps a TLS Secret signed by ca, reissuing it when it
+// is missing, signed by another CA or due for renewal. It returns the time
+// the current certificate should be renewed.
+func (r *KlausInstanceReconciler) ensureIssuedSecret(ctx context.Context, ca *certs.CA, name, namespace string, labels map[string]string, issue func(time.Time) (*certs.KeyPair, error)) (time.Time, error) {
+	now := time.Now()
+	existing := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	var renewAt time.Time
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, existing, func() error {
+		existing.Labels = labels
+		if existing.CreationTimestamp.IsZero() {
+			existing.Type = corev1.SecretTypeTLS
+		}
+		current := existing.Data[certs.CertKey]
+		if !certs.NeedsRenewal(current, ca, now) && bytes.Equal(existing.Data[certs.CAKey], ca.CertPEM) {
+			cert, err := certs.ParseCertificate(current)
+			if err != nil {
+				return err
+			}
+			renewAt = certs.RenewAt(cert)
+			return nil
+		}
+		pair, err := issue(now)
+		if err != nil {
+			return err
+		}
+		cert, err := certs.ParseCertificate(pair.CertPEM)
+		if err != nil {
+			return err
+		}
+		renewAt = certs.RenewAt(cert)
+		existing.Data = resources.BuildTLSSecret(name, namespace, labels, pair, ca.CertPEM).Data
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reconciling secret %s/%s: %w", namespace, name, err)
+	}
+	return renewAt, nil
+}
+
+func (r *KlausInstanceReconciler) reconcileCertificate(ctx context.Context, desired *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(resources.CertificateGVK)
+	err := r.Get(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, existing)
+	if apierrors.IsNotFound(err) {
+		if err := r.Create(ctx, desired); err != nil {
+			return fmt.Errorf("creating Certificate %s/%s: %w", desired.GetNamespace(), desired.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching Certificate %s/%s: %w", desired.GetNamespace(), desired.GetName(), err)
+	}
+	existing.Object["spec"] = desired.Object["spec"]
+	existing.SetLabels(desired.GetLabels())
+	if err := r.Update(ctx, existing); err != nil {
+		return fmt.Errorf("updating Certificate %s/%s: %w", desired.GetNamespace(), desired.GetName(), err)
+	}
+	return nil
+}
+
+// certificateSecretsIssued reports whether cert-manager has written both
+// certificate Secrets with a tls.crt and a ca.crt. A certificate that does
+// not chain to the CA next to it is an error: the mTLS peers verify each
+// other against that CA, so it must come from an issuer that publishes it.
+func (r *KlausInstanceReconciler) certificateSecretsIssued(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (bool, error) {
+	for _, key := range []types.NamespacedName{
+		{Name: resources.ServingCertSecretName(instance), Namespace: namespace},
+		{Name: resources.ClientCertSecretName(instance), Namespace: resources.MusterNamespace(instance)},
+	} {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, key, secret)
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("fetching certificate secret %s: %w", key, err)
+		}
+		if len(secret.Data[certs.CertKey]) == 0 || len(secret.Data[certs.CAKey]) == 0 {
+			return false, nil
+		}
+		if err := certs.VerifyChain(secret.Data[certs.CertKey], secret.Data[certs.CAKey], time.Now()); err != nil {
+			return false, fmt.Errorf("certificate secret %s: %w", key, err)
+		}
+	}
+	return true, nil
+}
+
+// deleteCertificates removes the cert-manager Certificates of an instance.
+// A cluster without cert-manager cannot have any, so a missing kind is not
+// an error.
+func (r *KlausInstanceReconciler) deleteCertificates(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	for _, key := range []types.NamespacedName{
+		{Name: resources.ServingCertSecretName(instance), Namespace: namespace},
+		{Name: resources.ClientCertSecretName(instance), Namespace: resources.MusterNamespace(instance)},
+	} {
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(resources.CertificateGVK)
+		cert.SetName(key.Name)
+		cert.SetNamespace(key.Namespace)
+		if err := r.Delete(ctx, cert); err != nil && !apierrors.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+			return fmt.Errorf("deleting Certificate %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// deleteTLSResources removes the certificate Secrets and Certificates of an
+// instance.
+func (r *KlausInstanceReconciler) deleteTLSResources(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) error {
+	if err := r.deleteCertificates(ctx, instance, namespace); err != nil {
+		return err
+	}
+	for _, secret := range []client.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.ServingCertSecretName(instance), Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.ClientCertSecretName(instance), Namespace: resources.MusterNamespace(instance)}},
+	} {
+		if err := r.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("deleting secret %s/%s: %w", secret.GetNamespace(), secret.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// instanceClientTLSConfig returns the TLS configuration the operator uses
+// to call an instance served over mTLS. It returns (nil, nil) for plain HTTP
+// instances.
+func (r *KlausInstanceReconciler) instanceClientTLSConfig(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (*tls.Config, error) {
+	if !resources.TLSEnabled(instance) {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: resources.ClientCertSecretName(instance), Namespace: resources.MusterNamespace(instance)}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("fetching client certificate secret %s: %w", key, err)
+	}
+	return resources.ClientTLSConfig(instance, namespace, secret)
+}
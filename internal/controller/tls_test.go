@@ -0,0 +1,323 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/certs"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const tlsTestNamespace = "klaus-user-user-example-com"
+
+func tlsTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := testScheme(t)
+	scheme.AddKnownTypeWithName(resources.CertificateGVK, &unstructured.Unstructured{})
+	listGVK := resources.CertificateGVK
+	listGVK.Kind += "List"
+	scheme.AddKnownTypeWithName(listGVK, &unstructured.UnstructuredList{})
+	return scheme
+}
+
+func tlsTestReconciler(t *testing.T) *KlausInstanceReconciler {
+	t.Helper()
+	return testReconciler(t, tlsTestScheme(t))
+}
+
+func tlsTestInstance(tlsConfig *klausv1alpha1.InstanceTLSConfig) *klausv1alpha1.KlausInstance {
+	instance := testInstance()
+	instance.Spec.TLS = tlsConfig
+	return instance
+}
+
+func getTLSSecret(t *testing.T, r *KlausInstanceReconciler, name, namespace string) (*corev1.Secret, error) {
+	t.Helper()
+	secret := &corev1.Secret{}
+	err := r.Get(context.Background(), types.NamespacedName{Name: name, Namespace: namespace}, secret)
+	return secret, err
+}
+
+func TestReconcileTLS_OperatorIssuer(t *testing.T) {
+	r := tlsTestReconciler(t)
+	instance := tlsTestInstance(&klausv1alpha1.InstanceTLSConfig{})
+
+	renewIn, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+	if renewIn <= 0 || renewIn > certs.LeafValidity {
+		t.Errorf("renewIn = %v, want within the leaf validity", renewIn)
+	}
+
+	caSecret, err := getTLSSecret(t, r, resources.OperatorCASecretName, "klaus-system")
+	if err != nil {
+		t.Fatalf("fetching CA secret: %v", err)
+	}
+	ca, err := certs.LoadCA(caSecret.Data[certs.CertKey], caSecret.Data[certs.KeyKey])
+	if err != nil {
+		t.Fatalf("loading CA: %v", err)
+	}
+
+	serving, err := getTLSSecret(t, r, resources.ServingCertSecretName(instance), tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("fetching serving secret: %v", err)
+	}
+	if serving.Type != corev1.SecretTypeTLS {
+		t.Errorf("serving secret type = %q, want %q", serving.Type, corev1.SecretTypeTLS)
+	}
+	if string(serving.Data[certs.CAKey]) != string(ca.CertPEM) {
+		t.Error("serving secret ca.crt should hold the operator CA")
+	}
+	if serving.Labels["app.kubernetes.io/instance"] != "my-agent" {
+		t.Errorf("serving secret labels = %v, want instance label", serving.Labels)
+	}
+
+	client, err := getTLSSecret(t, r, resources.ClientCertSecretName(instance), resources.MusterNamespace(instance))
+	if err != nil {
+		t.Fatalf("fetching client secret: %v", err)
+	}
+	if certs.NeedsRenewal(client.Data[certs.CertKey], ca, metav1.Now().Time) {
+		t.Error("client certificate should be signed by the operator CA and fresh")
+	}
+
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != reasonTLSOperatorIssued {
+		t.Errorf("TLSReady condition = %+v, want True/%s", cond, reasonTLSOperatorIssued)
+	}
+
+	cfg, err := r.instanceClientTLSConfig(context.Background(), instance, tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("instanceClientTLSConfig() error = %v", err)
+	}
+	if cfg.ServerName != resources.ServiceHost(instance, tlsTestNamespace) {
+		t.Errorf("ServerName = %q, want %q", cfg.ServerName, resources.ServiceHost(instance, tlsTestNamespace))
+	}
+}
+
+func TestReconcileTLS_OperatorIssuerReusesCertificates(t *testing.T) {
+	r := tlsTestReconciler(t)
+	instance := tlsTestInstance(&klausv1alpha1.InstanceTLSConfig{})
+
+	if _, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace); err != nil {
+		t.Fatalf("first reconcileTLS() error = %v", err)
+	}
+	first, err := getTLSSecret(t, r, resources.ServingCertSecretName(instance), tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("fetching serving secret: %v", err)
+	}
+	if _, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace); err != nil {
+		t.Fatalf("second reconcileTLS() error = %v", err)
+	}
+	second, err := getTLSSecret(t, r, resources.ServingCertSecretName(instance), tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("fetching serving secret: %v", err)
+	}
+	if string(first.Data[certs.CertKey]) != string(second.Data[certs.CertKey]) {
+		t.Error("serving certificate was reissued although it is still valid")
+	}
+}
+
+// writeCertManagerSecrets writes the certificate Secrets of an instance the
+// way cert-manager does, with certificates issued by ca and caPEM as ca.crt.
+func writeCertManagerSecrets(t *testing.T, r *KlausInstanceReconciler, instance *klausv1alpha1.KlausInstance, ca *certs.CA, caPEM []byte) {
+	t.Helper()
+	pair, err := ca.IssueServing([]string{"agent"}, time.Now())
+	if err != nil {
+		t.Fatalf("IssueServing() error = %v", err)
+	}
+	for _, key := range []types.NamespacedName{
+		{Name: resources.ServingCertSecretName(instance), Namespace: tlsTestNamespace},
+		{Name: resources.ClientCertSecretName(instance), Namespace: resources.MusterNamespace(instance)},
+	} {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{certs.CertKey: pair.CertPEM, certs.KeyKey: pair.KeyPEM},
+		}
+		if caPEM != nil {
+			secret.Data[certs.CAKey] = caPEM
+		}
+		err := r.Create(context.Background(), secret)
+		if apierrors.IsAlreadyExists(err) {
+			err = r.Update(context.Background(), secret)
+		}
+		if err != nil {
+			t.Fatalf("writing secret %s: %v", key, err)
+		}
+	}
+}
+
+func TestReconcileTLS_CertManagerIssuer(t *testing.T) {
+	r := tlsTestReconciler(t)
+	instance := tlsTestInstance(&klausv1alpha1.InstanceTLSConfig{
+		Issuer:        klausv1alpha1.TLSIssuerCertManager,
+		ClusterIssuer: "internal-ca",
+	})
+
+	requeue, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+	if requeue != certificatePendingRequeue {
+		t.Errorf("requeue = %v, want %v while pending", requeue, certificatePendingRequeue)
+	}
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != reasonTLSCertificatePending {
+		t.Fatalf("TLSReady condition = %+v, want False/%s", cond, reasonTLSCertificatePending)
+	}
+
+	for _, key := range []types.NamespacedName{
+		{Name: resources.ServingCertSecretName(instance), Namespace: tlsTestNamespace},
+		{Name: resources.ClientCertSecretName(instance), Namespace: resources.MusterNamespace(instance)},
+	} {
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(resources.CertificateGVK)
+		if err := r.Get(context.Background(), key, cert); err != nil {
+			t.Fatalf("fetching Certificate %s: %v", key, err)
+		}
+		issuer, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "name")
+		if issuer != "internal-ca" {
+			t.Errorf("Certificate %s issuerRef.name = %q, want internal-ca", key, issuer)
+		}
+	}
+
+	// Simulate cert-manager writing the Secrets, first without a ca.crt and
+	// then with a ca.crt of another CA.
+	ca, caPair, err := certs.NewCA("internal-ca", time.Now())
+	if err != nil {
+		t.Fatalf("NewCA() error = %v", err)
+	}
+	_, otherPair, err := certs.NewCA("other-ca", time.Now())
+	if err != nil {
+		t.Fatalf("NewCA() error = %v", err)
+	}
+	writeCertManagerSecrets(t, r, instance, ca, nil)
+	requeue, err = r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+	cond = apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady)
+	if requeue != certificatePendingRequeue || cond == nil || cond.Reason != reasonTLSCertificatePending {
+		t.Fatalf("requeue = %v, TLSReady condition = %+v, want pending without a ca.crt", requeue, cond)
+	}
+
+	writeCertManagerSecrets(t, r, instance, ca, otherPair.CertPEM)
+	if _, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace); err == nil {
+		t.Fatal("expected error for a certificate not signed by the ca.crt")
+	}
+	cond = apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady)
+	if cond == nil || cond.Reason != reasonTLSError {
+		t.Fatalf("TLSReady condition = %+v, want False/%s", cond, reasonTLSError)
+	}
+
+	writeCertManagerSecrets(t, r, instance, ca, caPair.CertPEM)
+	requeue, err = r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+	if requeue != 0 {
+		t.Errorf("requeue = %v, want 0 once issued", requeue)
+	}
+	cond = apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != reasonTLSCertManager {
+		t.Errorf("TLSReady condition = %+v, want True/%s", cond, reasonTLSCertManager)
+	}
+}
+
+func TestReconcileTLS_SwitchToOperatorDeletesCertificates(t *testing.T) {
+	r := tlsTestReconciler(t)
+	instance := tlsTestInstance(&klausv1alpha1.InstanceTLSConfig{
+		Issuer:        klausv1alpha1.TLSIssuerCertManager,
+		ClusterIssuer: "internal-ca",
+	})
+	if _, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace); err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+
+	instance.Spec.TLS = &klausv1alpha1.InstanceTLSConfig{Issuer: klausv1alpha1.TLSIssuerOperator}
+	if _, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace); err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(resources.CertificateGVK)
+	err := r.Get(context.Background(), types.NamespacedName{Name: resources.ServingCertSecretName(instance), Namespace: tlsTestNamespace}, cert)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected serving Certificate to be deleted, got err = %v", err)
+	}
+	if _, err := getTLSSecret(t, r, resources.ServingCertSecretName(instance), tlsTestNamespace); err != nil {
+		t.Errorf("expected operator-issued serving secret, got err = %v", err)
+	}
+}
+
+func TestReconcileTLS_DisabledCleansUp(t *testing.T) {
+	r := tlsTestReconciler(t)
+	instance := tlsTestInstance(&klausv1alpha1.InstanceTLSConfig{})
+	if _, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace); err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+
+	instance.Spec.TLS = nil
+	renewIn, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace)
+	if err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+	if renewIn != 0 {
+		t.Errorf("renewIn = %v, want 0 without TLS", renewIn)
+	}
+	if _, err := getTLSSecret(t, r, resources.ServingCertSecretName(instance), tlsTestNamespace); !apierrors.IsNotFound(err) {
+		t.Errorf("expected serving secret to be deleted, got err = %v", err)
+	}
+	if _, err := getTLSSecret(t, r, resources.ClientCertSecretName(instance), resources.MusterNamespace(instance)); !apierrors.IsNotFound(err) {
+		t.Errorf("expected client secret to be deleted, got err = %v", err)
+	}
+	if apimeta.FindStatusCondition(instance.Status.Conditions, ConditionTLSReady) != nil {
+		t.Error("expected TLSReady condition to be removed")
+	}
+}
+
+func TestReconcileTLS_NotEnabledIsNoop(t *testing.T) {
+	r := tlsTestReconciler(t)
+	instance := tlsTestInstance(nil)
+
+	if _, err := r.reconcileTLS(context.Background(), instance, instance, tlsTestNamespace); err != nil {
+		t.Fatalf("reconcileTLS() error = %v", err)
+	}
+	if _, err := getTLSSecret(t, r, resources.OperatorCASecretName, "klaus-system"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no operator CA without spec.tls, got err = %v", err)
+	}
+}
+
+func TestRequeueBefore(t *testing.T) {
+	tests := []struct {
+		name   string
+		after  time.Duration
+		d      time.Duration
+		expect time.Duration
+	}{
+		{name: "no renewal", after: 30 * time.Second, d: 0, expect: 30 * time.Second},
+		{name: "renewal earlier", after: 30 * time.Second, d: 10 * time.Second, expect: 10 * time.Second},
+		{name: "renewal later", after: 30 * time.Second, d: time.Minute, expect: 30 * time.Second},
+		{name: "no requeue", after: 0, d: time.Minute, expect: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := requeueBefore(tt.d)(ctrl.Result{RequeueAfter: tt.after}, nil)
+			if result.RequeueAfter != tt.expect {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, tt.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+// DefaultTokenUsageInterval is how often status.tokenUsage of the instances
+// and the KlausUsageReports are refreshed unless configured otherwise.
+const DefaultTokenUsageInterval = time.Minute
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaususagereports,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaususagereports/status,verbs=get;update;patch
+
+// TokenUsageReporter is a leader-elected manager runnable that periodically
+// records the token usage and cost of every instance in its
+// status.tokenUsage and aggregates them per owner into KlausUsageReports.
+// The KlausInstance reconciler suspends instances whose recorded cost
+// reached their budget.
+type TokenUsageReporter struct {
+	Client client.Client
+
+	// Collector reads the token usage of an instance.
+	Collector *usage.TokenCollector
+
+	// Namespace is the namespace the KlausUsageReports are maintained in.
+	Namespace string
+
+	// Interval is the refresh interval.
+	Interval time.Duration
+}
+
+// NeedLeaderElection ensures only the leader writes instance status and
+// the reports.
+func (r *TokenUsageReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs Refresh every Interval; see periodic.
+func (r *TokenUsageReporter) Start(ctx context.Context) error {
+	return periodic{
+		name:            "token-usage",
+		interval:        r.Interval,
+		defaultInterval: DefaultTokenUsageInterval,
+		action:          "refreshing token usage",
+		fn:              r.Refresh,
+	}.Start(ctx)
+}
+
+// Refresh collects the token usage of all instances, patches it into their
+// status and rewrites the usage report of every owner. Reports of owners
+// without instances are deleted.
+func (r *TokenUsageReporter) Refresh(ctx context.Context) error {
+	var instances klausv1alpha1.KlausInstanceList
+	if err := r.Client.List(ctx, &instances); err != nil {
+		return fmt.Errorf("listing KlausInstances: %w", err)
+	}
+
+	var errs []error
+	byOwner := map[string][]*klausv1alpha1.KlausInstance{}
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if !instance.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.refreshInstance(ctx, instance); err != nil {
+			errs = append(errs, fmt.Errorf("instance %s: %w", instance.Name, err))
+		}
+		byOwner[instance.Spec.Owner] = append(byOwner[instance.Spec.Owner], instance)
+	}
+
+	reports := map[string]bool{}
+	for owner, owned := range byOwner {
+		name := resources.UserNamespace(owner)
+		reports[name] = true
+		if err := r.writeReport(ctx, name, buildUsageReport(owner, owned)); err != nil {
+			errs = append(errs, fmt.Errorf("usage report %s: %w", name, err))
+		}
+	}
+	if err := r.deleteStaleReports(ctx, reports); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// refreshInstance records the current token usage of one instance. The
+// recorded usage never decreases, so an instance cannot escape its budget
+// when older telemetry ages out of Prometheus.
+func (r *TokenUsageReporter) refreshInstance(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	current, err := r.Collector.Collect(ctx, instance)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return nil
+	}
+
+	base := instance.DeepCopy()
+	instance.Status.TokenUsage = usage.MaxTokenUsage(instance.Status.TokenUsage, current)
+	if err := r.Client.Status().Patch(ctx, instance, client.MergeFrom(base)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("patching status: %w", err)
+	}
+	return nil
+}
+
+// buildUsageReport aggregates the recorded token usage of the instances of
+// an owner.
+func buildUsageReport(owner string, instances []*klausv1alpha1.KlausInstance) klausv1alpha1.KlausUsageReportStatus {
+	status := klausv1alpha1.KlausUsageReportStatus{Owner: owner}
+	total := &klausv1alpha1.TokenUsage{CostUSD: usage.FormatUSD(0)}
+	for _, instance := range instances {
+		entry := klausv1alpha1.InstanceTokenUsage{
+			Name:      instance.Name,
+			Suspended: usage.BudgetExceeded(instance),
+		}
+		if budget := instance.Spec.Claude.MaxBudgetUSD; budget != nil && *budget > 0 {
+			entry.BudgetUSD = strconv.FormatFloat(*budget, 'f', -1, 64)
+		}
+		if instance.Status.TokenUsage != nil {
+			entry.TokenUsage = *instance.Status.TokenUsage
+			usage.AddTokenUsage(total, instance.Status.TokenUsage)
+		}
+		status.Instances = append(status.Instances, entry)
+	}
+	sort.Slice(status.Instances, func(i, j int) bool {
+		return status.Instances[i].Name < status.Instances[j].Name
+	})
+	total.ObservedAt = metav1.Now()
+	status.Total = total
+	return status
+}
+
+// writeReport creates the report if needed and replaces its status.
+func (r *TokenUsageReporter) writeReport(ctx context.Context, name string, status klausv1alpha1.KlausUsageReportStatus) error {
+	report := &klausv1alpha1.KlausUsageReport{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: r.Namespace}, report)
+	if apierrors.IsNotFound(err) {
+		report = &klausv1alpha1.KlausUsageReport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.Namespace,
+				Labels:    map[string]string{resources.LabelManagedBy: resources.AppKlausOperator},
+			},
+		}
+		err = r.Client.Create(ctx, report)
+	}
+	if err != nil {
+		return fmt.Errorf("ensuring KlausUsageReport: %w", err)
+	}
+
+	report.Status = status
+	if err := patchObjectStatus(ctx, r.Client, nil, report); err != nil {
+		return fmt.Errorf("updating KlausUsageReport status: %w", err)
+	}
+	return nil
+}
+
+// deleteStaleReports deletes the reports not in keep.
+func (r *TokenUsageReporter) deleteStaleReports(ctx context.Context, keep map[string]bool) error {
+	var reports klausv1alpha1.KlausUsageReportList
+	if err := r.Client.List(ctx, &reports, client.InNamespace(r.Namespace)); err != nil {
+		return fmt.Errorf("listing KlausUsageReports: %w", err)
+	}
+	var errs []error
+	for i := range reports.Items {
+		if keep[reports.Items[i].Name] {
+			continue
+		}
+		if err := r.Client.Delete(ctx, &reports.Items[i]); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("deleting KlausUsageReport %s: %w", reports.Items[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+// costPrometheus reports the given cost for the cost query of each
+// instance and no token series.
+func costPrometheus(t *testing.T, costs map[string]string) *usage.PrometheusClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		body := `{"status":"success","data":{"resultType":"vector","result":[]}}`
+		for name, cost := range costs {
+			if strings.Contains(query, "claude_code_cost_usage_USD_total") && strings.Contains(query, `klaus_instance="`+name+`"`) {
+				body = `{"status":"success","data":{"resultType":"vector","result":[{"value":[1700000000,"` + cost + `"]}]}}`
+			}
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return &usage.PrometheusClient{URL: srv.URL}
+}
+
+func TestTokenUsageReporter_Refresh(t *testing.T) {
+	instance := func(name, owner string, budget *float64) *klausv1alpha1.KlausInstance {
+		return &klausv1alpha1.KlausInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system", CreationTimestamp: metav1.Now()},
+			Spec: klausv1alpha1.KlausInstanceSpec{
+				Owner:  owner,
+				Claude: klausv1alpha1.ClaudeConfig{MaxBudgetUSD: budget},
+			},
+		}
+	}
+	alice := instance("alice-dev", "alice@example.com", ptr.To(2.0))
+	// Usage recorded earlier must not decrease when older series are gone.
+	aliceReview := instance("alice-review", "alice@example.com", nil)
+	aliceReview.Status.TokenUsage = &klausv1alpha1.TokenUsage{CostUSD: "0.75", InputTokens: 100}
+	bob := instance("bob-dev", "bob@example.com", nil)
+	stale := &klausv1alpha1.KlausUsageReport{
+		ObjectMeta: metav1.ObjectMeta{Name: "klaus-user-carol-example-com", Namespace: "klaus-system"},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(alice, aliceReview, bob, stale).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}, &klausv1alpha1.KlausUsageReport{}).
+		Build()
+	reporter := &TokenUsageReporter{
+		Client: c,
+		Collector: &usage.TokenCollector{Prometheus: costPrometheus(t, map[string]string{
+			"alice-dev":    "2.5",
+			"alice-review": "0.5",
+		})},
+		Namespace: "klaus-system",
+	}
+
+	ctx := context.Background()
+	if err := reporter.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	var got klausv1alpha1.KlausInstance
+	if err := c.Get(ctx, types.NamespacedName{Name: "alice-dev", Namespace: "klaus-system"}, &got); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	if got.Status.TokenUsage == nil || got.Status.TokenUsage.CostUSD != "2.5" {
+		t.Errorf("alice-dev tokenUsage = %+v, want a cost of 2.5", got.Status.TokenUsage)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "alice-review", Namespace: "klaus-system"}, &got); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	if got.Status.TokenUsage.CostUSD != "0.75" || got.Status.TokenUsage.InputTokens != 100 {
+		t.Errorf("alice-review tokenUsage = %+v, want the previous usage kept", got.Status.TokenUsage)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "bob-dev", Namespace: "klaus-system"}, &got); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	if got.Status.TokenUsage != nil {
+		t.Errorf("bob-dev tokenUsage = %+v, want none without telemetry", got.Status.TokenUsage)
+	}
+
+	var report klausv1alpha1.KlausUsageReport
+	if err := c.Get(ctx, types.NamespacedName{Name: "klaus-user-alice-example-com", Namespace: "klaus-system"}, &report); err != nil {
+		t.Fatalf("failed to get usage report: %v", err)
+	}
+	if report.Status.Owner != "alice@example.com" || report.Status.Total == nil || report.Status.Total.CostUSD != "3.25" {
+		t.Errorf("report status = %+v", report.Status)
+	}
+	if len(report.Status.Instances) != 2 || report.Status.Instances[0].Name != "alice-dev" {
+		t.Fatalf("report instances = %+v, want both instances sorted by name", report.Status.Instances)
+	}
+	if dev := report.Status.Instances[0]; !dev.Suspended || dev.BudgetUSD != "2" {
+		t.Errorf("alice-dev entry = %+v, want it suspended with a budget of 2", dev)
+	}
+
+	if err := c.Get(ctx, types.NamespacedName{Name: "klaus-user-bob-example-com", Namespace: "klaus-system"}, &report); err != nil {
+		t.Errorf("expected a report for bob: %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: stale.Name, Namespace: "klaus-system"}, &report); err == nil {
+		t.Error("expected the report of an owner without instances to be deleted")
+	}
+}
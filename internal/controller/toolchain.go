@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const (
+	// ReasonToolchainNotFound is the reason of the Ready condition of
+	// instances whose spec.toolchainRef names no KlausToolchain.
+	ReasonToolchainNotFound = "ToolchainNotFound"
+
+	// ReasonToolchainPersonalityMismatch is the reason of the Ready
+	// condition of instances referencing a KlausToolchain whose
+	// spec.personalities does not list their personality.
+	ReasonToolchainPersonalityMismatch = "ToolchainPersonalityMismatch"
+)
+
+// resolveToolchainRef sets the image of the merged spec to that of the
+// KlausToolchain named by spec.toolchainRef, once the personality of the
+// instance is checked against the personalities of the toolchain. The image
+// is then resolved and checked against the registry policy like spec.image.
+// Errors are *reasonError.
+func (r *KlausInstanceReconciler) resolveToolchainRef(ctx context.Context, merged *klausv1alpha1.KlausInstance) error {
+	name := merged.Spec.ToolchainRef
+	if name == "" {
+		return nil
+	}
+	var toolchain klausv1alpha1.KlausToolchain
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: r.OperatorNamespace}, &toolchain); err != nil {
+		err = fmt.Errorf("resolving toolchain %q: %w", name, err)
+		if apierrors.IsNotFound(err) {
+			return &reasonError{reason: ReasonToolchainNotFound, err: err}
+		}
+		return &reasonError{reason: "ToolchainError", err: err}
+	}
+	if err := resources.ValidateToolchainPersonality(&toolchain, merged); err != nil {
+		return &reasonError{reason: ReasonToolchainPersonalityMismatch, err: err}
+	}
+	merged.Spec.Image = toolchain.Spec.Image
+	return nil
+}
+
+// EnqueueToolchainInstances returns a map function that enqueues the
+// KlausInstances referencing a KlausToolchain, so they pick up a changed
+// image.
+func EnqueueToolchainInstances(c client.Client, operatorNamespace string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		var instanceList klausv1alpha1.KlausInstanceList
+		if err := c.List(ctx, &instanceList, client.InNamespace(operatorNamespace)); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, inst := range instanceList.Items {
+			if inst.Spec.ToolchainRef != obj.GetName() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: inst.Name, Namespace: inst.Namespace},
+			})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testToolchain(name, image string) *klausv1alpha1.KlausToolchain {
+	return &klausv1alpha1.KlausToolchain{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausToolchainSpec{Image: image, Language: "go"},
+	}
+}
+
+func TestResolveToolchainRef(t *testing.T) {
+	r := testReconciler(t, testScheme(t), testToolchain("go-1.25", "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.25.0"))
+	instance := namedTestInstance("agent", "user@example.com")
+
+	if err := r.resolveToolchainRef(context.Background(), instance); err != nil || instance.Spec.Image != "" {
+		t.Errorf("image = %q, err = %v; want nothing resolved without a reference", instance.Spec.Image, err)
+	}
+
+	instance.Spec.ToolchainRef = "go-1.25"
+	if err := r.resolveToolchainRef(context.Background(), instance); err != nil {
+		t.Fatalf("resolveToolchainRef: %v", err)
+	}
+	if instance.Spec.Image != "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.25.0" {
+		t.Errorf("image = %q, want the toolchain image", instance.Spec.Image)
+	}
+
+	instance.Spec.ToolchainRef = "rust"
+	if err := r.resolveToolchainRef(context.Background(), instance); !apierrors.IsNotFound(err) {
+		t.Errorf("error = %v, want not found", err)
+	}
+}
+
+func TestResolveToolchainRef_Personalities(t *testing.T) {
+	toolchain := testToolchain("go-1.25", "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.25.0")
+	toolchain.Spec.Personalities = []string{"go-dev"}
+	r := testReconciler(t, testScheme(t), toolchain)
+	instance := namedTestInstance("agent", "user@example.com")
+	instance.Spec.ToolchainRef = "go-1.25"
+
+	instance.Spec.Personality = "gsoci.azurecr.io/giantswarm/klaus-personalities/go-dev:v1.0.0"
+	if err := r.resolveToolchainRef(context.Background(), instance); err != nil {
+		t.Fatalf("resolveToolchainRef: %v", err)
+	}
+
+	instance.Spec.Image = ""
+	instance.Spec.Personality = "gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v1.0.0"
+	err := r.resolveToolchainRef(context.Background(), instance)
+	var rerr *reasonError
+	if !errors.As(err, &rerr) || rerr.Reason() != ReasonToolchainPersonalityMismatch {
+		t.Errorf("error = %v, want %s", err, ReasonToolchainPersonalityMismatch)
+	}
+	if instance.Spec.Image != "" {
+		t.Errorf("image = %q, want none for a mismatched personality", instance.Spec.Image)
+	}
+}
+
+func TestEnqueueToolchainInstances(t *testing.T) {
+	referencing := namedTestInstance("agent", "user@example.com")
+	referencing.Spec.ToolchainRef = "go-1.25"
+	other := namedTestInstance("other", "user@example.com")
+	r := testReconciler(t, testScheme(t), referencing, other)
+
+	requests := EnqueueToolchainInstances(r.Client, "klaus-system")(context.Background(), testToolchain("go-1.25", "golang:1.25"))
+	if len(requests) != 1 || requests[0].Name != "agent" {
+		t.Errorf("requests = %v, want only the referencing instance", requests)
+	}
+}
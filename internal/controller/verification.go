@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+)
+
+// artifactVerifyTimeout bounds the registry round trips verifying the
+// artifacts of one instance.
+const artifactVerifyTimeout = 30 * time.Second
+
+// ReasonArtifactVerificationFailed is the reason of the Ready and
+// ArtifactVerificationFailed conditions of instances with an artifact whose
+// signature could not be verified.
+const ReasonArtifactVerificationFailed = "ArtifactVerificationFailed"
+
+// ArtifactVerifier verifies the signatures of OCI artifacts before they are
+// mounted.
+type ArtifactVerifier interface {
+	// Verify returns the manifest digest of the artifact at ref once its
+	// signature is verified, or "" when ref does not need to be signed.
+	Verify(ctx context.Context, ref string) (string, error)
+}
+
+// verifyOCIReferences verifies the signatures of the resolved personality,
+// toolchain image and plugins of instance and pins them to the verified
+// digests, so the pod mounts exactly the artifacts that were verified. It
+// returns the number of verified artifacts.
+func (r *KlausInstanceReconciler) verifyOCIReferences(ctx context.Context, instance *klausv1alpha1.KlausInstance) (int, error) {
+	if r.ArtifactVerifier == nil {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, artifactVerifyTimeout)
+	defer cancel()
+
+	verified := 0
+	verify := func(kind, ref string) (string, error) {
+		digest, err := r.verifyArtifact(ctx, kind, ref)
+		if digest != "" {
+			verified++
+		}
+		return digest, err
+	}
+
+	if instance.Spec.Personality != "" {
+		digest, err := verify(metrics.OCIKindPersonality, instance.Spec.Personality)
+		if err != nil {
+			return 0, err
+		}
+		instance.Spec.Personality = pinDigest(instance.Spec.Personality, digest)
+	}
+	if instance.Spec.Image != "" {
+		digest, err := verify(metrics.OCIKindToolchain, instance.Spec.Image)
+		if err != nil {
+			return 0, err
+		}
+		instance.Spec.Image = pinDigest(instance.Spec.Image, digest)
+	}
+	for i, p := range instance.Spec.Plugins {
+		ref := klausoci.PluginReference{
+			Repository: p.Repository,
+			Tag:        p.Tag,
+			Digest:     p.Digest,
+		}.Ref()
+		digest, err := verify(metrics.OCIKindPlugin, ref)
+		if err != nil {
+			return 0, err
+		}
+		if digest != "" {
+			instance.Spec.Plugins[i].Tag = ""
+			instance.Spec.Plugins[i].Digest = digest
+		}
+	}
+	return verified, nil
+}
+
+// verifyMCPServerArtifacts verifies the signatures of the OCI artifacts of
+// stdio MCP servers, keyed by server name, and pins them to the verified
+// digests like verifyOCIReferences does for plugins. It returns the number
+// of verified artifacts.
+func (r *KlausInstanceReconciler) verifyMCPServerArtifacts(ctx context.Context, artifacts map[string]string) (int, error) {
+	if r.ArtifactVerifier == nil || len(artifacts) == 0 {
+		return 0, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, artifactVerifyTimeout)
+	defer cancel()
+
+	verified := 0
+	for _, name := range slices.Sorted(maps.Keys(artifacts)) {
+		digest, err := r.verifyArtifact(ctx, metrics.OCIKindMCPServer, artifacts[name])
+		if err != nil {
+			return 0, fmt.Errorf("MCP server %q: %w", name, err)
+		}
+		if digest != "" {
+			artifacts[name] = pinDigest(artifacts[name], digest)
+			verified++
+		}
+	}
+	return verified, nil
+}
+
+// verifyArtifact verifies the signature of the artifact of kind at ref and
+// returns its digest, "" when ref does not need to be signed.
+func (r *KlausInstanceReconciler) verifyArtifact(ctx context.Context, kind, ref string) (string, error) {
+	digest, err := r.ArtifactVerifier.Verify(ctx, ref)
+	if err != nil {
+		metrics.ArtifactVerifications.WithLabelValues(kind, "failed").Inc()
+		return "", fmt.Errorf("verifying the signature of %s %q: %w", kind, ref, err)
+	}
+	if digest != "" {
+		metrics.ArtifactVerifications.WithLabelValues(kind, "verified").Inc()
+	}
+	return digest, nil
+}
+
+// pinDigest returns ref pinned to digest, keeping its tag for readability.
+// ref is returned as is when digest is empty.
+func pinDigest(ref, digest string) string {
+	if digest == "" {
+		return ref
+	}
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	return ref + "@" + digest
+}
+
+// setArtifactVerificationCondition records the outcome of
+// verifyOCIReferences. The condition is removed from instances none of
+// whose artifacts are subject to verification.
+func setArtifactVerificationCondition(instance *klausv1alpha1.KlausInstance, verified int, err error) {
+	switch {
+	case err != nil:
+		setCondition(instance, ConditionArtifactVerificationFailed, metav1.ConditionTrue, ReasonArtifactVerificationFailed, err.Error())
+	case verified > 0:
+		setCondition(instance, ConditionArtifactVerificationFailed, metav1.ConditionFalse, "Verified",
+			fmt.Sprintf("Verified the signatures of %d artifacts", verified))
+	default:
+		apimeta.RemoveStatusCondition(&instance.Status.Conditions, ConditionArtifactVerificationFailed)
+	}
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	digestPersonality = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	digestPlugin      = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// fakeArtifactVerifier verifies the references in digests and fails those in
+// failures; other references are not covered.
+type fakeArtifactVerifier struct {
+	digests  map[string]string
+	failures map[string]error
+	refs     []string
+}
+
+func (v *fakeArtifactVerifier) Verify(_ context.Context, ref string) (string, error) {
+	v.refs = append(v.refs, ref)
+	if err := v.failures[ref]; err != nil {
+		return "", err
+	}
+	return v.digests[ref], nil
+}
+
+func verificationInstance() *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance("agent", testOwner)
+	instance.Spec.Personality = "gsoci.azurecr.io/giantswarm/klaus-personalities/go:v1.2.0"
+	instance.Spec.Image = "docker.io/library/golang:1.26"
+	instance.Spec.Plugins = []klausv1alpha1.PluginReference{
+		{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", Tag: "v1.0.0"},
+	}
+	return instance
+}
+
+func TestVerifyOCIReferences_PinsVerifiedDigests(t *testing.T) {
+	verifier := &fakeArtifactVerifier{digests: map[string]string{
+		"gsoci.azurecr.io/giantswarm/klaus-personalities/go:v1.2.0": digestPersonality,
+		"gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v1.0.0":  digestPlugin,
+	}}
+	r := &KlausInstanceReconciler{ArtifactVerifier: verifier}
+	instance := verificationInstance()
+
+	verified, err := r.verifyOCIReferences(context.Background(), instance)
+	if err != nil {
+		t.Fatalf("verifyOCIReferences: %v", err)
+	}
+	if verified != 2 || len(verifier.refs) != 3 {
+		t.Errorf("verified = %d of %v, want the personality and plugin verified", verified, verifier.refs)
+	}
+	if want := "gsoci.azurecr.io/giantswarm/klaus-personalities/go:v1.2.0@" + digestPersonality; instance.Spec.Personality != want {
+		t.Errorf("personality = %q, want %q", instance.Spec.Personality, want)
+	}
+	if instance.Spec.Image != "docker.io/library/golang:1.26" {
+		t.Errorf("image = %q, want the uncovered image unchanged", instance.Spec.Image)
+	}
+	if p := instance.Spec.Plugins[0]; p.Tag != "" || p.Digest != digestPlugin {
+		t.Errorf("plugin = %+v, want it pinned to the verified digest", p)
+	}
+}
+
+func TestVerifyOCIReferences_NilVerifier(t *testing.T) {
+	instance := verificationInstance()
+	verified, err := (&KlausInstanceReconciler{}).verifyOCIReferences(context.Background(), instance)
+	if err != nil || verified != 0 || instance.Spec.Plugins[0].Tag != "v1.0.0" {
+		t.Errorf("verified = %d, err = %v, plugins = %+v; want nothing verified", verified, err, instance.Spec.Plugins)
+	}
+}
+
+func TestPinDigest(t *testing.T) {
+	tests := []struct{ ref, digest, want string }{
+		{"example.com/a:v1", "", "example.com/a:v1"},
+		{"example.com/a:v1", digestPlugin, "example.com/a:v1@" + digestPlugin},
+		{"example.com/a@" + digestPlugin, digestPlugin, "example.com/a@" + digestPlugin},
+	}
+	for _, tt := range tests {
+		if got := pinDigest(tt.ref, tt.digest); got != tt.want {
+			t.Errorf("pinDigest(%q, %q) = %q, want %q", tt.ref, tt.digest, got, tt.want)
+		}
+	}
+}
+
+func TestSetArtifactVerificationCondition(t *testing.T) {
+	instance := verificationInstance()
+	setArtifactVerificationCondition(instance, 2, nil)
+	cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionArtifactVerificationFailed)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "Verified" {
+		t.Errorf("condition = %+v, want Verified", cond)
+	}
+	setArtifactVerificationCondition(instance, 0, nil)
+	if cond := apimeta.FindStatusCondition(instance.Status.Conditions, ConditionArtifactVerificationFailed); cond != nil {
+		t.Errorf("condition = %+v, want it removed without verified artifacts", cond)
+	}
+}
+
+func TestReconcile_ArtifactVerificationFailed(t *testing.T) {
+	ctx := context.Background()
+	instance := verificationInstance()
+	instance.Finalizers = []string{FinalizerName}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(instance).
+		WithStatusSubresource(instance).
+		Build()
+	verifier := &fakeArtifactVerifier{failures: map[string]error{
+		"gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v1.0.0": errors.New("artifact sha256:2222 is not signed"),
+	}}
+	r := &KlausInstanceReconciler{
+		Client:            c,
+		Recorder:          record.NewFakeRecorder(10),
+		OperatorNamespace: "klaus-system",
+		ArtifactVerifier:  verifier,
+	}
+
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "agent", Namespace: "klaus-system"}}
+	if _, err := r.Reconcile(ctx, req); err == nil || !strings.Contains(err.Error(), "is not signed") {
+		t.Fatalf("Reconcile() error = %v, want the verification failure", err)
+	}
+
+	var got klausv1alpha1.KlausInstance
+	if err := c.Get(ctx, req.NamespacedName, &got); err != nil {
+		t.Fatalf("getting instance: %v", err)
+	}
+	cond := apimeta.FindStatusCondition(got.Status.Conditions, ConditionArtifactVerificationFailed)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != ReasonArtifactVerificationFailed ||
+		!strings.Contains(cond.Message, "gs-base") {
+		t.Errorf("condition = %+v, want ArtifactVerificationFailed naming the plugin", cond)
+	}
+	ready := apimeta.FindStatusCondition(got.Status.Conditions, ConditionReady)
+	if ready == nil || ready.Reason != ReasonArtifactVerificationFailed || got.Status.State != klausv1alpha1.InstanceStateError {
+		t.Errorf("state = %s, ready = %+v; want an error state", got.Status.State, ready)
+	}
+}
@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// DefaultWorkspaceStatusInterval is how often status.workspace of the
+// instances is refreshed unless configured otherwise.
+const DefaultWorkspaceStatusInterval = 5 * time.Minute
+
+// workspaceCheckTimeout bounds the git command run in an instance pod.
+const workspaceCheckTimeout = 20 * time.Second
+
+// commitSHA matches a full SHA-1 or SHA-256 git object name.
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+
+// WorkspaceStatusReporter is a leader-elected manager runnable that
+// periodically records the git state of instance workspaces in their
+// status.workspace: the commit the git-clone init container checked out,
+// and, by running git in the klaus container, the current commit and
+// whether there are uncommitted changes.
+type WorkspaceStatusReporter struct {
+	Client client.Client
+
+	// Executor runs git in the instance pod; nil only records the clone.
+	Executor PodExecutor
+
+	// Interval is the refresh interval.
+	Interval time.Duration
+
+	// NamespaceScoped looks up instance pods in the namespace of the
+	// instance instead of the owner's user namespace.
+	NamespaceScoped bool
+}
+
+// NeedLeaderElection ensures only the leader writes instance status.
+func (r *WorkspaceStatusReporter) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs Refresh every Interval; see periodic.
+func (r *WorkspaceStatusReporter) Start(ctx context.Context) error {
+	return periodic{
+		name:            "workspace-status",
+		interval:        r.Interval,
+		defaultInterval: DefaultWorkspaceStatusInterval,
+		action:          "refreshing workspace status",
+		fn:              r.Refresh,
+	}.Start(ctx)
+}
+
+// Refresh records the workspace state of all running instances with a
+// workspace git repository.
+func (r *WorkspaceStatusReporter) Refresh(ctx context.Context) error {
+	var instances klausv1alpha1.KlausInstanceList
+	if err := r.Client.List(ctx, &instances); err != nil {
+		return fmt.Errorf("listing KlausInstances: %w", err)
+	}
+
+	var errs []error
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if !instance.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := r.refreshInstance(ctx, instance); err != nil {
+			errs = append(errs, fmt.Errorf("instance %s: %w", instance.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refreshInstance records the workspace state of one instance. Instances
+// without a workspace git repository have the status cleared; stopped ones
+// keep their last observation.
+func (r *WorkspaceStatusReporter) refreshInstance(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	var status *klausv1alpha1.WorkspaceStatus
+	if resources.NeedsGitClone(instance) {
+		pod, err := r.instancePod(ctx, instance)
+		if err != nil {
+			return err
+		}
+		if pod == nil {
+			return nil
+		}
+		status = instance.Status.Workspace.DeepCopy()
+		if status == nil {
+			status = &klausv1alpha1.WorkspaceStatus{}
+		}
+		applyCloneStatus(status, pod)
+		if r.Executor != nil {
+			if err := r.checkWorkspace(ctx, pod, status); err != nil {
+				log.FromContext(ctx).V(1).Info("checking workspace failed", "instance", instance.Name, "error", err.Error())
+			}
+		}
+	}
+	if equality.Semantic.DeepEqual(status, instance.Status.Workspace) {
+		return nil
+	}
+
+	base := instance.DeepCopy()
+	instance.Status.Workspace = status
+	if err := r.Client.Status().Patch(ctx, instance, client.MergeFrom(base)); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("patching status: %w", err)
+	}
+	return nil
+}
+
+// instancePod returns the running pod of an instance, or nil when it has
+// none.
+func (r *WorkspaceStatusReporter) instancePod(ctx context.Context, instance *klausv1alpha1.KlausInstance) (*corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := r.Client.List(ctx, &pods,
+		client.InNamespace(resources.InstanceNamespace(instance, r.NamespaceScoped)),
+		client.MatchingLabels(resources.SelectorLabels(instance))); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning && pods.Items[i].DeletionTimestamp == nil {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// applyCloneStatus records the commit reported by the git-clone init
+// container of pod, unless the workspace was synced more recently, e.g. by
+// workspace_pull.
+func applyCloneStatus(status *klausv1alpha1.WorkspaceStatus, pod *corev1.Pod) {
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name != resources.GitCloneContainerName {
+			continue
+		}
+		terminated := cs.State.Terminated
+		if terminated == nil || terminated.ExitCode != 0 {
+			return
+		}
+		sha := strings.TrimSpace(terminated.Message)
+		if !commitSHA.MatchString(sha) {
+			return
+		}
+		if status.LastSyncTime != nil && !status.LastSyncTime.Before(&terminated.FinishedAt) {
+			return
+		}
+		status.ResolvedSHA = sha
+		status.LastSyncTime = ptr.To(terminated.FinishedAt)
+		return
+	}
+}
+
+// workspaceCheckScript prints the checked out commit followed by the
+// number of changed paths.
+var workspaceCheckScript = fmt.Sprintf(
+	"git -c safe.directory=%[1]s -C %[1]s rev-parse HEAD && git -c safe.directory=%[1]s -C %[1]s status --porcelain | wc -l",
+	resources.WorkspaceMountPath)
+
+// checkWorkspace runs git in the klaus container to read the current
+// commit and count uncommitted changes.
+func (r *WorkspaceStatusReporter) checkWorkspace(ctx context.Context, pod *corev1.Pod, status *klausv1alpha1.WorkspaceStatus) error {
+	ctx, cancel := context.WithTimeout(ctx, workspaceCheckTimeout)
+	defer cancel()
+
+	out, err := r.Executor.Exec(ctx, pod.Namespace, pod.Name, resources.AppKlaus, []string{"sh", "-c", workspaceCheckScript})
+	if err != nil {
+		return err
+	}
+	head, changed, err := parseWorkspaceCheck(out)
+	if err != nil {
+		return err
+	}
+	status.HeadSHA = head
+	status.ChangedFiles = changed
+	status.Dirty = ptr.To(changed > 0)
+	status.LastCheckTime = &metav1.Time{Time: time.Now()}
+	return nil
+}
+
+// parseWorkspaceCheck parses the output of workspaceCheckScript.
+func parseWorkspaceCheck(out string) (string, int32, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 2 || !commitSHA.MatchString(fields[0]) {
+		return "", 0, fmt.Errorf("unexpected git output %q", out)
+	}
+	changed, err := strconv.ParseInt(fields[1], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("unexpected git output %q", out)
+	}
+	return fields[0], int32(changed), nil
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	clonedSHA = "1111111111111111111111111111111111111111"
+	headSHA   = "2222222222222222222222222222222222222222"
+)
+
+// fakePodExecutor returns a fixed output and records the last command.
+type fakePodExecutor struct {
+	out       string
+	err       error
+	container string
+	command   []string
+}
+
+func (f *fakePodExecutor) Exec(_ context.Context, _, _, container string, command []string) (string, error) {
+	f.container, f.command = container, command
+	return f.out, f.err
+}
+
+func workspaceStatusInstance(name string, status *klausv1alpha1.WorkspaceStatus) *klausv1alpha1.KlausInstance {
+	instance := namedTestInstance(name, testOwner)
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{GitRepo: "https://github.com/example/repo.git"}
+	instance.Status.Workspace = status
+	return instance
+}
+
+func workspaceStatusPod(instance string, finished time.Time, message string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance + "-abc",
+			Namespace: "klaus-user-user-example-com",
+			Labels:    map[string]string{"app.kubernetes.io/name": "klaus", "app.kubernetes.io/instance": instance},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name: "git-clone",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   0,
+					Message:    message,
+					FinishedAt: metav1.NewTime(finished),
+				}},
+			}},
+		},
+	}
+}
+
+func workspaceStatusClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	return fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(objs...).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).
+		Build()
+}
+
+func getWorkspaceStatus(t *testing.T, c client.Client, name string) *klausv1alpha1.WorkspaceStatus {
+	t.Helper()
+	var instance klausv1alpha1.KlausInstance
+	if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	return instance.Status.Workspace
+}
+
+func TestWorkspaceStatusReporter_Refresh(t *testing.T) {
+	finished := time.Now().Add(-time.Hour).Truncate(time.Second)
+	c := workspaceStatusClient(t,
+		workspaceStatusInstance("agent", nil),
+		workspaceStatusPod("agent", finished, clonedSHA+"\n"),
+	)
+	executor := &fakePodExecutor{out: headSHA + "\n       3\n"}
+	reporter := &WorkspaceStatusReporter{Client: c, Executor: executor}
+
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	ws := getWorkspaceStatus(t, c, "agent")
+	if ws == nil || ws.ResolvedSHA != clonedSHA || !ws.LastSyncTime.Time.Equal(finished) {
+		t.Fatalf("workspace = %+v, want the cloned commit", ws)
+	}
+	if ws.HeadSHA != headSHA || ws.Dirty == nil || !*ws.Dirty || ws.ChangedFiles != 3 || ws.LastCheckTime == nil {
+		t.Errorf("workspace = %+v, want a dirty checkout at the head commit", ws)
+	}
+	if executor.container != "klaus" {
+		t.Errorf("ran git in container %q, want klaus", executor.container)
+	}
+}
+
+func TestWorkspaceStatusReporter_KeepsNewerSync(t *testing.T) {
+	finished := time.Now().Add(-time.Hour).Truncate(time.Second)
+	synced := metav1.NewTime(finished.Add(30 * time.Minute))
+	c := workspaceStatusClient(t,
+		workspaceStatusInstance("agent", &klausv1alpha1.WorkspaceStatus{ResolvedSHA: headSHA, LastSyncTime: &synced}),
+		workspaceStatusPod("agent", finished, clonedSHA),
+	)
+	// A failing check leaves headSHA and dirty unknown.
+	reporter := &WorkspaceStatusReporter{Client: c, Executor: &fakePodExecutor{err: errors.New("git: not found")}}
+
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	ws := getWorkspaceStatus(t, c, "agent")
+	if ws.ResolvedSHA != headSHA || ws.Dirty != nil {
+		t.Errorf("workspace = %+v, want the later sync kept", ws)
+	}
+}
+
+func TestWorkspaceStatusReporter_ClearsWithoutGitRepo(t *testing.T) {
+	instance := workspaceStatusInstance("agent", &klausv1alpha1.WorkspaceStatus{ResolvedSHA: clonedSHA})
+	instance.Spec.Workspace.GitRepo = ""
+	c := workspaceStatusClient(t, instance)
+	reporter := &WorkspaceStatusReporter{Client: c}
+
+	if err := reporter.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if ws := getWorkspaceStatus(t, c, "agent"); ws != nil {
+		t.Errorf("workspace = %+v, want it cleared", ws)
+	}
+}
+
+func TestParseWorkspaceCheck(t *testing.T) {
+	head, changed, err := parseWorkspaceCheck(headSHA + "\n0\n")
+	if err != nil || head != headSHA || changed != 0 {
+		t.Errorf("parseWorkspaceCheck() = %q, %d, %v", head, changed, err)
+	}
+	for _, out := range []string{"", "fatal: not a git repository\n", headSHA + "\nmany\n"} {
+		if _, _, err := parseWorkspaceCheck(out); err == nil {
+			t.Errorf("parseWorkspaceCheck(%q) expected an error", out)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package cosign verifies the cosign signatures of the OCI artifacts the
+// operator mounts into agent pods. Signatures are read from the
+// sha256-<digest>.sig tag cosign pushes next to an artifact and checked
+// with sigstore-go against the public keys or keyless identities the
+// verification policy trusts for the artifact's repository prefix.
+package cosign
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+)
+
+// Policy maps repository prefixes to the signers trusted for the artifacts
+// below them. References matched by no rule fail verification unless
+// AllowUnmatched is set.
+type Policy struct {
+	// FulcioRoots holds the PEM certificates keyless signing certificates
+	// must chain to: the self-signed roots and their intermediates.
+	// Required by rules with identities.
+	FulcioRoots string `json:"fulcioRoots,omitempty"`
+
+	// RekorPublicKey is the PEM ECDSA public key of the transparency log
+	// whose signed entry timestamps prove when a keyless signature was
+	// made. Required by rules with identities.
+	RekorPublicKey string `json:"rekorPublicKey,omitempty"`
+
+	// AllowUnmatched lets references matched by no rule be used
+	// unverified.
+	AllowUnmatched bool `json:"allowUnmatched,omitempty"`
+
+	// Rules trust signers per repository prefix. The rule with the longest
+	// matching prefix applies.
+	Rules []Rule `json:"rules"`
+
+	// keyless verifies keyless signatures against the Fulcio roots and
+	// the Rekor key.
+	keyless *verify.Verifier
+}
+
+// Rule trusts Keys and Identities to sign the artifacts below Prefix. A
+// signature by any of them is accepted.
+type Rule struct {
+	// Prefix is a registry host or repository prefix, e.g.
+	// gsoci.azurecr.io/giantswarm. It matches at path boundaries after the
+	// normalization of the registry policy, so gsoci.azurecr.io:443/giantswarm
+	// and GSOCI.azurecr.io/giantswarm are the same prefix, and
+	// gsoci.azurecr.io/giantswarm-evil is not below it.
+	Prefix string `json:"prefix"`
+
+	// Keys are PEM public keys (ECDSA, RSA or Ed25519) of key-based
+	// signatures, as created by cosign generate-key-pair.
+	Keys []string `json:"keys,omitempty"`
+
+	// Identities are the keyless signers, matched against the Fulcio
+	// signing certificate.
+	Identities []Identity `json:"identities,omitempty"`
+
+	keys       []*verify.Verifier
+	identities verify.CertificateIdentities
+}
+
+// Identity is a keyless signer: the OIDC issuer and the subject of the token
+// the signing certificate was issued for. Exactly one of Subject and
+// SubjectRegExp is set.
+type Identity struct {
+	Issuer        string `json:"issuer"`
+	Subject       string `json:"subject,omitempty"`
+	SubjectRegExp string `json:"subjectRegExp,omitempty"`
+}
+
+// LoadPolicy reads a YAML verification policy file. An empty path returns
+// nil: artifact verification is disabled.
+func LoadPolicy(path string) (*Policy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path is an operator flag
+	if err != nil {
+		return nil, fmt.Errorf("reading artifact verification policy: %w", err)
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("parsing artifact verification policy %s: %w", path, err)
+	}
+	if err := policy.complete(); err != nil {
+		return nil, fmt.Errorf("invalid artifact verification policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// complete validates the policy and sets up the sigstore verifiers of its
+// keys and keyless trust roots.
+func (p *Policy) complete() error {
+	if len(p.Rules) == 0 {
+		return errors.New("no rules")
+	}
+	keyless := false
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Prefix == "" {
+			return fmt.Errorf("rules[%d]: prefix is required", i)
+		}
+		if len(rule.Keys) == 0 && len(rule.Identities) == 0 {
+			return fmt.Errorf("rules[%d] (%s): keys or identities are required", i, rule.Prefix)
+		}
+		rule.keys = nil
+		for j, key := range rule.Keys {
+			v, err := keyVerifier(key)
+			if err != nil {
+				return fmt.Errorf("rules[%d].keys[%d]: %w", i, j, err)
+			}
+			rule.keys = append(rule.keys, v)
+		}
+		rule.identities = nil
+		for j, id := range rule.Identities {
+			if id.Issuer == "" {
+				return fmt.Errorf("rules[%d].identities[%d]: issuer is required", i, j)
+			}
+			if (id.Subject == "") == (id.SubjectRegExp == "") {
+				return fmt.Errorf("rules[%d].identities[%d]: exactly one of subject and subjectRegExp is required", i, j)
+			}
+			identity, err := verify.NewShortCertificateIdentity(id.Issuer, "", id.Subject, id.SubjectRegExp)
+			if err != nil {
+				return fmt.Errorf("rules[%d].identities[%d]: %w", i, j, err)
+			}
+			rule.identities = append(rule.identities, identity)
+			keyless = true
+		}
+	}
+
+	if p.FulcioRoots == "" || p.RekorPublicKey == "" {
+		if keyless {
+			return errors.New("rules with identities require fulcioRoots and rekorPublicKey")
+		}
+		return nil
+	}
+	trustedRoot, err := p.trustedRoot()
+	if err != nil {
+		return err
+	}
+	// Fulcio certificates are valid for minutes only: the integrated time
+	// of the transparency log entry proves the signature was made while
+	// the certificate was valid.
+	p.keyless, err = verify.NewVerifier(trustedRoot, verify.WithTransparencyLog(1), verify.WithIntegratedTimestamps(1))
+	return err
+}
+
+// trustedRoot returns the sigstore trust root of the Fulcio certificates
+// and the Rekor key. Every self-signed certificate is a root, with the
+// others as intermediates.
+func (p *Policy) trustedRoot() (*root.TrustedRoot, error) {
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM([]byte(p.FulcioRoots))
+	if err != nil || len(certs) == 0 {
+		return nil, errors.New("fulcioRoots: no PEM certificates")
+	}
+	var roots, intermediates []*x509.Certificate
+	for _, cert := range certs {
+		if cert.CheckSignatureFrom(cert) == nil {
+			roots = append(roots, cert)
+		} else {
+			intermediates = append(intermediates, cert)
+		}
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("fulcioRoots: no self-signed root certificate")
+	}
+	var authorities []root.CertificateAuthority
+	for _, cert := range roots {
+		authorities = append(authorities, &root.FulcioCertificateAuthority{Root: cert, Intermediates: intermediates})
+	}
+
+	rekorKey, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(p.RekorPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("rekorPublicKey: %w", err)
+	}
+	if _, ok := rekorKey.(*ecdsa.PublicKey); !ok {
+		return nil, fmt.Errorf("rekorPublicKey: unsupported key type %T, want ECDSA", rekorKey)
+	}
+	logID, err := rekorLogID(rekorKey)
+	if err != nil {
+		return nil, fmt.Errorf("rekorPublicKey: %w", err)
+	}
+	logs := map[string]*root.TransparencyLog{
+		hex.EncodeToString(logID): {
+			ID: logID,
+			// The key is trusted for every entry it signed.
+			ValidityPeriodStart: time.Unix(0, 0),
+			HashFunc:            crypto.SHA256,
+			PublicKey:           rekorKey,
+			SignatureHashFunc:   crypto.SHA256,
+		},
+	}
+	return root.NewTrustedRoot(root.TrustedRootMediaType01, authorities, nil, nil, logs)
+}
+
+// rekorLogID returns the ID of a transparency log, the SHA-256 digest of
+// its DER public key.
+func rekorLogID(key crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	return sum[:], nil
+}
+
+// keyVerifier returns a sigstore verifier accepting signatures by the PEM
+// public key. ECDSA and RSA (PKCS #1 v1.5) signatures are over the SHA-256
+// digest of the payload, as cosign creates them.
+func keyVerifier(data string) (*verify.Verifier, error) {
+	key, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(data))
+	if err != nil {
+		return nil, errors.New("no PEM public key")
+	}
+	sv, err := signature.LoadVerifier(key, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("loading public key: %w", err)
+	}
+	material := root.NewTrustedPublicKeyMaterialFromMapping(map[string]*root.ExpiringKey{
+		publicKeyHint: root.NewExpiringKey(sv, time.Time{}, time.Time{}),
+	})
+	return verify.NewVerifier(material, verify.WithNoObserverTimestamps())
+}
+
+// rule returns the rule with the longest prefix matching the repository of
+// ref, or nil.
+func (p *Policy) rule(ref string) *Rule {
+	repo := registrypolicy.Repository(ref, "")
+	var match *Rule
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if registrypolicy.Matches(repo, rule.Prefix) && (match == nil || len(rule.Prefix) > len(match.Prefix)) {
+			match = rule
+		}
+	}
+	return match
+}
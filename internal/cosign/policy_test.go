@@ -0,0 +1,109 @@
+package cosign
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing policy: %v", err)
+	}
+	return path
+}
+
+// indent indents the lines of s by n spaces for a YAML block scalar.
+func indent(s string, n int) string {
+	prefix := strings.Repeat(" ", n)
+	return prefix + strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n"+prefix)
+}
+
+func TestLoadPolicy_EmptyPath(t *testing.T) {
+	policy, err := LoadPolicy("")
+	if err != nil || policy != nil {
+		t.Errorf("policy = %+v, err = %v; want verification disabled", policy, err)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	_, keyPEM := newKey(t)
+	ca := newTestCA(t)
+	policy, err := LoadPolicy(writePolicy(t, `
+fulcioRoots: |
+`+indent(ca.rootPEM, 2)+`
+rekorPublicKey: |
+`+indent(ca.rekorPEM, 2)+`
+rules:
+- prefix: gsoci.azurecr.io/giantswarm/
+  keys:
+  - |
+`+indent(keyPEM, 4)+`
+- prefix: gsoci.azurecr.io/giantswarm/klaus-plugins/
+  identities:
+  - issuer: https://token.actions.githubusercontent.com
+    subjectRegExp: ^https://github.com/giantswarm/
+`))
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	if len(policy.Rules[0].keys) != 1 || len(policy.Rules[1].identities) != 1 || policy.keyless == nil {
+		t.Errorf("policy = %+v, want parsed keys and trust roots", policy)
+	}
+
+	if rule := policy.rule("gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v1.0.0"); rule == nil || rule.Prefix != "gsoci.azurecr.io/giantswarm/klaus-plugins/" {
+		t.Errorf("rule = %+v, want the longest matching prefix", rule)
+	}
+	if rule := policy.rule("gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.0.0"); rule == nil || rule.Prefix != "gsoci.azurecr.io/giantswarm/" {
+		t.Errorf("rule = %+v, want the registry-wide rule", rule)
+	}
+	// Prefixes match the normalized repository at path boundaries.
+	if rule := policy.rule("GSOCI.azurecr.io:443/giantswarm/klaus-plugins/gs-base:v1.0.0"); rule == nil || rule.Prefix != "gsoci.azurecr.io/giantswarm/klaus-plugins/" {
+		t.Errorf("rule = %+v, want the rule of the normalized repository", rule)
+	}
+	for _, ref := range []string{"docker.io/library/busybox:1.36", "gsoci.azurecr.io/giantswarm-evil/agent:v1"} {
+		if rule := policy.rule(ref); rule != nil {
+			t.Errorf("rule(%s) = %+v, want none", ref, rule)
+		}
+	}
+}
+
+func TestLoadPolicy_Invalid(t *testing.T) {
+	_, keyPEM := newKey(t)
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "no rules", content: "rules: []", wantErr: "no rules"},
+		{name: "no prefix", content: "rules:\n- keys: [x]", wantErr: "prefix is required"},
+		{name: "no signers", content: "rules:\n- prefix: example.com/", wantErr: "keys or identities are required"},
+		{name: "invalid key", content: "rules:\n- prefix: example.com/\n  keys: [not-a-key]", wantErr: "no PEM public key"},
+		{
+			name:    "identity without trust roots",
+			content: "rules:\n- prefix: example.com/\n  identities:\n  - issuer: https://issuer\n    subject: user@example.com",
+			wantErr: "require fulcioRoots and rekorPublicKey",
+		},
+		{
+			name:    "identity without subject",
+			content: "rules:\n- prefix: example.com/\n  identities:\n  - issuer: https://issuer",
+			wantErr: "exactly one of subject and subjectRegExp",
+		},
+		{
+			name:    "unknown field",
+			content: "rules:\n- prefix: example.com/\n  key: |\n" + indent(keyPEM, 4),
+			wantErr: "unknown field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicy(writePolicy(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
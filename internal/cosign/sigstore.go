@@ -0,0 +1,114 @@
+package cosign
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	protobundle "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
+	protocommon "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	protorekor "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+)
+
+// bundleMediaType is the sigstore bundle version whose transparency log
+// entries carry a signed entry timestamp rather than an inclusion proof,
+// like the entries cosign attaches to signatures.
+const bundleMediaType = "application/vnd.dev.sigstore.bundle+json;version=0.1"
+
+// publicKeyHint names the trusted key of the key verifiers of the policy.
+const publicKeyHint = "policy-key"
+
+// rekorBundle is the transparency log entry cosign attaches to keyless
+// signatures in the dev.sigstore.cosign/bundle annotation.
+type rekorBundle struct {
+	SignedEntryTimestamp []byte       `json:"SignedEntryTimestamp"`
+	Payload              rekorPayload `json:"Payload"`
+}
+
+// rekorPayload is the entry the signed entry timestamp is over.
+type rekorPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// messageSignature returns the sigstore content of a signature over data.
+func messageSignature(data, sig []byte) *protobundle.Bundle_MessageSignature {
+	sum := sha256.Sum256(data)
+	return &protobundle.Bundle_MessageSignature{MessageSignature: &protocommon.MessageSignature{
+		MessageDigest: &protocommon.HashOutput{Algorithm: protocommon.HashAlgorithm_SHA2_256, Digest: sum[:]},
+		Signature:     sig,
+	}}
+}
+
+// keyBundle returns the sigstore bundle of a key-based signature over data,
+// verified with the key named publicKeyHint.
+func keyBundle(data, sig []byte) (*bundle.Bundle, error) {
+	return bundle.NewBundle(&protobundle.Bundle{
+		MediaType: bundleMediaType,
+		VerificationMaterial: &protobundle.VerificationMaterial{
+			Content: &protobundle.VerificationMaterial_PublicKey{
+				PublicKey: &protocommon.PublicKeyIdentifier{Hint: publicKeyHint},
+			},
+		},
+		Content: messageSignature(data, sig),
+	})
+}
+
+// keylessBundle returns the sigstore bundle of a keyless signature over
+// data: the signing certificate and the transparency log entry of the
+// dev.sigstore.cosign/bundle annotation.
+func keylessBundle(data, sig []byte, certPEM, bundleJSON string) (*bundle.Bundle, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("no PEM signing certificate")
+	}
+	if bundleJSON == "" {
+		return nil, errors.New("keyless signature has no transparency log bundle")
+	}
+	var rekor rekorBundle
+	if err := json.Unmarshal([]byte(bundleJSON), &rekor); err != nil {
+		return nil, fmt.Errorf("decoding the transparency log bundle: %w", err)
+	}
+	body, err := base64.StdEncoding.DecodeString(rekor.Payload.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding the transparency log entry: %w", err)
+	}
+	var kind struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := json.Unmarshal(body, &kind); err != nil {
+		return nil, fmt.Errorf("decoding the transparency log entry: %w", err)
+	}
+	logID, err := hex.DecodeString(rekor.Payload.LogID)
+	if err != nil {
+		return nil, fmt.Errorf("decoding the transparency log ID: %w", err)
+	}
+
+	return bundle.NewBundle(&protobundle.Bundle{
+		MediaType: bundleMediaType,
+		VerificationMaterial: &protobundle.VerificationMaterial{
+			Content: &protobundle.VerificationMaterial_X509CertificateChain{
+				X509CertificateChain: &protocommon.X509CertificateChain{
+					Certificates: []*protocommon.X509Certificate{{RawBytes: block.Bytes}},
+				},
+			},
+			TlogEntries: []*protorekor.TransparencyLogEntry{{
+				LogIndex:          rekor.Payload.LogIndex,
+				LogId:             &protocommon.LogId{KeyId: logID},
+				KindVersion:       &protorekor.KindVersion{Kind: kind.Kind, Version: kind.APIVersion},
+				IntegratedTime:    rekor.Payload.IntegratedTime,
+				InclusionPromise:  &protorekor.InclusionPromise{SignedEntryTimestamp: rekor.SignedEntryTimestamp},
+				CanonicalizedBody: body,
+			}},
+		},
+		Content: messageSignature(data, sig),
+	})
+}
@@ -0,0 +1,202 @@
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+const (
+	// SignatureMediaType is the media type of the layers of a cosign
+	// signature manifest; each layer is a signed simple signing payload.
+	SignatureMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+	// signatureType is the critical.type of cosign simple signing payloads.
+	signatureType = "cosign container image signature"
+
+	annotationSignature   = "dev.cosignproject.cosign/signature"
+	annotationCertificate = "dev.sigstore.cosign/certificate"
+	annotationBundle      = "dev.sigstore.cosign/bundle"
+)
+
+// payload is the cosign simple signing payload, binding a signature to the
+// manifest digest of the signed artifact.
+type payload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// Verifier verifies the cosign signatures of artifacts in their registry,
+// using the Docker credentials mounted into the operator.
+type Verifier struct {
+	policy *Policy
+	client remote.Client
+	// offline, when set, is returned for the artifacts covered by the
+	// policy instead of reading their signatures.
+	offline error
+}
+
+// NewVerifier creates a Verifier enforcing policy. Credentials are resolved
+// from the Docker config; without one, registries are accessed anonymously.
+func NewVerifier(policy *Policy) *Verifier {
+	client := &auth.Client{
+		Client: http.DefaultClient,
+		Cache:  auth.NewCache(),
+	}
+	if store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{}); err == nil {
+		client.Credential = credentials.Credential(store)
+	}
+	return &Verifier{policy: policy, client: client}
+}
+
+// NewOfflineVerifier creates a Verifier for operators without registry
+// access. Signatures cannot be read, so the artifacts covered by policy fail
+// verification with err.
+func NewOfflineVerifier(policy *Policy, err error) *Verifier {
+	return &Verifier{policy: policy, offline: err}
+}
+
+// Verify checks that the artifact at ref carries a signature by a signer
+// the policy trusts for it and returns the verified manifest digest. When
+// no rule covers ref it fails, or with AllowUnmatched returns "" without
+// contacting the registry.
+func (v *Verifier) Verify(ctx context.Context, ref string) (string, error) {
+	rule := v.policy.rule(ref)
+	if rule == nil {
+		if v.policy.AllowUnmatched {
+			return "", nil
+		}
+		return "", fmt.Errorf("no rule of the artifact verification policy covers %s", ref)
+	}
+	if v.offline != nil {
+		return "", fmt.Errorf("reading the signatures of %s: %w", ref, v.offline)
+	}
+
+	parsed, err := registry.ParseReference(ref)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+	repo, err := remote.NewRepository(parsed.Registry + "/" + parsed.Repository)
+	if err != nil {
+		return "", fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+	repo.Client = v.client
+
+	return v.policy.verify(ctx, repo, parsed.Reference, rule)
+}
+
+// verify resolves reference in target and checks its cosign signatures
+// against rule. The first valid signature wins.
+func (p *Policy) verify(ctx context.Context, target oras.ReadOnlyTarget, reference string, rule *Rule) (string, error) {
+	desc, err := target.Resolve(ctx, reference)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", reference, err)
+	}
+	digest := desc.Digest.String()
+
+	sigTag := strings.Replace(digest, ":", "-", 1) + ".sig"
+	_, manifestJSON, err := oras.FetchBytes(ctx, target, sigTag, oras.DefaultFetchBytesOptions)
+	if errors.Is(err, errdef.ErrNotFound) {
+		return "", fmt.Errorf("artifact %s is not signed", digest)
+	} else if err != nil {
+		return "", fmt.Errorf("fetching the signatures of %s: %w", digest, err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return "", fmt.Errorf("decoding the signature manifest of %s: %w", digest, err)
+	}
+
+	var errs []error
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != SignatureMediaType {
+			continue
+		}
+		data, err := content.FetchAll(ctx, target, layer)
+		if err != nil {
+			return "", fmt.Errorf("fetching a signature of %s: %w", digest, err)
+		}
+		if err := p.verifySignature(rule, digest, data, layer.Annotations); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return digest, nil
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("artifact %s has no cosign signatures", digest)
+	}
+	return "", fmt.Errorf("artifact %s has no signature trusted for %s: %w", digest, rule.Prefix, errors.Join(errs...))
+}
+
+// verifySignature checks one signature layer: the payload must name digest
+// and be signed by a key or keyless identity of rule.
+func (p *Policy) verifySignature(rule *Rule, digest string, data []byte, annotations map[string]string) error {
+	var signed payload
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return fmt.Errorf("decoding signature payload: %w", err)
+	}
+	if signed.Critical.Type != signatureType {
+		return fmt.Errorf("unexpected signature type %q", signed.Critical.Type)
+	}
+	if signed.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("signature is for %s", signed.Critical.Image.DockerManifestDigest)
+	}
+	sig, err := base64.StdEncoding.DecodeString(annotations[annotationSignature])
+	if err != nil || len(sig) == 0 {
+		return errors.New("signature annotation is missing or invalid")
+	}
+
+	if cert := annotations[annotationCertificate]; cert != "" {
+		if len(rule.Identities) == 0 {
+			return errors.New("keyless signature, but no identities are trusted")
+		}
+		return p.verifyKeyless(rule, data, sig, cert, annotations[annotationBundle])
+	}
+	b, err := keyBundle(data, sig)
+	if err != nil {
+		return err
+	}
+	for _, key := range rule.keys {
+		if _, err := key.Verify(b, verify.NewPolicy(verify.WithArtifact(bytes.NewReader(data)), verify.WithKey())); err == nil {
+			return nil
+		}
+	}
+	return errors.New("signature does not match a trusted key")
+}
+
+// verifyKeyless checks a keyless signature: the signing certificate must
+// chain to the Fulcio roots at the time the transparency log entry signed
+// by the Rekor key was integrated, and name a trusted identity.
+func (p *Policy) verifyKeyless(rule *Rule, data, sig []byte, certPEM, bundleJSON string) error {
+	b, err := keylessBundle(data, sig, certPEM, bundleJSON)
+	if err != nil {
+		return err
+	}
+	result, err := p.keyless.Verify(b, verify.NewPolicy(verify.WithArtifact(bytes.NewReader(data)), verify.WithoutIdentitiesUnsafe()))
+	if err != nil {
+		return fmt.Errorf("verifying the keyless signature: %w", err)
+	}
+	signer := result.Signature.Certificate
+	if _, err := rule.identities.Verify(*signer); err != nil {
+		return fmt.Errorf("signed by %s (issuer %s), which is not a trusted identity", signer.SubjectAlternativeName, signer.Issuer)
+	}
+	return nil
+}
@@ -0,0 +1,412 @@
+package cosign
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+const (
+	testIssuer  = "https://token.actions.githubusercontent.com"
+	testSubject = "https://github.com/giantswarm/klaus-plugins/.github/workflows/release.yaml@refs/heads/main"
+)
+
+// oidIssuerV2 is the Fulcio certificate extension holding the OIDC issuer
+// of the token the certificate was issued for, a DER UTF8String.
+var oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshaling public key: %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatalf("signing: %v", err)
+	}
+	return sig
+}
+
+// testCA is a Fulcio and Rekor stand-in issuing short-lived signing
+// certificates and logging signatures.
+type testCA struct {
+	root     *x509.Certificate
+	rootKey  *ecdsa.PrivateKey
+	rootPEM  string
+	rekorKey *ecdsa.PrivateKey
+	rekorPEM string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	rootKey, _ := newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-fulcio"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("creating root certificate: %v", err)
+	}
+	root, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parsing root certificate: %v", err)
+	}
+	rekorKey, rekorPEM := newKey(t)
+	return &testCA{
+		root:     root,
+		rootKey:  rootKey,
+		rootPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		rekorKey: rekorKey,
+		rekorPEM: rekorPEM,
+	}
+}
+
+// issue returns a ten minute signing certificate for subject, valid from
+// notBefore, and its key.
+func (ca *testCA) issue(t *testing.T, issuer, subject string, notBefore time.Time) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, _ := newKey(t)
+	issuerExt, err := asn1.MarshalWithParams(issuer, "utf8")
+	if err != nil {
+		t.Fatalf("marshaling issuer: %v", err)
+	}
+	uri, err := url.Parse(subject)
+	if err != nil {
+		t.Fatalf("parsing subject: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       notBefore,
+		NotAfter:        notBefore.Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:            []*url.URL{uri},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuerExt}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.root, &key.PublicKey, ca.rootKey)
+	if err != nil {
+		t.Fatalf("creating signing certificate: %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// hashedRekord is the transparency log entry body of a signature.
+type hashedRekord struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// bundle logs sig over data with certPEM at integratedTime and returns the
+// bundle annotation.
+func (ca *testCA) bundle(t *testing.T, data, sig []byte, certPEM string, integratedTime time.Time) string {
+	t.Helper()
+	var entry hashedRekord
+	entry.APIVersion = "0.0.1"
+	entry.Kind = "hashedrekord"
+	sum := sha256.Sum256(data)
+	entry.Spec.Data.Hash.Algorithm = "sha256"
+	entry.Spec.Data.Hash.Value = hex.EncodeToString(sum[:])
+	entry.Spec.Signature.Content = base64.StdEncoding.EncodeToString(sig)
+	entry.Spec.Signature.PublicKey.Content = base64.StdEncoding.EncodeToString([]byte(certPEM))
+	body, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("marshaling entry: %v", err)
+	}
+	logID, err := rekorLogID(&ca.rekorKey.PublicKey)
+	if err != nil {
+		t.Fatalf("computing log ID: %v", err)
+	}
+	bundle := rekorBundle{Payload: rekorPayload{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: integratedTime.Unix(),
+		LogID:          hex.EncodeToString(logID),
+		LogIndex:       42,
+	}}
+	signed, err := json.Marshal(bundle.Payload)
+	if err != nil {
+		t.Fatalf("marshaling payload: %v", err)
+	}
+	bundle.SignedEntryTimestamp = sign(t, ca.rekorKey, signed)
+	out, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("marshaling bundle: %v", err)
+	}
+	return string(out)
+}
+
+// pushArtifact pushes an artifact tagged v1 to store and returns its
+// descriptor.
+func pushArtifact(t *testing.T, store *memory.Store, data string) ocispec.Descriptor {
+	t.Helper()
+	ctx := context.Background()
+	layer, err := oras.PushBytes(ctx, store, "application/vnd.giantswarm.klaus-plugin.content.v1.tar+gzip", []byte(data))
+	if err != nil {
+		t.Fatalf("pushing layer: %v", err)
+	}
+	desc, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, "application/vnd.giantswarm.klaus-plugin.v1",
+		oras.PackManifestOptions{Layers: []ocispec.Descriptor{layer}})
+	if err != nil {
+		t.Fatalf("packing manifest: %v", err)
+	}
+	if err := store.Tag(ctx, desc, "v1"); err != nil {
+		t.Fatalf("tagging artifact: %v", err)
+	}
+	return desc
+}
+
+func signaturePayload(digest string) []byte {
+	return fmt.Appendf(nil, `{"critical":{"identity":{"docker-reference":"example.com/plugins/base"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest)
+}
+
+// attachSignatures pushes the cosign signature manifest of digest with one
+// layer per annotation set.
+func attachSignatures(t *testing.T, store *memory.Store, digest string, payloads [][]byte, annotations []map[string]string) {
+	t.Helper()
+	ctx := context.Background()
+	var layers []ocispec.Descriptor
+	for i, data := range payloads {
+		layer, err := oras.PushBytes(ctx, store, SignatureMediaType, data)
+		if err != nil {
+			t.Fatalf("pushing signature layer: %v", err)
+		}
+		layer.Annotations = annotations[i]
+		layers = append(layers, layer)
+	}
+	config, err := oras.PushBytes(ctx, store, ocispec.MediaTypeImageConfig, []byte("{}"))
+	if err != nil {
+		t.Fatalf("pushing config: %v", err)
+	}
+	manifest, err := json.Marshal(ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    config,
+		Layers:    layers,
+	})
+	if err != nil {
+		t.Fatalf("marshaling signature manifest: %v", err)
+	}
+	desc, err := oras.PushBytes(ctx, store, ocispec.MediaTypeImageManifest, manifest)
+	if err != nil {
+		t.Fatalf("pushing signature manifest: %v", err)
+	}
+	if err := store.Tag(ctx, desc, strings.Replace(digest, ":", "-", 1)+".sig"); err != nil {
+		t.Fatalf("tagging signature manifest: %v", err)
+	}
+}
+
+func keyPolicy(t *testing.T, keyPEMs ...string) *Policy {
+	t.Helper()
+	policy := &Policy{Rules: []Rule{{Prefix: "example.com", Keys: keyPEMs}}}
+	if err := policy.complete(); err != nil {
+		t.Fatalf("completing policy: %v", err)
+	}
+	return policy
+}
+
+func TestVerify_Key(t *testing.T) {
+	ctx := context.Background()
+	key, keyPEM := newKey(t)
+	_, otherPEM := newKey(t)
+	policy := keyPolicy(t, otherPEM, keyPEM)
+
+	store := memory.New()
+	desc := pushArtifact(t, store, "plugin")
+	digest := desc.Digest.String()
+
+	if _, err := policy.verify(ctx, store, "v1", &policy.Rules[0]); err == nil || !strings.Contains(err.Error(), "is not signed") {
+		t.Errorf("error = %v, want an unsigned artifact error", err)
+	}
+
+	data := signaturePayload(digest)
+	attachSignatures(t, store, digest, [][]byte{data}, []map[string]string{
+		{annotationSignature: base64.StdEncoding.EncodeToString(sign(t, key, data))},
+	})
+	got, err := policy.verify(ctx, store, "v1", &policy.Rules[0])
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if got != digest {
+		t.Errorf("digest = %s, want %s", got, digest)
+	}
+
+	// A signature by an untrusted key is rejected.
+	untrusted := keyPolicy(t, otherPEM)
+	if _, err := untrusted.verify(ctx, store, "v1", &untrusted.Rules[0]); err == nil || !strings.Contains(err.Error(), "does not match a trusted key") {
+		t.Errorf("error = %v, want an untrusted key error", err)
+	}
+}
+
+func TestVerify_PayloadForAnotherArtifact(t *testing.T) {
+	ctx := context.Background()
+	key, keyPEM := newKey(t)
+	policy := keyPolicy(t, keyPEM)
+
+	store := memory.New()
+	desc := pushArtifact(t, store, "plugin")
+	digest := desc.Digest.String()
+
+	// A valid signature of another artifact copied to this one.
+	data := signaturePayload("sha256:" + strings.Repeat("0", 64))
+	attachSignatures(t, store, digest, [][]byte{data}, []map[string]string{
+		{annotationSignature: base64.StdEncoding.EncodeToString(sign(t, key, data))},
+	})
+	if _, err := policy.verify(ctx, store, "v1", &policy.Rules[0]); err == nil || !strings.Contains(err.Error(), "signature is for sha256:000") {
+		t.Errorf("error = %v, want a digest mismatch", err)
+	}
+}
+
+func TestVerify_Keyless(t *testing.T) {
+	ctx := context.Background()
+	ca := newTestCA(t)
+	policy := &Policy{
+		FulcioRoots:    ca.rootPEM,
+		RekorPublicKey: ca.rekorPEM,
+		Rules: []Rule{{
+			Prefix:     "example.com",
+			Identities: []Identity{{Issuer: testIssuer, SubjectRegExp: `^https://github\.com/giantswarm/`}},
+		}},
+	}
+	if err := policy.complete(); err != nil {
+		t.Fatalf("completing policy: %v", err)
+	}
+
+	// The certificate expired long ago; the logged time proves the
+	// signature was made while it was valid.
+	signedAt := time.Now().Add(-2 * time.Hour)
+
+	tests := []struct {
+		name    string
+		subject string
+		mutate  func(annotations map[string]string)
+		wantErr string
+	}{
+		{name: "trusted identity", subject: testSubject},
+		{name: "untrusted identity", subject: "https://github.com/attacker/repo/.github/workflows/release.yaml@refs/heads/main", wantErr: "not a trusted identity"},
+		{name: "no bundle", subject: testSubject, mutate: func(a map[string]string) { delete(a, annotationBundle) }, wantErr: "no transparency log bundle"},
+		{
+			name:    "tampered timestamp",
+			subject: testSubject,
+			mutate: func(a map[string]string) {
+				a[annotationBundle] = strings.Replace(a[annotationBundle], `"logIndex":42`, `"logIndex":43`, 1)
+			},
+			wantErr: "verifying the keyless signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memory.New()
+			digest := pushArtifact(t, store, "plugin").Digest.String()
+
+			key, certPEM := ca.issue(t, testIssuer, tt.subject, signedAt.Add(-time.Minute))
+			data := signaturePayload(digest)
+			sig := sign(t, key, data)
+			annotations := map[string]string{
+				annotationSignature:   base64.StdEncoding.EncodeToString(sig),
+				annotationCertificate: certPEM,
+				annotationBundle:      ca.bundle(t, data, sig, certPEM, signedAt),
+			}
+			if tt.mutate != nil {
+				tt.mutate(annotations)
+			}
+			attachSignatures(t, store, digest, [][]byte{data}, []map[string]string{annotations})
+
+			_, err := policy.verify(ctx, store, "v1", &policy.Rules[0])
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifier_NotCovered(t *testing.T) {
+	_, keyPEM := newKey(t)
+	policy := keyPolicy(t, keyPEM)
+	v := NewVerifier(policy)
+	// No rule covers the reference: it is refused without contacting the
+	// registry.
+	for _, ref := range []string{"docker.io/library/busybox:1.36", "example.com.evil.io/plugins/gs-base:v1"} {
+		if _, err := v.Verify(context.Background(), ref); err == nil || !strings.Contains(err.Error(), "no rule") {
+			t.Errorf("Verify(%s) error = %v, want the unmatched reference refused", ref, err)
+		}
+	}
+
+	policy.AllowUnmatched = true
+	digest, err := v.Verify(context.Background(), "docker.io/library/busybox:1.36")
+	if err != nil || digest != "" {
+		t.Errorf("digest = %q, err = %v; want the reference skipped", digest, err)
+	}
+}
+
+func TestVerifier_Offline(t *testing.T) {
+	_, keyPEM := newKey(t)
+	errOffline := errors.New("offline")
+	policy := keyPolicy(t, keyPEM)
+	policy.AllowUnmatched = true
+	v := NewOfflineVerifier(policy, errOffline)
+
+	if _, err := v.Verify(context.Background(), "example.com/plugins/gs-base:v1"); !errors.Is(err, errOffline) {
+		t.Errorf("error = %v, want the covered artifact refused offline", err)
+	}
+	digest, err := v.Verify(context.Background(), "docker.io/library/busybox:1.36")
+	if err != nil || digest != "" {
+		t.Errorf("digest = %q, err = %v; want the reference skipped", digest, err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package deprecation is the central registry of deprecated MCP tools, tool
+// arguments and KlausInstance fields. The MCP server adds warnings for them
+// to tool responses and the admission webhook returns them as Kubernetes
+// warnings, so clients get notice before they are removed.
+package deprecation
+
+import (
+	"fmt"
+	"slices"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// Deprecation describes an MCP tool, a tool argument or a KlausInstance
+// field that is scheduled for removal.
+type Deprecation struct {
+	// Tool is the deprecated MCP tool, or the tool of Argument.
+	Tool string
+
+	// Argument is the deprecated argument of Tool. The whole tool is
+	// deprecated when it is empty.
+	Argument string
+
+	// Field is the path of a deprecated KlausInstance field, e.g.
+	// spec.scheduling.runtimeClassName.
+	Field string
+
+	// Present reports whether an instance sets Field.
+	Present func(*klausv1alpha1.KlausInstance) bool
+
+	// Replacement is what to use instead, if anything.
+	Replacement string
+
+	// RemovedIn is the operator release for tools and arguments, or the API
+	// version for fields, in which the deprecated item is removed.
+	RemovedIn string
+}
+
+// Subject returns the name of the deprecated item as shown in warnings.
+func (d Deprecation) Subject() string {
+	switch {
+	case d.Field != "":
+		return d.Field
+	case d.Argument != "":
+		return fmt.Sprintf("argument %q of tool %s", d.Argument, d.Tool)
+	default:
+		return "tool " + d.Tool
+	}
+}
+
+// Warning returns the message shown to clients using the deprecated item.
+func (d Deprecation) Warning() string {
+	msg := d.Subject() + " is deprecated"
+	if d.RemovedIn != "" {
+		msg += " and will be removed in " + d.RemovedIn
+	}
+	if d.Replacement != "" {
+		msg += "; use " + d.Replacement + " instead"
+	}
+	return msg
+}
+
+// Registry is a list of deprecations.
+type Registry []Deprecation
+
+// Default is the registry of everything currently deprecated. Entries are
+// removed together with the deprecated item.
+var Default = Registry{
+	{
+		Tool:        "get_logs",
+		Replacement: "get_instance_logs",
+		RemovedIn:   "0.2.0",
+	},
+	{
+		Field: "spec.scheduling.runtimeClassName",
+		Present: func(instance *klausv1alpha1.KlausInstance) bool {
+			return instance.Spec.Scheduling != nil && instance.Spec.Scheduling.RuntimeClassName != nil
+		},
+		Replacement: "spec.sandbox",
+		RemovedIn:   "v1alpha2",
+	},
+	{
+		Field: "spec.stopped",
+		Present: func(instance *klausv1alpha1.KlausInstance) bool {
+			return instance.Spec.Stopped
+		},
+		Replacement: "spec.desiredState",
+		RemovedIn:   "v1alpha3",
+	},
+}
+
+// Tool returns the deprecation of a whole tool, if it is deprecated.
+func (r Registry) Tool(tool string) (Deprecation, bool) {
+	i := slices.IndexFunc(r, func(d Deprecation) bool {
+		return d.Field == "" && d.Argument == "" && d.Tool == tool
+	})
+	if i < 0 {
+		return Deprecation{}, false
+	}
+	return r[i], true
+}
+
+// ToolWarnings returns the warnings for a call of tool with args: one if
+// the tool is deprecated and one per deprecated argument that is set.
+func (r Registry) ToolWarnings(tool string, args map[string]any) []string {
+	var warnings []string
+	for _, d := range r {
+		if d.Field != "" || d.Tool != tool {
+			continue
+		}
+		if d.Argument != "" {
+			if _, ok := args[d.Argument]; !ok {
+				continue
+			}
+		}
+		warnings = append(warnings, d.Warning())
+	}
+	return warnings
+}
+
+// InstanceWarnings returns the warnings for the deprecated fields an
+// instance sets.
+func (r Registry) InstanceWarnings(instance *klausv1alpha1.KlausInstance) []string {
+	var warnings []string
+	for _, d := range r {
+		if d.Field == "" || d.Present == nil || !d.Present(instance) {
+			continue
+		}
+		warnings = append(warnings, d.Warning())
+	}
+	return warnings
+}
@@ -0,0 +1,96 @@
+package deprecation
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+var testRegistry = Registry{
+	{Tool: "old_tool", Replacement: "new_tool", RemovedIn: "0.5.0"},
+	{Tool: "create_instance", Argument: "legacy", RemovedIn: "0.5.0"},
+	{
+		Field: "spec.image",
+		Present: func(instance *klausv1alpha1.KlausInstance) bool {
+			return instance.Spec.Image != ""
+		},
+	},
+}
+
+func TestWarning(t *testing.T) {
+	tests := []struct {
+		d    Deprecation
+		want string
+	}{
+		{testRegistry[0], "tool old_tool is deprecated and will be removed in 0.5.0; use new_tool instead"},
+		{testRegistry[1], `argument "legacy" of tool create_instance is deprecated and will be removed in 0.5.0`},
+		{testRegistry[2], "spec.image is deprecated"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.Warning(); got != tt.want {
+			t.Errorf("Warning() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTool(t *testing.T) {
+	if d, ok := testRegistry.Tool("old_tool"); !ok || d.Replacement != "new_tool" {
+		t.Errorf("Tool(old_tool) = %+v, %v; want the deprecation", d, ok)
+	}
+	if _, ok := testRegistry.Tool("create_instance"); ok {
+		t.Error("a tool with a deprecated argument must not be reported as deprecated")
+	}
+}
+
+func TestToolWarnings(t *testing.T) {
+	if got := testRegistry.ToolWarnings("old_tool", nil); len(got) != 1 {
+		t.Errorf("ToolWarnings(old_tool) = %v, want one warning", got)
+	}
+	if got := testRegistry.ToolWarnings("create_instance", map[string]any{"name": "x"}); len(got) != 0 {
+		t.Errorf("ToolWarnings without the deprecated argument = %v, want none", got)
+	}
+	if got := testRegistry.ToolWarnings("create_instance", map[string]any{"legacy": true}); len(got) != 1 {
+		t.Errorf("ToolWarnings with the deprecated argument = %v, want one warning", got)
+	}
+	if got := testRegistry.ToolWarnings("list_instances", nil); len(got) != 0 {
+		t.Errorf("ToolWarnings(list_instances) = %v, want none", got)
+	}
+}
+
+func TestInstanceWarnings(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{}
+	if got := testRegistry.InstanceWarnings(instance); len(got) != 0 {
+		t.Errorf("InstanceWarnings() = %v, want none", got)
+	}
+	instance.Spec.Image = "example.com/klaus:v1"
+	if got := testRegistry.InstanceWarnings(instance); !slices.Equal(got, []string{"spec.image is deprecated"}) {
+		t.Errorf("InstanceWarnings() = %v, want the spec.image warning", got)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	for _, d := range Default {
+		if d.RemovedIn == "" {
+			t.Errorf("%s has no removal version", d.Subject())
+		}
+		if (d.Field != "") != (d.Present != nil) {
+			t.Errorf("%s: fields need Present and tools must not set it", d.Subject())
+		}
+	}
+
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{
+		Scheduling: &klausv1alpha1.SchedulingConfig{RuntimeClassName: ptr.To("gvisor")},
+	}}
+	if got := Default.InstanceWarnings(instance); len(got) != 1 {
+		t.Errorf("InstanceWarnings() = %v, want the runtimeClassName warning", got)
+	}
+
+	instance = &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{Stopped: true}}
+	if got := Default.InstanceWarnings(instance); len(got) != 1 || !strings.Contains(got[0], "spec.desiredState") {
+		t.Errorf("InstanceWarnings() = %v, want the spec.stopped warning", got)
+	}
+}
@@ -0,0 +1,262 @@
+// Package githubapp talks to the GitHub REST API for workspace git
+// operations. It mints short-lived GitHub App installation tokens, so that no
+// long-lived access tokens have to be stored in user namespaces, and opens
+// pull requests for pushed workspace output.
+package githubapp
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the GitHub REST API endpoint of github.com.
+const DefaultBaseURL = "https://api.github.com"
+
+const (
+	// jwtLifetime is the validity of the app JWTs, below GitHub's limit of
+	// ten minutes.
+	jwtLifetime = 9 * time.Minute
+
+	// jwtClockSkew backdates the JWTs to tolerate clock drift to GitHub.
+	jwtClockSkew = time.Minute
+
+	// maxResponseBytes bounds the API responses read.
+	maxResponseBytes = 1 << 20
+
+	// requestTimeout bounds a single API request.
+	requestTimeout = 30 * time.Second
+)
+
+// api sends requests to a GitHub REST API endpoint.
+type api struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func newAPI(baseURL string) api {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return api{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Client mints installation tokens of a GitHub App.
+type Client struct {
+	api
+	appID  int64
+	key    *rsa.PrivateKey
+	host   string
+	policy *RepositoryPolicy
+	now    func() time.Time
+}
+
+// TokenRequest describes the installation token of a workspace.
+type TokenRequest struct {
+	// InstanceOwner is the owner of the instance using the token, checked
+	// against the RepositoryPolicy.
+	InstanceOwner string
+
+	// Repository is the only repository the token grants access to.
+	Repository Repository
+
+	// InstallationID is the app installation, or 0 to look it up from the
+	// repository.
+	InstallationID int64
+
+	// Permissions are the repository permissions of the token, e.g.
+	// contents: read.
+	Permissions map[string]string
+}
+
+// NewClient returns a client for the GitHub App with the given ID and PEM
+// encoded private key, as downloaded from the app settings. baseURL is the
+// REST API endpoint, e.g. https://github.example.com/api/v3 for GitHub
+// Enterprise Server; DefaultBaseURL is used when empty. Tokens are only
+// minted for repositories on the host of baseURL that policy allows.
+func NewClient(appID int64, privateKeyPEM []byte, baseURL string, policy *RepositoryPolicy) (*Client, error) {
+	if appID <= 0 {
+		return nil, fmt.Errorf("invalid GitHub App ID %d", appID)
+	}
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		api:    newAPI(baseURL),
+		appID:  appID,
+		key:    key,
+		host:   WebHost(baseURL),
+		policy: policy,
+		now:    time.Now,
+	}, nil
+}
+
+// parsePrivateKey parses a PKCS #1 or PKCS #8 PEM encoded RSA private key.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// InstallationToken mints an installation token restricted to the
+// repository and permissions of req. Repositories on other hosts than the
+// app's and those the policy does not allow the instance owner are
+// refused. The installation is looked up from the repository when
+// req.InstallationID is 0.
+func (c *Client) InstallationToken(ctx context.Context, req TokenRequest) (string, time.Time, error) {
+	repo := req.Repository
+	if repo.Host != c.host {
+		return "", time.Time{}, fmt.Errorf("repository %s/%s is not on %s, the GitHub host of the operator's app", repo.Host, repo, c.host)
+	}
+	if !c.policy.Allows(req.InstanceOwner, repo) {
+		return "", time.Time{}, fmt.Errorf("the GitHub App repository policy does not allow %s to use %s", req.InstanceOwner, repo)
+	}
+
+	jwt, err := c.appJWT()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	installationID := req.InstallationID
+	if installationID == 0 {
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		path := "/repos/" + url.PathEscape(repo.Owner) + "/" + url.PathEscape(repo.Name) + "/installation"
+		if err := c.do(ctx, http.MethodGet, path, jwt, nil, http.StatusOK, &installation); err != nil {
+			return "", time.Time{}, fmt.Errorf("looking up the app installation for %s: %w", repo, err)
+		}
+		installationID = installation.ID
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := "/app/installations/" + strconv.FormatInt(installationID, 10) + "/access_tokens"
+	body := map[string]any{"repositories": []string{repo.Name}, "permissions": req.Permissions}
+	if err := c.do(ctx, http.MethodPost, path, jwt, body, http.StatusCreated, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("creating an installation token for %s: %w", repo, err)
+	}
+	if token.Token == "" {
+		return "", time.Time{}, fmt.Errorf("creating an installation token for %s: empty token in response", repo)
+	}
+	return token.Token, token.ExpiresAt, nil
+}
+
+// appJWT returns a JWT authenticating as the app, signed with RS256.
+func (c *Client) appJWT() (string, error) {
+	now := c.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(c.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing the GitHub App JWT: %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// do sends an API request authenticated with the bearer token, an app JWT or
+// an access token, and decodes the response into out when it has the wanted
+// status.
+func (c *api) do(ctx context.Context, method, path, token string, body any, wantStatus int, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != wantStatus {
+		apiErr := &apiError{status: resp.Status, code: resp.StatusCode}
+		var body struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &body) == nil {
+			apiErr.message = body.Message
+		}
+		return apiErr
+	}
+	return json.Unmarshal(data, out)
+}
+
+// apiError is an unexpected API response status.
+type apiError struct {
+	status  string
+	code    int
+	message string
+}
+
+func (e *apiError) Error() string {
+	if e.message != "" {
+		return fmt.Sprintf("GitHub API returned %s: %s", e.status, e.message)
+	}
+	return "GitHub API returned " + e.status
+}
@@ -0,0 +1,163 @@
+package githubapp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// verifyJWT checks the RS256 signature and issuer of an app JWT.
+func verifyJWT(t *testing.T, key *rsa.PrivateKey, authorization string) {
+	t.Helper()
+	jwt, ok := strings.CutPrefix(authorization, "Bearer ")
+	parts := strings.Split(jwt, ".")
+	if !ok || len(parts) != 3 {
+		t.Fatalf("authorization = %q, want a bearer JWT", authorization)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("decoding signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("JWT signature: %v", err)
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("decoding claims: %v", err)
+	}
+	if claims.Iss != "1234" || claims.Exp-claims.Iat > int64(10*time.Minute/time.Second) {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestInstallationToken(t *testing.T) {
+	key, keyPEM := testKey(t)
+	expiresAt := time.Date(2026, 3, 14, 13, 0, 0, 0, time.UTC)
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		verifyJWT(t, key, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/org/repo/installation":
+			_, _ = w.Write([]byte(`{"id": 99}`))
+		case "/app/installations/99/access_tokens":
+			var body struct {
+				Repositories []string          `json:"repositories"`
+				Permissions  map[string]string `json:"permissions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Repositories) != 1 || body.Repositories[0] != "repo" ||
+				len(body.Permissions) != 1 || body.Permissions["contents"] != "read" {
+				t.Errorf("token request body = %+v, %v; want the repository and read access only", body, err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token": "ghs_abc", "expires_at": "2026-03-14T13:00:00Z"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	policy := &RepositoryPolicy{Rules: []RepositoryRule{{Owners: []string{"*@example.com"}, Repositories: []string{"org/*"}}}}
+	c, err := NewClient(1234, keyPEM, srv.URL+"/", policy)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	host := WebHost(srv.URL)
+	req := TokenRequest{
+		InstanceOwner: "user@example.com",
+		Repository:    Repository{Host: host, Owner: "org", Name: "repo"},
+		Permissions:   map[string]string{"contents": "read"},
+	}
+
+	token, expiry, err := c.InstallationToken(context.Background(), req)
+	if err != nil {
+		t.Fatalf("InstallationToken: %v", err)
+	}
+	if token != "ghs_abc" || !expiry.Equal(expiresAt) {
+		t.Errorf("token = %q, expiry = %v", token, expiry)
+	}
+	if len(requests) != 2 {
+		t.Errorf("requests = %v, want an installation lookup and a token request", requests)
+	}
+
+	requests = nil
+	withID := req
+	withID.InstallationID = 99
+	if _, _, err := c.InstallationToken(context.Background(), withID); err != nil {
+		t.Fatalf("InstallationToken with installation ID: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("requests = %v, want no installation lookup", requests)
+	}
+
+	missing := req
+	missing.Repository.Name = "missing"
+	_, _, err = c.InstallationToken(context.Background(), missing)
+	if err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("error = %v, want the API message", err)
+	}
+
+	requests = nil
+	otherHost := req
+	otherHost.Repository.Host = "gitlab.example.com"
+	if _, _, err := c.InstallationToken(context.Background(), otherHost); err == nil || !strings.Contains(err.Error(), "GitHub host") {
+		t.Errorf("error = %v, want a repository on another host refused", err)
+	}
+	notAllowed := req
+	notAllowed.InstanceOwner = "user@other.example"
+	if _, _, err := c.InstallationToken(context.Background(), notAllowed); err == nil || !strings.Contains(err.Error(), "repository policy") {
+		t.Errorf("error = %v, want an owner outside the policy refused", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("requests = %v, want refused tokens not requested", requests)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	key, keyPEM := testKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshaling key: %v", err)
+	}
+
+	if _, err := NewClient(1, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), "", nil); err != nil {
+		t.Errorf("PKCS #8 key: %v", err)
+	}
+	if c, err := NewClient(1, keyPEM, "", nil); err != nil || c.baseURL != DefaultBaseURL {
+		t.Errorf("NewClient = %v, %v; want the default base URL", c, err)
+	}
+	if _, err := NewClient(0, keyPEM, "", nil); err == nil {
+		t.Error("expected an error for app ID 0")
+	}
+	if _, err := NewClient(1, []byte("not a key"), "", nil); err == nil {
+		t.Error("expected an error for a non-PEM key")
+	}
+}
@@ -0,0 +1,71 @@
+package githubapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// PullRequest is a pull request to open.
+type PullRequest struct {
+	// Title is the pull request title.
+	Title string
+
+	// Head is the branch with the changes.
+	Head string
+
+	// Base is the branch the changes are merged into.
+	Base string
+
+	// Body is the pull request description.
+	Body string
+}
+
+// PullRequests opens pull requests authenticated with a repository access
+// token, e.g. a workspace installation token or personal access token.
+type PullRequests struct {
+	api
+	host string
+}
+
+// NewPullRequests returns a client for the REST API endpoint baseURL;
+// DefaultBaseURL is used when empty. It only opens pull requests in
+// repositories on the host of baseURL.
+func NewPullRequests(baseURL string) *PullRequests {
+	return &PullRequests{api: newAPI(baseURL), host: WebHost(baseURL)}
+}
+
+// OpenPullRequest opens the pull request in repository and returns its web
+// URL. An open pull request for the same head branch is returned instead of
+// failing, so retries do not open duplicates. Repositories on other hosts
+// are rejected, as the token is not meant for the API of the operator.
+func (p *PullRequests) OpenPullRequest(ctx context.Context, token string, repository Repository, pr PullRequest) (string, error) {
+	if repository.Host != p.host {
+		return "", fmt.Errorf("repository %s/%s is not on %s, the GitHub host of the operator", repository.Host, repository, p.host)
+	}
+	owner, repo := repository.Owner, repository.Name
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	path := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + "/pulls"
+	body := map[string]string{"title": pr.Title, "head": pr.Head, "base": pr.Base, "body": pr.Body}
+	err := p.do(ctx, http.MethodPost, path, token, body, http.StatusCreated, &created)
+	if err == nil {
+		return created.HTMLURL, nil
+	}
+
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.code != http.StatusUnprocessableEntity {
+		return "", fmt.Errorf("opening a pull request in %s/%s: %w", owner, repo, err)
+	}
+	var existing []struct {
+		HTMLURL string `json:"html_url"`
+	}
+	query := url.Values{"head": {owner + ":" + pr.Head}, "base": {pr.Base}, "state": {"open"}}
+	if lookupErr := p.do(ctx, http.MethodGet, path+"?"+query.Encode(), token, nil, http.StatusOK, &existing); lookupErr != nil || len(existing) == 0 {
+		return "", fmt.Errorf("opening a pull request in %s/%s: %w", owner, repo, err)
+	}
+	return existing[0].HTMLURL, nil
+}
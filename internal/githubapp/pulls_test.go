@@ -0,0 +1,81 @@
+package githubapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenPullRequest(t *testing.T) {
+	var opened []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ghs_abc" {
+			t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/org/repo/pulls":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding body: %v", err)
+			}
+			opened = append(opened, body)
+			if body["head"] == "klaus/existing" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"message": "Validation Failed"}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"html_url": "https://github.com/org/repo/pull/1"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/org/repo/pulls":
+			if r.URL.Query().Get("head") != "org:klaus/existing" || r.URL.Query().Get("state") != "open" {
+				t.Errorf("lookup query = %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"html_url": "https://github.com/org/repo/pull/7"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewPullRequests(srv.URL)
+	repo := Repository{Host: WebHost(srv.URL), Owner: "org", Name: "repo"}
+	got, err := p.OpenPullRequest(context.Background(), "ghs_abc", repo,
+		PullRequest{Title: "klaus: fix", Head: "klaus/fix", Base: "main", Body: "done"})
+	if err != nil {
+		t.Fatalf("OpenPullRequest: %v", err)
+	}
+	if got != "https://github.com/org/repo/pull/1" {
+		t.Errorf("URL = %q", got)
+	}
+	if len(opened) != 1 || opened[0]["base"] != "main" || opened[0]["title"] != "klaus: fix" {
+		t.Errorf("opened = %v", opened)
+	}
+
+	// An open pull request for the branch is reused.
+	got, err = p.OpenPullRequest(context.Background(), "ghs_abc", repo,
+		PullRequest{Title: "klaus: again", Head: "klaus/existing", Base: "main"})
+	if err != nil || got != "https://github.com/org/repo/pull/7" {
+		t.Errorf("OpenPullRequest = %q, %v; want the existing pull request", got, err)
+	}
+
+	missing := repo
+	missing.Name = "missing"
+	_, err = p.OpenPullRequest(context.Background(), "ghs_abc", missing, PullRequest{Head: "klaus/fix", Base: "main"})
+	if err == nil || !strings.Contains(err.Error(), "Not Found") {
+		t.Errorf("error = %v, want the API message", err)
+	}
+
+	// The token is not sent to the API for repositories on other hosts.
+	elsewhere := repo
+	elsewhere.Host = "github.com"
+	if _, err := p.OpenPullRequest(context.Background(), "ghs_abc", elsewhere, PullRequest{Head: "klaus/fix", Base: "main"}); err == nil {
+		t.Error("OpenPullRequest for a repository on another host succeeded, want an error")
+	}
+	if len(opened) != 2 {
+		t.Errorf("opened %d pull requests, want no request for the other host", len(opened))
+	}
+}
@@ -0,0 +1,146 @@
+package githubapp
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Repository is a repository on a GitHub host.
+type Repository struct {
+	// Host is the web host of the repository, e.g. github.com.
+	Host string
+
+	// Owner is the user or organization owning the repository.
+	Owner string
+
+	// Name is the repository name.
+	Name string
+}
+
+// String returns owner/name.
+func (r Repository) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// ParseRepository returns the repository an HTTPS git URL points to, e.g.
+// github.com, org and repo for https://github.com/org/repo.git. URLs with
+// credentials are rejected: the operator supplies them.
+func ParseRepository(repoURL string) (Repository, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return Repository{}, fmt.Errorf("repository %q is not an HTTPS URL", repoURL)
+	}
+	if u.User != nil {
+		return Repository{}, fmt.Errorf("repository %q must not contain credentials", repoURL)
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return Repository{}, fmt.Errorf("repository %q does not name an owner and repository", repoURL)
+	}
+	return Repository{
+		Host:  strings.ToLower(u.Host),
+		Owner: segments[0],
+		Name:  strings.TrimSuffix(segments[1], ".git"),
+	}, nil
+}
+
+// WebHost returns the host serving the repositories of the REST API
+// endpoint baseURL: github.com for api.github.com, the subdomain of other
+// api. hosts such as GHE.com's api.example.ghe.com, and the host of GitHub
+// Enterprise Server endpoints like https://github.example.com/api/v3.
+func WebHost(baseURL string) string {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Host), "api.")
+}
+
+// RepositoryPolicy lists the repositories the operator's GitHub App may
+// mint installation tokens for, per instance owner. The app is typically
+// installed on whole organizations, so without it any user could have the
+// operator mint tokens for any repository of those organizations.
+type RepositoryPolicy struct {
+	// Rules allow owners to use repositories. A repository is allowed
+	// when any rule matching the owner allows it.
+	Rules []RepositoryRule `json:"rules"`
+}
+
+// RepositoryRule allows owners to use repositories. Both are path.Match
+// patterns, e.g. *@example.com and org/*.
+type RepositoryRule struct {
+	// Owners are the instance owners the rule applies to.
+	Owners []string `json:"owners"`
+
+	// Repositories are the owner/name repositories the owners may use.
+	Repositories []string `json:"repositories"`
+}
+
+// LoadRepositoryPolicy reads a YAML repository policy file. An empty path
+// returns nil: no repository is allowed.
+func LoadRepositoryPolicy(file string) (*RepositoryPolicy, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file) // #nosec G304 -- path is an operator flag
+	if err != nil {
+		return nil, fmt.Errorf("reading GitHub App repository policy: %w", err)
+	}
+	policy := &RepositoryPolicy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("parsing GitHub App repository policy %s: %w", file, err)
+	}
+	for i, rule := range policy.Rules {
+		if len(rule.Owners) == 0 || len(rule.Repositories) == 0 {
+			return nil, fmt.Errorf("GitHub App repository policy %s: rule %d needs owners and repositories", file, i)
+		}
+		for _, pattern := range append(rule.Owners, rule.Repositories...) {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return nil, fmt.Errorf("GitHub App repository policy %s: invalid pattern %q", file, pattern)
+			}
+		}
+		for _, pattern := range rule.Repositories {
+			if strings.Count(pattern, "/") != 1 {
+				return nil, fmt.Errorf("GitHub App repository policy %s: repository %q is not owner/name", file, pattern)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// Allows reports whether owner may use repository. A nil policy allows
+// nothing. Repositories are matched case-insensitively, as GitHub does.
+func (p *RepositoryPolicy) Allows(owner string, repository Repository) bool {
+	if p == nil {
+		return false
+	}
+	repo := strings.ToLower(repository.String())
+	for _, rule := range p.Rules {
+		if !matchAny(rule.Owners, owner) {
+			continue
+		}
+		for _, pattern := range rule.Repositories {
+			if ok, _ := path.Match(strings.ToLower(pattern), repo); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package githubapp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWebHost(t *testing.T) {
+	tests := map[string]string{
+		"":                                   "github.com",
+		"https://api.github.com":             "github.com",
+		"https://api.acme.ghe.com/":          "acme.ghe.com",
+		"https://github.example.com/api/v3":  "github.example.com",
+		"https://GitHub.Example.com/api/v3/": "github.example.com",
+	}
+	for baseURL, want := range tests {
+		if got := WebHost(baseURL); got != want {
+			t.Errorf("WebHost(%q) = %q, want %q", baseURL, got, want)
+		}
+	}
+}
+
+func TestRepositoryPolicy(t *testing.T) {
+	policy := &RepositoryPolicy{Rules: []RepositoryRule{
+		{Owners: []string{"*@example.com"}, Repositories: []string{"org/*"}},
+		{Owners: []string{"admin@example.com"}, Repositories: []string{"infra/deploy"}},
+	}}
+	tests := []struct {
+		owner string
+		repo  string
+		want  bool
+	}{
+		{owner: "user@example.com", repo: "org/repo", want: true},
+		{owner: "user@example.com", repo: "Org/Repo", want: true},
+		{owner: "user@example.com", repo: "infra/deploy"},
+		{owner: "admin@example.com", repo: "infra/deploy", want: true},
+		{owner: "user@other.example", repo: "org/repo"},
+	}
+	for _, tt := range tests {
+		owner, name, _ := strings.Cut(tt.repo, "/")
+		if got := policy.Allows(tt.owner, Repository{Host: "github.com", Owner: owner, Name: name}); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.owner, tt.repo, got, tt.want)
+		}
+	}
+
+	var none *RepositoryPolicy
+	if none.Allows("user@example.com", Repository{Host: "github.com", Owner: "org", Name: "repo"}) {
+		t.Error("a nil policy allowed a repository")
+	}
+}
+
+func TestLoadRepositoryPolicy(t *testing.T) {
+	if policy, err := LoadRepositoryPolicy(""); policy != nil || err != nil {
+		t.Errorf("LoadRepositoryPolicy(\"\") = %v, %v; want no policy", policy, err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "valid", content: "rules:\n- owners: ['*@example.com']\n  repositories: [org/*]\n"},
+		{name: "unknown field", content: "rules:\n- owner: ['*']\n  repositories: [org/*]\n", wantErr: true},
+		{name: "no repositories", content: "rules:\n- owners: ['*']\n", wantErr: true},
+		{name: "not owner/name", content: "rules:\n- owners: ['*']\n  repositories: [org]\n", wantErr: true},
+		{name: "bad pattern", content: "rules:\n- owners: ['[']\n  repositories: [org/*]\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadRepositoryPolicy(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadRepositoryPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
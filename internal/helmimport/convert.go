@@ -0,0 +1,309 @@
+package helmimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// specKeys are chart values that share their name and layout with a
+// KlausInstance spec field.
+var specKeys = []string{
+	"personality",
+	"claude",
+	"plugins",
+	"pluginDirs",
+	"skills",
+	"agentFiles",
+	"hooks",
+	"hookScripts",
+	"addDirs",
+	"loadAdditionalDirsMemory",
+	"workspace",
+	"resources",
+	"telemetry",
+	"muster",
+}
+
+// schedulingKeys are chart values that map to spec.scheduling.
+var schedulingKeys = []string{"affinity", "tolerations", "priorityClassName"}
+
+// ignoredKeys are chart values the operator manages itself; they have no
+// equivalent on a KlausInstance and are not reported as unmapped.
+var ignoredKeys = []string{
+	"nameOverride",
+	"fullnameOverride",
+	"replicaCount",
+	"serviceAccount",
+	"service",
+	"podAnnotations",
+	"podLabels",
+	"podSecurityContext",
+	"securityContext",
+}
+
+// Options configures the KlausInstance generated from a release.
+type Options struct {
+	// Name of the instance; defaults to the release name.
+	Name string
+	// Namespace of the instance, the operator namespace.
+	Namespace string
+	// Owner of the instance.
+	Owner string
+}
+
+// Result is a KlausInstance converted from a release.
+type Result struct {
+	// Instance is the generated KlausInstance.
+	Instance *klausv1alpha1.KlausInstance
+	// Unmapped lists the paths of set release values without an equivalent
+	// in the instance spec.
+	Unmapped []string
+	// PVCs lists the PersistentVolumeClaims of the release.
+	PVCs []string
+}
+
+// Convert generates a KlausInstance equivalent to a release of the
+// standalone Klaus chart.
+func Convert(release *Release, opts Options) (*Result, error) {
+	if opts.Owner == "" {
+		return nil, fmt.Errorf("owner is required")
+	}
+	name := opts.Name
+	if name == "" {
+		name = release.Name
+	}
+
+	values := release.Values()
+	spec := klausv1alpha1.KlausInstanceSpec{Owner: opts.Owner}
+	var unmapped []string
+
+	mapped := map[string]any{}
+	for _, key := range specKeys {
+		if value, ok := values[key]; ok {
+			mapped[key] = value
+		}
+	}
+	if err := decodeInto(mapped, &spec); err != nil {
+		return nil, fmt.Errorf("converting values: %w", err)
+	}
+
+	scheduling := map[string]any{}
+	for _, key := range schedulingKeys {
+		if value, ok := values[key]; ok {
+			scheduling[key] = value
+		}
+	}
+	if !isZero(scheduling) {
+		spec.Scheduling = &klausv1alpha1.SchedulingConfig{}
+		if err := decodeInto(scheduling, spec.Scheduling); err != nil {
+			return nil, fmt.Errorf("converting scheduling values: %w", err)
+		}
+	}
+
+	if value, ok := values["image"]; ok {
+		image, rest := convertImage(value)
+		spec.Image = image
+		unmapped = append(unmapped, prefixed("image", rest)...)
+	}
+	if value, ok := values["imagePullSecrets"]; ok {
+		secrets, rest := convertPullSecrets(value)
+		spec.ImagePullSecrets = secrets
+		unmapped = append(unmapped, rest...)
+	}
+
+	// Values lost when decoding into the typed spec are unknown fields.
+	var decoded map[string]any
+	if err := decodeInto(spec, &decoded); err != nil {
+		return nil, fmt.Errorf("encoding spec: %w", err)
+	}
+	if s, ok := decoded["scheduling"].(map[string]any); ok {
+		for _, key := range schedulingKeys {
+			decoded[key] = s[key]
+		}
+	}
+	for key, value := range values {
+		switch {
+		case slices.Contains(specKeys, key), slices.Contains(schedulingKeys, key):
+			unmapped = append(unmapped, missingPaths(key, value, decoded[key])...)
+		case key == "image", key == "imagePullSecrets", slices.Contains(ignoredKeys, key):
+		default:
+			if !isZero(value) {
+				unmapped = append(unmapped, key)
+			}
+		}
+	}
+	sort.Strings(unmapped)
+
+	return &Result{
+		Instance: &klausv1alpha1.KlausInstance{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: klausv1alpha1.GroupVersion.String(),
+				Kind:       "KlausInstance",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: opts.Namespace},
+			Spec:       spec,
+		},
+		Unmapped: unmapped,
+		PVCs:     release.ManifestPVCs(),
+	}, nil
+}
+
+// convertImage maps the chart image value, either a reference string or
+// {registry, repository, tag}, to an image reference. It returns the keys
+// it could not map.
+func convertImage(value any) (string, []string) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case map[string]any:
+		var rest []string
+		for key, field := range v {
+			if key != "registry" && key != "repository" && key != "tag" && key != "pullPolicy" && !isZero(field) {
+				rest = append(rest, key)
+			}
+		}
+		repository, _ := v["repository"].(string)
+		if repository == "" {
+			return "", append(rest, "repository")
+		}
+		if registry, _ := v["registry"].(string); registry != "" {
+			repository = registry + "/" + repository
+		}
+		if tag := scalarString(v["tag"]); tag != "" {
+			repository += ":" + tag
+		}
+		return repository, rest
+	}
+	if isZero(value) {
+		return "", nil
+	}
+	return "", []string{""}
+}
+
+// convertPullSecrets maps the chart imagePullSecrets value, a list of
+// names or of {name} references, to secret names.
+func convertPullSecrets(value any) ([]string, []string) {
+	items, ok := value.([]any)
+	if !ok {
+		if isZero(value) {
+			return nil, nil
+		}
+		return nil, []string{"imagePullSecrets"}
+	}
+	var names, rest []string
+	for i, item := range items {
+		switch v := item.(type) {
+		case string:
+			names = append(names, v)
+		case map[string]any:
+			if name, _ := v["name"].(string); name != "" {
+				names = append(names, name)
+				continue
+			}
+			rest = append(rest, fmt.Sprintf("imagePullSecrets[%d]", i))
+		default:
+			rest = append(rest, fmt.Sprintf("imagePullSecrets[%d]", i))
+		}
+	}
+	return names, rest
+}
+
+// missingPaths returns the paths of non-zero values in want that are
+// absent from, or differ in got.
+func missingPaths(path string, want, got any) []string {
+	if isZero(want) {
+		return nil
+	}
+	switch w := want.(type) {
+	case map[string]any:
+		g, _ := got.(map[string]any)
+		var paths []string
+		for key, value := range w {
+			paths = append(paths, missingPaths(path+"."+key, value, g[key])...)
+		}
+		return paths
+	case []any:
+		g, _ := got.([]any)
+		var paths []string
+		for i, value := range w {
+			var item any
+			if i < len(g) {
+				item = g[i]
+			}
+			paths = append(paths, missingPaths(path+"["+strconv.Itoa(i)+"]", value, item)...)
+		}
+		return paths
+	}
+	if reflect.DeepEqual(want, got) || scalarString(want) == scalarString(got) {
+		return nil
+	}
+	return []string{path}
+}
+
+// decodeInto converts in to out through its JSON encoding.
+func decodeInto(in, out any) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// isZero reports whether a decoded JSON value is null, empty or zero.
+func isZero(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		for _, field := range v {
+			if !isZero(field) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		return len(v) == 0
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	}
+	return false
+}
+
+// scalarString formats a decoded JSON scalar, so "1.0" given as a number
+// and as a string compare equal.
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// prefixed prepends key to the relative paths in rest.
+func prefixed(key string, rest []string) []string {
+	paths := make([]string, 0, len(rest))
+	for _, r := range rest {
+		if r == "" {
+			paths = append(paths, key)
+		} else {
+			paths = append(paths, key+"."+r)
+		}
+	}
+	return paths
+}
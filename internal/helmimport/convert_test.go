@@ -0,0 +1,98 @@
+package helmimport
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testRelease(values map[string]any) *Release {
+	release := &Release{Name: "agent", Namespace: "agents", Config: values}
+	release.Chart.Values = map[string]any{
+		"image":            map[string]any{"repository": "gsoci.azurecr.io/giantswarm/klaus", "tag": "1.2.0", "pullPolicy": "IfNotPresent"},
+		"replicaCount":     1,
+		"imagePullSecrets": []any{},
+		"claude":           map[string]any{"model": "sonnet", "permissionMode": "bypassPermissions"},
+	}
+	return release
+}
+
+func TestConvert(t *testing.T) {
+	release := testRelease(map[string]any{
+		"image":            map[string]any{"tag": "1.3.0"},
+		"imagePullSecrets": []any{map[string]any{"name": "regcred"}, "other"},
+		"claude": map[string]any{
+			"systemPrompt": "You review PRs.",
+			"maxBudgetUSD": 5,
+			"unknownFlag":  true,
+		},
+		"workspace":         map[string]any{"size": "10Gi", "gitRepo": "https://github.com/example/repo.git"},
+		"tolerations":       []any{map[string]any{"key": "agents", "operator": "Exists", "effect": "NoSchedule"}},
+		"priorityClassName": "agents",
+		"nodeSelector":      map[string]any{"pool": "agents"},
+		"serviceAccount":    map[string]any{"create": true},
+		"extraEnv":          []any{},
+	})
+
+	result, err := Convert(release, Options{Namespace: "klaus-system", Owner: "user@example.com"})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	instance := result.Instance
+	if instance.Name != "agent" || instance.Namespace != "klaus-system" || instance.Kind != "KlausInstance" {
+		t.Errorf("instance = %s %s/%s", instance.Kind, instance.Namespace, instance.Name)
+	}
+	spec := instance.Spec
+	if spec.Owner != "user@example.com" || spec.Image != "gsoci.azurecr.io/giantswarm/klaus:1.3.0" {
+		t.Errorf("owner/image = %q/%q", spec.Owner, spec.Image)
+	}
+	if !reflect.DeepEqual(spec.ImagePullSecrets, []string{"regcred", "other"}) {
+		t.Errorf("ImagePullSecrets = %v", spec.ImagePullSecrets)
+	}
+	if spec.Claude.Model != "sonnet" || spec.Claude.SystemPrompt != "You review PRs." || *spec.Claude.MaxBudgetUSD != 5 {
+		t.Errorf("claude = %+v", spec.Claude)
+	}
+	if spec.Workspace == nil || spec.Workspace.Size.String() != "10Gi" {
+		t.Errorf("workspace = %+v", spec.Workspace)
+	}
+	if spec.Scheduling == nil || spec.Scheduling.PriorityClassName != "agents" || len(spec.Scheduling.Tolerations) != 1 {
+		t.Errorf("scheduling = %+v", spec.Scheduling)
+	}
+
+	// Empty and chart-internal values are not reported.
+	want := []string{"claude.unknownFlag", "nodeSelector"}
+	if !reflect.DeepEqual(result.Unmapped, want) {
+		t.Errorf("Unmapped = %v, want %v", result.Unmapped, want)
+	}
+}
+
+func TestConvert_Options(t *testing.T) {
+	release := testRelease(map[string]any{"image": "registry.example.com/klaus:dev"})
+	release.Manifest = "kind: PersistentVolumeClaim\nmetadata:\n  name: agent-workspace\n"
+
+	result, err := Convert(release, Options{Name: "reviewer", Namespace: "klaus-system", Owner: "user@example.com"})
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if result.Instance.Name != "reviewer" || result.Instance.Spec.Image != "registry.example.com/klaus:dev" {
+		t.Errorf("instance = %s (%s)", result.Instance.Name, result.Instance.Spec.Image)
+	}
+	if result.Instance.Spec.Scheduling != nil {
+		t.Errorf("Scheduling = %+v, want nil without scheduling values", result.Instance.Spec.Scheduling)
+	}
+	if !reflect.DeepEqual(result.PVCs, []string{"agent-workspace"}) {
+		t.Errorf("PVCs = %v", result.PVCs)
+	}
+	if len(result.Unmapped) != 0 {
+		t.Errorf("Unmapped = %v, want none", result.Unmapped)
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	if _, err := Convert(testRelease(nil), Options{}); err == nil {
+		t.Error("expected an error without an owner")
+	}
+	release := testRelease(map[string]any{"claude": "opus"})
+	if _, err := Convert(release, Options{Owner: "user@example.com"}); err == nil {
+		t.Error("expected an error for values of the wrong type")
+	}
+}
@@ -0,0 +1,117 @@
+// Package helmimport converts releases of the standalone Klaus Helm chart
+// into KlausInstance specs, so teams can move existing agents under the
+// operator.
+package helmimport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ReleaseKey is the Secret data key holding the encoded Helm release.
+const ReleaseKey = "release"
+
+// gzipMagic prefixes gzip-compressed release payloads.
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// Release is the part of a Helm v3 release record the importer reads.
+type Release struct {
+	Name      string         `json:"name"`
+	Namespace string         `json:"namespace"`
+	Version   int            `json:"version"`
+	Config    map[string]any `json:"config,omitempty"`
+	Chart     struct {
+		Metadata struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"metadata"`
+		Values map[string]any `json:"values,omitempty"`
+	} `json:"chart"`
+	Manifest string `json:"manifest,omitempty"`
+}
+
+// DecodeRelease decodes the release stored by the Helm Secret storage
+// driver: base64-encoded, usually gzip-compressed, JSON.
+func DecodeRelease(data []byte) (*Release, error) {
+	raw, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding release: %w", err)
+	}
+	if bytes.HasPrefix(raw, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("decompressing release: %w", err)
+		}
+		defer func() { _ = zr.Close() }()
+		if raw, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("decompressing release: %w", err)
+		}
+	}
+	release := &Release{}
+	if err := json.Unmarshal(raw, release); err != nil {
+		return nil, fmt.Errorf("parsing release: %w", err)
+	}
+	return release, nil
+}
+
+// ChartRef returns the chart name and version of the release.
+func (r *Release) ChartRef() string {
+	return r.Chart.Metadata.Name + "-" + r.Chart.Metadata.Version
+}
+
+// Values returns the values the release was rendered with: the chart
+// defaults overridden by the user-supplied values, as Helm coalesces them.
+func (r *Release) Values() map[string]any {
+	return coalesce(maps.Clone(r.Chart.Values), r.Config)
+}
+
+// coalesce merges src into dst. Nested maps are merged, other values
+// replace those in dst, and a null value removes the key.
+func coalesce(dst, src map[string]any) map[string]any {
+	if dst == nil {
+		dst = map[string]any{}
+	}
+	for key, value := range src {
+		if value == nil {
+			delete(dst, key)
+			continue
+		}
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			dst[key] = coalesce(maps.Clone(dstMap), srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// ManifestPVCs returns the names of the PersistentVolumeClaims in the
+// rendered release manifest.
+func (r *Release) ManifestPVCs() []string {
+	var names []string
+	for _, doc := range strings.Split(r.Manifest, "\n---") {
+		var obj struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			continue
+		}
+		if obj.Kind == "PersistentVolumeClaim" && obj.Metadata.Name != "" {
+			names = append(names, obj.Metadata.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,99 @@
+package helmimport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// encodeRelease encodes a release as the Helm Secret storage driver does.
+func encodeRelease(t *testing.T, release map[string]any) []byte {
+	t.Helper()
+	data, err := json.Marshal(release)
+	if err != nil {
+		t.Fatalf("marshalling release: %v", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatalf("compressing release: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("compressing release: %v", err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+func TestDecodeRelease(t *testing.T) {
+	data := encodeRelease(t, map[string]any{
+		"name":      "agent",
+		"namespace": "agents",
+		"version":   3,
+		"chart": map[string]any{
+			"metadata": map[string]any{"name": "klaus", "version": "0.4.0"},
+			"values":   map[string]any{"claude": map[string]any{"model": "sonnet", "maxTurns": 10}},
+		},
+		"config": map[string]any{"claude": map[string]any{"model": "opus", "maxTurns": nil}},
+	})
+
+	release, err := DecodeRelease(data)
+	if err != nil {
+		t.Fatalf("DecodeRelease() error = %v", err)
+	}
+	if release.Name != "agent" || release.Namespace != "agents" || release.Version != 3 {
+		t.Errorf("release = %s/%s v%d", release.Namespace, release.Name, release.Version)
+	}
+	if got := release.ChartRef(); got != "klaus-0.4.0" {
+		t.Errorf("ChartRef() = %q, want klaus-0.4.0", got)
+	}
+	want := map[string]any{"claude": map[string]any{"model": "opus"}}
+	if got := release.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Values() = %v, want %v", got, want)
+	}
+	// Coalescing must not modify the chart defaults.
+	if release.Chart.Values["claude"].(map[string]any)["model"] != "sonnet" {
+		t.Errorf("chart values modified: %v", release.Chart.Values)
+	}
+}
+
+func TestDecodeRelease_Uncompressed(t *testing.T) {
+	data := []byte(base64.StdEncoding.EncodeToString([]byte(`{"name":"agent","version":1}`)))
+	release, err := DecodeRelease(data)
+	if err != nil {
+		t.Fatalf("DecodeRelease() error = %v", err)
+	}
+	if release.Name != "agent" {
+		t.Errorf("Name = %q, want agent", release.Name)
+	}
+}
+
+func TestDecodeRelease_Invalid(t *testing.T) {
+	if _, err := DecodeRelease([]byte("not base64!")); err == nil {
+		t.Error("expected an error for invalid base64")
+	}
+	if _, err := DecodeRelease([]byte(base64.StdEncoding.EncodeToString([]byte("{")))); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestManifestPVCs(t *testing.T) {
+	release := &Release{Manifest: `---
+# Source: klaus/templates/pvc.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: agent-klaus-workspace
+---
+# Source: klaus/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: agent-klaus
+`}
+	if got := release.ManifestPVCs(); !reflect.DeepEqual(got, []string{"agent-klaus-workspace"}) {
+		t.Errorf("ManifestPVCs() = %v", got)
+	}
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// handleAdminListInstances lists the instances of all owners, optionally
+// filtered by owner. Restricted to platform admins.
+func (s *Server) handleAdminListInstances(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	if _, err := s.extractUser(ctx); err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
+	if err := s.requireAdmin(ctx); err != nil {
+		return mcpError(err.Error()), nil
+	}
+	owner, _ := request.GetArguments()[keyOwner].(string)
+
+	var instanceList klausv1alpha1.KlausInstanceList
+	if err := s.client.List(ctx, &instanceList, client.InNamespace(s.operatorNamespace)); err != nil {
+		return mcpError("failed to list instances: " + err.Error()), nil
+	}
+
+	instances := []map[string]any{}
+	for i := range instanceList.Items {
+		inst := &instanceList.Items[i]
+		if owner != "" && inst.Spec.Owner != owner {
+			continue
+		}
+		item := map[string]any{
+			keyName:     inst.Name,
+			keyOwner:    inst.Spec.Owner,
+			"namespace": s.instanceNamespace(inst),
+			"state":     string(inst.Status.State),
+			"endpoint":  inst.Status.Endpoint,
+			keyMode:     inst.Status.Mode,
+			keyModel:    inst.Spec.Claude.Model,
+			"age":       time.Since(inst.CreationTimestamp.Time).Truncate(time.Second).String(),
+		}
+		if len(inst.Spec.Owners) > 0 {
+			item["owners"] = inst.Spec.Owners
+		}
+		if len(inst.Spec.OwnerGroups) > 0 {
+			item["ownerGroups"] = inst.Spec.OwnerGroups
+		}
+		if resources.IsStopped(inst) {
+			item["stopped"] = true
+		}
+		if !inst.DeletionTimestamp.IsZero() {
+			item["deleting"] = true
+		}
+		instances = append(instances, item)
+	}
+
+	return mcpSuccess(map[string]any{
+		"count":     len(instances),
+		"instances": instances,
+	}), nil
+}
+
+// handleAdminDeleteInstance deletes an instance of any owner. Restricted to
+// platform admins. The deletion is recorded in the provenance annotations
+// and as an event on the instance, so the owner can tell who removed it.
+func (s *Server) handleAdminDeleteInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	user, err := s.extractUser(ctx)
+	if err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
+	if err := s.requireAdmin(ctx); err != nil {
+		return mcpError(err.Error()), nil
+	}
+	name, _ := request.GetArguments()[keyName].(string)
+	if name == "" {
+		return mcpError("name is required"), nil
+	}
+
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(ctx, types.NamespacedName{Name: name, Namespace: s.operatorNamespace}, &instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			return mcpError("instance '" + name + "' not found"), nil
+		}
+		return mcpError("failed to get instance: " + err.Error()), nil
+	}
+
+	base := instance.DeepCopy()
+	s.stampProvenance(ctx, request, &instance)
+	if err := s.client.Patch(ctx, &instance, client.MergeFrom(base)); err != nil {
+		return mcpError("failed to record the deletion on the instance: " + err.Error()), nil
+	}
+	if s.recorder != nil {
+		s.recorder.Eventf(&instance, corev1.EventTypeWarning, "DeletedByAdmin",
+			"%s deleted the instance of %s through admin_delete_instance", user, instance.Spec.Owner)
+	}
+	if err := s.client.Delete(ctx, &instance); err != nil {
+		return mcpError("failed to delete instance: " + err.Error()), nil
+	}
+
+	return mcpSuccess(map[string]any{
+		keyName:    instance.Name,
+		keyOwner:   instance.Spec.Owner,
+		keyStatus:  "deleting",
+		keyMessage: "Instance '" + instance.Name + "' of " + instance.Spec.Owner + " is being deleted",
+	}), nil
+}
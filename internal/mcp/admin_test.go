@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func adminTestServer(t *testing.T) (*Server, *record.FakeRecorder) {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		&klausv1alpha1.KlausInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "alice-agent", Namespace: "klaus-system"},
+			Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "alice@example.com"},
+		},
+		&klausv1alpha1.KlausInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "bob-agent", Namespace: "klaus-system"},
+			Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "bob@example.com", Stopped: true},
+		},
+	).Build()
+	recorder := record.NewFakeRecorder(10)
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+	s.SetPermissionPolicy(testPermissionPolicy())
+	s.SetEventRecorder(recorder)
+	return s, recorder
+}
+
+func adminRequest(args map[string]any) mcpgolang.CallToolRequest {
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Name = "admin_delete_instance"
+	req.Params.Arguments = args
+	return req
+}
+
+func TestHandleAdminListInstances(t *testing.T) {
+	s, _ := adminTestServer(t)
+
+	result, err := s.handleAdminListInstances(groupsCtx(`["platform-admins"]`), adminRequest(map[string]any{}))
+	if err != nil || result.IsError {
+		t.Fatalf("handleAdminListInstances() = %v, %v", result.Content, err)
+	}
+	var data struct {
+		Count     int              `json:"count"`
+		Instances []map[string]any `json:"instances"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Count != 2 {
+		t.Fatalf("count = %d, want the instances of all owners", data.Count)
+	}
+	if data.Instances[1]["owner"] != "bob@example.com" || data.Instances[1]["stopped"] != true ||
+		data.Instances[1]["namespace"] != "klaus-user-bob-example-com" {
+		t.Errorf("instance = %v", data.Instances[1])
+	}
+
+	result, _ = s.handleAdminListInstances(groupsCtx(`["platform-admins"]`), adminRequest(map[string]any{"owner": "alice@example.com"}))
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Count != 1 || data.Instances[0]["name"] != "alice-agent" {
+		t.Errorf("instances = %v, want alice's only", data.Instances)
+	}
+}
+
+func TestHandleAdminDeleteInstance(t *testing.T) {
+	s, recorder := adminTestServer(t)
+
+	result, err := s.handleAdminDeleteInstance(groupsCtx(`["platform-admins"]`), adminRequest(map[string]any{"name": "alice-agent"}))
+	if err != nil || result.IsError {
+		t.Fatalf("handleAdminDeleteInstance() = %v, %v", result.Content, err)
+	}
+	err = s.client.Get(context.Background(), types.NamespacedName{Name: "alice-agent", Namespace: "klaus-system"}, &klausv1alpha1.KlausInstance{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected the instance to be deleted, got %v", err)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "DeletedByAdmin") ||
+		!strings.Contains(event, "user@example.com deleted the instance of alice@example.com") {
+		t.Errorf("event = %q", event)
+	}
+}
+
+func TestAdminTools_RequireAdmin(t *testing.T) {
+	s, _ := adminTestServer(t)
+
+	handlers := map[string]func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error){
+		"admin_list_instances":  s.handleAdminListInstances,
+		"admin_delete_instance": s.handleAdminDeleteInstance,
+	}
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			result, err := handler(groupsCtx(`["platform"]`), adminRequest(map[string]any{"name": "alice-agent"}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "restricted to platform admins") {
+				t.Errorf("result = %v, want access denied", result.Content)
+			}
+		})
+	}
+	if err := s.client.Get(context.Background(), types.NamespacedName{Name: "alice-agent", Namespace: "klaus-system"}, &klausv1alpha1.KlausInstance{}); err != nil {
+		t.Errorf("expected the instance to be kept: %v", err)
+	}
+}
+
+func TestRequireAdmin_AdminUsers(t *testing.T) {
+	s, _ := adminTestServer(t)
+	if err := s.requireAdmin(authCtx("ops@example.com")); err == nil {
+		t.Fatal("expected a caller without admin group to be rejected")
+	}
+	s.SetAdminUsers([]string{"ops@example.com"})
+	if err := s.requireAdmin(authCtx("ops@example.com")); err != nil {
+		t.Errorf("requireAdmin() error = %v, want admin users accepted", err)
+	}
+	if err := s.requireAdmin(authCtx("other@example.com")); err == nil {
+		t.Error("expected other users to be rejected")
+	}
+}
@@ -3,9 +3,11 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	mcpgolang "github.com/mark3labs/mcp-go/mcp"
 
@@ -18,6 +20,8 @@ type promptResult struct {
 	Status    string `json:"status"`
 	SessionID string `json:"session_id,omitempty"`
 	Result    string `json:"result,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Message   string `json:"message,omitempty"`
 }
 
 const (
@@ -25,10 +29,15 @@ const (
 	maxMessageBytes = 1 << 20 // 1 MiB
 	// maxBlockingWait caps how long a blocking prompt will wait for a result.
 	maxBlockingWait = 10 * time.Minute
+	// maxResponseBytes caps the result returned by prompt_instance. Callers
+	// can lower it with max_response_bytes to protect their context window.
+	maxResponseBytes = 1 << 20 // 1 MiB
 )
 
 // handlePromptInstance sends a prompt to a running agent instance and
-// optionally waits for the result.
+// optionally waits for the result. timeout_seconds bounds the whole call; a
+// blocking prompt that times out reports the agent as still running rather
+// than failing, so the result can be fetched later with get_result.
 func (s *Server) handlePromptInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
 	instance, errResult := s.getOwnedInstance(ctx, request)
 	if errResult != nil {
@@ -50,6 +59,22 @@ func (s *Server) handlePromptInstance(ctx context.Context, request mcpgolang.Cal
 		blocking = v
 	}
 
+	timeout := maxBlockingWait
+	if v, ok := args["timeout_seconds"].(float64); ok {
+		if v <= 0 {
+			return mcpError("timeout_seconds must be positive"), nil
+		}
+		timeout = min(time.Duration(v*float64(time.Second)), maxBlockingWait)
+	}
+
+	maxBytes := maxResponseBytes
+	if v, ok := args["max_response_bytes"].(float64); ok {
+		if v < 1 {
+			return mcpError("max_response_bytes must be positive"), nil
+		}
+		maxBytes = min(int(v), maxResponseBytes)
+	}
+
 	baseURL, errResult := s.agentBaseURL(instance)
 	if errResult != nil {
 		return errResult, nil
@@ -59,36 +84,84 @@ func (s *Server) handlePromptInstance(ctx context.Context, request mcpgolang.Cal
 		return mcpError("agent MCP client not configured"), nil
 	}
 
-	toolResult, err := s.agentClient.Prompt(ctx, instance.Name, baseURL, message)
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	toolResult, err := s.agentClient.Prompt(callCtx, instance.Name, baseURL, message)
 	if err != nil {
 		return mcpError(fmt.Sprintf("sending prompt to %q: %v", instance.Name, err)), nil
 	}
 
 	if !blocking {
+		result, truncated := truncateUTF8(extractText(toolResult), maxBytes)
 		return mcpSuccess(promptResult{
 			Instance:  instance.Name,
 			Status:    statusStarted,
 			SessionID: s.agentClient.SessionID(instance.Name),
-			Result:    extractText(toolResult),
+			Result:    result,
+			Truncated: truncated,
 		}), nil
 	}
 
-	pollCtx, cancel := context.WithTimeout(ctx, maxBlockingWait)
-	defer cancel()
-
-	result, err := s.waitForResult(pollCtx, instance.Name, baseURL)
+	text, err := s.waitForResult(callCtx, instance.Name, baseURL)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return mcpSuccess(promptResult{
+			Instance:  instance.Name,
+			Status:    statusRunning,
+			SessionID: s.agentClient.SessionID(instance.Name),
+			Message:   fmt.Sprintf("no result within %s; the agent is still working, use get_result to fetch it", timeout),
+		}), nil
+	}
 	if err != nil {
 		return mcpError(fmt.Sprintf("waiting for result from %q: %v", instance.Name, err)), nil
 	}
+	s.recordResult(ctx, instance.Name, text)
 
+	result, truncated := truncateUTF8(text, maxBytes)
 	return mcpSuccess(promptResult{
 		Instance:  instance.Name,
 		Status:    statusCompleted,
 		SessionID: s.agentClient.SessionID(instance.Name),
 		Result:    result,
+		Truncated: truncated,
 	}), nil
 }
 
+// SendPrompt sends a prompt to a running agent instance without waiting for
+// the result. It lets KlausTriggers prompt instances.
+func (s *Server) SendPrompt(ctx context.Context, instance *klausv1alpha1.KlausInstance, message string) error {
+	if len(message) > maxMessageBytes {
+		return errors.New("message exceeds maximum size (1 MiB)")
+	}
+	baseURL, errResult := s.agentBaseURL(instance)
+	if errResult != nil {
+		return errors.New(extractText(errResult))
+	}
+	if s.agentClient == nil {
+		return errors.New("agent MCP client not configured")
+	}
+	toolResult, err := s.agentClient.Prompt(ctx, instance.Name, baseURL, message)
+	if err != nil {
+		return fmt.Errorf("sending prompt to %q: %w", instance.Name, err)
+	}
+	if toolResult != nil && toolResult.IsError {
+		return fmt.Errorf("agent %q rejected the prompt: %s", instance.Name, extractText(toolResult))
+	}
+	return nil
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a UTF-8 sequence
+// and reports whether it was cut.
+func truncateUTF8(s string, n int) (string, bool) {
+	if len(s) <= n {
+		return s, false
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n], true
+}
+
 // agentResult is the JSON structure returned by handleGetResult.
 type agentResult struct {
 	Instance     string `json:"instance"`
@@ -152,6 +225,7 @@ func (s *Server) handleGetResult(ctx context.Context, request mcpgolang.CallTool
 	}
 
 	text := extractText(toolResult)
+	s.recordResult(ctx, instance.Name, text)
 
 	var parsed agentToolResponse
 	if err := json.Unmarshal([]byte(text), &parsed); err == nil && parsed.Status != "" {
@@ -177,10 +251,22 @@ func (s *Server) agentBaseURL(instance *klausv1alpha1.KlausInstance) (string, *m
 	if instance.Status.State != klausv1alpha1.InstanceStateRunning {
 		return "", mcpError(fmt.Sprintf("instance %q is not running (state: %s)", instance.Name, instance.Status.State))
 	}
-	if instance.Status.Endpoint == "" {
+	endpoint := internalEndpoint(instance)
+	if endpoint == "" {
 		return "", mcpError(fmt.Sprintf("instance %q has no endpoint yet", instance.Name))
 	}
-	return instance.Status.Endpoint + "/mcp", nil
+	return endpoint + "/mcp", nil
+}
+
+// internalEndpoint returns the in-cluster service URL of an instance. The
+// operator always talks to agents through the Service, even when
+// status.endpoint reports an external URL. Instances reconciled by older
+// operator versions only have status.endpoint.
+func internalEndpoint(instance *klausv1alpha1.KlausInstance) string {
+	if instance.Status.ServiceEndpoint != "" {
+		return instance.Status.ServiceEndpoint
+	}
+	return instance.Status.Endpoint
 }
 
 // terminalStatuses are the agent statuses that indicate the task is done.
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -66,6 +67,9 @@ func testScheme(t *testing.T) *runtime.Scheme {
 	if err := klausv1alpha1.AddToScheme(scheme); err != nil {
 		t.Fatalf("failed to add scheme: %v", err)
 	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 scheme: %v", err)
+	}
 	return scheme
 }
 
@@ -310,6 +314,108 @@ func TestHandlePromptInstance_PromptError(t *testing.T) {
 	}
 }
 
+func promptTestServer(t *testing.T, agent *fakeAgentMCPClient) *Server {
+	t.Helper()
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance).Build()
+	return &Server{client: c, operatorNamespace: "klaus-system", agentClient: agent}
+}
+
+func callPromptInstance(t *testing.T, s *Server, args map[string]any) (promptResult, string) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	args["name"] = "my-agent"
+	args["message"] = "hello"
+	req.Params.Arguments = args
+	result, err := s.handlePromptInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError {
+		return promptResult{}, text
+	}
+	var data promptResult
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	return data, ""
+}
+
+func TestHandlePromptInstance_MaxResponseBytes(t *testing.T) {
+	s := promptTestServer(t, &fakeAgentMCPClient{promptResult: textResult("héllo world")})
+
+	data, errText := callPromptInstance(t, s, map[string]any{"max_response_bytes": float64(2)})
+	if errText != "" {
+		t.Fatalf("unexpected MCP error: %s", errText)
+	}
+	// "h" plus the first byte of "é" would split the rune, so only "h" remains.
+	if data.Result != "h" || !data.Truncated {
+		t.Errorf("result = %q, truncated = %v; want %q truncated", data.Result, data.Truncated, "h")
+	}
+
+	data, _ = callPromptInstance(t, s, map[string]any{})
+	if data.Result != "héllo world" || data.Truncated {
+		t.Errorf("result = %q, truncated = %v; want the full result", data.Result, data.Truncated)
+	}
+}
+
+func TestHandlePromptInstance_BlockingTimeout(t *testing.T) {
+	s := promptTestServer(t, &fakeAgentMCPClient{
+		promptResult: textResult("prompt accepted"),
+		statusResult: textResult(`{"status":"busy"}`),
+		sessionID:    "sess-123",
+	})
+
+	data, errText := callPromptInstance(t, s, map[string]any{"blocking": true, "timeout_seconds": 0.05})
+	if errText != "" {
+		t.Fatalf("unexpected MCP error: %s", errText)
+	}
+	if data.Status != "running" || data.SessionID != "sess-123" || !strings.Contains(data.Message, "get_result") {
+		t.Errorf("response = %+v, want status running with a get_result hint", data)
+	}
+}
+
+func TestHandlePromptInstance_InvalidControls(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "zero timeout", args: map[string]any{"timeout_seconds": float64(0)}, wantErr: "timeout_seconds must be positive"},
+		{name: "zero max bytes", args: map[string]any{"max_response_bytes": float64(0)}, wantErr: "max_response_bytes must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := promptTestServer(t, &fakeAgentMCPClient{promptResult: textResult("ok")})
+			if _, errText := callPromptInstance(t, s, tt.args); !strings.Contains(errText, tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", errText, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		in            string
+		n             int
+		want          string
+		wantTruncated bool
+	}{
+		{in: "hello", n: 10, want: "hello"},
+		{in: "hello", n: 5, want: "hello"},
+		{in: "hello", n: 3, want: "hel", wantTruncated: true},
+		{in: "日本", n: 4, want: "日", wantTruncated: true},
+		{in: "日本", n: 2, want: "", wantTruncated: true},
+	}
+	for _, tt := range tests {
+		got, truncated := truncateUTF8(tt.in, tt.n)
+		if got != tt.want || truncated != tt.wantTruncated {
+			t.Errorf("truncateUTF8(%q, %d) = %q, %v; want %q, %v", tt.in, tt.n, got, truncated, tt.want, tt.wantTruncated)
+		}
+	}
+}
+
 // --- get_result tests ---
 
 func TestHandleGetResult_Summary(t *testing.T) {
@@ -582,6 +688,20 @@ func TestAgentBaseURL_Running(t *testing.T) {
 	}
 }
 
+func TestAgentBaseURL_PrefersServiceEndpoint(t *testing.T) {
+	s := &Server{}
+	instance := runningInstance("test", "user@example.com", "https://klaus.example.com")
+	instance.Status.ServiceEndpoint = "http://test.klaus:8080"
+
+	url, errResult := s.agentBaseURL(instance)
+	if errResult != nil {
+		t.Fatalf("unexpected error: %s", errResult.Content[0].(mcpgolang.TextContent).Text)
+	}
+	if url != "http://test.klaus:8080/mcp" {
+		t.Errorf("url = %q, want the in-cluster endpoint", url)
+	}
+}
+
 func TestAgentBaseURL_NotRunning(t *testing.T) {
 	s := &Server{}
 	instance := &klausv1alpha1.KlausInstance{
@@ -693,3 +813,26 @@ func TestParseStatusField(t *testing.T) {
 		})
 	}
 }
+
+func TestSendPrompt(t *testing.T) {
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	agent := &fakeAgentMCPClient{promptResult: textResult("prompt accepted")}
+	s := &Server{operatorNamespace: "klaus-system", agentClient: agent}
+
+	if err := s.SendPrompt(context.Background(), instance, "check the alert"); err != nil {
+		t.Fatalf("SendPrompt: %v", err)
+	}
+	if agent.lastPromptMessage != "check the alert" || agent.lastBaseURL != "http://my-agent.klaus:8080/mcp" {
+		t.Errorf("prompt = %q to %q", agent.lastPromptMessage, agent.lastBaseURL)
+	}
+
+	agent.promptResult = errorResult("busy")
+	if err := s.SendPrompt(context.Background(), instance, "again"); err == nil || !strings.Contains(err.Error(), "busy") {
+		t.Errorf("error = %v, want the agent rejection", err)
+	}
+
+	instance.Status.State = klausv1alpha1.InstanceStateStopped
+	if err := s.SendPrompt(context.Background(), instance, "again"); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("error = %v, want not running", err)
+	}
+}
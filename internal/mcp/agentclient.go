@@ -2,11 +2,21 @@ package mcp
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 
 	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
 )
 
 // AgentMCPClient communicates with the MCP endpoint running inside a klaus
@@ -20,17 +30,44 @@ type AgentMCPClient interface {
 	Close()
 }
 
+// TLSConfigFunc returns the client TLS configuration for calling an instance
+// served over mTLS.
+type TLSConfigFunc func(ctx context.Context, instanceName string) (*tls.Config, error)
+
 // agentMCPClient is the production AgentMCPClient backed by mcp-go's
 // StreamableHttpClient with per-instance session caching.
 type agentMCPClient struct {
-	mu       sync.Mutex
-	sessions map[string]*mcpclient.Client
+	mu        sync.Mutex
+	sessions  map[string]*mcpclient.Client
+	tlsConfig TLSConfigFunc
 }
 
 // NewAgentMCPClient creates a new AgentMCPClient with session caching.
-func NewAgentMCPClient() AgentMCPClient {
+// tlsConfig is consulted for https endpoints; it may be nil when no instance
+// is served over mTLS.
+func NewAgentMCPClient(tlsConfig TLSConfigFunc) AgentMCPClient {
 	return &agentMCPClient{
-		sessions: make(map[string]*mcpclient.Client),
+		sessions:  make(map[string]*mcpclient.Client),
+		tlsConfig: tlsConfig,
+	}
+}
+
+// NewInstanceTLSConfigFunc returns a TLSConfigFunc that loads the client
+// certificate of an instance in namespace from its Secret in the muster
+// namespace. namespaceScoped must match the operator mode, so the expected
+// server name matches the namespace the instance runs in.
+func NewInstanceTLSConfigFunc(c client.Reader, namespace string, namespaceScoped bool) TLSConfigFunc {
+	return func(ctx context.Context, instanceName string) (*tls.Config, error) {
+		instance := &klausv1alpha1.KlausInstance{}
+		if err := c.Get(ctx, types.NamespacedName{Name: instanceName, Namespace: namespace}, instance); err != nil {
+			return nil, fmt.Errorf("fetching instance %s: %w", instanceName, err)
+		}
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Name: resources.ClientCertSecretName(instance), Namespace: resources.MusterNamespace(instance)}
+		if err := c.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("fetching client certificate secret %s: %w", key, err)
+		}
+		return resources.ClientTLSConfig(instance, resources.InstanceNamespace(instance, namespaceScoped), secret)
 	}
 }
 
@@ -51,7 +88,18 @@ func (c *agentMCPClient) getOrCreateSession(ctx context.Context, instanceName, b
 		c.mu.Unlock()
 	}
 
-	mc, err := mcpclient.NewStreamableHttpClient(baseURL)
+	var opts []transport.StreamableHTTPCOption
+	if strings.HasPrefix(baseURL, "https://") && c.tlsConfig != nil {
+		tlsConfig, err := c.tlsConfig(ctx, instanceName)
+		if err != nil {
+			return nil, fmt.Errorf("loading TLS configuration for %s: %w", instanceName, err)
+		}
+		httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+		httpTransport.TLSClientConfig = tlsConfig
+		opts = append(opts, transport.WithHTTPBasicClient(&http.Client{Transport: httpTransport}))
+	}
+
+	mc, err := mcpclient.NewStreamableHttpClient(baseURL, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("creating MCP client for %s: %w", baseURL, err)
 	}
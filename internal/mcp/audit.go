@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/audit"
+)
+
+// anonymousActor is the audited actor of calls without a valid identity.
+const anonymousActor = "anonymous"
+
+// maxAuditMessage bounds the result text recorded for failed calls.
+const maxAuditMessage = 512
+
+// eventedTools change an instance without emitting an event of their own.
+// The audit middleware emits an MCPToolCalled event on the instance when
+// they succeed; update_instance, admin_delete_instance and
+// import_helm_release record their own events.
+var eventedTools = map[string]bool{
+	"create_instance":      true,
+	"delete_instance":      true,
+	"restart_instance":     true,
+	"stop_instance":        true,
+	"start_instance":       true,
+	"run_instance":         true,
+	"workspace_pull":       true,
+	"workspace_reset":      true,
+	"scaffold_personality": true,
+	"clone_instance":       true,
+}
+
+// SetAuditLogger sets the logger recording every tool call in the audit
+// stream. Without a logger tool calls are only audited through the events
+// on the instances they change.
+func (s *Server) SetAuditLogger(logger *audit.Logger) {
+	s.auditLogger = logger
+}
+
+// auditTools is a tool handler middleware recording who called which tool
+// on which instance, and with what result.
+func (s *Server) auditTools() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+			result, err := next(ctx, request)
+
+			actor, authErr := s.extractUser(ctx)
+			if authErr != nil {
+				actor = anonymousActor
+			}
+			tool := request.Params.Name
+			name, _ := request.GetArguments()[keyName].(string)
+
+			record := audit.Record{
+				Source: audit.SourceMCP,
+				Actor:  actor,
+				Action: tool,
+				Result: audit.ResultSuccess,
+			}
+			if name != "" {
+				record.Resource = &audit.Resource{Kind: "KlausInstance", Namespace: s.operatorNamespace, Name: name}
+			}
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				record.Session = session.SessionID()
+			}
+			switch {
+			case err != nil:
+				record.Result, record.Message = audit.ResultFailure, err.Error()
+			case result != nil && result.IsError:
+				record.Result, record.Message = audit.ResultFailure, resultText(result)
+			}
+			s.auditLogger.Record(record)
+
+			if record.Result == audit.ResultSuccess && authErr == nil && name != "" && eventedTools[tool] {
+				s.toolEvent(ctx, name, actor, tool)
+			}
+			return result, err
+		}
+	}
+}
+
+// toolEvent emits an MCPToolCalled event on the named instance, if it
+// still exists.
+func (s *Server) toolEvent(ctx context.Context, name, actor, tool string) {
+	if s.recorder == nil {
+		return
+	}
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(ctx, types.NamespacedName{Name: name, Namespace: s.operatorNamespace}, &instance); err != nil {
+		return
+	}
+	s.recorder.Eventf(&instance, corev1.EventTypeNormal, "MCPToolCalled", "%s called %s", actor, tool)
+}
+
+// resultText returns the first text of a result, truncated to
+// maxAuditMessage.
+func resultText(result *mcpgolang.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcpgolang.TextContent); ok {
+			message := strings.TrimSpace(text.Text)
+			if len(message) > maxAuditMessage {
+				message = message[:maxAuditMessage] + "..."
+			}
+			return message
+		}
+	}
+	return ""
+}
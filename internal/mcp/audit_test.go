@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/audit"
+)
+
+// auditedCall runs handler for tool through the audit middleware and returns
+// the audit records written and the events emitted.
+func auditedCall(t *testing.T, ctx context.Context, tool string, args map[string]any, handler func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error)) ([]audit.Record, []string) {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(&klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}).Build()
+	recorder := record.NewFakeRecorder(10)
+	var buf bytes.Buffer
+	logger := audit.NewLogger(audit.NewWriterSink(&buf))
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+	s.SetEventRecorder(recorder)
+	s.SetAuditLogger(logger)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Name = tool
+	req.Params.Arguments = args
+	_, _ = s.auditTools()(handler)(ctx, req)
+
+	stopped, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = logger.Start(stopped)
+	var records []audit.Record
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var r audit.Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			t.Fatalf("audit line is not JSON: %v", err)
+		}
+		records = append(records, r)
+	}
+	close(recorder.Events)
+	var events []string
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	return records, events
+}
+
+func TestAuditToolsSuccess(t *testing.T) {
+	handler := func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return mcpSuccess(map[string]any{keyStatus: "restarting"}), nil
+	}
+	records, events := auditedCall(t, authCtx("user@example.com"), "restart_instance", map[string]any{keyName: "agent"}, handler)
+
+	if len(records) != 1 {
+		t.Fatalf("recorded %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r.Source != audit.SourceMCP || r.Actor != "user@example.com" || r.Action != "restart_instance" || r.Result != audit.ResultSuccess {
+		t.Errorf("record = %+v", r)
+	}
+	if r.Resource == nil || r.Resource.Kind != "KlausInstance" || r.Resource.Namespace != "klaus-system" || r.Resource.Name != "agent" {
+		t.Errorf("resource = %+v, want the instance", r.Resource)
+	}
+	if len(events) != 1 || !strings.Contains(events[0], "MCPToolCalled user@example.com called restart_instance") {
+		t.Errorf("events = %v, want an MCPToolCalled event", events)
+	}
+}
+
+func TestAuditToolsFailure(t *testing.T) {
+	handler := func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return mcpError("instance 'agent' is not owned by you"), nil
+	}
+	records, events := auditedCall(t, authCtx("mallory@example.com"), "delete_instance", map[string]any{keyName: "agent"}, handler)
+
+	if len(records) != 1 || records[0].Result != audit.ResultFailure || records[0].Message != "instance 'agent' is not owned by you" {
+		t.Fatalf("records = %+v, want a failure with the error text", records)
+	}
+	if len(events) != 0 {
+		t.Errorf("events = %v, want none for a failed call", events)
+	}
+}
+
+func TestAuditToolsHandlerError(t *testing.T) {
+	handler := func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return nil, errors.New("connection reset")
+	}
+	records, _ := auditedCall(t, authCtx("user@example.com"), "get_instance", map[string]any{keyName: "agent"}, handler)
+
+	if len(records) != 1 || records[0].Result != audit.ResultFailure || records[0].Message != "connection reset" {
+		t.Errorf("records = %+v, want the handler error", records)
+	}
+}
+
+func TestAuditToolsAnonymous(t *testing.T) {
+	handler := func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return mcpError("authentication required: no Authorization header in request"), nil
+	}
+	records, _ := auditedCall(t, context.Background(), "list_instances", nil, handler)
+
+	if len(records) != 1 || records[0].Actor != anonymousActor || records[0].Resource != nil {
+		t.Errorf("records = %+v, want an anonymous call without resource", records)
+	}
+}
+
+func TestAuditToolsReadOnlyToolEmitsNoEvent(t *testing.T) {
+	handler := func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return mcpSuccess(map[string]any{keyName: "agent"}), nil
+	}
+	records, events := auditedCall(t, authCtx("user@example.com"), "get_instance", map[string]any{keyName: "agent"}, handler)
+
+	if len(records) != 1 {
+		t.Fatalf("recorded %d records, want read-only calls audited too", len(records))
+	}
+	if len(events) != 0 {
+		t.Errorf("events = %v, want none for get_instance", events)
+	}
+}
+
+func TestAuditToolsWithoutLogger(t *testing.T) {
+	s := &Server{operatorNamespace: "klaus-system"}
+	handler := func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return mcpSuccess(map[string]any{}), nil
+	}
+	result, err := s.auditTools()(handler)(authCtx("user@example.com"), mcpgolang.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Errorf("auditTools() = %v, %v, want the handler result", result, err)
+	}
+}
+
+func TestResultTextTruncates(t *testing.T) {
+	if got := resultText(mcpError(strings.Repeat("x", 2*maxAuditMessage))); len(got) != maxAuditMessage+3 {
+		t.Errorf("len(resultText()) = %d, want %d", len(got), maxAuditMessage+3)
+	}
+}
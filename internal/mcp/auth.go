@@ -7,6 +7,11 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/klaus-operator/internal/oidc"
 )
 
 // contextKey is a private type for context keys in this package.
@@ -15,6 +20,9 @@ type contextKey int
 const (
 	// authTokenKey is the context key for the Authorization header value.
 	authTokenKey contextKey = iota
+	// callerKey is the context key of the caller verified by
+	// identifyCaller.
+	callerKey
 )
 
 // HTTPContextFuncAuth extracts the Authorization header from the incoming HTTP
@@ -36,29 +44,84 @@ func AuthTokenFromContext(ctx context.Context) string {
 	return ""
 }
 
-// ExtractUserFromToken extracts the user identity (email or subject) from a
-// JWT token forwarded by muster. This does not verify the token -- verification
-// is handled by muster before forwarding.
-func ExtractUserFromToken(token string) (string, error) {
-	if token == "" {
-		return "", fmt.Errorf("no token provided")
+// TokenVerifier verifies the token of a caller and maps its claims to the
+// caller identity. *oidc.Verifier implements it.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*oidc.Identity, error)
+}
+
+// SetTokenVerifier makes the MCP server verify the signature, issuer,
+// audience and expiry of caller tokens instead of trusting muster to have
+// done so. Without a verifier the token payload is decoded unverified.
+func (s *Server) SetTokenVerifier(verifier TokenVerifier) {
+	s.tokenVerifier = verifier
+}
+
+// rejectingVerifier is the TokenVerifier of a server that can verify no
+// tokens.
+type rejectingVerifier struct{ err error }
+
+func (v rejectingVerifier) Verify(context.Context, string) (*oidc.Identity, error) {
+	return nil, v.err
+}
+
+// RejectTokens returns a TokenVerifier rejecting every token with err, for
+// servers configured neither to verify tokens nor to trust a gateway.
+func RejectTokens(err error) TokenVerifier {
+	return rejectingVerifier{err: err}
+}
+
+// caller is the outcome of verifying the token of a tool call.
+type caller struct {
+	identity *oidc.Identity
+	err      error
+}
+
+// identifyCaller is a tool handler middleware verifying the caller token
+// once per tool call and storing the outcome in the context, so the audit
+// and rate limiting middlewares and the tool handlers do not verify it
+// again.
+func (s *Server) identifyCaller() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+			if s.tokenVerifier == nil {
+				return next(ctx, request)
+			}
+			identity, err := s.verifyCaller(ctx)
+			return next(context.WithValue(ctx, callerKey, &caller{identity: identity, err: err}), request)
+		}
 	}
+}
 
-	// Strip "Bearer " prefix (case-insensitive per RFC 6750).
-	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
-		token = token[7:]
+// callerIdentity returns the verified identity of the caller, verified by
+// identifyCaller unless called outside a tool call.
+func (s *Server) callerIdentity(ctx context.Context) (*oidc.Identity, error) {
+	if c, ok := ctx.Value(callerKey).(*caller); ok {
+		return c.identity, c.err
 	}
+	return s.verifyCaller(ctx)
+}
 
-	// JWT has three parts separated by dots.
-	parts := strings.Split(token, ".")
-	if len(parts) != 3 {
-		return "", fmt.Errorf("invalid JWT format: expected 3 parts, got %d", len(parts))
+// verifyCaller verifies the token of the caller.
+func (s *Server) verifyCaller(ctx context.Context) (*oidc.Identity, error) {
+	token := AuthTokenFromContext(ctx)
+	if token == "" {
+		return nil, fmt.Errorf("no Authorization header in request")
+	}
+	identity, err := s.tokenVerifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("verifying token: %w", err)
 	}
+	return identity, nil
+}
 
-	// Decode the payload (second part).
-	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+// ExtractUserFromToken extracts the user identity (email or subject) from a
+// JWT token forwarded by muster. This does not verify the token -- verification
+// is handled by muster before forwarding.
+func ExtractUserFromToken(token string) (string, error) {
+	payload, err := tokenPayload(token)
 	if err != nil {
-		return "", fmt.Errorf("decoding JWT payload: %w", err)
+		return "", err
 	}
 
 	// Parse claims.
@@ -80,3 +143,61 @@ func ExtractUserFromToken(token string) (string, error) {
 
 	return "", fmt.Errorf("JWT contains neither email nor sub claim")
 }
+
+// ExtractGroupsFromToken returns the string values of the groups claim of a
+// JWT token forwarded by muster. A missing claim yields no groups. Like
+// ExtractUserFromToken, the token is not verified.
+func ExtractGroupsFromToken(token, claim string) ([]string, error) {
+	payload, err := tokenPayload(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("parsing JWT claims: %w", err)
+	}
+
+	switch v := claims[claim].(type) {
+	case []any:
+		groups := make([]string, 0, len(v))
+		for _, item := range v {
+			if g, ok := item.(string); ok && g != "" {
+				groups = append(groups, g)
+			}
+		}
+		return groups, nil
+	case string:
+		// Some identity providers emit a single group as a string.
+		if v != "" {
+			return []string{v}, nil
+		}
+	}
+	return nil, nil
+}
+
+// tokenPayload returns the decoded payload of a JWT token, with or without a
+// "Bearer " prefix.
+func tokenPayload(token string) ([]byte, error) {
+	if token == "" {
+		return nil, fmt.Errorf("no token provided")
+	}
+
+	// Strip "Bearer " prefix (case-insensitive per RFC 6750).
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = token[7:]
+	}
+
+	// JWT has three parts separated by dots.
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT format: expected 3 parts, got %d", len(parts))
+	}
+
+	// Decode the payload (second part).
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWT payload: %w", err)
+	}
+	return payload, nil
+}
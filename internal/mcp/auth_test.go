@@ -3,9 +3,15 @@ package mcp
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
 	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/klaus-operator/internal/oidc"
 )
 
 func TestExtractUserFromToken(t *testing.T) {
@@ -71,6 +77,53 @@ func TestExtractUserFromToken(t *testing.T) {
 	}
 }
 
+func TestExtractGroupsFromToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		claim      string
+		wantGroups []string
+		wantError  bool
+	}{
+		{name: "empty token", token: "", claim: "groups", wantError: true},
+		{
+			name:       "array claim",
+			token:      "Bearer " + buildTestJWT(`{"email":"user@example.com","groups":["platform","",42,"dev"]}`),
+			claim:      "groups",
+			wantGroups: []string{"platform", "dev"},
+		},
+		{
+			name:       "string claim",
+			token:      buildTestJWT(`{"teams":"platform"}`),
+			claim:      "teams",
+			wantGroups: []string{"platform"},
+		},
+		{
+			name:  "missing claim",
+			token: buildTestJWT(`{"email":"user@example.com"}`),
+			claim: "groups",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := ExtractGroupsFromToken(tt.token, tt.claim)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got groups: %v", groups)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(groups, tt.wantGroups) {
+				t.Errorf("ExtractGroupsFromToken() = %v, want %v", groups, tt.wantGroups)
+			}
+		})
+	}
+}
+
 func TestHTTPContextFuncAuth(t *testing.T) {
 	token := "Bearer " + buildTestJWT(`{"email":"user@example.com"}`)
 
@@ -105,6 +158,106 @@ func TestHTTPContextFuncAuth(t *testing.T) {
 	})
 }
 
+// fakeVerifier accepts a single token.
+type fakeVerifier struct {
+	token    string
+	identity *oidc.Identity
+}
+
+func (f *fakeVerifier) Verify(_ context.Context, token string) (*oidc.Identity, error) {
+	if token != f.token {
+		return nil, errors.New("invalid token signature")
+	}
+	return f.identity, nil
+}
+
+func TestServer_TokenVerifier(t *testing.T) {
+	s := &Server{}
+	signed := "Bearer " + buildTestJWT(`{"email":"user@example.com"}`)
+	s.SetTokenVerifier(&fakeVerifier{
+		token:    signed,
+		identity: &oidc.Identity{Username: "verified@example.com", Groups: []string{"platform"}},
+	})
+
+	ctx := context.WithValue(context.Background(), authTokenKey, signed)
+	user, err := s.extractUser(ctx)
+	if err != nil || user != "verified@example.com" {
+		t.Errorf("extractUser() = %q, %v, want the verified username", user, err)
+	}
+	groups, err := s.callerGroups(ctx)
+	if err != nil || !slices.Equal(groups, []string{"platform"}) {
+		t.Errorf("callerGroups() = %v, %v, want the verified groups", groups, err)
+	}
+
+	// A token that decodes fine but fails verification is rejected.
+	forged := authCtx("admin@example.com")
+	if _, err := s.extractUser(forged); err == nil || !strings.Contains(err.Error(), "verifying token") {
+		t.Errorf("extractUser() error = %v, want a verification error", err)
+	}
+	if _, err := s.callerGroups(forged); err == nil {
+		t.Error("callerGroups() accepted an unverified token")
+	}
+	if _, err := s.extractUser(context.Background()); err == nil {
+		t.Error("extractUser() accepted a request without token")
+	}
+}
+
+// countingVerifier counts the tokens it verifies.
+type countingVerifier struct {
+	fakeVerifier
+	calls int
+}
+
+func (c *countingVerifier) Verify(ctx context.Context, token string) (*oidc.Identity, error) {
+	c.calls++
+	return c.fakeVerifier.Verify(ctx, token)
+}
+
+func TestServer_IdentifyCaller(t *testing.T) {
+	signed := "Bearer " + buildTestJWT(`{"email":"user@example.com"}`)
+	verifier := &countingVerifier{fakeVerifier: fakeVerifier{
+		token:    signed,
+		identity: &oidc.Identity{Username: "verified@example.com", Groups: []string{"platform"}},
+	}}
+	s := &Server{}
+	s.SetTokenVerifier(verifier)
+
+	var user string
+	var groups []string
+	handler := func(ctx context.Context, _ mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		user, _ = s.extractUser(ctx)
+		groups, _ = s.callerGroups(ctx)
+		return nil, nil
+	}
+	ctx := context.WithValue(context.Background(), authTokenKey, signed)
+	_, _ = s.identifyCaller()(s.auditTools()(handler))(ctx, mcpgolang.CallToolRequest{})
+	if user != "verified@example.com" || !slices.Equal(groups, []string{"platform"}) {
+		t.Errorf("caller = %q %v, want the verified identity", user, groups)
+	}
+	if verifier.calls != 1 {
+		t.Errorf("token verified %d times, want once per tool call", verifier.calls)
+	}
+
+	// A failed verification is kept for the whole call too.
+	_, _ = s.identifyCaller()(handler)(authCtx("admin@example.com"), mcpgolang.CallToolRequest{})
+	if user != "" || verifier.calls != 2 {
+		t.Errorf("caller = %q after %d verifications, want the forged token rejected once", user, verifier.calls)
+	}
+}
+
+func TestServer_RejectTokens(t *testing.T) {
+	s := &Server{}
+	s.SetTokenVerifier(RejectTokens(errors.New("caller tokens cannot be verified")))
+
+	ctx := authCtx("user@example.com")
+	if _, err := s.extractUser(ctx); err == nil || !strings.Contains(err.Error(), "cannot be verified") {
+		t.Errorf("extractUser() error = %v, want the token rejected", err)
+	}
+	if _, err := s.callerGroups(ctx); err == nil {
+		t.Error("callerGroups() accepted a token")
+	}
+}
+
 // buildTestJWT creates a minimal JWT with the given payload.
 // Header and signature are dummy values -- we only decode the payload.
 func buildTestJWT(payload string) string {
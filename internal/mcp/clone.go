@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// cloneResult is the JSON structure returned by clone_instance.
+type cloneResult struct {
+	Name       string   `json:"name"`
+	Owner      string   `json:"owner"`
+	ClonedFrom string   `json:"clonedFrom"`
+	Workspace  bool     `json:"workspace"`
+	Namespace  string   `json:"namespace"`
+	Status     string   `json:"status"`
+	Omitted    []string `json:"omitted,omitempty"`
+}
+
+// handleCloneInstance creates a copy of an instance the caller owns under a
+// new name, subject to the same permission policy and preflight checks as
+// create_instance. The clone records its source in spec.cloneFrom; with
+// workspace set, the controller provisions its workspace PVC as a copy of
+// the workspace of the source.
+func (s *Server) handleCloneInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	user, err := s.extractUser(ctx)
+	if err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
+	source, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	args := request.GetArguments()
+	name, _ := args["new_name"].(string)
+	if name == "" {
+		return mcpError("new_name is required"), nil
+	}
+	workspace, _ := args["workspace"].(bool)
+
+	spec, omitted, err := cloneSpec(source, user, workspace)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+	if err := s.applyPermissionPolicy(ctx, spec); err != nil {
+		return mcpError(err.Error()), nil
+	}
+	if err := s.preflightCreate(ctx, name, user, spec); err != nil {
+		return toolError(err), nil
+	}
+
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.operatorNamespace,
+		},
+		Spec: *spec,
+	}
+	s.stampProvenance(ctx, request, instance)
+	if err := s.client.Create(ctx, instance); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return mcpError("instance '" + name + "' already exists"), nil
+		}
+		return mcpError("failed to create instance: " + err.Error()), nil
+	}
+
+	return mcpSuccess(cloneResult{
+		Name:       name,
+		Owner:      user,
+		ClonedFrom: source.Name,
+		Workspace:  workspace,
+		Namespace:  s.ownerNamespace(user),
+		Status:     "creating",
+		Omitted:    omitted,
+	}), nil
+}
+
+// cloneSpec returns the spec of a clone of source owned by user, and the
+// fields of source left out of it. The clone is started even when the
+// source is stopped, and drops spec.expose, whose hostname would collide
+// with the source. Only the primary owner keeps the sharing and the
+// credentials of the source and can clone its workspace, as the workspace
+// PVC can only be cloned within the namespace of the owner.
+func cloneSpec(source *klausv1alpha1.KlausInstance, user string, workspace bool) (*klausv1alpha1.KlausInstanceSpec, []string, error) {
+	spec := source.Spec.DeepCopy()
+	var omitted []string
+
+	if workspace {
+		if spec.Workspace == nil {
+			return nil, nil, fmt.Errorf("instance '%s' has no workspace to clone", source.Name)
+		}
+		if user != source.Spec.Owner {
+			return nil, nil, fmt.Errorf("only the primary owner of instance '%s' can clone its workspace", source.Name)
+		}
+	}
+	if user != source.Spec.Owner {
+		if len(spec.Owners) > 0 || len(spec.OwnerGroups) > 0 {
+			omitted = append(omitted, "owners", "ownerGroups")
+		}
+		spec.Owners = nil
+		spec.OwnerGroups = nil
+		omitted = append(omitted, stripCredentials(spec)...)
+	}
+	if spec.Expose != nil {
+		omitted = append(omitted, "expose")
+		spec.Expose = nil
+	}
+
+	spec.Owner = user
+	spec.Stopped = false
+	spec.DesiredState = ""
+	spec.CloneFrom = &klausv1alpha1.CloneSource{Name: source.Name, Workspace: workspace}
+	return spec, omitted, nil
+}
+
+// stripCredentials removes the fields of spec referencing Secrets or
+// credentials the operator copies or mints for the owner, and returns them.
+// Shared owners cloning an instance into their own namespace must not have
+// the operator copy the Secrets of the primary owner for them.
+func stripCredentials(spec *klausv1alpha1.KlausInstanceSpec) []string {
+	var omitted []string
+	if len(spec.Claude.MCPServerSecrets) > 0 {
+		omitted = append(omitted, "claude.mcpServerSecrets")
+		spec.Claude.MCPServerSecrets = nil
+	}
+	env := spec.ExtraEnv[:0]
+	for _, e := range spec.ExtraEnv {
+		if e.ValueFrom == nil || e.ValueFrom.SecretKeyRef == nil {
+			env = append(env, e)
+		}
+	}
+	if len(env) < len(spec.ExtraEnv) {
+		omitted = append(omitted, "extraEnv")
+	}
+	spec.ExtraEnv = env
+	envFrom := spec.ExtraEnvFrom[:0]
+	for _, source := range spec.ExtraEnvFrom {
+		if source.SecretRef == nil {
+			envFrom = append(envFrom, source)
+		}
+	}
+	if len(envFrom) < len(spec.ExtraEnvFrom) {
+		omitted = append(omitted, "extraEnvFrom")
+	}
+	spec.ExtraEnvFrom = envFrom
+	if w := spec.Workspace; w != nil {
+		if w.GitSecretRef != nil {
+			omitted = append(omitted, "workspace.gitSecretRef")
+			w.GitSecretRef = nil
+		}
+		if w.GitHubApp != nil {
+			omitted = append(omitted, "workspace.gitHubApp")
+			w.GitHubApp = nil
+		}
+		stripped := false
+		for i := range w.Repos {
+			if w.Repos[i].SecretRef != nil {
+				w.Repos[i].SecretRef = nil
+				stripped = true
+			}
+		}
+		if stripped {
+			omitted = append(omitted, "workspace.repos.secretRef")
+		}
+	}
+	if spec.KubernetesAccess != nil {
+		omitted = append(omitted, "kubernetesAccess")
+		spec.KubernetesAccess = nil
+	}
+	if spec.Claude.Provider != nil {
+		omitted = append(omitted, "claude.provider")
+		spec.Claude.Provider = nil
+	}
+	return omitted
+}
@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func cloneSourceInstance() *klausv1alpha1.KlausInstance {
+	source := runningInstance("agent", "user@example.com", "")
+	source.Spec.Owners = []string{"friend@example.com"}
+	source.Spec.Claude.Model = "claude-opus-4-20250514"
+	source.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{GitRepo: "https://github.com/org/repo.git"}
+	source.Spec.Expose = &klausv1alpha1.ExposeConfig{Hostname: "agent.example.com"}
+	source.Spec.Stopped = true
+	return source
+}
+
+func callClone(t *testing.T, s *Server, user string, args map[string]any) (*mcpgolang.CallToolResult, cloneResult) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := s.handleCloneInstance(authCtx(user), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data cloneResult
+	if !result.IsError {
+		if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return result, data
+}
+
+func cloneTestServer(t *testing.T, objs ...client.Object) *Server {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objs...).Build()
+	return &Server{client: c, operatorNamespace: "klaus-system"}
+}
+
+func TestHandleCloneInstance(t *testing.T) {
+	s := cloneTestServer(t, cloneSourceInstance())
+
+	result, data := callClone(t, s, "user@example.com", map[string]any{
+		"name":      "agent",
+		"new_name":  "agent-copy",
+		"workspace": true,
+	})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if data.Status != "creating" || data.ClonedFrom != "agent" || !data.Workspace {
+		t.Errorf("result = %+v", data)
+	}
+	if len(data.Omitted) != 1 || data.Omitted[0] != "expose" {
+		t.Errorf("omitted = %v, want expose", data.Omitted)
+	}
+
+	var clone klausv1alpha1.KlausInstance
+	if err := s.client.Get(context.Background(), types.NamespacedName{Name: "agent-copy", Namespace: "klaus-system"}, &clone); err != nil {
+		t.Fatalf("clone not created: %v", err)
+	}
+	if c := clone.Spec.CloneFrom; c == nil || c.Name != "agent" || !c.Workspace {
+		t.Errorf("cloneFrom = %+v, want agent with its workspace", c)
+	}
+	if clone.Spec.Claude.Model != "claude-opus-4-20250514" || clone.Spec.Workspace == nil || clone.Spec.Workspace.GitRepo != "https://github.com/org/repo.git" {
+		t.Errorf("clone spec = %+v, want the spec of the source", clone.Spec)
+	}
+	if len(clone.Spec.Owners) != 1 {
+		t.Errorf("owners = %v, want the owners of the source", clone.Spec.Owners)
+	}
+	if clone.Spec.Expose != nil || clone.Spec.Stopped {
+		t.Errorf("expose = %+v, stopped = %v, want a started clone without expose", clone.Spec.Expose, clone.Spec.Stopped)
+	}
+}
+
+func TestHandleCloneInstance_SharedOwner(t *testing.T) {
+	s := cloneTestServer(t, cloneSourceInstance())
+
+	result, _ := callClone(t, s, "friend@example.com", map[string]any{"name": "agent", "new_name": "mine", "workspace": true})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "only the primary owner") {
+		t.Fatalf("workspace clone by a shared owner = %v, want an error", result.Content)
+	}
+
+	result, data := callClone(t, s, "friend@example.com", map[string]any{"name": "agent", "new_name": "mine"})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	var clone klausv1alpha1.KlausInstance
+	if err := s.client.Get(context.Background(), types.NamespacedName{Name: "mine", Namespace: "klaus-system"}, &clone); err != nil {
+		t.Fatalf("clone not created: %v", err)
+	}
+	if clone.Spec.Owner != "friend@example.com" || len(clone.Spec.Owners) != 0 {
+		t.Errorf("owner = %q, owners = %v, want the caller only", clone.Spec.Owner, clone.Spec.Owners)
+	}
+	if !strings.Contains(strings.Join(data.Omitted, ","), "owners") {
+		t.Errorf("omitted = %v, want owners", data.Omitted)
+	}
+}
+
+func TestCloneSpec_SharedOwnerCredentials(t *testing.T) {
+	secretKey := &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "team-env"}, Key: "token",
+	}}
+	tests := []struct {
+		name    string
+		set     func(*klausv1alpha1.KlausInstanceSpec)
+		omitted string
+		check   func(*klausv1alpha1.KlausInstanceSpec) bool
+	}{
+		{
+			name: "MCP server Secrets",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.Claude.MCPServerSecrets = []klausv1alpha1.MCPServerSecret{{SecretName: "github-token", Env: map[string]string{"GITHUB_TOKEN": "token"}}}
+			},
+			omitted: "claude.mcpServerSecrets",
+			check:   func(spec *klausv1alpha1.KlausInstanceSpec) bool { return len(spec.Claude.MCPServerSecrets) == 0 },
+		},
+		{
+			name: "extra env Secret references",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.ExtraEnv = []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}, {Name: "TOKEN", ValueFrom: secretKey}}
+			},
+			omitted: "extraEnv",
+			check: func(spec *klausv1alpha1.KlausInstanceSpec) bool {
+				return len(spec.ExtraEnv) == 1 && spec.ExtraEnv[0].Name == "LOG_LEVEL"
+			},
+		},
+		{
+			name: "extra env Secrets",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.ExtraEnvFrom = []corev1.EnvFromSource{{Prefix: "TEAM_", SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "team-env"},
+				}}}
+			},
+			omitted: "extraEnvFrom",
+			check:   func(spec *klausv1alpha1.KlausInstanceSpec) bool { return len(spec.ExtraEnvFrom) == 0 },
+		},
+		{
+			name: "workspace git Secret",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.Workspace.GitSecretRef = &klausv1alpha1.GitSecretReference{Name: "git-token"}
+			},
+			omitted: "workspace.gitSecretRef",
+			check:   func(spec *klausv1alpha1.KlausInstanceSpec) bool { return spec.Workspace.GitSecretRef == nil },
+		},
+		{
+			name: "workspace GitHub App",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.Workspace.GitHubApp = &klausv1alpha1.GitHubAppCredentials{}
+			},
+			omitted: "workspace.gitHubApp",
+			check:   func(spec *klausv1alpha1.KlausInstanceSpec) bool { return spec.Workspace.GitHubApp == nil },
+		},
+		{
+			name: "workspace repository Secret",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.Workspace.Repos = []klausv1alpha1.WorkspaceRepository{{
+					Repo: "https://github.com/org/other.git", SecretRef: &klausv1alpha1.GitSecretReference{Name: "git-token"},
+				}}
+			},
+			omitted: "workspace.repos.secretRef",
+			check: func(spec *klausv1alpha1.KlausInstanceSpec) bool {
+				return len(spec.Workspace.Repos) == 1 && spec.Workspace.Repos[0].SecretRef == nil
+			},
+		},
+		{
+			name: "Kubernetes access",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.KubernetesAccess = &klausv1alpha1.KubernetesAccess{}
+			},
+			omitted: "kubernetesAccess",
+			check:   func(spec *klausv1alpha1.KlausInstanceSpec) bool { return spec.KubernetesAccess == nil },
+		},
+		{
+			name: "provider credentials",
+			set: func(spec *klausv1alpha1.KlausInstanceSpec) {
+				spec.Claude.Provider = &klausv1alpha1.ProviderConfig{
+					Type: klausv1alpha1.ProviderBedrock,
+					Bedrock: &klausv1alpha1.BedrockConfig{
+						Region:               "eu-central-1",
+						CredentialsSecretRef: &klausv1alpha1.ProviderSecretReference{Name: "aws-credentials"},
+					},
+				}
+			},
+			omitted: "claude.provider",
+			check:   func(spec *klausv1alpha1.KlausInstanceSpec) bool { return spec.Claude.Provider == nil },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := cloneSourceInstance()
+			tt.set(&source.Spec)
+
+			spec, omitted, err := cloneSpec(source, "friend@example.com", false)
+			if err != nil {
+				t.Fatalf("cloneSpec: %v", err)
+			}
+			if !tt.check(spec) || !slices.Contains(omitted, tt.omitted) {
+				t.Errorf("omitted = %v, want %s removed from the clone of a shared owner", omitted, tt.omitted)
+			}
+
+			spec, omitted, err = cloneSpec(source, "user@example.com", false)
+			if err != nil {
+				t.Fatalf("cloneSpec: %v", err)
+			}
+			if tt.check(spec) || slices.Contains(omitted, tt.omitted) {
+				t.Errorf("omitted = %v, want %s kept for the primary owner", omitted, tt.omitted)
+			}
+		})
+	}
+}
+
+func TestHandleCloneInstance_Errors(t *testing.T) {
+	noWorkspace := runningInstance("plain", "user@example.com", "")
+	tests := []struct {
+		name    string
+		user    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "missing new name", user: "user@example.com", args: map[string]any{"name": "agent"}, wantErr: "new_name is required"},
+		{name: "not owned", user: "other@example.com", args: map[string]any{"name": "agent", "new_name": "x"}, wantErr: "access denied"},
+		{name: "existing name", user: "user@example.com", args: map[string]any{"name": "agent", "new_name": "plain"}, wantErr: "already exists"},
+		{name: "no workspace", user: "user@example.com", args: map[string]any{"name": "plain", "new_name": "x", "workspace": true}, wantErr: "no workspace to clone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := cloneTestServer(t, cloneSourceInstance(), noWorkspace.DeepCopy())
+			result, _ := callClone(t, s, tt.user, tt.args)
+			if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, tt.wantErr) {
+				t.Errorf("result = %v, want an error containing %q", result.Content, tt.wantErr)
+			}
+		})
+	}
+}
@@ -16,9 +16,18 @@ const (
 
 	// Status values returned in tool response payloads.
 	statusStarted   = "started"
+	statusRunning   = "running"
 	statusCompleted = "completed"
 	statusError     = "error"
 
 	// klausContainerName is the canonical container name in instance pods.
 	klausContainerName = "klaus"
+
+	// conditionDeploymentReady is the KlausInstance condition carrying the
+	// rollout summary. Mirrors controller.ConditionDeploymentReady.
+	conditionDeploymentReady = "DeploymentReady"
+
+	// conditionResultValid reports whether status.result matches
+	// spec.claude.jsonSchema. Mirrors controller.ConditionResultValid.
+	conditionResultValid = "ResultValid"
 )
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/klaus-operator/internal/deprecation"
+)
+
+// deprecationWarnings is a tool handler middleware adding warnings for
+// deprecated tools and arguments to the responses. JSON object responses
+// get a "warnings" array; other responses, such as errors and raw logs, get
+// an additional text content item.
+func deprecationWarnings(registry deprecation.Registry) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil {
+				return result, err
+			}
+			if warnings := registry.ToolWarnings(request.Params.Name, request.GetArguments()); len(warnings) > 0 {
+				addWarnings(result, warnings)
+			}
+			return result, nil
+		}
+	}
+}
+
+// addWarnings adds warnings to a tool result.
+func addWarnings(result *mcpgolang.CallToolResult, warnings []string) {
+	if len(result.Content) > 0 && !result.IsError {
+		if text, ok := result.Content[0].(mcpgolang.TextContent); ok {
+			var data map[string]any
+			if json.Unmarshal([]byte(text.Text), &data) == nil && data != nil {
+				data["warnings"] = warnings
+				if jsonBytes, err := json.MarshalIndent(data, "", "  "); err == nil {
+					result.Content[0] = mcpgolang.NewTextContent(string(jsonBytes))
+					return
+				}
+			}
+		}
+	}
+	result.Content = append(result.Content, mcpgolang.NewTextContent("Warning: "+strings.Join(warnings, "\nWarning: ")))
+}
+
+// deprecatedDescription marks the description of a deprecated tool.
+func deprecatedDescription(tool, description string) string {
+	d, ok := deprecation.Default.Tool(tool)
+	if !ok {
+		return description
+	}
+	description += " (deprecated"
+	if d.RemovedIn != "" {
+		description += ", removed in " + d.RemovedIn
+	}
+	if d.Replacement != "" {
+		description += "; use " + d.Replacement + " instead"
+	}
+	return description + ")"
+}
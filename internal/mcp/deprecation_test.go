@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/klaus-operator/internal/deprecation"
+)
+
+var testDeprecations = deprecation.Registry{
+	{Tool: "old_tool", Replacement: "new_tool", RemovedIn: "0.5.0"},
+	{Tool: "create_instance", Argument: "legacy", RemovedIn: "0.5.0"},
+}
+
+func callWithDeprecations(t *testing.T, tool string, args map[string]any, result *mcpgolang.CallToolResult) *mcpgolang.CallToolResult {
+	t.Helper()
+	handler := deprecationWarnings(testDeprecations)(func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return result, nil
+	})
+	var request mcpgolang.CallToolRequest
+	request.Params.Name = tool
+	request.Params.Arguments = args
+	got, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return got
+}
+
+func TestDeprecationWarnings_JSONResult(t *testing.T) {
+	result := callWithDeprecations(t, "old_tool", nil, mcpSuccess(map[string]any{"name": "test"}))
+
+	if len(result.Content) != 1 {
+		t.Fatalf("expected one content item, got %d", len(result.Content))
+	}
+	var data struct {
+		Name     string   `json:"name"`
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("response is no longer JSON: %v", err)
+	}
+	if data.Name != "test" {
+		t.Errorf("name = %q, want the handler response to be kept", data.Name)
+	}
+	if len(data.Warnings) != 1 || !strings.Contains(data.Warnings[0], "use new_tool instead") {
+		t.Errorf("warnings = %v, want the old_tool deprecation", data.Warnings)
+	}
+}
+
+func TestDeprecationWarnings_TextResult(t *testing.T) {
+	result := callWithDeprecations(t, "create_instance", map[string]any{"legacy": true}, mcpError("boom"))
+
+	if len(result.Content) != 2 {
+		t.Fatalf("expected the warning as a second content item, got %d items", len(result.Content))
+	}
+	if text := result.Content[0].(mcpgolang.TextContent).Text; text != "boom" {
+		t.Errorf("content[0] = %q, want the handler response", text)
+	}
+	if text := result.Content[1].(mcpgolang.TextContent).Text; !strings.HasPrefix(text, `Warning: argument "legacy"`) {
+		t.Errorf("content[1] = %q, want the argument deprecation", text)
+	}
+}
+
+func TestDeprecationWarnings_NotDeprecated(t *testing.T) {
+	result := callWithDeprecations(t, "create_instance", map[string]any{"name": "test"}, mcpSuccess(map[string]any{"name": "test"}))
+
+	if strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "warnings") {
+		t.Errorf("unexpected warnings in %s", result.Content[0].(mcpgolang.TextContent).Text)
+	}
+}
+
+func TestDeprecatedDescription(t *testing.T) {
+	if got := deprecatedDescription("get_logs", "Get logs"); got != "Get logs (deprecated, removed in 0.2.0; use get_instance_logs instead)" {
+		t.Errorf("deprecatedDescription(get_logs) = %q", got)
+	}
+	if got := deprecatedDescription("get_instance_logs", "Get logs"); got != "Get logs" {
+		t.Errorf("deprecatedDescription(get_instance_logs) = %q, want it unchanged", got)
+	}
+}
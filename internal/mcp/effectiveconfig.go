@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// effectiveConfigResult is the JSON structure returned by
+// get_effective_config.
+type effectiveConfigResult struct {
+	Name    string `json:"name"`
+	Hash    string `json:"hash"`
+	Spec    string `json:"spec"`
+	Stale   bool   `json:"stale,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// handleGetEffectiveConfig returns the effective spec of an owned instance
+// published by the controller: its spec merged with the personality, skill
+// packs and KlausMCPServers, with its OCI references resolved and secrets
+// redacted. It is reported stale until the controller finished reconciling
+// the latest spec.
+func (s *Server) handleGetEffectiveConfig(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	instance, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	var cm corev1.ConfigMap
+	key := types.NamespacedName{Name: resources.EffectiveConfigName(instance), Namespace: s.instanceNamespace(instance)}
+	if err := s.client.Get(ctx, key, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return mcpError("the effective configuration of instance '" + instance.Name + "' is not published yet; check its status for reconcile errors"), nil
+		}
+		return mcpError("failed to get effective configuration: " + err.Error()), nil
+	}
+
+	res := effectiveConfigResult{
+		Name: instance.Name,
+		Hash: resources.EffectiveConfigHash(&cm),
+		Spec: cm.Data[resources.EffectiveConfigKey],
+	}
+	if instance.Status.ObservedGeneration != instance.Generation || instance.Status.EffectiveConfigHash != res.Hash {
+		res.Stale = true
+		res.Message = "the controller has not finished reconciling the latest spec of the instance; the effective configuration may not reflect it yet"
+	}
+	return mcpSuccess(res), nil
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+func callGetEffectiveConfig(t *testing.T, s *Server, user string) (*mcpgolang.CallToolResult, effectiveConfigResult) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "agent"}
+	result, err := s.handleGetEffectiveConfig(authCtx(user), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data effectiveConfigResult
+	if !result.IsError {
+		if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return result, data
+}
+
+// effectiveConfigInstance returns an instance with its effective
+// configuration ConfigMap as published by the controller.
+func effectiveConfigInstance(t *testing.T) (*klausv1alpha1.KlausInstance, *corev1.ConfigMap) {
+	t.Helper()
+	instance := runningInstance("agent", "user@example.com", "")
+	instance.Spec.Claude.Model = "claude-opus-4-20250514"
+	cm, err := resources.BuildEffectiveConfigMap(instance, resources.InstanceNamespace(instance, false), "registry.example.com/klaus:v1")
+	if err != nil {
+		t.Fatalf("BuildEffectiveConfigMap: %v", err)
+	}
+	instance.Generation = 2
+	instance.Status.ObservedGeneration = 2
+	instance.Status.EffectiveConfigHash = resources.EffectiveConfigHash(cm)
+	return instance, cm
+}
+
+func TestHandleGetEffectiveConfig(t *testing.T) {
+	instance, cm := effectiveConfigInstance(t)
+	s := cloneTestServer(t, instance, cm)
+
+	result, data := callGetEffectiveConfig(t, s, "user@example.com")
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if data.Hash != instance.Status.EffectiveConfigHash || data.Stale {
+		t.Errorf("result = %+v, want the current hash", data)
+	}
+	if !strings.Contains(data.Spec, "model: claude-opus-4-20250514") {
+		t.Errorf("spec = %s, want the effective model", data.Spec)
+	}
+}
+
+func TestHandleGetEffectiveConfig_Stale(t *testing.T) {
+	instance, cm := effectiveConfigInstance(t)
+	instance.Generation = 3
+	s := cloneTestServer(t, instance, cm)
+
+	result, data := callGetEffectiveConfig(t, s, "user@example.com")
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if !data.Stale || data.Message == "" {
+		t.Errorf("result = %+v, want it reported stale", data)
+	}
+}
+
+func TestHandleGetEffectiveConfig_Errors(t *testing.T) {
+	instance, cm := effectiveConfigInstance(t)
+
+	s := cloneTestServer(t, instance, cm)
+	result, _ := callGetEffectiveConfig(t, s, "other@example.com")
+	if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "access denied") {
+		t.Errorf("result = %v, want access denied for another user", result.Content)
+	}
+
+	s = cloneTestServer(t, instance)
+	result, _ = callGetEffectiveConfig(t, s, "user@example.com")
+	if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "not published yet") {
+		t.Errorf("result = %v, want an error before the controller published it", result.Content)
+	}
+}
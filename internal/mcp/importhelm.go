@@ -0,0 +1,242 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/helmimport"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;patch
+
+// Labels of the Secrets the Helm storage driver stores releases in.
+const (
+	helmOwnerLabel   = "owner"
+	helmNameLabel    = "name"
+	helmStatusLabel  = "status"
+	helmVersionLabel = "version"
+)
+
+// helmImportResult is the JSON structure returned by import_helm_release.
+type helmImportResult struct {
+	Release   string   `json:"release"`
+	Chart     string   `json:"chart"`
+	Name      string   `json:"name"`
+	Owner     string   `json:"owner"`
+	Status    string   `json:"status"`
+	Instance  string   `json:"instance"`
+	Unmapped  []string `json:"unmapped,omitempty"`
+	PVCs      []string `json:"pvcs,omitempty"`
+	Adopted   string   `json:"adoptedVolume,omitempty"`
+	NextSteps []string `json:"nextSteps,omitempty"`
+}
+
+// handleImportHelmRelease converts a release of the standalone Klaus chart
+// into a KlausInstance. By default it only reports the generated instance;
+// with apply it creates it, optionally binding the release's workspace
+// volume to the instance PVC so the workspace survives the migration.
+// Restricted to platform admins, as it reads release Secrets in any
+// namespace and creates instances for other owners.
+func (s *Server) handleImportHelmRelease(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	if _, err := s.extractUser(ctx); err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
+	if err := s.requireAdmin(ctx); err != nil {
+		return mcpError(err.Error()), nil
+	}
+
+	args := request.GetArguments()
+	releaseName, _ := args["release"].(string)
+	releaseNamespace, _ := args["namespace"].(string)
+	owner, _ := args[keyOwner].(string)
+	if releaseName == "" || releaseNamespace == "" || owner == "" {
+		return mcpError("release, namespace and owner are required"), nil
+	}
+	name, _ := args[keyName].(string)
+	apply, _ := args["apply"].(bool)
+	adoptPVC, _ := args["adopt_pvc"].(string)
+
+	release, err := s.findHelmRelease(ctx, releaseNamespace, releaseName)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+	converted, err := helmimport.Convert(release, helmimport.Options{
+		Name:      name,
+		Namespace: s.operatorNamespace,
+		Owner:     owner,
+	})
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+	instance := converted.Instance
+
+	var pv *corev1.PersistentVolume
+	if adoptPVC != "" {
+		if pv, err = s.adoptableVolume(ctx, releaseNamespace, adoptPVC, instance); err != nil {
+			return mcpError(err.Error()), nil
+		}
+	}
+
+	result := helmImportResult{
+		Release:  releaseNamespace + "/" + releaseName,
+		Chart:    release.ChartRef(),
+		Name:     instance.Name,
+		Owner:    owner,
+		Status:   "dry-run",
+		Unmapped: converted.Unmapped,
+		PVCs:     converted.PVCs,
+	}
+	if pv != nil {
+		result.Adopted = pv.Name
+	}
+	manifest, err := yaml.Marshal(instance)
+	if err != nil {
+		return mcpError("failed to encode instance: " + err.Error()), nil
+	}
+	result.Instance = string(manifest)
+
+	if !apply {
+		result.NextSteps = []string{"review the instance and unmapped values, then call again with apply=true"}
+		return mcpSuccess(result), nil
+	}
+
+	if err := s.preflightCreate(ctx, instance.Name, owner, &instance.Spec); err != nil {
+		return toolError(err), nil
+	}
+	if pv != nil {
+		if err := s.bindVolume(ctx, pv, instance); err != nil {
+			return mcpError(err.Error()), nil
+		}
+	}
+	s.stampProvenance(ctx, request, instance)
+	if err := s.client.Create(ctx, instance); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return mcpError("instance '" + instance.Name + "' already exists"), nil
+		}
+		return mcpError("failed to create instance: " + err.Error()), nil
+	}
+	if s.recorder != nil {
+		s.recorder.Eventf(instance, corev1.EventTypeNormal, "ImportedFromHelm",
+			"Imported from Helm release %s (%s)", result.Release, result.Chart)
+	}
+
+	result.Status = "creating"
+	result.NextSteps = []string{
+		fmt.Sprintf("uninstall the release: helm uninstall %s -n %s", releaseName, releaseNamespace),
+	}
+	if pv != nil {
+		result.NextSteps = append(result.NextSteps,
+			fmt.Sprintf("the instance pod cannot mount volume %s on another node until the release pod is gone", pv.Name))
+	}
+	return mcpSuccess(result), nil
+}
+
+// findHelmRelease returns the deployed revision of a Helm release.
+func (s *Server) findHelmRelease(ctx context.Context, namespace, name string) (*helmimport.Release, error) {
+	var secrets corev1.SecretList
+	if err := s.client.List(ctx, &secrets, client.InNamespace(namespace), client.MatchingLabels{
+		helmOwnerLabel:  "helm",
+		helmNameLabel:   name,
+		helmStatusLabel: "deployed",
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list release secrets: %w", err)
+	}
+
+	var latest *corev1.Secret
+	latestVersion := -1
+	for i := range secrets.Items {
+		version, err := strconv.Atoi(secrets.Items[i].Labels[helmVersionLabel])
+		if err != nil {
+			continue
+		}
+		if version > latestVersion {
+			latest, latestVersion = &secrets.Items[i], version
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no deployed Helm release '%s' found in namespace %s", name, namespace)
+	}
+
+	release, err := helmimport.DecodeRelease(latest.Data[helmimport.ReleaseKey])
+	if err != nil {
+		return nil, fmt.Errorf("release secret %s: %w", latest.Name, err)
+	}
+	return release, nil
+}
+
+// adoptableVolume returns the volume bound to a release PVC, and configures
+// the instance workspace to the storage class and size of that PVC.
+func (s *Server) adoptableVolume(ctx context.Context, namespace, pvcName string, instance *klausv1alpha1.KlausInstance) (*corev1.PersistentVolume, error) {
+	var pvc corev1.PersistentVolumeClaim
+	if err := s.client.Get(ctx, types.NamespacedName{Name: pvcName, Namespace: namespace}, &pvc); err != nil {
+		return nil, fmt.Errorf("failed to get PVC %s/%s: %w", namespace, pvcName, err)
+	}
+	if pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return nil, fmt.Errorf("PVC %s/%s is not bound", namespace, pvcName)
+	}
+
+	var pv corev1.PersistentVolume
+	if err := s.client.Get(ctx, types.NamespacedName{Name: pvc.Spec.VolumeName}, &pv); err != nil {
+		return nil, fmt.Errorf("failed to get volume %s: %w", pvc.Spec.VolumeName, err)
+	}
+
+	if instance.Spec.Workspace == nil {
+		instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{}
+	}
+	ws := instance.Spec.Workspace
+	ws.StorageClass = ""
+	if pvc.Spec.StorageClassName != nil {
+		ws.StorageClass = *pvc.Spec.StorageClassName
+	}
+	if size, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		ws.Size = &size
+	}
+	return &pv, nil
+}
+
+// bindVolume hands a release volume over to the instance: the volume is
+// retained when the release PVC is deleted, and pre-bound to an instance
+// PVC created ahead of the controller, which keeps an existing PVC.
+func (s *Server) bindVolume(ctx context.Context, pv *corev1.PersistentVolume, instance *klausv1alpha1.KlausInstance) error {
+	namespace := s.instanceNamespace(instance)
+
+	base := pv.DeepCopy()
+	pv.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	if err := s.client.Patch(ctx, pv, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to retain volume %s: %w", pv.Name, err)
+	}
+
+	if !s.namespaceScoped {
+		if err := s.client.Create(ctx, resources.BuildNamespace(instance)); client.IgnoreAlreadyExists(err) != nil {
+			return fmt.Errorf("failed to create namespace %s: %w", namespace, err)
+		}
+	}
+	pvc := resources.BuildPVC(instance, namespace)
+	pvc.Spec.VolumeName = pv.Name
+	pvc.Spec.AccessModes = pv.Spec.AccessModes
+	if err := s.client.Create(ctx, pvc); err != nil {
+		return fmt.Errorf("failed to create PVC %s/%s: %w", namespace, pvc.Name, err)
+	}
+
+	base = pv.DeepCopy()
+	pv.Spec.ClaimRef = &corev1.ObjectReference{
+		Kind:       "PersistentVolumeClaim",
+		APIVersion: "v1",
+		Namespace:  namespace,
+		Name:       pvc.Name,
+	}
+	if err := s.client.Patch(ctx, pv, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("failed to bind volume %s to PVC %s/%s: %w", pv.Name, namespace, pvc.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const importTestNamespace = "klaus-user-dev-example-com"
+
+// helmReleaseSecret builds a release Secret as stored by the Helm Secret
+// storage driver.
+func helmReleaseSecret(t *testing.T, version int, status string, config map[string]any) *corev1.Secret {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{
+		"name":      "agent",
+		"namespace": "agents",
+		"version":   version,
+		"config":    config,
+		"chart": map[string]any{
+			"metadata": map[string]any{"name": "klaus", "version": "0.4.0"},
+			"values":   map[string]any{"claude": map[string]any{"model": "sonnet"}, "replicaCount": 1},
+		},
+		"manifest": "kind: PersistentVolumeClaim\nmetadata:\n  name: agent-klaus-workspace\n",
+	})
+	if err != nil {
+		t.Fatalf("marshalling release: %v", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1.agent.v" + strconv.Itoa(version),
+			Namespace: "agents",
+			Labels: map[string]string{
+				"owner": "helm", "name": "agent", "status": status, "version": strconv.Itoa(version),
+			},
+		},
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))},
+	}
+}
+
+func importTestServer(t *testing.T, objs ...client.Object) *Server {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objs...).Build()
+	return &Server{client: c, operatorNamespace: "klaus-system", permissionPolicy: testPermissionPolicy()}
+}
+
+func importRequest(args map[string]any) mcpgolang.CallToolRequest {
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = args
+	return req
+}
+
+func TestHandleImportHelmRelease_DryRun(t *testing.T) {
+	s := importTestServer(t,
+		helmReleaseSecret(t, 1, "superseded", map[string]any{"claude": map[string]any{"model": "haiku"}}),
+		helmReleaseSecret(t, 2, "deployed", map[string]any{"claude": map[string]any{"model": "opus"}, "nodeSelector": map[string]any{"pool": "agents"}}),
+	)
+
+	result, err := s.handleImportHelmRelease(groupsCtx(`["platform-admins"]`), importRequest(map[string]any{
+		"release": "agent", "namespace": "agents", "owner": "dev@example.com",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", text)
+	}
+
+	var data helmImportResult
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Status != "dry-run" || data.Chart != "klaus-0.4.0" || data.Name != "agent" {
+		t.Errorf("result = %+v", data)
+	}
+	if !strings.Contains(data.Instance, "model: opus") || !strings.Contains(data.Instance, "owner: dev@example.com") {
+		t.Errorf("instance manifest =\n%s", data.Instance)
+	}
+	if len(data.Unmapped) != 1 || data.Unmapped[0] != "nodeSelector" {
+		t.Errorf("Unmapped = %v", data.Unmapped)
+	}
+	if len(data.PVCs) != 1 || data.PVCs[0] != "agent-klaus-workspace" {
+		t.Errorf("PVCs = %v", data.PVCs)
+	}
+
+	var instance klausv1alpha1.KlausInstance
+	err = s.client.Get(t.Context(), types.NamespacedName{Name: "agent", Namespace: "klaus-system"}, &instance)
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("expected no instance on a dry run, got %v", err)
+	}
+}
+
+func TestHandleImportHelmRelease_ApplyAdoptsPVC(t *testing.T) {
+	storageClass := "fast"
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-klaus-workspace", Namespace: "agents"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			VolumeName:       "pv-1",
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimDelete,
+			AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			ClaimRef:                      &corev1.ObjectReference{Namespace: "agents", Name: "agent-klaus-workspace", UID: "old-uid"},
+		},
+	}
+	s := importTestServer(t, helmReleaseSecret(t, 1, "deployed", nil), pvc, pv)
+
+	result, err := s.handleImportHelmRelease(groupsCtx(`["platform-admins"]`), importRequest(map[string]any{
+		"release":   "agent",
+		"namespace": "agents",
+		"owner":     "dev@example.com",
+		"name":      "reviewer",
+		"apply":     true,
+		"adopt_pvc": "agent-klaus-workspace",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", text)
+	}
+	if !strings.Contains(text, `"status": "creating"`) || !strings.Contains(text, "helm uninstall agent -n agents") {
+		t.Errorf("result = %s", text)
+	}
+
+	ctx := t.Context()
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(ctx, types.NamespacedName{Name: "reviewer", Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("instance not created: %v", err)
+	}
+	ws := instance.Spec.Workspace
+	if ws == nil || ws.StorageClass != "fast" || ws.Size.String() != "20Gi" {
+		t.Errorf("workspace = %+v, want the storage class and size of the adopted PVC", ws)
+	}
+
+	var gotPV corev1.PersistentVolume
+	if err := s.client.Get(ctx, types.NamespacedName{Name: "pv-1"}, &gotPV); err != nil {
+		t.Fatalf("failed to get PV: %v", err)
+	}
+	if gotPV.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+		t.Errorf("reclaim policy = %s, want Retain", gotPV.Spec.PersistentVolumeReclaimPolicy)
+	}
+	ref := gotPV.Spec.ClaimRef
+	if ref == nil || ref.Namespace != importTestNamespace || ref.Name != "reviewer-workspace" || ref.UID != "" {
+		t.Errorf("claimRef = %+v, want the instance PVC without a UID", ref)
+	}
+
+	var gotPVC corev1.PersistentVolumeClaim
+	if err := s.client.Get(ctx, types.NamespacedName{Name: "reviewer-workspace", Namespace: importTestNamespace}, &gotPVC); err != nil {
+		t.Fatalf("instance PVC not created: %v", err)
+	}
+	if gotPVC.Spec.VolumeName != "pv-1" {
+		t.Errorf("VolumeName = %q, want pv-1", gotPVC.Spec.VolumeName)
+	}
+	var ns corev1.Namespace
+	if err := s.client.Get(ctx, types.NamespacedName{Name: importTestNamespace}, &ns); err != nil {
+		t.Errorf("owner namespace not created: %v", err)
+	}
+}
+
+func TestHandleImportHelmRelease_UnboundPVC(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-klaus-workspace", Namespace: "agents"},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+	}
+	s := importTestServer(t, helmReleaseSecret(t, 1, "deployed", nil), pvc)
+
+	result, err := s.handleImportHelmRelease(groupsCtx(`["platform-admins"]`), importRequest(map[string]any{
+		"release": "agent", "namespace": "agents", "owner": "dev@example.com", "adopt_pvc": "agent-klaus-workspace",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcpgolang.TextContent).Text; !result.IsError || !strings.Contains(text, "is not bound") {
+		t.Errorf("result = %q", text)
+	}
+}
+
+func TestHandleImportHelmRelease_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  string
+		args    map[string]any
+		wantErr string
+	}{
+		{
+			name:    "not an admin",
+			groups:  `["platform"]`,
+			args:    map[string]any{"release": "agent", "namespace": "agents", "owner": "dev@example.com"},
+			wantErr: "restricted to platform admins",
+		},
+		{
+			name:    "missing owner",
+			groups:  `["platform-admins"]`,
+			args:    map[string]any{"release": "agent", "namespace": "agents"},
+			wantErr: "owner are required",
+		},
+		{
+			name:    "release not found",
+			groups:  `["platform-admins"]`,
+			args:    map[string]any{"release": "other", "namespace": "agents", "owner": "dev@example.com"},
+			wantErr: "no deployed Helm release 'other'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := importTestServer(t, helmReleaseSecret(t, 1, "deployed", nil))
+			result, err := s.handleImportHelmRelease(groupsCtx(tt.groups), importRequest(tt.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text := result.Content[0].(mcpgolang.TextContent).Text; !result.IsError || !strings.Contains(text, tt.wantErr) {
+				t.Errorf("result = %q, want %q", text, tt.wantErr)
+			}
+		})
+	}
+}
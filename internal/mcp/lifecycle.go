@@ -6,10 +6,15 @@ import (
 
 	mcpgolang "github.com/mark3labs/mcp-go/mcp"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+	"github.com/giantswarm/klaus-operator/internal/usage"
 )
 
-// handleStopInstance sets spec.stopped=true on a KlausInstance so the
-// controller scales the Deployment to zero replicas.
+// handleStopInstance sets spec.desiredState to Stopped on a KlausInstance so
+// the controller scales the Deployment to zero replicas, keeping its PVCs and
+// configuration.
 func (s *Server) handleStopInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
 	instance, errResult := s.getOwnedInstance(ctx, request)
 	if errResult != nil {
@@ -17,7 +22,7 @@ func (s *Server) handleStopInstance(ctx context.Context, request mcpgolang.CallT
 	}
 
 	// Already stopped -- return a clear message, not an error.
-	if instance.Spec.Stopped {
+	if resources.IsStopped(instance) {
 		return mcpSuccess(map[string]any{
 			keyName:    instance.Name,
 			keyStatus:  "already_stopped",
@@ -25,9 +30,10 @@ func (s *Server) handleStopInstance(ctx context.Context, request mcpgolang.CallT
 		}), nil
 	}
 
-	// Patch spec.stopped = true using a merge patch.
+	// Patch spec.desiredState using a merge patch.
 	base := instance.DeepCopy()
-	instance.Spec.Stopped = true
+	resources.SetDesiredState(instance, klausv1alpha1.DesiredStateStopped)
+	s.stampProvenance(ctx, request, instance)
 	if err := s.client.Patch(ctx, instance, client.MergeFrom(base)); err != nil {
 		return mcpError("failed to stop instance: " + err.Error()), nil
 	}
@@ -39,16 +45,23 @@ func (s *Server) handleStopInstance(ctx context.Context, request mcpgolang.CallT
 	}), nil
 }
 
-// handleStartInstance clears spec.stopped on a KlausInstance so the
-// controller scales the Deployment back to 1 replica.
+// handleStartInstance sets spec.desiredState to Running on a KlausInstance,
+// clearing the deprecated spec.stopped, so the controller scales the
+// Deployment back to 1 replica.
 func (s *Server) handleStartInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
 	instance, errResult := s.getOwnedInstance(ctx, request)
 	if errResult != nil {
 		return errResult, nil
 	}
 
+	// Suspended instances only resume once their budget is raised.
+	if usage.BudgetExceeded(instance) {
+		return mcpError(fmt.Sprintf("instance '%s' is suspended: its cost of %s USD reached its budget of %g USD; raise max_budget_usd with update_instance to resume it",
+			instance.Name, instance.Status.TokenUsage.CostUSD, *instance.Spec.Claude.MaxBudgetUSD)), nil
+	}
+
 	// Not stopped -- return a clear message, not an error.
-	if !instance.Spec.Stopped {
+	if !resources.IsStopped(instance) {
 		return mcpSuccess(map[string]any{
 			keyName:    instance.Name,
 			keyStatus:  "already_running",
@@ -56,9 +69,19 @@ func (s *Server) handleStartInstance(ctx context.Context, request mcpgolang.Call
 		}), nil
 	}
 
-	// Patch spec.stopped = false using a merge patch.
+	// Starting counts against the owner's instance limit like creating.
+	var instances klausv1alpha1.KlausInstanceList
+	if err := s.client.List(ctx, &instances, client.InNamespace(s.operatorNamespace)); err != nil {
+		return mcpError("failed to list instances: " + err.Error()), nil
+	}
+	if err := s.checkInstanceLimit(ctx, instance.Spec.Owner, runningInstances(instances.Items, instance.Spec.Owner)); err != nil {
+		return toolError(err), nil
+	}
+
+	// Patch spec.desiredState using a merge patch.
 	base := instance.DeepCopy()
-	instance.Spec.Stopped = false
+	resources.SetDesiredState(instance, klausv1alpha1.DesiredStateRunning)
+	s.stampProvenance(ctx, request, instance)
 	if err := s.client.Patch(ctx, instance, client.MergeFrom(base)); err != nil {
 		return mcpError("failed to start instance: " + err.Error()), nil
 	}
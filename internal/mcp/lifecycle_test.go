@@ -53,8 +53,8 @@ func TestHandleStopInstance_Success(t *testing.T) {
 	}, &updated); err != nil {
 		t.Fatalf("failed to get updated instance: %v", err)
 	}
-	if !updated.Spec.Stopped {
-		t.Error("expected spec.stopped to be true after stop")
+	if updated.Spec.DesiredState != klausv1alpha1.DesiredStateStopped {
+		t.Errorf("spec.desiredState = %q, want Stopped after stop", updated.Spec.DesiredState)
 	}
 }
 
@@ -209,8 +209,33 @@ func TestHandleStartInstance_Success(t *testing.T) {
 	}, &updated); err != nil {
 		t.Fatalf("failed to get updated instance: %v", err)
 	}
-	if updated.Spec.Stopped {
-		t.Error("expected spec.stopped to be false after start")
+	if updated.Spec.Stopped || updated.Spec.DesiredState != klausv1alpha1.DesiredStateRunning {
+		t.Errorf("spec = %+v, want desiredState Running and spec.stopped cleared after start", updated.Spec)
+	}
+}
+
+func TestHandleStartInstance_DesiredStateStopped(t *testing.T) {
+	instance := runningInstance("my-agent", "user@example.com", "")
+	instance.Spec.DesiredState = klausv1alpha1.DesiredStateStopped
+	instance.Status.State = klausv1alpha1.InstanceStateStopped
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleStartInstance(authCtx("user@example.com"), req)
+	if err != nil || result.IsError {
+		t.Fatalf("start_instance failed: %v %v", err, result)
+	}
+
+	var updated klausv1alpha1.KlausInstance
+	if err := c.Get(authCtx("user@example.com"), types.NamespacedName{
+		Name: "my-agent", Namespace: "klaus-system",
+	}, &updated); err != nil {
+		t.Fatalf("failed to get updated instance: %v", err)
+	}
+	if updated.Spec.DesiredState != klausv1alpha1.DesiredStateRunning {
+		t.Errorf("spec.desiredState = %q, want Running after start", updated.Spec.DesiredState)
 	}
 }
 
@@ -246,6 +271,36 @@ func TestHandleStartInstance_AlreadyRunning(t *testing.T) {
 	}
 }
 
+func TestHandleStartInstance_BudgetExceeded(t *testing.T) {
+	scheme := testScheme(t)
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	budget := 5.0
+	instance.Spec.Claude.MaxBudgetUSD = &budget
+	instance.Status.State = klausv1alpha1.InstanceStateStopped
+	instance.Status.TokenUsage = &klausv1alpha1.TokenUsage{CostUSD: "5.5"}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build()
+
+	s := &Server{
+		client:            c,
+		operatorNamespace: "klaus-system",
+	}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+
+	result, err := s.handleStartInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected an error for an instance suspended by its budget")
+	}
+	if text := result.Content[0].(mcpgolang.TextContent).Text; !strings.Contains(text, "max_budget_usd") {
+		t.Errorf("error = %q, want a hint to raise max_budget_usd", text)
+	}
+}
+
 func TestHandleStartInstance_AccessDenied(t *testing.T) {
 	scheme := testScheme(t)
 	instance := &klausv1alpha1.KlausInstance{
@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Defaults of the MCP listener.
+const (
+	DefaultReadTimeout     = 30 * time.Second
+	DefaultIdleTimeout     = 2 * time.Minute
+	DefaultMaxRequestBytes = 4 << 20
+)
+
+// mcpEndpointPath is the path MCP requests are served on.
+const mcpEndpointPath = "/mcp"
+
+// ListenerOptions harden the MCP HTTP listener for exposure beyond the
+// cluster.
+type ListenerOptions struct {
+	// CertFile and KeyFile are the PEM serving certificate and key. Both
+	// enable TLS; the files are read again when they change, so certificates
+	// rotated by cert-manager are picked up without a restart.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is a PEM bundle of CAs client certificates must be
+	// signed by. Requires TLS; clients without a valid certificate are
+	// rejected during the handshake.
+	ClientCAFile string
+
+	// ReadTimeout bounds reading a request, headers included.
+	ReadTimeout time.Duration
+
+	// WriteTimeout bounds writing a response. Zero, the default, keeps the
+	// server-sent event streams of long tool calls open.
+	WriteTimeout time.Duration
+
+	// IdleTimeout bounds how long keep-alive connections wait for the next
+	// request.
+	IdleTimeout time.Duration
+
+	// MaxRequestBytes caps the size of request bodies. Zero disables the
+	// limit.
+	MaxRequestBytes int64
+}
+
+// DefaultListenerOptions returns plaintext listener options with the
+// default timeouts and body limit.
+func DefaultListenerOptions() ListenerOptions {
+	return ListenerOptions{
+		ReadTimeout:     DefaultReadTimeout,
+		IdleTimeout:     DefaultIdleTimeout,
+		MaxRequestBytes: DefaultMaxRequestBytes,
+	}
+}
+
+// SetListenerOptions sets the TLS, timeouts and body limit of the MCP
+// listener. Without options it uses DefaultListenerOptions.
+func (s *Server) SetListenerOptions(opts ListenerOptions) {
+	s.listenerOptions = &opts
+}
+
+// newHTTPServer builds the HTTP server serving the MCP endpoint.
+func (s *Server) newHTTPServer() (*http.Server, error) {
+	opts := DefaultListenerOptions()
+	if s.listenerOptions != nil {
+		opts = *s.listenerOptions
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(mcpEndpointPath, limitRequestBody(opts.MaxRequestBytes, s.httpServer))
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           mux,
+		ReadHeaderTimeout: opts.ReadTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+
+	tlsConfig, err := opts.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	srv.TLSConfig = tlsConfig
+	return srv, nil
+}
+
+// tlsConfig returns the server TLS configuration, or nil for plaintext.
+func (o ListenerOptions) tlsConfig() (*tls.Config, error) {
+	if o.CertFile == "" && o.KeyFile == "" {
+		if o.ClientCAFile != "" {
+			return nil, errors.New("a client CA requires a TLS certificate and key")
+		}
+		return nil, nil
+	}
+	if o.CertFile == "" || o.KeyFile == "" {
+		return nil, errors.New("both a TLS certificate and key are required")
+	}
+
+	reloader := &certReloader{certFile: o.CertFile, keyFile: o.KeyFile}
+	if _, err := reloader.certificate(); err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return reloader.certificate()
+		},
+		MinVersion: tls.VersionTLS12,
+	}
+	if o.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(o.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("loading client CA: no certificates found")
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// certReloader serves a key pair from files, loading it again when the
+// certificate file changes.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// certificate returns the current key pair. A key pair that fails to load
+// after a change, such as a certificate not matching its key yet, keeps the
+// previous one in use and is retried on the next handshake.
+func (r *certReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.certFile)
+	switch {
+	case err != nil && r.cert == nil:
+		return nil, fmt.Errorf("reading TLS certificate: %w", err)
+	case err != nil, info.ModTime().Equal(r.modTime):
+		return r.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			slog.Warn("keeping the previous MCP serving certificate", "error", err)
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("loading TLS key pair: %w", err)
+	}
+	r.cert, r.modTime = &cert, info.ModTime()
+	return r.cert, nil
+}
+
+// limitRequestBody rejects request bodies larger than limit bytes. Zero
+// disables the limit.
+func limitRequestBody(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, fmt.Sprintf("request body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serve serves the MCP endpoint on the listener until ctx is cancelled.
+func (s *Server) serve(ctx context.Context, srv *http.Server, listener net.Listener) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ServeTLS(listener, "", "")
+			return
+		}
+		errCh <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		slog.Info("shutting down MCP server")
+		err := srv.Shutdown(context.Background())
+		return errors.Join(err, s.httpServer.Shutdown(context.Background()))
+	}
+}
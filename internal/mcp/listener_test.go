@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/klaus-operator/internal/certs"
+)
+
+const initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+
+// writeKeyPair writes kp into dir and returns the certificate and key paths.
+func writeKeyPair(t *testing.T, dir string, kp *certs.KeyPair) (string, string) {
+	t.Helper()
+	certFile, keyFile := filepath.Join(dir, certs.CertKey), filepath.Join(dir, certs.KeyKey)
+	if err := os.WriteFile(certFile, kp.CertPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, kp.KeyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// startListener serves a new MCP server with opts and returns its address.
+func startListener(t *testing.T, opts ListenerOptions) string {
+	t.Helper()
+	s := NewServer(fake.NewClientBuilder().WithScheme(testScheme(t)).Build(), "klaus-system", "127.0.0.1:0", nil, nil, nil)
+	s.SetListenerOptions(opts)
+	srv, err := s.newHTTPServer()
+	if err != nil {
+		t.Fatalf("newHTTPServer() = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.serve(ctx, srv, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve() = %v", err)
+		}
+	})
+	return listener.Addr().String()
+}
+
+func postInitialize(c *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(initializeRequest))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	return c.Do(req)
+}
+
+func TestListenerTLS(t *testing.T) {
+	now := time.Now()
+	ca, _, err := certs.NewCA("test-ca", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serving, err := ca.IssueServing([]string{"localhost"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPair, err := ca.IssueClient("muster", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, serving)
+	caFile := filepath.Join(dir, certs.CAKey)
+	if err := os.WriteFile(caFile, ca.CertPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultListenerOptions()
+	opts.CertFile, opts.KeyFile, opts.ClientCAFile = certFile, keyFile, caFile
+	addr := startListener(t, opts)
+	_, port, _ := net.SplitHostPort(addr)
+	url := "https://localhost:" + port + mcpEndpointPath
+
+	clientTLS, err := certs.ClientTLSConfig(clientPair.CertPEM, clientPair.KeyPEM, ca.CertPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withCert := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+	resp, err := postInitialize(withCert, url)
+	if err != nil {
+		t.Fatalf("request with a client certificate: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %s, want 200", resp.Status)
+	}
+
+	withoutCert := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:    clientTLS.RootCAs,
+		MinVersion: tls.VersionTLS12,
+	}}}
+	if resp, err := postInitialize(withoutCert, url); err == nil {
+		_ = resp.Body.Close()
+		t.Error("request without a client certificate succeeded")
+	}
+}
+
+func TestListenerRequestBodyLimit(t *testing.T) {
+	opts := DefaultListenerOptions()
+	opts.MaxRequestBytes = 64
+	addr := startListener(t, opts)
+
+	resp, err := postInitialize(http.DefaultClient, "http://"+addr+mcpEndpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %s, want 413 for a body over the limit", resp.Status)
+	}
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	var read int
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		n, err := buf.ReadFrom(r.Body)
+		read = int(n)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	handler := limitRequestBody(8, inner)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("small")))
+	if rec.Code != http.StatusOK || read != 5 {
+		t.Errorf("small body: status %d, read %d", rec.Code, read)
+	}
+
+	// Bodies of unknown length are cut off while reading.
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("far too large"))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || read > 8 {
+		t.Errorf("unknown length: status %d, read %d, want the read to fail at 8 bytes", rec.Code, read)
+	}
+
+	rec = httptest.NewRecorder()
+	limitRequestBody(0, inner).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("far too large")))
+	if rec.Code != http.StatusOK || read != 13 {
+		t.Errorf("without a limit: status %d, read %d, want the whole body", rec.Code, read)
+	}
+}
+
+func TestListenerOptionsTLSConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ListenerOptions
+		want string
+	}{
+		{name: "key without certificate", opts: ListenerOptions{KeyFile: "tls.key"}, want: "both a TLS certificate and key"},
+		{name: "client CA without TLS", opts: ListenerOptions{ClientCAFile: "ca.crt"}, want: "requires a TLS certificate"},
+		{name: "missing files", opts: ListenerOptions{CertFile: "/nonexistent/tls.crt", KeyFile: "/nonexistent/tls.key"}, want: "reading TLS certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.opts.tlsConfig(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("tlsConfig() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if cfg, err := (ListenerOptions{}).tlsConfig(); cfg != nil || err != nil {
+		t.Errorf("tlsConfig() without files = %v, %v, want plaintext", cfg, err)
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	now := time.Now()
+	ca, _, err := certs.NewCA("test-ca", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := ca.IssueServing([]string{"first.example.com"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ca.IssueServing([]string{"second.example.com"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := writeKeyPair(t, dir, first)
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+
+	servedName := func() string {
+		t.Helper()
+		cert, err := r.certificate()
+		if err != nil {
+			t.Fatalf("certificate() = %v", err)
+		}
+		leaf := cert.Leaf
+		if leaf == nil {
+			t.Fatal("certificate has no parsed leaf")
+		}
+		return leaf.DNSNames[0]
+	}
+	if got := servedName(); got != "first.example.com" {
+		t.Fatalf("served %s, want the first certificate", got)
+	}
+
+	// A rotated key pair is served once the certificate file changes.
+	writeKeyPair(t, dir, second)
+	later := now.Add(time.Minute)
+	if err := os.Chtimes(certFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := servedName(); got != "second.example.com" {
+		t.Errorf("served %s after rotation, want the second certificate", got)
+	}
+
+	// A broken update keeps the previous key pair.
+	if err := os.WriteFile(certFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	evenLater := later.Add(time.Minute)
+	if err := os.Chtimes(certFile, evenLater, evenLater); err != nil {
+		t.Fatal(err)
+	}
+	if got := servedName(); got != "second.example.com" {
+		t.Errorf("served %s after a broken update, want the previous certificate", got)
+	}
+}
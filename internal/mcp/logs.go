@@ -0,0 +1,199 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const (
+	// defaultTailLines is the default number of log lines to return.
+	defaultTailLines int64 = 100
+	// maxTailLines caps the number of lines to prevent unbounded reads.
+	maxTailLines int64 = 10_000
+	// maxLogBytes caps the total bytes read from the log stream (1 MiB).
+	maxLogBytes int64 = 1 << 20
+
+	// maxLogFollow caps how long get_instance_logs streams new log lines.
+	maxLogFollow = time.Minute
+)
+
+// instanceLogs are the logs of an instance pod container.
+type instanceLogs struct {
+	instance  *klausv1alpha1.KlausInstance
+	pod       *corev1.Pod
+	container string
+	logs      string
+	truncated bool
+}
+
+// handleGetInstanceLogs returns the klaus or git-clone container logs of an
+// owned instance's pod. Logs can be limited to the last lines and to entries
+// newer than since, taken from the previous terminated container to debug
+// crash loops, or streamed for up to maxLogFollow with follow_seconds.
+func (s *Server) handleGetInstanceLogs(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	logs, errResult := s.readInstanceLogs(ctx, request, "lines")
+	if errResult != nil {
+		return errResult, nil
+	}
+	result := map[string]any{
+		keyName:     logs.instance.Name,
+		"pod":       logs.pod.Name,
+		"phase":     string(logs.pod.Status.Phase),
+		"container": logs.container,
+		"logs":      logs.logs,
+	}
+	if logs.truncated {
+		result["truncated"] = true
+	}
+	return mcpSuccess(result), nil
+}
+
+// handleGetLogs implements the deprecated get_logs tool: get_instance_logs
+// with the line count in tail, returning the raw log text.
+func (s *Server) handleGetLogs(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	logs, errResult := s.readInstanceLogs(ctx, request, "tail")
+	if errResult != nil {
+		return errResult, nil
+	}
+	return &mcpgolang.CallToolResult{
+		Content: []mcpgolang.Content{
+			mcpgolang.NewTextContent(logs.logs),
+		},
+	}, nil
+}
+
+// readInstanceLogs reads the logs of the owned instance named in a request,
+// taking the number of lines from the linesArg argument.
+func (s *Server) readInstanceLogs(ctx context.Context, request mcpgolang.CallToolRequest, linesArg string) (*instanceLogs, *mcpgolang.CallToolResult) {
+	instance, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	args := request.GetArguments()
+	logOpts := &corev1.PodLogOptions{Container: klausContainerName}
+
+	switch v, _ := args["container"].(string); v {
+	case "", klausContainerName:
+	case resources.GitCloneContainerName:
+		logOpts.Container = v
+	default:
+		return nil, mcpError(fmt.Sprintf("invalid container %q: must be %q or %q", v, klausContainerName, resources.GitCloneContainerName))
+	}
+
+	if v, _ := args["since"].(string); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			// Round up, so durations under a second do not become 0,
+			// which the API server rejects.
+			seconds := int64(math.Ceil(d.Seconds()))
+			logOpts.SinceSeconds = &seconds
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			logOpts.SinceTime = &metav1.Time{Time: t}
+		} else {
+			return nil, mcpError(fmt.Sprintf("invalid since %q: must be a duration (e.g. 10m) or an RFC 3339 timestamp", v))
+		}
+	}
+
+	// Without since, default to the last defaultTailLines lines.
+	if v, ok := args[linesArg].(float64); ok && v > 0 {
+		lines := min(int64(v), maxTailLines)
+		logOpts.TailLines = &lines
+	} else if logOpts.SinceSeconds == nil && logOpts.SinceTime == nil {
+		lines := defaultTailLines
+		logOpts.TailLines = &lines
+	}
+
+	if v, _ := args["previous"].(bool); v {
+		logOpts.Previous = true
+	}
+
+	var follow time.Duration
+	if v, ok := args["follow_seconds"].(float64); ok && v > 0 && !logOpts.Previous {
+		follow = min(time.Duration(v*float64(time.Second)), maxLogFollow)
+		logOpts.Follow = true
+	}
+
+	namespace := s.instanceNamespace(instance)
+	pod, errResult := s.instancePod(ctx, instance, namespace)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	readCtx := ctx
+	if follow > 0 {
+		var cancel context.CancelFunc
+		readCtx, cancel = context.WithTimeout(ctx, follow)
+		defer cancel()
+	}
+	logs, truncated, err := s.readPodLogs(readCtx, namespace, pod.Name, logOpts)
+	if err != nil {
+		return nil, mcpError(err.Error())
+	}
+	return &instanceLogs{
+		instance:  instance,
+		pod:       pod,
+		container: logOpts.Container,
+		logs:      logs,
+		truncated: truncated,
+	}, nil
+}
+
+// instancePod returns the pod of an instance, preferring a Running pod when
+// several exist (e.g. during a rollout).
+func (s *Server) instancePod(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (*corev1.Pod, *mcpgolang.CallToolResult) {
+	var podList corev1.PodList
+	sel := labels.SelectorFromSet(resources.SelectorLabels(instance))
+	if err := s.client.List(ctx, &podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return nil, mcpError("failed to list pods: " + err.Error())
+	}
+
+	if len(podList.Items) == 0 {
+		return nil, mcpError("no pods found for instance '" + instance.Name + "' (instance may still be starting)")
+	}
+
+	pod := &podList.Items[0]
+	for i := range podList.Items {
+		if podList.Items[i].Status.Phase == corev1.PodRunning {
+			pod = &podList.Items[i]
+			break
+		}
+	}
+	return pod, nil
+}
+
+// readPodLogs reads a container log stream, capped at maxLogBytes. A follow
+// stream ends when ctx expires; what was read until then is returned.
+func (s *Server) readPodLogs(ctx context.Context, namespace, podName string, logOpts *corev1.PodLogOptions) (string, bool, error) {
+	if s.podLogReader == nil {
+		return "", false, errors.New("pod log reader not configured")
+	}
+
+	stream, err := s.podLogReader.GetLogs(ctx, namespace, podName, logOpts)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get logs for container %q: %v", logOpts.Container, err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	// Read one byte past the cap to detect truncation.
+	logBytes, err := io.ReadAll(io.LimitReader(stream, maxLogBytes+1))
+	if err != nil && !(logOpts.Follow && ctx.Err() != nil) {
+		return "", false, errors.New("failed to read log stream: " + err.Error())
+	}
+	if int64(len(logBytes)) > maxLogBytes {
+		return string(logBytes[:maxLogBytes]), true, nil
+	}
+	return string(logBytes), false, nil
+}
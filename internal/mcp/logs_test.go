@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// blockingPodLogReader returns a follow stream that yields logs and then
+// blocks until the context is cancelled, like a live container log.
+type blockingPodLogReader struct {
+	logs     string
+	lastOpts *corev1.PodLogOptions
+}
+
+func (b *blockingPodLogReader) GetLogs(ctx context.Context, _, _ string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	b.lastOpts = opts
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte(b.logs))
+		<-ctx.Done()
+		_ = pw.CloseWithError(ctx.Err())
+	}()
+	return pr, nil
+}
+
+func instanceLogsServer(t *testing.T, reader PodLogReader) *Server {
+	t.Helper()
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-instance", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-instance-abc123",
+			Namespace: "klaus-user-user-example-com",
+			Labels: map[string]string{
+				"app.kubernetes.io/name":     "klaus",
+				"app.kubernetes.io/instance": "test-instance",
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance, pod).Build()
+	return &Server{client: c, operatorNamespace: "klaus-system", podLogReader: reader}
+}
+
+func callGetInstanceLogs(t *testing.T, s *Server, args map[string]any) (map[string]any, string) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := s.handleGetInstanceLogs(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError {
+		return nil, text
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", text, err)
+	}
+	return data, ""
+}
+
+func TestHandleGetInstanceLogs_Defaults(t *testing.T) {
+	reader := &fakePodLogReader{logs: "starting\n"}
+	s := instanceLogsServer(t, reader)
+
+	data, errText := callGetInstanceLogs(t, s, map[string]any{"name": "test-instance"})
+	if errText != "" {
+		t.Fatalf("unexpected MCP error: %s", errText)
+	}
+	if data["logs"] != "starting\n" || data["pod"] != "test-instance-abc123" || data["phase"] != "Pending" || data["container"] != "klaus" {
+		t.Errorf("result = %v", data)
+	}
+	if _, ok := data["truncated"]; ok {
+		t.Error("expected short logs not to be truncated")
+	}
+	if reader.lastOpts.Container != "klaus" || reader.lastOpts.TailLines == nil || *reader.lastOpts.TailLines != defaultTailLines {
+		t.Errorf("opts = %+v, want the last %d klaus lines", reader.lastOpts, defaultTailLines)
+	}
+	if reader.lastOpts.Follow || reader.lastOpts.Previous {
+		t.Errorf("opts = %+v, want neither follow nor previous", reader.lastOpts)
+	}
+}
+
+func TestHandleGetInstanceLogs_Options(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  map[string]any
+		check func(t *testing.T, opts *corev1.PodLogOptions)
+	}{
+		{
+			name: "git-clone container with lines",
+			args: map[string]any{"container": "git-clone", "lines": float64(20)},
+			check: func(t *testing.T, opts *corev1.PodLogOptions) {
+				if opts.Container != "git-clone" || *opts.TailLines != 20 {
+					t.Errorf("opts = %+v", opts)
+				}
+			},
+		},
+		{
+			name: "lines capped",
+			args: map[string]any{"lines": float64(1_000_000)},
+			check: func(t *testing.T, opts *corev1.PodLogOptions) {
+				if *opts.TailLines != maxTailLines {
+					t.Errorf("TailLines = %d, want %d", *opts.TailLines, maxTailLines)
+				}
+			},
+		},
+		{
+			name: "since duration drops the default tail",
+			args: map[string]any{"since": "10m"},
+			check: func(t *testing.T, opts *corev1.PodLogOptions) {
+				if opts.SinceSeconds == nil || *opts.SinceSeconds != 600 || opts.TailLines != nil {
+					t.Errorf("opts = %+v, want 600s without a tail", opts)
+				}
+			},
+		},
+		{
+			name: "since under a second rounds up",
+			args: map[string]any{"since": "500ms"},
+			check: func(t *testing.T, opts *corev1.PodLogOptions) {
+				if opts.SinceSeconds == nil || *opts.SinceSeconds != 1 {
+					t.Errorf("SinceSeconds = %v, want 1", opts.SinceSeconds)
+				}
+			},
+		},
+		{
+			name: "since timestamp",
+			args: map[string]any{"since": "2026-01-02T03:04:05Z"},
+			check: func(t *testing.T, opts *corev1.PodLogOptions) {
+				want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+				if opts.SinceTime == nil || !opts.SinceTime.Time.Equal(want) {
+					t.Errorf("SinceTime = %v, want %v", opts.SinceTime, want)
+				}
+			},
+		},
+		{
+			name: "previous container",
+			args: map[string]any{"previous": true},
+			check: func(t *testing.T, opts *corev1.PodLogOptions) {
+				if !opts.Previous {
+					t.Error("expected Previous to be set")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &fakePodLogReader{logs: "ok\n"}
+			s := instanceLogsServer(t, reader)
+			tt.args["name"] = "test-instance"
+			if _, errText := callGetInstanceLogs(t, s, tt.args); errText != "" {
+				t.Fatalf("unexpected MCP error: %s", errText)
+			}
+			tt.check(t, reader.lastOpts)
+		})
+	}
+}
+
+func TestHandleGetInstanceLogs_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "unknown container", args: map[string]any{"container": "sidecar"}, wantErr: `invalid container "sidecar"`},
+		{name: "invalid since", args: map[string]any{"since": "yesterday"}, wantErr: `invalid since "yesterday"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := instanceLogsServer(t, &fakePodLogReader{})
+			tt.args["name"] = "test-instance"
+			if _, errText := callGetInstanceLogs(t, s, tt.args); !strings.Contains(errText, tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", errText, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleGetInstanceLogs_Follow(t *testing.T) {
+	reader := &blockingPodLogReader{logs: "line1\nline2\n"}
+	s := instanceLogsServer(t, reader)
+
+	data, errText := callGetInstanceLogs(t, s, map[string]any{"name": "test-instance", "follow_seconds": 0.1})
+	if errText != "" {
+		t.Fatalf("unexpected MCP error: %s", errText)
+	}
+	if !reader.lastOpts.Follow {
+		t.Error("expected Follow to be set")
+	}
+	if data["logs"] != "line1\nline2\n" {
+		t.Errorf("logs = %q, want the lines streamed before the deadline", data["logs"])
+	}
+}
+
+func TestHandleGetInstanceLogs_Truncated(t *testing.T) {
+	s := instanceLogsServer(t, &fakePodLogReader{logs: strings.Repeat("x", int(maxLogBytes)+10)})
+
+	data, errText := callGetInstanceLogs(t, s, map[string]any{"name": "test-instance"})
+	if errText != "" {
+		t.Fatalf("unexpected MCP error: %s", errText)
+	}
+	if data["truncated"] != true || len(data["logs"].(string)) != int(maxLogBytes) {
+		t.Errorf("truncated = %v, len(logs) = %d", data["truncated"], len(data["logs"].(string)))
+	}
+}
+
+func TestHandleGetInstanceLogs_ReaderError(t *testing.T) {
+	s := instanceLogsServer(t, &fakePodLogReader{err: errors.New("container \"git-clone\" is waiting to start")})
+
+	_, errText := callGetInstanceLogs(t, s, map[string]any{"name": "test-instance", "container": "git-clone"})
+	if !strings.Contains(errText, `failed to get logs for container "git-clone"`) {
+		t.Errorf("error = %q", errText)
+	}
+}
@@ -0,0 +1,29 @@
+package mcp
+
+import (
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// SetNamespaceScoped configures the server for an operator running in
+// namespace-scoped mode, where the child resources of instances live in the
+// namespace of the instance rather than in the owner's user namespace.
+func (s *Server) SetNamespaceScoped(namespaceScoped bool) {
+	s.namespaceScoped = namespaceScoped
+}
+
+// instanceNamespace returns the namespace of the child resources of an
+// instance.
+func (s *Server) instanceNamespace(instance *klausv1alpha1.KlausInstance) string {
+	return resources.InstanceNamespace(instance, s.namespaceScoped)
+}
+
+// ownerNamespace returns the namespace the child resources of new instances
+// of an owner are created in. The server creates instances in the operator
+// namespace, so in namespace-scoped mode that is where they run.
+func (s *Server) ownerNamespace(owner string) string {
+	if s.namespaceScoped {
+		return s.operatorNamespace
+	}
+	return resources.UserNamespace(owner)
+}
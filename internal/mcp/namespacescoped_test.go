@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestHandleCreateInstance_NamespaceScoped(t *testing.T) {
+	// Owners whose identities sanitize to the same user namespace do not
+	// collide when instances run in the operator namespace.
+	otherOwner := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "theirs", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user.example@com"},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(otherOwner).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+	s.SetNamespaceScoped(true)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "mine"}
+	result, err := s.handleCreateInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", text)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["namespace"] != "klaus-system" {
+		t.Errorf("namespace = %v, want the operator namespace", got["namespace"])
+	}
+}
+
+func TestHandleCreateInstance_NamespaceScopedQuota(t *testing.T) {
+	exhausted := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "klaus-system"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")},
+			Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")},
+		},
+	}
+	operatorNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "klaus-system"}}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(operatorNamespace, exhausted).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+	s.SetNamespaceScoped(true)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "mine"}
+	result, err := s.handleCreateInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "in namespace klaus-system has no room for pods") {
+		t.Errorf("result = %q, want the operator namespace quota enforced", text)
+	}
+}
+
+func TestInstanceNamespace_NamespaceScoped(t *testing.T) {
+	s := &Server{operatorNamespace: "klaus-system"}
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	if got := s.instanceNamespace(instance); got != "klaus-user-user-example-com" {
+		t.Errorf("instanceNamespace() = %q, want the user namespace", got)
+	}
+	s.SetNamespaceScoped(true)
+	if got := s.instanceNamespace(instance); got != "klaus-system" {
+		t.Errorf("instanceNamespace() = %q, want the instance namespace", got)
+	}
+}
@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+)
+
+// SetPermissionPolicy sets the policy deciding the default and maximum
+// permission mode of instances created through the MCP server. Without a
+// policy, permissions.DefaultPolicy applies.
+func (s *Server) SetPermissionPolicy(policy *permissions.Policy) {
+	s.permissionPolicy = policy
+}
+
+// applyPermissionPolicy defaults spec.claude.permissionMode for the groups in
+// the caller's token and rejects modes more permissive than those groups
+// allow. The operator creates the KlausInstance with its own identity, so the
+// admission webhook cannot see the caller's groups and this is the only place
+// the policy is enforced for MCP-created instances.
+func (s *Server) applyPermissionPolicy(ctx context.Context, spec *klausv1alpha1.KlausInstanceSpec) error {
+	policy := s.policy()
+	groups, err := s.callerGroups(ctx)
+	if err != nil {
+		return err
+	}
+	decision := policy.Decide(groups)
+	decision.ApplyDefault(&spec.Claude)
+	return decision.Check(&spec.Claude)
+}
+
+// SetAdminUsers sets the user identities that are platform admins regardless
+// of their groups, in addition to the admin groups of the permission policy.
+func (s *Server) SetAdminUsers(users []string) {
+	s.adminUsers = users
+}
+
+// requireAdmin returns an error unless the caller is one of the admin users
+// or the groups in their token include an admin group of the permission
+// policy.
+func (s *Server) requireAdmin(ctx context.Context) error {
+	user, err := s.extractUser(ctx)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(s.adminUsers, user) {
+		return nil
+	}
+	policy := s.policy()
+	groups, err := s.callerGroups(ctx)
+	if err != nil {
+		return err
+	}
+	if !policy.Decide(groups).Admin {
+		return fmt.Errorf("access denied: this tool is restricted to platform admins")
+	}
+	return nil
+}
+
+// policy returns the configured permission policy, or
+// permissions.DefaultPolicy.
+func (s *Server) policy() *permissions.Policy {
+	if s.permissionPolicy == nil {
+		return permissions.DefaultPolicy()
+	}
+	return s.permissionPolicy
+}
+
+// callerGroups returns the groups in the caller's token, read from the
+// groups claim of the permission policy or, for verified tokens, of the
+// TokenVerifier.
+func (s *Server) callerGroups(ctx context.Context) ([]string, error) {
+	if s.tokenVerifier != nil {
+		identity, err := s.callerIdentity(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return identity.Groups, nil
+	}
+	groups, err := ExtractGroupsFromToken(AuthTokenFromContext(ctx), s.policy().GroupsClaim)
+	if err != nil {
+		return nil, fmt.Errorf("reading groups from token: %w", err)
+	}
+	return groups, nil
+}
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+)
+
+func groupsCtx(groups string) context.Context {
+	return context.WithValue(context.Background(), authTokenKey,
+		"Bearer "+buildTestJWT(`{"email":"user@example.com","groups":`+groups+`}`))
+}
+
+func testPermissionPolicy() *permissions.Policy {
+	return &permissions.Policy{
+		Default:     klausv1alpha1.PermissionModeDefault,
+		Max:         klausv1alpha1.PermissionModeDefault,
+		GroupsClaim: "groups",
+		AdminGroups: []string{"platform-admins"},
+		Rules: []permissions.Rule{{
+			Groups:  []string{"platform"},
+			Default: klausv1alpha1.PermissionModeBypass,
+			Max:     klausv1alpha1.PermissionModeBypass,
+		}},
+	}
+}
+
+func TestApplyPermissionPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   string
+		claude   klausv1alpha1.ClaudeConfig
+		wantMode klausv1alpha1.PermissionMode
+		wantErr  string
+	}{
+		{
+			name:     "restricted caller gets the restricted default",
+			groups:   `["dev"]`,
+			wantMode: klausv1alpha1.PermissionModeDefault,
+		},
+		{
+			name:    "restricted caller cannot request bypass",
+			groups:  `["dev"]`,
+			claude:  klausv1alpha1.ClaudeConfig{PermissionMode: klausv1alpha1.PermissionModeBypass},
+			wantErr: "exceeds",
+		},
+		{
+			name:     "matching rule raises the default",
+			groups:   `["dev","platform"]`,
+			wantMode: klausv1alpha1.PermissionModeBypass,
+		},
+		{
+			name:    "override requires an admin group",
+			groups:  `["platform"]`,
+			claude:  klausv1alpha1.ClaudeConfig{PermissionMode: klausv1alpha1.PermissionModeBypass, PermissionModeOverride: true},
+			wantErr: "platform admins",
+		},
+		{
+			name:     "admin override",
+			groups:   `["platform-admins"]`,
+			claude:   klausv1alpha1.ClaudeConfig{PermissionMode: klausv1alpha1.PermissionModeBypass, PermissionModeOverride: true},
+			wantMode: klausv1alpha1.PermissionModeBypass,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{}
+			s.SetPermissionPolicy(testPermissionPolicy())
+			spec := &klausv1alpha1.KlausInstanceSpec{Claude: tt.claude}
+			err := s.applyPermissionPolicy(groupsCtx(tt.groups), spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if spec.Claude.PermissionMode != tt.wantMode {
+				t.Errorf("PermissionMode = %q, want %q", spec.Claude.PermissionMode, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestApplyPermissionPolicy_NoPolicy(t *testing.T) {
+	s := &Server{}
+	spec := &klausv1alpha1.KlausInstanceSpec{}
+	if err := s.applyPermissionPolicy(authCtx("user@example.com"), spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Claude.PermissionMode != klausv1alpha1.PermissionModeBypass {
+		t.Errorf("PermissionMode = %q, want %q", spec.Claude.PermissionMode, klausv1alpha1.PermissionModeBypass)
+	}
+}
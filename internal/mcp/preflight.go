@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch
+
+// preflightCreate checks that an instance can be created for user before the
+// KlausInstance is written, so create_instance and run_instance fail fast
+// with an actionable error instead of reporting "creating" for an instance
+// the controller cannot reconcile. It checks that the name is usable for the
+// child resources, that the owner namespace name is valid and not shared
+// with another owner, that the owner is below their instance limit, that the
+// namespace is not terminating, and that its ResourceQuotas leave room for
+// the instance.
+func (s *Server) preflightCreate(ctx context.Context, name, user string, spec *klausv1alpha1.KlausInstanceSpec) error {
+	// The instance name is also the Service name and the instance label
+	// value, both of which are DNS-1035 labels.
+	if errs := validation.IsDNS1035Label(name); len(errs) > 0 {
+		return fmt.Errorf("invalid instance name %q: %s; use lowercase letters, digits and '-', starting with a letter",
+			name, strings.Join(errs, "; "))
+	}
+
+	namespace := s.ownerNamespace(user)
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("owner %q does not map to a valid namespace (%s): %s", user, namespace, strings.Join(errs, "; "))
+	}
+
+	var instances klausv1alpha1.KlausInstanceList
+	if err := s.client.List(ctx, &instances, client.InNamespace(s.operatorNamespace)); err != nil {
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+	for _, inst := range instances.Items {
+		if inst.Name == name {
+			return fmt.Errorf("instance '%s' already exists", name)
+		}
+		if !s.namespaceScoped && inst.Spec.Owner != user && resources.UserNamespace(inst.Spec.Owner) == namespace {
+			return fmt.Errorf("owner namespace %s is already used by instances of another owner whose identity "+
+				"sanitizes to the same name; ask an administrator to resolve the collision", namespace)
+		}
+	}
+	if err := s.checkInstanceLimit(ctx, user, runningInstances(instances.Items, user)); err != nil {
+		return err
+	}
+
+	ns := &corev1.Namespace{}
+	if err := s.client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The controller creates the namespace; there are no quotas yet.
+			return nil
+		}
+		return fmt.Errorf("failed to check namespace %s: %w", namespace, err)
+	}
+	if ns.DeletionTimestamp != nil || ns.Status.Phase == corev1.NamespaceTerminating {
+		return fmt.Errorf("namespace %s is still terminating, usually because your last instance was just deleted; "+
+			"retry once the namespace is gone", namespace)
+	}
+
+	return s.checkQuotaHeadroom(ctx, namespace, instanceQuotaUsage(spec))
+}
+
+// instanceQuotaUsage returns the quota usage added by one instance. Secrets
+// copied into the namespace may be shared between instances, so only the API
+// key Secret is counted.
+func instanceQuotaUsage(spec *klausv1alpha1.KlausInstanceSpec) corev1.ResourceList {
+	one := resource.MustParse("1")
+	usage := corev1.ResourceList{
+		corev1.ResourcePods:       one,
+		corev1.ResourceServices:   one,
+		corev1.ResourceConfigMaps: one,
+		corev1.ResourceSecrets:    one,
+		"count/pods":              one,
+		"count/services":          one,
+		"count/configmaps":        one,
+		"count/secrets":           one,
+		"count/deployments.apps":  one,
+	}
+	if spec.Workspace != nil {
+		usage[corev1.ResourcePersistentVolumeClaims] = one
+		usage["count/persistentvolumeclaims"] = one
+		usage[corev1.ResourceRequestsStorage] = resources.WorkspaceSize(spec.Workspace)
+	}
+	return usage
+}
+
+// checkQuotaHeadroom returns an error naming the first ResourceQuota in
+// namespace that cannot accommodate usage. Scoped quotas only apply to a
+// subset of pods and are skipped.
+func (s *Server) checkQuotaHeadroom(ctx context.Context, namespace string, usage corev1.ResourceList) error {
+	var quotas corev1.ResourceQuotaList
+	if err := s.client.List(ctx, &quotas, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list resource quotas in namespace %s: %w", namespace, err)
+	}
+	for _, quota := range quotas.Items {
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		names := slices.Sorted(maps.Keys(quota.Status.Hard))
+		for _, name := range names {
+			need := usage[name]
+			if need.IsZero() {
+				continue
+			}
+			hard := quota.Status.Hard[name]
+			used := quota.Status.Used[name]
+			remaining := hard.DeepCopy()
+			remaining.Sub(used)
+			if remaining.Cmp(need) < 0 {
+				return fmt.Errorf("resource quota %s in namespace %s has no room for %s (%s of %s used, instance needs %s); "+
+					"delete unused instances or ask an administrator to raise the quota",
+					quota.Name, namespace, name, used.String(), hard.String(), need.String())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const preflightNamespace = "klaus-user-user-example-com"
+
+func quota(name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: preflightNamespace},
+		Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func TestHandleCreateInstance_Preflight(t *testing.T) {
+	terminating := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: preflightNamespace},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+	}
+	active := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: preflightNamespace}}
+	otherOwner := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "theirs", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user.example@com"},
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		objects []client.Object
+		wantErr string
+	}{
+		{
+			name:    "name is not a DNS label",
+			args:    map[string]any{"name": "My.Agent"},
+			wantErr: "invalid instance name",
+		},
+		{
+			name:    "name starts with a digit",
+			args:    map[string]any{"name": "1agent"},
+			wantErr: "invalid instance name",
+		},
+		{
+			name:    "owner namespace collision",
+			args:    map[string]any{"name": "mine"},
+			objects: []client.Object{otherOwner},
+			wantErr: "already used by instances of another owner",
+		},
+		{
+			name:    "name already taken",
+			args:    map[string]any{"name": "theirs"},
+			objects: []client.Object{otherOwner},
+			wantErr: "instance 'theirs' already exists",
+		},
+		{
+			name:    "namespace terminating",
+			args:    map[string]any{"name": "mine"},
+			objects: []client.Object{terminating},
+			wantErr: "is still terminating",
+		},
+		{
+			name: "pod quota exhausted",
+			args: map[string]any{"name": "mine"},
+			objects: []client.Object{active, quota("compute",
+				corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")},
+				corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")})},
+			wantErr: "resource quota compute in namespace " + preflightNamespace + " has no room for pods",
+		},
+		{
+			name: "storage quota too small for the workspace",
+			args: map[string]any{"name": "mine", "workspace_size": "20Gi"},
+			objects: []client.Object{active, quota("storage",
+				corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("50Gi")},
+				corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("40Gi")})},
+			wantErr: "has no room for requests.storage",
+		},
+		{
+			name: "quota with headroom",
+			args: map[string]any{"name": "mine", "workspace_size": "5Gi"},
+			objects: []client.Object{active, quota("compute",
+				corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3"), corev1.ResourceRequestsStorage: resource.MustParse("50Gi")},
+				corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2"), corev1.ResourceRequestsStorage: resource.MustParse("40Gi")})},
+		},
+		{
+			name: "storage quota ignored without a workspace",
+			args: map[string]any{"name": "mine"},
+			objects: []client.Object{active, quota("storage",
+				corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("1Gi")},
+				corev1.ResourceList{corev1.ResourceRequestsStorage: resource.MustParse("1Gi")})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(tt.objects...).Build()
+			s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+			req := mcpgolang.CallToolRequest{}
+			req.Params.Arguments = tt.args
+
+			result, err := s.handleCreateInstance(authCtx("user@example.com"), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			text := result.Content[0].(mcpgolang.TextContent).Text
+			if tt.wantErr == "" {
+				if result.IsError {
+					t.Fatalf("unexpected MCP error: %s", text)
+				}
+				return
+			}
+			if !result.IsError || !strings.Contains(text, tt.wantErr) {
+				t.Fatalf("result = %q, want error containing %q", text, tt.wantErr)
+			}
+
+			var instances klausv1alpha1.KlausInstanceList
+			if err := c.List(t.Context(), &instances, client.InNamespace("klaus-system")); err != nil {
+				t.Fatal(err)
+			}
+			for _, inst := range instances.Items {
+				if inst.Name == tt.args["name"] && inst.Spec.Owner == "user@example.com" {
+					t.Error("instance must not be created when a pre-flight check fails")
+				}
+			}
+		})
+	}
+}
+
+func TestInstanceQuotaUsage(t *testing.T) {
+	usage := instanceQuotaUsage(&klausv1alpha1.KlausInstanceSpec{})
+	if _, ok := usage[corev1.ResourceRequestsStorage]; ok {
+		t.Error("instances without a workspace must not request storage")
+	}
+
+	usage = instanceQuotaUsage(&klausv1alpha1.KlausInstanceSpec{Workspace: &klausv1alpha1.WorkspaceConfig{}})
+	storage := usage[corev1.ResourceRequestsStorage]
+	if storage.Cmp(resource.MustParse("5Gi")) != 0 {
+		t.Errorf("requests.storage = %s, want the 5Gi default", storage.String())
+	}
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// InstancePreviewer renders the child resources the controller creates for
+// a KlausInstance without creating them. The production implementation is
+// the KlausInstanceReconciler, whose errors report the reason of the Ready
+// condition the reconcile would set with a Reason() string method.
+type InstancePreviewer interface {
+	PreviewInstance(ctx context.Context, instance *klausv1alpha1.KlausInstance) ([]client.Object, error)
+}
+
+// SetInstancePreviewer sets the previewer backing preview_instance. Without
+// a previewer the tool reports that previews are unavailable.
+func (s *Server) SetInstancePreviewer(previewer InstancePreviewer) {
+	s.previewer = previewer
+}
+
+// previewResult is the JSON structure returned by preview_instance.
+type previewResult struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Valid     bool   `json:"valid"`
+	Reason    string `json:"reason,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Manifests string `json:"manifests,omitempty"`
+}
+
+// handlePreviewInstance renders the ConfigMaps and Deployment of an instance
+// built from the create_instance parameters, or from a KlausInstance
+// manifest, without creating anything. Personality, skill pack, OCI and
+// KlausMCPServer resolution and validation errors are reported in the result
+// rather than as tool errors, so callers such as CI jobs can tell an invalid
+// instance from a failed call.
+func (s *Server) handlePreviewInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	user, err := s.extractUser(ctx)
+	if err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
+	if s.previewer == nil {
+		return mcpError("instance previews are not available on this server"), nil
+	}
+
+	args := request.GetArguments()
+	instance := &klausv1alpha1.KlausInstance{}
+	if manifest, _ := args["manifest"].(string); manifest != "" {
+		if err := yaml.UnmarshalStrict([]byte(manifest), instance); err != nil {
+			return mcpError("invalid manifest: " + err.Error()), nil
+		}
+		if instance.Kind != "" && instance.Kind != "KlausInstance" {
+			return mcpError(fmt.Sprintf("invalid manifest: kind %s is not KlausInstance", instance.Kind)), nil
+		}
+		// Instances created through the MCP server are owned by the caller.
+		instance.Spec.Owner = user
+	} else {
+		spec, err := buildInstanceSpec(args, user)
+		if err != nil {
+			return mcpError(err.Error()), nil
+		}
+		instance.Spec = spec
+	}
+	if name, _ := args[keyName].(string); name != "" {
+		instance.Name = name
+	}
+	if instance.Name == "" {
+		return mcpError("name is required"), nil
+	}
+	if errs := validation.IsDNS1035Label(instance.Name); len(errs) > 0 {
+		return mcpError(fmt.Sprintf("invalid instance name %q: %s; use lowercase letters, digits and '-', starting with a letter",
+			instance.Name, strings.Join(errs, "; "))), nil
+	}
+	instance.Namespace = s.operatorNamespace
+	if err := s.applyPermissionPolicy(ctx, &instance.Spec); err != nil {
+		return mcpError(err.Error()), nil
+	}
+
+	res := previewResult{Name: instance.Name, Namespace: s.instanceNamespace(instance)}
+	objs, err := s.previewer.PreviewInstance(ctx, instance)
+	if err != nil {
+		res.Error = err.Error()
+		var reasoned interface{ Reason() string }
+		if errors.As(err, &reasoned) {
+			res.Reason = reasoned.Reason()
+		}
+		return mcpSuccess(res), nil
+	}
+
+	var manifests bytes.Buffer
+	for _, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return mcpError("failed to render " + obj.GetName() + ": " + err.Error()), nil
+		}
+		manifests.WriteString("---\n")
+		manifests.Write(data)
+	}
+	res.Valid = true
+	res.Manifests = manifests.String()
+	return mcpSuccess(res), nil
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// fakePreviewer records the instance it previews and renders a Deployment
+// named after it, or fails with err.
+type fakePreviewer struct {
+	instance *klausv1alpha1.KlausInstance
+	err      error
+}
+
+func (p *fakePreviewer) PreviewInstance(_ context.Context, instance *klausv1alpha1.KlausInstance) ([]client.Object, error) {
+	p.instance = instance
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []client.Object{&appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: "klaus-user-user"},
+	}}, nil
+}
+
+// reasonedError is an error with the reason of a Ready condition, like the
+// errors of the KlausInstanceReconciler.
+type reasonedError struct{ reason string }
+
+func (e reasonedError) Error() string  { return "rejected" }
+func (e reasonedError) Reason() string { return e.reason }
+
+func callPreviewInstance(t *testing.T, s *Server, args map[string]any) (*mcpgolang.CallToolResult, previewResult) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := s.handlePreviewInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data previewResult
+	if !result.IsError {
+		if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return result, data
+}
+
+func TestHandlePreviewInstance(t *testing.T) {
+	previewer := &fakePreviewer{}
+	s := cloneTestServer(t)
+	s.SetInstancePreviewer(previewer)
+
+	result, data := callPreviewInstance(t, s, map[string]any{"name": "agent", "model": "claude-opus-4-20250514"})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if !data.Valid || !strings.Contains(data.Manifests, "kind: Deployment") || !strings.Contains(data.Manifests, "name: agent") {
+		t.Errorf("result = %+v, want the rendered Deployment", data)
+	}
+	if previewer.instance.Spec.Owner != "user@example.com" || previewer.instance.Spec.Claude.Model != "claude-opus-4-20250514" {
+		t.Errorf("previewed spec = %+v, want the caller and the model", previewer.instance.Spec)
+	}
+}
+
+func TestHandlePreviewInstance_Manifest(t *testing.T) {
+	previewer := &fakePreviewer{}
+	s := cloneTestServer(t)
+	s.SetInstancePreviewer(previewer)
+
+	manifest := `apiVersion: klaus.giantswarm.io/v1alpha1
+kind: KlausInstance
+metadata:
+  name: from-ci
+spec:
+  owner: someone-else@example.com
+  personality: reviewer
+`
+	result, data := callPreviewInstance(t, s, map[string]any{"manifest": manifest})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if !data.Valid || data.Name != "from-ci" {
+		t.Errorf("result = %+v, want the instance of the manifest", data)
+	}
+	if previewer.instance.Spec.Owner != "user@example.com" || previewer.instance.Spec.Personality != "reviewer" {
+		t.Errorf("previewed spec = %+v, want the manifest owned by the caller", previewer.instance.Spec)
+	}
+
+	result, _ = callPreviewInstance(t, s, map[string]any{"manifest": "kind: KlausJob\nmetadata:\n  name: job\n"})
+	if !result.IsError {
+		t.Error("want an error for a manifest of another kind")
+	}
+}
+
+func TestHandlePreviewInstance_Invalid(t *testing.T) {
+	s := cloneTestServer(t)
+	s.SetInstancePreviewer(&fakePreviewer{err: reasonedError{reason: "PersonalityNotFound"}})
+
+	result, data := callPreviewInstance(t, s, map[string]any{"name": "agent"})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if data.Valid || data.Reason != "PersonalityNotFound" || data.Error != "rejected" || data.Manifests != "" {
+		t.Errorf("result = %+v, want the reason of the error", data)
+	}
+
+	s.SetInstancePreviewer(&fakePreviewer{err: errors.New("plain")})
+	_, data = callPreviewInstance(t, s, map[string]any{"name": "agent"})
+	if data.Valid || data.Reason != "" || data.Error != "plain" {
+		t.Errorf("result = %+v, want the error without a reason", data)
+	}
+}
+
+func TestHandlePreviewInstance_Errors(t *testing.T) {
+	s := cloneTestServer(t)
+	result, _ := callPreviewInstance(t, s, map[string]any{"name": "agent"})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "not available") {
+		t.Errorf("result = %v, want an error without a previewer", result.Content)
+	}
+
+	s.SetInstancePreviewer(&fakePreviewer{})
+	result, _ = callPreviewInstance(t, s, map[string]any{})
+	if !result.IsError {
+		t.Error("want an error without a name")
+	}
+	result, _ = callPreviewInstance(t, s, map[string]any{"name": "Agent_1"})
+	if !result.IsError {
+		t.Error("want an error for an invalid name")
+	}
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Provenance annotations stamped on the objects the MCP tools create or
+// change. The controller never rewrites the annotations of a KlausInstance,
+// so they survive reconciles and show up in kubectl describe.
+const (
+	// AnnotationCreatedBy is the subject that created the object through
+	// the MCP server. It is set once on creation.
+	AnnotationCreatedBy = "klaus.giantswarm.io/created-by"
+
+	// AnnotationMCPSubject is the subject of the last MCP request that
+	// changed the object.
+	AnnotationMCPSubject = "klaus.giantswarm.io/mcp-subject"
+
+	// AnnotationMCPSession is the MCP client session ID of that request.
+	AnnotationMCPSession = "klaus.giantswarm.io/mcp-session"
+
+	// AnnotationMCPTool is the tool of that request.
+	AnnotationMCPTool = "klaus.giantswarm.io/mcp-tool"
+
+	// AnnotationMCPTimestamp is when that request was handled, in RFC 3339.
+	AnnotationMCPTimestamp = "klaus.giantswarm.io/mcp-timestamp"
+)
+
+// stampProvenance records the caller, client session and tool of an MCP
+// request in the annotations of obj. Objects that do not exist yet also get
+// AnnotationCreatedBy. The session annotation is omitted outside of a
+// client session.
+func (s *Server) stampProvenance(ctx context.Context, request mcpgolang.CallToolRequest, obj metav1.Object) {
+	user, _ := s.extractUser(ctx)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if obj.GetResourceVersion() == "" && user != "" {
+		annotations[AnnotationCreatedBy] = user
+	}
+	annotations[AnnotationMCPSubject] = user
+	annotations[AnnotationMCPTool] = request.Params.Name
+	annotations[AnnotationMCPTimestamp] = time.Now().UTC().Format(time.RFC3339)
+	if session := server.ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+		annotations[AnnotationMCPSession] = session.SessionID()
+	} else {
+		delete(annotations, AnnotationMCPSession)
+	}
+	obj.SetAnnotations(annotations)
+}
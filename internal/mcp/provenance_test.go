@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// testSession is a minimal MCP client session.
+type testSession struct{ id string }
+
+func (s testSession) Initialize()                                               {}
+func (s testSession) Initialized() bool                                         { return true }
+func (s testSession) NotificationChannel() chan<- mcpgolang.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                         { return s.id }
+
+func sessionCtx(ctx context.Context, id string) context.Context {
+	return server.NewMCPServer("test", "0.0.0").WithContext(ctx, testSession{id: id})
+}
+
+func getTestInstance(t *testing.T, s *Server, name string) *klausv1alpha1.KlausInstance {
+	t.Helper()
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("getting instance: %v", err)
+	}
+	return &instance
+}
+
+func TestProvenance_Create(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Name = "create_instance"
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+
+	result, err := s.handleCreateInstance(sessionCtx(authCtx("user@example.com"), "session-1"), req)
+	if err != nil || result.IsError {
+		t.Fatalf("create_instance failed: %v %v", err, result)
+	}
+
+	annotations := getTestInstance(t, s, "my-agent").Annotations
+	if annotations[AnnotationCreatedBy] != "user@example.com" || annotations[AnnotationMCPSubject] != "user@example.com" {
+		t.Errorf("subject annotations = %v", annotations)
+	}
+	if annotations[AnnotationMCPSession] != "session-1" {
+		t.Errorf("session = %q, want session-1", annotations[AnnotationMCPSession])
+	}
+	if annotations[AnnotationMCPTool] != "create_instance" {
+		t.Errorf("tool = %q, want create_instance", annotations[AnnotationMCPTool])
+	}
+	if _, err := time.Parse(time.RFC3339, annotations[AnnotationMCPTimestamp]); err != nil {
+		t.Errorf("timestamp %q is not RFC 3339: %v", annotations[AnnotationMCPTimestamp], err)
+	}
+}
+
+func TestProvenance_Update(t *testing.T) {
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	instance.Annotations = map[string]string{
+		AnnotationCreatedBy:  "admin@example.com",
+		AnnotationMCPSession: "session-1",
+		"example.com/keep":   "yes",
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Name = "stop_instance"
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+
+	result, err := s.handleStopInstance(authCtx("user@example.com"), req)
+	if err != nil || result.IsError {
+		t.Fatalf("stop_instance failed: %v %v", err, result)
+	}
+
+	annotations := getTestInstance(t, s, "my-agent").Annotations
+	if annotations[AnnotationCreatedBy] != "admin@example.com" {
+		t.Errorf("created-by = %q, want it unchanged", annotations[AnnotationCreatedBy])
+	}
+	if annotations[AnnotationMCPSubject] != "user@example.com" || annotations[AnnotationMCPTool] != "stop_instance" {
+		t.Errorf("provenance annotations = %v, want the stop_instance request", annotations)
+	}
+	if _, ok := annotations[AnnotationMCPSession]; ok {
+		t.Errorf("session = %q, want the stale session removed", annotations[AnnotationMCPSession])
+	}
+	if annotations["example.com/keep"] != "yes" {
+		t.Error("unrelated annotations must be kept")
+	}
+}
@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustenants,verbs=get;list;watch
+
+// Codes of the structured errors returned when a limit is exceeded.
+const (
+	errorCodeRateLimited           = "rate_limited"
+	errorCodeInstanceLimitExceeded = "instance_limit_exceeded"
+)
+
+// idleBucketTTL is how long the bucket of a user without calls is kept, so
+// memory is bounded by the recently active users. A user returning after
+// that starts with a full bucket.
+const idleBucketTTL = 10 * time.Minute
+
+// Limits are the operator-wide limits on MCP callers. A KlausTenant naming
+// an owner, or the default KlausTenant, overrides them. Zero disables a
+// limit.
+type Limits struct {
+	// RequestsPerMinute is the rate each user's tool call token bucket
+	// refills at.
+	RequestsPerMinute int
+
+	// Burst is the token bucket size. Defaults to RequestsPerMinute.
+	Burst int
+
+	// MaxInstancesPerOwner caps the instances of an owner that are not
+	// stopped.
+	MaxInstancesPerOwner int
+}
+
+// SetLimits sets the operator-wide rate and instance limits. Without limits
+// only KlausTenants limit callers.
+func (s *Server) SetLimits(limits Limits) {
+	s.limits = limits
+}
+
+// limitError is returned when a caller exceeds a limit. It is reported as a
+// structured error so clients can back off instead of retrying at once.
+type limitError struct {
+	code       string
+	message    string
+	retryAfter time.Duration
+}
+
+func (e *limitError) Error() string {
+	return e.message
+}
+
+// toolError returns the tool error for err: a structured error for
+// exceeded limits and the error text otherwise.
+func toolError(err error) *mcpgolang.CallToolResult {
+	var limitErr *limitError
+	if !errors.As(err, &limitErr) {
+		return mcpError(err.Error())
+	}
+	data := map[string]any{
+		"error":    limitErr.code,
+		keyMessage: limitErr.message,
+	}
+	if limitErr.retryAfter > 0 {
+		data["retryAfterSeconds"] = int64(math.Ceil(limitErr.retryAfter.Seconds()))
+	}
+	jsonBytes, _ := json.MarshalIndent(data, "", "  ")
+	return &mcpgolang.CallToolResult{
+		Content: []mcpgolang.Content{mcpgolang.NewTextContent(string(jsonBytes))},
+		IsError: true,
+	}
+}
+
+// ownerLimits returns the limits of an owner: the operator-wide limits with
+// those set by the owner's KlausTenant taking precedence.
+func (s *Server) ownerLimits(ctx context.Context, owner string) (Limits, error) {
+	limits := s.limits
+	var tenantList klausv1alpha1.KlausTenantList
+	if err := s.client.List(ctx, &tenantList, client.InNamespace(s.operatorNamespace)); err != nil {
+		return limits, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	tenant := resources.SelectTenant(tenantList.Items, owner)
+	if tenant == nil {
+		return limits, nil
+	}
+	if mcp := tenant.Spec.MCP; mcp != nil {
+		limits.RequestsPerMinute, limits.Burst = int(mcp.RequestsPerMinute), int(mcp.Burst)
+	}
+	limits.MaxInstancesPerOwner = resources.InstanceLimit(tenant, limits.MaxInstancesPerOwner)
+	return limits, nil
+}
+
+// checkInstanceLimit fails when the owner's instances that are not stopped,
+// given as running, already reach the owner's instance limit.
+func (s *Server) checkInstanceLimit(ctx context.Context, owner string, running int) error {
+	limits, err := s.ownerLimits(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if limits.MaxInstancesPerOwner > 0 && running >= limits.MaxInstancesPerOwner {
+		return &limitError{
+			code: errorCodeInstanceLimitExceeded,
+			message: fmt.Sprintf("owner %s already has %d running instances, the maximum of %d; "+
+				"stop or delete an instance first", owner, running, limits.MaxInstancesPerOwner),
+		}
+	}
+	return nil
+}
+
+// runningInstances counts the owner's instances that are not stopped or
+// being deleted.
+func runningInstances(instances []klausv1alpha1.KlausInstance, owner string) int {
+	running := 0
+	for i := range instances {
+		inst := &instances[i]
+		if inst.Spec.Owner == owner && resources.CountsAgainstInstanceLimit(inst) {
+			running++
+		}
+	}
+	return running
+}
+
+// rateLimitTools is a tool handler middleware limiting the tool calls of
+// each user with a token bucket. Calls without an identity pass through;
+// the tools reject them.
+func (s *Server) rateLimitTools() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+			user, err := s.extractUser(ctx)
+			if err != nil {
+				return next(ctx, request)
+			}
+			limits, err := s.ownerLimits(ctx, user)
+			if err != nil {
+				return mcpError(err.Error()), nil
+			}
+			if limits.RequestsPerMinute <= 0 {
+				return next(ctx, request)
+			}
+			if wait := s.rateLimiter.take(user, limits, time.Now()); wait > 0 {
+				return toolError(&limitError{
+					code:       errorCodeRateLimited,
+					message:    fmt.Sprintf("rate limit of %d tool calls per minute exceeded for %s", limits.RequestsPerMinute, user),
+					retryAfter: wait,
+				}), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// rateLimiter holds a token bucket per user. The zero value is ready to use.
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket is a token bucket refilled continuously.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from the user's bucket at now. It returns zero when a
+// token was available and how long until the next one otherwise.
+func (l *rateLimiter) take(user string, limits Limits, now time.Time) time.Duration {
+	rate := float64(limits.RequestsPerMinute) / 60
+	size := float64(limits.Burst)
+	if size <= 0 {
+		size = float64(limits.RequestsPerMinute)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+	if now.Sub(l.lastSweep) > idleBucketTTL {
+		for key, b := range l.buckets {
+			if now.Sub(b.last) > idleBucketTTL {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[user]
+	if !ok {
+		b = &bucket{tokens: size, last: now}
+		l.buckets[user] = b
+	}
+	// Limits can change between calls; a smaller bucket is capped at once.
+	b.tokens = min(size, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func limitTestServer(t *testing.T, limits Limits, objs ...client.Object) *Server {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objs...).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+	s.SetLimits(limits)
+	return s
+}
+
+func limitTestInstance(name, owner string, stopped bool) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: owner, Stopped: stopped},
+	}
+}
+
+// limitErrorData parses a structured limit error.
+func limitErrorData(t *testing.T, result *mcpgolang.CallToolResult) map[string]any {
+	t.Helper()
+	if !result.IsError {
+		t.Fatalf("result = %v, want an error", result.Content)
+	}
+	var data map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("error is not structured: %v", err)
+	}
+	return data
+}
+
+func TestRateLimiterTake(t *testing.T) {
+	var l rateLimiter
+	limits := Limits{RequestsPerMinute: 60, Burst: 2}
+	now := time.Now()
+
+	for i := range 2 {
+		if wait := l.take("alice", limits, now); wait != 0 {
+			t.Fatalf("call %d waited %v, want the burst allowed", i, wait)
+		}
+	}
+	if wait := l.take("alice", limits, now); wait != time.Second {
+		t.Errorf("wait = %v, want 1s at one call per second", wait)
+	}
+	if wait := l.take("bob", limits, now); wait != 0 {
+		t.Errorf("bob waited %v, want a bucket per user", wait)
+	}
+	if wait := l.take("alice", limits, now.Add(time.Second)); wait != 0 {
+		t.Errorf("wait after refill = %v, want 0", wait)
+	}
+}
+
+func TestRateLimiterBurstDefaultsToRate(t *testing.T) {
+	var l rateLimiter
+	now := time.Now()
+	for i := range 3 {
+		if wait := l.take("alice", Limits{RequestsPerMinute: 3}, now); wait != 0 {
+			t.Fatalf("call %d waited %v, want a bucket of 3", i, wait)
+		}
+	}
+	if wait := l.take("alice", Limits{RequestsPerMinute: 3}, now); wait == 0 {
+		t.Error("fourth call allowed, want it limited")
+	}
+}
+
+func TestRateLimiterDropsIdleBuckets(t *testing.T) {
+	var l rateLimiter
+	now := time.Now()
+	l.take("alice", Limits{RequestsPerMinute: 1}, now)
+	l.take("bob", Limits{RequestsPerMinute: 1}, now.Add(2*idleBucketTTL))
+	if _, ok := l.buckets["alice"]; ok {
+		t.Error("idle bucket was kept")
+	}
+	if _, ok := l.buckets["bob"]; !ok {
+		t.Error("active bucket was dropped")
+	}
+}
+
+func TestRateLimitTools(t *testing.T) {
+	s := limitTestServer(t, Limits{RequestsPerMinute: 1})
+	handler := s.rateLimitTools()(func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return mcpSuccess(map[string]any{}), nil
+	})
+
+	if result, _ := handler(authCtx("user@example.com"), mcpgolang.CallToolRequest{}); result.IsError {
+		t.Fatalf("first call = %v, want it allowed", result.Content)
+	}
+	result, err := handler(authCtx("user@example.com"), mcpgolang.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := limitErrorData(t, result)
+	if data["error"] != errorCodeRateLimited || data["retryAfterSeconds"] != float64(60) {
+		t.Errorf("error = %v, want rate_limited with a retry after 60s", data)
+	}
+
+	// Unauthenticated calls reach the tool, which rejects them.
+	if result, _ := handler(context.Background(), mcpgolang.CallToolRequest{}); result.IsError {
+		t.Errorf("unauthenticated call = %v, want it passed through", result.Content)
+	}
+}
+
+func TestRateLimitToolsTenantOverride(t *testing.T) {
+	tenant := &klausv1alpha1.KlausTenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausTenantSpec{
+			Owner: "user@example.com",
+			MCP:   &klausv1alpha1.MCPRateLimit{RequestsPerMinute: 60, Burst: 3},
+		},
+	}
+	s := limitTestServer(t, Limits{RequestsPerMinute: 1}, tenant)
+	handler := s.rateLimitTools()(func(context.Context, mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+		return mcpSuccess(map[string]any{}), nil
+	})
+
+	for i := range 3 {
+		if result, _ := handler(authCtx("user@example.com"), mcpgolang.CallToolRequest{}); result.IsError {
+			t.Fatalf("call %d = %v, want the tenant's burst of 3", i, result.Content)
+		}
+	}
+	handler(authCtx("other@example.com"), mcpgolang.CallToolRequest{})
+	if result, _ := handler(authCtx("other@example.com"), mcpgolang.CallToolRequest{}); !result.IsError {
+		t.Error("second call of another owner allowed, want the operator limit")
+	}
+}
+
+func TestHandleCreateInstance_InstanceLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		objects []client.Object
+		tenant  int32
+		wantErr bool
+	}{
+		{
+			name:    "below the limit",
+			limits:  Limits{MaxInstancesPerOwner: 2},
+			objects: []client.Object{limitTestInstance("first", "user@example.com", false)},
+		},
+		{
+			name:    "at the limit",
+			limits:  Limits{MaxInstancesPerOwner: 1},
+			objects: []client.Object{limitTestInstance("first", "user@example.com", false)},
+			wantErr: true,
+		},
+		{
+			name:   "stopped and other owners' instances do not count",
+			limits: Limits{MaxInstancesPerOwner: 1},
+			objects: []client.Object{
+				limitTestInstance("first", "user@example.com", true),
+				limitTestInstance("theirs", "other@example.com", false),
+			},
+		},
+		{
+			name:    "tenant raises the limit",
+			limits:  Limits{MaxInstancesPerOwner: 1},
+			objects: []client.Object{limitTestInstance("first", "user@example.com", false)},
+			tenant:  2,
+		},
+		{
+			name:    "default tenant sets the limit",
+			objects: []client.Object{limitTestInstance("first", "user@example.com", false)},
+			tenant:  1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := tt.objects
+			if tt.tenant > 0 {
+				objects = append(objects, &klausv1alpha1.KlausTenant{
+					ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "klaus-system"},
+					Spec:       klausv1alpha1.KlausTenantSpec{MaxInstances: tt.tenant},
+				})
+			}
+			s := limitTestServer(t, tt.limits, objects...)
+
+			req := mcpgolang.CallToolRequest{}
+			req.Params.Arguments = map[string]any{keyName: "second"}
+			result, err := s.handleCreateInstance(authCtx("user@example.com"), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantErr {
+				if result.IsError {
+					t.Fatalf("create = %v, want it allowed", result.Content)
+				}
+				return
+			}
+			if data := limitErrorData(t, result); data["error"] != errorCodeInstanceLimitExceeded {
+				t.Errorf("error = %v, want instance_limit_exceeded", data)
+			}
+		})
+	}
+}
+
+func TestHandleStartInstance_InstanceLimit(t *testing.T) {
+	s := limitTestServer(t, Limits{MaxInstancesPerOwner: 1},
+		limitTestInstance("running", "user@example.com", false),
+		limitTestInstance("stopped", "user@example.com", true),
+	)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{keyName: "stopped"}
+	result, err := s.handleStartInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := limitErrorData(t, result); data["error"] != errorCodeInstanceLimitExceeded {
+		t.Errorf("error = %v, want instance_limit_exceeded", data)
+	}
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// instanceResult is the JSON structure returned by handleGetInstanceResult.
+type instanceResult struct {
+	Instance string                        `json:"instance"`
+	State    string                        `json:"state"`
+	Result   *klausv1alpha1.InstanceResult `json:"result,omitempty"`
+	Message  string                        `json:"message,omitempty"`
+}
+
+// FetchResult returns the result of the last completed prompt of a running
+// agent, or nil while the agent is still working or not reachable. It lets
+// the controller record results of prompts it did not send.
+func (s *Server) FetchResult(ctx context.Context, instance *klausv1alpha1.KlausInstance) (*klausv1alpha1.InstanceResult, error) {
+	if s.agentClient == nil {
+		return nil, nil
+	}
+	baseURL, errResult := s.agentBaseURL(instance)
+	if errResult != nil {
+		return nil, nil
+	}
+	toolResult, err := s.agentClient.Result(ctx, instance.Name, baseURL, false)
+	if err != nil {
+		return nil, fmt.Errorf("fetching result: %w", err)
+	}
+	if toolResult.IsError {
+		return nil, nil
+	}
+	return completedResult(instance, extractText(toolResult)), nil
+}
+
+// completedResult returns the status.result for the response of the agent's
+// result tool, or nil when the agent has not completed a prompt. Responses
+// that are not the expected JSON structure are taken as the output, like
+// get_result does.
+func completedResult(instance *klausv1alpha1.KlausInstance, text string) *klausv1alpha1.InstanceResult {
+	var parsed agentToolResponse
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil || parsed.Status == "" {
+		return resources.BuildInstanceResult(instance, text, 0, time.Now())
+	}
+	if parsed.Status != statusCompleted {
+		return nil
+	}
+	return resources.BuildInstanceResult(instance, parsed.ResultText, parsed.MessageCount, time.Now())
+}
+
+// recordResult records the response of the agent's result tool in the
+// status.result of the named instance, with the ResultValid condition.
+// Failures, including conflicts, are only logged; the result reporter
+// records the result on its next refresh.
+func (s *Server) recordResult(ctx context.Context, name, text string) {
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(ctx, types.NamespacedName{Name: name, Namespace: s.operatorNamespace}, &instance); err != nil {
+		return
+	}
+	result := completedResult(&instance, text)
+	if result == nil || !resources.ResultChanged(instance.Status.Result, result) {
+		return
+	}
+	base := instance.DeepCopy()
+	instance.Status.Result = result
+	if status, reason, message, ok := resources.ResultCondition(&instance); ok {
+		apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+			Type:               conditionResultValid,
+			Status:             status,
+			ObservedGeneration: instance.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+	// The conditions are patched as a whole, so a concurrent reconcile must
+	// not be overwritten.
+	patch := client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{})
+	if err := s.client.Status().Patch(ctx, &instance, patch); client.IgnoreNotFound(err) != nil {
+		slog.Warn("recording instance result failed", "instance", name, "error", err)
+	}
+}
+
+// handleGetInstanceResult returns the result recorded in status.result of
+// an owned instance. Unlike get_result it does not contact the agent, so it
+// also works while the instance is stopped.
+func (s *Server) handleGetInstanceResult(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	instance, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	res := instanceResult{
+		Instance: instance.Name,
+		State:    string(instance.Status.State),
+		Result:   instance.Status.Result,
+	}
+	if res.Result == nil {
+		res.Message = "no result recorded yet; results of agent mode instances are recorded once a prompt completes"
+	}
+	return mcpSuccess(res), nil
+}
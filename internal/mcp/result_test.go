@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func resultTestServer(t *testing.T, agent *fakeAgentMCPClient, instance *klausv1alpha1.KlausInstance) *Server {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(instance).
+		WithStatusSubresource(&klausv1alpha1.KlausInstance{}).
+		Build()
+	return &Server{client: c, operatorNamespace: "klaus-system", agentClient: agent}
+}
+
+func recordedResult(t *testing.T, c client.Client, name string) *klausv1alpha1.InstanceResult {
+	t.Helper()
+	var instance klausv1alpha1.KlausInstance
+	if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	return instance.Status.Result
+}
+
+func TestFetchResult(t *testing.T) {
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	instance.Spec.Claude.JSONSchema = `{"type":"object"}`
+
+	tests := []struct {
+		name     string
+		response *mcpgolang.CallToolResult
+		wantText string
+		wantNil  bool
+	}{
+		{name: "completed", response: textResult(`{"status":"completed","message_count":3,"result_text":"{ \"ok\": true }"}`), wantText: `{"ok":true}`},
+		{name: "still working", response: textResult(`{"status":"busy","message_count":2}`), wantNil: true},
+		{name: "agent error", response: errorResult("no session"), wantNil: true},
+		{name: "unstructured response", response: textResult(`{"ok":false}`), wantText: `{"ok":false}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := resultTestServer(t, &fakeAgentMCPClient{resultResult: tt.response}, instance)
+
+			result, err := s.FetchResult(context.Background(), instance)
+			if err != nil {
+				t.Fatalf("FetchResult: %v", err)
+			}
+			if tt.wantNil {
+				if result != nil {
+					t.Errorf("result = %+v, want nil", result)
+				}
+				return
+			}
+			if result == nil || result.Text != tt.wantText || !result.Structured {
+				t.Errorf("result = %+v, want structured %q", result, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestFetchResult_NotRunning(t *testing.T) {
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	instance.Status.State = klausv1alpha1.InstanceStateStopped
+	agent := &fakeAgentMCPClient{}
+	s := resultTestServer(t, agent, instance)
+
+	result, err := s.FetchResult(context.Background(), instance)
+	if err != nil || result != nil {
+		t.Errorf("FetchResult = %+v, %v; want nil without contacting the agent", result, err)
+	}
+	if agent.lastInstanceName != "" {
+		t.Error("the agent of a stopped instance was contacted")
+	}
+}
+
+func TestHandleGetResult_RecordsResult(t *testing.T) {
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	agent := &fakeAgentMCPClient{
+		resultResult: textResult(`{"status":"completed","message_count":5,"result_text":"Task done successfully"}`),
+	}
+	s := resultTestServer(t, agent, instance)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	if _, err := s.handleGetResult(authCtx("user@example.com"), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := recordedResult(t, s.client, "my-agent")
+	if result == nil || result.Text != "Task done successfully" || result.MessageCount != 5 {
+		t.Errorf("status.result = %+v, want the completed result", result)
+	}
+}
+
+func TestHandleGetInstanceResult(t *testing.T) {
+	instance := runningInstance("my-agent", "user@example.com", "http://my-agent.klaus:8080")
+	instance.Status.State = klausv1alpha1.InstanceStateStopped
+	instance.Status.Result = &klausv1alpha1.InstanceResult{Text: `{"ok":true}`, Structured: true, CompletedAt: metav1.Now()}
+	s := resultTestServer(t, &fakeAgentMCPClient{}, instance)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleGetInstanceResult(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", result.Content[0].(mcpgolang.TextContent).Text)
+	}
+
+	var data instanceResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.State != "Stopped" || data.Result == nil || data.Result.Text != `{"ok":true}` || !data.Result.Structured {
+		t.Errorf("response = %+v, want the recorded structured result", data)
+	}
+}
+
+func TestHandleGetInstanceResult_NoResult(t *testing.T) {
+	s := resultTestServer(t, &fakeAgentMCPClient{}, runningInstance("my-agent", "user@example.com", ""))
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleGetInstanceResult(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var data instanceResult
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Result != nil || data.Message == "" {
+		t.Errorf("response = %+v, want a message without result", data)
+	}
+}
+
+func TestHandleGetInstanceResult_AccessDenied(t *testing.T) {
+	s := resultTestServer(t, &fakeAgentMCPClient{}, runningInstance("my-agent", "owner@example.com", ""))
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleGetInstanceResult(authCtx("other@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected an access error for another owner's instance")
+	}
+}
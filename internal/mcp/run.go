@@ -11,7 +11,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
-	"github.com/giantswarm/klaus-operator/internal/resources"
 )
 
 const (
@@ -67,6 +66,14 @@ func (s *Server) handleRunInstance(ctx context.Context, request mcpgolang.CallTo
 		return mcpError(err.Error()), nil
 	}
 
+	if err := s.applyPermissionPolicy(ctx, &spec); err != nil {
+		return mcpError(err.Error()), nil
+	}
+
+	if err := s.preflightCreate(ctx, name, user, &spec); err != nil {
+		return toolError(err), nil
+	}
+
 	// Stage 1: Create the KlausInstance CR.
 	instance := &klausv1alpha1.KlausInstance{
 		ObjectMeta: metav1.ObjectMeta{
@@ -75,6 +82,7 @@ func (s *Server) handleRunInstance(ctx context.Context, request mcpgolang.CallTo
 		},
 		Spec: spec,
 	}
+	s.stampProvenance(ctx, request, instance)
 
 	if err := s.client.Create(ctx, instance); err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -109,7 +117,7 @@ func (s *Server) handleRunInstance(ctx context.Context, request mcpgolang.CallTo
 		Name:      name,
 		Owner:     user,
 		Model:     spec.Claude.Model,
-		Namespace: resources.UserNamespace(user),
+		Namespace: s.ownerNamespace(user),
 		Status:    statusStarted,
 		SessionID: s.agentClient.SessionID(name),
 		Result:    extractText(toolResult),
@@ -126,6 +134,7 @@ func (s *Server) handleRunInstance(ctx context.Context, request mcpgolang.CallTo
 	if err != nil {
 		return mcpError(fmt.Sprintf("prompt sent but waiting for result failed: %v", err)), nil
 	}
+	s.recordResult(ctx, name, result)
 
 	res.Status = statusCompleted
 	res.Result = result
@@ -146,8 +155,8 @@ func (s *Server) waitForRunning(ctx context.Context, name string) (string, error
 
 		switch instance.Status.State {
 		case klausv1alpha1.InstanceStateRunning:
-			if instance.Status.Endpoint != "" {
-				return instance.Status.Endpoint, nil
+			if endpoint := internalEndpoint(&instance); endpoint != "" {
+				return endpoint, nil
 			}
 			// Running but no endpoint yet; keep polling.
 		case klausv1alpha1.InstanceStateError:
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// savedPersonalityResult is the JSON structure returned by
+// save_as_personality.
+type savedPersonalityResult struct {
+	Name        string   `json:"name"`
+	Personality string   `json:"personality"`
+	Instance    string   `json:"instance"`
+	Omitted     []string `json:"omitted,omitempty"`
+	NextSteps   []string `json:"nextSteps,omitempty"`
+}
+
+// handleSaveAsPersonality extracts an inline personality from the effective
+// spec of an instance the caller owns: the spec with its inline personality
+// merged in, as the controller reconciles it. The personality is returned
+// together with a KlausInstance manifest using it, for the caller to share;
+// nothing is created. Instances of an OCI personality are rejected, as the
+// content of the artifact is not part of their spec.
+func (s *Server) handleSaveAsPersonality(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	user, err := s.extractUser(ctx)
+	if err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
+	source, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+	if source.Spec.Personality != "" {
+		return mcpError(fmt.Sprintf("instance '%s' uses the OCI personality %s; its content is not part of the instance spec, reference the personality instead",
+			source.Name, source.Spec.Personality)), nil
+	}
+
+	args := request.GetArguments()
+	name, _ := args["new_name"].(string)
+	if name == "" {
+		name = defaultScaffoldName
+	}
+	description, _ := args["description"].(string)
+
+	personality, omitted, err := s.extractPersonality(ctx, source)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+	if description != "" {
+		personality.Description = description
+	}
+
+	instance := &klausv1alpha1.KlausInstance{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: klausv1alpha1.GroupVersion.String(),
+			Kind:       "KlausInstance",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.operatorNamespace,
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:             user,
+			Claude:            klausv1alpha1.ClaudeConfig{Model: source.Spec.Claude.Model},
+			InlinePersonality: personality,
+		},
+	}
+	personalityYAML, err := yaml.Marshal(personality)
+	if err != nil {
+		return mcpError("failed to encode personality: " + err.Error()), nil
+	}
+	manifest, err := yaml.Marshal(instance)
+	if err != nil {
+		return mcpError("failed to encode instance: " + err.Error()), nil
+	}
+
+	return mcpSuccess(savedPersonalityResult{
+		Name:        source.Name,
+		Personality: string(personalityYAML),
+		Instance:    string(manifest),
+		Omitted:     omitted,
+		NextSteps: []string{
+			"review extraEnv and templateValues for values that must not be shared",
+			"share the personality as spec.inlinePersonality, or apply the instance manifest",
+		},
+	}), nil
+}
+
+// extractPersonality returns the inline personality of the effective spec of
+// source, and the instance fields with personality-like content that an
+// inline personality cannot carry. Skill packs stay references, and a
+// toolchainRef is resolved to its image.
+func (s *Server) extractPersonality(ctx context.Context, source *klausv1alpha1.KlausInstance) (*klausv1alpha1.InlinePersonality, []string, error) {
+	merged := source.DeepCopy()
+	resources.MergeInlinePersonality(merged)
+	spec := merged.Spec
+
+	image := spec.Image
+	if image == "" && spec.ToolchainRef != "" {
+		var err error
+		if image, err = s.resolveToolchain(ctx, spec.ToolchainRef); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	personality := &klausv1alpha1.InlinePersonality{
+		Image:          image,
+		Plugins:        spec.Plugins,
+		Skills:         spec.Skills,
+		Commands:       spec.Commands,
+		SkillPacks:     spec.SkillPacks,
+		Memory:         spec.Memory,
+		TemplateValues: spec.TemplateValues,
+		Probes:         spec.Probes,
+		ExtraEnv:       spec.ExtraEnv,
+		ExtraEnvFrom:   spec.ExtraEnvFrom,
+		Scheduling:     spec.Scheduling,
+	}
+	if p := source.Spec.InlinePersonality; p != nil {
+		personality.Description = p.Description
+		personality.Soul = p.Soul
+	}
+
+	var omitted []string
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"agentFiles", len(spec.AgentFiles) > 0},
+		{"hooks", len(spec.Hooks) > 0},
+		{"hookScripts", len(spec.HookScripts) > 0},
+		{"mcpServers", len(spec.MCPServers) > 0},
+	} {
+		if field.set {
+			omitted = append(omitted, field.name)
+		}
+	}
+	return personality, omitted, nil
+}
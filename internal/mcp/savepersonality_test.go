@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func callSaveAsPersonality(t *testing.T, s *Server, args map[string]any) (*mcpgolang.CallToolResult, savedPersonalityResult) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := s.handleSaveAsPersonality(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data savedPersonalityResult
+	if !result.IsError {
+		if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return result, data
+}
+
+func TestHandleSaveAsPersonality(t *testing.T) {
+	source := runningInstance("agent", "user@example.com", "")
+	source.Spec.ToolchainRef = "go"
+	source.Spec.Skills = map[string]klausv1alpha1.SkillConfig{"review": {Content: "Review the diff."}}
+	source.Spec.MCPServers = []klausv1alpha1.MCPServerReference{{Name: "github"}}
+	source.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{
+		Description: "Reviews Go code.",
+		Soul:        "You are a careful reviewer.",
+		Plugins:     []klausv1alpha1.PluginReference{{Repository: "registry.example.com/plugins/go", Tag: "v1"}},
+		SkillPacks:  []klausv1alpha1.SkillPackReference{{Name: "shared"}},
+	}
+	toolchain := &klausv1alpha1.KlausToolchain{
+		ObjectMeta: metav1.ObjectMeta{Name: "go", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausToolchainSpec{Image: "registry.example.com/toolchains/go:1.26"},
+	}
+	s := cloneTestServer(t, source, toolchain)
+
+	result, data := callSaveAsPersonality(t, s, map[string]any{"name": "agent", "new_name": "go-reviewer"})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+
+	var personality klausv1alpha1.InlinePersonality
+	if err := yaml.Unmarshal([]byte(data.Personality), &personality); err != nil {
+		t.Fatalf("decoding personality: %v", err)
+	}
+	if personality.Soul != "You are a careful reviewer." || personality.Description != "Reviews Go code." {
+		t.Errorf("soul = %q, description = %q, want those of the inline personality", personality.Soul, personality.Description)
+	}
+	if personality.Image != "registry.example.com/toolchains/go:1.26" {
+		t.Errorf("image = %q, want the image of the toolchainRef", personality.Image)
+	}
+	if _, ok := personality.Skills["review"]; !ok || len(personality.Plugins) != 1 || len(personality.SkillPacks) != 1 {
+		t.Errorf("personality = %+v, want the merged skills, plugins and skill packs", personality)
+	}
+	if len(data.Omitted) != 1 || data.Omitted[0] != "mcpServers" {
+		t.Errorf("omitted = %v, want mcpServers", data.Omitted)
+	}
+
+	var instance klausv1alpha1.KlausInstance
+	if err := yaml.Unmarshal([]byte(data.Instance), &instance); err != nil {
+		t.Fatalf("decoding instance: %v", err)
+	}
+	if instance.Name != "go-reviewer" || instance.Spec.InlinePersonality == nil || instance.Spec.Owner != "user@example.com" {
+		t.Errorf("instance = %+v, want go-reviewer with the personality", instance)
+	}
+
+	var instances klausv1alpha1.KlausInstanceList
+	if err := s.client.List(t.Context(), &instances); err != nil || len(instances.Items) != 1 {
+		t.Errorf("instances = %d (%v), want save_as_personality to create nothing", len(instances.Items), err)
+	}
+}
+
+func TestHandleSaveAsPersonality_OCIPersonality(t *testing.T) {
+	source := runningInstance("agent", "user@example.com", "")
+	source.Spec.Personality = "registry.example.com/personalities/reviewer:v1"
+	s := cloneTestServer(t, source)
+
+	result, _ := callSaveAsPersonality(t, s, map[string]any{"name": "agent"})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "reference the personality instead") {
+		t.Errorf("result = %v, want an error for an OCI personality", result.Content)
+	}
+}
@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// defaultScaffoldName is the instance name of a scaffolded personality when
+// the caller does not choose one.
+const defaultScaffoldName = "new-personality"
+
+// genericPluginWords are parts of catalog plugin names too generic to
+// suggest a plugin on their own.
+var genericPluginWords = []string{"base", "klaus", "plugin", "plugins", "tools"}
+
+// scaffoldResult is the JSON structure returned by scaffold_personality.
+type scaffoldResult struct {
+	Name             string   `json:"name"`
+	Status           string   `json:"status"`
+	Toolchain        string   `json:"toolchain,omitempty"`
+	SuggestedPlugins []string `json:"suggestedPlugins,omitempty"`
+	Soul             string   `json:"soul"`
+	Instance         string   `json:"instance"`
+	NextSteps        []string `json:"nextSteps,omitempty"`
+}
+
+// handleScaffoldPersonality generates a starter personality from a
+// description, a toolchain and the repositories the agent works on. The
+// personality is returned as the inline personality of a KlausInstance
+// manifest, with a SOUL.md starter and plugins from the catalog whose names
+// match the description. By default it only returns the manifest for
+// review; with apply it creates the instance for the caller, subject to the
+// same permission policy and preflight checks as create_instance.
+func (s *Server) handleScaffoldPersonality(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	user, err := s.extractUser(ctx)
+	if err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
+
+	args := request.GetArguments()
+	description, _ := args["description"].(string)
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return mcpError("description is required"), nil
+	}
+	name, _ := args[keyName].(string)
+	if name == "" {
+		name = defaultScaffoldName
+	}
+	toolchain, _ := args["toolchain"].(string)
+	repos := parseStringArray(args["repos"])
+	apply, _ := args["apply"].(bool)
+
+	image, err := s.resolveToolchain(ctx, toolchain)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+	plugins, refs, err := s.suggestPlugins(ctx, description, repos)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+
+	personality := &klausv1alpha1.InlinePersonality{
+		Description: description,
+		Soul:        scaffoldSoul(description, repos),
+		Image:       image,
+		Plugins:     plugins,
+	}
+	instance := &klausv1alpha1.KlausInstance{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: klausv1alpha1.GroupVersion.String(),
+			Kind:       "KlausInstance",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.operatorNamespace,
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:             user,
+			Claude:            klausv1alpha1.ClaudeConfig{Model: defaultModel},
+			InlinePersonality: personality,
+		},
+	}
+	// A workspace holds a single repository; the others are listed in the
+	// soul for the agent to clone.
+	if len(repos) > 0 {
+		instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{GitRepo: repos[0]}
+	}
+
+	result := scaffoldResult{
+		Name:             name,
+		Status:           "dry-run",
+		Toolchain:        image,
+		SuggestedPlugins: refs,
+		Soul:             personality.Soul,
+	}
+	manifest, err := yaml.Marshal(instance)
+	if err != nil {
+		return mcpError("failed to encode instance: " + err.Error()), nil
+	}
+	result.Instance = string(manifest)
+
+	if !apply {
+		result.NextSteps = []string{"review the soul and suggested plugins, edit the manifest as needed, then apply it or call again with apply=true"}
+		return mcpSuccess(result), nil
+	}
+
+	if err := s.applyPermissionPolicy(ctx, &instance.Spec); err != nil {
+		return mcpError(err.Error()), nil
+	}
+	if err := s.preflightCreate(ctx, name, user, &instance.Spec); err != nil {
+		return toolError(err), nil
+	}
+	s.stampProvenance(ctx, request, instance)
+	if err := s.client.Create(ctx, instance); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return mcpError("instance '" + name + "' already exists"), nil
+		}
+		return mcpError("failed to create instance: " + err.Error()), nil
+	}
+
+	result.Status = "creating"
+	result.NextSteps = []string{"refine the personality with update_instance or by editing spec.inlinePersonality"}
+	return mcpSuccess(result), nil
+}
+
+// resolveToolchain returns the image of a toolchain given by KlausToolchain
+// or catalog name, or as an image reference. KlausToolchains take precedence
+// over the catalog. An empty toolchain leaves the image to the operator
+// default.
+func (s *Server) resolveToolchain(ctx context.Context, toolchain string) (string, error) {
+	if toolchain == "" || strings.ContainsAny(toolchain, "/:@") {
+		return toolchain, nil
+	}
+	var tc klausv1alpha1.KlausToolchain
+	err := s.client.Get(ctx, types.NamespacedName{Name: toolchain, Namespace: s.operatorNamespace}, &tc)
+	if err == nil {
+		return tc.Spec.Image, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get toolchain %q: %w", toolchain, err)
+	}
+	if s.ociClient == nil {
+		return "", fmt.Errorf("OCI client not configured: give the toolchain as an image reference")
+	}
+	entries, err := s.ociClient.ListToolchains(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list toolchains: %w", err)
+	}
+	for _, e := range entries {
+		if e.Name == toolchain {
+			return e.Reference, nil
+		}
+	}
+	return "", fmt.Errorf("toolchain %q not found in the catalog; list_toolchains shows the available toolchains", toolchain)
+}
+
+// suggestPlugins returns the catalog plugins sharing a word with the
+// description or the repository names, for example gs-kubernetes for a
+// description mentioning Kubernetes. Without an OCI client no plugins are
+// suggested. The plugins are returned with their references.
+func (s *Server) suggestPlugins(ctx context.Context, description string, repos []string) ([]klausv1alpha1.PluginReference, []string, error) {
+	if s.ociClient == nil {
+		return nil, nil, nil
+	}
+	entries, err := s.ociClient.ListPlugins(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list plugins: %w", err)
+	}
+
+	text := description
+	for _, repo := range repos {
+		text += " " + strings.TrimSuffix(path.Base(repo), ".git")
+	}
+	words := scaffoldWords(text)
+
+	var plugins []klausv1alpha1.PluginReference
+	var refs []string
+	for _, e := range entries {
+		if !pluginMatches(e, words) {
+			continue
+		}
+		p, err := parsePluginReference(e.Reference)
+		if err != nil {
+			continue
+		}
+		plugins = append(plugins, p)
+		refs = append(refs, e.Reference)
+	}
+	return plugins, refs, nil
+}
+
+// pluginMatches reports whether a word of the plugin name, other than a
+// generic one, is among words.
+func pluginMatches(e klausoci.ListEntry, words []string) bool {
+	for _, part := range scaffoldWords(e.Name) {
+		if len(part) >= 3 && !slices.Contains(genericPluginWords, part) && slices.Contains(words, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// scaffoldWords splits text into lower-case words.
+func scaffoldWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+}
+
+// scaffoldSoul renders a SOUL.md starter for the personality author to
+// refine.
+func scaffoldSoul(description string, repos []string) string {
+	var b strings.Builder
+	b.WriteString("# Soul\n\n")
+	b.WriteString(description)
+	b.WriteString("\n")
+	if len(repos) > 0 {
+		b.WriteString("\n## Repositories\n\nYou work on these repositories:\n\n")
+		for _, repo := range repos {
+			fmt.Fprintf(&b, "- %s\n", repo)
+		}
+	}
+	b.WriteString(`
+## Working style
+
+- Explain what you are about to change and why before changing it.
+- Keep changes small and reviewable.
+- Ask when a request is ambiguous instead of guessing.
+`)
+	return b.String()
+}
@@ -0,0 +1,223 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// fakeCatalog implements ArtifactLister with fixed entries.
+type fakeCatalog struct {
+	plugins    []klausoci.ListEntry
+	toolchains []klausoci.ListEntry
+}
+
+func (f *fakeCatalog) ListPlugins(context.Context, ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+	return f.plugins, nil
+}
+
+func (f *fakeCatalog) ListPersonalities(context.Context, ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeCatalog) ListToolchains(context.Context, ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+	return f.toolchains, nil
+}
+
+func scaffoldTestServer(t *testing.T, objs ...client.Object) *Server {
+	t.Helper()
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objs...).Build()
+	catalog := &fakeCatalog{
+		plugins: []klausoci.ListEntry{
+			{Name: "gs-base", Reference: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v0.1.0"},
+			{Name: "gs-kubernetes", Reference: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-kubernetes:v0.2.0"},
+			{Name: "go-review", Reference: "gsoci.azurecr.io/giantswarm/klaus-plugins/go-review:v1.0.0"},
+		},
+		toolchains: []klausoci.ListEntry{
+			{Name: "go", Reference: "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.26.0"},
+		},
+	}
+	return &Server{client: c, operatorNamespace: "klaus-system", ociClient: catalog}
+}
+
+func callScaffold(t *testing.T, s *Server, args map[string]any) (*mcpgolang.CallToolResult, scaffoldResult) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := s.handleScaffoldPersonality(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data scaffoldResult
+	if !result.IsError {
+		if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return result, data
+}
+
+func TestHandleScaffoldPersonality_DryRun(t *testing.T) {
+	s := scaffoldTestServer(t)
+
+	result, data := callScaffold(t, s, map[string]any{
+		"description": "Keeps our Kubernetes operators healthy.",
+		"toolchain":   "go",
+		"repos":       []any{"https://github.com/giantswarm/klaus-operator", "https://github.com/giantswarm/klaus.git"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if data.Status != "dry-run" || data.Name != defaultScaffoldName {
+		t.Errorf("status = %q, name = %q", data.Status, data.Name)
+	}
+	if data.Toolchain != "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.26.0" {
+		t.Errorf("toolchain = %q, want the catalog reference", data.Toolchain)
+	}
+	if len(data.SuggestedPlugins) != 1 || !strings.HasSuffix(data.SuggestedPlugins[0], "/gs-kubernetes:v0.2.0") {
+		t.Errorf("suggested plugins = %v, want gs-kubernetes only", data.SuggestedPlugins)
+	}
+	for _, want := range []string{"# Soul", "Keeps our Kubernetes operators healthy.", "- https://github.com/giantswarm/klaus.git"} {
+		if !strings.Contains(data.Soul, want) {
+			t.Errorf("soul = %q, want it to contain %q", data.Soul, want)
+		}
+	}
+
+	var instance klausv1alpha1.KlausInstance
+	if err := yaml.Unmarshal([]byte(data.Instance), &instance); err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	if instance.Kind != "KlausInstance" || instance.Spec.Owner != "user@example.com" {
+		t.Errorf("manifest kind = %q, owner = %q", instance.Kind, instance.Spec.Owner)
+	}
+	p := instance.Spec.InlinePersonality
+	if p == nil || p.Soul != data.Soul || p.Image != data.Toolchain || len(p.Plugins) != 1 || p.Plugins[0].Tag != "v0.2.0" {
+		t.Errorf("inline personality = %+v", p)
+	}
+	if ws := instance.Spec.Workspace; ws == nil || ws.GitRepo != "https://github.com/giantswarm/klaus-operator" {
+		t.Errorf("workspace = %+v, want the first repository", ws)
+	}
+
+	var created klausv1alpha1.KlausInstance
+	if err := s.client.Get(context.Background(), types.NamespacedName{Name: defaultScaffoldName, Namespace: "klaus-system"}, &created); err == nil {
+		t.Error("expected no instance to be created in a dry run")
+	}
+}
+
+func TestHandleScaffoldPersonality_Apply(t *testing.T) {
+	s := scaffoldTestServer(t)
+
+	result, data := callScaffold(t, s, map[string]any{
+		"name":        "reviewer",
+		"description": "Does Go code review.",
+		"toolchain":   "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.25.0",
+		"apply":       true,
+	})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if data.Status != "creating" {
+		t.Errorf("status = %q, want creating", data.Status)
+	}
+
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(context.Background(), types.NamespacedName{Name: "reviewer", Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("expected the instance to be created: %v", err)
+	}
+	p := instance.Spec.InlinePersonality
+	if p == nil || p.Image != "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.25.0" || len(p.Plugins) != 1 {
+		t.Errorf("inline personality = %+v, want the given image and go-review", p)
+	}
+	if instance.Spec.Claude.PermissionMode == "" {
+		t.Error("expected the permission policy to default the permission mode")
+	}
+	if instance.Annotations[AnnotationCreatedBy] != "user@example.com" {
+		t.Errorf("annotations = %v, want provenance", instance.Annotations)
+	}
+}
+
+func TestHandleScaffoldPersonality_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "no description", args: map[string]any{}, wantErr: "description is required"},
+		{name: "unknown toolchain", args: map[string]any{"description": "x", "toolchain": "rust"}, wantErr: "not found in the catalog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := callScaffold(t, scaffoldTestServer(t), tt.args)
+			if !result.IsError {
+				t.Fatal("expected an MCP error")
+			}
+			if text := result.Content[0].(mcpgolang.TextContent).Text; !strings.Contains(text, tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", text, tt.wantErr)
+			}
+		})
+	}
+}
+
+func testToolchain() *klausv1alpha1.KlausToolchain {
+	return &klausv1alpha1.KlausToolchain{
+		ObjectMeta: metav1.ObjectMeta{Name: "go", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausToolchainSpec{
+			Image:         "registry.example.com/toolchains/go:1.25",
+			Language:      "go",
+			Personalities: []string{"go-dev"},
+		},
+	}
+}
+
+func TestHandleScaffoldPersonality_KlausToolchain(t *testing.T) {
+	_, data := callScaffold(t, scaffoldTestServer(t, testToolchain()), map[string]any{
+		"description": "Writes Go services.",
+		"toolchain":   "go",
+	})
+	if data.Toolchain != "registry.example.com/toolchains/go:1.25" {
+		t.Errorf("toolchain = %q, want the KlausToolchain image over the catalog", data.Toolchain)
+	}
+}
+
+func TestHandleListToolchains(t *testing.T) {
+	s := scaffoldTestServer(t, testToolchain())
+	result, err := s.handleListToolchains(context.Background(), mcpgolang.CallToolRequest{})
+	if err != nil || result.IsError {
+		t.Fatalf("handleListToolchains() = %v, %v", result, err)
+	}
+	var data struct {
+		Count int              `json:"count"`
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Count != 2 {
+		t.Fatalf("count = %d, want the KlausToolchain and the registry toolchain", data.Count)
+	}
+	if item := data.Items[0]; item["source"] != "KlausToolchain" || item["reference"] != "registry.example.com/toolchains/go:1.25" ||
+		item["language"] != "go" {
+		t.Errorf("items[0] = %v, want the KlausToolchain", item)
+	}
+	if item := data.Items[1]; item["source"] != "registry" {
+		t.Errorf("items[1] = %v, want the registry toolchain", item)
+	}
+
+	// Without an OCI client only the KlausToolchains are listed.
+	s.ociClient = nil
+	result, _ = s.handleListToolchains(context.Background(), mcpgolang.CallToolRequest{})
+	if result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, `"count": 1`) {
+		t.Errorf("result = %v, want the KlausToolchain only", result.Content)
+	}
+}
@@ -2,14 +2,22 @@ package mcp
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
+	"net"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 	mcpgolang "github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/klaus-operator/internal/audit"
+	"github.com/giantswarm/klaus-operator/internal/deprecation"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+	"github.com/giantswarm/klaus-operator/internal/usage"
 )
 
 // ArtifactLister discovers available OCI artifacts from a registry.
@@ -35,7 +43,19 @@ type Server struct {
 	addr              string
 	ociClient         ArtifactLister
 	podLogReader      PodLogReader
+	previewer         InstancePreviewer
 	agentClient       AgentMCPClient
+	permissionPolicy  *permissions.Policy
+	recorder          record.EventRecorder
+	gitImage          string
+	usageCollector    *usage.Collector
+	namespaceScoped   bool
+	tokenVerifier     TokenVerifier
+	adminUsers        []string
+	auditLogger       *audit.Logger
+	limits            Limits
+	rateLimiter       rateLimiter
+	listenerOptions   *ListenerOptions
 	httpServer        *server.StreamableHTTPServer
 }
 
@@ -56,6 +76,10 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 		"klaus-operator",
 		"0.1.0",
 		server.WithToolCapabilities(true),
+		server.WithToolHandlerMiddleware(s.identifyCaller()),
+		server.WithToolHandlerMiddleware(deprecationWarnings(deprecation.Default)),
+		server.WithToolHandlerMiddleware(s.auditTools()),
+		server.WithToolHandlerMiddleware(s.rateLimitTools()),
 	)
 
 	// instanceSpecParams defines parameters shared by create_instance and run_instance.
@@ -65,23 +89,30 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 		mcpgolang.WithString("personality", mcpgolang.Description("OCI reference to a personality artifact (e.g. registry/repo:tag)")),
 		// High priority.
 		mcpgolang.WithString("image", mcpgolang.Description("Toolchain container image override")),
+		mcpgolang.WithString("toolchain_ref", mcpgolang.Description("Name of a KlausToolchain from list_toolchains to use instead of image")),
 		mcpgolang.WithArray("plugins", mcpgolang.Description("OCI plugin references (e.g. registry/repo:tag)"), mcpgolang.WithStringItems()),
 		mcpgolang.WithString("workspace_git_repo", mcpgolang.Description("Git repository URL to clone into the workspace")),
 		mcpgolang.WithString("workspace_git_ref", mcpgolang.Description("Git ref to checkout (branch, tag, or commit)")),
 		mcpgolang.WithString("workspace_git_secret", mcpgolang.Description("Name of a Kubernetes Secret containing a git access token")),
+		mcpgolang.WithBoolean("workspace_github_app", mcpgolang.Description("Authenticate workspace git operations with short-lived tokens of the operator's GitHub App instead of workspace_git_secret")),
 		mcpgolang.WithString("workspace_storage_class", mcpgolang.Description("Kubernetes StorageClass for the workspace PVC")),
 		mcpgolang.WithString("workspace_size", mcpgolang.Description("Workspace PVC size (e.g. 5Gi, 10Gi)")),
 		mcpgolang.WithNumber("max_budget_usd", mcpgolang.Description("Maximum spend per session in USD")),
-		mcpgolang.WithString("permission_mode", mcpgolang.Description("Tool permission mode: bypassPermissions (default) or default"), mcpgolang.Enum("bypassPermissions", "default")),
+		mcpgolang.WithString("permission_mode", mcpgolang.Description("Tool permission mode: bypassPermissions or default (default: decided by the operator's permission policy for your groups)"), mcpgolang.Enum("bypassPermissions", "default")),
+		mcpgolang.WithBoolean("permission_mode_override", mcpgolang.Description("Allow a permission_mode above the maximum for your groups; platform admins only (default: false)")),
 		mcpgolang.WithNumber("max_turns", mcpgolang.Description("Maximum number of agentic turns (0 = unlimited)")),
 		mcpgolang.WithString("effort", mcpgolang.Description("Thinking effort level"), mcpgolang.Enum("low", "medium", "high")),
 		// Medium priority.
 		mcpgolang.WithArray("mcp_servers", mcpgolang.Description("KlausMCPServer resource names to attach"), mcpgolang.WithStringItems()),
+		mcpgolang.WithArray("depends_on", mcpgolang.Description("Names of your instances this one waits for and can reach as MCP servers"), mcpgolang.WithStringItems()),
 		mcpgolang.WithString("append_system_prompt", mcpgolang.Description("Text appended to the default system prompt")),
 		mcpgolang.WithArray("allowed_tools", mcpgolang.Description("Restrict which tools can be used"), mcpgolang.WithStringItems()),
 		mcpgolang.WithArray("disallowed_tools", mcpgolang.Description("Prevent specific tools from being used"), mcpgolang.WithStringItems()),
 		mcpgolang.WithString("fallback_model", mcpgolang.Description("Fallback model if the primary is unavailable")),
 		mcpgolang.WithString("mode", mcpgolang.Description("Instance process mode: agent (default, autonomous coding) or chat (interactive conversation)"), mcpgolang.Enum("agent", "chat")),
+		mcpgolang.WithBoolean("mock_mode", mcpgolang.Description("Run against the mock agent instead of Claude; no API key is used and the instance is marked non-production (default: false)")),
+		mcpgolang.WithArray("owners", mcpgolang.Description("Additional user identities (emails) sharing the instance"), mcpgolang.WithStringItems()),
+		mcpgolang.WithArray("owner_groups", mcpgolang.Description("Groups from the token groups claim whose members share the instance"), mcpgolang.WithStringItems()),
 	}
 
 	// Register tools.
@@ -94,7 +125,7 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 
 	mcpSrv.AddTool(mcpgolang.NewTool(
 		"list_instances",
-		mcpgolang.WithDescription("List the calling user's Klaus instances"),
+		mcpgolang.WithDescription("List the Klaus instances owned by or shared with the calling user"),
 	), s.handleListInstances)
 
 	mcpSrv.AddTool(mcpgolang.NewTool(
@@ -109,6 +140,20 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
 	), s.handleGetInstance)
 
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"update_instance",
+		mcpgolang.WithDescription("Change the configuration of an existing Klaus instance in place (owner-only). Only the given fields change; an empty value or list clears the field. The instance is rolled out with the new configuration."),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance to update")),
+		mcpgolang.WithString("model", mcpgolang.Description("Claude model to use")),
+		mcpgolang.WithString("system_prompt", mcpgolang.Description("System prompt for the agent")),
+		mcpgolang.WithString("personality", mcpgolang.Description("OCI reference to a personality artifact (e.g. registry/repo:tag)")),
+		mcpgolang.WithArray("plugins", mcpgolang.Description("OCI plugin references replacing the current plugins"), mcpgolang.WithStringItems()),
+		mcpgolang.WithArray("mcp_servers", mcpgolang.Description("KlausMCPServer resource names replacing the attached servers"), mcpgolang.WithStringItems()),
+		mcpgolang.WithNumber("max_budget_usd", mcpgolang.Description("Maximum spend per session in USD (0 removes the budget)")),
+		mcpgolang.WithArray("owners", mcpgolang.Description("Additional user identities (emails) sharing the instance; primary owner only"), mcpgolang.WithStringItems()),
+		mcpgolang.WithArray("owner_groups", mcpgolang.Description("Groups whose members share the instance; primary owner only"), mcpgolang.WithStringItems()),
+	), s.handleUpdateInstance)
+
 	mcpSrv.AddTool(mcpgolang.NewTool(
 		"restart_instance",
 		mcpgolang.WithDescription("Restart a Klaus instance by cycling its Deployment"),
@@ -129,18 +174,56 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 
 	mcpSrv.AddTool(mcpgolang.NewTool(
 		"get_logs",
-		mcpgolang.WithDescription("Get recent log output from a Klaus instance pod"),
+		mcpgolang.WithDescription(deprecatedDescription("get_logs", "Get recent log output from a Klaus instance pod")),
 		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
 		mcpgolang.WithNumber("tail", mcpgolang.Description("Number of lines from end (default: 100)")),
 		mcpgolang.WithString("container", mcpgolang.Description("Container name (default: klaus; use git-clone for init container logs)")),
 	), s.handleGetLogs)
 
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"get_instance_logs",
+		mcpgolang.WithDescription("Get or stream the klaus container or git-clone init container logs of an owned instance, e.g. to debug startup failures"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
+		mcpgolang.WithNumber("lines", mcpgolang.Description("Number of lines from the end (default: 100 unless since is set; max: 10000)")),
+		mcpgolang.WithString("since", mcpgolang.Description("Only return logs newer than a duration (e.g. 10m, 1h) or an RFC 3339 timestamp")),
+		mcpgolang.WithString("container", mcpgolang.Description("Container to read (default: klaus)"), mcpgolang.Enum("klaus", "git-clone")),
+		mcpgolang.WithBoolean("previous", mcpgolang.Description("Return the logs of the previous terminated container, e.g. after a crash (default: false)")),
+		mcpgolang.WithNumber("follow_seconds", mcpgolang.Description("Stream new log lines for up to this many seconds before returning (max: 60)")),
+	), s.handleGetInstanceLogs)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"get_instance_metrics",
+		mcpgolang.WithDescription("Get the CPU, memory and workspace volume usage of an owned instance against its requests and limits, with hints when it is resource-starved"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
+	), s.handleGetInstanceMetrics)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"workspace_status",
+		mcpgolang.WithDescription("Show the branch, last commit and local changes of an owned instance's workspace git checkout"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
+	), s.handleWorkspaceStatus)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"workspace_pull",
+		mcpgolang.WithDescription("Fast-forward an owned instance's workspace checkout to the latest remote commit of its git ref, without restarting the instance"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
+	), s.handleWorkspacePull)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"workspace_reset",
+		mcpgolang.WithDescription("Reset an owned instance's workspace checkout to the remote state of its git ref, discarding local commits and changes to tracked files"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
+		mcpgolang.WithBoolean("clean", mcpgolang.Description("Also remove untracked files (default: false)")),
+	), s.handleWorkspaceReset)
+
 	mcpSrv.AddTool(mcpgolang.NewTool(
 		"prompt_instance",
 		mcpgolang.WithDescription("Send a prompt to a running Klaus agent instance and optionally wait for the result"),
 		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Instance name")),
 		mcpgolang.WithString("message", mcpgolang.Required(), mcpgolang.Description("Prompt message to send to the agent")),
 		mcpgolang.WithBoolean("blocking", mcpgolang.Description("Wait for the agent to complete and return the result (default: false)")),
+		mcpgolang.WithNumber("timeout_seconds", mcpgolang.Description("Give up waiting after this many seconds; a blocking prompt then reports status running (default and max: 600)")),
+		mcpgolang.WithNumber("max_response_bytes", mcpgolang.Description("Truncate the returned result to this many bytes (default and max: 1048576)")),
 	), s.handlePromptInstance)
 
 	mcpSrv.AddTool(mcpgolang.NewTool(
@@ -150,6 +233,25 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 		mcpgolang.WithBoolean("full", mcpgolang.Description("Return full agent detail including tool_calls, model_usage, token_usage, cost, etc.")),
 	), s.handleGetResult)
 
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"get_instance_result",
+		mcpgolang.WithDescription("Get the final output of the last completed prompt recorded in an owned agent instance's status, also while it is stopped"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Instance name")),
+	), s.handleGetInstanceResult)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"get_effective_config",
+		mcpgolang.WithDescription("Get the effective spec an owned Klaus instance runs, merged with its personality, skill packs and KlausMCPServers and with its OCI references resolved; secrets are redacted"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Instance name")),
+	), s.handleGetEffectiveConfig)
+
+	previewOpts := append([]mcpgolang.ToolOption{
+		mcpgolang.WithDescription("Preview a Klaus instance without creating it: resolve its personality, skill packs, OCI references and KlausMCPServers, validate it and return the rendered ConfigMap and Deployment YAML or the error its creation would report"),
+		mcpgolang.WithString("name", mcpgolang.Description("Name of the instance (required unless manifest sets metadata.name)")),
+		mcpgolang.WithString("manifest", mcpgolang.Description("KlausInstance YAML manifest to preview instead of the other parameters; spec.owner is set to the caller")),
+	}, instanceSpecParams...)
+	mcpSrv.AddTool(mcpgolang.NewTool("preview_instance", previewOpts...), s.handlePreviewInstance)
+
 	runOpts := append([]mcpgolang.ToolOption{
 		mcpgolang.WithDescription("Create a new Klaus agent instance, wait for it to become ready, and send a prompt -- a single operation combining create_instance + prompt_instance"),
 		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name for the new instance")),
@@ -161,6 +263,55 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 
 	mcpSrv.AddTool(mcpgolang.NewTool("run_instance", runOpts...), s.handleRunInstance)
 
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"admin_list_instances",
+		mcpgolang.WithDescription("Admin only: list the Klaus instances of all owners with their state and namespace"),
+		mcpgolang.WithString("owner", mcpgolang.Description("Only list the instances of this primary owner")),
+	), s.handleAdminListInstances)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"admin_delete_instance",
+		mcpgolang.WithDescription("Admin only: delete the Klaus instance of any owner; the deletion is recorded as an event on the instance"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance to delete")),
+	), s.handleAdminDeleteInstance)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"import_helm_release",
+		mcpgolang.WithDescription("Admin only: convert a release of the standalone Klaus Helm chart into a KlausInstance, reporting values without an equivalent; dry run unless apply is set"),
+		mcpgolang.WithString("release", mcpgolang.Required(), mcpgolang.Description("Name of the Helm release")),
+		mcpgolang.WithString("namespace", mcpgolang.Required(), mcpgolang.Description("Namespace of the Helm release")),
+		mcpgolang.WithString("owner", mcpgolang.Required(), mcpgolang.Description("Owner of the new instance")),
+		mcpgolang.WithString("name", mcpgolang.Description("Name of the new instance (default: the release name)")),
+		mcpgolang.WithBoolean("apply", mcpgolang.Description("Create the instance instead of only returning it (default: false)")),
+		mcpgolang.WithString("adopt_pvc", mcpgolang.Description("Release PVC whose volume becomes the instance workspace; the volume is set to Retain and rebound")),
+	), s.handleImportHelmRelease)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"scaffold_personality",
+		mcpgolang.WithDescription("Generate a starter personality from a description: a KlausInstance manifest with an inline personality, a SOUL.md starter and suggested catalog plugins; dry run unless apply is set"),
+		mcpgolang.WithString("description", mcpgolang.Required(), mcpgolang.Description("What the agent is for, used for the soul and to suggest plugins")),
+		mcpgolang.WithString("toolchain", mcpgolang.Description("Toolchain name from list_toolchains or a container image reference")),
+		mcpgolang.WithArray("repos", mcpgolang.Description("Git repositories the agent works on; the first one is cloned into the workspace"), mcpgolang.WithStringItems()),
+		mcpgolang.WithString("name", mcpgolang.Description("Name of the instance (default: "+defaultScaffoldName+")")),
+		mcpgolang.WithBoolean("apply", mcpgolang.Description("Create the instance instead of only returning it (default: false)")),
+	), s.handleScaffoldPersonality)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"clone_instance",
+		mcpgolang.WithDescription("Create a copy of an owned Klaus instance under a new name, optionally with a copy of its workspace volume; the expose settings are not copied, nor the sharing and credentials when the caller is not the primary owner"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance to clone")),
+		mcpgolang.WithString("new_name", mcpgolang.Required(), mcpgolang.Description("Name for the new instance")),
+		mcpgolang.WithBoolean("workspace", mcpgolang.Description("Also copy the workspace volume, using CSI volume cloning; primary owner only (default: false)")),
+	), s.handleCloneInstance)
+
+	mcpSrv.AddTool(mcpgolang.NewTool(
+		"save_as_personality",
+		mcpgolang.WithDescription("Extract an inline personality from the effective spec of an owned Klaus instance, returned with an instance manifest using it for others to reuse; nothing is created"),
+		mcpgolang.WithString("name", mcpgolang.Required(), mcpgolang.Description("Name of the instance")),
+		mcpgolang.WithString("new_name", mcpgolang.Description("Name of the instance in the returned manifest (default: "+defaultScaffoldName+")")),
+		mcpgolang.WithString("description", mcpgolang.Description("Description of the personality (default: that of the inline personality of the instance)")),
+	), s.handleSaveAsPersonality)
+
 	mcpSrv.AddTool(mcpgolang.NewTool(
 		"list_plugins",
 		mcpgolang.WithDescription("List available Klaus plugins from the OCI registry with version and metadata"),
@@ -173,7 +324,7 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 
 	mcpSrv.AddTool(mcpgolang.NewTool(
 		"list_toolchains",
-		mcpgolang.WithDescription("List available Klaus toolchain images from the OCI registry with version and metadata"),
+		mcpgolang.WithDescription("List the approved KlausToolchains, usable as toolchain_ref, and the Klaus toolchain images from the OCI registry with version and metadata"),
 	), s.handleListToolchains)
 
 	s.httpServer = server.NewStreamableHTTPServer(mcpSrv,
@@ -186,21 +337,16 @@ func NewServer(c client.Client, operatorNamespace, addr string, ociClient Artifa
 // Start implements manager.Runnable. It starts the MCP server and shuts it
 // down gracefully when the context is cancelled (i.e. when the manager stops).
 func (s *Server) Start(ctx context.Context) error {
-	slog.Info("starting MCP server", "addr", s.addr)
-
-	// Start listening in a goroutine so we can wait on context cancellation.
-	errCh := make(chan error, 1)
-	go func() {
-		errCh <- s.httpServer.Start(s.addr)
-	}()
-
-	select {
-	case err := <-errCh:
+	srv, err := s.newHTTPServer()
+	if err != nil {
+		return fmt.Errorf("configuring MCP listener: %w", err)
+	}
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
 		return err
-	case <-ctx.Done():
-		slog.Info("shutting down MCP server")
-		return s.httpServer.Shutdown(context.Background())
 	}
+	slog.Info("starting MCP server", "addr", s.addr, "tls", srv.TLSConfig != nil)
+	return s.serve(ctx, srv, listener)
 }
 
 // NeedLeaderElection implements manager.LeaderElectionRunnable to indicate the
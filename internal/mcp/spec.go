@@ -54,8 +54,8 @@ func parsePluginReference(ref string) (klausv1alpha1.PluginReference, error) {
 }
 
 // buildInstanceSpec extracts MCP tool arguments and assembles a KlausInstanceSpec.
-// The returned spec has Owner, Claude, Personality, Image, Plugins, MCPServers,
-// and Workspace fields populated based on the provided arguments. Fields not
+// The returned spec has Owner, Owners, OwnerGroups, Claude, Personality, Image,
+// Plugins, MCPServers, and Workspace fields populated based on the provided arguments. Fields not
 // present in args are left at their zero values.
 func buildInstanceSpec(args map[string]any, owner string) (klausv1alpha1.KlausInstanceSpec, error) {
 	model, _ := args[keyModel].(string)
@@ -69,9 +69,8 @@ func buildInstanceSpec(args map[string]any, owner string) (klausv1alpha1.KlausIn
 	spec := klausv1alpha1.KlausInstanceSpec{
 		Owner: owner,
 		Claude: klausv1alpha1.ClaudeConfig{
-			Model:          model,
-			PermissionMode: klausv1alpha1.PermissionModeBypass,
-			SystemPrompt:   systemPrompt,
+			Model:        model,
+			SystemPrompt: systemPrompt,
 		},
 	}
 
@@ -79,10 +78,13 @@ func buildInstanceSpec(args map[string]any, owner string) (klausv1alpha1.KlausIn
 		spec.Personality = personality
 	}
 
-	// Image override.
+	// Image override, given directly or as a KlausToolchain name.
 	if v, _ := args["image"].(string); v != "" {
 		spec.Image = v
 	}
+	if v, _ := args["toolchain_ref"].(string); v != "" {
+		spec.ToolchainRef = v
+	}
 
 	// Permission mode.
 	if v, _ := args["permission_mode"].(string); v != "" {
@@ -95,6 +97,10 @@ func buildInstanceSpec(args map[string]any, owner string) (klausv1alpha1.KlausIn
 		}
 	}
 
+	if v, _ := args["permission_mode_override"].(bool); v {
+		spec.Claude.PermissionModeOverride = true
+	}
+
 	// Max budget USD.
 	if v, ok := args["max_budget_usd"].(float64); ok && v > 0 {
 		spec.Claude.MaxBudgetUSD = &v
@@ -137,6 +143,11 @@ func buildInstanceSpec(args map[string]any, owner string) (klausv1alpha1.KlausIn
 		}
 	}
 
+	// Mock mode.
+	if v, _ := args["mock_mode"].(bool); v {
+		spec.MockMode = true
+	}
+
 	// Allowed tools.
 	if tools := parseStringArray(args["allowed_tools"]); len(tools) > 0 {
 		spec.Claude.AllowedTools = tools
@@ -169,6 +180,23 @@ func buildInstanceSpec(args map[string]any, owner string) (klausv1alpha1.KlausIn
 		spec.MCPServers = refs
 	}
 
+	// Instances this one depends on (by name).
+	if names := parseStringArray(args["depends_on"]); len(names) > 0 {
+		deps := make([]klausv1alpha1.InstanceDependency, 0, len(names))
+		for _, name := range names {
+			deps = append(deps, klausv1alpha1.InstanceDependency{Name: name})
+		}
+		spec.DependsOn = deps
+	}
+
+	// Additional owners and owner groups sharing the instance.
+	if owners := parseStringArray(args["owners"]); len(owners) > 0 {
+		spec.Owners = owners
+	}
+	if groups := parseStringArray(args["owner_groups"]); len(groups) > 0 {
+		spec.OwnerGroups = groups
+	}
+
 	// Workspace fields -- build a WorkspaceConfig if any workspace param is set.
 	var ws klausv1alpha1.WorkspaceConfig
 	hasWorkspace := false
@@ -185,6 +213,10 @@ func buildInstanceSpec(args map[string]any, owner string) (klausv1alpha1.KlausIn
 		ws.GitSecretRef = &klausv1alpha1.GitSecretReference{Name: v}
 		hasWorkspace = true
 	}
+	if v, _ := args["workspace_github_app"].(bool); v {
+		ws.GitHubApp = &klausv1alpha1.GitHubAppCredentials{}
+		hasWorkspace = true
+	}
 	if v, _ := args["workspace_storage_class"].(string); v != "" {
 		ws.StorageClass = v
 		hasWorkspace = true
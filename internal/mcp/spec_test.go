@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"slices"
 	"testing"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
@@ -105,8 +106,9 @@ func TestBuildInstanceSpec_Defaults(t *testing.T) {
 	if spec.Claude.Model != "claude-sonnet-4-20250514" {
 		t.Errorf("Model = %q, want default", spec.Claude.Model)
 	}
-	if spec.Claude.PermissionMode != klausv1alpha1.PermissionModeBypass {
-		t.Errorf("PermissionMode = %q, want %q", spec.Claude.PermissionMode, klausv1alpha1.PermissionModeBypass)
+	// The permission policy picks the default for the caller's groups.
+	if spec.Claude.PermissionMode != "" {
+		t.Errorf("PermissionMode = %q, want it left to the permission policy", spec.Claude.PermissionMode)
 	}
 	if spec.Workspace != nil {
 		t.Error("Workspace should be nil when no workspace params provided")
@@ -116,6 +118,16 @@ func TestBuildInstanceSpec_Defaults(t *testing.T) {
 	}
 }
 
+func TestBuildInstanceSpec_ToolchainRef(t *testing.T) {
+	spec, err := buildInstanceSpec(map[string]any{"toolchain_ref": "go-1.25"}, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.ToolchainRef != "go-1.25" || spec.Image != "" {
+		t.Errorf("toolchainRef = %q, image = %q; want the KlausToolchain referenced", spec.ToolchainRef, spec.Image)
+	}
+}
+
 func TestBuildInstanceSpec_AllHighPriority(t *testing.T) {
 	args := map[string]any{
 		"model":                   "claude-opus-4-20250514",
@@ -297,6 +309,20 @@ func TestBuildInstanceSpec_WorkspacePartial(t *testing.T) {
 	}
 }
 
+func TestBuildInstanceSpec_WorkspaceGitHubApp(t *testing.T) {
+	args := map[string]any{
+		"workspace_git_repo":   "https://github.com/org/repo.git",
+		"workspace_github_app": true,
+	}
+	spec, err := buildInstanceSpec(args, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Workspace == nil || spec.Workspace.GitHubApp == nil || spec.Workspace.GitSecretRef != nil {
+		t.Errorf("Workspace = %+v, want GitHub App credentials", spec.Workspace)
+	}
+}
+
 func TestBuildInstanceSpec_ModeAgent(t *testing.T) {
 	args := map[string]any{
 		"mode": "agent",
@@ -320,6 +346,51 @@ func TestBuildInstanceSpec_InvalidMode(t *testing.T) {
 	}
 }
 
+func TestBuildInstanceSpec_DependsOn(t *testing.T) {
+	spec, err := buildInstanceSpec(map[string]any{"depends_on": []any{"researcher", "reviewer"}}, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []klausv1alpha1.InstanceDependency{{Name: "researcher"}, {Name: "reviewer"}}
+	if !slices.Equal(spec.DependsOn, want) {
+		t.Errorf("DependsOn = %v, want %v", spec.DependsOn, want)
+	}
+}
+
+func TestBuildInstanceSpec_Owners(t *testing.T) {
+	spec, err := buildInstanceSpec(map[string]any{
+		"owners":       []any{"teammate@example.com"},
+		"owner_groups": []any{"platform-team"},
+	}, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Owner != "user@example.com" {
+		t.Errorf("Owner = %q, want the caller", spec.Owner)
+	}
+	if !slices.Equal(spec.Owners, []string{"teammate@example.com"}) || !slices.Equal(spec.OwnerGroups, []string{"platform-team"}) {
+		t.Errorf("Owners = %v, OwnerGroups = %v", spec.Owners, spec.OwnerGroups)
+	}
+}
+
+func TestBuildInstanceSpec_MockMode(t *testing.T) {
+	spec, err := buildInstanceSpec(map[string]any{"mock_mode": true}, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !spec.MockMode {
+		t.Error("MockMode = false, want true")
+	}
+
+	spec, err = buildInstanceSpec(map[string]any{}, "user@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.MockMode {
+		t.Error("MockMode = true, want false by default")
+	}
+}
+
 func TestParseStringArray(t *testing.T) {
 	tests := []struct {
 		name string
@@ -4,16 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"time"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 	mcpgolang "github.com/mark3labs/mcp-go/mcp"
 	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -40,6 +38,14 @@ func (s *Server) handleCreateInstance(ctx context.Context, request mcpgolang.Cal
 		return mcpError(err.Error()), nil
 	}
 
+	if err := s.applyPermissionPolicy(ctx, &spec); err != nil {
+		return mcpError(err.Error()), nil
+	}
+
+	if err := s.preflightCreate(ctx, name, user, &spec); err != nil {
+		return toolError(err), nil
+	}
+
 	instance := &klausv1alpha1.KlausInstance{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -47,6 +53,7 @@ func (s *Server) handleCreateInstance(ctx context.Context, request mcpgolang.Cal
 		},
 		Spec: spec,
 	}
+	s.stampProvenance(ctx, request, instance)
 
 	if err := s.client.Create(ctx, instance); err != nil {
 		if apierrors.IsAlreadyExists(err) {
@@ -59,17 +66,22 @@ func (s *Server) handleCreateInstance(ctx context.Context, request mcpgolang.Cal
 		keyName:     name,
 		keyOwner:    user,
 		keyModel:    spec.Claude.Model,
-		"namespace": resources.UserNamespace(user),
+		"namespace": s.ownerNamespace(user),
 		keyStatus:   "creating",
 	}), nil
 }
 
-// handleListInstances lists the calling user's instances.
+// handleListInstances lists the instances owned by or shared with the calling
+// user.
 func (s *Server) handleListInstances(ctx context.Context, _ mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
 	user, err := s.extractUser(ctx)
 	if err != nil {
 		return mcpError("authentication required: " + err.Error()), nil
 	}
+	groups, err := s.callerGroups(ctx)
+	if err != nil {
+		return mcpError("authentication required: " + err.Error()), nil
+	}
 
 	var instanceList klausv1alpha1.KlausInstanceList
 	if err := s.client.List(ctx, &instanceList, client.InNamespace(s.operatorNamespace)); err != nil {
@@ -78,11 +90,12 @@ func (s *Server) handleListInstances(ctx context.Context, _ mcpgolang.CallToolRe
 
 	var userInstances []map[string]any
 	for _, inst := range instanceList.Items {
-		if inst.Spec.Owner != user {
+		if !resources.IsOwnedBy(&inst, user, groups) {
 			continue
 		}
 		userInstances = append(userInstances, map[string]any{
 			keyName:        inst.Name,
+			keyOwner:       inst.Spec.Owner,
 			"state":        string(inst.Status.State),
 			"endpoint":     inst.Status.Endpoint,
 			keyMode:        inst.Status.Mode,
@@ -142,17 +155,46 @@ func (s *Server) handleGetInstance(ctx context.Context, request mcpgolang.CallTo
 		keyPlugins:     instance.Status.PluginCount,
 		"mcpServers":   instance.Status.MCPServerCount,
 		"created":      instance.CreationTimestamp.Format(time.RFC3339),
-		"namespace":    resources.UserNamespace(instance.Spec.Owner),
+		"namespace":    s.instanceNamespace(instance),
+	}
+
+	if len(instance.Spec.Owners) > 0 {
+		result["owners"] = instance.Spec.Owners
+	}
+	if len(instance.Spec.OwnerGroups) > 0 {
+		result["ownerGroups"] = instance.Spec.OwnerGroups
+	}
+
+	if instance.Status.PersonalityRevision != "" && instance.Status.PersonalityRevision != instance.Status.Personality {
+		result["personalityRevision"] = instance.Status.PersonalityRevision
 	}
 
 	if instance.Status.Toolchain != "" {
 		result["toolchain"] = instance.Status.Toolchain
 	}
 
+	if instance.Spec.MockMode {
+		result["mock"] = true
+	}
+
 	if instance.Status.LastActivity != nil {
 		result["lastActivity"] = instance.Status.LastActivity.Format(time.RFC3339)
 	}
 
+	if instance.Status.Workspace != nil {
+		result["workspace"] = instance.Status.Workspace
+	}
+
+	if instance.Status.TokenUsage != nil {
+		result["tokenUsage"] = instance.Status.TokenUsage
+	}
+
+	// Rollout summary from the DeploymentReady condition, e.g.
+	// "0/1 replicas available: container klaus: ImagePullBackOff: ...".
+	if cond := apimeta.FindStatusCondition(instance.Status.Conditions, conditionDeploymentReady); cond != nil {
+		result["rollout"] = cond.Message
+	}
+
 	// Best-effort enrichment: query agent-level status when running.
 	s.enrichAgentStatus(ctx, instance, result)
 
@@ -211,7 +253,7 @@ func (s *Server) handleRestartInstance(ctx context.Context, request mcpgolang.Ca
 	}
 
 	// Restart by patching the Deployment with a restart annotation.
-	namespace := resources.UserNamespace(instance.Spec.Owner)
+	namespace := s.instanceNamespace(instance)
 	var deployment appsv1.Deployment
 	if err := s.client.Get(ctx, types.NamespacedName{
 		Name:      instance.Name,
@@ -242,92 +284,9 @@ func (s *Server) handleRestartInstance(ctx context.Context, request mcpgolang.Ca
 	}), nil
 }
 
-const (
-	// defaultTailLines is the default number of log lines to return.
-	defaultTailLines int64 = 100
-	// maxTailLines caps the tail parameter to prevent unbounded reads.
-	maxTailLines int64 = 10_000
-	// maxLogBytes caps the total bytes read from the log stream (1 MiB).
-	maxLogBytes int64 = 1 << 20
-)
-
-// handleGetLogs retrieves recent log output from a Klaus instance pod.
-func (s *Server) handleGetLogs(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
-	instance, errResult := s.getOwnedInstance(ctx, request)
-	if errResult != nil {
-		return errResult, nil
-	}
-
-	args := request.GetArguments()
-
-	// Parse optional tail parameter (default 100, capped at 10 000).
-	tailLines := defaultTailLines
-	if v, ok := args["tail"].(float64); ok && v > 0 {
-		tailLines = int64(v)
-	}
-	if tailLines > maxTailLines {
-		tailLines = maxTailLines
-	}
-
-	// Parse optional container parameter (default "klaus").
-	container := klausContainerName
-	if v, _ := args["container"].(string); v != "" {
-		container = v
-	}
-
-	// Find pods matching the instance in the user namespace.
-	namespace := resources.UserNamespace(instance.Spec.Owner)
-	var podList corev1.PodList
-	sel := labels.SelectorFromSet(resources.SelectorLabels(instance))
-	if err := s.client.List(ctx, &podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
-		return mcpError("failed to list pods: " + err.Error()), nil
-	}
-
-	if len(podList.Items) == 0 {
-		return mcpError("no pods found for instance '" + instance.Name + "' (instance may still be starting)"), nil
-	}
-
-	// Prefer a Running pod when multiple exist (e.g. during a rollout).
-	pod := podList.Items[0]
-	for _, p := range podList.Items {
-		if p.Status.Phase == corev1.PodRunning {
-			pod = p
-			break
-		}
-	}
-
-	if s.podLogReader == nil {
-		return mcpError("pod log reader not configured"), nil
-	}
-
-	logOpts := &corev1.PodLogOptions{
-		Container: container,
-		TailLines: &tailLines,
-	}
-
-	stream, err := s.podLogReader.GetLogs(ctx, namespace, pod.Name, logOpts)
-	if err != nil {
-		return mcpError(fmt.Sprintf("failed to get logs for container %q: %v", container, err)), nil
-	}
-	defer func() { _ = stream.Close() }()
-
-	// Cap the read to maxLogBytes to prevent unbounded memory allocation.
-	logBytes, err := io.ReadAll(io.LimitReader(stream, maxLogBytes))
-	if err != nil {
-		return mcpError("failed to read log stream: " + err.Error()), nil
-	}
-
-	// Return raw text rather than JSON; log content is already human-readable.
-	return &mcpgolang.CallToolResult{
-		Content: []mcpgolang.Content{
-			mcpgolang.NewTextContent(string(logBytes)),
-		},
-	}, nil
-}
-
 // getOwnedInstance extracts the user and instance name from a tool request,
-// fetches the KlausInstance, and verifies ownership. Returns the instance on
-// success, or an MCP error result on failure.
+// fetches the KlausInstance, and verifies that the user or one of their groups
+// owns it. Returns the instance on success, or an MCP error result on failure.
 func (s *Server) getOwnedInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*klausv1alpha1.KlausInstance, *mcpgolang.CallToolResult) {
 	user, err := s.extractUser(ctx)
 	if err != nil {
@@ -351,7 +310,11 @@ func (s *Server) getOwnedInstance(ctx context.Context, request mcpgolang.CallToo
 		return nil, mcpError("failed to get instance: " + err.Error())
 	}
 
-	if instance.Spec.Owner != user {
+	groups, err := s.callerGroups(ctx)
+	if err != nil {
+		return nil, mcpError("authentication required: " + err.Error())
+	}
+	if !resources.IsOwnedBy(&instance, user, groups) {
 		return nil, mcpError("access denied: you do not own instance '" + name + "'")
 	}
 
@@ -360,8 +323,16 @@ func (s *Server) getOwnedInstance(ctx context.Context, request mcpgolang.CallToo
 
 // extractUser extracts the user identity from the request context.
 // The Authorization header is injected into context by HTTPContextFuncAuth via
-// mcp-go's WithHTTPContextFunc. The token is a JWT forwarded by muster.
+// mcp-go's WithHTTPContextFunc. The token is a JWT forwarded by muster, and is
+// verified when a TokenVerifier is set.
 func (s *Server) extractUser(ctx context.Context) (string, error) {
+	if s.tokenVerifier != nil {
+		identity, err := s.callerIdentity(ctx)
+		if err != nil {
+			return "", err
+		}
+		return identity.Username, nil
+	}
 	token := AuthTokenFromContext(ctx)
 	if token == "" {
 		return "", fmt.Errorf("no Authorization header in request")
@@ -384,7 +355,7 @@ func mcpSuccess(data any) *mcpgolang.CallToolResult {
 // handleListPlugins lists available Klaus plugins from the OCI registry.
 func (s *Server) handleListPlugins(ctx context.Context, _ mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
 	if s.ociClient == nil {
-		return mcpError("OCI client not configured"), nil
+		return mcpError("OCI client not configured, e.g. in offline mode"), nil
 	}
 	return s.listEntries(ctx, s.ociClient.ListPlugins, "plugins")
 }
@@ -392,17 +363,56 @@ func (s *Server) handleListPlugins(ctx context.Context, _ mcpgolang.CallToolRequ
 // handleListPersonalities lists available Klaus personalities from the OCI registry.
 func (s *Server) handleListPersonalities(ctx context.Context, _ mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
 	if s.ociClient == nil {
-		return mcpError("OCI client not configured"), nil
+		return mcpError("OCI client not configured, e.g. in offline mode"), nil
 	}
 	return s.listEntries(ctx, s.ociClient.ListPersonalities, "personalities")
 }
 
-// handleListToolchains lists available Klaus toolchain images from the OCI registry.
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustoolchains,verbs=get;list;watch
+
+// handleListToolchains lists the KlausToolchains in the operator namespace
+// followed by the Klaus toolchain images from the OCI registry. Without an
+// OCI client only the KlausToolchains are listed.
 func (s *Server) handleListToolchains(ctx context.Context, _ mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
-	if s.ociClient == nil {
-		return mcpError("OCI client not configured"), nil
+	var toolchains klausv1alpha1.KlausToolchainList
+	if err := s.client.List(ctx, &toolchains, client.InNamespace(s.operatorNamespace)); err != nil {
+		return mcpError("failed to list toolchains: " + err.Error()), nil
+	}
+	items := make([]map[string]any, 0, len(toolchains.Items))
+	for _, tc := range toolchains.Items {
+		item := map[string]any{
+			keyName:     tc.Name,
+			"source":    "KlausToolchain",
+			"reference": tc.Spec.Image,
+		}
+		if tc.Spec.Description != "" {
+			item["description"] = tc.Spec.Description
+		}
+		if tc.Spec.Language != "" {
+			item["language"] = tc.Spec.Language
+		}
+		if len(tc.Spec.Personalities) > 0 {
+			item["personalities"] = tc.Spec.Personalities
+		}
+		items = append(items, item)
+	}
+
+	if s.ociClient != nil {
+		entries, err := s.ociClient.ListToolchains(ctx)
+		if err != nil {
+			return mcpError("failed to list toolchains: " + err.Error()), nil
+		}
+		for _, item := range entryItems(entries) {
+			item["source"] = "registry"
+			items = append(items, item)
+		}
 	}
-	return s.listEntries(ctx, s.ociClient.ListToolchains, "toolchains")
+
+	return mcpSuccess(map[string]any{
+		"kind":  "toolchains",
+		"count": len(items),
+		"items": items,
+	}), nil
 }
 
 func (s *Server) listEntries(ctx context.Context, listFn func(context.Context, ...klausoci.ListOption) ([]klausoci.ListEntry, error), kind string) (*mcpgolang.CallToolResult, error) {
@@ -411,6 +421,16 @@ func (s *Server) listEntries(ctx context.Context, listFn func(context.Context, .
 		return mcpError(fmt.Sprintf("failed to list %s: %s", kind, err.Error())), nil
 	}
 
+	items := entryItems(entries)
+	return mcpSuccess(map[string]any{
+		"kind":  kind,
+		"count": len(items),
+		"items": items,
+	}), nil
+}
+
+// entryItems converts OCI catalog entries to list items.
+func entryItems(entries []klausoci.ListEntry) []map[string]any {
 	items := make([]map[string]any, 0, len(entries))
 	for _, e := range entries {
 		item := map[string]any{
@@ -423,12 +443,7 @@ func (s *Server) listEntries(ctx context.Context, listFn func(context.Context, .
 		}
 		items = append(items, item)
 	}
-
-	return mcpSuccess(map[string]any{
-		"kind":  kind,
-		"count": len(items),
-		"items": items,
-	}), nil
+	return items
 }
 
 func mcpError(msg string) *mcpgolang.CallToolResult {
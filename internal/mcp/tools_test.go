@@ -13,6 +13,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -143,6 +144,134 @@ func TestHandleGetInstance_AgentUnreachable(t *testing.T) {
 	}
 }
 
+func TestHandleGetInstance_RolloutSummary(t *testing.T) {
+	scheme := testScheme(t)
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-agent",
+			Namespace: "klaus-system",
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "user@example.com",
+		},
+		Status: klausv1alpha1.KlausInstanceStatus{
+			State: klausv1alpha1.InstanceStatePending,
+			Conditions: []metav1.Condition{{
+				Type:    conditionDeploymentReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "ImagePullBackOff",
+				Message: "0/1 replicas available: container klaus: ImagePullBackOff: unauthorized",
+			}},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+
+	result, err := s.handleGetInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data["rollout"] != "0/1 replicas available: container klaus: ImagePullBackOff: unauthorized" {
+		t.Errorf("rollout = %v, want the DeploymentReady message", data["rollout"])
+	}
+}
+
+func TestHandleGetInstance_WorkspaceStatus(t *testing.T) {
+	scheme := testScheme(t)
+	synced := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-agent",
+			Namespace: "klaus-system",
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "user@example.com",
+		},
+		Status: klausv1alpha1.KlausInstanceStatus{
+			State: klausv1alpha1.InstanceStatePending,
+			Workspace: &klausv1alpha1.WorkspaceStatus{
+				ResolvedSHA:  "1111111111111111111111111111111111111111",
+				LastSyncTime: &synced,
+				Dirty:        ptr.To(true),
+				ChangedFiles: 2,
+			},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+
+	result, err := s.handleGetInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var data struct {
+		Workspace map[string]any `json:"workspace"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Workspace["resolvedSHA"] != "1111111111111111111111111111111111111111" ||
+		data.Workspace["lastSyncTime"] != "2026-01-02T03:04:05Z" ||
+		data.Workspace["dirty"] != true || data.Workspace["changedFiles"] != float64(2) {
+		t.Errorf("workspace = %v, want the recorded workspace status", data.Workspace)
+	}
+}
+
+func TestHandleGetInstance_PersonalityRevision(t *testing.T) {
+	scheme := testScheme(t)
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-agent",
+			Namespace: "klaus-system",
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:                   "user@example.com",
+			Personality:             "gsoci.azurecr.io/giantswarm/personalities/go-dev:latest",
+			PersonalityUpdatePolicy: klausv1alpha1.PersonalityUpdatePolicyManual,
+		},
+		Status: klausv1alpha1.KlausInstanceStatus{
+			State:               klausv1alpha1.InstanceStatePending,
+			Personality:         "gsoci.azurecr.io/giantswarm/personalities/go-dev:latest",
+			PersonalityRevision: "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.0.0",
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+
+	result, err := s.handleGetInstance(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var data struct {
+		PersonalityRevision string `json:"personalityRevision"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.PersonalityRevision != "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.0.0" {
+		t.Errorf("personalityRevision = %q, want the pinned revision", data.PersonalityRevision)
+	}
+}
+
 func TestHandleGetInstance_NotRunningSkipsAgent(t *testing.T) {
 	scheme := testScheme(t)
 	instance := &klausv1alpha1.KlausInstance{
@@ -793,3 +922,68 @@ func TestHandleCreateInstance_InvalidPlugin(t *testing.T) {
 		t.Errorf("error = %q, want it to mention plugin reference", text)
 	}
 }
+
+func TestSharedInstanceAccess(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := klausv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	shared := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:       "lead@example.com",
+			Owners:      []string{"teammate@example.com"},
+			OwnerGroups: []string{"platform-team"},
+		},
+	}
+	private := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "lead-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "lead@example.com"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(shared, private).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	tests := []struct {
+		name      string
+		claims    string
+		wantNames []string
+	}{
+		{name: "primary owner", claims: `{"email":"lead@example.com"}`, wantNames: []string{"lead-agent", "team-agent"}},
+		{name: "additional owner", claims: `{"email":"teammate@example.com"}`, wantNames: []string{"team-agent"}},
+		{name: "owner group member", claims: `{"email":"other@example.com","groups":["platform-team"]}`, wantNames: []string{"team-agent"}},
+		{name: "stranger", claims: `{"email":"other@example.com","groups":["dev"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), authTokenKey, "Bearer "+buildTestJWT(tt.claims))
+
+			result, err := s.handleListInstances(ctx, mcpgolang.CallToolRequest{})
+			if err != nil || result.IsError {
+				t.Fatalf("handleListInstances() = %v, %v", result, err)
+			}
+			var data struct {
+				Instances []map[string]any `json:"instances"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+				t.Fatalf("failed to parse response: %v", err)
+			}
+			var names []string
+			for _, inst := range data.Instances {
+				names = append(names, inst["name"].(string))
+				if inst["owner"] != "lead@example.com" {
+					t.Errorf("owner = %v, want the primary owner", inst["owner"])
+				}
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.wantNames) {
+				t.Errorf("listed instances = %v, want %v", names, tt.wantNames)
+			}
+
+			req := mcpgolang.CallToolRequest{}
+			req.Params.Arguments = map[string]any{"name": "team-agent"}
+			_, errResult := s.getOwnedInstance(ctx, req)
+			if got := errResult == nil; got != (len(tt.wantNames) > 0) {
+				t.Errorf("getOwnedInstance() granted = %v, want %v", got, len(tt.wantNames) > 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// updatableFields are the update_instance arguments, in the order they are
+// reported.
+var updatableFields = []string{keyModel, "system_prompt", keyPersonality, keyPlugins, "mcp_servers", "max_budget_usd", "owners", "owner_groups"}
+
+// SetEventRecorder sets the recorder for events about changes made through
+// the MCP server. Without a recorder no events are emitted.
+func (s *Server) SetEventRecorder(recorder record.EventRecorder) {
+	s.recorder = recorder
+}
+
+// handleUpdateInstance changes a subset of the spec of an owned instance in
+// place. Only the fields present in the request are changed; the controller
+// rolls the instance out with the new configuration. Only the primary owner
+// may change who shares the instance.
+func (s *Server) handleUpdateInstance(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	instance, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+	user, _ := s.extractUser(ctx)
+
+	base := instance.DeepCopy()
+	updated, err := applyInstanceUpdate(request.GetArguments(), &instance.Spec)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+	if len(updated) == 0 {
+		return mcpError("nothing to update: set at least one of " + strings.Join(updatableFields, ", ")), nil
+	}
+	if user != instance.Spec.Owner && (slices.Contains(updated, "owners") || slices.Contains(updated, "owner_groups")) {
+		return mcpError("access denied: only the primary owner " + instance.Spec.Owner + " can change owners and owner_groups"), nil
+	}
+
+	if equality.Semantic.DeepEqual(base.Spec, instance.Spec) {
+		return mcpSuccess(map[string]any{
+			keyName:    instance.Name,
+			keyStatus:  "unchanged",
+			keyMessage: fmt.Sprintf("Instance '%s' already has the requested configuration", instance.Name),
+		}), nil
+	}
+
+	s.stampProvenance(ctx, request, instance)
+	if err := s.client.Patch(ctx, instance, client.MergeFrom(base)); err != nil {
+		return mcpError("failed to update instance: " + err.Error()), nil
+	}
+
+	if s.recorder != nil {
+		s.recorder.Eventf(instance, corev1.EventTypeNormal, "UpdatedViaMCP",
+			"%s updated %s through update_instance", user, strings.Join(updated, ", "))
+	}
+
+	return mcpSuccess(map[string]any{
+		keyName:    instance.Name,
+		keyStatus:  "updating",
+		"updated":  updated,
+		keyMessage: fmt.Sprintf("Instance '%s' is being updated", instance.Name),
+	}), nil
+}
+
+// applyInstanceUpdate applies the update_instance arguments to spec and
+// returns the names of the arguments present. An empty string or list
+// clears the field, and a max_budget_usd of 0 removes the budget. The model
+// cannot be cleared.
+func applyInstanceUpdate(args map[string]any, spec *klausv1alpha1.KlausInstanceSpec) ([]string, error) {
+	var updated []string
+	for _, field := range updatableFields {
+		v, ok := args[field]
+		if !ok || v == nil {
+			continue
+		}
+		updated = append(updated, field)
+
+		switch field {
+		case keyModel:
+			model, _ := v.(string)
+			if model == "" {
+				return nil, fmt.Errorf("model must not be empty")
+			}
+			spec.Claude.Model = model
+		case "system_prompt":
+			spec.Claude.SystemPrompt, _ = v.(string)
+		case keyPersonality:
+			spec.Personality, _ = v.(string)
+		case keyPlugins:
+			refs := parseStringArray(v)
+			plugins := make([]klausv1alpha1.PluginReference, 0, len(refs))
+			for _, ref := range refs {
+				p, err := parsePluginReference(ref)
+				if err != nil {
+					return nil, fmt.Errorf("invalid plugin reference: %w", err)
+				}
+				plugins = append(plugins, p)
+			}
+			spec.Plugins = plugins
+		case "mcp_servers":
+			// Keep the overrides of servers that stay referenced.
+			existing := make(map[string]klausv1alpha1.MCPServerReference, len(spec.MCPServers))
+			for _, ref := range spec.MCPServers {
+				existing[ref.Name] = ref
+			}
+			names := parseStringArray(v)
+			refs := make([]klausv1alpha1.MCPServerReference, 0, len(names))
+			for _, name := range names {
+				ref, ok := existing[name]
+				if !ok {
+					ref = klausv1alpha1.MCPServerReference{Name: name}
+				}
+				refs = append(refs, ref)
+			}
+			spec.MCPServers = refs
+		case "max_budget_usd":
+			budget, ok := v.(float64)
+			if !ok || budget < 0 {
+				return nil, fmt.Errorf("invalid max_budget_usd %v: must be a non-negative number", v)
+			}
+			if budget == 0 {
+				spec.Claude.MaxBudgetUSD = nil
+			} else {
+				spec.Claude.MaxBudgetUSD = &budget
+			}
+		case "owners":
+			spec.Owners = parseStringArray(v)
+		case "owner_groups":
+			spec.OwnerGroups = parseStringArray(v)
+		}
+	}
+
+	// Empty lists are cleared rather than stored.
+	if len(spec.Plugins) == 0 {
+		spec.Plugins = nil
+	}
+	if len(spec.MCPServers) == 0 {
+		spec.MCPServers = nil
+	}
+	if len(spec.Owners) == 0 {
+		spec.Owners = nil
+	}
+	if len(spec.OwnerGroups) == 0 {
+		spec.OwnerGroups = nil
+	}
+	return updated, nil
+}
@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func updateTestServer(t *testing.T) (*Server, *record.FakeRecorder) {
+	t.Helper()
+	budget := 5.0
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:       "user@example.com",
+			Personality: "gsoci.azurecr.io/giantswarm/personalities/sre:v1.0.0",
+			Claude: klausv1alpha1.ClaudeConfig{
+				Model:        defaultModel,
+				SystemPrompt: "be helpful",
+				MaxBudgetUSD: &budget,
+			},
+			Plugins:    []klausv1alpha1.PluginReference{{Repository: "gsoci.azurecr.io/giantswarm/plugins/gs-base", Tag: "v0.1.0"}},
+			MCPServers: []klausv1alpha1.MCPServerReference{{Name: "github"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance).Build()
+	recorder := record.NewFakeRecorder(10)
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+	s.SetEventRecorder(recorder)
+	return s, recorder
+}
+
+func callUpdateInstance(t *testing.T, s *Server, user string, args map[string]any) (*mcpgolang.CallToolResult, map[string]any) {
+	t.Helper()
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := s.handleUpdateInstance(authCtx(user), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data map[string]any
+	if !result.IsError {
+		if err := json.Unmarshal([]byte(result.Content[0].(mcpgolang.TextContent).Text), &data); err != nil {
+			t.Fatalf("failed to parse response: %v", err)
+		}
+	}
+	return result, data
+}
+
+func getUpdatedInstance(t *testing.T, s *Server) *klausv1alpha1.KlausInstance {
+	t.Helper()
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(context.Background(), types.NamespacedName{Name: "my-agent", Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	return &instance
+}
+
+func TestHandleUpdateInstance_Success(t *testing.T) {
+	s, recorder := updateTestServer(t)
+
+	result, data := callUpdateInstance(t, s, "user@example.com", map[string]any{
+		"name":    "my-agent",
+		"model":   "claude-opus-4-20250514",
+		"plugins": []any{"gsoci.azurecr.io/giantswarm/plugins/gs-sre:v0.2.0"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", result.Content[0].(mcpgolang.TextContent).Text)
+	}
+	if data["status"] != "updating" {
+		t.Errorf("status = %v, want updating", data["status"])
+	}
+	if updated, _ := data["updated"].([]any); len(updated) != 2 || updated[0] != "model" || updated[1] != "plugins" {
+		t.Errorf("updated = %v, want [model plugins]", data["updated"])
+	}
+
+	instance := getUpdatedInstance(t, s)
+	if instance.Spec.Claude.Model != "claude-opus-4-20250514" {
+		t.Errorf("model = %q", instance.Spec.Claude.Model)
+	}
+	if len(instance.Spec.Plugins) != 1 || instance.Spec.Plugins[0].Tag != "v0.2.0" {
+		t.Errorf("plugins = %+v", instance.Spec.Plugins)
+	}
+	// Fields not in the request are kept.
+	if instance.Spec.Claude.SystemPrompt != "be helpful" || len(instance.Spec.MCPServers) != 1 || *instance.Spec.Claude.MaxBudgetUSD != 5 {
+		t.Errorf("unrequested fields changed: %+v", instance.Spec)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "UpdatedViaMCP") || !strings.Contains(event, "model, plugins") {
+			t.Errorf("event = %q", event)
+		}
+	default:
+		t.Error("expected an UpdatedViaMCP event")
+	}
+}
+
+func TestHandleUpdateInstance_ClearsFields(t *testing.T) {
+	s, _ := updateTestServer(t)
+
+	result, _ := callUpdateInstance(t, s, "user@example.com", map[string]any{
+		"name":           "my-agent",
+		"system_prompt":  "",
+		"personality":    "",
+		"mcp_servers":    []any{},
+		"max_budget_usd": float64(0),
+	})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", result.Content[0].(mcpgolang.TextContent).Text)
+	}
+
+	instance := getUpdatedInstance(t, s)
+	if instance.Spec.Claude.SystemPrompt != "" || instance.Spec.Personality != "" ||
+		instance.Spec.MCPServers != nil || instance.Spec.Claude.MaxBudgetUSD != nil {
+		t.Errorf("expected the fields to be cleared, got %+v", instance.Spec)
+	}
+	if len(instance.Spec.Plugins) != 1 {
+		t.Errorf("plugins = %+v, want them kept", instance.Spec.Plugins)
+	}
+}
+
+func TestHandleUpdateInstance_Unchanged(t *testing.T) {
+	s, recorder := updateTestServer(t)
+
+	_, data := callUpdateInstance(t, s, "user@example.com", map[string]any{"name": "my-agent", "model": defaultModel})
+	if data["status"] != "unchanged" {
+		t.Errorf("status = %v, want unchanged", data["status"])
+	}
+	if len(recorder.Events) != 0 {
+		t.Error("expected no event for a no-op update")
+	}
+}
+
+func TestHandleUpdateInstance_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		args    map[string]any
+		wantErr string
+	}{
+		{name: "not owner", user: "other@example.com", args: map[string]any{"model": "x"}, wantErr: "access denied"},
+		{name: "nothing to update", user: "user@example.com", args: map[string]any{}, wantErr: "nothing to update"},
+		{name: "empty model", user: "user@example.com", args: map[string]any{"model": ""}, wantErr: "model must not be empty"},
+		{name: "invalid plugin", user: "user@example.com", args: map[string]any{"plugins": []any{"no-tag"}}, wantErr: "invalid plugin reference"},
+		{name: "negative budget", user: "user@example.com", args: map[string]any{"max_budget_usd": float64(-1)}, wantErr: "invalid max_budget_usd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := updateTestServer(t)
+			tt.args["name"] = "my-agent"
+			result, _ := callUpdateInstance(t, s, tt.user, tt.args)
+			if !result.IsError {
+				t.Fatal("expected an MCP error")
+			}
+			if text := result.Content[0].(mcpgolang.TextContent).Text; !strings.Contains(text, tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", text, tt.wantErr)
+			}
+			if getUpdatedInstance(t, s).Spec.Claude.Model != defaultModel {
+				t.Error("expected the instance to be left unchanged")
+			}
+		})
+	}
+}
+
+func TestHandleUpdateInstance_Owners(t *testing.T) {
+	s, recorder := updateTestServer(t)
+
+	result, _ := callUpdateInstance(t, s, "user@example.com", map[string]any{
+		"name":         "my-agent",
+		"owners":       []any{"teammate@example.com"},
+		"owner_groups": []any{"platform-team"},
+	})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	spec := getUpdatedInstance(t, s).Spec
+	if len(spec.Owners) != 1 || spec.Owners[0] != "teammate@example.com" ||
+		len(spec.OwnerGroups) != 1 || spec.OwnerGroups[0] != "platform-team" {
+		t.Errorf("owners = %v, ownerGroups = %v", spec.Owners, spec.OwnerGroups)
+	}
+	<-recorder.Events
+
+	// Additional owners can change the configuration, and the event names
+	// them, but cannot change who shares the instance.
+	result, _ = callUpdateInstance(t, s, "teammate@example.com", map[string]any{"name": "my-agent", "system_prompt": "be brief"})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "teammate@example.com updated system_prompt") {
+		t.Errorf("event = %q, want it to name the caller", event)
+	}
+	result, _ = callUpdateInstance(t, s, "teammate@example.com", map[string]any{"name": "my-agent", "owners": []any{}})
+	if !result.IsError || !strings.Contains(result.Content[0].(mcpgolang.TextContent).Text, "only the primary owner") {
+		t.Errorf("expected the owners update to be rejected, got %v", result.Content)
+	}
+	if len(getUpdatedInstance(t, s).Spec.Owners) != 1 {
+		t.Error("expected the owners to be left unchanged")
+	}
+
+	result, _ = callUpdateInstance(t, s, "user@example.com", map[string]any{"name": "my-agent", "owners": []any{}})
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %v", result.Content)
+	}
+	if owners := getUpdatedInstance(t, s).Spec.Owners; owners != nil {
+		t.Errorf("owners = %v, want cleared", owners)
+	}
+}
+
+func TestApplyInstanceUpdate_KeepsMCPServerOverrides(t *testing.T) {
+	spec := &klausv1alpha1.KlausInstanceSpec{MCPServers: []klausv1alpha1.MCPServerReference{
+		{Name: "github", Headers: map[string]string{"X-GitHub-Org": "giantswarm"}},
+		{Name: "slack"},
+	}}
+	if _, err := applyInstanceUpdate(map[string]any{"mcp_servers": []any{"github", "linear"}}, spec); err != nil {
+		t.Fatalf("applyInstanceUpdate: %v", err)
+	}
+	if len(spec.MCPServers) != 2 || spec.MCPServers[0].Headers["X-GitHub-Org"] != "giantswarm" || spec.MCPServers[1].Name != "linear" {
+		t.Errorf("mcp servers = %+v, want the github overrides kept", spec.MCPServers)
+	}
+}
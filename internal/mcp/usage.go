@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+// instanceMetricsResult is the JSON structure returned by
+// get_instance_metrics.
+type instanceMetricsResult struct {
+	Name string `json:"name"`
+	*usage.Usage
+	// Recorded is the usage last recorded in the instance status, returned
+	// when live collection is disabled.
+	Recorded *klausv1alpha1.ResourceUsage `json:"recorded,omitempty"`
+	Hints    []string                     `json:"hints,omitempty"`
+}
+
+// SetUsageCollector sets the collector reading live instance resource
+// usage. Without a collector, get_instance_metrics returns the usage
+// recorded in the instance status.
+func (s *Server) SetUsageCollector(collector *usage.Collector) {
+	s.usageCollector = collector
+}
+
+// handleGetInstanceMetrics returns the CPU, memory and workspace usage of
+// an owned instance next to its requests and limits, with hints when the
+// instance is short of a resource.
+func (s *Server) handleGetInstanceMetrics(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	instance, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	if s.usageCollector == nil {
+		return mcpSuccess(instanceMetricsResult{
+			Name:     instance.Name,
+			Usage:    &usage.Usage{Warnings: []string{"live usage collection is disabled"}},
+			Recorded: instance.Status.ResourceUsage,
+		}), nil
+	}
+
+	current, err := s.usageCollector.Collect(ctx, instance)
+	if err != nil {
+		return mcpError("failed to collect usage: " + err.Error()), nil
+	}
+	if current.Pod == "" {
+		current.Warnings = append(current.Warnings, "instance '"+instance.Name+"' has no running pod")
+	}
+	return mcpSuccess(instanceMetricsResult{
+		Name:  instance.Name,
+		Usage: current,
+		Hints: current.Hints(),
+	}), nil
+}
@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+)
+
+func TestHandleGetInstanceMetrics(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-agent-abc",
+			Namespace: "klaus-user-user-example-com",
+			Labels:    map[string]string{"app.kubernetes.io/name": "klaus", "app.kubernetes.io/instance": "my-agent"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "klaus",
+			Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	metrics := &unstructured.Unstructured{Object: map[string]any{
+		"metadata":   map[string]any{"name": "my-agent-abc", "namespace": "klaus-user-user-example-com"},
+		"containers": []any{map[string]any{"name": "klaus", "usage": map[string]any{"cpu": "300m", "memory": "1000Mi"}}},
+	}}
+	metrics.SetGroupVersionKind(usage.PodMetricsGVK)
+
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance, pod, metrics).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+	s.SetUsageCollector(&usage.Collector{Client: c, MetricsReader: c})
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleGetInstanceMetrics(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", text)
+	}
+
+	var data struct {
+		Name       string                 `json:"name"`
+		Pod        string                 `json:"pod"`
+		CPU        string                 `json:"cpu"`
+		Containers []usage.ContainerUsage `json:"containers"`
+		Hints      []string               `json:"hints"`
+	}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Name != "my-agent" || data.Pod != "my-agent-abc" || data.CPU != "300m" {
+		t.Errorf("result = %+v", data)
+	}
+	if len(data.Containers) != 1 || data.Containers[0].MemoryLimit.String() != "1Gi" {
+		t.Errorf("containers = %+v", data.Containers)
+	}
+	if len(data.Hints) != 1 || !strings.Contains(data.Hints[0], "98% of its memory limit") {
+		t.Errorf("hints = %v", data.Hints)
+	}
+
+	if result, _ := s.handleGetInstanceMetrics(authCtx("other@example.com"), req); !result.IsError {
+		t.Error("expected access to be denied for another user")
+	}
+}
+
+func TestHandleGetInstanceMetrics_NoCollector(t *testing.T) {
+	cpu := resource.MustParse("100m")
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+		Status: klausv1alpha1.KlausInstanceStatus{
+			ResourceUsage: &klausv1alpha1.ResourceUsage{CPU: &cpu, ObservedAt: metav1.Now()},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(instance).Build()
+	s := &Server{client: c, operatorNamespace: "klaus-system"}
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleGetInstanceMetrics(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError || !strings.Contains(text, `"recorded"`) || !strings.Contains(text, "collection is disabled") {
+		t.Errorf("result = %s", text)
+	}
+}
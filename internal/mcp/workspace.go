@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const (
+	// workspaceGitWait caps how long a workspace tool waits for its Job.
+	workspaceGitWait = 2 * time.Minute
+	// initialWorkspaceGitPoll is the initial polling interval for the Job.
+	initialWorkspaceGitPoll = time.Second
+	// maxWorkspaceGitPoll caps the exponential backoff for Job polling.
+	maxWorkspaceGitPoll = 5 * time.Second
+
+	// jobNameLabel is set by the Job controller on the pods of a Job.
+	jobNameLabel = "batch.kubernetes.io/job-name"
+)
+
+// commitSHA matches a full SHA-1 or SHA-256 git object name.
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// workspaceGitResult is the JSON structure returned by the workspace tools.
+type workspaceGitResult struct {
+	Name      string `json:"name"`
+	Operation string `json:"operation"`
+	Status    string `json:"status"`
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// SetGitImage sets the image running workspace git operations. It defaults
+// to resources.DefaultGitCloneImage.
+func (s *Server) SetGitImage(image string) {
+	s.gitImage = image
+}
+
+// handleWorkspaceStatus reports the git state of an owned instance's
+// workspace checkout.
+func (s *Server) handleWorkspaceStatus(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	return s.runWorkspaceGit(ctx, request, resources.WorkspaceGitStatus)
+}
+
+// handleWorkspacePull fast-forwards an owned instance's workspace checkout
+// to the remote ref.
+func (s *Server) handleWorkspacePull(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	return s.runWorkspaceGit(ctx, request, resources.WorkspaceGitPull)
+}
+
+// handleWorkspaceReset resets an owned instance's workspace checkout to the
+// remote ref, discarding local commits and changes.
+func (s *Server) handleWorkspaceReset(ctx context.Context, request mcpgolang.CallToolRequest) (*mcpgolang.CallToolResult, error) {
+	return s.runWorkspaceGit(ctx, request, resources.WorkspaceGitReset)
+}
+
+// runWorkspaceGit runs a git operation on the workspace PVC of an owned
+// instance in an ephemeral Job and returns its output. The instance keeps
+// running; the Job is pinned to the node of the instance pod so it can
+// mount a ReadWriteOnce workspace volume alongside it.
+func (s *Server) runWorkspaceGit(ctx context.Context, request mcpgolang.CallToolRequest, op resources.WorkspaceGitOperation) (*mcpgolang.CallToolResult, error) {
+	instance, errResult := s.getOwnedInstance(ctx, request)
+	if errResult != nil {
+		return errResult, nil
+	}
+	if !resources.NeedsGitClone(instance) {
+		if resources.HasWorkspaceRepos(instance) {
+			return mcpError("instance '" + instance.Name + "' clones spec.workspace.repos, which the workspace tools do not support"), nil
+		}
+		return mcpError("instance '" + instance.Name + "' has no workspace git repository"), nil
+	}
+
+	namespace := s.instanceNamespace(instance)
+	nodeName, err := s.instanceNodeName(ctx, instance, namespace)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+
+	clean, _ := request.GetArguments()["clean"].(bool)
+	job, err := resources.BuildWorkspaceGitJob(instance, namespace, s.gitImage, op, nodeName, clean)
+	if err != nil {
+		return mcpError(err.Error()), nil
+	}
+	if err := s.client.Create(ctx, job); err != nil {
+		return mcpError("failed to create workspace git job: " + err.Error()), nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, workspaceGitWait)
+	defer cancel()
+	succeeded, err := s.waitForJob(waitCtx, job)
+	if err != nil {
+		// Leave the Job running; its TTL removes it once it finishes.
+		return mcpError(fmt.Sprintf("workspace %s did not finish: %v; job %s/%s keeps running", op, err, namespace, job.Name)), nil
+	}
+
+	output, truncated, err := s.workspaceGitOutput(ctx, job)
+	if err != nil {
+		output = "output unavailable: " + err.Error()
+	}
+	if err := s.client.Delete(ctx, job, client.PropagationPolicy("Background")); client.IgnoreNotFound(err) != nil {
+		output += "\nfailed to delete workspace git job: " + err.Error()
+	}
+
+	if !succeeded {
+		return mcpError(fmt.Sprintf("workspace %s failed:\n%s", op, strings.TrimSpace(output))), nil
+	}
+	if op != resources.WorkspaceGitStatus {
+		if err := s.recordWorkspaceSync(ctx, instance, output); err != nil {
+			output += "\nfailed to record the synced commit in the instance status: " + err.Error()
+		}
+	}
+	return mcpSuccess(workspaceGitResult{
+		Name:      instance.Name,
+		Operation: string(op),
+		Status:    statusCompleted,
+		Output:    output,
+		Truncated: truncated,
+	}), nil
+}
+
+// recordWorkspaceSync records the commit a pull or reset moved the checkout
+// to in status.workspace. The scripts print it as the last line of their
+// output.
+func (s *Server) recordWorkspaceSync(ctx context.Context, instance *klausv1alpha1.KlausInstance, output string) error {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) == 0 || !commitSHA.MatchString(fields[0]) {
+		return nil
+	}
+
+	base := instance.DeepCopy()
+	if instance.Status.Workspace == nil {
+		instance.Status.Workspace = &klausv1alpha1.WorkspaceStatus{}
+	}
+	instance.Status.Workspace.ResolvedSHA = fields[0]
+	instance.Status.Workspace.HeadSHA = fields[0]
+	instance.Status.Workspace.LastSyncTime = &metav1.Time{Time: time.Now()}
+	return client.IgnoreNotFound(s.client.Status().Patch(ctx, instance, client.MergeFrom(base)))
+}
+
+// instanceNodeName returns the node of the instance's running pod, or an
+// empty string when no pod is running, for example while stopped.
+func (s *Server) instanceNodeName(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (string, error) {
+	var podList corev1.PodList
+	sel := labels.SelectorFromSet(resources.SelectorLabels(instance))
+	if err := s.client.List(ctx, &podList, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: sel}); err != nil {
+		return "", fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.Spec.NodeName != "" {
+			return pod.Spec.NodeName, nil
+		}
+	}
+	return "", nil
+}
+
+// waitForJob polls a Job until it succeeds or fails, reporting which.
+func (s *Server) waitForJob(ctx context.Context, job *batchv1.Job) (bool, error) {
+	poll := initialWorkspaceGitPoll
+	nn := types.NamespacedName{Name: job.Name, Namespace: job.Namespace}
+
+	for {
+		// The cache may not hold the Job created just before yet.
+		var current batchv1.Job
+		if err := s.client.Get(ctx, nn, &current); client.IgnoreNotFound(err) != nil {
+			return false, fmt.Errorf("fetching job: %w", err)
+		}
+		if current.Status.Succeeded > 0 {
+			return true, nil
+		}
+		if current.Status.Failed > 0 {
+			return false, nil
+		}
+
+		timer := time.NewTimer(poll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, fmt.Errorf("timed out after %s", workspaceGitWait)
+		case <-timer.C:
+		}
+
+		if poll < maxWorkspaceGitPoll {
+			poll = min(poll*2, maxWorkspaceGitPoll)
+		}
+	}
+}
+
+// workspaceGitOutput returns the git container log of a finished Job.
+func (s *Server) workspaceGitOutput(ctx context.Context, job *batchv1.Job) (string, bool, error) {
+	var podList corev1.PodList
+	if err := s.client.List(ctx, &podList, client.InNamespace(job.Namespace), client.MatchingLabels{jobNameLabel: job.Name}); err != nil {
+		return "", false, fmt.Errorf("failed to list job pods: %w", err)
+	}
+	if len(podList.Items) == 0 {
+		return "", false, fmt.Errorf("no pods found for job %s", job.Name)
+	}
+	return s.readPodLogs(ctx, job.Namespace, podList.Items[0].Name, &corev1.PodLogOptions{Container: resources.WorkspaceGitContainerName})
+}
@@ -0,0 +1,233 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	mcpgolang "github.com/mark3labs/mcp-go/mcp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const workspaceTestNamespace = "klaus-user-user-example-com"
+
+// simulateJobController waits for a workspace git Job, creates its pod and
+// marks it succeeded or failed. Stops when ctx is cancelled.
+func simulateJobController(ctx context.Context, c client.Client, succeed bool, created chan<- *batchv1.Job) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			var jobs batchv1.JobList
+			if err := c.List(ctx, &jobs, client.InNamespace(workspaceTestNamespace)); err != nil || len(jobs.Items) == 0 {
+				continue
+			}
+			job := &jobs.Items[0]
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:      job.Name + "-pod",
+				Namespace: job.Namespace,
+				Labels:    map[string]string{jobNameLabel: job.Name},
+			}}
+			_ = c.Create(ctx, pod)
+			if succeed {
+				job.Status.Succeeded = 1
+			} else {
+				job.Status.Failed = 1
+			}
+			_ = c.Status().Update(ctx, job)
+			created <- job.DeepCopy()
+			return
+		}
+	}
+}
+
+func workspaceTestServer(t *testing.T, reader PodLogReader, instance *klausv1alpha1.KlausInstance, objs ...client.Object) *Server {
+	t.Helper()
+	scheme := testScheme(t)
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add batchv1 scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(append(objs, instance)...).
+		WithStatusSubresource(&batchv1.Job{}, &klausv1alpha1.KlausInstance{}).
+		Build()
+	return &Server{client: c, operatorNamespace: "klaus-system", podLogReader: reader}
+}
+
+func workspaceTestInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:     "user@example.com",
+			Workspace: &klausv1alpha1.WorkspaceConfig{GitRepo: "https://github.com/example/repo.git", GitRef: "main"},
+		},
+	}
+}
+
+func TestHandleWorkspacePull_Success(t *testing.T) {
+	instancePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-agent-abc",
+			Namespace: workspaceTestNamespace,
+			Labels:    map[string]string{"app.kubernetes.io/name": "klaus", "app.kubernetes.io/instance": "my-agent"},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	reader := &fakePodLogReader{logs: "Updating 1234..5678\nFast-forward\n5678567856785678567856785678567856785678 Fix tests\n"}
+	s := workspaceTestServer(t, reader, workspaceTestInstance(), instancePod)
+
+	ctx, cancel := context.WithTimeout(authCtx("user@example.com"), 30*time.Second)
+	defer cancel()
+	created := make(chan *batchv1.Job, 1)
+	go simulateJobController(ctx, s.client, true, created)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleWorkspacePull(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if result.IsError {
+		t.Fatalf("unexpected MCP error: %s", text)
+	}
+
+	var data workspaceGitResult
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if data.Operation != "pull" || data.Status != statusCompleted || !strings.Contains(data.Output, "Fast-forward") {
+		t.Errorf("result = %+v", data)
+	}
+	if reader.lastOpts.Container != "git" {
+		t.Errorf("read logs of container %q, want git", reader.lastOpts.Container)
+	}
+
+	job := <-created
+	if got := affinityNode(job); got != "node-1" {
+		t.Errorf("node affinity = %q, want the instance pod's node", got)
+	}
+	var jobs batchv1.JobList
+	if err := s.client.List(ctx, &jobs, client.InNamespace(workspaceTestNamespace)); err != nil || len(jobs.Items) != 0 {
+		t.Errorf("expected the finished Job to be deleted, got %d (%v)", len(jobs.Items), err)
+	}
+
+	var instance klausv1alpha1.KlausInstance
+	if err := s.client.Get(ctx, types.NamespacedName{Name: "my-agent", Namespace: "klaus-system"}, &instance); err != nil {
+		t.Fatalf("failed to get instance: %v", err)
+	}
+	if ws := instance.Status.Workspace; ws == nil || ws.ResolvedSHA != "5678567856785678567856785678567856785678" || ws.LastSyncTime == nil {
+		t.Errorf("status.workspace = %+v, want the pulled commit recorded", ws)
+	}
+}
+
+func TestHandleWorkspaceReset_Failed(t *testing.T) {
+	reader := &fakePodLogReader{logs: "fatal: could not read from remote repository\n"}
+	s := workspaceTestServer(t, reader, workspaceTestInstance())
+
+	ctx, cancel := context.WithTimeout(authCtx("user@example.com"), 30*time.Second)
+	defer cancel()
+	created := make(chan *batchv1.Job, 1)
+	go simulateJobController(ctx, s.client, false, created)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent", "clean": true}
+	result, err := s.handleWorkspaceReset(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcpgolang.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "workspace reset failed") || !strings.Contains(text, "could not read") {
+		t.Errorf("result = %q, want the git error", text)
+	}
+
+	// A stopped instance has no pod; the Job is left to the scheduler.
+	if job := <-created; job.Spec.Template.Spec.Affinity != nil {
+		t.Errorf("affinity = %+v, want none without a running pod", job.Spec.Template.Spec.Affinity)
+	}
+}
+
+// affinityNode returns the node the Job pod is pinned to by node affinity.
+func affinityNode(job *batchv1.Job) string {
+	affinity := job.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, field := range term.MatchFields {
+			if field.Key == "metadata.name" && len(field.Values) == 1 {
+				return field.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+func TestHandleWorkspaceStatus_NoGitRepo(t *testing.T) {
+	instance := workspaceTestInstance()
+	instance.Spec.Workspace.GitRepo = ""
+	s := workspaceTestServer(t, &fakePodLogReader{}, instance)
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleWorkspaceStatus(authCtx("user@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcpgolang.TextContent).Text; !result.IsError || !strings.Contains(text, "no workspace git repository") {
+		t.Errorf("result = %q", text)
+	}
+}
+
+func TestHandleWorkspaceStatus_AccessDenied(t *testing.T) {
+	s := workspaceTestServer(t, &fakePodLogReader{}, workspaceTestInstance())
+
+	req := mcpgolang.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"name": "my-agent"}
+	result, err := s.handleWorkspaceStatus(authCtx("other@example.com"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcpgolang.TextContent).Text; !result.IsError || !strings.Contains(text, "access denied") {
+		t.Errorf("result = %q", text)
+	}
+}
+
+func TestWaitForJob_NotCachedYet(t *testing.T) {
+	s := workspaceTestServer(t, &fakePodLogReader{}, workspaceTestInstance())
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "my-agent-git-pull-x", Namespace: workspaceTestNamespace}}
+
+	// The Job only shows up after the first poll, as with a lagging cache.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		created := job.DeepCopy()
+		if err := s.client.Create(ctx, created); err != nil {
+			t.Errorf("creating job: %v", err)
+			return
+		}
+		created.Status.Succeeded = 1
+		if err := s.client.Status().Update(ctx, created); err != nil {
+			t.Errorf("updating job status: %v", err)
+		}
+	}()
+
+	succeeded, err := s.waitForJob(ctx, job)
+	if err != nil || !succeeded {
+		t.Errorf("waitForJob() = %v, %v; want the Job found once cached and succeeded", succeeded, err)
+	}
+}
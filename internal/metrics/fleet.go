@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// fleetCollectTimeout bounds the cache reads of one scrape.
+const fleetCollectTimeout = 10 * time.Second
+
+var (
+	instancesDesc = prometheus.NewDesc("klaus_operator_instances",
+		"KlausInstances by state and personality.",
+		[]string{"state", "personality"}, nil)
+
+	ownerInstancesDesc = prometheus.NewDesc("klaus_operator_instances",
+		"KlausInstances by state, owner and personality.",
+		[]string{"state", "owner", "personality"}, nil)
+
+	mcpServerInstancesDesc = prometheus.NewDesc("klaus_operator_mcpserver_instances",
+		"KlausInstances referencing each KlausMCPServer.",
+		[]string{"namespace", "mcpserver"}, nil)
+)
+
+// FleetCollector reports gauges of the instance fleet. It reads the
+// instances on every scrape, so the gauges are never stale and need no
+// leader election.
+type FleetCollector struct {
+	Reader client.Reader
+
+	// OwnerLabel adds the owner label to klaus_operator_instances. Every
+	// owner is a series per state and personality, so it is opt-in.
+	OwnerLabel bool
+}
+
+// Describe implements prometheus.Collector.
+func (c *FleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.instancesDesc()
+	ch <- mcpServerInstancesDesc
+}
+
+func (c *FleetCollector) instancesDesc() *prometheus.Desc {
+	if c.OwnerLabel {
+		return ownerInstancesDesc
+	}
+	return instancesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *FleetCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), fleetCollectTimeout)
+	defer cancel()
+
+	var instances klausv1alpha1.KlausInstanceList
+	if err := c.Reader.List(ctx, &instances); err != nil {
+		ch <- prometheus.NewInvalidMetric(c.instancesDesc(), err)
+		return
+	}
+
+	type instanceKey struct{ state, owner, personality string }
+	type mcpServerKey struct{ namespace, name string }
+	byKey := map[instanceKey]int{}
+	byMCPServer := map[mcpServerKey]int{}
+	for _, instance := range instances.Items {
+		key := instanceKey{state: instanceState(&instance), personality: personalityLabel(&instance)}
+		if c.OwnerLabel {
+			key.owner = instance.Spec.Owner
+		}
+		byKey[key]++
+		for _, ref := range instance.Spec.MCPServers {
+			byMCPServer[mcpServerKey{instance.Namespace, ref.Name}]++
+		}
+	}
+
+	// Report KlausMCPServers without instances as zero. They are optional
+	// for the instance counts, so a failed list only drops them.
+	var servers klausv1alpha1.KlausMCPServerList
+	if err := c.Reader.List(ctx, &servers); err == nil {
+		for _, server := range servers.Items {
+			key := mcpServerKey{server.Namespace, server.Name}
+			if _, ok := byMCPServer[key]; !ok {
+				byMCPServer[key] = 0
+			}
+		}
+	}
+
+	for key, n := range byKey {
+		if c.OwnerLabel {
+			ch <- prometheus.MustNewConstMetric(ownerInstancesDesc, prometheus.GaugeValue, float64(n), key.state, key.owner, key.personality)
+		} else {
+			ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(n), key.state, key.personality)
+		}
+	}
+	for key, n := range byMCPServer {
+		ch <- prometheus.MustNewConstMetric(mcpServerInstancesDesc, prometheus.GaugeValue, float64(n), key.namespace, key.name)
+	}
+}
+
+// instanceState returns the state label of an instance. Instances the
+// controller has not reconciled yet are Pending.
+func instanceState(instance *klausv1alpha1.KlausInstance) string {
+	if instance.Status.State == "" {
+		return string(klausv1alpha1.InstanceStatePending)
+	}
+	return string(instance.Status.State)
+}
+
+// personalityLabel returns the personality label of an instance. Inline
+// personalities are reported as "inline".
+func personalityLabel(instance *klausv1alpha1.KlausInstance) string {
+	if instance.Spec.InlinePersonality != nil {
+		return resources.InlinePersonalityStatus
+	}
+	return personalityName(instance.Spec.Personality)
+}
+
+// personalityName returns the short name of a personality reference, e.g.
+// go-dev for gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.0.0, so tag
+// changes do not create new series.
+func personalityName(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	return klausoci.ShortName(klausoci.RepositoryFromRef(ref))
+}
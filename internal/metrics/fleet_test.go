@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := klausv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding klausv1alpha1 to scheme: %v", err)
+	}
+	return scheme
+}
+
+func fleetInstance(name, owner, personality string, state klausv1alpha1.InstanceState, mcpServers ...string) *klausv1alpha1.KlausInstance {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: owner, Personality: personality},
+		Status:     klausv1alpha1.KlausInstanceStatus{State: state},
+	}
+	for _, name := range mcpServers {
+		instance.Spec.MCPServers = append(instance.Spec.MCPServers, klausv1alpha1.MCPServerReference{Name: name})
+	}
+	return instance
+}
+
+func TestFleetCollector(t *testing.T) {
+	inline := fleetInstance("d", "carol@example.com", "", klausv1alpha1.InstanceStateStopped)
+	inline.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{Soul: "You are helpful."}
+	reader := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(
+		fleetInstance("a", "alice@example.com", "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.0.0", klausv1alpha1.InstanceStateRunning, "github"),
+		fleetInstance("b", "alice@example.com", "gsoci.azurecr.io/giantswarm/personalities/go-dev@sha256:abc", klausv1alpha1.InstanceStateRunning, "github"),
+		fleetInstance("c", "bob@example.com", "", "", "github", "jira"),
+		fleetInstance("e", "carol@example.com", "gsoci.azurecr.io/giantswarm/personalities/go-dev:v1.1.0", klausv1alpha1.InstanceStateRunning),
+		inline,
+		&klausv1alpha1.KlausMCPServer{ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "klaus-system"}},
+	).Build()
+
+	expected := `
+# HELP klaus_operator_instances KlausInstances by state and personality.
+# TYPE klaus_operator_instances gauge
+klaus_operator_instances{personality="go-dev",state="Running"} 3
+klaus_operator_instances{personality="",state="Pending"} 1
+klaus_operator_instances{personality="inline",state="Stopped"} 1
+# HELP klaus_operator_mcpserver_instances KlausInstances referencing each KlausMCPServer.
+# TYPE klaus_operator_mcpserver_instances gauge
+klaus_operator_mcpserver_instances{mcpserver="github",namespace="klaus-system"} 3
+klaus_operator_mcpserver_instances{mcpserver="jira",namespace="klaus-system"} 1
+klaus_operator_mcpserver_instances{mcpserver="slack",namespace="klaus-system"} 0
+`
+	if err := testutil.CollectAndCompare(&FleetCollector{Reader: reader}, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	expected = `
+# HELP klaus_operator_instances KlausInstances by state, owner and personality.
+# TYPE klaus_operator_instances gauge
+klaus_operator_instances{owner="alice@example.com",personality="go-dev",state="Running"} 2
+klaus_operator_instances{owner="bob@example.com",personality="",state="Pending"} 1
+klaus_operator_instances{owner="carol@example.com",personality="go-dev",state="Running"} 1
+klaus_operator_instances{owner="carol@example.com",personality="inline",state="Stopped"} 1
+`
+	if err := testutil.CollectAndCompare(&FleetCollector{Reader: reader, OwnerLabel: true}, strings.NewReader(expected), "klaus_operator_instances"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFleetCollector_ListError(t *testing.T) {
+	// Without the klaus types in the scheme the list fails.
+	reader := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	if _, err := testutil.CollectAndLint(&FleetCollector{Reader: reader}); err == nil {
+		t.Error("expected the collection to fail")
+	}
+}
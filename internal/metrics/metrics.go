@@ -0,0 +1,183 @@
+// Package metrics defines the operator's Prometheus metrics. They are
+// registered with the controller-runtime registry, so they are served on the
+// manager's metrics endpoint next to the controller-runtime defaults.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Controller label values of ReconcileErrors.
+const (
+	ControllerKlausInstance = "klausinstance"
+	ControllerKlausJob      = "klausjob"
+)
+
+// Secret label values of SecretCopyFailures.
+const (
+	SecretAnthropicAPIKey  = "anthropic-api-key"
+	SecretProvider         = "provider"
+	SecretGit              = "git"
+	SecretOTelCollector    = "otel-collector"
+	SecretCABundle         = "ca-bundle"
+	SecretProxyCredentials = "proxy-credentials"
+)
+
+// Kind label values of OCIResolveDuration, ArtifactVerifications and
+// OCICacheRequests.
+const (
+	OCIKindPersonality = "personality"
+	OCIKindToolchain   = "toolchain"
+	OCIKindPlugin      = "plugin"
+	OCIKindMCPServer   = "mcp-server"
+	OCIKindDigest      = "digest"
+)
+
+var (
+	// ReconcileErrors counts failed reconciles by controller and the
+	// status reason they were reported with, e.g. DeploymentError.
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "klaus_operator_reconcile_errors_total",
+		Help: "Failed reconciles by controller and reason.",
+	}, []string{"controller", "reason"})
+
+	// SecretCopyFailures counts failed copies of credential Secrets into
+	// user namespaces.
+	SecretCopyFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "klaus_operator_secret_copy_failures_total",
+		Help: "Failed copies of credential Secrets into user namespaces by secret type.",
+	}, []string{"secret"})
+
+	// OCIResolveDuration observes the registry round trips resolving
+	// personality, toolchain and plugin references to pinned versions.
+	OCIResolveDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "klaus_operator_oci_resolve_duration_seconds",
+		Help:    "Duration of OCI reference resolutions by artifact kind and result.",
+		Buckets: prometheus.ExponentialBuckets(0.025, 2, 10),
+	}, []string{"kind", "result"})
+
+	// ArtifactVerifications counts the cosign signature verifications of
+	// personality, toolchain, plugin and MCP server artifacts.
+	ArtifactVerifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "klaus_operator_artifact_verifications_total",
+		Help: "Signature verifications of OCI artifacts by artifact kind and result.",
+	}, []string{"kind", "result"})
+
+	// OCICacheRequests counts the lookups of the in-memory OCI resolution
+	// cache by artifact kind and result, hit or miss.
+	OCICacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "klaus_operator_oci_cache_requests_total",
+		Help: "Lookups of the OCI resolution cache by artifact kind and result.",
+	}, []string{"kind", "result"})
+
+	// OrphanedResourcesFound counts the child resources found by the
+	// orphan sweeper whose KlausInstance or KlausJob no longer exists.
+	OrphanedResourcesFound = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "klaus_operator_orphaned_resources_found_total",
+		Help: "Child resources of deleted KlausInstances and KlausJobs found by the orphan sweeper by kind.",
+	}, []string{"kind"})
+
+	// OrphanedResourcesDeleted counts the orphaned child resources the
+	// sweeper deleted.
+	OrphanedResourcesDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "klaus_operator_orphaned_resources_deleted_total",
+		Help: "Orphaned child resources deleted by the orphan sweeper by kind.",
+	}, []string{"kind"})
+
+	// AuditRecordsDropped counts the audit records dropped because the
+	// audit buffer stayed full, by record source.
+	AuditRecordsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "klaus_operator_audit_records_dropped_total",
+		Help: "Audit records dropped because the audit buffer was full by source.",
+	}, []string{"source"})
+
+	// OCICacheEntries is the number of entries in the in-memory OCI
+	// resolution cache.
+	OCICacheEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "klaus_operator_oci_cache_entries",
+		Help: "Entries in the OCI resolution cache.",
+	})
+)
+
+// Register registers the operator metrics and the fleet collector reading
+// instances through reader, labeling the instance gauge with owners when
+// ownerLabel is set.
+func Register(registry prometheus.Registerer, reader client.Reader, ownerLabel bool) error {
+	for _, c := range []prometheus.Collector{
+		ReconcileErrors,
+		SecretCopyFailures,
+		OCIResolveDuration,
+		ArtifactVerifications,
+		OCICacheRequests,
+		OCICacheEntries,
+		OrphanedResourcesFound,
+		OrphanedResourcesDeleted,
+		AuditRecordsDropped,
+		&FleetCollector{Reader: reader, OwnerLabel: ownerLabel},
+	} {
+		if err := registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveOCIResolve records the duration of an OCI reference resolution
+// that started at start.
+func ObserveOCIResolve(kind string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	OCIResolveDuration.WithLabelValues(kind, result).Observe(time.Since(start).Seconds())
+}
+
+// OCIResolver resolves artifact references to pinned versions, like the
+// klaus-oci client.
+type OCIResolver interface {
+	ResolvePersonalityRef(ctx context.Context, ref string) (string, error)
+	ResolveToolchainRef(ctx context.Context, ref string) (string, error)
+	ResolvePluginRef(ctx context.Context, ref string) (string, error)
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// InstrumentOCIResolver wraps resolver to observe OCIResolveDuration.
+func InstrumentOCIResolver(resolver OCIResolver) OCIResolver {
+	return &instrumentedResolver{resolver: resolver}
+}
+
+type instrumentedResolver struct {
+	resolver OCIResolver
+}
+
+func (r *instrumentedResolver) ResolvePersonalityRef(ctx context.Context, ref string) (string, error) {
+	start := time.Now()
+	resolved, err := r.resolver.ResolvePersonalityRef(ctx, ref)
+	ObserveOCIResolve(OCIKindPersonality, start, err)
+	return resolved, err
+}
+
+func (r *instrumentedResolver) ResolveToolchainRef(ctx context.Context, ref string) (string, error) {
+	start := time.Now()
+	resolved, err := r.resolver.ResolveToolchainRef(ctx, ref)
+	ObserveOCIResolve(OCIKindToolchain, start, err)
+	return resolved, err
+}
+
+func (r *instrumentedResolver) ResolvePluginRef(ctx context.Context, ref string) (string, error) {
+	start := time.Now()
+	resolved, err := r.resolver.ResolvePluginRef(ctx, ref)
+	ObserveOCIResolve(OCIKindPlugin, start, err)
+	return resolved, err
+}
+
+func (r *instrumentedResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	start := time.Now()
+	digest, err := r.resolver.Resolve(ctx, ref)
+	ObserveOCIResolve(OCIKindDigest, start, err)
+	return digest, err
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeResolver returns ref unchanged, or err when set.
+type fakeResolver struct {
+	err error
+}
+
+func (f *fakeResolver) ResolvePersonalityRef(_ context.Context, ref string) (string, error) {
+	return ref, f.err
+}
+
+func (f *fakeResolver) ResolveToolchainRef(_ context.Context, ref string) (string, error) {
+	return ref, f.err
+}
+
+func (f *fakeResolver) ResolvePluginRef(_ context.Context, ref string) (string, error) {
+	return ref + "@sha256:abc", f.err
+}
+
+func (f *fakeResolver) Resolve(_ context.Context, _ string) (string, error) {
+	return "sha256:abc", f.err
+}
+
+func TestInstrumentOCIResolver(t *testing.T) {
+	OCIResolveDuration.Reset()
+	resolver := InstrumentOCIResolver(&fakeResolver{})
+
+	resolved, err := resolver.ResolvePluginRef(context.Background(), "example.com/plugin:v1")
+	if err != nil || resolved != "example.com/plugin:v1@sha256:abc" {
+		t.Fatalf("ResolvePluginRef() = %q, %v; want the wrapped result", resolved, err)
+	}
+	if _, err := InstrumentOCIResolver(&fakeResolver{err: errors.New("unauthorized")}).
+		ResolvePersonalityRef(context.Background(), "example.com/personality:v1"); err == nil {
+		t.Fatal("expected the wrapped error")
+	}
+
+	if n := testutil.CollectAndCount(OCIResolveDuration); n != 2 {
+		t.Errorf("got %d histogram series, want 2", n)
+	}
+	if n := testutil.CollectAndCount(OCIResolveDuration.WithLabelValues(OCIKindPlugin, "success").(prometheus.Histogram)); n != 1 {
+		t.Errorf("got %d plugin success series, want 1", n)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reader := fake.NewClientBuilder().WithScheme(testScheme(t)).Build()
+	if err := Register(registry, reader, false); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := Register(registry, reader, true); err == nil {
+		t.Error("expected an error registering twice")
+	}
+}
@@ -0,0 +1,142 @@
+// Package oci caches the OCI reference resolutions of the controllers in
+// memory. Every reconcile resolves the personality, toolchain and plugin
+// references of an instance; the cache bounds the registry round trips this
+// takes on busy operators while still picking up new tags within its TTL.
+// It also reads the souls of personality artifacts for the instance memory,
+// and provides OfflineClient for operators without registry access.
+package oci
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+)
+
+const (
+	// DefaultCacheSize is the default maximum number of cached resolutions.
+	DefaultCacheSize = 1024
+	// DefaultCacheTTL is how long a resolution is served from the cache by
+	// default.
+	DefaultCacheTTL = time.Minute
+)
+
+// CachingResolver serves the resolutions of a resolver from a least
+// recently used cache of at most Size entries, each valid for TTL. Failed
+// resolutions are not cached.
+type CachingResolver struct {
+	resolver metrics.OCIResolver
+	size     int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[cacheKey]*list.Element
+}
+
+type cacheKey struct {
+	kind string
+	ref  string
+}
+
+type cacheEntry struct {
+	key      cacheKey
+	resolved string
+	expires  time.Time
+}
+
+// NewCachingResolver returns resolver wrapped into a cache of size entries
+// valid for ttl. A size or ttl of zero disables caching and returns
+// resolver as is.
+func NewCachingResolver(resolver metrics.OCIResolver, size int, ttl time.Duration) metrics.OCIResolver {
+	if size <= 0 || ttl <= 0 {
+		return resolver
+	}
+	return &CachingResolver{
+		resolver: resolver,
+		size:     size,
+		ttl:      ttl,
+		now:      time.Now,
+		lru:      list.New(),
+		entries:  map[cacheKey]*list.Element{},
+	}
+}
+
+// ResolvePersonalityRef resolves a personality reference.
+func (c *CachingResolver) ResolvePersonalityRef(ctx context.Context, ref string) (string, error) {
+	return c.resolve(ctx, metrics.OCIKindPersonality, ref, c.resolver.ResolvePersonalityRef)
+}
+
+// ResolveToolchainRef resolves a toolchain image reference.
+func (c *CachingResolver) ResolveToolchainRef(ctx context.Context, ref string) (string, error) {
+	return c.resolve(ctx, metrics.OCIKindToolchain, ref, c.resolver.ResolveToolchainRef)
+}
+
+// ResolvePluginRef resolves a plugin reference.
+func (c *CachingResolver) ResolvePluginRef(ctx context.Context, ref string) (string, error) {
+	return c.resolve(ctx, metrics.OCIKindPlugin, ref, c.resolver.ResolvePluginRef)
+}
+
+// Resolve resolves a tag or digest reference to its manifest digest.
+func (c *CachingResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	return c.resolve(ctx, metrics.OCIKindDigest, ref, c.resolver.Resolve)
+}
+
+func (c *CachingResolver) resolve(ctx context.Context, kind, ref string, resolve func(context.Context, string) (string, error)) (string, error) {
+	key := cacheKey{kind: kind, ref: ref}
+	if resolved, ok := c.get(key); ok {
+		metrics.OCICacheRequests.WithLabelValues(kind, "hit").Inc()
+		return resolved, nil
+	}
+	metrics.OCICacheRequests.WithLabelValues(kind, "miss").Inc()
+
+	resolved, err := resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	c.add(key, resolved)
+	return resolved, nil
+}
+
+// get returns the unexpired resolution of key, marking it recently used.
+// An expired entry is removed.
+func (c *CachingResolver) get(key cacheKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return "", false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.resolved, true
+}
+
+// add caches the resolution of key, evicting the least recently used
+// entries beyond the cache size.
+func (c *CachingResolver) add(key cacheKey, resolved string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, resolved: resolved, expires: c.now().Add(c.ttl)})
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+	metrics.OCICacheEntries.Set(float64(c.lru.Len()))
+}
+
+// remove drops elem from the cache. c.mu must be held.
+func (c *CachingResolver) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+	metrics.OCICacheEntries.Set(float64(c.lru.Len()))
+}
@@ -0,0 +1,132 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+)
+
+// countingResolver resolves ref to ref with a suffix, or fails with err,
+// and counts the calls per reference.
+type countingResolver struct {
+	calls map[string]int
+	err   error
+}
+
+func (r *countingResolver) resolve(_ context.Context, ref string) (string, error) {
+	if r.calls == nil {
+		r.calls = map[string]int{}
+	}
+	r.calls[ref]++
+	if r.err != nil {
+		return "", r.err
+	}
+	return ref + ":resolved", nil
+}
+
+func (r *countingResolver) ResolvePersonalityRef(ctx context.Context, ref string) (string, error) {
+	return r.resolve(ctx, ref)
+}
+
+func (r *countingResolver) ResolveToolchainRef(ctx context.Context, ref string) (string, error) {
+	return r.resolve(ctx, ref)
+}
+
+func (r *countingResolver) ResolvePluginRef(ctx context.Context, ref string) (string, error) {
+	return r.resolve(ctx, ref)
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	return r.resolve(ctx, ref)
+}
+
+func newTestCache(t *testing.T, resolver metrics.OCIResolver, size int, now *time.Time) *CachingResolver {
+	t.Helper()
+	c, ok := NewCachingResolver(resolver, size, time.Minute).(*CachingResolver)
+	if !ok {
+		t.Fatal("expected a caching resolver")
+	}
+	c.now = func() time.Time { return *now }
+	return c
+}
+
+func TestCachingResolver_HitsAndTTL(t *testing.T) {
+	metrics.OCICacheRequests.Reset()
+	ctx := context.Background()
+	now := time.Now()
+	resolver := &countingResolver{}
+	c := newTestCache(t, resolver, 10, &now)
+
+	for range 3 {
+		if got, err := c.ResolvePersonalityRef(ctx, "go-dev"); err != nil || got != "go-dev:resolved" {
+			t.Fatalf("ResolvePersonalityRef() = %q, %v", got, err)
+		}
+	}
+	if resolver.calls["go-dev"] != 1 {
+		t.Errorf("calls = %d, want one registry resolution", resolver.calls["go-dev"])
+	}
+	if hits := testutil.ToFloat64(metrics.OCICacheRequests.WithLabelValues(metrics.OCIKindPersonality, "hit")); hits != 2 {
+		t.Errorf("hits = %v, want 2", hits)
+	}
+
+	// Kinds are cached separately.
+	if _, err := c.Resolve(ctx, "go-dev"); err != nil || resolver.calls["go-dev"] != 2 {
+		t.Errorf("calls = %d, err = %v; want a digest resolution of its own", resolver.calls["go-dev"], err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := c.ResolvePersonalityRef(ctx, "go-dev"); err != nil || resolver.calls["go-dev"] != 3 {
+		t.Errorf("calls = %d, err = %v; want the expired entry resolved again", resolver.calls["go-dev"], err)
+	}
+}
+
+func TestCachingResolver_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	resolver := &countingResolver{}
+	c := newTestCache(t, resolver, 2, &now)
+
+	for _, ref := range []string{"a", "b", "a", "c", "a", "b"} {
+		if _, err := c.ResolvePluginRef(ctx, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// b was evicted by c, a stayed as the most recently used.
+	if resolver.calls["a"] != 1 || resolver.calls["b"] != 2 || resolver.calls["c"] != 1 {
+		t.Errorf("calls = %v, want b resolved again after its eviction", resolver.calls)
+	}
+	if entries := testutil.ToFloat64(metrics.OCICacheEntries); entries != 2 {
+		t.Errorf("entries = %v, want the cache size", entries)
+	}
+}
+
+func TestCachingResolver_ErrorsNotCached(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	resolver := &countingResolver{err: errors.New("unauthorized")}
+	c := newTestCache(t, resolver, 10, &now)
+
+	for range 2 {
+		if _, err := c.ResolveToolchainRef(ctx, "golang"); err == nil {
+			t.Fatal("expected the resolver error")
+		}
+	}
+	if resolver.calls["golang"] != 2 {
+		t.Errorf("calls = %d, want failed resolutions retried", resolver.calls["golang"])
+	}
+}
+
+func TestNewCachingResolver_Disabled(t *testing.T) {
+	resolver := &countingResolver{}
+	if got := NewCachingResolver(resolver, 0, time.Minute); got != resolver {
+		t.Errorf("NewCachingResolver() = %T, want the resolver unwrapped without a size", got)
+	}
+	if got := NewCachingResolver(resolver, 10, 0); got != resolver {
+		t.Errorf("NewCachingResolver() = %T, want the resolver unwrapped without a TTL", got)
+	}
+}
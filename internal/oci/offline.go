@@ -0,0 +1,90 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+// ErrOffline is returned for lookups that need an OCI registry while the
+// operator runs offline.
+var ErrOffline = errors.New("the operator runs offline and cannot reach the OCI registry")
+
+// ErrSoulUnavailable is returned for souls with FailOpen: the personality is
+// used without its soul, which callers surface on the instance.
+var ErrSoulUnavailable = errors.New("the soul of the personality cannot be pulled offline")
+
+// OfflineClient stands in for the klaus-oci client of operators without
+// registry access and never contacts a registry. References that need no
+// lookup, those with a digest or a version tag, resolve as klaus-oci would
+// resolve them, with short names expanded to the default registries.
+// Lookups that need the registry, the latest version of untagged and
+// :latest references, the digest of a tag and souls, fail with ErrOffline.
+// With FailOpen the references are used as written instead: tags are left
+// unpinned and souls fail with ErrSoulUnavailable.
+type OfflineClient struct {
+	FailOpen bool
+}
+
+// ResolvePersonalityRef resolves a personality reference.
+func (c *OfflineClient) ResolvePersonalityRef(_ context.Context, ref string) (string, error) {
+	return c.resolveRef(ref, klausoci.DefaultPersonalityRegistry)
+}
+
+// ResolveToolchainRef resolves a toolchain image reference.
+func (c *OfflineClient) ResolveToolchainRef(_ context.Context, ref string) (string, error) {
+	return c.resolveRef(ref, klausoci.DefaultToolchainRegistry)
+}
+
+// ResolvePluginRef resolves a plugin reference.
+func (c *OfflineClient) ResolvePluginRef(_ context.Context, ref string) (string, error) {
+	return c.resolveRef(ref, klausoci.DefaultPluginRegistry)
+}
+
+// Resolve returns the digest of references pinned to one. The digest of a
+// tag needs the registry: it is "" with FailOpen, leaving the reference
+// unpinned.
+func (c *OfflineClient) Resolve(_ context.Context, ref string) (string, error) {
+	if _, digest, ok := strings.Cut(ref, "@"); ok {
+		return digest, nil
+	}
+	if c.FailOpen {
+		return "", nil
+	}
+	return "", ErrOffline
+}
+
+// PullPersonality pulls nothing: it fails with ErrSoulUnavailable with
+// FailOpen and ErrOffline otherwise.
+func (c *OfflineClient) PullPersonality(_ context.Context, _, _ string) (*klausoci.PulledPersonality, error) {
+	if c.FailOpen {
+		return nil, ErrSoulUnavailable
+	}
+	return nil, ErrOffline
+}
+
+// resolveRef expands short names to registryBase and keeps references with
+// a digest or a version tag. Untagged and :latest references need the tags
+// of the registry: they fail, or with FailOpen are kept as written.
+func (c *OfflineClient) resolveRef(ref, registryBase string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("empty artifact reference")
+	}
+	if !strings.Contains(ref, "/") {
+		ref = registryBase + "/" + ref
+	}
+	if strings.Contains(ref, "@") {
+		return ref, nil
+	}
+	if _, tag := klausoci.SplitNameTag(ref); tag != "" && tag != "latest" {
+		return ref, nil
+	}
+	if c.FailOpen {
+		return ref, nil
+	}
+	return "", fmt.Errorf("resolving the latest version of %s: %w", ref, ErrOffline)
+}
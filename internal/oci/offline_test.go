@@ -0,0 +1,103 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOfflineClient_Resolve(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name     string
+		ref      string
+		want     string
+		wantOpen string
+		offline  bool
+	}{
+		{
+			name: "short name with version",
+			ref:  "sre:v1.0.0",
+			want: "gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v1.0.0",
+		},
+		{
+			name: "version tag",
+			ref:  "registry.example.com/personalities/sre:v1.0.0",
+			want: "registry.example.com/personalities/sre:v1.0.0",
+		},
+		{
+			name: "digest",
+			ref:  "registry.example.com/personalities/sre@" + digest,
+			want: "registry.example.com/personalities/sre@" + digest,
+		},
+		{
+			name:     "short name",
+			ref:      "sre",
+			wantOpen: "gsoci.azurecr.io/giantswarm/klaus-personalities/sre",
+			offline:  true,
+		},
+		{
+			name:     "latest tag",
+			ref:      "localhost:5000/personalities/sre:latest",
+			wantOpen: "localhost:5000/personalities/sre:latest",
+			offline:  true,
+		},
+		{
+			name:     "untagged with port",
+			ref:      "localhost:5000/personalities/sre",
+			wantOpen: "localhost:5000/personalities/sre",
+			offline:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			got, err := (&OfflineClient{}).ResolvePersonalityRef(ctx, tt.ref)
+			if tt.offline {
+				if !errors.Is(err, ErrOffline) {
+					t.Errorf("ResolvePersonalityRef(%q) = %q, %v, want ErrOffline", tt.ref, got, err)
+				}
+			} else if err != nil || got != tt.want {
+				t.Errorf("ResolvePersonalityRef(%q) = %q, %v, want %q", tt.ref, got, err, tt.want)
+			}
+
+			wantOpen := tt.want
+			if tt.offline {
+				wantOpen = tt.wantOpen
+			}
+			got, err = (&OfflineClient{FailOpen: true}).ResolvePersonalityRef(ctx, tt.ref)
+			if err != nil || got != wantOpen {
+				t.Errorf("fail open ResolvePersonalityRef(%q) = %q, %v, want %q", tt.ref, got, err, wantOpen)
+			}
+		})
+	}
+}
+
+func TestOfflineClient_Digest(t *testing.T) {
+	ctx := context.Background()
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	for _, c := range []*OfflineClient{{}, {FailOpen: true}} {
+		if got, err := c.Resolve(ctx, "registry.example.com/plugins/gs-base@"+digest); err != nil || got != digest {
+			t.Errorf("FailOpen=%v: Resolve of a digest reference = %q, %v, want %s", c.FailOpen, got, err, digest)
+		}
+	}
+
+	if _, err := (&OfflineClient{}).Resolve(ctx, "registry.example.com/plugins/gs-base:v1.0.0"); !errors.Is(err, ErrOffline) {
+		t.Errorf("Resolve of a tag = %v, want ErrOffline", err)
+	}
+	if got, err := (&OfflineClient{FailOpen: true}).Resolve(ctx, "registry.example.com/plugins/gs-base:v1.0.0"); err != nil || got != "" {
+		t.Errorf("fail open Resolve of a tag = %q, %v, want no digest", got, err)
+	}
+}
+
+func TestOfflineClient_Souls(t *testing.T) {
+	ctx := context.Background()
+	ref := "registry.example.com/personalities/sre:v1.0.0"
+
+	if _, err := NewSoulFetcher(&OfflineClient{}, DefaultSoulCacheSize).FetchSoul(ctx, ref); !errors.Is(err, ErrOffline) {
+		t.Errorf("FetchSoul = %v, want ErrOffline", err)
+	}
+	if _, err := NewSoulFetcher(&OfflineClient{FailOpen: true}, DefaultSoulCacheSize).FetchSoul(ctx, ref); !errors.Is(err, ErrSoulUnavailable) {
+		t.Errorf("fail open FetchSoul = %v, want ErrSoulUnavailable", err)
+	}
+}
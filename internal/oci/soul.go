@@ -0,0 +1,69 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+// DefaultSoulCacheSize is the default maximum number of cached souls.
+const DefaultSoulCacheSize = 256
+
+// PersonalityPuller pulls a personality artifact into a directory;
+// *klausoci.Client implements it.
+type PersonalityPuller interface {
+	PullPersonality(ctx context.Context, ref, dir string) (*klausoci.PulledPersonality, error)
+}
+
+// SoulFetcher reads the SOUL.md of personality artifacts. Souls of
+// references pinned to a digest cannot change and are kept in memory, up to
+// size of them; the cache is cleared when it is full.
+type SoulFetcher struct {
+	puller PersonalityPuller
+	size   int
+
+	mu    sync.Mutex
+	souls map[string]string
+}
+
+// NewSoulFetcher returns a SoulFetcher pulling personalities with puller
+// and caching up to size souls.
+func NewSoulFetcher(puller PersonalityPuller, size int) *SoulFetcher {
+	return &SoulFetcher{puller: puller, size: size, souls: map[string]string{}}
+}
+
+// FetchSoul returns the SOUL.md of the personality at ref, "" when the
+// artifact has none.
+func (f *SoulFetcher) FetchSoul(ctx context.Context, ref string) (string, error) {
+	f.mu.Lock()
+	soul, ok := f.souls[ref]
+	f.mu.Unlock()
+	if ok {
+		return soul, nil
+	}
+
+	dir, err := os.MkdirTemp("", "klaus-personality-")
+	if err != nil {
+		return "", fmt.Errorf("creating personality directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	pulled, err := f.puller.PullPersonality(ctx, ref, dir)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.Contains(ref, "@") && f.size > 0 {
+		f.mu.Lock()
+		if len(f.souls) >= f.size {
+			clear(f.souls)
+		}
+		f.souls[ref] = pulled.Soul
+		f.mu.Unlock()
+	}
+	return pulled.Soul, nil
+}
@@ -0,0 +1,59 @@
+package oci
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+type fakePuller struct {
+	souls map[string]string
+	pulls int
+}
+
+func (p *fakePuller) PullPersonality(_ context.Context, ref, dir string) (*klausoci.PulledPersonality, error) {
+	p.pulls++
+	soul, ok := p.souls[ref]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SOUL.md"), []byte(soul), 0o600); err != nil {
+		return nil, err
+	}
+	return &klausoci.PulledPersonality{Soul: soul, Dir: dir}, nil
+}
+
+func TestSoulFetcher(t *testing.T) {
+	pinned := "gsoci.azurecr.io/giantswarm/klaus-personalities/sre@sha256:abc"
+	tagged := "gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v1.0.0"
+	puller := &fakePuller{souls: map[string]string{pinned: "# SRE", tagged: "# SRE"}}
+	f := NewSoulFetcher(puller, 1)
+	ctx := context.Background()
+
+	for range 2 {
+		soul, err := f.FetchSoul(ctx, pinned)
+		if err != nil || soul != "# SRE" {
+			t.Fatalf("FetchSoul() = %q, %v", soul, err)
+		}
+	}
+	if puller.pulls != 1 {
+		t.Errorf("pulled %d times, want the pinned soul cached", puller.pulls)
+	}
+
+	for range 2 {
+		if _, err := f.FetchSoul(ctx, tagged); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if puller.pulls != 3 {
+		t.Errorf("pulled %d times, want tagged references pulled every time", puller.pulls)
+	}
+
+	if _, err := f.FetchSoul(ctx, "gsoci.azurecr.io/missing@sha256:def"); err == nil {
+		t.Error("expected the pull error")
+	}
+}
@@ -0,0 +1,215 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+// maxResponseBytes bounds the JWKS responses read.
+const maxResponseBytes = 1 << 20
+
+// signingAlgorithms are the supported JWS signing algorithms. HMAC and
+// "none" are deliberately missing: the MCP server only holds public keys.
+var signingAlgorithms = []string{
+	gooidc.RS256, gooidc.RS384, gooidc.RS512,
+	gooidc.PS256, gooidc.PS384, gooidc.PS512,
+	gooidc.ES256, gooidc.ES384, gooidc.ES512,
+}
+
+// keySet is the go-oidc KeySet of the issuer's signing keys. The JWKS
+// endpoint is discovered on the first token and its keys are cached. Network
+// requests run without mu held, so tokens signed with cached keys are
+// verified while a refresh is in flight, and concurrent refreshes are
+// collapsed into one.
+type keySet struct {
+	issuerURL string
+	client    *http.Client
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	jwksURI    string
+	keys       []jose.JSONWebKey
+	fetched    time.Time
+	attempted  time.Time
+	refreshing chan struct{}
+	refreshErr error
+}
+
+// VerifySignature implements gooidc.KeySet.
+func (k *keySet) VerifySignature(ctx context.Context, token string) ([]byte, error) {
+	jws, err := jose.ParseSigned(token, algorithms())
+	if err != nil {
+		return nil, fmt.Errorf("parsing JWT: %w", err)
+	}
+	kid := jws.Signatures[0].Header.KeyID
+	keys, err := k.signingKeys(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if payload, err := jws.Verify(&key); err == nil {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("invalid token signature")
+}
+
+func algorithms() []jose.SignatureAlgorithm {
+	algs := make([]jose.SignatureAlgorithm, 0, len(signingAlgorithms))
+	for _, alg := range signingAlgorithms {
+		algs = append(algs, jose.SignatureAlgorithm(alg))
+	}
+	return algs
+}
+
+// signingKeys returns the cached keys a token with key ID kid may be signed
+// with, or all keys for a token without one. The keys are refreshed when
+// the cache is stale or kid is unknown, at most once per
+// minKeyRefreshInterval, so rotated keys are picked up without forged key
+// IDs flooding the issuer. A failed refresh keeps using the cached keys.
+func (k *keySet) signingKeys(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	k.mu.Lock()
+	now := k.now()
+	keys := k.lookup(kid)
+	stale := k.keys == nil || now.Sub(k.fetched) >= k.ttl || len(keys) == 0
+	if !stale || (k.keys != nil && now.Sub(k.attempted) < minKeyRefreshInterval) {
+		k.mu.Unlock()
+		return keys, errUnlessFound(keys, kid)
+	}
+
+	done := k.refreshing
+	if done == nil {
+		done = make(chan struct{})
+		k.refreshing = done
+		k.attempted = now
+		jwksURI := k.jwksURI
+		k.mu.Unlock()
+
+		// The refresh outlives the token that triggered it: other tokens
+		// wait for it. The client timeout bounds it.
+		jwksURI, fetched, err := k.fetch(context.WithoutCancel(ctx), jwksURI)
+
+		k.mu.Lock()
+		k.jwksURI = jwksURI
+		if err == nil {
+			k.keys = fetched
+			k.fetched = k.now()
+		}
+		k.refreshErr = err
+		k.refreshing = nil
+		close(done)
+	} else {
+		k.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		k.mu.Lock()
+	}
+	defer k.mu.Unlock()
+
+	if k.keys == nil && k.refreshErr != nil {
+		return nil, k.refreshErr
+	}
+	keys = k.lookup(kid)
+	return keys, errUnlessFound(keys, kid)
+}
+
+func errUnlessFound(keys []jose.JSONWebKey, kid string) error {
+	if len(keys) > 0 {
+		return nil
+	}
+	return fmt.Errorf("no signing key %q found at the issuer", kid)
+}
+
+// lookup returns the cached keys matching kid. k.mu must be held.
+func (k *keySet) lookup(kid string) []jose.JSONWebKey {
+	if kid == "" {
+		return k.keys
+	}
+	var keys []jose.JSONWebKey
+	for _, key := range k.keys {
+		if key.KeyID == kid {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// fetch discovers the JWKS endpoint of the issuer unless jwksURI is already
+// known, and returns it with the signing keys it serves.
+func (k *keySet) fetch(ctx context.Context, jwksURI string) (string, []jose.JSONWebKey, error) {
+	if jwksURI == "" {
+		provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, k.client), k.issuerURL)
+		if err != nil {
+			return "", nil, fmt.Errorf("discovering issuer %s: %w", k.issuerURL, err)
+		}
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := provider.Claims(&discovery); err != nil || discovery.JWKSURI == "" {
+			return "", nil, fmt.Errorf("issuer %s has no jwks_uri", k.issuerURL)
+		}
+		jwksURI = discovery.JWKSURI
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return jwksURI, nil, err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return jwksURI, nil, fmt.Errorf("fetching signing keys from %s: %w", jwksURI, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return jwksURI, nil, fmt.Errorf("fetching signing keys from %s: unexpected status %s", jwksURI, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return jwksURI, nil, fmt.Errorf("fetching signing keys from %s: %w", jwksURI, err)
+	}
+	keys, err := parseKeySet(body)
+	if err != nil {
+		return jwksURI, nil, fmt.Errorf("signing keys of %s: %w", jwksURI, err)
+	}
+	return jwksURI, keys, nil
+}
+
+// parseKeySet returns the public signing keys of a JWKS document. Keys of
+// unsupported types, symmetric keys and encryption keys are skipped, like
+// other verifiers do.
+func parseKeySet(data []byte) ([]jose.JSONWebKey, error) {
+	var raw struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var keys []jose.JSONWebKey
+	for _, data := range raw.Keys {
+		var key jose.JSONWebKey
+		if err := key.UnmarshalJSON(data); err != nil {
+			continue
+		}
+		if (key.Use != "" && key.Use != "sig") || !key.IsPublic() || !key.Valid() {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable signing keys")
+	}
+	return keys, nil
+}
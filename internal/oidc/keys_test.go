@@ -0,0 +1,90 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseKeySet(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := ecJWK("ec-1", key)
+	offCurve := ecJWK("ec-2", key)
+	offCurve["y"] = offCurve["x"]
+	encryption := ecJWK("ec-3", key)
+	encryption["use"] = "enc"
+	secp256k1 := ecJWK("ec-4", key)
+	secp256k1["crv"] = "secp256k1"
+
+	data, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		valid, offCurve, encryption, secp256k1,
+		{"kty": "RSA", "kid": "rsa-1", "e": "AQAB"},
+		{"kty": "oct", "kid": "hmac", "k": b64([]byte("secret"))},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		t.Fatalf("parseKeySet() error = %v", err)
+	}
+	if len(keys) != 1 || keys[0].KeyID != "ec-1" {
+		t.Errorf("parseKeySet() = %v, want only the valid public signing key", keys)
+	}
+
+	if _, err := parseKeySet([]byte(`{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`)); err == nil {
+		t.Error("expected an error without usable keys")
+	}
+}
+
+func TestSigningKeys_ConcurrentRefresh(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestIssuer(t, rsaJWK("rsa-1", key))
+	v := testVerifier(t, issuer)
+	token := signRS256(t, "rsa-1", key, validClaims(issuer.URL))
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := v.Verify(context.Background(), token); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if n := issuer.jwksFetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want concurrent refreshes collapsed", n)
+	}
+}
+
+func TestSigningKeys_CancelledTrigger(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestIssuer(t, rsaJWK("rsa-1", key))
+	v := testVerifier(t, issuer)
+
+	// A refresh triggered by a request that goes away still completes.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = v.Verify(ctx, signRS256(t, "rsa-1", key, validClaims(issuer.URL)))
+	v.keys.mu.Lock()
+	fetched := v.keys.fetched
+	v.keys.mu.Unlock()
+	if fetched.IsZero() || time.Since(fetched) > time.Minute {
+		t.Errorf("keys fetched at %v, want the refresh to complete", fetched)
+	}
+}
@@ -0,0 +1,169 @@
+// Package oidc verifies the OIDC ID tokens callers present to the MCP
+// server with go-oidc. Signing keys are discovered from the issuer's JWKS
+// endpoint and cached.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+)
+
+const (
+	// DefaultKeyCacheTTL is how long discovered signing keys are used
+	// before the JWKS endpoint is queried again.
+	DefaultKeyCacheTTL = time.Hour
+
+	// DefaultGroupsClaim is the claim holding the caller's groups.
+	DefaultGroupsClaim = "groups"
+
+	// minKeyRefreshInterval rate-limits the JWKS refreshes triggered by
+	// tokens signed with an unknown key, so forged key IDs cannot be used
+	// to flood the issuer.
+	minKeyRefreshInterval = time.Minute
+
+	// clockSkew is the leeway granted when checking exp. go-oidc grants
+	// its own on nbf.
+	clockSkew = time.Minute
+)
+
+// DefaultUsernameClaims are the claims the username is read from, in order
+// of preference.
+var DefaultUsernameClaims = []string{"email", "sub"}
+
+// Config configures token verification.
+type Config struct {
+	// IssuerURL is the issuer the tokens must be issued by. The signing keys
+	// are discovered from its /.well-known/openid-configuration.
+	IssuerURL string
+
+	// Audiences are the accepted values of the aud claim. A token must be
+	// issued for at least one of them.
+	Audiences []string
+
+	// UsernameClaims are the claims the username is read from; the first
+	// non-empty one wins. Defaults to DefaultUsernameClaims.
+	UsernameClaims []string
+
+	// GroupsClaim is the claim the groups are read from. Defaults to
+	// DefaultGroupsClaim.
+	GroupsClaim string
+
+	// KeyCacheTTL is how long signing keys are cached. Defaults to
+	// DefaultKeyCacheTTL.
+	KeyCacheTTL time.Duration
+
+	// HTTPClient is used for discovery and JWKS requests. Defaults to a
+	// client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// Identity is the caller identity mapped from the claims of a verified
+// token.
+type Identity struct {
+	Username string
+	Groups   []string
+}
+
+// Verifier verifies the signature, issuer, audience and lifetime of tokens
+// with go-oidc. It is safe for concurrent use.
+type Verifier struct {
+	config   Config
+	now      func() time.Time
+	keys     *keySet
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewVerifier returns a verifier for config. Discovery is deferred to the
+// first token, so the operator starts while the issuer is unreachable.
+func NewVerifier(config Config) (*Verifier, error) {
+	if config.IssuerURL == "" {
+		return nil, errors.New("issuer URL is required")
+	}
+	if len(config.Audiences) == 0 {
+		return nil, errors.New("at least one audience is required")
+	}
+	if len(config.UsernameClaims) == 0 {
+		config.UsernameClaims = DefaultUsernameClaims
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = DefaultGroupsClaim
+	}
+	if config.KeyCacheTTL <= 0 {
+		config.KeyCacheTTL = DefaultKeyCacheTTL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	v := &Verifier{config: config, now: time.Now}
+	v.keys = &keySet{
+		issuerURL: config.IssuerURL,
+		client:    config.HTTPClient,
+		ttl:       config.KeyCacheTTL,
+		now:       func() time.Time { return v.now() },
+	}
+	v.verifier = gooidc.NewVerifier(config.IssuerURL, v.keys, &gooidc.Config{
+		// The audience is checked against the list of accepted audiences.
+		SkipClientIDCheck:    true,
+		SupportedSigningAlgs: signingAlgorithms,
+		Now:                  func() time.Time { return v.now().Add(-clockSkew) },
+	})
+	return v, nil
+}
+
+// Verify checks a token, with or without a "Bearer " prefix, and returns the
+// identity in its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = token[7:]
+	}
+	idToken, err := v.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(idToken.Audience, func(a string) bool { return slices.Contains(v.config.Audiences, a) }) {
+		return nil, fmt.Errorf("token audience %v does not include %v", idToken.Audience, v.config.Audiences)
+	}
+
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("decoding JWT claims: %w", err)
+	}
+	return v.identity(claims)
+}
+
+// identity maps the claims of a verified token to the caller identity.
+func (v *Verifier) identity(claims map[string]any) (*Identity, error) {
+	id := &Identity{}
+	for _, claim := range v.config.UsernameClaims {
+		if s, _ := claims[claim].(string); s != "" {
+			id.Username = s
+			break
+		}
+	}
+	if id.Username == "" {
+		return nil, fmt.Errorf("token contains none of the username claims %v", v.config.UsernameClaims)
+	}
+
+	switch groups := claims[v.config.GroupsClaim].(type) {
+	case []any:
+		for _, g := range groups {
+			if s, ok := g.(string); ok && s != "" {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	case string:
+		// Some identity providers emit a single group as a string.
+		if groups != "" {
+			id.Groups = []string{groups}
+		}
+	}
+	return id, nil
+}
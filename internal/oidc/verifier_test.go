@@ -0,0 +1,291 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testIssuer serves the discovery document and JWKS of an issuer whose keys
+// can be swapped by the test.
+type testIssuer struct {
+	*httptest.Server
+	keys        atomic.Value // []map[string]string
+	jwksFetches atomic.Int32
+}
+
+func newTestIssuer(t *testing.T, keys ...map[string]string) *testIssuer {
+	t.Helper()
+	issuer := &testIssuer{}
+	issuer.keys.Store(keys)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": issuer.URL, "jwks_uri": issuer.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		issuer.jwksFetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": issuer.keys.Load()})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+		"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	raw, err := key.PublicKey.Bytes()
+	if err != nil {
+		panic(err)
+	}
+	return map[string]string{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(raw[1:33]), "y": b64(raw[33:])}
+}
+
+// signRS256 returns a token with claims signed by key.
+func signRS256(t *testing.T, kid string, key *rsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegments(t, map[string]any{"alg": "RS256", "kid": kid}, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + b64(sig)
+}
+
+// signES256 returns a token with claims signed by key.
+func signES256(t *testing.T, kid string, key *ecdsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+	signed := encodeSegments(t, map[string]any{"alg": "ES256", "kid": kid}, claims)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signed + "." + b64(sig)
+}
+
+func encodeSegments(t *testing.T, header, claims map[string]any) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b64(h) + "." + b64(c)
+}
+
+func testVerifier(t *testing.T, issuer *testIssuer) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(Config{IssuerURL: issuer.URL, Audiences: []string{"klaus"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func validClaims(issuer string) map[string]any {
+	return map[string]any{
+		"iss":    issuer,
+		"aud":    []string{"other", "klaus"},
+		"sub":    "CiQ1",
+		"email":  "user@example.com",
+		"groups": []string{"dev", "platform"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestNewVerifier_RequiresIssuerAndAudience(t *testing.T) {
+	if _, err := NewVerifier(Config{Audiences: []string{"klaus"}}); err == nil {
+		t.Error("expected an error without issuer")
+	}
+	if _, err := NewVerifier(Config{IssuerURL: "https://dex.example.com"}); err == nil {
+		t.Error("expected an error without audience")
+	}
+}
+
+func TestVerify_ValidTokens(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestIssuer(t, rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey))
+	v := testVerifier(t, issuer)
+
+	for name, token := range map[string]string{
+		"RS256": "Bearer " + signRS256(t, "rsa-1", rsaKey, validClaims(issuer.URL)),
+		"ES256": signES256(t, "ec-1", ecKey, validClaims(issuer.URL)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			id, err := v.Verify(context.Background(), token)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if id.Username != "user@example.com" || strings.Join(id.Groups, ",") != "dev,platform" {
+				t.Errorf("identity = %+v", id)
+			}
+		})
+	}
+	if n := issuer.jwksFetches.Load(); n != 1 {
+		t.Errorf("JWKS fetched %d times, want the keys cached", n)
+	}
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestIssuer(t, rsaJWK("rsa-1", key))
+	v := testVerifier(t, issuer)
+
+	with := func(k string, val any) map[string]any {
+		claims := validClaims(issuer.URL)
+		if val == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = val
+		}
+		return claims
+	}
+	valid := signRS256(t, "rsa-1", key, validClaims(issuer.URL))
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "forged signature", token: signRS256(t, "rsa-1", other, validClaims(issuer.URL)), wantErr: "invalid token signature"},
+		{name: "tampered claims", token: parts[0] + "." + b64([]byte(`{"email":"admin@example.com"}`)) + "." + parts[2], wantErr: "invalid token signature"},
+		{name: "unsigned", token: b64([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".", wantErr: "unexpected signature algorithm"},
+		{name: "unknown key", token: signRS256(t, "rsa-2", key, validClaims(issuer.URL)), wantErr: "no signing key"},
+		{name: "wrong issuer", token: signRS256(t, "rsa-1", key, with("iss", "https://evil.example.com")), wantErr: "issued by a different provider"},
+		{name: "wrong audience", token: signRS256(t, "rsa-1", key, with("aud", "other")), wantErr: "audience"},
+		{name: "expired", token: signRS256(t, "rsa-1", key, with("exp", time.Now().Add(-time.Hour).Unix())), wantErr: "expired"},
+		{name: "no expiry", token: signRS256(t, "rsa-1", key, with("exp", nil)), wantErr: "expired"},
+		{name: "not yet valid", token: signRS256(t, "rsa-1", key, with("nbf", time.Now().Add(time.Hour).Unix())), wantErr: "before the nbf"},
+		{name: "malformed", token: "not-a-jwt", wantErr: "malformed jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerify_ClaimMapping(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestIssuer(t, rsaJWK("rsa-1", key))
+	v, err := NewVerifier(Config{
+		IssuerURL:      issuer.URL,
+		Audiences:      []string{"klaus"},
+		UsernameClaims: []string{"preferred_username", "sub"},
+		GroupsClaim:    "roles",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := validClaims(issuer.URL)
+	claims["roles"] = "admins"
+	id, err := v.Verify(context.Background(), signRS256(t, "rsa-1", key, claims))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if id.Username != "CiQ1" || len(id.Groups) != 1 || id.Groups[0] != "admins" {
+		t.Errorf("identity = %+v, want sub and the roles claim", id)
+	}
+
+	delete(claims, "sub")
+	if _, err := v.Verify(context.Background(), signRS256(t, "rsa-1", key, claims)); err == nil ||
+		!strings.Contains(err.Error(), "username claims") {
+		t.Errorf("Verify() error = %v, want a missing username error", err)
+	}
+}
+
+func TestVerify_KeyRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestIssuer(t, rsaJWK("old", oldKey))
+	v := testVerifier(t, issuer)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+
+	if _, err := v.Verify(context.Background(), signRS256(t, "old", oldKey, validClaims(issuer.URL))); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// An unknown key ID refreshes the keys, but not more than once per
+	// minKeyRefreshInterval.
+	issuer.keys.Store([]map[string]string{rsaJWK("new", newKey)})
+	rotated := signRS256(t, "new", newKey, validClaims(issuer.URL))
+	if _, err := v.Verify(context.Background(), rotated); err == nil {
+		t.Error("expected the keys not to be refreshed right after a fetch")
+	}
+	now = now.Add(minKeyRefreshInterval)
+	if _, err := v.Verify(context.Background(), rotated); err != nil {
+		t.Fatalf("Verify() after rotation error = %v", err)
+	}
+	if n := issuer.jwksFetches.Load(); n != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", n)
+	}
+
+	// Cached keys outlive an unreachable issuer.
+	claims := validClaims(issuer.URL)
+	claims["exp"] = now.Add(4 * DefaultKeyCacheTTL).Unix()
+	longLived := signRS256(t, "new", newKey, claims)
+	issuer.Close()
+	now = now.Add(2 * DefaultKeyCacheTTL)
+	if _, err := v.Verify(context.Background(), longLived); err != nil {
+		t.Errorf("Verify() with unreachable issuer error = %v, want the cached keys used", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package permissions implements the role-aware permission mode policy. The
+// policy maps identity groups to the permissionMode an instance defaults to
+// and the most permissive mode its creator may request. It is enforced by the
+// MCP create path and the KlausInstance, KlausJob, KlausCronJob and
+// KlausTrigger admission webhooks.
+package permissions
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// DefaultGroupsClaim is the JWT claim holding the caller's groups.
+const DefaultGroupsClaim = "groups"
+
+// Policy maps identity groups to permission mode defaults and maximums.
+type Policy struct {
+	// Default is the permission mode of instances whose creator matches no
+	// rule.
+	Default klausv1alpha1.PermissionMode `json:"default,omitempty"`
+
+	// Max is the most permissive mode creators matching no rule may request.
+	Max klausv1alpha1.PermissionMode `json:"max,omitempty"`
+
+	// GroupsClaim is the JWT claim the MCP server reads groups from.
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+
+	// AdminGroups may exceed their maximum by setting
+	// spec.claude.permissionModeOverride.
+	AdminGroups []string `json:"adminGroups,omitempty"`
+
+	// Rules grant defaults and maximums to groups. Like RBAC, rules are
+	// additive: a creator matching several rules gets the most permissive
+	// of them.
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule sets the permission mode default and maximum for members of Groups.
+type Rule struct {
+	Groups  []string                     `json:"groups"`
+	Default klausv1alpha1.PermissionMode `json:"default,omitempty"`
+	Max     klausv1alpha1.PermissionMode `json:"max,omitempty"`
+}
+
+// Decision is the outcome of evaluating the policy for a set of groups.
+type Decision struct {
+	Default klausv1alpha1.PermissionMode
+	Max     klausv1alpha1.PermissionMode
+	Admin   bool
+}
+
+// DefaultPolicy returns the policy used when none is configured: every
+// instance defaults to, and may use, bypassPermissions.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Default:     klausv1alpha1.PermissionModeBypass,
+		Max:         klausv1alpha1.PermissionModeBypass,
+		GroupsClaim: DefaultGroupsClaim,
+	}
+}
+
+// LoadPolicy reads a YAML policy file. An empty path returns DefaultPolicy.
+// Unset defaults and maximums fall back to those of DefaultPolicy.
+func LoadPolicy(path string) (*Policy, error) {
+	if path == "" {
+		return DefaultPolicy(), nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path is an operator flag
+	if err != nil {
+		return nil, fmt.Errorf("reading permission policy: %w", err)
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("parsing permission policy %s: %w", path, err)
+	}
+	if err := policy.complete(); err != nil {
+		return nil, fmt.Errorf("invalid permission policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// complete fills unset fields and validates the policy.
+func (p *Policy) complete() error {
+	defaults := DefaultPolicy()
+	if p.Max == "" {
+		p.Max = defaults.Max
+	}
+	if p.Default == "" {
+		p.Default = p.Max
+	}
+	if p.GroupsClaim == "" {
+		p.GroupsClaim = defaults.GroupsClaim
+	}
+	if err := checkModes(p.Default, p.Max); err != nil {
+		return err
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if len(rule.Groups) == 0 {
+			return fmt.Errorf("rule %d: groups must not be empty", i)
+		}
+		if rule.Max == "" {
+			rule.Max = p.Max
+		}
+		if rule.Default == "" {
+			rule.Default = minMode(p.Default, rule.Max)
+		}
+		if err := checkModes(rule.Default, rule.Max); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func checkModes(defaultMode, maximum klausv1alpha1.PermissionMode) error {
+	for _, mode := range []klausv1alpha1.PermissionMode{defaultMode, maximum} {
+		if rank(mode) < 0 {
+			return fmt.Errorf("unknown permission mode %q", mode)
+		}
+	}
+	if rank(defaultMode) > rank(maximum) {
+		return fmt.Errorf("default %q is more permissive than max %q", defaultMode, maximum)
+	}
+	return nil
+}
+
+// Decide evaluates the policy for a creator's groups.
+func (p *Policy) Decide(groups []string) Decision {
+	d := Decision{Default: p.Default, Max: p.Max}
+	matched := false
+	for _, rule := range p.Rules {
+		if !matchesAny(rule.Groups, groups) {
+			continue
+		}
+		if !matched {
+			d.Default, d.Max = rule.Default, rule.Max
+			matched = true
+			continue
+		}
+		d.Default = maxMode(d.Default, rule.Default)
+		d.Max = maxMode(d.Max, rule.Max)
+	}
+	d.Admin = matchesAny(p.AdminGroups, groups)
+	return d
+}
+
+// ApplyDefault sets the permission mode to the decided default when unset.
+func (d Decision) ApplyDefault(claude *klausv1alpha1.ClaudeConfig) {
+	if claude.PermissionMode == "" {
+		claude.PermissionMode = d.Default
+	}
+}
+
+// Check returns an error if the permission mode exceeds the decided maximum,
+// or if permissionModeOverride is set by a creator who is not an admin.
+func (d Decision) Check(claude *klausv1alpha1.ClaudeConfig) error {
+	if claude.PermissionModeOverride {
+		if !d.Admin {
+			return fmt.Errorf("permissionModeOverride can only be set by platform admins")
+		}
+		return nil
+	}
+	mode := claude.PermissionMode
+	if mode == "" {
+		mode = d.Default
+	}
+	if rank(mode) > rank(d.Max) {
+		return fmt.Errorf("permissionMode %q exceeds %q, the most permissive mode allowed for your groups; "+
+			"ask a platform admin to set permissionModeOverride if the instance needs it", mode, d.Max)
+	}
+	return nil
+}
+
+// rank orders permission modes from least to most permissive. Unknown modes
+// rank below zero.
+func rank(mode klausv1alpha1.PermissionMode) int {
+	switch mode {
+	case klausv1alpha1.PermissionModeDefault:
+		return 0
+	case klausv1alpha1.PermissionModeBypass:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func maxMode(a, b klausv1alpha1.PermissionMode) klausv1alpha1.PermissionMode {
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+func minMode(a, b klausv1alpha1.PermissionMode) klausv1alpha1.PermissionMode {
+	if rank(b) < rank(a) {
+		return b
+	}
+	return a
+}
+
+func matchesAny(want, have []string) bool {
+	for _, g := range have {
+		if slices.Contains(want, g) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package permissions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	modeDefault = klausv1alpha1.PermissionModeDefault
+	modeBypass  = klausv1alpha1.PermissionModeBypass
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing policy: %v", err)
+	}
+	return path
+}
+
+func TestLoadPolicy_EmptyPath(t *testing.T) {
+	policy, err := LoadPolicy("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Default != modeBypass || policy.Max != modeBypass || policy.GroupsClaim != DefaultGroupsClaim {
+		t.Errorf("policy = %+v, want the default policy", policy)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := writePolicy(t, `
+default: default
+max: default
+groupsClaim: teams
+adminGroups: [platform-admins]
+rules:
+- groups: [platform]
+  max: bypassPermissions
+- groups: [sre]
+  default: bypassPermissions
+  max: bypassPermissions
+`)
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.GroupsClaim != "teams" || len(policy.Rules) != 2 {
+		t.Fatalf("policy = %+v", policy)
+	}
+	// A rule without a default keeps the more restrictive top-level default.
+	if got := policy.Rules[0]; got.Default != modeDefault || got.Max != modeBypass {
+		t.Errorf("rule 0 = %+v, want default %q and max %q", got, modeDefault, modeBypass)
+	}
+}
+
+func TestLoadPolicy_Defaults(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, "max: default\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Default != modeDefault || policy.GroupsClaim != DefaultGroupsClaim {
+		t.Errorf("policy = %+v, want default %q and claim %q", policy, modeDefault, DefaultGroupsClaim)
+	}
+}
+
+func TestLoadPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown field", content: "defaults: default\n", wantErr: "parsing permission policy"},
+		{name: "unknown mode", content: "max: acceptEdits\n", wantErr: `unknown permission mode "acceptEdits"`},
+		{name: "default above max", content: "default: bypassPermissions\nmax: default\n", wantErr: "more permissive than max"},
+		{name: "rule without groups", content: "rules:\n- max: default\n", wantErr: "rule 0: groups must not be empty"},
+		{name: "rule default above max", content: "rules:\n- groups: [dev]\n  default: bypassPermissions\n  max: default\n", wantErr: "rule 0:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicy(writePolicy(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy_MissingFile(t *testing.T) {
+	if _, err := LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestDecide(t *testing.T) {
+	policy := &Policy{
+		Default:     modeDefault,
+		Max:         modeDefault,
+		AdminGroups: []string{"platform-admins"},
+		Rules: []Rule{
+			{Groups: []string{"platform"}, Default: modeDefault, Max: modeBypass},
+			{Groups: []string{"sre"}, Default: modeBypass, Max: modeBypass},
+		},
+	}
+	tests := []struct {
+		name   string
+		groups []string
+		want   Decision
+	}{
+		{name: "no groups", want: Decision{Default: modeDefault, Max: modeDefault}},
+		{name: "unmatched group", groups: []string{"dev"}, want: Decision{Default: modeDefault, Max: modeDefault}},
+		{name: "one rule", groups: []string{"platform"}, want: Decision{Default: modeDefault, Max: modeBypass}},
+		{name: "rules are additive", groups: []string{"platform", "sre"}, want: Decision{Default: modeBypass, Max: modeBypass}},
+		{name: "admin", groups: []string{"platform-admins"}, want: Decision{Default: modeDefault, Max: modeDefault, Admin: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Decide(tt.groups); got != tt.want {
+				t.Errorf("Decide(%v) = %+v, want %+v", tt.groups, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecision_ApplyDefault(t *testing.T) {
+	d := Decision{Default: modeDefault, Max: modeBypass}
+
+	unset := &klausv1alpha1.ClaudeConfig{}
+	d.ApplyDefault(unset)
+	if unset.PermissionMode != modeDefault {
+		t.Errorf("PermissionMode = %q, want %q", unset.PermissionMode, modeDefault)
+	}
+
+	explicit := &klausv1alpha1.ClaudeConfig{PermissionMode: modeBypass}
+	d.ApplyDefault(explicit)
+	if explicit.PermissionMode != modeBypass {
+		t.Errorf("PermissionMode = %q, want the explicit %q", explicit.PermissionMode, modeBypass)
+	}
+}
+
+func TestDecision_Check(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision Decision
+		claude   klausv1alpha1.ClaudeConfig
+		wantErr  string
+	}{
+		{name: "within max", decision: Decision{Default: modeDefault, Max: modeBypass}, claude: klausv1alpha1.ClaudeConfig{PermissionMode: modeBypass}},
+		{name: "unset uses the default", decision: Decision{Default: modeDefault, Max: modeDefault}},
+		{name: "exceeds max", decision: Decision{Default: modeDefault, Max: modeDefault}, claude: klausv1alpha1.ClaudeConfig{PermissionMode: modeBypass}, wantErr: "exceeds"},
+		{
+			name:     "override without admin",
+			decision: Decision{Default: modeBypass, Max: modeBypass},
+			claude:   klausv1alpha1.ClaudeConfig{PermissionMode: modeBypass, PermissionModeOverride: true},
+			wantErr:  "only be set by platform admins",
+		},
+		{
+			name:     "admin override exceeds max",
+			decision: Decision{Default: modeDefault, Max: modeDefault, Admin: true},
+			claude:   klausv1alpha1.ClaudeConfig{PermissionMode: modeBypass, PermissionModeOverride: true},
+		},
+		{
+			name:     "admin without override is still capped",
+			decision: Decision{Default: modeDefault, Max: modeDefault, Admin: true},
+			claude:   klausv1alpha1.ClaudeConfig{PermissionMode: modeBypass},
+			wantErr:  "exceeds",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decision.Check(&tt.claude)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Package pluginsync implements the sync-plugins subcommand run by plugin
+// sync Jobs. It pulls plugin artifacts into their sub-paths of the shared
+// plugins PVC, for clusters where instance pods cannot mount OCI image
+// volumes.
+package pluginsync
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+// Puller pulls a plugin artifact into a directory; *klausoci.Client
+// implements it.
+type Puller interface {
+	PullPlugin(ctx context.Context, ref, destDir string) (*klausoci.PulledPlugin, error)
+}
+
+// Entry is a plugin to sync: its reference and the sub-path of the
+// destination directory it is extracted to.
+type Entry struct {
+	SubPath string
+	Ref     string
+}
+
+// ParseEntry parses a "<sub-path>=<reference>" argument. The sub-path must
+// be a single path element.
+func ParseEntry(arg string) (Entry, error) {
+	subPath, ref, ok := strings.Cut(arg, "=")
+	if !ok || subPath == "" || ref == "" {
+		return Entry{}, fmt.Errorf("invalid plugin %q: want <sub-path>=<reference>", arg)
+	}
+	if subPath == "." || subPath == ".." || strings.ContainsAny(subPath, `/\`) {
+		return Entry{}, fmt.Errorf("invalid plugin sub-path %q", subPath)
+	}
+	return Entry{SubPath: subPath, Ref: ref}, nil
+}
+
+// Sync pulls every entry into dest. Entries already extracted at the same
+// digest are skipped by the puller's cache check.
+func Sync(ctx context.Context, puller Puller, dest string, entries []Entry, out io.Writer) error {
+	for _, entry := range entries {
+		pulled, err := puller.PullPlugin(ctx, entry.Ref, filepath.Join(dest, entry.SubPath))
+		if err != nil {
+			return fmt.Errorf("pulling %s: %w", entry.Ref, err)
+		}
+		state := "pulled"
+		if pulled.Cached {
+			state = "up to date"
+		}
+		_, _ = fmt.Fprintf(out, "%s %s (%s) into %s\n", state, entry.Ref, klausoci.TruncateDigest(pulled.Digest), entry.SubPath)
+	}
+	return nil
+}
+
+// Main runs the sync-plugins subcommand with the arguments following it
+// and returns the process exit code.
+func Main(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("sync-plugins", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	dest := fs.String("dest", "", "Directory the plugins are extracted to, one sub-path each.")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *dest == "" {
+		_, _ = fmt.Fprintln(stderr, "--dest is required")
+		return 2
+	}
+
+	var entries []Entry
+	for _, arg := range fs.Args() {
+		entry, err := ParseEntry(arg)
+		if err != nil {
+			_, _ = fmt.Fprintln(stderr, err)
+			return 2
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := Sync(ctx, klausoci.NewClient(), *dest, entries, stdout); err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package pluginsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+// fakePuller records the pulls and reports cached for refs in cached.
+type fakePuller struct {
+	pulls  map[string]string
+	cached map[string]bool
+	err    error
+}
+
+func (f *fakePuller) PullPlugin(_ context.Context, ref, destDir string) (*klausoci.PulledPlugin, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.pulls == nil {
+		f.pulls = map[string]string{}
+	}
+	f.pulls[ref] = destDir
+	return &klausoci.PulledPlugin{
+		ArtifactInfo: klausoci.ArtifactInfo{Ref: ref, Digest: "sha256:0123456789abcdef"},
+		Dir:          destDir,
+		Cached:       f.cached[ref],
+	}, nil
+}
+
+func TestParseEntry(t *testing.T) {
+	entry, err := ParseEntry("gs-base-0a1b2c3d4e=example.com/plugins/gs-base:v1.0.0")
+	if err != nil || entry.SubPath != "gs-base-0a1b2c3d4e" || entry.Ref != "example.com/plugins/gs-base:v1.0.0" {
+		t.Errorf("ParseEntry() = %+v, %v", entry, err)
+	}
+
+	for _, arg := range []string{"", "no-separator", "=ref", "sub=", "../up=ref", "a/b=ref", ".=ref"} {
+		if _, err := ParseEntry(arg); err == nil {
+			t.Errorf("ParseEntry(%q) expected an error", arg)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	puller := &fakePuller{cached: map[string]bool{"example.com/b:v1": true}}
+	var out bytes.Buffer
+	entries := []Entry{{SubPath: "a-1", Ref: "example.com/a:v1"}, {SubPath: "b-1", Ref: "example.com/b:v1"}}
+
+	if err := Sync(context.Background(), puller, "/plugins", entries, &out); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if puller.pulls["example.com/a:v1"] != filepath.Join("/plugins", "a-1") || puller.pulls["example.com/b:v1"] != filepath.Join("/plugins", "b-1") {
+		t.Errorf("pulls = %v, want one sub-path each", puller.pulls)
+	}
+	if !strings.Contains(out.String(), "pulled example.com/a:v1") || !strings.Contains(out.String(), "up to date example.com/b:v1") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestSync_Error(t *testing.T) {
+	puller := &fakePuller{err: errors.New("connection refused")}
+	err := Sync(context.Background(), puller, "/plugins", []Entry{{SubPath: "a-1", Ref: "example.com/a:v1"}}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "example.com/a:v1") {
+		t.Errorf("Sync() error = %v, want the failed reference", err)
+	}
+}
+
+func TestMain_InvalidArguments(t *testing.T) {
+	for _, args := range [][]string{{}, {"--dest", "/plugins", "invalid"}} {
+		var stderr bytes.Buffer
+		if code := Main(context.Background(), args, &bytes.Buffer{}, &stderr); code != 2 || stderr.Len() == 0 {
+			t.Errorf("Main(%v) = %d, stderr %q; want usage error", args, code, stderr.String())
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package registrypolicy restricts the registries and repositories the
+// container image, personality and plugins of instances and jobs may be
+// pulled from. It is enforced by the admission webhooks and, on the resolved
+// references, by the controllers.
+package registrypolicy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// dockerHub is the registry of references without a registry host, as the
+// container runtime resolves them.
+const dockerHub = "docker.io"
+
+// Policy lists the registries and repositories artifacts may and may not
+// come from. Entries are a registry host (gsoci.azurecr.io), a repository
+// prefix matched at path boundaries (gsoci.azurecr.io/giantswarm) or a
+// repository.
+type Policy struct {
+	// Allow are the only registries and repositories that may be used.
+	// Empty allows every one not denied.
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny are registries and repositories that may not be used, even
+	// when allowed.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// LoadPolicy reads a YAML registry policy file. An empty path returns nil:
+// every registry is allowed.
+func LoadPolicy(path string) (*Policy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path is an operator flag
+	if err != nil {
+		return nil, fmt.Errorf("reading registry policy: %w", err)
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("parsing registry policy %s: %w", path, err)
+	}
+	if len(policy.Allow) == 0 && len(policy.Deny) == 0 {
+		return nil, fmt.Errorf("registry policy %s neither allows nor denies any registry", path)
+	}
+	for _, entry := range append(policy.Allow, policy.Deny...) {
+		if entry == "" || strings.ContainsAny(entry, "@ ") {
+			return nil, fmt.Errorf("registry policy %s: invalid entry %q", path, entry)
+		}
+	}
+	return policy, nil
+}
+
+// Check returns an error unless the repository of ref may be used. Short
+// names are expanded with registryBase like the klaus-oci resolver does.
+func (p *Policy) Check(ref, registryBase string) error {
+	if p == nil {
+		return nil
+	}
+	repo := Repository(ref, registryBase)
+	for _, entry := range p.Deny {
+		if Matches(repo, entry) {
+			return fmt.Errorf("repository %s is denied by the registry policy", repo)
+		}
+	}
+	if len(p.Allow) == 0 {
+		return nil
+	}
+	for _, entry := range p.Allow {
+		if Matches(repo, entry) {
+			return nil
+		}
+	}
+	return fmt.Errorf("repository %s is not allowed by the registry policy (allowed: %s)", repo, strings.Join(p.Allow, ", "))
+}
+
+// Validate checks the container image, personality and plugin references
+// at path, e.g. spec or spec.inlinePersonality.
+func (p *Policy) Validate(path *field.Path, image, personality string, plugins []klausv1alpha1.PluginReference) field.ErrorList {
+	if p == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if image != "" {
+		if err := p.Check(image, klausoci.DefaultToolchainRegistry); err != nil {
+			errs = append(errs, field.Forbidden(path.Child("image"), err.Error()))
+		}
+	}
+	if personality != "" {
+		if err := p.Check(personality, klausoci.DefaultPersonalityRegistry); err != nil {
+			errs = append(errs, field.Forbidden(path.Child("personality"), err.Error()))
+		}
+	}
+	for i, plugin := range plugins {
+		if err := p.Check(plugin.Repository, klausoci.DefaultPluginRegistry); err != nil {
+			errs = append(errs, field.Forbidden(path.Child("plugins").Index(i).Child("repository"), err.Error()))
+		}
+	}
+	return errs
+}
+
+// ValidateInstanceSpec checks the references of an instance, including
+// those of its inline personality.
+func (p *Policy) ValidateInstanceSpec(spec *klausv1alpha1.KlausInstanceSpec) field.ErrorList {
+	path := field.NewPath("spec")
+	errs := p.Validate(path, spec.Image, spec.Personality, spec.Plugins)
+	if inline := spec.InlinePersonality; inline != nil {
+		errs = append(errs, p.Validate(path.Child("inlinePersonality"), inline.Image, "", inline.Plugins)...)
+	}
+	return errs
+}
+
+// Repository returns the fully qualified, normalized repository of ref
+// without tag or digest. Short names without a slash are expanded with
+// registryBase, references without a registry host are on Docker Hub, and
+// the official Docker Hub images are below library/.
+func Repository(ref, registryBase string) string {
+	repo := klausoci.RepositoryFromRef(strings.TrimSpace(ref))
+	if !strings.Contains(repo, "/") {
+		name, _ := klausoci.SplitNameTag(repo)
+		return Normalize(registryBase + "/" + name)
+	}
+	host, _, _ := strings.Cut(repo, "/")
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		repo = dockerHub + "/" + repo
+	}
+	repo = Normalize(repo)
+	if host, path, _ := strings.Cut(repo, "/"); host == dockerHub && !strings.Contains(path, "/") {
+		repo = dockerHub + "/library/" + path
+	}
+	return repo
+}
+
+// Normalize returns the canonical spelling of a repository or repository
+// prefix: the registry host in lower case, without the default HTTPS port
+// and with the aliases of Docker Hub as docker.io, so the spellings a
+// container runtime treats as the same registry compare equal.
+func Normalize(repo string) string {
+	host, path, found := strings.Cut(repo, "/")
+	host = strings.TrimSuffix(strings.ToLower(host), ":443")
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		host = dockerHub
+	}
+	if !found {
+		return host
+	}
+	return host + "/" + path
+}
+
+// Matches reports whether repo, as returned by Repository, is entry or
+// below it, entry being a registry host or a repository prefix matched at
+// path boundaries.
+func Matches(repo, entry string) bool {
+	entry = strings.TrimSuffix(Normalize(entry), "/")
+	return repo == entry || strings.HasPrefix(repo, entry+"/")
+}
@@ -0,0 +1,130 @@
+package registrypolicy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "registry-policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing policy: %v", err)
+	}
+	return path
+}
+
+func TestLoadPolicy(t *testing.T) {
+	if policy, err := LoadPolicy(""); err != nil || policy != nil {
+		t.Errorf("policy = %+v, err = %v; want no policy", policy, err)
+	}
+
+	policy, err := LoadPolicy(writePolicy(t, "allow: [gsoci.azurecr.io/giantswarm]\ndeny: [gsoci.azurecr.io/giantswarm/klaus-experimental]\n"))
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	if len(policy.Allow) != 1 || len(policy.Deny) != 1 {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	for content, wantErr := range map[string]string{
+		"allow: []":                        "neither allows nor denies",
+		"allow: ['']":                      "invalid entry",
+		"deny: [example.com/a@sha256:abc]": "invalid entry",
+		"allowed: [example.com]":           "unknown field",
+	} {
+		if _, err := LoadPolicy(writePolicy(t, content)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("LoadPolicy(%q) error = %v, want %q", content, err, wantErr)
+		}
+	}
+}
+
+func TestRepository(t *testing.T) {
+	tests := []struct{ ref, want string }{
+		{"go-dev", "gsoci.azurecr.io/giantswarm/klaus-personalities/go-dev"},
+		{"go-dev:v1.2.0", "gsoci.azurecr.io/giantswarm/klaus-personalities/go-dev"},
+		{"gsoci.azurecr.io/giantswarm/klaus-personalities/go-dev:v1.2.0", "gsoci.azurecr.io/giantswarm/klaus-personalities/go-dev"},
+		{"localhost:5000/team/agent@sha256:abc", "localhost:5000/team/agent"},
+		{"library/golang:1.26", "docker.io/library/golang"},
+		{"docker.io/golang:1.26", "docker.io/library/golang"},
+		{"index.docker.io/library/golang:1.26", "docker.io/library/golang"},
+		{"GSOCI.azurecr.io:443/giantswarm/agent:v1", "gsoci.azurecr.io/giantswarm/agent"},
+	}
+	for _, tt := range tests {
+		if got := Repository(tt.ref, "gsoci.azurecr.io/giantswarm/klaus-personalities"); got != tt.want {
+			t.Errorf("Repository(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policy := &Policy{
+		Allow: []string{"gsoci.azurecr.io/giantswarm", "docker.io/library/golang"},
+		Deny:  []string{"gsoci.azurecr.io/giantswarm/klaus-experimental/"},
+	}
+	tests := []struct {
+		ref     string
+		wantErr string
+	}{
+		{ref: "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:v1.0.0"},
+		{ref: "docker.io/library/golang:1.26"},
+		{ref: "index.docker.io/golang:1.26"},
+		{ref: "golang:1.26", wantErr: "giantswarm-evil/klaus-toolchains/golang is not allowed"},
+		{ref: "docker.io/library/golang-evil:1.0", wantErr: "not allowed"},
+		{ref: "gsoci.azurecr.io/giantswarm-evil/agent:v1", wantErr: "not allowed"},
+		{ref: "attacker/agent:v1", wantErr: "docker.io/attacker/agent is not allowed"},
+		{ref: "gsoci.azurecr.io/giantswarm/klaus-experimental/agent:v1", wantErr: "denied"},
+		{ref: "GSOCI.azurecr.io:443/giantswarm/klaus-experimental/agent:v1", wantErr: "denied"},
+	}
+	// Short names expand to the registry base, here outside the allowed
+	// repositories.
+	for _, tt := range tests {
+		err := policy.Check(tt.ref, "gsoci.azurecr.io/giantswarm-evil/klaus-toolchains")
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Check(%q) unexpected error: %v", tt.ref, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Check(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+		}
+	}
+
+	var disabled *Policy
+	if err := disabled.Check("anything/at:all", "example.com"); err != nil {
+		t.Errorf("nil policy error = %v, want everything allowed", err)
+	}
+}
+
+func TestValidateInstanceSpec(t *testing.T) {
+	policy := &Policy{Allow: []string{"gsoci.azurecr.io/giantswarm"}}
+	spec := &klausv1alpha1.KlausInstanceSpec{
+		Personality: "go-dev",
+		Image:       "docker.io/library/golang:1.26",
+		Plugins: []klausv1alpha1.PluginReference{
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", Tag: "v1.0.0"},
+			{Repository: "ghcr.io/someone/plugin", Tag: "v1.0.0"},
+		},
+		InlinePersonality: &klausv1alpha1.InlinePersonality{
+			Plugins: []klausv1alpha1.PluginReference{{Repository: "gs-platform", Tag: "v1.0.0"}},
+		},
+	}
+	errs := policy.ValidateInstanceSpec(spec)
+	var fields []string
+	for _, err := range errs {
+		if err.Type != field.ErrorTypeForbidden {
+			t.Errorf("error type = %s, want Forbidden", err.Type)
+		}
+		fields = append(fields, err.Field)
+	}
+	if got := strings.Join(fields, ","); got != "spec.image,spec.plugins[1].repository" {
+		t.Errorf("forbidden fields = %s, want the image and the ghcr.io plugin", got)
+	}
+}
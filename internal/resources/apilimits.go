@@ -0,0 +1,73 @@
+package resources
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// APIMaxConcurrencyEnvVar, APIRequestsPerMinuteEnvVar and APIBurstEnvVar
+	// pass the instance's share of the owner's API limits to the agent.
+	APIMaxConcurrencyEnvVar    = "KLAUS_API_MAX_CONCURRENCY"
+	APIRequestsPerMinuteEnvVar = "KLAUS_API_REQUESTS_PER_MINUTE"
+	APIBurstEnvVar             = "KLAUS_API_BURST"
+)
+
+// APIRateLimitShare returns the share of the owner-wide limit each of the
+// owner's instances running against the Anthropic API gets, so instances
+// together stay within the budget. Every set limit is divided by instances
+// and rounded down, to at least 1: an owner with more instances than
+// requests per minute can exceed the budget.
+func APIRateLimitShare(limit *klausv1alpha1.APIRateLimit, instances int) *klausv1alpha1.APIRateLimit {
+	if limit == nil {
+		return nil
+	}
+	n := int32(max(instances, 1))
+	share := func(v int32) int32 {
+		if v <= 0 {
+			return 0
+		}
+		return max(v/n, 1)
+	}
+	return &klausv1alpha1.APIRateLimit{
+		MaxConcurrentRequests: share(limit.MaxConcurrentRequests),
+		RequestsPerMinute:     share(limit.RequestsPerMinute),
+		Burst:                 share(burst(limit)),
+	}
+}
+
+// ApplyAPIRateLimit passes an instance's API limits, its share of the
+// owner's budget, to the klaus container. Instances not using the Anthropic
+// API are left alone.
+func ApplyAPIRateLimit(podSpec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance, limit *klausv1alpha1.APIRateLimit) {
+	if limit == nil || !UsesAnthropicAPIKey(instance) {
+		return
+	}
+
+	var env []corev1.EnvVar
+	if limit.MaxConcurrentRequests > 0 {
+		env = append(env, corev1.EnvVar{Name: APIMaxConcurrencyEnvVar, Value: strconv.Itoa(int(limit.MaxConcurrentRequests))})
+	}
+	if limit.RequestsPerMinute > 0 {
+		env = append(env,
+			corev1.EnvVar{Name: APIRequestsPerMinuteEnvVar, Value: strconv.Itoa(int(limit.RequestsPerMinute))},
+			corev1.EnvVar{Name: APIBurstEnvVar, Value: strconv.Itoa(int(burst(limit)))},
+		)
+	}
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == AppKlaus {
+			podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, env...)
+		}
+	}
+}
+
+// burst returns the token bucket size, defaulting to the refill rate.
+func burst(limit *klausv1alpha1.APIRateLimit) int32 {
+	if limit.Burst > 0 {
+		return limit.Burst
+	}
+	return limit.RequestsPerMinute
+}
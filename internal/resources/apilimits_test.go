@@ -0,0 +1,91 @@
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func envValue(c *corev1.Container, name string) (string, bool) {
+	for _, e := range c.Env {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestAPIRateLimitShare(t *testing.T) {
+	limit := &klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 4, RequestsPerMinute: 60}
+	tests := []struct {
+		instances int
+		want      klausv1alpha1.APIRateLimit
+	}{
+		{instances: 1, want: klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 4, RequestsPerMinute: 60, Burst: 60}},
+		{instances: 3, want: klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 1, RequestsPerMinute: 20, Burst: 20}},
+		// The share is never computed over zero instances.
+		{instances: 0, want: klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 4, RequestsPerMinute: 60, Burst: 60}},
+		// Every instance keeps at least one request.
+		{instances: 8, want: klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 1, RequestsPerMinute: 7, Burst: 7}},
+	}
+	for _, tt := range tests {
+		if got := APIRateLimitShare(limit, tt.instances); *got != tt.want {
+			t.Errorf("APIRateLimitShare(%d) = %+v, want %+v", tt.instances, *got, tt.want)
+		}
+	}
+
+	// Unset limits stay unset.
+	if got := APIRateLimitShare(&klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 2}, 2); got.RequestsPerMinute != 0 || got.Burst != 0 {
+		t.Errorf("APIRateLimitShare() = %+v, want no rate", *got)
+	}
+	if APIRateLimitShare(nil, 2) != nil {
+		t.Error("APIRateLimitShare(nil) != nil")
+	}
+}
+
+func TestApplyAPIRateLimit_Env(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: AppKlaus}}}
+	limit := &klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 2, RequestsPerMinute: 30, Burst: 5}
+	ApplyAPIRateLimit(podSpec, &klausv1alpha1.KlausInstance{}, limit)
+
+	c := klausContainer(t, podSpec)
+	for name, want := range map[string]string{
+		APIMaxConcurrencyEnvVar:    "2",
+		APIRequestsPerMinuteEnvVar: "30",
+		APIBurstEnvVar:             "5",
+	} {
+		if got, _ := envValue(c, name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestApplyAPIRateLimit_NotApplied(t *testing.T) {
+	limit := &klausv1alpha1.APIRateLimit{MaxConcurrentRequests: 2}
+	tests := []struct {
+		name     string
+		instance *klausv1alpha1.KlausInstance
+		limit    *klausv1alpha1.APIRateLimit
+	}{
+		{name: "no limit", instance: &klausv1alpha1.KlausInstance{}},
+		{name: "mock mode", instance: &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{MockMode: true}}, limit: limit},
+		{
+			name: "bedrock",
+			instance: &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{
+				Claude: klausv1alpha1.ClaudeConfig{Provider: &klausv1alpha1.ProviderConfig{Type: klausv1alpha1.ProviderBedrock}},
+			}},
+			limit: limit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: AppKlaus}}}
+			ApplyAPIRateLimit(podSpec, tt.instance, tt.limit)
+			if len(podSpec.Containers[0].Env) != 0 {
+				t.Errorf("pod spec changed: %+v", podSpec)
+			}
+		})
+	}
+}
@@ -44,12 +44,19 @@ const (
 	// PluginBasePath is the base path for OCI plugin mounts.
 	PluginBasePath = "/var/lib/klaus/plugins"
 
+	// MCPServerArtifactBasePath is the base path for the OCI artifacts of
+	// stdio MCP servers.
+	MCPServerArtifactBasePath = "/var/lib/klaus/mcp-servers"
+
 	// PersonalityVolumeName is the volume name for the personality image volume.
 	PersonalityVolumeName = "personality"
 
 	// PersonalityMountPath is the mount path for the personality OCI artifact.
 	PersonalityMountPath = "/var/lib/klaus/personality"
 
+	// InlineSoulPath is the path to the SOUL.md of an inline personality.
+	InlineSoulPath = "/etc/klaus/SOUL.md"
+
 	// HookScriptsPath is the base path for hook scripts.
 	HookScriptsPath = "/etc/klaus/hooks"
 
@@ -76,6 +83,13 @@ const (
 	// the --git-clone-image flag.
 	DefaultGitCloneImage = "alpine/git:v2.47.2"
 
+	// GitCloneContainerName is the name of the git clone init container.
+	GitCloneContainerName = "git-clone"
+
+	// GitCloneTerminationMessagePath is where the git clone init container
+	// writes the commit it checked out.
+	GitCloneTerminationMessagePath = "/dev/termination-log"
+
 	// LabelAppName is the standard Kubernetes "app.kubernetes.io/name" label key.
 	LabelAppName = "app.kubernetes.io/name"
 
@@ -103,10 +117,28 @@ const (
 
 var sanitizeRegexp = regexp.MustCompile(`[^a-z0-9-]`)
 
+// UserNamespacePrefix is the name prefix of the per-owner namespaces.
+const UserNamespacePrefix = "klaus-user-"
+
 // UserNamespace returns the namespace name for a given owner.
 func UserNamespace(owner string) string {
 	// Prefix is 11 chars; namespace max is 63.
-	return "klaus-user-" + sanitizeIdentifier(owner, 50)
+	return UserNamespacePrefix + sanitizeIdentifier(owner, 50)
+}
+
+// InstanceNamespace returns the namespace of the child resources of an
+// instance: the owner's user namespace, or in namespace-scoped mode the
+// namespace of the instance itself.
+func InstanceNamespace(instance *klausv1alpha1.KlausInstance, namespaceScoped bool) string {
+	if namespaceScoped {
+		return instance.Namespace
+	}
+	return UserNamespace(instance.Spec.Owner)
+}
+
+// IsUserNamespace reports whether namespace is a per-owner namespace.
+func IsUserNamespace(namespace string) bool {
+	return strings.HasPrefix(namespace, UserNamespacePrefix)
 }
 
 // SelectorLabels returns the minimal label set used for pod selection by both
@@ -124,6 +156,9 @@ func InstanceLabels(instance *klausv1alpha1.KlausInstance) map[string]string {
 	labels := SelectorLabels(instance)
 	labels[LabelManagedBy] = AppKlausOperator
 	labels[LabelOwner] = sanitizeLabelValue(instance.Spec.Owner)
+	if instance.Spec.MockMode {
+		labels[LabelMock] = envValueTrue
+	}
 	return labels
 }
 
@@ -144,6 +179,11 @@ func ConfigMapName(instance *klausv1alpha1.KlausInstance) string {
 	return instance.Name + "-config"
 }
 
+// DeploymentName returns the Deployment name for an instance.
+func DeploymentName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name
+}
+
 // ServiceName returns the Service name for an instance.
 func ServiceName(instance *klausv1alpha1.KlausInstance) string {
 	return instance.Name
@@ -204,10 +244,10 @@ func ConfigMapChecksum(data map[string]string) string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
-// HasInlineExtensions returns true if the instance has skills or agent files
-// that need the extensions directory in CLAUDE_ADD_DIRS.
+// HasInlineExtensions returns true if the instance has skills, agent files or
+// commands that need the extensions directory in CLAUDE_ADD_DIRS.
 func HasInlineExtensions(instance *klausv1alpha1.KlausInstance) bool {
-	return len(instance.Spec.Skills) > 0 || len(instance.Spec.AgentFiles) > 0
+	return len(instance.Spec.Skills) > 0 || len(instance.Spec.AgentFiles) > 0 || len(instance.Spec.Commands) > 0
 }
 
 // NeedsScriptsVolume returns true if hook scripts need a separate executable volume.
@@ -233,14 +273,45 @@ func HasHooks(instance *klausv1alpha1.KlausInstance) bool {
 	return len(instance.Spec.Hooks) > 0
 }
 
+// HasSettings returns true if the instance configures hooks or other keys
+// rendered to the generated settings.json.
+func HasSettings(instance *klausv1alpha1.KlausInstance) bool {
+	return HasHooks(instance) || instance.Spec.Claude.OutputStyle != "" || instance.Spec.Claude.StatusLine != nil
+}
+
+// HasInlineSoul returns true if the instance has an inline personality with
+// a SOUL.md.
+func HasInlineSoul(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.InlinePersonality != nil && instance.Spec.InlinePersonality.Soul != ""
+}
+
 // NeedsGitClone returns true if the workspace has a git repo to clone.
 func NeedsGitClone(instance *klausv1alpha1.KlausInstance) bool {
 	return instance.Spec.Workspace != nil && instance.Spec.Workspace.GitRepo != ""
 }
 
-// NeedsGitSecret returns true if a git secret reference is configured for the workspace.
+// NeedsGitSecret returns true if a git secret reference or GitHub App
+// credentials are configured for the workspace or one of its repos.
 func NeedsGitSecret(instance *klausv1alpha1.KlausInstance) bool {
-	return instance.Spec.Workspace != nil && instance.Spec.Workspace.GitSecretRef != nil
+	ws := instance.Spec.Workspace
+	if ws == nil {
+		return false
+	}
+	if ws.GitSecretRef != nil || ws.GitHubApp != nil {
+		return true
+	}
+	for _, repo := range ws.Repos {
+		if repo.SecretRef != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesGitHubApp returns true if the workspace git credential is minted from
+// the operator's GitHub App rather than copied from a Secret.
+func UsesGitHubApp(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.Workspace != nil && instance.Spec.Workspace.GitHubApp != nil
 }
 
 // shellQuote wraps a value in POSIX single quotes for safe shell
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	klausoci "github.com/giantswarm/klaus-oci"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
@@ -56,6 +57,19 @@ func TestUserNamespace(t *testing.T) {
 	}
 }
 
+func TestInstanceNamespace(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	if got := InstanceNamespace(instance, false); got != "klaus-user-user-example-com" {
+		t.Errorf("InstanceNamespace() = %q, want the user namespace", got)
+	}
+	if got := InstanceNamespace(instance, true); got != "klaus-system" {
+		t.Errorf("InstanceNamespace() in namespace-scoped mode = %q, want the instance namespace", got)
+	}
+}
+
 func TestShortName(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -178,6 +192,17 @@ func TestHasInlineExtensions(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "with commands",
+			instance: &klausv1alpha1.KlausInstance{
+				Spec: klausv1alpha1.KlausInstanceSpec{
+					Commands: map[string]klausv1alpha1.CommandConfig{
+						"test": {Content: "test content"},
+					},
+				},
+			},
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,3 +214,16 @@ func TestHasInlineExtensions(t *testing.T) {
 		})
 	}
 }
+
+func TestOwnerAPIKeySecretName(t *testing.T) {
+	name := OwnerAPIKeySecretName("anthropic-api-key", "User@Example.com")
+	if name != OwnerAPIKeySecretName("anthropic-api-key", "user@example.com") {
+		t.Error("expected owner hash to be case-insensitive")
+	}
+	if len(name) != len("anthropic-api-key-")+ownerHashLength {
+		t.Errorf("unexpected secret name length: %q", name)
+	}
+	if name == OwnerAPIKeySecretName("anthropic-api-key", "other@example.com") {
+		t.Error("expected different owners to produce different secret names")
+	}
+}
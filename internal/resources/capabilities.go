@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"slices"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// Agent feature names reported by the klaus /capabilities endpoint.
+const (
+	// FeaturePersistentMode indicates support for chat mode, where the agent
+	// keeps a persistent process and saves sessions.
+	FeaturePersistentMode = "persistent-mode"
+
+	// FeaturePartialMessages indicates support for streaming partial messages.
+	FeaturePartialMessages = "partial-messages"
+
+	// FeatureStructuredOutput indicates support for JSON schema constrained output.
+	FeatureStructuredOutput = "structured-output"
+
+	// FeatureGracefulShutdown indicates the agent serves DrainPath, finishing
+	// or checkpointing its current turn before it stops.
+	FeatureGracefulShutdown = "graceful-shutdown"
+)
+
+// RequiredFeatures returns the agent features the instance spec depends on,
+// in a stable order.
+func RequiredFeatures(instance *klausv1alpha1.KlausInstance) []string {
+	var features []string
+	if IsPersistent(instance) {
+		features = append(features, FeaturePersistentMode)
+	}
+	if instance.Spec.Claude.IncludePartialMessages != nil && *instance.Spec.Claude.IncludePartialMessages {
+		features = append(features, FeaturePartialMessages)
+	}
+	if instance.Spec.Claude.JSONSchema != "" {
+		features = append(features, FeatureStructuredOutput)
+	}
+	return features
+}
+
+// UnsupportedFeatures returns the features required by the instance spec that
+// are not listed in the observed agent capabilities. Returns nil when the
+// capabilities are unknown, since older agents do not expose the endpoint and
+// absence of data must not be treated as absence of support.
+func UnsupportedFeatures(instance *klausv1alpha1.KlausInstance, caps *klausv1alpha1.AgentCapabilities) []string {
+	if caps == nil {
+		return nil
+	}
+	var missing []string
+	for _, f := range RequiredFeatures(instance) {
+		if !slices.Contains(caps.Features, f) {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
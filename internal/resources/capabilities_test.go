@@ -0,0 +1,56 @@
+package resources
+
+import (
+	"slices"
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestRequiredFeatures(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Claude: klausv1alpha1.ClaudeConfig{
+				Mode:                   ptr.To(klausv1alpha1.ModeChat),
+				IncludePartialMessages: ptr.To(true),
+				JSONSchema:             `{"type":"object"}`,
+			},
+		},
+	}
+
+	got := RequiredFeatures(instance)
+	want := []string{FeaturePersistentMode, FeaturePartialMessages, FeatureStructuredOutput}
+	if !slices.Equal(got, want) {
+		t.Errorf("RequiredFeatures() = %v, want %v", got, want)
+	}
+}
+
+func TestRequiredFeatures_Defaults(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{}
+	if got := RequiredFeatures(instance); len(got) != 0 {
+		t.Errorf("RequiredFeatures() = %v, want none", got)
+	}
+}
+
+func TestUnsupportedFeatures(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Claude: klausv1alpha1.ClaudeConfig{
+				Mode:       ptr.To(klausv1alpha1.ModeChat),
+				JSONSchema: `{"type":"object"}`,
+			},
+		},
+	}
+
+	caps := &klausv1alpha1.AgentCapabilities{Features: []string{FeaturePersistentMode}}
+	got := UnsupportedFeatures(instance, caps)
+	if !slices.Equal(got, []string{FeatureStructuredOutput}) {
+		t.Errorf("UnsupportedFeatures() = %v, want [%s]", got, FeatureStructuredOutput)
+	}
+
+	if got := UnsupportedFeatures(instance, nil); got != nil {
+		t.Errorf("UnsupportedFeatures(nil caps) = %v, want nil", got)
+	}
+}
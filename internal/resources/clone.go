@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// ClonesWorkspace reports whether the workspace PVC of the instance is
+// provisioned as a copy of the workspace of its spec.cloneFrom instance.
+func ClonesWorkspace(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.Workspace != nil && instance.Spec.CloneFrom != nil && instance.Spec.CloneFrom.Workspace
+}
+
+// validateCloneFrom ensures that spec.cloneFrom names another instance and
+// that a cloned workspace has a workspace to clone into.
+func validateCloneFrom(instance *klausv1alpha1.KlausInstance) error {
+	clone := instance.Spec.CloneFrom
+	if clone == nil {
+		return nil
+	}
+	if clone.Name == "" || clone.Name == instance.Name {
+		return fmt.Errorf("spec.cloneFrom.name must name another instance")
+	}
+	if clone.Workspace && instance.Spec.Workspace == nil {
+		return fmt.Errorf("spec.cloneFrom.workspace requires spec.workspace to be set")
+	}
+	return nil
+}
+
+// SetWorkspaceDataSource makes the workspace PVC of a clone a CSI volume clone
+// of source. Clones must request at least the size of the source and use its
+// StorageClass; a PVC without a StorageClass inherits it.
+func SetWorkspaceDataSource(pvc, source *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName == nil {
+		pvc.Spec.StorageClassName = source.Spec.StorageClassName
+	} else if source.Spec.StorageClassName == nil || *source.Spec.StorageClassName != *pvc.Spec.StorageClassName {
+		return fmt.Errorf("workspace PVC %s of the clone source uses StorageClass %q, but spec.workspace.storageClass is %q; volume clones keep the StorageClass",
+			source.Name, storageClassName(source), *pvc.Spec.StorageClassName)
+	}
+
+	size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	for _, sourceSize := range []corev1.ResourceList{source.Spec.Resources.Requests, source.Status.Capacity} {
+		if s, ok := sourceSize[corev1.ResourceStorage]; ok && s.Cmp(size) > 0 {
+			size = s
+		}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+		Kind: "PersistentVolumeClaim",
+		Name: source.Name,
+	}
+	return nil
+}
+
+func storageClassName(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
@@ -0,0 +1,120 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestValidateCloneFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		clone     *klausv1alpha1.CloneSource
+		workspace bool
+		wantErr   string
+	}{
+		{name: "not cloned"},
+		{name: "spec only", clone: &klausv1alpha1.CloneSource{Name: "source"}},
+		{name: "workspace", clone: &klausv1alpha1.CloneSource{Name: "source", Workspace: true}, workspace: true},
+		{name: "itself", clone: &klausv1alpha1.CloneSource{Name: "test"}, wantErr: "another instance"},
+		{name: "workspace without workspace", clone: &klausv1alpha1.CloneSource{Name: "source", Workspace: true}, wantErr: "requires spec.workspace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := soulInstance()
+			instance.Spec.CloneFrom = tt.clone
+			if tt.workspace {
+				instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{}
+			}
+			err := validateCloneFrom(instance)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateCloneFrom() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateCloneFrom() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClonesWorkspace(t *testing.T) {
+	instance := soulInstance()
+	instance.Spec.CloneFrom = &klausv1alpha1.CloneSource{Name: "source", Workspace: true}
+	if ClonesWorkspace(instance) {
+		t.Error("ClonesWorkspace() = true without spec.workspace")
+	}
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{}
+	if !ClonesWorkspace(instance) {
+		t.Error("ClonesWorkspace() = false, want true")
+	}
+	instance.Spec.CloneFrom.Workspace = false
+	if ClonesWorkspace(instance) {
+		t.Error("ClonesWorkspace() = true for a spec-only clone")
+	}
+}
+
+func clonePVCs(storageClass, sourceStorageClass *string) (*corev1.PersistentVolumeClaim, *corev1.PersistentVolumeClaim) {
+	instance := soulInstance()
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{Size: ptr.To(resource.MustParse("5Gi"))}
+	if storageClass != nil {
+		instance.Spec.Workspace.StorageClass = *storageClass
+	}
+	pvc := BuildPVC(instance, "user-ns")
+
+	source := &corev1.PersistentVolumeClaim{}
+	source.Name = "source-workspace"
+	source.Spec.StorageClassName = sourceStorageClass
+	source.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("8Gi")}
+	source.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}
+	return pvc, source
+}
+
+func TestSetWorkspaceDataSource(t *testing.T) {
+	pvc, source := clonePVCs(nil, ptr.To("csi-fast"))
+
+	if err := SetWorkspaceDataSource(pvc, source); err != nil {
+		t.Fatalf("SetWorkspaceDataSource() error = %v", err)
+	}
+	if ds := pvc.Spec.DataSource; ds == nil || ds.Kind != "PersistentVolumeClaim" || ds.Name != "source-workspace" || ds.APIGroup != nil {
+		t.Errorf("dataSource = %+v, want the source PVC", ds)
+	}
+	if sc := pvc.Spec.StorageClassName; sc == nil || *sc != "csi-fast" {
+		t.Errorf("storageClassName = %v, want the StorageClass of the source", sc)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.Cmp(resource.MustParse("10Gi")) != 0 {
+		t.Errorf("storage request = %s, want the 10Gi capacity of the source", size.String())
+	}
+}
+
+func TestSetWorkspaceDataSource_KeepsLargerSize(t *testing.T) {
+	pvc, source := clonePVCs(ptr.To("csi-fast"), ptr.To("csi-fast"))
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("20Gi")
+
+	if err := SetWorkspaceDataSource(pvc, source); err != nil {
+		t.Fatalf("SetWorkspaceDataSource() error = %v", err)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.Cmp(resource.MustParse("20Gi")) != 0 {
+		t.Errorf("storage request = %s, want 20Gi", size.String())
+	}
+}
+
+func TestSetWorkspaceDataSource_StorageClassMismatch(t *testing.T) {
+	pvc, source := clonePVCs(ptr.To("csi-slow"), ptr.To("csi-fast"))
+
+	err := SetWorkspaceDataSource(pvc, source)
+	if err == nil || !strings.Contains(err.Error(), `StorageClass "csi-fast"`) {
+		t.Errorf("SetWorkspaceDataSource() error = %v, want a StorageClass mismatch", err)
+	}
+	if pvc.Spec.DataSource != nil {
+		t.Errorf("dataSource = %+v, want none on error", pvc.Spec.DataSource)
+	}
+}
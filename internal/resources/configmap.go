@@ -17,18 +17,23 @@ import (
 
 // BuildConfigMap creates the ConfigMap for a KlausInstance, containing all
 // configuration data: system prompts, MCP config, skills, agent files, hooks,
-// hook scripts, agents JSON, and JSON schema.
+// hook scripts, agents JSON, and JSON schema. The template values of the
+// instance are substituted in the prompts, skills, commands, memory and
+// soul. It fails when a single key
+// exceeds the size limit of a ConfigMap; SplitConfigMap splits content
+// exceeding it in total.
 func BuildConfigMap(instance *klausv1alpha1.KlausInstance, namespace string) (*corev1.ConfigMap, error) {
 	data := make(map[string]string)
+	values := templateValues(instance)
 
 	// System prompt.
 	if instance.Spec.Claude.SystemPrompt != "" {
-		data["system-prompt"] = instance.Spec.Claude.SystemPrompt
+		data["system-prompt"] = expandTemplate(instance.Spec.Claude.SystemPrompt, values)
 	}
 
 	// Append system prompt.
 	if instance.Spec.Claude.AppendSystemPrompt != "" {
-		data["append-system-prompt"] = instance.Spec.Claude.AppendSystemPrompt
+		data["append-system-prompt"] = expandTemplate(instance.Spec.Claude.AppendSystemPrompt, values)
 	}
 
 	// MCP config (inline mcpServers rendered as {"mcpServers": {...}}).
@@ -57,6 +62,8 @@ func BuildConfigMap(instance *klausv1alpha1.KlausInstance, namespace string) (*c
 	// Skills (SKILL.md with YAML frontmatter).
 	for _, name := range slices.Sorted(maps.Keys(instance.Spec.Skills)) {
 		skill := instance.Spec.Skills[name]
+		skill.Description = expandTemplate(skill.Description, values)
+		skill.Content = expandTemplate(skill.Content, values)
 		data["skill-"+name] = renderSkillMD(skill)
 	}
 
@@ -66,13 +73,36 @@ func BuildConfigMap(instance *klausv1alpha1.KlausInstance, namespace string) (*c
 		data["agentfile-"+name] = agentFile.Content
 	}
 
-	// Hooks (rendered to settings.json).
-	if HasHooks(instance) {
-		hooksJSON, err := marshalRawExtensionMap(instance.Spec.Hooks, "hooks")
+	// Commands (command files with YAML frontmatter).
+	for _, name := range slices.Sorted(maps.Keys(instance.Spec.Commands)) {
+		command := instance.Spec.Commands[name]
+		command.Description = expandTemplate(command.Description, values)
+		command.Content = expandTemplate(command.Content, values)
+		data["command-"+name] = renderCommandMD(command)
+	}
+
+	// Inline personality SOUL.md.
+	var soul string
+	if HasInlineSoul(instance) {
+		soul = expandTemplate(instance.Spec.InlinePersonality.Soul, values)
+		data[SoulConfigKey] = soul
+	}
+
+	// Memory (CLAUDE.md of the extensions directory and the workspace).
+	if memory := renderMemory(soul, expandTemplate(memoryContent(instance), values)); memory != "" {
+		data[MemoryConfigKey] = memory
+	}
+	for i, p := range memoryFilePaths(instance) {
+		data[memoryFileConfigKey(i)] = expandTemplate(instance.Spec.Memory.Files[p], values)
+	}
+
+	// Hooks, output style and status line (rendered to settings.json).
+	if HasSettings(instance) {
+		settingsJSON, err := buildSettingsJSON(instance)
 		if err != nil {
-			return nil, fmt.Errorf("building hooks JSON: %w", err)
+			return nil, fmt.Errorf("building settings JSON: %w", err)
 		}
-		data["settings.json"] = hooksJSON
+		data["settings.json"] = settingsJSON
 	}
 
 	// Hook scripts.
@@ -80,6 +110,10 @@ func BuildConfigMap(instance *klausv1alpha1.KlausInstance, namespace string) (*c
 		data["hookscript-"+name] = instance.Spec.HookScripts[name]
 	}
 
+	if err := checkConfigSize(data); err != nil {
+		return nil, err
+	}
+
 	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ConfigMapName(instance),
@@ -92,6 +126,66 @@ func BuildConfigMap(instance *klausv1alpha1.KlausInstance, namespace string) (*c
 	return cm, nil
 }
 
+// renderCommandMD renders a slash command file. Its frontmatter uses the
+// kebab-case keys Claude Code reads from command files.
+func renderCommandMD(command klausv1alpha1.CommandConfig) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+
+	if command.Description != "" {
+		fmt.Fprintf(&b, "description: %q\n", command.Description)
+	}
+	if len(command.AllowedTools) > 0 {
+		fmt.Fprintf(&b, "allowed-tools: %q\n", strings.Join(command.AllowedTools, ","))
+	}
+	if command.ArgumentHint != "" {
+		fmt.Fprintf(&b, "argument-hint: %q\n", command.ArgumentHint)
+	}
+	if command.Model != "" {
+		fmt.Fprintf(&b, "model: %q\n", command.Model)
+	}
+	if command.DisableModelInvocation != nil {
+		fmt.Fprintf(&b, "disable-model-invocation: %t\n", *command.DisableModelInvocation)
+	}
+
+	b.WriteString("---\n")
+	b.WriteString(command.Content)
+	if !strings.HasSuffix(command.Content, "\n") {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// buildSettingsJSON renders the settings.json of the instance: its hooks,
+// output style and status line under the Claude Code settings keys.
+func buildSettingsJSON(instance *klausv1alpha1.KlausInstance) (string, error) {
+	settings := map[string]any{}
+	if HasHooks(instance) {
+		hooks := make(map[string]json.RawMessage, len(instance.Spec.Hooks))
+		for name, ext := range instance.Spec.Hooks {
+			hooks[name] = json.RawMessage(ext.Raw)
+		}
+		settings["hooks"] = hooks
+	}
+	if instance.Spec.Claude.OutputStyle != "" {
+		settings["outputStyle"] = instance.Spec.Claude.OutputStyle
+	}
+	if statusLine := instance.Spec.Claude.StatusLine; statusLine != nil {
+		rendered := *statusLine
+		if rendered.Type == "" {
+			rendered.Type = klausv1alpha1.StatusLineTypeCommand
+		}
+		settings["statusLine"] = rendered
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
 // marshalRawExtensionMap converts a map of RawExtensions to a JSON string.
 // If wrapperKey is non-empty, the map is wrapped under that key; otherwise
 // it is serialized directly.
@@ -2,10 +2,12 @@ package resources
 
 import (
 	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
@@ -103,6 +105,38 @@ func TestBuildConfigMap_Skills(t *testing.T) {
 	}
 }
 
+func TestBuildConfigMap_Commands(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Commands: map[string]klausv1alpha1.CommandConfig{
+				"release": {
+					Description:  "Cut a release",
+					Content:      "Tag release $ARGUMENTS.",
+					AllowedTools: []string{"Bash(git tag:*)", "Read"},
+					ArgumentHint: "<version>",
+				},
+			},
+		},
+	}
+	instance.Name = "test-instance"
+
+	cm, err := BuildConfigMap(instance, "test-ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "---\n" +
+		"description: \"Cut a release\"\n" +
+		"allowed-tools: \"Bash(git tag:*),Read\"\n" +
+		"argument-hint: \"<version>\"\n" +
+		"---\n" +
+		"Tag release $ARGUMENTS.\n"
+	if got := cm.Data["command-release"]; got != want {
+		t.Errorf("command-release = %q, want %q", got, want)
+	}
+}
+
 func TestBuildConfigMap_Hooks(t *testing.T) {
 	instance := &klausv1alpha1.KlausInstance{
 		Spec: klausv1alpha1.KlausInstanceSpec{
@@ -130,6 +164,39 @@ func TestBuildConfigMap_Hooks(t *testing.T) {
 	}
 }
 
+func TestBuildConfigMap_OutputStyleAndStatusLine(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Claude: klausv1alpha1.ClaudeConfig{
+				OutputStyle: "Explanatory",
+				StatusLine:  &klausv1alpha1.StatusLineConfig{Command: "/etc/klaus/hooks/statusline.sh", Padding: ptr.To(0)},
+			},
+		},
+	}
+	instance.Name = "test-instance"
+
+	cm, err := BuildConfigMap(instance, "test-ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal([]byte(cm.Data["settings.json"]), &settings); err != nil {
+		t.Fatalf("settings.json is not valid JSON: %v", err)
+	}
+	if settings["outputStyle"] != "Explanatory" {
+		t.Errorf("outputStyle = %v, want Explanatory", settings["outputStyle"])
+	}
+	want := map[string]any{"type": "command", "command": "/etc/klaus/hooks/statusline.sh", "padding": float64(0)}
+	if !reflect.DeepEqual(settings["statusLine"], want) {
+		t.Errorf("statusLine = %v, want %v", settings["statusLine"], want)
+	}
+	if _, ok := settings["hooks"]; ok {
+		t.Error("settings.json should not contain hooks without spec.hooks")
+	}
+}
+
 func TestBuildConfigMap_Empty(t *testing.T) {
 	instance := &klausv1alpha1.KlausInstance{
 		Spec: klausv1alpha1.KlausInstanceSpec{
@@ -147,3 +214,22 @@ func TestBuildConfigMap_Empty(t *testing.T) {
 		t.Errorf("expected empty ConfigMap data for empty spec, got %d keys", len(cm.Data))
 	}
 }
+
+func TestBuildConfigMap_InlineSoul(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:             "test@example.com",
+			InlinePersonality: &klausv1alpha1.InlinePersonality{Soul: "You are a careful Go developer."},
+		},
+	}
+	instance.Name = "test-instance"
+
+	cm, err := BuildConfigMap(instance, "test-ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cm.Data["soul"] != "You are a careful Go developer." {
+		t.Errorf("expected soul in ConfigMap data, got: %q", cm.Data["soul"])
+	}
+}
@@ -0,0 +1,190 @@
+package resources
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// ConfigMapDataLimit is the maximum total size in bytes of the values of a
+// ConfigMap accepted by the API server.
+const ConfigMapDataLimit = corev1.MaxSecretSize
+
+// maxReportedConfigKeys bounds the keys listed in a configuration size
+// error.
+const maxReportedConfigKeys = 5
+
+// ConfigMapShardName returns the name of the ith ConfigMap holding the
+// configuration of an instance. The first one is ConfigMapName, content
+// overflowing it is split into numbered ConfigMaps.
+func ConfigMapShardName(instance *klausv1alpha1.KlausInstance, i int) string {
+	if i == 0 {
+		return ConfigMapName(instance)
+	}
+	return ConfigMapName(instance) + "-" + strconv.Itoa(i)
+}
+
+func configShardVolumeName(volume string, i int) string {
+	if i == 0 {
+		return volume
+	}
+	return volume + "-" + strconv.Itoa(i)
+}
+
+// checkConfigSize returns an error listing the largest keys when a single
+// key of the configuration does not fit into a ConfigMap. Content exceeding
+// a ConfigMap in total is split by ConfigMapShards instead.
+func checkConfigSize(data map[string]string) error {
+	var oversized []string
+	for key, value := range data {
+		if len(value) > ConfigMapDataLimit {
+			oversized = append(oversized, key)
+		}
+	}
+	if len(oversized) == 0 {
+		return nil
+	}
+	slices.Sort(oversized)
+
+	keys := slices.SortedFunc(maps.Keys(data), func(a, b string) int {
+		if len(data[a]) != len(data[b]) {
+			return len(data[b]) - len(data[a])
+		}
+		return strings.Compare(a, b)
+	})
+	sizes := make([]string, 0, maxReportedConfigKeys)
+	for _, key := range keys[:min(len(keys), maxReportedConfigKeys)] {
+		sizes = append(sizes, fmt.Sprintf("%s %d", key, len(data[key])))
+	}
+	return fmt.Errorf("configuration %s exceeds the %d byte limit of a ConfigMap (largest keys in bytes: %s)",
+		strings.Join(oversized, ", "), ConfigMapDataLimit, strings.Join(sizes, ", "))
+}
+
+// ConfigMapShards splits the configuration into the data of as few
+// ConfigMaps as hold it. Keys are assigned in sorted order to the first
+// ConfigMap with room left, so the split only depends on the data. Keys
+// must fit into a single ConfigMap, see checkConfigSize.
+func ConfigMapShards(data map[string]string) []map[string]string {
+	shards := []map[string]string{{}}
+	sizes := []int{0}
+	for _, key := range slices.Sorted(maps.Keys(data)) {
+		value := data[key]
+		i := slices.IndexFunc(sizes, func(size int) bool { return size+len(value) <= ConfigMapDataLimit })
+		if i < 0 {
+			shards = append(shards, map[string]string{})
+			sizes = append(sizes, 0)
+			i = len(shards) - 1
+		}
+		shards[i][key] = value
+		sizes[i] += len(value)
+	}
+	return shards
+}
+
+// SplitConfigMap returns the ConfigMaps holding the configuration of cm,
+// cm itself when it fits into one. The first ConfigMap keeps the name of cm.
+func SplitConfigMap(instance *klausv1alpha1.KlausInstance, cm *corev1.ConfigMap) []*corev1.ConfigMap {
+	shards := ConfigMapShards(cm.Data)
+	if len(shards) == 1 {
+		return []*corev1.ConfigMap{cm}
+	}
+	cms := make([]*corev1.ConfigMap, 0, len(shards))
+	for i, data := range shards {
+		shard := cm.DeepCopy()
+		shard.Name = ConfigMapShardName(instance, i)
+		shard.Data = data
+		cms = append(cms, shard)
+	}
+	return cms
+}
+
+// configShardIndex returns the ConfigMap each key of the configuration is
+// stored in, by its index in ConfigMapShards.
+func configShardIndex(data map[string]string) map[string]int {
+	index := map[string]int{}
+	for i, shard := range ConfigMapShards(data) {
+		for key := range shard {
+			index[key] = i
+		}
+	}
+	return index
+}
+
+// configVolumeForKey returns the name of the config volume mounting the
+// given key of the configuration.
+func configVolumeForKey(data map[string]string, key string) string {
+	return configShardVolumeName(ConfigVolumeName, configShardIndex(data)[key])
+}
+
+// applyConfigShards points the env vars, volumes and mounts of the pod that
+// reference keys moved to further ConfigMaps by ConfigMapShards at those
+// ConfigMaps. Pods of instances whose configuration fits into one ConfigMap
+// are left unchanged.
+func applyConfigShards(podSpec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance, data map[string]string) {
+	shardCount := len(ConfigMapShards(data))
+	if shardCount <= 1 {
+		return
+	}
+	index := configShardIndex(data)
+	primary := ConfigMapName(instance)
+
+	for i := 1; i < shardCount; i++ {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: configShardVolumeName(ConfigVolumeName, i),
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ConfigMapShardName(instance, i)},
+			}},
+		})
+	}
+	splitScriptsVolume(podSpec, instance, index)
+
+	retarget := func(c *corev1.Container) {
+		for j := range c.Env {
+			ref := c.Env[j].ValueFrom
+			if ref != nil && ref.ConfigMapKeyRef != nil && ref.ConfigMapKeyRef.Name == primary {
+				ref.ConfigMapKeyRef.Name = ConfigMapShardName(instance, index[ref.ConfigMapKeyRef.Key])
+			}
+		}
+		for j := range c.VolumeMounts {
+			m := &c.VolumeMounts[j]
+			if m.Name == ConfigVolumeName || m.Name == ConfigScriptsVolumeName {
+				m.Name = configShardVolumeName(m.Name, index[m.SubPath])
+			}
+		}
+	}
+	for i := range podSpec.InitContainers {
+		retarget(&podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		retarget(&podSpec.Containers[i])
+	}
+}
+
+// splitScriptsVolume splits the executable hook scripts volume into one
+// volume per ConfigMap holding scripts.
+func splitScriptsVolume(podSpec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance, index map[string]int) {
+	i := slices.IndexFunc(podSpec.Volumes, func(v corev1.Volume) bool { return v.Name == ConfigScriptsVolumeName })
+	if i < 0 {
+		return
+	}
+	scripts := podSpec.Volumes[i]
+	items := map[int][]corev1.KeyToPath{}
+	for _, item := range scripts.ConfigMap.Items {
+		items[index[item.Key]] = append(items[index[item.Key]], item)
+	}
+
+	podSpec.Volumes = slices.Delete(podSpec.Volumes, i, i+1)
+	for _, shard := range slices.Sorted(maps.Keys(items)) {
+		volume := *scripts.DeepCopy()
+		volume.Name = configShardVolumeName(ConfigScriptsVolumeName, shard)
+		volume.ConfigMap.Name = ConfigMapShardName(instance, shard)
+		volume.ConfigMap.Items = items[shard]
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+	}
+}
@@ -0,0 +1,133 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const kib = 1024
+
+// largeConfigInstance returns an instance whose configuration exceeds one
+// ConfigMap: the hook script and skill fill the first, the system prompt
+// moves to a second.
+func largeConfigInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:       "test@example.com",
+			Claude:      klausv1alpha1.ClaudeConfig{SystemPrompt: strings.Repeat("p", 400*kib)},
+			HookScripts: map[string]string{"guard.sh": strings.Repeat("s", 400*kib)},
+			Skills:      map[string]klausv1alpha1.SkillConfig{"a": {Content: strings.Repeat("k", 400*kib)}},
+		},
+	}
+}
+
+func TestConfigMapShards(t *testing.T) {
+	shards := ConfigMapShards(map[string]string{
+		"a": strings.Repeat("a", 600*kib),
+		"b": strings.Repeat("b", 600*kib),
+		"c": strings.Repeat("c", 300*kib),
+	})
+	if len(shards) != 2 {
+		t.Fatalf("got %d shards, want 2", len(shards))
+	}
+	if _, ok := shards[0]["c"]; !ok || len(shards[0]) != 2 {
+		t.Errorf("first shard keys = %d, want a and c", len(shards[0]))
+	}
+	if _, ok := shards[1]["b"]; !ok {
+		t.Errorf("second shard does not hold b")
+	}
+
+	if shards := ConfigMapShards(map[string]string{"a": "small"}); len(shards) != 1 {
+		t.Errorf("got %d shards for small content, want 1", len(shards))
+	}
+}
+
+func TestBuildConfigMap_KeyTooLarge(t *testing.T) {
+	instance := largeConfigInstance()
+	instance.Spec.AgentFiles = map[string]klausv1alpha1.AgentFileConfig{"huge": {Content: strings.Repeat("x", ConfigMapDataLimit+1)}}
+
+	_, err := BuildConfigMap(instance, "test-ns")
+	if err == nil {
+		t.Fatal("expected an error for a key larger than a ConfigMap")
+	}
+	for _, want := range []string{"configuration agentfile-huge exceeds", "agentfile-huge 1048577", "system-prompt 409600"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if err := ValidateSpec(instance); err == nil || !strings.Contains(err.Error(), "agentfile-huge") {
+		t.Errorf("ValidateSpec() = %v, want the size error", err)
+	}
+}
+
+func TestSplitConfigMap(t *testing.T) {
+	instance := largeConfigInstance()
+	cm, err := BuildConfigMap(instance, "test-ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cms := SplitConfigMap(instance, cm)
+	if len(cms) != 2 || cms[0].Name != "test-config" || cms[1].Name != "test-config-1" {
+		t.Fatalf("got %d ConfigMaps, want test-config and test-config-1", len(cms))
+	}
+	if _, ok := cms[1].Data["system-prompt"]; !ok || len(cms[1].Data) != 1 {
+		t.Errorf("second ConfigMap keys = %d, want only the system prompt", len(cms[1].Data))
+	}
+	if cms[1].Labels["app.kubernetes.io/instance"] != cm.Labels["app.kubernetes.io/instance"] {
+		t.Errorf("labels = %v, want those of the first ConfigMap", cms[1].Labels)
+	}
+
+	small := &corev1.ConfigMap{Data: map[string]string{"a": "b"}}
+	if cms := SplitConfigMap(instance, small); len(cms) != 1 || cms[0] != small {
+		t.Errorf("SplitConfigMap() of small content = %v, want it unchanged", cms)
+	}
+}
+
+func TestBuildPodTemplate_ConfigShards(t *testing.T) {
+	instance := largeConfigInstance()
+	// The agent file fills the first ConfigMap, moving the hook script and
+	// skill to the second and the system prompt to the third.
+	instance.Spec.AgentFiles = map[string]klausv1alpha1.AgentFileConfig{"reviewer": {Content: strings.Repeat("r", 900*kib)}}
+	cm, err := BuildConfigMap(instance, "test-ns")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tmpl := BuildPodTemplate(instance, "klaus:latest", "", cm.Data)
+
+	volumes := map[string]corev1.Volume{}
+	for _, v := range tmpl.Spec.Volumes {
+		volumes[v.Name] = v
+	}
+	for name, configMap := range map[string]string{"config": "test-config", "config-1": "test-config-1", "config-2": "test-config-2"} {
+		if v, ok := volumes[name]; !ok || v.ConfigMap.Name != configMap {
+			t.Errorf("volume %s = %+v, want %s", name, v, configMap)
+		}
+	}
+	if _, ok := volumes[ConfigScriptsVolumeName]; ok {
+		t.Errorf("scripts volume of the first ConfigMap kept without scripts")
+	}
+	if v, ok := volumes["config-scripts-1"]; !ok || v.ConfigMap.Name != "test-config-1" || len(v.ConfigMap.Items) != 1 {
+		t.Errorf("volume config-scripts-1 = %+v, want the hook script of test-config-1", v)
+	}
+
+	c := tmpl.Spec.Containers[0]
+	want := map[string]string{"agentfile-reviewer": "config", "skill-a": "config-1", "hookscript-guard.sh": "config-scripts-1"}
+	for _, m := range c.VolumeMounts {
+		if volume, ok := want[m.SubPath]; ok && m.Name != volume {
+			t.Errorf("%s mounted from %s, want %s", m.SubPath, m.Name, volume)
+		}
+	}
+	for _, env := range c.Env {
+		if env.Name == "CLAUDE_SYSTEM_PROMPT" && env.ValueFrom.ConfigMapKeyRef.Name != "test-config-2" {
+			t.Errorf("system prompt read from %s, want test-config-2", env.ValueFrom.ConfigMapKeyRef.Name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// LabelCronJob identifies KlausJobs created by a KlausCronJob.
+	LabelCronJob = "klaus.giantswarm.io/cronjob"
+
+	// AnnotationScheduledTime records the schedule activation a KlausJob
+	// was created for, in RFC 3339 format.
+	AnnotationScheduledTime = "klaus.giantswarm.io/scheduled-at"
+)
+
+// CronJobRunName returns the deterministic KlausJob name for a scheduled
+// run, so that a retried reconcile never creates the same run twice.
+func CronJobRunName(cron *klausv1alpha1.KlausCronJob, scheduled time.Time) string {
+	return fmt.Sprintf("%s-%d", cron.Name, scheduled.Unix()/60)
+}
+
+// BuildCronJobRun renders the KlausJob for a scheduled run of a KlausCronJob.
+// The job lives in the cron job's namespace and carries its labels so the
+// history can be listed and pruned.
+func BuildCronJobRun(cron *klausv1alpha1.KlausCronJob, scheduled time.Time) *klausv1alpha1.KlausJob {
+	return &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CronJobRunName(cron, scheduled),
+			Namespace: cron.Namespace,
+			Labels: map[string]string{
+				LabelManagedBy: AppKlausOperator,
+				LabelCronJob:   cron.Name,
+			},
+			Annotations: map[string]string{
+				AnnotationScheduledTime: scheduled.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: *cron.Spec.JobTemplate.DeepCopy(),
+	}
+}
@@ -0,0 +1,62 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestBuildCronJobRun(t *testing.T) {
+	cron := &klausv1alpha1.KlausCronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausCronJobSpec{
+			Schedule: "0 2 * * *",
+			JobTemplate: klausv1alpha1.KlausJobSpec{
+				Owner:  "user@example.com",
+				Prompt: "Triage new issues",
+			},
+		},
+	}
+	scheduled := time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)
+
+	run := BuildCronJobRun(cron, scheduled)
+
+	if want := "nightly-29557560"; run.Name != want {
+		t.Errorf("name = %q, want %q", run.Name, want)
+	}
+	if run.Namespace != "klaus-system" {
+		t.Errorf("namespace = %q", run.Namespace)
+	}
+	if run.Labels[LabelCronJob] != "nightly" {
+		t.Errorf("cronjob label = %q", run.Labels[LabelCronJob])
+	}
+	if run.Annotations[AnnotationScheduledTime] != "2026-03-14T02:00:00Z" {
+		t.Errorf("scheduled-at = %q", run.Annotations[AnnotationScheduledTime])
+	}
+	if run.Spec.Prompt != "Triage new issues" || run.Spec.Owner != "user@example.com" {
+		t.Errorf("spec not copied from template: %+v", run.Spec)
+	}
+
+	// The template must not be aliased by the run.
+	run.Spec.Prompt = "changed"
+	if cron.Spec.JobTemplate.Prompt != "Triage new issues" {
+		t.Error("modifying the run changed the template")
+	}
+}
+
+func TestCronJobRunName_StablePerActivation(t *testing.T) {
+	cron := &klausv1alpha1.KlausCronJob{ObjectMeta: metav1.ObjectMeta{Name: "nightly"}}
+	a := time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)
+	b := time.Date(2026, 3, 14, 2, 0, 30, 0, time.UTC)
+	c := time.Date(2026, 3, 14, 2, 1, 0, 0, time.UTC)
+
+	if CronJobRunName(cron, a) != CronJobRunName(cron, b) {
+		t.Error("expected the same name within one minute")
+	}
+	if CronJobRunName(cron, a) == CronJobRunName(cron, c) {
+		t.Error("expected different names for different activations")
+	}
+}
@@ -0,0 +1,84 @@
+package resources
+
+import (
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// DependencyEnvVarPrefix prefixes the environment variables carrying the
+// endpoints of spec.dependsOn instances.
+const DependencyEnvVarPrefix = "KLAUS_DEPENDENCY_"
+
+// Dependency is a resolved spec.dependsOn entry.
+type Dependency struct {
+	// Name is the name of the KlausInstance depended on.
+	Name string
+
+	// Endpoint is its in-cluster service URL.
+	Endpoint string
+
+	// TLS reports whether the dependency requires mTLS clients.
+	TLS bool
+}
+
+// NewDependency returns the Dependency for a KlausInstance whose child
+// resources live in namespace. The endpoint is derived from the spec, so it
+// is known before the dependency is Running.
+func NewDependency(instance *klausv1alpha1.KlausInstance, namespace string) Dependency {
+	return Dependency{
+		Name:     instance.Name,
+		Endpoint: ServiceEndpoint(instance, namespace),
+		TLS:      TLSEnabled(instance),
+	}
+}
+
+// DependencyEnvVarName returns the environment variable holding the endpoint
+// of a dependency: KLAUS_DEPENDENCY_<NAME>_URL with the name upper-cased and
+// dashes and dots replaced by underscores.
+func DependencyEnvVarName(name string) string {
+	return DependencyEnvVarPrefix + strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(name)) + "_URL"
+}
+
+// MergeDependencyMCPServers adds an MCP server entry named after each
+// dependency to the instance's inline MCP servers. Inline entries with the
+// same name take precedence. Dependencies requiring mTLS are skipped since
+// the instance holds no client certificate for them. This should be called
+// on a deep-copied instance.
+func MergeDependencyMCPServers(instance *klausv1alpha1.KlausInstance, deps []Dependency) error {
+	for _, dep := range deps {
+		if dep.TLS {
+			continue
+		}
+		if _, exists := instance.Spec.Claude.MCPServers[dep.Name]; exists {
+			continue
+		}
+		raw, err := json.Marshal(map[string]any{"type": "http", "url": dep.Endpoint + "/mcp"})
+		if err != nil {
+			return err
+		}
+		if instance.Spec.Claude.MCPServers == nil {
+			instance.Spec.Claude.MCPServers = make(map[string]runtime.RawExtension, len(deps))
+		}
+		instance.Spec.Claude.MCPServers[dep.Name] = runtime.RawExtension{Raw: raw}
+	}
+	return nil
+}
+
+// ApplyDependencyEnv sets the endpoint environment variable of each
+// dependency on the klaus container.
+func ApplyDependencyEnv(podSpec *corev1.PodSpec, deps []Dependency) {
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name != AppKlaus {
+			continue
+		}
+		for _, dep := range deps {
+			c.Env = append(c.Env, corev1.EnvVar{Name: DependencyEnvVarName(dep.Name), Value: dep.Endpoint})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestNewDependency(t *testing.T) {
+	researcher := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "researcher", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	dep := NewDependency(researcher, "klaus-user-user-example-com")
+	want := Dependency{Name: "researcher", Endpoint: "http://researcher.klaus-user-user-example-com.svc.cluster.local:8080"}
+	if dep != want {
+		t.Errorf("NewDependency() = %+v, want %+v", dep, want)
+	}
+
+	researcher.Spec.TLS = &klausv1alpha1.InstanceTLSConfig{}
+	if dep := NewDependency(researcher, "klaus-user-user-example-com"); !dep.TLS {
+		t.Errorf("NewDependency() = %+v, want TLS", dep)
+	}
+}
+
+func TestDependencyEnvVarName(t *testing.T) {
+	tests := map[string]string{
+		"researcher":     "KLAUS_DEPENDENCY_RESEARCHER_URL",
+		"code-reviewer":  "KLAUS_DEPENDENCY_CODE_REVIEWER_URL",
+		"team.sre-agent": "KLAUS_DEPENDENCY_TEAM_SRE_AGENT_URL",
+	}
+	for name, want := range tests {
+		if got := DependencyEnvVarName(name); got != want {
+			t.Errorf("DependencyEnvVarName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestMergeDependencyMCPServers(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{Claude: klausv1alpha1.ClaudeConfig{
+			MCPServers: map[string]runtime.RawExtension{"reviewer": {Raw: []byte(`{"type":"http","url":"http://custom/mcp"}`)}},
+		}},
+	}
+	deps := []Dependency{
+		{Name: "researcher", Endpoint: "http://researcher:8080"},
+		{Name: "reviewer", Endpoint: "http://reviewer:8080"},
+		{Name: "secure", Endpoint: "https://secure:8080", TLS: true},
+	}
+	if err := MergeDependencyMCPServers(instance, deps); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	servers := instance.Spec.Claude.MCPServers
+	if len(servers) != 2 {
+		t.Fatalf("MCPServers = %v, want researcher and reviewer", servers)
+	}
+	var researcher map[string]string
+	if err := json.Unmarshal(servers["researcher"].Raw, &researcher); err != nil {
+		t.Fatalf("invalid researcher config: %v", err)
+	}
+	if researcher["type"] != "http" || researcher["url"] != "http://researcher:8080/mcp" {
+		t.Errorf("researcher = %v", researcher)
+	}
+	if string(servers["reviewer"].Raw) != `{"type":"http","url":"http://custom/mcp"}` {
+		t.Errorf("reviewer = %s, want the inline entry to win", servers["reviewer"].Raw)
+	}
+}
+
+func TestApplyDependencyEnv(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "git-clone"}, {Name: AppKlaus}}}
+	ApplyDependencyEnv(podSpec, []Dependency{{Name: "researcher", Endpoint: "http://researcher:8080"}})
+
+	if len(podSpec.Containers[0].Env) != 0 {
+		t.Errorf("unexpected env on other containers: %v", podSpec.Containers[0].Env)
+	}
+	env := klausContainer(t, podSpec).Env
+	if len(env) != 1 || env[0].Name != "KLAUS_DEPENDENCY_RESEARCHER_URL" || env[0].Value != "http://researcher:8080" {
+		t.Errorf("env = %v", env)
+	}
+}
@@ -8,15 +8,50 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
 
+// AnnotationRestartedAt on a KlausInstance restarts its pods whenever its
+// value changes: the controller copies it to the pod template, like kubectl
+// rollout restart does with kubectl.kubernetes.io/restartedAt. Its value is
+// the RFC 3339 time of the restart request.
+const AnnotationRestartedAt = "klaus.giantswarm.io/restarted-at"
+
 // BuildDeployment creates the Deployment for a KlausInstance, mirroring the
 // standalone Helm chart's deployment.yaml rendering.
 func BuildDeployment(instance *klausv1alpha1.KlausInstance, namespace, klausImage, gitCloneImage string, configMapData map[string]string) *appsv1.Deployment {
+	replicas := int32(1)
+	if IsStopped(instance) {
+		replicas = 0
+	}
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DeploymentName(instance),
+			Namespace: namespace,
+			Labels:    InstanceLabels(instance),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(replicas),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: SelectorLabels(instance),
+			},
+			Strategy: deploymentStrategy(instance),
+			Template: BuildPodTemplate(instance, klausImage, gitCloneImage, configMapData),
+		},
+	}
+	if restartedAt := instance.Annotations[AnnotationRestartedAt]; restartedAt != "" {
+		dep.Spec.Template.Annotations[AnnotationRestartedAt] = restartedAt
+	}
+
+	return dep
+}
+
+// BuildPodTemplate creates the pod template running the klaus container for
+// an instance. It is shared by the Deployment and by KlausJob batch Jobs.
+func BuildPodTemplate(instance *klausv1alpha1.KlausInstance, klausImage, gitCloneImage string, configMapData map[string]string) corev1.PodTemplateSpec {
 	labels := InstanceLabels(instance)
 	cmName := ConfigMapName(instance)
 	secName := SecretName(instance)
@@ -24,6 +59,7 @@ func BuildDeployment(instance *klausv1alpha1.KlausInstance, namespace, klausImag
 	envVars := BuildEnvVars(instance, cmName, secName)
 	volumes := BuildVolumes(instance, cmName)
 	volumeMounts := BuildVolumeMounts(instance)
+	livenessProbe, readinessProbe, startupProbe := BuildProbes(instance)
 
 	// Resource requirements (with defaults).
 	resources := corev1.ResourceRequirements{}
@@ -38,96 +74,69 @@ func BuildDeployment(instance *klausv1alpha1.KlausInstance, namespace, klausImag
 	}
 
 	initContainers := buildGitCloneInitContainers(instance, gitCloneImage)
+	initContainers = append(initContainers, buildWorkspaceSetupInitContainers(instance, klausImage)...)
 
-	replicas := int32(1)
-	if instance.Spec.Stopped {
-		replicas = 0
-	}
-
-	dep := &appsv1.Deployment{
+	tmpl := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      instance.Name,
-			Namespace: namespace,
-			Labels:    labels,
+			Labels:      labels,
+			Annotations: podAnnotations,
 		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: ptr.To(replicas),
-			Selector: &metav1.LabelSelector{
-				MatchLabels: SelectorLabels(instance),
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels:      labels,
-					Annotations: podAnnotations,
+		Spec: corev1.PodSpec{
+			ServiceAccountName: instance.Name,
+			ImagePullSecrets:   buildImagePullSecrets(instance),
+			InitContainers:     initContainers,
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsUser:  ptr.To(int64(1000)),
+				RunAsGroup: ptr.To(int64(1000)),
+				FSGroup:    ptr.To(int64(1000)),
+				SeccompProfile: &corev1.SeccompProfile{
+					Type: corev1.SeccompProfileTypeRuntimeDefault,
 				},
-				Spec: corev1.PodSpec{
-					ServiceAccountName: instance.Name,
-					ImagePullSecrets:   buildImagePullSecrets(instance),
-					InitContainers:     initContainers,
-					SecurityContext: &corev1.PodSecurityContext{
-						RunAsUser:  ptr.To(int64(1000)),
-						RunAsGroup: ptr.To(int64(1000)),
-						FSGroup:    ptr.To(int64(1000)),
-						SeccompProfile: &corev1.SeccompProfile{
-							Type: corev1.SeccompProfileTypeRuntimeDefault,
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  AppKlaus,
+					Image: klausImage,
+					Ports: []corev1.ContainerPort{
+						{
+							Name:          HTTPPortName,
+							ContainerPort: int32(KlausPort),
+							Protocol:      corev1.ProtocolTCP,
 						},
 					},
-					Containers: []corev1.Container{
-						{
-							Name:  AppKlaus,
-							Image: klausImage,
-							Ports: []corev1.ContainerPort{
-								{
-									Name:          HTTPPortName,
-									ContainerPort: int32(KlausPort),
-									Protocol:      corev1.ProtocolTCP,
-								},
-							},
-							Env:          envVars,
-							Resources:    resources,
-							VolumeMounts: volumeMounts,
-							LivenessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/healthz",
-										Port: intstr.FromInt32(int32(KlausPort)),
-									},
-								},
-								InitialDelaySeconds: 10,
-								PeriodSeconds:       30,
-							},
-							ReadinessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/readyz",
-										Port: intstr.FromInt32(int32(KlausPort)),
-									},
-								},
-								InitialDelaySeconds: 5,
-								PeriodSeconds:       10,
-							},
-							SecurityContext: &corev1.SecurityContext{
-								AllowPrivilegeEscalation: ptr.To(false),
-								Capabilities: &corev1.Capabilities{
-									Drop: []corev1.Capability{"ALL"},
-								},
-								// readOnlyRootFilesystem is false because Claude CLI
-								// needs write access to npm cache and git state.
-								ReadOnlyRootFilesystem: ptr.To(false),
-							},
+					Env:            envVars,
+					EnvFrom:        buildExtraEnvFrom(instance),
+					Resources:      resources,
+					VolumeMounts:   volumeMounts,
+					LivenessProbe:  livenessProbe,
+					ReadinessProbe: readinessProbe,
+					StartupProbe:   startupProbe,
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: ptr.To(false),
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
 						},
+						// readOnlyRootFilesystem is false because Claude CLI
+						// needs write access to npm cache and git state.
+						ReadOnlyRootFilesystem: ptr.To(false),
 					},
-					Volumes: volumes,
 				},
 			},
+			Volumes: volumes,
 		},
 	}
-
-	return dep
+	applyMemory(&tmpl.Spec, instance, configMapData)
+	applyConfigShards(&tmpl.Spec, instance, configMapData)
+	applyKubernetesAccess(&tmpl.Spec, instance)
+	applyScheduling(&tmpl.Spec, instance)
+	applyShutdown(&tmpl.Spec, instance)
+	applyNetwork(&tmpl.Spec, instance)
+	return tmpl
 }
 
 // buildGitCloneInitContainers returns init containers for git-cloning the
-// workspace repository. Returns nil if no git repo is configured.
+// workspace repository or repositories. Returns nil if no git repo is
+// configured.
 //
 // The init container runs with ReadOnlyRootFilesystem: true for security
 // hardening. A writable /tmp emptyDir is mounted so git has a scratch area
@@ -135,7 +144,7 @@ func BuildDeployment(instance *klausv1alpha1.KlausInstance, namespace, klausImag
 // set to /tmp so git config writes (e.g., safe.directory) land there.
 // GIT_CONFIG_NOSYSTEM=1 prevents reading /etc/gitconfig which may not exist.
 func buildGitCloneInitContainers(instance *klausv1alpha1.KlausInstance, gitCloneImage string) []corev1.Container {
-	if !NeedsGitClone(instance) {
+	if !NeedsWorkspaceClone(instance) {
 		return nil
 	}
 
@@ -144,8 +153,12 @@ func buildGitCloneInitContainers(instance *klausv1alpha1.KlausInstance, gitClone
 	}
 
 	ws := instance.Spec.Workspace
-	secretKey := GitSecretKey(instance)
-	script := buildGitCloneScript(ws.GitRepo, ws.GitRef, NeedsGitSecret(instance), secretKey)
+	var script string
+	if HasWorkspaceRepos(instance) {
+		script = buildReposCloneScript(instance)
+	} else {
+		script = buildGitCloneScript(ws.GitRepo, ws.GitRef, NeedsGitSecret(instance), GitSecretKey(instance), ws.GitCloneOptions)
+	}
 
 	mounts := []corev1.VolumeMount{
 		{Name: WorkspaceVolumeName, MountPath: WorkspaceMountPath},
@@ -161,10 +174,11 @@ func buildGitCloneInitContainers(instance *klausv1alpha1.KlausInstance, gitClone
 
 	return []corev1.Container{
 		{
-			Name:    "git-clone",
-			Image:   gitCloneImage,
-			Command: []string{"sh", "-c"},
-			Args:    []string{script},
+			Name:                   GitCloneContainerName,
+			Image:                  gitCloneImage,
+			Command:                []string{"sh", "-c"},
+			Args:                   []string{script},
+			TerminationMessagePath: GitCloneTerminationMessagePath,
 			Env: []corev1.EnvVar{
 				{Name: "HOME", Value: GitTmpMountPath},
 				{Name: "GIT_CONFIG_NOSYSTEM", Value: "1"},
@@ -193,10 +207,9 @@ func buildGitCloneInitContainers(instance *klausv1alpha1.KlausInstance, gitClone
 // the token is read from the mounted secret, injected into the clone URL
 // via POSIX parameter expansion, and stripped from the persisted remote
 // after clone/fetch to avoid leaking credentials on the workspace PVC.
-func buildGitCloneScript(gitRepo, gitRef string, hasSecret bool, secretKey string) string {
-	quotedRepo := shellQuote(gitRepo)
-	quotedWs := shellQuote(WorkspaceMountPath)
-
+// The shallow, sparse, LFS and submodule settings in opts are applied as
+// described at gitCloneCommands.
+func buildGitCloneScript(gitRepo, gitRef string, hasSecret bool, secretKey string, opts klausv1alpha1.GitCloneOptions) string {
 	var parts []string
 
 	// Fail fast on any command error. The update path uses explicit fallback
@@ -204,8 +217,58 @@ func buildGitCloneScript(gitRepo, gitRef string, hasSecret bool, secretKey strin
 	// previously cloned workspace.
 	parts = append(parts, "set -e")
 
+	// Report the checked out commit as the termination message, read by the
+	// operator into status.workspace.resolvedSHA. Also runs when the update
+	// path exits early on a failed fetch.
+	parts = append(parts,
+		"WS="+shellQuote(WorkspaceMountPath),
+		fmt.Sprintf(`trap 'rc=$?; set +e; if [ "$rc" -eq 0 ]; then git -C "$WS" rev-parse HEAD > %s 2>/dev/null; fi; exit $rc' EXIT`,
+			GitCloneTerminationMessagePath),
+	)
+
+	parts = append(parts, gitCloneCommands(gitRepo, gitRef, WorkspaceMountPath, hasSecret, secretKey, opts)...)
+	return strings.Join(parts, "\n")
+}
+
+// buildReposCloneScript generates the git-clone init container script for
+// spec.workspace.repos. Every repository is cloned or updated like the
+// gitRepo checkout, in a subshell so that its credentials and an early exit
+// of its update path do not affect the others.
+func buildReposCloneScript(instance *klausv1alpha1.KlausInstance) string {
+	parts := []string{"set -e"}
+	for i, repo := range instance.Spec.Workspace.Repos {
+		parts = append(parts, "(")
+		parts = append(parts, gitCloneCommands(repo.Repo, repo.Ref, WorkspaceRepoPath(repo), repo.SecretRef != nil, WorkspaceRepoSecretKey(i), repo.GitCloneOptions)...)
+		parts = append(parts, ")")
+	}
+	return strings.Join(parts, "\n")
+}
+
+// gitCloneCommands returns the commands cloning gitRepo into dir, or
+// updating an existing checkout there.
+//
+// A depth makes the initial clone shallow; updates of a shallow checkout
+// fetch only the new commits, keeping the history truncated. Sparse checkouts
+// clone without blobs and materialize only the listed directories;
+// sparse-checkout, submodule and LFS commands run after the remote is
+// reset, so the token is passed to them through GIT_CONFIG_* environment
+// variables that are not persisted on the workspace PVC.
+func gitCloneCommands(gitRepo, gitRef, dir string, hasSecret bool, secretKey string, opts klausv1alpha1.GitCloneOptions) []string {
+	quotedRepo := shellQuote(gitRepo)
+	quotedWs := shellQuote(dir)
+
+	var parts []string
 	cloneURL := quotedRepo
 
+	var cloneFlags, depthFlag string
+	if opts.Depth != nil {
+		depthFlag = fmt.Sprintf(" --depth %d", *opts.Depth)
+		cloneFlags += depthFlag
+	}
+	if len(opts.SparseCheckoutPaths) > 0 {
+		cloneFlags += " --filter=blob:none --sparse"
+	}
+
 	if hasSecret {
 		keyPath := path.Join(GitSecretMountPath, secretKey)
 		parts = append(parts,
@@ -220,9 +283,9 @@ func buildGitCloneScript(gitRepo, gitRef string, hasSecret bool, secretKey strin
 	// Fresh clone vs incremental update.
 	parts = append(parts, fmt.Sprintf("if [ ! -d %s/.git ]; then", quotedWs))
 	if gitRef != "" {
-		parts = append(parts, fmt.Sprintf("  git clone --branch %s %s %s", shellQuote(gitRef), cloneURL, quotedWs))
+		parts = append(parts, fmt.Sprintf("  git clone%s --branch %s %s %s", cloneFlags, shellQuote(gitRef), cloneURL, quotedWs))
 	} else {
-		parts = append(parts, fmt.Sprintf("  git clone %s %s", cloneURL, quotedWs))
+		parts = append(parts, fmt.Sprintf("  git clone%s %s %s", cloneFlags, cloneURL, quotedWs))
 	}
 	if hasSecret {
 		parts = append(parts, fmt.Sprintf("  cd %s", quotedWs))
@@ -248,7 +311,39 @@ func buildGitCloneScript(gitRepo, gitRef string, hasSecret bool, secretKey strin
 	}
 	parts = append(parts, "fi")
 
-	return strings.Join(parts, "\n")
+	if len(opts.SparseCheckoutPaths) == 0 && !opts.Submodules && !opts.LFS {
+		return parts
+	}
+	parts = append(parts, "cd "+quotedWs)
+	if hasSecret {
+		parts = append(parts,
+			`HOST=${REPO#*://}; HOST=${HOST%%/*}`,
+			"export GIT_CONFIG_COUNT=1",
+			`export GIT_CONFIG_KEY_0="url.${REPO%%://*}://x-access-token:${TOKEN}@${HOST}/.insteadOf"`,
+			`export GIT_CONFIG_VALUE_0="${REPO%%://*}://${HOST}/"`,
+		)
+	}
+	if len(opts.SparseCheckoutPaths) > 0 {
+		quoted := make([]string, 0, len(opts.SparseCheckoutPaths))
+		for _, p := range opts.SparseCheckoutPaths {
+			quoted = append(quoted, shellQuote(p))
+		}
+		parts = append(parts, "git sparse-checkout set -- "+strings.Join(quoted, " "))
+	}
+	if opts.Submodules {
+		parts = append(parts, "git submodule update --init --recursive"+depthFlag)
+	}
+	if opts.LFS {
+		parts = append(parts,
+			"if command -v git-lfs >/dev/null 2>&1; then",
+			"  git lfs install --local",
+			"  git lfs pull",
+			"else",
+			"  echo 'WARNING: git-lfs is not installed in the git clone image, LFS files are pointers'",
+			"fi",
+		)
+	}
+	return parts
 }
 
 // buildImagePullSecrets converts the list of pull secret names to
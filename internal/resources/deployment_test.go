@@ -447,6 +447,40 @@ func TestBuildDeployment_StoppedZeroReplicas(t *testing.T) {
 	}
 }
 
+func TestBuildDeployment_DesiredStateStoppedZeroReplicas(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-instance"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:        "user@example.com",
+			DesiredState: klausv1alpha1.DesiredStateStopped,
+		},
+	}
+
+	dep := BuildDeployment(instance, "klaus-user-test", "klaus:latest", DefaultGitCloneImage, nil)
+
+	if *dep.Spec.Replicas != 0 {
+		t.Errorf("Replicas = %d, want 0 when desiredState is Stopped", *dep.Spec.Replicas)
+	}
+}
+
+func TestBuildDeployment_RestartedAt(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-instance"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+
+	dep := BuildDeployment(instance, "klaus-user-test", "klaus:latest", DefaultGitCloneImage, nil)
+	if _, ok := dep.Spec.Template.Annotations[AnnotationRestartedAt]; ok {
+		t.Errorf("pod annotations = %v, want no restart annotation", dep.Spec.Template.Annotations)
+	}
+
+	instance.Annotations = map[string]string{AnnotationRestartedAt: "2026-01-02T03:04:05Z"}
+	dep = BuildDeployment(instance, "klaus-user-test", "klaus:latest", DefaultGitCloneImage, nil)
+	if got := dep.Spec.Template.Annotations[AnnotationRestartedAt]; got != "2026-01-02T03:04:05Z" {
+		t.Errorf("pod restart annotation = %q, want the one of the instance", got)
+	}
+}
+
 func TestBuildDeployment_NotStoppedOneReplica(t *testing.T) {
 	instance := &klausv1alpha1.KlausInstance{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-instance"},
@@ -464,7 +498,7 @@ func TestBuildDeployment_NotStoppedOneReplica(t *testing.T) {
 }
 
 func TestBuildGitCloneScript_WithRef(t *testing.T) {
-	script := buildGitCloneScript("https://github.com/example/project.git", "main", false, "")
+	script := buildGitCloneScript("https://github.com/example/project.git", "main", false, "", klausv1alpha1.GitCloneOptions{})
 	if !strings.Contains(script, "--branch 'main'") {
 		t.Error("expected --branch 'main' (quoted) in clone script")
 	}
@@ -493,7 +527,7 @@ func TestBuildGitCloneScript_WithRef(t *testing.T) {
 }
 
 func TestBuildGitCloneScript_WithoutRef(t *testing.T) {
-	script := buildGitCloneScript("https://github.com/example/project.git", "", false, "")
+	script := buildGitCloneScript("https://github.com/example/project.git", "", false, "", klausv1alpha1.GitCloneOptions{})
 	if strings.Contains(script, "--branch") {
 		t.Error("unexpected --branch when gitRef is empty")
 	}
@@ -503,7 +537,7 @@ func TestBuildGitCloneScript_WithoutRef(t *testing.T) {
 }
 
 func TestBuildGitCloneScript_WithSecret(t *testing.T) {
-	script := buildGitCloneScript("https://github.com/example/project.git", "main", true, "token")
+	script := buildGitCloneScript("https://github.com/example/project.git", "main", true, "token", klausv1alpha1.GitCloneOptions{})
 	if !strings.Contains(script, "x-access-token") {
 		t.Error("expected x-access-token in clone script when hasSecret is true")
 	}
@@ -526,7 +560,7 @@ func TestBuildGitCloneScript_WithSecret(t *testing.T) {
 }
 
 func TestBuildGitCloneScript_WithSecretNoRef(t *testing.T) {
-	script := buildGitCloneScript("https://github.com/example/project.git", "", true, "token")
+	script := buildGitCloneScript("https://github.com/example/project.git", "", true, "token", klausv1alpha1.GitCloneOptions{})
 	if !strings.Contains(script, "x-access-token") {
 		t.Error("expected x-access-token in clone script")
 	}
@@ -542,7 +576,7 @@ func TestBuildGitCloneScript_WithSecretNoRef(t *testing.T) {
 }
 
 func TestBuildGitCloneScript_ValuesAreShellQuoted(t *testing.T) {
-	script := buildGitCloneScript("https://example.com/repo.git", "main", false, "")
+	script := buildGitCloneScript("https://example.com/repo.git", "main", false, "", klausv1alpha1.GitCloneOptions{})
 	if !strings.Contains(script, "'https://example.com/repo.git'") {
 		t.Error("expected gitRepo to be single-quoted in clone script")
 	}
@@ -554,3 +588,48 @@ func TestBuildGitCloneScript_ValuesAreShellQuoted(t *testing.T) {
 		t.Error("expected workspace mount path to be single-quoted in clone script")
 	}
 }
+
+func TestBuildGitCloneScript_ReportsCommit(t *testing.T) {
+	script := buildGitCloneScript("https://github.com/example/project.git", "main", false, "", klausv1alpha1.GitCloneOptions{})
+	if !strings.Contains(script, `rev-parse HEAD > /dev/termination-log`) {
+		t.Errorf("expected the checked out commit to be written to the termination message:\n%s", script)
+	}
+	// The trap must be set before the update path can exit early.
+	if strings.Index(script, "trap ") > strings.Index(script, "git fetch origin") {
+		t.Error("expected the trap before the update path")
+	}
+}
+
+func TestBuildGitCloneScript_CloneOptions(t *testing.T) {
+	opts := klausv1alpha1.GitCloneOptions{
+		Depth:               ptr.To(int32(1)),
+		SparseCheckoutPaths: []string{"services/api", "docs"},
+		LFS:                 true,
+		Submodules:          true,
+	}
+	script := buildGitCloneScript("https://github.com/example/monorepo.git", "main", true, "token", opts)
+	for _, want := range []string{
+		`git clone --depth 1 --filter=blob:none --sparse --branch 'main' "$AUTH_URL"`,
+		"git sparse-checkout set -- 'services/api' 'docs'",
+		"git submodule update --init --recursive --depth 1",
+		"git lfs pull",
+		`export GIT_CONFIG_KEY_0="url.${REPO%%://*}://x-access-token:${TOKEN}@${HOST}/.insteadOf"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected %q in clone script:\n%s", want, script)
+		}
+	}
+	// Updates of a shallow checkout fetch without --depth, which would make
+	// the local and remote histories unrelated.
+	if strings.Contains(script, "fetch --depth") || strings.Contains(script, "pull --depth") {
+		t.Errorf("unexpected shallow update:\n%s", script)
+	}
+
+	script = buildGitCloneScript("https://github.com/example/monorepo.git", "", false, "", klausv1alpha1.GitCloneOptions{Submodules: true})
+	if strings.Contains(script, "GIT_CONFIG_KEY_0") {
+		t.Error("unexpected credential config without a git secret")
+	}
+	if !strings.Contains(script, "git clone 'https://github.com/example/monorepo.git'") {
+		t.Errorf("expected a full clone:\n%s", script)
+	}
+}
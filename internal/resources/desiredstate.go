@@ -0,0 +1,32 @@
+package resources
+
+import (
+	"fmt"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// IsStopped reports whether the instance should be scaled to zero replicas:
+// spec.desiredState is Stopped, or the deprecated spec.stopped is set.
+func IsStopped(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.DesiredState == klausv1alpha1.DesiredStateStopped || instance.Spec.Stopped
+}
+
+// SetDesiredState sets spec.desiredState of the instance. Running also
+// clears the deprecated spec.stopped, which would otherwise keep the
+// instance stopped.
+func SetDesiredState(instance *klausv1alpha1.KlausInstance, state klausv1alpha1.DesiredState) {
+	instance.Spec.DesiredState = state
+	if state == klausv1alpha1.DesiredStateRunning {
+		instance.Spec.Stopped = false
+	}
+}
+
+// validateDesiredState rejects the deprecated spec.stopped stopping an
+// instance whose spec.desiredState is Running.
+func validateDesiredState(instance *klausv1alpha1.KlausInstance) error {
+	if instance.Spec.DesiredState == klausv1alpha1.DesiredStateRunning && instance.Spec.Stopped {
+		return fmt.Errorf("spec.stopped contradicts spec.desiredState Running; set spec.desiredState only")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestIsStopped(t *testing.T) {
+	tests := []struct {
+		name string
+		spec klausv1alpha1.KlausInstanceSpec
+		want bool
+	}{
+		{name: "default"},
+		{name: "running", spec: klausv1alpha1.KlausInstanceSpec{DesiredState: klausv1alpha1.DesiredStateRunning}},
+		{name: "desired state stopped", spec: klausv1alpha1.KlausInstanceSpec{DesiredState: klausv1alpha1.DesiredStateStopped}, want: true},
+		{name: "deprecated stopped", spec: klausv1alpha1.KlausInstanceSpec{Stopped: true}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStopped(&klausv1alpha1.KlausInstance{Spec: tt.spec}); got != tt.want {
+				t.Errorf("IsStopped() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDesiredState(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{Stopped: true}}
+
+	SetDesiredState(instance, klausv1alpha1.DesiredStateStopped)
+	if !IsStopped(instance) || !instance.Spec.Stopped {
+		t.Errorf("spec = %+v, want it stopped", instance.Spec)
+	}
+
+	SetDesiredState(instance, klausv1alpha1.DesiredStateRunning)
+	if IsStopped(instance) || instance.Spec.DesiredState != klausv1alpha1.DesiredStateRunning {
+		t.Errorf("spec = %+v, want it running with spec.stopped cleared", instance.Spec)
+	}
+}
+
+func TestValidateInstanceSpec_DesiredState(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{
+		Owner:        "user@example.com",
+		DesiredState: klausv1alpha1.DesiredStateStopped,
+		Stopped:      true,
+	}}
+	if err := ValidateInstanceSpec(instance); err != nil {
+		t.Errorf("ValidateInstanceSpec() = %v, want both fields stopping the instance accepted", err)
+	}
+
+	instance.Spec.DesiredState = klausv1alpha1.DesiredStateRunning
+	if err := ValidateInstanceSpec(instance); err == nil || !strings.Contains(err.Error(), "contradicts") {
+		t.Errorf("ValidateInstanceSpec() = %v, want the contradiction rejected", err)
+	}
+}
@@ -0,0 +1,191 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// EffectiveConfigKey is the key of the effective spec in the effective
+	// configuration ConfigMap.
+	EffectiveConfigKey = "spec.yaml"
+
+	// RedactedValue replaces the literal values of environment variables
+	// and MCP server headers in the effective spec.
+	RedactedValue = "<redacted>"
+
+	// omittedContent replaces file content left out of an effective spec
+	// that does not fit into a ConfigMap.
+	omittedContent = "<omitted: see the configuration ConfigMap>"
+)
+
+// EffectiveConfigName returns the name of the ConfigMap publishing the
+// effective spec of an instance.
+func EffectiveConfigName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name + "-effective-config"
+}
+
+// BuildEffectiveConfigMap creates the ConfigMap publishing the effective
+// spec of an instance: its spec after the personality, skill pack, OCI and
+// KlausMCPServer resolution, with image the image the pods run. The inline
+// personality is left out as it is merged into the spec, and secrets are
+// redacted by RedactSpec. When the spec does not fit into a ConfigMap, the
+// content of skills, commands, agent files, hook scripts and memory files is
+// left out; it is part of the configuration ConfigMaps as well.
+func BuildEffectiveConfigMap(instance *klausv1alpha1.KlausInstance, namespace, image string) (*corev1.ConfigMap, error) {
+	spec, err := RedactSpec(&instance.Spec)
+	if err != nil {
+		return nil, err
+	}
+	spec.Image = image
+	spec.InlinePersonality = nil
+
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("encoding effective spec: %w", err)
+	}
+	if len(data) > ConfigMapDataLimit {
+		omitFileContent(spec)
+		if data, err = yaml.Marshal(spec); err != nil {
+			return nil, fmt.Errorf("encoding effective spec: %w", err)
+		}
+		if len(data) > ConfigMapDataLimit {
+			return nil, fmt.Errorf("effective spec is %d bytes without file content, exceeding the ConfigMap limit of %d bytes", len(data), ConfigMapDataLimit)
+		}
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      EffectiveConfigName(instance),
+			Namespace: namespace,
+			Labels:    InstanceLabels(instance),
+		},
+		Data: map[string]string{EffectiveConfigKey: string(data)},
+	}, nil
+}
+
+// EffectiveConfigHash returns the checksum of an effective configuration
+// ConfigMap, recorded in status.effectiveConfigHash.
+func EffectiveConfigHash(cm *corev1.ConfigMap) string {
+	return ConfigMapChecksum(cm.Data)
+}
+
+// RedactSpec returns a copy of spec with the literal values of spec.extraEnv
+// replaced by RedactedValue, as are the env and headers values of the MCP
+// servers in spec.claude.mcpServers and spec.mcpServers. MCP server values
+// with ${VAR} placeholders are kept: the secrets they expand to are not part
+// of the spec.
+func RedactSpec(spec *klausv1alpha1.KlausInstanceSpec) (*klausv1alpha1.KlausInstanceSpec, error) {
+	out := spec.DeepCopy()
+	for i := range out.ExtraEnv {
+		if out.ExtraEnv[i].Value != "" {
+			out.ExtraEnv[i].Value = RedactedValue
+		}
+	}
+	for i := range out.MCPServers {
+		redactMCPValues(out.MCPServers[i].Headers)
+		redactMCPValues(out.MCPServers[i].Env)
+	}
+	if network := out.Network; network != nil {
+		// Credentials are rejected since they moved to a Secret, but
+		// instances created before may still embed them.
+		network.HTTPProxy = redactURLCredentials(network.HTTPProxy)
+		network.HTTPSProxy = redactURLCredentials(network.HTTPSProxy)
+	}
+	for _, name := range slices.Sorted(maps.Keys(out.Claude.MCPServers)) {
+		server, err := redactMCPServerConfig(out.Claude.MCPServers[name])
+		if err != nil {
+			return nil, fmt.Errorf("redacting MCP server %q: %w", name, err)
+		}
+		out.Claude.MCPServers[name] = server
+	}
+	return out, nil
+}
+
+// redactURLCredentials redacts the user information of rawURL.
+func redactURLCredentials(rawURL string) string {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return rawURL
+	}
+	userinfo, host, ok := strings.Cut(rest, "@")
+	if !ok || strings.Contains(userinfo, "/") {
+		return rawURL
+	}
+	return scheme + "://" + RedactedValue + "@" + host
+}
+
+// redactMCPServerConfig redacts the env and headers values of a MCP server
+// config.
+func redactMCPServerConfig(server runtime.RawExtension) (runtime.RawExtension, error) {
+	var config map[string]any
+	if err := json.Unmarshal(server.Raw, &config); err != nil {
+		return server, err
+	}
+	for _, key := range []string{"env", "headers"} {
+		values, ok := config[key].(map[string]any)
+		if !ok {
+			continue
+		}
+		for name, value := range values {
+			if s, ok := value.(string); !ok || !strings.Contains(s, "${") {
+				values[name] = RedactedValue
+			}
+		}
+	}
+	// Keep RedactedValue readable instead of escaping its angle brackets.
+	var raw bytes.Buffer
+	enc := json.NewEncoder(&raw)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(config); err != nil {
+		return server, err
+	}
+	return runtime.RawExtension{Raw: bytes.TrimSuffix(raw.Bytes(), []byte("\n"))}, nil
+}
+
+// redactMCPValues redacts the values of values without ${VAR} placeholders.
+func redactMCPValues(values map[string]string) {
+	for name, value := range values {
+		if !strings.Contains(value, "${") {
+			values[name] = RedactedValue
+		}
+	}
+}
+
+// omitFileContent replaces the file content of spec with omittedContent.
+func omitFileContent(spec *klausv1alpha1.KlausInstanceSpec) {
+	for name, skill := range spec.Skills {
+		skill.Content = omittedContent
+		spec.Skills[name] = skill
+	}
+	for name, command := range spec.Commands {
+		command.Content = omittedContent
+		spec.Commands[name] = command
+	}
+	for name, agentFile := range spec.AgentFiles {
+		agentFile.Content = omittedContent
+		spec.AgentFiles[name] = agentFile
+	}
+	for name := range spec.HookScripts {
+		spec.HookScripts[name] = omittedContent
+	}
+	if memory := spec.Memory; memory != nil {
+		if memory.Content != "" {
+			memory.Content = omittedContent
+		}
+		for name := range memory.Files {
+			memory.Files[name] = omittedContent
+		}
+	}
+}
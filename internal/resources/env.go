@@ -13,9 +13,22 @@ import (
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
 
+// LabelExtraEnvSecret marks the Secrets that spec.extraEnv and
+// spec.extraEnvFrom of instances may read in namespace-scoped mode, where
+// the instance pods share their namespace with other owners' Secrets.
+const LabelExtraEnvSecret = "klaus.giantswarm.io/extra-env"
+
 // BuildEnvVars creates the full list of environment variables for a Klaus
 // instance container, mirroring the Helm chart's deployment.yaml env rendering.
 func BuildEnvVars(instance *klausv1alpha1.KlausInstance, configMapName, secretName string) []corev1.EnvVar {
+	// User-defined variables, after the operator's. Validation rejects
+	// names the operator sets.
+	return appendExtraEnvVars(buildOperatorEnvVars(instance, configMapName, secretName), instance.Spec.ExtraEnv)
+}
+
+// buildOperatorEnvVars returns the environment variables the operator sets
+// on the klaus container.
+func buildOperatorEnvVars(instance *klausv1alpha1.KlausInstance, configMapName, secretName string) []corev1.EnvVar {
 	var envs []corev1.EnvVar
 
 	// PORT is always set.
@@ -24,16 +37,8 @@ func BuildEnvVars(instance *klausv1alpha1.KlausInstance, configMapName, secretNa
 		Value: strconv.Itoa(KlausPort),
 	})
 
-	// Anthropic API key from Secret.
-	envs = append(envs, corev1.EnvVar{
-		Name: "ANTHROPIC_API_KEY",
-		ValueFrom: &corev1.EnvVarSource{
-			SecretKeyRef: &corev1.SecretKeySelector{
-				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
-				Key:                  "api-key",
-			},
-		},
-	})
+	// Model provider: Anthropic API key from Secret, or Bedrock/Vertex settings.
+	envs = append(envs, buildProviderEnvVars(instance, secretName)...)
 
 	// Claude model.
 	if instance.Spec.Claude.Model != "" {
@@ -148,7 +153,7 @@ func BuildEnvVars(instance *klausv1alpha1.KlausInstance, configMapName, secretNa
 	}
 
 	// Settings file.
-	if HasHooks(instance) {
+	if HasSettings(instance) {
 		envs = append(envs, corev1.EnvVar{
 			Name:  "CLAUDE_SETTINGS_FILE",
 			Value: SettingsFilePath,
@@ -207,9 +212,8 @@ func BuildEnvVars(instance *klausv1alpha1.KlausInstance, configMapName, secretNa
 	}
 
 	// Additional directories memory loading.
-	hasAddDirs := len(instance.Spec.AddDirs) > 0 || HasInlineExtensions(instance)
 	loadMemory := instance.Spec.LoadAdditionalDirsMemory == nil || *instance.Spec.LoadAdditionalDirsMemory
-	if hasAddDirs && loadMemory {
+	if addDirs != "" && loadMemory {
 		envs = append(envs, corev1.EnvVar{
 			Name:  "CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD",
 			Value: envValueTrue,
@@ -255,6 +259,11 @@ func BuildEnvVars(instance *klausv1alpha1.KlausInstance, configMapName, secretNa
 			Name:  "KLAUS_SOUL_FILE",
 			Value: path.Join(PersonalityMountPath, "SOUL.md"),
 		})
+	} else if HasInlineSoul(instance) {
+		envs = append(envs, corev1.EnvVar{
+			Name:  "KLAUS_SOUL_FILE",
+			Value: InlineSoulPath,
+		})
 	}
 
 	// Owner subject for JWT-based access control.
@@ -268,9 +277,65 @@ func BuildEnvVars(instance *klausv1alpha1.KlausInstance, configMapName, secretNa
 	// Telemetry.
 	envs = append(envs, buildTelemetryEnvVars(instance)...)
 
+	// mTLS serving certificate.
+	envs = append(envs, buildTLSEnvVars(instance)...)
+
+	return envs
+}
+
+// ReservedEnvPrefixes are the environment variable prefixes the operator and
+// the klaus agent own. spec.extraEnv and spec.extraEnvFrom cannot use them.
+var ReservedEnvPrefixes = []string{"KLAUS_", "CLAUDE_"}
+
+// IsReservedEnvName reports whether name is set by the operator or reserved
+// for it, and cannot be set in spec.extraEnv.
+func IsReservedEnvName(name string) bool {
+	if name == "PORT" {
+		return true
+	}
+	for _, prefix := range ReservedEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReservedEnvPrefix reports whether variables of an spec.extraEnvFrom
+// source with prefix could have a reserved name, e.g. K for a LAUS_TOKEN
+// key.
+func IsReservedEnvPrefix(prefix string) bool {
+	for _, reserved := range append(ReservedEnvPrefixes, "PORT") {
+		if strings.HasPrefix(prefix, reserved) || strings.HasPrefix(reserved, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendExtraEnvVars appends the extra variables not already in envs, so
+// the variables the operator sets take precedence should validation have
+// been skipped.
+func appendExtraEnvVars(envs, extra []corev1.EnvVar) []corev1.EnvVar {
+	for _, env := range extra {
+		if slices.ContainsFunc(envs, func(e corev1.EnvVar) bool { return e.Name == env.Name }) {
+			continue
+		}
+		envs = append(envs, *env.DeepCopy())
+	}
 	return envs
 }
 
+// buildExtraEnvFrom returns the spec.extraEnvFrom sources of the klaus
+// container.
+func buildExtraEnvFrom(instance *klausv1alpha1.KlausInstance) []corev1.EnvFromSource {
+	var sources []corev1.EnvFromSource
+	for _, source := range instance.Spec.ExtraEnvFrom {
+		sources = append(sources, *source.DeepCopy())
+	}
+	return sources
+}
+
 func envFromConfigMap(envName, configMapName, key string) corev1.EnvVar {
 	return corev1.EnvVar{
 		Name: envName,
@@ -300,9 +365,23 @@ func buildAddDirs(instance *klausv1alpha1.KlausInstance) string {
 	if HasInlineExtensions(instance) {
 		dirs = append(dirs, ExtensionsBasePath)
 	}
+	dirs = append(dirs, workspaceRepoPaths(instance)...)
 	return strings.Join(dirs, ",")
 }
 
+// OpenTelemetry resource attributes identifying the instance in its
+// telemetry. Exported to Prometheus they become the k8s_namespace_name and
+// klaus_instance labels.
+const (
+	TelemetryAttributeNamespace = "k8s.namespace.name"
+	TelemetryAttributeInstance  = "klaus.instance"
+
+	TelemetryLabelNamespace = "k8s_namespace_name"
+	TelemetryLabelInstance  = "klaus_instance"
+
+	telemetryNamespaceEnvVar = "KLAUS_NAMESPACE"
+)
+
 func buildTelemetryEnvVars(instance *klausv1alpha1.KlausInstance) []corev1.EnvVar {
 	tel := instance.Spec.Telemetry
 	if tel == nil || tel.Enabled == nil || !*tel.Enabled {
@@ -394,12 +473,26 @@ func buildTelemetryEnvVars(instance *klausv1alpha1.KlausInstance) []corev1.EnvVa
 			Value: "false",
 		})
 	}
+	// The instance attributes come last, so user-provided attributes cannot
+	// misattribute the metrics the operator reads back, e.g. token usage.
+	// The namespace is read from the pod, which runs in the user namespace
+	// or, in namespace-scoped mode, next to the instance.
+	envs = append(envs, corev1.EnvVar{
+		Name: telemetryNamespaceEnvVar,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+		},
+	})
+	attributes := fmt.Sprintf("%s=$(%s),%s=%s",
+		TelemetryAttributeNamespace, telemetryNamespaceEnvVar,
+		TelemetryAttributeInstance, instance.Name)
 	if tel.ResourceAttributes != "" {
-		envs = append(envs, corev1.EnvVar{
-			Name:  "OTEL_RESOURCE_ATTRIBUTES",
-			Value: tel.ResourceAttributes,
-		})
+		attributes = tel.ResourceAttributes + "," + attributes
 	}
+	envs = append(envs, corev1.EnvVar{
+		Name:  "OTEL_RESOURCE_ATTRIBUTES",
+		Value: attributes,
+	})
 
 	return envs
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
@@ -67,6 +68,19 @@ func TestBuildEnvVars_AddDirsWithExtensions(t *testing.T) {
 	assertEnvValue(t, envs, "CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD", "true")
 }
 
+func TestBuildEnvVars_SettingsWithoutHooks(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:  "test@example.com",
+			Claude: klausv1alpha1.ClaudeConfig{OutputStyle: "Explanatory"},
+		},
+	}
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+
+	assertEnvValue(t, envs, "CLAUDE_SETTINGS_FILE", SettingsFilePath)
+}
+
 func TestBuildEnvVars_Telemetry(t *testing.T) {
 	instance := &klausv1alpha1.KlausInstance{
 		Spec: klausv1alpha1.KlausInstanceSpec{
@@ -90,6 +104,33 @@ func TestBuildEnvVars_Telemetry(t *testing.T) {
 	assertEnvValue(t, envs, "OTEL_EXPORTER_OTLP_ENDPOINT", "http://otel-collector:4317")
 }
 
+func TestBuildEnvVars_TelemetryResourceAttributes(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Telemetry: &klausv1alpha1.TelemetryConfig{
+				Enabled:            ptr.To(true),
+				ResourceAttributes: "team=platform,klaus.instance=spoofed",
+			},
+		},
+	}
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+
+	assertEnvValue(t, envs, "OTEL_RESOURCE_ATTRIBUTES",
+		"team=platform,klaus.instance=spoofed,k8s.namespace.name=$(KLAUS_NAMESPACE),klaus.instance=dev")
+	for _, env := range envs {
+		if env.Name == "KLAUS_NAMESPACE" {
+			if env.ValueFrom == nil || env.ValueFrom.FieldRef == nil || env.ValueFrom.FieldRef.FieldPath != "metadata.namespace" {
+				t.Errorf("KLAUS_NAMESPACE = %+v, want the pod namespace", env)
+			}
+			return
+		}
+	}
+	t.Error("KLAUS_NAMESPACE not set")
+}
+
 func TestBuildEnvVars_ModeChat(t *testing.T) {
 	instance := &klausv1alpha1.KlausInstance{
 		Spec: klausv1alpha1.KlausInstanceSpec{
@@ -130,6 +171,19 @@ func TestBuildEnvVars_SoulFileWithPersonality(t *testing.T) {
 	assertEnvValue(t, envs, "KLAUS_SOUL_FILE", "/var/lib/klaus/personality/SOUL.md")
 }
 
+func TestBuildEnvVars_InlineSoulFile(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:             "test@example.com",
+			InlinePersonality: &klausv1alpha1.InlinePersonality{Soul: "You are helpful."},
+		},
+	}
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+
+	assertEnvValue(t, envs, "KLAUS_SOUL_FILE", "/etc/klaus/SOUL.md")
+}
+
 func TestBuildEnvVars_NoSoulFileWithoutPersonality(t *testing.T) {
 	instance := &klausv1alpha1.KlausInstance{
 		Spec: klausv1alpha1.KlausInstanceSpec{
@@ -142,6 +196,51 @@ func TestBuildEnvVars_NoSoulFileWithoutPersonality(t *testing.T) {
 	assertEnvAbsent(t, envs, "KLAUS_SOUL_FILE")
 }
 
+func TestBuildEnvVars_ExtraEnv(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			ExtraEnv: []corev1.EnvVar{
+				{Name: "PIP_INDEX_URL", Value: "https://pypi.example.com/simple"},
+				{Name: "ANTHROPIC_API_KEY", Value: "overridden"},
+			},
+		},
+	}
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+
+	assertEnvValue(t, envs, "PIP_INDEX_URL", "https://pypi.example.com/simple")
+	assertEnvFromSecret(t, envs, "ANTHROPIC_API_KEY", "test-secret", "api-key")
+	count := 0
+	for _, env := range envs {
+		if env.Name == "ANTHROPIC_API_KEY" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("ANTHROPIC_API_KEY set %d times, want the operator value only", count)
+	}
+}
+
+func TestBuildPodTemplate_ExtraEnvFrom(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			ExtraEnvFrom: []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "team-tokens"}},
+			}},
+		},
+	}
+
+	tmpl := BuildPodTemplate(instance, "klaus:latest", "", nil)
+
+	envFrom := tmpl.Spec.Containers[0].EnvFrom
+	if len(envFrom) != 1 || envFrom[0].SecretRef == nil || envFrom[0].SecretRef.Name != "team-tokens" {
+		t.Errorf("envFrom = %+v, want the team-tokens Secret", envFrom)
+	}
+}
+
 func assertEnvAbsent(t *testing.T, envs []corev1.EnvVar, name string) {
 	t.Helper()
 	for _, env := range envs {
@@ -0,0 +1,160 @@
+package resources
+
+import (
+	"maps"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// HTTPRouteGVK is the GroupVersionKind of the Gateway API HTTPRoute. It is
+// handled as an unstructured object so the Gateway API CRDs stay optional.
+var HTTPRouteGVK = schema.GroupVersionKind{
+	Group:   "gateway.networking.k8s.io",
+	Version: "v1",
+	Kind:    "HTTPRoute",
+}
+
+// ExposeType returns the effective expose type, or "" when the instance is
+// not exposed.
+func ExposeType(instance *klausv1alpha1.KlausInstance) klausv1alpha1.ExposeType {
+	if instance.Spec.Expose == nil {
+		return ""
+	}
+	if instance.Spec.Expose.Type == "" {
+		return klausv1alpha1.ExposeTypeIngress
+	}
+	return instance.Spec.Expose.Type
+}
+
+// ExposeName returns the name of the Ingress or HTTPRoute for an instance.
+func ExposeName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name
+}
+
+func exposePath(expose *klausv1alpha1.ExposeConfig) string {
+	if expose.Path == "" {
+		return "/"
+	}
+	return expose.Path
+}
+
+// ExternalEndpoint returns the external URL of an exposed instance, or ""
+// when spec.expose is unset.
+func ExternalEndpoint(instance *klausv1alpha1.KlausInstance) string {
+	expose := instance.Spec.Expose
+	if expose == nil {
+		return ""
+	}
+	scheme := "http"
+	if expose.TLSSecretName != "" {
+		scheme = "https"
+	}
+	return scheme + "://" + expose.Hostname + strings.TrimSuffix(exposePath(expose), "/")
+}
+
+// BuildIngress creates the Ingress routing spec.expose.hostname to the
+// instance Service.
+func BuildIngress(instance *klausv1alpha1.KlausInstance, namespace string) *networkingv1.Ingress {
+	expose := instance.Spec.Expose
+	pathType := networkingv1.PathTypePrefix
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ExposeName(instance),
+			Namespace:   namespace,
+			Labels:      InstanceLabels(instance),
+			Annotations: maps.Clone(expose.Annotations),
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: expose.IngressClassName,
+			Rules: []networkingv1.IngressRule{{
+				Host: expose.Hostname,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     exposePath(expose),
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: ServiceName(instance),
+									Port: networkingv1.ServiceBackendPort{Name: HTTPPortName},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if expose.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{expose.Hostname},
+			SecretName: expose.TLSSecretName,
+		}}
+	}
+	return ingress
+}
+
+// BuildHTTPRoute creates a Gateway API HTTPRoute attaching the instance
+// Service to spec.expose.gateway.
+func BuildHTTPRoute(instance *klausv1alpha1.KlausInstance, namespace string) *unstructured.Unstructured {
+	expose := instance.Spec.Expose
+
+	parentRef := map[string]any{}
+	if gw := expose.Gateway; gw != nil {
+		parentRef["name"] = gw.Name
+		if gw.Namespace != "" {
+			parentRef["namespace"] = gw.Namespace
+		}
+		if gw.SectionName != "" {
+			parentRef["sectionName"] = gw.SectionName
+		}
+	}
+
+	labels := map[string]any{}
+	for k, v := range InstanceLabels(instance) {
+		labels[k] = v
+	}
+	metadata := map[string]any{
+		"name":      ExposeName(instance),
+		"namespace": namespace,
+		"labels":    labels,
+	}
+	if len(expose.Annotations) > 0 {
+		annotations := map[string]any{}
+		for k, v := range expose.Annotations {
+			annotations[k] = v
+		}
+		metadata["annotations"] = annotations
+	}
+
+	route := &unstructured.Unstructured{
+		Object: map[string]any{
+			"metadata": metadata,
+			"spec": map[string]any{
+				"parentRefs": []any{parentRef},
+				"hostnames":  []any{expose.Hostname},
+				"rules": []any{map[string]any{
+					"matches": []any{map[string]any{
+						"path": map[string]any{
+							"type":  "PathPrefix",
+							"value": exposePath(expose),
+						},
+					}},
+					"backendRefs": []any{map[string]any{
+						"name": ServiceName(instance),
+						"port": int64(KlausPort),
+					}},
+				}},
+			},
+		},
+	}
+	route.SetGroupVersionKind(HTTPRouteGVK)
+	return route
+}
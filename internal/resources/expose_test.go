@@ -0,0 +1,147 @@
+package resources
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func exposedInstance(expose *klausv1alpha1.ExposeConfig) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:  "user@example.com",
+			Expose: expose,
+		},
+	}
+}
+
+func TestExternalEndpoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		expose *klausv1alpha1.ExposeConfig
+		want   string
+	}{
+		{name: "not exposed", expose: nil, want: ""},
+		{name: "plain http", expose: &klausv1alpha1.ExposeConfig{Hostname: "klaus.example.com"}, want: "http://klaus.example.com"},
+		{name: "tls", expose: &klausv1alpha1.ExposeConfig{Hostname: "klaus.example.com", TLSSecretName: "klaus-tls"}, want: "https://klaus.example.com"},
+		{name: "path prefix", expose: &klausv1alpha1.ExposeConfig{Hostname: "example.com", Path: "/agents/my-agent/"}, want: "http://example.com/agents/my-agent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExternalEndpoint(exposedInstance(tt.expose)); got != tt.want {
+				t.Errorf("ExternalEndpoint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildIngress(t *testing.T) {
+	className := "nginx"
+	instance := exposedInstance(&klausv1alpha1.ExposeConfig{
+		Hostname:         "klaus.example.com",
+		TLSSecretName:    "klaus-tls",
+		IngressClassName: &className,
+		Annotations:      map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+	})
+
+	ingress := BuildIngress(instance, "klaus-user-user-example-com")
+
+	if ingress.Name != "my-agent" || ingress.Namespace != "klaus-user-user-example-com" {
+		t.Errorf("unexpected name/namespace %s/%s", ingress.Namespace, ingress.Name)
+	}
+	if ingress.Labels[LabelManagedBy] != AppKlausOperator {
+		t.Errorf("expected managed-by label, got %v", ingress.Labels)
+	}
+	if ingress.Annotations["cert-manager.io/cluster-issuer"] != "letsencrypt" {
+		t.Errorf("annotations = %v", ingress.Annotations)
+	}
+	if ingress.Spec.IngressClassName == nil || *ingress.Spec.IngressClassName != "nginx" {
+		t.Errorf("ingressClassName = %v", ingress.Spec.IngressClassName)
+	}
+	if len(ingress.Spec.TLS) != 1 || ingress.Spec.TLS[0].SecretName != "klaus-tls" || ingress.Spec.TLS[0].Hosts[0] != "klaus.example.com" {
+		t.Errorf("tls = %+v", ingress.Spec.TLS)
+	}
+	if len(ingress.Spec.Rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(ingress.Spec.Rules))
+	}
+	rule := ingress.Spec.Rules[0]
+	if rule.Host != "klaus.example.com" {
+		t.Errorf("host = %q", rule.Host)
+	}
+	path := rule.HTTP.Paths[0]
+	if path.Path != "/" {
+		t.Errorf("path = %q, want /", path.Path)
+	}
+	if path.Backend.Service.Name != ServiceName(instance) || path.Backend.Service.Port.Name != HTTPPortName {
+		t.Errorf("backend = %+v", path.Backend.Service)
+	}
+
+	// Modifying the Ingress must not alias the spec annotations.
+	ingress.Annotations["extra"] = "x"
+	if _, ok := instance.Spec.Expose.Annotations["extra"]; ok {
+		t.Error("Ingress annotations alias the instance spec")
+	}
+}
+
+func TestBuildIngress_NoTLS(t *testing.T) {
+	ingress := BuildIngress(exposedInstance(&klausv1alpha1.ExposeConfig{Hostname: "klaus.example.com"}), "ns")
+	if len(ingress.Spec.TLS) != 0 {
+		t.Errorf("expected no TLS, got %+v", ingress.Spec.TLS)
+	}
+	if ingress.Spec.IngressClassName != nil {
+		t.Errorf("expected default ingress class, got %v", *ingress.Spec.IngressClassName)
+	}
+}
+
+func TestBuildHTTPRoute(t *testing.T) {
+	instance := exposedInstance(&klausv1alpha1.ExposeConfig{
+		Type:     klausv1alpha1.ExposeTypeHTTPRoute,
+		Hostname: "klaus.example.com",
+		Path:     "/agent",
+		Gateway:  &klausv1alpha1.GatewayReference{Name: "public", Namespace: "gateways", SectionName: "https"},
+	})
+
+	route := BuildHTTPRoute(instance, "klaus-user-user-example-com")
+
+	if route.GroupVersionKind() != HTTPRouteGVK {
+		t.Errorf("gvk = %v", route.GroupVersionKind())
+	}
+	if route.GetName() != "my-agent" || route.GetNamespace() != "klaus-user-user-example-com" {
+		t.Errorf("unexpected name/namespace %s/%s", route.GetNamespace(), route.GetName())
+	}
+	if route.GetLabels()[LabelManagedBy] != AppKlausOperator {
+		t.Errorf("labels = %v", route.GetLabels())
+	}
+
+	parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if len(parents) != 1 {
+		t.Fatalf("parentRefs = %v", parents)
+	}
+	parent := parents[0].(map[string]any)
+	if parent["name"] != "public" || parent["namespace"] != "gateways" || parent["sectionName"] != "https" {
+		t.Errorf("parentRef = %v", parent)
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != "klaus.example.com" {
+		t.Errorf("hostnames = %v", hostnames)
+	}
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	rule := rules[0].(map[string]any)
+	match := rule["matches"].([]any)[0].(map[string]any)["path"].(map[string]any)
+	if match["type"] != "PathPrefix" || match["value"] != "/agent" {
+		t.Errorf("match = %v", match)
+	}
+	backend := rule["backendRefs"].([]any)[0].(map[string]any)
+	if backend["name"] != ServiceName(instance) || backend["port"] != int64(KlausPort) {
+		t.Errorf("backend = %v", backend)
+	}
+
+	// The object must be deep-copyable for the controller-runtime client.
+	_ = route.DeepCopy()
+}
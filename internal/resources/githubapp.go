@@ -0,0 +1,89 @@
+package resources
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/githubapp"
+)
+
+const (
+	// AnnotationGitTokenExpiresAt records when the GitHub App installation
+	// token in a git credential Secret expires, in RFC 3339.
+	AnnotationGitTokenExpiresAt = "klaus.giantswarm.io/git-token-expires-at" // #nosec G101 -- annotation key, not a credential
+
+	// AnnotationGitTokenScope records the installation and repository a
+	// GitHub App installation token was minted for, so that a changed
+	// workspace repository mints a new token.
+	AnnotationGitTokenScope = "klaus.giantswarm.io/git-token-scope" // #nosec G101 -- annotation key, not a credential
+)
+
+// GitHubRepository returns the owner and name of the repository an HTTPS
+// git URL points to, e.g. "org" and "repo" for
+// https://github.com/org/repo.git.
+func GitHubRepository(repoURL string) (owner, repo string, err error) {
+	r, err := githubapp.ParseRepository(repoURL)
+	if err != nil {
+		return "", "", err
+	}
+	return r.Owner, r.Name, nil
+}
+
+// GitHubAppPermissions returns the repository permissions of the
+// installation token of an instance: read access to the contents, and
+// write access to them and to pull requests only when spec.workspace.output
+// pushes the changes and opens a pull request.
+func GitHubAppPermissions(instance *klausv1alpha1.KlausInstance) map[string]string {
+	permissions := map[string]string{"contents": "read"}
+	if output := instance.Spec.Workspace.Output; output != nil {
+		permissions["contents"] = "write"
+		if output.CreatePR {
+			permissions["pull_requests"] = "write"
+		}
+	}
+	return permissions
+}
+
+// GitHubAppTokenScope identifies the installation token an instance needs:
+// the configured installation ID, or 0 to look it up, the workspace
+// repository and the permissions.
+func GitHubAppTokenScope(instance *klausv1alpha1.KlausInstance) string {
+	owner, repo, _ := GitHubRepository(instance.Spec.Workspace.GitRepo)
+	id := instance.Spec.Workspace.GitHubApp.InstallationID
+	permissions := GitHubAppPermissions(instance)
+	scope := make([]string, 0, len(permissions))
+	for name, access := range permissions {
+		scope = append(scope, name+"="+access)
+	}
+	sort.Strings(scope)
+	return strconv.FormatInt(id, 10) + ":" + owner + "/" + repo + ":" + strings.Join(scope, ",")
+}
+
+// GitHubAppTokenNeedsRefresh returns true unless the git credential Secret
+// holds a token for the instance's current scope that is valid for at
+// least the given duration.
+func GitHubAppTokenNeedsRefresh(secret *corev1.Secret, instance *klausv1alpha1.KlausInstance, now time.Time, validFor time.Duration) bool {
+	if len(secret.Data[DefaultGitSecretKey]) == 0 ||
+		secret.Annotations[AnnotationGitTokenScope] != GitHubAppTokenScope(instance) {
+		return true
+	}
+	expiresAt, err := time.Parse(time.RFC3339, secret.Annotations[AnnotationGitTokenExpiresAt])
+	return err != nil || !now.Add(validFor).Before(expiresAt)
+}
+
+// SetGitHubAppToken stores an installation token in the git credential
+// Secret of an instance along with its scope and expiry.
+func SetGitHubAppToken(secret *corev1.Secret, instance *klausv1alpha1.KlausInstance, token string, expiresAt time.Time) {
+	secret.Type = corev1.SecretTypeOpaque
+	secret.Data = map[string][]byte{DefaultGitSecretKey: []byte(token)}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[AnnotationGitTokenScope] = GitHubAppTokenScope(instance)
+	secret.Annotations[AnnotationGitTokenExpiresAt] = expiresAt.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,164 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// LabelJob identifies resources belonging to a KlausJob.
+	LabelJob = "klaus.giantswarm.io/job"
+
+	// JobPromptKey is the ConfigMap key holding the rendered job prompt.
+	JobPromptKey = "prompt"
+
+	// JobPromptPath is where the job prompt is mounted in the container.
+	JobPromptPath = "/etc/klaus/prompt.txt"
+)
+
+// JobResourceName returns the name used for the child resources (Job,
+// ConfigMap, ServiceAccount, ...) of a KlausJob. The suffix keeps them apart
+// from KlausInstance children of the same base name.
+func JobResourceName(job *klausv1alpha1.KlausJob) string {
+	return job.Name + "-job"
+}
+
+// JobInstance returns a KlausInstance view of a KlausJob so that the
+// instance resource builders (env vars, volumes, ConfigMap, pod template) can
+// be reused. The returned object is never persisted.
+func JobInstance(job *klausv1alpha1.KlausJob) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      JobResourceName(job),
+			Namespace: job.Namespace,
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:            job.Spec.Owner,
+			Personality:      job.Spec.Personality,
+			Image:            job.Spec.Image,
+			ToolchainRef:     job.Spec.ToolchainRef,
+			Claude:           job.Spec.Claude,
+			Plugins:          job.Spec.Plugins,
+			ImagePullSecrets: job.Spec.ImagePullSecrets,
+			Workspace:        job.Spec.Workspace,
+			Resources:        job.Spec.Resources,
+			Telemetry:        job.Spec.Telemetry,
+		},
+	}
+}
+
+// JobLabels returns the labels applied to KlausJob child resources.
+func JobLabels(job *klausv1alpha1.KlausJob) map[string]string {
+	labels := InstanceLabels(JobInstance(job))
+	labels[LabelJob] = job.Name
+	return labels
+}
+
+// RenderJobPrompt returns the prompt for a KlausJob, rendering the prompt
+// template when one is set. Missing template variables are an error.
+func RenderJobPrompt(job *klausv1alpha1.KlausJob) (string, error) {
+	if job.Spec.PromptTemplate == nil {
+		return job.Spec.Prompt, nil
+	}
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(job.Spec.PromptTemplate.Template)
+	if err != nil {
+		return "", fmt.Errorf("parsing spec.promptTemplate.template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, job.Spec.PromptTemplate.Variables); err != nil {
+		return "", fmt.Errorf("rendering spec.promptTemplate: %w", err)
+	}
+	return b.String(), nil
+}
+
+// ValidateJobSpec checks KlausJob-specific constraints and the shared
+// instance spec rules.
+func ValidateJobSpec(job *klausv1alpha1.KlausJob) error {
+	hasPrompt := job.Spec.Prompt != ""
+	hasTemplate := job.Spec.PromptTemplate != nil
+	if hasPrompt == hasTemplate {
+		return fmt.Errorf("exactly one of spec.prompt and spec.promptTemplate must be set")
+	}
+	return ValidateSpec(JobInstance(job))
+}
+
+// BuildJobConfigMap creates the ConfigMap for a KlausJob, containing the
+// instance configuration entries plus the rendered prompt.
+func BuildJobConfigMap(job *klausv1alpha1.KlausJob, namespace, prompt string) (*corev1.ConfigMap, error) {
+	cm, err := BuildConfigMap(JobInstance(job), namespace)
+	if err != nil {
+		return nil, err
+	}
+	cm.Labels = JobLabels(job)
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[JobPromptKey] = prompt
+	if err := checkConfigSize(cm.Data); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// BuildJob creates the batch Job running a KlausJob to completion. The klaus
+// container runs in one-shot mode: it reads the prompt from KLAUS_PROMPT_FILE,
+// exits when the agent finishes, and writes its exit summary (or the bare
+// result) to its termination message.
+func BuildJob(job *klausv1alpha1.KlausJob, namespace, klausImage, gitCloneImage string, configMapData map[string]string) *batchv1.Job {
+	instance := JobInstance(job)
+	labels := JobLabels(job)
+
+	podTemplate := BuildPodTemplate(instance, klausImage, gitCloneImage, configMapData)
+	podTemplate.Labels = labels
+	podTemplate.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	container := &podTemplate.Spec.Containers[0]
+	// There is no long-running HTTP server to probe in one-shot mode.
+	container.Ports = nil
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+	container.StartupProbe = nil
+	container.Lifecycle = nil
+	container.TerminationMessagePath = JobTerminationMessagePath(job)
+	container.TerminationMessagePolicy = JobTerminationMessagePolicy(job)
+	container.Env = append(container.Env,
+		corev1.EnvVar{Name: "KLAUS_RUN_ONCE", Value: envValueTrue},
+		corev1.EnvVar{Name: "KLAUS_PROMPT_FILE", Value: JobPromptPath},
+	)
+	container.Env = append(container.Env, buildTerminationEnvVars(job)...)
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      configVolumeForKey(configMapData, JobPromptKey),
+		MountPath: JobPromptPath,
+		SubPath:   JobPromptKey,
+		ReadOnly:  true,
+	})
+
+	backoffLimit := int32(0)
+	if job.Spec.Retry != nil && job.Spec.Retry.BackoffLimit != nil {
+		backoffLimit = *job.Spec.Retry.BackoffLimit
+	}
+
+	batchJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      JobResourceName(job),
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(backoffLimit),
+			Template:     podTemplate,
+		},
+	}
+	if job.Spec.Timeout != nil {
+		batchJob.Spec.ActiveDeadlineSeconds = ptr.To(int64(job.Spec.Timeout.Seconds()))
+	}
+	return batchJob
+}
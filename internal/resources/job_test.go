@@ -0,0 +1,131 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testJob() *klausv1alpha1.KlausJob {
+	return &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:   "user@example.com",
+			Prompt:  "Review the repository",
+			Timeout: &metav1.Duration{Duration: 30 * time.Minute},
+			Retry:   &klausv1alpha1.JobRetryPolicy{BackoffLimit: ptr.To(int32(2))},
+		},
+	}
+}
+
+func TestRenderJobPrompt_Template(t *testing.T) {
+	job := testJob()
+	job.Spec.Prompt = ""
+	job.Spec.PromptTemplate = &klausv1alpha1.PromptTemplate{
+		Template:  "Review {{ .repo }} for {{ .focus }}",
+		Variables: map[string]string{"repo": "klaus-operator", "focus": "security"},
+	}
+
+	got, err := RenderJobPrompt(job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Review klaus-operator for security" {
+		t.Errorf("RenderJobPrompt() = %q", got)
+	}
+}
+
+func TestRenderJobPrompt_MissingVariable(t *testing.T) {
+	job := testJob()
+	job.Spec.Prompt = ""
+	job.Spec.PromptTemplate = &klausv1alpha1.PromptTemplate{Template: "Review {{ .repo }}"}
+
+	if _, err := RenderJobPrompt(job); err == nil {
+		t.Error("expected error for missing template variable")
+	}
+}
+
+func TestValidateJobSpec_PromptExclusivity(t *testing.T) {
+	job := testJob()
+	if err := ValidateJobSpec(job); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	job.Spec.PromptTemplate = &klausv1alpha1.PromptTemplate{Template: "x"}
+	if err := ValidateJobSpec(job); err == nil {
+		t.Error("expected error when both prompt and promptTemplate are set")
+	}
+
+	job.Spec.Prompt = ""
+	job.Spec.PromptTemplate = nil
+	if err := ValidateJobSpec(job); err == nil {
+		t.Error("expected error when neither prompt nor promptTemplate is set")
+	}
+}
+
+func TestBuildJob(t *testing.T) {
+	job := testJob()
+
+	batchJob := BuildJob(job, "klaus-user-user-example-com", "klaus:latest", DefaultGitCloneImage, map[string]string{JobPromptKey: "x"})
+
+	if batchJob.Name != "review-job" {
+		t.Errorf("Name = %q, want %q", batchJob.Name, "review-job")
+	}
+	if batchJob.Labels[LabelJob] != "review" {
+		t.Errorf("missing %s label, got %v", LabelJob, batchJob.Labels)
+	}
+	if *batchJob.Spec.BackoffLimit != 2 {
+		t.Errorf("BackoffLimit = %d, want 2", *batchJob.Spec.BackoffLimit)
+	}
+	if *batchJob.Spec.ActiveDeadlineSeconds != 1800 {
+		t.Errorf("ActiveDeadlineSeconds = %d, want 1800", *batchJob.Spec.ActiveDeadlineSeconds)
+	}
+
+	pod := batchJob.Spec.Template.Spec
+	if pod.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("RestartPolicy = %q, want Never", pod.RestartPolicy)
+	}
+	c := pod.Containers[0]
+	if c.LivenessProbe != nil || c.ReadinessProbe != nil || c.StartupProbe != nil || c.Lifecycle != nil {
+		t.Error("expected no probes on the job container")
+	}
+	if c.TerminationMessagePolicy != corev1.TerminationMessageFallbackToLogsOnError {
+		t.Errorf("TerminationMessagePolicy = %q", c.TerminationMessagePolicy)
+	}
+	if c.TerminationMessagePath != corev1.TerminationMessagePathDefault {
+		t.Errorf("TerminationMessagePath = %q", c.TerminationMessagePath)
+	}
+	assertEnvValue(t, c.Env, "KLAUS_RUN_ONCE", "true")
+	assertEnvValue(t, c.Env, "KLAUS_PROMPT_FILE", JobPromptPath)
+	assertEnvValue(t, c.Env, "KLAUS_TERMINATION_MESSAGE_PATH", corev1.TerminationMessagePathDefault)
+	assertEnvValue(t, c.Env, "KLAUS_EXIT_SUMMARY", "true")
+}
+
+func TestBuildJob_TerminationMessage(t *testing.T) {
+	job := testJob()
+	job.Spec.TerminationMessage = &klausv1alpha1.TerminationMessageConfig{
+		Path:        "/workspace/.klaus/exit.json",
+		Policy:      corev1.TerminationMessageReadFile,
+		ExitSummary: ptr.To(false),
+	}
+
+	c := BuildJob(job, "klaus-user-user-example-com", "klaus:latest", DefaultGitCloneImage, nil).Spec.Template.Spec.Containers[0]
+
+	if c.TerminationMessagePath != "/workspace/.klaus/exit.json" {
+		t.Errorf("TerminationMessagePath = %q", c.TerminationMessagePath)
+	}
+	if c.TerminationMessagePolicy != corev1.TerminationMessageReadFile {
+		t.Errorf("TerminationMessagePolicy = %q, want File", c.TerminationMessagePolicy)
+	}
+	assertEnvValue(t, c.Env, "KLAUS_TERMINATION_MESSAGE_PATH", "/workspace/.klaus/exit.json")
+	for _, e := range c.Env {
+		if e.Name == "KLAUS_EXIT_SUMMARY" {
+			t.Error("unexpected KLAUS_EXIT_SUMMARY with exitSummary disabled")
+		}
+	}
+}
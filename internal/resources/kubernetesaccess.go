@@ -0,0 +1,114 @@
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// KubernetesAccessVolumeName is the projected volume holding the
+	// ServiceAccount token of instances with Kubernetes API access.
+	KubernetesAccessVolumeName = "kube-api-access"
+
+	// ServiceAccountTokenMountPath is where Kubernetes clients look for the
+	// in-cluster ServiceAccount token, CA bundle and namespace.
+	ServiceAccountTokenMountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// DefaultTokenExpirationSeconds is the lifetime of the projected token
+	// when spec.kubernetesAccess.expirationSeconds is not set.
+	DefaultTokenExpirationSeconds int64 = 3600
+)
+
+// HasKubernetesAccess returns true when the instance may use the Kubernetes
+// API.
+func HasKubernetesAccess(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.KubernetesAccess != nil && instance.Spec.KubernetesAccess.Enabled
+}
+
+// KubernetesAccessBindingName returns the name of the RoleBinding granting
+// the instance ServiceAccount spec.kubernetesAccess.clusterRole.
+func KubernetesAccessBindingName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name
+}
+
+// BuildKubernetesAccessRoleBinding creates the RoleBinding granting the
+// ClusterRole of spec.kubernetesAccess to the instance ServiceAccount in
+// namespace. It returns nil when the instance has no Kubernetes API access
+// or no ClusterRole.
+func BuildKubernetesAccessRoleBinding(instance *klausv1alpha1.KlausInstance, namespace string) *rbacv1.RoleBinding {
+	if !HasKubernetesAccess(instance) || instance.Spec.KubernetesAccess.ClusterRole == "" {
+		return nil
+	}
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KubernetesAccessBindingName(instance),
+			Namespace: namespace,
+			Labels:    InstanceLabels(instance),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     instance.Spec.KubernetesAccess.ClusterRole,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      instance.Name,
+			Namespace: namespace,
+		}},
+	}
+}
+
+// applyKubernetesAccess disables the automatic ServiceAccount token mount
+// and, for instances with Kubernetes API access, mounts a projected token
+// with the configured audience and lifetime into the klaus container in its
+// place.
+func applyKubernetesAccess(spec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance) {
+	spec.AutomountServiceAccountToken = ptr.To(false)
+	if !HasKubernetesAccess(instance) {
+		return
+	}
+	access := instance.Spec.KubernetesAccess
+	expiration := DefaultTokenExpirationSeconds
+	if access.ExpirationSeconds != nil {
+		expiration = *access.ExpirationSeconds
+	}
+
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: KubernetesAccessVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+						Audience:          access.Audience,
+						ExpirationSeconds: ptr.To(expiration),
+						Path:              "token",
+					}},
+					{ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "kube-root-ca.crt"},
+						Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+					}},
+					{DownwardAPI: &corev1.DownwardAPIProjection{
+						Items: []corev1.DownwardAPIVolumeFile{{
+							Path:     "namespace",
+							FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"},
+						}},
+					}},
+				},
+			},
+		},
+	})
+	for i := range spec.Containers {
+		if spec.Containers[i].Name != AppKlaus {
+			continue
+		}
+		spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      KubernetesAccessVolumeName,
+			MountPath: ServiceAccountTokenMountPath,
+			ReadOnly:  true,
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func kubernetesAccessInstance(access *klausv1alpha1.KubernetesAccess) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:            "user@example.com",
+			KubernetesAccess: access,
+		},
+	}
+}
+
+func findVolume(spec corev1.PodSpec, name string) *corev1.Volume {
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == name {
+			return &spec.Volumes[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildPodTemplate_NoKubernetesAccess(t *testing.T) {
+	for _, access := range []*klausv1alpha1.KubernetesAccess{nil, {Enabled: false, ClusterRole: "view"}} {
+		spec := BuildPodTemplate(kubernetesAccessInstance(access), "klaus:latest", "", nil).Spec
+		if spec.AutomountServiceAccountToken == nil || *spec.AutomountServiceAccountToken {
+			t.Errorf("automountServiceAccountToken = %v, want false", spec.AutomountServiceAccountToken)
+		}
+		if findVolume(spec, KubernetesAccessVolumeName) != nil {
+			t.Error("expected no token volume without Kubernetes API access")
+		}
+	}
+}
+
+func TestBuildPodTemplate_KubernetesAccess(t *testing.T) {
+	instance := kubernetesAccessInstance(&klausv1alpha1.KubernetesAccess{Enabled: true, Audience: "vault"})
+	spec := BuildPodTemplate(instance, "klaus:latest", "", nil).Spec
+	if spec.AutomountServiceAccountToken == nil || *spec.AutomountServiceAccountToken {
+		t.Errorf("automountServiceAccountToken = %v, want the projected token instead", spec.AutomountServiceAccountToken)
+	}
+
+	volume := findVolume(spec, KubernetesAccessVolumeName)
+	if volume == nil || volume.Projected == nil || len(volume.Projected.Sources) != 3 {
+		t.Fatalf("volume = %+v, want the token, CA bundle and namespace projected", volume)
+	}
+	token := volume.Projected.Sources[0].ServiceAccountToken
+	if token == nil || token.Audience != "vault" || ptr.Deref(token.ExpirationSeconds, 0) != DefaultTokenExpirationSeconds {
+		t.Errorf("token = %+v, want the audience and the default expiration", token)
+	}
+
+	var mounted bool
+	for _, m := range spec.Containers[0].VolumeMounts {
+		if m.Name == KubernetesAccessVolumeName && m.MountPath == ServiceAccountTokenMountPath && m.ReadOnly {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Errorf("volume mounts = %+v, want the token at the in-cluster location", spec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestBuildKubernetesAccessRoleBinding(t *testing.T) {
+	if binding := BuildKubernetesAccessRoleBinding(kubernetesAccessInstance(&klausv1alpha1.KubernetesAccess{Enabled: true}), "ns"); binding != nil {
+		t.Errorf("binding = %+v, want none without a ClusterRole", binding)
+	}
+
+	instance := kubernetesAccessInstance(&klausv1alpha1.KubernetesAccess{Enabled: true, ClusterRole: "klaus-pod-reader"})
+	binding := BuildKubernetesAccessRoleBinding(instance, "klaus-user-user-example-com")
+	if binding == nil || binding.Name != "my-agent" || binding.Namespace != "klaus-user-user-example-com" {
+		t.Fatalf("binding = %+v", binding)
+	}
+	if binding.RoleRef.Kind != "ClusterRole" || binding.RoleRef.Name != "klaus-pod-reader" {
+		t.Errorf("roleRef = %+v, want the configured ClusterRole", binding.RoleRef)
+	}
+	if len(binding.Subjects) != 1 || binding.Subjects[0].Kind != rbacv1.ServiceAccountKind || binding.Subjects[0].Name != "my-agent" ||
+		binding.Subjects[0].Namespace != "klaus-user-user-example-com" {
+		t.Errorf("subjects = %+v, want the instance ServiceAccount", binding.Subjects)
+	}
+}
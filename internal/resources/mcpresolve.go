@@ -1,11 +1,16 @@
 package resources
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"fmt"
 	"maps"
+	"path"
 	"slices"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
@@ -18,6 +23,66 @@ type ResolvedMCPConfig struct {
 
 	// Secrets holds aggregated secretRefs from all resolved KlausMCPServer objects.
 	Secrets []klausv1alpha1.MCPServerSecret
+
+	// Artifacts maps KlausMCPServer name to the OCI artifact of its stdio
+	// server, mounted by ApplyMCPServerArtifacts.
+	Artifacts map[string]string
+}
+
+// MCPServerArtifactVolumeName returns the volume name for the OCI artifact
+// of a KlausMCPServer. Volume names are DNS labels, while KlausMCPServer
+// names may be longer and contain dots: those are sanitized, truncated and
+// suffixed with a hash of the name to stay unique.
+func MCPServerArtifactVolumeName(name string) string {
+	volume := "mcp-" + name
+	if len(validation.IsDNS1123Label(volume)) == 0 {
+		return volume
+	}
+	sum := sha256.Sum256([]byte(name))
+	return fmt.Sprintf("%s-%x", sanitizeIdentifier(volume, validation.DNS1123LabelMaxLength-11), sum[:5])
+}
+
+// MCPServerArtifactMountPath returns the mount path for the OCI artifact of
+// a KlausMCPServer.
+func MCPServerArtifactMountPath(name string) string {
+	return path.Join(MCPServerArtifactBasePath, name)
+}
+
+// MCPServerArtifactCommand resolves the command of a KlausMCPServer with an
+// OCI artifact: relative commands, e.g. bin/server, point into the mount,
+// absolute ones are kept so that an interpreter of the instance image can
+// run a package from the artifact.
+func MCPServerArtifactCommand(name, command string) string {
+	if path.IsAbs(command) {
+		return command
+	}
+	return path.Join(MCPServerArtifactMountPath(name), command)
+}
+
+// ApplyMCPServerArtifacts mounts the OCI artifacts of stdio MCP servers
+// read-only into the klaus container as image volumes.
+func ApplyMCPServerArtifacts(spec *corev1.PodSpec, artifacts map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(artifacts)) {
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: MCPServerArtifactVolumeName(name),
+			VolumeSource: corev1.VolumeSource{
+				Image: &corev1.ImageVolumeSource{
+					Reference:  artifacts[name],
+					PullPolicy: corev1.PullIfNotPresent,
+				},
+			},
+		})
+		for i := range spec.Containers {
+			if spec.Containers[i].Name != AppKlaus {
+				continue
+			}
+			spec.Containers[i].VolumeMounts = append(spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      MCPServerArtifactVolumeName(name),
+				MountPath: MCPServerArtifactMountPath(name),
+				ReadOnly:  true,
+			})
+		}
+	}
 }
 
 // ServerConfigToRawExtension converts a KlausMCPServerSpec into a
@@ -25,6 +90,40 @@ type ResolvedMCPConfig struct {
 // field is excluded -- it is used for pod-level env injection only, not for
 // .mcp.json assembly.
 func ServerConfigToRawExtension(spec *klausv1alpha1.KlausMCPServerSpec) (runtime.RawExtension, error) {
+	return marshalServerConfig(serverConfig(spec))
+}
+
+// ReferencedServerConfigToRawExtension converts a KlausMCPServerSpec into
+// the MCP server config JSON of an instance referencing it with ref: the
+// headers and env of ref are merged over those of spec, and its timeout is
+// added.
+func ReferencedServerConfigToRawExtension(spec *klausv1alpha1.KlausMCPServerSpec, ref klausv1alpha1.MCPServerReference) (runtime.RawExtension, error) {
+	merged := *spec
+	merged.Headers = mergeStringMaps(spec.Headers, ref.Headers)
+	merged.Env = mergeStringMaps(spec.Env, ref.Env)
+	config := serverConfig(&merged)
+	if ref.TimeoutMs != nil {
+		config["timeout"] = *ref.TimeoutMs
+	}
+	return marshalServerConfig(config)
+}
+
+// mergeStringMaps returns a copy of base with overrides applied, or base
+// itself without overrides.
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]string, len(overrides))
+	}
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// serverConfig returns the .mcp.json entry of a KlausMCPServerSpec.
+func serverConfig(spec *klausv1alpha1.KlausMCPServerSpec) map[string]any {
 	config := make(map[string]any)
 
 	if spec.Type != "" {
@@ -45,7 +144,10 @@ func ServerConfigToRawExtension(spec *klausv1alpha1.KlausMCPServerSpec) (runtime
 	if len(spec.Headers) > 0 {
 		config["headers"] = spec.Headers
 	}
+	return config
+}
 
+func marshalServerConfig(config map[string]any) (runtime.RawExtension, error) {
 	raw, err := json.Marshal(config)
 	if err != nil {
 		return runtime.RawExtension{}, err
@@ -2,9 +2,13 @@ package resources
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
@@ -280,3 +284,97 @@ func collectEnvVars(secrets []klausv1alpha1.MCPServerSecret) map[string]secretRe
 	}
 	return result
 }
+
+func TestMCPServerArtifactCommand(t *testing.T) {
+	tests := []struct{ command, want string }{
+		{"bin/server", "/var/lib/klaus/mcp-servers/github/bin/server"},
+		{"./server", "/var/lib/klaus/mcp-servers/github/server"},
+		{"/usr/bin/node", "/usr/bin/node"},
+	}
+	for _, tt := range tests {
+		if got := MCPServerArtifactCommand("github", tt.command); got != tt.want {
+			t.Errorf("MCPServerArtifactCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestApplyMCPServerArtifacts(t *testing.T) {
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{Name: AppKlaus}, {Name: "sidecar"}}}
+	ApplyMCPServerArtifacts(spec, map[string]string{
+		"kubernetes": "gsoci.azurecr.io/giantswarm/mcp-servers/kubernetes:v2.0.0",
+		"github":     "gsoci.azurecr.io/giantswarm/mcp-servers/github:v1.0.0",
+	})
+
+	if len(spec.Volumes) != 2 || spec.Volumes[0].Name != "mcp-github" ||
+		spec.Volumes[0].Image == nil || spec.Volumes[0].Image.Reference != "gsoci.azurecr.io/giantswarm/mcp-servers/github:v1.0.0" {
+		t.Fatalf("volumes = %+v, want sorted image volumes", spec.Volumes)
+	}
+	mounts := spec.Containers[0].VolumeMounts
+	if len(mounts) != 2 || mounts[1].MountPath != "/var/lib/klaus/mcp-servers/kubernetes" || !mounts[1].ReadOnly {
+		t.Errorf("klaus mounts = %+v, want read-only artifact mounts", mounts)
+	}
+	if len(spec.Containers[1].VolumeMounts) != 0 {
+		t.Errorf("sidecar mounts = %+v, want none", spec.Containers[1].VolumeMounts)
+	}
+}
+
+func TestMCPServerArtifactVolumeName(t *testing.T) {
+	if got := MCPServerArtifactVolumeName("github"); got != "mcp-github" {
+		t.Errorf("MCPServerArtifactVolumeName(github) = %q, want mcp-github", got)
+	}
+	long := strings.Repeat("kubernetes-", 10)
+	for _, name := range []string{long + "a", long + "b", "github.example.com"} {
+		got := MCPServerArtifactVolumeName(name)
+		if errs := validation.IsDNS1123Label(got); len(errs) > 0 {
+			t.Errorf("MCPServerArtifactVolumeName(%q) = %q: %v", name, got, errs)
+		}
+	}
+	if MCPServerArtifactVolumeName(long+"a") == MCPServerArtifactVolumeName(long+"b") {
+		t.Error("expected long names sharing a prefix to get distinct volume names")
+	}
+}
+
+func TestReferencedServerConfigToRawExtension(t *testing.T) {
+	spec := &klausv1alpha1.KlausMCPServerSpec{
+		Type:    "streamable-http",
+		URL:     "https://github-mcp.example.com/mcp",
+		Headers: map[string]string{"Authorization": "Bearer ${GITHUB_TOKEN}", "X-GitHub-Org": "giantswarm"},
+	}
+	ref := klausv1alpha1.MCPServerReference{
+		Name:      "github",
+		Headers:   map[string]string{"X-GitHub-Org": "giantswarm-playground"},
+		Env:       map[string]string{"LOG_LEVEL": "debug"},
+		TimeoutMs: ptr.To(int64(30000)),
+	}
+
+	ext, err := ReferencedServerConfigToRawExtension(spec, ref)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var config struct {
+		Headers map[string]string `json:"headers"`
+		Env     map[string]string `json:"env"`
+		Timeout int64             `json:"timeout"`
+	}
+	if err := json.Unmarshal(ext.Raw, &config); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+	if config.Headers["X-GitHub-Org"] != "giantswarm-playground" || config.Headers["Authorization"] != "Bearer ${GITHUB_TOKEN}" {
+		t.Errorf("headers = %v, want the org overridden and the token kept", config.Headers)
+	}
+	if config.Env["LOG_LEVEL"] != "debug" || config.Timeout != 30000 {
+		t.Errorf("env = %v, timeout = %d; want the overrides", config.Env, config.Timeout)
+	}
+	if spec.Headers["X-GitHub-Org"] != "giantswarm" {
+		t.Errorf("shared headers = %v, want them unchanged", spec.Headers)
+	}
+
+	ext, err = ReferencedServerConfigToRawExtension(spec, klausv1alpha1.MCPServerReference{Name: "github"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	base, _ := ServerConfigToRawExtension(spec)
+	if string(ext.Raw) != string(base.Raw) {
+		t.Errorf("config = %s, want %s without overrides", ext.Raw, base.Raw)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/certs"
 )
 
 // BuildMCPServerCRD creates an unstructured MCPServer CRD for registering a
@@ -28,6 +29,15 @@ func BuildMCPServerCRD(instance *klausv1alpha1.KlausInstance, instanceNamespace
 	if toolPrefix != "" {
 		spec["toolPrefix"] = toolPrefix
 	}
+	// With mTLS, muster verifies the serving certificate against the CA of
+	// the client certificate Secret and presents that client certificate.
+	if TLSEnabled(instance) {
+		spec["tls"] = map[string]any{
+			"serverName":          ServiceHost(instance, instanceNamespace),
+			"caSecretRef":         map[string]any{"name": ClientCertSecretName(instance), "key": certs.CAKey},
+			"clientCertSecretRef": map[string]any{"name": ClientCertSecretName(instance)},
+		}
+	}
 
 	mcpServer := &unstructured.Unstructured{
 		Object: map[string]any{
@@ -45,6 +55,13 @@ func BuildMCPServerCRD(instance *klausv1alpha1.KlausInstance, instanceNamespace
 			"spec": spec,
 		},
 	}
+	// Mock instances are labeled so muster users and tooling can tell them
+	// apart from production agents.
+	if instance.Spec.MockMode {
+		labels := mcpServer.GetLabels()
+		labels[LabelMock] = envValueTrue
+		mcpServer.SetLabels(labels)
+	}
 
 	return mcpServer
 }
@@ -73,3 +90,30 @@ func BuildOperatorMCPServerCRD(operatorServiceURL, musterNamespace string) *unst
 		},
 	}
 }
+
+// Annotations of instance Services registered with the service gateway
+// backend. Gateways discovering MCP servers by annotation list the Services
+// carrying AnnotationMCPURL.
+const (
+	// AnnotationMCPURL is the URL of the instance's MCP endpoint.
+	AnnotationMCPURL = "klaus.giantswarm.io/mcp-url"
+
+	// AnnotationMCPTransport is the MCP transport, always streamable-http.
+	AnnotationMCPTransport = "klaus.giantswarm.io/mcp-transport"
+
+	// AnnotationMCPToolPrefix is the tool name prefix of spec.muster.toolPrefix.
+	AnnotationMCPToolPrefix = "klaus.giantswarm.io/mcp-tool-prefix"
+)
+
+// GatewayServiceAnnotations returns the discovery annotations of the
+// Service of an instance whose Service lives in instanceNamespace.
+func GatewayServiceAnnotations(instance *klausv1alpha1.KlausInstance, instanceNamespace string) map[string]string {
+	annotations := map[string]string{
+		AnnotationMCPURL:       ServiceEndpoint(instance, instanceNamespace) + "/mcp",
+		AnnotationMCPTransport: "streamable-http",
+	}
+	if instance.Spec.Muster != nil && instance.Spec.Muster.ToolPrefix != "" {
+		annotations[AnnotationMCPToolPrefix] = instance.Spec.Muster.ToolPrefix
+	}
+	return annotations
+}
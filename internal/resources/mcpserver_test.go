@@ -0,0 +1,29 @@
+package resources
+
+import (
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestGatewayServiceAnnotations(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{}
+	instance.Name = "my-agent"
+	instance.Spec.Owner = "user@example.com"
+
+	got := GatewayServiceAnnotations(instance, "klaus-user-user")
+	if got[AnnotationMCPURL] != ServiceEndpoint(instance, "klaus-user-user")+"/mcp" {
+		t.Errorf("%s = %q", AnnotationMCPURL, got[AnnotationMCPURL])
+	}
+	if got[AnnotationMCPTransport] != "streamable-http" {
+		t.Errorf("%s = %q, want streamable-http", AnnotationMCPTransport, got[AnnotationMCPTransport])
+	}
+	if _, ok := got[AnnotationMCPToolPrefix]; ok {
+		t.Errorf("tool prefix set without spec.muster.toolPrefix: %v", got)
+	}
+
+	instance.Spec.Muster = &klausv1alpha1.MusterConfig{ToolPrefix: "agent"}
+	if got := GatewayServiceAnnotations(instance, "klaus-user-user"); got[AnnotationMCPToolPrefix] != "agent" {
+		t.Errorf("%s = %q, want agent", AnnotationMCPToolPrefix, got[AnnotationMCPToolPrefix])
+	}
+}
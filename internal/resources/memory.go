@@ -0,0 +1,137 @@
+package resources
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// SoulConfigKey is the ConfigMap key holding the soul of an inline
+	// personality.
+	SoulConfigKey = "soul"
+
+	// MemoryConfigKey is the ConfigMap key holding the CLAUDE.md of the
+	// extensions directory: the soul of the personality followed by
+	// spec.memory.content.
+	MemoryConfigKey = "memory"
+
+	// MemoryPath is where the CLAUDE.md of the extensions directory is
+	// mounted, which Claude Code loads as memory.
+	MemoryPath = ExtensionsBasePath + "/CLAUDE.md"
+
+	// MemoryFileName is the file name of memory files.
+	MemoryFileName = "CLAUDE.md"
+)
+
+// memoryContent returns spec.memory.content of the instance.
+func memoryContent(instance *klausv1alpha1.KlausInstance) string {
+	if instance.Spec.Memory == nil {
+		return ""
+	}
+	return instance.Spec.Memory.Content
+}
+
+// renderMemory renders the CLAUDE.md of the extensions directory from the
+// soul of the personality and the memory content of the instance.
+func renderMemory(soul, content string) string {
+	var parts []string
+	for _, part := range []string{soul, content} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// memoryFilePaths returns the sorted paths of spec.memory.files.
+func memoryFilePaths(instance *klausv1alpha1.KlausInstance) []string {
+	if instance.Spec.Memory == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(instance.Spec.Memory.Files))
+}
+
+// memoryFileConfigKey returns the ConfigMap key of the ith memory file in
+// the order of memoryFilePaths, as paths are no valid keys.
+func memoryFileConfigKey(i int) string {
+	return "memoryfile-" + strconv.Itoa(i)
+}
+
+// SetPersonalitySoul renders the SOUL.md read from the personality artifact
+// of an instance into the memory in its ConfigMap. The soul of an inline
+// personality is already rendered by BuildConfigMap.
+func SetPersonalitySoul(instance *klausv1alpha1.KlausInstance, cm *corev1.ConfigMap, soul string) error {
+	if soul == "" {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	values := templateValues(instance)
+	cm.Data[MemoryConfigKey] = renderMemory(expandTemplate(soul, values), expandTemplate(memoryContent(instance), values))
+	return checkConfigSize(cm.Data)
+}
+
+// validateMemory ensures that the memory files of the instance are CLAUDE.md
+// files inside the workspace.
+func validateMemory(instance *klausv1alpha1.KlausInstance) error {
+	paths := memoryFilePaths(instance)
+	if len(paths) > 0 && instance.Spec.Workspace == nil {
+		return fmt.Errorf("spec.memory.files requires spec.workspace to be set")
+	}
+	for _, p := range paths {
+		if path.IsAbs(p) || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../") {
+			return fmt.Errorf("spec.memory.files: path %q must be a clean path relative to the workspace", p)
+		}
+		if path.Base(p) != MemoryFileName {
+			return fmt.Errorf("spec.memory.files: path %q must name a %s file", p, MemoryFileName)
+		}
+	}
+	return nil
+}
+
+// applyMemory mounts the memory in the configuration as the CLAUDE.md of
+// the extensions directory and adds that directory to CLAUDE_ADD_DIRS, so
+// the personality identity and standing instructions reach the agent.
+// spec.loadAdditionalDirsMemory set to false turns loading it off.
+func applyMemory(podSpec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance, data map[string]string) {
+	if data[MemoryConfigKey] == "" {
+		return
+	}
+	loadMemory := instance.Spec.LoadAdditionalDirsMemory == nil || *instance.Spec.LoadAdditionalDirsMemory
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name != AppKlaus {
+			continue
+		}
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+			Name:      ConfigVolumeName,
+			MountPath: MemoryPath,
+			SubPath:   MemoryConfigKey,
+			ReadOnly:  true,
+		})
+
+		addDirs := ""
+		if j := slices.IndexFunc(c.Env, func(e corev1.EnvVar) bool { return e.Name == "CLAUDE_ADD_DIRS" }); j >= 0 {
+			addDirs = c.Env[j].Value
+		}
+		if !slices.Contains(strings.Split(addDirs, ","), ExtensionsBasePath) {
+			addDirs = strings.TrimPrefix(addDirs+","+ExtensionsBasePath, ",")
+			setEnvVar(c, "CLAUDE_ADD_DIRS", addDirs)
+		}
+		if loadMemory {
+			setEnvVar(c, "CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD", envValueTrue)
+		}
+	}
+}
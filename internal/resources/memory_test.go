@@ -0,0 +1,159 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func soulInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:       "test@example.com",
+			Personality: "registry.example.com/personalities/reviewer@sha256:abc",
+		},
+	}
+}
+
+func TestSetPersonalitySoul(t *testing.T) {
+	instance := soulInstance()
+	instance.Spec.Memory = &klausv1alpha1.MemoryConfig{Content: "Use British spelling."}
+	cm := &corev1.ConfigMap{}
+	if err := SetPersonalitySoul(instance, cm, ""); err != nil || cm.Data != nil {
+		t.Fatalf("SetPersonalitySoul(\"\") = %v, data %v, want nothing set", err, cm.Data)
+	}
+	if err := SetPersonalitySoul(instance, cm, "You review code.\n"); err != nil {
+		t.Fatalf("SetPersonalitySoul() error = %v", err)
+	}
+	if want := "You review code.\n\nUse British spelling.\n"; cm.Data[MemoryConfigKey] != want {
+		t.Errorf("data[%s] = %q, want %q", MemoryConfigKey, cm.Data[MemoryConfigKey], want)
+	}
+	if err := SetPersonalitySoul(instance, cm, strings.Repeat("x", ConfigMapDataLimit+1)); err == nil {
+		t.Error("SetPersonalitySoul() of an oversized soul succeeded, want an error")
+	}
+}
+
+func TestBuildConfigMap_Memory(t *testing.T) {
+	instance := soulInstance()
+	instance.Spec.Personality = ""
+	instance.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{Soul: "You are careful."}
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{}
+	instance.Spec.Memory = &klausv1alpha1.MemoryConfig{
+		Content: "Run make test before committing.",
+		Files: map[string]string{
+			"services/api/CLAUDE.md": "The API is generated.",
+			"CLAUDE.md":              "This is a monorepo.",
+		},
+	}
+
+	cm, err := BuildConfigMap(instance, "test-ns")
+	if err != nil {
+		t.Fatalf("BuildConfigMap() error = %v", err)
+	}
+	if want := "You are careful.\n\nRun make test before committing.\n"; cm.Data[MemoryConfigKey] != want {
+		t.Errorf("data[%s] = %q, want %q", MemoryConfigKey, cm.Data[MemoryConfigKey], want)
+	}
+	if cm.Data[SoulConfigKey] != "You are careful." {
+		t.Errorf("data[%s] = %q, want the inline soul", SoulConfigKey, cm.Data[SoulConfigKey])
+	}
+	if cm.Data["memoryfile-0"] != "This is a monorepo." || cm.Data["memoryfile-1"] != "The API is generated." {
+		t.Errorf("memory files = %q, %q, want them in path order", cm.Data["memoryfile-0"], cm.Data["memoryfile-1"])
+	}
+
+	mounts := BuildVolumeMounts(instance)
+	if m := findMount(mounts, "/workspace/services/api/CLAUDE.md"); m == nil || m.SubPath != "memoryfile-1" || !m.ReadOnly {
+		t.Errorf("services/api/CLAUDE.md mount = %+v, want the memoryfile-1 key", m)
+	}
+	if m := findMount(mounts, "/workspace/CLAUDE.md"); m == nil || m.SubPath != "memoryfile-0" {
+		t.Errorf("CLAUDE.md mount = %+v, want the memoryfile-0 key", m)
+	}
+}
+
+func TestBuildPodTemplate_Memory(t *testing.T) {
+	instance := soulInstance()
+	data := map[string]string{MemoryConfigKey: "You review code.\n"}
+
+	tmpl := BuildPodTemplate(instance, "klaus:latest", "", data)
+
+	c := tmpl.Spec.Containers[0]
+	m := findMount(c.VolumeMounts, MemoryPath)
+	if m == nil || m.Name != ConfigVolumeName || m.SubPath != MemoryConfigKey || !m.ReadOnly {
+		t.Errorf("memory mount at %s = %+v, want the %s key", MemoryPath, m, MemoryConfigKey)
+	}
+	assertEnvValue(t, c.Env, "CLAUDE_ADD_DIRS", ExtensionsBasePath)
+	assertEnvValue(t, c.Env, "CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD", "true")
+}
+
+func TestBuildPodTemplate_MemoryWithExtensions(t *testing.T) {
+	instance := soulInstance()
+	instance.Spec.AddDirs = []string{"/workspace/docs"}
+	instance.Spec.Skills = map[string]klausv1alpha1.SkillConfig{"review": {Content: "Review."}}
+	instance.Spec.LoadAdditionalDirsMemory = ptr.To(false)
+	data := map[string]string{MemoryConfigKey: "You review code.\n"}
+
+	tmpl := BuildPodTemplate(instance, "klaus:latest", "", data)
+
+	c := tmpl.Spec.Containers[0]
+	var addDirs string
+	for _, env := range c.Env {
+		if env.Name == "CLAUDE_ADD_DIRS" {
+			addDirs = env.Value
+		}
+	}
+	if strings.Count(addDirs, ExtensionsBasePath) != 1 || !strings.Contains(addDirs, "/workspace/docs") {
+		t.Errorf("CLAUDE_ADD_DIRS = %q, want /workspace/docs and the extensions directory once", addDirs)
+	}
+	assertEnvAbsent(t, c.Env, "CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD")
+}
+
+func TestBuildPodTemplate_NoMemory(t *testing.T) {
+	tmpl := BuildPodTemplate(soulInstance(), "klaus:latest", "", map[string]string{})
+
+	if m := findMount(tmpl.Spec.Containers[0].VolumeMounts, MemoryPath); m != nil {
+		t.Errorf("memory mounted without one in the configuration: %+v", m)
+	}
+	assertEnvAbsent(t, tmpl.Spec.Containers[0].Env, "CLAUDE_ADD_DIRS")
+}
+
+func TestValidateMemory(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		workspace bool
+		wantErr   string
+	}{
+		{name: "workspace root", path: "CLAUDE.md", workspace: true},
+		{name: "subdirectory", path: "services/api/CLAUDE.md", workspace: true},
+		{name: "without workspace", path: "CLAUDE.md", wantErr: "requires spec.workspace"},
+		{name: "absolute", path: "/etc/CLAUDE.md", workspace: true, wantErr: "relative to the workspace"},
+		{name: "outside the workspace", path: "../CLAUDE.md", workspace: true, wantErr: "relative to the workspace"},
+		{name: "unclean", path: "./CLAUDE.md", workspace: true, wantErr: "relative to the workspace"},
+		{name: "other file", path: "README.md", workspace: true, wantErr: "CLAUDE.md file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := soulInstance()
+			instance.Spec.Memory = &klausv1alpha1.MemoryConfig{Files: map[string]string{tt.path: "content"}}
+			if tt.workspace {
+				instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{}
+			}
+			err := validateMemory(instance)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateMemory() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateMemory() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
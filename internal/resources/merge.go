@@ -1 +1,201 @@
 package resources
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// InlinePersonalityStatus is recorded in status.personality for instances
+// with an inline personality.
+const InlinePersonalityStatus = "inline"
+
+// MergeInlinePersonality merges the inline personality of an instance into
+// its spec: the personality image applies when spec.image is unset, and its
+// plugins, skills and commands are added unless spec.plugins, spec.skills or
+// spec.commands define the same repository or name, its skill packs are
+// added to spec.skillPacks, its memory is merged into spec.memory, and its
+// template values are the defaults of spec.templateValues. The soul is rendered from the inline
+// personality by BuildConfigMap.
+func MergeInlinePersonality(instance *klausv1alpha1.KlausInstance) {
+	p := instance.Spec.InlinePersonality
+	if p == nil {
+		return
+	}
+
+	if instance.Spec.Image == "" {
+		instance.Spec.Image = p.Image
+	}
+
+	for _, plugin := range p.Plugins {
+		if !hasPluginRepository(instance.Spec.Plugins, plugin.Repository) {
+			instance.Spec.Plugins = append(instance.Spec.Plugins, plugin)
+		}
+	}
+
+	for name, skill := range p.Skills {
+		if _, exists := instance.Spec.Skills[name]; exists {
+			continue
+		}
+		if instance.Spec.Skills == nil {
+			instance.Spec.Skills = make(map[string]klausv1alpha1.SkillConfig)
+		}
+		instance.Spec.Skills[name] = skill
+	}
+
+	for name, command := range p.Commands {
+		if _, exists := instance.Spec.Commands[name]; exists {
+			continue
+		}
+		if instance.Spec.Commands == nil {
+			instance.Spec.Commands = make(map[string]klausv1alpha1.CommandConfig)
+		}
+		instance.Spec.Commands[name] = command
+	}
+
+	for _, ref := range p.SkillPacks {
+		if !slices.ContainsFunc(instance.Spec.SkillPacks, func(existing klausv1alpha1.SkillPackReference) bool { return existing.Name == ref.Name }) {
+			instance.Spec.SkillPacks = append(instance.Spec.SkillPacks, ref)
+		}
+	}
+
+	mergeMemory(instance, p.Memory)
+	for name, value := range p.TemplateValues {
+		if _, exists := instance.Spec.TemplateValues[name]; exists {
+			continue
+		}
+		if instance.Spec.TemplateValues == nil {
+			instance.Spec.TemplateValues = make(map[string]string)
+		}
+		instance.Spec.TemplateValues[name] = value
+	}
+	mergeExtraEnv(instance, p.ExtraEnv, p.ExtraEnvFrom)
+	mergeProbes(instance, p.Probes)
+	mergeScheduling(instance, p.Scheduling)
+}
+
+// mergeMemory merges the personality memory into spec.memory. The
+// personality content comes first, followed by the instance content, and
+// personality files are added unless the instance defines the same path.
+func mergeMemory(instance *klausv1alpha1.KlausInstance, memory *klausv1alpha1.MemoryConfig) {
+	if memory == nil {
+		return
+	}
+	if instance.Spec.Memory == nil {
+		instance.Spec.Memory = &klausv1alpha1.MemoryConfig{}
+	}
+	merged := instance.Spec.Memory
+	merged.Content = renderMemory(memory.Content, merged.Content)
+	for p, content := range memory.Files {
+		if _, exists := merged.Files[p]; exists {
+			continue
+		}
+		if merged.Files == nil {
+			merged.Files = make(map[string]string)
+		}
+		merged.Files[p] = content
+	}
+}
+
+// mergeExtraEnv merges the personality environment into spec.extraEnv and
+// spec.extraEnvFrom. Personality variables are added unless the instance
+// sets the same name; personality sources are placed before the instance
+// sources, which take precedence for keys in both.
+func mergeExtraEnv(instance *klausv1alpha1.KlausInstance, env []corev1.EnvVar, envFrom []corev1.EnvFromSource) {
+	for _, e := range env {
+		if !slices.ContainsFunc(instance.Spec.ExtraEnv, func(existing corev1.EnvVar) bool { return existing.Name == e.Name }) {
+			instance.Spec.ExtraEnv = append(instance.Spec.ExtraEnv, *e.DeepCopy())
+		}
+	}
+	if len(envFrom) == 0 {
+		return
+	}
+	var sources []corev1.EnvFromSource
+	for _, source := range envFrom {
+		if !slices.ContainsFunc(instance.Spec.ExtraEnvFrom, func(existing corev1.EnvFromSource) bool {
+			return equality.Semantic.DeepEqual(existing, source)
+		}) {
+			sources = append(sources, *source.DeepCopy())
+		}
+	}
+	instance.Spec.ExtraEnvFrom = append(sources, instance.Spec.ExtraEnvFrom...)
+}
+
+// mergeProbes applies the personality probes to those not tuned by
+// spec.probes.
+func mergeProbes(instance *klausv1alpha1.KlausInstance, probes *klausv1alpha1.ProbesConfig) {
+	if probes == nil {
+		return
+	}
+	if instance.Spec.Probes == nil {
+		instance.Spec.Probes = &klausv1alpha1.ProbesConfig{}
+	}
+	if instance.Spec.Probes.Liveness == nil {
+		instance.Spec.Probes.Liveness = probes.Liveness.DeepCopy()
+	}
+	if instance.Spec.Probes.Readiness == nil {
+		instance.Spec.Probes.Readiness = probes.Readiness.DeepCopy()
+	}
+	if instance.Spec.Probes.Startup == nil {
+		instance.Spec.Probes.Startup = probes.Startup.DeepCopy()
+	}
+}
+
+// mergeScheduling merges the personality scheduling into spec.scheduling.
+// Node selector labels and tolerations of the personality are added, an
+// instance label of the same key taking precedence; the other fields apply
+// when the instance does not set them.
+func mergeScheduling(instance *klausv1alpha1.KlausInstance, sched *klausv1alpha1.SchedulingConfig) {
+	if sched == nil {
+		return
+	}
+	if instance.Spec.Scheduling == nil {
+		instance.Spec.Scheduling = &klausv1alpha1.SchedulingConfig{}
+	}
+	merged := instance.Spec.Scheduling
+
+	for key, value := range sched.NodeSelector {
+		if _, exists := merged.NodeSelector[key]; exists {
+			continue
+		}
+		if merged.NodeSelector == nil {
+			merged.NodeSelector = make(map[string]string)
+		}
+		merged.NodeSelector[key] = value
+	}
+	for _, toleration := range sched.Tolerations {
+		if !slices.ContainsFunc(merged.Tolerations, func(t corev1.Toleration) bool { return t.MatchToleration(&toleration) }) {
+			merged.Tolerations = append(merged.Tolerations, *toleration.DeepCopy())
+		}
+	}
+	if merged.Affinity == nil {
+		merged.Affinity = sched.Affinity.DeepCopy()
+	}
+	if len(merged.TopologySpreadConstraints) == 0 {
+		for _, c := range sched.TopologySpreadConstraints {
+			merged.TopologySpreadConstraints = append(merged.TopologySpreadConstraints, *c.DeepCopy())
+		}
+	}
+	if merged.PriorityClassName == "" {
+		merged.PriorityClassName = sched.PriorityClassName
+	}
+	if merged.RuntimeClassName == nil && sched.RuntimeClassName != nil {
+		merged.RuntimeClassName = ptr.To(*sched.RuntimeClassName)
+	}
+	if merged.DisruptionBudget == nil {
+		merged.DisruptionBudget = sched.DisruptionBudget.DeepCopy()
+	}
+}
+
+func hasPluginRepository(plugins []klausv1alpha1.PluginReference, repository string) bool {
+	for _, plugin := range plugins {
+		if plugin.Repository == repository {
+			return true
+		}
+	}
+	return false
+}
@@ -1 +1,300 @@
 package resources
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestMergeInlinePersonality(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Plugins: []klausv1alpha1.PluginReference{
+				{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", Tag: "v2.0.0"},
+			},
+			Skills: map[string]klausv1alpha1.SkillConfig{
+				"review": {Content: "instance review"},
+			},
+			Commands: map[string]klausv1alpha1.CommandConfig{
+				"release": {Content: "instance release"},
+			},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				Soul:  "You are a careful Go developer.",
+				Image: "gsoci.azurecr.io/giantswarm/klaus-go:1.25",
+				Plugins: []klausv1alpha1.PluginReference{
+					{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", Tag: "v1.0.0"},
+					{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/go", Tag: "v1.0.0"},
+				},
+				Skills: map[string]klausv1alpha1.SkillConfig{
+					"review": {Content: "personality review"},
+					"test":   {Content: "personality test"},
+				},
+				Commands: map[string]klausv1alpha1.CommandConfig{
+					"release":   {Content: "personality release"},
+					"changelog": {Content: "personality changelog"},
+				},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	if instance.Spec.Image != "gsoci.azurecr.io/giantswarm/klaus-go:1.25" {
+		t.Errorf("Image = %q, want the personality image", instance.Spec.Image)
+	}
+	if len(instance.Spec.Plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %d", len(instance.Spec.Plugins))
+	}
+	if instance.Spec.Plugins[0].Tag != "v2.0.0" {
+		t.Errorf("gs-base tag = %q, want the instance plugin to take precedence", instance.Spec.Plugins[0].Tag)
+	}
+	if instance.Spec.Plugins[1].Repository != "gsoci.azurecr.io/giantswarm/klaus-plugins/go" {
+		t.Errorf("plugins[1] = %q, want the personality plugin", instance.Spec.Plugins[1].Repository)
+	}
+	if instance.Spec.Skills["review"].Content != "instance review" {
+		t.Errorf("review skill = %q, want the instance skill to take precedence", instance.Spec.Skills["review"].Content)
+	}
+	if instance.Spec.Skills["test"].Content != "personality test" {
+		t.Errorf("test skill = %q, want the personality skill", instance.Spec.Skills["test"].Content)
+	}
+	if instance.Spec.Commands["release"].Content != "instance release" {
+		t.Errorf("release command = %q, want the instance command to take precedence", instance.Spec.Commands["release"].Content)
+	}
+	if instance.Spec.Commands["changelog"].Content != "personality changelog" {
+		t.Errorf("changelog command = %q, want the personality command", instance.Spec.Commands["changelog"].Content)
+	}
+}
+
+func TestMergeInlinePersonality_InstanceImageWins(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Image: "custom:latest",
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				Image:  "gsoci.azurecr.io/giantswarm/klaus-go:1.25",
+				Skills: map[string]klausv1alpha1.SkillConfig{"test": {Content: "test"}},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	if instance.Spec.Image != "custom:latest" {
+		t.Errorf("Image = %q, want the instance image", instance.Spec.Image)
+	}
+	if _, ok := instance.Spec.Skills["test"]; !ok {
+		t.Error("expected the personality skill on an instance without skills")
+	}
+}
+
+func TestMergeInlinePersonality_Probes(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Probes: &klausv1alpha1.ProbesConfig{
+				Startup: &klausv1alpha1.ProbeConfig{FailureThreshold: ptr.To[int32](10)},
+			},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				Probes: &klausv1alpha1.ProbesConfig{
+					Liveness: &klausv1alpha1.ProbeConfig{TimeoutSeconds: ptr.To[int32](5)},
+					Startup:  &klausv1alpha1.ProbeConfig{FailureThreshold: ptr.To[int32](120)},
+				},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	probes := instance.Spec.Probes
+	if probes.Liveness == nil || *probes.Liveness.TimeoutSeconds != 5 {
+		t.Errorf("liveness = %+v, want the personality probe", probes.Liveness)
+	}
+	if *probes.Startup.FailureThreshold != 10 {
+		t.Errorf("startup failureThreshold = %d, want the instance probe", *probes.Startup.FailureThreshold)
+	}
+	if probes.Readiness != nil {
+		t.Errorf("readiness = %+v, want the default", probes.Readiness)
+	}
+}
+
+func TestMergeInlinePersonality_Scheduling(t *testing.T) {
+	gpu := corev1.Toleration{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Scheduling: &klausv1alpha1.SchedulingConfig{
+				NodeSelector:      map[string]string{"node-pool": "team-a"},
+				Tolerations:       []corev1.Toleration{gpu},
+				PriorityClassName: "team-a",
+			},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				Scheduling: &klausv1alpha1.SchedulingConfig{
+					NodeSelector: map[string]string{"node-pool": "agents", "accelerator": "gpu-adjacent"},
+					Tolerations: []corev1.Toleration{gpu, {
+						Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "agents", Effect: corev1.TaintEffectNoSchedule,
+					}},
+					PriorityClassName: "agents",
+					RuntimeClassName:  ptr.To("gvisor"),
+				},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	sched := instance.Spec.Scheduling
+	if sched.NodeSelector["node-pool"] != "team-a" || sched.NodeSelector["accelerator"] != "gpu-adjacent" {
+		t.Errorf("node selector = %v, want the instance pool and the personality accelerator", sched.NodeSelector)
+	}
+	if len(sched.Tolerations) != 2 {
+		t.Errorf("tolerations = %+v, want the duplicate dropped", sched.Tolerations)
+	}
+	if sched.PriorityClassName != "team-a" || sched.RuntimeClassName == nil || *sched.RuntimeClassName != "gvisor" {
+		t.Errorf("scheduling = %+v, want the instance priority class and the personality runtime class", sched)
+	}
+}
+
+func TestMergeInlinePersonality_ExtraEnv(t *testing.T) {
+	shared := corev1.EnvFromSource{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "shared"}}}
+	team := corev1.EnvFromSource{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "team"}}}
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:        "test@example.com",
+			ExtraEnv:     []corev1.EnvVar{{Name: "HTTPS_PROXY", Value: "http://proxy.team.example.com"}},
+			ExtraEnvFrom: []corev1.EnvFromSource{team, shared},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				ExtraEnv: []corev1.EnvVar{
+					{Name: "HTTPS_PROXY", Value: "http://proxy.example.com"},
+					{Name: "PIP_INDEX_URL", Value: "https://pypi.example.com/simple"},
+				},
+				ExtraEnvFrom: []corev1.EnvFromSource{
+					{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "defaults"}}},
+					shared,
+				},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	env := instance.Spec.ExtraEnv
+	if len(env) != 2 || env[0].Value != "http://proxy.team.example.com" || env[1].Name != "PIP_INDEX_URL" {
+		t.Errorf("extraEnv = %+v, want the instance proxy and the personality index", env)
+	}
+	var names []string
+	for _, source := range instance.Spec.ExtraEnvFrom {
+		if source.ConfigMapRef != nil {
+			names = append(names, source.ConfigMapRef.Name)
+		} else {
+			names = append(names, source.SecretRef.Name)
+		}
+	}
+	if want := []string{"defaults", "team", "shared"}; !slices.Equal(names, want) {
+		t.Errorf("extraEnvFrom = %v, want %v", names, want)
+	}
+}
+
+func TestMergeInlinePersonality_Memory(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Memory: &klausv1alpha1.MemoryConfig{
+				Content: "Run make test before committing.",
+				Files:   map[string]string{"CLAUDE.md": "instance root"},
+			},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				Memory: &klausv1alpha1.MemoryConfig{
+					Content: "Follow the Go style guide.",
+					Files: map[string]string{
+						"CLAUDE.md":     "personality root",
+						"api/CLAUDE.md": "personality api",
+					},
+				},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	memory := instance.Spec.Memory
+	if want := "Follow the Go style guide.\n\nRun make test before committing.\n"; memory.Content != want {
+		t.Errorf("content = %q, want the personality content first", memory.Content)
+	}
+	if memory.Files["CLAUDE.md"] != "instance root" {
+		t.Errorf("CLAUDE.md = %q, want the instance file to take precedence", memory.Files["CLAUDE.md"])
+	}
+	if memory.Files["api/CLAUDE.md"] != "personality api" {
+		t.Errorf("api/CLAUDE.md = %q, want the personality file", memory.Files["api/CLAUDE.md"])
+	}
+
+	withoutMemory := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:             "test@example.com",
+			InlinePersonality: &klausv1alpha1.InlinePersonality{Memory: &klausv1alpha1.MemoryConfig{Content: "Be brief."}},
+		},
+	}
+	MergeInlinePersonality(withoutMemory)
+	if withoutMemory.Spec.Memory == nil || withoutMemory.Spec.Memory.Content != "Be brief.\n" {
+		t.Errorf("memory = %+v, want the personality memory", withoutMemory.Spec.Memory)
+	}
+}
+
+func TestMergeInlinePersonality_TemplateValues(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:          "test@example.com",
+			TemplateValues: map[string]string{"team": "platform"},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				TemplateValues: map[string]string{"team": "default", "language": "Go"},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	if instance.Spec.TemplateValues["team"] != "platform" {
+		t.Errorf("team = %q, want the instance value to take precedence", instance.Spec.TemplateValues["team"])
+	}
+	if instance.Spec.TemplateValues["language"] != "Go" {
+		t.Errorf("language = %q, want the personality default", instance.Spec.TemplateValues["language"])
+	}
+}
+
+func TestMergeInlinePersonality_SkillPacks(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:      "test@example.com",
+			SkillPacks: []klausv1alpha1.SkillPackReference{{Name: "go"}},
+			InlinePersonality: &klausv1alpha1.InlinePersonality{
+				SkillPacks: []klausv1alpha1.SkillPackReference{{Name: "review"}, {Name: "go"}},
+			},
+		},
+	}
+
+	MergeInlinePersonality(instance)
+
+	var names []string
+	for _, ref := range instance.Spec.SkillPacks {
+		names = append(names, ref.Name)
+	}
+	if !slices.Equal(names, []string{"go", "review"}) {
+		t.Errorf("skill packs = %v, want the instance packs followed by the personality packs", names)
+	}
+}
+
+func TestMergeInlinePersonality_None(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{Owner: "test@example.com"},
+	}
+
+	MergeInlinePersonality(instance)
+
+	if instance.Spec.Image != "" || instance.Spec.Plugins != nil || instance.Spec.Skills != nil {
+		t.Errorf("spec changed without inline personality: %+v", instance.Spec)
+	}
+}
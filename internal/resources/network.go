@@ -0,0 +1,337 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// CABundleVolumeName is the name of the custom CA bundle volume.
+	CABundleVolumeName = "ca-bundle"
+
+	// CABundleMountPath is where the custom CA bundle is mounted.
+	CABundleMountPath = "/etc/klaus/ca"
+
+	// CABundleFile is the file name of the mounted CA bundle.
+	CABundleFile = "ca.crt"
+
+	// DefaultCABundleKey is the ConfigMap key read when
+	// caBundleConfigMapRef.key is unset.
+	DefaultCABundleKey = "ca.crt"
+
+	// CABundleChecksumAnnotation is the pod template annotation that rolls
+	// the pods when the CA bundle changes, as processes read it on start.
+	CABundleChecksumAnnotation = "checksum/ca-bundle"
+
+	// ProxyCredentialsChecksumAnnotation is the pod template annotation
+	// that rolls the pods when the proxy credentials change.
+	ProxyCredentialsChecksumAnnotation = "checksum/proxy-credentials"
+
+	// CATrustContainerName is the init container concatenating the system
+	// CAs of the instance image with the custom CA bundle.
+	CATrustContainerName = "ca-trust"
+
+	// CATrustVolumeName is the emptyDir holding the concatenated bundle.
+	CATrustVolumeName = "ca-trust"
+
+	// CATrustMountPath is where the concatenated bundle is mounted.
+	CATrustMountPath = "/etc/klaus/ca-trust"
+
+	// CATrustFile is the file name of the concatenated bundle.
+	CATrustFile = "ca-certificates.crt"
+
+	// DefaultProxyUsernameKey and DefaultProxyPasswordKey are the Secret
+	// keys read when proxyCredentialsSecretRef does not name them.
+	DefaultProxyUsernameKey = "username"
+	DefaultProxyPasswordKey = "password"
+
+	proxyUsernameEnv = "KLAUS_PROXY_USERNAME"
+	proxyPasswordEnv = "KLAUS_PROXY_PASSWORD"
+)
+
+// systemCABundles are the system bundle locations of Debian, RHEL and Alpine
+// based images, in the order they are tried.
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/cert.pem",
+}
+
+// defaultNoProxy are the hosts always reached without the proxy: loopback
+// and in-cluster Services like muster.
+var defaultNoProxy = []string{"localhost", "127.0.0.1", ".svc", ".cluster.local"}
+
+// HasCABundle reports whether the instance mounts a custom CA bundle.
+func HasCABundle(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.Network != nil && instance.Spec.Network.CABundleConfigMapRef != nil
+}
+
+// CABundleConfigMapName returns the name of the CA bundle ConfigMap copy in
+// the user namespace.
+func CABundleConfigMapName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name + "-ca-bundle"
+}
+
+// CABundleKey returns the key of the CA bundle in the referenced ConfigMap.
+func CABundleKey(instance *klausv1alpha1.KlausInstance) string {
+	if key := instance.Spec.Network.CABundleConfigMapRef.Key; key != "" {
+		return key
+	}
+	return DefaultCABundleKey
+}
+
+// HasProxyCredentials reports whether the instance reads its proxy
+// credentials from a Secret.
+func HasProxyCredentials(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.Network != nil && instance.Spec.Network.ProxyCredentialsSecretRef != nil
+}
+
+// ProxyCredentialsSecretName returns the name of the proxy credentials
+// Secret copy in the user namespace.
+func ProxyCredentialsSecretName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name + "-proxy-credentials"
+}
+
+// ProxyCredentialKeys returns the Secret keys of the proxy username and
+// password.
+func ProxyCredentialKeys(ref *klausv1alpha1.ProxyCredentialsReference) (string, string) {
+	username, password := ref.UsernameKey, ref.PasswordKey
+	if username == "" {
+		username = DefaultProxyUsernameKey
+	}
+	if password == "" {
+		password = DefaultProxyPasswordKey
+	}
+	return username, password
+}
+
+// ProxyCredentialsChecksum returns a checksum of the proxy credentials.
+func ProxyCredentialsChecksum(username, password []byte) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%x\n%x\n", username, password)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// proxyURL returns proxy with the credentials of the pod environment, which
+// Kubernetes expands from the variables defined before it.
+func proxyURL(instance *klausv1alpha1.KlausInstance, proxy string) string {
+	if !HasProxyCredentials(instance) {
+		return proxy
+	}
+	scheme, rest, ok := strings.Cut(proxy, "://")
+	if !ok {
+		return proxy
+	}
+	return scheme + "://$(" + proxyUsernameEnv + "):$(" + proxyPasswordEnv + ")@" + rest
+}
+
+// CABundleChecksum returns a checksum of the CA bundle.
+func CABundleChecksum(bundle string) string {
+	sum := sha256.Sum256([]byte(bundle))
+	return hex.EncodeToString(sum[:])
+}
+
+// buildNetworkEnvVars returns the proxy and CA bundle variables of the
+// instance. Both spellings of the proxy variables are set, as curl and git
+// only read the lowercase ones and Node.js tools the uppercase ones. Proxy
+// credentials are read from the Secret copy and precede the proxy URLs
+// expanding them. The CA bundle is added to the trusted CAs of Node.js, and
+// appended to the system CAs for OpenSSL, git and Python requests.
+func buildNetworkEnvVars(instance *klausv1alpha1.KlausInstance) []corev1.EnvVar {
+	network := instance.Spec.Network
+	if network == nil {
+		return nil
+	}
+
+	var envs []corev1.EnvVar
+	if HasProxyCredentials(instance) {
+		usernameKey, passwordKey := ProxyCredentialKeys(network.ProxyCredentialsSecretRef)
+		for _, cred := range []struct{ name, key string }{{proxyUsernameEnv, usernameKey}, {proxyPasswordEnv, passwordKey}} {
+			envs = append(envs, corev1.EnvVar{Name: cred.name, ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ProxyCredentialsSecretName(instance)},
+					Key:                  cred.key,
+				},
+			}})
+		}
+	}
+	proxies := []struct{ name, value string }{
+		{"HTTP_PROXY", network.HTTPProxy},
+		{"HTTPS_PROXY", network.HTTPSProxy},
+	}
+	for _, proxy := range proxies {
+		if proxy.value == "" {
+			continue
+		}
+		value := proxyURL(instance, proxy.value)
+		envs = append(envs,
+			corev1.EnvVar{Name: proxy.name, Value: value},
+			corev1.EnvVar{Name: strings.ToLower(proxy.name), Value: value},
+		)
+	}
+	if network.HTTPProxy != "" || network.HTTPSProxy != "" {
+		noProxy := noProxyList(network.NoProxy)
+		envs = append(envs,
+			corev1.EnvVar{Name: "NO_PROXY", Value: noProxy},
+			corev1.EnvVar{Name: "no_proxy", Value: noProxy},
+		)
+	}
+
+	if HasCABundle(instance) {
+		envs = append(envs, corev1.EnvVar{Name: "NODE_EXTRA_CA_CERTS", Value: path.Join(CABundleMountPath, CABundleFile)})
+		// These replace the system CAs, so they point at both.
+		trust := path.Join(CATrustMountPath, CATrustFile)
+		for _, name := range []string{"SSL_CERT_FILE", "GIT_SSL_CAINFO", "REQUESTS_CA_BUNDLE"} {
+			envs = append(envs, corev1.EnvVar{Name: name, Value: trust})
+		}
+	}
+	return envs
+}
+
+// caTrustScript concatenates the first system bundle found with the custom
+// CA bundle.
+func caTrustScript() string {
+	out := path.Join(CATrustMountPath, CATrustFile)
+	return fmt.Sprintf(`set -e
+: > %[1]s
+for f in %[2]s; do
+  if [ -f "$f" ]; then cat "$f" >> %[1]s; break; fi
+done
+cat %[3]s >> %[1]s`, out, strings.Join(systemCABundles, " "), path.Join(CABundleMountPath, CABundleFile))
+}
+
+// buildCATrustInitContainer returns the init container writing the
+// concatenated CA bundle, running the image of the Klaus container so the
+// system CAs are those the agent would trust without a custom bundle.
+func buildCATrustInitContainer(image string) corev1.Container {
+	return corev1.Container{
+		Name:    CATrustContainerName,
+		Image:   image,
+		Command: []string{"sh", "-c"},
+		Args:    []string{caTrustScript()},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("16Mi"),
+			},
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: CABundleVolumeName, MountPath: CABundleMountPath, ReadOnly: true},
+			{Name: CATrustVolumeName, MountPath: CATrustMountPath},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                ptr.To(int64(1000)),
+			RunAsGroup:               ptr.To(int64(1000)),
+			AllowPrivilegeEscalation: ptr.To(false),
+			ReadOnlyRootFilesystem:   ptr.To(true),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}
+}
+
+// noProxyList appends the default hosts missing from the comma-separated
+// noProxy list.
+func noProxyList(noProxy string) string {
+	var hosts []string
+	seen := map[string]bool{}
+	for _, host := range append(strings.Split(noProxy, ","), defaultNoProxy...) {
+		host = strings.TrimSpace(host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return strings.Join(hosts, ",")
+}
+
+// applyNetwork sets the proxy and CA bundle variables on the Klaus container
+// and the init containers cloning and setting up the workspace, replacing
+// user-defined variables of the same name, and mounts the CA bundle. With a
+// CA bundle, an init container running first writes the concatenated
+// bundle.
+func applyNetwork(podSpec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance) {
+	envs := buildNetworkEnvVars(instance)
+	if len(envs) == 0 {
+		return
+	}
+
+	if HasCABundle(instance) {
+		podSpec.Volumes = append(podSpec.Volumes,
+			corev1.Volume{
+				Name: CABundleVolumeName,
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: CABundleConfigMapName(instance)},
+					Items:                []corev1.KeyToPath{{Key: CABundleKey(instance), Path: CABundleFile}},
+				}},
+			},
+			corev1.Volume{
+				Name:         CATrustVolumeName,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			},
+		)
+	}
+
+	names := make(map[string]bool, len(envs))
+	for _, env := range envs {
+		names[env.Name] = true
+	}
+	apply := func(c *corev1.Container) {
+		// Appended in order, so the proxy URLs follow the credentials they
+		// expand.
+		c.Env = slices.DeleteFunc(c.Env, func(e corev1.EnvVar) bool { return names[e.Name] })
+		c.Env = append(c.Env, envs...)
+		if HasCABundle(instance) {
+			c.VolumeMounts = append(c.VolumeMounts,
+				corev1.VolumeMount{Name: CABundleVolumeName, MountPath: CABundleMountPath, ReadOnly: true},
+				corev1.VolumeMount{Name: CATrustVolumeName, MountPath: CATrustMountPath, ReadOnly: true},
+			)
+		}
+	}
+	for i := range podSpec.InitContainers {
+		apply(&podSpec.InitContainers[i])
+	}
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != AppKlaus {
+			continue
+		}
+		apply(&podSpec.Containers[i])
+		if HasCABundle(instance) {
+			podSpec.InitContainers = append([]corev1.Container{buildCATrustInitContainer(podSpec.Containers[i].Image)}, podSpec.InitContainers...)
+		}
+	}
+}
+
+// ApplyCABundleChecksum records the checksum of the CA bundle on the pod
+// template. It leaves templates of instances without a CA bundle unchanged.
+func ApplyCABundleChecksum(template *corev1.PodTemplateSpec, checksum string) {
+	setChecksumAnnotation(template, CABundleChecksumAnnotation, checksum)
+}
+
+// ApplyProxyCredentialsChecksum records the checksum of the proxy
+// credentials on the pod template. It leaves templates of instances
+// without proxy credentials unchanged.
+func ApplyProxyCredentialsChecksum(template *corev1.PodTemplateSpec, checksum string) {
+	setChecksumAnnotation(template, ProxyCredentialsChecksumAnnotation, checksum)
+}
+
+func setChecksumAnnotation(template *corev1.PodTemplateSpec, annotation, checksum string) {
+	if checksum == "" {
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[annotation] = checksum
+}
@@ -0,0 +1,210 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	batchv1 "k8s.io/api/batch/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// WorkspaceOutputComponent is the component label of the Jobs pushing
+	// the workspace changes of a KlausJob.
+	WorkspaceOutputComponent = "workspace-output"
+
+	// DefaultOutputTitleTemplate is the pull request title and commit
+	// message when spec.workspace.output.prTitleTemplate is not set.
+	DefaultOutputTitleTemplate = "klaus: {{.Name}}"
+
+	// outputUnchanged is the termination message of an output Job that
+	// found no changes to push.
+	outputUnchanged = "unchanged"
+
+	// outputCommitterName is the author and committer name of the pushed
+	// commit; the e-mail is the owner.
+	outputCommitterName = "Klaus"
+)
+
+// outputTemplateData are the fields available to the spec.workspace.output
+// templates.
+type outputTemplateData struct {
+	Name      string
+	Namespace string
+	Owner     string
+	Branch    string
+}
+
+// JobOutputName returns the name of the Job pushing the workspace changes of
+// a KlausJob.
+func JobOutputName(job *klausv1alpha1.KlausJob) string {
+	return JobResourceName(job) + "-output"
+}
+
+// JobWorkspaceOutput returns spec.workspace.output of a KlausJob, or nil.
+func JobWorkspaceOutput(job *klausv1alpha1.KlausJob) *klausv1alpha1.WorkspaceOutput {
+	if job.Spec.Workspace == nil {
+		return nil
+	}
+	return job.Spec.Workspace.Output
+}
+
+// RenderWorkspaceOutput renders the push branch and the pull request title
+// of a KlausJob with spec.workspace.output. Missing template fields are an
+// error.
+func RenderWorkspaceOutput(job *klausv1alpha1.KlausJob) (string, string, error) {
+	out := JobWorkspaceOutput(job)
+	if out == nil {
+		return "", "", fmt.Errorf("KlausJob %q has no spec.workspace.output", job.Name)
+	}
+	data := outputTemplateData{Name: job.Name, Namespace: job.Namespace, Owner: job.Spec.Owner}
+	branch, err := renderOutputTemplate("pushBranch", out.PushBranch, data)
+	if err != nil {
+		return "", "", err
+	}
+	if branch = strings.TrimSpace(branch); branch == "" {
+		return "", "", fmt.Errorf("spec.workspace.output.pushBranch renders to an empty branch name")
+	}
+	data.Branch = branch
+
+	titleTemplate := out.PRTitleTemplate
+	if titleTemplate == "" {
+		titleTemplate = DefaultOutputTitleTemplate
+	}
+	title, err := renderOutputTemplate("prTitleTemplate", titleTemplate, data)
+	if err != nil {
+		return "", "", err
+	}
+	return branch, strings.TrimSpace(title), nil
+}
+
+func renderOutputTemplate(field, text string, data outputTemplateData) (string, error) {
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing spec.workspace.output.%s: %w", field, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering spec.workspace.output.%s: %w", field, err)
+	}
+	return b.String(), nil
+}
+
+// OutputPullRequestBody returns the description of the pull request opened
+// for the workspace changes of a KlausJob, including the agent result.
+func OutputPullRequestBody(job *klausv1alpha1.KlausJob) string {
+	body := fmt.Sprintf("Changes made by the agent of KlausJob `%s/%s`, owned by %s.", job.Namespace, job.Name, job.Spec.Owner)
+	if result := strings.TrimSpace(job.Status.Result); result != "" {
+		body += "\n\n" + result
+	}
+	return body
+}
+
+// BuildJobOutputJob creates the Job committing the workspace changes of a
+// finished KlausJob run with the commit message title and pushing them to
+// branch. It mounts the workspace PVC the run left behind and reports the
+// pushed commit and the base branch of the checkout in its termination
+// message.
+func BuildJobOutputJob(job *klausv1alpha1.KlausJob, namespace, gitImage, branch, title string) *batchv1.Job {
+	instance := JobInstance(job)
+	script := buildOutputScript(instance, branch, title)
+	batchJob := buildWorkspaceGitJob(instance, namespace, gitImage, script, "", true)
+	batchJob.Name = JobOutputName(job)
+	batchJob.Labels["app.kubernetes.io/component"] = WorkspaceOutputComponent
+	batchJob.Labels[LabelJob] = job.Name
+	return batchJob
+}
+
+// buildOutputScript generates the shell script of the output Job. Like the
+// workspace git Jobs, it resets the config of the checkout the agent wrote
+// before running git in it. Changes are committed as the owner; nothing is pushed when the checkout still is
+// at the cloned ref. The push is not forced, so an existing branch is only
+// fast-forwarded. The base is the cloned branch, or the remote default
+// branch, and left empty when the checkout is not on a branch.
+func buildOutputScript(instance *klausv1alpha1.KlausInstance, branch, title string) string {
+	ws := instance.Spec.Workspace
+	quotedWs := shellQuote(WorkspaceMountPath)
+
+	parts := []string{
+		"set -e",
+		fmt.Sprintf("if [ ! -d %s/.git ]; then echo 'workspace has not been cloned' >&2; exit 1; fi", quotedWs),
+		fmt.Sprintf("cd %s", quotedWs),
+		fmt.Sprintf("git config --global --add safe.directory %s", quotedWs),
+	}
+	parts = append(parts, workspaceGitConfigCommands(instance)...)
+	parts = append(parts,
+		"BRANCH="+shellQuote(branch),
+		`git check-ref-format --branch "$BRANCH" >/dev/null`,
+	)
+	if ws.GitRef != "" {
+		parts = append(parts, "BASE="+shellQuote(ws.GitRef))
+	} else {
+		// The clone records the remote default branch as origin/HEAD.
+		parts = append(parts,
+			"BASE=$(git symbolic-ref --short -q refs/remotes/origin/HEAD || true)",
+			`BASE="${BASE#origin/}"`,
+		)
+	}
+	parts = append(parts,
+		fmt.Sprintf("export GIT_AUTHOR_NAME=%s GIT_COMMITTER_NAME=%s", outputCommitterName, outputCommitterName),
+		fmt.Sprintf("export GIT_AUTHOR_EMAIL=%s GIT_COMMITTER_EMAIL=%s", shellQuote(instance.Spec.Owner), shellQuote(instance.Spec.Owner)),
+		"git add -A",
+		"if ! git diff --cached --quiet; then git commit -q -m "+shellQuote(title)+"; fi",
+		`START=$(git rev-parse -q --verify "origin/$BASE^{commit}" || git rev-parse -q --verify "$BASE^{commit}" || true)`,
+		`if [ -n "$START" ] && git merge-base --is-ancestor HEAD "$START"; then`,
+		"  echo 'no workspace changes to push'",
+		"  echo "+outputUnchanged+" > /dev/termination-log",
+		"  exit 0",
+		"fi",
+		`git push origin "HEAD:refs/heads/$BRANCH"`,
+		`git rev-parse -q --verify "refs/remotes/origin/$BASE" >/dev/null || BASE=`,
+		`printf 'commit=%s\nbase=%s\n' "$(git rev-parse HEAD)" "$BASE" > /dev/termination-log`,
+	)
+	return strings.Join(parts, "\n")
+}
+
+// ParseOutputTerminationMessage returns the pushed commit and the base
+// branch from the termination message of an output Job. changed is false
+// when the Job found nothing to push.
+func ParseOutputTerminationMessage(message string) (commit, base string, changed bool) {
+	if strings.TrimSpace(message) == outputUnchanged {
+		return "", "", false
+	}
+	for line := range strings.SplitSeq(message, "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "commit":
+			commit = value
+		case "base":
+			base = value
+		}
+	}
+	return commit, base, true
+}
+
+// validateWorkspaceOutput checks that spec.workspace.output has a repository
+// to push to with credentials, a GitHub repository for pull requests and
+// templates that parse.
+func validateWorkspaceOutput(ws *klausv1alpha1.WorkspaceConfig) error {
+	out := ws.Output
+	if ws.GitRepo == "" {
+		return fmt.Errorf("spec.workspace.output requires spec.workspace.gitRepo to be set")
+	}
+	if ws.GitSecretRef == nil && ws.GitHubApp == nil {
+		return fmt.Errorf("spec.workspace.output requires spec.workspace.gitSecretRef or spec.workspace.gitHubApp credentials to push")
+	}
+	if out.CreatePR {
+		if _, _, err := GitHubRepository(ws.GitRepo); err != nil {
+			return fmt.Errorf("spec.workspace.output.createPR: %w", err)
+		}
+	}
+	if _, err := template.New("pushBranch").Parse(out.PushBranch); err != nil {
+		return fmt.Errorf("parsing spec.workspace.output.pushBranch: %w", err)
+	}
+	if _, err := template.New("prTitleTemplate").Parse(out.PRTitleTemplate); err != nil {
+		return fmt.Errorf("parsing spec.workspace.output.prTitleTemplate: %w", err)
+	}
+	return nil
+}
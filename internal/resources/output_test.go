@@ -0,0 +1,167 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testOutputJob(output *klausv1alpha1.WorkspaceOutput) *klausv1alpha1.KlausJob {
+	return &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "fix", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:  "user@example.com",
+			Prompt: "Fix the flaky test",
+			Workspace: &klausv1alpha1.WorkspaceConfig{
+				GitRepo:      "https://github.com/org/repo.git",
+				GitRef:       "develop",
+				GitSecretRef: &klausv1alpha1.GitSecretReference{Name: "github-token", Key: "pat"},
+				Output:       output,
+			},
+		},
+	}
+}
+
+func TestRenderWorkspaceOutput(t *testing.T) {
+	branch, title, err := RenderWorkspaceOutput(testOutputJob(&klausv1alpha1.WorkspaceOutput{PushBranch: "klaus/{{.Name}}"}))
+	if err != nil {
+		t.Fatalf("RenderWorkspaceOutput: %v", err)
+	}
+	if branch != "klaus/fix" || title != "klaus: fix" {
+		t.Errorf("branch = %q, title = %q; want klaus/fix with the default title", branch, title)
+	}
+
+	_, title, err = RenderWorkspaceOutput(testOutputJob(&klausv1alpha1.WorkspaceOutput{
+		PushBranch:      "agent/{{.Namespace}}",
+		PRTitleTemplate: "{{.Owner}}: {{.Branch}}",
+	}))
+	if err != nil || title != "user@example.com: agent/klaus-system" {
+		t.Errorf("title = %q, %v", title, err)
+	}
+
+	if _, _, err := RenderWorkspaceOutput(testOutputJob(&klausv1alpha1.WorkspaceOutput{PushBranch: "{{.Missing}}"})); err == nil {
+		t.Error("expected an error for an unknown template field")
+	}
+	if _, _, err := RenderWorkspaceOutput(testOutputJob(&klausv1alpha1.WorkspaceOutput{PushBranch: "{{if false}}x{{end}}"})); err == nil || !strings.Contains(err.Error(), "empty branch") {
+		t.Errorf("error = %v, want an empty branch error", err)
+	}
+}
+
+func TestBuildJobOutputJob(t *testing.T) {
+	job := testOutputJob(&klausv1alpha1.WorkspaceOutput{PushBranch: "klaus/fix"})
+	batchJob := BuildJobOutputJob(job, "klaus-user-user", "", "klaus/fix", "klaus: it's fixed")
+
+	if batchJob.Name != "fix-job-output" || batchJob.Labels[LabelJob] != "fix" || batchJob.Labels["app.kubernetes.io/component"] != WorkspaceOutputComponent {
+		t.Errorf("name = %q, labels = %v", batchJob.Name, batchJob.Labels)
+	}
+	if batchJob.Spec.Template.Labels[LabelJob] != "fix" {
+		t.Errorf("pod labels = %v, want the KlausJob label", batchJob.Spec.Template.Labels)
+	}
+	spec := batchJob.Spec.Template.Spec
+	if spec.Containers[0].Image != DefaultGitCloneImage {
+		t.Errorf("image = %q, want the default git clone image", spec.Containers[0].Image)
+	}
+	if ref := workspaceGitEnvValueFrom(spec.Containers[0].Env, workspaceGitTokenEnv); ref == nil ||
+		ref.SecretKeyRef.Name != GitSecretName(JobInstance(job)) || ref.SecretKeyRef.Key != "pat" {
+		t.Errorf("token env = %+v, want the git credential Secret", ref)
+	}
+
+	script := spec.Containers[0].Args[0]
+	if strings.Contains(script, "x-access-token") {
+		t.Errorf("script writes the token into the remote URL:\n%s", script)
+	}
+	for _, want := range []string{
+		"git config --file .git/config remote.origin.url 'https://github.com/org/repo.git'",
+		"BRANCH='klaus/fix'",
+		"BASE='develop'",
+		"GIT_AUTHOR_EMAIL='user@example.com'",
+		`git commit -q -m 'klaus: it'\''s fixed'`,
+		`git push origin "HEAD:refs/heads/$BRANCH"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "--force") {
+		t.Errorf("script force-pushes:\n%s", script)
+	}
+
+	job.Spec.Workspace.GitRef = ""
+	script = BuildJobOutputJob(job, "klaus-user-user", "", "klaus/fix", "klaus: fix").Spec.Template.Spec.Containers[0].Args[0]
+	if !strings.Contains(script, "refs/remotes/origin/HEAD") {
+		t.Errorf("script does not use the remote default branch without gitRef:\n%s", script)
+	}
+}
+
+func TestParseOutputTerminationMessage(t *testing.T) {
+	if _, _, changed := ParseOutputTerminationMessage("unchanged\n"); changed {
+		t.Error("expected no changes")
+	}
+	commit, base, changed := ParseOutputTerminationMessage("commit=abc123\nbase=main\n")
+	if !changed || commit != "abc123" || base != "main" {
+		t.Errorf("commit = %q, base = %q, changed = %v", commit, base, changed)
+	}
+	if _, base, _ := ParseOutputTerminationMessage("commit=abc123\nbase=\n"); base != "" {
+		t.Errorf("base = %q, want empty for a detached checkout", base)
+	}
+}
+
+func TestValidateJobSpec_WorkspaceOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*klausv1alpha1.WorkspaceConfig)
+		wantErr string
+	}{
+		{name: "valid", mutate: func(*klausv1alpha1.WorkspaceConfig) {}},
+		{
+			name:    "without gitRepo",
+			mutate:  func(ws *klausv1alpha1.WorkspaceConfig) { ws.GitRepo, ws.GitSecretRef = "", nil },
+			wantErr: "requires spec.workspace.gitRepo",
+		},
+		{
+			name:    "without credentials",
+			mutate:  func(ws *klausv1alpha1.WorkspaceConfig) { ws.GitSecretRef = nil },
+			wantErr: "credentials to push",
+		},
+		{
+			name:    "pull request outside GitHub",
+			mutate:  func(ws *klausv1alpha1.WorkspaceConfig) { ws.GitRepo = "https://gitlab.com/org/group/repo.git" },
+			wantErr: "spec.workspace.output.createPR",
+		},
+		{
+			name:    "invalid template",
+			mutate:  func(ws *klausv1alpha1.WorkspaceConfig) { ws.Output.PRTitleTemplate = "{{.Name" },
+			wantErr: "parsing spec.workspace.output.prTitleTemplate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := testOutputJob(&klausv1alpha1.WorkspaceOutput{PushBranch: "klaus/{{.Name}}", CreatePR: true})
+			tt.mutate(job.Spec.Workspace)
+			err := ValidateJobSpec(job)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateInstanceSpec_WorkspaceOutput(t *testing.T) {
+	instance := JobInstance(testOutputJob(&klausv1alpha1.WorkspaceOutput{PushBranch: "klaus/fix"}))
+	if err := ValidateInstanceSpec(instance); err == nil || !strings.Contains(err.Error(), "only supported by KlausJobs") {
+		t.Errorf("error = %v, want output rejected for instances", err)
+	}
+	instance.Spec.Workspace.Output = nil
+	if err := ValidateInstanceSpec(instance); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
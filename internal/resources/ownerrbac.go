@@ -0,0 +1,50 @@
+package resources
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OwnerRoleBindingName is the name of the RoleBinding granting the owner
+// access to their namespace.
+const OwnerRoleBindingName = "klaus-owner"
+
+// OwnerRoleBindingLabels returns labels for the owner RoleBinding. The
+// binding is shared by all instances of the owner, so it carries no
+// instance-specific labels.
+func OwnerRoleBindingLabels(owner string) map[string]string {
+	return map[string]string{
+		LabelAppName:                  AppKlaus,
+		LabelManagedBy:                AppKlausOperator,
+		"app.kubernetes.io/component": "owner-access",
+		LabelOwner:                    sanitizeLabelValue(owner),
+	}
+}
+
+// BuildOwnerRoleBinding creates the RoleBinding granting the owner's
+// identity the given ClusterRole in their namespace. subjectPrefix is
+// prepended to the owner to match the username the API server derives from
+// the OIDC token (its --oidc-username-prefix). It returns nil when no
+// ClusterRole is configured.
+func BuildOwnerRoleBinding(owner, namespace, clusterRole, subjectPrefix string) *rbacv1.RoleBinding {
+	if clusterRole == "" {
+		return nil
+	}
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      OwnerRoleBindingName,
+			Namespace: namespace,
+			Labels:    OwnerRoleBindingLabels(owner),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		},
+		Subjects: []rbacv1.Subject{{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.UserKind,
+			Name:     subjectPrefix + owner,
+		}},
+	}
+}
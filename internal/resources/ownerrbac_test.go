@@ -0,0 +1,33 @@
+package resources
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestBuildOwnerRoleBinding(t *testing.T) {
+	if rb := BuildOwnerRoleBinding("user@example.com", "klaus-user-user-example-com", "", ""); rb != nil {
+		t.Errorf("expected no RoleBinding without a ClusterRole, got %+v", rb)
+	}
+
+	rb := BuildOwnerRoleBinding("user@example.com", "klaus-user-user-example-com", "klaus-operator-owner", "oidc:")
+	if rb == nil {
+		t.Fatal("expected a RoleBinding")
+	}
+	if rb.Name != OwnerRoleBindingName || rb.Namespace != "klaus-user-user-example-com" {
+		t.Errorf("RoleBinding = %s/%s", rb.Namespace, rb.Name)
+	}
+	if rb.RoleRef.Kind != "ClusterRole" || rb.RoleRef.Name != "klaus-operator-owner" || rb.RoleRef.APIGroup != rbacv1.GroupName {
+		t.Errorf("RoleRef = %+v", rb.RoleRef)
+	}
+	if len(rb.Subjects) != 1 || rb.Subjects[0].Kind != rbacv1.UserKind || rb.Subjects[0].Name != "oidc:user@example.com" {
+		t.Errorf("Subjects = %+v, want the prefixed owner", rb.Subjects)
+	}
+	if rb.Labels[LabelManagedBy] != AppKlausOperator || rb.Labels[LabelOwner] != "user-example-com" {
+		t.Errorf("Labels = %v", rb.Labels)
+	}
+	if _, ok := rb.Labels["app.kubernetes.io/instance"]; ok {
+		t.Error("the owner RoleBinding is shared by all instances and must not carry an instance label")
+	}
+}
@@ -0,0 +1,25 @@
+package resources
+
+import (
+	"slices"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// IsOwnedBy reports whether a user with the given groups owns the instance:
+// the user is the primary owner or one of spec.owners, or one of the groups
+// is listed in spec.ownerGroups. An empty user owns nothing.
+func IsOwnedBy(instance *klausv1alpha1.KlausInstance, user string, groups []string) bool {
+	if user == "" {
+		return false
+	}
+	if instance.Spec.Owner == user || slices.Contains(instance.Spec.Owners, user) {
+		return true
+	}
+	for _, group := range groups {
+		if group != "" && slices.Contains(instance.Spec.OwnerGroups, group) {
+			return true
+		}
+	}
+	return false
+}
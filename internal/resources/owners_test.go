@@ -0,0 +1,38 @@
+package resources
+
+import (
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestIsOwnedBy(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:       "user@example.com",
+			Owners:      []string{"teammate@example.com"},
+			OwnerGroups: []string{"platform-team"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		user   string
+		groups []string
+		want   bool
+	}{
+		{name: "primary owner", user: "user@example.com", want: true},
+		{name: "additional owner", user: "teammate@example.com", want: true},
+		{name: "owner group member", user: "other@example.com", groups: []string{"dev", "platform-team"}, want: true},
+		{name: "stranger", user: "other@example.com", groups: []string{"dev"}},
+		{name: "empty user", user: "", groups: []string{"platform-team"}},
+		{name: "empty group", user: "other@example.com", groups: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsOwnedBy(instance, tt.user, tt.groups); got != tt.want {
+				t.Errorf("IsOwnedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package resources
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LabelArch is the well-known node label carrying the node CPU architecture.
+const LabelArch = corev1.LabelArchStable
+
+// FormatPlatform renders an OCI platform as os/architecture[/variant].
+func FormatPlatform(os, arch, variant string) string {
+	platform := os + "/" + arch
+	if variant != "" {
+		platform += "/" + variant
+	}
+	return platform
+}
+
+// LinuxArchitectures returns the sorted, de-duplicated CPU architectures of
+// the linux entries in platforms. Variants are dropped since nodes only
+// advertise the architecture.
+func LinuxArchitectures(platforms []string) []string {
+	var archs []string
+	for _, platform := range platforms {
+		parts := strings.Split(platform, "/")
+		if len(parts) < 2 || parts[0] != "linux" || parts[1] == "" {
+			continue
+		}
+		if !slices.Contains(archs, parts[1]) {
+			archs = append(archs, parts[1])
+		}
+	}
+	slices.Sort(archs)
+	return archs
+}
+
+// ApplyArchitectureAffinity restricts the pod to nodes of the given
+// architectures through a required node affinity on kubernetes.io/arch.
+// The requirement is added to every existing node selector term so it is
+// combined with, not alternative to, affinity already present. A nil or
+// empty archs leaves the pod spec unchanged.
+func ApplyArchitectureAffinity(podSpec *corev1.PodSpec, archs []string) {
+	if len(archs) == 0 {
+		return
+	}
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      LabelArch,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   slices.Clone(archs),
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := podSpec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range selector.NodeSelectorTerms {
+		term := &selector.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+}
@@ -0,0 +1,79 @@
+package resources
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestFormatPlatform(t *testing.T) {
+	if got := FormatPlatform("linux", "amd64", ""); got != "linux/amd64" {
+		t.Errorf("FormatPlatform() = %q", got)
+	}
+	if got := FormatPlatform("linux", "arm64", "v8"); got != "linux/arm64/v8" {
+		t.Errorf("FormatPlatform() = %q", got)
+	}
+}
+
+func TestLinuxArchitectures(t *testing.T) {
+	got := LinuxArchitectures([]string{
+		"linux/arm64/v8",
+		"linux/amd64",
+		"windows/amd64",
+		"unknown/unknown",
+		"linux/arm64",
+		"linux",
+	})
+	want := []string{"amd64", "arm64"}
+	if !slices.Equal(got, want) {
+		t.Errorf("LinuxArchitectures() = %v, want %v", got, want)
+	}
+
+	if got := LinuxArchitectures(nil); len(got) != 0 {
+		t.Errorf("LinuxArchitectures(nil) = %v, want none", got)
+	}
+}
+
+func TestApplyArchitectureAffinity(t *testing.T) {
+	podSpec := &corev1.PodSpec{}
+	ApplyArchitectureAffinity(podSpec, []string{"arm64"})
+
+	terms := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 || len(terms[0].MatchExpressions) != 1 {
+		t.Fatalf("unexpected node selector terms: %+v", terms)
+	}
+	expr := terms[0].MatchExpressions[0]
+	if expr.Key != "kubernetes.io/arch" || expr.Operator != corev1.NodeSelectorOpIn || !slices.Equal(expr.Values, []string{"arm64"}) {
+		t.Errorf("unexpected requirement: %+v", expr)
+	}
+}
+
+func TestApplyArchitectureAffinity_CombinesWithExistingTerms(t *testing.T) {
+	zone := corev1.NodeSelectorRequirement{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}
+	pool := corev1.NodeSelectorRequirement{Key: "pool", Operator: corev1.NodeSelectorOpExists}
+	podSpec := &corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{zone}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{pool}},
+			},
+		},
+	}}}
+
+	ApplyArchitectureAffinity(podSpec, []string{"amd64", "arm64"})
+
+	for i, term := range podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) != 2 || term.MatchExpressions[1].Key != LabelArch {
+			t.Errorf("term %d: expected architecture requirement to be appended, got %+v", i, term.MatchExpressions)
+		}
+	}
+}
+
+func TestApplyArchitectureAffinity_NoArchitectures(t *testing.T) {
+	podSpec := &corev1.PodSpec{}
+	ApplyArchitectureAffinity(podSpec, nil)
+	if podSpec.Affinity != nil {
+		t.Errorf("expected no affinity, got %+v", podSpec.Affinity)
+	}
+}
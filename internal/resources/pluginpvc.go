@@ -0,0 +1,280 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"slices"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// PluginSourceImage mounts every plugin as an OCI image volume.
+	PluginSourceImage = "image"
+	// PluginSourcePVC mounts plugins as sub-paths of a shared PVC per user
+	// namespace, synced once by an operator-run Job, for clusters without
+	// image volume support or registry access from instance pods.
+	PluginSourcePVC = "pvc"
+	// PluginSourceInit pulls plugins into an emptyDir with an init
+	// container running PluginSyncCommand, for clusters without image
+	// volume support whose instance pods can reach the registry.
+	PluginSourceInit = "init"
+	// PluginSourceAuto selects PluginSourceImage when the API server
+	// accepts image volumes and PluginSourceInit otherwise.
+	PluginSourceAuto = "auto"
+
+	// PluginsPVCName is the shared plugins PVC in each user namespace.
+	PluginsPVCName = "klaus-plugins"
+	// PluginsVolumeName is the pod volume of the shared plugins PVC.
+	PluginsVolumeName = "plugins"
+	// PluginCatalogName is the ConfigMap mapping the sub-paths of the
+	// shared plugins PVC to the plugin references synced into them.
+	PluginCatalogName = "klaus-plugin-catalog"
+
+	// PluginSyncCommand is the operator subcommand run by plugin sync Jobs.
+	PluginSyncCommand = "sync-plugins"
+	// PluginSyncContainerName is the container of plugin sync Jobs.
+	PluginSyncContainerName = "sync"
+	// PluginPullContainerName is the init container pulling plugins with
+	// PluginSourceInit.
+	PluginPullContainerName = "pull-plugins"
+	// PluginSyncMountPath is where plugin sync Jobs mount the plugins PVC.
+	PluginSyncMountPath = "/plugins"
+	// PluginSyncComponent is the component label of the shared plugin
+	// storage objects.
+	PluginSyncComponent = "plugin-sync"
+
+	// DefaultPluginsPVCSize is the default size of the plugins PVC.
+	DefaultPluginsPVCSize = "5Gi"
+
+	// pluginSyncDeadline bounds how long a plugin sync Job may run.
+	pluginSyncDeadline int64 = 900
+	// pluginSyncTTL is how long a finished plugin sync Job is kept. A failed
+	// Job is retried once it has expired.
+	pluginSyncTTL int32 = 600
+)
+
+// PluginPVCOptions configures the shared plugins PVC used with
+// PluginSourcePVC.
+type PluginPVCOptions struct {
+	// StorageClass of the plugins PVC; the cluster default when empty. It
+	// must support ReadWriteMany so instances on any node can mount it.
+	StorageClass string
+
+	// Size of the plugins PVC.
+	Size resource.Quantity
+
+	// SyncImage runs PluginSyncCommand, normally the operator image.
+	SyncImage string
+}
+
+// PluginSubPath returns the sub-path of a plugin on the shared plugins PVC.
+// It is derived from the full reference, so every tag or digest is synced
+// once into its own directory, and doubles as the plugin catalog key.
+func PluginSubPath(plugin klausv1alpha1.PluginReference) string {
+	sum := sha256.Sum256([]byte(PluginImageReference(plugin)))
+	return fmt.Sprintf("%s-%x", klausoci.ShortName(plugin.Repository), sum[:5])
+}
+
+// PluginStorageLabels returns the labels of the plugins PVC, the plugin
+// catalog and plugin sync Jobs. They are shared by all instances in the
+// namespace, so they carry no instance-specific labels.
+func PluginStorageLabels() map[string]string {
+	return map[string]string{
+		LabelAppName:                  AppKlaus,
+		LabelManagedBy:                AppKlausOperator,
+		"app.kubernetes.io/component": PluginSyncComponent,
+	}
+}
+
+// BuildPluginsPVC creates the shared plugins PVC of a user namespace.
+func BuildPluginsPVC(namespace string, opts PluginPVCOptions) *corev1.PersistentVolumeClaim {
+	size := opts.Size
+	if size.IsZero() {
+		size = resource.MustParse(DefaultPluginsPVCSize)
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PluginsPVCName,
+			Namespace: namespace,
+			Labels:    PluginStorageLabels(),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if opts.StorageClass != "" {
+		pvc.Spec.StorageClassName = ptr.To(opts.StorageClass)
+	}
+	return pvc
+}
+
+// BuildPluginCatalog creates an empty plugin catalog ConfigMap.
+func BuildPluginCatalog(namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PluginCatalogName,
+			Namespace: namespace,
+			Labels:    PluginStorageLabels(),
+		},
+	}
+}
+
+// PendingPlugins returns the sub-paths and references of the plugins that
+// are not in the catalog yet.
+func PendingPlugins(catalog *corev1.ConfigMap, plugins []klausv1alpha1.PluginReference) map[string]string {
+	pending := map[string]string{}
+	for _, plugin := range plugins {
+		subPath := PluginSubPath(plugin)
+		if _, ok := catalog.Data[subPath]; !ok {
+			pending[subPath] = PluginImageReference(plugin)
+		}
+	}
+	return pending
+}
+
+// PluginSyncJobName returns the name of the Job syncing a set of plugins.
+// It is derived from the set, so reconciles of instances needing the same
+// plugins share one Job.
+func PluginSyncJobName(pending map[string]string) string {
+	h := sha256.New()
+	for _, subPath := range slices.Sorted(maps.Keys(pending)) {
+		_, _ = fmt.Fprintf(h, "%s=%s\n", subPath, pending[subPath])
+	}
+	return fmt.Sprintf("klaus-plugin-sync-%x", h.Sum(nil)[:5])
+}
+
+// BuildPluginSyncJob creates the Job pulling the pending plugins into their
+// sub-paths of the plugins PVC with the operator's OCI client.
+func BuildPluginSyncJob(namespace string, opts PluginPVCOptions, pending map[string]string) *batchv1.Job {
+	labels := PluginStorageLabels()
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PluginSyncJobName(pending),
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(2)),
+			ActiveDeadlineSeconds:   ptr.To(pluginSyncDeadline),
+			TTLSecondsAfterFinished: ptr.To(pluginSyncTTL),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot:   ptr.To(true),
+						RunAsUser:      ptr.To(int64(1000)),
+						RunAsGroup:     ptr.To(int64(1000)),
+						FSGroup:        ptr.To(int64(1000)),
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []corev1.Container{
+						pluginSyncContainer(PluginSyncContainerName, opts.SyncImage, pending),
+					},
+					Volumes: []corev1.Volume{{
+						Name: PluginsVolumeName,
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: PluginsPVCName},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// pluginSyncContainer creates a container pulling plugins into their
+// sub-paths of the plugins volume with PluginSyncCommand.
+func pluginSyncContainer(name, image string, plugins map[string]string) corev1.Container {
+	args := []string{PluginSyncCommand, "--dest", PluginSyncMountPath}
+	for _, subPath := range slices.Sorted(maps.Keys(plugins)) {
+		args = append(args, subPath+"="+plugins[subPath])
+	}
+	return corev1.Container{
+		Name:  name,
+		Image: image,
+		Args:  args,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: PluginsVolumeName, MountPath: PluginSyncMountPath},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsNonRoot:             ptr.To(true),
+			AllowPrivilegeEscalation: ptr.To(false),
+			ReadOnlyRootFilesystem:   ptr.To(true),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}
+}
+
+// ApplyPluginPVC replaces the plugin image volumes of a pod spec with
+// read-only sub-path mounts of the shared plugins PVC. The mount paths stay
+// the same, so the agent configuration is unaffected.
+func ApplyPluginPVC(spec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance) {
+	mountPluginSubPaths(spec, instance, corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: PluginsPVCName,
+			ReadOnly:  true,
+		},
+	})
+}
+
+// ApplyPluginInitContainer replaces the plugin image volumes of a pod spec
+// with read-only sub-path mounts of an emptyDir, into which an init
+// container running image pulls every plugin before the agent starts.
+func ApplyPluginInitContainer(spec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance, image string) {
+	if len(instance.Spec.Plugins) == 0 {
+		return
+	}
+	mountPluginSubPaths(spec, instance, corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}})
+
+	plugins := map[string]string{}
+	for _, plugin := range instance.Spec.Plugins {
+		plugins[PluginSubPath(plugin)] = PluginImageReference(plugin)
+	}
+	spec.InitContainers = append(spec.InitContainers, pluginSyncContainer(PluginPullContainerName, image, plugins))
+}
+
+// mountPluginSubPaths replaces the plugin image volumes of a pod spec with
+// read-only mounts of the plugins' sub-paths of a plugins volume.
+func mountPluginSubPaths(spec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance, source corev1.VolumeSource) {
+	if len(instance.Spec.Plugins) == 0 {
+		return
+	}
+
+	subPaths := map[string]string{}
+	for _, plugin := range instance.Spec.Plugins {
+		subPaths[PluginVolumeName(plugin)] = PluginSubPath(plugin)
+	}
+	spec.Volumes = slices.DeleteFunc(spec.Volumes, func(v corev1.Volume) bool {
+		_, ok := subPaths[v.Name]
+		return ok
+	})
+	spec.Volumes = append(spec.Volumes, corev1.Volume{Name: PluginsVolumeName, VolumeSource: source})
+
+	for i := range spec.Containers {
+		mounts := spec.Containers[i].VolumeMounts
+		for j := range mounts {
+			subPath, ok := subPaths[mounts[j].Name]
+			if !ok {
+				continue
+			}
+			mounts[j].Name = PluginsVolumeName
+			mounts[j].SubPath = subPath
+			mounts[j].ReadOnly = true
+		}
+	}
+}
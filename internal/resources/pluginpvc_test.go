@@ -0,0 +1,212 @@
+package resources
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+var (
+	pluginPVCBase = klausv1alpha1.PluginReference{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", Tag: "v1.0.0"}
+	pluginPVCSRE  = klausv1alpha1.PluginReference{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-sre", Digest: "sha256:abc123"}
+)
+
+func TestPluginSubPath(t *testing.T) {
+	subPath := PluginSubPath(pluginPVCBase)
+	if !strings.HasPrefix(subPath, "gs-base-") {
+		t.Errorf("PluginSubPath() = %q, want the plugin name as prefix", subPath)
+	}
+	// Sub-paths double as ConfigMap keys.
+	if !regexp.MustCompile(`^[-._a-zA-Z0-9]+$`).MatchString(subPath) {
+		t.Errorf("PluginSubPath() = %q is not a valid ConfigMap key", subPath)
+	}
+	other := pluginPVCBase
+	other.Tag = "v1.1.0"
+	if PluginSubPath(other) == subPath {
+		t.Error("expected a different sub-path for another tag")
+	}
+	if PluginSubPath(pluginPVCBase) != subPath {
+		t.Error("expected a stable sub-path")
+	}
+}
+
+func TestBuildPluginsPVC(t *testing.T) {
+	pvc := BuildPluginsPVC("klaus-user-user-example-com", PluginPVCOptions{})
+	if pvc.Name != PluginsPVCName || pvc.Spec.StorageClassName != nil {
+		t.Errorf("pvc = %s, storageClass %v", pvc.Name, pvc.Spec.StorageClassName)
+	}
+	if pvc.Spec.AccessModes[0] != corev1.ReadWriteMany {
+		t.Errorf("access modes = %v, want ReadWriteMany", pvc.Spec.AccessModes)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != DefaultPluginsPVCSize {
+		t.Errorf("size = %s, want the default", size.String())
+	}
+
+	pvc = BuildPluginsPVC("ns", PluginPVCOptions{StorageClass: "nfs", Size: resource.MustParse("20Gi")})
+	if *pvc.Spec.StorageClassName != "nfs" {
+		t.Errorf("storage class = %s, want nfs", *pvc.Spec.StorageClassName)
+	}
+	if size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "20Gi" {
+		t.Errorf("size = %s, want 20Gi", size.String())
+	}
+}
+
+func TestPendingPlugins(t *testing.T) {
+	catalog := BuildPluginCatalog("ns")
+	catalog.Data = map[string]string{PluginSubPath(pluginPVCBase): PluginImageReference(pluginPVCBase)}
+
+	pending := PendingPlugins(catalog, []klausv1alpha1.PluginReference{pluginPVCBase, pluginPVCSRE})
+	if len(pending) != 1 || pending[PluginSubPath(pluginPVCSRE)] != PluginImageReference(pluginPVCSRE) {
+		t.Errorf("pending = %v, want only the uncatalogued plugin", pending)
+	}
+}
+
+func TestBuildPluginSyncJob(t *testing.T) {
+	pending := map[string]string{
+		PluginSubPath(pluginPVCSRE):  PluginImageReference(pluginPVCSRE),
+		PluginSubPath(pluginPVCBase): PluginImageReference(pluginPVCBase),
+	}
+	job := BuildPluginSyncJob("ns", PluginPVCOptions{SyncImage: "klaus-operator:1.0.0"}, pending)
+
+	if job.Name != PluginSyncJobName(pending) || !strings.HasPrefix(job.Name, "klaus-plugin-sync-") {
+		t.Errorf("job name = %q", job.Name)
+	}
+	container := job.Spec.Template.Spec.Containers[0]
+	if container.Image != "klaus-operator:1.0.0" {
+		t.Errorf("image = %q", container.Image)
+	}
+	want := []string{PluginSyncCommand, "--dest", PluginSyncMountPath,
+		PluginSubPath(pluginPVCBase) + "=" + PluginImageReference(pluginPVCBase),
+		PluginSubPath(pluginPVCSRE) + "=" + PluginImageReference(pluginPVCSRE)}
+	if strings.Join(container.Args, " ") != strings.Join(want, " ") {
+		t.Errorf("args = %v, want %v", container.Args, want)
+	}
+	if claim := job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim; claim == nil || claim.ClaimName != PluginsPVCName || claim.ReadOnly {
+		t.Errorf("volume = %+v, want the writable plugins PVC", job.Spec.Template.Spec.Volumes[0])
+	}
+}
+
+func TestPluginSyncJobName_DiffersPerSet(t *testing.T) {
+	one := map[string]string{"a": "ref-a"}
+	two := map[string]string{"a": "ref-a", "b": "ref-b"}
+	if PluginSyncJobName(one) == PluginSyncJobName(two) {
+		t.Error("expected different Job names for different plugin sets")
+	}
+}
+
+func TestApplyPluginPVC(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:   "user@example.com",
+			Plugins: []klausv1alpha1.PluginReference{pluginPVCBase, pluginPVCSRE},
+		},
+	}
+	dep := BuildDeployment(instance, "ns", "klaus:latest", "", nil)
+	spec := &dep.Spec.Template.Spec
+	ApplyPluginPVC(spec, instance)
+
+	for _, v := range spec.Volumes {
+		if v.Image != nil {
+			t.Errorf("image volume %s left in place", v.Name)
+		}
+	}
+	var plugins *corev1.Volume
+	for i := range spec.Volumes {
+		if spec.Volumes[i].Name == PluginsVolumeName {
+			plugins = &spec.Volumes[i]
+		}
+	}
+	if plugins == nil || plugins.PersistentVolumeClaim.ClaimName != PluginsPVCName || !plugins.PersistentVolumeClaim.ReadOnly {
+		t.Fatalf("plugins volume = %+v, want the read-only plugins PVC", plugins)
+	}
+
+	mounts := map[string]corev1.VolumeMount{}
+	for _, m := range spec.Containers[0].VolumeMounts {
+		mounts[m.MountPath] = m
+	}
+	for _, plugin := range instance.Spec.Plugins {
+		m, ok := mounts[PluginMountPath(plugin)]
+		if !ok || m.Name != PluginsVolumeName || m.SubPath != PluginSubPath(plugin) || !m.ReadOnly {
+			t.Errorf("mount for %s = %+v, want a read-only sub-path mount", plugin.Repository, m)
+		}
+	}
+}
+
+func TestApplyPluginPVC_NoPlugins(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	dep := BuildDeployment(instance, "ns", "klaus:latest", "", nil)
+	before := len(dep.Spec.Template.Spec.Volumes)
+	ApplyPluginPVC(&dep.Spec.Template.Spec, instance)
+	if len(dep.Spec.Template.Spec.Volumes) != before {
+		t.Error("expected no plugins volume without plugins")
+	}
+}
+
+func TestApplyPluginInitContainer(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:   "user@example.com",
+			Plugins: []klausv1alpha1.PluginReference{pluginPVCBase, pluginPVCSRE},
+		},
+	}
+	dep := BuildDeployment(instance, "ns", "klaus:latest", "", nil)
+	spec := &dep.Spec.Template.Spec
+	ApplyPluginInitContainer(spec, instance, "klaus-operator:v1")
+
+	for _, v := range spec.Volumes {
+		if v.Image != nil {
+			t.Errorf("image volume %s left in place", v.Name)
+		}
+		if v.Name == PluginsVolumeName && v.EmptyDir == nil {
+			t.Errorf("plugins volume = %+v, want an emptyDir", v)
+		}
+	}
+
+	if len(spec.InitContainers) == 0 {
+		t.Fatal("expected the plugin pull init container")
+	}
+	pull := spec.InitContainers[len(spec.InitContainers)-1]
+	if pull.Name != PluginPullContainerName || pull.Image != "klaus-operator:v1" || pull.Args[0] != PluginSyncCommand {
+		t.Errorf("init container = %s %s %v, want sync-plugins", pull.Name, pull.Image, pull.Args)
+	}
+	args := strings.Join(pull.Args, " ")
+	for _, plugin := range instance.Spec.Plugins {
+		if !strings.Contains(args, PluginSubPath(plugin)+"="+PluginImageReference(plugin)) {
+			t.Errorf("args = %s, want %s pulled into its sub-path", args, plugin.Repository)
+		}
+	}
+
+	mounts := map[string]corev1.VolumeMount{}
+	for _, m := range spec.Containers[0].VolumeMounts {
+		mounts[m.MountPath] = m
+	}
+	for _, plugin := range instance.Spec.Plugins {
+		m, ok := mounts[PluginMountPath(plugin)]
+		if !ok || m.Name != PluginsVolumeName || m.SubPath != PluginSubPath(plugin) || !m.ReadOnly {
+			t.Errorf("mount for %s = %+v, want a read-only sub-path mount", plugin.Repository, m)
+		}
+	}
+}
+
+func TestApplyPluginInitContainer_NoPlugins(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	dep := BuildDeployment(instance, "ns", "klaus:latest", "", nil)
+	ApplyPluginInitContainer(&dep.Spec.Template.Spec, instance, "klaus-operator:v1")
+	if len(dep.Spec.Template.Spec.InitContainers) != 0 {
+		t.Error("expected no init container without plugins")
+	}
+}
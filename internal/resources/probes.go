@@ -0,0 +1,90 @@
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// Default probes of the Klaus container. The startup probe allows five
+// minutes for toolchain images loading many plugins before the liveness
+// probe can restart the container.
+var (
+	defaultLivenessProbe = klausv1alpha1.ProbeConfig{
+		Path:                "/healthz",
+		InitialDelaySeconds: ptr.To[int32](10),
+		PeriodSeconds:       ptr.To[int32](30),
+	}
+	defaultReadinessProbe = klausv1alpha1.ProbeConfig{
+		Path:                "/readyz",
+		InitialDelaySeconds: ptr.To[int32](5),
+		PeriodSeconds:       ptr.To[int32](10),
+	}
+	defaultStartupProbe = klausv1alpha1.ProbeConfig{
+		Path:             "/healthz",
+		PeriodSeconds:    ptr.To[int32](5),
+		FailureThreshold: ptr.To[int32](60),
+	}
+)
+
+// BuildProbes returns the liveness, readiness and startup probes of the Klaus
+// container: the defaults overridden field by field by spec.probes.
+func BuildProbes(instance *klausv1alpha1.KlausInstance) (liveness, readiness, startup *corev1.Probe) {
+	var probes klausv1alpha1.ProbesConfig
+	if instance.Spec.Probes != nil {
+		probes = *instance.Spec.Probes
+	}
+	return buildProbe(instance, defaultLivenessProbe, probes.Liveness),
+		buildProbe(instance, defaultReadinessProbe, probes.Readiness),
+		buildProbe(instance, defaultStartupProbe, probes.Startup)
+}
+
+func buildProbe(instance *klausv1alpha1.KlausInstance, defaults klausv1alpha1.ProbeConfig, override *klausv1alpha1.ProbeConfig) *corev1.Probe {
+	config := defaults
+	if override != nil {
+		if override.Path != "" {
+			config.Path = override.Path
+		}
+		config.InitialDelaySeconds = firstInt32(override.InitialDelaySeconds, config.InitialDelaySeconds)
+		config.PeriodSeconds = firstInt32(override.PeriodSeconds, config.PeriodSeconds)
+		config.TimeoutSeconds = firstInt32(override.TimeoutSeconds, config.TimeoutSeconds)
+		config.SuccessThreshold = firstInt32(override.SuccessThreshold, config.SuccessThreshold)
+		config.FailureThreshold = firstInt32(override.FailureThreshold, config.FailureThreshold)
+	}
+
+	probe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   config.Path,
+				Port:   intstr.FromInt32(int32(KlausPort)),
+				Scheme: probeScheme(instance),
+			},
+		},
+	}
+	if config.InitialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *config.InitialDelaySeconds
+	}
+	if config.PeriodSeconds != nil {
+		probe.PeriodSeconds = *config.PeriodSeconds
+	}
+	if config.TimeoutSeconds != nil {
+		probe.TimeoutSeconds = *config.TimeoutSeconds
+	}
+	if config.SuccessThreshold != nil {
+		probe.SuccessThreshold = *config.SuccessThreshold
+	}
+	if config.FailureThreshold != nil {
+		probe.FailureThreshold = *config.FailureThreshold
+	}
+	return probe
+}
+
+// firstInt32 returns override when set, else value.
+func firstInt32(override, value *int32) *int32 {
+	if override != nil {
+		return override
+	}
+	return value
+}
@@ -0,0 +1,52 @@
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestBuildProbes_Defaults(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{Owner: "test@example.com"}}
+
+	liveness, readiness, startup := BuildProbes(instance)
+	if liveness.HTTPGet.Path != "/healthz" || liveness.InitialDelaySeconds != 10 || liveness.PeriodSeconds != 30 {
+		t.Errorf("liveness = %+v", liveness)
+	}
+	if readiness.HTTPGet.Path != "/readyz" || readiness.InitialDelaySeconds != 5 || readiness.PeriodSeconds != 10 {
+		t.Errorf("readiness = %+v", readiness)
+	}
+	if startup.HTTPGet.Path != "/healthz" || startup.PeriodSeconds*startup.FailureThreshold != 300 {
+		t.Errorf("startup = %+v, want five minutes of /healthz", startup)
+	}
+	if startup.HTTPGet.Port.IntValue() != KlausPort || startup.HTTPGet.Scheme != corev1.URISchemeHTTP {
+		t.Errorf("startup handler = %+v", startup.HTTPGet)
+	}
+}
+
+func TestBuildProbes_Overrides(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{
+		Owner: "test@example.com",
+		Probes: &klausv1alpha1.ProbesConfig{
+			Liveness: &klausv1alpha1.ProbeConfig{InitialDelaySeconds: ptr.To[int32](0), TimeoutSeconds: ptr.To[int32](5)},
+			Readiness: &klausv1alpha1.ProbeConfig{
+				Path: "/ready", SuccessThreshold: ptr.To[int32](2), FailureThreshold: ptr.To[int32](6),
+			},
+			Startup: &klausv1alpha1.ProbeConfig{FailureThreshold: ptr.To[int32](180)},
+		},
+	}}
+
+	liveness, readiness, startup := BuildProbes(instance)
+	if liveness.HTTPGet.Path != "/healthz" || liveness.InitialDelaySeconds != 0 || liveness.PeriodSeconds != 30 || liveness.TimeoutSeconds != 5 {
+		t.Errorf("liveness = %+v, want the timeout and delay overridden", liveness)
+	}
+	if readiness.HTTPGet.Path != "/ready" || readiness.SuccessThreshold != 2 || readiness.FailureThreshold != 6 || readiness.PeriodSeconds != 10 {
+		t.Errorf("readiness = %+v", readiness)
+	}
+	if startup.FailureThreshold != 180 || startup.PeriodSeconds != 5 {
+		t.Errorf("startup = %+v", startup)
+	}
+}
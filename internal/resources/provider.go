@@ -0,0 +1,173 @@
+package resources
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// ProviderCredentialsVolumeName is the name of the provider credentials volume.
+	ProviderCredentialsVolumeName = "provider-credentials"
+
+	// ProviderCredentialsMountPath is where provider credential files are mounted.
+	ProviderCredentialsMountPath = "/var/run/secrets/klaus/provider" // #nosec G101 -- mount path, not a credential
+
+	// DefaultVertexCredentialsKey is the default Secret key holding the GCP
+	// service account key file.
+	DefaultVertexCredentialsKey = "credentials.json" // #nosec G101 -- key name, not a credential
+
+	// AnnotationIRSARoleARN is the ServiceAccount annotation used by EKS IRSA.
+	AnnotationIRSARoleARN = "eks.amazonaws.com/role-arn"
+
+	// AnnotationGKEServiceAccount is the ServiceAccount annotation used by GKE
+	// Workload Identity.
+	AnnotationGKEServiceAccount = "iam.gke.io/gcp-service-account"
+
+	// LabelMock marks the resources of instances running in mock mode as
+	// non-production.
+	LabelMock = "klaus.giantswarm.io/mock"
+
+	// MockModeEnvVar tells the agent image it runs in mock mode.
+	MockModeEnvVar = "KLAUS_MOCK_MODE"
+)
+
+// Provider returns the effective model provider for the instance, defaulting
+// to the Anthropic API.
+func Provider(instance *klausv1alpha1.KlausInstance) klausv1alpha1.ProviderType {
+	if p := instance.Spec.Claude.Provider; p != nil && p.Type != "" {
+		return p.Type
+	}
+	return klausv1alpha1.ProviderAnthropic
+}
+
+// UsesAnthropicAPIKey returns true if the instance talks to the Anthropic API
+// directly and therefore needs the Anthropic API key Secret. Mock instances
+// never need it.
+func UsesAnthropicAPIKey(instance *klausv1alpha1.KlausInstance) bool {
+	return !instance.Spec.MockMode && Provider(instance) == klausv1alpha1.ProviderAnthropic
+}
+
+// ProviderSecretName returns the name of the provider credentials Secret copy
+// in the user namespace.
+func ProviderSecretName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name + "-provider-creds"
+}
+
+// ProviderCredentialsRef returns the credentials Secret reference for the
+// active provider, or nil if the provider uses ambient credentials or the
+// instance runs in mock mode.
+func ProviderCredentialsRef(instance *klausv1alpha1.KlausInstance) *klausv1alpha1.ProviderSecretReference {
+	p := instance.Spec.Claude.Provider
+	if p == nil || instance.Spec.MockMode {
+		return nil
+	}
+	switch Provider(instance) {
+	case klausv1alpha1.ProviderBedrock:
+		if p.Bedrock != nil {
+			return p.Bedrock.CredentialsSecretRef
+		}
+	case klausv1alpha1.ProviderVertex:
+		if p.Vertex != nil {
+			return p.Vertex.CredentialsSecretRef
+		}
+	}
+	return nil
+}
+
+// NeedsProviderCredentialsVolume returns true if provider credentials must be
+// mounted as a file (Vertex AI service account keys).
+func NeedsProviderCredentialsVolume(instance *klausv1alpha1.KlausInstance) bool {
+	return Provider(instance) == klausv1alpha1.ProviderVertex && ProviderCredentialsRef(instance) != nil
+}
+
+// vertexCredentialsKey returns the Secret key of the Vertex AI credentials file.
+func vertexCredentialsKey(instance *klausv1alpha1.KlausInstance) string {
+	if ref := ProviderCredentialsRef(instance); ref != nil && ref.Key != "" {
+		return ref.Key
+	}
+	return DefaultVertexCredentialsKey
+}
+
+// ProviderServiceAccountAnnotations returns the workload identity annotations
+// the instance ServiceAccount needs for the configured provider.
+func ProviderServiceAccountAnnotations(instance *klausv1alpha1.KlausInstance) map[string]string {
+	p := instance.Spec.Claude.Provider
+	if p == nil {
+		return nil
+	}
+	switch Provider(instance) {
+	case klausv1alpha1.ProviderBedrock:
+		if p.Bedrock != nil && p.Bedrock.RoleARN != "" {
+			return map[string]string{AnnotationIRSARoleARN: p.Bedrock.RoleARN}
+		}
+	case klausv1alpha1.ProviderVertex:
+		if p.Vertex != nil && p.Vertex.ServiceAccount != "" {
+			return map[string]string{AnnotationGKEServiceAccount: p.Vertex.ServiceAccount}
+		}
+	}
+	return nil
+}
+
+// buildProviderEnvVars returns the environment variables selecting and
+// configuring the model provider. Mock instances get no provider settings.
+func buildProviderEnvVars(instance *klausv1alpha1.KlausInstance, apiKeySecretName string) []corev1.EnvVar {
+	if instance.Spec.MockMode {
+		return []corev1.EnvVar{{Name: MockModeEnvVar, Value: envValueTrue}}
+	}
+
+	var envs []corev1.EnvVar
+	p := instance.Spec.Claude.Provider
+
+	switch Provider(instance) {
+	case klausv1alpha1.ProviderBedrock:
+		envs = append(envs, corev1.EnvVar{Name: "CLAUDE_CODE_USE_BEDROCK", Value: "1"})
+		if p.Bedrock == nil {
+			return envs
+		}
+		envs = append(envs, corev1.EnvVar{Name: "AWS_REGION", Value: p.Bedrock.Region})
+		if ref := p.Bedrock.CredentialsSecretRef; ref != nil {
+			secretName := ProviderSecretName(instance)
+			envs = append(envs,
+				envFromSecret("AWS_ACCESS_KEY_ID", secretName, "AWS_ACCESS_KEY_ID", false),
+				envFromSecret("AWS_SECRET_ACCESS_KEY", secretName, "AWS_SECRET_ACCESS_KEY", false),
+				envFromSecret("AWS_SESSION_TOKEN", secretName, "AWS_SESSION_TOKEN", true),
+			)
+		}
+	case klausv1alpha1.ProviderVertex:
+		envs = append(envs, corev1.EnvVar{Name: "CLAUDE_CODE_USE_VERTEX", Value: "1"})
+		if p.Vertex == nil {
+			return envs
+		}
+		envs = append(envs,
+			corev1.EnvVar{Name: "CLOUD_ML_REGION", Value: p.Vertex.Region},
+			corev1.EnvVar{Name: "ANTHROPIC_VERTEX_PROJECT_ID", Value: p.Vertex.ProjectID},
+		)
+		if NeedsProviderCredentialsVolume(instance) {
+			envs = append(envs, corev1.EnvVar{
+				Name:  "GOOGLE_APPLICATION_CREDENTIALS",
+				Value: path.Join(ProviderCredentialsMountPath, vertexCredentialsKey(instance)),
+			})
+		}
+	default:
+		envs = append(envs, envFromSecret("ANTHROPIC_API_KEY", apiKeySecretName, "api-key", false))
+	}
+	return envs
+}
+
+func envFromSecret(envName, secretName, key string, optional bool) corev1.EnvVar {
+	selector := &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+		Key:                  key,
+	}
+	if optional {
+		selector.Optional = ptr.To(true)
+	}
+	return corev1.EnvVar{
+		Name:      envName,
+		ValueFrom: &corev1.EnvVarSource{SecretKeyRef: selector},
+	}
+}
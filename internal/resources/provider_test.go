@@ -0,0 +1,140 @@
+package resources
+
+import (
+	"testing"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestBuildEnvVars_ProviderBedrock(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Claude: klausv1alpha1.ClaudeConfig{
+				Provider: &klausv1alpha1.ProviderConfig{
+					Type: klausv1alpha1.ProviderBedrock,
+					Bedrock: &klausv1alpha1.BedrockConfig{
+						Region:               "eu-central-1",
+						CredentialsSecretRef: &klausv1alpha1.ProviderSecretReference{Name: "aws-creds"},
+					},
+				},
+			},
+		},
+	}
+	instance.Name = "agent"
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+
+	assertEnvValue(t, envs, "CLAUDE_CODE_USE_BEDROCK", "1")
+	assertEnvValue(t, envs, "AWS_REGION", "eu-central-1")
+	assertEnvFromSecret(t, envs, "AWS_ACCESS_KEY_ID", "agent-provider-creds", "AWS_ACCESS_KEY_ID")
+	assertEnvFromSecret(t, envs, "AWS_SECRET_ACCESS_KEY", "agent-provider-creds", "AWS_SECRET_ACCESS_KEY")
+	assertEnvAbsent(t, envs, "ANTHROPIC_API_KEY")
+	if UsesAnthropicAPIKey(instance) {
+		t.Error("expected bedrock instance not to use the Anthropic API key")
+	}
+}
+
+func TestBuildEnvVars_ProviderVertex(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "test@example.com",
+			Claude: klausv1alpha1.ClaudeConfig{
+				Provider: &klausv1alpha1.ProviderConfig{
+					Type: klausv1alpha1.ProviderVertex,
+					Vertex: &klausv1alpha1.VertexConfig{
+						ProjectID:            "my-project",
+						Region:               "us-east5",
+						CredentialsSecretRef: &klausv1alpha1.ProviderSecretReference{Name: "gcp-creds", Key: "sa.json"},
+					},
+				},
+			},
+		},
+	}
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+
+	assertEnvValue(t, envs, "CLAUDE_CODE_USE_VERTEX", "1")
+	assertEnvValue(t, envs, "CLOUD_ML_REGION", "us-east5")
+	assertEnvValue(t, envs, "ANTHROPIC_VERTEX_PROJECT_ID", "my-project")
+	assertEnvValue(t, envs, "GOOGLE_APPLICATION_CREDENTIALS", "/var/run/secrets/klaus/provider/sa.json")
+	assertEnvAbsent(t, envs, "ANTHROPIC_API_KEY")
+
+	found := false
+	for _, m := range BuildVolumeMounts(instance) {
+		if m.Name == ProviderCredentialsVolumeName && m.MountPath == ProviderCredentialsMountPath {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected provider credentials volume mount")
+	}
+}
+
+func TestProviderServiceAccountAnnotations(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Claude: klausv1alpha1.ClaudeConfig{
+				Provider: &klausv1alpha1.ProviderConfig{
+					Type:    klausv1alpha1.ProviderBedrock,
+					Bedrock: &klausv1alpha1.BedrockConfig{Region: "eu-west-1", RoleARN: "arn:aws:iam::123:role/klaus"},
+				},
+			},
+		},
+	}
+
+	got := ProviderServiceAccountAnnotations(instance)
+	if got[AnnotationIRSARoleARN] != "arn:aws:iam::123:role/klaus" {
+		t.Errorf("annotations = %v, want IRSA role ARN", got)
+	}
+
+	if got := ProviderServiceAccountAnnotations(&klausv1alpha1.KlausInstance{}); got != nil {
+		t.Errorf("expected no annotations for default provider, got %v", got)
+	}
+}
+
+func TestMockMode(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:    "test@example.com",
+			MockMode: true,
+			Claude: klausv1alpha1.ClaudeConfig{
+				Provider: &klausv1alpha1.ProviderConfig{
+					Type: klausv1alpha1.ProviderBedrock,
+					Bedrock: &klausv1alpha1.BedrockConfig{
+						Region:               "eu-central-1",
+						CredentialsSecretRef: &klausv1alpha1.ProviderSecretReference{Name: "aws-creds"},
+					},
+				},
+			},
+		},
+	}
+	instance.Name = "agent"
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+
+	assertEnvValue(t, envs, MockModeEnvVar, "true")
+	assertEnvAbsent(t, envs, "ANTHROPIC_API_KEY")
+	assertEnvAbsent(t, envs, "CLAUDE_CODE_USE_BEDROCK")
+	assertEnvAbsent(t, envs, "AWS_ACCESS_KEY_ID")
+	if UsesAnthropicAPIKey(instance) {
+		t.Error("expected mock instance not to use the Anthropic API key")
+	}
+	if ProviderCredentialsRef(instance) != nil {
+		t.Error("expected mock instance not to need provider credentials")
+	}
+	if InstanceLabels(instance)[LabelMock] != "true" {
+		t.Errorf("InstanceLabels = %v, want the mock label", InstanceLabels(instance))
+	}
+	if BuildMCPServerCRD(instance, "klaus-user-test-example-com").GetLabels()[LabelMock] != "true" {
+		t.Error("expected the muster MCPServer to be labeled as mock")
+	}
+
+	instance.Spec.MockMode = false
+	if _, ok := InstanceLabels(instance)[LabelMock]; ok {
+		t.Error("expected no mock label on regular instances")
+	}
+	if _, ok := BuildMCPServerCRD(instance, "klaus-user-test-example-com").GetLabels()[LabelMock]; ok {
+		t.Error("expected no mock label on the muster MCPServer of regular instances")
+	}
+}
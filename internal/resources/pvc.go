@@ -1,6 +1,8 @@
 package resources
 
 import (
+	"slices"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -8,6 +10,26 @@ import (
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
 
+// DefaultWorkspaceSize is the workspace PVC size when spec.workspace.size is
+// not set.
+const DefaultWorkspaceSize = "5Gi"
+
+// WorkspaceSize returns the requested size of the workspace PVC.
+func WorkspaceSize(ws *klausv1alpha1.WorkspaceConfig) resource.Quantity {
+	if ws == nil || ws.Size == nil {
+		return resource.MustParse(DefaultWorkspaceSize)
+	}
+	return *ws.Size
+}
+
+// WorkspaceAccessModes returns the access modes of the workspace PVC.
+func WorkspaceAccessModes(ws *klausv1alpha1.WorkspaceConfig) []corev1.PersistentVolumeAccessMode {
+	if ws == nil || len(ws.AccessModes) == 0 {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return slices.Clone(ws.AccessModes)
+}
+
 // BuildPVC creates the PersistentVolumeClaim for a KlausInstance workspace.
 // Returns nil if workspace is not configured.
 func BuildPVC(instance *klausv1alpha1.KlausInstance, namespace string) *corev1.PersistentVolumeClaim {
@@ -16,12 +38,7 @@ func BuildPVC(instance *klausv1alpha1.KlausInstance, namespace string) *corev1.P
 	}
 
 	labels := InstanceLabels(instance)
-
-	// Default size is 5Gi.
-	size := resource.MustParse("5Gi")
-	if instance.Spec.Workspace.Size != nil {
-		size = *instance.Spec.Workspace.Size
-	}
+	size := WorkspaceSize(instance.Spec.Workspace)
 
 	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -30,9 +47,7 @@ func BuildPVC(instance *klausv1alpha1.KlausInstance, namespace string) *corev1.P
 			Labels:    labels,
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteOnce,
-			},
+			AccessModes: WorkspaceAccessModes(instance.Spec.Workspace),
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: corev1.ResourceList{
 					corev1.ResourceStorage: size,
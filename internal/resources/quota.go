@@ -0,0 +1,66 @@
+package resources
+
+import (
+	"slices"
+	"strings"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// SelectQuota returns the quota applying to an owner: the quota naming the
+// owner, or else the default quota without an owner. When several match, the
+// first by name wins. It returns nil when no quota applies.
+func SelectQuota(quotas []klausv1alpha1.KlausQuota, owner string) *klausv1alpha1.KlausQuota {
+	return selectForOwner(quotas, owner, func(q *klausv1alpha1.KlausQuota) (string, string) {
+		return q.Name, q.Spec.Owner
+	})
+}
+
+// SelectTenant returns the tenant applying to an owner, chosen like
+// SelectQuota. It returns nil when no tenant applies.
+func SelectTenant(tenants []klausv1alpha1.KlausTenant, owner string) *klausv1alpha1.KlausTenant {
+	return selectForOwner(tenants, owner, func(t *klausv1alpha1.KlausTenant) (string, string) {
+		return t.Name, t.Spec.Owner
+	})
+}
+
+// InstanceLimit returns the instance limit of an owner: spec.maxInstances of
+// their tenant, or else defaultMax. Zero means unlimited.
+func InstanceLimit(tenant *klausv1alpha1.KlausTenant, defaultMax int) int {
+	if tenant != nil && tenant.Spec.MaxInstances > 0 {
+		return int(tenant.Spec.MaxInstances)
+	}
+	return defaultMax
+}
+
+// CountsAgainstInstanceLimit reports whether an instance counts against its
+// owner's instance limit: it is neither stopped nor being deleted.
+func CountsAgainstInstanceLimit(instance *klausv1alpha1.KlausInstance) bool {
+	return !IsStopped(instance) && instance.DeletionTimestamp.IsZero()
+}
+
+// selectForOwner returns the item naming owner, or else the first item by
+// name without an owner.
+func selectForOwner[T any](items []T, owner string, key func(*T) (name, owner string)) *T {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b T) int {
+		nameA, _ := key(&a)
+		nameB, _ := key(&b)
+		return strings.Compare(nameA, nameB)
+	})
+
+	var fallback *T
+	for i := range sorted {
+		item := &sorted[i]
+		_, itemOwner := key(item)
+		switch itemOwner {
+		case owner:
+			return item
+		case "":
+			if fallback == nil {
+				fallback = item
+			}
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,68 @@
+package resources
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestSelectQuota(t *testing.T) {
+	quota := func(name, owner string) klausv1alpha1.KlausQuota {
+		return klausv1alpha1.KlausQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       klausv1alpha1.KlausQuotaSpec{Owner: owner},
+		}
+	}
+	quotas := []klausv1alpha1.KlausQuota{
+		quota("b-default", ""),
+		quota("a-default", ""),
+		quota("z-alice", "alice@example.com"),
+	}
+
+	tests := []struct {
+		owner string
+		want  string
+	}{
+		{owner: "alice@example.com", want: "z-alice"},
+		{owner: "bob@example.com", want: "a-default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			if got := SelectQuota(quotas, tt.owner); got == nil || got.Name != tt.want {
+				t.Errorf("SelectQuota() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got := SelectQuota(quotas[2:], "bob@example.com"); got != nil {
+		t.Errorf("SelectQuota() without a default = %v, want nil", got)
+	}
+	if quotas[0].Name != "b-default" {
+		t.Error("SelectQuota() reordered its argument")
+	}
+}
+
+func TestSelectTenant(t *testing.T) {
+	tenant := func(name, owner string, maxInstances int32) klausv1alpha1.KlausTenant {
+		return klausv1alpha1.KlausTenant{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       klausv1alpha1.KlausTenantSpec{Owner: owner, MaxInstances: maxInstances},
+		}
+	}
+	tenants := []klausv1alpha1.KlausTenant{
+		tenant("default", "", 2),
+		tenant("alice", "alice@example.com", 5),
+	}
+
+	if got := SelectTenant(tenants, "alice@example.com"); got == nil || got.Name != "alice" {
+		t.Errorf("SelectTenant(alice) = %v, want alice", got)
+	}
+	if got := InstanceLimit(SelectTenant(tenants, "bob@example.com"), 10); got != 2 {
+		t.Errorf("InstanceLimit(bob) = %d, want the default tenant's 2", got)
+	}
+	if got := InstanceLimit(nil, 10); got != 10 {
+		t.Errorf("InstanceLimit(nil) = %d, want the operator default 10", got)
+	}
+}
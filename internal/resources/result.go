@@ -0,0 +1,129 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// MaxResultBytes caps the agent output stored in status.result, keeping the
+// instance object well below the etcd object size limit.
+const MaxResultBytes = 32 << 10
+
+// resultSchemaURL is the location the spec.claude.jsonSchema of an instance
+// is compiled at. Validation errors refer to it.
+const resultSchemaURL = "https://klaus.giantswarm.io/result-schema.json"
+
+// schemaMessages prints the schema validation errors.
+var schemaMessages = message.NewPrinter(language.English)
+
+// BuildInstanceResult returns the status.result of an agent output observed
+// at now. With spec.claude.jsonSchema set the output is expected to be JSON
+// valid against the schema; output that is not JSON or does not match the
+// schema is recorded with the mismatch in Error.
+func BuildInstanceResult(instance *klausv1alpha1.KlausInstance, text string, messageCount int, now time.Time) *klausv1alpha1.InstanceResult {
+	result := &klausv1alpha1.InstanceResult{
+		Text:         text,
+		MessageCount: int64(messageCount),
+		CompletedAt:  metav1.NewTime(now),
+	}
+	if instance.Spec.Claude.JSONSchema != "" {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(strings.TrimSpace(text))); err != nil {
+			result.Error = "output is not valid JSON: " + err.Error()
+		} else if err := validateResult(instance.Spec.Claude.JSONSchema, compact.Bytes()); err != nil {
+			result.Text, result.Error = compact.String(), err.Error()
+		} else {
+			result.Text, result.Structured = compact.String(), true
+		}
+	}
+	if len(result.Text) > MaxResultBytes {
+		n := MaxResultBytes
+		for n > 0 && !utf8.RuneStart(result.Text[n]) {
+			n--
+		}
+		result.Text, result.Truncated = result.Text[:n], true
+	}
+	return result
+}
+
+// validateResult checks the JSON output against the JSON schema. Schemas
+// referring to other documents are not loaded: the operator would fetch
+// them on behalf of the instance owner.
+func validateResult(schema string, output []byte) error {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(schema))
+	if err != nil {
+		return fmt.Errorf("spec.claude.jsonSchema is not valid JSON: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(resultSchemaURL, doc); err != nil {
+		return fmt.Errorf("spec.claude.jsonSchema is invalid: %w", err)
+	}
+	compiled, err := compiler.Compile(resultSchemaURL)
+	if err != nil {
+		return fmt.Errorf("spec.claude.jsonSchema is invalid: %w", err)
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(output))
+	if err != nil {
+		return fmt.Errorf("output is not valid JSON: %w", err)
+	}
+	var validationErr *jsonschema.ValidationError
+	if err := compiled.Validate(value); errors.As(err, &validationErr) {
+		return fmt.Errorf("output does not match spec.claude.jsonSchema: %s", schemaErrors(validationErr))
+	} else if err != nil {
+		return fmt.Errorf("output does not match spec.claude.jsonSchema: %w", err)
+	}
+	return nil
+}
+
+// schemaErrors joins the leaf errors of a validation error, each with the
+// location of the offending value.
+func schemaErrors(err *jsonschema.ValidationError) string {
+	if len(err.Causes) == 0 {
+		location := "/" + strings.Join(err.InstanceLocation, "/")
+		return fmt.Sprintf("at %s: %s", location, err.ErrorKind.LocalizedString(schemaMessages))
+	}
+	messages := make([]string, 0, len(err.Causes))
+	for _, cause := range err.Causes {
+		messages = append(messages, schemaErrors(cause))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ResultCondition returns the status, reason and message of the condition
+// reporting whether status.result of an instance matches its
+// spec.claude.jsonSchema. ok is false when there is nothing to report: the
+// instance has no schema or no result.
+func ResultCondition(instance *klausv1alpha1.KlausInstance) (status metav1.ConditionStatus, reason, message string, ok bool) {
+	result := instance.Status.Result
+	if instance.Spec.Claude.JSONSchema == "" || result == nil {
+		return "", "", "", false
+	}
+	if result.Error != "" {
+		return metav1.ConditionFalse, "SchemaMismatch", result.Error, true
+	}
+	return metav1.ConditionTrue, "SchemaMatched", "The result matches spec.claude.jsonSchema", true
+}
+
+// ResultChanged reports whether next records a different output than
+// current, ignoring when the outputs were observed.
+func ResultChanged(current, next *klausv1alpha1.InstanceResult) bool {
+	if current == nil || next == nil {
+		return current != next
+	}
+	a, b := *current, *next
+	a.CompletedAt, b.CompletedAt = metav1.Time{}, metav1.Time{}
+	return a != b
+}
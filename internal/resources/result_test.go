@@ -0,0 +1,100 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestBuildInstanceResult_Text(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	result := BuildInstanceResult(&klausv1alpha1.KlausInstance{}, "All tests pass.", 4, now)
+
+	if result.Text != "All tests pass." || result.Structured || result.Truncated || result.Error != "" {
+		t.Errorf("result = %+v, want plain text", result)
+	}
+	if result.MessageCount != 4 {
+		t.Errorf("MessageCount = %d, want 4", result.MessageCount)
+	}
+	if !result.CompletedAt.Time.Equal(now) {
+		t.Errorf("CompletedAt = %v, want %v", result.CompletedAt, now)
+	}
+}
+
+func TestBuildInstanceResult_Structured(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{}
+	instance.Spec.Claude.JSONSchema = `{"type":"object"}`
+
+	result := BuildInstanceResult(instance, "\n{ \"issues\": [1, 2] }\n", 1, time.Now())
+	if result.Text != `{"issues":[1,2]}` || !result.Structured || result.Error != "" {
+		t.Errorf("result = %+v, want compact structured JSON", result)
+	}
+
+	result = BuildInstanceResult(instance, "no JSON here", 1, time.Now())
+	if result.Structured || result.Text != "no JSON here" {
+		t.Errorf("result = %+v, want the verbatim text", result)
+	}
+	if !strings.HasPrefix(result.Error, "output is not valid JSON") {
+		t.Errorf("Error = %q, want a JSON error", result.Error)
+	}
+}
+
+func TestBuildInstanceResult_SchemaMismatch(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{}
+	instance.Spec.Claude.JSONSchema = `{
+		"type": "object",
+		"required": ["issues"],
+		"properties": {"issues": {"type": "array", "items": {"type": "integer"}}}
+	}`
+
+	result := BuildInstanceResult(instance, `{"issues": [1, "two"]}`, 1, time.Now())
+	if result.Structured || result.Text != `{"issues":[1,"two"]}` {
+		t.Errorf("result = %+v, want the compact output not marked structured", result)
+	}
+	if !strings.HasPrefix(result.Error, "output does not match spec.claude.jsonSchema: at /issues/1:") {
+		t.Errorf("Error = %q, want the mismatch at /issues/1", result.Error)
+	}
+
+	result = BuildInstanceResult(instance, `{}`, 1, time.Now())
+	if !strings.Contains(result.Error, "issues") {
+		t.Errorf("Error = %q, want the missing property", result.Error)
+	}
+
+	instance.Spec.Claude.JSONSchema = `{"$ref": "file:///etc/passwd"}`
+	result = BuildInstanceResult(instance, `{}`, 1, time.Now())
+	if !strings.HasPrefix(result.Error, "spec.claude.jsonSchema is invalid") {
+		t.Errorf("Error = %q, want external references rejected", result.Error)
+	}
+}
+
+func TestBuildInstanceResult_Truncated(t *testing.T) {
+	text := strings.Repeat("a", MaxResultBytes-1) + "é"
+	result := BuildInstanceResult(&klausv1alpha1.KlausInstance{}, text, 1, time.Now())
+
+	if !result.Truncated {
+		t.Error("Truncated = false, want true")
+	}
+	if len(result.Text) != MaxResultBytes-1 {
+		t.Errorf("len(Text) = %d, want %d without the split rune", len(result.Text), MaxResultBytes-1)
+	}
+}
+
+func TestResultChanged(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{}
+	current := BuildInstanceResult(instance, "done", 2, time.Now())
+
+	if ResultChanged(current, BuildInstanceResult(instance, "done", 2, time.Now().Add(time.Minute))) {
+		t.Error("ResultChanged = true for the same output observed later")
+	}
+	if !ResultChanged(current, BuildInstanceResult(instance, "done", 4, time.Now())) {
+		t.Error("ResultChanged = false for a new prompt with the same output")
+	}
+	if !ResultChanged(nil, current) {
+		t.Error("ResultChanged = false for a first result")
+	}
+	if ResultChanged(nil, nil) {
+		t.Error("ResultChanged = true without results")
+	}
+}
@@ -0,0 +1,186 @@
+package resources
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// SandboxTmpVolumeName is the writable scratch volume mounted at /tmp
+	// when a sandbox preset makes the root filesystem read-only.
+	SandboxTmpVolumeName = "sandbox-tmp"
+
+	// SandboxTmpMountPath is where the scratch volume is mounted. HOME points
+	// here so the Claude CLI can write its npm cache and state.
+	SandboxTmpMountPath = "/tmp"
+
+	// namespaceNameLabel is set by the API server on every namespace.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+// privateCIDRs are excluded from the HTTPS egress allowed by a restricted
+// network, so sandboxed agents cannot reach cluster-internal or cloud
+// metadata endpoints.
+var privateCIDRs = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "169.254.0.0/16"}
+
+// SandboxPreset is what a sandbox profile maps to on the cluster.
+type SandboxPreset struct {
+	// RuntimeClassName is set on the pod unless spec.scheduling sets one.
+	RuntimeClassName string
+
+	// SeccompProfile replaces the pod seccomp profile when set.
+	SeccompProfile *corev1.SeccompProfile
+
+	// ReadOnlyRootFilesystem makes the klaus container root filesystem
+	// read-only and mounts a writable /tmp as HOME.
+	ReadOnlyRootFilesystem bool
+
+	// RestrictNetwork generates a NetworkPolicy for the instance.
+	RestrictNetwork bool
+}
+
+// SandboxPresets maps sandbox profiles to presets.
+type SandboxPresets map[klausv1alpha1.SandboxProfile]SandboxPreset
+
+// NewSandboxPresets returns the presets for the given RuntimeClass names.
+// strictSeccompProfile is a Localhost seccomp profile path for the strict
+// preset; when empty the strict preset keeps the runtime default profile.
+func NewSandboxPresets(gvisorRuntimeClass, kataRuntimeClass, strictRuntimeClass, strictSeccompProfile string) SandboxPresets {
+	strict := SandboxPreset{
+		RuntimeClassName:       strictRuntimeClass,
+		ReadOnlyRootFilesystem: true,
+		RestrictNetwork:        true,
+	}
+	if strictSeccompProfile != "" {
+		strict.SeccompProfile = &corev1.SeccompProfile{
+			Type:             corev1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: ptr.To(strictSeccompProfile),
+		}
+	}
+	return SandboxPresets{
+		klausv1alpha1.SandboxNone:   {},
+		klausv1alpha1.SandboxGVisor: {RuntimeClassName: gvisorRuntimeClass},
+		klausv1alpha1.SandboxKata:   {RuntimeClassName: kataRuntimeClass},
+		klausv1alpha1.SandboxStrict: strict,
+	}
+}
+
+// DefaultSandboxPresets returns the presets for the conventional "gvisor"
+// and "kata" RuntimeClass names.
+func DefaultSandboxPresets() SandboxPresets {
+	return NewSandboxPresets("gvisor", "kata", "gvisor", "")
+}
+
+// Preset returns the preset for the instance's sandbox profile. Instances
+// without a profile, or with a profile missing from the presets, get the
+// zero preset.
+func (p SandboxPresets) Preset(instance *klausv1alpha1.KlausInstance) SandboxPreset {
+	if instance.Spec.Sandbox == "" {
+		return SandboxPreset{}
+	}
+	return p[instance.Spec.Sandbox]
+}
+
+// ApplySandbox hardens the pod spec according to the preset. A RuntimeClass
+// set through spec.scheduling takes precedence over the preset.
+func ApplySandbox(podSpec *corev1.PodSpec, preset SandboxPreset) {
+	if preset.RuntimeClassName != "" && podSpec.RuntimeClassName == nil {
+		podSpec.RuntimeClassName = ptr.To(preset.RuntimeClassName)
+	}
+	if preset.SeccompProfile != nil {
+		if podSpec.SecurityContext == nil {
+			podSpec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		podSpec.SecurityContext.SeccompProfile = preset.SeccompProfile.DeepCopy()
+	}
+	if !preset.ReadOnlyRootFilesystem {
+		return
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         SandboxTmpVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name != AppKlaus {
+			continue
+		}
+		if c.SecurityContext == nil {
+			c.SecurityContext = &corev1.SecurityContext{}
+		}
+		c.SecurityContext.ReadOnlyRootFilesystem = ptr.To(true)
+		c.SecurityContext.AllowPrivilegeEscalation = ptr.To(false)
+		c.SecurityContext.Capabilities = &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
+		c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: SandboxTmpVolumeName, MountPath: SandboxTmpMountPath})
+		c.Env = append(c.Env, corev1.EnvVar{Name: "HOME", Value: SandboxTmpMountPath})
+	}
+}
+
+// NetworkPolicyName returns the name of the NetworkPolicy for a KlausInstance.
+func NetworkPolicyName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name
+}
+
+// BuildNetworkPolicy creates the NetworkPolicy restricting a sandboxed
+// instance pod. Ingress to the klaus port is allowed from the muster and
+// operator namespaces, or from anywhere when the instance is exposed through
+// an ingress controller or gateway. Egress is limited to DNS and HTTPS to
+// public addresses. It returns nil when the preset does not restrict the
+// network.
+func BuildNetworkPolicy(instance *klausv1alpha1.KlausInstance, namespace, operatorNamespace string, preset SandboxPreset) *networkingv1.NetworkPolicy {
+	if !preset.RestrictNetwork {
+		return nil
+	}
+
+	tcp := corev1.ProtocolTCP
+	udp := corev1.ProtocolUDP
+	klausPort := intstr.FromInt32(int32(KlausPort))
+	dnsPort := intstr.FromInt32(53)
+	httpsPort := intstr.FromInt32(443)
+
+	ingress := networkingv1.NetworkPolicyIngressRule{
+		Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &klausPort}},
+	}
+	if instance.Spec.Expose == nil {
+		for _, ns := range []string{MusterNamespace(instance), operatorNamespace} {
+			ingress.From = append(ingress.From, networkingv1.NetworkPolicyPeer{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: ns}},
+			})
+		}
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      NetworkPolicyName(instance),
+			Namespace: namespace,
+			Labels:    InstanceLabels(instance),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: SelectorLabels(instance)},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{ingress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{
+						{Protocol: &udp, Port: &dnsPort},
+						{Protocol: &tcp, Port: &dnsPort},
+					},
+				},
+				{
+					To: []networkingv1.NetworkPolicyPeer{{
+						IPBlock: &networkingv1.IPBlock{CIDR: "0.0.0.0/0", Except: slices.Clone(privateCIDRs)},
+					}},
+					Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &httpsPort}},
+				},
+			},
+		},
+	}
+}
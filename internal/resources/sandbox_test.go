@@ -0,0 +1,177 @@
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func sandboxedInstance(profile klausv1alpha1.SandboxProfile) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com", Sandbox: profile},
+	}
+}
+
+func klausContainer(t *testing.T, spec *corev1.PodSpec) *corev1.Container {
+	t.Helper()
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == AppKlaus {
+			return &spec.Containers[i]
+		}
+	}
+	t.Fatal("klaus container not found")
+	return nil
+}
+
+func TestSandboxPresets_Preset(t *testing.T) {
+	presets := NewSandboxPresets("runsc", "kata-qemu", "runsc", "profiles/klaus.json")
+
+	if got := presets.Preset(sandboxedInstance("")); got != (SandboxPreset{}) {
+		t.Errorf("unset profile = %+v, want the zero preset", got)
+	}
+	if got := presets.Preset(sandboxedInstance(klausv1alpha1.SandboxNone)); got != (SandboxPreset{}) {
+		t.Errorf("none = %+v, want the zero preset", got)
+	}
+	if got := presets.Preset(sandboxedInstance(klausv1alpha1.SandboxKata)).RuntimeClassName; got != "kata-qemu" {
+		t.Errorf("kata RuntimeClassName = %q, want kata-qemu", got)
+	}
+	strict := presets.Preset(sandboxedInstance(klausv1alpha1.SandboxStrict))
+	if strict.RuntimeClassName != "runsc" || !strict.ReadOnlyRootFilesystem || !strict.RestrictNetwork {
+		t.Errorf("strict = %+v, want runsc with read-only root and restricted network", strict)
+	}
+	if strict.SeccompProfile == nil || strict.SeccompProfile.Type != corev1.SeccompProfileTypeLocalhost ||
+		*strict.SeccompProfile.LocalhostProfile != "profiles/klaus.json" {
+		t.Errorf("strict SeccompProfile = %+v, want the localhost profile", strict.SeccompProfile)
+	}
+	if DefaultSandboxPresets()[klausv1alpha1.SandboxStrict].SeccompProfile != nil {
+		t.Error("default strict preset must keep the runtime default seccomp profile")
+	}
+}
+
+func TestApplySandbox_Strict(t *testing.T) {
+	instance := sandboxedInstance(klausv1alpha1.SandboxStrict)
+	preset := NewSandboxPresets("gvisor", "kata", "gvisor", "profiles/klaus.json").Preset(instance)
+
+	spec := BuildPodTemplate(instance, "klaus:latest", "", nil).Spec
+	ApplySandbox(&spec, preset)
+
+	if spec.RuntimeClassName == nil || *spec.RuntimeClassName != "gvisor" {
+		t.Errorf("RuntimeClassName = %v, want gvisor", spec.RuntimeClassName)
+	}
+	if spec.SecurityContext.SeccompProfile.Type != corev1.SeccompProfileTypeLocalhost {
+		t.Errorf("SeccompProfile = %+v, want Localhost", spec.SecurityContext.SeccompProfile)
+	}
+	if spec.SecurityContext.RunAsUser == nil {
+		t.Error("the pod security context must be kept")
+	}
+
+	c := klausContainer(t, &spec)
+	if c.SecurityContext.ReadOnlyRootFilesystem == nil || !*c.SecurityContext.ReadOnlyRootFilesystem {
+		t.Error("klaus container root filesystem must be read-only")
+	}
+	if caps := c.SecurityContext.Capabilities; caps == nil || len(caps.Drop) != 1 || caps.Drop[0] != "ALL" {
+		t.Errorf("Capabilities = %+v, want all dropped", caps)
+	}
+	mounted := false
+	for _, m := range c.VolumeMounts {
+		if m.Name == SandboxTmpVolumeName && m.MountPath == SandboxTmpMountPath {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Error("expected a writable /tmp mount")
+	}
+	envMap := map[string]string{}
+	for _, e := range c.Env {
+		envMap[e.Name] = e.Value
+	}
+	if envMap["HOME"] != SandboxTmpMountPath {
+		t.Errorf("HOME = %q, want %q", envMap["HOME"], SandboxTmpMountPath)
+	}
+	hasVolume := false
+	for _, v := range spec.Volumes {
+		if v.Name == SandboxTmpVolumeName && v.EmptyDir != nil {
+			hasVolume = true
+		}
+	}
+	if !hasVolume {
+		t.Error("expected the sandbox-tmp emptyDir volume")
+	}
+}
+
+func TestApplySandbox_SchedulingRuntimeClassWins(t *testing.T) {
+	instance := sandboxedInstance(klausv1alpha1.SandboxGVisor)
+	instance.Spec.Scheduling = &klausv1alpha1.SchedulingConfig{RuntimeClassName: ptr.To("custom")}
+
+	spec := BuildPodTemplate(instance, "klaus:latest", "", nil).Spec
+	ApplySandbox(&spec, DefaultSandboxPresets().Preset(instance))
+
+	if *spec.RuntimeClassName != "custom" {
+		t.Errorf("RuntimeClassName = %q, want the explicit custom class", *spec.RuntimeClassName)
+	}
+	c := klausContainer(t, &spec)
+	if *c.SecurityContext.ReadOnlyRootFilesystem {
+		t.Error("the gvisor preset must not make the root filesystem read-only")
+	}
+	if spec.SecurityContext.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Errorf("SeccompProfile = %+v, want RuntimeDefault", spec.SecurityContext.SeccompProfile)
+	}
+}
+
+func TestBuildNetworkPolicy(t *testing.T) {
+	restricted := SandboxPreset{RestrictNetwork: true}
+
+	if np := BuildNetworkPolicy(sandboxedInstance(klausv1alpha1.SandboxGVisor), "ns", "klaus-system", SandboxPreset{}); np != nil {
+		t.Errorf("expected no NetworkPolicy without network restriction, got %+v", np)
+	}
+
+	np := BuildNetworkPolicy(sandboxedInstance(klausv1alpha1.SandboxStrict), "klaus-user-user-example-com", "klaus-system", restricted)
+	if np == nil {
+		t.Fatal("expected a NetworkPolicy")
+	}
+	if np.Name != "my-agent" || np.Namespace != "klaus-user-user-example-com" {
+		t.Errorf("NetworkPolicy = %s/%s", np.Namespace, np.Name)
+	}
+	if np.Spec.PodSelector.MatchLabels["app.kubernetes.io/instance"] != "my-agent" {
+		t.Errorf("podSelector = %+v, want the instance selector labels", np.Spec.PodSelector)
+	}
+	if len(np.Spec.PolicyTypes) != 2 {
+		t.Errorf("PolicyTypes = %v, want Ingress and Egress", np.Spec.PolicyTypes)
+	}
+
+	var sources []string
+	for _, peer := range np.Spec.Ingress[0].From {
+		sources = append(sources, peer.NamespaceSelector.MatchLabels["kubernetes.io/metadata.name"])
+	}
+	if len(sources) != 2 || sources[0] != "muster" || sources[1] != "klaus-system" {
+		t.Errorf("ingress sources = %v, want muster and klaus-system", sources)
+	}
+	if got := np.Spec.Ingress[0].Ports[0].Port.IntValue(); got != KlausPort {
+		t.Errorf("ingress port = %d, want %d", got, KlausPort)
+	}
+
+	var https *networkingv1.NetworkPolicyEgressRule
+	for i := range np.Spec.Egress {
+		for _, p := range np.Spec.Egress[i].Ports {
+			if p.Port.IntValue() == 443 {
+				https = &np.Spec.Egress[i]
+			}
+		}
+	}
+	if https == nil || https.To[0].IPBlock == nil || len(https.To[0].IPBlock.Except) == 0 {
+		t.Fatalf("egress = %+v, want HTTPS to public addresses only", np.Spec.Egress)
+	}
+
+	exposed := sandboxedInstance(klausv1alpha1.SandboxStrict)
+	exposed.Spec.Expose = &klausv1alpha1.ExposeConfig{Hostname: "agent.example.com"}
+	np = BuildNetworkPolicy(exposed, "ns", "klaus-system", restricted)
+	if len(np.Spec.Ingress[0].From) != 0 {
+		t.Errorf("exposed instance ingress sources = %+v, want any source", np.Spec.Ingress[0].From)
+	}
+}
@@ -0,0 +1,84 @@
+package resources
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// PodDisruptionBudgetName returns the name of the PodDisruptionBudget for a
+// KlausInstance.
+func PodDisruptionBudgetName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name
+}
+
+// applyScheduling copies spec.scheduling onto the pod spec. The values are
+// deep-copied so later changes to the pod spec, such as the architecture
+// affinity, do not write back into the instance.
+func applyScheduling(podSpec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance) {
+	sched := instance.Spec.Scheduling
+	if sched == nil {
+		return
+	}
+	if len(sched.NodeSelector) > 0 {
+		podSpec.NodeSelector = maps.Clone(sched.NodeSelector)
+	}
+	if sched.Affinity != nil {
+		podSpec.Affinity = sched.Affinity.DeepCopy()
+	}
+	for _, t := range sched.Tolerations {
+		podSpec.Tolerations = append(podSpec.Tolerations, *t.DeepCopy())
+	}
+	for _, c := range sched.TopologySpreadConstraints {
+		constraint := *c.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					LabelAppName:   AppKlaus,
+					LabelManagedBy: AppKlausOperator,
+				},
+			}
+		}
+		podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, constraint)
+	}
+	podSpec.PriorityClassName = sched.PriorityClassName
+	if sched.RuntimeClassName != nil {
+		podSpec.RuntimeClassName = ptr.To(*sched.RuntimeClassName)
+	}
+}
+
+// BuildPodDisruptionBudget creates the PodDisruptionBudget protecting the
+// instance pod. It returns nil when no disruption budget is configured or
+// the instance is stopped.
+func BuildPodDisruptionBudget(instance *klausv1alpha1.KlausInstance, namespace string) *policyv1.PodDisruptionBudget {
+	if instance.Spec.Scheduling == nil || instance.Spec.Scheduling.DisruptionBudget == nil || IsStopped(instance) {
+		return nil
+	}
+	budget := instance.Spec.Scheduling.DisruptionBudget
+
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PodDisruptionBudgetName(instance),
+			Namespace: namespace,
+			Labels:    InstanceLabels(instance),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: SelectorLabels(instance)},
+		},
+	}
+	switch {
+	case budget.MaxUnavailable != nil:
+		pdb.Spec.MaxUnavailable = ptr.To(*budget.MaxUnavailable)
+	case budget.MinAvailable != nil:
+		pdb.Spec.MinAvailable = ptr.To(*budget.MinAvailable)
+	default:
+		pdb.Spec.MinAvailable = ptr.To(intstr.FromInt32(1))
+	}
+	return pdb
+}
@@ -0,0 +1,166 @@
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func scheduledInstance(sched *klausv1alpha1.SchedulingConfig) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:      "user@example.com",
+			Scheduling: sched,
+		},
+	}
+}
+
+func TestBuildPodTemplate_Scheduling(t *testing.T) {
+	instance := scheduledInstance(&klausv1alpha1.SchedulingConfig{
+		Affinity: &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key: "node-pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"agents"},
+						}},
+					}},
+				},
+			},
+		},
+		Tolerations: []corev1.Toleration{{
+			Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "agents", Effect: corev1.TaintEffectNoSchedule,
+		}},
+		TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+		}},
+		PriorityClassName: "agents-high",
+		RuntimeClassName:  ptr.To("gvisor"),
+	})
+
+	spec := BuildPodTemplate(instance, "klaus:latest", "", nil).Spec
+	ApplyArchitectureAffinity(&spec, []string{"amd64"})
+
+	terms := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 || len(terms[0].MatchExpressions) != 2 {
+		t.Fatalf("node selector terms = %+v, want the pool and arch requirements in one term", terms)
+	}
+	instanceTerms := instance.Spec.Scheduling.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(instanceTerms[0].MatchExpressions) != 1 {
+		t.Error("architecture affinity must not modify the instance spec")
+	}
+
+	if len(spec.Tolerations) != 1 || spec.Tolerations[0].Key != "dedicated" {
+		t.Errorf("tolerations = %+v, want the dedicated toleration", spec.Tolerations)
+	}
+
+	if len(spec.TopologySpreadConstraints) != 1 {
+		t.Fatalf("topology spread constraints = %+v, want one", spec.TopologySpreadConstraints)
+	}
+	selector := spec.TopologySpreadConstraints[0].LabelSelector
+	if selector == nil || selector.MatchLabels[LabelAppName] != AppKlaus || selector.MatchLabels[LabelManagedBy] != AppKlausOperator {
+		t.Errorf("labelSelector = %+v, want all Klaus instance pods", selector)
+	}
+	if instance.Spec.Scheduling.TopologySpreadConstraints[0].LabelSelector != nil {
+		t.Error("defaulting the labelSelector must not modify the instance spec")
+	}
+
+	if spec.PriorityClassName != "agents-high" {
+		t.Errorf("PriorityClassName = %q, want agents-high", spec.PriorityClassName)
+	}
+	if spec.RuntimeClassName == nil || *spec.RuntimeClassName != "gvisor" {
+		t.Errorf("RuntimeClassName = %v, want gvisor", spec.RuntimeClassName)
+	}
+	if spec.RuntimeClassName == instance.Spec.Scheduling.RuntimeClassName {
+		t.Error("RuntimeClassName must be copied, not shared with the instance spec")
+	}
+}
+
+func TestBuildPodTemplate_NodeSelector(t *testing.T) {
+	instance := scheduledInstance(&klausv1alpha1.SchedulingConfig{NodeSelector: map[string]string{"node-pool": "agents"}})
+
+	spec := BuildPodTemplate(instance, "klaus:latest", "", nil).Spec
+	if spec.NodeSelector["node-pool"] != "agents" {
+		t.Errorf("node selector = %v, want node-pool=agents", spec.NodeSelector)
+	}
+	spec.NodeSelector["other"] = "x"
+	if _, ok := instance.Spec.Scheduling.NodeSelector["other"]; ok {
+		t.Error("the pod node selector must not share the instance map")
+	}
+}
+
+func TestBuildPodTemplate_NoScheduling(t *testing.T) {
+	spec := BuildPodTemplate(scheduledInstance(nil), "klaus:latest", "", nil).Spec
+	if spec.Affinity != nil || spec.Tolerations != nil || spec.TopologySpreadConstraints != nil {
+		t.Errorf("unexpected scheduling settings: %+v %+v %+v", spec.Affinity, spec.Tolerations, spec.TopologySpreadConstraints)
+	}
+	if spec.PriorityClassName != "" || spec.RuntimeClassName != nil {
+		t.Errorf("unexpected priority or runtime class: %q %v", spec.PriorityClassName, spec.RuntimeClassName)
+	}
+}
+
+func TestBuildPodDisruptionBudget(t *testing.T) {
+	percent := intstr.FromString("50%")
+	tests := []struct {
+		name               string
+		budget             *klausv1alpha1.DisruptionBudgetConfig
+		stopped            bool
+		wantNil            bool
+		wantMinAvailable   *intstr.IntOrString
+		wantMaxUnavailable *intstr.IntOrString
+	}{
+		{name: "not configured", wantNil: true},
+		{name: "default", budget: &klausv1alpha1.DisruptionBudgetConfig{}, wantMinAvailable: ptr.To(intstr.FromInt32(1))},
+		{name: "min available", budget: &klausv1alpha1.DisruptionBudgetConfig{MinAvailable: &percent}, wantMinAvailable: &percent},
+		{name: "max unavailable", budget: &klausv1alpha1.DisruptionBudgetConfig{MaxUnavailable: ptr.To(intstr.FromInt32(0))}, wantMaxUnavailable: ptr.To(intstr.FromInt32(0))},
+		{name: "stopped", budget: &klausv1alpha1.DisruptionBudgetConfig{}, stopped: true, wantNil: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sched *klausv1alpha1.SchedulingConfig
+			if tt.budget != nil {
+				sched = &klausv1alpha1.SchedulingConfig{DisruptionBudget: tt.budget}
+			}
+			instance := scheduledInstance(sched)
+			instance.Spec.Stopped = tt.stopped
+
+			pdb := BuildPodDisruptionBudget(instance, "klaus-user-user-example-com")
+			if tt.wantNil {
+				if pdb != nil {
+					t.Errorf("expected no PodDisruptionBudget, got %+v", pdb)
+				}
+				return
+			}
+			if pdb == nil {
+				t.Fatal("expected a PodDisruptionBudget")
+			}
+			if pdb.Name != "my-agent" || pdb.Namespace != "klaus-user-user-example-com" {
+				t.Errorf("PodDisruptionBudget = %s/%s", pdb.Namespace, pdb.Name)
+			}
+			if pdb.Spec.Selector.MatchLabels["app.kubernetes.io/instance"] != "my-agent" {
+				t.Errorf("selector = %+v, want the instance selector labels", pdb.Spec.Selector)
+			}
+			if !intOrStringEqual(pdb.Spec.MinAvailable, tt.wantMinAvailable) {
+				t.Errorf("MinAvailable = %v, want %v", pdb.Spec.MinAvailable, tt.wantMinAvailable)
+			}
+			if !intOrStringEqual(pdb.Spec.MaxUnavailable, tt.wantMaxUnavailable) {
+				t.Errorf("MaxUnavailable = %v, want %v", pdb.Spec.MaxUnavailable, tt.wantMaxUnavailable)
+			}
+		})
+	}
+}
+
+func intOrStringEqual(a, b *intstr.IntOrString) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -1,14 +1,37 @@
 package resources
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
 
+// ownerHashLength is the number of hex characters of the owner hash used in
+// per-owner API key Secret names.
+const ownerHashLength = 12
+
+// OwnerHash returns a short, stable, DNS-safe hash of the owner identity.
+// The owner is lower-cased first so that case variations of the same email
+// map to the same hash.
+func OwnerHash(owner string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(owner)))
+	return fmt.Sprintf("%x", sum)[:ownerHashLength]
+}
+
+// OwnerAPIKeySecretName returns the name of the per-owner Anthropic API key
+// Secret, derived from the shared Secret name and the owner hash
+// (e.g. "anthropic-api-key-1a2b3c4d5e6f").
+func OwnerAPIKeySecretName(sharedName, owner string) string {
+	return sharedName + "-" + OwnerHash(owner)
+}
+
 // BuildAPIKeySecret creates a Secret in the instance namespace containing the
-// Anthropic API key, copied from the shared org secret.
+// Anthropic API key, copied from the per-owner or shared org secret.
 func BuildAPIKeySecret(instance *klausv1alpha1.KlausInstance, namespace string, apiKey []byte) *corev1.Secret {
 	labels := InstanceLabels(instance)
 
@@ -35,7 +35,12 @@ func BuildService(instance *klausv1alpha1.KlausInstance, namespace string) *core
 	}
 }
 
-// ServiceEndpoint returns the internal service URL for a KlausInstance.
+// ServiceEndpoint returns the internal service URL for a KlausInstance. The
+// scheme is https when the instance is served over mTLS.
 func ServiceEndpoint(instance *klausv1alpha1.KlausInstance, namespace string) string {
-	return "http://" + ServiceName(instance) + "." + namespace + ".svc.cluster.local:" + strconv.Itoa(KlausPort)
+	scheme := "http"
+	if TLSEnabled(instance) {
+		scheme = "https"
+	}
+	return scheme + "://" + ServiceHost(instance, namespace) + ":" + strconv.Itoa(KlausPort)
 }
@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// DrainPath is the agent endpoint the preStop hook requests. It returns
+	// once the current turn is finished or checkpointed.
+	DrainPath = "/drain"
+
+	// PersistentTerminationGracePeriodSeconds is the default grace period of
+	// chat mode instances, long enough for a turn to finish.
+	PersistentTerminationGracePeriodSeconds int64 = 300
+)
+
+// IsPersistent reports whether the instance runs a persistent agent process
+// holding conversations, i.e. chat mode.
+func IsPersistent(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.Claude.Mode != nil && *instance.Spec.Claude.Mode == klausv1alpha1.ModeChat
+}
+
+// applyShutdown sets the termination grace period of the pod and the preStop
+// drain hook of the Klaus container from spec.shutdown.
+func applyShutdown(podSpec *corev1.PodSpec, instance *klausv1alpha1.KlausInstance) {
+	var shutdown klausv1alpha1.ShutdownConfig
+	if instance.Spec.Shutdown != nil {
+		shutdown = *instance.Spec.Shutdown
+	}
+
+	switch {
+	case shutdown.TerminationGracePeriodSeconds != nil:
+		podSpec.TerminationGracePeriodSeconds = ptr.To(*shutdown.TerminationGracePeriodSeconds)
+	case IsPersistent(instance):
+		podSpec.TerminationGracePeriodSeconds = ptr.To(PersistentTerminationGracePeriodSeconds)
+	}
+
+	drain := IsPersistent(instance) && agentSupports(instance, FeatureGracefulShutdown)
+	if shutdown.Drain != nil {
+		drain = *shutdown.Drain
+	}
+	if !drain {
+		return
+	}
+	podSpec.Containers[0].Lifecycle = &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   DrainPath,
+				Port:   intstr.FromInt32(int32(KlausPort)),
+				Scheme: probeScheme(instance),
+			},
+		},
+	}
+}
+
+// agentSupports reports whether the last observed capabilities of the agent
+// list feature.
+func agentSupports(instance *klausv1alpha1.KlausInstance, feature string) bool {
+	caps := instance.Status.AgentCapabilities
+	return caps != nil && slices.Contains(caps.Features, feature)
+}
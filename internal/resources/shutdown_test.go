@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"testing"
+
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func shutdownTestInstance(mode string) *klausv1alpha1.KlausInstance {
+	instance := &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"}}
+	instance.Name = "my-agent"
+	instance.Spec.Claude.Mode = ptr.To(mode)
+	return instance
+}
+
+func TestBuildDeployment_ShutdownAgentMode(t *testing.T) {
+	dep := BuildDeployment(shutdownTestInstance(klausv1alpha1.ModeAgent), "ns", "klaus:latest", "", nil)
+
+	if dep.Spec.Template.Spec.TerminationGracePeriodSeconds != nil {
+		t.Errorf("terminationGracePeriodSeconds = %d, want the Kubernetes default", *dep.Spec.Template.Spec.TerminationGracePeriodSeconds)
+	}
+	if dep.Spec.Template.Spec.Containers[0].Lifecycle != nil {
+		t.Error("expected no preStop hook for agent mode")
+	}
+}
+
+func TestBuildDeployment_ShutdownChatMode(t *testing.T) {
+	instance := shutdownTestInstance(klausv1alpha1.ModeChat)
+
+	dep := BuildDeployment(instance, "ns", "klaus:latest", "", nil)
+	if got := dep.Spec.Template.Spec.TerminationGracePeriodSeconds; got == nil || *got != PersistentTerminationGracePeriodSeconds {
+		t.Errorf("terminationGracePeriodSeconds = %v, want %d", got, PersistentTerminationGracePeriodSeconds)
+	}
+	if dep.Spec.Template.Spec.Containers[0].Lifecycle != nil {
+		t.Error("expected no preStop hook before the agent reports graceful-shutdown")
+	}
+
+	instance.Status.AgentCapabilities = &klausv1alpha1.AgentCapabilities{Features: []string{FeaturePersistentMode, FeatureGracefulShutdown}}
+	dep = BuildDeployment(instance, "ns", "klaus:latest", "", nil)
+	lifecycle := dep.Spec.Template.Spec.Containers[0].Lifecycle
+	if lifecycle == nil || lifecycle.PreStop.HTTPGet.Path != DrainPath || lifecycle.PreStop.HTTPGet.Port.IntValue() != KlausPort {
+		t.Errorf("lifecycle = %+v, want the drain hook", lifecycle)
+	}
+}
+
+func TestBuildDeployment_ShutdownOverrides(t *testing.T) {
+	instance := shutdownTestInstance(klausv1alpha1.ModeChat)
+	instance.Status.AgentCapabilities = &klausv1alpha1.AgentCapabilities{Features: []string{FeatureGracefulShutdown}}
+	instance.Spec.Shutdown = &klausv1alpha1.ShutdownConfig{TerminationGracePeriodSeconds: ptr.To[int64](900), Drain: ptr.To(false)}
+
+	dep := BuildDeployment(instance, "ns", "klaus:latest", "", nil)
+	if got := dep.Spec.Template.Spec.TerminationGracePeriodSeconds; got == nil || *got != 900 {
+		t.Errorf("terminationGracePeriodSeconds = %v, want 900", got)
+	}
+	if dep.Spec.Template.Spec.Containers[0].Lifecycle != nil {
+		t.Error("expected no preStop hook with drain disabled")
+	}
+
+	agent := shutdownTestInstance(klausv1alpha1.ModeAgent)
+	agent.Spec.Shutdown = &klausv1alpha1.ShutdownConfig{Drain: ptr.To(true)}
+	dep = BuildDeployment(agent, "ns", "klaus:latest", "", nil)
+	if dep.Spec.Template.Spec.Containers[0].Lifecycle == nil {
+		t.Error("expected the preStop hook with drain enabled")
+	}
+}
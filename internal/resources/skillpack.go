@@ -0,0 +1,101 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// extensionNamePattern is the format of the names of skills, agent files
+// and commands of a skill pack, which become ConfigMap keys and file names.
+var extensionNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// SkillPackNames returns the names of the KlausSkillPacks referenced by the
+// instance and its inline personality, without duplicates.
+func SkillPackNames(instance *klausv1alpha1.KlausInstance) []string {
+	var names []string
+	refs := instance.Spec.SkillPacks
+	if p := instance.Spec.InlinePersonality; p != nil {
+		refs = append(slices.Clip(refs), p.SkillPacks...)
+	}
+	for _, ref := range refs {
+		if !slices.Contains(names, ref.Name) {
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// ValidateSkillPack renders the skills, agent files and commands of a skill
+// pack and checks that their names are valid file names, that skill
+// contexts are JSON objects and that each rendered file fits into a
+// ConfigMap.
+func ValidateSkillPack(spec *klausv1alpha1.KlausSkillPackSpec) error {
+	data := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(spec.Skills)) {
+		skill := spec.Skills[name]
+		if err := validateExtension("skill", name, skill.Content); err != nil {
+			return err
+		}
+		if skill.Context != nil && skill.Context.Raw != nil {
+			var skillContext map[string]any
+			if err := json.Unmarshal(skill.Context.Raw, &skillContext); err != nil {
+				return fmt.Errorf("skill %q: context must be a JSON object: %w", name, err)
+			}
+		}
+		data["skill-"+name] = renderSkillMD(skill)
+	}
+	for _, name := range slices.Sorted(maps.Keys(spec.AgentFiles)) {
+		if err := validateExtension("agent file", name, spec.AgentFiles[name].Content); err != nil {
+			return err
+		}
+		data["agentfile-"+name] = spec.AgentFiles[name].Content
+	}
+	for _, name := range slices.Sorted(maps.Keys(spec.Commands)) {
+		if err := validateExtension("command", name, spec.Commands[name].Content); err != nil {
+			return err
+		}
+		data["command-"+name] = renderCommandMD(spec.Commands[name])
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("skill pack defines no skills, agent files or commands")
+	}
+	return checkConfigSize(data)
+}
+
+func validateExtension(kind, name, content string) error {
+	if !extensionNamePattern.MatchString(name) {
+		return fmt.Errorf("%s name %q must consist of letters, digits, '.', '_' and '-' and start with a letter or digit", kind, name)
+	}
+	if content == "" {
+		return fmt.Errorf("%s %q has no content", kind, name)
+	}
+	return nil
+}
+
+// MergeSkillPack adds the skills, agent files and commands of a skill pack
+// to the spec of the instance, unless it defines an entry with the same
+// name.
+func MergeSkillPack(instance *klausv1alpha1.KlausInstance, spec *klausv1alpha1.KlausSkillPackSpec) {
+	instance.Spec.Skills = mergeMissing(instance.Spec.Skills, spec.Skills)
+	instance.Spec.AgentFiles = mergeMissing(instance.Spec.AgentFiles, spec.AgentFiles)
+	instance.Spec.Commands = mergeMissing(instance.Spec.Commands, spec.Commands)
+}
+
+// mergeMissing returns dst with the entries of src whose keys it lacks.
+func mergeMissing[V any](dst, src map[string]V) map[string]V {
+	for name, value := range src {
+		if _, exists := dst[name]; exists {
+			continue
+		}
+		if dst == nil {
+			dst = make(map[string]V, len(src))
+		}
+		dst[name] = value
+	}
+	return dst
+}
@@ -0,0 +1,112 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestValidateSkillPack(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    klausv1alpha1.KlausSkillPackSpec
+		wantErr string
+	}{
+		{
+			name: "valid",
+			spec: klausv1alpha1.KlausSkillPackSpec{
+				Skills:     map[string]klausv1alpha1.SkillConfig{"review": {Description: "Review code", Content: "Review."}},
+				AgentFiles: map[string]klausv1alpha1.AgentFileConfig{"planner.md": {Content: "Plan."}},
+				Commands:   map[string]klausv1alpha1.CommandConfig{"fix-issue": {Content: "Fix $ARGUMENTS."}},
+			},
+		},
+		{
+			name:    "empty",
+			wantErr: "defines no skills",
+		},
+		{
+			name:    "invalid name",
+			spec:    klausv1alpha1.KlausSkillPackSpec{Skills: map[string]klausv1alpha1.SkillConfig{"../review": {Content: "Review."}}},
+			wantErr: `skill name "../review"`,
+		},
+		{
+			name:    "no content",
+			spec:    klausv1alpha1.KlausSkillPackSpec{Commands: map[string]klausv1alpha1.CommandConfig{"deploy": {}}},
+			wantErr: `command "deploy" has no content`,
+		},
+		{
+			name: "context not an object",
+			spec: klausv1alpha1.KlausSkillPackSpec{Skills: map[string]klausv1alpha1.SkillConfig{
+				"review": {Content: "Review.", Context: &runtime.RawExtension{Raw: []byte(`["fork"]`)}},
+			}},
+			wantErr: "context must be a JSON object",
+		},
+		{
+			name: "oversized",
+			spec: klausv1alpha1.KlausSkillPackSpec{AgentFiles: map[string]klausv1alpha1.AgentFileConfig{
+				"planner.md": {Content: strings.Repeat("x", ConfigMapDataLimit+1)},
+			}},
+			wantErr: "agentfile-planner.md exceeds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSkillPack(&tt.spec)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateSkillPack() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateSkillPack() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeSkillPack(t *testing.T) {
+	instance := soulInstance()
+	instance.Spec.Skills = map[string]klausv1alpha1.SkillConfig{"review": {Content: "Instance review."}}
+
+	MergeSkillPack(instance, &klausv1alpha1.KlausSkillPackSpec{
+		Skills:   map[string]klausv1alpha1.SkillConfig{"review": {Content: "Pack review."}, "triage": {Content: "Triage."}},
+		Commands: map[string]klausv1alpha1.CommandConfig{"deploy": {Content: "Deploy."}},
+	})
+	MergeSkillPack(instance, &klausv1alpha1.KlausSkillPackSpec{
+		Skills: map[string]klausv1alpha1.SkillConfig{"triage": {Content: "Later triage."}},
+	})
+
+	if got := instance.Spec.Skills["review"].Content; got != "Instance review." {
+		t.Errorf("review = %q, want the instance skill", got)
+	}
+	if got := instance.Spec.Skills["triage"].Content; got != "Triage." {
+		t.Errorf("triage = %q, want the skill of the earlier pack", got)
+	}
+	if got := instance.Spec.Commands["deploy"].Content; got != "Deploy." {
+		t.Errorf("deploy = %q, want the pack command", got)
+	}
+	if instance.Spec.AgentFiles != nil {
+		t.Errorf("agent files = %v, want none", instance.Spec.AgentFiles)
+	}
+}
+
+func TestSkillPackNames(t *testing.T) {
+	instance := soulInstance()
+	instance.Spec.SkillPacks = []klausv1alpha1.SkillPackReference{{Name: "go"}, {Name: "review"}}
+	instance.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{
+		SkillPacks: []klausv1alpha1.SkillPackReference{{Name: "review"}, {Name: "docs"}},
+	}
+
+	got := SkillPackNames(instance)
+	if strings.Join(got, ",") != "go,review,docs" {
+		t.Errorf("SkillPackNames() = %v, want go, review, docs", got)
+	}
+	if len(instance.Spec.SkillPacks) != 2 {
+		t.Errorf("spec.skillPacks = %v, want it unchanged", instance.Spec.SkillPacks)
+	}
+}
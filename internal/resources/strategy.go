@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// UpdateStrategy returns the update strategy of the instance: spec.updateStrategy,
+// else Recreate for a workspace PVC only one node can mount, else
+// RollingUpdate.
+func UpdateStrategy(instance *klausv1alpha1.KlausInstance) klausv1alpha1.UpdateStrategyType {
+	if instance.Spec.UpdateStrategy != "" {
+		return instance.Spec.UpdateStrategy
+	}
+	if ws := instance.Spec.Workspace; ws != nil {
+		modes := WorkspaceAccessModes(ws)
+		if slices.Contains(modes, corev1.ReadWriteOnce) || slices.Contains(modes, corev1.ReadWriteOncePod) {
+			return klausv1alpha1.UpdateStrategyRecreate
+		}
+	}
+	return klausv1alpha1.UpdateStrategyRollingUpdate
+}
+
+// deploymentStrategy returns the rollout strategy of the instance
+// Deployment. Rolling updates start the new pod before the old one is asked
+// to stop, so a conversation of a persistent instance is never left without
+// an agent.
+func deploymentStrategy(instance *klausv1alpha1.KlausInstance) appsv1.DeploymentStrategy {
+	if UpdateStrategy(instance) == klausv1alpha1.UpdateStrategyRecreate {
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+			MaxSurge:       ptr.To(intstr.FromInt32(1)),
+		},
+	}
+}
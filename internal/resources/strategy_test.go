@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func TestUpdateStrategy(t *testing.T) {
+	tests := []struct {
+		name      string
+		workspace *klausv1alpha1.WorkspaceConfig
+		strategy  klausv1alpha1.UpdateStrategyType
+		want      klausv1alpha1.UpdateStrategyType
+	}{
+		{name: "no workspace", want: klausv1alpha1.UpdateStrategyRollingUpdate},
+		{name: "default access mode", workspace: &klausv1alpha1.WorkspaceConfig{}, want: klausv1alpha1.UpdateStrategyRecreate},
+		{
+			name:      "read write once pod",
+			workspace: &klausv1alpha1.WorkspaceConfig{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod}},
+			want:      klausv1alpha1.UpdateStrategyRecreate,
+		},
+		{
+			name:      "read write many",
+			workspace: &klausv1alpha1.WorkspaceConfig{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}},
+			want:      klausv1alpha1.UpdateStrategyRollingUpdate,
+		},
+		{
+			name:      "explicit",
+			workspace: &klausv1alpha1.WorkspaceConfig{},
+			strategy:  klausv1alpha1.UpdateStrategyRollingUpdate,
+			want:      klausv1alpha1.UpdateStrategyRollingUpdate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := shutdownTestInstance(klausv1alpha1.ModeChat)
+			instance.Spec.Workspace = tt.workspace
+			instance.Spec.UpdateStrategy = tt.strategy
+			if got := UpdateStrategy(instance); got != tt.want {
+				t.Errorf("UpdateStrategy() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDeployment_Strategy(t *testing.T) {
+	instance := shutdownTestInstance(klausv1alpha1.ModeChat)
+
+	strategy := BuildDeployment(instance, "ns", "klaus:latest", "", nil).Spec.Strategy
+	if strategy.Type != appsv1.RollingUpdateDeploymentStrategyType ||
+		strategy.RollingUpdate.MaxUnavailable.IntValue() != 0 || strategy.RollingUpdate.MaxSurge.IntValue() != 1 {
+		t.Errorf("strategy = %+v, want a surge-first rolling update", strategy)
+	}
+
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{}
+	strategy = BuildDeployment(instance, "ns", "klaus:latest", "", nil).Spec.Strategy
+	if strategy.Type != appsv1.RecreateDeploymentStrategyType || strategy.RollingUpdate != nil {
+		t.Errorf("strategy = %+v, want Recreate for a ReadWriteOnce workspace", strategy)
+	}
+}
+
+func TestBuildPVC_AccessModes(t *testing.T) {
+	instance := shutdownTestInstance(klausv1alpha1.ModeAgent)
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{}
+	if got := BuildPVC(instance, "ns").Spec.AccessModes; len(got) != 1 || got[0] != corev1.ReadWriteOnce {
+		t.Errorf("access modes = %v, want ReadWriteOnce", got)
+	}
+
+	instance.Spec.Workspace.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	if got := BuildPVC(instance, "ns").Spec.AccessModes; len(got) != 1 || got[0] != corev1.ReadWriteMany {
+		t.Errorf("access modes = %v, want ReadWriteMany", got)
+	}
+}
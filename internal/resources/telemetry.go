@@ -0,0 +1,188 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// OTelCollectorName is the name of the collector configuration
+	// ConfigMap and credentials Secret copied into each user namespace.
+	OTelCollectorName = "klaus-otel-collector"
+
+	// OTelCollectorContainerName is the collector sidecar container.
+	OTelCollectorContainerName = "otel-collector"
+
+	// OTelCollectorConfigKey is the ConfigMap key of the collector
+	// configuration.
+	OTelCollectorConfigKey = "config.yaml"
+
+	// OTelCollectorOTLPPort is the loopback port the collector sidecar
+	// receives OTLP over HTTP on. The central configuration must define an
+	// otlp receiver listening on it.
+	OTelCollectorOTLPPort = 4318
+
+	// OTelCollectorChecksumAnnotation is the pod template annotation that
+	// rolls the pod when the collector configuration or credentials change.
+	OTelCollectorChecksumAnnotation = "checksum/otel-collector"
+
+	otelCollectorVolumeName = "otel-collector-config"
+	otelCollectorMountPath  = "/etc/otelcol"
+	otlpExporter            = "otlp"
+)
+
+// TelemetryCollector is the operator-level telemetry profile. Instances with
+// telemetry enabled but no OTLP endpoint export to an OpenTelemetry
+// Collector sidecar running a centrally managed configuration, so the
+// export target and its credentials never appear in instance specs.
+type TelemetryCollector struct {
+	// Image is the OpenTelemetry Collector image.
+	Image string
+
+	// ConfigMapName is the ConfigMap in the operator namespace holding the
+	// collector configuration in its config.yaml key.
+	ConfigMapName string
+
+	// SecretName optionally names a Secret in the operator namespace whose
+	// keys are passed to the collector as environment variables, to be
+	// referenced from the configuration as ${env:KEY}.
+	SecretName string
+}
+
+// UsesTelemetryCollector reports whether the instance exports its
+// telemetry through the collector sidecar.
+func UsesTelemetryCollector(instance *klausv1alpha1.KlausInstance, collector *TelemetryCollector) bool {
+	tel := instance.Spec.Telemetry
+	if collector == nil || tel == nil || tel.Enabled == nil || !*tel.Enabled {
+		return false
+	}
+	return tel.OTLP == nil || tel.OTLP.Endpoint == ""
+}
+
+// MountsTelemetryCollector reports whether a pod mounts the collector
+// configuration copy of its namespace.
+func MountsTelemetryCollector(podSpec *corev1.PodSpec) bool {
+	return slices.ContainsFunc(podSpec.Volumes, func(v corev1.Volume) bool {
+		return v.ConfigMap != nil && v.ConfigMap.Name == OTelCollectorName
+	})
+}
+
+// OTelCollectorLabels returns the labels of the collector ConfigMap and
+// Secret copies. They are shared by all instances of the owner.
+func OTelCollectorLabels(owner string) map[string]string {
+	return map[string]string{
+		LabelAppName:                  AppKlaus,
+		LabelManagedBy:                AppKlausOperator,
+		"app.kubernetes.io/component": OTelCollectorContainerName,
+		LabelOwner:                    sanitizeLabelValue(owner),
+	}
+}
+
+// OTelCollectorChecksum returns a checksum of the collector configuration
+// and credentials.
+func OTelCollectorChecksum(config map[string]string, credentials map[string][]byte) string {
+	h := sha256.New()
+	for _, k := range slices.Sorted(maps.Keys(config)) {
+		_, _ = fmt.Fprintf(h, "config/%s=%s\n", k, config[k])
+	}
+	for _, k := range slices.Sorted(maps.Keys(credentials)) {
+		_, _ = fmt.Fprintf(h, "secret/%s=%x\n", k, credentials[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// ApplyTelemetryCollector adds the collector as a native sidecar and points
+// the klaus container's OTLP exporters at it. checksum is recorded on the
+// pod template. Instances not using the collector are left alone.
+func ApplyTelemetryCollector(template *corev1.PodTemplateSpec, instance *klausv1alpha1.KlausInstance, collector *TelemetryCollector, checksum string) {
+	if !UsesTelemetryCollector(instance, collector) {
+		return
+	}
+	podSpec := &template.Spec
+
+	tel := instance.Spec.Telemetry
+	env := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": "http://127.0.0.1:" + strconv.Itoa(OTelCollectorOTLPPort),
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+	}
+	if tel.MetricsExporter == "" {
+		env["OTEL_METRICS_EXPORTER"] = otlpExporter
+	}
+	if tel.LogsExporter == "" {
+		env["OTEL_LOGS_EXPORTER"] = otlpExporter
+	}
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != AppKlaus {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(env)) {
+			setEnvVar(&podSpec.Containers[i], name, env[name])
+		}
+		// The collector authenticates to the export target; headers meant
+		// for it are not sent to the sidecar.
+		podSpec.Containers[i].Env = slices.DeleteFunc(podSpec.Containers[i].Env, func(e corev1.EnvVar) bool {
+			return e.Name == "OTEL_EXPORTER_OTLP_HEADERS"
+		})
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: otelCollectorVolumeName,
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: OTelCollectorName},
+		}},
+	})
+	sidecar := corev1.Container{
+		Name:          OTelCollectorContainerName,
+		Image:         collector.Image,
+		RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways),
+		Args:          []string{"--config=" + otelCollectorMountPath + "/" + OTelCollectorConfigKey},
+		VolumeMounts:  []corev1.VolumeMount{{Name: otelCollectorVolumeName, MountPath: otelCollectorMountPath, ReadOnly: true}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: ptr.To(false),
+			ReadOnlyRootFilesystem:   ptr.To(true),
+			RunAsNonRoot:             ptr.To(true),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}
+	if collector.SecretName != "" {
+		sidecar.EnvFrom = []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: OTelCollectorName}},
+		}}
+	}
+	// A native sidecar starts before the klaus container and does not keep
+	// Job pods running after the agent exits.
+	podSpec.InitContainers = append(podSpec.InitContainers, sidecar)
+
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[OTelCollectorChecksumAnnotation] = checksum
+}
+
+// setEnvVar sets an environment variable of a container, replacing an
+// existing value.
+func setEnvVar(container *corev1.Container, name, value string) {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			container.Env[i] = corev1.EnvVar{Name: name, Value: value}
+			return
+		}
+	}
+	container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: value})
+}
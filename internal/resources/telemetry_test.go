@@ -0,0 +1,124 @@
+package resources
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func collectorInstance(tel *klausv1alpha1.TelemetryConfig) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{Spec: klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com", Telemetry: tel}}
+}
+
+func TestUsesTelemetryCollector(t *testing.T) {
+	collector := &TelemetryCollector{Image: "otelcol:1", ConfigMapName: "otel"}
+	tests := []struct {
+		name      string
+		tel       *klausv1alpha1.TelemetryConfig
+		collector *TelemetryCollector
+		want      bool
+	}{
+		{"no telemetry", nil, collector, false},
+		{"telemetry disabled", &klausv1alpha1.TelemetryConfig{Enabled: ptr.To(false)}, collector, false},
+		{"no profile", &klausv1alpha1.TelemetryConfig{Enabled: ptr.To(true)}, nil, false},
+		{"no endpoint", &klausv1alpha1.TelemetryConfig{Enabled: ptr.To(true)}, collector, true},
+		{"protocol only", &klausv1alpha1.TelemetryConfig{Enabled: ptr.To(true), OTLP: &klausv1alpha1.OTLPConfig{Protocol: "grpc"}}, collector, true},
+		{"own endpoint", &klausv1alpha1.TelemetryConfig{Enabled: ptr.To(true), OTLP: &klausv1alpha1.OTLPConfig{Endpoint: "http://otel:4318"}}, collector, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UsesTelemetryCollector(collectorInstance(tt.tel), tt.collector); got != tt.want {
+				t.Errorf("UsesTelemetryCollector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTelemetryCollector(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name: AppKlaus,
+		Env: []corev1.EnvVar{
+			{Name: "OTEL_EXPORTER_OTLP_PROTOCOL", Value: "grpc"},
+			{Name: "OTEL_EXPORTER_OTLP_HEADERS", Value: "Authorization=Bearer x"},
+			{Name: "OTEL_METRICS_EXPORTER", Value: "otlp,prometheus"},
+		},
+	}}}}
+	instance := collectorInstance(&klausv1alpha1.TelemetryConfig{
+		Enabled:         ptr.To(true),
+		MetricsExporter: "otlp,prometheus",
+		OTLP:            &klausv1alpha1.OTLPConfig{Protocol: "grpc", Headers: "Authorization=Bearer x"},
+	})
+	collector := &TelemetryCollector{Image: "otelcol:1", ConfigMapName: "otel", SecretName: "otel-credentials"}
+	ApplyTelemetryCollector(template, instance, collector, "abc")
+
+	c := klausContainer(t, &template.Spec)
+	for name, want := range map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": "http://127.0.0.1:4318",
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+		"OTEL_METRICS_EXPORTER":       "otlp,prometheus",
+		"OTEL_LOGS_EXPORTER":          "otlp",
+	} {
+		if got, _ := envValue(c, name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, ok := envValue(c, "OTEL_EXPORTER_OTLP_HEADERS"); ok {
+		t.Error("expected the OTLP headers to be dropped")
+	}
+
+	if len(template.Spec.InitContainers) != 1 {
+		t.Fatalf("init containers = %+v, want the collector sidecar", template.Spec.InitContainers)
+	}
+	sidecar := template.Spec.InitContainers[0]
+	if sidecar.Name != OTelCollectorContainerName || sidecar.Image != "otelcol:1" {
+		t.Errorf("sidecar = %s %s", sidecar.Name, sidecar.Image)
+	}
+	if sidecar.RestartPolicy == nil || *sidecar.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+		t.Error("expected a native sidecar")
+	}
+	if len(sidecar.EnvFrom) != 1 || sidecar.EnvFrom[0].SecretRef.Name != OTelCollectorName {
+		t.Errorf("envFrom = %+v, want the collector secret copy", sidecar.EnvFrom)
+	}
+	if len(template.Spec.Volumes) != 1 || template.Spec.Volumes[0].ConfigMap.Name != OTelCollectorName {
+		t.Errorf("volumes = %+v, want the collector config copy", template.Spec.Volumes)
+	}
+	if got := template.Annotations[OTelCollectorChecksumAnnotation]; got != "abc" {
+		t.Errorf("checksum annotation = %q, want abc", got)
+	}
+	if !MountsTelemetryCollector(&template.Spec) {
+		t.Error("MountsTelemetryCollector() = false, want true")
+	}
+}
+
+func TestApplyTelemetryCollector_OwnEndpoint(t *testing.T) {
+	template := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: AppKlaus}}}}
+	instance := collectorInstance(&klausv1alpha1.TelemetryConfig{
+		Enabled: ptr.To(true),
+		OTLP:    &klausv1alpha1.OTLPConfig{Endpoint: "http://otel:4318"},
+	})
+	ApplyTelemetryCollector(template, instance, &TelemetryCollector{Image: "otelcol:1", ConfigMapName: "otel"}, "abc")
+
+	if len(template.Spec.InitContainers) != 0 || len(template.Spec.Containers[0].Env) != 0 || template.Annotations != nil {
+		t.Errorf("expected an instance with its own endpoint to be left alone: %+v", template)
+	}
+	if MountsTelemetryCollector(&template.Spec) {
+		t.Error("MountsTelemetryCollector() = true, want false")
+	}
+}
+
+func TestOTelCollectorChecksum(t *testing.T) {
+	config := map[string]string{OTelCollectorConfigKey: "receivers: {}"}
+	base := OTelCollectorChecksum(config, nil)
+	if OTelCollectorChecksum(config, nil) != base {
+		t.Error("expected a stable checksum")
+	}
+	if OTelCollectorChecksum(config, map[string][]byte{"TOKEN": []byte("x")}) == base {
+		t.Error("expected the credentials to change the checksum")
+	}
+	if OTelCollectorChecksum(map[string]string{OTelCollectorConfigKey: "receivers: {otlp: {}}"}, nil) == base {
+		t.Error("expected the configuration to change the checksum")
+	}
+}
@@ -0,0 +1,80 @@
+package resources
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// templatePlaceholder matches the ${name} placeholders of text fields, and
+// $${name} escaping a literal ${name} of a name with a value.
+var templatePlaceholder = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// templateValueName is the format of the names of spec.templateValues. They
+// cannot contain dots, which the built-in klaus.* values use.
+var templateValueName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// templateValues returns the values substituted in the text fields of the
+// instance: spec.templateValues and the built-in klaus.* values.
+func templateValues(instance *klausv1alpha1.KlausInstance) map[string]string {
+	values := maps.Clone(instance.Spec.TemplateValues)
+	if values == nil {
+		values = map[string]string{}
+	}
+	values["klaus.owner"] = instance.Spec.Owner
+	values["klaus.instance"] = instance.Name
+	values["klaus.namespace"] = instance.Namespace
+	values["klaus.repo"] = workspaceRepo(instance)
+	return values
+}
+
+// workspaceRepo returns the repository cloned into the workspace, the first
+// one of spec.workspace.repos.
+func workspaceRepo(instance *klausv1alpha1.KlausInstance) string {
+	ws := instance.Spec.Workspace
+	switch {
+	case ws == nil:
+		return ""
+	case ws.GitRepo != "":
+		return ws.GitRepo
+	case len(ws.Repos) > 0:
+		return ws.Repos[0].Repo
+	}
+	return ""
+}
+
+// expandTemplate substitutes values for the ${name} placeholders of text.
+// Placeholders of unknown names, e.g. shell variables in a prompt, are kept,
+// escaped or not: $${HOME} in a Makefile snippet written before template
+// values existed still renders as written. Only $${name} of a name with a
+// value renders a literal ${name}.
+func expandTemplate(text string, values map[string]string) string {
+	if !strings.Contains(text, "${") {
+		return text
+	}
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		value, ok := values[placeholder[strings.Index(placeholder, "{")+1:len(placeholder)-1]]
+		switch {
+		case !ok:
+			return placeholder
+		case strings.HasPrefix(placeholder, "$$"):
+			return placeholder[1:]
+		}
+		return value
+	})
+}
+
+// validateTemplateValues ensures that the names of spec.templateValues can
+// be referenced by placeholders.
+func validateTemplateValues(instance *klausv1alpha1.KlausInstance) error {
+	for _, name := range slices.Sorted(maps.Keys(instance.Spec.TemplateValues)) {
+		if !templateValueName.MatchString(name) {
+			return fmt.Errorf("spec.templateValues: name %q must consist of letters, digits and underscores and not start with a digit", name)
+		}
+	}
+	return nil
+}
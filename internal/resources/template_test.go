@@ -0,0 +1,121 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func templateInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviewer", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:          "jane@example.com",
+			Workspace:      &klausv1alpha1.WorkspaceConfig{GitRepo: "https://github.com/org/repo.git"},
+			TemplateValues: map[string]string{"team": "platform"},
+		},
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	values := templateValues(templateInstance())
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "You work for team ${team}.", want: "You work for team platform."},
+		{text: "Owner ${klaus.owner} of ${klaus.instance} in ${klaus.namespace}", want: "Owner jane@example.com of reviewer in klaus-system"},
+		{text: "Clone ${klaus.repo}", want: "Clone https://github.com/org/repo.git"},
+		{text: "Keep ${HOME} and $team", want: "Keep ${HOME} and $team"},
+		{text: "Literal $${team}", want: "Literal ${team}"},
+		{text: "echo $${HOME} $$HOME", want: "echo $${HOME} $$HOME"},
+		{text: "No placeholders", want: "No placeholders"},
+	}
+	for _, tt := range tests {
+		if got := expandTemplate(tt.text, values); got != tt.want {
+			t.Errorf("expandTemplate(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaceRepo(t *testing.T) {
+	instance := templateInstance()
+	instance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{Repos: []klausv1alpha1.WorkspaceRepository{
+		{Repo: "https://github.com/org/first.git"},
+		{Repo: "https://github.com/org/second.git"},
+	}}
+	if got := workspaceRepo(instance); got != "https://github.com/org/first.git" {
+		t.Errorf("workspaceRepo() = %q, want the first repository", got)
+	}
+	instance.Spec.Workspace = nil
+	if got := workspaceRepo(instance); got != "" {
+		t.Errorf("workspaceRepo() without a workspace = %q, want none", got)
+	}
+}
+
+func TestBuildConfigMap_TemplateValues(t *testing.T) {
+	instance := templateInstance()
+	instance.Spec.Claude.SystemPrompt = "You help team ${team}."
+	instance.Spec.Claude.AppendSystemPrompt = "Report to ${klaus.owner}."
+	instance.Spec.Skills = map[string]klausv1alpha1.SkillConfig{
+		"deploy": {Description: "Deploy for ${team}", Content: "Deploy ${klaus.repo}."},
+	}
+	instance.Spec.Commands = map[string]klausv1alpha1.CommandConfig{"review": {Content: "Review ${klaus.repo} $ARGUMENTS."}}
+	instance.Spec.Memory = &klausv1alpha1.MemoryConfig{
+		Content: "Team ${team} owns this.",
+		Files:   map[string]string{"CLAUDE.md": "Ask ${klaus.owner}."},
+	}
+
+	cm, err := BuildConfigMap(instance, "test-ns")
+	if err != nil {
+		t.Fatalf("BuildConfigMap() error = %v", err)
+	}
+
+	want := map[string]string{
+		"system-prompt":        "You help team platform.",
+		"append-system-prompt": "Report to jane@example.com.",
+		MemoryConfigKey:        "Team platform owns this.\n",
+		"memoryfile-0":         "Ask jane@example.com.",
+	}
+	for key, value := range want {
+		if cm.Data[key] != value {
+			t.Errorf("data[%s] = %q, want %q", key, cm.Data[key], value)
+		}
+	}
+	if skill := cm.Data["skill-deploy"]; !strings.Contains(skill, `description: "Deploy for platform"`) ||
+		!strings.Contains(skill, "Deploy https://github.com/org/repo.git.") {
+		t.Errorf("skill-deploy = %q, want the values substituted", skill)
+	}
+	if command := cm.Data["command-review"]; !strings.Contains(command, "Review https://github.com/org/repo.git $ARGUMENTS.") {
+		t.Errorf("command-review = %q, want the values substituted", command)
+	}
+}
+
+func TestSetPersonalitySoul_TemplateValues(t *testing.T) {
+	cm := &corev1.ConfigMap{}
+	if err := SetPersonalitySoul(templateInstance(), cm, "You serve team ${team}."); err != nil {
+		t.Fatalf("SetPersonalitySoul() error = %v", err)
+	}
+	if want := "You serve team platform.\n"; cm.Data[MemoryConfigKey] != want {
+		t.Errorf("data[%s] = %q, want %q", MemoryConfigKey, cm.Data[MemoryConfigKey], want)
+	}
+}
+
+func TestValidateTemplateValues(t *testing.T) {
+	instance := templateInstance()
+	instance.Spec.TemplateValues = map[string]string{"team_name": "platform", "Region2": "eu"}
+	if err := validateTemplateValues(instance); err != nil {
+		t.Errorf("validateTemplateValues() error = %v", err)
+	}
+	for _, name := range []string{"klaus.owner", "2fa", "team-name", ""} {
+		instance.Spec.TemplateValues = map[string]string{name: "x"}
+		if err := validateTemplateValues(instance); err == nil {
+			t.Errorf("validateTemplateValues() of %q succeeded, want an error", name)
+		}
+	}
+}
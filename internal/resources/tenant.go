@@ -0,0 +1,64 @@
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TenantServiceAccountName is the ServiceAccount in each user namespace the
+// operator impersonates for its writes in that namespace, and the name of
+// the RoleBinding granting it the tenant ClusterRole.
+const TenantServiceAccountName = "klaus-operator-tenant"
+
+// TenantLabels returns the labels of the tenant ServiceAccount and
+// RoleBinding. They are shared by all instances of the owner.
+func TenantLabels(owner string) map[string]string {
+	return map[string]string{
+		LabelAppName:                  AppKlaus,
+		LabelManagedBy:                AppKlausOperator,
+		"app.kubernetes.io/component": "tenant",
+		LabelOwner:                    sanitizeLabelValue(owner),
+	}
+}
+
+// TenantUsername returns the username of the tenant ServiceAccount of a
+// user namespace, as the API server authenticates it.
+func TenantUsername(namespace string) string {
+	return "system:serviceaccount:" + namespace + ":" + TenantServiceAccountName
+}
+
+// BuildTenantServiceAccount creates the tenant ServiceAccount of a user
+// namespace. It is never mounted into pods.
+func BuildTenantServiceAccount(owner, namespace string) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TenantServiceAccountName,
+			Namespace: namespace,
+			Labels:    TenantLabels(owner),
+		},
+		AutomountServiceAccountToken: new(bool),
+	}
+}
+
+// BuildTenantRoleBinding creates the RoleBinding granting the tenant
+// ServiceAccount the given ClusterRole in its namespace.
+func BuildTenantRoleBinding(owner, namespace, clusterRole string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TenantServiceAccountName,
+			Namespace: namespace,
+			Labels:    TenantLabels(owner),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      TenantServiceAccountName,
+			Namespace: namespace,
+		}},
+	}
+}
@@ -0,0 +1,45 @@
+package resources
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestIsUserNamespace(t *testing.T) {
+	if !IsUserNamespace(UserNamespace("user@example.com")) {
+		t.Error("expected the owner namespace to be a user namespace")
+	}
+	for _, ns := range []string{"klaus-system", "", "klaus-user"} {
+		if IsUserNamespace(ns) {
+			t.Errorf("IsUserNamespace(%q) = true", ns)
+		}
+	}
+}
+
+func TestBuildTenantRoleBinding(t *testing.T) {
+	rb := BuildTenantRoleBinding("user@example.com", "klaus-user-user-example-com", "klaus-operator-tenant")
+	if rb.Name != TenantServiceAccountName || rb.Namespace != "klaus-user-user-example-com" {
+		t.Errorf("RoleBinding = %s/%s", rb.Namespace, rb.Name)
+	}
+	if rb.RoleRef.Kind != "ClusterRole" || rb.RoleRef.Name != "klaus-operator-tenant" || rb.RoleRef.APIGroup != rbacv1.GroupName {
+		t.Errorf("RoleRef = %+v", rb.RoleRef)
+	}
+	if len(rb.Subjects) != 1 || rb.Subjects[0].Kind != rbacv1.ServiceAccountKind ||
+		rb.Subjects[0].Name != TenantServiceAccountName || rb.Subjects[0].Namespace != "klaus-user-user-example-com" {
+		t.Errorf("Subjects = %+v, want the tenant ServiceAccount", rb.Subjects)
+	}
+	if rb.Labels[LabelOwner] != "user-example-com" {
+		t.Errorf("Labels = %v", rb.Labels)
+	}
+}
+
+func TestBuildTenantServiceAccount(t *testing.T) {
+	sa := BuildTenantServiceAccount("user@example.com", "klaus-user-user-example-com")
+	if sa.AutomountServiceAccountToken == nil || *sa.AutomountServiceAccountToken {
+		t.Error("the tenant ServiceAccount token must not be mounted")
+	}
+	if got, want := TenantUsername(sa.Namespace), "system:serviceaccount:klaus-user-user-example-com:"+TenantServiceAccountName; got != want {
+		t.Errorf("TenantUsername() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// exitSummaryType is the "type" of the JSON exit summary the klaus container
+// writes to its termination message.
+const exitSummaryType = "exit_summary"
+
+// exitSummary is the JSON exit summary written by the klaus container. The
+// field names follow the Claude Code result message.
+type exitSummary struct {
+	Type         string          `json:"type"`
+	NumTurns     *int32          `json:"num_turns,omitempty"`
+	TotalCostUSD *float64        `json:"total_cost_usd,omitempty"`
+	DurationMs   *int64          `json:"duration_ms,omitempty"`
+	IsError      bool            `json:"is_error,omitempty"`
+	Result       json.RawMessage `json:"result,omitempty"`
+	ResultPath   string          `json:"result_path,omitempty"`
+}
+
+// JobTerminationMessagePath returns the termination message path of the
+// klaus container in a KlausJob pod.
+func JobTerminationMessagePath(job *klausv1alpha1.KlausJob) string {
+	if tm := job.Spec.TerminationMessage; tm != nil && tm.Path != "" {
+		return tm.Path
+	}
+	return corev1.TerminationMessagePathDefault
+}
+
+// JobTerminationMessagePolicy returns the termination message policy of the
+// klaus container in a KlausJob pod.
+func JobTerminationMessagePolicy(job *klausv1alpha1.KlausJob) corev1.TerminationMessagePolicy {
+	if tm := job.Spec.TerminationMessage; tm != nil && tm.Policy != "" {
+		return tm.Policy
+	}
+	return corev1.TerminationMessageFallbackToLogsOnError
+}
+
+// JobExitSummaryEnabled reports whether the agent writes a JSON exit summary
+// to its termination message.
+func JobExitSummaryEnabled(job *klausv1alpha1.KlausJob) bool {
+	if tm := job.Spec.TerminationMessage; tm != nil && tm.ExitSummary != nil {
+		return *tm.ExitSummary
+	}
+	return true
+}
+
+// buildTerminationEnvVars tells the klaus container where to write its
+// termination message and in which format.
+func buildTerminationEnvVars(job *klausv1alpha1.KlausJob) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{
+		{Name: "KLAUS_TERMINATION_MESSAGE_PATH", Value: JobTerminationMessagePath(job)},
+	}
+	if JobExitSummaryEnabled(job) {
+		envVars = append(envVars, corev1.EnvVar{Name: "KLAUS_EXIT_SUMMARY", Value: envValueTrue})
+	}
+	return envVars
+}
+
+// ParseTerminationMessage splits a klaus container termination message into
+// the agent result and, when summaryEnabled is set and the message is a JSON
+// exit summary, the summary. Any other message (including the log tail used
+// by FallbackToLogsOnError) is returned verbatim as the result.
+func ParseTerminationMessage(message string, summaryEnabled bool) (string, *klausv1alpha1.JobExitSummary) {
+	if !summaryEnabled {
+		return message, nil
+	}
+	var parsed exitSummary
+	if err := json.Unmarshal([]byte(message), &parsed); err != nil || parsed.Type != exitSummaryType {
+		return message, nil
+	}
+
+	summary := &klausv1alpha1.JobExitSummary{
+		Turns:                parsed.NumTurns,
+		DurationMilliseconds: parsed.DurationMs,
+		IsError:              parsed.IsError,
+		ResultPath:           parsed.ResultPath,
+	}
+	if parsed.TotalCostUSD != nil {
+		summary.CostUSD = strconv.FormatFloat(*parsed.TotalCostUSD, 'f', -1, 64)
+	}
+	return summaryResult(parsed.Result), summary
+}
+
+// summaryResult returns a text result as-is and stores structured output
+// verbatim as compact JSON.
+func summaryResult(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
@@ -0,0 +1,68 @@
+package resources
+
+import (
+	"testing"
+)
+
+func TestParseTerminationMessage_Summary(t *testing.T) {
+	message := `{"type":"exit_summary","num_turns":7,"total_cost_usd":0.0421,"duration_ms":93000,"result":"All tests pass.","result_path":"/workspace/.klaus/result.md"}`
+
+	result, summary := ParseTerminationMessage(message, true)
+	if result != "All tests pass." {
+		t.Errorf("result = %q, want %q", result, "All tests pass.")
+	}
+	if summary == nil {
+		t.Fatal("expected an exit summary")
+	}
+	if summary.Turns == nil || *summary.Turns != 7 {
+		t.Errorf("Turns = %v, want 7", summary.Turns)
+	}
+	if summary.CostUSD != "0.0421" {
+		t.Errorf("CostUSD = %q, want 0.0421", summary.CostUSD)
+	}
+	if summary.DurationMilliseconds == nil || *summary.DurationMilliseconds != 93000 {
+		t.Errorf("DurationMilliseconds = %v, want 93000", summary.DurationMilliseconds)
+	}
+	if summary.ResultPath != "/workspace/.klaus/result.md" {
+		t.Errorf("ResultPath = %q", summary.ResultPath)
+	}
+	if summary.IsError {
+		t.Error("IsError = true, want false")
+	}
+}
+
+func TestParseTerminationMessage_StructuredResult(t *testing.T) {
+	message := `{"type":"exit_summary","is_error":true,"result":{ "issues": [1, 2] }}`
+
+	result, summary := ParseTerminationMessage(message, true)
+	if result != `{"issues":[1,2]}` {
+		t.Errorf("result = %q, want compact JSON", result)
+	}
+	if summary == nil || !summary.IsError {
+		t.Errorf("summary = %+v, want IsError", summary)
+	}
+}
+
+func TestParseTerminationMessage_Verbatim(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		enabled bool
+	}{
+		{name: "plain text", message: "done", enabled: true},
+		{name: "log tail", message: "Error: something failed\n", enabled: true},
+		{name: "json without summary type", message: `{"summary":"no issues"}`, enabled: true},
+		{name: "summary disabled", message: `{"type":"exit_summary","num_turns":1}`, enabled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, summary := ParseTerminationMessage(tt.message, tt.enabled)
+			if result != tt.message {
+				t.Errorf("result = %q, want %q", result, tt.message)
+			}
+			if summary != nil {
+				t.Errorf("summary = %+v, want nil", summary)
+			}
+		})
+	}
+}
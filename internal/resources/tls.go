@@ -0,0 +1,190 @@
+package resources
+
+import (
+	"crypto/tls"
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/certs"
+)
+
+const (
+	// TLSVolumeName is the name of the serving certificate volume.
+	TLSVolumeName = "tls"
+
+	// TLSMountPath is where the serving certificate Secret is mounted.
+	TLSMountPath = "/etc/klaus/tls"
+
+	// OperatorCASecretName is the Secret in the operator namespace holding
+	// the operator-managed CA.
+	OperatorCASecretName = "klaus-operator-ca"
+
+	// MusterClientCommonName is the common name of the client certificate
+	// muster presents to instances.
+	MusterClientCommonName = "muster"
+)
+
+// CertificateGVK is the GroupVersionKind of the cert-manager Certificate. It
+// is handled as an unstructured object so cert-manager stays optional.
+var CertificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// TLSIssuer returns the effective certificate issuer, or "" when TLS is not
+// enabled.
+func TLSIssuer(instance *klausv1alpha1.KlausInstance) klausv1alpha1.TLSIssuer {
+	if instance.Spec.TLS == nil {
+		return ""
+	}
+	if instance.Spec.TLS.Issuer == "" {
+		return klausv1alpha1.TLSIssuerOperator
+	}
+	return instance.Spec.TLS.Issuer
+}
+
+// TLSEnabled reports whether the instance Service is served over mTLS.
+func TLSEnabled(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.TLS != nil
+}
+
+// ServingCertSecretName returns the name of the serving certificate Secret
+// in the user namespace.
+func ServingCertSecretName(instance *klausv1alpha1.KlausInstance) string {
+	return instance.Name + "-serving-tls"
+}
+
+// ClientCertSecretName returns the name of the client certificate Secret in
+// the muster namespace, used by muster and the operator to reach the
+// instance.
+func ClientCertSecretName(instance *klausv1alpha1.KlausInstance) string {
+	return "klaus-" + instance.Name + "-client-tls"
+}
+
+// ServiceHost returns the fully qualified in-cluster host name of the
+// instance Service.
+func ServiceHost(instance *klausv1alpha1.KlausInstance, namespace string) string {
+	return ServiceName(instance) + "." + namespace + ".svc.cluster.local"
+}
+
+// ServiceDNSNames returns the DNS names the serving certificate covers.
+func ServiceDNSNames(instance *klausv1alpha1.KlausInstance, namespace string) []string {
+	svc := ServiceName(instance)
+	return []string{
+		svc + "." + namespace + ".svc.cluster.local",
+		svc + "." + namespace + ".svc",
+		svc + "." + namespace,
+		svc,
+	}
+}
+
+// BuildTLSSecret creates a kubernetes.io/tls Secret with the cert-manager
+// key layout (tls.crt, tls.key, ca.crt).
+func BuildTLSSecret(name, namespace string, labels map[string]string, pair *certs.KeyPair, caPEM []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			certs.CertKey: pair.CertPEM,
+			certs.KeyKey:  pair.KeyPEM,
+			certs.CAKey:   caPEM,
+		},
+	}
+}
+
+// BuildServingCertificate creates the cert-manager Certificate for the
+// instance serving certificate in the user namespace.
+func BuildServingCertificate(instance *klausv1alpha1.KlausInstance, namespace string) *unstructured.Unstructured {
+	dnsNames := make([]any, 0, 4)
+	for _, name := range ServiceDNSNames(instance, namespace) {
+		dnsNames = append(dnsNames, name)
+	}
+	return buildCertificate(instance, ServingCertSecretName(instance), namespace, map[string]any{
+		"commonName": ServiceHost(instance, namespace),
+		"dnsNames":   dnsNames,
+		"usages":     []any{"digital signature", "server auth"},
+	})
+}
+
+// BuildClientCertificate creates the cert-manager Certificate for the muster
+// client certificate in the muster namespace.
+func BuildClientCertificate(instance *klausv1alpha1.KlausInstance) *unstructured.Unstructured {
+	return buildCertificate(instance, ClientCertSecretName(instance), MusterNamespace(instance), map[string]any{
+		"commonName": MusterClientCommonName,
+		"usages":     []any{"digital signature", "client auth"},
+	})
+}
+
+func buildCertificate(instance *klausv1alpha1.KlausInstance, name, namespace string, spec map[string]any) *unstructured.Unstructured {
+	labels := map[string]any{}
+	for k, v := range InstanceLabels(instance) {
+		labels[k] = v
+	}
+	spec["secretName"] = name
+	spec["duration"] = certs.LeafValidity.String()
+	spec["privateKey"] = map[string]any{"algorithm": "ECDSA", "size": int64(256), "rotationPolicy": "Always"}
+	spec["issuerRef"] = map[string]any{
+		"group": CertificateGVK.Group,
+		"kind":  "ClusterIssuer",
+		"name":  instance.Spec.TLS.ClusterIssuer,
+	}
+	// Label the issued Secret so it is picked up by the instance watches.
+	spec["secretTemplate"] = map[string]any{"labels": labels}
+
+	cert := &unstructured.Unstructured{
+		Object: map[string]any{
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+				"labels":    labels,
+			},
+			"spec": spec,
+		},
+	}
+	cert.SetGroupVersionKind(CertificateGVK)
+	return cert
+}
+
+// ClientTLSConfig builds the TLS configuration for calling an instance
+// served over mTLS from its client certificate Secret.
+func ClientTLSConfig(instance *klausv1alpha1.KlausInstance, namespace string, secret *corev1.Secret) (*tls.Config, error) {
+	cfg, err := certs.ClientTLSConfig(secret.Data[certs.CertKey], secret.Data[certs.KeyKey], secret.Data[certs.CAKey])
+	if err != nil {
+		return nil, fmt.Errorf("client certificate secret %s: %w", secret.Name, err)
+	}
+	cfg.ServerName = ServiceHost(instance, namespace)
+	return cfg, nil
+}
+
+// buildTLSEnvVars points the klaus HTTP server at the mounted serving
+// certificate and the CA used to verify client certificates.
+func buildTLSEnvVars(instance *klausv1alpha1.KlausInstance) []corev1.EnvVar {
+	if !TLSEnabled(instance) {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: "KLAUS_TLS_CERT_FILE", Value: path.Join(TLSMountPath, certs.CertKey)},
+		{Name: "KLAUS_TLS_KEY_FILE", Value: path.Join(TLSMountPath, certs.KeyKey)},
+		{Name: "KLAUS_TLS_CLIENT_CA_FILE", Value: path.Join(TLSMountPath, certs.CAKey)},
+	}
+}
+
+// probeScheme returns the scheme of the health probes, which follow the
+// serving protocol of the klaus container.
+func probeScheme(instance *klausv1alpha1.KlausInstance) corev1.URIScheme {
+	if TLSEnabled(instance) {
+		return corev1.URISchemeHTTPS
+	}
+	return corev1.URISchemeHTTP
+}
@@ -0,0 +1,229 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/certs"
+)
+
+const tlsTestNamespace = "klaus-user-user-example-com"
+
+func tlsInstance(tlsConfig *klausv1alpha1.InstanceTLSConfig) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "user@example.com",
+			TLS:   tlsConfig,
+		},
+	}
+}
+
+func TestTLSIssuer(t *testing.T) {
+	if got := TLSIssuer(tlsInstance(nil)); got != "" {
+		t.Errorf("TLSIssuer() = %q, want empty without spec.tls", got)
+	}
+	if got := TLSIssuer(tlsInstance(&klausv1alpha1.InstanceTLSConfig{})); got != klausv1alpha1.TLSIssuerOperator {
+		t.Errorf("TLSIssuer() = %q, want %q by default", got, klausv1alpha1.TLSIssuerOperator)
+	}
+	cm := tlsInstance(&klausv1alpha1.InstanceTLSConfig{Issuer: klausv1alpha1.TLSIssuerCertManager, ClusterIssuer: "ca"})
+	if got := TLSIssuer(cm); got != klausv1alpha1.TLSIssuerCertManager {
+		t.Errorf("TLSIssuer() = %q, want %q", got, klausv1alpha1.TLSIssuerCertManager)
+	}
+}
+
+func TestServiceEndpoint_TLS(t *testing.T) {
+	instance := tlsInstance(&klausv1alpha1.InstanceTLSConfig{})
+	want := "https://my-agent." + tlsTestNamespace + ".svc.cluster.local:8080"
+	if got := ServiceEndpoint(instance, tlsTestNamespace); got != want {
+		t.Errorf("ServiceEndpoint() = %q, want %q", got, want)
+	}
+}
+
+func TestServiceDNSNames(t *testing.T) {
+	names := ServiceDNSNames(tlsInstance(nil), tlsTestNamespace)
+	want := []string{
+		"my-agent." + tlsTestNamespace + ".svc.cluster.local",
+		"my-agent." + tlsTestNamespace + ".svc",
+		"my-agent." + tlsTestNamespace,
+		"my-agent",
+	}
+	if len(names) != len(want) {
+		t.Fatalf("ServiceDNSNames() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("ServiceDNSNames()[%d] = %q, want %q", i, names[i], want[i])
+		}
+	}
+}
+
+func TestBuildServingCertificate(t *testing.T) {
+	instance := tlsInstance(&klausv1alpha1.InstanceTLSConfig{Issuer: klausv1alpha1.TLSIssuerCertManager, ClusterIssuer: "internal-ca"})
+	cert := BuildServingCertificate(instance, tlsTestNamespace)
+
+	if cert.GroupVersionKind() != CertificateGVK {
+		t.Errorf("GVK = %v, want %v", cert.GroupVersionKind(), CertificateGVK)
+	}
+	if cert.GetName() != "my-agent-serving-tls" || cert.GetNamespace() != tlsTestNamespace {
+		t.Errorf("Certificate = %s/%s, want %s/my-agent-serving-tls", cert.GetNamespace(), cert.GetName(), tlsTestNamespace)
+	}
+	secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+	if secretName != ServingCertSecretName(instance) {
+		t.Errorf("secretName = %q, want %q", secretName, ServingCertSecretName(instance))
+	}
+	dnsNames, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "dnsNames")
+	if len(dnsNames) != 4 || dnsNames[0] != ServiceHost(instance, tlsTestNamespace) {
+		t.Errorf("dnsNames = %v, want the Service DNS names", dnsNames)
+	}
+	usages, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "usages")
+	if len(usages) != 2 || usages[1] != "server auth" {
+		t.Errorf("usages = %v, want server auth", usages)
+	}
+	issuerKind, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "kind")
+	issuerName, _, _ := unstructured.NestedString(cert.Object, "spec", "issuerRef", "name")
+	if issuerKind != "ClusterIssuer" || issuerName != "internal-ca" {
+		t.Errorf("issuerRef = %s/%s, want ClusterIssuer/internal-ca", issuerKind, issuerName)
+	}
+	secretLabels, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "secretTemplate", "labels")
+	if secretLabels[LabelManagedBy] != AppKlausOperator {
+		t.Errorf("secretTemplate labels = %v, want managed-by label", secretLabels)
+	}
+}
+
+func TestBuildClientCertificate(t *testing.T) {
+	instance := tlsInstance(&klausv1alpha1.InstanceTLSConfig{Issuer: klausv1alpha1.TLSIssuerCertManager, ClusterIssuer: "internal-ca"})
+	cert := BuildClientCertificate(instance)
+
+	if cert.GetName() != "klaus-my-agent-client-tls" || cert.GetNamespace() != MusterNamespace(instance) {
+		t.Errorf("Certificate = %s/%s, want %s/klaus-my-agent-client-tls", cert.GetNamespace(), cert.GetName(), MusterNamespace(instance))
+	}
+	commonName, _, _ := unstructured.NestedString(cert.Object, "spec", "commonName")
+	if commonName != MusterClientCommonName {
+		t.Errorf("commonName = %q, want %q", commonName, MusterClientCommonName)
+	}
+	usages, _, _ := unstructured.NestedStringSlice(cert.Object, "spec", "usages")
+	if len(usages) != 2 || usages[1] != "client auth" {
+		t.Errorf("usages = %v, want client auth", usages)
+	}
+}
+
+func TestBuildPodTemplate_TLS(t *testing.T) {
+	instance := tlsInstance(&klausv1alpha1.InstanceTLSConfig{})
+	tmpl := BuildPodTemplate(instance, "klaus:latest", "", nil)
+	container := tmpl.Spec.Containers[0]
+
+	if container.LivenessProbe.HTTPGet.Scheme != corev1.URISchemeHTTPS || container.ReadinessProbe.HTTPGet.Scheme != corev1.URISchemeHTTPS {
+		t.Error("expected HTTPS probes with TLS enabled")
+	}
+
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{
+		"KLAUS_TLS_CERT_FILE":      "/etc/klaus/tls/tls.crt",
+		"KLAUS_TLS_KEY_FILE":       "/etc/klaus/tls/tls.key",
+		"KLAUS_TLS_CLIENT_CA_FILE": "/etc/klaus/tls/ca.crt",
+	} {
+		if env[name] != want {
+			t.Errorf("env %s = %q, want %q", name, env[name], want)
+		}
+	}
+
+	var mounted bool
+	for _, m := range container.VolumeMounts {
+		if m.Name == TLSVolumeName && m.MountPath == TLSMountPath && m.ReadOnly {
+			mounted = true
+		}
+	}
+	if !mounted {
+		t.Error("expected read-only TLS volume mount")
+	}
+	var secretName string
+	for _, v := range tmpl.Spec.Volumes {
+		if v.Name == TLSVolumeName && v.Secret != nil {
+			secretName = v.Secret.SecretName
+		}
+	}
+	if secretName != ServingCertSecretName(instance) {
+		t.Errorf("TLS volume secret = %q, want %q", secretName, ServingCertSecretName(instance))
+	}
+}
+
+func TestBuildPodTemplate_NoTLS(t *testing.T) {
+	tmpl := BuildPodTemplate(tlsInstance(nil), "klaus:latest", "", nil)
+	container := tmpl.Spec.Containers[0]
+
+	if container.LivenessProbe.HTTPGet.Scheme != corev1.URISchemeHTTP {
+		t.Errorf("liveness scheme = %q, want HTTP", container.LivenessProbe.HTTPGet.Scheme)
+	}
+	for _, e := range container.Env {
+		if e.Name == "KLAUS_TLS_CERT_FILE" {
+			t.Error("unexpected TLS env var without spec.tls")
+		}
+	}
+	for _, v := range tmpl.Spec.Volumes {
+		if v.Name == TLSVolumeName {
+			t.Error("unexpected TLS volume without spec.tls")
+		}
+	}
+}
+
+func TestBuildMCPServerCRD_TLS(t *testing.T) {
+	instance := tlsInstance(&klausv1alpha1.InstanceTLSConfig{})
+	mcpServer := BuildMCPServerCRD(instance, tlsTestNamespace)
+
+	url, _, _ := unstructured.NestedString(mcpServer.Object, "spec", "url")
+	if url != ServiceEndpoint(instance, tlsTestNamespace)+"/mcp" {
+		t.Errorf("url = %q, want https service endpoint", url)
+	}
+	serverName, _, _ := unstructured.NestedString(mcpServer.Object, "spec", "tls", "serverName")
+	if serverName != ServiceHost(instance, tlsTestNamespace) {
+		t.Errorf("tls.serverName = %q, want %q", serverName, ServiceHost(instance, tlsTestNamespace))
+	}
+	caSecret, _, _ := unstructured.NestedString(mcpServer.Object, "spec", "tls", "caSecretRef", "name")
+	clientSecret, _, _ := unstructured.NestedString(mcpServer.Object, "spec", "tls", "clientCertSecretRef", "name")
+	if caSecret != ClientCertSecretName(instance) || clientSecret != ClientCertSecretName(instance) {
+		t.Errorf("tls secret refs = %q/%q, want %q", caSecret, clientSecret, ClientCertSecretName(instance))
+	}
+
+	if _, found, _ := unstructured.NestedMap(BuildMCPServerCRD(tlsInstance(nil), tlsTestNamespace).Object, "spec", "tls"); found {
+		t.Error("unexpected spec.tls without TLS enabled")
+	}
+}
+
+func TestClientTLSConfig(t *testing.T) {
+	now := time.Now()
+	ca, _, err := certs.NewCA("test-ca", now)
+	if err != nil {
+		t.Fatalf("NewCA() error = %v", err)
+	}
+	pair, err := ca.IssueClient(MusterClientCommonName, now)
+	if err != nil {
+		t.Fatalf("IssueClient() error = %v", err)
+	}
+	instance := tlsInstance(&klausv1alpha1.InstanceTLSConfig{})
+	secret := BuildTLSSecret(ClientCertSecretName(instance), MusterNamespace(instance), nil, pair, ca.CertPEM)
+
+	cfg, err := ClientTLSConfig(instance, tlsTestNamespace, secret)
+	if err != nil {
+		t.Fatalf("ClientTLSConfig() error = %v", err)
+	}
+	if cfg.ServerName != ServiceHost(instance, tlsTestNamespace) {
+		t.Errorf("ServerName = %q, want %q", cfg.ServerName, ServiceHost(instance, tlsTestNamespace))
+	}
+	if len(cfg.Certificates) != 1 {
+		t.Errorf("expected the client certificate, got %d certificates", len(cfg.Certificates))
+	}
+
+	secret.Data[certs.CAKey] = nil
+	if _, err := ClientTLSConfig(instance, tlsTestNamespace, secret); err == nil {
+		t.Error("expected error for a secret without ca.crt")
+	}
+}
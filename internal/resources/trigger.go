@@ -0,0 +1,119 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// LabelTrigger identifies KlausJobs created by a KlausTrigger.
+	LabelTrigger = "klaus.giantswarm.io/trigger"
+
+	// AnnotationTriggerEvent records the event a KlausJob was created for.
+	AnnotationTriggerEvent = "klaus.giantswarm.io/trigger-event"
+
+	// TriggerPathPrefix is the path prefix of triggers on the trigger
+	// listener.
+	TriggerPathPrefix = "/triggers/"
+
+	// defaultTriggerSecretKey is the Secret key read when
+	// spec.source.secretRef.key is unset.
+	defaultTriggerSecretKey = "token"
+)
+
+// TriggerPath returns the path a KlausTrigger receives events on.
+func TriggerPath(trigger *klausv1alpha1.KlausTrigger) string {
+	return TriggerPathPrefix + trigger.Namespace + "/" + trigger.Name
+}
+
+// TriggerSecretKey returns the Secret key holding the token or webhook
+// secret of a KlausTrigger.
+func TriggerSecretKey(trigger *klausv1alpha1.KlausTrigger) string {
+	if key := trigger.Spec.Source.SecretRef.Key; key != "" {
+		return key
+	}
+	return defaultTriggerSecretKey
+}
+
+// ValidateTriggerSpec checks the constraints of a KlausTrigger that the CRD
+// schema cannot express.
+func ValidateTriggerSpec(trigger *klausv1alpha1.KlausTrigger) error {
+	spec := &trigger.Spec
+	if (spec.Job == nil) == (spec.Instance == nil) {
+		return fmt.Errorf("exactly one of spec.job and spec.instance must be set")
+	}
+	if spec.Instance != nil && spec.PromptTemplate == "" {
+		return fmt.Errorf("spec.promptTemplate is required with spec.instance")
+	}
+	if spec.PromptTemplate != "" {
+		if _, err := parseTriggerTemplate(spec.PromptTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseTriggerTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing spec.promptTemplate: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTriggerPrompt renders the prompt template of a KlausTrigger for an
+// event with its decoded JSON payload. Fields missing from the payload are
+// an error, so a prompt is never sent with holes.
+func RenderTriggerPrompt(trigger *klausv1alpha1.KlausTrigger, event string, payload any) (string, error) {
+	tmpl, err := parseTriggerTemplate(trigger.Spec.PromptTemplate)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, map[string]any{
+		"event":   event,
+		"payload": payload,
+		"trigger": trigger.Name,
+	}); err != nil {
+		return "", fmt.Errorf("rendering spec.promptTemplate: %w", err)
+	}
+	return b.String(), nil
+}
+
+// BuildTriggerJob renders the KlausJob for an event of a KlausTrigger with a
+// job action. A non-empty prompt replaces the prompt of the job template.
+// With a delivery ID the name is derived from it, so a redelivered event
+// never creates a second job; otherwise the API server generates the name.
+func BuildTriggerJob(trigger *klausv1alpha1.KlausTrigger, event, prompt, delivery string) *klausv1alpha1.KlausJob {
+	job := &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: trigger.Namespace,
+			Labels: map[string]string{
+				LabelManagedBy: AppKlausOperator,
+				LabelTrigger:   trigger.Name,
+			},
+			Annotations: map[string]string{
+				AnnotationTriggerEvent: event,
+			},
+		},
+		Spec: *trigger.Spec.Job.JobTemplate.DeepCopy(),
+	}
+	if delivery != "" {
+		sum := sha256.Sum256([]byte(delivery))
+		job.Name = trigger.Name + "-" + hex.EncodeToString(sum[:])[:10]
+	} else {
+		job.GenerateName = trigger.Name + "-"
+	}
+	if prompt != "" {
+		job.Spec.Prompt = prompt
+		job.Spec.PromptTemplate = nil
+	}
+	return job
+}
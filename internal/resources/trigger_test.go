@@ -0,0 +1,120 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testTrigger() *klausv1alpha1.KlausTrigger {
+	return &klausv1alpha1.KlausTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "pr-review", Namespace: "team-a"},
+		Spec: klausv1alpha1.KlausTriggerSpec{
+			Owner:          "user@example.com",
+			Source:         klausv1alpha1.TriggerSource{Type: klausv1alpha1.TriggerSourceGitHub, SecretRef: klausv1alpha1.TriggerSecretReference{Name: "github"}},
+			PromptTemplate: "Review {{ .payload.pull_request.html_url }} ({{ .event }})",
+			Job: &klausv1alpha1.TriggerJobAction{JobTemplate: klausv1alpha1.KlausJobSpec{
+				Owner:          "user@example.com",
+				PromptTemplate: &klausv1alpha1.PromptTemplate{Template: "unused"},
+			}},
+		},
+	}
+}
+
+func TestTriggerPathAndSecretKey(t *testing.T) {
+	trigger := testTrigger()
+	if got := TriggerPath(trigger); got != "/triggers/team-a/pr-review" {
+		t.Errorf("TriggerPath = %q", got)
+	}
+	if got := TriggerSecretKey(trigger); got != "token" {
+		t.Errorf("TriggerSecretKey = %q, want the default", got)
+	}
+	trigger.Spec.Source.SecretRef.Key = "webhook-secret"
+	if got := TriggerSecretKey(trigger); got != "webhook-secret" {
+		t.Errorf("TriggerSecretKey = %q, want webhook-secret", got)
+	}
+}
+
+func TestValidateTriggerSpec(t *testing.T) {
+	if err := ValidateTriggerSpec(testTrigger()); err != nil {
+		t.Errorf("valid trigger: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*klausv1alpha1.KlausTrigger)
+		want   string
+	}{
+		{name: "no action", mutate: func(tr *klausv1alpha1.KlausTrigger) { tr.Spec.Job = nil }, want: "exactly one"},
+		{name: "both actions", mutate: func(tr *klausv1alpha1.KlausTrigger) {
+			tr.Spec.Instance = &klausv1alpha1.TriggerInstanceAction{Name: "agent"}
+		}, want: "exactly one"},
+		{name: "instance without template", mutate: func(tr *klausv1alpha1.KlausTrigger) {
+			tr.Spec.Job, tr.Spec.PromptTemplate = nil, ""
+			tr.Spec.Instance = &klausv1alpha1.TriggerInstanceAction{Name: "agent"}
+		}, want: "promptTemplate is required"},
+		{name: "bad template", mutate: func(tr *klausv1alpha1.KlausTrigger) { tr.Spec.PromptTemplate = "{{ .event" }, want: "parsing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := testTrigger()
+			tt.mutate(trigger)
+			err := ValidateTriggerSpec(trigger)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTriggerPrompt(t *testing.T) {
+	trigger := testTrigger()
+	payload := map[string]any{"pull_request": map[string]any{"html_url": "https://github.com/org/repo/pull/7"}}
+
+	prompt, err := RenderTriggerPrompt(trigger, "pull_request", payload)
+	if err != nil {
+		t.Fatalf("RenderTriggerPrompt: %v", err)
+	}
+	if prompt != "Review https://github.com/org/repo/pull/7 (pull_request)" {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	if _, err := RenderTriggerPrompt(trigger, "push", map[string]any{}); err == nil {
+		t.Error("expected an error for a payload without the templated field")
+	}
+}
+
+func TestBuildTriggerJob(t *testing.T) {
+	trigger := testTrigger()
+
+	job := BuildTriggerJob(trigger, "pull_request", "Review it", "delivery-1")
+	if job.Namespace != "team-a" || job.Labels[LabelTrigger] != "pr-review" || job.Labels[LabelManagedBy] != AppKlausOperator {
+		t.Errorf("job metadata = %+v", job.ObjectMeta)
+	}
+	if job.Annotations[AnnotationTriggerEvent] != "pull_request" {
+		t.Errorf("annotations = %v, want the event", job.Annotations)
+	}
+	if job.Spec.Prompt != "Review it" || job.Spec.PromptTemplate != nil {
+		t.Errorf("spec prompt = %q, template = %v; want the rendered prompt only", job.Spec.Prompt, job.Spec.PromptTemplate)
+	}
+	if !strings.HasPrefix(job.Name, "pr-review-") || job.GenerateName != "" {
+		t.Errorf("name = %q, generateName = %q; want a delivery based name", job.Name, job.GenerateName)
+	}
+	if again := BuildTriggerJob(trigger, "pull_request", "Review it", "delivery-1"); again.Name != job.Name {
+		t.Errorf("redelivery name = %q, want %q", again.Name, job.Name)
+	}
+
+	job = BuildTriggerJob(trigger, "push", "", "")
+	if job.Name != "" || job.GenerateName != "pr-review-" {
+		t.Errorf("name = %q, generateName = %q; want a generated name", job.Name, job.GenerateName)
+	}
+	if job.Spec.PromptTemplate == nil {
+		t.Error("the job template prompt was dropped without a rendered prompt")
+	}
+	if trigger.Spec.Job.JobTemplate.Prompt != "" {
+		t.Error("BuildTriggerJob modified the trigger")
+	}
+}
@@ -2,9 +2,11 @@ package resources
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 
 	klausoci "github.com/giantswarm/klaus-oci"
+	corev1 "k8s.io/api/core/v1"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
 )
@@ -16,36 +18,246 @@ func ValidateSpec(instance *klausv1alpha1.KlausInstance) error {
 	if err := validateHooksExclusivity(instance); err != nil {
 		return err
 	}
+	if err := validatePersonality(instance); err != nil {
+		return err
+	}
+	if err := validateToolchain(instance); err != nil {
+		return err
+	}
 	if err := validatePlugins(instance); err != nil {
 		return err
 	}
 	if err := validateWorkspace(instance); err != nil {
 		return err
 	}
+	if err := validateCloneFrom(instance); err != nil {
+		return err
+	}
+	if err := validateProvider(instance); err != nil {
+		return err
+	}
+	if err := validateTLS(instance); err != nil {
+		return err
+	}
+	if err := validateExpose(instance); err != nil {
+		return err
+	}
+	if err := validateScheduling(instance); err != nil {
+		return err
+	}
+	if err := validateProbes(instance); err != nil {
+		return err
+	}
+	if err := validateExtraEnv(instance); err != nil {
+		return err
+	}
+	if err := validateNetwork(instance); err != nil {
+		return err
+	}
+	if err := validateMemory(instance); err != nil {
+		return err
+	}
+	if err := validateTemplateValues(instance); err != nil {
+		return err
+	}
+	if err := validateConfigSize(instance); err != nil {
+		return err
+	}
 	return nil
 }
 
-// validateHooksExclusivity ensures that inline hooks and settingsFile are
-// mutually exclusive -- you cannot specify both because they both control
+// ValidateInstanceSpec validates a KlausInstance spec: the ValidateSpec rules
+// shared with KlausJobs and the fields only KlausJobs support.
+func ValidateInstanceSpec(instance *klausv1alpha1.KlausInstance) error {
+	if instance.Spec.Workspace != nil && instance.Spec.Workspace.Output != nil {
+		return fmt.Errorf("spec.workspace.output is only supported by KlausJobs")
+	}
+	if err := validateDesiredState(instance); err != nil {
+		return err
+	}
+	return ValidateSpec(instance)
+}
+
+// validateHooksExclusivity ensures that the keys rendered to settings.json
+// -- inline hooks, the output style and the status line -- and settingsFile
+// are mutually exclusive: you cannot specify both because they both control
 // settings.json.
 func validateHooksExclusivity(instance *klausv1alpha1.KlausInstance) error {
-	if len(instance.Spec.Hooks) > 0 && instance.Spec.Claude.SettingsFile != "" {
-		return fmt.Errorf("spec.hooks and spec.claude.settingsFile are mutually exclusive: " +
-			"hooks are rendered to settings.json, but settingsFile points to a custom path")
+	if instance.Spec.Claude.SettingsFile == "" {
+		return nil
+	}
+	var fields []string
+	if len(instance.Spec.Hooks) > 0 {
+		fields = append(fields, "spec.hooks")
+	}
+	if instance.Spec.Claude.OutputStyle != "" {
+		fields = append(fields, "spec.claude.outputStyle")
+	}
+	if instance.Spec.Claude.StatusLine != nil {
+		fields = append(fields, "spec.claude.statusLine")
+	}
+	if len(fields) > 0 {
+		return fmt.Errorf("%s and spec.claude.settingsFile are mutually exclusive: "+
+			"they are rendered to settings.json, but settingsFile points to a custom path", strings.Join(fields, ", "))
 	}
 	return nil
 }
 
+// validatePersonality ensures that a personality reference and an inline
+// personality are mutually exclusive -- both provide the soul of the agent.
+func validatePersonality(instance *klausv1alpha1.KlausInstance) error {
+	if instance.Spec.Personality != "" && instance.Spec.InlinePersonality != nil {
+		return fmt.Errorf("spec.personality and spec.inlinePersonality are mutually exclusive")
+	}
+	return nil
+}
+
+// validateToolchain ensures that an image and a toolchain reference are
+// mutually exclusive -- both select the container image.
+func validateToolchain(instance *klausv1alpha1.KlausInstance) error {
+	if instance.Spec.Image != "" && instance.Spec.ToolchainRef != "" {
+		return fmt.Errorf("spec.image and spec.toolchainRef are mutually exclusive")
+	}
+	return nil
+}
+
+// ValidateToolchainPersonality ensures that an instance referencing a
+// KlausToolchain which lists personalities uses one of them. Personalities
+// are compared by short name, so go-dev matches any tag or registry of the
+// go-dev repository; inline personalities and instances without a
+// personality match none.
+func ValidateToolchainPersonality(toolchain *klausv1alpha1.KlausToolchain, instance *klausv1alpha1.KlausInstance) error {
+	if len(toolchain.Spec.Personalities) == 0 {
+		return nil
+	}
+	if ref := instance.Spec.Personality; ref != "" {
+		name := personalityShortName(ref)
+		for _, personality := range toolchain.Spec.Personalities {
+			if personalityShortName(personality) == name {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("toolchain %s is restricted to the personalities %s: set spec.personality to one of them",
+		toolchain.Name, strings.Join(toolchain.Spec.Personalities, ", "))
+}
+
+// personalityShortName returns the repository name of a personality
+// reference without registry, tag or digest, e.g. go-dev for
+// gsoci.azurecr.io/giantswarm/klaus-personalities/go-dev:v1.0.0.
+func personalityShortName(ref string) string {
+	name, _, _ := strings.Cut(klausoci.ShortName(klausoci.RepositoryFromRef(ref)), ":")
+	return name
+}
+
 // validateWorkspace checks workspace configuration consistency: gitSecretRef
-// requires gitRepo to be set, otherwise the Secret is copied and a volume
-// is created but nothing consumes them.
+// and gitHubApp require gitRepo to be set, otherwise the Secret is created
+// and a volume is mounted but nothing consumes them. GitHub App tokens are
+// scoped to the repository, which therefore has to be an HTTPS URL naming
+// it. The setup timeout is bounded so a hanging command cannot block the
+// pod indefinitely.
 func validateWorkspace(instance *klausv1alpha1.KlausInstance) error {
-	if instance.Spec.Workspace == nil {
+	ws := instance.Spec.Workspace
+	if ws == nil {
 		return nil
 	}
-	if instance.Spec.Workspace.GitSecretRef != nil && instance.Spec.Workspace.GitRepo == "" {
+	if err := validateWorkspaceRepos(ws); err != nil {
+		return err
+	}
+	if ws.SetupTimeout != nil && (ws.SetupTimeout.Duration <= 0 || ws.SetupTimeout.Duration > MaxWorkspaceSetupTimeout) {
+		return fmt.Errorf("spec.workspace.setupTimeout must be positive and at most %s", MaxWorkspaceSetupTimeout)
+	}
+	if ws.GitSecretRef != nil && ws.GitRepo == "" {
 		return fmt.Errorf("spec.workspace.gitSecretRef requires spec.workspace.gitRepo to be set")
 	}
+	if ws.GitHubApp != nil {
+		if ws.GitSecretRef != nil {
+			return fmt.Errorf("spec.workspace.gitSecretRef and spec.workspace.gitHubApp are mutually exclusive")
+		}
+		if ws.GitRepo == "" {
+			return fmt.Errorf("spec.workspace.gitHubApp requires spec.workspace.gitRepo to be set")
+		}
+		if _, _, err := GitHubRepository(ws.GitRepo); err != nil {
+			return fmt.Errorf("spec.workspace.gitHubApp: %w", err)
+		}
+	}
+	if ws.Output != nil {
+		return validateWorkspaceOutput(ws)
+	}
+	return nil
+}
+
+// validateProvider checks that the provider block matching the selected
+// provider type is present and complete, and that no block for a different
+// provider is set.
+func validateProvider(instance *klausv1alpha1.KlausInstance) error {
+	p := instance.Spec.Claude.Provider
+	if p == nil {
+		return nil
+	}
+	switch Provider(instance) {
+	case klausv1alpha1.ProviderAnthropic:
+		if p.Bedrock != nil || p.Vertex != nil {
+			return fmt.Errorf("spec.claude.provider: bedrock and vertex settings require the matching provider type")
+		}
+	case klausv1alpha1.ProviderBedrock:
+		if p.Bedrock == nil || p.Bedrock.Region == "" {
+			return fmt.Errorf("spec.claude.provider.bedrock.region is required when provider type is bedrock")
+		}
+		if p.Vertex != nil {
+			return fmt.Errorf("spec.claude.provider.vertex must not be set when provider type is bedrock")
+		}
+	case klausv1alpha1.ProviderVertex:
+		if p.Vertex == nil || p.Vertex.ProjectID == "" || p.Vertex.Region == "" {
+			return fmt.Errorf("spec.claude.provider.vertex.projectID and region are required when provider type is vertex")
+		}
+		if p.Bedrock != nil {
+			return fmt.Errorf("spec.claude.provider.bedrock must not be set when provider type is vertex")
+		}
+	}
+	return nil
+}
+
+// validateExpose checks that the expose settings match the expose type.
+func validateExpose(instance *klausv1alpha1.KlausInstance) error {
+	expose := instance.Spec.Expose
+	if expose == nil {
+		return nil
+	}
+	if expose.Hostname == "" {
+		return fmt.Errorf("spec.expose.hostname is required")
+	}
+	if expose.Path != "" && !strings.HasPrefix(expose.Path, "/") {
+		return fmt.Errorf("spec.expose.path must start with /")
+	}
+	switch ExposeType(instance) {
+	case klausv1alpha1.ExposeTypeIngress:
+		if expose.Gateway != nil {
+			return fmt.Errorf("spec.expose.gateway requires expose type HTTPRoute")
+		}
+	case klausv1alpha1.ExposeTypeHTTPRoute:
+		if expose.Gateway == nil || expose.Gateway.Name == "" {
+			return fmt.Errorf("spec.expose.gateway.name is required when expose type is HTTPRoute")
+		}
+		if expose.IngressClassName != nil {
+			return fmt.Errorf("spec.expose.ingressClassName must not be set when expose type is HTTPRoute")
+		}
+	}
+	return nil
+}
+
+// validateTLS checks that the TLS settings match the certificate issuer.
+func validateTLS(instance *klausv1alpha1.KlausInstance) error {
+	switch TLSIssuer(instance) {
+	case klausv1alpha1.TLSIssuerCertManager:
+		if instance.Spec.TLS.ClusterIssuer == "" {
+			return fmt.Errorf("spec.tls.clusterIssuer is required when issuer is CertManager")
+		}
+	case klausv1alpha1.TLSIssuerOperator:
+		if instance.Spec.TLS.ClusterIssuer != "" {
+			return fmt.Errorf("spec.tls.clusterIssuer requires issuer CertManager")
+		}
+	}
 	return nil
 }
 
@@ -91,3 +303,103 @@ func ValidatePluginRefs(plugins []klausv1alpha1.PluginReference) error {
 
 	return nil
 }
+
+// validateScheduling checks the disruption budget settings.
+func validateScheduling(instance *klausv1alpha1.KlausInstance) error {
+	sched := instance.Spec.Scheduling
+	if sched == nil || sched.DisruptionBudget == nil {
+		return nil
+	}
+	if sched.DisruptionBudget.MinAvailable != nil && sched.DisruptionBudget.MaxUnavailable != nil {
+		return fmt.Errorf("spec.scheduling.disruptionBudget: minAvailable and maxUnavailable are mutually exclusive")
+	}
+	return nil
+}
+
+// validateProbes checks the probe thresholds Kubernetes constrains.
+func validateProbes(instance *klausv1alpha1.KlausInstance) error {
+	probes := instance.Spec.Probes
+	if probes == nil {
+		return nil
+	}
+	for name, probe := range map[string]*klausv1alpha1.ProbeConfig{"liveness": probes.Liveness, "startup": probes.Startup} {
+		if probe != nil && probe.SuccessThreshold != nil && *probe.SuccessThreshold != 1 {
+			return fmt.Errorf("spec.probes.%s.successThreshold must be 1", name)
+		}
+	}
+	return nil
+}
+
+// validateExtraEnv rejects extra environment variables of the instance or
+// its inline personality that use a reserved name or a name the operator
+// sets for the instance, and envFrom sources without a prefix or with one
+// that could produce a reserved name.
+func validateExtraEnv(instance *klausv1alpha1.KlausInstance) error {
+	operatorEnv := map[string]bool{}
+	for _, e := range append(buildOperatorEnvVars(instance, "", ""), buildNetworkEnvVars(instance)...) {
+		operatorEnv[e.Name] = true
+	}
+	if err := validateExtraEnvNames("spec", instance.Spec.ExtraEnv, instance.Spec.ExtraEnvFrom, operatorEnv); err != nil {
+		return err
+	}
+	if p := instance.Spec.InlinePersonality; p != nil {
+		return validateExtraEnvNames("spec.inlinePersonality", p.ExtraEnv, p.ExtraEnvFrom, operatorEnv)
+	}
+	return nil
+}
+
+func validateExtraEnvNames(field string, env []corev1.EnvVar, envFrom []corev1.EnvFromSource, operatorEnv map[string]bool) error {
+	for i, e := range env {
+		if IsReservedEnvName(e.Name) {
+			return fmt.Errorf("%s.extraEnv[%d]: %s is reserved for variables set by the operator", field, i, e.Name)
+		}
+		if operatorEnv[e.Name] {
+			return fmt.Errorf("%s.extraEnv[%d]: %s is set by the operator for this instance", field, i, e.Name)
+		}
+	}
+	for i, source := range envFrom {
+		if source.Prefix == "" {
+			// The keys of the source are only known at runtime: without a
+			// prefix they could use reserved names.
+			return fmt.Errorf("%s.extraEnvFrom[%d]: a prefix is required, e.g. APP_", field, i)
+		}
+		if IsReservedEnvPrefix(source.Prefix) {
+			return fmt.Errorf("%s.extraEnvFrom[%d]: prefix %s is reserved for variables set by the operator", field, i, source.Prefix)
+		}
+	}
+	return nil
+}
+
+// validateNetwork checks that the proxies are absolute http or https URLs
+// without credentials, which are read from proxyCredentialsSecretRef.
+func validateNetwork(instance *klausv1alpha1.KlausInstance) error {
+	network := instance.Spec.Network
+	if network == nil {
+		return nil
+	}
+	for field, proxy := range map[string]string{"httpProxy": network.HTTPProxy, "httpsProxy": network.HTTPSProxy} {
+		if proxy == "" {
+			continue
+		}
+		u, err := url.Parse(proxy)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("spec.network.%s must be an http or https URL, e.g. http://proxy.example.com:3128", field)
+		}
+		if u.User != nil {
+			return fmt.Errorf("spec.network.%s must not embed credentials, set spec.network.proxyCredentialsSecretRef instead", field)
+		}
+	}
+	if network.ProxyCredentialsSecretRef != nil && network.HTTPProxy == "" && network.HTTPSProxy == "" {
+		return fmt.Errorf("spec.network.proxyCredentialsSecretRef requires httpProxy or httpsProxy")
+	}
+	return nil
+}
+
+// validateConfigSize rejects configuration content with a key that does not
+// fit into a ConfigMap.
+func validateConfigSize(instance *klausv1alpha1.KlausInstance) error {
+	if _, err := BuildConfigMap(instance, ""); err != nil {
+		return fmt.Errorf("spec: %w", err)
+	}
+	return nil
+}
@@ -77,10 +77,11 @@ func BuildVolumes(instance *klausv1alpha1.KlausInstance, configMapName string) [
 		})
 	}
 
-	// Writable /tmp for the git-clone init container. Git needs a scratch area
-	// for index.lock, pack negotiation, and credential helpers when the init
-	// container runs with ReadOnlyRootFilesystem: true.
-	if NeedsGitClone(instance) {
+	// Writable /tmp for the git-clone and workspace-setup init containers.
+	// Git needs a scratch area for index.lock, pack negotiation, and
+	// credential helpers when the init container runs with
+	// ReadOnlyRootFilesystem: true; setup commands use it as HOME.
+	if NeedsWorkspaceClone(instance) || HasWorkspaceSetup(instance) {
 		volumes = append(volumes, corev1.Volume{
 			Name: GitTmpVolumeName,
 			VolumeSource: corev1.VolumeSource{
@@ -103,6 +104,34 @@ func BuildVolumes(instance *klausv1alpha1.KlausInstance, configMapName string) [
 		})
 	}
 
+	// Provider credentials volume (Vertex AI service account key).
+	if NeedsProviderCredentialsVolume(instance) {
+		keyMode := int32(0400)
+		volumes = append(volumes, corev1.Volume{
+			Name: ProviderCredentialsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  ProviderSecretName(instance),
+					DefaultMode: &keyMode,
+				},
+			},
+		})
+	}
+
+	// Serving certificate volume (mTLS).
+	if TLSEnabled(instance) {
+		keyMode := int32(0400)
+		volumes = append(volumes, corev1.Volume{
+			Name: TLSVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  ServingCertSecretName(instance),
+					DefaultMode: &keyMode,
+				},
+			},
+		})
+	}
+
 	return volumes
 }
 
@@ -140,8 +169,18 @@ func BuildVolumeMounts(instance *klausv1alpha1.KlausInstance) []corev1.VolumeMou
 		})
 	}
 
-	// Settings.json mount (hooks).
-	if HasHooks(instance) {
+	// Command file mounts.
+	for _, name := range slices.Sorted(maps.Keys(instance.Spec.Commands)) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      ConfigVolumeName,
+			MountPath: path.Join(ExtensionsBasePath, ".claude/commands", name+".md"),
+			SubPath:   "command-" + name,
+			ReadOnly:  true,
+		})
+	}
+
+	// Settings.json mount (hooks, output style, status line).
+	if HasSettings(instance) {
 		mounts = append(mounts, corev1.VolumeMount{
 			Name:      ConfigVolumeName,
 			MountPath: SettingsFilePath,
@@ -150,6 +189,16 @@ func BuildVolumeMounts(instance *klausv1alpha1.KlausInstance) []corev1.VolumeMou
 		})
 	}
 
+	// Inline personality SOUL.md mount.
+	if HasInlineSoul(instance) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      ConfigVolumeName,
+			MountPath: InlineSoulPath,
+			SubPath:   SoulConfigKey,
+			ReadOnly:  true,
+		})
+	}
+
 	// Hook script mounts (from executable volume).
 	if NeedsScriptsVolume(instance) {
 		for _, name := range slices.Sorted(maps.Keys(instance.Spec.HookScripts)) {
@@ -186,6 +235,34 @@ func BuildVolumeMounts(instance *klausv1alpha1.KlausInstance) []corev1.VolumeMou
 			Name:      WorkspaceVolumeName,
 			MountPath: WorkspaceMountPath,
 		})
+
+		// Memory file mounts, on top of the workspace.
+		for i, p := range memoryFilePaths(instance) {
+			mounts = append(mounts, corev1.VolumeMount{
+				Name:      ConfigVolumeName,
+				MountPath: path.Join(WorkspaceMountPath, p),
+				SubPath:   memoryFileConfigKey(i),
+				ReadOnly:  true,
+			})
+		}
+	}
+
+	// Provider credentials mount.
+	if NeedsProviderCredentialsVolume(instance) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      ProviderCredentialsVolumeName,
+			MountPath: ProviderCredentialsMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	// Serving certificate mount.
+	if TLSEnabled(instance) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      TLSVolumeName,
+			MountPath: TLSMountPath,
+			ReadOnly:  true,
+		})
 	}
 
 	return mounts
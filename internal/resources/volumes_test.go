@@ -76,3 +76,44 @@ func TestBuildVolumeMounts_NoPersonality(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildVolumeMounts_InlineSoul(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:             "test@example.com",
+			InlinePersonality: &klausv1alpha1.InlinePersonality{Soul: "You are helpful."},
+		},
+	}
+
+	mounts := BuildVolumeMounts(instance)
+
+	m := findMount(mounts, InlineSoulPath)
+	if m == nil {
+		t.Fatalf("expected soul mount at %s", InlineSoulPath)
+	}
+	if m.Name != ConfigVolumeName || m.SubPath != "soul" {
+		t.Errorf("soul mount = %+v, want the soul key of the config volume", m)
+	}
+	if pm := findMount(mounts, PersonalityMountPath); pm != nil {
+		t.Error("unexpected personality image mount for an inline personality")
+	}
+}
+
+func TestBuildVolumeMounts_Commands(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:    "test@example.com",
+			Commands: map[string]klausv1alpha1.CommandConfig{"release": {Content: "Tag a release."}},
+		},
+	}
+
+	mounts := BuildVolumeMounts(instance)
+
+	m := findMount(mounts, ExtensionsBasePath+"/.claude/commands/release.md")
+	if m == nil {
+		t.Fatalf("expected command mount, got %+v", mounts)
+	}
+	if m.Name != ConfigVolumeName || m.SubPath != "command-release" || !m.ReadOnly {
+		t.Errorf("command mount = %+v, want the command-release key of the config volume", m)
+	}
+}
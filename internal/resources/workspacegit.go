@@ -0,0 +1,282 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// WorkspaceGitOperation is a git operation run on an instance workspace.
+type WorkspaceGitOperation string
+
+const (
+	// WorkspaceGitStatus reports the branch, the last commit and local
+	// changes of the checkout.
+	WorkspaceGitStatus WorkspaceGitOperation = "status"
+
+	// WorkspaceGitPull fast-forwards the checkout to the remote ref.
+	WorkspaceGitPull WorkspaceGitOperation = "pull"
+
+	// WorkspaceGitReset discards local commits and changes to tracked files
+	// and resets the checkout to the remote ref.
+	WorkspaceGitReset WorkspaceGitOperation = "reset"
+)
+
+const (
+	// WorkspaceGitContainerName is the container running the git operation.
+	WorkspaceGitContainerName = "git"
+
+	// WorkspaceGitComponent is the component label of workspace git Jobs.
+	WorkspaceGitComponent = "workspace-git"
+
+	// workspaceGitDeadline bounds how long a workspace git Job may run.
+	workspaceGitDeadline int64 = 300
+
+	// workspaceGitTTL is how long a finished workspace git Job is kept if
+	// the MCP server did not delete it.
+	workspaceGitTTL int32 = 600
+)
+
+// WorkspaceGitLabels returns the labels of workspace git Jobs. They differ
+// from SelectorLabels so the Job pods are not selected by the instance
+// Service or mistaken for the instance pod.
+func WorkspaceGitLabels(instance *klausv1alpha1.KlausInstance) map[string]string {
+	return map[string]string{
+		LabelAppName:                  AppKlaus + "-" + WorkspaceGitComponent,
+		"app.kubernetes.io/instance":  instance.Name,
+		"app.kubernetes.io/component": WorkspaceGitComponent,
+		LabelManagedBy:                AppKlausOperator,
+		LabelOwner:                    sanitizeLabelValue(instance.Spec.Owner),
+	}
+}
+
+// BuildWorkspaceGitJob creates a Job running a git operation on the
+// instance's workspace PVC with the git clone image. nodeName pins the Job
+// to the node of the running instance pod by node affinity, so a
+// ReadWriteOnce volume can be mounted by both while the scheduler still
+// checks taints and resources. The node is left to the scheduler when
+// nodeName is empty. clean also removes untracked files on reset.
+func BuildWorkspaceGitJob(instance *klausv1alpha1.KlausInstance, namespace, gitImage string, op WorkspaceGitOperation, nodeName string, clean bool) (*batchv1.Job, error) {
+	if !NeedsGitClone(instance) {
+		return nil, fmt.Errorf("instance %q has no workspace git repository", instance.Name)
+	}
+	script, err := buildWorkspaceGitScript(instance, op, clean)
+	if err != nil {
+		return nil, err
+	}
+	job := buildWorkspaceGitJob(instance, namespace, gitImage, script, nodeName, NeedsGitSecret(instance) && op != WorkspaceGitStatus)
+	job.GenerateName = instance.Name + "-git-" + string(op) + "-"
+	return job, nil
+}
+
+// buildWorkspaceGitJob creates a Job running script in the git clone image
+// on the instance's workspace PVC, with the git credential passed to the
+// credential helper of workspaceGitEnv when withSecret is set.
+func buildWorkspaceGitJob(instance *klausv1alpha1.KlausInstance, namespace, gitImage, script, nodeName string, withSecret bool) *batchv1.Job {
+	if gitImage == "" {
+		gitImage = DefaultGitCloneImage
+	}
+
+	mounts := []corev1.VolumeMount{
+		{Name: WorkspaceVolumeName, MountPath: WorkspaceMountPath},
+		{Name: GitTmpVolumeName, MountPath: GitTmpMountPath},
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: WorkspaceVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: PVCName(instance)},
+			},
+		},
+		{Name: GitTmpVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+
+	labels := WorkspaceGitLabels(instance)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(0)),
+			ActiveDeadlineSeconds:   ptr.To(workspaceGitDeadline),
+			TTLSecondsAfterFinished: ptr.To(workspaceGitTTL),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					Affinity:                     nodeAffinity(nodeName),
+					AutomountServiceAccountToken: ptr.To(false),
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:      ptr.To(int64(1000)),
+						RunAsGroup:     ptr.To(int64(1000)),
+						FSGroup:        ptr.To(int64(1000)),
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []corev1.Container{{
+						Name:         WorkspaceGitContainerName,
+						Image:        gitImage,
+						Command:      []string{"sh", "-c"},
+						Args:         []string{script},
+						Env:          workspaceGitEnv(instance, withSecret),
+						VolumeMounts: mounts,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.To(false),
+							ReadOnlyRootFilesystem:   ptr.To(true),
+							Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+// workspaceGitCredentialHelper answers git credential requests with the
+// token of the workspaceGitTokenEnv variable.
+const workspaceGitCredentialHelper = `!f() { test "$1" = get || return 0; echo username=x-access-token; echo "password=$` + workspaceGitTokenEnv + `"; }; f`
+
+// workspaceGitTokenEnv is the variable holding the git credential of
+// workspace git Jobs.
+const workspaceGitTokenEnv = "KLAUS_GIT_TOKEN"
+
+// workspaceGitConfigKeys matches the keys of the checkout's own config that
+// workspace git Jobs keep: the repository format, the sparse checkout, the
+// fetch refspecs and branch tracking. Everything else, such as hooks,
+// filters, URL rewrites and credential helpers, is dropped.
+const workspaceGitConfigKeys = `^(core\.(repositoryformatversion|bare|logallrefupdates|sparsecheckout|sparsecheckoutcone)|extensions\..*|remote\.origin\.(fetch|promisor|partialclonefilter)|branch\..*\.(remote|merge))$`
+
+// workspaceGitEnv returns the environment of workspace git Jobs. They run
+// git in a checkout the agent could write to, so git ignores the system
+// config, hooks and the file system monitor through GIT_CONFIG_* variables,
+// which take precedence over the checkout's config. With withSecret, the
+// token is passed from the git credential Secret to a credential helper
+// instead of being written into the remote URL.
+func workspaceGitEnv(instance *klausv1alpha1.KlausInstance, withSecret bool) []corev1.EnvVar {
+	config := [][2]string{
+		{"core.hooksPath", "/dev/null"},
+		{"core.fsmonitor", "false"},
+	}
+	if withSecret {
+		// The empty helper discards the helpers configured before.
+		config = append(config, [2]string{"credential.helper", ""}, [2]string{"credential.helper", workspaceGitCredentialHelper})
+	}
+	env := []corev1.EnvVar{
+		{Name: "HOME", Value: GitTmpMountPath},
+		{Name: "GIT_CONFIG_NOSYSTEM", Value: "1"},
+		{Name: "GIT_TERMINAL_PROMPT", Value: "0"},
+		{Name: "GIT_CONFIG_COUNT", Value: fmt.Sprint(len(config))},
+	}
+	for i, kv := range config {
+		env = append(env,
+			corev1.EnvVar{Name: fmt.Sprintf("GIT_CONFIG_KEY_%d", i), Value: kv[0]},
+			corev1.EnvVar{Name: fmt.Sprintf("GIT_CONFIG_VALUE_%d", i), Value: kv[1]},
+		)
+	}
+	if withSecret {
+		env = append(env, corev1.EnvVar{
+			Name: workspaceGitTokenEnv,
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: GitSecretName(instance)},
+				Key:                  GitSecretKey(instance),
+			}},
+		})
+	}
+	return env
+}
+
+// workspaceGitConfigCommands replace the config of the checkout in the
+// working directory with the workspaceGitConfigKeys it has and point the
+// origin remote at the workspace repository, so that nothing the agent
+// configured runs or redirects the credential.
+func workspaceGitConfigCommands(instance *klausv1alpha1.KlausInstance) []string {
+	return []string{
+		"for f in .git/config .git/config.worktree; do",
+		`  [ -f "$f" ] || continue`,
+		fmt.Sprintf(`  git config --file "$f" --get-regexp %s > %s/git-config || true`, shellQuote(workspaceGitConfigKeys), GitTmpMountPath),
+		`  : > "$f"`,
+		fmt.Sprintf(`  while read -r key value; do git config --file "$f" --add "$key" "$value"; done < %s/git-config`, GitTmpMountPath),
+		"done",
+		"git config --file .git/config remote.origin.url " + shellQuote(instance.Spec.Workspace.GitRepo),
+	}
+}
+
+// buildWorkspaceGitScript generates the shell script for a workspace git
+// operation. The checkout's config is reset by workspaceGitConfigCommands
+// first, and the token is only known to the credential helper, so it is
+// never written to the workspace PVC.
+func buildWorkspaceGitScript(instance *klausv1alpha1.KlausInstance, op WorkspaceGitOperation, clean bool) (string, error) {
+	ws := instance.Spec.Workspace
+	quotedWs := shellQuote(WorkspaceMountPath)
+
+	parts := []string{
+		"set -e",
+		fmt.Sprintf("if [ ! -d %s/.git ]; then echo 'workspace has not been cloned yet' >&2; exit 1; fi", quotedWs),
+		fmt.Sprintf("cd %s", quotedWs),
+		// The workspace may contain files written by the agent under another uid.
+		fmt.Sprintf("git config --global --add safe.directory %s", quotedWs),
+	}
+	parts = append(parts, workspaceGitConfigCommands(instance)...)
+
+	if op == WorkspaceGitStatus {
+		parts = append(parts,
+			"git status --branch --short",
+			"git log -1 --format='%H %an <%ae> %cI%n%s'",
+		)
+		return strings.Join(parts, "\n"), nil
+	}
+	if op != WorkspaceGitPull && op != WorkspaceGitReset {
+		return "", fmt.Errorf("unknown workspace git operation %q", op)
+	}
+
+	parts = append(parts, "git fetch --tags origin")
+	target := "@{upstream}"
+	if ws.GitRef != "" {
+		// Branches move to their remote head; tags and commits are used as
+		// they are.
+		parts = append(parts,
+			"REF="+shellQuote(ws.GitRef),
+			`git checkout "$REF"`,
+			`TARGET=$(git rev-parse --verify -q "origin/$REF" || echo "$REF")`,
+		)
+		target = `"$TARGET"`
+	}
+
+	switch op {
+	case WorkspaceGitPull:
+		parts = append(parts, "git merge --ff-only "+target)
+	case WorkspaceGitReset:
+		parts = append(parts, "git reset --hard "+target)
+		if clean {
+			parts = append(parts, "git clean -fd")
+		}
+	}
+	parts = append(parts, "git log -1 --format='%H %s'")
+	return strings.Join(parts, "\n"), nil
+}
+
+// nodeAffinity returns the affinity scheduling a pod on the node named
+// nodeName, nil when it is empty.
+func nodeAffinity(nodeName string) *corev1.Affinity {
+	if nodeName == "" {
+		return nil
+	}
+	return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchFields: []corev1.NodeSelectorRequirement{{
+					Key:      "metadata.name",
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{nodeName},
+				}},
+			}},
+		},
+	}}
+}
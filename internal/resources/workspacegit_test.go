@@ -0,0 +1,204 @@
+package resources
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func workspaceGitTestInstance(ref, secret string) *klausv1alpha1.KlausInstance {
+	ws := &klausv1alpha1.WorkspaceConfig{GitRepo: "https://github.com/example/repo.git", GitRef: ref}
+	if secret != "" {
+		ws.GitSecretRef = &klausv1alpha1.GitSecretReference{Name: secret}
+	}
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com", Workspace: ws},
+	}
+}
+
+func TestBuildWorkspaceGitJob(t *testing.T) {
+	instance := workspaceGitTestInstance("main", "git-token")
+	job, err := BuildWorkspaceGitJob(instance, "klaus-user-user-example-com", "", WorkspaceGitPull, "node-1", false)
+	if err != nil {
+		t.Fatalf("BuildWorkspaceGitJob() error = %v", err)
+	}
+
+	if job.GenerateName != "my-agent-git-pull-" || job.Namespace != "klaus-user-user-example-com" {
+		t.Errorf("job = %s/%s*", job.Namespace, job.GenerateName)
+	}
+	pod := job.Spec.Template.Spec
+	if pod.NodeName != "" || !reflect.DeepEqual(pod.Affinity, nodeAffinity("node-1")) {
+		t.Errorf("NodeName = %q, affinity = %+v; want node-1 by node affinity", pod.NodeName, pod.Affinity)
+	}
+	if *job.Spec.BackoffLimit != 0 || job.Spec.TTLSecondsAfterFinished == nil {
+		t.Errorf("job spec = %+v, want no retries and a TTL", job.Spec)
+	}
+
+	// The Job pod must not be selected as the instance pod.
+	selector := SelectorLabels(instance)
+	if job.Spec.Template.Labels[LabelAppName] == selector[LabelAppName] {
+		t.Errorf("pod labels %v match the instance selector", job.Spec.Template.Labels)
+	}
+
+	c := pod.Containers[0]
+	if c.Image != DefaultGitCloneImage || c.Name != WorkspaceGitContainerName {
+		t.Errorf("container = %s (%s)", c.Name, c.Image)
+	}
+	var claim string
+	for _, v := range pod.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			claim = v.PersistentVolumeClaim.ClaimName
+		}
+		if v.Secret != nil {
+			t.Errorf("volume %s mounts a Secret, want the token passed to the credential helper only", v.Name)
+		}
+	}
+	if claim != PVCName(instance) {
+		t.Errorf("volumes = %+v, want the workspace PVC", pod.Volumes)
+	}
+	if ref := workspaceGitEnvValueFrom(c.Env, workspaceGitTokenEnv); ref == nil ||
+		ref.SecretKeyRef.Name != GitSecretName(instance) || ref.SecretKeyRef.Key != GitSecretKey(instance) {
+		t.Errorf("token env = %+v, want it read from the git credential Secret", ref)
+	}
+}
+
+// workspaceGitEnvValueFrom returns the source of the env variable name.
+func workspaceGitEnvValueFrom(env []corev1.EnvVar, name string) *corev1.EnvVarSource {
+	for _, e := range env {
+		if e.Name == name {
+			return e.ValueFrom
+		}
+	}
+	return nil
+}
+
+func TestWorkspaceGitEnv(t *testing.T) {
+	instance := workspaceGitTestInstance("main", "git-token")
+	config := func(env []corev1.EnvVar) map[string][]string {
+		values := map[string]string{}
+		for _, e := range env {
+			values[e.Name] = e.Value
+		}
+		got := map[string][]string{}
+		n := 0
+		for ; ; n++ {
+			key, ok := values[fmt.Sprintf("GIT_CONFIG_KEY_%d", n)]
+			if !ok {
+				break
+			}
+			got[key] = append(got[key], values[fmt.Sprintf("GIT_CONFIG_VALUE_%d", n)])
+		}
+		if values["GIT_CONFIG_COUNT"] != fmt.Sprint(n) {
+			t.Errorf("GIT_CONFIG_COUNT = %q, want %d", values["GIT_CONFIG_COUNT"], n)
+		}
+		if values["GIT_CONFIG_NOSYSTEM"] != "1" {
+			t.Error("GIT_CONFIG_NOSYSTEM is not set")
+		}
+		return got
+	}
+
+	got := config(workspaceGitEnv(instance, true))
+	if h := got["core.hooksPath"]; len(h) != 1 || h[0] != "/dev/null" {
+		t.Errorf("core.hooksPath = %v, want hooks disabled", h)
+	}
+	if f := got["core.fsmonitor"]; len(f) != 1 || f[0] != "false" {
+		t.Errorf("core.fsmonitor = %v, want it disabled", f)
+	}
+	if h := got["credential.helper"]; len(h) != 2 || h[0] != "" || !strings.Contains(h[1], "$"+workspaceGitTokenEnv) {
+		t.Errorf("credential.helper = %v, want the configured helpers reset and the token helper", h)
+	}
+
+	env := workspaceGitEnv(instance, false)
+	if h := config(env)["credential.helper"]; len(h) != 0 {
+		t.Errorf("credential.helper = %v without a secret, want none", h)
+	}
+	if workspaceGitEnvValueFrom(env, workspaceGitTokenEnv) != nil {
+		t.Error("token env is set without a secret")
+	}
+}
+
+func TestBuildWorkspaceGitJob_NoGitRepo(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{ObjectMeta: metav1.ObjectMeta{Name: "my-agent"}}
+	if _, err := BuildWorkspaceGitJob(instance, "ns", "", WorkspaceGitStatus, "", false); err == nil {
+		t.Error("expected an error for an instance without a workspace git repository")
+	}
+}
+
+func TestBuildWorkspaceGitScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *klausv1alpha1.KlausInstance
+		op       WorkspaceGitOperation
+		clean    bool
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "status",
+			instance: workspaceGitTestInstance("main", "git-token"),
+			op:       WorkspaceGitStatus,
+			want:     []string{"git status --branch --short", "git log -1", "git config --file .git/config remote.origin.url 'https://github.com/example/repo.git'"},
+			notWant:  []string{"git fetch"},
+		},
+		{
+			name:     "pull with ref and secret",
+			instance: workspaceGitTestInstance("main", "git-token"),
+			op:       WorkspaceGitPull,
+			want: []string{
+				`git config --file "$f" --get-regexp ` + shellQuote(workspaceGitConfigKeys),
+				"REF='main'",
+				`git merge --ff-only "$TARGET"`,
+			},
+			notWant: []string{"git reset", "x-access-token", "remote set-url"},
+		},
+		{
+			name:     "pull without ref",
+			instance: workspaceGitTestInstance("", ""),
+			op:       WorkspaceGitPull,
+			want:     []string{"git fetch --tags origin", "git merge --ff-only @{upstream}"},
+			notWant:  []string{"REF="},
+		},
+		{
+			name:     "reset with clean",
+			instance: workspaceGitTestInstance("v1.2.0", ""),
+			op:       WorkspaceGitReset,
+			clean:    true,
+			want:     []string{`git reset --hard "$TARGET"`, "git clean -fd"},
+		},
+		{
+			name:     "reset keeps untracked files",
+			instance: workspaceGitTestInstance("main", ""),
+			op:       WorkspaceGitReset,
+			notWant:  []string{"git clean"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := buildWorkspaceGitScript(tt.instance, tt.op, tt.clean)
+			if err != nil {
+				t.Fatalf("buildWorkspaceGitScript() error = %v", err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(script, w) {
+					t.Errorf("script missing %q:\n%s", w, script)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(script, w) {
+					t.Errorf("script unexpectedly contains %q:\n%s", w, script)
+				}
+			}
+		})
+	}
+
+	if _, err := buildWorkspaceGitScript(workspaceGitTestInstance("", ""), "rebase", false); err == nil {
+		t.Error("expected an error for an unknown operation")
+	}
+}
@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// HasWorkspaceRepos returns true if spec.workspace.repos lists repositories
+// to clone into the workspace.
+func HasWorkspaceRepos(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.Workspace != nil && len(instance.Spec.Workspace.Repos) > 0
+}
+
+// NeedsWorkspaceClone returns true if the git-clone init container is
+// needed, for gitRepo or repos.
+func NeedsWorkspaceClone(instance *klausv1alpha1.KlausInstance) bool {
+	return NeedsGitClone(instance) || HasWorkspaceRepos(instance)
+}
+
+// WorkspaceRepoPath returns the absolute checkout directory of a
+// spec.workspace.repos entry. It defaults to the repository name, e.g.
+// /workspace/repo for https://github.com/org/repo.git.
+func WorkspaceRepoPath(repo klausv1alpha1.WorkspaceRepository) string {
+	if repo.Path != "" {
+		return path.Join(WorkspaceMountPath, repo.Path)
+	}
+	name := repo.Repo[strings.LastIndexAny(repo.Repo, "/:")+1:]
+	return path.Join(WorkspaceMountPath, strings.TrimSuffix(name, ".git"))
+}
+
+// WorkspaceRepoSecretKey returns the key holding the access token of the
+// spec.workspace.repos entry at index i in the git credential Secret.
+func WorkspaceRepoSecretKey(i int) string {
+	return "repo-" + strconv.Itoa(i)
+}
+
+// workspaceRepoPaths returns the checkout directories of spec.workspace.repos.
+func workspaceRepoPaths(instance *klausv1alpha1.KlausInstance) []string {
+	if !HasWorkspaceRepos(instance) {
+		return nil
+	}
+	paths := make([]string, 0, len(instance.Spec.Workspace.Repos))
+	for _, repo := range instance.Spec.Workspace.Repos {
+		paths = append(paths, WorkspaceRepoPath(repo))
+	}
+	return paths
+}
+
+// validateWorkspaceRepos checks that spec.workspace.repos are not combined
+// with gitRepo and that every checkout has its own directory that is neither
+// the workspace root nor inside another checkout.
+func validateWorkspaceRepos(ws *klausv1alpha1.WorkspaceConfig) error {
+	if len(ws.Repos) == 0 {
+		return nil
+	}
+	if ws.GitRepo != "" {
+		return fmt.Errorf("spec.workspace.gitRepo and spec.workspace.repos are mutually exclusive")
+	}
+	seen := make([]string, 0, len(ws.Repos))
+	for i, repo := range ws.Repos {
+		dir := WorkspaceRepoPath(repo)
+		if !strings.HasPrefix(dir, WorkspaceMountPath+"/") {
+			return fmt.Errorf("spec.workspace.repos[%d]: set a path, the repository name is not a valid checkout directory", i)
+		}
+		for _, other := range seen {
+			if dir == other || strings.HasPrefix(dir, other+"/") || strings.HasPrefix(other, dir+"/") {
+				return fmt.Errorf("spec.workspace.repos[%d]: checkout path %s overlaps %s", i, dir, other)
+			}
+		}
+		seen = append(seen, dir)
+	}
+	return nil
+}
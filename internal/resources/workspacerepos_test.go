@@ -0,0 +1,110 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func reposInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-instance"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "user@example.com",
+			Workspace: &klausv1alpha1.WorkspaceConfig{
+				Repos: []klausv1alpha1.WorkspaceRepository{
+					{Repo: "https://github.com/org/api.git", Ref: "main", SecretRef: &klausv1alpha1.GitSecretReference{Name: "api-token"}},
+					{Repo: "https://github.com/org/docs", Path: "vendor/docs"},
+				},
+			},
+		},
+	}
+}
+
+func TestWorkspaceRepoPath(t *testing.T) {
+	tests := []struct {
+		repo klausv1alpha1.WorkspaceRepository
+		want string
+	}{
+		{repo: klausv1alpha1.WorkspaceRepository{Repo: "https://github.com/org/api.git"}, want: "/workspace/api"},
+		{repo: klausv1alpha1.WorkspaceRepository{Repo: "git@github.com:api.git"}, want: "/workspace/api"},
+		{repo: klausv1alpha1.WorkspaceRepository{Repo: "https://github.com/org/api.git", Path: "services/api"}, want: "/workspace/services/api"},
+	}
+	for _, tt := range tests {
+		if got := WorkspaceRepoPath(tt.repo); got != tt.want {
+			t.Errorf("WorkspaceRepoPath(%+v) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestBuildDeployment_WithWorkspaceRepos(t *testing.T) {
+	instance := reposInstance()
+	if !NeedsWorkspaceClone(instance) || NeedsGitClone(instance) || !NeedsGitSecret(instance) {
+		t.Fatal("repos need the clone init container and the git secret, but no gitRepo clone")
+	}
+
+	dep := BuildDeployment(instance, "klaus-user-test", "klaus:latest", DefaultGitCloneImage, nil)
+	initContainers := dep.Spec.Template.Spec.InitContainers
+	if len(initContainers) != 1 {
+		t.Fatalf("expected 1 init container, got %d", len(initContainers))
+	}
+	script := initContainers[0].Args[0]
+	for _, want := range []string{
+		"git clone --branch 'main' \"$AUTH_URL\" '/workspace/api'",
+		"TOKEN=$(cat '/etc/git-secret/repo-0')",
+		"git clone 'https://github.com/org/docs' '/workspace/vendor/docs'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected %q in clone script:\n%s", want, script)
+		}
+	}
+	// Each repository is handled in its own subshell.
+	if strings.Count(script, "\n(\n") != 2 {
+		t.Errorf("expected a subshell per repository:\n%s", script)
+	}
+	if strings.Contains(script, "TOKEN=$(cat '/etc/git-secret/repo-1')") {
+		t.Error("unexpected token for the repository without a secretRef")
+	}
+
+	envs := BuildEnvVars(instance, "test-config", "test-secret")
+	assertEnvValue(t, envs, "CLAUDE_ADD_DIRS", "/workspace/api,/workspace/vendor/docs")
+	assertEnvValue(t, envs, "CLAUDE_CODE_ADDITIONAL_DIRECTORIES_CLAUDE_MD", envValueTrue)
+}
+
+func TestValidateWorkspaceRepos(t *testing.T) {
+	if err := ValidateSpec(reposInstance()); err != nil {
+		t.Fatalf("valid repos: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*klausv1alpha1.WorkspaceConfig)
+		want   string
+	}{
+		{name: "with gitRepo", mutate: func(ws *klausv1alpha1.WorkspaceConfig) {
+			ws.GitRepo = "https://github.com/org/main.git"
+		}, want: "mutually exclusive"},
+		{name: "duplicate path", mutate: func(ws *klausv1alpha1.WorkspaceConfig) {
+			ws.Repos[1].Path = "api"
+		}, want: "overlaps"},
+		{name: "nested path", mutate: func(ws *klausv1alpha1.WorkspaceConfig) {
+			ws.Repos[1].Path = "api/docs"
+		}, want: "overlaps"},
+		{name: "unusable repository name", mutate: func(ws *klausv1alpha1.WorkspaceConfig) {
+			ws.Repos[1] = klausv1alpha1.WorkspaceRepository{Repo: "https://github.com/org/.."}
+		}, want: "set a path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := reposInstance()
+			tt.mutate(instance.Spec.Workspace)
+			err := ValidateSpec(instance)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
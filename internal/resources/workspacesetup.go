@@ -0,0 +1,119 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const (
+	// WorkspaceSetupContainerName is the name of the init container running
+	// spec.workspace.setupCommands.
+	WorkspaceSetupContainerName = "workspace-setup"
+
+	// DefaultWorkspaceSetupTimeout bounds the setup commands when
+	// spec.workspace.setupTimeout is unset.
+	DefaultWorkspaceSetupTimeout = 10 * time.Minute
+
+	// MaxWorkspaceSetupTimeout is the longest spec.workspace.setupTimeout
+	// the operator accepts.
+	MaxWorkspaceSetupTimeout = time.Hour
+
+	// workspaceSetupTimeoutMarker is created by the watchdog of the setup
+	// script when it stops the commands, so the exit trap can tell a timeout
+	// from a failing command.
+	workspaceSetupTimeoutMarker = GitTmpMountPath + "/.workspace-setup-timeout"
+)
+
+// HasWorkspaceSetup returns true if spec.workspace.setupCommands are set.
+func HasWorkspaceSetup(instance *klausv1alpha1.KlausInstance) bool {
+	return instance.Spec.Workspace != nil && len(instance.Spec.Workspace.SetupCommands) > 0
+}
+
+// WorkspaceSetupTimeout returns the time the setup commands of instance may
+// take.
+func WorkspaceSetupTimeout(instance *klausv1alpha1.KlausInstance) time.Duration {
+	if ws := instance.Spec.Workspace; ws != nil && ws.SetupTimeout != nil {
+		return ws.SetupTimeout.Duration
+	}
+	return DefaultWorkspaceSetupTimeout
+}
+
+// buildWorkspaceSetupInitContainers returns the init container running
+// spec.workspace.setupCommands in the instance image, or nil without
+// setup commands. It runs after the git clone with the same security
+// context: non-root, without capabilities and with a read-only root
+// filesystem. /tmp is writable and HOME, so tool caches outside the
+// workspace are discarded with the container.
+func buildWorkspaceSetupInitContainers(instance *klausv1alpha1.KlausInstance, image string) []corev1.Container {
+	if !HasWorkspaceSetup(instance) {
+		return nil
+	}
+
+	resources := corev1.ResourceRequirements{}
+	if instance.Spec.Resources != nil {
+		resources = *instance.Spec.Resources
+	}
+
+	return []corev1.Container{
+		{
+			Name:       WorkspaceSetupContainerName,
+			Image:      image,
+			Command:    []string{"sh", "-c"},
+			Args:       []string{buildWorkspaceSetupScript(instance.Spec.Workspace.SetupCommands, WorkspaceSetupTimeout(instance))},
+			WorkingDir: WorkspaceMountPath,
+			Env: []corev1.EnvVar{
+				{Name: "HOME", Value: GitTmpMountPath},
+			},
+			Resources: resources,
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: WorkspaceVolumeName, MountPath: WorkspaceMountPath},
+				{Name: GitTmpVolumeName, MountPath: GitTmpMountPath},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser:                ptr.To(int64(1000)),
+				RunAsGroup:               ptr.To(int64(1000)),
+				AllowPrivilegeEscalation: ptr.To(false),
+				ReadOnlyRootFilesystem:   ptr.To(true),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			},
+		},
+	}
+}
+
+// buildWorkspaceSetupScript generates the script of the workspace-setup
+// init container. Every command runs in its own shell, in order, and the
+// first failure fails the container. A background watchdog enforces the
+// timeout: the script is the container's PID 1, so kill -1 stops every
+// other process in the container, including commands that spawned
+// children. The reason of a failure is written as the termination message.
+// The timeout marker is removed first: the tmp volume outlives restarts of
+// the container, so a marker left by a timed out run would otherwise report
+// the next failing command as a timeout.
+func buildWorkspaceSetupScript(commands []string, timeout time.Duration) string {
+	seconds := int64(timeout / time.Second)
+	parts := []string{
+		"set -e",
+		"rm -f " + workspaceSetupTimeoutMarker,
+		"STEP=0",
+		fmt.Sprintf(`trap 'rc=$?; set +e; if [ -e %[1]s ]; then echo "workspace setup timed out after %[2]s" > %[3]s; elif [ "$rc" -ne 0 ]; then echo "workspace setup command $STEP failed with exit code $rc" > %[3]s; fi; exit $rc' EXIT`,
+			workspaceSetupTimeoutMarker, timeout, GitCloneTerminationMessagePath),
+		fmt.Sprintf("(sleep %d; touch %s; kill -TERM -1) &", seconds, workspaceSetupTimeoutMarker),
+	}
+	for i, command := range commands {
+		parts = append(parts,
+			"STEP="+strconv.Itoa(i+1),
+			"echo "+shellQuote("+ "+command),
+			"sh -c "+shellQuote(command),
+		)
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,109 @@
+package resources
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func setupInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-instance"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: "user@example.com",
+			Workspace: &klausv1alpha1.WorkspaceConfig{
+				GitRepo:       "https://github.com/org/api.git",
+				SetupCommands: []string{"go mod download", "npm ci --prefix 'web'"},
+			},
+		},
+	}
+}
+
+func TestBuildDeployment_WithWorkspaceSetup(t *testing.T) {
+	dep := BuildDeployment(setupInstance(), "klaus-user-test", "gsoci.azurecr.io/giantswarm/klaus-go:1.26", DefaultGitCloneImage, nil)
+	spec := dep.Spec.Template.Spec
+	if len(spec.InitContainers) != 2 || spec.InitContainers[0].Name != GitCloneContainerName {
+		t.Fatalf("init containers = %+v, want the git clone followed by the setup", spec.InitContainers)
+	}
+	setup := spec.InitContainers[1]
+	if setup.Name != WorkspaceSetupContainerName || setup.Image != "gsoci.azurecr.io/giantswarm/klaus-go:1.26" {
+		t.Errorf("setup container = %s (%s), want the instance image", setup.Name, setup.Image)
+	}
+	if setup.WorkingDir != WorkspaceMountPath {
+		t.Errorf("working dir = %q, want the workspace", setup.WorkingDir)
+	}
+	sc := setup.SecurityContext
+	if sc == nil || !*sc.ReadOnlyRootFilesystem || *sc.AllowPrivilegeEscalation || *sc.RunAsUser != 1000 ||
+		len(sc.Capabilities.Drop) != 1 {
+		t.Errorf("security context = %+v, want the git-clone constraints", sc)
+	}
+	if got := setup.Env; len(got) != 1 || got[0].Name != "HOME" || got[0].Value != GitTmpMountPath {
+		t.Errorf("env = %+v, want HOME on the writable tmp volume", got)
+	}
+
+	script := setup.Args[0]
+	if !strings.HasPrefix(script, "set -e\nrm -f /tmp/.workspace-setup-timeout\nSTEP=0\n") {
+		t.Errorf("script does not remove a stale timeout marker first:\n%s", script)
+	}
+	for _, want := range []string{
+		"(sleep 600; touch /tmp/.workspace-setup-timeout; kill -TERM -1) &",
+		"STEP=1\necho '+ go mod download'\nsh -c 'go mod download'",
+		`sh -c 'npm ci --prefix '\''web'\'''`,
+		"workspace setup timed out after 10m0s",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestBuildDeployment_WorkspaceSetupWithoutClone(t *testing.T) {
+	instance := setupInstance()
+	instance.Spec.Workspace.GitRepo = ""
+	instance.Spec.Workspace.SetupTimeout = &metav1.Duration{Duration: 30 * time.Minute}
+
+	spec := BuildDeployment(instance, "klaus-user-test", "klaus:latest", DefaultGitCloneImage, nil).Spec.Template.Spec
+	if len(spec.InitContainers) != 1 || spec.InitContainers[0].Name != WorkspaceSetupContainerName {
+		t.Fatalf("init containers = %+v, want only the setup", spec.InitContainers)
+	}
+	if !strings.Contains(spec.InitContainers[0].Args[0], "sleep 1800;") {
+		t.Errorf("script does not enforce the 30m timeout:\n%s", spec.InitContainers[0].Args[0])
+	}
+	found := false
+	for _, v := range spec.Volumes {
+		found = found || v.Name == GitTmpVolumeName
+	}
+	if !found {
+		t.Error("the writable tmp volume is missing")
+	}
+}
+
+func TestBuildDeployment_NoWorkspaceSetup(t *testing.T) {
+	instance := setupInstance()
+	instance.Spec.Workspace.SetupCommands = nil
+	for _, c := range BuildDeployment(instance, "klaus-user-test", "klaus:latest", DefaultGitCloneImage, nil).Spec.Template.Spec.InitContainers {
+		if c.Name == WorkspaceSetupContainerName {
+			t.Error("setup container added without setup commands")
+		}
+	}
+}
+
+func TestValidateSpec_WorkspaceSetupTimeout(t *testing.T) {
+	for timeout, wantErr := range map[time.Duration]bool{
+		time.Minute:   false,
+		time.Hour:     false,
+		0:             true,
+		2 * time.Hour: true,
+		-time.Minute:  true,
+	} {
+		instance := setupInstance()
+		instance.Spec.Workspace.SetupTimeout = &metav1.Duration{Duration: timeout}
+		if err := ValidateInstanceSpec(instance); (err != nil) != wantErr {
+			t.Errorf("setupTimeout %s: error = %v, want error %t", timeout, err, wantErr)
+		}
+	}
+}
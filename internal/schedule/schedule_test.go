@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC) // Saturday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 3, 15, 2, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 10 14 3 *", time.Date(2027, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)}, // Friday or the 13th
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * 1", time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)},  // odd-day Monday
+		{"0 0 13 * */2", time.Date(2026, 6, 13, 0, 0, 0, 0, time.UTC)}, // the 13th on Sun, Tue, Thu or Sat
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.expr, err)
+			}
+			if got := s.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Sunday7(t *testing.T) {
+	const (
+		sun = 1 << 0
+		mon = 1 << 1
+		fri = 1 << 5
+		sat = 1 << 6
+	)
+	tests := []struct {
+		dow  string
+		want uint64
+	}{
+		{"7", sun},
+		{"0,7", sun},
+		{"1-7", 0x7f},
+		{"5-7", fri | sat | sun},
+		{"*/7", sun},
+		{"0-7/7", sun},
+		{"mon,7", mon | sun},
+		{"sat-7", sat | sun},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dow, func(t *testing.T) {
+			s, err := Parse("0 0 * * " + tt.dow)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if s.dow != tt.want {
+				t.Errorf("days of week = %07b, want %07b", s.dow, tt.want)
+			}
+		})
+	}
+
+	if _, err := Parse("0 0 * * 8"); err == nil {
+		t.Error("Parse of day of week 8 succeeded, want an error")
+	}
+}
+
+func TestNext_NeverMatches(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero time", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * mon-", "*/0 * * * *", "5-1 * * * *"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package testutil holds test doubles shared by the operator tests.
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	godigest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/giantswarm/klaus-operator/pkg/ocibuild"
+)
+
+// Registry is an in-process OCI distribution registry holding its
+// repositories in memory, a test double for the registries the operator
+// resolves, pulls and lists artifacts from. It serves plain HTTP on a
+// loopback port: clients reach it with klaus-oci's WithPlainHTTP option or
+// oras' PlainHTTP. It implements what those clients use: tag listing,
+// manifest and blob reads and monolithic pushes.
+type Registry struct {
+	server   *httptest.Server
+	requests atomic.Int64
+
+	mu      sync.Mutex
+	blobs   map[godigest.Digest][]byte
+	repos   map[string]*registryRepo
+	uploads int
+}
+
+type registryRepo struct {
+	manifests map[godigest.Digest]registryManifest
+	tags      map[string]godigest.Digest
+}
+
+type registryManifest struct {
+	mediaType string
+	data      []byte
+}
+
+// NewRegistry starts an empty Registry. Close stops it.
+func NewRegistry() *Registry {
+	r := &Registry{
+		blobs: map[godigest.Digest][]byte{},
+		repos: map[string]*registryRepo{},
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	return r
+}
+
+// Close stops the registry.
+func (r *Registry) Close() {
+	r.server.Close()
+}
+
+// Host returns the host:port of the registry, the registry part of the
+// references it serves.
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// Ref returns the reference of repository at tag in the registry, or of the
+// repository itself when tag is empty.
+func (r *Registry) Ref(repository, tag string) string {
+	ref := r.Host() + "/" + repository
+	if tag != "" {
+		ref += ":" + tag
+	}
+	return ref
+}
+
+// Requests returns the number of requests the registry has served, e.g. to
+// assert that an offline operator sent none.
+func (r *Registry) Requests() int {
+	return int(r.requests.Load())
+}
+
+// Push pushes the artifact to repository through the registry API and tags
+// it unless tag is empty.
+func (r *Registry) Push(ctx context.Context, repository, tag string, a *ocibuild.Artifact) error {
+	repo, err := remote.NewRepository(r.Ref(repository, ""))
+	if err != nil {
+		return fmt.Errorf("opening repository %s: %w", repository, err)
+	}
+	repo.PlainHTTP = true
+	return a.Push(ctx, repo, tag)
+}
+
+func (r *Registry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests.Add(1)
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	if path == req.URL.Path {
+		registryError(w, http.StatusNotFound, "NAME_UNKNOWN", "not a registry path")
+		return
+	}
+	if path == "" {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("{}"))
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(path, "/tags/list"):
+		r.serveTags(w, strings.TrimSuffix(path, "/tags/list"))
+	case strings.Contains(path, "/manifests/"):
+		name, ref, _ := strings.Cut(path, "/manifests/")
+		r.serveManifest(w, req, name, ref)
+	case strings.Contains(path, "/blobs/uploads/"):
+		name, _, _ := strings.Cut(path, "/blobs/uploads/")
+		r.serveUpload(w, req, name)
+	case strings.Contains(path, "/blobs/"):
+		_, digest, _ := strings.Cut(path, "/blobs/")
+		r.serveBlob(w, req, godigest.Digest(digest))
+	default:
+		registryError(w, http.StatusNotFound, "NAME_UNKNOWN", "unknown registry path")
+	}
+}
+
+func (r *Registry) serveTags(w http.ResponseWriter, name string) {
+	r.mu.Lock()
+	repo := r.repos[name]
+	var tags []string
+	if repo != nil {
+		for tag := range repo.tags {
+			tags = append(tags, tag)
+		}
+	}
+	r.mu.Unlock()
+	if repo == nil {
+		registryError(w, http.StatusNotFound, "NAME_UNKNOWN", "repository "+name+" not found")
+		return
+	}
+	slices.Sort(tags)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"name": name, "tags": tags})
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, name, ref string) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		r.mu.Lock()
+		manifest, digest, ok := r.manifest(name, ref)
+		r.mu.Unlock()
+		if !ok {
+			registryError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest "+ref+" not found")
+			return
+		}
+		writeContent(w, req, manifest.mediaType, digest, manifest.data)
+	case http.MethodPut:
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			registryError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+		digest := godigest.FromBytes(data)
+		if d, err := godigest.Parse(ref); err == nil && d != digest {
+			registryError(w, http.StatusBadRequest, "DIGEST_INVALID", "manifest digest mismatch")
+			return
+		}
+		r.mu.Lock()
+		repo := r.repos[name]
+		if repo == nil {
+			repo = &registryRepo{manifests: map[godigest.Digest]registryManifest{}, tags: map[string]godigest.Digest{}}
+			r.repos[name] = repo
+		}
+		repo.manifests[digest] = registryManifest{mediaType: req.Header.Get("Content-Type"), data: data}
+		if _, err := godigest.Parse(ref); err != nil {
+			repo.tags[ref] = digest
+		}
+		r.mu.Unlock()
+		w.Header().Set("Location", "/v2/"+name+"/manifests/"+digest.String())
+		w.Header().Set("Docker-Content-Digest", digest.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		registryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", req.Method+" is not supported on manifests")
+	}
+}
+
+// manifest returns the manifest of repository name at the tag or digest
+// ref. r.mu must be held.
+func (r *Registry) manifest(name, ref string) (registryManifest, godigest.Digest, bool) {
+	repo := r.repos[name]
+	if repo == nil {
+		return registryManifest{}, "", false
+	}
+	digest, err := godigest.Parse(ref)
+	if err != nil {
+		var ok bool
+		if digest, ok = repo.tags[ref]; !ok {
+			return registryManifest{}, "", false
+		}
+	}
+	manifest, ok := repo.manifests[digest]
+	return manifest, digest, ok
+}
+
+func (r *Registry) serveBlob(w http.ResponseWriter, req *http.Request, digest godigest.Digest) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		registryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", req.Method+" is not supported on blobs")
+		return
+	}
+	r.mu.Lock()
+	data, ok := r.blobs[digest]
+	r.mu.Unlock()
+	if !ok {
+		registryError(w, http.StatusNotFound, "BLOB_UNKNOWN", "blob "+digest.String()+" not found")
+		return
+	}
+	writeContent(w, req, "application/octet-stream", digest, data)
+}
+
+// serveUpload implements monolithic blob uploads, in a single POST with
+// the digest or a POST opening the upload followed by a PUT of the blob.
+func (r *Registry) serveUpload(w http.ResponseWriter, req *http.Request, name string) {
+	digest := req.URL.Query().Get("digest")
+	switch {
+	case req.Method == http.MethodPost && digest == "":
+		r.mu.Lock()
+		r.uploads++
+		id := r.uploads
+		r.mu.Unlock()
+		w.Header().Set("Location", "/v2/"+name+"/blobs/uploads/"+strconv.Itoa(id))
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == http.MethodPost || req.Method == http.MethodPut:
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			registryError(w, http.StatusBadRequest, "BLOB_UPLOAD_INVALID", err.Error())
+			return
+		}
+		if godigest.FromBytes(data).String() != digest {
+			registryError(w, http.StatusBadRequest, "DIGEST_INVALID", "blob digest mismatch")
+			return
+		}
+		r.mu.Lock()
+		r.blobs[godigest.Digest(digest)] = data
+		r.mu.Unlock()
+		w.Header().Set("Location", "/v2/"+name+"/blobs/"+digest)
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		registryError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", req.Method+" is not supported on uploads")
+	}
+}
+
+func writeContent(w http.ResponseWriter, req *http.Request, mediaType string, digest godigest.Digest, data []byte) {
+	if mediaType == "" {
+		mediaType = ocispec.MediaTypeImageManifest
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Docker-Content-Digest", digest.String())
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if req.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+// registryError writes an error in the format of the distribution spec.
+func registryError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"code": code, "message": message}},
+	})
+}
@@ -0,0 +1,79 @@
+package testutil
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	"github.com/giantswarm/klaus-operator/pkg/ocibuild"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	defer registry.Close()
+
+	v1, err := ocibuild.BuildPersonality(klausoci.Personality{Name: "sre"}, fstest.MapFS{
+		"SOUL.md": {Data: []byte("You are an SRE.\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := ocibuild.BuildPersonality(klausoci.Personality{Name: "sre"}, fstest.MapFS{
+		"SOUL.md": {Data: []byte("You are an SRE on call.\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Push(ctx, "personalities/sre", "v1.0.0", v1); err != nil {
+		t.Fatalf("pushing v1.0.0: %v", err)
+	}
+	if err := registry.Push(ctx, "personalities/sre", "v1.1.0", v2); err != nil {
+		t.Fatalf("pushing v1.1.0: %v", err)
+	}
+
+	client := klausoci.NewClient(klausoci.WithPlainHTTP(true))
+	resolved, err := client.ResolvePersonalityRef(ctx, registry.Ref("personalities/sre", ""))
+	if err != nil {
+		t.Fatalf("ResolvePersonalityRef: %v", err)
+	}
+	if want := registry.Ref("personalities/sre", "v1.1.0"); resolved != want {
+		t.Errorf("resolved %s, want the latest version %s", resolved, want)
+	}
+	digest, err := client.Resolve(ctx, registry.Ref("personalities/sre", "v1.0.0"))
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if digest != v1.Digest() {
+		t.Errorf("v1.0.0 resolved to %s, want %s", digest, v1.Digest())
+	}
+
+	pulled, err := client.PullPersonality(ctx, registry.Ref("personalities/sre", "")+"@"+v1.Digest(), t.TempDir())
+	if err != nil {
+		t.Fatalf("PullPersonality: %v", err)
+	}
+	if pulled.Soul != "You are an SRE.\n" {
+		t.Errorf("soul = %q, want the soul of v1.0.0", pulled.Soul)
+	}
+	if registry.Requests() == 0 {
+		t.Error("Requests() = 0, want the requests served counted")
+	}
+}
+
+func TestRegistry_NotFound(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRegistry()
+	defer registry.Close()
+
+	client := klausoci.NewClient(klausoci.WithPlainHTTP(true))
+	if _, err := client.Resolve(ctx, registry.Ref("personalities/missing", "v1.0.0")); err == nil {
+		t.Error("Resolve of a missing repository succeeded, want an error")
+	}
+	_, err := client.ResolvePersonalityRef(ctx, registry.Ref("personalities/missing", ""))
+	if err == nil || !strings.Contains(err.Error(), "personalities/missing") {
+		t.Errorf("ResolvePersonalityRef = %v, want the missing repository reported", err)
+	}
+}
@@ -0,0 +1,365 @@
+// Package trigger receives the external events of KlausTriggers over HTTP
+// and acts on them: it creates a KlausJob or prompts a persistent instance.
+package trigger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// DefaultMaxBodyBytes caps event payloads unless configured otherwise.
+const DefaultMaxBodyBytes = 1 << 20
+
+// readHeaderTimeout bounds reading the request headers of an event.
+const readHeaderTimeout = 10 * time.Second
+
+// Headers of the event sources.
+const (
+	headerEvent          = "X-Klaus-Event"
+	headerGitHubEvent    = "X-GitHub-Event"
+	headerGitHubDelivery = "X-GitHub-Delivery"
+	headerGitHubSig      = "X-Hub-Signature-256"
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustriggers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustriggers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klausjobs,verbs=create
+
+// Prompter sends a prompt to a running instance without waiting for the
+// result.
+type Prompter interface {
+	SendPrompt(ctx context.Context, instance *klausv1alpha1.KlausInstance, message string) error
+}
+
+// Listener is a manager runnable serving the KlausTrigger endpoints at
+// /triggers/<namespace>/<name>. It runs on every replica, so events are
+// accepted while the leader changes.
+type Listener struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+
+	// Addr is the address the listener binds to.
+	Addr string
+
+	// OperatorNamespace is the namespace of the instances triggers prompt.
+	OperatorNamespace string
+
+	// Prompter prompts instances; nil rejects events of instance actions.
+	Prompter Prompter
+
+	// Recorder records a Triggered event on the trigger for every event
+	// acted on.
+	Recorder record.EventRecorder
+
+	// MaxBodyBytes caps event payloads. Defaults to DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+}
+
+// NeedLeaderElection lets every replica receive events.
+func (l *Listener) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the trigger endpoints until the context is cancelled.
+func (l *Listener) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              l.Addr,
+		Handler:           l.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		log.FromContext(ctx).WithName("trigger-listener").Info("starting trigger listener", "addr", l.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return srv.Shutdown(context.Background())
+	}
+}
+
+// Handler returns the HTTP handler of the trigger endpoints.
+func (l *Listener) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+resources.TriggerPathPrefix+"{namespace}/{name}", l.handleEvent)
+	return mux
+}
+
+// response is the JSON body of every listener response.
+type response struct {
+	Status   string `json:"status"`
+	Job      string `json:"job,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// errUnauthorized is the message of every rejected event, whether the
+// trigger does not exist or the event failed authentication.
+const errUnauthorized = "unauthorized"
+
+// Statuses of the listener responses.
+const (
+	statusCreated   = "created"
+	statusDuplicate = "duplicate"
+	statusPrompted  = "prompted"
+	statusIgnored   = "ignored"
+	statusError     = "error"
+)
+
+// handleEvent authenticates an event of a trigger and runs its action.
+func (l *Listener) handleEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+
+	// Unknown triggers are rejected like failed authentication, so that
+	// unauthenticated callers cannot probe which triggers exist.
+	var trigger klausv1alpha1.KlausTrigger
+	if err := l.Client.Get(ctx, key, &trigger); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "reading trigger")
+		return
+	}
+
+	maxBytes := l.MaxBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("payload exceeds %d bytes", maxBytes))
+		return
+	}
+
+	// Authenticate before revealing anything else about the trigger. The
+	// reason is logged only: it would tell callers that the trigger exists.
+	if err := l.authenticate(ctx, &trigger, r, body); err != nil {
+		log.FromContext(ctx).Info("rejected trigger event", "trigger", key, "reason", err.Error())
+		writeError(w, http.StatusUnauthorized, errUnauthorized)
+		return
+	}
+	if trigger.Spec.Suspend {
+		writeError(w, http.StatusConflict, "trigger is suspended")
+		return
+	}
+
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, "payload is not JSON: "+err.Error())
+		return
+	}
+	event := eventName(&trigger, r, payload)
+	if trigger.Spec.Source.Type == klausv1alpha1.TriggerSourceGitHub && event == "ping" {
+		writeJSON(w, http.StatusOK, response{Status: statusIgnored, Message: "pong"})
+		return
+	}
+	if !matchesEvents(&trigger, event, payload) {
+		writeJSON(w, http.StatusOK, response{Status: statusIgnored, Message: fmt.Sprintf("event %q is not in spec.source.events", event)})
+		return
+	}
+	if err := resources.ValidateTriggerSpec(&trigger); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	var prompt string
+	if trigger.Spec.PromptTemplate != "" {
+		if prompt, err = resources.RenderTriggerPrompt(&trigger, event, payload); err != nil {
+			l.Recorder.Event(&trigger, corev1.EventTypeWarning, "TemplateError", err.Error())
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+	}
+
+	if trigger.Spec.Job != nil {
+		l.createJob(ctx, w, r, &trigger, event, prompt)
+		return
+	}
+	l.promptInstance(ctx, w, &trigger, event, prompt)
+}
+
+// createJob creates the KlausJob of an event. A redelivered event whose job
+// already exists is acknowledged without creating another.
+func (l *Listener) createJob(ctx context.Context, w http.ResponseWriter, r *http.Request, trigger *klausv1alpha1.KlausTrigger, event, prompt string) {
+	job := resources.BuildTriggerJob(trigger, event, prompt, deliveryID(trigger, r))
+	if err := controllerutil.SetControllerReference(trigger, job, l.Scheme); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := l.Client.Create(ctx, job); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			writeJSON(w, http.StatusOK, response{Status: statusDuplicate, Job: job.Name})
+			return
+		}
+		l.Recorder.Event(trigger, corev1.EventTypeWarning, "FailedCreate", err.Error())
+		writeError(w, http.StatusInternalServerError, "creating KlausJob: "+err.Error())
+		return
+	}
+	l.Recorder.Eventf(trigger, corev1.EventTypeNormal, "Triggered", "Event %q created KlausJob %s", event, job.Name)
+	l.recordTrigger(ctx, trigger, event, job.Name)
+	writeJSON(w, http.StatusAccepted, response{Status: statusCreated, Job: job.Name})
+}
+
+// promptInstance sends the prompt of an event to the trigger's instance.
+func (l *Listener) promptInstance(ctx context.Context, w http.ResponseWriter, trigger *klausv1alpha1.KlausTrigger, event, prompt string) {
+	name := trigger.Spec.Instance.Name
+	var instance klausv1alpha1.KlausInstance
+	if err := l.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: l.OperatorNamespace}, &instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeError(w, http.StatusConflict, fmt.Sprintf("instance %q not found", name))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "reading instance: "+err.Error())
+		return
+	}
+	// Checked again here: the instance owner may have changed since the
+	// trigger was reconciled.
+	if !resources.IsOwnedBy(&instance, trigger.Spec.Owner, nil) {
+		writeError(w, http.StatusForbidden, fmt.Sprintf("instance %q is not owned by %s", name, trigger.Spec.Owner))
+		return
+	}
+	if l.Prompter == nil {
+		writeError(w, http.StatusServiceUnavailable, "prompting instances is not configured")
+		return
+	}
+	if err := l.Prompter.SendPrompt(ctx, &instance, prompt); err != nil {
+		l.Recorder.Eventf(trigger, corev1.EventTypeWarning, "PromptFailed", "Prompting instance %s failed: %v", name, err)
+		writeError(w, http.StatusBadGateway, "prompting instance: "+err.Error())
+		return
+	}
+	l.Recorder.Eventf(trigger, corev1.EventTypeNormal, "Triggered", "Event %q prompted instance %s", event, name)
+	l.recordTrigger(ctx, trigger, event, "")
+	writeJSON(w, http.StatusAccepted, response{Status: statusPrompted, Instance: name})
+}
+
+// recordTrigger records the last event in the trigger status. A failed
+// update does not fail the event, which was acted on already.
+func (l *Listener) recordTrigger(ctx context.Context, trigger *klausv1alpha1.KlausTrigger, event, jobName string) {
+	base := trigger.DeepCopy()
+	trigger.Status.LastTriggerTime = &metav1.Time{Time: time.Now()}
+	trigger.Status.LastEvent = event
+	trigger.Status.LastJobName = jobName
+	if err := l.Client.Status().Patch(ctx, trigger, client.MergeFrom(base)); client.IgnoreNotFound(err) != nil {
+		log.FromContext(ctx).Error(err, "recording trigger status failed", "trigger", trigger.Name)
+	}
+}
+
+// authenticate verifies the bearer token or, for GitHub, the payload
+// signature of an event against the trigger's Secret.
+func (l *Listener) authenticate(ctx context.Context, trigger *klausv1alpha1.KlausTrigger, r *http.Request, body []byte) error {
+	var secret corev1.Secret
+	ref := trigger.Spec.Source.SecretRef
+	if err := l.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: trigger.Namespace}, &secret); err != nil {
+		return errors.New("trigger credentials unavailable")
+	}
+	want := secret.Data[resources.TriggerSecretKey(trigger)]
+	if len(want) == 0 {
+		return errors.New("trigger credentials unavailable")
+	}
+
+	if trigger.Spec.Source.Type == klausv1alpha1.TriggerSourceGitHub {
+		signature, ok := strings.CutPrefix(r.Header.Get(headerGitHubSig), "sha256=")
+		got, err := hex.DecodeString(signature)
+		if !ok || err != nil {
+			return errors.New("missing or malformed " + headerGitHubSig)
+		}
+		mac := hmac.New(sha256.New, want)
+		mac.Write(body)
+		if !hmac.Equal(got, mac.Sum(nil)) {
+			return errors.New("invalid payload signature")
+		}
+		return nil
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), want) != 1 {
+		return errors.New("invalid or missing bearer token")
+	}
+	return nil
+}
+
+// eventName returns the name of an event: the GitHub event header, the
+// Alertmanager notification status or the X-Klaus-Event header.
+func eventName(trigger *klausv1alpha1.KlausTrigger, r *http.Request, payload any) string {
+	switch trigger.Spec.Source.Type {
+	case klausv1alpha1.TriggerSourceGitHub:
+		return r.Header.Get(headerGitHubEvent)
+	case klausv1alpha1.TriggerSourceAlertmanager:
+		return payloadString(payload, "status")
+	default:
+		return r.Header.Get(headerEvent)
+	}
+}
+
+// matchesEvents reports whether an event fires the trigger. GitHub events
+// with an action also match as <event>.<action>.
+func matchesEvents(trigger *klausv1alpha1.KlausTrigger, event string, payload any) bool {
+	events := trigger.Spec.Source.Events
+	if len(events) == 0 || slices.Contains(events, event) {
+		return true
+	}
+	if trigger.Spec.Source.Type == klausv1alpha1.TriggerSourceGitHub {
+		if action := payloadString(payload, "action"); action != "" {
+			return slices.Contains(events, event+"."+action)
+		}
+	}
+	return false
+}
+
+// deliveryID returns the ID identifying redeliveries of an event, or an
+// empty string when the source sends none.
+func deliveryID(trigger *klausv1alpha1.KlausTrigger, r *http.Request) string {
+	if trigger.Spec.Source.Type == klausv1alpha1.TriggerSourceGitHub {
+		if id := r.Header.Get(headerGitHubDelivery); id != "" {
+			return id
+		}
+	}
+	return r.Header.Get(headerIdempotencyKey)
+}
+
+// payloadString returns a top-level string field of a JSON object payload.
+func payloadString(payload any, field string) string {
+	object, _ := payload.(map[string]any)
+	value, _ := object[field].(string)
+	return value
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, response{Status: statusError, Message: message})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,284 @@
+package trigger
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+const testToken = "s3cret"
+
+// fakePrompter records the prompts sent to instances.
+type fakePrompter struct {
+	instance string
+	message  string
+	err      error
+}
+
+func (f *fakePrompter) SendPrompt(_ context.Context, instance *klausv1alpha1.KlausInstance, message string) error {
+	f.instance, f.message = instance.Name, message
+	return f.err
+}
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := klausv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 scheme: %v", err)
+	}
+	return scheme
+}
+
+func jobTrigger(source klausv1alpha1.TriggerSourceType) *klausv1alpha1.KlausTrigger {
+	return &klausv1alpha1.KlausTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook", Namespace: "team-a", UID: "trigger-uid"},
+		Spec: klausv1alpha1.KlausTriggerSpec{
+			Owner: "user@example.com",
+			Source: klausv1alpha1.TriggerSource{
+				Type:      source,
+				SecretRef: klausv1alpha1.TriggerSecretReference{Name: "hook-secret"},
+			},
+			PromptTemplate: "Handle {{ .event }}: {{ .payload.summary }}",
+			Job: &klausv1alpha1.TriggerJobAction{JobTemplate: klausv1alpha1.KlausJobSpec{
+				Owner:  "user@example.com",
+				Prompt: "template prompt",
+			}},
+		},
+	}
+}
+
+func instanceTrigger() *klausv1alpha1.KlausTrigger {
+	trigger := jobTrigger(klausv1alpha1.TriggerSourceAlertmanager)
+	trigger.Spec.Job = nil
+	trigger.Spec.Instance = &klausv1alpha1.TriggerInstanceAction{Name: "on-call"}
+	trigger.Spec.PromptTemplate = "Investigate {{ .payload.commonLabels.alertname }}"
+	return trigger
+}
+
+func testListener(t *testing.T, prompter Prompter, objs ...client.Object) (*Listener, client.Client) {
+	t.Helper()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hook-secret", Namespace: "team-a"},
+		Data:       map[string][]byte{"token": []byte(testToken)},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(append(objs, secret)...).
+		WithStatusSubresource(&klausv1alpha1.KlausTrigger{}).
+		Build()
+	return &Listener{
+		Client:            c,
+		Scheme:            c.Scheme(),
+		OperatorNamespace: "klaus-system",
+		Prompter:          prompter,
+		Recorder:          record.NewFakeRecorder(10),
+	}, c
+}
+
+func post(t *testing.T, l *Listener, path, body string, header http.Header) (int, response) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	l.Handler().ServeHTTP(rec, req)
+
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to parse response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func githubHeaders(event, body, secret string) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return http.Header{
+		"X-Github-Event":      {event},
+		"X-Github-Delivery":   {"delivery-1"},
+		"X-Hub-Signature-256": {"sha256=" + hex.EncodeToString(mac.Sum(nil))},
+	}
+}
+
+func TestListener_WebhookCreatesJob(t *testing.T) {
+	l, c := testListener(t, nil, jobTrigger(klausv1alpha1.TriggerSourceWebhook))
+	header := bearer(testToken)
+	header.Set("X-Klaus-Event", "deploy")
+
+	code, resp := post(t, l, "/triggers/team-a/hook", `{"summary":"v2 rolled out"}`, header)
+	if code != http.StatusAccepted || resp.Status != statusCreated || resp.Job == "" {
+		t.Fatalf("response = %d %+v, want a created job", code, resp)
+	}
+
+	var jobs klausv1alpha1.KlausJobList
+	if err := c.List(context.Background(), &jobs, client.InNamespace("team-a")); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 1 {
+		t.Fatalf("jobs = %d, want 1", len(jobs.Items))
+	}
+	job := jobs.Items[0]
+	if job.Spec.Prompt != "Handle deploy: v2 rolled out" {
+		t.Errorf("prompt = %q", job.Spec.Prompt)
+	}
+	if job.Labels[resources.LabelTrigger] != "hook" || len(job.OwnerReferences) != 1 || job.OwnerReferences[0].UID != "trigger-uid" {
+		t.Errorf("job is not linked to the trigger: labels %v, owners %v", job.Labels, job.OwnerReferences)
+	}
+
+	var trigger klausv1alpha1.KlausTrigger
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "hook", Namespace: "team-a"}, &trigger); err != nil {
+		t.Fatalf("failed to get trigger: %v", err)
+	}
+	if trigger.Status.LastTriggerTime == nil || trigger.Status.LastEvent != "deploy" || trigger.Status.LastJobName != job.Name {
+		t.Errorf("status = %+v, want the last event recorded", trigger.Status)
+	}
+}
+
+func TestListener_RejectsUnauthenticated(t *testing.T) {
+	l, _ := testListener(t, nil, jobTrigger(klausv1alpha1.TriggerSourceWebhook))
+
+	_, missing := post(t, l, "/triggers/team-a/missing", `{}`, nil)
+	for name, header := range map[string]http.Header{
+		"no token":    nil,
+		"wrong token": bearer("guess"),
+	} {
+		code, resp := post(t, l, "/triggers/team-a/hook", `{}`, header)
+		if code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, code)
+		}
+		// Callers cannot tell rejected events from unknown triggers.
+		if resp != missing {
+			t.Errorf("%s: response = %+v, want it to match the unknown trigger response %+v", name, resp, missing)
+		}
+	}
+}
+
+func TestListener_GitHubSignature(t *testing.T) {
+	trigger := jobTrigger(klausv1alpha1.TriggerSourceGitHub)
+	trigger.Spec.Source.Events = []string{"pull_request.opened"}
+	trigger.Spec.PromptTemplate = "Review {{ .payload.pull_request.html_url }}"
+	l, c := testListener(t, nil, trigger)
+	body := `{"action":"opened","pull_request":{"html_url":"https://github.com/org/repo/pull/7"}}`
+
+	if code, _ := post(t, l, "/triggers/team-a/hook", body, githubHeaders("pull_request", body, "wrong")); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status = %d, want 401", code)
+	}
+
+	code, resp := post(t, l, "/triggers/team-a/hook", body, githubHeaders("pull_request", body, testToken))
+	if code != http.StatusAccepted || resp.Status != statusCreated {
+		t.Fatalf("response = %d %+v, want a created job", code, resp)
+	}
+	code, again := post(t, l, "/triggers/team-a/hook", body, githubHeaders("pull_request", body, testToken))
+	if code != http.StatusOK || again.Status != statusDuplicate || again.Job != resp.Job {
+		t.Errorf("redelivery = %d %+v, want the duplicate acknowledged", code, again)
+	}
+
+	closed := `{"action":"closed"}`
+	if _, resp := post(t, l, "/triggers/team-a/hook", closed, githubHeaders("pull_request", closed, testToken)); resp.Status != statusIgnored {
+		t.Errorf("filtered event = %+v, want ignored", resp)
+	}
+	if _, resp := post(t, l, "/triggers/team-a/hook", `{}`, githubHeaders("ping", `{}`, testToken)); resp.Status != statusIgnored {
+		t.Errorf("ping = %+v, want ignored", resp)
+	}
+
+	var jobs klausv1alpha1.KlausJobList
+	if err := c.List(context.Background(), &jobs, client.InNamespace("team-a")); err != nil {
+		t.Fatalf("listing jobs: %v", err)
+	}
+	if len(jobs.Items) != 1 || jobs.Items[0].Spec.Prompt != "Review https://github.com/org/repo/pull/7" {
+		t.Errorf("jobs = %+v, want one review job", jobs.Items)
+	}
+}
+
+func TestListener_AlertmanagerPromptsInstance(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "on-call", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	prompter := &fakePrompter{}
+	l, _ := testListener(t, prompter, instanceTrigger(), instance)
+
+	code, resp := post(t, l, "/triggers/team-a/hook",
+		`{"status":"firing","commonLabels":{"alertname":"KubePodCrashLooping"}}`, bearer(testToken))
+	if code != http.StatusAccepted || resp.Status != statusPrompted || resp.Instance != "on-call" {
+		t.Fatalf("response = %d %+v, want the instance prompted", code, resp)
+	}
+	if prompter.instance != "on-call" || prompter.message != "Investigate KubePodCrashLooping" {
+		t.Errorf("prompt = %q to %q", prompter.message, prompter.instance)
+	}
+
+	prompter.err = errors.New("instance \"on-call\" is not running")
+	if code, _ := post(t, l, "/triggers/team-a/hook", `{"status":"firing","commonLabels":{"alertname":"X"}}`, bearer(testToken)); code != http.StatusBadGateway {
+		t.Errorf("failed prompt: status = %d, want 502", code)
+	}
+}
+
+func TestListener_InstanceOfAnotherOwner(t *testing.T) {
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "on-call", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "other@example.com"},
+	}
+	prompter := &fakePrompter{}
+	l, _ := testListener(t, prompter, instanceTrigger(), instance)
+
+	code, _ := post(t, l, "/triggers/team-a/hook", `{"status":"firing","commonLabels":{"alertname":"X"}}`, bearer(testToken))
+	if code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", code)
+	}
+	if prompter.instance != "" {
+		t.Error("another owner's instance was prompted")
+	}
+}
+
+func TestListener_Errors(t *testing.T) {
+	suspended := jobTrigger(klausv1alpha1.TriggerSourceWebhook)
+	suspended.Name = "suspended"
+	suspended.Spec.Suspend = true
+	l, _ := testListener(t, nil, jobTrigger(klausv1alpha1.TriggerSourceWebhook), suspended)
+	l.MaxBodyBytes = 64
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{name: "unknown trigger", path: "/triggers/team-a/missing", body: `{}`, want: http.StatusUnauthorized},
+		{name: "suspended", path: "/triggers/team-a/suspended", body: `{}`, want: http.StatusConflict},
+		{name: "not JSON", path: "/triggers/team-a/hook", body: `not json`, want: http.StatusBadRequest},
+		{name: "missing template field", path: "/triggers/team-a/hook", body: `{}`, want: http.StatusUnprocessableEntity},
+		{name: "too large", path: "/triggers/team-a/hook", body: `{"summary":"` + strings.Repeat("a", 64) + `"}`, want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, resp := post(t, l, tt.path, tt.body, bearer(testToken)); code != tt.want {
+				t.Errorf("status = %d (%+v), want %d", code, resp, tt.want)
+			}
+		})
+	}
+}
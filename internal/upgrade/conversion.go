@@ -0,0 +1,162 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ConversionPath is the path the operator serves the conversion webhook
+	// at.
+	ConversionPath = "/convert"
+
+	// injectCAAnnotation makes the cert-manager CA injector keep the CA
+	// bundle of the conversion webhook up to date as the serving certificate
+	// is renewed.
+	injectCAAnnotation = "cert-manager.io/inject-ca-from"
+)
+
+// The delay between attempts to configure the conversion starts at
+// conversionRetryDelay and doubles up to conversionMaxRetryDelay.
+var (
+	conversionRetryDelay    = time.Second
+	conversionMaxRetryDelay = time.Minute
+)
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=update
+
+// ConversionWebhook points the CRDs with several versions at the conversion
+// webhook of the operator and then serves all their versions. The CRDs are
+// shipped without a conversion configuration, as the Service they reference
+// depends on the release, and with the versions needing conversion not
+// served, so that they cannot be written before the webhook converts them.
+// The operator configures both on start when it serves webhooks.
+type ConversionWebhook struct {
+	Client client.Client
+	CRDs   []CRD
+
+	// Service is the Service fronting the webhook server.
+	Service types.NamespacedName
+
+	// Port is the port of the Service.
+	Port int32
+
+	// CABundle is the PEM-encoded CA the serving certificate is signed by.
+	CABundle []byte
+
+	// Certificate is the cert-manager Certificate of the serving
+	// certificate, as namespace/name. The CA injector then updates the CA
+	// bundle on renewal.
+	Certificate string
+}
+
+// NeedLeaderElection lets every replica configure the conversion, so it is
+// in place before a leader is elected. The update is idempotent.
+func (w *ConversionWebhook) NeedLeaderElection() bool {
+	return false
+}
+
+// Start configures the conversion of the CRDs, retrying with a backoff
+// until it succeeds. Failures are logged but do not stop the manager, so
+// the served versions keep working meanwhile.
+func (w *ConversionWebhook) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("upgrade")
+	delay := conversionRetryDelay
+	for {
+		err := w.Configure(ctx)
+		if err == nil {
+			return nil
+		}
+		logger.Error(err, "configuring the conversion webhook failed", "retryIn", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		delay = min(2*delay, conversionMaxRetryDelay)
+	}
+}
+
+// Configure sets the webhook conversion strategy on the CRDs with more than
+// one version and serves all their versions.
+func (w *ConversionWebhook) Configure(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("upgrade")
+
+	var errs []error
+	for _, c := range w.CRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := w.Client.Get(ctx, types.NamespacedName{Name: c.Name}, crd); err != nil {
+			errs = append(errs, fmt.Errorf("fetching CRD %s: %w", c.Name, err))
+			continue
+		}
+		if len(crd.Spec.Versions) < 2 {
+			continue
+		}
+
+		conversion := w.conversion(crd)
+		annotated := w.Certificate == "" || crd.Annotations[injectCAAnnotation] == w.Certificate
+		served := true
+		for _, v := range crd.Spec.Versions {
+			served = served && v.Served
+		}
+		if equality.Semantic.DeepEqual(crd.Spec.Conversion, conversion) && annotated && served {
+			continue
+		}
+		// Versions are only served in the update setting the conversion, so
+		// no object is written in them before it can be converted.
+		crd.Spec.Conversion = conversion
+		for i := range crd.Spec.Versions {
+			crd.Spec.Versions[i].Served = true
+		}
+		if w.Certificate != "" {
+			if crd.Annotations == nil {
+				crd.Annotations = map[string]string{}
+			}
+			crd.Annotations[injectCAAnnotation] = w.Certificate
+		}
+		if err := w.Client.Update(ctx, crd); err != nil {
+			errs = append(errs, fmt.Errorf("updating conversion of CRD %s: %w", c.Name, err))
+			continue
+		}
+		logger.Info("configured conversion webhook", "crd", c.Name, "service", w.Service.String())
+	}
+	return errors.Join(errs...)
+}
+
+// conversion returns the webhook conversion of the CRD. The CA bundle
+// already injected into the CRD is kept when the operator has none, or
+// when cert-manager maintains it.
+func (w *ConversionWebhook) conversion(crd *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.CustomResourceConversion {
+	caBundle := w.CABundle
+	if current := crd.Spec.Conversion; current != nil && current.Webhook != nil && current.Webhook.ClientConfig != nil &&
+		len(current.Webhook.ClientConfig.CABundle) > 0 && (len(caBundle) == 0 || w.Certificate != "") {
+		caBundle = current.Webhook.ClientConfig.CABundle
+	}
+	return &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: w.Service.Namespace,
+					Name:      w.Service.Name,
+					Path:      ptr.To(ConversionPath),
+					Port:      ptr.To(w.Port),
+				},
+				CABundle: caBundle,
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+}
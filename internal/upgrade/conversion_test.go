@@ -0,0 +1,133 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	klausv1alpha2 "github.com/giantswarm/klaus-operator/api/v1alpha2"
+)
+
+func TestConversionWebhookConfigure(t *testing.T) {
+	multi := testCRD(ManagedCRDs[0].Name, []apiextensionsv1.CustomResourceDefinitionVersion{
+		{Name: "v1alpha1", Served: true, Storage: true},
+		{Name: "v1alpha2"},
+	}, []string{"v1alpha1"})
+	single := testCRD(ManagedCRDs[1].Name, []apiextensionsv1.CustomResourceDefinitionVersion{
+		{Name: "v1alpha1", Served: true, Storage: true},
+	}, []string{"v1alpha1"})
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(multi, single).Build()
+	w := &ConversionWebhook{
+		Client:      c,
+		CRDs:        ManagedCRDs[:2],
+		Service:     types.NamespacedName{Namespace: "klaus-system", Name: "klaus-operator"},
+		Port:        443,
+		CABundle:    []byte("ca"),
+		Certificate: "klaus-system/klaus-operator-webhook",
+	}
+
+	if err := w.Configure(context.Background()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	var got apiextensionsv1.CustomResourceDefinition
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(multi), &got); err != nil {
+		t.Fatal(err)
+	}
+	conv := got.Spec.Conversion
+	if conv == nil || conv.Strategy != apiextensionsv1.WebhookConverter || conv.Webhook.ClientConfig.Service.Name != "klaus-operator" ||
+		*conv.Webhook.ClientConfig.Service.Path != ConversionPath || string(conv.Webhook.ClientConfig.CABundle) != "ca" {
+		t.Errorf("conversion = %+v, want the webhook of the operator Service", conv)
+	}
+	if got.Annotations[injectCAAnnotation] != "klaus-system/klaus-operator-webhook" {
+		t.Errorf("annotations = %v, want the CA injected from the Certificate", got.Annotations)
+	}
+	if !got.Spec.Versions[1].Served {
+		t.Errorf("versions = %+v, want v1alpha2 served once it is converted", got.Spec.Versions)
+	}
+
+	// A bundle injected by cert-manager is kept.
+	got.Spec.Conversion.Webhook.ClientConfig.CABundle = []byte("renewed")
+	if err := c.Update(context.Background(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Configure(context.Background()); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(multi), &got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Spec.Conversion.Webhook.ClientConfig.CABundle) != "renewed" {
+		t.Errorf("caBundle = %q, want the injected bundle kept", got.Spec.Conversion.Webhook.ClientConfig.CABundle)
+	}
+
+	if err := c.Get(context.Background(), client.ObjectKeyFromObject(single), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.Conversion != nil {
+		t.Errorf("conversion of a single-version CRD = %+v, want none", got.Spec.Conversion)
+	}
+}
+
+func TestConversionWebhookStart_Retries(t *testing.T) {
+	conversionRetryDelay = time.Millisecond
+	defer func() { conversionRetryDelay = time.Second }()
+
+	crd := testCRD(ManagedCRDs[0].Name, []apiextensionsv1.CustomResourceDefinitionVersion{
+		{Name: "v1alpha1", Served: true, Storage: true},
+		{Name: "v1alpha2"},
+	}, []string{"v1alpha1"})
+	failures := 2
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(crd).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if failures > 0 {
+					failures--
+					return errors.New("webhook service not ready")
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}).Build()
+	w := &ConversionWebhook{
+		Client:  c,
+		CRDs:    ManagedCRDs[:1],
+		Service: types.NamespacedName{Namespace: "klaus-system", Name: "klaus-operator"},
+		Port:    443,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := w.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if failures != 0 {
+		t.Fatalf("Start() returned with %d failures left, want it to retry", failures)
+	}
+	var got apiextensionsv1.CustomResourceDefinition
+	if err := c.Get(ctx, client.ObjectKeyFromObject(crd), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.Conversion == nil || !got.Spec.Versions[1].Served {
+		t.Errorf("CRD = %+v, want the conversion configured after the retries", got.Spec)
+	}
+}
+
+func TestKlausInstanceConvertible(t *testing.T) {
+	scheme := testScheme(t)
+	if err := klausv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding klausv1alpha2 to scheme: %v", err)
+	}
+	ok, err := conversion.IsConvertible(scheme, &klausv1alpha1.KlausInstance{})
+	if err != nil || !ok {
+		t.Errorf("IsConvertible() = %v, %v; want the conversion webhook to be registered", ok, err)
+	}
+}
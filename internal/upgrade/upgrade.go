@@ -0,0 +1,218 @@
+// Package upgrade implements the safety checks that allow the operator to be
+// upgraded without downtime: a CRD version skew check that refuses to start
+// against incompatible CRDs, a storage version migration that rewrites stored
+// objects to the current storage version, a sanity pass over instances
+// whose finalization is pending, and the conversion webhook configuration of
+// CRDs serving several versions.
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	klausv1alpha2 "github.com/giantswarm/klaus-operator/api/v1alpha2"
+)
+
+// stuckFinalizerThreshold is how long an instance may stay in deletion with
+// the operator finalizer before the sanity pass reports it as stuck.
+const stuckFinalizerThreshold = 10 * time.Minute
+
+// CRD describes a custom resource definition managed by the operator.
+type CRD struct {
+	// Name is the CRD object name (<plural>.<group>).
+	Name string
+	// Kind is the resource kind.
+	Kind string
+}
+
+// ManagedCRDs lists the CRDs served by this operator.
+var ManagedCRDs = []CRD{
+	{Name: "klausinstances." + klausv1alpha1.GroupVersion.Group, Kind: "KlausInstance"},
+	{Name: "klausmcpservers." + klausv1alpha1.GroupVersion.Group, Kind: "KlausMCPServer"},
+	{Name: "klausjobs." + klausv1alpha1.GroupVersion.Group, Kind: "KlausJob"},
+	{Name: "klauscronjobs." + klausv1alpha1.GroupVersion.Group, Kind: "KlausCronJob"},
+	{Name: "klausfleetstatuses." + klausv1alpha1.GroupVersion.Group, Kind: "KlausFleetStatus"},
+	{Name: "klausquotas." + klausv1alpha1.GroupVersion.Group, Kind: "KlausQuota"},
+	{Name: "klaustenants." + klausv1alpha1.GroupVersion.Group, Kind: "KlausTenant"},
+	{Name: "klaususagereports." + klausv1alpha1.GroupVersion.Group, Kind: "KlausUsageReport"},
+	{Name: "klaustriggers." + klausv1alpha1.GroupVersion.Group, Kind: "KlausTrigger"},
+	{Name: "klaustoolchains." + klausv1alpha1.GroupVersion.Group, Kind: "KlausToolchain"},
+	{Name: "klausskillpacks." + klausv1alpha1.GroupVersion.Group, Kind: "KlausSkillPack"},
+	{Name: "klausfleetoperations." + klausv1alpha1.GroupVersion.Group, Kind: "KlausFleetOperation"},
+}
+
+// SupportedVersions lists the API versions this operator binary understands.
+var SupportedVersions = []string{klausv1alpha1.GroupVersion.Version, klausv1alpha2.GroupVersion.Version}
+
+// CheckVersionSkew verifies that every managed CRD is installed, serves the
+// API version the operator uses, and has a storage version the operator
+// understands. It returns a descriptive error when the installed CRDs are
+// older or newer than this operator build, so the operator refuses to start
+// instead of corrupting objects or leaving finalizers stuck.
+func CheckVersionSkew(ctx context.Context, reader client.Reader, crds []CRD) error {
+	var errs []error
+	for _, c := range crds {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := reader.Get(ctx, types.NamespacedName{Name: c.Name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("CRD %s is not installed; apply the CRDs shipped with this operator release", c.Name))
+				continue
+			}
+			return fmt.Errorf("fetching CRD %s: %w", c.Name, err)
+		}
+
+		served := servedVersions(crd)
+		if !slices.Contains(served, klausv1alpha1.GroupVersion.Version) {
+			errs = append(errs, fmt.Errorf("CRD %s does not serve %s (served: %v); upgrade the CRDs to match this operator release",
+				c.Name, klausv1alpha1.GroupVersion.Version, served))
+		}
+		if storage := StorageVersion(crd); storage != "" && !slices.Contains(SupportedVersions, storage) {
+			errs = append(errs, fmt.Errorf("CRD %s stores version %s which this operator does not support (supported: %v); upgrade the operator",
+				c.Name, storage, SupportedVersions))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StorageVersion returns the version marked as the storage version on the CRD.
+func StorageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+func servedVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	var versions []string
+	for _, v := range crd.Spec.Versions {
+		if v.Served {
+			versions = append(versions, v.Name)
+		}
+	}
+	return versions
+}
+
+// Migrator is a leader-elected manager runnable that performs the startup
+// upgrade tasks: storage version migration and the finalizer sanity pass.
+type Migrator struct {
+	Client    client.Client
+	Recorder  record.EventRecorder
+	CRDs      []CRD
+	Finalizer string
+}
+
+// NeedLeaderElection ensures only the leader rewrites objects.
+func (m *Migrator) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the upgrade tasks once. Failures are logged but do not stop the
+// manager: a failed migration is retried on the next operator start and the
+// stored objects remain readable in the meantime.
+func (m *Migrator) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("upgrade")
+
+	for _, c := range m.CRDs {
+		if err := m.MigrateStorage(ctx, c); err != nil {
+			logger.Error(err, "storage version migration failed", "crd", c.Name)
+		}
+	}
+	if err := m.CheckFinalizers(ctx); err != nil {
+		logger.Error(err, "finalizer sanity pass failed")
+	}
+	return nil
+}
+
+// MigrateStorage rewrites every object of the CRD so the API server persists
+// it in the current storage version, then trims status.storedVersions to the
+// storage version. It is a no-op when only the storage version is recorded.
+func (m *Migrator) MigrateStorage(ctx context.Context, c CRD) error {
+	logger := log.FromContext(ctx).WithName("upgrade")
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := m.Client.Get(ctx, types.NamespacedName{Name: c.Name}, crd); err != nil {
+		return fmt.Errorf("fetching CRD %s: %w", c.Name, err)
+	}
+	storage := StorageVersion(crd)
+	if storage == "" {
+		return fmt.Errorf("CRD %s has no storage version", c.Name)
+	}
+	if slices.Equal(crd.Status.StoredVersions, []string{storage}) {
+		return nil
+	}
+
+	gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: storage, Kind: c.Kind + "List"}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	if err := m.Client.List(ctx, list); err != nil {
+		return fmt.Errorf("listing %s: %w", c.Name, err)
+	}
+
+	var errs []error
+	for i := range list.Items {
+		obj := &list.Items[i]
+		// An unmodified update is enough for the API server to re-encode the
+		// object in the storage version. Conflicts and deletions mean the
+		// object was written concurrently, which also migrates it.
+		if err := m.Client.Update(ctx, obj); err != nil && !apierrors.IsConflict(err) && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("rewriting %s/%s: %w", obj.GetNamespace(), obj.GetName(), err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	crd.Status.StoredVersions = []string{storage}
+	if err := m.Client.Status().Update(ctx, crd); err != nil {
+		return fmt.Errorf("updating storedVersions of CRD %s: %w", c.Name, err)
+	}
+	logger.Info("migrated stored objects to storage version", "crd", c.Name, "version", storage, "objects", len(list.Items))
+	return nil
+}
+
+// CheckFinalizers reports instances that have been pending deletion with the
+// operator finalizer for longer than stuckFinalizerThreshold, typically
+// because a previous operator version failed to clean up. A warning event is
+// emitted on each so the condition is visible to the owner and the instance
+// is picked up by the controller on its initial sync.
+func (m *Migrator) CheckFinalizers(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("upgrade")
+
+	var instances klausv1alpha1.KlausInstanceList
+	if err := m.Client.List(ctx, &instances); err != nil {
+		return fmt.Errorf("listing KlausInstances: %w", err)
+	}
+
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if instance.DeletionTimestamp == nil || !slices.Contains(instance.Finalizers, m.Finalizer) {
+			continue
+		}
+		if time.Since(instance.DeletionTimestamp.Time) < stuckFinalizerThreshold {
+			continue
+		}
+		msg := fmt.Sprintf("instance has been pending deletion since %s; finalizer %s is still present",
+			instance.DeletionTimestamp.UTC().Format(time.RFC3339), m.Finalizer)
+		logger.Info("stuck finalizer detected", "instance", instance.Name, "namespace", instance.Namespace)
+		if m.Recorder != nil {
+			m.Recorder.Event(instance, corev1.EventTypeWarning, "FinalizerStuck", msg)
+		}
+	}
+	return nil
+}
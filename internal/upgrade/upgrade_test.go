@@ -0,0 +1,155 @@
+package upgrade
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const testFinalizer = "klaus.giantswarm.io/finalizer"
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := klausv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding klausv1alpha1 to scheme: %v", err)
+	}
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("adding apiextensionsv1 to scheme: %v", err)
+	}
+	return scheme
+}
+
+func testCRD(name string, versions []apiextensionsv1.CustomResourceDefinitionVersion, stored []string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    klausv1alpha1.GroupVersion.Group,
+			Versions: versions,
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: stored},
+	}
+}
+
+func TestCheckVersionSkew_Compatible(t *testing.T) {
+	crd := testCRD(ManagedCRDs[0].Name, []apiextensionsv1.CustomResourceDefinitionVersion{
+		{Name: "v1alpha1", Served: true, Storage: true},
+	}, []string{"v1alpha1"})
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(crd).Build()
+
+	if err := CheckVersionSkew(context.Background(), c, ManagedCRDs[:1]); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckVersionSkew_Incompatible(t *testing.T) {
+	tests := []struct {
+		name    string
+		crd     *apiextensionsv1.CustomResourceDefinition
+		wantErr string
+	}{
+		{
+			name:    "missing CRD",
+			wantErr: "is not installed",
+		},
+		{
+			name: "version not served",
+			crd: testCRD(ManagedCRDs[0].Name, []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: false, Storage: false},
+				{Name: "v1beta1", Served: true, Storage: true},
+			}, nil),
+			wantErr: "does not serve v1alpha1",
+		},
+		{
+			name: "unknown storage version",
+			crd: testCRD(ManagedCRDs[0].Name, []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true},
+				{Name: "v1beta1", Served: true, Storage: true},
+			}, nil),
+			wantErr: "upgrade the operator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(testScheme(t))
+			if tt.crd != nil {
+				builder = builder.WithObjects(tt.crd)
+			}
+			err := CheckVersionSkew(context.Background(), builder.Build(), ManagedCRDs[:1])
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckVersionSkew() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMigrateStorage_TrimsStoredVersions(t *testing.T) {
+	crd := testCRD(ManagedCRDs[0].Name, []apiextensionsv1.CustomResourceDefinitionVersion{
+		{Name: "v1alpha1", Served: true, Storage: true},
+	}, []string{"v1alpha0", "v1alpha1"})
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "klaus-system"},
+		Spec:       klausv1alpha1.KlausInstanceSpec{Owner: "user@example.com"},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(crd, instance).
+		WithStatusSubresource(crd).
+		Build()
+
+	m := &Migrator{Client: c, CRDs: ManagedCRDs[:1]}
+	if err := m.MigrateStorage(context.Background(), ManagedCRDs[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var updated apiextensionsv1.CustomResourceDefinition
+	if err := c.Get(context.Background(), types.NamespacedName{Name: crd.Name}, &updated); err != nil {
+		t.Fatalf("failed to get CRD: %v", err)
+	}
+	if len(updated.Status.StoredVersions) != 1 || updated.Status.StoredVersions[0] != "v1alpha1" {
+		t.Errorf("storedVersions = %v, want [v1alpha1]", updated.Status.StoredVersions)
+	}
+}
+
+func TestCheckFinalizers_ReportsStuckInstances(t *testing.T) {
+	stuck := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "stuck",
+			Namespace:         "klaus-system",
+			Finalizers:        []string{testFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+		},
+	}
+	recent := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "recent",
+			Namespace:         "klaus-system",
+			Finalizers:        []string{testFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(stuck, recent).Build()
+	recorder := record.NewFakeRecorder(10)
+
+	m := &Migrator{Client: c, Recorder: recorder, Finalizer: testFinalizer}
+	if err := m.CheckFinalizers(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(recorder.Events) != 1 {
+		t.Fatalf("got %d events, want 1", len(recorder.Events))
+	}
+	if ev := <-recorder.Events; !strings.Contains(ev, "FinalizerStuck") {
+		t.Errorf("unexpected event: %q", ev)
+	}
+}
@@ -0,0 +1,307 @@
+// Package usage collects the resource consumption of Klaus instances from
+// metrics-server and Prometheus.
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// PodMetricsGVK is the metrics-server resource holding pod usage.
+var PodMetricsGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "PodMetrics"}
+
+// throttleWindow is the rate window of the CPU throttling query.
+const throttleWindow = "5m"
+
+// Collector reads the resource usage of instances. CPU and memory come from
+// metrics-server; workspace volume usage and CPU throttling from Prometheus
+// when configured.
+type Collector struct {
+	// Client lists instance pods.
+	Client client.Reader
+
+	// MetricsReader reads PodMetrics. It must not be a cached reader, as the
+	// metrics API cannot be watched.
+	MetricsReader client.Reader
+
+	// Prometheus reads kubelet volume statistics and cAdvisor throttling
+	// counters; nil disables them.
+	Prometheus *PrometheusClient
+
+	// NamespaceScoped looks up instance pods in the namespace of the
+	// instance instead of the owner's user namespace.
+	NamespaceScoped bool
+}
+
+// ContainerUsage is the usage and resource settings of one container.
+type ContainerUsage struct {
+	Name          string             `json:"name"`
+	CPU           *resource.Quantity `json:"cpu,omitempty"`
+	Memory        *resource.Quantity `json:"memory,omitempty"`
+	CPURequest    *resource.Quantity `json:"cpuRequest,omitempty"`
+	CPULimit      *resource.Quantity `json:"cpuLimit,omitempty"`
+	MemoryRequest *resource.Quantity `json:"memoryRequest,omitempty"`
+	MemoryLimit   *resource.Quantity `json:"memoryLimit,omitempty"`
+	Restarts      int32              `json:"restarts,omitempty"`
+	// LastTerminationReason is the reason the previous container exited,
+	// e.g. OOMKilled.
+	LastTerminationReason string `json:"lastTerminationReason,omitempty"`
+}
+
+// Usage is the resource consumption of an instance.
+type Usage struct {
+	Pod               string             `json:"pod,omitempty"`
+	Containers        []ContainerUsage   `json:"containers,omitempty"`
+	CPU               *resource.Quantity `json:"cpu,omitempty"`
+	Memory            *resource.Quantity `json:"memory,omitempty"`
+	WorkspaceUsed     *resource.Quantity `json:"workspaceUsed,omitempty"`
+	WorkspaceCapacity *resource.Quantity `json:"workspaceCapacity,omitempty"`
+	// CPUThrottledPercent is the share of CFS periods the pod was throttled
+	// in over the last five minutes.
+	CPUThrottledPercent *float64 `json:"cpuThrottledPercent,omitempty"`
+	// Warnings explains usage that could not be collected.
+	Warnings   []string  `json:"warnings,omitempty"`
+	ObservedAt time.Time `json:"observedAt"`
+}
+
+// Collect returns the current usage of an instance. Sources that are not
+// available, such as a cluster without metrics-server, are reported as
+// warnings; only failing to list the instance pods is an error.
+func (c *Collector) Collect(ctx context.Context, instance *klausv1alpha1.KlausInstance) (*Usage, error) {
+	namespace := resources.InstanceNamespace(instance, c.NamespaceScoped)
+	usage := &Usage{ObservedAt: time.Now()}
+
+	pod, err := c.instancePod(ctx, instance, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if pod == nil {
+		return usage, nil
+	}
+	usage.Pod = pod.Name
+	usage.Containers = containerSettings(pod)
+
+	if warning := c.collectPodMetrics(ctx, pod, usage); warning != "" {
+		usage.Warnings = append(usage.Warnings, warning)
+	}
+	if c.Prometheus != nil {
+		usage.Warnings = append(usage.Warnings, c.collectPrometheus(ctx, instance, pod, usage)...)
+	}
+	return usage, nil
+}
+
+// Status returns the usage as recorded in the instance status, or nil when
+// nothing was collected.
+func (u *Usage) Status() *klausv1alpha1.ResourceUsage {
+	if u.CPU == nil && u.Memory == nil && u.WorkspaceUsed == nil && u.WorkspaceCapacity == nil {
+		return nil
+	}
+	return &klausv1alpha1.ResourceUsage{
+		CPU:               u.CPU,
+		Memory:            u.Memory,
+		WorkspaceUsed:     u.WorkspaceUsed,
+		WorkspaceCapacity: u.WorkspaceCapacity,
+		ObservedAt:        metav1.NewTime(u.ObservedAt),
+	}
+}
+
+// instancePod returns the running pod of an instance, or nil when it has
+// none.
+func (c *Collector) instancePod(ctx context.Context, instance *klausv1alpha1.KlausInstance, namespace string) (*corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := c.Client.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels(resources.SelectorLabels(instance))); err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning && pods.Items[i].DeletionTimestamp == nil {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// containerSettings returns the requests, limits and restarts of the
+// containers of a pod.
+func containerSettings(pod *corev1.Pod) []ContainerUsage {
+	statuses := map[string]corev1.ContainerStatus{}
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	containers := make([]ContainerUsage, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		cu := ContainerUsage{
+			Name:          container.Name,
+			CPURequest:    quantity(container.Resources.Requests, corev1.ResourceCPU),
+			CPULimit:      quantity(container.Resources.Limits, corev1.ResourceCPU),
+			MemoryRequest: quantity(container.Resources.Requests, corev1.ResourceMemory),
+			MemoryLimit:   quantity(container.Resources.Limits, corev1.ResourceMemory),
+		}
+		if status, ok := statuses[container.Name]; ok {
+			cu.Restarts = status.RestartCount
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				cu.LastTerminationReason = terminated.Reason
+			}
+		}
+		containers = append(containers, cu)
+	}
+	return containers
+}
+
+// collectPodMetrics adds the metrics-server usage of a pod, returning a
+// warning when it is not available.
+func (c *Collector) collectPodMetrics(ctx context.Context, pod *corev1.Pod, usage *Usage) string {
+	metrics := &unstructured.Unstructured{}
+	metrics.SetGroupVersionKind(PodMetricsGVK)
+	if err := c.MetricsReader.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, metrics); err != nil {
+		switch {
+		case apimeta.IsNoMatchError(err):
+			return "metrics-server is not installed"
+		case apierrors.IsNotFound(err):
+			return "no metrics reported for pod " + pod.Name + " yet"
+		}
+		return "reading pod metrics: " + err.Error()
+	}
+
+	items, _, _ := unstructured.NestedSlice(metrics.Object, "containers")
+	cpu, memory := resource.Quantity{}, resource.Quantity{}
+	for _, item := range items {
+		container, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(container, "name")
+		containerCPU := parseQuantity(container, "cpu")
+		containerMemory := parseQuantity(container, "memory")
+		if containerCPU != nil {
+			cpu.Add(*containerCPU)
+		}
+		if containerMemory != nil {
+			memory.Add(*containerMemory)
+		}
+		for i := range usage.Containers {
+			if usage.Containers[i].Name == name {
+				usage.Containers[i].CPU = containerCPU
+				usage.Containers[i].Memory = containerMemory
+			}
+		}
+	}
+	usage.CPU, usage.Memory = &cpu, &memory
+	return ""
+}
+
+// collectPrometheus adds the workspace volume usage and CPU throttling of
+// an instance, returning warnings for failed queries.
+func (c *Collector) collectPrometheus(ctx context.Context, instance *klausv1alpha1.KlausInstance, pod *corev1.Pod, usage *Usage) []string {
+	var warnings []string
+	if instance.Spec.Workspace != nil {
+		volume := fmt.Sprintf(`{namespace=%q,persistentvolumeclaim=%q}`, pod.Namespace, resources.PVCName(instance))
+		for _, q := range []struct {
+			metric string
+			target **resource.Quantity
+		}{
+			{"kubelet_volume_stats_used_bytes", &usage.WorkspaceUsed},
+			{"kubelet_volume_stats_capacity_bytes", &usage.WorkspaceCapacity},
+		} {
+			value, ok, err := c.Prometheus.Query(ctx, "max("+q.metric+volume+")")
+			if err != nil {
+				warnings = append(warnings, "reading workspace usage: "+err.Error())
+				continue
+			}
+			if ok {
+				*q.target = resource.NewQuantity(int64(value), resource.BinarySI)
+			}
+		}
+	}
+
+	selector := fmt.Sprintf(`{namespace=%q,pod=%q}`, pod.Namespace, pod.Name)
+	query := fmt.Sprintf("100 * sum(rate(container_cpu_cfs_throttled_periods_total%s[%s])) / sum(rate(container_cpu_cfs_periods_total%s[%s]))",
+		selector, throttleWindow, selector, throttleWindow)
+	value, ok, err := c.Prometheus.Query(ctx, query)
+	if err != nil {
+		warnings = append(warnings, "reading CPU throttling: "+err.Error())
+	} else if ok {
+		usage.CPUThrottledPercent = &value
+	}
+	return warnings
+}
+
+// quantity returns the quantity of a resource in a list, or nil when unset.
+func quantity(list corev1.ResourceList, name corev1.ResourceName) *resource.Quantity {
+	if q, ok := list[name]; ok {
+		return &q
+	}
+	return nil
+}
+
+// parseQuantity parses a quantity in the usage map of a PodMetrics
+// container, returning nil when it is missing or invalid.
+func parseQuantity(container map[string]any, name string) *resource.Quantity {
+	raw, found, _ := unstructured.NestedString(container, "usage", name)
+	if !found {
+		return nil
+	}
+	q, err := resource.ParseQuantity(raw)
+	if err != nil {
+		return nil
+	}
+	return &q
+}
+
+// Thresholds above which Hints reports an instance as resource-starved.
+const (
+	nearLimitPercent     = 90
+	throttledPercentHint = 25
+	workspaceFullPercent = 90
+)
+
+// oomKilledReason is the termination reason of a container killed for
+// exceeding its memory limit.
+const oomKilledReason = "OOMKilled"
+
+// Hints explains which resources an instance is short of, e.g. a container
+// using most of its memory limit or a nearly full workspace.
+func (u *Usage) Hints() []string {
+	var hints []string
+	for _, c := range u.Containers {
+		if c.LastTerminationReason == oomKilledReason {
+			hints = append(hints, fmt.Sprintf("container %s was OOMKilled; raise its memory limit in spec.resources", c.Name))
+		} else if p := percentOf(c.Memory, c.MemoryLimit); p >= nearLimitPercent {
+			hints = append(hints, fmt.Sprintf("container %s uses %.0f%% of its memory limit", c.Name, p))
+		}
+		if p := percentOf(c.CPU, c.CPULimit); p >= nearLimitPercent {
+			hints = append(hints, fmt.Sprintf("container %s uses %.0f%% of its CPU limit", c.Name, p))
+		}
+	}
+	if u.CPUThrottledPercent != nil && *u.CPUThrottledPercent >= throttledPercentHint {
+		hints = append(hints, fmt.Sprintf("the pod was CPU throttled in %.0f%% of periods over the last %s; raise the CPU limit in spec.resources",
+			*u.CPUThrottledPercent, throttleWindow))
+	}
+	if p := percentOf(u.WorkspaceUsed, u.WorkspaceCapacity); p >= workspaceFullPercent {
+		hints = append(hints, fmt.Sprintf("the workspace volume is %.0f%% full", p))
+	}
+	return hints
+}
+
+// percentOf returns used as a percentage of limit, or 0 when either is
+// unknown.
+func percentOf(used, limit *resource.Quantity) float64 {
+	if used == nil || limit == nil || limit.IsZero() {
+		return 0
+	}
+	return 100 * used.AsApproximateFloat64() / limit.AsApproximateFloat64()
+}
@@ -0,0 +1,185 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+const testNamespace = "klaus-user-user-example-com"
+
+func testInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-agent", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:     "user@example.com",
+			Workspace: &klausv1alpha1.WorkspaceConfig{},
+		},
+	}
+}
+
+func testPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-agent-abc",
+			Namespace: testNamespace,
+			Labels:    map[string]string{"app.kubernetes.io/name": "klaus", "app.kubernetes.io/instance": "my-agent"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "klaus",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+		}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "klaus",
+				RestartCount:         2,
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}},
+			}},
+		},
+	}
+}
+
+func testPodMetrics() *unstructured.Unstructured {
+	metrics := &unstructured.Unstructured{Object: map[string]any{
+		"metadata": map[string]any{"name": "my-agent-abc", "namespace": testNamespace},
+		"containers": []any{
+			map[string]any{"name": "klaus", "usage": map[string]any{"cpu": "200m", "memory": "900Mi"}},
+			map[string]any{"name": "api-limiter", "usage": map[string]any{"cpu": "5m", "memory": "20Mi"}},
+		},
+	}}
+	metrics.SetGroupVersionKind(PodMetricsGVK)
+	return metrics
+}
+
+func testClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add corev1 scheme: %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestCollect(t *testing.T) {
+	c := testClient(t, testPod(), testPodMetrics())
+	pvc := fmt.Sprintf(`{namespace=%q,persistentvolumeclaim="my-agent-workspace"}`, testNamespace)
+	pod := fmt.Sprintf(`{namespace=%q,pod="my-agent-abc"}`, testNamespace)
+	srv := prometheusServer(t, map[string]string{
+		"max(kubelet_volume_stats_used_bytes" + pvc + ")":     vector("1073741824"),
+		"max(kubelet_volume_stats_capacity_bytes" + pvc + ")": vector("5368709120"),
+		"100 * sum(rate(container_cpu_cfs_throttled_periods_total" + pod + "[5m])) / sum(rate(container_cpu_cfs_periods_total" + pod + "[5m]))": vector("12.5"),
+	})
+	collector := &Collector{Client: c, MetricsReader: c, Prometheus: &PrometheusClient{URL: srv.URL}}
+
+	usage, err := collector.Collect(context.Background(), testInstance())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(usage.Warnings) != 0 {
+		t.Errorf("Warnings = %v", usage.Warnings)
+	}
+	if usage.Pod != "my-agent-abc" || usage.CPU.String() != "205m" || usage.Memory.String() != "920Mi" {
+		t.Errorf("usage = %s cpu=%s memory=%s", usage.Pod, usage.CPU, usage.Memory)
+	}
+	if usage.WorkspaceUsed.String() != "1Gi" || usage.WorkspaceCapacity.String() != "5Gi" {
+		t.Errorf("workspace = %s/%s", usage.WorkspaceUsed, usage.WorkspaceCapacity)
+	}
+	if usage.CPUThrottledPercent == nil || *usage.CPUThrottledPercent != 12.5 {
+		t.Errorf("CPUThrottledPercent = %v, want 12.5", usage.CPUThrottledPercent)
+	}
+
+	klaus := usage.Containers[0]
+	if klaus.CPU.String() != "200m" || klaus.CPURequest.String() != "250m" || klaus.MemoryLimit.String() != "1Gi" || klaus.CPULimit != nil {
+		t.Errorf("klaus container = %+v", klaus)
+	}
+	if klaus.Restarts != 2 || klaus.LastTerminationReason != "OOMKilled" {
+		t.Errorf("restarts = %d (%s)", klaus.Restarts, klaus.LastTerminationReason)
+	}
+
+	status := usage.Status()
+	if status == nil || status.CPU.String() != "205m" || status.WorkspaceUsed.String() != "1Gi" || status.ObservedAt.IsZero() {
+		t.Errorf("Status() = %+v", status)
+	}
+}
+
+func TestCollect_NoMetrics(t *testing.T) {
+	c := testClient(t, testPod())
+	collector := &Collector{Client: c, MetricsReader: c}
+
+	usage, err := collector.Collect(context.Background(), testInstance())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if usage.CPU != nil || len(usage.Warnings) != 1 || !strings.Contains(usage.Warnings[0], "no metrics") {
+		t.Errorf("usage = %+v, want a warning", usage)
+	}
+	if usage.Status() != nil {
+		t.Errorf("Status() = %+v, want nil without usage", usage.Status())
+	}
+}
+
+func TestCollect_NoPod(t *testing.T) {
+	c := testClient(t)
+	collector := &Collector{Client: c, MetricsReader: c}
+
+	usage, err := collector.Collect(context.Background(), testInstance())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if usage.Pod != "" || len(usage.Warnings) != 0 || usage.Status() != nil {
+		t.Errorf("usage = %+v, want none for a stopped instance", usage)
+	}
+}
+
+func TestUsageHints(t *testing.T) {
+	q := func(s string) *resource.Quantity {
+		v := resource.MustParse(s)
+		return &v
+	}
+	throttled := 40.0
+
+	usage := &Usage{
+		Containers: []ContainerUsage{
+			{Name: "klaus", Memory: q("500Mi"), MemoryLimit: q("1Gi"), LastTerminationReason: "OOMKilled"},
+			{Name: "api-limiter", CPU: q("95m"), CPULimit: q("100m"), Memory: q("60Mi"), MemoryLimit: q("64Mi")},
+		},
+		CPUThrottledPercent: &throttled,
+		WorkspaceUsed:       q("4.8Gi"),
+		WorkspaceCapacity:   q("5Gi"),
+	}
+	want := []string{
+		"container klaus was OOMKilled",
+		"container api-limiter uses 94% of its memory limit",
+		"container api-limiter uses 95% of its CPU limit",
+		"CPU throttled in 40% of periods",
+		"workspace volume is 96% full",
+	}
+	hints := usage.Hints()
+	if len(hints) != len(want) {
+		t.Fatalf("Hints() = %v, want %d hints", hints, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(hints[i], w) {
+			t.Errorf("hint %d = %q, want it to contain %q", i, hints[i], w)
+		}
+	}
+
+	if hints := (&Usage{Containers: []ContainerUsage{{Name: "klaus", Memory: q("100Mi")}}}).Hints(); len(hints) != 0 {
+		t.Errorf("Hints() = %v, want none without limits", hints)
+	}
+}
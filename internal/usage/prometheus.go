@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPrometheusTimeout bounds a single Prometheus query.
+const defaultPrometheusTimeout = 10 * time.Second
+
+// maxPrometheusResponse caps the size of a decoded query response.
+const maxPrometheusResponse = 1 << 20
+
+// PrometheusClient runs instant queries against the Prometheus HTTP API.
+type PrometheusClient struct {
+	// URL is the base URL of the Prometheus server, e.g.
+	// http://prometheus.monitoring:9090.
+	URL string
+
+	// HTTPClient sends the queries; http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// queryResponse is the part of a Prometheus query response the collector
+// reads.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value [2]any `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// Query runs an instant query that yields a single sample and returns its
+// value. It reports false when the query returned no sample, e.g. because
+// the series does not exist.
+func (p *PrometheusClient) Query(ctx context.Context, query string) (float64, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultPrometheusTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimSuffix(p.URL, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("building Prometheus request: %w", err)
+	}
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("querying Prometheus: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body queryResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPrometheusResponse)).Decode(&body); err != nil {
+		return 0, false, fmt.Errorf("decoding Prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return 0, false, fmt.Errorf("prometheus query failed: %s", body.Error)
+	}
+	if body.Data.ResultType != "vector" || len(body.Data.Result) == 0 {
+		return 0, false, nil
+	}
+
+	raw, ok := body.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected Prometheus sample %v", body.Data.Result[0].Value)
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("parsing Prometheus sample %q: %w", raw, err)
+	}
+	// Ratios over series without samples evaluate to NaN.
+	if math.IsNaN(value) {
+		return 0, false, nil
+	}
+	return value, true, nil
+}
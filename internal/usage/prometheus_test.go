@@ -0,0 +1,58 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func prometheusServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		body, ok := responses[r.URL.Query().Get("query")]
+		if !ok {
+			body = `{"status":"success","data":{"resultType":"vector","result":[]}}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func vector(value string) string {
+	return `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000.1,"` + value + `"]}]}}`
+}
+
+func TestPrometheusClient_Query(t *testing.T) {
+	srv := prometheusServer(t, map[string]string{
+		"up":    vector("1.5"),
+		"nan":   vector("NaN"),
+		"bad":   `{"status":"error","error":"parse error"}`,
+		"weird": `{"status":"success","data":{"resultType":"vector","result":[{"value":[1, 2]}]}}`,
+	})
+	p := &PrometheusClient{URL: srv.URL + "/"}
+	ctx := context.Background()
+
+	value, ok, err := p.Query(ctx, "up")
+	if err != nil || !ok || value != 1.5 {
+		t.Errorf("Query(up) = %v, %v, %v; want 1.5", value, ok, err)
+	}
+	if _, ok, err := p.Query(ctx, "missing"); err != nil || ok {
+		t.Errorf("Query(missing) = %v, %v; want no sample", ok, err)
+	}
+	if _, ok, err := p.Query(ctx, "nan"); err != nil || ok {
+		t.Errorf("Query(nan) = %v, %v; want no sample", ok, err)
+	}
+	if _, _, err := p.Query(ctx, "bad"); err == nil {
+		t.Error("expected an error for a failed query")
+	}
+	if _, _, err := p.Query(ctx, "weird"); err == nil {
+		t.Error("expected an error for a non-string sample")
+	}
+}
@@ -0,0 +1,138 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// Claude Code telemetry metrics as exported to Prometheus.
+const (
+	costMetric  = "claude_code_cost_usage_USD_total"
+	tokenMetric = "claude_code_token_usage_tokens_total"
+)
+
+// minTokenWindow is the shortest range the token usage queries look back.
+const minTokenWindow = time.Minute
+
+// TokenCollector reads the token usage and cost of instances from the
+// Claude Code telemetry metrics in Prometheus. The metrics carry the
+// instance resource attributes the operator sets, which must be promoted to
+// labels on the way to Prometheus, e.g. by the OpenTelemetry Collector's
+// resource_to_telemetry_conversion.
+type TokenCollector struct {
+	Prometheus *PrometheusClient
+
+	// NamespaceScoped matches the telemetry of instances running in their
+	// own namespace instead of the owner's user namespace.
+	NamespaceScoped bool
+}
+
+// Collect returns the usage of an instance since it was created. Every
+// agent process exports its own series, distinguished by its session, so
+// the usage is the sum of the highest value of each series, which survives
+// pod restarts. It returns nil when the instance has not reported any
+// usage.
+func (c *TokenCollector) Collect(ctx context.Context, instance *klausv1alpha1.KlausInstance) (*klausv1alpha1.TokenUsage, error) {
+	window := max(time.Since(instance.CreationTimestamp.Time), minTokenWindow)
+	selector := fmt.Sprintf(`%s=%q,%s=%q`,
+		resources.TelemetryLabelNamespace, resources.InstanceNamespace(instance, c.NamespaceScoped),
+		resources.TelemetryLabelInstance, instance.Name)
+	query := func(metric, extra string) (float64, bool, error) {
+		return c.Prometheus.Query(ctx, fmt.Sprintf("sum(max_over_time(%s{%s%s}[%ds]))",
+			metric, selector, extra, int64(math.Ceil(window.Seconds()))))
+	}
+
+	cost, found, err := query(costMetric, "")
+	if err != nil {
+		return nil, fmt.Errorf("reading cost: %w", err)
+	}
+	usage := &klausv1alpha1.TokenUsage{
+		CostUSD:    FormatUSD(cost),
+		ObservedAt: metav1.Now(),
+	}
+	for _, t := range []struct {
+		tokenType string
+		target    *int64
+	}{
+		{"input", &usage.InputTokens},
+		{"output", &usage.OutputTokens},
+		{"cacheRead", &usage.CacheReadTokens},
+		{"cacheCreation", &usage.CacheCreationTokens},
+	} {
+		value, ok, err := query(tokenMetric, fmt.Sprintf(`,type=%q`, t.tokenType))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s tokens: %w", t.tokenType, err)
+		}
+		if ok {
+			*t.target = int64(value)
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	return usage, nil
+}
+
+// FormatUSD formats a cost in US dollars as recorded in TokenUsage.
+func FormatUSD(cost float64) string {
+	return strconv.FormatFloat(math.Round(cost*1e4)/1e4, 'f', -1, 64)
+}
+
+// ParseUSD parses a cost recorded in TokenUsage, treating an empty or
+// invalid value as zero.
+func ParseUSD(cost string) float64 {
+	value, err := strconv.ParseFloat(cost, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// BudgetExceeded reports whether the recorded cost of an instance reached
+// its spec.claude.maxBudgetUSD.
+func BudgetExceeded(instance *klausv1alpha1.KlausInstance) bool {
+	budget := instance.Spec.Claude.MaxBudgetUSD
+	if budget == nil || *budget <= 0 || instance.Status.TokenUsage == nil {
+		return false
+	}
+	return ParseUSD(instance.Status.TokenUsage.CostUSD) >= *budget
+}
+
+// AddTokenUsage adds the counts and cost of u to total.
+func AddTokenUsage(total *klausv1alpha1.TokenUsage, u *klausv1alpha1.TokenUsage) {
+	total.InputTokens += u.InputTokens
+	total.OutputTokens += u.OutputTokens
+	total.CacheReadTokens += u.CacheReadTokens
+	total.CacheCreationTokens += u.CacheCreationTokens
+	total.CostUSD = FormatUSD(ParseUSD(total.CostUSD) + ParseUSD(u.CostUSD))
+}
+
+// MaxTokenUsage returns the current usage with every count and the cost
+// raised to at least the previous observation. Usage never decreases,
+// even when older series have aged out of Prometheus retention.
+func MaxTokenUsage(previous, current *klausv1alpha1.TokenUsage) *klausv1alpha1.TokenUsage {
+	if previous == nil {
+		return current
+	}
+	if current == nil {
+		return previous
+	}
+	merged := current.DeepCopy()
+	merged.InputTokens = max(merged.InputTokens, previous.InputTokens)
+	merged.OutputTokens = max(merged.OutputTokens, previous.OutputTokens)
+	merged.CacheReadTokens = max(merged.CacheReadTokens, previous.CacheReadTokens)
+	merged.CacheCreationTokens = max(merged.CacheCreationTokens, previous.CacheCreationTokens)
+	if ParseUSD(previous.CostUSD) > ParseUSD(merged.CostUSD) {
+		merged.CostUSD = previous.CostUSD
+	}
+	return merged
+}
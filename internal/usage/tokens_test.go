@@ -0,0 +1,116 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+// tokenPrometheus answers the token usage queries of the dev instance with
+// the value of the first fragment contained in the query.
+func tokenPrometheus(t *testing.T, values map[string]string) *PrometheusClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		body := `{"status":"success","data":{"resultType":"vector","result":[]}}`
+		if strings.Contains(query, `k8s_namespace_name="klaus-user-dev-example-com",klaus_instance="dev"`) {
+			for fragment, value := range values {
+				if strings.Contains(query, fragment) {
+					body = vector(value)
+				}
+			}
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return &PrometheusClient{URL: srv.URL}
+}
+
+func tokenInstance() *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "dev",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+		Spec: klausv1alpha1.KlausInstanceSpec{Owner: "dev@example.com"},
+	}
+}
+
+func TestTokenCollector_Collect(t *testing.T) {
+	c := &TokenCollector{Prometheus: tokenPrometheus(t, map[string]string{
+		"claude_code_cost_usage_USD_total": "1.23456",
+		`type="input"`:                     "1000",
+		`type="output"`:                    "250",
+		`type="cacheRead"`:                 "5000",
+	})}
+
+	got, err := c.Collect(context.Background(), tokenInstance())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil {
+		t.Fatal("expected usage")
+	}
+	if got.CostUSD != "1.2346" || got.InputTokens != 1000 || got.OutputTokens != 250 ||
+		got.CacheReadTokens != 5000 || got.CacheCreationTokens != 0 {
+		t.Errorf("usage = %+v", got)
+	}
+}
+
+func TestTokenCollector_Collect_NoTelemetry(t *testing.T) {
+	c := &TokenCollector{Prometheus: tokenPrometheus(t, nil)}
+
+	got, err := c.Collect(context.Background(), tokenInstance())
+	if err != nil || got != nil {
+		t.Errorf("Collect() = %+v, %v; want no usage", got, err)
+	}
+}
+
+func TestBudgetExceeded(t *testing.T) {
+	instance := tokenInstance()
+	if BudgetExceeded(instance) {
+		t.Error("an instance without budget must not exceed it")
+	}
+	instance.Spec.Claude.MaxBudgetUSD = ptr.To(5.0)
+	if BudgetExceeded(instance) {
+		t.Error("an instance without usage must not exceed its budget")
+	}
+	instance.Status.TokenUsage = &klausv1alpha1.TokenUsage{CostUSD: "4.99"}
+	if BudgetExceeded(instance) {
+		t.Error("4.99 must not exceed a budget of 5")
+	}
+	instance.Status.TokenUsage.CostUSD = "5"
+	if !BudgetExceeded(instance) {
+		t.Error("5 must exceed a budget of 5")
+	}
+}
+
+func TestAddTokenUsage(t *testing.T) {
+	total := &klausv1alpha1.TokenUsage{}
+	AddTokenUsage(total, &klausv1alpha1.TokenUsage{InputTokens: 10, CostUSD: "0.1"})
+	AddTokenUsage(total, &klausv1alpha1.TokenUsage{InputTokens: 5, OutputTokens: 3, CostUSD: "0.2"})
+	if total.InputTokens != 15 || total.OutputTokens != 3 || total.CostUSD != "0.3" {
+		t.Errorf("total = %+v", total)
+	}
+}
+
+func TestMaxTokenUsage(t *testing.T) {
+	previous := &klausv1alpha1.TokenUsage{InputTokens: 100, OutputTokens: 10, CostUSD: "2"}
+	current := &klausv1alpha1.TokenUsage{InputTokens: 50, OutputTokens: 20, CostUSD: "1.5"}
+
+	got := MaxTokenUsage(previous, current)
+	if got.InputTokens != 100 || got.OutputTokens != 20 || got.CostUSD != "2" {
+		t.Errorf("MaxTokenUsage() = %+v", got)
+	}
+	if MaxTokenUsage(nil, current) != current || MaxTokenUsage(previous, nil) != previous {
+		t.Error("a missing observation must keep the other one")
+	}
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// +kubebuilder:rbac:groups=klaus.giantswarm.io,resources=klaustenants,verbs=get;list;watch
+
+// instanceReservationTTL is how long an admitted instance counts against its
+// owner's limit while the API server does not return it as running yet. It
+// bounds how long a create rejected by a later admission step holds a slot.
+const instanceReservationTTL = 30 * time.Second
+
+// InstanceLimits rejects creating or starting an instance beyond its owner's
+// instance limit: spec.maxInstances of the owner's KlausTenant, or else
+// MaxInstancesPerOwner. Admissions are serialized, and admitted instances
+// are counted until the API server returns them, so concurrent requests of
+// an owner cannot both take the last slot.
+type InstanceLimits struct {
+	// Reader reads instances and tenants. It must read from the API server,
+	// not a cache, so recently written instances are counted.
+	Reader client.Reader
+
+	// Namespace is the operator namespace holding the KlausTenants.
+	Namespace string
+
+	// MaxInstancesPerOwner is the limit of owners without a tenant setting
+	// one. Zero disables it.
+	MaxInstancesPerOwner int
+
+	mu       sync.Mutex
+	reserved map[client.ObjectKey]instanceReservation
+}
+
+// instanceReservation is an admitted instance not yet returned as running.
+type instanceReservation struct {
+	owner string
+	until time.Time
+}
+
+// check rejects instance if its owner's other running and reserved
+// instances already reach the owner's limit, and reserves a slot for it
+// otherwise. Stopped instances and dry runs are not counted.
+func (l *InstanceLimits) check(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	if l == nil || !resources.CountsAgainstInstanceLimit(instance) {
+		return nil
+	}
+	owner := instance.Spec.Owner
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var tenants klausv1alpha1.KlausTenantList
+	if err := l.Reader.List(ctx, &tenants, client.InNamespace(l.Namespace)); err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("listing tenants: %w", err))
+	}
+	limit := resources.InstanceLimit(resources.SelectTenant(tenants.Items, owner), l.MaxInstancesPerOwner)
+	if limit <= 0 {
+		return nil
+	}
+	var instances klausv1alpha1.KlausInstanceList
+	if err := l.Reader.List(ctx, &instances, client.InNamespace(instance.Namespace)); err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("listing instances: %w", err))
+	}
+
+	key := client.ObjectKeyFromObject(instance)
+	running := 0
+	listed := map[client.ObjectKey]bool{}
+	for i := range instances.Items {
+		existing := &instances.Items[i]
+		if !resources.CountsAgainstInstanceLimit(existing) {
+			continue
+		}
+		listed[client.ObjectKeyFromObject(existing)] = true
+		if existing.Name != instance.Name && existing.Spec.Owner == owner {
+			running++
+		}
+	}
+	now := time.Now()
+	for reservedKey, reservation := range l.reserved {
+		if listed[reservedKey] || now.After(reservation.until) {
+			delete(l.reserved, reservedKey)
+			continue
+		}
+		if reservedKey != key && reservation.owner == owner {
+			running++
+		}
+	}
+
+	if running >= limit {
+		return apierrors.NewInvalid(klausv1alpha1.GroupVersion.WithKind("KlausInstance").GroupKind(), instance.Name, field.ErrorList{
+			field.Forbidden(field.NewPath("spec", "owner"), fmt.Sprintf(
+				"owner %s already has %d running instances, the maximum of %d; stop or delete an instance first",
+				owner, running, limit)),
+		})
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.DryRun != nil && *req.DryRun {
+		return nil
+	}
+	if l.reserved == nil {
+		l.reserved = map[client.ObjectKey]instanceReservation{}
+	}
+	l.reserved[key] = instanceReservation{owner: owner, until: now.Add(instanceReservationTTL)}
+	return nil
+}
+
+// startsInstance reports whether an update makes an instance count against
+// an owner's limit it did not count against before: it is started, or a
+// running instance is handed to another owner.
+func startsInstance(oldInstance, instance *klausv1alpha1.KlausInstance) bool {
+	if !resources.CountsAgainstInstanceLimit(instance) {
+		return false
+	}
+	return !resources.CountsAgainstInstanceLimit(oldInstance) || oldInstance.Spec.Owner != instance.Spec.Owner
+}
@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func limitTestPermissions(t *testing.T, maxInstances int, objs ...client.Object) *KlausInstancePermissions {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := klausv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	w := testPermissions()
+	w.Limits = &InstanceLimits{
+		Reader:               fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		Namespace:            "klaus-system",
+		MaxInstancesPerOwner: maxInstances,
+	}
+	return w
+}
+
+func limitInstance(name, owner string, stopped bool) *klausv1alpha1.KlausInstance {
+	instance := testInstance("", false)
+	instance.Name = name
+	instance.Spec.Owner = owner
+	if stopped {
+		instance.Spec.DesiredState = klausv1alpha1.DesiredStateStopped
+	}
+	return instance
+}
+
+func TestValidateCreate_InstanceLimit(t *testing.T) {
+	tenant := func(owner string, maxInstances int32) *klausv1alpha1.KlausTenant {
+		return &klausv1alpha1.KlausTenant{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant-" + owner, Namespace: "klaus-system"},
+			Spec:       klausv1alpha1.KlausTenantSpec{Owner: owner, MaxInstances: maxInstances},
+		}
+	}
+	tests := []struct {
+		name     string
+		max      int
+		objects  []client.Object
+		instance *klausv1alpha1.KlausInstance
+		wantErr  bool
+	}{
+		{
+			name:     "below the limit",
+			max:      2,
+			objects:  []client.Object{limitInstance("first", "user@example.com", false)},
+			instance: limitInstance("second", "user@example.com", false),
+		},
+		{
+			name:     "at the limit",
+			max:      1,
+			objects:  []client.Object{limitInstance("first", "user@example.com", false)},
+			instance: limitInstance("second", "user@example.com", false),
+			wantErr:  true,
+		},
+		{
+			name: "stopped and other owners' instances do not count",
+			max:  1,
+			objects: []client.Object{
+				limitInstance("first", "user@example.com", true),
+				limitInstance("theirs", "other@example.com", false),
+			},
+			instance: limitInstance("second", "user@example.com", false),
+		},
+		{
+			name:     "stopped instances are not limited",
+			max:      1,
+			objects:  []client.Object{limitInstance("first", "user@example.com", false)},
+			instance: limitInstance("second", "user@example.com", true),
+		},
+		{
+			name: "tenant raises the limit",
+			max:  1,
+			objects: []client.Object{
+				limitInstance("first", "user@example.com", false),
+				tenant("user@example.com", 2),
+			},
+			instance: limitInstance("second", "user@example.com", false),
+		},
+		{
+			name: "default tenant sets the limit",
+			objects: []client.Object{
+				limitInstance("first", "user@example.com", false),
+				tenant("", 1),
+			},
+			instance: limitInstance("second", "user@example.com", false),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := limitTestPermissions(t, tt.max, tt.objects...)
+			_, err := w.ValidateCreate(requestCtx(operatorUser), tt.instance)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "the maximum of 1") {
+					t.Errorf("ValidateCreate() error = %v, want the instance limit", err)
+				}
+			} else if err != nil {
+				t.Errorf("ValidateCreate() error = %v", err)
+			}
+		})
+	}
+}
+
+// Admitted instances count until the API server returns them, so concurrent
+// creates cannot both take the last slot.
+func TestValidateCreate_InstanceLimitReservesAdmitted(t *testing.T) {
+	w := limitTestPermissions(t, 1)
+	ctx := requestCtx(operatorUser)
+
+	dryRun := admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{DryRun: ptr.To(true)},
+	})
+	if _, err := w.ValidateCreate(dryRun, limitInstance("preview", "user@example.com", false)); err != nil {
+		t.Fatalf("dry run ValidateCreate() error = %v", err)
+	}
+	if _, err := w.ValidateCreate(ctx, limitInstance("first", "user@example.com", false)); err != nil {
+		t.Fatalf("ValidateCreate(first) error = %v, want the dry run not to hold a slot", err)
+	}
+	if _, err := w.ValidateCreate(ctx, limitInstance("second", "user@example.com", false)); err == nil {
+		t.Error("ValidateCreate(second) allowed while first is admitted but not yet listed")
+	}
+	if _, err := w.ValidateCreate(ctx, limitInstance("first", "user@example.com", false)); err != nil {
+		t.Errorf("retried ValidateCreate(first) error = %v, want its own reservation ignored", err)
+	}
+}
+
+func TestValidateUpdate_InstanceLimit(t *testing.T) {
+	running := limitInstance("first", "user@example.com", false)
+	stopped := limitInstance("second", "user@example.com", true)
+	w := limitTestPermissions(t, 1, running, stopped)
+	ctx := requestCtx(operatorUser)
+
+	started := stopped.DeepCopy()
+	started.Spec.DesiredState = klausv1alpha1.DesiredStateRunning
+	if _, err := w.ValidateUpdate(ctx, stopped, started); err == nil {
+		t.Error("starting an instance beyond the limit allowed")
+	}
+
+	edited := running.DeepCopy()
+	edited.Spec.Claude.Model = "claude-sonnet-4-5"
+	if _, err := w.ValidateUpdate(ctx, running, edited); err != nil {
+		t.Errorf("editing a running instance at the limit: %v", err)
+	}
+
+	restopped := stopped.DeepCopy()
+	restopped.Spec.Claude.Model = "claude-sonnet-4-5"
+	if _, err := w.ValidateUpdate(ctx, stopped, restopped); err != nil {
+		t.Errorf("editing a stopped instance at the limit: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+)
+
+// +kubebuilder:webhook:path=/mutate-klaus-giantswarm-io-v1alpha1-klauscronjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klauscronjobs,verbs=create;update,versions=v1alpha1,name=mklauscronjob.klaus.giantswarm.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-klaus-giantswarm-io-v1alpha1-klauscronjob,mutating=false,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klauscronjobs,verbs=create;update,versions=v1alpha1,name=vklauscronjob.klaus.giantswarm.io,admissionReviewVersions=v1
+
+// KlausCronJobPermissions defaults and validates
+// spec.jobTemplate.claude.permissionMode against the permission policy for
+// the groups of the requesting user. The operator stamps out the KlausJobs
+// of a schedule as a trusted user, so the template is checked here, when
+// its author writes it.
+type KlausCronJobPermissions struct {
+	// Policy decides the default and maximum permission mode.
+	Policy *permissions.Policy
+
+	// TrustedUsers are exempt from the policy.
+	TrustedUsers []string
+}
+
+// SetupKlausCronJobWebhookWithManager registers the defaulting and
+// validating webhooks for KlausCronJob.
+func SetupKlausCronJobWebhookWithManager(mgr ctrl.Manager, w *KlausCronJobPermissions) error {
+	return ctrl.NewWebhookManagedBy(mgr, &klausv1alpha1.KlausCronJob{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets an unset permission mode of the job template to the default
+// for the requester's groups.
+func (w *KlausCronJobPermissions) Default(ctx context.Context, cronJob *klausv1alpha1.KlausCronJob) error {
+	return defaultPermissionMode(ctx, w.Policy, w.TrustedUsers, &cronJob.Spec.JobTemplate.Claude)
+}
+
+// ValidateCreate rejects job templates with permission modes the requester's
+// groups may not use.
+func (w *KlausCronJobPermissions) ValidateCreate(ctx context.Context, cronJob *klausv1alpha1.KlausCronJob) (admission.Warnings, error) {
+	return nil, w.check(ctx, cronJob)
+}
+
+// ValidateUpdate applies the policy only when the permission mode or the
+// override of the job template change.
+func (w *KlausCronJobPermissions) ValidateUpdate(ctx context.Context, oldCronJob, cronJob *klausv1alpha1.KlausCronJob) (admission.Warnings, error) {
+	if !permissionModeChanged(&oldCronJob.Spec.JobTemplate.Claude, &cronJob.Spec.JobTemplate.Claude) {
+		return nil, nil
+	}
+	return nil, w.check(ctx, cronJob)
+}
+
+// ValidateDelete allows all deletions.
+func (w *KlausCronJobPermissions) ValidateDelete(context.Context, *klausv1alpha1.KlausCronJob) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *KlausCronJobPermissions) check(ctx context.Context, cronJob *klausv1alpha1.KlausCronJob) error {
+	return checkPermissionMode(ctx, w.Policy, w.TrustedUsers, klausv1alpha1.GroupVersion.WithKind("KlausCronJob").GroupKind(),
+		cronJob.Name, field.NewPath("spec", "jobTemplate", "claude"), &cronJob.Spec.JobTemplate.Claude)
+}
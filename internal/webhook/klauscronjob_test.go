@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testCronJob(mode klausv1alpha1.PermissionMode) *klausv1alpha1.KlausCronJob {
+	return &klausv1alpha1.KlausCronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausCronJobSpec{
+			Schedule: "0 2 * * *",
+			JobTemplate: klausv1alpha1.KlausJobSpec{
+				Owner:  "user@example.com",
+				Claude: klausv1alpha1.ClaudeConfig{PermissionMode: mode},
+			},
+		},
+	}
+}
+
+func TestKlausCronJobPermissions(t *testing.T) {
+	w := &KlausCronJobPermissions{Policy: testPermissions().Policy, TrustedUsers: []string{operatorUser}}
+	dev := requestCtx("dev@example.com", "dev")
+
+	cronJob := testCronJob("")
+	if err := w.Default(requestCtx("ops@example.com", "platform"), cronJob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode := cronJob.Spec.JobTemplate.Claude.PermissionMode; mode != klausv1alpha1.PermissionModeBypass {
+		t.Errorf("PermissionMode = %q, want the group default", mode)
+	}
+
+	_, err := w.ValidateCreate(dev, testCronJob(klausv1alpha1.PermissionModeBypass))
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.jobTemplate.claude.permissionMode") {
+		t.Errorf("error = %v, want Invalid for a mode above the maximum", err)
+	}
+	if _, err := w.ValidateCreate(dev, testCronJob(klausv1alpha1.PermissionModeDefault)); err != nil {
+		t.Errorf("unexpected error for an allowed mode: %v", err)
+	}
+
+	old := testCronJob(klausv1alpha1.PermissionModeBypass)
+	rescheduled := old.DeepCopy()
+	rescheduled.Spec.Schedule = "0 3 * * *"
+	if _, err := w.ValidateUpdate(dev, old, rescheduled); err != nil {
+		t.Errorf("unexpected error when keeping the mode: %v", err)
+	}
+	if _, err := w.ValidateUpdate(dev, testCronJob(klausv1alpha1.PermissionModeDefault), old); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when raising the mode", err)
+	}
+}
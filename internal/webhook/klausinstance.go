@@ -0,0 +1,152 @@
+// Package webhook implements the KlausInstance, KlausJob, KlausCronJob and
+// KlausTrigger admission webhooks.
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/deprecation"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// +kubebuilder:webhook:path=/mutate-klaus-giantswarm-io-v1alpha1-klausinstance,mutating=true,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klausinstances,verbs=create;update,versions=v1alpha1,name=mklausinstance.klaus.giantswarm.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-klaus-giantswarm-io-v1alpha1-klausinstance,mutating=false,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klausinstances,verbs=create;update,versions=v1alpha1,name=vklausinstance.klaus.giantswarm.io,admissionReviewVersions=v1
+
+// KlausInstancePermissions defaults and validates spec.claude.permissionMode
+// against the permission policy for the groups of the requesting user, the
+// image, personality and plugin references against the registry policy, and
+// running instances against their owner's instance limit.
+type KlausInstancePermissions struct {
+	// Policy decides the default and maximum permission mode.
+	Policy *permissions.Policy
+
+	// Registries restricts the registries references may point to; every
+	// registry is allowed when nil. It applies to trusted users too.
+	Registries *registrypolicy.Policy
+
+	// TrustedUsers are exempt from the policy. The operator itself is
+	// trusted: it enforces the policy for the MCP caller before creating
+	// instances and updates instances it did not author.
+	TrustedUsers []string
+
+	// Limits caps the running instances of each owner; owners are unlimited
+	// when nil. It applies to trusted users too.
+	Limits *InstanceLimits
+}
+
+// SetupKlausInstanceWebhookWithManager registers the defaulting and
+// validating webhooks for KlausInstance.
+func SetupKlausInstanceWebhookWithManager(mgr ctrl.Manager, w *KlausInstancePermissions) error {
+	return ctrl.NewWebhookManagedBy(mgr, &klausv1alpha1.KlausInstance{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets an unset permission mode to the default for the requester's
+// groups.
+func (w *KlausInstancePermissions) Default(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	return defaultPermissionMode(ctx, w.Policy, w.TrustedUsers, &instance.Spec.Claude)
+}
+
+// ValidateCreate rejects permission modes the requester's groups may not use,
+// references the registry policy does not allow and running instances beyond
+// the owner's instance limit, and warns about deprecated fields.
+func (w *KlausInstancePermissions) ValidateCreate(ctx context.Context, instance *klausv1alpha1.KlausInstance) (admission.Warnings, error) {
+	warnings := deprecation.Default.InstanceWarnings(instance)
+	if err := w.checkRegistries(instance); err != nil {
+		return warnings, err
+	}
+	if err := w.check(ctx, instance); err != nil {
+		return warnings, err
+	}
+	return warnings, w.Limits.check(ctx, instance)
+}
+
+// ValidateUpdate rejects shrinking the workspace and applies the policies
+// only when the permission mode or the override, respectively the
+// references, change, so existing instances, including those created before
+// a policy existed, can still be edited and stopped by their owners. The
+// instance limit applies when the update starts the instance or hands it to
+// another owner. Deprecated fields are warned about on every update that
+// keeps them.
+func (w *KlausInstancePermissions) ValidateUpdate(ctx context.Context, oldInstance, instance *klausv1alpha1.KlausInstance) (admission.Warnings, error) {
+	warnings := deprecation.Default.InstanceWarnings(instance)
+	if err := validateWorkspaceSize(oldInstance, instance); err != nil {
+		return warnings, err
+	}
+	if referencesChanged(oldInstance, instance) {
+		if err := w.checkRegistries(instance); err != nil {
+			return warnings, err
+		}
+	}
+	if permissionModeChanged(&oldInstance.Spec.Claude, &instance.Spec.Claude) {
+		if err := w.check(ctx, instance); err != nil {
+			return warnings, err
+		}
+	}
+	if startsInstance(oldInstance, instance) {
+		return warnings, w.Limits.check(ctx, instance)
+	}
+	return warnings, nil
+}
+
+// ValidateDelete allows all deletions.
+func (w *KlausInstancePermissions) ValidateDelete(context.Context, *klausv1alpha1.KlausInstance) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *KlausInstancePermissions) check(ctx context.Context, instance *klausv1alpha1.KlausInstance) error {
+	return checkPermissionMode(ctx, w.Policy, w.TrustedUsers, klausv1alpha1.GroupVersion.WithKind("KlausInstance").GroupKind(),
+		instance.Name, field.NewPath("spec", "claude"), &instance.Spec.Claude)
+}
+
+// checkRegistries rejects references the registry policy does not allow.
+func (w *KlausInstancePermissions) checkRegistries(instance *klausv1alpha1.KlausInstance) error {
+	if errs := w.Registries.ValidateInstanceSpec(&instance.Spec); len(errs) > 0 {
+		return apierrors.NewInvalid(klausv1alpha1.GroupVersion.WithKind("KlausInstance").GroupKind(), instance.Name, errs)
+	}
+	return nil
+}
+
+// referencesChanged reports whether an update changes the image,
+// personality or plugin references of an instance.
+func referencesChanged(oldInstance, instance *klausv1alpha1.KlausInstance) bool {
+	oldSpec, spec := &oldInstance.Spec, &instance.Spec
+	if oldSpec.Image != spec.Image || oldSpec.Personality != spec.Personality ||
+		!equality.Semantic.DeepEqual(oldSpec.Plugins, spec.Plugins) {
+		return true
+	}
+	if (oldSpec.InlinePersonality == nil) != (spec.InlinePersonality == nil) {
+		return true
+	}
+	return spec.InlinePersonality != nil && (oldSpec.InlinePersonality.Image != spec.InlinePersonality.Image ||
+		!equality.Semantic.DeepEqual(oldSpec.InlinePersonality.Plugins, spec.InlinePersonality.Plugins))
+}
+
+// validateWorkspaceSize rejects a smaller spec.workspace.size: PVCs can be
+// expanded but not shrunk.
+func validateWorkspaceSize(oldInstance, instance *klausv1alpha1.KlausInstance) error {
+	if oldInstance.Spec.Workspace == nil || instance.Spec.Workspace == nil {
+		return nil
+	}
+	oldSize := resources.WorkspaceSize(oldInstance.Spec.Workspace)
+	size := resources.WorkspaceSize(instance.Spec.Workspace)
+	if size.Cmp(oldSize) >= 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(klausv1alpha1.GroupVersion.WithKind("KlausInstance").GroupKind(), instance.Name, field.ErrorList{
+		field.Invalid(field.NewPath("spec", "workspace", "size"), size.String(),
+			fmt.Sprintf("the workspace cannot shrink below %s, only expanding the PVC is supported", oldSize.String())),
+	})
+}
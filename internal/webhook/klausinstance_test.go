@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+)
+
+const operatorUser = "system:serviceaccount:klaus-system:klaus-operator"
+
+func testPermissions() *KlausInstancePermissions {
+	return &KlausInstancePermissions{
+		Policy: &permissions.Policy{
+			Default:     klausv1alpha1.PermissionModeDefault,
+			Max:         klausv1alpha1.PermissionModeDefault,
+			AdminGroups: []string{"platform-admins"},
+			Rules: []permissions.Rule{{
+				Groups:  []string{"platform"},
+				Default: klausv1alpha1.PermissionModeBypass,
+				Max:     klausv1alpha1.PermissionModeBypass,
+			}},
+		},
+		TrustedUsers: []string{operatorUser},
+	}
+}
+
+func requestCtx(username string, groups ...string) context.Context {
+	return admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+		},
+	})
+}
+
+func testInstance(mode klausv1alpha1.PermissionMode, override bool) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-instance", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:  "user@example.com",
+			Claude: klausv1alpha1.ClaudeConfig{PermissionMode: mode, PermissionModeOverride: override},
+		},
+	}
+}
+
+func TestDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		mode     klausv1alpha1.PermissionMode
+		wantMode klausv1alpha1.PermissionMode
+	}{
+		{name: "unmatched user", ctx: requestCtx("dev@example.com", "dev"), wantMode: klausv1alpha1.PermissionModeDefault},
+		{name: "group default", ctx: requestCtx("ops@example.com", "platform"), wantMode: klausv1alpha1.PermissionModeBypass},
+		{name: "explicit mode kept", ctx: requestCtx("ops@example.com", "platform"), mode: klausv1alpha1.PermissionModeDefault, wantMode: klausv1alpha1.PermissionModeDefault},
+		{name: "trusted user left alone", ctx: requestCtx(operatorUser), wantMode: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := testInstance(tt.mode, false)
+			if err := testPermissions().Default(tt.ctx, instance); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if instance.Spec.Claude.PermissionMode != tt.wantMode {
+				t.Errorf("PermissionMode = %q, want %q", instance.Spec.Claude.PermissionMode, tt.wantMode)
+			}
+		})
+	}
+}
+
+func TestDefault_NoRequest(t *testing.T) {
+	if err := testPermissions().Default(context.Background(), testInstance("", false)); err == nil {
+		t.Error("expected error without an admission request")
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		mode    klausv1alpha1.PermissionMode
+		over    bool
+		wantErr string
+	}{
+		{name: "allowed mode", ctx: requestCtx("ops@example.com", "platform"), mode: klausv1alpha1.PermissionModeBypass},
+		{name: "mode above max", ctx: requestCtx("dev@example.com", "dev"), mode: klausv1alpha1.PermissionModeBypass, wantErr: "exceeds"},
+		{name: "override by non-admin", ctx: requestCtx("ops@example.com", "platform"), mode: klausv1alpha1.PermissionModeBypass, over: true, wantErr: "platform admins"},
+		{name: "admin override", ctx: requestCtx("admin@example.com", "platform-admins"), mode: klausv1alpha1.PermissionModeBypass, over: true},
+		{name: "trusted user", ctx: requestCtx(operatorUser), mode: klausv1alpha1.PermissionModeBypass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testPermissions().ValidateCreate(tt.ctx, testInstance(tt.mode, tt.over))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want an Invalid error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	ctx := requestCtx("dev@example.com", "dev")
+	w := testPermissions()
+
+	// Instances created with bypassPermissions before the policy existed can
+	// still be edited without touching the mode.
+	oldInstance := testInstance(klausv1alpha1.PermissionModeBypass, false)
+	edited := oldInstance.DeepCopy()
+	edited.Spec.Claude.Model = "claude-sonnet-4-20250514"
+	if _, err := w.ValidateUpdate(ctx, oldInstance, edited); err != nil {
+		t.Errorf("unexpected error for an unchanged mode: %v", err)
+	}
+
+	escalated := testInstance(klausv1alpha1.PermissionModeDefault, false)
+	raised := escalated.DeepCopy()
+	raised.Spec.Claude.PermissionMode = klausv1alpha1.PermissionModeBypass
+	if _, err := w.ValidateUpdate(ctx, escalated, raised); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when raising the mode", err)
+	}
+
+	overridden := oldInstance.DeepCopy()
+	overridden.Spec.Claude.PermissionModeOverride = true
+	if _, err := w.ValidateUpdate(ctx, oldInstance, overridden); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when setting the override", err)
+	}
+}
+
+func TestValidateUpdate_WorkspaceSize(t *testing.T) {
+	ctx := requestCtx("dev@example.com", "dev")
+	w := testPermissions()
+
+	oldInstance := testInstance(klausv1alpha1.PermissionModeDefault, false)
+	oldInstance.Spec.Workspace = &klausv1alpha1.WorkspaceConfig{Size: ptr.To(resource.MustParse("10Gi"))}
+
+	grown := oldInstance.DeepCopy()
+	grown.Spec.Workspace.Size = ptr.To(resource.MustParse("20Gi"))
+	if _, err := w.ValidateUpdate(ctx, oldInstance, grown); err != nil {
+		t.Errorf("unexpected error when growing the workspace: %v", err)
+	}
+
+	shrunk := oldInstance.DeepCopy()
+	shrunk.Spec.Workspace.Size = ptr.To(resource.MustParse("8Gi"))
+	if _, err := w.ValidateUpdate(ctx, oldInstance, shrunk); !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.workspace.size") {
+		t.Errorf("error = %v, want Invalid for spec.workspace.size", err)
+	}
+
+	// An unset size is the 5Gi default.
+	unset := oldInstance.DeepCopy()
+	unset.Spec.Workspace.Size = nil
+	if _, err := w.ValidateUpdate(ctx, oldInstance, unset); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when unsetting a larger size", err)
+	}
+	if _, err := w.ValidateUpdate(ctx, unset, oldInstance); err != nil {
+		t.Errorf("unexpected error when growing from the default: %v", err)
+	}
+}
+
+func TestValidate_RegistryPolicy(t *testing.T) {
+	w := testPermissions()
+	w.Registries = &registrypolicy.Policy{Allow: []string{"gsoci.azurecr.io/giantswarm"}}
+
+	instance := testInstance(klausv1alpha1.PermissionModeDefault, false)
+	instance.Spec.Personality = "go-dev"
+	if _, err := w.ValidateCreate(requestCtx("dev@example.com", "dev"), instance); err != nil {
+		t.Errorf("unexpected error for an allowed personality: %v", err)
+	}
+
+	// The operator is trusted for the permission policy, not for the
+	// registry policy.
+	disallowed := instance.DeepCopy()
+	disallowed.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{
+		Plugins: []klausv1alpha1.PluginReference{{Repository: "ghcr.io/someone/plugin", Tag: "v1.0.0"}},
+	}
+	_, err := w.ValidateCreate(requestCtx(operatorUser), disallowed)
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.inlinePersonality.plugins[0].repository") {
+		t.Errorf("error = %v, want Invalid for the inline personality plugin", err)
+	}
+
+	// Instances created before the policy can be updated as long as their
+	// references stay the same.
+	edited := disallowed.DeepCopy()
+	edited.Spec.Claude.Model = "claude-sonnet-4-20250514"
+	if _, err := w.ValidateUpdate(requestCtx("dev@example.com", "dev"), disallowed, edited); err != nil {
+		t.Errorf("unexpected error when keeping the references: %v", err)
+	}
+	edited.Spec.Image = "docker.io/library/golang:1.26"
+	if _, err := w.ValidateUpdate(requestCtx("dev@example.com", "dev"), disallowed, edited); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when changing the image", err)
+	}
+}
+
+func TestValidate_DeprecationWarnings(t *testing.T) {
+	ctx := requestCtx("ops@example.com", "platform")
+	w := testPermissions()
+
+	instance := testInstance(klausv1alpha1.PermissionModeBypass, false)
+	if warnings, _ := w.ValidateCreate(ctx, instance); len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	deprecated := instance.DeepCopy()
+	deprecated.Spec.Scheduling = &klausv1alpha1.SchedulingConfig{RuntimeClassName: ptr.To("gvisor")}
+	warnings, err := w.ValidateCreate(ctx, deprecated)
+	if err != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "spec.scheduling.runtimeClassName") {
+		t.Errorf("ValidateCreate() = %v, %v; want the runtimeClassName warning", warnings, err)
+	}
+
+	edited := deprecated.DeepCopy()
+	edited.Spec.Claude.Model = "claude-sonnet-4-20250514"
+	if warnings, _ := w.ValidateUpdate(ctx, deprecated, edited); len(warnings) != 1 {
+		t.Errorf("ValidateUpdate() warnings = %v, want the runtimeClassName warning", warnings)
+	}
+}
+
+func TestValidateDelete(t *testing.T) {
+	if _, err := testPermissions().ValidateDelete(requestCtx("dev@example.com"), testInstance(klausv1alpha1.PermissionModeBypass, true)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+)
+
+// +kubebuilder:webhook:path=/mutate-klaus-giantswarm-io-v1alpha1-klausjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klausjobs,verbs=create;update,versions=v1alpha1,name=mklausjob.klaus.giantswarm.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-klaus-giantswarm-io-v1alpha1-klausjob,mutating=false,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klausjobs,verbs=create;update,versions=v1alpha1,name=vklausjob.klaus.giantswarm.io,admissionReviewVersions=v1
+
+// KlausJobPolicies defaults and validates spec.claude.permissionMode of
+// KlausJobs against the permission policy, like KlausInstancePermissions,
+// and their image, personality and plugin references against the registry
+// policy. KlausJobs created by KlausCronJobs and KlausTriggers are checked
+// against the registry policy like any other; their permission mode was
+// checked in the template when the owner wrote it.
+type KlausJobPolicies struct {
+	// Policy decides the default and maximum permission mode.
+	Policy *permissions.Policy
+
+	// Registries restricts the registries references may point to; every
+	// registry is allowed when nil. It applies to trusted users too.
+	Registries *registrypolicy.Policy
+
+	// TrustedUsers are exempt from the permission policy. The operator
+	// itself is trusted: it stamps out the jobs of KlausCronJobs and
+	// KlausTriggers.
+	TrustedUsers []string
+}
+
+// SetupKlausJobWebhookWithManager registers the defaulting and validating
+// webhooks for KlausJob.
+func SetupKlausJobWebhookWithManager(mgr ctrl.Manager, w *KlausJobPolicies) error {
+	return ctrl.NewWebhookManagedBy(mgr, &klausv1alpha1.KlausJob{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets an unset permission mode to the default for the requester's
+// groups.
+func (w *KlausJobPolicies) Default(ctx context.Context, job *klausv1alpha1.KlausJob) error {
+	return defaultPermissionMode(ctx, w.Policy, w.TrustedUsers, &job.Spec.Claude)
+}
+
+// ValidateCreate rejects permission modes the requester's groups may not use
+// and references the registry policy does not allow.
+func (w *KlausJobPolicies) ValidateCreate(ctx context.Context, job *klausv1alpha1.KlausJob) (admission.Warnings, error) {
+	if err := w.checkRegistries(job); err != nil {
+		return nil, err
+	}
+	return nil, w.checkPermissions(ctx, job)
+}
+
+// ValidateUpdate applies the policies only when the permission mode or the
+// override, respectively the references, change, so jobs created before the
+// policies existed can still be updated, e.g. to remove their finalizer.
+func (w *KlausJobPolicies) ValidateUpdate(ctx context.Context, oldJob, job *klausv1alpha1.KlausJob) (admission.Warnings, error) {
+	if oldJob.Spec.Image != job.Spec.Image || oldJob.Spec.Personality != job.Spec.Personality ||
+		!equality.Semantic.DeepEqual(oldJob.Spec.Plugins, job.Spec.Plugins) {
+		if err := w.checkRegistries(job); err != nil {
+			return nil, err
+		}
+	}
+	if !permissionModeChanged(&oldJob.Spec.Claude, &job.Spec.Claude) {
+		return nil, nil
+	}
+	return nil, w.checkPermissions(ctx, job)
+}
+
+// ValidateDelete allows all deletions.
+func (w *KlausJobPolicies) ValidateDelete(context.Context, *klausv1alpha1.KlausJob) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *KlausJobPolicies) checkPermissions(ctx context.Context, job *klausv1alpha1.KlausJob) error {
+	return checkPermissionMode(ctx, w.Policy, w.TrustedUsers, klausv1alpha1.GroupVersion.WithKind("KlausJob").GroupKind(),
+		job.Name, field.NewPath("spec", "claude"), &job.Spec.Claude)
+}
+
+func (w *KlausJobPolicies) checkRegistries(job *klausv1alpha1.KlausJob) error {
+	errs := w.Registries.Validate(field.NewPath("spec"), job.Spec.Image, job.Spec.Personality, job.Spec.Plugins)
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(klausv1alpha1.GroupVersion.WithKind("KlausJob").GroupKind(), job.Name, errs)
+	}
+	return nil
+}
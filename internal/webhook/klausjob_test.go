@@ -0,0 +1,117 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
+)
+
+func testJob() *klausv1alpha1.KlausJob {
+	return &klausv1alpha1.KlausJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "klaus-system"},
+		Spec: klausv1alpha1.KlausJobSpec{
+			Owner:       "user@example.com",
+			Personality: "go-dev",
+			Plugins: []klausv1alpha1.PluginReference{
+				{Repository: "gs-base", Tag: "v1.0.0"},
+			},
+		},
+	}
+}
+
+func TestKlausJobValidateCreate(t *testing.T) {
+	ctx := requestCtx("user@example.com")
+	w := &KlausJobPolicies{Registries: &registrypolicy.Policy{Deny: []string{"docker.io"}}}
+
+	if _, err := w.ValidateCreate(ctx, testJob()); err != nil {
+		t.Errorf("unexpected error for allowed references: %v", err)
+	}
+
+	job := testJob()
+	job.Spec.Image = "golang:1.26"
+	job.Spec.Plugins = append(job.Spec.Plugins, klausv1alpha1.PluginReference{Repository: "someone/plugin", Tag: "v1.0.0"})
+	_, err := w.ValidateCreate(ctx, job)
+	if !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), "spec.plugins[1].repository") ||
+		!strings.Contains(err.Error(), "docker.io/someone/plugin is denied") {
+		t.Errorf("error = %v, want Invalid for the Docker Hub plugin", err)
+	}
+
+	// Short toolchain names expand to the toolchain registry, not Docker Hub.
+	if strings.Contains(err.Error(), "spec.image") {
+		t.Errorf("error = %v, want the short image name allowed", err)
+	}
+
+	var disabled KlausJobPolicies
+	if _, err := disabled.ValidateCreate(ctx, job); err != nil {
+		t.Errorf("unexpected error without a registry policy: %v", err)
+	}
+}
+
+func TestKlausJobValidateUpdate(t *testing.T) {
+	ctx := requestCtx("user@example.com")
+	w := &KlausJobPolicies{Registries: &registrypolicy.Policy{Allow: []string{"gsoci.azurecr.io/giantswarm"}}}
+
+	oldJob := testJob()
+	oldJob.Spec.Image = "docker.io/library/golang:1.26"
+
+	finalized := oldJob.DeepCopy()
+	finalized.Finalizers = nil
+	if _, err := w.ValidateUpdate(ctx, oldJob, finalized); err != nil {
+		t.Errorf("unexpected error when keeping the references: %v", err)
+	}
+
+	changed := oldJob.DeepCopy()
+	changed.Spec.Plugins[0].Tag = "v1.1.0"
+	if _, err := w.ValidateUpdate(ctx, oldJob, changed); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when changing the references", err)
+	}
+}
+
+func TestKlausJobValidateDelete(t *testing.T) {
+	w := &KlausJobPolicies{Registries: &registrypolicy.Policy{Allow: []string{"example.com"}}}
+	if _, err := w.ValidateDelete(context.Background(), testJob()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestKlausJobPermissions(t *testing.T) {
+	w := &KlausJobPolicies{Policy: testPermissions().Policy, TrustedUsers: []string{operatorUser}}
+
+	job := testJob()
+	if err := w.Default(requestCtx("dev@example.com", "dev"), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Spec.Claude.PermissionMode != klausv1alpha1.PermissionModeDefault {
+		t.Errorf("PermissionMode = %q, want the group default", job.Spec.Claude.PermissionMode)
+	}
+
+	bypass := testJob()
+	bypass.Spec.Claude.PermissionMode = klausv1alpha1.PermissionModeBypass
+	if _, err := w.ValidateCreate(requestCtx("dev@example.com", "dev"), bypass); !apierrors.IsInvalid(err) ||
+		!strings.Contains(err.Error(), "spec.claude.permissionMode") {
+		t.Errorf("error = %v, want Invalid for a mode above the maximum", err)
+	}
+	if _, err := w.ValidateCreate(requestCtx("ops@example.com", "platform"), bypass); err != nil {
+		t.Errorf("unexpected error for an allowed mode: %v", err)
+	}
+	// Jobs stamped out by the operator from checked templates pass.
+	if _, err := w.ValidateCreate(requestCtx(operatorUser), bypass); err != nil {
+		t.Errorf("unexpected error for the operator: %v", err)
+	}
+
+	// Updates keeping the mode are allowed, raising it is not.
+	finalized := bypass.DeepCopy()
+	finalized.Finalizers = nil
+	if _, err := w.ValidateUpdate(requestCtx("dev@example.com", "dev"), bypass, finalized); err != nil {
+		t.Errorf("unexpected error when keeping the mode: %v", err)
+	}
+	if _, err := w.ValidateUpdate(requestCtx("dev@example.com", "dev"), job, bypass); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when raising the mode", err)
+	}
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+)
+
+// +kubebuilder:webhook:path=/mutate-klaus-giantswarm-io-v1alpha1-klaustrigger,mutating=true,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klaustriggers,verbs=create;update,versions=v1alpha1,name=mklaustrigger.klaus.giantswarm.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-klaus-giantswarm-io-v1alpha1-klaustrigger,mutating=false,failurePolicy=fail,sideEffects=None,groups=klaus.giantswarm.io,resources=klaustriggers,verbs=create;update,versions=v1alpha1,name=vklaustrigger.klaus.giantswarm.io,admissionReviewVersions=v1
+
+// KlausTriggerOwners rejects KlausTriggers acting for another user than the
+// requester. The KlausJobs and prompts of a trigger run as its spec.owner,
+// so without the check anyone allowed to write triggers could act as any
+// owner, e.g. prompt their instances. Like KlausCronJobPermissions, it also
+// defaults and validates spec.job.jobTemplate.claude.permissionMode against
+// the permission policy.
+type KlausTriggerOwners struct {
+	// UsernamePrefix is stripped from the requester's username before it
+	// is compared with spec.owner, matching the API server's OIDC username
+	// prefix.
+	UsernamePrefix string
+
+	// Policy decides the default and maximum permission mode.
+	Policy *permissions.Policy
+
+	// TrustedUsers may write triggers of any owner and are exempt from the
+	// permission policy.
+	TrustedUsers []string
+}
+
+// SetupKlausTriggerWebhookWithManager registers the defaulting and
+// validating webhooks for KlausTrigger.
+func SetupKlausTriggerWebhookWithManager(mgr ctrl.Manager, w *KlausTriggerOwners) error {
+	return ctrl.NewWebhookManagedBy(mgr, &klausv1alpha1.KlausTrigger{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets an unset permission mode of the job template to the default
+// for the requester's groups.
+func (w *KlausTriggerOwners) Default(ctx context.Context, trigger *klausv1alpha1.KlausTrigger) error {
+	if trigger.Spec.Job == nil {
+		return nil
+	}
+	return defaultPermissionMode(ctx, w.Policy, w.TrustedUsers, &trigger.Spec.Job.JobTemplate.Claude)
+}
+
+// ValidateCreate rejects triggers of another owner than the requester and
+// job templates with permission modes the requester's groups may not use.
+func (w *KlausTriggerOwners) ValidateCreate(ctx context.Context, trigger *klausv1alpha1.KlausTrigger) (admission.Warnings, error) {
+	if err := w.check(ctx, trigger); err != nil {
+		return nil, err
+	}
+	return nil, w.checkPermissions(ctx, trigger)
+}
+
+// ValidateUpdate applies the owner check to every spec change: changing the
+// prompt or action of another owner's trigger acts as that owner too.
+// Metadata-only updates, e.g. finalizer removals, are allowed. The
+// permission policy applies when the job template's permission mode or its
+// override change.
+func (w *KlausTriggerOwners) ValidateUpdate(ctx context.Context, oldTrigger, trigger *klausv1alpha1.KlausTrigger) (admission.Warnings, error) {
+	if equality.Semantic.DeepEqual(oldTrigger.Spec, trigger.Spec) {
+		return nil, nil
+	}
+	if oldTrigger.Spec.Owner != trigger.Spec.Owner {
+		// Taking over a trigger needs the old owner's consent as well.
+		if err := w.check(ctx, oldTrigger); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.check(ctx, trigger); err != nil {
+		return nil, err
+	}
+	if !permissionModeChanged(triggerClaude(oldTrigger), triggerClaude(trigger)) {
+		return nil, nil
+	}
+	return nil, w.checkPermissions(ctx, trigger)
+}
+
+// ValidateDelete allows all deletions.
+func (w *KlausTriggerOwners) ValidateDelete(context.Context, *klausv1alpha1.KlausTrigger) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (w *KlausTriggerOwners) check(ctx context.Context, trigger *klausv1alpha1.KlausTrigger) error {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("reading admission request: %w", err)
+	}
+	username := req.UserInfo.Username
+	if slices.Contains(w.TrustedUsers, username) {
+		return nil
+	}
+	if user, ok := strings.CutPrefix(username, w.UsernamePrefix); ok && user == trigger.Spec.Owner {
+		return nil
+	}
+	return apierrors.NewInvalid(klausv1alpha1.GroupVersion.WithKind("KlausTrigger").GroupKind(), trigger.Name, field.ErrorList{
+		field.Forbidden(field.NewPath("spec", "owner"),
+			fmt.Sprintf("%s may not create or change triggers acting for %s", username, trigger.Spec.Owner)),
+	})
+}
+
+func (w *KlausTriggerOwners) checkPermissions(ctx context.Context, trigger *klausv1alpha1.KlausTrigger) error {
+	if trigger.Spec.Job == nil {
+		return nil
+	}
+	return checkPermissionMode(ctx, w.Policy, w.TrustedUsers, klausv1alpha1.GroupVersion.WithKind("KlausTrigger").GroupKind(),
+		trigger.Name, field.NewPath("spec", "job", "jobTemplate", "claude"), &trigger.Spec.Job.JobTemplate.Claude)
+}
+
+// triggerClaude returns the Claude configuration of the job template of a
+// trigger, empty for triggers prompting instances.
+func triggerClaude(trigger *klausv1alpha1.KlausTrigger) *klausv1alpha1.ClaudeConfig {
+	if trigger.Spec.Job == nil {
+		return &klausv1alpha1.ClaudeConfig{}
+	}
+	return &trigger.Spec.Job.JobTemplate.Claude
+}
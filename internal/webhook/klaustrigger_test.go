@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+)
+
+func testTrigger(owner string) *klausv1alpha1.KlausTrigger {
+	return &klausv1alpha1.KlausTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: "review", Namespace: "team"},
+		Spec: klausv1alpha1.KlausTriggerSpec{
+			Owner:          owner,
+			PromptTemplate: "Review {{.payload.url}}",
+			Instance:       &klausv1alpha1.TriggerInstanceAction{Name: "reviewer"},
+		},
+	}
+}
+
+func TestKlausTriggerValidateCreate(t *testing.T) {
+	w := &KlausTriggerOwners{UsernamePrefix: "oidc:", TrustedUsers: []string{operatorUser}}
+	tests := []struct {
+		name     string
+		username string
+		wantErr  bool
+	}{
+		{name: "owner", username: "oidc:user@example.com"},
+		{name: "trusted user", username: operatorUser},
+		{name: "other user", username: "oidc:other@example.com", wantErr: true},
+		{name: "owner without the prefix", username: "user@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := w.ValidateCreate(requestCtx(tt.username), testTrigger("user@example.com"))
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("error = %v, want Invalid", err)
+			}
+		})
+	}
+}
+
+func TestKlausTriggerValidateUpdate(t *testing.T) {
+	w := &KlausTriggerOwners{}
+	old := testTrigger("user@example.com")
+
+	// Metadata changes, e.g. by a controller removing a finalizer, pass.
+	relabeled := old.DeepCopy()
+	relabeled.Labels = map[string]string{"team": "a"}
+	if _, err := w.ValidateUpdate(requestCtx("other@example.com"), old, relabeled); err != nil {
+		t.Errorf("metadata update error = %v, want allowed", err)
+	}
+
+	changed := old.DeepCopy()
+	changed.Spec.PromptTemplate = "Delete everything"
+	if _, err := w.ValidateUpdate(requestCtx("other@example.com"), old, changed); err == nil {
+		t.Error("expected another user changing the trigger to be rejected")
+	}
+	if _, err := w.ValidateUpdate(requestCtx("user@example.com"), old, changed); err != nil {
+		t.Errorf("owner update error = %v, want allowed", err)
+	}
+
+	takenOver := old.DeepCopy()
+	takenOver.Spec.Owner = "other@example.com"
+	if _, err := w.ValidateUpdate(requestCtx("other@example.com"), old, takenOver); err == nil {
+		t.Error("expected taking over another owner's trigger to be rejected")
+	}
+	if _, err := w.ValidateUpdate(requestCtx("user@example.com"), old, takenOver); err == nil {
+		t.Error("expected handing a trigger to another owner to be rejected")
+	}
+}
+
+func TestKlausTriggerPermissions(t *testing.T) {
+	w := &KlausTriggerOwners{Policy: testPermissions().Policy}
+	ctx := requestCtx("user@example.com", "dev")
+
+	trigger := testTrigger("user@example.com")
+	trigger.Spec.Instance = nil
+	trigger.Spec.Job = &klausv1alpha1.TriggerJobAction{}
+	if err := w.Default(ctx, trigger); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode := trigger.Spec.Job.JobTemplate.Claude.PermissionMode; mode != klausv1alpha1.PermissionModeDefault {
+		t.Errorf("PermissionMode = %q, want the group default", mode)
+	}
+	if _, err := w.ValidateCreate(ctx, trigger); err != nil {
+		t.Errorf("unexpected error for an allowed mode: %v", err)
+	}
+
+	bypass := trigger.DeepCopy()
+	bypass.Spec.Job.JobTemplate.Claude.PermissionMode = klausv1alpha1.PermissionModeBypass
+	if _, err := w.ValidateCreate(ctx, bypass); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid for a mode above the maximum", err)
+	}
+	if _, err := w.ValidateUpdate(ctx, trigger, bypass); !apierrors.IsInvalid(err) {
+		t.Errorf("error = %v, want Invalid when raising the mode", err)
+	}
+
+	// Triggers prompting instances have no job template to check.
+	if _, err := w.ValidateCreate(ctx, testTrigger("user@example.com")); err != nil {
+		t.Errorf("unexpected error for an instance trigger: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+)
+
+// decide evaluates the policy, or the default policy when nil, for the user
+// making the admission request. It reports trusted requesters, which are
+// exempt from the policy.
+func decide(ctx context.Context, policy *permissions.Policy, trustedUsers []string) (permissions.Decision, bool, error) {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return permissions.Decision{}, false, fmt.Errorf("reading admission request: %w", err)
+	}
+	if slices.Contains(trustedUsers, req.UserInfo.Username) {
+		return permissions.Decision{}, true, nil
+	}
+	if policy == nil {
+		policy = permissions.DefaultPolicy()
+	}
+	return policy.Decide(req.UserInfo.Groups), false, nil
+}
+
+// defaultPermissionMode sets an unset permission mode of claude to the
+// default for the requester's groups.
+func defaultPermissionMode(ctx context.Context, policy *permissions.Policy, trustedUsers []string, claude *klausv1alpha1.ClaudeConfig) error {
+	decision, trusted, err := decide(ctx, policy, trustedUsers)
+	if err != nil || trusted {
+		return err
+	}
+	decision.ApplyDefault(claude)
+	return nil
+}
+
+// checkPermissionMode rejects a permission mode of claude, found at path of
+// the named resource of kind, that the requester's groups may not use.
+func checkPermissionMode(ctx context.Context, policy *permissions.Policy, trustedUsers []string,
+	kind schema.GroupKind, name string, path *field.Path, claude *klausv1alpha1.ClaudeConfig) error {
+	decision, trusted, err := decide(ctx, policy, trustedUsers)
+	if err != nil || trusted {
+		return err
+	}
+	if err := decision.Check(claude); err != nil {
+		return apierrors.NewInvalid(kind, name, field.ErrorList{
+			field.Forbidden(path.Child("permissionMode"), err.Error()),
+		})
+	}
+	return nil
+}
+
+// permissionModeChanged reports whether an update changes the permission
+// mode or its override. The policy is only applied to such updates, so
+// resources created before a policy existed can still be edited.
+func permissionModeChanged(oldClaude, claude *klausv1alpha1.ClaudeConfig) bool {
+	return oldClaude.PermissionMode != claude.PermissionMode ||
+		oldClaude.PermissionModeOverride != claude.PermissionModeOverride
+}
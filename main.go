@@ -2,24 +2,52 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 
 	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	klausv1alpha2 "github.com/giantswarm/klaus-operator/api/v1alpha2"
+	"github.com/giantswarm/klaus-operator/internal/audit"
 	"github.com/giantswarm/klaus-operator/internal/controller"
+	"github.com/giantswarm/klaus-operator/internal/cosign"
+	"github.com/giantswarm/klaus-operator/internal/githubapp"
 	"github.com/giantswarm/klaus-operator/internal/mcp"
+	"github.com/giantswarm/klaus-operator/internal/metrics"
+	"github.com/giantswarm/klaus-operator/internal/oci"
+	"github.com/giantswarm/klaus-operator/internal/oidc"
+	"github.com/giantswarm/klaus-operator/internal/permissions"
+	"github.com/giantswarm/klaus-operator/internal/pluginsync"
+	"github.com/giantswarm/klaus-operator/internal/registrypolicy"
 	"github.com/giantswarm/klaus-operator/internal/resources"
+	"github.com/giantswarm/klaus-operator/internal/trigger"
+	"github.com/giantswarm/klaus-operator/internal/upgrade"
+	"github.com/giantswarm/klaus-operator/internal/usage"
+	"github.com/giantswarm/klaus-operator/internal/webhook"
 	"github.com/giantswarm/klaus-operator/pkg/project"
 )
 
@@ -31,28 +59,258 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(klausv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(klausv1alpha2.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 }
 
+// auditOTLPHeadersEnv holds the audit log export headers carrying
+// credentials, so they stay out of the operator's command line and pod spec.
+const auditOTLPHeadersEnv = "AUDIT_OTLP_HEADERS"
+
 func main() {
+	// Plugin sync Jobs run the operator image with the sync-plugins
+	// subcommand.
+	if len(os.Args) > 1 && os.Args[1] == resources.PluginSyncCommand {
+		os.Exit(pluginsync.Main(ctrl.SetupSignalHandler(), os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	var (
-		metricsAddr          string
-		probeAddr            string
-		mcpAddr              string
-		enableLeaderElection bool
-		klausImage           string
-		gitCloneImage        string
-		anthropicKeySecret   string
-		anthropicKeyNs       string
+		metricsAddr             string
+		metricsOwnerLabel       bool
+		probeAddr               string
+		mcpAddr                 string
+		triggerAddr             string
+		enableLeaderElection    bool
+		shards                  int
+		klausImage              string
+		mockImage               string
+		gitCloneImage           string
+		anthropicKeySecret      string
+		anthropicKeyNs          string
+		ociCacheDir             string
+		ociCacheSize            int
+		ociCacheTTL             time.Duration
+		offline                 bool
+		offlineFailOpen         bool
+		fleetStatusInterval     time.Duration
+		usageInterval           time.Duration
+		tokenUsageInterval      time.Duration
+		workspaceStatusInterval time.Duration
+		resultInterval          time.Duration
+		prometheusURL           string
+		gvisorRuntimeClass      string
+		kataRuntimeClass        string
+		strictRuntimeClass      string
+		strictSeccompProfile    string
+		ownerClusterRole        string
+		ownerSubjectPrefix      string
+		tenantClusterRole       string
+		kubeAccessClusterRoles  string
+		namespaceScoped         bool
+		permissionPolicyFile    string
+		verificationPolicyFile  string
+		registryPolicyFile      string
+		mcpServerNamespaces     string
+		adminUsers              string
+		oidcIssuerURL           string
+		oidcAudiences           string
+		oidcUsernameClaims      string
+		oidcGroupsClaim         string
+		oidcKeyCacheTTL         time.Duration
+		trustGatewayTokens      bool
+		mcpTLSCertFile          string
+		mcpTLSKeyFile           string
+		mcpTLSClientCAFile      string
+		mcpReadTimeout          time.Duration
+		mcpWriteTimeout         time.Duration
+		mcpIdleTimeout          time.Duration
+		mcpMaxRequestBytes      int64
+		mcpRateLimit            int
+		mcpRateBurst            int
+		maxInstancesPerOwner    int
+		auditLogPath            string
+		auditOTLPEndpoint       string
+		auditOTLPHeaders        string
+		pluginSource            string
+		pluginPVCStorageClass   string
+		pluginPVCSize           string
+		pluginSyncImage         string
+		personalityRolloutBatch string
+		otelCollectorImage      string
+		otelCollectorConfig     string
+		otelCollectorSecret     string
+		gitHubAppID             int64
+		gitHubAppKeyFile        string
+		gitHubAppRepoPolicy     string
+		gitHubAPIURL            string
+		gitHubAppTokenInterval  time.Duration
+		pluginRefreshInterval   time.Duration
+		personalityResync       time.Duration
+		mcpServerProbeInterval  time.Duration
+		orphanSweepInterval     time.Duration
+		gateway                 string
+		maxConcurrentReconciles string
+		rateLimiterBaseDelay    time.Duration
+		rateLimiterMaxDelay     time.Duration
+		rateLimiterQPS          float64
+		rateLimiterBurst        int
+		cacheManagedOnly        bool
+		enableWebhooks          bool
+		webhookPort             int
+		webhookService          string
+		webhookCertificate      string
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&metricsOwnerLabel, "metrics-owner-label", false,
+		"Label klaus_operator_instances with the instance owner. Adds series per owner, so the cardinality grows with the users.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&mcpAddr, "mcp-bind-address", ":9090", "The address the MCP server binds to.")
+	flag.StringVar(&triggerAddr, "trigger-bind-address", "",
+		"The address the KlausTrigger event listener binds to (disabled when empty).")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+	flag.IntVar(&shards, "shards", 1,
+		"Number of shards KlausInstances are split into by the hash of their namespace and name. Each replica reconciles the instances of the shard Leases it holds; requires --leader-elect above 1.")
 	flag.StringVar(&klausImage, "klaus-image", "gsoci.azurecr.io/giantswarm/klaus:latest", "The Klaus container image to use for instances.")
+	flag.StringVar(&mockImage, "mock-image", "gsoci.azurecr.io/giantswarm/klaus-mock:latest",
+		"The mock agent container image used by instances with spec.mockMode set.")
 	flag.StringVar(&gitCloneImage, "git-clone-image", resources.DefaultGitCloneImage, "The git clone image for workspace init containers.")
 	flag.StringVar(&anthropicKeySecret, "anthropic-key-secret", "anthropic-api-key", "Name of the Secret containing the Anthropic API key.")
 	flag.StringVar(&anthropicKeyNs, "anthropic-key-namespace", "", "Namespace of the Anthropic API key Secret (defaults to operator namespace).")
+	flag.StringVar(&ociCacheDir, "oci-cache-dir", "", "Directory for the on-disk OCI registry cache (disabled when empty).")
+	flag.IntVar(&ociCacheSize, "oci-cache-size", oci.DefaultCacheSize,
+		"Maximum number of personality, toolchain and plugin resolutions kept in memory, least recently used first out (disabled when 0).")
+	flag.DurationVar(&ociCacheTTL, "oci-cache-ttl", oci.DefaultCacheTTL,
+		"How long an in-memory OCI resolution, e.g. of a tag to its digest, is used before the registry is asked again (disabled when 0).")
+	flag.BoolVar(&offline, "offline", false,
+		"Never contact an OCI registry, for air-gapped clusters. References with a version tag or digest are used as written and their images must be pre-pulled or mirrored; instances needing a registry lookup fail with the RegistryOffline reason.")
+	flag.BoolVar(&offlineFailOpen, "offline-fail-open", false,
+		"With --offline, use the references that need a registry lookup as written instead of failing: :latest and untagged references stay unresolved, tags unpinned, personalities run without their soul, with a Degraded condition. References covered by the verification policy still fail.")
+	flag.DurationVar(&fleetStatusInterval, "fleet-status-interval", controller.DefaultFleetStatusInterval,
+		"How often the KlausFleetStatus singleton is refreshed (0 disables it).")
+	flag.DurationVar(&usageInterval, "resource-usage-interval", controller.DefaultResourceUsageInterval,
+		"How often KlausInstance status.resourceUsage is refreshed (0 disables it).")
+	flag.DurationVar(&tokenUsageInterval, "token-usage-interval", controller.DefaultTokenUsageInterval,
+		"How often KlausInstance status.tokenUsage and the KlausUsageReports are refreshed from --prometheus-url (0 disables it).")
+	flag.DurationVar(&workspaceStatusInterval, "workspace-status-interval", controller.DefaultWorkspaceStatusInterval,
+		"How often KlausInstance status.workspace is refreshed (0 disables it).")
+	flag.DurationVar(&resultInterval, "result-capture-interval", controller.DefaultResultInterval,
+		"How often the results of agent mode instances are recorded in KlausInstance status.result (0 disables it).")
+	flag.StringVar(&prometheusURL, "prometheus-url", "",
+		"Prometheus server queried for workspace volume usage, CPU throttling and token usage (disabled when empty).")
+	flag.StringVar(&gvisorRuntimeClass, "sandbox-gvisor-runtime-class", "gvisor", "RuntimeClass used by the gvisor sandbox preset.")
+	flag.StringVar(&kataRuntimeClass, "sandbox-kata-runtime-class", "kata", "RuntimeClass used by the kata sandbox preset.")
+	flag.StringVar(&strictRuntimeClass, "sandbox-strict-runtime-class", "gvisor", "RuntimeClass used by the strict sandbox preset.")
+	flag.StringVar(&strictSeccompProfile, "sandbox-strict-seccomp-profile", "",
+		"Localhost seccomp profile used by the strict sandbox preset (defaults to the runtime default profile).")
+	flag.StringVar(&ownerClusterRole, "owner-cluster-role", "",
+		"ClusterRole bound to each instance owner in their namespace (no RoleBinding is created when empty).")
+	flag.StringVar(&ownerSubjectPrefix, "owner-subject-prefix", "",
+		"Prefix for the owner in RoleBinding subjects, matching the API server's OIDC username prefix.")
+	flag.StringVar(&tenantClusterRole, "tenant-cluster-role", "",
+		"ClusterRole bound to a tenant ServiceAccount in each user namespace; when set, the controllers write child resources in user namespaces impersonating it (disabled when empty).")
+	flag.StringVar(&kubeAccessClusterRoles, "kubernetes-access-cluster-roles", "",
+		"Comma-separated ClusterRoles spec.kubernetesAccess.clusterRole may grant to instance ServiceAccounts (none when empty).")
+	flag.BoolVar(&namespaceScoped, "namespace-scoped", false,
+		"Create the child resources of instances in the instance's own namespace, owned by it, instead of operator-managed per-owner user namespaces.")
+	flag.StringVar(&permissionPolicyFile, "permission-policy-file", "",
+		"YAML file mapping groups to the default and maximum permissionMode (bypassPermissions for everyone when empty).")
+	flag.StringVar(&verificationPolicyFile, "artifact-verification-policy-file", "",
+		"YAML file with the cosign keys and keyless identities trusted to sign personality, toolchain and plugin artifacts per registry prefix (verification disabled when empty).")
+	flag.StringVar(&registryPolicyFile, "registry-policy-file", "",
+		"YAML file with the registries and repositories images, personalities and plugins may (allow) and may not (deny) come from (every registry allowed when empty).")
+	flag.StringVar(&mcpServerNamespaces, "mcp-server-namespaces", "",
+		"Comma-separated namespaces whose KlausMCPServers instances in other namespaces may reference, e.g. a central catalog.")
+	flag.StringVar(&adminUsers, "admin-users", "",
+		"Comma-separated user identities allowed to use the admin MCP tools, in addition to the permission policy's adminGroups.")
+	flag.StringVar(&oidcIssuerURL, "oidc-issuer-url", "",
+		"OIDC issuer whose signing keys verify the tokens of MCP callers.")
+	flag.StringVar(&oidcAudiences, "oidc-audience", "",
+		"Comma-separated audiences accepted in the aud claim of MCP caller tokens; required with --oidc-issuer-url.")
+	flag.StringVar(&oidcUsernameClaims, "oidc-username-claims", strings.Join(oidc.DefaultUsernameClaims, ","),
+		"Comma-separated claims the MCP caller's username is read from; the first non-empty one wins.")
+	flag.StringVar(&oidcGroupsClaim, "oidc-groups-claim", "",
+		"Claim the MCP caller's groups are read from (defaults to the permission policy's groupsClaim).")
+	flag.DurationVar(&oidcKeyCacheTTL, "oidc-key-cache-ttl", oidc.DefaultKeyCacheTTL,
+		"How long the issuer's signing keys are cached before they are fetched again.")
+	flag.BoolVar(&trustGatewayTokens, "mcp-trust-gateway-tokens", false,
+		"Decode MCP caller tokens unverified, trusting the gateway forwarding them to have verified them; without it and without --oidc-issuer-url every MCP call is rejected.")
+	flag.StringVar(&mcpTLSCertFile, "mcp-tls-cert-file", "",
+		"PEM serving certificate of the MCP server; with --mcp-tls-key-file the server only accepts TLS (plaintext when empty).")
+	flag.StringVar(&mcpTLSKeyFile, "mcp-tls-key-file", "", "PEM private key of --mcp-tls-cert-file.")
+	flag.StringVar(&mcpTLSClientCAFile, "mcp-tls-client-ca-file", "",
+		"PEM bundle of CAs MCP client certificates must be signed by; clients without one are rejected (disabled when empty).")
+	flag.DurationVar(&mcpReadTimeout, "mcp-read-timeout", mcp.DefaultReadTimeout, "Maximum duration for reading an MCP request.")
+	flag.DurationVar(&mcpWriteTimeout, "mcp-write-timeout", 0,
+		"Maximum duration for writing an MCP response (unlimited when 0, so event streams of long tool calls stay open).")
+	flag.DurationVar(&mcpIdleTimeout, "mcp-idle-timeout", mcp.DefaultIdleTimeout, "How long idle MCP keep-alive connections are kept open.")
+	flag.Int64Var(&mcpMaxRequestBytes, "mcp-max-request-bytes", mcp.DefaultMaxRequestBytes,
+		"Maximum size of MCP request bodies in bytes (unlimited when 0).")
+	flag.IntVar(&mcpRateLimit, "mcp-rate-limit", 0,
+		"MCP tool calls per minute allowed per user, refilling a token bucket (unlimited when 0); KlausTenant spec.mcp overrides it per owner.")
+	flag.IntVar(&mcpRateBurst, "mcp-rate-burst", 0,
+		"Token bucket size of --mcp-rate-limit (defaults to the rate).")
+	flag.IntVar(&maxInstancesPerOwner, "max-instances-per-owner", 0,
+		"Instances that are not stopped an owner may have before creating and starting more is rejected, at admission and through MCP (unlimited when 0); KlausTenant spec.maxInstances overrides it per owner.")
+	flag.StringVar(&auditLogPath, "audit-log-path", "",
+		"File the audit log of MCP tool calls and controller actions is appended to as JSON lines, or - for standard output (disabled when empty).")
+	flag.StringVar(&auditOTLPEndpoint, "audit-otlp-endpoint", "",
+		"OTLP/HTTP logs URL the audit log is exported to, e.g. http://collector:4318/v1/logs (disabled when empty).")
+	flag.StringVar(&auditOTLPHeaders, "audit-otlp-headers", "",
+		"Comma-separated key=value headers sent with audit log exports. Headers carrying credentials belong in the "+auditOTLPHeadersEnv+" environment variable, set from a Secret, which has the same format and takes precedence.")
+	flag.StringVar(&pluginSource, "plugin-source", resources.PluginSourceImage,
+		"How plugins reach instance pods: image (OCI image volumes), pvc (sub-paths of a shared PVC synced by the operator), init (pulled by an init container into an emptyDir) or auto (image when the API server accepts image volumes, init otherwise).")
+	flag.StringVar(&pluginPVCStorageClass, "plugin-pvc-storage-class", "",
+		"ReadWriteMany StorageClass of the shared plugins PVC with --plugin-source=pvc (cluster default when empty).")
+	flag.StringVar(&pluginPVCSize, "plugin-pvc-size", resources.DefaultPluginsPVCSize,
+		"Size of the shared plugins PVC with --plugin-source=pvc.")
+	flag.StringVar(&pluginSyncImage, "plugin-sync-image", "",
+		"Image of the plugin sync Jobs with --plugin-source=pvc and of the plugin pull init containers with init or auto, normally the operator image.")
+	flag.DurationVar(&pluginRefreshInterval, "plugin-refresh-interval", controller.DefaultPluginRefreshInterval,
+		"How often the plugin tags of instances with spec.pluginRefreshPolicy Periodic are resolved to their current digest.")
+	flag.DurationVar(&personalityResync, "personality-resync-interval", controller.DefaultPersonalityResyncInterval,
+		"How often the personalities of instances with spec.personalityRefreshPolicy Periodic are re-resolved to their current version and digest.")
+	flag.DurationVar(&mcpServerProbeInterval, "mcp-server-probe-interval", controller.DefaultMCPServerProbeInterval,
+		"How often the URLs of HTTP-based KlausMCPServers are probed for the Reachable condition. Zero disables probing.")
+	flag.DurationVar(&orphanSweepInterval, "orphan-sweep-interval", controller.DefaultOrphanSweepInterval,
+		"How often child resources of KlausInstances and KlausJobs that no longer exist are deleted (0 disables it).")
+	flag.StringVar(&gateway, "gateway", string(klausv1alpha1.GatewayTypeMuster),
+		"MCP gateway backend of instances without spec.gateway.type: muster for a muster MCPServer, service for discovery annotations on the instance Service, or none.")
+	flag.StringVar(&personalityRolloutBatch, "personality-rollout-batch", "",
+		"How many instances sharing a personality roll forward to a new revision at once, as a count or a percentage (all at once when empty).")
+	flag.StringVar(&otelCollectorImage, "otel-collector-image", "otel/opentelemetry-collector-contrib:0.137.0",
+		"The OpenTelemetry Collector sidecar image used with --otel-collector-config.")
+	flag.StringVar(&otelCollectorConfig, "otel-collector-config", "",
+		"ConfigMap in the operator namespace whose config.yaml configures a collector sidecar for instances with telemetry enabled but no OTLP endpoint (disabled when empty).")
+	flag.StringVar(&otelCollectorSecret, "otel-collector-secret", "",
+		"Secret in the operator namespace passed to the collector sidecar as environment variables, e.g. export credentials.")
+	flag.Int64Var(&gitHubAppID, "github-app-id", 0,
+		"ID of the GitHub App minting installation tokens for workspaces with spec.workspace.gitHubApp (disabled when 0).")
+	flag.StringVar(&gitHubAppKeyFile, "github-app-private-key-file", "", "PEM private key of --github-app-id.")
+	flag.StringVar(&gitHubAppRepoPolicy, "github-app-repository-policy-file", "",
+		"YAML file listing the repositories each instance owner may have --github-app-id mint tokens for (none when empty).")
+	flag.StringVar(&gitHubAPIURL, "github-api-url", githubapp.DefaultBaseURL,
+		"GitHub REST API endpoint of --github-app-id and for the pull requests of spec.workspace.output, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server.")
+	flag.DurationVar(&gitHubAppTokenInterval, "github-app-token-interval", controller.DefaultGitHubAppTokenInterval,
+		"How often GitHub App installation tokens of instances are checked and replaced before they expire.")
+	flag.StringVar(&maxConcurrentReconciles, "max-concurrent-reconciles", "",
+		"Comma-separated worker counts of the controllers: name=N for one of klausinstance, klausjob, klausmcpserver, klauscronjob, klaustrigger, klausskillpack or klausfleetoperation, a bare N for the others, e.g. 2,klausinstance=8 (one each when empty).")
+	flag.DurationVar(&rateLimiterBaseDelay, "rate-limiter-base-delay", controller.DefaultRateLimiterBaseDelay,
+		"Initial requeue delay of an object whose reconcile failed, doubled on every further failure.")
+	flag.DurationVar(&rateLimiterMaxDelay, "rate-limiter-max-delay", controller.DefaultRateLimiterMaxDelay,
+		"Maximum requeue delay of an object whose reconciles keep failing.")
+	flag.Float64Var(&rateLimiterQPS, "rate-limiter-qps", controller.DefaultRateLimiterQPS,
+		"Overall rate at which each controller's work queue hands out objects.")
+	flag.IntVar(&rateLimiterBurst, "rate-limiter-burst", controller.DefaultRateLimiterBurst,
+		"Burst of each controller's work queue above --rate-limiter-qps.")
+	flag.BoolVar(&cacheManagedOnly, "cache-managed-only", false,
+		"Only cache Secrets and ConfigMaps labeled app.kubernetes.io/managed-by=klaus-operator; others are read from the API server.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the KlausInstance and KlausJob admission webhooks.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the admission webhook server listens on.")
+	flag.StringVar(&webhookService, "webhook-service", "",
+		"Name of the Service in the operator namespace fronting the webhook server. When set with --enable-webhooks, the CRDs serving several versions are pointed at its conversion webhook.")
+	flag.StringVar(&webhookCertificate, "webhook-certificate", "",
+		"Name of the cert-manager Certificate of the webhook server in the operator namespace, whose CA the cert-manager CA injector keeps in the CRD conversion configuration.")
 
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
@@ -60,14 +318,180 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	permissionPolicy, err := permissions.LoadPolicy(permissionPolicyFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load permission policy")
+		os.Exit(1)
+	}
+
+	// Artifacts covered by the verification policy are only mounted with a
+	// trusted cosign signature.
+	verificationPolicy, err := cosign.LoadPolicy(verificationPolicyFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load artifact verification policy")
+		os.Exit(1)
+	}
+	if offlineFailOpen && !offline {
+		setupLog.Error(errors.New("--offline-fail-open requires --offline"), "invalid offline configuration")
+		os.Exit(1)
+	}
+	var artifactVerifier controller.ArtifactVerifier
+	switch {
+	case verificationPolicy == nil:
+	case offline:
+		// Fail-open only leaves tags unpinned: references the policy covers
+		// still fail with ErrOffline.
+		artifactVerifier = cosign.NewOfflineVerifier(verificationPolicy, oci.ErrOffline)
+	default:
+		artifactVerifier = cosign.NewVerifier(verificationPolicy)
+	}
+
+	registryPolicy, err := registrypolicy.LoadPolicy(registryPolicyFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load registry policy")
+		os.Exit(1)
+	}
+
+	// Owner RoleBindings and tenant ServiceAccounts are per user namespace.
+	if namespaceScoped && (ownerClusterRole != "" || tenantClusterRole != "") {
+		setupLog.Error(errors.New("--owner-cluster-role and --tenant-cluster-role require user namespaces"),
+			"invalid --namespace-scoped configuration")
+		os.Exit(1)
+	}
+
+	// The other controllers still run on the leader only.
+	if shards < 1 || (shards > 1 && !enableLeaderElection) {
+		setupLog.Error(errors.New("--shards must be 1, or above 1 with --leader-elect"), "invalid --shards")
+		os.Exit(1)
+	}
+
+	var pluginPVC *resources.PluginPVCOptions
+	switch pluginSource {
+	case resources.PluginSourceImage:
+	case resources.PluginSourcePVC:
+		size, err := resource.ParseQuantity(pluginPVCSize)
+		if err != nil {
+			setupLog.Error(err, "invalid --plugin-pvc-size")
+			os.Exit(1)
+		}
+		if pluginSyncImage == "" {
+			setupLog.Error(errors.New("--plugin-sync-image is required"), "invalid plugin source configuration")
+			os.Exit(1)
+		}
+		pluginPVC = &resources.PluginPVCOptions{StorageClass: pluginPVCStorageClass, Size: size, SyncImage: pluginSyncImage}
+	case resources.PluginSourceInit, resources.PluginSourceAuto:
+		if pluginSyncImage == "" {
+			setupLog.Error(errors.New("--plugin-sync-image is required"), "invalid plugin source configuration")
+			os.Exit(1)
+		}
+	default:
+		setupLog.Error(fmt.Errorf("unknown plugin source %q", pluginSource), "invalid --plugin-source")
+		os.Exit(1)
+	}
+
+	defaultGateway, err := controller.ParseGatewayType(gateway)
+	if err != nil {
+		setupLog.Error(err, "invalid --gateway")
+		os.Exit(1)
+	}
+
+	var rolloutBatch *intstr.IntOrString
+	if personalityRolloutBatch != "" {
+		batch := intstr.Parse(personalityRolloutBatch)
+		if _, err := intstr.GetScaledValueFromIntOrPercent(&batch, 100, true); err != nil {
+			setupLog.Error(err, "invalid --personality-rollout-batch")
+			os.Exit(1)
+		}
+		rolloutBatch = &batch
+	}
+
+	var telemetryCollector *resources.TelemetryCollector
+	if otelCollectorConfig != "" {
+		telemetryCollector = &resources.TelemetryCollector{
+			Image:         otelCollectorImage,
+			ConfigMapName: otelCollectorConfig,
+			SecretName:    otelCollectorSecret,
+		}
+	}
+
+	// The interface stays nil without an app, so that instances using
+	// spec.workspace.gitHubApp report the missing configuration.
+	var gitHubApp controller.GitHubTokenMinter
+	if gitHubAppID != 0 {
+		key, err := os.ReadFile(gitHubAppKeyFile)
+		if err != nil {
+			setupLog.Error(err, "unable to read --github-app-private-key-file")
+			os.Exit(1)
+		}
+		policy, err := githubapp.LoadRepositoryPolicy(gitHubAppRepoPolicy)
+		if err != nil {
+			setupLog.Error(err, "invalid --github-app-repository-policy-file")
+			os.Exit(1)
+		}
+		if policy == nil {
+			setupLog.Info("no --github-app-repository-policy-file: no GitHub App installation tokens are minted")
+		}
+		app, err := githubapp.NewClient(gitHubAppID, key, gitHubAPIURL, policy)
+		if err != nil {
+			setupLog.Error(err, "invalid GitHub App configuration")
+			os.Exit(1)
+		}
+		gitHubApp = app
+	}
+
+	var auditSinks []audit.Sink
+	if auditLogPath != "" {
+		sink, err := audit.NewFileSink(auditLogPath)
+		if err != nil {
+			setupLog.Error(err, "invalid --audit-log-path")
+			os.Exit(1)
+		}
+		auditSinks = append(auditSinks, sink)
+	}
+	if auditOTLPEndpoint != "" {
+		headers, err := parseHeaders(auditOTLPHeaders + "," + os.Getenv(auditOTLPHeadersEnv))
+		if err != nil {
+			setupLog.Error(err, "invalid --audit-otlp-headers or "+auditOTLPHeadersEnv)
+			os.Exit(1)
+		}
+		auditSinks = append(auditSinks, audit.NewOTLPSink(auditOTLPEndpoint, "klaus-operator", headers))
+	}
+	var auditLogger *audit.Logger
+	if len(auditSinks) > 0 {
+		auditLogger = audit.NewLogger(auditSinks...)
+	}
+
+	controllerOptions := controller.ControllerOptions{
+		BaseDelay: rateLimiterBaseDelay,
+		MaxDelay:  rateLimiterMaxDelay,
+		QPS:       rateLimiterQPS,
+		Burst:     rateLimiterBurst,
+	}
+	if err := controllerOptions.ParseMaxConcurrentReconciles(maxConcurrentReconciles); err != nil {
+		setupLog.Error(err, "invalid --max-concurrent-reconciles")
+		os.Exit(1)
+	}
+
+	// Caching only the operator's own Secrets and ConfigMaps keeps the
+	// unrelated ones of large clusters out of memory.
+	var cacheOptions cache.Options
+	newClient := client.New
+	if cacheManagedOnly {
+		cacheOptions.ByObject = controller.ManagedCacheByObject()
+		newClient = controller.NewManagedCacheClient
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme: scheme,
+		Scheme:    scheme,
+		Cache:     cacheOptions,
+		NewClient: newClient,
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "klaus-operator.giantswarm.io",
+		WebhookServer:          ctrlwebhook.NewServer(ctrlwebhook.Options{Port: webhookPort}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create manager")
@@ -83,32 +507,268 @@ func main() {
 		anthropicKeyNs = operatorNamespace
 	}
 
-	// Register field indexer for efficient MCP server reference lookups.
+	// Refuse to start against CRDs this build does not understand. The API
+	// reader is uncached and usable before the manager starts.
 	ctx := context.Background()
+	if err := upgrade.CheckVersionSkew(ctx, mgr.GetAPIReader(), upgrade.ManagedCRDs); err != nil {
+		setupLog.Error(err, "incompatible CRD versions installed")
+		os.Exit(1)
+	}
+
+	// Fall back to pulling plugins with an init container when the API
+	// server does not accept image volumes. MCP server artifacts have no
+	// such fallback, so the API server is probed for them with the other
+	// plugin sources too; "image" asserts the support.
+	imageVolumes := true
+	if pluginSource != resources.PluginSourceImage {
+		supported, err := controller.ProbeImageVolumes(ctx, mgr.GetClient(), operatorNamespace, pluginSyncImage)
+		if err != nil {
+			setupLog.Error(err, "unable to probe image volume support")
+			os.Exit(1)
+		}
+		imageVolumes = supported
+	}
+	if pluginSource == resources.PluginSourceAuto {
+		pluginSource = resources.PluginSourceImage
+		if !imageVolumes {
+			pluginSource = resources.PluginSourceInit
+		}
+		setupLog.Info("selected plugin source", "source", pluginSource)
+	}
+	var pluginPullImage string
+	if pluginSource == resources.PluginSourceInit {
+		pluginPullImage = pluginSyncImage
+	}
+
+	// Register field indexer for efficient MCP server reference lookups.
 	if err := mgr.GetFieldIndexer().IndexField(ctx, &klausv1alpha1.KlausInstance{},
 		controller.MCPServerRefIndexField, controller.IndexMCPServerRefs); err != nil {
 		setupLog.Error(err, "unable to create field indexer", "field", controller.MCPServerRefIndexField)
 		os.Exit(1)
 	}
 
+	// Register field indexer for looking up instances by referenced skill
+	// packs.
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &klausv1alpha1.KlausInstance{},
+		controller.SkillPackRefIndexField, controller.IndexSkillPackRefs); err != nil {
+		setupLog.Error(err, "unable to create field indexer", "field", controller.SkillPackRefIndexField)
+		os.Exit(1)
+	}
+
+	// Register field indexer for looking up instances by their dependencies.
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &klausv1alpha1.KlausInstance{},
+		controller.DependsOnIndexField, controller.IndexDependsOn); err != nil {
+		setupLog.Error(err, "unable to create field indexer", "field", controller.DependsOnIndexField)
+		os.Exit(1)
+	}
+
 	// Create the OCI client for version resolution and artifact discovery.
 	// Credentials are resolved from the Docker config mounted into the
-	// operator container via Kubernetes (single pull secret).
-	ociClient := klausoci.NewClient()
+	// operator container via Kubernetes (single pull secret). Registry
+	// responses are cached on disk when --oci-cache-dir is set. Offline
+	// operators resolve the references that need no registry lookup only,
+	// do not inspect image platforms and offer no registry listings over
+	// MCP.
+	var (
+		ociClient         registryClient
+		artifactLister    mcp.ArtifactLister
+		platformInspector controller.PlatformInspector
+	)
+	if offline {
+		ociClient = &oci.OfflineClient{FailOpen: offlineFailOpen}
+	} else {
+		registry := klausoci.NewClient(klausoci.WithCache(ociCacheDir))
+		ociClient, artifactLister = registry, registry
+		platformInspector = controller.NewRegistryPlatformInspector()
+	}
+	// Reconciler resolutions are served from an in-memory cache bounded by
+	// --oci-cache-size and --oci-cache-ttl; the misses are observed in the
+	// OCI resolve histogram.
+	ociResolver := oci.NewCachingResolver(metrics.InstrumentOCIResolver(ociClient), ociCacheSize, ociCacheTTL)
+	// The souls of personality artifacts are pulled once per digest.
+	soulFetcher := oci.NewSoulFetcher(ociClient, oci.DefaultSoulCacheSize)
+
+	// Serve the operator and fleet metrics next to the controller-runtime
+	// defaults.
+	if err := metrics.Register(ctrlmetrics.Registry, mgr.GetClient(), metricsOwnerLabel); err != nil {
+		setupLog.Error(err, "unable to register metrics")
+		os.Exit(1)
+	}
+
+	// Create a Kubernetes clientset for pod log access and pods/exec (the
+	// controller-runtime client does not support these subresources).
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create Kubernetes clientset")
+		os.Exit(1)
+	}
+
+	// In impersonation mode, child resources in user namespaces are written
+	// as the namespace's tenant ServiceAccount.
+	childClient := mgr.GetClient()
+	if tenantClusterRole != "" {
+		childClient = controller.NewImpersonatingClient(mgr.GetClient(), mgr.GetConfig())
+	}
+
+	// Split the instances across the replicas by shard Lease.
+	var shardClaimer *controller.ShardClaimer
+	if shards > 1 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "unable to determine the shard Lease identity")
+			os.Exit(1)
+		}
+		shardClaimer = &controller.ShardClaimer{
+			Client:    clientset.CoordinationV1(),
+			Namespace: operatorNamespace,
+			Identity:  hostname + "_" + string(uuid.NewUUID()),
+			Shards:    shards,
+		}
+		if err := mgr.Add(shardClaimer); err != nil {
+			setupLog.Error(err, "unable to add shard claimer")
+			os.Exit(1)
+		}
+	}
 
 	// Set up the KlausInstance controller.
-	if err := (&controller.KlausInstanceReconciler{
-		Client:             mgr.GetClient(),
+	instanceReconciler := &controller.KlausInstanceReconciler{
+		Client:                       childClient,
+		Scheme:                       mgr.GetScheme(),
+		Recorder:                     audit.NewEventRecorder(mgr.GetEventRecorderFor("klausinstance-controller"), auditLogger, scheme, "klausinstance-controller"), //nolint:staticcheck
+		KlausImage:                   klausImage,
+		MockImage:                    mockImage,
+		GitCloneImage:                gitCloneImage,
+		AnthropicKeySecret:           anthropicKeySecret,
+		AnthropicKeyNs:               anthropicKeyNs,
+		OperatorNamespace:            operatorNamespace,
+		OCIClient:                    ociResolver,
+		CapabilityProber:             controller.NewHTTPCapabilityProber(),
+		ReadinessChecker:             controller.NewReadinessChecker(mgr.GetAPIReader(), controller.NewPodExecutor(mgr.GetConfig(), clientset.CoreV1())),
+		PlatformInspector:            platformInspector,
+		SandboxPresets:               resources.NewSandboxPresets(gvisorRuntimeClass, kataRuntimeClass, strictRuntimeClass, strictSeccompProfile),
+		OwnerClusterRole:             ownerClusterRole,
+		OwnerSubjectPrefix:           ownerSubjectPrefix,
+		DefaultPermission:            permissionPolicy.Default,
+		APIReader:                    mgr.GetAPIReader(),
+		PluginPVC:                    pluginPVC,
+		PluginPullImage:              pluginPullImage,
+		ImageVolumesUnsupported:      !imageVolumes,
+		PluginRefreshInterval:        pluginRefreshInterval,
+		PersonalityResyncInterval:    personalityResync,
+		PersonalityRolloutBatch:      rolloutBatch,
+		DefaultGateway:               defaultGateway,
+		TelemetryCollector:           telemetryCollector,
+		TenantClusterRole:            tenantClusterRole,
+		KubernetesAccessClusterRoles: splitList(kubeAccessClusterRoles),
+		NamespaceScoped:              namespaceScoped,
+		GitHubApp:                    gitHubApp,
+		ArtifactVerifier:             artifactVerifier,
+		SoulFetcher:                  soulFetcher,
+		RegistryPolicy:               registryPolicy,
+		MCPServerNamespaces:          splitList(mcpServerNamespaces),
+		Shards:                       shardClaimer,
+		ControllerOptions:            controllerOptions,
+	}
+	if err := instanceReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KlausInstance")
+		os.Exit(1)
+	}
+
+	// Default and enforce permissionMode for instances, jobs and the job
+	// templates of cron jobs and triggers created through the Kubernetes
+	// API. The operator's own requests are trusted: it enforces the policy
+	// for MCP callers itself and stamps out jobs from checked templates. The registry policy applies to
+	// every request, including those of the operator, as do the owner
+	// instance limits. Triggers may only be written by their owner.
+	if enableWebhooks {
+		trusted := []string{"system:serviceaccount:" + operatorNamespace + ":" + os.Getenv("SERVICE_ACCOUNT_NAME")}
+		if err := webhook.SetupKlausInstanceWebhookWithManager(mgr, &webhook.KlausInstancePermissions{
+			Policy:       permissionPolicy,
+			Registries:   registryPolicy,
+			TrustedUsers: trusted,
+			Limits: &webhook.InstanceLimits{
+				Reader:               mgr.GetAPIReader(),
+				Namespace:            operatorNamespace,
+				MaxInstancesPerOwner: maxInstancesPerOwner,
+			},
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KlausInstance")
+			os.Exit(1)
+		}
+		if err := webhook.SetupKlausJobWebhookWithManager(mgr, &webhook.KlausJobPolicies{
+			Policy:       permissionPolicy,
+			Registries:   registryPolicy,
+			TrustedUsers: trusted,
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KlausJob")
+			os.Exit(1)
+		}
+		if err := webhook.SetupKlausCronJobWebhookWithManager(mgr, &webhook.KlausCronJobPermissions{
+			Policy:       permissionPolicy,
+			TrustedUsers: trusted,
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KlausCronJob")
+			os.Exit(1)
+		}
+		if err := webhook.SetupKlausTriggerWebhookWithManager(mgr, &webhook.KlausTriggerOwners{
+			UsernamePrefix: ownerSubjectPrefix,
+			Policy:         permissionPolicy,
+			TrustedUsers:   trusted,
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KlausTrigger")
+			os.Exit(1)
+		}
+	}
+
+	// Set up the KlausJob controller.
+	if err := (&controller.KlausJobReconciler{
+		Client:             childClient,
 		Scheme:             mgr.GetScheme(),
-		Recorder:           mgr.GetEventRecorderFor("klausinstance-controller"), //nolint:staticcheck
+		Recorder:           audit.NewEventRecorder(mgr.GetEventRecorderFor("klausjob-controller"), auditLogger, scheme, "klausjob-controller"), //nolint:staticcheck
 		KlausImage:         klausImage,
 		GitCloneImage:      gitCloneImage,
 		AnthropicKeySecret: anthropicKeySecret,
 		AnthropicKeyNs:     anthropicKeyNs,
 		OperatorNamespace:  operatorNamespace,
-		OCIClient:          ociClient,
+		OCIClient:          ociResolver,
+		DefaultPermission:  permissionPolicy.Default,
+		APIReader:          mgr.GetAPIReader(),
+		PluginPVC:          pluginPVC,
+		PluginPullImage:    pluginPullImage,
+		TenantClusterRole:  tenantClusterRole,
+		NamespaceScoped:    namespaceScoped,
+		GitHubApp:          gitHubApp,
+		PullRequests:       githubapp.NewPullRequests(gitHubAPIURL),
+		ArtifactVerifier:   artifactVerifier,
+		SoulFetcher:        soulFetcher,
+		RegistryPolicy:     registryPolicy,
+		TelemetryCollector: telemetryCollector,
+		ControllerOptions:  controllerOptions,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "KlausInstance")
+		setupLog.Error(err, "unable to create controller", "controller", "KlausJob")
+		os.Exit(1)
+	}
+
+	// Set up the KlausCronJob controller.
+	if err := (&controller.KlausCronJobReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Recorder:          audit.NewEventRecorder(mgr.GetEventRecorderFor("klauscronjob-controller"), auditLogger, scheme, "klauscronjob-controller"), //nolint:staticcheck
+		APIReader:         mgr.GetAPIReader(),
+		ControllerOptions: controllerOptions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KlausCronJob")
+		os.Exit(1)
+	}
+
+	// Set up the KlausTrigger controller.
+	if err := (&controller.KlausTriggerReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		OperatorNamespace: operatorNamespace,
+		ControllerOptions: controllerOptions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KlausTrigger")
 		os.Exit(1)
 	}
 
@@ -116,13 +776,43 @@ func main() {
 	if err := (&controller.KlausMCPServerReconciler{
 		Client:            mgr.GetClient(),
 		Scheme:            mgr.GetScheme(),
-		Recorder:          mgr.GetEventRecorderFor("klausmcpserver-controller"), //nolint:staticcheck
+		Recorder:          audit.NewEventRecorder(mgr.GetEventRecorderFor("klausmcpserver-controller"), auditLogger, scheme, "klausmcpserver-controller"), //nolint:staticcheck
+		APIReader:         mgr.GetAPIReader(),
 		OperatorNamespace: operatorNamespace,
+		Prober:            controller.NewHTTPMCPServerProber(),
+		ProbeInterval:     mcpServerProbeInterval,
+		ControllerOptions: controllerOptions,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KlausMCPServer")
 		os.Exit(1)
 	}
 
+	// Set up the KlausSkillPack controller.
+	if err := (&controller.KlausSkillPackReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Recorder:          audit.NewEventRecorder(mgr.GetEventRecorderFor("klausskillpack-controller"), auditLogger, scheme, "klausskillpack-controller"), //nolint:staticcheck
+		APIReader:         mgr.GetAPIReader(),
+		ControllerOptions: controllerOptions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KlausSkillPack")
+		os.Exit(1)
+	}
+
+	// Set up the KlausFleetOperation controller.
+	if err := (&controller.KlausFleetOperationReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Recorder:          audit.NewEventRecorder(mgr.GetEventRecorderFor("klausfleetoperation-controller"), auditLogger, scheme, "klausfleetoperation-controller"), //nolint:staticcheck
+		APIReader:         mgr.GetAPIReader(),
+		OperatorNamespace: operatorNamespace,
+		NamespaceScoped:   namespaceScoped,
+		ControllerOptions: controllerOptions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "KlausFleetOperation")
+		os.Exit(1)
+	}
+
 	// Set up health checks.
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -133,23 +823,228 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Create a Kubernetes clientset for pod log access (the controller-runtime
-	// client does not support the pod log subresource).
-	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
-	if err != nil {
-		setupLog.Error(err, "unable to create Kubernetes clientset")
-		os.Exit(1)
-	}
 	podLogReader := mcp.NewPodLogReader(clientset.CoreV1())
-	agentClient := mcp.NewAgentMCPClient()
+	agentClient := mcp.NewAgentMCPClient(mcp.NewInstanceTLSConfigFunc(mgr.GetClient(), operatorNamespace, namespaceScoped))
+
+	// CPU and memory usage come from metrics-server, which cannot be watched,
+	// so PodMetrics are read without the cache.
+	usageCollector := &usage.Collector{Client: mgr.GetClient(), MetricsReader: mgr.GetAPIReader(), NamespaceScoped: namespaceScoped}
+	if prometheusURL != "" {
+		usageCollector.Prometheus = &usage.PrometheusClient{URL: prometheusURL}
+	}
+
+	// Add the MCP server as a manager runnable for graceful lifecycle
+	// management. Its workspace Jobs, PVC imports and restarts are child
+	// resources, written as the tenant in impersonation mode.
+	mcpServer := mcp.NewServer(childClient, operatorNamespace, mcpAddr, artifactLister, podLogReader, agentClient)
+	mcpServer.SetPermissionPolicy(permissionPolicy)
+	mcpServer.SetGitImage(gitCloneImage)
+	mcpServer.SetEventRecorder(mgr.GetEventRecorderFor("klaus-mcp-server")) //nolint:staticcheck
+	mcpServer.SetUsageCollector(usageCollector)
+	mcpServer.SetNamespaceScoped(namespaceScoped)
+	mcpServer.SetInstancePreviewer(instanceReconciler)
+	mcpServer.SetAdminUsers(splitList(adminUsers))
+	mcpServer.SetListenerOptions(mcp.ListenerOptions{
+		CertFile:        mcpTLSCertFile,
+		KeyFile:         mcpTLSKeyFile,
+		ClientCAFile:    mcpTLSClientCAFile,
+		ReadTimeout:     mcpReadTimeout,
+		WriteTimeout:    mcpWriteTimeout,
+		IdleTimeout:     mcpIdleTimeout,
+		MaxRequestBytes: mcpMaxRequestBytes,
+	})
+	mcpServer.SetLimits(mcp.Limits{
+		RequestsPerMinute:    mcpRateLimit,
+		Burst:                mcpRateBurst,
+		MaxInstancesPerOwner: maxInstancesPerOwner,
+	})
+	mcpServer.SetAuditLogger(auditLogger)
+	if oidcIssuerURL != "" {
+		groupsClaim := oidcGroupsClaim
+		if groupsClaim == "" {
+			groupsClaim = permissionPolicy.GroupsClaim
+		}
+		verifier, err := oidc.NewVerifier(oidc.Config{
+			IssuerURL:      oidcIssuerURL,
+			Audiences:      splitList(oidcAudiences),
+			UsernameClaims: splitList(oidcUsernameClaims),
+			GroupsClaim:    groupsClaim,
+			KeyCacheTTL:    oidcKeyCacheTTL,
+		})
+		if err != nil {
+			setupLog.Error(err, "invalid OIDC configuration")
+			os.Exit(1)
+		}
+		mcpServer.SetTokenVerifier(verifier)
+	} else if trustGatewayTokens {
+		setupLog.Info("--mcp-trust-gateway-tokens is set; MCP caller tokens are not verified and must only be forwarded by a trusted gateway")
+	} else {
+		setupLog.Info("neither --oidc-issuer-url nor --mcp-trust-gateway-tokens is set; MCP calls are rejected")
+		mcpServer.SetTokenVerifier(mcp.RejectTokens(errors.New(
+			"caller tokens cannot be verified: the operator needs --oidc-issuer-url, or --mcp-trust-gateway-tokens behind a trusted gateway")))
+	}
+	if auditLogger != nil {
+		if err := mgr.Add(auditLogger); err != nil {
+			setupLog.Error(err, "unable to add audit logger")
+			os.Exit(1)
+		}
+	}
 
-	// Add the MCP server as a manager runnable for graceful lifecycle management.
-	mcpServer := mcp.NewServer(mgr.GetClient(), operatorNamespace, mcpAddr, ociClient, podLogReader, agentClient)
 	if err := mgr.Add(mcpServer); err != nil {
 		setupLog.Error(err, "unable to add MCP server to manager")
 		os.Exit(1)
 	}
 
+	// Receive KlausTrigger events. Prompts are sent through the MCP
+	// server's agent client.
+	if triggerAddr != "" {
+		if err := mgr.Add(&trigger.Listener{
+			Client:            mgr.GetClient(),
+			Scheme:            mgr.GetScheme(),
+			Addr:              triggerAddr,
+			OperatorNamespace: operatorNamespace,
+			Prompter:          mcpServer,
+			Recorder:          audit.NewEventRecorder(mgr.GetEventRecorderFor("klaus-trigger-listener"), auditLogger, scheme, "klaus-trigger-listener"), //nolint:staticcheck
+		}); err != nil {
+			setupLog.Error(err, "unable to add trigger listener to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Migrate stored objects to the current storage version and report
+	// instances with stuck finalizers left behind by previous versions.
+	if err := mgr.Add(&upgrade.Migrator{
+		Client:    mgr.GetClient(),
+		Recorder:  audit.NewEventRecorder(mgr.GetEventRecorderFor("klaus-operator-upgrade"), auditLogger, scheme, "klaus-operator-upgrade"), //nolint:staticcheck
+		CRDs:      upgrade.ManagedCRDs,
+		Finalizer: controller.FinalizerName,
+	}); err != nil {
+		setupLog.Error(err, "unable to add upgrade migrator to manager")
+		os.Exit(1)
+	}
+
+	// Point the CRDs serving several versions at the conversion webhook,
+	// registered with the KlausInstance webhooks.
+	if enableWebhooks && webhookService != "" {
+		// The CA injector maintains the CA bundle when a Certificate is
+		// given; the mounted CA is only a starting point.
+		caBundle, err := os.ReadFile(filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs", "ca.crt"))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			setupLog.Error(err, "unable to read the webhook CA bundle")
+			os.Exit(1)
+		}
+		conversion := &upgrade.ConversionWebhook{
+			Client:   mgr.GetClient(),
+			CRDs:     upgrade.ManagedCRDs,
+			Service:  types.NamespacedName{Namespace: operatorNamespace, Name: webhookService},
+			Port:     443,
+			CABundle: caBundle,
+		}
+		if webhookCertificate != "" {
+			conversion.Certificate = operatorNamespace + "/" + webhookCertificate
+		}
+		if err := mgr.Add(conversion); err != nil {
+			setupLog.Error(err, "unable to add conversion webhook configuration to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Maintain the KlausFleetStatus singleton in the operator namespace.
+	if fleetStatusInterval > 0 {
+		if err := mgr.Add(&controller.FleetStatusReporter{
+			Client:      mgr.GetClient(),
+			APIReader:   mgr.GetAPIReader(),
+			Namespace:   operatorNamespace,
+			Interval:    fleetStatusInterval,
+			OCICacheDir: ociCacheDir,
+		}); err != nil {
+			setupLog.Error(err, "unable to add fleet status reporter to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Record instance resource usage in status.resourceUsage.
+	if usageInterval > 0 {
+		if err := mgr.Add(&controller.ResourceUsageReporter{
+			Client:    mgr.GetClient(),
+			Collector: usageCollector,
+			Interval:  usageInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add resource usage reporter to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Record instance token usage in status.tokenUsage and per-owner
+	// KlausUsageReports. Instances whose cost reached their budget are
+	// suspended by the KlausInstance controller.
+	if tokenUsageInterval > 0 && usageCollector.Prometheus != nil {
+		if err := mgr.Add(&controller.TokenUsageReporter{
+			Client:    mgr.GetClient(),
+			Collector: &usage.TokenCollector{Prometheus: usageCollector.Prometheus, NamespaceScoped: namespaceScoped},
+			Namespace: operatorNamespace,
+			Interval:  tokenUsageInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add token usage reporter to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Record the git state of instance workspaces in status.workspace.
+	if workspaceStatusInterval > 0 {
+		if err := mgr.Add(&controller.WorkspaceStatusReporter{
+			Client:          mgr.GetClient(),
+			Executor:        controller.NewPodExecutor(mgr.GetConfig(), clientset.CoreV1()),
+			Interval:        workspaceStatusInterval,
+			NamespaceScoped: namespaceScoped,
+		}); err != nil {
+			setupLog.Error(err, "unable to add workspace status reporter to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Record the final output of agent mode instances in status.result,
+	// also for prompts sent to the agent without the operator's MCP tools.
+	if resultInterval > 0 {
+		if err := mgr.Add(&controller.ResultReporter{
+			Client:   mgr.GetClient(),
+			Fetcher:  mcpServer,
+			Interval: resultInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add result reporter to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Replace the GitHub App installation tokens of workspaces before they
+	// expire.
+	if gitHubApp != nil {
+		if err := mgr.Add(&controller.GitHubAppTokenRefresher{
+			Client:          childClient,
+			Minter:          gitHubApp,
+			Interval:        gitHubAppTokenInterval,
+			NamespaceScoped: namespaceScoped,
+		}); err != nil {
+			setupLog.Error(err, "unable to add GitHub App token refresher to manager")
+			os.Exit(1)
+		}
+	}
+
+	// Delete child resources left behind by instances and jobs deleted
+	// without their finalizer running.
+	if orphanSweepInterval > 0 {
+		if err := mgr.Add(&controller.OrphanSweeper{
+			Client:          childClient,
+			APIReader:       mgr.GetAPIReader(),
+			Namespace:       operatorNamespace,
+			Interval:        orphanSweepInterval,
+			NamespaceScoped: namespaceScoped,
+		}); err != nil {
+			setupLog.Error(err, "unable to add orphan sweeper to manager")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager",
 		"version", project.Version(),
 		"gitSHA", project.GitSHA(),
@@ -160,3 +1055,34 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// registryClient resolves references and pulls personality souls;
+// *klausoci.Client and oci.OfflineClient implement it.
+type registryClient interface {
+	metrics.OCIResolver
+	oci.PersonalityPuller
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseHeaders parses a comma-separated list of key=value headers.
+func parseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range splitList(value) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("header %q is not key=value", pair)
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
@@ -0,0 +1,245 @@
+// Package ocibuild builds Klaus plugin and personality OCI artifacts in
+// memory. The artifacts use the same media types, manifest annotations and
+// layer format as klaus-oci, so tests can serve them from the in-process
+// Registry and publishing tooling can push them without a source directory
+// on disk.
+package ocibuild
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	godigest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+)
+
+// Artifact is a fully assembled OCI artifact: config blob, a single content
+// layer and the manifest referencing both.
+type Artifact struct {
+	Manifest           ocispec.Manifest
+	ManifestDescriptor ocispec.Descriptor
+	ManifestJSON       []byte
+	Config             []byte
+	Layer              []byte
+}
+
+// Digest returns the manifest digest, i.e. the value a reference resolves to.
+func (a *Artifact) Digest() string {
+	return a.ManifestDescriptor.Digest.String()
+}
+
+// Push stores the artifact blobs and manifest in target (e.g. an oras
+// memory store or remote repository) and tags the manifest unless tag is
+// empty. Blobs that already exist in target are skipped.
+func (a *Artifact) Push(ctx context.Context, target oras.Target, tag string) error {
+	blobs := []struct {
+		desc ocispec.Descriptor
+		data []byte
+	}{
+		{a.Manifest.Config, a.Config},
+		{a.Manifest.Layers[0], a.Layer},
+		{a.ManifestDescriptor, a.ManifestJSON},
+	}
+	for _, b := range blobs {
+		exists, err := target.Exists(ctx, b.desc)
+		if err != nil {
+			return fmt.Errorf("checking blob %s: %w", b.desc.Digest, err)
+		}
+		if exists {
+			continue
+		}
+		if err := target.Push(ctx, b.desc, bytes.NewReader(b.data)); err != nil {
+			return fmt.Errorf("pushing %s: %w", b.desc.MediaType, err)
+		}
+	}
+	if tag == "" {
+		return nil
+	}
+	if err := target.Tag(ctx, a.ManifestDescriptor, tag); err != nil {
+		return fmt.Errorf("tagging manifest as %s: %w", tag, err)
+	}
+	return nil
+}
+
+// pluginConfig mirrors the klaus-oci plugin config blob: only the
+// discovered components, common metadata lives in annotations.
+type pluginConfig struct {
+	Skills     []string `json:"skills,omitempty"`
+	Commands   []string `json:"commands,omitempty"`
+	Agents     []string `json:"agents,omitempty"`
+	HasHooks   bool     `json:"hasHooks,omitempty"`
+	MCPServers []string `json:"mcpServers,omitempty"`
+	LSPServers []string `json:"lspServers,omitempty"`
+}
+
+// personalityConfig mirrors the klaus-oci personality config blob.
+type personalityConfig struct {
+	Toolchain klausoci.ToolchainReference `json:"toolchain,omitempty"`
+	Plugins   []klausoci.PluginReference  `json:"plugins,omitempty"`
+}
+
+// BuildPlugin builds a plugin artifact from p and the plugin directory
+// contents in files (e.g. an fstest.MapFS or os.DirFS).
+func BuildPlugin(p klausoci.Plugin, files fs.FS) (*Artifact, error) {
+	config, err := json.Marshal(pluginConfig{
+		Skills:     p.Skills,
+		Commands:   p.Commands,
+		Agents:     p.Agents,
+		HasHooks:   p.HasHooks,
+		MCPServers: p.MCPServers,
+		LSPServers: p.LSPServers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling plugin config: %w", err)
+	}
+	annotations := Annotations(p.Name, p.Description, p.Author, p.Homepage, p.SourceRepo, p.License, p.Keywords)
+	return build(config, files, annotations, klausoci.MediaTypePluginConfig, klausoci.MediaTypePluginContent)
+}
+
+// BuildPersonality builds a personality artifact from p and the
+// personality directory contents in files (typically SOUL.md and
+// personality.yaml).
+func BuildPersonality(p klausoci.Personality, files fs.FS) (*Artifact, error) {
+	config, err := json.Marshal(personalityConfig{
+		Toolchain: p.Toolchain,
+		Plugins:   p.Plugins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling personality config: %w", err)
+	}
+	annotations := Annotations(p.Name, p.Description, p.Author, p.Homepage, p.SourceRepo, p.License, p.Keywords)
+	return build(config, files, annotations, klausoci.MediaTypePersonalityConfig, klausoci.MediaTypePersonalityContent)
+}
+
+// Annotations returns the io.giantswarm.klaus.* manifest annotations for
+// the given metadata. Empty fields are omitted; nil is returned when all
+// fields are empty.
+func Annotations(name, description string, author *klausoci.Author, homepage, repository, license string, keywords []string) map[string]string {
+	annotations := map[string]string{}
+	set := func(key, value string) {
+		if value != "" {
+			annotations[key] = value
+		}
+	}
+	set(klausoci.AnnotationName, name)
+	set(klausoci.AnnotationDescription, description)
+	set(klausoci.AnnotationHomepage, homepage)
+	set(klausoci.AnnotationRepository, repository)
+	set(klausoci.AnnotationLicense, license)
+	set(klausoci.AnnotationKeywords, strings.Join(keywords, ","))
+	if author != nil {
+		set(klausoci.AnnotationAuthorName, author.Name)
+		set(klausoci.AnnotationAuthorEmail, author.Email)
+		set(klausoci.AnnotationAuthorURL, author.URL)
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
+
+func build(config []byte, files fs.FS, annotations map[string]string, configMediaType, contentMediaType string) (*Artifact, error) {
+	layer, err := TarGz(files)
+	if err != nil {
+		return nil, fmt.Errorf("creating archive: %w", err)
+	}
+
+	manifest := ocispec.Manifest{
+		Versioned:   specs.Versioned{SchemaVersion: 2},
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      descriptor(configMediaType, config),
+		Layers:      []ocispec.Descriptor{descriptor(contentMediaType, layer)},
+		Annotations: annotations,
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling manifest: %w", err)
+	}
+
+	return &Artifact{
+		Manifest:           manifest,
+		ManifestDescriptor: descriptor(ocispec.MediaTypeImageManifest, manifestJSON),
+		ManifestJSON:       manifestJSON,
+		Config:             config,
+		Layer:              layer,
+	}, nil
+}
+
+func descriptor(mediaType string, data []byte) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    godigest.FromBytes(data),
+		Size:      int64(len(data)),
+	}
+}
+
+// TarGz archives files as a gzip-compressed tar in the layout klaus-oci
+// extracts. Entries are written in lexical order with zeroed timestamps and
+// ownership, so identical contents always produce the same digest.
+func TarGz(files fs.FS) ([]byte, error) {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	err := fs.WalkDir(files, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		// Only regular files and directories are packaged, as in klaus-oci.
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		header := &tar.Header{Name: path, Mode: int64(info.Mode().Perm()), Typeflag: tar.TypeReg}
+		if d.IsDir() {
+			header.Name += "/"
+			header.Typeflag = tar.TypeDir
+			if header.Mode == 0 {
+				header.Mode = 0o755
+			}
+			return tw.WriteHeader(header)
+		}
+		if header.Mode == 0 {
+			header.Mode = 0o644
+		}
+
+		data, err := fs.ReadFile(files, path)
+		if err != nil {
+			return err
+		}
+		header.Size = int64(len(data))
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, bytes.NewReader(data))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,245 @@
+package ocibuild
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"testing/fstest"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+func testPersonality() klausoci.Personality {
+	return klausoci.Personality{
+		Name:        "sre",
+		Description: "Site reliability engineer",
+		Author:      &klausoci.Author{Name: "Giant Swarm", Email: "dev@giantswarm.io"},
+		Keywords:    []string{"kubernetes", "oncall"},
+		Toolchain:   klausoci.ToolchainReference{Repository: "gsoci.azurecr.io/giantswarm/klaus-toolchains/go", Tag: "v1.0.0"},
+		Plugins: []klausoci.PluginReference{
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", Tag: "v0.1.0"},
+		},
+	}
+}
+
+func testPersonalityFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"SOUL.md":          {Data: []byte("You are an SRE.\n")},
+		"personality.yaml": {Data: []byte("name: sre\n")},
+	}
+}
+
+func tarEntries(t *testing.T, layer []byte) map[string]string {
+	t.Helper()
+	gzr, err := gzip.NewReader(bytes.NewReader(layer))
+	if err != nil {
+		t.Fatalf("opening gzip: %v", err)
+	}
+	tr := tar.NewReader(gzr)
+	entries := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading tar: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		entries[header.Name] = string(data)
+	}
+	return entries
+}
+
+func TestBuildPersonality(t *testing.T) {
+	art, err := BuildPersonality(testPersonality(), testPersonalityFiles())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if art.Manifest.MediaType != ocispec.MediaTypeImageManifest {
+		t.Errorf("manifest media type = %q", art.Manifest.MediaType)
+	}
+	if art.Manifest.Config.MediaType != klausoci.MediaTypePersonalityConfig {
+		t.Errorf("config media type = %q", art.Manifest.Config.MediaType)
+	}
+	if len(art.Manifest.Layers) != 1 || art.Manifest.Layers[0].MediaType != klausoci.MediaTypePersonalityContent {
+		t.Fatalf("layers = %+v", art.Manifest.Layers)
+	}
+
+	want := map[string]string{
+		klausoci.AnnotationName:        "sre",
+		klausoci.AnnotationDescription: "Site reliability engineer",
+		klausoci.AnnotationAuthorName:  "Giant Swarm",
+		klausoci.AnnotationAuthorEmail: "dev@giantswarm.io",
+		klausoci.AnnotationKeywords:    "kubernetes,oncall",
+	}
+	if len(art.Manifest.Annotations) != len(want) {
+		t.Errorf("annotations = %v", art.Manifest.Annotations)
+	}
+	for k, v := range want {
+		if art.Manifest.Annotations[k] != v {
+			t.Errorf("annotation %s = %q, want %q", k, art.Manifest.Annotations[k], v)
+		}
+	}
+
+	var config struct {
+		Toolchain klausoci.ToolchainReference `json:"toolchain"`
+		Plugins   []klausoci.PluginReference  `json:"plugins"`
+	}
+	if err := json.Unmarshal(art.Config, &config); err != nil {
+		t.Fatalf("decoding config: %v", err)
+	}
+	if config.Toolchain.Tag != "v1.0.0" || len(config.Plugins) != 1 {
+		t.Errorf("config = %+v", config)
+	}
+
+	entries := tarEntries(t, art.Layer)
+	if entries["SOUL.md"] != "You are an SRE.\n" {
+		t.Errorf("SOUL.md = %q", entries["SOUL.md"])
+	}
+	if _, ok := entries["personality.yaml"]; !ok {
+		t.Error("expected personality.yaml in layer")
+	}
+}
+
+func TestBuildPlugin(t *testing.T) {
+	plugin := klausoci.Plugin{
+		Name:       "gs-base",
+		Skills:     []string{"kubernetes"},
+		HasHooks:   true,
+		MCPServers: []string{"github"},
+	}
+	files := fstest.MapFS{
+		".claude-plugin/plugin.json":   {Data: []byte(`{"name":"gs-base"}`)},
+		"skills/kubernetes/SKILL.md":   {Data: []byte("# Kubernetes\n")},
+		"hooks/hooks.json":             {Data: []byte(`{}`)},
+		"scripts/check.sh":             {Data: []byte("#!/bin/sh\n"), Mode: 0o755},
+		".mcp.json":                    {Data: []byte(`{"mcpServers":{"github":{}}}`)},
+		"skills/kubernetes/README.txt": {Data: []byte("notes")},
+	}
+
+	art, err := BuildPlugin(plugin, files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if art.Manifest.Config.MediaType != klausoci.MediaTypePluginConfig {
+		t.Errorf("config media type = %q", art.Manifest.Config.MediaType)
+	}
+	if art.Manifest.Layers[0].MediaType != klausoci.MediaTypePluginContent {
+		t.Errorf("layer media type = %q", art.Manifest.Layers[0].MediaType)
+	}
+	if art.Manifest.Annotations[klausoci.AnnotationName] != "gs-base" {
+		t.Errorf("annotations = %v", art.Manifest.Annotations)
+	}
+	if string(art.Config) != `{"skills":["kubernetes"],"hasHooks":true,"mcpServers":["github"]}` {
+		t.Errorf("config = %s", art.Config)
+	}
+
+	gzr, err := gzip.NewReader(bytes.NewReader(art.Layer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gzr)
+	seenDir := false
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch header.Name {
+		case "skills/":
+			seenDir = header.Typeflag == tar.TypeDir
+		case "scripts/check.sh":
+			if header.Mode != 0o755 {
+				t.Errorf("check.sh mode = %o, want 755", header.Mode)
+			}
+		}
+	}
+	if !seenDir {
+		t.Error("expected skills/ directory entry")
+	}
+}
+
+func TestBuild_Reproducible(t *testing.T) {
+	a, err := BuildPersonality(testPersonality(), testPersonalityFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := BuildPersonality(testPersonality(), testPersonalityFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Digest() != b.Digest() {
+		t.Errorf("digests differ: %s != %s", a.Digest(), b.Digest())
+	}
+
+	files := testPersonalityFiles()
+	files["SOUL.md"] = &fstest.MapFile{Data: []byte("You are a different SRE.\n")}
+	c, err := BuildPersonality(testPersonality(), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Digest() == c.Digest() {
+		t.Error("expected different digest for different content")
+	}
+}
+
+func TestAnnotations_Empty(t *testing.T) {
+	if got := Annotations("", "", nil, "", "", "", nil); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestArtifactPush(t *testing.T) {
+	ctx := context.Background()
+	art, err := BuildPersonality(testPersonality(), testPersonalityFiles())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memory.New()
+
+	if err := art.Push(ctx, store, "v1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Pushing again is a no-op for existing blobs.
+	if err := art.Push(ctx, store, "latest"); err != nil {
+		t.Fatalf("unexpected error on second push: %v", err)
+	}
+
+	for _, tag := range []string{"v1.0.0", "latest"} {
+		desc, err := store.Resolve(ctx, tag)
+		if err != nil {
+			t.Fatalf("resolving %s: %v", tag, err)
+		}
+		if desc.Digest.String() != art.Digest() {
+			t.Errorf("%s resolved to %s, want %s", tag, desc.Digest, art.Digest())
+		}
+	}
+
+	manifestJSON, err := content.FetchAll(ctx, store, art.ManifestDescriptor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	layer, err := content.FetchAll(ctx, store, manifest.Layers[0])
+	if err != nil {
+		t.Fatalf("fetching layer: %v", err)
+	}
+	if tarEntries(t, layer)["SOUL.md"] != "You are an SRE.\n" {
+		t.Error("layer content mismatch after push")
+	}
+}
@@ -0,0 +1,136 @@
+//go:build e2e
+
+// Package e2e runs against a cluster with the operator chart installed, by
+// default the kind cluster set up by `make test-e2e`, and checks the pods the
+// operator starts for a KlausInstance.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// podTimeout bounds the wait for the instance pod, including the pull of
+// the mock agent image.
+const podTimeout = 5 * time.Minute
+
+// operatorNamespace is the namespace the chart is installed in,
+// E2E_OPERATOR_NAMESPACE or klaus-system.
+func operatorNamespace() string {
+	if ns := os.Getenv("E2E_OPERATOR_NAMESPACE"); ns != "" {
+		return ns
+	}
+	return "klaus-system"
+}
+
+func newClient(t *testing.T) client.Client {
+	t.Helper()
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		t.Fatalf("loading the kubeconfig: %v", err)
+	}
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(klausv1alpha1.AddToScheme(scheme))
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatalf("creating the client: %v", err)
+	}
+	return c
+}
+
+func TestKlausInstance_Pod(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	instance := &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "e2e-mock", Namespace: operatorNamespace()},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner:    "e2e@example.com",
+			MockMode: true,
+			Claude: klausv1alpha1.ClaudeConfig{
+				Model: "claude-sonnet-4-20250514",
+			},
+		},
+	}
+	if err := c.Create(ctx, instance); err != nil {
+		t.Fatalf("creating the KlausInstance: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := c.Delete(ctx, instance); err != nil && !apierrors.IsNotFound(err) {
+			t.Errorf("deleting the KlausInstance: %v", err)
+		}
+	})
+
+	namespace := resources.InstanceNamespace(instance, false)
+	var pod *corev1.Pod
+	deadline := time.Now().Add(podTimeout)
+	for pod == nil {
+		var pods corev1.PodList
+		err := c.List(ctx, &pods, client.InNamespace(namespace),
+			client.MatchingLabels{"app.kubernetes.io/instance": instance.Name})
+		if err == nil {
+			for i := range pods.Items {
+				if pods.Items[i].Status.Phase == corev1.PodRunning {
+					pod = &pods.Items[i]
+				}
+			}
+		}
+		if pod == nil {
+			if time.Now().After(deadline) {
+				_ = c.Get(ctx, client.ObjectKeyFromObject(instance), instance)
+				t.Fatalf("no running pod for the instance in %s (listing: %v), instance status %+v", namespace, err, instance.Status)
+			}
+			time.Sleep(2 * time.Second)
+		}
+	}
+
+	container := pod.Spec.Containers[0]
+	env := map[string]corev1.EnvVar{}
+	for _, e := range container.Env {
+		env[e.Name] = e
+	}
+	if got := env["PORT"].Value; got != fmt.Sprint(resources.KlausPort) {
+		t.Errorf("PORT = %q, want %d", got, resources.KlausPort)
+	}
+	if got := env["CLAUDE_MODEL"].Value; got != "claude-sonnet-4-20250514" {
+		t.Errorf("CLAUDE_MODEL = %q, want the model of the spec", got)
+	}
+	if got := env[resources.MockModeEnvVar].Value; got != "true" {
+		t.Errorf("%s = %q, want the mock agent", resources.MockModeEnvVar, got)
+	}
+	if _, ok := env["ANTHROPIC_API_KEY"]; ok {
+		t.Error("ANTHROPIC_API_KEY is set, want no API key in mock mode")
+	}
+
+	mounts := map[string]bool{}
+	for _, m := range container.VolumeMounts {
+		mounts[m.Name] = true
+	}
+	volumes := map[string]*corev1.Volume{}
+	for i := range pod.Spec.Volumes {
+		volumes[pod.Spec.Volumes[i].Name] = &pod.Spec.Volumes[i]
+	}
+	config := volumes[resources.ConfigVolumeName]
+	if config == nil || config.ConfigMap == nil || config.ConfigMap.Name != resources.ConfigMapName(instance) {
+		t.Errorf("config volume = %+v, want the instance ConfigMap", config)
+	}
+	if !mounts[resources.ConfigVolumeName] {
+		t.Errorf("mounts = %v, want the config volume mounted", container.VolumeMounts)
+	}
+}
@@ -0,0 +1,269 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	"github.com/giantswarm/klaus-operator/internal/controller"
+	"github.com/giantswarm/klaus-operator/internal/resources"
+)
+
+// eventually retries check until it returns nil or eventuallyTimeout passes.
+func eventually(t *testing.T, what string, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(eventuallyTimeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: %v", what, err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// newInstance returns an instance of its own owner, so every test gets a
+// user namespace of its own: envtest runs no namespace controller, so a
+// deleted namespace stays terminating.
+func newInstance(name string) *klausv1alpha1.KlausInstance {
+	return &klausv1alpha1.KlausInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: operatorNamespace},
+		Spec: klausv1alpha1.KlausInstanceSpec{
+			Owner: name + "@example.com",
+			Claude: klausv1alpha1.ClaudeConfig{
+				Model: "claude-sonnet-4-20250514",
+			},
+		},
+	}
+}
+
+func getDeployment(ctx context.Context, instance *klausv1alpha1.KlausInstance) (*appsv1.Deployment, error) {
+	var dep appsv1.Deployment
+	key := types.NamespacedName{Name: resources.DeploymentName(instance), Namespace: resources.InstanceNamespace(instance, false)}
+	return &dep, k8sClient.Get(ctx, key, &dep)
+}
+
+// markAvailable reports the Deployment available, as the Deployment
+// controller would once its pod is ready.
+func markAvailable(ctx context.Context, dep *appsv1.Deployment) error {
+	dep.Status.Replicas = 1
+	dep.Status.UpdatedReplicas = 1
+	dep.Status.ReadyReplicas = 1
+	dep.Status.AvailableReplicas = 1
+	dep.Status.ObservedGeneration = dep.Generation
+	return k8sClient.Status().Update(ctx, dep)
+}
+
+func envNamed(container corev1.Container, name string) *corev1.EnvVar {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			return &container.Env[i]
+		}
+	}
+	return nil
+}
+
+func TestKlausInstance_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	apiKey := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: anthropicKeySecret, Namespace: operatorNamespace},
+		Data:       map[string][]byte{"api-key": []byte("sk-ant-integration")},
+	}
+	if err := k8sClient.Create(ctx, apiKey); err != nil && !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("creating the API key Secret: %v", err)
+	}
+	server := &klausv1alpha1.KlausMCPServer{
+		ObjectMeta: metav1.ObjectMeta{Name: "github", Namespace: operatorNamespace},
+		Spec:       klausv1alpha1.KlausMCPServerSpec{Type: "streamable-http", URL: "https://mcp.example.com/github"},
+	}
+	if err := k8sClient.Create(ctx, server); err != nil {
+		t.Fatalf("creating the KlausMCPServer: %v", err)
+	}
+
+	instance := newInstance("lifecycle")
+	instance.Spec.InlinePersonality = &klausv1alpha1.InlinePersonality{
+		Soul:  "You review pull requests.",
+		Image: "registry.example.com/toolchains/go:1.24",
+	}
+	instance.Spec.MCPServers = []klausv1alpha1.MCPServerReference{{Name: "github"}}
+	if err := k8sClient.Create(ctx, instance); err != nil {
+		t.Fatalf("creating the KlausInstance: %v", err)
+	}
+	namespace := resources.InstanceNamespace(instance, false)
+
+	// The finalizer is added before any child is created.
+	eventually(t, "finalizer", func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		if !controllerutil.ContainsFinalizer(instance, controller.FinalizerName) {
+			return fmt.Errorf("finalizers = %v", instance.Finalizers)
+		}
+		return nil
+	})
+
+	// The API key is copied to the user namespace.
+	eventually(t, "API key Secret copy", func() error {
+		var secret corev1.Secret
+		if err := k8sClient.Get(ctx, types.NamespacedName{Name: resources.SecretName(instance), Namespace: namespace}, &secret); err != nil {
+			return err
+		}
+		if string(secret.Data["api-key"]) != "sk-ant-integration" {
+			return fmt.Errorf("api-key = %q", secret.Data["api-key"])
+		}
+		return nil
+	})
+
+	// The KlausMCPServer is resolved into the MCP configuration.
+	eventually(t, "MCP configuration", func() error {
+		var cm corev1.ConfigMap
+		if err := k8sClient.Get(ctx, types.NamespacedName{Name: resources.ConfigMapName(instance), Namespace: namespace}, &cm); err != nil {
+			return err
+		}
+		if !strings.Contains(cm.Data["mcp-config.json"], "https://mcp.example.com/github") {
+			return fmt.Errorf("mcp-config.json = %s", cm.Data["mcp-config.json"])
+		}
+		return nil
+	})
+
+	// The Deployment runs the image of the personality with the API key.
+	var dep *appsv1.Deployment
+	eventually(t, "Deployment", func() error {
+		var err error
+		dep, err = getDeployment(ctx, instance)
+		return err
+	})
+	container := dep.Spec.Template.Spec.Containers[0]
+	if container.Image != "registry.example.com/toolchains/go:1.24" {
+		t.Errorf("image = %s, want the image of the inline personality", container.Image)
+	}
+	if env := envNamed(container, "ANTHROPIC_API_KEY"); env == nil || env.ValueFrom == nil ||
+		env.ValueFrom.SecretKeyRef.Name != resources.SecretName(instance) {
+		t.Errorf("ANTHROPIC_API_KEY = %+v, want the copied Secret", env)
+	}
+	if env := envNamed(container, "CLAUDE_MODEL"); env == nil || env.Value != "claude-sonnet-4-20250514" {
+		t.Errorf("CLAUDE_MODEL = %+v, want the model of the spec", env)
+	}
+
+	// The instance is Pending until the Deployment is available.
+	eventually(t, "Pending state", func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		if instance.Status.State != klausv1alpha1.InstanceStatePending {
+			return fmt.Errorf("state = %s", instance.Status.State)
+		}
+		return nil
+	})
+	if err := markAvailable(ctx, dep); err != nil {
+		t.Fatalf("updating the Deployment status: %v", err)
+	}
+	eventually(t, "Running state", func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		ready := apimeta.FindStatusCondition(instance.Status.Conditions, controller.ConditionReady)
+		if instance.Status.State != klausv1alpha1.InstanceStateRunning || ready == nil || ready.Status != metav1.ConditionTrue {
+			return fmt.Errorf("state = %s, Ready = %+v", instance.Status.State, ready)
+		}
+		return nil
+	})
+	if instance.Status.Personality != resources.InlinePersonalityStatus || instance.Status.MCPServerCount != 1 {
+		t.Errorf("status = %+v, want the inline personality and the MCP server", instance.Status)
+	}
+
+	// Deleting the instance removes its children before the finalizer.
+	if err := k8sClient.Delete(ctx, instance); err != nil {
+		t.Fatalf("deleting the KlausInstance: %v", err)
+	}
+	eventually(t, "KlausInstance deletion", func() error {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("instance still exists with finalizers %v: %v", instance.Finalizers, err)
+	})
+	for _, obj := range []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: resources.DeploymentName(instance), Namespace: namespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: resources.ConfigMapName(instance), Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: resources.SecretName(instance), Namespace: namespace}},
+	} {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if err == nil && obj.GetDeletionTimestamp().IsZero() {
+			t.Errorf("%T %s still exists after the instance was deleted", obj, obj.GetName())
+		}
+	}
+}
+
+func TestKlausInstance_MissingMCPServer(t *testing.T) {
+	ctx := context.Background()
+
+	instance := newInstance("missing-mcp")
+	instance.Spec.MockMode = true
+	instance.Spec.MCPServers = []klausv1alpha1.MCPServerReference{{Name: "does-not-exist"}}
+	if err := k8sClient.Create(ctx, instance); err != nil {
+		t.Fatalf("creating the KlausInstance: %v", err)
+	}
+	t.Cleanup(func() { _ = k8sClient.Delete(ctx, instance) })
+
+	eventually(t, "Error state", func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		ready := apimeta.FindStatusCondition(instance.Status.Conditions, controller.ConditionReady)
+		if instance.Status.State != klausv1alpha1.InstanceStateError || ready == nil || ready.Status != metav1.ConditionFalse {
+			return fmt.Errorf("state = %s, Ready = %+v", instance.Status.State, ready)
+		}
+		return nil
+	})
+	if _, err := getDeployment(ctx, instance); !apierrors.IsNotFound(err) {
+		t.Errorf("getting the Deployment = %v, want none for an unresolved MCP server", err)
+	}
+}
+
+func TestKlausInstance_Stopped(t *testing.T) {
+	ctx := context.Background()
+
+	instance := newInstance("stopped")
+	instance.Spec.MockMode = true
+	instance.Spec.DesiredState = klausv1alpha1.DesiredStateStopped
+	if err := k8sClient.Create(ctx, instance); err != nil {
+		t.Fatalf("creating the KlausInstance: %v", err)
+	}
+	t.Cleanup(func() { _ = k8sClient.Delete(ctx, instance) })
+
+	eventually(t, "Stopped state", func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		if instance.Status.State != klausv1alpha1.InstanceStateStopped {
+			return fmt.Errorf("state = %s", instance.Status.State)
+		}
+		return nil
+	})
+	dep, err := getDeployment(ctx, instance)
+	if err != nil {
+		t.Fatalf("getting the Deployment: %v", err)
+	}
+	if dep.Spec.Replicas == nil || *dep.Spec.Replicas != 0 {
+		t.Errorf("replicas = %v, want the stopped instance scaled to zero", dep.Spec.Replicas)
+	}
+}
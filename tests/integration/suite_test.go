@@ -0,0 +1,146 @@
+//go:build integration
+
+// Package integration runs the KlausInstance controller against a real API
+// server and etcd started by envtest, covering the full reconcile loop that
+// the unit tests only exercise against the fake client. Run it with
+// `make test-integration`, which downloads the binaries and sets
+// KUBEBUILDER_ASSETS.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	klausv1alpha1 "github.com/giantswarm/klaus-operator/api/v1alpha1"
+	klausv1alpha2 "github.com/giantswarm/klaus-operator/api/v1alpha2"
+	"github.com/giantswarm/klaus-operator/internal/controller"
+)
+
+const (
+	operatorNamespace  = "klaus-system"
+	anthropicKeySecret = "anthropic-api-key"
+	klausImage         = "registry.example.com/klaus:v1"
+
+	// eventuallyTimeout bounds the wait for the controller to converge.
+	eventuallyTimeout = 30 * time.Second
+)
+
+// k8sClient talks to the envtest API server directly, without a cache, so
+// assertions see every write of the controller.
+var k8sClient client.Client
+
+func TestMain(m *testing.M) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		fmt.Println("skipping the integration tests: KUBEBUILDER_ASSETS is not set, run make test-integration")
+		os.Exit(0)
+	}
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	ctrl.SetLogger(zap.New(zap.WriteTo(os.Stderr), zap.UseDevMode(true)))
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "helm", "klaus-operator", "crds")},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "starting envtest: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := env.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "stopping envtest: %v\n", err)
+		}
+	}()
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(klausv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(klausv1alpha2.AddToScheme(scheme))
+
+	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "creating client: %v\n", err)
+		return 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := startManager(ctx, cfg, scheme); err != nil {
+		fmt.Fprintf(os.Stderr, "starting manager: %v\n", err)
+		return 1
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: operatorNamespace}}
+	if err := k8sClient.Create(ctx, ns); err != nil {
+		fmt.Fprintf(os.Stderr, "creating the operator namespace: %v\n", err)
+		return 1
+	}
+	return m.Run()
+}
+
+// startManager runs the KlausInstance controller configured like main.go,
+// without an OCI registry: instances use inline personalities.
+func startManager(ctx context.Context, cfg *rest.Config, scheme *runtime.Scheme) error {
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: "0",
+	})
+	if err != nil {
+		return err
+	}
+
+	indexes := []struct {
+		field   string
+		indexer client.IndexerFunc
+	}{
+		{controller.MCPServerRefIndexField, controller.IndexMCPServerRefs},
+		{controller.SkillPackRefIndexField, controller.IndexSkillPackRefs},
+		{controller.DependsOnIndexField, controller.IndexDependsOn},
+	}
+	for _, index := range indexes {
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &klausv1alpha1.KlausInstance{}, index.field, index.indexer); err != nil {
+			return fmt.Errorf("indexing %s: %w", index.field, err)
+		}
+	}
+
+	if err := (&controller.KlausInstanceReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Recorder:           mgr.GetEventRecorderFor("klausinstance-controller"), //nolint:staticcheck
+		KlausImage:         klausImage,
+		AnthropicKeySecret: anthropicKeySecret,
+		AnthropicKeyNs:     operatorNamespace,
+		OperatorNamespace:  operatorNamespace,
+		APIReader:          mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("setting up the KlausInstance controller: %w", err)
+	}
+
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "running manager: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+	return nil
+}